@@ -11,12 +11,15 @@ import (
 
 	"github.com/spf13/cobra"
 	"golang.org/x/sys/unix"
+
+	"kubevirt.io/kubevirt/pkg/util/rlimit"
 )
 
 var mntNamespace string
 var cpuTime uint32
 var megabyte uint32
 var targetUser string
+var rlimits []string
 
 func init() {
 	// main needs to be locked on one thread and no go routines
@@ -77,6 +80,16 @@ func main() {
 				}
 			}
 
+			for _, value := range rlimits {
+				resource, limit, err := rlimit.Parse(value)
+				if err != nil {
+					return err
+				}
+				if err := syscall.Setrlimit(resource, &limit); err != nil {
+					return fmt.Errorf("error setting prlimit on resource %d with value %v: %v", resource, limit, err)
+				}
+			}
+
 			// Now let's switch users and drop privileges
 			if u != nil {
 				uid, err := strconv.ParseInt(u.Uid, 10, 32)
@@ -112,6 +125,7 @@ func main() {
 	rootCmd.PersistentFlags().Uint32Var(&megabyte, "memory", 0, "memory in megabyte for the process")
 	rootCmd.PersistentFlags().StringVar(&mntNamespace, "mount", "", "mount namespace to use")
 	rootCmd.PersistentFlags().StringVar(&targetUser, "user", "", "switch to this targetUser to e.g. drop privileges")
+	rootCmd.PersistentFlags().StringArrayVar(&rlimits, "rlimit", nil, "resource limit for the process, as <resource>=<soft>:<hard>, can be repeated")
 
 	execCmd := &cobra.Command{
 		Use:   "exec",
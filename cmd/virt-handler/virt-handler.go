@@ -34,6 +34,7 @@ import (
 
 	"github.com/emicklei/go-restful"
 	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
 	flag "github.com/spf13/pflag"
 	k8sv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/fields"
@@ -63,6 +64,7 @@ import (
 	_ "kubevirt.io/kubevirt/pkg/monitoring/workqueue/prometheus" // import for prometheus metrics
 	"kubevirt.io/kubevirt/pkg/service"
 	"kubevirt.io/kubevirt/pkg/util"
+	"kubevirt.io/kubevirt/pkg/util/fdhygiene"
 	"kubevirt.io/kubevirt/pkg/util/webhooks"
 	virtconfig "kubevirt.io/kubevirt/pkg/virt-config"
 	virthandler "kubevirt.io/kubevirt/pkg/virt-handler"
@@ -79,6 +81,10 @@ import (
 const (
 	defaultWatchdogTimeout = 30 * time.Second
 
+	// Interval at which the FDs kept open to monitor the launchers are
+	// audited for close-on-exec.
+	closeOnExecAuditInterval = 5 * time.Minute
+
 	// Default port that virt-handler listens on.
 	defaultPort = 8185
 
@@ -121,6 +127,7 @@ type virtHandlerApp struct {
 	MaxDevices                int
 	MaxRequestsInFlight       int
 	domainResyncPeriodSeconds int
+	SELinuxDenialAuditLog     string
 
 	caConfigMapName    string
 	clientCertFilePath string
@@ -302,6 +309,7 @@ func (app *virtHandlerApp) Run() {
 	app.clusterConfig = virtconfig.NewClusterConfig(factory.ConfigMap(), factory.CRD(), factory.KubeVirt(), app.namespace)
 	// set log verbosity
 	app.clusterConfig.SetConfigModifiedCallback(app.shouldChangeLogVerbosity)
+	app.clusterConfig.SetConfigModifiedCallback(app.shouldAllowPermissiveTransitions)
 
 	vmController := virthandler.NewController(
 		recorder,
@@ -321,6 +329,11 @@ func (app *virtHandlerApp) Run() {
 		app.clientTLSConfig,
 		podIsolationDetector,
 	)
+	if app.SELinuxDenialAuditLog != "" {
+		vmController.WatchSELinuxDenials(app.SELinuxDenialAuditLog)
+	}
+	// unlike the private dir, the lib dir is not a tmpfs and survives reboots
+	vmController.DetectNodeReboots(filepath.Join(app.VirtLibDir, "boot-id"))
 
 	promErrCh := make(chan error)
 	go app.runPrometheusServer(promErrCh)
@@ -335,6 +348,17 @@ func (app *virtHandlerApp) Run() {
 		app.VirtShareDir,
 	)
 
+	selinuxHandler := rest.NewSELinuxHandler(
+		podIsolationDetector,
+		vmiInformer,
+	)
+
+	relabelHandler := rest.NewRelabelHandler(
+		vmController,
+		vmiInformer,
+		app.HostOverride,
+	)
+
 	promvm.SetupCollector(app.virtCli, app.VirtShareDir, app.HostOverride, app.MaxRequestsInFlight)
 
 	go app.clientcertmanager.Start()
@@ -344,6 +368,7 @@ func (app *virtHandlerApp) Run() {
 	stop := make(chan struct{})
 	defer close(stop)
 	factory.Start(stop)
+	go fdhygiene.RunCloseOnExecAudit(closeOnExecAuditInterval, stop)
 
 	se, exists, err := selinux.NewSELinux()
 	if err == nil && exists {
@@ -353,6 +378,18 @@ func (app *virtHandlerApp) Run() {
 		if err != nil {
 			panic(fmt.Errorf("failed to install virt-launcher selinux policy: %v", err))
 		}
+		// report the policy modules the launchers need which are missing on
+		// the node, with the heartbeat
+		if enumerator, ok := se.(selinux.PolicyModuleEnumerator); ok {
+			vmController.CheckSELinuxPolicyModules(enumerator)
+		}
+		// put the types marked permissive by the executors of the previous
+		// run back to enforcing
+		if manager, ok := se.(selinux.PermissiveDomainManager); ok {
+			if err := selinux.ReconcilePermissiveDomains(manager, filepath.Join(app.VirtLibDir, "selinux-permissive-types")); err != nil {
+				log.Log.Reason(err).Error("failed to reconcile the selinux types marked permissive by virt-handler")
+			}
+		}
 
 		// relabel tun device
 		unprivilegedContainerSELinuxLabel := "system_u:object_r:container_file_t:s0"
@@ -367,10 +404,19 @@ func (app *virtHandlerApp) Run() {
 
 	cache.WaitForCacheSync(stop, factory.ConfigMap().HasSynced, vmiInformer.HasSynced, factory.CRD().HasSynced)
 
+	if exists {
+		// report a policy blocking the launcher contexts before VMIs fail on it,
+		// the check logs its outcome and exposes it as a metric
+		if launcherType := app.clusterConfig.GetSELinuxLauncherType(); launcherType != "" {
+			selinux.CheckExecTransition(nil, launcherType)
+		}
+	}
+
 	go vmController.Run(10, stop)
 
 	errCh := make(chan error)
-	go app.runServer(errCh, consoleHandler, lifecycleHandler)
+	go app.runServer(errCh, consoleHandler, lifecycleHandler, selinuxHandler)
+	go app.runRelabelServer(errCh, relabelHandler)
 
 	// wait for one of the servers to exit
 	fmt.Println(<-errCh)
@@ -383,6 +429,12 @@ func (app *virtHandlerApp) shouldChangeLogVerbosity() {
 	log.Log.V(2).Infof("set verbosity to %d", verbosity)
 }
 
+func (app *virtHandlerApp) shouldAllowPermissiveTransitions() {
+	allowed := app.clusterConfig.SELinuxPermissiveTransitionsEnabled()
+	selinux.AllowPermissiveTransitions(allowed)
+	log.Log.V(2).Infof("set the selinux permissive transitions allowed to %t", allowed)
+}
+
 func (app *virtHandlerApp) runPrometheusServer(errCh chan error) {
 	mux := restful.NewContainer()
 	webService := new(restful.WebService)
@@ -390,6 +442,9 @@ func (app *virtHandlerApp) runPrometheusServer(errCh chan error) {
 	webService.Route(webService.GET("/healthz").To(healthz.KubeConnectionHealthzFuncFactory(app.clusterConfig)).Doc("Health endpoint"))
 	mux.Add(webService)
 	log.Log.V(1).Infof("metrics: max concurrent requests=%d", app.MaxRequestsInFlight)
+	if err := fdhygiene.RegisterMetrics(prometheus.DefaultRegisterer); err != nil {
+		log.Log.Reason(err).Error("failed to register the fd hygiene metrics")
+	}
 	mux.Handle("/metrics", promvm.Handler(app.MaxRequestsInFlight))
 	server := http.Server{
 		Addr:      app.ServiceListen.Address(),
@@ -399,15 +454,19 @@ func (app *virtHandlerApp) runPrometheusServer(errCh chan error) {
 	errCh <- server.ListenAndServeTLS("", "")
 }
 
-func (app *virtHandlerApp) runServer(errCh chan error, consoleHandler *rest.ConsoleHandler, lifecycleHandler *rest.LifecycleHandler) {
+func (app *virtHandlerApp) runServer(errCh chan error, consoleHandler *rest.ConsoleHandler, lifecycleHandler *rest.LifecycleHandler, selinuxHandler *rest.SELinuxHandler) {
 	ws := new(restful.WebService)
 	ws.Route(ws.GET("/v1/namespaces/{namespace}/virtualmachineinstances/{name}/console").To(consoleHandler.SerialHandler))
 	ws.Route(ws.GET("/v1/namespaces/{namespace}/virtualmachineinstances/{name}/vnc").To(consoleHandler.VNCHandler))
 	ws.Route(ws.PUT("/v1/namespaces/{namespace}/virtualmachineinstances/{name}/pause").To(lifecycleHandler.PauseHandler))
 	ws.Route(ws.PUT("/v1/namespaces/{namespace}/virtualmachineinstances/{name}/unpause").To(lifecycleHandler.UnpauseHandler))
+	ws.Route(ws.PUT("/v1/namespaces/{namespace}/virtualmachineinstances/{name}/softreboot").To(lifecycleHandler.SoftRebootHandler))
+	ws.Route(ws.PUT("/v1/namespaces/{namespace}/virtualmachineinstances/{name}/freeze").To(lifecycleHandler.FreezeHandler))
+	ws.Route(ws.PUT("/v1/namespaces/{namespace}/virtualmachineinstances/{name}/unfreeze").To(lifecycleHandler.UnfreezeHandler))
 	ws.Route(ws.GET("/v1/namespaces/{namespace}/virtualmachineinstances/{name}/guestosinfo").To(lifecycleHandler.GetGuestInfo).Produces(restful.MIME_JSON).Consumes(restful.MIME_JSON).Returns(http.StatusOK, "OK", v1.VirtualMachineInstanceGuestAgentInfo{}))
 	ws.Route(ws.GET("/v1/namespaces/{namespace}/virtualmachineinstances/{name}/userlist").To(lifecycleHandler.GetUsers).Produces(restful.MIME_JSON).Consumes(restful.MIME_JSON).Returns(http.StatusOK, "OK", v1.VirtualMachineInstanceGuestOSUserList{}))
 	ws.Route(ws.GET("/v1/namespaces/{namespace}/virtualmachineinstances/{name}/filesystemlist").To(lifecycleHandler.GetFilesystems).Produces(restful.MIME_JSON).Consumes(restful.MIME_JSON).Returns(http.StatusOK, "OK", v1.VirtualMachineInstanceFileSystemList{}))
+	ws.Route(ws.GET("/v1/namespaces/{namespace}/virtualmachineinstances/{name}/selinuxlabels").To(selinuxHandler.LabelsHandler).Produces(restful.MIME_JSON).Returns(http.StatusOK, "OK", selinux.LabelReport{}))
 	restful.DefaultContainer.Add(ws)
 	server := &http.Server{
 		Addr:    fmt.Sprintf("%s:%d", app.ServiceListen.BindAddress, app.consoleServerPort),
@@ -418,6 +477,17 @@ func (app *virtHandlerApp) runServer(errCh chan error, consoleHandler *rest.Cons
 	errCh <- server.ListenAndServeTLS("", "")
 }
 
+// runRelabelServer serves the on-demand relabeling of the VMIs to the root
+// processes of the node, on a unix socket in the private dir.
+func (app *virtHandlerApp) runRelabelServer(errCh chan error, relabelHandler *rest.RelabelHandler) {
+	listener, err := rest.ListenRelabelSocket(filepath.Join(app.VirtPrivateDir, "relabel", "relabel.sock"))
+	if err != nil {
+		errCh <- fmt.Errorf("failed to listen on the relabel socket: %v", err)
+		return
+	}
+	errCh <- rest.NewRelabelServer(relabelHandler).Serve(listener)
+}
+
 func (app *virtHandlerApp) AddFlags() {
 	app.InitFlags()
 
@@ -480,6 +550,9 @@ func (app *virtHandlerApp) AddFlags() {
 	flag.IntVar(&app.domainResyncPeriodSeconds, "domain-resync-period-seconds", defaultDomainResyncPeriodSeconds,
 		"Recurring period for resyncing all known virt-launcher domains.")
 
+	flag.StringVar(&app.SELinuxDenialAuditLog, "selinux-denial-audit-log", "",
+		"Audit log to watch for the SELinux denials of the launchers, e.g. /proc/1/root/var/log/audit/audit.log. The last denial of a launcher is recorded on its VMI. Disabled if empty.")
+
 }
 
 func (app *virtHandlerApp) setupTLS(factory controller.KubeInformerFactory) error {
@@ -530,6 +603,7 @@ func relabelFiles(newLabel string, files ...string) error {
 	relabelArgs := []string{"selinux", "relabel", newLabel}
 	for _, file := range files {
 		cmd := exec.Command("virt-chroot", append(relabelArgs, file)...)
+		fdhygiene.PreventLeakOntoChild()
 		err := cmd.Run()
 		if err != nil {
 			return fmt.Errorf("error relabeling file %s with label %s. Reason: %v", file, newLabel, err)
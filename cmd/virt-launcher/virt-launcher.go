@@ -108,9 +108,9 @@ func startCmdServer(socketPath string,
 	return done
 }
 
-func createLibvirtConnection() virtcli.Connection {
+func createLibvirtConnection(allowedAgentCommands []string) virtcli.Connection {
 	libvirtUri := "qemu:///system"
-	domainConn, err := virtcli.NewConnection(libvirtUri, "", "", 10*time.Second)
+	domainConn, err := virtcli.NewConnection(libvirtUri, "", "", 10*time.Second, allowedAgentCommands)
 	if err != nil {
 		panic(fmt.Sprintf("failed to connect to libvirtd: %v", err))
 	}
@@ -130,6 +130,7 @@ func startDomainEventMonitoring(
 	qemuAgentFileInterval time.Duration,
 	qemuAgentUserInterval time.Duration,
 	qemuAgentVersionInterval time.Duration,
+	hostDeviceHealthInterval time.Duration,
 ) {
 	go func() {
 		for {
@@ -140,7 +141,7 @@ func startDomainEventMonitoring(
 		}
 	}()
 
-	err := notifier.StartDomainNotifier(domainConn, deleteNotificationSent, vmi, domainName, agentStore, qemuAgentSysInterval, qemuAgentFileInterval, qemuAgentUserInterval, qemuAgentVersionInterval)
+	err := notifier.StartDomainNotifier(domainConn, deleteNotificationSent, vmi, domainName, agentStore, qemuAgentSysInterval, qemuAgentFileInterval, qemuAgentUserInterval, qemuAgentVersionInterval, hostDeviceHealthInterval)
 	if err != nil {
 		panic(err)
 	}
@@ -211,6 +212,11 @@ func initializeDirs(virtShareDir string,
 	if err != nil {
 		panic(err)
 	}
+
+	err = virtlauncher.InitializeDisksDirectories(config.HostDeviceROMsDir)
+	if err != nil {
+		panic(err)
+	}
 }
 
 func waitForDomainUUID(timeout time.Duration, events chan watch.Event, stop chan struct{}, domainManager virtwrap.DomainManager) *api.Domain {
@@ -322,6 +328,8 @@ func main() {
 	qemuAgentFileInterval := pflag.Duration("qemu-agent-file-interval", 300, "Interval in seconds between consecutive qemu agent calls for file command")
 	qemuAgentUserInterval := pflag.Duration("qemu-agent-user-interval", 10, "Interval in seconds between consecutive qemu agent calls for user command")
 	qemuAgentVersionInterval := pflag.Duration("qemu-agent-version-interval", 300, "Interval in seconds between consecutive qemu agent calls for version command")
+	hostDeviceHealthInterval := pflag.Duration("host-device-health-interval", 30*time.Second, "Interval between consecutive sysfs health checks of the passed through host devices, 0 disables the checks")
+	allowedGuestAgentCommands := pflag.StringSlice("allowed-guest-agent-commands", nil, "Guest agent commands which may be executed on the VMI, all commands are allowed if empty")
 	// set new default verbosity, was set to 0 by glog
 	goflag.Set("v", "2")
 
@@ -373,7 +381,7 @@ func main() {
 	domainName := api.VMINamespaceKeyFunc(vmi)
 	util.StartVirtlog(stopChan, domainName)
 
-	domainConn := createLibvirtConnection()
+	domainConn := createLibvirtConnection(*allowedGuestAgentCommands)
 	defer domainConn.Close()
 
 	var agentStore = agentpoller.NewAsyncAgentStore()
@@ -421,7 +429,7 @@ func main() {
 
 	events := make(chan watch.Event, 2)
 	// Send domain notifications to virt-handler
-	startDomainEventMonitoring(notifier, *virtShareDir, domainConn, events, vmi, domainName, &agentStore, *qemuAgentSysInterval, *qemuAgentFileInterval, *qemuAgentUserInterval, *qemuAgentVersionInterval)
+	startDomainEventMonitoring(notifier, *virtShareDir, domainConn, events, vmi, domainName, &agentStore, *qemuAgentSysInterval, *qemuAgentFileInterval, *qemuAgentUserInterval, *qemuAgentVersionInterval, *hostDeviceHealthInterval)
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt,
@@ -443,6 +451,15 @@ func main() {
 	// managing virtual machines.
 	markReady()
 
+	// The compute container only becomes ready once virt-handler confirmed
+	// that it applied the SELinux labels of the VMI.
+	readinessGate := virtlauncher.NewReadinessGate(cmdclient.SELinuxRelabeledFileOnGuest(), cmdclient.ReadyFileOnGuest())
+	go func() {
+		if err := readinessGate.Run(signalStopChan); err != nil && err != wait.ErrWaitTimeout {
+			log.Log.Reason(err).Error("failed to run the readiness gate")
+		}
+	}()
+
 	domain := waitForDomainUUID(*qemuTimeout, events, signalStopChan, domainManager)
 	if domain != nil {
 		mon := virtlauncher.NewProcessMonitor(domain.Spec.UUID,
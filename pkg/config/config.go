@@ -56,6 +56,12 @@ var (
 	SecretSourceDir = mountBaseDir + "/secret"
 	// DownwardAPISourceDir represents a location where downwardapi is attached to the pod
 	DownwardAPISourceDir = mountBaseDir + "/downwardapi"
+	// SMBiosSecretSourceDir represents a location where the secret with the SMBIOS system information is attached to the pod
+	SMBiosSecretSourceDir = mountBaseDir + "/smbios-secret"
+	// SecureBootCertificatesSourceDir represents a location where the secrets with the Secure Boot certificates are attached to the pod
+	SecureBootCertificatesSourceDir = mountBaseDir + "/secure-boot-certificates"
+	// HostDeviceROMSourceDir represents a location where the config maps and secrets with the ROM images of the host devices are attached to the pod
+	HostDeviceROMSourceDir = mountBaseDir + "/host-device-rom"
 	// ServiceAccountSourceDir represents the location where the ServiceAccount token is attached to the pod
 	ServiceAccountSourceDir = "/var/run/secrets/kubernetes.io/serviceaccount/"
 
@@ -69,6 +75,8 @@ var (
 	ServiceAccountDiskDir = mountBaseDir + "/service-account-disk"
 	// ServiceAccountDiskName represents the name of the ServiceAccount iso image
 	ServiceAccountDiskName = "service-account.iso"
+	// HostDeviceROMsDir represents a path to the ROM images of the host devices
+	HostDeviceROMsDir = mountBaseDir + "/host-device-roms"
 
 	createISOImage = defaultCreateIsoImage
 )
@@ -0,0 +1,93 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	ephemeraldiskutils "kubevirt.io/kubevirt/pkg/ephemeral-disk-utils"
+)
+
+const (
+	// HostDeviceROMKey is the config map or secret key holding the ROM image of a host device
+	HostDeviceROMKey = "rom"
+	// MaxHostDeviceROMSize is the size of the largest expansion ROM a PCI device can map
+	MaxHostDeviceROMSize = 16 * 1024 * 1024
+)
+
+// hostDeviceROMSignature starts every PCI expansion ROM image
+var hostDeviceROMSignature = []byte{0x55, 0xaa}
+
+// GetHostDeviceROMSourcePath returns a path to the config map or secret with the ROM image of a host device mounted on a pod
+func GetHostDeviceROMSourcePath(deviceName string) string {
+	return filepath.Join(HostDeviceROMSourceDir, deviceName)
+}
+
+// GetHostDeviceROMPath returns a path to the ROM image of a host device handed to libvirt
+func GetHostDeviceROMPath(deviceName string) string {
+	return filepath.Join(HostDeviceROMsDir, deviceName+".rom")
+}
+
+// CreateHostDeviceROMs validates the ROM images of the host devices of the vmi, read from their mounted
+// config maps or secrets, and copies them to the files owned by qemu libvirt points the host devices at
+func CreateHostDeviceROMs(vmi *v1.VirtualMachineInstance) error {
+	for _, hostDev := range vmi.Spec.Domain.Devices.HostDevices {
+		if hostDev.ROM == nil {
+			continue
+		}
+		source := filepath.Join(GetHostDeviceROMSourcePath(hostDev.Name), HostDeviceROMKey)
+		rom, err := ioutil.ReadFile(source)
+		if os.IsNotExist(err) {
+			return fmt.Errorf("the ROM image of host device %s is missing, the key %s of its source is required", hostDev.Name, HostDeviceROMKey)
+		} else if err != nil {
+			return fmt.Errorf("failed to read the ROM image of host device %s: %v", hostDev.Name, err)
+		}
+		if err := validateHostDeviceROM(rom); err != nil {
+			return fmt.Errorf("invalid ROM image for host device %s: %v", hostDev.Name, err)
+		}
+
+		target := GetHostDeviceROMPath(hostDev.Name)
+		if err := ioutil.WriteFile(target, rom, 0644); err != nil {
+			return fmt.Errorf("failed to write the ROM image of host device %s: %v", hostDev.Name, err)
+		}
+		if err := ephemeraldiskutils.DefaultOwnershipManager.SetFileOwnership(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateHostDeviceROM(rom []byte) error {
+	if len(rom) == 0 {
+		return fmt.Errorf("the image is empty")
+	}
+	if len(rom) > MaxHostDeviceROMSize {
+		return fmt.Errorf("the image has %d bytes, more than the %d bytes of the largest PCI expansion ROM", len(rom), MaxHostDeviceROMSize)
+	}
+	if !bytes.HasPrefix(rom, hostDeviceROMSignature) {
+		return fmt.Errorf("the image does not start with the 0x55 0xaa signature of PCI expansion ROMs")
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	k8sv1 "k8s.io/api/core/v1"
+
+	v1 "kubevirt.io/client-go/api/v1"
+)
+
+var _ = Describe("Host device ROM", func() {
+	var vmi *v1.VirtualMachineInstance
+
+	writeROM := func(deviceName string, rom []byte) {
+		Expect(os.MkdirAll(GetHostDeviceROMSourcePath(deviceName), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(GetHostDeviceROMSourcePath(deviceName), HostDeviceROMKey), rom, 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		HostDeviceROMSourceDir, err = ioutil.TempDir("", "host-device-rom")
+		Expect(err).NotTo(HaveOccurred())
+		HostDeviceROMsDir, err = ioutil.TempDir("", "host-device-roms")
+		Expect(err).NotTo(HaveOccurred())
+
+		vmi = v1.NewMinimalVMI("fake-vmi")
+		vmi.Spec.Domain.Devices.HostDevices = []v1.HostDevice{
+			{
+				Name:       "gpu1",
+				DeviceName: "vendor.com/gpu",
+				ROM: &v1.HostDeviceROM{
+					ConfigMap: &k8sv1.LocalObjectReference{Name: "gpu-rom"},
+				},
+			},
+			{
+				Name:       "nic1",
+				DeviceName: "vendor.com/nic",
+			},
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(HostDeviceROMSourceDir)
+		os.RemoveAll(HostDeviceROMsDir)
+	})
+
+	It("Should copy the ROM image of the host devices which have one", func() {
+		rom := []byte{0x55, 0xaa, 0x40, 0xe9}
+		writeROM("gpu1", rom)
+
+		Expect(CreateHostDeviceROMs(vmi)).To(Succeed())
+		content, err := ioutil.ReadFile(GetHostDeviceROMPath("gpu1"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(Equal(rom))
+		_, err = os.Stat(GetHostDeviceROMPath("nic1"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("Should fail if the source has no ROM image", func() {
+		Expect(os.MkdirAll(GetHostDeviceROMSourcePath("gpu1"), 0755)).To(Succeed())
+
+		err := CreateHostDeviceROMs(vmi)
+		Expect(err).To(MatchError("the ROM image of host device gpu1 is missing, the key rom of its source is required"))
+	})
+
+	table.DescribeTable("Should reject an invalid ROM image", func(rom []byte, reason string) {
+		writeROM("gpu1", rom)
+
+		err := CreateHostDeviceROMs(vmi)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(reason))
+		_, err = os.Stat(GetHostDeviceROMPath("gpu1"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	},
+		table.Entry("which is empty", []byte{}, "the image is empty"),
+		table.Entry("without the signature", []byte{0x7f, 'E', 'L', 'F'}, "signature"),
+		table.Entry("larger than a PCI expansion ROM", append([]byte{0x55, 0xaa}, make([]byte, MaxHostDeviceROMSize)...), "more than the 16777216 bytes"),
+	)
+})
@@ -20,12 +20,49 @@
 package config
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strings"
 
 	v1 "kubevirt.io/client-go/api/v1"
 	ephemeraldiskutils "kubevirt.io/kubevirt/pkg/ephemeral-disk-utils"
 )
 
+const (
+	// SMBiosManufacturerKey is the secret key holding the SMBIOS system manufacturer
+	SMBiosManufacturerKey = "manufacturer"
+	// SMBiosProductKey is the secret key holding the SMBIOS system product name
+	SMBiosProductKey = "product"
+	// SMBiosOEMStringsKey is the secret key holding the SMBIOS OEM strings, one per line
+	SMBiosOEMStringsKey = "oemStrings"
+
+	// SecureBootCertificatesKey is the secret key holding the certificates of a Secure Boot certificate store
+	SecureBootCertificatesKey = "certificates"
+	// SecureBootPKStore is the Secure Boot certificate store holding the Platform Key
+	SecureBootPKStore = "pk"
+	// SecureBootKEKStore is the Secure Boot certificate store holding the Key Exchange Keys
+	SecureBootKEKStore = "kek"
+	// SecureBootDBStore is the Secure Boot certificate store holding the allowed signature database
+	SecureBootDBStore = "db"
+)
+
+// SecureBootCertificatesSecrets holds the raw content of the secrets referenced by the vmi EFI bootloader,
+// one per Secure Boot certificate store
+type SecureBootCertificatesSecrets struct {
+	PK  []byte
+	KEK []byte
+	DB  []byte
+}
+
+// SMBiosSecret holds the SMBIOS system information read from the secret referenced by the vmi firmware
+type SMBiosSecret struct {
+	Manufacturer string
+	Product      string
+	OEMStrings   []string
+}
+
 // GetSecretSourcePath returns a path to Secret mounted on a pod
 func GetSecretSourcePath(volumeName string) string {
 	return filepath.Join(SecretSourceDir, volumeName)
@@ -60,3 +97,64 @@ func CreateSecretDisks(vmi *v1.VirtualMachineInstance) error {
 
 	return nil
 }
+
+// ReadSMBiosSecret reads the SMBIOS system information from the secret mounted on the pod.
+// Keys which are not present in the secret are left empty.
+func ReadSMBiosSecret() (*SMBiosSecret, error) {
+	if _, err := os.Stat(SMBiosSecretSourceDir); err != nil {
+		return nil, err
+	}
+
+	smbios := &SMBiosSecret{}
+	var err error
+	if smbios.Manufacturer, err = readSecretKey(SMBiosManufacturerKey); err != nil {
+		return nil, err
+	}
+	if smbios.Product, err = readSecretKey(SMBiosProductKey); err != nil {
+		return nil, err
+	}
+	oemStrings, err := readSecretKey(SMBiosOEMStringsKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(oemStrings, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			smbios.OEMStrings = append(smbios.OEMStrings, line)
+		}
+	}
+
+	return smbios, nil
+}
+
+// GetSecureBootCertificatesSourcePath returns a path to the secret of a Secure Boot certificate store mounted on a pod
+func GetSecureBootCertificatesSourcePath(store string) string {
+	return filepath.Join(SecureBootCertificatesSourceDir, store)
+}
+
+// ReadSecureBootCertificatesSecrets reads the certificates of all Secure Boot certificate stores from the
+// secrets mounted on the pod. All stores are required.
+func ReadSecureBootCertificatesSecrets() (*SecureBootCertificatesSecrets, error) {
+	secrets := &SecureBootCertificatesSecrets{}
+	for store, value := range map[string]*[]byte{
+		SecureBootPKStore:  &secrets.PK,
+		SecureBootKEKStore: &secrets.KEK,
+		SecureBootDBStore:  &secrets.DB,
+	} {
+		content, err := ioutil.ReadFile(filepath.Join(GetSecureBootCertificatesSourcePath(store), SecureBootCertificatesKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the certificates of the Secure Boot %s store: %v", store, err)
+		}
+		*value = content
+	}
+	return secrets, nil
+}
+
+func readSecretKey(key string) (string, error) {
+	value, err := ioutil.ReadFile(filepath.Join(SMBiosSecretSourceDir, key))
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(value)), nil
+}
@@ -67,4 +67,79 @@ var _ = Describe("Secret", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	Context("with a SMBIOS secret", func() {
+
+		BeforeEach(func() {
+			var err error
+			SMBiosSecretSourceDir, err = ioutil.TempDir("", "smbios-secret")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(SMBiosSecretSourceDir)
+		})
+
+		It("Should read all SMBIOS fields", func() {
+			Expect(ioutil.WriteFile(filepath.Join(SMBiosSecretSourceDir, SMBiosManufacturerKey), []byte("KubeVirt\n"), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(SMBiosSecretSourceDir, SMBiosProductKey), []byte("None"), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(SMBiosSecretSourceDir, SMBiosOEMStringsKey), []byte("app:license-key\n\nother:value\n"), 0644)).To(Succeed())
+
+			smbios, err := ReadSMBiosSecret()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(smbios.Manufacturer).To(Equal("KubeVirt"))
+			Expect(smbios.Product).To(Equal("None"))
+			Expect(smbios.OEMStrings).To(Equal([]string{"app:license-key", "other:value"}))
+		})
+
+		It("Should leave fields empty for missing keys", func() {
+			Expect(ioutil.WriteFile(filepath.Join(SMBiosSecretSourceDir, SMBiosOEMStringsKey), []byte("app:license-key"), 0644)).To(Succeed())
+
+			smbios, err := ReadSMBiosSecret()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(smbios.Manufacturer).To(BeEmpty())
+			Expect(smbios.Product).To(BeEmpty())
+			Expect(smbios.OEMStrings).To(Equal([]string{"app:license-key"}))
+		})
+
+		It("Should fail if the secret is not mounted", func() {
+			Expect(os.RemoveAll(SMBiosSecretSourceDir)).To(Succeed())
+
+			_, err := ReadSMBiosSecret()
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+
+	Context("with Secure Boot certificates secrets", func() {
+
+		BeforeEach(func() {
+			var err error
+			SecureBootCertificatesSourceDir, err = ioutil.TempDir("", "secure-boot-certificates")
+			Expect(err).NotTo(HaveOccurred())
+			for _, store := range []string{SecureBootPKStore, SecureBootKEKStore, SecureBootDBStore} {
+				Expect(os.MkdirAll(GetSecureBootCertificatesSourcePath(store), 0755)).To(Succeed())
+				Expect(ioutil.WriteFile(filepath.Join(GetSecureBootCertificatesSourcePath(store), SecureBootCertificatesKey), []byte(store+"-certificates"), 0644)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(SecureBootCertificatesSourceDir)
+		})
+
+		It("Should read the certificates of all stores", func() {
+			secrets, err := ReadSecureBootCertificatesSecrets()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(secrets.PK)).To(Equal("pk-certificates"))
+			Expect(string(secrets.KEK)).To(Equal("kek-certificates"))
+			Expect(string(secrets.DB)).To(Equal("db-certificates"))
+		})
+
+		It("Should fail if the secret of a store is missing", func() {
+			Expect(os.RemoveAll(GetSecureBootCertificatesSourcePath(SecureBootKEKStore))).To(Succeed())
+
+			_, err := ReadSecureBootCertificatesSecrets()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("kek store"))
+		})
+	})
+
 })
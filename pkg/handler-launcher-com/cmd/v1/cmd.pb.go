@@ -334,6 +334,30 @@ func (m *GuestFilesystemsResponse) GetGuestFilesystemsResponse() string {
 	return ""
 }
 
+type MemoryDumpRequest struct {
+	Vmi      *VMI   `protobuf:"bytes,1,opt,name=vmi" json:"vmi,omitempty"`
+	DumpPath string `protobuf:"bytes,2,opt,name=dumpPath" json:"dumpPath,omitempty"`
+}
+
+func (m *MemoryDumpRequest) Reset()                    { *m = MemoryDumpRequest{} }
+func (m *MemoryDumpRequest) String() string            { return proto.CompactTextString(m) }
+func (*MemoryDumpRequest) ProtoMessage()               {}
+func (*MemoryDumpRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *MemoryDumpRequest) GetVmi() *VMI {
+	if m != nil {
+		return m.Vmi
+	}
+	return nil
+}
+
+func (m *MemoryDumpRequest) GetDumpPath() string {
+	if m != nil {
+		return m.DumpPath
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*VMI)(nil), "kubevirt.cmd.v1.VMI")
 	proto.RegisterType((*SMBios)(nil), "kubevirt.cmd.v1.SMBios")
@@ -347,6 +371,7 @@ func init() {
 	proto.RegisterType((*GuestInfoResponse)(nil), "kubevirt.cmd.v1.GuestInfoResponse")
 	proto.RegisterType((*GuestUserListResponse)(nil), "kubevirt.cmd.v1.GuestUserListResponse")
 	proto.RegisterType((*GuestFilesystemsResponse)(nil), "kubevirt.cmd.v1.GuestFilesystemsResponse")
+	proto.RegisterType((*MemoryDumpRequest)(nil), "kubevirt.cmd.v1.MemoryDumpRequest")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -363,6 +388,10 @@ type CmdClient interface {
 	SyncVirtualMachine(ctx context.Context, in *VMIRequest, opts ...grpc.CallOption) (*Response, error)
 	PauseVirtualMachine(ctx context.Context, in *VMIRequest, opts ...grpc.CallOption) (*Response, error)
 	UnpauseVirtualMachine(ctx context.Context, in *VMIRequest, opts ...grpc.CallOption) (*Response, error)
+	SoftRebootVirtualMachine(ctx context.Context, in *VMIRequest, opts ...grpc.CallOption) (*Response, error)
+	FreezeVirtualMachine(ctx context.Context, in *VMIRequest, opts ...grpc.CallOption) (*Response, error)
+	UnfreezeVirtualMachine(ctx context.Context, in *VMIRequest, opts ...grpc.CallOption) (*Response, error)
+	VirtualMachineMemoryDump(ctx context.Context, in *MemoryDumpRequest, opts ...grpc.CallOption) (*Response, error)
 	ShutdownVirtualMachine(ctx context.Context, in *VMIRequest, opts ...grpc.CallOption) (*Response, error)
 	KillVirtualMachine(ctx context.Context, in *VMIRequest, opts ...grpc.CallOption) (*Response, error)
 	DeleteVirtualMachine(ctx context.Context, in *VMIRequest, opts ...grpc.CallOption) (*Response, error)
@@ -413,6 +442,42 @@ func (c *cmdClient) UnpauseVirtualMachine(ctx context.Context, in *VMIRequest, o
 	return out, nil
 }
 
+func (c *cmdClient) SoftRebootVirtualMachine(ctx context.Context, in *VMIRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := grpc.Invoke(ctx, "/kubevirt.cmd.v1.Cmd/SoftRebootVirtualMachine", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cmdClient) FreezeVirtualMachine(ctx context.Context, in *VMIRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := grpc.Invoke(ctx, "/kubevirt.cmd.v1.Cmd/FreezeVirtualMachine", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cmdClient) UnfreezeVirtualMachine(ctx context.Context, in *VMIRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := grpc.Invoke(ctx, "/kubevirt.cmd.v1.Cmd/UnfreezeVirtualMachine", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cmdClient) VirtualMachineMemoryDump(ctx context.Context, in *MemoryDumpRequest, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := grpc.Invoke(ctx, "/kubevirt.cmd.v1.Cmd/VirtualMachineMemoryDump", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *cmdClient) ShutdownVirtualMachine(ctx context.Context, in *VMIRequest, opts ...grpc.CallOption) (*Response, error) {
 	out := new(Response)
 	err := grpc.Invoke(ctx, "/kubevirt.cmd.v1.Cmd/ShutdownVirtualMachine", in, out, c.cc, opts...)
@@ -536,6 +601,10 @@ type CmdServer interface {
 	SyncVirtualMachine(context.Context, *VMIRequest) (*Response, error)
 	PauseVirtualMachine(context.Context, *VMIRequest) (*Response, error)
 	UnpauseVirtualMachine(context.Context, *VMIRequest) (*Response, error)
+	SoftRebootVirtualMachine(context.Context, *VMIRequest) (*Response, error)
+	FreezeVirtualMachine(context.Context, *VMIRequest) (*Response, error)
+	UnfreezeVirtualMachine(context.Context, *VMIRequest) (*Response, error)
+	VirtualMachineMemoryDump(context.Context, *MemoryDumpRequest) (*Response, error)
 	ShutdownVirtualMachine(context.Context, *VMIRequest) (*Response, error)
 	KillVirtualMachine(context.Context, *VMIRequest) (*Response, error)
 	DeleteVirtualMachine(context.Context, *VMIRequest) (*Response, error)
@@ -609,6 +678,78 @@ func _Cmd_UnpauseVirtualMachine_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Cmd_SoftRebootVirtualMachine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VMIRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CmdServer).SoftRebootVirtualMachine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kubevirt.cmd.v1.Cmd/SoftRebootVirtualMachine",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CmdServer).SoftRebootVirtualMachine(ctx, req.(*VMIRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cmd_FreezeVirtualMachine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VMIRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CmdServer).FreezeVirtualMachine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kubevirt.cmd.v1.Cmd/FreezeVirtualMachine",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CmdServer).FreezeVirtualMachine(ctx, req.(*VMIRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cmd_UnfreezeVirtualMachine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VMIRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CmdServer).UnfreezeVirtualMachine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kubevirt.cmd.v1.Cmd/UnfreezeVirtualMachine",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CmdServer).UnfreezeVirtualMachine(ctx, req.(*VMIRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cmd_VirtualMachineMemoryDump_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MemoryDumpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CmdServer).VirtualMachineMemoryDump(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kubevirt.cmd.v1.Cmd/VirtualMachineMemoryDump",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CmdServer).VirtualMachineMemoryDump(ctx, req.(*MemoryDumpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Cmd_ShutdownVirtualMachine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(VMIRequest)
 	if err := dec(in); err != nil {
@@ -859,6 +1000,22 @@ var _Cmd_serviceDesc = grpc.ServiceDesc{
 			MethodName: "UnpauseVirtualMachine",
 			Handler:    _Cmd_UnpauseVirtualMachine_Handler,
 		},
+		{
+			MethodName: "SoftRebootVirtualMachine",
+			Handler:    _Cmd_SoftRebootVirtualMachine_Handler,
+		},
+		{
+			MethodName: "FreezeVirtualMachine",
+			Handler:    _Cmd_FreezeVirtualMachine_Handler,
+		},
+		{
+			MethodName: "UnfreezeVirtualMachine",
+			Handler:    _Cmd_UnfreezeVirtualMachine_Handler,
+		},
+		{
+			MethodName: "VirtualMachineMemoryDump",
+			Handler:    _Cmd_VirtualMachineMemoryDump_Handler,
+		},
 		{
 			MethodName: "ShutdownVirtualMachine",
 			Handler:    _Cmd_ShutdownVirtualMachine_Handler,
@@ -919,52 +1076,56 @@ var _Cmd_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("pkg/handler-launcher-com/cmd/v1/cmd.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 738 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x96, 0x61, 0x4f, 0xd3, 0x40,
-	0x18, 0xc7, 0x37, 0x86, 0x30, 0x1e, 0x26, 0xc2, 0xc1, 0xb0, 0x62, 0x08, 0x78, 0x31, 0x44, 0x12,
-	0x19, 0x01, 0xf1, 0x8d, 0x2f, 0x8c, 0x19, 0xe8, 0x82, 0x38, 0x98, 0x1d, 0xcc, 0x68, 0x4c, 0xcc,
-	0xd1, 0x1e, 0xdd, 0x85, 0xf6, 0x6e, 0xf6, 0xae, 0xd3, 0xbd, 0xf7, 0x95, 0x89, 0x5f, 0xc0, 0x4f,
-	0xe3, 0x47, 0x33, 0xbd, 0x76, 0x83, 0xad, 0x9d, 0x8b, 0xd9, 0x5e, 0xd1, 0xe7, 0x9e, 0xe7, 0x7e,
-	0xff, 0xe7, 0x9e, 0x6b, 0xff, 0x0c, 0xb6, 0x5b, 0xd7, 0xce, 0x6e, 0x93, 0x70, 0xdb, 0xa5, 0xfe,
-	0x8e, 0x4b, 0x02, 0x6e, 0x35, 0xa9, 0xbf, 0x63, 0x09, 0x6f, 0xd7, 0xf2, 0xec, 0xdd, 0xf6, 0x5e,
-	0xf8, 0xa7, 0xd4, 0xf2, 0x85, 0x12, 0xe8, 0xde, 0x75, 0x70, 0x49, 0xdb, 0xcc, 0x57, 0xa5, 0x70,
-	0xad, 0xbd, 0x87, 0x37, 0x20, 0xd7, 0xa8, 0x1e, 0x23, 0x03, 0x66, 0xdb, 0x1e, 0x7b, 0x2b, 0x05,
-	0x37, 0xb2, 0x9b, 0xd9, 0x27, 0x05, 0xb3, 0x1b, 0xe2, 0x9f, 0x59, 0x98, 0xa9, 0x57, 0xcb, 0x4c,
-	0x48, 0x84, 0xa1, 0xe0, 0x11, 0x1e, 0x5c, 0x11, 0x4b, 0x05, 0x3e, 0xf5, 0x75, 0xe5, 0x9c, 0xd9,
-	0xb7, 0x16, 0x82, 0x5a, 0xbe, 0xb0, 0x03, 0x4b, 0x19, 0x53, 0x3a, 0xdd, 0x0d, 0xb5, 0x04, 0xf5,
-	0x25, 0x13, 0xdc, 0xc8, 0x45, 0x99, 0x38, 0x44, 0x8b, 0x90, 0x93, 0xd7, 0x81, 0x31, 0xad, 0x57,
-	0xc3, 0x47, 0xb4, 0x0a, 0x33, 0x57, 0xc4, 0x63, 0x6e, 0xc7, 0xb8, 0xa3, 0x17, 0xe3, 0x08, 0xff,
-	0xce, 0x42, 0xb1, 0xc1, 0x7c, 0x15, 0x10, 0xb7, 0x4a, 0xac, 0x26, 0xe3, 0xf4, 0xac, 0xa5, 0x98,
-	0xe0, 0x12, 0x9d, 0xc0, 0x4a, 0x7f, 0x22, 0xea, 0x59, 0xf7, 0x38, 0xbf, 0x7f, 0xbf, 0x34, 0x70,
-	0xee, 0x52, 0x94, 0x36, 0x53, 0x37, 0xa1, 0x03, 0x28, 0x56, 0xa9, 0x57, 0x26, 0xae, 0x2b, 0x04,
-	0xaf, 0x2b, 0xa2, 0x64, 0x8d, 0xfa, 0x4c, 0xd8, 0xfa, 0x48, 0x77, 0xcd, 0xf4, 0x24, 0x6e, 0x03,
-	0x34, 0xaa, 0xc7, 0x26, 0xfd, 0x1a, 0x50, 0xa9, 0xd0, 0x16, 0xe4, 0xda, 0x1e, 0x8b, 0xf5, 0x57,
-	0x12, 0xfa, 0x61, 0x65, 0x58, 0x80, 0x5e, 0xc1, 0xac, 0x88, 0xce, 0xa0, 0xe9, 0xf3, 0xfb, 0x5b,
-	0xc9, 0xda, 0xb4, 0x13, 0x9b, 0xdd, 0x6d, 0xf8, 0x1c, 0x16, 0xab, 0xcc, 0xf1, 0x49, 0x18, 0xfd,
-	0xaf, 0xba, 0xd1, 0xaf, 0x5e, 0xb8, 0xa1, 0x2e, 0x40, 0xe1, 0xb5, 0xd7, 0x52, 0x9d, 0x98, 0x88,
-	0x5f, 0x42, 0xde, 0xa4, 0xb2, 0x25, 0xb8, 0xa4, 0xe1, 0x2e, 0x19, 0x58, 0x16, 0x95, 0xd1, 0x7c,
-	0xf3, 0x66, 0x37, 0x0c, 0x33, 0x1e, 0x95, 0x92, 0x38, 0xb4, 0x7b, 0xfd, 0x71, 0x88, 0xbf, 0xc0,
-	0xc2, 0x91, 0xf0, 0x08, 0xe3, 0x3d, 0xca, 0x73, 0xc8, 0xfb, 0xf1, 0x73, 0xdc, 0xe8, 0x83, 0x44,
-	0xa3, 0xdd, 0x62, 0xb3, 0x57, 0x1a, 0xbe, 0x1b, 0xb6, 0x06, 0xc5, 0x0a, 0x71, 0x84, 0x39, 0x2c,
-	0x47, 0x02, 0xfa, 0x4e, 0xc6, 0x55, 0xd9, 0x84, 0x79, 0xfb, 0x86, 0x16, 0x4b, 0xdd, 0x5e, 0xc2,
-	0xdf, 0x61, 0xa9, 0x12, 0x4e, 0xe6, 0x98, 0x5f, 0x89, 0x71, 0xd5, 0x9e, 0xc2, 0x92, 0x33, 0xc8,
-	0x8a, 0x35, 0x93, 0x09, 0xfc, 0x23, 0x0b, 0x45, 0x2d, 0x7d, 0x21, 0xa9, 0xff, 0x8e, 0x49, 0x35,
-	0xae, 0xfc, 0x01, 0x14, 0x9d, 0x34, 0x5e, 0xdc, 0x42, 0x7a, 0x12, 0xff, 0xca, 0x82, 0xa1, 0xdb,
-	0x78, 0xc3, 0x5c, 0x2a, 0x3b, 0x52, 0x51, 0x6f, 0xec, 0xb1, 0xbf, 0x00, 0xc3, 0x19, 0x82, 0x8c,
-	0x9b, 0x19, 0x9a, 0xdf, 0xff, 0x03, 0x90, 0x3b, 0xf4, 0x6c, 0x74, 0x0a, 0xa8, 0xde, 0xe1, 0x56,
-	0xff, 0x57, 0x83, 0x1e, 0xa6, 0x7e, 0x04, 0xd1, 0xcb, 0xbd, 0x36, 0xbc, 0x37, 0x9c, 0x41, 0x67,
-	0xb0, 0x5c, 0x23, 0x81, 0xa4, 0x13, 0x03, 0xbe, 0x87, 0xe2, 0x05, 0x6f, 0x4d, 0x14, 0x69, 0xc2,
-	0x6a, 0xbd, 0x19, 0x28, 0x5b, 0x7c, 0xe3, 0x13, 0x63, 0x9e, 0x02, 0x3a, 0x61, 0xae, 0x3b, 0x31,
-	0x5e, 0x0d, 0x56, 0x8e, 0xa8, 0x4b, 0xd5, 0xe4, 0x4e, 0xfd, 0x01, 0x8a, 0x91, 0xf3, 0x0d, 0x22,
-	0x1f, 0x25, 0x76, 0x0d, 0x3a, 0xe4, 0xc8, 0x2b, 0x0f, 0x5f, 0xa1, 0xde, 0xa6, 0x73, 0xe2, 0x3b,
-	0x54, 0x8d, 0xd1, 0xe9, 0x47, 0x58, 0x3f, 0x24, 0xdc, 0xa2, 0x03, 0xd3, 0xec, 0x09, 0x8c, 0x81,
-	0x6e, 0xc0, 0x5a, 0x9d, 0xaa, 0x7e, 0xae, 0xfe, 0x2c, 0xcf, 0x99, 0x37, 0xce, 0x70, 0xab, 0x30,
-	0x57, 0xa1, 0x2a, 0xb2, 0x54, 0xb4, 0x9e, 0xa8, 0xbc, 0xfd, 0xcf, 0x61, 0x6d, 0x23, 0x91, 0xee,
-	0xf7, 0x7a, 0x7d, 0x57, 0x0b, 0x3d, 0x9c, 0x36, 0xd0, 0x51, 0xcc, 0xc7, 0x43, 0x98, 0x7d, 0xf6,
-	0x8e, 0x33, 0xa8, 0x0e, 0x85, 0x0a, 0x55, 0x3d, 0x2b, 0x1e, 0x85, 0xc5, 0x89, 0x74, 0xc2, 0xc5,
-	0x35, 0x34, 0x5f, 0xa1, 0xda, 0xf2, 0x46, 0xf6, 0xb9, 0x95, 0x0e, 0x4c, 0xd8, 0x65, 0x06, 0x7d,
-	0xd6, 0x23, 0xb8, 0x65, 0x5d, 0xa3, 0xd0, 0xdb, 0xe9, 0xe8, 0x14, 0xf3, 0xc3, 0x19, 0x54, 0x86,
-	0xe9, 0x1a, 0xe3, 0xce, 0x28, 0xe6, 0xbf, 0xee, 0xbc, 0x3c, 0xfd, 0x69, 0xaa, 0xbd, 0x77, 0x39,
-	0xa3, 0x7f, 0x2b, 0x3e, 0xfb, 0x1b, 0x00, 0x00, 0xff, 0xff, 0x5c, 0xa3, 0x1e, 0x96, 0x58, 0x0a,
-	0x00, 0x00,
+	// 805 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x96, 0xdf, 0x6e, 0xdb, 0x36,
+	0x14, 0xc6, 0xe3, 0x3a, 0x4b, 0xdd, 0x13, 0x37, 0x6b, 0xd8, 0xb8, 0xd3, 0x32, 0x14, 0xed, 0x88,
+	0x21, 0x58, 0x81, 0xd5, 0x41, 0xb2, 0xee, 0x66, 0x17, 0xc3, 0xe0, 0x66, 0x35, 0xb2, 0x4e, 0xad,
+	0x27, 0x3b, 0x2e, 0xf6, 0x07, 0x18, 0x18, 0x89, 0x96, 0x89, 0x88, 0xa4, 0x46, 0x52, 0xde, 0xbc,
+	0xeb, 0x5d, 0x0d, 0xd8, 0x03, 0x6c, 0x4f, 0x3b, 0x88, 0x92, 0x9d, 0xd8, 0x92, 0x67, 0x04, 0xd6,
+	0x55, 0x74, 0x78, 0xc8, 0xdf, 0x77, 0x78, 0x48, 0xe6, 0x33, 0x3c, 0x8b, 0xaf, 0xc2, 0xe3, 0x31,
+	0x11, 0x41, 0x44, 0xd5, 0xf3, 0x88, 0x24, 0xc2, 0x1f, 0x53, 0xf5, 0xdc, 0x97, 0xfc, 0xd8, 0xe7,
+	0xc1, 0xf1, 0xe4, 0x24, 0xfd, 0xd3, 0x8e, 0x95, 0x34, 0x12, 0xbd, 0x7f, 0x95, 0x5c, 0xd2, 0x09,
+	0x53, 0xa6, 0x9d, 0x8e, 0x4d, 0x4e, 0xf0, 0x13, 0xa8, 0x0f, 0xdd, 0x73, 0xe4, 0xc0, 0xdd, 0x09,
+	0x67, 0xdf, 0x6a, 0x29, 0x9c, 0xda, 0xd3, 0xda, 0xa7, 0x4d, 0x6f, 0x16, 0xe2, 0xbf, 0x6a, 0xb0,
+	0xd3, 0x77, 0x3b, 0x4c, 0x6a, 0x84, 0xa1, 0xc9, 0x89, 0x48, 0x46, 0xc4, 0x37, 0x89, 0xa2, 0xca,
+	0xce, 0xbc, 0xe7, 0x2d, 0x8c, 0xa5, 0xa0, 0x58, 0xc9, 0x20, 0xf1, 0x8d, 0x73, 0xc7, 0xa6, 0x67,
+	0xa1, 0x95, 0xa0, 0x4a, 0x33, 0x29, 0x9c, 0x7a, 0x96, 0xc9, 0x43, 0xf4, 0x00, 0xea, 0xfa, 0x2a,
+	0x71, 0xb6, 0xed, 0x68, 0xfa, 0x89, 0x1e, 0xc1, 0xce, 0x88, 0x70, 0x16, 0x4d, 0x9d, 0xf7, 0xec,
+	0x60, 0x1e, 0xe1, 0x7f, 0x6b, 0xd0, 0x1a, 0x32, 0x65, 0x12, 0x12, 0xb9, 0xc4, 0x1f, 0x33, 0x41,
+	0xdf, 0xc6, 0x86, 0x49, 0xa1, 0xd1, 0x6b, 0x38, 0x58, 0x4c, 0x64, 0x35, 0xdb, 0x1a, 0x77, 0x4f,
+	0x3f, 0x68, 0x2f, 0xed, 0xbb, 0x9d, 0xa5, 0xbd, 0xd2, 0x45, 0xe8, 0x05, 0xb4, 0x5c, 0xca, 0x3b,
+	0x24, 0x8a, 0xa4, 0x14, 0x7d, 0x43, 0x8c, 0xee, 0x51, 0xc5, 0x64, 0x60, 0xb7, 0x74, 0xdf, 0x2b,
+	0x4f, 0xe2, 0x09, 0xc0, 0xd0, 0x3d, 0xf7, 0xe8, 0xaf, 0x09, 0xd5, 0x06, 0x1d, 0x41, 0x7d, 0xc2,
+	0x59, 0xae, 0x7f, 0x50, 0xd0, 0x4f, 0x67, 0xa6, 0x13, 0xd0, 0xd7, 0x70, 0x57, 0x66, 0x7b, 0xb0,
+	0xf4, 0xdd, 0xd3, 0xa3, 0xe2, 0xdc, 0xb2, 0x1d, 0x7b, 0xb3, 0x65, 0x78, 0x00, 0x0f, 0x5c, 0x16,
+	0x2a, 0x92, 0x46, 0xb7, 0x55, 0x77, 0x16, 0xd5, 0x9b, 0xd7, 0xd4, 0x3d, 0x68, 0x7e, 0xc3, 0x63,
+	0x33, 0xcd, 0x89, 0xf8, 0x2b, 0x68, 0x78, 0x54, 0xc7, 0x52, 0x68, 0x9a, 0xae, 0xd2, 0x89, 0xef,
+	0x53, 0x9d, 0xf5, 0xb7, 0xe1, 0xcd, 0xc2, 0x34, 0xc3, 0xa9, 0xd6, 0x24, 0xa4, 0xb3, 0xe3, 0xcf,
+	0x43, 0xfc, 0x0b, 0xec, 0x9d, 0x49, 0x4e, 0x98, 0x98, 0x53, 0xbe, 0x80, 0x86, 0xca, 0xbf, 0xf3,
+	0x42, 0x3f, 0x2c, 0x14, 0x3a, 0x9b, 0xec, 0xcd, 0xa7, 0xa6, 0x77, 0x23, 0xb0, 0xa0, 0x5c, 0x21,
+	0x8f, 0xb0, 0x80, 0x87, 0x99, 0x80, 0x3d, 0x93, 0x4d, 0x55, 0x9e, 0xc2, 0x6e, 0x70, 0x4d, 0xcb,
+	0xa5, 0x6e, 0x0e, 0xe1, 0xdf, 0x61, 0xbf, 0x9b, 0x76, 0xe6, 0x5c, 0x8c, 0xe4, 0xa6, 0x6a, 0x9f,
+	0xc1, 0x7e, 0xb8, 0xcc, 0xca, 0x35, 0x8b, 0x09, 0xfc, 0x67, 0x0d, 0x5a, 0x56, 0xfa, 0x42, 0x53,
+	0xf5, 0x1d, 0xd3, 0x66, 0x53, 0xf9, 0x17, 0xd0, 0x0a, 0xcb, 0x78, 0x79, 0x09, 0xe5, 0x49, 0xfc,
+	0x77, 0x0d, 0x1c, 0x5b, 0xc6, 0x2b, 0x16, 0x51, 0x3d, 0xd5, 0x86, 0xf2, 0x8d, 0xdb, 0xfe, 0x25,
+	0x38, 0xe1, 0x0a, 0x64, 0x5e, 0xcc, 0xca, 0x3c, 0x7e, 0x07, 0xfb, 0x2e, 0xe5, 0x52, 0x4d, 0xcf,
+	0x12, 0x1e, 0xdf, 0xf6, 0x21, 0x1c, 0x42, 0x23, 0x48, 0x78, 0xdc, 0x23, 0x66, 0x9c, 0x0b, 0xcd,
+	0xe3, 0xd3, 0x7f, 0xee, 0x43, 0xfd, 0x25, 0x0f, 0xd0, 0x1b, 0x40, 0xfd, 0xa9, 0xf0, 0x17, 0x9f,
+	0x23, 0xfa, 0xa8, 0x14, 0x9a, 0xc9, 0x1f, 0xae, 0xde, 0x34, 0xde, 0x42, 0x6f, 0xe1, 0x61, 0x8f,
+	0x24, 0x9a, 0x56, 0x06, 0xfc, 0x1e, 0x5a, 0x17, 0x22, 0xae, 0x14, 0x39, 0x00, 0xa7, 0x2f, 0x47,
+	0xc6, 0xa3, 0x97, 0x52, 0x9a, 0xca, 0xa8, 0x3d, 0x38, 0x78, 0xa5, 0x28, 0xfd, 0xa3, 0xba, 0x3a,
+	0x3d, 0x78, 0x74, 0x21, 0x46, 0xd5, 0x32, 0x7f, 0x02, 0x67, 0x91, 0x75, 0x7d, 0xbd, 0x10, 0x2e,
+	0x2c, 0x2c, 0xdc, 0xbd, 0xb5, 0x05, 0xf7, 0xc7, 0x89, 0x09, 0xe4, 0x6f, 0xa2, 0xb2, 0x82, 0xdf,
+	0x00, 0x7a, 0xcd, 0xa2, 0xa8, 0xca, 0x63, 0x3a, 0xa3, 0x11, 0x35, 0xd5, 0xb5, 0xf4, 0x1d, 0xb4,
+	0x32, 0xaf, 0x5a, 0x46, 0x7e, 0x5c, 0xec, 0xe7, 0x92, 0xa7, 0xad, 0x7d, 0x4b, 0xe9, 0xdb, 0x9c,
+	0x2f, 0x1a, 0x10, 0x15, 0x52, 0xb3, 0x41, 0xa5, 0x3f, 0xc0, 0xe3, 0x97, 0x44, 0xf8, 0x74, 0xa9,
+	0x9b, 0x73, 0x81, 0x0d, 0xd0, 0x43, 0x38, 0xec, 0xd3, 0xa5, 0xc7, 0x64, 0xff, 0x91, 0x0e, 0x18,
+	0xdf, 0xa4, 0xb9, 0x2e, 0xdc, 0xeb, 0x52, 0x93, 0x99, 0x20, 0x7a, 0x5c, 0x98, 0x79, 0xd3, 0xce,
+	0x0f, 0x9f, 0x14, 0xd2, 0x8b, 0xee, 0x6c, 0xcf, 0x6a, 0x6f, 0x8e, 0xb3, 0x96, 0xb7, 0x8e, 0xf9,
+	0xc9, 0x0a, 0xe6, 0x82, 0x21, 0xe3, 0x2d, 0xd4, 0x87, 0x66, 0x97, 0x9a, 0xb9, 0x79, 0xae, 0xc3,
+	0x16, 0x9f, 0x5a, 0xc1, 0x77, 0x2d, 0xb4, 0xd1, 0xa5, 0xd6, 0xa4, 0xd6, 0xd6, 0x79, 0x54, 0x0e,
+	0x2c, 0x18, 0xdc, 0x16, 0xfa, 0xd9, 0xb6, 0xe0, 0x86, 0xd9, 0xac, 0x43, 0x3f, 0x2b, 0x47, 0x97,
+	0xd9, 0xd5, 0x16, 0xea, 0xc0, 0x76, 0x8f, 0x89, 0x70, 0x1d, 0xf3, 0xff, 0xce, 0xbc, 0xb3, 0xfd,
+	0xe3, 0x9d, 0xc9, 0xc9, 0xe5, 0x8e, 0xfd, 0x75, 0xff, 0xf9, 0x7f, 0x03, 0x00, 0xc8, 0x65, 0x21,
+	0xd8, 0x0a, 0x0c, 0x00, 0x00,
 }
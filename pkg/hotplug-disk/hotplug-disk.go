@@ -69,6 +69,27 @@ func GetFileSystemDiskTargetPathFromHostView(virtlauncherPodUID types.UID, volum
 	return diskFile, err
 }
 
+// memoryDumpDirName is the directory below the hotplug disks the claim of a
+// memory dump is mounted to. Volume names can't start with a dot, hence it
+// never collides with the directory of a hotplugged volume.
+const memoryDumpDirName = ".memory-dump"
+
+// MemoryDumpMarkerFileName is the file the memory dump target pod creates at the root of the claim, which allows finding
+// the mount of the claim below the target pod on the host.
+const MemoryDumpMarkerFileName = ".kubevirt-memory-dump"
+
+// GetMemoryDumpTargetPathFromHostView gets the directory the claim of a memory dump is mounted to in the target pod
+// (virt-launcher) on the host.
+func GetMemoryDumpTargetPathFromHostView(virtlauncherPodUID types.UID, create bool) (string, error) {
+	return GetFileSystemDiskTargetPathFromHostView(virtlauncherPodUID, memoryDumpDirName, create)
+}
+
+// GetMemoryDumpTargetPath gets the directory the claim of a memory dump is mounted to, as seen from the target pod
+// (virt-launcher).
+func GetMemoryDumpTargetPath() string {
+	return filepath.Join(mountBaseDir, memoryDumpDirName)
+}
+
 // SetLocalDirectory sets the base directory where disk images will be mounted when hotplugged. File system volumes will be in
 // a directory under this, that contains the volume name. block volumes will be in this directory as a block device.
 func SetLocalDirectory(dir string) error {
@@ -109,6 +109,22 @@ var _ = Describe("HotplugDisk", func() {
 		Expect(res).To(Equal(testPath))
 	})
 
+	It("GetMemoryDumpTargetPathFromHostView should create the memory dump directory", func() {
+		testUID := types.UID("abcd")
+		SetKubeletPodsDirectory(tempDir)
+		targetPodBasePath = func(podUID types.UID) string {
+			Expect(podUID).To(Equal(testUID))
+			return string(testUID)
+		}
+		res, err := GetMemoryDumpTargetPathFromHostView(testUID, true)
+		Expect(err).ToNot(HaveOccurred())
+		testPath := filepath.Join(tempDir, string(testUID), ".memory-dump")
+		exists, _ := diskutils.FileExists(testPath)
+		Expect(exists).To(BeTrue())
+		Expect(res).To(Equal(testPath))
+		Expect(GetMemoryDumpTargetPath()).To(Equal("/var/run/kubevirt/hotplug-disks/.memory-dump"))
+	})
+
 	It("GetFileSystemDiskTargetPathFromHostView should fail on invalid UID", func() {
 		testUID := types.UID("abcde")
 		SetKubeletPodsDirectory(tempDir)
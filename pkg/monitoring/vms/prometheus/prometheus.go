@@ -36,6 +36,7 @@ import (
 	"kubevirt.io/client-go/version"
 	"kubevirt.io/kubevirt/pkg/util/lookup"
 	cmdclient "kubevirt.io/kubevirt/pkg/virt-handler/cmd-client"
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux"
 	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/stats"
 )
 
@@ -544,6 +545,9 @@ func SetupCollector(virtCli kubecli.KubevirtClient, virtShareDir, nodeName strin
 		concCollector: NewConcurrentCollector(MaxRequestsInFlight),
 	}
 	prometheus.MustRegister(co)
+	if err := selinux.RegisterMetrics(prometheus.DefaultRegisterer, nodeName); err != nil {
+		log.Log.Reason(err).Error("failed to register the selinux context switch metrics")
+	}
 	return co
 }
 
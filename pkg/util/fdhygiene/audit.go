@@ -0,0 +1,146 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package fdhygiene
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"kubevirt.io/client-go/log"
+)
+
+// FD is an open file descriptor of a process, with the target of its link in
+// the proc fd directory, e.g. socket:[1234] or /var/run/kubevirt/foo.
+type FD struct {
+	Num    int
+	Target string
+}
+
+// FDTable lists the FDs of a process and their close-on-exec flag.
+type FDTable interface {
+	List() ([]FD, error)
+	IsCloseOnExec(fd int) (bool, error)
+	SetCloseOnExec(fd int) error
+}
+
+// procFDTable is the FDTable of the process described by a proc fd directory,
+// the flags can only be read and set for the calling process.
+type procFDTable struct {
+	fdDir string
+}
+
+// NewProcFDTable returns the FDTable of the calling process listed in fdDir,
+// usually ProcSelfFDDir.
+func NewProcFDTable(fdDir string) FDTable {
+	return &procFDTable{fdDir: fdDir}
+}
+
+func (t *procFDTable) List() ([]FD, error) {
+	fds, err := OpenFDs(t.fdDir)
+	if err != nil {
+		return nil, err
+	}
+	table := make([]FD, 0, len(fds))
+	for _, fd := range fds {
+		target, err := os.Readlink(filepath.Join(t.fdDir, strconv.Itoa(fd)))
+		if err != nil {
+			// closed since listed, e.g. the fd of the listed directory
+			continue
+		}
+		table = append(table, FD{Num: fd, Target: target})
+	}
+	return table, nil
+}
+
+func (t *procFDTable) IsCloseOnExec(fd int) (bool, error) {
+	flags, err := fcntl(uintptr(fd), unix.F_GETFD, 0)
+	if err != nil {
+		return false, err
+	}
+	return flags&unix.FD_CLOEXEC != 0, nil
+}
+
+func (t *procFDTable) SetCloseOnExec(fd int) error {
+	flags, err := fcntl(uintptr(fd), unix.F_GETFD, 0)
+	if err != nil {
+		return err
+	}
+	_, err = fcntl(uintptr(fd), unix.F_SETFD, flags|unix.FD_CLOEXEC)
+	return err
+}
+
+// IsMonitorFD matches the FDs a process keeps open to monitor others: the
+// sockets, e.g. the connections to the launchers, and the anonymous inodes,
+// e.g. the inotify watches and the epoll sets.
+func IsMonitorFD(fd FD) bool {
+	return strings.HasPrefix(fd.Target, "socket:") || strings.HasPrefix(fd.Target, "anon_inode:")
+}
+
+// AuditCloseOnExec flags the FDs of table matched by match close-on-exec, and
+// returns the ones which were not, each of which is logged and counted by the
+// kubevirt_fds_repaired_close_on_exec_total metric. FDs closed while audited
+// are skipped.
+func AuditCloseOnExec(table FDTable, match func(FD) bool) ([]FD, error) {
+	fds, err := table.List()
+	if err != nil {
+		return nil, err
+	}
+	var repaired []FD
+	for _, fd := range fds {
+		if fd.Num < MinFDToCloseOnExec || !match(fd) {
+			continue
+		}
+		closeOnExec, err := table.IsCloseOnExec(fd.Num)
+		if err == unix.EBADF {
+			continue
+		} else if err != nil {
+			return repaired, err
+		}
+		if closeOnExec {
+			continue
+		}
+		if err := table.SetCloseOnExec(fd.Num); err == unix.EBADF {
+			continue
+		} else if err != nil {
+			return repaired, err
+		}
+		log.Log.Warningf("fd %d (%s) would have leaked into the forked children, flagged it close-on-exec", fd.Num, fd.Target)
+		repaired = append(repaired, fd)
+	}
+	countRepairedFDs(len(repaired))
+	return repaired, nil
+}
+
+// RunCloseOnExecAudit audits the monitor FDs of the calling process right
+// away, and then every interval until stopCh is closed.
+func RunCloseOnExecAudit(interval time.Duration, stopCh <-chan struct{}) {
+	table := NewProcFDTable(ProcSelfFDDir)
+	wait.Until(func() {
+		if _, err := AuditCloseOnExec(table, IsMonitorFD); err != nil {
+			log.Log.Reason(err).Warning("failed to audit the close-on-exec flag of the open FDs")
+		}
+	}, interval, stopCh)
+}
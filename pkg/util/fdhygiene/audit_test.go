@@ -0,0 +1,167 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package fdhygiene
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/unix"
+)
+
+type fakeAuditFDEntry struct {
+	target      string
+	closeOnExec bool
+}
+
+type fakeAuditFDTable struct {
+	entries map[int]*fakeAuditFDEntry
+	listErr error
+}
+
+func (t *fakeAuditFDTable) List() ([]FD, error) {
+	if t.listErr != nil {
+		return nil, t.listErr
+	}
+	var fds []FD
+	for num, entry := range t.entries {
+		fds = append(fds, FD{Num: num, Target: entry.target})
+	}
+	return fds, nil
+}
+
+func (t *fakeAuditFDTable) IsCloseOnExec(fd int) (bool, error) {
+	entry, ok := t.entries[fd]
+	if !ok {
+		return false, unix.EBADF
+	}
+	return entry.closeOnExec, nil
+}
+
+func (t *fakeAuditFDTable) SetCloseOnExec(fd int) error {
+	entry, ok := t.entries[fd]
+	if !ok {
+		return unix.EBADF
+	}
+	entry.closeOnExec = true
+	return nil
+}
+
+var _ = Describe("Close-on-exec audit", func() {
+	var fdTable *fakeAuditFDTable
+
+	BeforeEach(func() {
+		fdTable = &fakeAuditFDTable{entries: map[int]*fakeAuditFDEntry{
+			0:  {target: "socket:[100]"},
+			7:  {target: "socket:[101]", closeOnExec: true},
+			8:  {target: "socket:[102]"},
+			9:  {target: "anon_inode:inotify"},
+			10: {target: "/var/run/kubevirt/container-disks/disk.img"},
+		}}
+	})
+
+	It("should repair the monitor FDs which are not close-on-exec", func() {
+		repaired, err := AuditCloseOnExec(fdTable, IsMonitorFD)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(repaired).To(ConsistOf(
+			FD{Num: 8, Target: "socket:[102]"},
+			FD{Num: 9, Target: "anon_inode:inotify"},
+		))
+		Expect(fdTable.entries[8].closeOnExec).To(BeTrue())
+		Expect(fdTable.entries[9].closeOnExec).To(BeTrue())
+	})
+
+	It("should leave the standard and the unmatched FDs alone", func() {
+		_, err := AuditCloseOnExec(fdTable, IsMonitorFD)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fdTable.entries[0].closeOnExec).To(BeFalse())
+		Expect(fdTable.entries[10].closeOnExec).To(BeFalse())
+	})
+
+	It("should repair nothing the second time", func() {
+		_, err := AuditCloseOnExec(fdTable, IsMonitorFD)
+		Expect(err).ToNot(HaveOccurred())
+		repaired, err := AuditCloseOnExec(fdTable, IsMonitorFD)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(repaired).To(BeEmpty())
+	})
+
+	It("should fail when the FDs can't be listed", func() {
+		fdTable.listErr = errors.New("no proc")
+		_, err := AuditCloseOnExec(fdTable, IsMonitorFD)
+		Expect(err).To(MatchError("no proc"))
+	})
+
+	It("should count the repaired FDs", func() {
+		registry := prometheus.NewRegistry()
+		Expect(RegisterMetrics(registry)).To(Succeed())
+		counterValue := func() float64 {
+			families, err := registry.Gather()
+			Expect(err).ToNot(HaveOccurred())
+			for _, family := range families {
+				if family.GetName() == "kubevirt_fds_repaired_close_on_exec_total" {
+					return family.GetMetric()[0].GetCounter().GetValue()
+				}
+			}
+			return 0
+		}
+
+		before := counterValue()
+		_, err := AuditCloseOnExec(fdTable, IsMonitorFD)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(counterValue() - before).To(Equal(float64(2)))
+	})
+
+	It("should repair a socket of the calling process", func() {
+		fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM, 0)
+		Expect(err).ToNot(HaveOccurred())
+		defer unix.Close(fds[0])
+		defer unix.Close(fds[1])
+
+		procTable := NewProcFDTable(ProcSelfFDDir)
+		closeOnExec, err := procTable.IsCloseOnExec(fds[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(closeOnExec).To(BeFalse())
+
+		repaired, err := AuditCloseOnExec(procTable, func(fd FD) bool {
+			return fd.Num == fds[0] && IsMonitorFD(fd)
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(repaired).To(HaveLen(1))
+		Expect(repaired[0].Num).To(Equal(fds[0]))
+
+		closeOnExec, err = procTable.IsCloseOnExec(fds[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(closeOnExec).To(BeTrue())
+	})
+
+	table.DescribeTable("should match the monitor FDs", func(target string, expected bool) {
+		Expect(IsMonitorFD(FD{Num: 3, Target: target})).To(Equal(expected))
+	},
+		table.Entry("a socket", "socket:[123]", true),
+		table.Entry("an inotify watch", "anon_inode:inotify", true),
+		table.Entry("an epoll set", "anon_inode:[eventpoll]", true),
+		table.Entry("a pipe", "pipe:[123]", false),
+		table.Entry("a file", "/var/log/messages", false),
+	)
+})
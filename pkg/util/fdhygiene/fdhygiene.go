@@ -0,0 +1,170 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+// Package fdhygiene keeps the file descriptors of a process from being
+// inherited by the children it forks, e.g. device nodes opened for passthrough
+// leaking into qemu.
+package fdhygiene
+
+import (
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+
+	"kubevirt.io/client-go/log"
+)
+
+const (
+	// MinFDToCloseOnExec is the first FD flagged close-on-exec, the children
+	// share the std{in|out|err} of the parent
+	MinFDToCloseOnExec = 3
+	// ProcSelfFDDir lists the FDs open by the calling process
+	ProcSelfFDDir = "/proc/self/fd"
+	// MaxFDToCloseOnExecEnv overrides the end of the fixed FD range probed
+	// when /proc can't be read
+	MaxFDToCloseOnExecEnv = "KUBEVIRT_MAX_FD_CLOEXEC"
+
+	// fallbackMaxFDToCloseOnExec ends the range if the limit of open files
+	// of the process can't be read
+	fallbackMaxFDToCloseOnExec = 256
+	// upperMaxFDToCloseOnExec bounds the range, it is the default limit of
+	// open files per process of the kernel (fs.nr_open)
+	upperMaxFDToCloseOnExec = 1048576
+)
+
+// fcntl is the syscall flagging the FDs close-on-exec
+var fcntl = unix.FcntlInt
+
+// getrlimit reads the limit of open files of the process
+var getrlimit = unix.Getrlimit
+
+// maxFDToCloseOnExec ends the fixed FD range probed when /proc can't be read
+var maxFDToCloseOnExec = maxFDToCloseOnExecFrom(os.Getenv(MaxFDToCloseOnExecEnv))
+
+// maxFDToCloseOnExecFrom parses the override of the fixed FD range end. An
+// empty or invalid value keeps the default, see defaultMaxFDToCloseOnExec.
+func maxFDToCloseOnExecFrom(value string) int {
+	if value == "" {
+		return defaultMaxFDToCloseOnExec()
+	}
+	max, err := strconv.Atoi(value)
+	if err != nil || max <= MinFDToCloseOnExec || max > upperMaxFDToCloseOnExec {
+		defaultMax := defaultMaxFDToCloseOnExec()
+		log.Log.Warningf("Ignoring %s=%q, it is not an integer in (%d, %d], closing the FDs up to %d on exec", MaxFDToCloseOnExecEnv, value, MinFDToCloseOnExec, upperMaxFDToCloseOnExec, defaultMax)
+		return defaultMax
+	}
+	return max
+}
+
+// defaultMaxFDToCloseOnExec ends the fixed FD range at the soft limit of open
+// files of the process, no FD can be opened past it.
+func defaultMaxFDToCloseOnExec() int {
+	var limit unix.Rlimit
+	if err := getrlimit(unix.RLIMIT_NOFILE, &limit); err != nil {
+		log.Log.Reason(err).Warningf("failed to read the limit of open files, closing the FDs up to %d on exec", fallbackMaxFDToCloseOnExec)
+		return fallbackMaxFDToCloseOnExec
+	}
+	if limit.Cur <= MinFDToCloseOnExec || limit.Cur > upperMaxFDToCloseOnExec {
+		return upperMaxFDToCloseOnExec
+	}
+	return int(limit.Cur)
+}
+
+// PreventLeakOntoChild flags all FDs of the process but std{in|out|err}
+// close-on-exec, and returns how many of them were inherited so far. It is
+// meant to be called right before forking.
+func PreventLeakOntoChild() int {
+	flagged, err := CloseOnExecFrom(ProcSelfFDDir)
+	if err != nil {
+		// /proc is not readable, fall back to probing the whole FD range.
+		flagged = closeOnExecFDRange()
+	}
+	countFlaggedFDs(flagged)
+	return flagged
+}
+
+// CloseOnExecFrom flags the FDs listed in the proc fd directory fdDir, from
+// MinFDToCloseOnExec on, close-on-exec. It returns the number of FDs which
+// were not flagged yet.
+func CloseOnExecFrom(fdDir string) (int, error) {
+	fds, err := OpenFDs(fdDir)
+	if err != nil {
+		return 0, err
+	}
+	flagged := 0
+	for _, fd := range fds {
+		if fd < MinFDToCloseOnExec {
+			continue
+		}
+		if _, wasInherited := closeOnExec(fd); wasInherited {
+			flagged++
+		}
+	}
+	return flagged, nil
+}
+
+// closeOnExecFDRange flags the open FDs of the fixed range close-on-exec.
+// Every FD of the range is probed, the FD table may be sparse, e.g. after a
+// dup2 onto a high FD.
+func closeOnExecFDRange() int {
+	flagged := 0
+	for fd := MinFDToCloseOnExec; fd < maxFDToCloseOnExec; fd++ {
+		if _, wasInherited := closeOnExec(fd); wasInherited {
+			flagged++
+		}
+	}
+	return flagged
+}
+
+// closeOnExec flags fd close-on-exec, unless it already is. It returns
+// whether fd is open and whether it had to be flagged.
+func closeOnExec(fd int) (open bool, wasInherited bool) {
+	flags, err := fcntl(uintptr(fd), unix.F_GETFD, 0)
+	if err != nil {
+		return err != unix.EBADF, false
+	}
+	if flags&unix.FD_CLOEXEC != 0 {
+		return true, false
+	}
+	_, err = fcntl(uintptr(fd), unix.F_SETFD, flags|unix.FD_CLOEXEC)
+	return true, err == nil
+}
+
+// OpenFDs lists the file descriptors currently open by the process, as
+// reported by the given proc fd directory.
+func OpenFDs(fdDir string) ([]int, error) {
+	dir, err := os.Open(fdDir)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return nil, err
+	}
+	fds := make([]int, 0, len(names))
+	for _, name := range names {
+		fd, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+		fds = append(fds, fd)
+	}
+	return fds, nil
+}
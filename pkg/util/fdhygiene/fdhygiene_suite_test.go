@@ -0,0 +1,13 @@
+package fdhygiene_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestFdhygiene(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fdhygiene Suite")
+}
@@ -0,0 +1,316 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package fdhygiene
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"golang.org/x/sys/unix"
+)
+
+var _ = Describe("FD hygiene", func() {
+	var fds []int
+
+	BeforeEach(func() {
+		fds = nil
+	})
+
+	AfterEach(func() {
+		for _, fd := range fds {
+			syscall.Close(fd)
+		}
+	})
+
+	isCloseOnExec := func(fd int) bool {
+		flags, err := unix.FcntlInt(uintptr(fd), unix.F_GETFD, 0)
+		Expect(err).ToNot(HaveOccurred())
+		return flags&unix.FD_CLOEXEC != 0
+	}
+
+	It("should flag every open FD above stderr as close-on-exec", func() {
+		orgMaxFD := maxFDToCloseOnExec
+		defer func() { maxFDToCloseOnExec = orgMaxFD }()
+		maxFDToCloseOnExec = fallbackMaxFDToCloseOnExec
+		for i := 0; i < 300; i++ {
+			fd, err := syscall.Open("/dev/null", syscall.O_RDONLY, 0)
+			Expect(err).ToNot(HaveOccurred())
+			fds = append(fds, fd)
+		}
+		Expect(fds[len(fds)-1]).To(BeNumerically(">", maxFDToCloseOnExec))
+
+		var stdFlags []int
+		for fd := 0; fd < MinFDToCloseOnExec; fd++ {
+			flags, err := unix.FcntlInt(uintptr(fd), unix.F_GETFD, 0)
+			Expect(err).ToNot(HaveOccurred())
+			stdFlags = append(stdFlags, flags)
+		}
+
+		Expect(PreventLeakOntoChild()).To(BeNumerically(">=", len(fds)))
+
+		for _, fd := range fds {
+			Expect(isCloseOnExec(fd)).To(BeTrue(), "fd %d should be flagged close-on-exec", fd)
+		}
+		for fd := 0; fd < MinFDToCloseOnExec; fd++ {
+			flags, err := unix.FcntlInt(uintptr(fd), unix.F_GETFD, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(flags).To(Equal(stdFlags[fd]))
+		}
+	})
+
+	It("should list the open FDs from the proc fd directory", func() {
+		fd, err := syscall.Open("/dev/null", syscall.O_RDONLY, 0)
+		Expect(err).ToNot(HaveOccurred())
+		fds = append(fds, fd)
+
+		open, err := OpenFDs(ProcSelfFDDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(open).To(ContainElement(fd))
+	})
+
+	It("should fail listing FDs if the proc fd directory can't be read", func() {
+		_, err := OpenFDs("/nonexistent/fd")
+		Expect(err).To(HaveOccurred())
+	})
+
+	table.DescribeTable("should read the range end from the environment", func(value string, expected int) {
+		Expect(maxFDToCloseOnExecFrom(value)).To(Equal(expected))
+	},
+		table.Entry("defaulting if unset", "", defaultMaxFDToCloseOnExec()),
+		table.Entry("overridden", "1024", 1024),
+		table.Entry("overridden to the upper bound", "1048576", upperMaxFDToCloseOnExec),
+		table.Entry("ignoring a non integer", "lots", defaultMaxFDToCloseOnExec()),
+		table.Entry("ignoring a negative value", "-1", defaultMaxFDToCloseOnExec()),
+		table.Entry("ignoring a value not above stderr", "3", defaultMaxFDToCloseOnExec()),
+		table.Entry("ignoring a value above the upper bound", "1048577", defaultMaxFDToCloseOnExec()),
+	)
+
+	It("should default the range end to the limit of open files", func() {
+		var limit unix.Rlimit
+		Expect(unix.Getrlimit(unix.RLIMIT_NOFILE, &limit)).To(Succeed())
+		if limit.Cur > upperMaxFDToCloseOnExec {
+			Expect(defaultMaxFDToCloseOnExec()).To(Equal(upperMaxFDToCloseOnExec))
+		} else {
+			Expect(defaultMaxFDToCloseOnExec()).To(Equal(int(limit.Cur)))
+		}
+	})
+
+	table.DescribeTable("should bound the default range end", func(limit uint64, err error, expected int) {
+		defer func() { getrlimit = unix.Getrlimit }()
+		getrlimit = func(resource int, rlim *unix.Rlimit) error {
+			rlim.Cur = limit
+			return err
+		}
+		Expect(defaultMaxFDToCloseOnExec()).To(Equal(expected))
+	},
+		table.Entry("to the soft limit", uint64(4096), nil, 4096),
+		table.Entry("to the upper bound without limit", uint64(unix.RLIM_INFINITY), nil, upperMaxFDToCloseOnExec),
+		table.Entry("to the fallback if the limit can't be read", uint64(0), unix.EPERM, fallbackMaxFDToCloseOnExec),
+	)
+
+	Context("with a fake FD fdTable", func() {
+		var fdTable *fakeFDTable
+
+		var orgMaxFD int
+
+		BeforeEach(func() {
+			fdTable = newFakeFDTable()
+			fcntl = fdTable.fcntl
+			orgMaxFD = maxFDToCloseOnExec
+			maxFDToCloseOnExec = fallbackMaxFDToCloseOnExec
+		})
+
+		AfterEach(func() {
+			fcntl = unix.FcntlInt
+			maxFDToCloseOnExec = orgMaxFD
+		})
+
+		It("should only set the flag of the FDs missing it", func() {
+			fdTable.open(3, 0)
+			fdTable.open(4, unix.FD_CLOEXEC)
+
+			open, wasInherited := closeOnExec(3)
+			Expect(open).To(BeTrue())
+			Expect(wasInherited).To(BeTrue())
+			open, wasInherited = closeOnExec(4)
+			Expect(open).To(BeTrue())
+			Expect(wasInherited).To(BeFalse())
+			Expect(fdTable.flags[3]).To(Equal(unix.FD_CLOEXEC))
+			Expect(fdTable.flags[4]).To(Equal(unix.FD_CLOEXEC))
+			Expect(fdTable.setCalls).To(Equal(1))
+		})
+
+		It("should report closed FDs", func() {
+			open, wasInherited := closeOnExec(3)
+			Expect(open).To(BeFalse())
+			Expect(wasInherited).To(BeFalse())
+			Expect(fdTable.setCalls).To(BeZero())
+		})
+
+		Context("listed by a proc fd directory", func() {
+			var fdDir string
+
+			BeforeEach(func() {
+				var err error
+				fdDir, err = ioutil.TempDir("", "fd")
+				Expect(err).ToNot(HaveOccurred())
+				for _, name := range []string{"0", "1", "2", "3", "4", "7", "notanfd"} {
+					Expect(ioutil.WriteFile(filepath.Join(fdDir, name), nil, 0644)).To(Succeed())
+				}
+				for fd := 0; fd < 8; fd++ {
+					fdTable.open(fd, 0)
+				}
+				fdTable.flags[4] = unix.FD_CLOEXEC
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(fdDir)
+			})
+
+			It("should only flag the listed FDs above stderr", func() {
+				Expect(CloseOnExecFrom(fdDir)).To(Equal(2))
+				for _, fd := range []int{3, 4, 7} {
+					Expect(fdTable.flags[fd]).To(Equal(unix.FD_CLOEXEC), "fd %d should be flagged close-on-exec", fd)
+				}
+				for _, fd := range []int{0, 1, 2, 5, 6} {
+					Expect(fdTable.flags[fd]).To(BeZero(), "fd %d should not be flagged close-on-exec", fd)
+				}
+			})
+
+			It("should not count the FDs flagged before", func() {
+				Expect(CloseOnExecFrom(fdDir)).To(Equal(2))
+				Expect(CloseOnExecFrom(fdDir)).To(BeZero())
+			})
+
+			It("should fail if the directory can't be read", func() {
+				_, err := CloseOnExecFrom(filepath.Join(fdDir, "nonexistent"))
+				Expect(err).To(HaveOccurred())
+				Expect(fdTable.getCalls).To(BeZero())
+			})
+		})
+
+		It("should flag the FDs of a sparse fdTable", func() {
+			for _, fd := range []int{3, 40, 100, 160} {
+				fdTable.open(fd, 0)
+			}
+
+			Expect(closeOnExecFDRange()).To(Equal(4))
+			for _, fd := range []int{3, 40, 100, 160} {
+				Expect(fdTable.flags[fd]).To(Equal(unix.FD_CLOEXEC), "fd %d should be flagged close-on-exec", fd)
+			}
+		})
+
+		It("should probe up to the overridden range end", func() {
+			maxFDToCloseOnExec = 8
+			for _, fd := range []int{3, 7, 8} {
+				fdTable.open(fd, 0)
+			}
+
+			Expect(closeOnExecFDRange()).To(Equal(2))
+			Expect(fdTable.flags[8]).To(BeZero())
+		})
+
+		It("should flag the FDs past a long run of closed FDs", func() {
+			fdTable.open(10, 0)
+			fdTable.open(200, 0)
+
+			Expect(closeOnExecFDRange()).To(Equal(2))
+			Expect(fdTable.flags[200]).To(Equal(unix.FD_CLOEXEC))
+			Expect(fdTable.getCalls).To(Equal(maxFDToCloseOnExec - MinFDToCloseOnExec))
+			Expect(fdTable.setCalls).To(Equal(2))
+		})
+	})
+})
+
+// fakeFDTable emulates the fcntl calls against a set of open FDs.
+type fakeFDTable struct {
+	flags    map[int]int
+	getCalls int
+	setCalls int
+}
+
+func newFakeFDTable() *fakeFDTable {
+	return &fakeFDTable{flags: map[int]int{}}
+}
+
+func (t *fakeFDTable) open(fd int, flags int) {
+	t.flags[fd] = flags
+}
+
+func (t *fakeFDTable) fcntl(fd uintptr, cmd int, arg int) (int, error) {
+	flags, open := t.flags[int(fd)]
+	switch cmd {
+	case unix.F_GETFD:
+		t.getCalls++
+	case unix.F_SETFD:
+		t.setCalls++
+		flags = arg
+	}
+	if !open {
+		return -1, unix.EBADF
+	}
+	t.flags[int(fd)] = flags
+	return flags, nil
+}
+
+func BenchmarkCloseOnExecFDRange(b *testing.B) {
+	// go opens its own files close-on-exec, only a few inherited FDs miss the flag
+	newTable := func() *fakeFDTable {
+		fdTable := newFakeFDTable()
+		for fd := MinFDToCloseOnExec; fd < 16; fd++ {
+			fdTable.open(fd, unix.FD_CLOEXEC)
+		}
+		fdTable.open(5, 0)
+		return fdTable
+	}
+	reportSyscalls := func(b *testing.B, fdTable *fakeFDTable) {
+		b.ReportMetric(float64(fdTable.getCalls+fdTable.setCalls)/float64(b.N), "syscalls/op")
+	}
+	orgMaxFD := maxFDToCloseOnExec
+	maxFDToCloseOnExec = fallbackMaxFDToCloseOnExec
+	defer func() {
+		fcntl = unix.FcntlInt
+		maxFDToCloseOnExec = orgMaxFD
+	}()
+
+	b.Run("blind", func(b *testing.B) {
+		fdTable := newTable()
+		for i := 0; i < b.N; i++ {
+			for fd := MinFDToCloseOnExec; fd < maxFDToCloseOnExec; fd++ {
+				fdTable.fcntl(uintptr(fd), unix.F_SETFD, unix.FD_CLOEXEC)
+			}
+		}
+		reportSyscalls(b, fdTable)
+	})
+	b.Run("probing", func(b *testing.B) {
+		fdTable := newTable()
+		fcntl = fdTable.fcntl
+		for i := 0; i < b.N; i++ {
+			closeOnExecFDRange()
+		}
+		reportSyscalls(b, fdTable)
+	})
+}
@@ -0,0 +1,58 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+package fdhygiene
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var fdsFlaggedTotal = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "kubevirt_fds_flagged_close_on_exec_total",
+		Help: "Number of file descriptors which would have been inherited by forked children, until flagged close-on-exec.",
+	},
+)
+
+var fdsRepairedTotal = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "kubevirt_fds_repaired_close_on_exec_total",
+		Help: "Number of long-lived file descriptors found without close-on-exec by the periodic audit, and flagged.",
+	},
+)
+
+// RegisterMetrics registers the flagged and repaired FDs counters with
+// registerer.
+func RegisterMetrics(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{fdsFlaggedTotal, fdsRepairedTotal} {
+		if err := registerer.Register(collector); err != nil {
+			if _, alreadyRegistered := err.(prometheus.AlreadyRegisteredError); !alreadyRegistered {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func countFlaggedFDs(flagged int) {
+	fdsFlaggedTotal.Add(float64(flagged))
+}
+
+func countRepairedFDs(repaired int) {
+	fdsRepairedTotal.Add(float64(repaired))
+}
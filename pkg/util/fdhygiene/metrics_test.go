@@ -0,0 +1,66 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+package fdhygiene
+
+import (
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _ = Describe("FD hygiene metrics", func() {
+	var registry *prometheus.Registry
+
+	counterValue := func() float64 {
+		families, err := registry.Gather()
+		Expect(err).ToNot(HaveOccurred())
+		for _, family := range families {
+			if family.GetName() == "kubevirt_fds_flagged_close_on_exec_total" {
+				return family.GetMetric()[0].GetCounter().GetValue()
+			}
+		}
+		return 0
+	}
+
+	BeforeEach(func() {
+		registry = prometheus.NewRegistry()
+		Expect(RegisterMetrics(registry)).To(Succeed())
+	})
+
+	It("should count the flagged FDs", func() {
+		PreventLeakOntoChild()
+		flagged := counterValue()
+
+		fd, err := syscall.Open("/dev/null", syscall.O_RDONLY, 0)
+		Expect(err).ToNot(HaveOccurred())
+		defer syscall.Close(fd)
+
+		Expect(PreventLeakOntoChild()).To(Equal(1))
+		Expect(counterValue()).To(Equal(flagged + 1))
+
+		Expect(PreventLeakOntoChild()).To(BeZero())
+		Expect(counterValue()).To(Equal(flagged + 1))
+	})
+
+	It("should tolerate being registered twice", func() {
+		Expect(RegisterMetrics(registry)).To(Succeed())
+	})
+})
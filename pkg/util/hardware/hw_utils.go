@@ -21,16 +21,23 @@ package hardware
 
 import (
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/api/resource"
+
 	v1 "kubevirt.io/client-go/api/v1"
 )
 
 const (
 	CPUSET_PATH = "/sys/fs/cgroup/cpuset/cpuset.cpus"
 
+	NUMA_NODES_PATH = "/sys/devices/system/node"
+
 	PCI_ADDRESS_PATTERN = `^([\da-fA-F]{4}):([\da-fA-F]{2}):([\da-fA-F]{2})\.([0-7]{1})$`
 )
 
@@ -98,3 +105,87 @@ func ParsePciAddress(pciAddress string) ([]string, error) {
 	}
 	return res[1:], nil
 }
+
+// GetNUMANodeFreeHugepages returns the number of free hugepages of pageSizeKiB
+// on the host NUMA node, as reported below nodesDir.
+func GetNUMANodeFreeHugepages(nodesDir string, node uint32, pageSizeKiB int64) (int64, error) {
+	path := filepath.Join(nodesDir, fmt.Sprintf("node%d", node), "hugepages", fmt.Sprintf("hugepages-%dkB", pageSizeKiB), "free_hugepages")
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the free hugepages of NUMA node %d: %v", node, err)
+	}
+	free, err := strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse the free hugepages of NUMA node %d: %v", node, err)
+	}
+	return free, nil
+}
+
+// GetNUMANodeCPUs returns the CPUs of every host NUMA node reported below
+// nodesDir.
+func GetNUMANodeCPUs(nodesDir string) (map[uint32][]int, error) {
+	cpuLists, err := filepath.Glob(filepath.Join(nodesDir, "node*", "cpulist"))
+	if err != nil {
+		return nil, err
+	}
+	nodeCPUs := map[uint32][]int{}
+	for _, cpuList := range cpuLists {
+		node, err := strconv.ParseUint(strings.TrimPrefix(filepath.Base(filepath.Dir(cpuList)), "node"), 10, 32)
+		if err != nil {
+			continue
+		}
+		content, err := ioutil.ReadFile(cpuList)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the CPUs of NUMA node %d: %v", node, err)
+		}
+		line := strings.TrimSpace(string(content))
+		if line == "" {
+			// a node with memory only
+			nodeCPUs[uint32(node)] = nil
+			continue
+		}
+		cpus, err := ParseCPUSetLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the CPUs of NUMA node %d: %v", node, err)
+		}
+		nodeCPUs[uint32(node)] = cpus
+	}
+	if len(nodeCPUs) == 0 {
+		return nil, fmt.Errorf("no NUMA node found in %s", nodesDir)
+	}
+	return nodeCPUs, nil
+}
+
+// VerifyPerNUMANodeHugepages verifies that every host NUMA node referenced by
+// hugepages.PerNUMANode has enough free hugepages for all the guest NUMA nodes
+// placed on it.
+func VerifyPerNUMANodeHugepages(nodesDir string, hugepages *v1.Hugepages) error {
+	if hugepages == nil || len(hugepages.PerNUMANode) == 0 {
+		return nil
+	}
+	pageSize, err := resource.ParseQuantity(hugepages.PageSize)
+	if err != nil {
+		return fmt.Errorf("invalid hugepage size %q: %v", hugepages.PageSize, err)
+	}
+
+	requiredPages := map[uint32]int64{}
+	for _, node := range hugepages.PerNUMANode {
+		requiredPages[node.HostNode] += node.Memory.Value() / pageSize.Value()
+	}
+	hostNodes := make([]uint32, 0, len(requiredPages))
+	for node := range requiredPages {
+		hostNodes = append(hostNodes, node)
+	}
+	sort.Slice(hostNodes, func(i, j int) bool { return hostNodes[i] < hostNodes[j] })
+
+	for _, node := range hostNodes {
+		free, err := GetNUMANodeFreeHugepages(nodesDir, node, pageSize.Value()/1024)
+		if err != nil {
+			return err
+		}
+		if free < requiredPages[node] {
+			return fmt.Errorf("NUMA node %d has %d free hugepages of %s, %d are required", node, free, hugepages.PageSize, requiredPages[node])
+		}
+	}
+	return nil
+}
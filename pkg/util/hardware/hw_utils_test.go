@@ -20,8 +20,14 @@
 package hardware
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	v1 "kubevirt.io/client-go/api/v1"
 )
@@ -112,4 +118,115 @@ var _ = Describe("Hardware utils test", func() {
 			}
 		})
 	})
+
+	Context("per NUMA node hugepages", func() {
+		var nodesDir string
+
+		setFreeHugepages := func(node int, pageSizeKiB int, free string) {
+			dir := filepath.Join(nodesDir, fmt.Sprintf("node%d", node), "hugepages", fmt.Sprintf("hugepages-%dkB", pageSizeKiB))
+			Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "free_hugepages"), []byte(free+"\n"), 0644)).To(Succeed())
+		}
+
+		hugepages := func(nodes ...v1.NUMANodeHugepages) *v1.Hugepages {
+			return &v1.Hugepages{PageSize: "2Mi", PerNUMANode: nodes}
+		}
+
+		node := func(guestNode, hostNode uint32, memory string) v1.NUMANodeHugepages {
+			return v1.NUMANodeHugepages{GuestNode: guestNode, HostNode: hostNode, Memory: resource.MustParse(memory)}
+		}
+
+		BeforeEach(func() {
+			var err error
+			nodesDir, err = ioutil.TempDir("", "nodes")
+			Expect(err).ToNot(HaveOccurred())
+			setFreeHugepages(0, 2048, "512")
+			setFreeHugepages(1, 2048, "256")
+			setFreeHugepages(1, 1048576, "4")
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(nodesDir)).To(Succeed())
+		})
+
+		It("should read the free hugepages of a node", func() {
+			free, err := GetNUMANodeFreeHugepages(nodesDir, 1, 1048576)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(free).To(Equal(int64(4)))
+		})
+
+		It("should fail to read the free hugepages of an unknown node", func() {
+			_, err := GetNUMANodeFreeHugepages(nodesDir, 2, 2048)
+			Expect(err).To(MatchError(ContainSubstring("NUMA node 2")))
+		})
+
+		It("should accept a single node with enough hugepages", func() {
+			Expect(VerifyPerNUMANodeHugepages(nodesDir, hugepages(node(0, 0, "1Gi")))).To(Succeed())
+		})
+
+		It("should reject a single node without enough hugepages", func() {
+			err := VerifyPerNUMANodeHugepages(nodesDir, hugepages(node(0, 1, "1Gi")))
+			Expect(err).To(MatchError("NUMA node 1 has 256 free hugepages of 2Mi, 512 are required"))
+		})
+
+		It("should accept two nodes with enough hugepages", func() {
+			Expect(VerifyPerNUMANodeHugepages(nodesDir, hugepages(node(0, 0, "1Gi"), node(1, 1, "512Mi")))).To(Succeed())
+		})
+
+		It("should sum up the guest nodes placed on the same host node", func() {
+			err := VerifyPerNUMANodeHugepages(nodesDir, hugepages(node(0, 1, "256Mi"), node(1, 1, "512Mi")))
+			Expect(err).To(MatchError("NUMA node 1 has 256 free hugepages of 2Mi, 384 are required"))
+		})
+
+		It("should ignore hugepages without per NUMA node placement", func() {
+			Expect(VerifyPerNUMANodeHugepages(nodesDir, nil)).To(Succeed())
+			Expect(VerifyPerNUMANodeHugepages(nodesDir, hugepages())).To(Succeed())
+		})
+	})
+
+	Context("NUMA node CPUs", func() {
+		var nodesDir string
+
+		setCPUList := func(node string, cpuList string) {
+			dir := filepath.Join(nodesDir, node)
+			Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "cpulist"), []byte(cpuList+"\n"), 0644)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			var err error
+			nodesDir, err = ioutil.TempDir("", "nodes")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(nodesDir)).To(Succeed())
+		})
+
+		It("should read the CPUs of every node", func() {
+			setCPUList("node0", "0-3,8-11")
+			setCPUList("node1", "4-7,12-15")
+			setCPUList("node2", "")
+			Expect(os.MkdirAll(filepath.Join(nodesDir, "power"), 0755)).To(Succeed())
+
+			nodeCPUs, err := GetNUMANodeCPUs(nodesDir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nodeCPUs).To(Equal(map[uint32][]int{
+				0: {0, 1, 2, 3, 8, 9, 10, 11},
+				1: {4, 5, 6, 7, 12, 13, 14, 15},
+				2: nil,
+			}))
+		})
+
+		It("should fail on a malformed CPU list", func() {
+			setCPUList("node0", "0-a")
+			_, err := GetNUMANodeCPUs(nodesDir)
+			Expect(err).To(MatchError(ContainSubstring("failed to parse the CPUs of NUMA node 0")))
+		})
+
+		It("should fail without any node", func() {
+			_, err := GetNUMANodeCPUs(nodesDir)
+			Expect(err).To(MatchError(ContainSubstring("no NUMA node found")))
+		})
+	})
 })
@@ -0,0 +1,60 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+// Package rlimit encodes the resource limits virt-chroot applies to the
+// commands it executes, as passed by virt-handler.
+package rlimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Format encodes the limit of resource, e.g. unix.RLIMIT_NOFILE, as
+// <resource>=<soft>:<hard>.
+func Format(resource int, limit syscall.Rlimit) string {
+	return fmt.Sprintf("%d=%d:%d", resource, limit.Cur, limit.Max)
+}
+
+// Parse decodes a limit encoded by Format.
+func Parse(value string) (resource int, limit syscall.Rlimit, err error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return 0, limit, fmt.Errorf("malformed resource limit %q: expected <resource>=<soft>:<hard>", value)
+	}
+	if resource, err = strconv.Atoi(parts[0]); err != nil || resource < 0 {
+		return 0, limit, fmt.Errorf("malformed resource limit %q: invalid resource %q", value, parts[0])
+	}
+	limits := strings.SplitN(parts[1], ":", 2)
+	if len(limits) != 2 {
+		return 0, limit, fmt.Errorf("malformed resource limit %q: expected <resource>=<soft>:<hard>", value)
+	}
+	if limit.Cur, err = strconv.ParseUint(limits[0], 10, 64); err != nil {
+		return 0, limit, fmt.Errorf("malformed resource limit %q: invalid soft limit %q", value, limits[0])
+	}
+	if limit.Max, err = strconv.ParseUint(limits[1], 10, 64); err != nil {
+		return 0, limit, fmt.Errorf("malformed resource limit %q: invalid hard limit %q", value, limits[1])
+	}
+	if limit.Cur > limit.Max {
+		return 0, limit, fmt.Errorf("invalid resource limit %q: the soft limit exceeds the hard limit", value)
+	}
+	return resource, limit, nil
+}
@@ -0,0 +1,13 @@
+package rlimit_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestRlimit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Rlimit Suite")
+}
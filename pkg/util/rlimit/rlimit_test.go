@@ -0,0 +1,56 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package rlimit
+
+import (
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"golang.org/x/sys/unix"
+)
+
+var _ = Describe("Resource limits", func() {
+	table.DescribeTable("should round trip", func(resource int, limit syscall.Rlimit) {
+		parsedResource, parsedLimit, err := Parse(Format(resource, limit))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsedResource).To(Equal(resource))
+		Expect(parsedLimit).To(Equal(limit))
+	},
+		table.Entry("the number of open files", unix.RLIMIT_NOFILE, syscall.Rlimit{Cur: 1024, Max: 4096}),
+		table.Entry("an unlimited core size", unix.RLIMIT_CORE, syscall.Rlimit{Cur: unix.RLIM_INFINITY, Max: unix.RLIM_INFINITY}),
+	)
+
+	It("should encode the resource, the soft and the hard limit", func() {
+		Expect(Format(unix.RLIMIT_NOFILE, syscall.Rlimit{Cur: 1024, Max: 4096})).To(Equal("7=1024:4096"))
+	})
+
+	table.DescribeTable("should reject", func(value string) {
+		_, _, err := Parse(value)
+		Expect(err).To(HaveOccurred())
+	},
+		table.Entry("a missing resource", "1024:4096"),
+		table.Entry("a named resource", "nofile=1024:4096"),
+		table.Entry("a missing hard limit", "7=1024"),
+		table.Entry("a negative limit", "7=-1:4096"),
+		table.Entry("a soft limit above the hard limit", "7=4096:1024"),
+	)
+})
@@ -0,0 +1,235 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+// Package schedule evaluates the cron schedules VirtualMachines are started
+// and stopped on.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearchYears bounds the search for the next time a cron schedule fires,
+// it covers the leap days.
+const maxSearchYears = 5
+
+// bitSet holds the values a cron field matches.
+type bitSet uint64
+
+func (b bitSet) has(value int) bool {
+	return b&(1<<uint(value)) != 0
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var (
+	minuteField     = cronField{name: "minute", min: 0, max: 59}
+	hourField       = cronField{name: "hour", min: 0, max: 23}
+	dayOfMonthField = cronField{name: "day of month", min: 1, max: 31}
+	monthField      = cronField{name: "month", min: 1, max: 12}
+	// 7 is an alias of sunday
+	dayOfWeekField = cronField{name: "day of week", min: 0, max: 7}
+)
+
+// Cron is a parsed cron schedule in the five field format
+// "minute hour day-of-month month day-of-week". The fields are lists of
+// values, ranges and steps, e.g. "0-30/10,45". Like in crontab, a day matches
+// either field if both day fields are restricted.
+type Cron struct {
+	minute, hour, dayOfMonth, month, dayOfWeek bitSet
+	// dayOfMonthAny and dayOfWeekAny are set for the fields which start with a *
+	dayOfMonthAny, dayOfWeekAny bool
+}
+
+// ParseCron parses a cron schedule in the five field format.
+func ParseCron(expr string) (*Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron schedule %q has %d fields, expected 5: minute hour day-of-month month day-of-week", expr, len(fields))
+	}
+	c := &Cron{
+		dayOfMonthAny: strings.HasPrefix(fields[2], "*"),
+		dayOfWeekAny:  strings.HasPrefix(fields[4], "*"),
+	}
+	var err error
+	for _, f := range []struct {
+		bits  *bitSet
+		field cronField
+		expr  string
+	}{
+		{&c.minute, minuteField, fields[0]},
+		{&c.hour, hourField, fields[1]},
+		{&c.dayOfMonth, dayOfMonthField, fields[2]},
+		{&c.month, monthField, fields[3]},
+		{&c.dayOfWeek, dayOfWeekField, fields[4]},
+	} {
+		if *f.bits, err = parseField(f.expr, f.field); err != nil {
+			return nil, fmt.Errorf("invalid cron schedule %q: %v", expr, err)
+		}
+	}
+	if c.dayOfWeek.has(7) {
+		c.dayOfWeek |= 1
+	}
+	return c, nil
+}
+
+// parseField parses a comma separated list of values, ranges and steps.
+func parseField(expr string, field cronField) (bitSet, error) {
+	var bits bitSet
+	for _, item := range strings.Split(expr, ",") {
+		rangeExpr, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			var err error
+			rangeExpr = item[:i]
+			if step, err = strconv.Atoi(item[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q of the %s field", item[i+1:], field.name)
+			}
+		}
+
+		first, last := field.min, field.max
+		switch {
+		case rangeExpr == "*":
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+			var err error
+			if first, err = parseValue(bounds[0], field); err != nil {
+				return 0, err
+			}
+			if last, err = parseValue(bounds[1], field); err != nil {
+				return 0, err
+			}
+			if first > last {
+				return 0, fmt.Errorf("invalid range %q of the %s field", rangeExpr, field.name)
+			}
+		default:
+			var err error
+			if first, err = parseValue(rangeExpr, field); err != nil {
+				return 0, err
+			}
+			// a single value with a step runs up to the end of the field
+			if step == 1 {
+				last = first
+			}
+		}
+
+		for value := first; value <= last; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(expr string, field cronField) (int, error) {
+	value, err := strconv.Atoi(expr)
+	if err != nil || value < field.min || value > field.max {
+		return 0, fmt.Errorf("invalid value %q of the %s field, expected a number in [%d, %d]", expr, field.name, field.min, field.max)
+	}
+	return value, nil
+}
+
+func (c *Cron) matchesMonth(month time.Month) bool {
+	return c.month.has(int(month))
+}
+
+func (c *Cron) matchesDay(t time.Time) bool {
+	dayOfMonth := c.dayOfMonth.has(t.Day())
+	dayOfWeek := c.dayOfWeek.has(int(t.Weekday()))
+	if c.dayOfMonthAny || c.dayOfWeekAny {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+func (c *Cron) matchesHour(hour int) bool {
+	return c.hour.has(hour)
+}
+
+func (c *Cron) matchesMinute(minute int) bool {
+	return c.minute.has(minute)
+}
+
+// Next returns the first time after t the schedule fires at, in the location
+// of t. It returns false if the schedule doesn't fire within the next years,
+// e.g. for the 30th of February.
+func (c *Cron) Next(t time.Time) (time.Time, bool) {
+	return next(c, t)
+}
+
+// matcher is implemented by the schedules next can search the times of.
+type matcher interface {
+	matchesMonth(month time.Month) bool
+	matchesDay(t time.Time) bool
+	matchesHour(hour int) bool
+	matchesMinute(minute int) bool
+}
+
+// both matches the times both cron schedules fire at.
+type both [2]*Cron
+
+func (b both) matchesMonth(month time.Month) bool {
+	return b[0].matchesMonth(month) && b[1].matchesMonth(month)
+}
+
+func (b both) matchesDay(t time.Time) bool {
+	return b[0].matchesDay(t) && b[1].matchesDay(t)
+}
+
+func (b both) matchesHour(hour int) bool {
+	return b[0].matchesHour(hour) && b[1].matchesHour(hour)
+}
+
+func (b both) matchesMinute(minute int) bool {
+	return b[0].matchesMinute(minute) && b[1].matchesMinute(minute)
+}
+
+// next searches the first minute after t which m matches, skipping whole
+// months, days and hours which don't match.
+func next(m matcher, t time.Time) (time.Time, bool) {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + maxSearchYears
+
+	for t.Year() <= limit {
+		var skipTo time.Time
+		switch {
+		case !m.matchesMonth(t.Month()):
+			skipTo = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !m.matchesDay(t):
+			skipTo = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case !m.matchesHour(t.Hour()):
+			skipTo = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case !m.matchesMinute(t.Minute()):
+			skipTo = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+		// the wall clock may repeat across daylight saving time changes
+		if !skipTo.After(t) {
+			skipTo = t.Add(time.Minute)
+		}
+		t = skipTo
+	}
+	return time.Time{}, false
+}
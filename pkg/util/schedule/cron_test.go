@@ -0,0 +1,102 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package schedule_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/util/schedule"
+)
+
+var _ = Describe("Cron", func() {
+
+	// a monday
+	now := time.Date(2021, time.March, 1, 10, 30, 0, 0, time.UTC)
+
+	table.DescribeTable("should find the next time", func(expr string, from time.Time, expected time.Time) {
+		cron, err := schedule.ParseCron(expr)
+		Expect(err).ToNot(HaveOccurred())
+		next, ok := cron.Next(from)
+		Expect(ok).To(BeTrue())
+		Expect(next).To(Equal(expected))
+	},
+		table.Entry("every minute", "* * * * *", now, now.Add(time.Minute)),
+		table.Entry("within the seconds of a minute", "* * * * *", now.Add(42*time.Second), now.Add(time.Minute)),
+		table.Entry("later the same day", "0 18 * * *", now, time.Date(2021, time.March, 1, 18, 0, 0, 0, time.UTC)),
+		table.Entry("on the next day", "0 8 * * *", now, time.Date(2021, time.March, 2, 8, 0, 0, 0, time.UTC)),
+		table.Entry("with a step", "*/20 * * * *", now, time.Date(2021, time.March, 1, 10, 40, 0, 0, time.UTC)),
+		table.Entry("with a range and a step", "0 9-17/4 * * *", now, time.Date(2021, time.March, 1, 13, 0, 0, 0, time.UTC)),
+		table.Entry("with a list", "15,45 * * * *", now, time.Date(2021, time.March, 1, 10, 45, 0, 0, time.UTC)),
+		table.Entry("on weekdays", "0 8 * * 1-5", time.Date(2021, time.March, 5, 9, 0, 0, 0, time.UTC), time.Date(2021, time.March, 8, 8, 0, 0, 0, time.UTC)),
+		table.Entry("on sunday as 7", "0 0 * * 7", now, time.Date(2021, time.March, 7, 0, 0, 0, 0, time.UTC)),
+		table.Entry("in the next year", "0 0 1 1 *", now, time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		table.Entry("on a leap day", "0 0 29 2 *", now, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)),
+		table.Entry("on either restricted day field", "0 0 15 * 5", now, time.Date(2021, time.March, 5, 0, 0, 0, 0, time.UTC)),
+		table.Entry("on both day fields if one is a *", "0 0 */2 * 6", now, time.Date(2021, time.March, 13, 0, 0, 0, 0, time.UTC)),
+	)
+
+	It("should find the next time in the location of the given time", func() {
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		Expect(err).ToNot(HaveOccurred())
+		cron, err := schedule.ParseCron("0 8 * * *")
+		Expect(err).ToNot(HaveOccurred())
+
+		next, ok := cron.Next(now.In(berlin))
+		Expect(ok).To(BeTrue())
+		Expect(next.UTC()).To(Equal(time.Date(2021, time.March, 2, 7, 0, 0, 0, time.UTC)))
+	})
+
+	It("should skip the times which don't exist on daylight saving time changes", func() {
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		Expect(err).ToNot(HaveOccurred())
+		cron, err := schedule.ParseCron("30 2 * * *")
+		Expect(err).ToNot(HaveOccurred())
+
+		// the clocks move from 2:00 to 3:00 on the 28th of March 2021
+		next, ok := cron.Next(time.Date(2021, time.March, 28, 1, 0, 0, 0, berlin))
+		Expect(ok).To(BeTrue())
+		Expect(next).To(Equal(time.Date(2021, time.March, 29, 2, 30, 0, 0, berlin)))
+	})
+
+	It("should not find a time for schedules which never fire", func() {
+		cron, err := schedule.ParseCron("0 0 30 2 *")
+		Expect(err).ToNot(HaveOccurred())
+		_, ok := cron.Next(now)
+		Expect(ok).To(BeFalse())
+	})
+
+	table.DescribeTable("should reject", func(expr string, message string) {
+		_, err := schedule.ParseCron(expr)
+		Expect(err).To(MatchError(ContainSubstring(message)))
+	},
+		table.Entry("too few fields", "* * * *", "has 4 fields, expected 5"),
+		table.Entry("too many fields", "* * * * * *", "has 6 fields, expected 5"),
+		table.Entry("values out of range", "60 * * * *", `invalid value "60" of the minute field`),
+		table.Entry("a zero day of month", "0 0 0 * *", `invalid value "0" of the day of month field`),
+		table.Entry("a month out of range", "0 0 1 13 *", `invalid value "13" of the month field`),
+		table.Entry("inverted ranges", "0 17-9 * * *", `invalid range "17-9" of the hour field`),
+		table.Entry("zero steps", "*/0 * * * *", `invalid step "0" of the minute field`),
+		table.Entry("names", "0 0 * * mon", `invalid value "mon" of the day of week field`),
+	)
+})
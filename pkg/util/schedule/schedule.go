@@ -0,0 +1,142 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	v1 "kubevirt.io/client-go/api/v1"
+)
+
+// maxEvaluatedTransitions bounds the transitions Evaluate walks through at
+// once, e.g. after the controller was down for long
+const maxEvaluatedTransitions = 1000
+
+// Transition is a start or a stop of a VirtualMachine.
+type Transition struct {
+	Time  time.Time
+	Start bool
+}
+
+// RunStrategy returns the run strategy the VirtualMachine has after the transition.
+func (t *Transition) RunStrategy() v1.VirtualMachineRunStrategy {
+	if t.Start {
+		return v1.RunStrategyAlways
+	}
+	return v1.RunStrategyHalted
+}
+
+// Schedule is the parsed schedule of a VirtualMachine.
+type Schedule struct {
+	start    *Cron
+	stop     *Cron
+	location *time.Location
+}
+
+// Parse parses the start and stop cron schedules of spec and loads its time
+// zone. At least one of the schedules has to be set.
+func Parse(spec *v1.VirtualMachineSchedule) (*Schedule, error) {
+	if spec.Start == "" && spec.Stop == "" {
+		return nil, fmt.Errorf("at least one of the start and stop schedules is required")
+	}
+	s := &Schedule{location: time.UTC}
+	var err error
+	if spec.Start != "" {
+		if s.start, err = ParseCron(spec.Start); err != nil {
+			return nil, fmt.Errorf("invalid start schedule: %v", err)
+		}
+	}
+	if spec.Stop != "" {
+		if s.stop, err = ParseCron(spec.Stop); err != nil {
+			return nil, fmt.Errorf("invalid stop schedule: %v", err)
+		}
+	}
+	if spec.TimeZone != "" {
+		if s.location, err = time.LoadLocation(spec.TimeZone); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %v", spec.TimeZone, err)
+		}
+	}
+	return s, nil
+}
+
+// Conflict returns the first time after now both the start and the stop
+// schedules fire at, if they do within the next years.
+func (s *Schedule) Conflict(now time.Time) (time.Time, bool) {
+	if s.start == nil || s.stop == nil {
+		return time.Time{}, false
+	}
+	return next(both{s.start, s.stop}, now.In(s.location))
+}
+
+// Never returns the schedules which don't fire within the next years after
+// now, e.g. the ones for the 30th of February.
+func (s *Schedule) Never(now time.Time) []string {
+	var never []string
+	if s.start != nil {
+		if _, ok := s.start.Next(now.In(s.location)); !ok {
+			never = append(never, "start")
+		}
+	}
+	if s.stop != nil {
+		if _, ok := s.stop.Next(now.In(s.location)); !ok {
+			never = append(never, "stop")
+		}
+	}
+	return never
+}
+
+// Next returns the first start or stop after t. A stop wins over a start
+// at the same time.
+func (s *Schedule) Next(t time.Time) (*Transition, bool) {
+	t = t.In(s.location)
+	var transition *Transition
+	if s.stop != nil {
+		if stop, ok := s.stop.Next(t); ok {
+			transition = &Transition{Time: stop}
+		}
+	}
+	if s.start != nil {
+		if start, ok := s.start.Next(t); ok && (transition == nil || start.Before(transition.Time)) {
+			transition = &Transition{Time: start, Start: true}
+		}
+	}
+	return transition, transition != nil
+}
+
+// Evaluate walks the starts and stops after since, up to now, and returns
+// the latest of them, nil if there was none. It also returns the time the
+// schedule has to be evaluated again at, false if it doesn't fire anymore.
+// Once maxEvaluatedTransitions were walked, that time is the one of the
+// latest transition, so that the rest of them are walked right away.
+func (s *Schedule) Evaluate(since, now time.Time) (latest *Transition, next time.Time, ok bool) {
+	for i := 0; i < maxEvaluatedTransitions; i++ {
+		transition, found := s.Next(since)
+		if !found {
+			return latest, time.Time{}, false
+		}
+		if transition.Time.After(now) {
+			return latest, transition.Time, true
+		}
+		latest = transition
+		since = transition.Time
+	}
+	return latest, latest.Time, true
+}
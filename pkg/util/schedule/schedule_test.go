@@ -0,0 +1,158 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package schedule_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/kubevirt/pkg/util/schedule"
+)
+
+var _ = Describe("Schedule", func() {
+
+	// a monday
+	now := time.Date(2021, time.March, 1, 10, 30, 0, 0, time.UTC)
+
+	officeHours := &v1.VirtualMachineSchedule{
+		Start: "0 8 * * 1-5",
+		Stop:  "0 18 * * 1-5",
+	}
+
+	Context("parsing", func() {
+		It("should require a start or a stop schedule", func() {
+			_, err := schedule.Parse(&v1.VirtualMachineSchedule{TimeZone: "UTC"})
+			Expect(err).To(MatchError("at least one of the start and stop schedules is required"))
+		})
+
+		It("should reject invalid schedules", func() {
+			_, err := schedule.Parse(&v1.VirtualMachineSchedule{Start: "0 8 * *"})
+			Expect(err).To(MatchError(ContainSubstring("invalid start schedule")))
+			_, err = schedule.Parse(&v1.VirtualMachineSchedule{Stop: "0 25 * * *"})
+			Expect(err).To(MatchError(ContainSubstring("invalid stop schedule")))
+		})
+
+		It("should reject unknown time zones", func() {
+			_, err := schedule.Parse(&v1.VirtualMachineSchedule{Start: "0 8 * * *", TimeZone: "Mars/Olympus_Mons"})
+			Expect(err).To(MatchError(ContainSubstring(`invalid time zone "Mars/Olympus_Mons"`)))
+		})
+
+		It("should find schedules which fire at the same time", func() {
+			s, err := schedule.Parse(&v1.VirtualMachineSchedule{Start: "0 8 * * 1-5", Stop: "0 */4 * * 5"})
+			Expect(err).ToNot(HaveOccurred())
+			conflict, ok := s.Conflict(now)
+			Expect(ok).To(BeTrue())
+			Expect(conflict).To(Equal(time.Date(2021, time.March, 5, 8, 0, 0, 0, time.UTC)))
+		})
+
+		It("should not find conflicts of disjoint schedules", func() {
+			s, err := schedule.Parse(officeHours)
+			Expect(err).ToNot(HaveOccurred())
+			_, ok := s.Conflict(now)
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should find schedules which never fire", func() {
+			s, err := schedule.Parse(&v1.VirtualMachineSchedule{Start: "0 8 * * *", Stop: "0 0 31 4 *"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(s.Never(now)).To(Equal([]string{"stop"}))
+		})
+	})
+
+	Context("evaluating", func() {
+		It("should return the next transition if none is due", func() {
+			s, err := schedule.Parse(officeHours)
+			Expect(err).ToNot(HaveOccurred())
+
+			latest, next, ok := s.Evaluate(now, now)
+			Expect(latest).To(BeNil())
+			Expect(ok).To(BeTrue())
+			Expect(next).To(Equal(time.Date(2021, time.March, 1, 18, 0, 0, 0, time.UTC)))
+		})
+
+		It("should return the latest due transition", func() {
+			s, err := schedule.Parse(officeHours)
+			Expect(err).ToNot(HaveOccurred())
+
+			latest, next, ok := s.Evaluate(now.Add(-3*time.Hour), now)
+			Expect(latest).To(Equal(&schedule.Transition{Time: time.Date(2021, time.March, 1, 8, 0, 0, 0, time.UTC), Start: true}))
+			Expect(latest.RunStrategy()).To(Equal(v1.RunStrategyAlways))
+			Expect(ok).To(BeTrue())
+			Expect(next).To(Equal(time.Date(2021, time.March, 1, 18, 0, 0, 0, time.UTC)))
+		})
+
+		It("should only return the latest of several due transitions", func() {
+			s, err := schedule.Parse(officeHours)
+			Expect(err).ToNot(HaveOccurred())
+
+			// the controller missed the whole week end
+			friday := time.Date(2021, time.February, 26, 7, 0, 0, 0, time.UTC)
+			monday := time.Date(2021, time.March, 1, 7, 0, 0, 0, time.UTC)
+			latest, next, ok := s.Evaluate(friday, monday)
+			Expect(latest.Time).To(Equal(time.Date(2021, time.February, 26, 18, 0, 0, 0, time.UTC)))
+			Expect(latest.RunStrategy()).To(Equal(v1.RunStrategyHalted))
+			Expect(ok).To(BeTrue())
+			Expect(next).To(Equal(time.Date(2021, time.March, 1, 8, 0, 0, 0, time.UTC)))
+		})
+
+		It("should evaluate the schedules in their time zone", func() {
+			s, err := schedule.Parse(&v1.VirtualMachineSchedule{Start: "0 8 * * *", TimeZone: "America/New_York"})
+			Expect(err).ToNot(HaveOccurred())
+
+			latest, next, ok := s.Evaluate(now, now)
+			Expect(latest).To(BeNil())
+			Expect(ok).To(BeTrue())
+			// New York is 5 hours behind UTC in winter
+			Expect(next.UTC()).To(Equal(time.Date(2021, time.March, 1, 13, 0, 0, 0, time.UTC)))
+		})
+
+		It("should bound the transitions walked at once", func() {
+			s, err := schedule.Parse(&v1.VirtualMachineSchedule{Start: "* * * * *"})
+			Expect(err).ToNot(HaveOccurred())
+
+			since := now.Add(-7 * 24 * time.Hour)
+			latest, next, ok := s.Evaluate(since, now)
+			Expect(latest.Time).To(Equal(since.Add(1000 * time.Minute)))
+			Expect(ok).To(BeTrue())
+			Expect(next).To(Equal(latest.Time))
+		})
+
+		It("should prefer stopping over starting at the same time", func() {
+			s, err := schedule.Parse(&v1.VirtualMachineSchedule{Start: "0 8 * * *", Stop: "0 8 * * *"})
+			Expect(err).ToNot(HaveOccurred())
+
+			transition, ok := s.Next(now)
+			Expect(ok).To(BeTrue())
+			Expect(transition.Start).To(BeFalse())
+		})
+
+		It("should stop evaluating schedules which never fire", func() {
+			s, err := schedule.Parse(&v1.VirtualMachineSchedule{Stop: "0 0 30 2 *"})
+			Expect(err).ToNot(HaveOccurred())
+
+			latest, _, ok := s.Evaluate(now, now)
+			Expect(latest).To(BeNil())
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,68 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package types
+
+import (
+	"fmt"
+
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	v1 "kubevirt.io/client-go/api/v1"
+)
+
+// MemoryDumpOverhead is added to the memory of the guest when estimating the
+// size of its dump, for the ELF headers and notes of the dump and the
+// metadata of the filesystem of the claim.
+var MemoryDumpOverhead = resource.MustParse("100Mi")
+
+// GetMemoryDumpSize returns the space needed to hold a memory dump of the
+// guest of vmi.
+func GetMemoryDumpSize(vmi *v1.VirtualMachineInstance) *resource.Quantity {
+	size := guestMemory(vmi).DeepCopy()
+	size.Add(MemoryDumpOverhead)
+	return &size
+}
+
+// guestMemory returns the memory of the guest, like the converter does.
+func guestMemory(vmi *v1.VirtualMachineInstance) resource.Quantity {
+	if vmi.Spec.Domain.Memory != nil && vmi.Spec.Domain.Memory.Guest != nil {
+		return *vmi.Spec.Domain.Memory.Guest
+	}
+	if memory, ok := vmi.Spec.Domain.Resources.Limits[k8sv1.ResourceMemory]; ok {
+		return memory
+	}
+	return vmi.Spec.Domain.Resources.Requests[k8sv1.ResourceMemory]
+}
+
+// CheckClaimFitsMemoryDump returns an error if the claim is too small to hold
+// a memory dump of vmi. The capacity of a bound claim is used, the request of
+// a claim which is not bound yet.
+func CheckClaimFitsMemoryDump(pvc *k8sv1.PersistentVolumeClaim, vmi *v1.VirtualMachineInstance) error {
+	capacity, ok := pvc.Status.Capacity[k8sv1.ResourceStorage]
+	if !ok {
+		capacity = pvc.Spec.Resources.Requests[k8sv1.ResourceStorage]
+	}
+	required := GetMemoryDumpSize(vmi)
+	if capacity.Cmp(*required) < 0 {
+		return fmt.Errorf("claim %s has a capacity of %s, a memory dump of %s needs at least %s", pvc.Name, capacity.String(), vmi.Name, required.String())
+	}
+	return nil
+}
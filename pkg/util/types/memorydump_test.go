@@ -0,0 +1,84 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package types
+
+import (
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "kubevirt.io/client-go/api/v1"
+)
+
+var _ = Describe("Memory dump utils", func() {
+
+	newVMI := func(request, limit, guest string) *v1.VirtualMachineInstance {
+		vmi := v1.NewMinimalVMI("testvmi")
+		vmi.Spec.Domain.Resources.Requests = k8sv1.ResourceList{k8sv1.ResourceMemory: resource.MustParse(request)}
+		if limit != "" {
+			vmi.Spec.Domain.Resources.Limits = k8sv1.ResourceList{k8sv1.ResourceMemory: resource.MustParse(limit)}
+		}
+		if guest != "" {
+			guestMemory := resource.MustParse(guest)
+			vmi.Spec.Domain.Memory = &v1.Memory{Guest: &guestMemory}
+		}
+		return vmi
+	}
+
+	newClaim := func(request, capacity string) *k8sv1.PersistentVolumeClaim {
+		pvc := &k8sv1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "dump"},
+			Spec: k8sv1.PersistentVolumeClaimSpec{
+				Resources: k8sv1.ResourceRequirements{
+					Requests: k8sv1.ResourceList{k8sv1.ResourceStorage: resource.MustParse(request)},
+				},
+			},
+		}
+		if capacity != "" {
+			pvc.Status.Capacity = k8sv1.ResourceList{k8sv1.ResourceStorage: resource.MustParse(capacity)}
+		}
+		return pvc
+	}
+
+	table.DescribeTable("should add the overhead to the memory of the guest", func(vmi *v1.VirtualMachineInstance, expected string) {
+		Expect(GetMemoryDumpSize(vmi).Cmp(resource.MustParse(expected))).To(BeZero())
+	},
+		table.Entry("from the request", newVMI("1Gi", "", ""), "1124Mi"),
+		table.Entry("from the limit", newVMI("1Gi", "2Gi", ""), "2148Mi"),
+		table.Entry("from the guest memory", newVMI("1Gi", "2Gi", "512Mi"), "612Mi"),
+	)
+
+	table.DescribeTable("should check the claim fits the dump", func(pvc *k8sv1.PersistentVolumeClaim, fits bool) {
+		err := CheckClaimFitsMemoryDump(pvc, newVMI("1Gi", "", ""))
+		if fits {
+			Expect(err).ToNot(HaveOccurred())
+		} else {
+			Expect(err).To(MatchError(ContainSubstring("a memory dump of testvmi needs at least 1124Mi")))
+		}
+	},
+		table.Entry("with a large enough capacity", newClaim("1Gi", "2Gi"), true),
+		table.Entry("with an insufficient capacity", newClaim("2Gi", "1Gi"), false),
+		table.Entry("with a large enough request of an unbound claim", newClaim("2Gi", ""), true),
+		table.Entry("with an insufficient request of an unbound claim", newClaim("1Gi", ""), false),
+	)
+})
@@ -254,6 +254,15 @@ func (app *virtAPIApp) composeSubresources() {
 			Returns(http.StatusNotFound, httpStatusNotFoundMessage, "").
 			Returns(http.StatusBadRequest, httpStatusBadRequestMessage, ""))
 
+		subws.Route(subws.PUT(rest.ResourcePath(subresourcesvmiGVR)+rest.SubResourcePath("softreboot")).
+			To(subresourceApp.SoftRebootVMIRequestHandler).
+			Param(rest.NamespaceParam(subws)).Param(rest.NameParam(subws)).
+			Operation(version.Version+"SoftReboot").
+			Doc("Soft reboot a VirtualMachineInstance object, through the guest agent if it is connected, through ACPI otherwise.").
+			Returns(http.StatusOK, "OK", "").
+			Returns(http.StatusNotFound, httpStatusNotFoundMessage, "").
+			Returns(http.StatusBadRequest, httpStatusBadRequestMessage, ""))
+
 		subws.Route(subws.GET(rest.ResourcePath(subresourcesvmiGVR) + rest.SubResourcePath("console")).
 			To(subresourceApp.ConsoleRequestHandler).
 			Param(rest.NamespaceParam(subws)).Param(rest.NameParam(subws)).
@@ -339,6 +348,26 @@ func (app *virtAPIApp) composeSubresources() {
 			Returns(http.StatusOK, "OK", "").
 			Returns(http.StatusBadRequest, httpStatusBadRequestMessage, ""))
 
+		subws.Route(subws.PUT(rest.ResourcePath(subresourcesvmiGVR)+rest.SubResourcePath("exportdisk")).
+			To(subresourceApp.ExportDiskVMIRequestHandler).
+			Reads(v1.ExportDiskOptions{}).
+			Param(rest.NamespaceParam(subws)).Param(rest.NameParam(subws)).
+			Operation(version.Version+"vmi-exportdisk").
+			Doc("Export a volume of a running Virtual Machine Instance as a new DataVolume, from a snapshot taken while the guest filesystems are frozen").
+			Returns(http.StatusOK, "OK", "").
+			Returns(http.StatusNotFound, httpStatusNotFoundMessage, "").
+			Returns(http.StatusBadRequest, httpStatusBadRequestMessage, ""))
+
+		subws.Route(subws.PUT(rest.ResourcePath(subresourcesvmiGVR)+rest.SubResourcePath("memorydump")).
+			To(subresourceApp.MemoryDumpVMIRequestHandler).
+			Reads(v1.MemoryDumpOptions{}).
+			Param(rest.NamespaceParam(subws)).Param(rest.NameParam(subws)).
+			Operation(version.Version+"vmi-memorydump").
+			Doc("Dump the memory of a running Virtual Machine Instance to a PersistentVolumeClaim").
+			Returns(http.StatusAccepted, "Accepted", "").
+			Returns(http.StatusNotFound, httpStatusNotFoundMessage, "").
+			Returns(http.StatusBadRequest, httpStatusBadRequestMessage, ""))
+
 		subws.Route(subws.PUT(rest.ResourcePath(subresourcesvmGVR)+rest.SubResourcePath("addvolume")).
 			To(subresourceApp.VMAddVolumeRequestHandler).
 			Param(rest.NamespaceParam(subws)).Param(rest.NameParam(subws)).
@@ -385,6 +414,10 @@ func (app *virtAPIApp) composeSubresources() {
 						Name:       "virtualmachineinstances/unpause",
 						Namespaced: true,
 					},
+					{
+						Name:       "virtualmachineinstances/softreboot",
+						Namespaced: true,
+					},
 					{
 						Name:       "virtualmachines/start",
 						Namespaced: true,
@@ -425,6 +458,14 @@ func (app *virtAPIApp) composeSubresources() {
 						Name:       "virtualmachineinstances/removevolume",
 						Namespaced: true,
 					},
+					{
+						Name:       "virtualmachineinstances/exportdisk",
+						Namespaced: true,
+					},
+					{
+						Name:       "virtualmachineinstances/memorydump",
+						Namespaced: true,
+					},
 				}
 
 				response.WriteAsJson(list)
@@ -0,0 +1,414 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package rest
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	vsv1beta1 "github.com/kubernetes-csi/external-snapshotter/v2/pkg/apis/volumesnapshot/v1beta1"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/client-go/kubecli"
+	"kubevirt.io/client-go/log"
+	"kubevirt.io/kubevirt/pkg/controller"
+)
+
+const (
+	// the DataVolume takes the claim populated from the snapshot over,
+	// instead of populating a new claim from its source
+	populatedForPVCAnnotation = "cdi.kubevirt.io/storage.populatedFor"
+
+	exportSourceVMIAnnotation    = "kubevirt.io/export-source-vmi"
+	exportSourceVolumeAnnotation = "kubevirt.io/export-source-volume"
+)
+
+var (
+	// exportSnapshotTimeout bounds how long the guest stays frozen, waiting
+	// for the storage to take the snapshot of the exported volume
+	exportSnapshotTimeout      = 1 * time.Minute
+	exportSnapshotPollInterval = 1 * time.Second
+)
+
+// diskExportSteps are the steps of a disk export. They are kept behind an
+// interface for the orchestration of the steps and the rollback of failed
+// exports to be tested without a cluster.
+type diskExportSteps interface {
+	freeze(vmi *v1.VirtualMachineInstance) error
+	unfreeze(vmi *v1.VirtualMachineInstance) error
+	createSnapshot(vmi *v1.VirtualMachineInstance, claim *k8sv1.PersistentVolumeClaim, opts *v1.ExportDiskOptions) (*vsv1beta1.VolumeSnapshot, error)
+	waitForSnapshot(snapshot *vsv1beta1.VolumeSnapshot) error
+	deleteSnapshot(snapshot *vsv1beta1.VolumeSnapshot) error
+	createExportedClaim(claim *k8sv1.PersistentVolumeClaim, snapshot *vsv1beta1.VolumeSnapshot, opts *v1.ExportDiskOptions) (*k8sv1.PersistentVolumeClaim, error)
+	deleteExportedClaim(exportedClaim *k8sv1.PersistentVolumeClaim) error
+	createDataVolume(claim *k8sv1.PersistentVolumeClaim, exportedClaim *k8sv1.PersistentVolumeClaim, opts *v1.ExportDiskOptions) error
+}
+
+// exportDisk freezes the guest filesystems, snapshots the claim of the exported
+// volume and thaws the guest as soon as the storage took the snapshot. The
+// snapshot is then exported as a new DataVolume, populated from the snapshot.
+// Whichever step fails, the guest is thawed and the objects created by the
+// steps before the failure are deleted again.
+func exportDisk(steps diskExportSteps, vmi *v1.VirtualMachineInstance, claim *k8sv1.PersistentVolumeClaim, opts *v1.ExportDiskOptions) (err error) {
+	var rollbacks []func() error
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := len(rollbacks) - 1; i >= 0; i-- {
+			if rollbackErr := rollbacks[i](); rollbackErr != nil {
+				log.Log.Object(vmi).Reason(rollbackErr).Error("Failed to roll the disk export back")
+			}
+		}
+	}()
+
+	// a failed freeze may have frozen some of the filesystems already, thaw
+	// them in any case
+	frozen := true
+	defer func() {
+		if !frozen {
+			return
+		}
+		if thawErr := steps.unfreeze(vmi); thawErr != nil {
+			log.Log.Object(vmi).Reason(thawErr).Error("Failed to thaw the guest filesystems after a failed disk export")
+		}
+	}()
+	if err = steps.freeze(vmi); err != nil {
+		return fmt.Errorf("failed to freeze the guest filesystems: %v", err)
+	}
+
+	snapshot, err := steps.createSnapshot(vmi, claim, opts)
+	if err != nil {
+		return fmt.Errorf("failed to create the snapshot of claim %s: %v", claim.Name, err)
+	}
+	rollbacks = append(rollbacks, func() error { return steps.deleteSnapshot(snapshot) })
+
+	if err = steps.waitForSnapshot(snapshot); err != nil {
+		return fmt.Errorf("failed to snapshot claim %s: %v", claim.Name, err)
+	}
+
+	frozen = false
+	if err = steps.unfreeze(vmi); err != nil {
+		return fmt.Errorf("failed to thaw the guest filesystems: %v", err)
+	}
+
+	exportedClaim, err := steps.createExportedClaim(claim, snapshot, opts)
+	if err != nil {
+		return fmt.Errorf("failed to create claim %s from the snapshot: %v", opts.DataVolumeName, err)
+	}
+	rollbacks = append(rollbacks, func() error { return steps.deleteExportedClaim(exportedClaim) })
+
+	if err = steps.createDataVolume(claim, exportedClaim, opts); err != nil {
+		return fmt.Errorf("failed to create DataVolume %s: %v", opts.DataVolumeName, err)
+	}
+	return nil
+}
+
+// clusterDiskExportSteps freezes and thaws the guest through virt-handler and
+// creates the snapshot, the claim and the DataVolume of the export in the
+// namespace of the VMI.
+type clusterDiskExportSteps struct {
+	virtCli   kubecli.KubevirtClient
+	conn      kubecli.VirtHandlerConn
+	tlsConfig *tls.Config
+}
+
+func (s *clusterDiskExportSteps) freeze(vmi *v1.VirtualMachineInstance) error {
+	url, err := s.conn.FreezeURI(vmi)
+	if err != nil {
+		return err
+	}
+	return s.conn.Put(url, s.tlsConfig)
+}
+
+func (s *clusterDiskExportSteps) unfreeze(vmi *v1.VirtualMachineInstance) error {
+	url, err := s.conn.UnfreezeURI(vmi)
+	if err != nil {
+		return err
+	}
+	return s.conn.Put(url, s.tlsConfig)
+}
+
+func (s *clusterDiskExportSteps) createSnapshot(vmi *v1.VirtualMachineInstance, claim *k8sv1.PersistentVolumeClaim, opts *v1.ExportDiskOptions) (*vsv1beta1.VolumeSnapshot, error) {
+	snapshot := &vsv1beta1.VolumeSnapshot{
+		ObjectMeta: k8smetav1.ObjectMeta{
+			Name: exportSnapshotName(opts),
+			Annotations: map[string]string{
+				exportSourceVMIAnnotation:    vmi.Name,
+				exportSourceVolumeAnnotation: opts.VolumeName,
+			},
+		},
+		Spec: vsv1beta1.VolumeSnapshotSpec{
+			Source: vsv1beta1.VolumeSnapshotSource{
+				PersistentVolumeClaimName: &claim.Name,
+			},
+			VolumeSnapshotClassName: opts.VolumeSnapshotClassName,
+		},
+	}
+	return s.virtCli.KubernetesSnapshotClient().SnapshotV1beta1().VolumeSnapshots(claim.Namespace).
+		Create(context.Background(), snapshot, k8smetav1.CreateOptions{})
+}
+
+// waitForSnapshot waits for the snapshot to be taken, it does not need to be
+// ready to use for the guest to be thawed.
+func (s *clusterDiskExportSteps) waitForSnapshot(snapshot *vsv1beta1.VolumeSnapshot) error {
+	return wait.PollImmediate(exportSnapshotPollInterval, exportSnapshotTimeout, func() (bool, error) {
+		current, err := s.virtCli.KubernetesSnapshotClient().SnapshotV1beta1().VolumeSnapshots(snapshot.Namespace).
+			Get(context.Background(), snapshot.Name, k8smetav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if current.Status == nil {
+			return false, nil
+		}
+		if current.Status.Error != nil && current.Status.Error.Message != nil {
+			return false, fmt.Errorf("%s", *current.Status.Error.Message)
+		}
+		ready := current.Status.ReadyToUse != nil && *current.Status.ReadyToUse
+		return ready || current.Status.CreationTime != nil, nil
+	})
+}
+
+func (s *clusterDiskExportSteps) deleteSnapshot(snapshot *vsv1beta1.VolumeSnapshot) error {
+	return s.virtCli.KubernetesSnapshotClient().SnapshotV1beta1().VolumeSnapshots(snapshot.Namespace).
+		Delete(context.Background(), snapshot.Name, k8smetav1.DeleteOptions{})
+}
+
+// createExportedClaim creates the claim of the DataVolume from the snapshot
+// and hands the snapshot over to the claim, for it to be deleted along with
+// the claim.
+func (s *clusterDiskExportSteps) createExportedClaim(claim *k8sv1.PersistentVolumeClaim, snapshot *vsv1beta1.VolumeSnapshot, opts *v1.ExportDiskOptions) (*k8sv1.PersistentVolumeClaim, error) {
+	apiGroup := vsv1beta1.GroupName
+	exportedClaim := &k8sv1.PersistentVolumeClaim{
+		ObjectMeta: k8smetav1.ObjectMeta{
+			Name: opts.DataVolumeName,
+			Annotations: map[string]string{
+				populatedForPVCAnnotation: opts.DataVolumeName,
+			},
+		},
+		Spec: exportedClaimSpec(claim),
+	}
+	exportedClaim.Spec.DataSource = &k8sv1.TypedLocalObjectReference{
+		APIGroup: &apiGroup,
+		Kind:     "VolumeSnapshot",
+		Name:     snapshot.Name,
+	}
+
+	exportedClaim, err := s.virtCli.CoreV1().PersistentVolumeClaims(claim.Namespace).
+		Create(context.Background(), exportedClaim, k8smetav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot = snapshot.DeepCopy()
+	snapshot.OwnerReferences = append(snapshot.OwnerReferences, k8smetav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "PersistentVolumeClaim",
+		Name:       exportedClaim.Name,
+		UID:        exportedClaim.UID,
+	})
+	if _, err = s.virtCli.KubernetesSnapshotClient().SnapshotV1beta1().VolumeSnapshots(snapshot.Namespace).
+		Update(context.Background(), snapshot, k8smetav1.UpdateOptions{}); err != nil {
+		// the claim is not handed back to the rollback, delete it here
+		if deleteErr := s.deleteExportedClaim(exportedClaim); deleteErr != nil {
+			log.Log.Reason(deleteErr).Errorf("Failed to delete claim %s", exportedClaim.Name)
+		}
+		return nil, err
+	}
+	return exportedClaim, nil
+}
+
+func (s *clusterDiskExportSteps) deleteExportedClaim(exportedClaim *k8sv1.PersistentVolumeClaim) error {
+	return s.virtCli.CoreV1().PersistentVolumeClaims(exportedClaim.Namespace).
+		Delete(context.Background(), exportedClaim.Name, k8smetav1.DeleteOptions{})
+}
+
+func (s *clusterDiskExportSteps) createDataVolume(claim *k8sv1.PersistentVolumeClaim, exportedClaim *k8sv1.PersistentVolumeClaim, opts *v1.ExportDiskOptions) error {
+	pvcSpec := exportedClaimSpec(claim)
+	dataVolume := &cdiv1.DataVolume{
+		ObjectMeta: k8smetav1.ObjectMeta{
+			Name: opts.DataVolumeName,
+		},
+		Spec: cdiv1.DataVolumeSpec{
+			Source: cdiv1.DataVolumeSource{
+				PVC: &cdiv1.DataVolumeSourcePVC{
+					Namespace: claim.Namespace,
+					Name:      claim.Name,
+				},
+			},
+			PVC: &pvcSpec,
+		},
+	}
+	_, err := s.virtCli.CdiClient().CdiV1alpha1().DataVolumes(exportedClaim.Namespace).
+		Create(context.Background(), dataVolume, k8smetav1.CreateOptions{})
+	return err
+}
+
+// exportedClaimSpec requests storage like the exported claim for the
+// snapshot to fit into the new claim.
+func exportedClaimSpec(claim *k8sv1.PersistentVolumeClaim) k8sv1.PersistentVolumeClaimSpec {
+	return k8sv1.PersistentVolumeClaimSpec{
+		AccessModes:      claim.Spec.AccessModes,
+		Resources:        *claim.Spec.Resources.DeepCopy(),
+		StorageClassName: claim.Spec.StorageClassName,
+		VolumeMode:       claim.Spec.VolumeMode,
+	}
+}
+
+func exportSnapshotName(opts *v1.ExportDiskOptions) string {
+	return fmt.Sprintf("export-%s", opts.DataVolumeName)
+}
+
+// exportedClaimName returns the claim backing the volume of the VMI to export
+func exportedClaimName(vmi *v1.VirtualMachineInstance, volumeName string) (string, error) {
+	for _, volume := range vmi.Spec.Volumes {
+		if volume.Name != volumeName {
+			continue
+		}
+		if volume.PersistentVolumeClaim != nil {
+			return volume.PersistentVolumeClaim.ClaimName, nil
+		}
+		if volume.DataVolume != nil {
+			return volume.DataVolume.Name, nil
+		}
+		return "", fmt.Errorf("volume %s is neither backed by a PersistentVolumeClaim nor by a DataVolume", volumeName)
+	}
+	return "", fmt.Errorf("volume %s not found", volumeName)
+}
+
+// ExportDiskVMIRequestHandler exports a volume of a running VMI as a new
+// DataVolume, populated from a snapshot of the volume taken while the guest
+// filesystems are frozen.
+func (app *SubresourceAPIApp) ExportDiskVMIRequestHandler(request *restful.Request, response *restful.Response) {
+	if !app.clusterConfig.SnapshotEnabled() {
+		writeError(errors.NewBadRequest("Unable to export a disk because Snapshot feature gate is not enabled."), response)
+		return
+	}
+
+	opts := &v1.ExportDiskOptions{}
+	if request.Request.Body != nil {
+		defer request.Request.Body.Close()
+		err := yaml.NewYAMLOrJSONDecoder(request.Request.Body, 1024).Decode(opts)
+		switch err {
+		case io.EOF, nil:
+			break
+		default:
+			writeError(errors.NewBadRequest(fmt.Sprintf("Can not unmarshal Request body to struct, error: %s",
+				err)), response)
+			return
+		}
+	} else {
+		writeError(errors.NewBadRequest("Request with no body, export disk options are expected as the request body"),
+			response)
+		return
+	}
+
+	if opts.VolumeName == "" {
+		writeError(errors.NewBadRequest("ExportDiskOptions requires volumeName to be set"), response)
+		return
+	}
+	if opts.DataVolumeName == "" {
+		writeError(errors.NewBadRequest("ExportDiskOptions requires dataVolumeName to be set"), response)
+		return
+	}
+
+	validate := func(vmi *v1.VirtualMachineInstance) *errors.StatusError {
+		if vmi.Status.Phase != v1.Running {
+			return errors.NewConflict(v1.Resource("virtualmachineinstance"), vmi.Name, fmt.Errorf("VMI is not running"))
+		}
+		condManager := controller.NewVirtualMachineInstanceConditionManager()
+		if condManager.HasCondition(vmi, v1.VirtualMachineInstancePaused) {
+			return errors.NewConflict(v1.Resource("virtualmachineinstance"), vmi.Name, fmt.Errorf("VMI is paused"))
+		}
+		if !app.isGuestAgentConnected(vmi) {
+			return errors.NewConflict(v1.Resource("virtualmachineinstance"), vmi.Name, fmt.Errorf("the guest agent is not connected"))
+		}
+		if _, err := exportedClaimName(vmi, opts.VolumeName); err != nil {
+			return errors.NewBadRequest(err.Error())
+		}
+		return nil
+	}
+	getURL := func(vmi *v1.VirtualMachineInstance, conn kubecli.VirtHandlerConn) (string, error) {
+		return conn.FreezeURI(vmi)
+	}
+	vmi, _, conn, statusErr := app.prepareConnection(request, validate, getURL)
+	if statusErr != nil {
+		writeError(statusErr, response)
+		return
+	}
+
+	claimName, _ := exportedClaimName(vmi, opts.VolumeName)
+	claim, err := app.virtCli.CoreV1().PersistentVolumeClaims(vmi.Namespace).Get(context.Background(), claimName, k8smetav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			writeError(errors.NewNotFound(k8sv1.Resource("persistentvolumeclaim"), claimName), response)
+			return
+		}
+		writeError(errors.NewInternalError(err), response)
+		return
+	}
+
+	// refuse before freezing the guest, instead of failing on the conflict
+	// after taking the snapshot
+	if statusErr := app.checkExportTargetIsFree(vmi.Namespace, opts); statusErr != nil {
+		writeError(statusErr, response)
+		return
+	}
+
+	steps := &clusterDiskExportSteps{
+		virtCli:   app.virtCli,
+		conn:      conn,
+		tlsConfig: app.handlerTLSConfiguration,
+	}
+	if err := exportDisk(steps, vmi, claim, opts); err != nil {
+		log.Log.Object(vmi).Reason(err).Errorf("Failed to export volume %s", opts.VolumeName)
+		writeError(errors.NewInternalError(err), response)
+		return
+	}
+
+	response.WriteHeader(http.StatusAccepted)
+}
+
+func (app *SubresourceAPIApp) checkExportTargetIsFree(namespace string, opts *v1.ExportDiskOptions) *errors.StatusError {
+	_, err := app.virtCli.CdiClient().CdiV1alpha1().DataVolumes(namespace).Get(context.Background(), opts.DataVolumeName, k8smetav1.GetOptions{})
+	if err == nil {
+		return errors.NewAlreadyExists(cdiv1.SchemeGroupVersion.WithResource("datavolumes").GroupResource(), opts.DataVolumeName)
+	} else if !errors.IsNotFound(err) {
+		return errors.NewInternalError(err)
+	}
+	_, err = app.virtCli.CoreV1().PersistentVolumeClaims(namespace).Get(context.Background(), opts.DataVolumeName, k8smetav1.GetOptions{})
+	if err == nil {
+		return errors.NewAlreadyExists(k8sv1.Resource("persistentvolumeclaim"), opts.DataVolumeName)
+	} else if !errors.IsNotFound(err) {
+		return errors.NewInternalError(err)
+	}
+	return nil
+}
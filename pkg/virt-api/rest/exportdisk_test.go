@@ -0,0 +1,174 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package rest
+
+import (
+	"fmt"
+
+	vsv1beta1 "github.com/kubernetes-csi/external-snapshotter/v2/pkg/apis/volumesnapshot/v1beta1"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	k8sv1 "k8s.io/api/core/v1"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "kubevirt.io/client-go/api/v1"
+)
+
+type fakeDiskExportSteps struct {
+	calls    []string
+	failures map[string]error
+}
+
+func (f *fakeDiskExportSteps) step(name string) error {
+	f.calls = append(f.calls, name)
+	return f.failures[name]
+}
+
+func (f *fakeDiskExportSteps) freeze(_ *v1.VirtualMachineInstance) error {
+	return f.step("freeze")
+}
+
+func (f *fakeDiskExportSteps) unfreeze(_ *v1.VirtualMachineInstance) error {
+	return f.step("unfreeze")
+}
+
+func (f *fakeDiskExportSteps) createSnapshot(_ *v1.VirtualMachineInstance, _ *k8sv1.PersistentVolumeClaim, opts *v1.ExportDiskOptions) (*vsv1beta1.VolumeSnapshot, error) {
+	if err := f.step("createSnapshot"); err != nil {
+		return nil, err
+	}
+	return &vsv1beta1.VolumeSnapshot{ObjectMeta: k8smetav1.ObjectMeta{Name: exportSnapshotName(opts)}}, nil
+}
+
+func (f *fakeDiskExportSteps) waitForSnapshot(_ *vsv1beta1.VolumeSnapshot) error {
+	return f.step("waitForSnapshot")
+}
+
+func (f *fakeDiskExportSteps) deleteSnapshot(_ *vsv1beta1.VolumeSnapshot) error {
+	return f.step("deleteSnapshot")
+}
+
+func (f *fakeDiskExportSteps) createExportedClaim(_ *k8sv1.PersistentVolumeClaim, _ *vsv1beta1.VolumeSnapshot, opts *v1.ExportDiskOptions) (*k8sv1.PersistentVolumeClaim, error) {
+	if err := f.step("createExportedClaim"); err != nil {
+		return nil, err
+	}
+	return &k8sv1.PersistentVolumeClaim{ObjectMeta: k8smetav1.ObjectMeta{Name: opts.DataVolumeName}}, nil
+}
+
+func (f *fakeDiskExportSteps) deleteExportedClaim(_ *k8sv1.PersistentVolumeClaim) error {
+	return f.step("deleteExportedClaim")
+}
+
+func (f *fakeDiskExportSteps) createDataVolume(_ *k8sv1.PersistentVolumeClaim, _ *k8sv1.PersistentVolumeClaim, _ *v1.ExportDiskOptions) error {
+	return f.step("createDataVolume")
+}
+
+var _ = Describe("Disk export", func() {
+	var vmi *v1.VirtualMachineInstance
+	var claim *k8sv1.PersistentVolumeClaim
+	var opts *v1.ExportDiskOptions
+
+	BeforeEach(func() {
+		vmi = v1.NewMinimalVMI("testvmi")
+		claim = &k8sv1.PersistentVolumeClaim{ObjectMeta: k8smetav1.ObjectMeta{Name: "rootdisk-claim", Namespace: vmi.Namespace}}
+		opts = &v1.ExportDiskOptions{VolumeName: "rootdisk", DataVolumeName: "rootdisk-export"}
+	})
+
+	It("should thaw the guest as soon as the snapshot is taken", func() {
+		steps := &fakeDiskExportSteps{}
+
+		Expect(exportDisk(steps, vmi, claim, opts)).To(Succeed())
+		Expect(steps.calls).To(Equal([]string{
+			"freeze", "createSnapshot", "waitForSnapshot", "unfreeze", "createExportedClaim", "createDataVolume",
+		}))
+	})
+
+	table.DescribeTable("should thaw the guest and roll the export back", func(failingStep string, expectedCalls []string) {
+		steps := &fakeDiskExportSteps{failures: map[string]error{failingStep: fmt.Errorf("%s failed", failingStep)}}
+
+		err := exportDisk(steps, vmi, claim, opts)
+		Expect(err).To(MatchError(ContainSubstring("%s failed", failingStep)))
+		Expect(steps.calls).To(Equal(expectedCalls))
+	},
+		table.Entry("when freezing the guest fails", "freeze", []string{
+			"freeze", "unfreeze",
+		}),
+		table.Entry("when creating the snapshot fails", "createSnapshot", []string{
+			"freeze", "createSnapshot", "unfreeze",
+		}),
+		table.Entry("when taking the snapshot fails", "waitForSnapshot", []string{
+			"freeze", "createSnapshot", "waitForSnapshot", "unfreeze", "deleteSnapshot",
+		}),
+		table.Entry("when thawing the guest fails", "unfreeze", []string{
+			"freeze", "createSnapshot", "waitForSnapshot", "unfreeze", "deleteSnapshot",
+		}),
+		table.Entry("when creating the claim from the snapshot fails", "createExportedClaim", []string{
+			"freeze", "createSnapshot", "waitForSnapshot", "unfreeze", "createExportedClaim", "deleteSnapshot",
+		}),
+		table.Entry("when creating the DataVolume fails", "createDataVolume", []string{
+			"freeze", "createSnapshot", "waitForSnapshot", "unfreeze", "createExportedClaim", "createDataVolume", "deleteExportedClaim", "deleteSnapshot",
+		}),
+	)
+
+	It("should go on rolling the export back when a rollback fails", func() {
+		steps := &fakeDiskExportSteps{failures: map[string]error{
+			"createDataVolume":    fmt.Errorf("createDataVolume failed"),
+			"deleteExportedClaim": fmt.Errorf("deleteExportedClaim failed"),
+		}}
+
+		Expect(exportDisk(steps, vmi, claim, opts)).ToNot(Succeed())
+		Expect(steps.calls[len(steps.calls)-2:]).To(Equal([]string{"deleteExportedClaim", "deleteSnapshot"}))
+	})
+
+	table.DescribeTable("should find the claim backing the exported volume", func(volume v1.Volume, expectedClaim string) {
+		vmi.Spec.Volumes = []v1.Volume{volume}
+
+		claimName, err := exportedClaimName(vmi, "rootdisk")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(claimName).To(Equal(expectedClaim))
+	},
+		table.Entry("of a PersistentVolumeClaim volume", v1.Volume{
+			Name: "rootdisk",
+			VolumeSource: v1.VolumeSource{
+				PersistentVolumeClaim: &k8sv1.PersistentVolumeClaimVolumeSource{ClaimName: "rootdisk-claim"},
+			},
+		}, "rootdisk-claim"),
+		table.Entry("of a DataVolume volume", v1.Volume{
+			Name: "rootdisk",
+			VolumeSource: v1.VolumeSource{
+				DataVolume: &v1.DataVolumeSource{Name: "rootdisk-dv"},
+			},
+		}, "rootdisk-dv"),
+	)
+
+	It("should refuse to export a volume which is not backed by a claim", func() {
+		vmi.Spec.Volumes = []v1.Volume{{
+			Name: "rootdisk",
+			VolumeSource: v1.VolumeSource{
+				ContainerDisk: &v1.ContainerDiskSource{Image: "registry:5000/disk"},
+			},
+		}}
+
+		_, err := exportedClaimName(vmi, "rootdisk")
+		Expect(err).To(MatchError(ContainSubstring("neither backed by a PersistentVolumeClaim nor by a DataVolume")))
+		_, err = exportedClaimName(vmi, "missing")
+		Expect(err).To(MatchError("volume missing not found"))
+	})
+})
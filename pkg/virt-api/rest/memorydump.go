@@ -0,0 +1,172 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package rest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/client-go/log"
+	kubevirttypes "kubevirt.io/kubevirt/pkg/util/types"
+)
+
+// memoryDumpFileName returns the name of the file a dump of vmi requested at
+// now is written to, unique among the dumps of the VMI kept on the claim.
+func memoryDumpFileName(vmi *v1.VirtualMachineInstance, now time.Time) string {
+	return fmt.Sprintf("%s-%s.memory.dump", vmi.Name, now.UTC().Format("20060102-150405"))
+}
+
+// generateMemoryDumpRequestPatch replaces the last memory dump of the VMI by
+// a new pending one, failing if another dump was requested in between.
+func generateMemoryDumpRequestPatch(vmi *v1.VirtualMachineInstance, dump *v1.MemoryDumpStatus) (string, error) {
+	newDumpJSON, err := json.Marshal(dump)
+	if err != nil {
+		return "", err
+	}
+	if vmi.Status.MemoryDump == nil {
+		return fmt.Sprintf(`[{ "op": "add", "path": "/status/memoryDump", "value": %s}]`, string(newDumpJSON)), nil
+	}
+	oldDumpJSON, err := json.Marshal(vmi.Status.MemoryDump)
+	if err != nil {
+		return "", err
+	}
+	test := fmt.Sprintf(`{ "op": "test", "path": "/status/memoryDump", "value": %s}`, string(oldDumpJSON))
+	replace := fmt.Sprintf(`{ "op": "replace", "path": "/status/memoryDump", "value": %s}`, string(newDumpJSON))
+	return fmt.Sprintf("[%s, %s]", test, replace), nil
+}
+
+// claimUsedByVMI returns whether a volume of the VMI is backed by the claim.
+func claimUsedByVMI(vmi *v1.VirtualMachineInstance, claimName string) bool {
+	for _, volume := range vmi.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == claimName {
+			return true
+		}
+		if volume.DataVolume != nil && volume.DataVolume.Name == claimName {
+			return true
+		}
+	}
+	return false
+}
+
+// MemoryDumpVMIRequestHandler requests a dump of the memory of a running VMI
+// to a claim. The request is recorded as a pending dump in the status of the
+// VMI: virt-controller attaches the claim to the node of the VMI, and
+// virt-handler has virt-launcher write the dump to it.
+func (app *SubresourceAPIApp) MemoryDumpVMIRequestHandler(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter("name")
+	namespace := request.PathParameter("namespace")
+
+	if !app.clusterConfig.HotplugVolumesEnabled() {
+		writeError(errors.NewBadRequest("Unable to dump the memory because HotplugVolumes feature gate is not enabled."), response)
+		return
+	}
+
+	opts := &v1.MemoryDumpOptions{}
+	if request.Request.Body != nil {
+		defer request.Request.Body.Close()
+		err := yaml.NewYAMLOrJSONDecoder(request.Request.Body, 1024).Decode(opts)
+		switch err {
+		case io.EOF, nil:
+			break
+		default:
+			writeError(errors.NewBadRequest(fmt.Sprintf("Can not unmarshal Request body to struct, error: %s",
+				err)), response)
+			return
+		}
+	} else {
+		writeError(errors.NewBadRequest("Request with no body, memory dump options are expected as the request body"),
+			response)
+		return
+	}
+
+	if opts.ClaimName == "" {
+		writeError(errors.NewBadRequest("MemoryDumpOptions requires claimName to be set"), response)
+		return
+	}
+
+	vmi, statusErr := app.fetchVirtualMachineInstance(name, namespace)
+	if statusErr != nil {
+		writeError(statusErr, response)
+		return
+	}
+	if !vmi.IsRunning() {
+		writeError(errors.NewConflict(v1.Resource("virtualmachineinstance"), name, fmt.Errorf("VMI is not running")), response)
+		return
+	}
+	if dump := vmi.Status.MemoryDump; dump != nil && !dump.IsFinished() {
+		writeError(errors.NewConflict(v1.Resource("virtualmachineinstance"), name, fmt.Errorf("a memory dump to claim %s is already in progress", dump.ClaimName)), response)
+		return
+	}
+	if claimUsedByVMI(vmi, opts.ClaimName) {
+		writeError(errors.NewConflict(v1.Resource("virtualmachineinstance"), name, fmt.Errorf("claim %s backs a volume of the VMI", opts.ClaimName)), response)
+		return
+	}
+
+	pvc, exists, isBlock, err := kubevirttypes.IsPVCBlockFromClient(app.virtCli, namespace, opts.ClaimName)
+	if err != nil {
+		writeError(errors.NewInternalError(err), response)
+		return
+	}
+	if !exists {
+		writeError(errors.NewNotFound(k8sv1.Resource("persistentvolumeclaim"), opts.ClaimName), response)
+		return
+	}
+	if isBlock {
+		writeError(errors.NewBadRequest(fmt.Sprintf("claim %s must be in filesystem mode to hold a memory dump", opts.ClaimName)), response)
+		return
+	}
+	if err := kubevirttypes.CheckClaimFitsMemoryDump(pvc, vmi); err != nil {
+		writeError(errors.NewBadRequest(fmt.Sprintf("insufficient space to dump the memory: %v", err)), response)
+		return
+	}
+
+	now := k8smetav1.Now()
+	dump := &v1.MemoryDumpStatus{
+		ClaimName:      opts.ClaimName,
+		Phase:          v1.MemoryDumpPending,
+		FileName:       memoryDumpFileName(vmi, now.Time),
+		StartTimestamp: &now,
+	}
+	patch, err := generateMemoryDumpRequestPatch(vmi, dump)
+	if err != nil {
+		writeError(errors.NewInternalError(err), response)
+		return
+	}
+
+	log.Log.Object(vmi).V(4).Infof("Patching VMI: %s", patch)
+	_, err = app.virtCli.VirtualMachineInstance(vmi.Namespace).Patch(vmi.Name, types.JSONPatchType, []byte(patch))
+	if err != nil {
+		writeError(errors.NewInternalError(fmt.Errorf("unable to patch vmi during memory dump request: %v", err)), response)
+		return
+	}
+
+	response.WriteHeader(http.StatusAccepted)
+}
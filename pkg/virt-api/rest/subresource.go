@@ -30,6 +30,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/emicklei/go-restful"
 	v12 "k8s.io/api/core/v1"
@@ -39,6 +40,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/util/yaml"
 
 	"kubevirt.io/kubevirt/pkg/util/status"
@@ -753,6 +755,24 @@ func (app *SubresourceAPIApp) UnpauseVMIRequestHandler(request *restful.Request,
 
 }
 
+func (app *SubresourceAPIApp) SoftRebootVMIRequestHandler(request *restful.Request, response *restful.Response) {
+
+	validate := func(vmi *v1.VirtualMachineInstance) *errors.StatusError {
+		if vmi.Status.Phase != v1.Running {
+			return errors.NewConflict(v1.Resource("virtualmachineinstance"), vmi.Name, fmt.Errorf("VMI is not running"))
+		}
+		condManager := controller.NewVirtualMachineInstanceConditionManager()
+		if condManager.HasCondition(vmi, v1.VirtualMachineInstancePaused) {
+			return errors.NewConflict(v1.Resource("virtualmachineinstance"), vmi.Name, fmt.Errorf("VMI is paused"))
+		}
+		return nil
+	}
+	getURL := func(vmi *v1.VirtualMachineInstance, conn kubecli.VirtHandlerConn) (string, error) {
+		return conn.SoftRebootURI(vmi)
+	}
+	app.putRequestHandler(request, response, validate, getURL)
+}
+
 func (app *SubresourceAPIApp) fetchVirtualMachine(name string, namespace string) (*v1.VirtualMachine, *errors.StatusError) {
 
 	vm, err := app.virtCli.VirtualMachine(namespace).Get(name, &k8smetav1.GetOptions{})
@@ -777,6 +797,31 @@ func (app *SubresourceAPIApp) fetchVirtualMachineInstance(name string, namespace
 	return vmi, nil
 }
 
+// agentConnectPollInterval is how often a VMI is fetched again while waiting
+// for its guest agent to connect
+var agentConnectPollInterval = time.Second
+
+// isGuestAgentConnected tells whether the guest agent of the VMI is connected,
+// waiting up to the agent connect timeout of the VMI for it to connect.
+func (app *SubresourceAPIApp) isGuestAgentConnected(vmi *v1.VirtualMachineInstance) bool {
+	condManager := controller.NewVirtualMachineInstanceConditionManager()
+	if condManager.HasCondition(vmi, v1.VirtualMachineInstanceAgentConnected) {
+		return true
+	}
+	timeout := vmi.Spec.Domain.Devices.AgentConnectTimeoutSeconds
+	if timeout == nil || *timeout <= 0 {
+		return false
+	}
+	err := wait.Poll(agentConnectPollInterval, time.Duration(*timeout)*time.Second, func() (bool, error) {
+		current, statusErr := app.fetchVirtualMachineInstance(vmi.Name, vmi.Namespace)
+		if statusErr != nil {
+			return false, statusErr
+		}
+		return condManager.HasCondition(current, v1.VirtualMachineInstanceAgentConnected), nil
+	})
+	return err == nil
+}
+
 func writeError(error *errors.StatusError, response *restful.Response) {
 	errStatus := error.ErrStatus.DeepCopy()
 	errStatus.Kind = "Status"
@@ -793,8 +838,7 @@ func (app *SubresourceAPIApp) GuestOSInfo(request *restful.Request, response *re
 		if vmi == nil || vmi.Status.Phase != v1.Running {
 			return errors.NewConflict(v1.Resource("virtualmachineinstance"), vmi.Name, fmt.Errorf("VMI is not running"))
 		}
-		condManager := controller.NewVirtualMachineInstanceConditionManager()
-		if !condManager.HasCondition(vmi, v1.VirtualMachineInstanceAgentConnected) {
+		if !app.isGuestAgentConnected(vmi) {
 			return errors.NewConflict(v1.Resource("virtualmachineinstance"), vmi.Name, fmt.Errorf("VMI does not have guest agent connected"))
 		}
 		return nil
@@ -833,8 +877,7 @@ func (app *SubresourceAPIApp) UserList(request *restful.Request, response *restf
 		if vmi == nil || vmi.Status.Phase != v1.Running {
 			return errors.NewConflict(v1.Resource("virtualmachineinstance"), vmi.Name, fmt.Errorf("VMI is not running"))
 		}
-		condManager := controller.NewVirtualMachineInstanceConditionManager()
-		if !condManager.HasCondition(vmi, v1.VirtualMachineInstanceAgentConnected) {
+		if !app.isGuestAgentConnected(vmi) {
 			return errors.NewConflict(v1.Resource("virtualmachineinstance"), vmi.Name, fmt.Errorf("VMI does not have guest agent connected"))
 		}
 		return nil
@@ -873,8 +916,7 @@ func (app *SubresourceAPIApp) FilesystemList(request *restful.Request, response
 		if vmi == nil || vmi.Status.Phase != v1.Running {
 			return errors.NewConflict(v1.Resource("virtualmachineinstance"), vmi.Name, fmt.Errorf("VMI is not running"))
 		}
-		condManager := controller.NewVirtualMachineInstanceConditionManager()
-		if !condManager.HasCondition(vmi, v1.VirtualMachineInstanceAgentConnected) {
+		if !app.isGuestAgentConnected(vmi) {
 			return errors.NewConflict(v1.Resource("virtualmachineinstance"), vmi.Name, fmt.Errorf("VMI does not have guest agent connected"))
 		}
 		return nil
@@ -32,8 +32,10 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/emicklei/go-restful"
+	vsv1beta1 "github.com/kubernetes-csi/external-snapshotter/v2/pkg/apis/volumesnapshot/v1beta1"
 	. "github.com/onsi/ginkgo"
 	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
@@ -43,6 +45,7 @@ import (
 
 	k8sv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
 
@@ -1630,6 +1633,69 @@ var _ = Describe("VirtualMachineInstance Subresources", func() {
 			table.Entry("for UserList", app.UserList),
 			table.Entry("for FilesystemList", app.FilesystemList),
 		)
+
+		Context("waiting for the guest agent", func() {
+			const vmiPath = "/apis/kubevirt.io/v1alpha3/namespaces/default/virtualmachineinstances/testvm"
+			var orgPollInterval time.Duration
+
+			BeforeEach(func() {
+				orgPollInterval = agentConnectPollInterval
+				agentConnectPollInterval = 10 * time.Millisecond
+			})
+
+			AfterEach(func() {
+				agentConnectPollInterval = orgPollInterval
+			})
+
+			newVMI := func(timeoutSeconds *int32, agentConnected bool) *v1.VirtualMachineInstance {
+				vmi := v1.NewMinimalVMI("testvm")
+				vmi.Namespace = "default"
+				vmi.Status.Phase = v1.Running
+				vmi.Spec.Domain.Devices.AgentConnectTimeoutSeconds = timeoutSeconds
+				if agentConnected {
+					vmi.Status.Conditions = []v1.VirtualMachineInstanceCondition{{
+						Type:   v1.VirtualMachineInstanceAgentConnected,
+						Status: k8sv1.ConditionTrue,
+					}}
+				}
+				return vmi
+			}
+
+			// respondConnectedAfter serves the VMI, with its guest agent
+			// connected from the given fetch on
+			respondConnectedAfter := func(timeoutSeconds *int32, connectedFetch int) *int {
+				fetches := 0
+				server.RouteToHandler("GET", vmiPath, func(w http.ResponseWriter, r *http.Request) {
+					fetches++
+					ghttp.RespondWithJSONEncoded(http.StatusOK, newVMI(timeoutSeconds, fetches >= connectedFetch))(w, r)
+				})
+				return &fetches
+			}
+
+			It("should let a slow guest agent connect within the timeout", func() {
+				timeout := int32(5)
+				fetches := respondConnectedAfter(&timeout, 3)
+				Expect(app.isGuestAgentConnected(newVMI(&timeout, false))).To(BeTrue())
+				Expect(*fetches).To(Equal(3))
+			})
+
+			It("should give up on a guest agent not connecting within the timeout", func() {
+				timeout := int32(1)
+				respondConnectedAfter(&timeout, 1000)
+				Expect(app.isGuestAgentConnected(newVMI(&timeout, false))).To(BeFalse())
+			})
+
+			It("should not wait for the guest agent without a timeout", func() {
+				Expect(app.isGuestAgentConnected(newVMI(nil, false))).To(BeFalse())
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+
+			It("should not wait for a connected guest agent", func() {
+				timeout := int32(5)
+				Expect(app.isGuestAgentConnected(newVMI(&timeout, true))).To(BeTrue())
+				Expect(server.ReceivedRequests()).To(BeEmpty())
+			})
+		})
 	})
 
 	Context("StateChange JSON", func() {
@@ -1849,6 +1915,423 @@ var _ = Describe("VirtualMachineInstance Subresources", func() {
 		})
 	})
 
+	Context("Exporting disks", func() {
+		const claimPath = "/api/v1/namespaces/default/persistentvolumeclaims"
+		const dataVolumePath = "/apis/cdi.kubevirt.io/v1alpha1/namespaces/default/datavolumes"
+		const snapshotPath = "/apis/snapshot.storage.k8s.io/v1beta1/namespaces/default/volumesnapshots"
+
+		newExportDiskBody := func(opts *v1.ExportDiskOptions) io.ReadCloser {
+			optsJson, _ := json.Marshal(opts)
+			return &readCloserWrapper{bytes.NewReader(optsJson)}
+		}
+
+		expectExportedVMI := func(agentConnected bool) {
+			request.PathParameters()["name"] = "testvmi"
+			request.PathParameters()["namespace"] = "default"
+
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.Namespace = "default"
+			vmi.Status.Phase = v1.Running
+			vmi.Spec.Volumes = []v1.Volume{{
+				Name: "rootdisk",
+				VolumeSource: v1.VolumeSource{
+					PersistentVolumeClaim: &k8sv1.PersistentVolumeClaimVolumeSource{ClaimName: "rootdisk-claim"},
+				},
+			}}
+			if agentConnected {
+				vmi.Status.Conditions = []v1.VirtualMachineInstanceCondition{{
+					Type:   v1.VirtualMachineInstanceAgentConnected,
+					Status: k8sv1.ConditionTrue,
+				}}
+			}
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/apis/kubevirt.io/v1alpha3/namespaces/default/virtualmachineinstances/testvmi"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, vmi),
+				),
+			)
+		}
+
+		expectFreeExportTarget := func() {
+			claim := k8sv1.PersistentVolumeClaim{
+				ObjectMeta: k8smetav1.ObjectMeta{Name: "rootdisk-claim", Namespace: "default"},
+			}
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", claimPath+"/rootdisk-claim"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, claim),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", dataVolumePath+"/rootdisk-export"),
+					ghttp.RespondWithJSONEncoded(http.StatusNotFound, k8smetav1.Status{Reason: k8smetav1.StatusReasonNotFound, Code: http.StatusNotFound}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", claimPath+"/rootdisk-export"),
+					ghttp.RespondWithJSONEncoded(http.StatusNotFound, k8smetav1.Status{Reason: k8smetav1.StatusReasonNotFound, Code: http.StatusNotFound}),
+				),
+			)
+		}
+
+		expectFreezeAndThaw := func() {
+			backend.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v1/namespaces/default/virtualmachineinstances/testvmi/freeze"),
+					ghttp.RespondWith(http.StatusOK, ""),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v1/namespaces/default/virtualmachineinstances/testvmi/unfreeze"),
+					ghttp.RespondWith(http.StatusOK, ""),
+				),
+			)
+		}
+
+		BeforeEach(func() {
+			enableFeatureGate(virtconfig.SnapshotGate)
+			request.Request.Body = newExportDiskBody(&v1.ExportDiskOptions{VolumeName: "rootdisk", DataVolumeName: "rootdisk-export"})
+		})
+
+		AfterEach(func() {
+			disableFeatureGates()
+		})
+
+		It("should export a volume from a snapshot taken while the guest is frozen", func() {
+			expectExportedVMI(true)
+			expectHandlerPod()
+			expectFreeExportTarget()
+			expectFreezeAndThaw()
+
+			now := k8smetav1.Now()
+			takenSnapshot := vsv1beta1.VolumeSnapshot{
+				ObjectMeta: k8smetav1.ObjectMeta{Name: "export-rootdisk-export", Namespace: "default"},
+				Status:     &vsv1beta1.VolumeSnapshotStatus{CreationTime: &now},
+			}
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", snapshotPath),
+					ghttp.RespondWithJSONEncoded(http.StatusCreated, vsv1beta1.VolumeSnapshot{
+						ObjectMeta: k8smetav1.ObjectMeta{Name: "export-rootdisk-export", Namespace: "default"},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", snapshotPath+"/export-rootdisk-export"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, takenSnapshot),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", claimPath),
+					func(w http.ResponseWriter, r *http.Request) {
+						claim := &k8sv1.PersistentVolumeClaim{}
+						Expect(json.NewDecoder(r.Body).Decode(claim)).To(Succeed())
+						Expect(claim.Annotations).To(HaveKeyWithValue("cdi.kubevirt.io/storage.populatedFor", "rootdisk-export"))
+						Expect(claim.Spec.DataSource).ToNot(BeNil())
+						Expect(claim.Spec.DataSource.Kind).To(Equal("VolumeSnapshot"))
+						Expect(claim.Spec.DataSource.Name).To(Equal("export-rootdisk-export"))
+					},
+					ghttp.RespondWithJSONEncoded(http.StatusCreated, k8sv1.PersistentVolumeClaim{
+						ObjectMeta: k8smetav1.ObjectMeta{Name: "rootdisk-export", Namespace: "default"},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", snapshotPath+"/export-rootdisk-export"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, takenSnapshot),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", dataVolumePath),
+					ghttp.RespondWithJSONEncoded(http.StatusCreated, k8smetav1.Status{}),
+				),
+			)
+
+			app.ExportDiskVMIRequestHandler(request, response)
+
+			Expect(response.StatusCode()).To(Equal(http.StatusAccepted))
+			Expect(backend.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("should thaw the guest when the snapshot can't be created", func() {
+			expectExportedVMI(true)
+			expectHandlerPod()
+			expectFreeExportTarget()
+			expectFreezeAndThaw()
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", snapshotPath),
+					ghttp.RespondWithJSONEncoded(http.StatusInternalServerError, k8smetav1.Status{}),
+				),
+			)
+
+			app.ExportDiskVMIRequestHandler(request, response)
+
+			ExpectStatusErrorWithCode(recorder, http.StatusInternalServerError)
+			Expect(backend.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("should not freeze the guest when the DataVolume already exists", func() {
+			expectExportedVMI(true)
+			expectHandlerPod()
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", claimPath+"/rootdisk-claim"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, k8sv1.PersistentVolumeClaim{}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", dataVolumePath+"/rootdisk-export"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, k8smetav1.Status{}),
+				),
+			)
+
+			app.ExportDiskVMIRequestHandler(request, response)
+
+			ExpectStatusErrorWithCode(recorder, http.StatusConflict)
+			Expect(backend.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("should fail exporting a volume without a connected guest agent", func() {
+			expectExportedVMI(false)
+
+			app.ExportDiskVMIRequestHandler(request, response)
+
+			ExpectStatusErrorWithCode(recorder, http.StatusConflict)
+		})
+
+		It("should fail exporting an unknown volume", func() {
+			request.Request.Body = newExportDiskBody(&v1.ExportDiskOptions{VolumeName: "unknown", DataVolumeName: "rootdisk-export"})
+			expectExportedVMI(true)
+
+			app.ExportDiskVMIRequestHandler(request, response)
+
+			status := ExpectStatusErrorWithCode(recorder, http.StatusBadRequest)
+			Expect(status.Error()).To(ContainSubstring("volume unknown not found"))
+		})
+
+		It("should fail exporting without a DataVolume name", func() {
+			request.Request.Body = newExportDiskBody(&v1.ExportDiskOptions{VolumeName: "rootdisk"})
+
+			app.ExportDiskVMIRequestHandler(request, response)
+
+			status := ExpectStatusErrorWithCode(recorder, http.StatusBadRequest)
+			Expect(status.Error()).To(ContainSubstring("requires dataVolumeName"))
+		})
+
+		It("should fail exporting without the Snapshot feature gate", func() {
+			disableFeatureGates()
+
+			app.ExportDiskVMIRequestHandler(request, response)
+
+			ExpectStatusErrorWithCode(recorder, http.StatusBadRequest)
+		})
+	})
+
+	Context("Dumping the memory", func() {
+		const claimPath = "/api/v1/namespaces/default/persistentvolumeclaims"
+		const vmiPath = "/apis/kubevirt.io/v1alpha3/namespaces/default/virtualmachineinstances/testvmi"
+
+		newMemoryDumpBody := func(opts *v1.MemoryDumpOptions) io.ReadCloser {
+			optsJson, _ := json.Marshal(opts)
+			return &readCloserWrapper{bytes.NewReader(optsJson)}
+		}
+
+		expectDumpedVMI := func(dump *v1.MemoryDumpStatus) {
+			request.PathParameters()["name"] = "testvmi"
+			request.PathParameters()["namespace"] = "default"
+
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.Namespace = "default"
+			vmi.Status.Phase = v1.Running
+			vmi.Spec.Domain.Resources.Requests = k8sv1.ResourceList{
+				k8sv1.ResourceMemory: resource.MustParse("1Gi"),
+			}
+			vmi.Spec.Volumes = []v1.Volume{{
+				Name: "rootdisk",
+				VolumeSource: v1.VolumeSource{
+					PersistentVolumeClaim: &k8sv1.PersistentVolumeClaimVolumeSource{ClaimName: "rootdisk-claim"},
+				},
+			}}
+			vmi.Status.MemoryDump = dump
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", vmiPath),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, vmi),
+				),
+			)
+		}
+
+		expectDumpClaim := func(capacity string, volumeMode k8sv1.PersistentVolumeMode) {
+			claim := k8sv1.PersistentVolumeClaim{
+				ObjectMeta: k8smetav1.ObjectMeta{Name: "dump-claim", Namespace: "default"},
+				Spec:       k8sv1.PersistentVolumeClaimSpec{VolumeMode: &volumeMode},
+				Status: k8sv1.PersistentVolumeClaimStatus{
+					Phase:    k8sv1.ClaimBound,
+					Capacity: k8sv1.ResourceList{k8sv1.ResourceStorage: resource.MustParse(capacity)},
+				},
+			}
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", claimPath+"/dump-claim"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, claim),
+				),
+			)
+		}
+
+		BeforeEach(func() {
+			enableFeatureGate(virtconfig.HotplugVolumesGate)
+			request.Request.Body = newMemoryDumpBody(&v1.MemoryDumpOptions{ClaimName: "dump-claim"})
+		})
+
+		AfterEach(func() {
+			disableFeatureGates()
+		})
+
+		table.DescribeTable("should request a memory dump", func(previous *v1.MemoryDumpStatus, expectedOps []string) {
+			expectDumpedVMI(previous)
+			expectDumpClaim("2Gi", k8sv1.PersistentVolumeFilesystem)
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PATCH", vmiPath),
+					func(w http.ResponseWriter, r *http.Request) {
+						var patch []struct {
+							Op    string              `json:"op"`
+							Path  string              `json:"path"`
+							Value v1.MemoryDumpStatus `json:"value"`
+						}
+						Expect(json.NewDecoder(r.Body).Decode(&patch)).To(Succeed())
+						Expect(patch).To(HaveLen(len(expectedOps)))
+						for i, op := range expectedOps {
+							Expect(patch[i].Op).To(Equal(op))
+							Expect(patch[i].Path).To(Equal("/status/memoryDump"))
+						}
+						dump := patch[len(patch)-1].Value
+						Expect(dump.ClaimName).To(Equal("dump-claim"))
+						Expect(dump.Phase).To(Equal(v1.MemoryDumpPending))
+						Expect(dump.FileName).To(HavePrefix("testvmi-"))
+						Expect(dump.FileName).To(HaveSuffix(".memory.dump"))
+						Expect(dump.StartTimestamp).ToNot(BeNil())
+					},
+					ghttp.RespondWithJSONEncoded(http.StatusOK, v1.VirtualMachineInstance{}),
+				),
+			)
+
+			app.MemoryDumpVMIRequestHandler(request, response)
+
+			Expect(response.StatusCode()).To(Equal(http.StatusAccepted))
+		},
+			table.Entry("for the first time", nil, []string{"add"}),
+			table.Entry("after a finished dump", &v1.MemoryDumpStatus{
+				ClaimName: "dump-claim",
+				Phase:     v1.MemoryDumpCompleted,
+				FileName:  "testvmi-20210101-000000.memory.dump",
+			}, []string{"test", "replace"}),
+		)
+
+		It("should fail when the claim is too small for the memory dump", func() {
+			expectDumpedVMI(nil)
+			expectDumpClaim("1Gi", k8sv1.PersistentVolumeFilesystem)
+
+			app.MemoryDumpVMIRequestHandler(request, response)
+
+			status := ExpectStatusErrorWithCode(recorder, http.StatusBadRequest)
+			Expect(status.Error()).To(ContainSubstring("insufficient space"))
+		})
+
+		It("should fail dumping to a block mode claim", func() {
+			expectDumpedVMI(nil)
+			expectDumpClaim("2Gi", k8sv1.PersistentVolumeBlock)
+
+			app.MemoryDumpVMIRequestHandler(request, response)
+
+			status := ExpectStatusErrorWithCode(recorder, http.StatusBadRequest)
+			Expect(status.Error()).To(ContainSubstring("filesystem mode"))
+		})
+
+		It("should fail dumping to a missing claim", func() {
+			expectDumpedVMI(nil)
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", claimPath+"/dump-claim"),
+					ghttp.RespondWithJSONEncoded(http.StatusNotFound, k8smetav1.Status{Reason: k8smetav1.StatusReasonNotFound, Code: http.StatusNotFound}),
+				),
+			)
+
+			app.MemoryDumpVMIRequestHandler(request, response)
+
+			ExpectStatusErrorWithCode(recorder, http.StatusNotFound)
+		})
+
+		It("should fail dumping to a claim backing a volume of the VMI", func() {
+			request.Request.Body = newMemoryDumpBody(&v1.MemoryDumpOptions{ClaimName: "rootdisk-claim"})
+			expectDumpedVMI(nil)
+
+			app.MemoryDumpVMIRequestHandler(request, response)
+
+			ExpectStatusErrorWithCode(recorder, http.StatusConflict)
+		})
+
+		It("should fail while another memory dump is in progress", func() {
+			expectDumpedVMI(&v1.MemoryDumpStatus{
+				ClaimName: "dump-claim",
+				Phase:     v1.MemoryDumpInProgress,
+				FileName:  "testvmi-20210101-000000.memory.dump",
+			})
+
+			app.MemoryDumpVMIRequestHandler(request, response)
+
+			status := ExpectStatusErrorWithCode(recorder, http.StatusConflict)
+			Expect(status.Error()).To(ContainSubstring("already in progress"))
+		})
+
+		It("should fail without a claim name", func() {
+			request.Request.Body = newMemoryDumpBody(&v1.MemoryDumpOptions{})
+
+			app.MemoryDumpVMIRequestHandler(request, response)
+
+			status := ExpectStatusErrorWithCode(recorder, http.StatusBadRequest)
+			Expect(status.Error()).To(ContainSubstring("requires claimName"))
+		})
+
+		It("should fail without the HotplugVolumes feature gate", func() {
+			disableFeatureGates()
+
+			app.MemoryDumpVMIRequestHandler(request, response)
+
+			ExpectStatusErrorWithCode(recorder, http.StatusBadRequest)
+		})
+	})
+
+	Context("Soft rebooting", func() {
+		It("Should soft reboot a running, not paused VMI", func() {
+			backend.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/v1/namespaces/default/virtualmachineinstances/testvmi/softreboot"),
+					ghttp.RespondWith(http.StatusOK, ""),
+				),
+			)
+			expectVMI(true, false)
+
+			app.SoftRebootVMIRequestHandler(request, response)
+
+			Expect(response.StatusCode()).To(Equal(http.StatusOK))
+		})
+
+		It("Should fail soft rebooting a not running VMI", func() {
+
+			expectVMI(false, false)
+
+			app.SoftRebootVMIRequestHandler(request, response)
+
+			ExpectStatusErrorWithCode(recorder, http.StatusConflict)
+		})
+
+		It("Should fail soft rebooting a paused VMI", func() {
+
+			expectVMI(true, true)
+
+			app.SoftRebootVMIRequestHandler(request, response)
+
+			ExpectStatusErrorWithCode(recorder, http.StatusConflict)
+		})
+	})
+
 	AfterEach(func() {
 		server.Close()
 		backend.Close()
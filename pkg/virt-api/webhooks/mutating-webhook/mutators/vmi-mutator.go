@@ -269,12 +269,10 @@ func (mutator *VMIsMutator) setDefaultResourceRequests(vmi *v1.VirtualMachineIns
 				resources.Requests = k8sv1.ResourceList{}
 			}
 			overcommit := mutator.ClusterConfig.GetMemoryOvercommit()
-			if overcommit == 100 {
-				resources.Requests[k8sv1.ResourceMemory] = *memory
-			} else {
-				value := (memory.Value() * int64(100)) / int64(overcommit)
-				resources.Requests[k8sv1.ResourceMemory] = *resource.NewQuantity(value, memory.Format)
+			if vmi.Spec.Domain.Memory != nil && vmi.Spec.Domain.Memory.OvercommitPercent != nil {
+				overcommit = int(*vmi.Spec.Domain.Memory.OvercommitPercent)
 			}
+			resources.Requests[k8sv1.ResourceMemory] = virtconfig.OvercommittedMemoryRequest(*memory, overcommit)
 			memoryRequest := resources.Requests[k8sv1.ResourceMemory]
 			log.Log.Object(vmi).V(4).Infof("Set memory-request to %s as a result of memory-overcommit = %v%%", memoryRequest.String(), overcommit)
 		}
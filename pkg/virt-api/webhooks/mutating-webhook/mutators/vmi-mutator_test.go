@@ -527,6 +527,28 @@ var _ = Describe("VirtualMachineInstance Mutator", func() {
 		Expect(vmiSpec.Domain.Resources.Requests.Memory().String()).To(Equal("2048M"))
 	})
 
+	It("should apply the memory overcommit of the vmi instead of the one of the cluster", func() {
+		// no limits wanted on this test, to not copy the limit to requests
+		namespaceLimitInformer, _ = testutils.NewFakeInformerFor(&k8sv1.LimitRange{})
+		webhooks.SetInformers(
+			&webhooks.Informers{
+				VMIPresetInformer:       presetInformer,
+				NamespaceLimitsInformer: namespaceLimitInformer,
+			},
+		)
+		testutils.UpdateFakeClusterConfig(configMapInformer, &k8sv1.ConfigMap{
+			Data: map[string]string{
+				virtconfig.MemoryOvercommitKey: "150",
+			},
+		})
+		guestMemory := resource.MustParse("3072M")
+		overcommit := uint32(200)
+		vmi.Spec.Domain.Memory = &v1.Memory{Guest: &guestMemory, OvercommitPercent: &overcommit}
+		vmiSpec, _ := getVMISpecMetaFromResponse()
+		Expect(vmiSpec.Domain.Memory.Guest.String()).To(Equal("3072M"))
+		Expect(vmiSpec.Domain.Resources.Requests.Memory().String()).To(Equal("1536M"))
+	})
+
 	It("should apply memory-overcommit when hugepages are set and memory-request is not set", func() {
 		// no limits wanted on this test, to not copy the limit to requests
 		namespaceLimitInformer, _ = testutils.NewFakeInformerFor(&k8sv1.LimitRange{})
@@ -138,5 +138,29 @@ func ValidateVirtualMachineInstanceMigrationSpec(field *k8sfield.Path, spec *v1.
 		})
 	}
 
+	if spec.CommitCutover && !spec.Warmup {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s can only be set on migrations with %s", field.Child("commitCutover").String(), field.Child("warmup").String()),
+			Field:   field.Child("commitCutover").String(),
+		})
+	}
+
+	if spec.Bandwidth != nil && spec.Bandwidth.Sign() < 0 {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s must not be negative", field.Child("bandwidth").String()),
+			Field:   field.Child("bandwidth").String(),
+		})
+	}
+
+	if spec.ParallelMigrationThreads != nil && *spec.ParallelMigrationThreads == 0 {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s must be at least 1", field.Child("parallelMigrationThreads").String()),
+			Field:   field.Child("parallelMigrationThreads").String(),
+		})
+	}
+
 	return causes
 }
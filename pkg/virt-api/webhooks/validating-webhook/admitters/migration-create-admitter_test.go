@@ -27,8 +27,10 @@ import (
 	. "github.com/onsi/gomega"
 	"k8s.io/api/admission/v1beta1"
 	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	k8sfield "k8s.io/apimachinery/pkg/util/validation/field"
 
 	v1 "kubevirt.io/client-go/api/v1"
 	"kubevirt.io/kubevirt/pkg/testutils"
@@ -81,6 +83,94 @@ var _ = Describe("Validating MigrationCreate Admitter", func() {
 		Expect(resp.Result.Details.Causes[0].Field).To(Equal("spec.vmiName"))
 	})
 
+	It("should reject committing the cutover of a Migration without warmup on create", func() {
+		migration := v1.VirtualMachineInstanceMigration{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+			},
+			Spec: v1.VirtualMachineInstanceMigrationSpec{
+				VMIName:       "testvmimigrate1",
+				CommitCutover: true,
+			},
+		}
+		migrationBytes, _ := json.Marshal(&migration)
+
+		enableFeatureGate(virtconfig.LiveMigrationGate)
+
+		ar := &v1beta1.AdmissionReview{
+			Request: &v1beta1.AdmissionRequest{
+				Resource: webhooks.MigrationGroupVersionResource,
+				Object: runtime.RawExtension{
+					Raw: migrationBytes,
+				},
+			},
+		}
+
+		resp := migrationCreateAdmitter.Admit(ar)
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(len(resp.Result.Details.Causes)).To(Equal(1))
+		Expect(resp.Result.Details.Causes[0].Field).To(Equal("spec.commitCutover"))
+	})
+
+	table.DescribeTable("should validate the tuning of the Migration", func(bandwidth string, threads int, expectedField string) {
+		spec := &v1.VirtualMachineInstanceMigrationSpec{
+			VMIName: "testvmimigrate1",
+		}
+		if bandwidth != "" {
+			quantity := resource.MustParse(bandwidth)
+			spec.Bandwidth = &quantity
+		}
+		if threads >= 0 {
+			parallelMigrationThreads := uint32(threads)
+			spec.ParallelMigrationThreads = &parallelMigrationThreads
+		}
+		causes := ValidateVirtualMachineInstanceMigrationSpec(k8sfield.NewPath("spec"), spec)
+		if expectedField == "" {
+			Expect(causes).To(BeEmpty())
+		} else {
+			Expect(causes).To(HaveLen(1))
+			Expect(causes[0].Field).To(Equal(expectedField))
+		}
+	},
+		table.Entry("accepting a bandwidth", "32Mi", -1, ""),
+		table.Entry("accepting an unlimited bandwidth", "0", -1, ""),
+		table.Entry("rejecting a negative bandwidth", "-1Mi", -1, "spec.bandwidth"),
+		table.Entry("accepting parallel connections", "", 4, ""),
+		table.Entry("rejecting zero parallel connections", "", 0, "spec.parallelMigrationThreads"),
+	)
+
+	It("should accept a warm Migration spec on create", func() {
+		vmi := v1.NewMinimalVMI("testvmimigratewarm")
+
+		informers := webhooks.GetInformers()
+		informers.VMIInformer.GetIndexer().Add(vmi)
+
+		migration := v1.VirtualMachineInstanceMigration{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: vmi.Namespace,
+			},
+			Spec: v1.VirtualMachineInstanceMigrationSpec{
+				VMIName: "testvmimigratewarm",
+				Warmup:  true,
+			},
+		}
+		migrationBytes, _ := json.Marshal(&migration)
+
+		enableFeatureGate(virtconfig.LiveMigrationGate)
+
+		ar := &v1beta1.AdmissionReview{
+			Request: &v1beta1.AdmissionRequest{
+				Resource: webhooks.MigrationGroupVersionResource,
+				Object: runtime.RawExtension{
+					Raw: migrationBytes,
+				},
+			},
+		}
+
+		resp := migrationCreateAdmitter.Admit(ar)
+		Expect(resp.Allowed).To(BeTrue())
+	})
+
 	It("should accept valid Migration spec on create", func() {
 		vmi := v1.NewMinimalVMI("testvmimigrate1")
 
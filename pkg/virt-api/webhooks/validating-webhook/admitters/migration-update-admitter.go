@@ -20,10 +20,12 @@
 package admitters
 
 import (
+	"fmt"
 	"reflect"
 
 	"k8s.io/api/admission/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfield "k8s.io/apimachinery/pkg/util/validation/field"
 
 	v1 "kubevirt.io/client-go/api/v1"
 	webhookutils "kubevirt.io/kubevirt/pkg/util/webhooks"
@@ -43,17 +45,38 @@ func (admitter *MigrationUpdateAdmitter) Admit(ar *v1beta1.AdmissionReview) *v1b
 		return resp
 	}
 
-	// Reject Migration update if spec changed
-	if !reflect.DeepEqual(newMigration.Spec, oldMigration.Spec) {
-		return webhookutils.ToAdmissionResponse([]metav1.StatusCause{
+	// Reject Migration update if spec changed, besides committing the cutover
+	// of a warm migration
+	if causes := validateMigrationSpecUpdate(k8sfield.NewPath("spec"), &newMigration.Spec, &oldMigration.Spec); len(causes) > 0 {
+		return webhookutils.ToAdmissionResponse(causes)
+	}
+
+	reviewResponse := v1beta1.AdmissionResponse{}
+	reviewResponse.Allowed = true
+	return &reviewResponse
+}
+
+func validateMigrationSpecUpdate(field *k8sfield.Path, newSpec, oldSpec *v1.VirtualMachineInstanceMigrationSpec) []metav1.StatusCause {
+	committedSpec := oldSpec.DeepCopy()
+	committedSpec.CommitCutover = newSpec.CommitCutover
+	if !reflect.DeepEqual(*newSpec, *committedSpec) {
+		return []metav1.StatusCause{
 			{
 				Type:    metav1.CauseTypeFieldValueNotSupported,
 				Message: "update of Migration object's spec is restricted",
 			},
-		})
+		}
 	}
 
-	reviewResponse := v1beta1.AdmissionResponse{}
-	reviewResponse.Allowed = true
-	return &reviewResponse
+	if oldSpec.CommitCutover && !newSpec.CommitCutover {
+		return []metav1.StatusCause{
+			{
+				Type:    metav1.CauseTypeFieldValueNotSupported,
+				Message: fmt.Sprintf("%s can't be unset once the cutover is committed", field.Child("commitCutover").String()),
+				Field:   field.Child("commitCutover").String(),
+			},
+		}
+	}
+
+	return ValidateVirtualMachineInstanceMigrationSpec(field, newSpec)
 }
@@ -130,4 +130,72 @@ var _ = Describe("Validating MigrationUpdate Admitter", func() {
 		resp := migrationUpdateAdmitter.Admit(ar)
 		Expect(resp.Allowed).To(BeTrue())
 	})
+
+	Context("with the cutover of a warm migration", func() {
+		admitUpdate := func(oldSpec, newSpec v1.VirtualMachineInstanceMigrationSpec) *v1beta1.AdmissionResponse {
+			migration := v1.VirtualMachineInstanceMigration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "somewarmmigration",
+					Namespace: "default",
+					UID:       "5678",
+				},
+				Spec: oldSpec,
+			}
+			oldMigrationBytes, _ := json.Marshal(&migration)
+
+			newMigration := migration.DeepCopy()
+			newMigration.Spec = newSpec
+			newMigrationBytes, _ := json.Marshal(&newMigration)
+
+			enableFeatureGate(virtconfig.LiveMigrationGate)
+
+			ar := &v1beta1.AdmissionReview{
+				Request: &v1beta1.AdmissionRequest{
+					Resource: webhooks.MigrationGroupVersionResource,
+					Object: runtime.RawExtension{
+						Raw: newMigrationBytes,
+					},
+					OldObject: runtime.RawExtension{
+						Raw: oldMigrationBytes,
+					},
+					Operation: v1beta1.Update,
+				},
+			}
+			return migrationUpdateAdmitter.Admit(ar)
+		}
+
+		It("should accept committing the cutover", func() {
+			resp := admitUpdate(
+				v1.VirtualMachineInstanceMigrationSpec{VMIName: "testvmi", Warmup: true},
+				v1.VirtualMachineInstanceMigrationSpec{VMIName: "testvmi", Warmup: true, CommitCutover: true},
+			)
+			Expect(resp.Allowed).To(BeTrue())
+		})
+
+		It("should reject unsetting a committed cutover", func() {
+			resp := admitUpdate(
+				v1.VirtualMachineInstanceMigrationSpec{VMIName: "testvmi", Warmup: true, CommitCutover: true},
+				v1.VirtualMachineInstanceMigrationSpec{VMIName: "testvmi", Warmup: true},
+			)
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(resp.Result.Details.Causes[0].Field).To(Equal("spec.commitCutover"))
+		})
+
+		It("should reject committing the cutover of a migration without warmup", func() {
+			resp := admitUpdate(
+				v1.VirtualMachineInstanceMigrationSpec{VMIName: "testvmi"},
+				v1.VirtualMachineInstanceMigrationSpec{VMIName: "testvmi", CommitCutover: true},
+			)
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(resp.Result.Details.Causes[0].Field).To(Equal("spec.commitCutover"))
+		})
+
+		It("should reject enabling the warmup", func() {
+			resp := admitUpdate(
+				v1.VirtualMachineInstanceMigrationSpec{VMIName: "testvmi"},
+				v1.VirtualMachineInstanceMigrationSpec{VMIName: "testvmi", Warmup: true},
+			)
+			Expect(resp.Allowed).To(BeFalse())
+		})
+	})
 })
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"k8s.io/api/admission/v1beta1"
+	k8sv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	virtv1 "kubevirt.io/client-go/api/v1"
@@ -21,10 +22,6 @@ type PodEvictionAdmitter struct {
 }
 
 func (admitter *PodEvictionAdmitter) Admit(ar *v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
-	if !admitter.ClusterConfig.LiveMigrationEnabled() {
-		return validating_webhooks.NewPassingAdmissionResponse()
-	}
-
 	launcher, err := admitter.VirtClient.CoreV1().Pods(ar.Request.Namespace).Get(context.Background(), ar.Request.Name, metav1.GetOptions{})
 	if err != nil {
 		return validating_webhooks.NewPassingAdmissionResponse()
@@ -43,6 +40,16 @@ func (admitter *PodEvictionAdmitter) Admit(ar *v1beta1.AdmissionReview) *v1beta1
 	if err != nil {
 		return denied(fmt.Sprintf("kubevirt failed getting the vmi: %s", err.Error()))
 	}
+	if vmi.IsProtectedUntilDrained() {
+		// The pod is protected by a PDB until the VMI got shut down, marking the
+		// VMI triggers its graceful shutdown since live migration is not involved.
+		return admitter.markVMIForEviction(ar, vmi, launcher)
+	}
+
+	if !admitter.ClusterConfig.LiveMigrationEnabled() {
+		return validating_webhooks.NewPassingAdmissionResponse()
+	}
+
 	if !vmi.IsEvictable() {
 		// we don't act on VMIs without an eviction strategy
 		return validating_webhooks.NewPassingAdmissionResponse()
@@ -51,6 +58,12 @@ func (admitter *PodEvictionAdmitter) Admit(ar *v1beta1.AdmissionReview) *v1beta1
 			"VMI %s is configured with an eviction strategy but is not live-migratable", vmi.Name))
 	}
 
+	// We can let the request go through because the pod is protected by a PDB if the VMI wants to be live-migrated on
+	// eviction. Otherwise, we can just evict it.
+	return admitter.markVMIForEviction(ar, vmi, launcher)
+}
+
+func (admitter *PodEvictionAdmitter) markVMIForEviction(ar *v1beta1.AdmissionReview, vmi *virtv1.VirtualMachineInstance, launcher *k8sv1.Pod) *v1beta1.AdmissionResponse {
 	if !vmi.IsMarkedForEviction() && vmi.Status.NodeName == launcher.Spec.NodeName {
 		dryRun := ar.Request.DryRun != nil && *ar.Request.DryRun == true
 		err := admitter.markVMI(ar, vmi, dryRun)
@@ -59,9 +72,6 @@ func (admitter *PodEvictionAdmitter) Admit(ar *v1beta1.AdmissionReview) *v1beta1
 			return denied(fmt.Sprintf("kubevirt failed marking the vmi for eviction: %s", err.Error()))
 		}
 	}
-
-	// We can let the request go through because the pod is protected by a PDB if the VMI wants to be live-migrated on
-	// eviction. Otherwise, we can just evict it.
 	return validating_webhooks.NewPassingAdmissionResponse()
 }
 
@@ -301,14 +301,84 @@ var _ = Describe("Pod eviction admitter", func() {
 	})
 
 	Context("Live migration disabled", func() {
-		kv := kubecli.NewMinimalKubeVirt(testns)
-		clusterConfig, _, _, _ := testutils.NewFakeClusterConfigUsingKV(kv)
-		podEvictionAdmitter := PodEvictionAdmitter{
-			ClusterConfig: clusterConfig,
+
+		var podEvictionAdmitter PodEvictionAdmitter
+		var pod *k8sv1.Pod
+		var ar *v1beta1.AdmissionReview
+
+		BeforeEach(func() {
+			kv := kubecli.NewMinimalKubeVirt(testns)
+			clusterConfig, _, _, _ := testutils.NewFakeClusterConfigUsingKV(kv)
+			podEvictionAdmitter = PodEvictionAdmitter{
+				ClusterConfig: clusterConfig,
+				VirtClient:    virtClient,
+			}
+
+			pod = &k8sv1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "testpod",
+					Namespace: testns,
+					Annotations: map[string]string{
+						virtv1.DomainAnnotation: "testvmi",
+					},
+					Labels: map[string]string{
+						virtv1.AppLabel: "virt-launcher",
+					},
+				},
+				Spec: k8sv1.PodSpec{
+					NodeName: "node01",
+				},
+			}
+			ar = &v1beta1.AdmissionReview{
+				Request: &v1beta1.AdmissionRequest{
+					Name:      pod.Name,
+					Namespace: pod.Namespace,
+				},
+			}
+			kubeClient.Fake.PrependReactor("get", "pods", func(action testing.Action) (handled bool, obj runtime.Object, err error) {
+				return true, pod, nil
+			})
+		})
+
+		newVMI := func(strategy virtv1.EvictionStrategy) *virtv1.VirtualMachineInstance {
+			return &virtv1.VirtualMachineInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: testns,
+					Name:      "testvmi",
+				},
+				Spec: virtv1.VirtualMachineInstanceSpec{
+					EvictionStrategy: &strategy,
+				},
+				Status: virtv1.VirtualMachineInstanceStatus{
+					NodeName: "node01",
+				},
+			}
 		}
 
-		It("Should allow any review request", func() {
-			resp := podEvictionAdmitter.Admit(&v1beta1.AdmissionReview{})
+		It("Should allow review requests without marking VMIs which want to be live migrated", func() {
+			vmiClient.EXPECT().Get("testvmi", &metav1.GetOptions{}).Return(newVMI(virtv1.EvictionStrategyLiveMigrate), nil)
+
+			resp := podEvictionAdmitter.Admit(ar)
+			Expect(resp.Allowed).To(BeTrue())
+		})
+
+		It("Should allow review requests and mark VMIs which are protected until drained", func() {
+			vmi := newVMI(virtv1.EvictionStrategyProtectUntilDrained)
+			vmiClient.EXPECT().Get("testvmi", &metav1.GetOptions{}).Return(vmi, nil)
+			vmiClient.EXPECT().Update(gomock.Any()).Do(func(obj *virtv1.VirtualMachineInstance) {
+				Expect(obj.Status.EvacuationNodeName).To(Equal("node01"))
+			}).Return(vmi, nil)
+
+			resp := podEvictionAdmitter.Admit(ar)
+			Expect(resp.Allowed).To(BeTrue())
+		})
+
+		It("Should allow review requests without marking VMIs which are protected until drained twice", func() {
+			vmi := newVMI(virtv1.EvictionStrategyProtectUntilDrained)
+			vmi.Status.EvacuationNodeName = "node01"
+			vmiClient.EXPECT().Get("testvmi", &metav1.GetOptions{}).Return(vmi, nil)
+
+			resp := podEvictionAdmitter.Admit(ar)
 			Expect(resp.Allowed).To(BeTrue())
 		})
 
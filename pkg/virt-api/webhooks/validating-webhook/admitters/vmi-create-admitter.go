@@ -39,6 +39,8 @@ import (
 	webhookutils "kubevirt.io/kubevirt/pkg/util/webhooks"
 	"kubevirt.io/kubevirt/pkg/virt-api/webhooks"
 	virtconfig "kubevirt.io/kubevirt/pkg/virt-config"
+	"kubevirt.io/kubevirt/pkg/virt-controller/services"
+	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/converter"
 )
 
 const (
@@ -56,10 +58,32 @@ const (
 	maxDNSNameservers     = 3
 	maxDNSSearchPaths     = 6
 	maxDNSSearchListChars = 256
+
+	// Bounds of the MTU the tap device of an interface accepts, 68 being the
+	// smallest MTU IPv4 requires.
+	minInterfaceMTU = 68
+	maxInterfaceMTU = 65535
+
+	// maxAgentConnectTimeoutSeconds bounds how long the requests waiting for
+	// the guest agent are held.
+	maxAgentConnectTimeoutSeconds = 600
+
+	// libvirt accepts reboot timeouts up to 65535 milliseconds.
+	maxRebootTimeoutSeconds = 65
+
+	// maxFreezeHookTimeoutSeconds keeps the freeze hooks within the timeout
+	// virt-handler applies to the commands it sends to virt-launcher.
+	maxFreezeHookTimeoutSeconds = 15
 )
 
 var validInterfaceModels = map[string]*struct{}{"e1000": nil, "e1000e": nil, "ne2k_pci": nil, "pcnet": nil, "rtl8139": nil, "virtio": nil}
 var validIOThreadsPolicies = []v1.IOThreadsPolicy{v1.IOThreadsPolicyShared, v1.IOThreadsPolicyAuto}
+var validCrashActions = []v1.CrashAction{v1.CrashActionDestroy, v1.CrashActionRestart, v1.CrashActionPreserve}
+// SELinux types are advertised as node label names, hence limited to their length and characters
+var validSELinuxTypeRegex = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+
+const maxSELinuxTypeLength = 63
+
 var validCPUFeaturePolicies = map[string]*struct{}{"": nil, "force": nil, "require": nil, "optional": nil, "disable": nil, "forbid": nil}
 
 var restriectedVmiLabels = map[string]bool{
@@ -124,12 +148,16 @@ func ValidateVirtualMachineInstanceSpec(field *k8sfield.Path, spec *v1.VirtualMa
 
 	causes = append(causes, validateHostNameNotConformingToDNSLabelRules(field, spec)...)
 	causes = append(causes, validateSubdomainDNSSubdomainRules(field, spec)...)
+	causes = append(causes, validateWorkloadClass(field, spec)...)
 	causes = append(causes, validateMemoryRequestsNegativeOrNull(field, spec)...)
 	causes = append(causes, validateMemoryLimitsNegativeOrNull(field, spec)...)
 	causes = append(causes, validateHugepagesMemoryRequests(field, spec)...)
 	causes = append(causes, validateGuestMemoryLimit(field, spec)...)
+	causes = append(causes, validateBalloonFloor(field, spec)...)
+	causes = append(causes, validateMemoryOvercommit(field, spec, config)...)
 	causes = append(causes, validateEmulatedMachine(field, spec, config)...)
 	causes = append(causes, validateFirmwareSerial(field, spec)...)
+	causes = append(causes, validateClock(field, spec)...)
 	causes = append(causes, validateCPURequestNotNegative(field, spec)...)
 	causes = append(causes, validateCPULimitNotNegative(field, spec)...)
 	causes = append(causes, validateCpuRequestDoesNotExceedLimit(field, spec)...)
@@ -173,8 +201,14 @@ func ValidateVirtualMachineInstanceSpec(field *k8sfield.Path, spec *v1.VirtualMa
 
 	causes = append(causes, validateInputDevices(field, spec)...)
 	causes = append(causes, validateIOThreadsPolicy(field, spec)...)
+	causes = append(causes, validateSELinuxLauncherType(field, spec)...)
+	causes = append(causes, validateOnCrash(field, spec)...)
+	causes = append(causes, validateBoot(field, spec)...)
 	causes = append(causes, validateReadinessProbe(field, spec)...)
 	causes = append(causes, validateLivenessProbe(field, spec)...)
+	causes = append(causes, validateLivenessProbeFailureAction(field, spec)...)
+	causes = append(causes, validateStartupProbe(field, spec)...)
+	causes = append(causes, validateFreezeHooks(field, spec)...)
 
 	if getNumberOfPodInterfaces(spec) < 1 {
 		causes = appendStatusCauseForLivenessProbeNotAllowedWithNoPodNetworkPresent(field, spec, causes)
@@ -193,8 +227,25 @@ func ValidateVirtualMachineInstanceSpec(field *k8sfield.Path, spec *v1.VirtualMa
 	causes = append(causes, validateLiveMigration(field, spec, config)...)
 	causes = append(causes, validateGPUsWithPassthroughEnabled(field, spec, config)...)
 	causes = append(causes, validateFilesystemsWithVirtIOFSEnabled(field, spec, config)...)
+	causes = append(causes, validateFilesystemsVirtiofs(field, spec)...)
 	causes = append(causes, validateHostDevicesWithPassthroughEnabled(field, spec, config)...)
 	causes = append(causes, validatePermittedHostDevices(field, spec, config)...)
+	causes = append(causes, validateHostDevicesPciAddresses(field, spec)...)
+	causes = append(causes, validateHostDevicesROMs(field, spec)...)
+	causes = append(causes, validateGuestPciAddressCollisions(field, spec)...)
+	causes = append(causes, validateAgentConnectTimeout(field, spec)...)
+	return causes
+}
+
+func validateAgentConnectTimeout(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
+	timeout := spec.Domain.Devices.AgentConnectTimeoutSeconds
+	if timeout != nil && (*timeout < 0 || *timeout > maxAgentConnectTimeoutSeconds) {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s %d is out of range, it must be between 0 and %d.", field.Child("domain", "devices", "agentConnectTimeoutSeconds").String(), *timeout, maxAgentConnectTimeoutSeconds),
+			Field:   field.Child("domain", "devices", "agentConnectTimeoutSeconds").String(),
+		})
+	}
 	return causes
 }
 
@@ -226,6 +277,8 @@ func validateNetworksMatchInterfaces(field *k8sfield.Path, spec *v1.VirtualMachi
 		causes = append(causes, validatePortConfiguration(field, networkExists, networkData, iface, idx, portForwardMap)...)
 		causes = append(causes, validateInterfaceModel(field, iface, idx)...)
 		causes = append(causes, validateMacAddress(field, iface, idx)...)
+		causes = append(causes, validateMacAddressPool(field, iface, idx, config)...)
+		causes = append(causes, validateInterfaceMTU(field, iface, idx)...)
 		causes = append(causes, validateInterfaceBootOrder(field, iface, idx, bootOrderMap)...)
 		causes = append(causes, validateInterfacePciAddress(field, iface, idx)...)
 
@@ -375,6 +428,46 @@ func validateMacAddress(field *k8sfield.Path, iface v1.Interface, idx int) (caus
 	return causes
 }
 
+func validateMacAddressPool(field *k8sfield.Path, iface v1.Interface, idx int, config *virtconfig.ClusterConfig) (causes []metav1.StatusCause) {
+	if iface.MacAddressPool == "" {
+		return causes
+	}
+	for _, pool := range config.GetMacAddressPools() {
+		if pool.Name == iface.MacAddressPool {
+			return causes
+		}
+	}
+	causes = append(causes, metav1.StatusCause{
+		Type:    metav1.CauseTypeFieldValueInvalid,
+		Message: fmt.Sprintf("interface %s requests MAC address pool %s which is not configured.", field.Child("domain", "devices", "interfaces").Index(idx).Child("name").String(), iface.MacAddressPool),
+		Field:   field.Child("domain", "devices", "interfaces").Index(idx).Child("macAddressPool").String(),
+	})
+	return causes
+}
+
+func validateInterfaceMTU(field *k8sfield.Path, iface v1.Interface, idx int) (causes []metav1.StatusCause) {
+	if iface.MTU == nil {
+		return causes
+	}
+	if iface.Bridge == nil && iface.Masquerade == nil {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("interface %s can only set an MTU with the bridge or masquerade binding.", field.Child("domain", "devices", "interfaces").Index(idx).Child("name").String()),
+			Field:   field.Child("domain", "devices", "interfaces").Index(idx).Child("mtu").String(),
+		})
+	}
+	// The MTU of the pod network is only known in virt-launcher, which refuses
+	// an MTU exceeding it.
+	if *iface.MTU < minInterfaceMTU || *iface.MTU > maxInterfaceMTU {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("interface %s has MTU %d out of range, it must be between %d and %d.", field.Child("domain", "devices", "interfaces").Index(idx).Child("name").String(), *iface.MTU, minInterfaceMTU, maxInterfaceMTU),
+			Field:   field.Child("domain", "devices", "interfaces").Index(idx).Child("mtu").String(),
+		})
+	}
+	return causes
+}
+
 func validateInterfaceModel(field *k8sfield.Path, iface v1.Interface, idx int) (causes []metav1.StatusCause) {
 	if iface.Model != "" {
 		if _, exists := validInterfaceModels[iface.Model]; !exists {
@@ -624,6 +717,187 @@ func validateIOThreadsPolicy(field *k8sfield.Path, spec *v1.VirtualMachineInstan
 	return causes
 }
 
+func validateBoot(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
+	boot := spec.Domain.Boot
+	if boot == nil {
+		return causes
+	}
+	bootField := field.Child("domain", "boot")
+
+	if len(boot.Order) > 0 {
+		causes = append(causes, validateBootDeviceOrder(field, spec)...)
+	} else {
+		causes = append(causes, validateContiguousBootOrder(field, spec)...)
+	}
+
+	if boot.RebootTimeoutSeconds != nil {
+		if spec.Domain.Firmware != nil && spec.Domain.Firmware.Bootloader != nil && spec.Domain.Firmware.Bootloader.EFI != nil {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s is only supported with BIOS", bootField.Child("rebootTimeoutSeconds").String()),
+				Field:   bootField.Child("rebootTimeoutSeconds").String(),
+			})
+		}
+		if *boot.RebootTimeoutSeconds > maxRebootTimeoutSeconds {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s must not be greater than %d", bootField.Child("rebootTimeoutSeconds").String(), maxRebootTimeoutSeconds),
+				Field:   bootField.Child("rebootTimeoutSeconds").String(),
+			})
+		}
+	}
+	return causes
+}
+
+// validateBootDeviceOrder verifies that spec.domain.boot.order references
+// every device at most once, and that it is not mixed with per device boot orders
+func validateBootDeviceOrder(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
+	orderField := field.Child("domain", "boot", "order")
+
+	disks := map[string]bool{}
+	for idx, disk := range spec.Domain.Devices.Disks {
+		disks[disk.Name] = true
+		if disk.BootOrder != nil {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s can't be combined with %s", field.Child("domain", "devices", "disks").Index(idx).Child("bootOrder").String(), orderField.String()),
+				Field:   field.Child("domain", "devices", "disks").Index(idx).Child("bootOrder").String(),
+			})
+		}
+	}
+	interfaces := map[string]*v1.Interface{}
+	for idx, iface := range spec.Domain.Devices.Interfaces {
+		interfaces[iface.Name] = &spec.Domain.Devices.Interfaces[idx]
+		if iface.BootOrder != nil {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s can't be combined with %s", field.Child("domain", "devices", "interfaces").Index(idx).Child("bootOrder").String(), orderField.String()),
+				Field:   field.Child("domain", "devices", "interfaces").Index(idx).Child("bootOrder").String(),
+			})
+		}
+	}
+
+	seenDisks := map[string]bool{}
+	seenInterfaces := map[string]bool{}
+	for idx, device := range spec.Domain.Boot.Order {
+		deviceField := orderField.Index(idx)
+		switch {
+		case (device.Disk == "") == (device.Interface == ""):
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s must reference either a disk or an interface", deviceField.String()),
+				Field:   deviceField.String(),
+			})
+		case device.Disk != "":
+			if !disks[device.Disk] {
+				causes = append(causes, metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: fmt.Sprintf(nameOfTypeNotFoundMessagePattern, deviceField.Child("disk").String(), device.Disk),
+					Field:   deviceField.Child("disk").String(),
+				})
+			} else if seenDisks[device.Disk] {
+				causes = append(causes, metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldValueDuplicate,
+					Message: fmt.Sprintf("Boot order for disk %s is already set by a different entry of %s.", device.Disk, orderField.String()),
+					Field:   deviceField.Child("disk").String(),
+				})
+			}
+			seenDisks[device.Disk] = true
+		default:
+			iface, exists := interfaces[device.Interface]
+			if !exists {
+				causes = append(causes, metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: fmt.Sprintf(nameOfTypeNotFoundMessagePattern, deviceField.Child("interface").String(), device.Interface),
+					Field:   deviceField.Child("interface").String(),
+				})
+			} else if seenInterfaces[device.Interface] {
+				causes = append(causes, metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldValueDuplicate,
+					Message: fmt.Sprintf("Boot order for interface %s is already set by a different entry of %s.", device.Interface, orderField.String()),
+					Field:   deviceField.Child("interface").String(),
+				})
+			} else if iface.Slirp != nil {
+				causes = append(causes, metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: fmt.Sprintf("%s references the slirp interface %s, which can't be booted from", deviceField.Child("interface").String(), device.Interface),
+					Field:   deviceField.Child("interface").String(),
+				})
+			}
+			seenInterfaces[device.Interface] = true
+		}
+	}
+	return causes
+}
+
+// validateContiguousBootOrder verifies that the boot orders of the devices
+// are 1 to n, so that the network boot orders appended by
+// spec.domain.boot.networkBootLast directly follow them. Duplicates are
+// reported by validateBootOrder and validateInterfaceBootOrder.
+func validateContiguousBootOrder(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
+	orders := map[uint]bool{}
+	var highest uint
+	for _, disk := range spec.Domain.Devices.Disks {
+		if disk.BootOrder != nil {
+			orders[*disk.BootOrder] = true
+			if *disk.BootOrder > highest {
+				highest = *disk.BootOrder
+			}
+		}
+	}
+	for _, iface := range spec.Domain.Devices.Interfaces {
+		if iface.BootOrder != nil {
+			orders[*iface.BootOrder] = true
+			if *iface.BootOrder > highest {
+				highest = *iface.BootOrder
+			}
+		}
+	}
+	for order := uint(1); order <= highest; order++ {
+		if !orders[order] {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("The boot orders of the devices must be contiguous when %s is set, no device has the boot order %d.", field.Child("domain", "boot").String(), order),
+				Field:   field.Child("domain", "boot").String(),
+			})
+			break
+		}
+	}
+	return causes
+}
+
+func validateOnCrash(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
+	if spec.Domain.OnCrash == nil {
+		return causes
+	}
+	for _, action := range validCrashActions {
+		if *spec.Domain.OnCrash == action {
+			return causes
+		}
+	}
+	causes = append(causes, metav1.StatusCause{
+		Type:    metav1.CauseTypeFieldValueNotSupported,
+		Message: fmt.Sprintf("Invalid crash action (%s), must be one of %v", *spec.Domain.OnCrash, validCrashActions),
+		Field:   field.Child("domain", "onCrash").String(),
+	})
+	return causes
+}
+
+func validateSELinuxLauncherType(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
+	if spec.Domain.SELinux == nil || spec.Domain.SELinux.LauncherType == "" {
+		return causes
+	}
+	selinuxType := spec.Domain.SELinux.LauncherType
+	if len(selinuxType) > maxSELinuxTypeLength || !validSELinuxTypeRegex.MatchString(selinuxType) {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("Invalid SELinux type (%s): it must be at most %d characters long, start and end with an alphanumeric character and only contain alphanumeric characters, underscores (_) or dots (.)", selinuxType, maxSELinuxTypeLength),
+			Field:   field.Child("domain", "selinux", "launcherType").String(),
+		})
+	}
+	return causes
+}
+
 func validateReadinessProbe(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
 	if spec.ReadinessProbe != nil {
 		if spec.ReadinessProbe.HTTPGet != nil && spec.ReadinessProbe.TCPSocket != nil {
@@ -670,6 +944,125 @@ func validateLivenessProbe(field *k8sfield.Path, spec *v1.VirtualMachineInstance
 	return causes
 }
 
+func validateLivenessProbeFailureAction(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
+	switch spec.LivenessProbeFailureAction {
+	case "", v1.LivenessProbeFailureActionRestart:
+	case v1.LivenessProbeFailureActionMigrate:
+		if spec.LivenessProbe == nil {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueRequired,
+				Message: fmt.Sprintf("%s must be set if %s is %s", field.Child("livenessProbe").String(), field.Child("livenessProbeFailureAction").String(), v1.LivenessProbeFailureActionMigrate),
+				Field:   field.Child("livenessProbe").String(),
+			})
+		}
+		if spec.ReadinessProbe != nil {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueNotSupported,
+				Message: fmt.Sprintf("%s is not supported if %s is %s", field.Child("readinessProbe").String(), field.Child("livenessProbeFailureAction").String(), v1.LivenessProbeFailureActionMigrate),
+				Field:   field.Child("readinessProbe").String(),
+			})
+		}
+	default:
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueNotSupported,
+			Message: fmt.Sprintf("%s must be one of %s or %s", field.Child("livenessProbeFailureAction").String(), v1.LivenessProbeFailureActionRestart, v1.LivenessProbeFailureActionMigrate),
+			Field:   field.Child("livenessProbeFailureAction").String(),
+		})
+	}
+	return causes
+}
+
+func validateStartupProbe(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
+	probe := spec.StartupProbe
+	if probe == nil {
+		return causes
+	}
+	probeField := field.Child("startupProbe")
+	if probe.HTTPGet != nil || probe.TCPSocket != nil {
+		causes = append(causes, metav1.StatusCause{
+			Type: metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s only supports %s and %s",
+				probeField.String(),
+				probeField.Child("exec").String(),
+				probeField.Child("guestAgentPing").String(),
+			),
+			Field: probeField.String(),
+		})
+	}
+	if probe.Exec != nil && probe.GuestAgentPing != nil {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s must have exactly one probe type set", probeField.String()),
+			Field:   probeField.String(),
+		})
+	} else if probe.Exec == nil && probe.GuestAgentPing == nil {
+		causes = append(causes, metav1.StatusCause{
+			Type: metav1.CauseTypeFieldValueRequired,
+			Message: fmt.Sprintf("either %s or %s must be set if a %s is specified",
+				probeField.Child("exec").String(),
+				probeField.Child("guestAgentPing").String(),
+				probeField.String(),
+			),
+			Field: probeField.String(),
+		})
+	}
+	if probe.Exec != nil && len(probe.Exec.Command) == 0 {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueRequired,
+			Message: fmt.Sprintf("%s must not be empty", probeField.Child("exec", "command").String()),
+			Field:   probeField.Child("exec", "command").String(),
+		})
+	}
+	if probe.SuccessThreshold > 1 {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s must be 1 for a startup probe", probeField.Child("successThreshold").String()),
+			Field:   probeField.Child("successThreshold").String(),
+		})
+	}
+	return causes
+}
+
+func validateFreezeHooks(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
+	hooks := spec.FreezeHooks
+	if hooks == nil {
+		return causes
+	}
+	causes = append(causes, validateFreezeHook(field.Child("freezeHooks", "preFreeze"), hooks.PreFreeze)...)
+	causes = append(causes, validateFreezeHook(field.Child("freezeHooks", "postThaw"), hooks.PostThaw)...)
+	return causes
+}
+
+func validateFreezeHook(field *k8sfield.Path, hook *v1.FreezeHook) (causes []metav1.StatusCause) {
+	if hook == nil {
+		return causes
+	}
+	if len(hook.Command) == 0 {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueRequired,
+			Message: fmt.Sprintf("%s must not be empty", field.Child("command").String()),
+			Field:   field.Child("command").String(),
+		})
+	}
+	if hook.TimeoutSeconds < 0 || hook.TimeoutSeconds > maxFreezeHookTimeoutSeconds {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s must be between 1 and %d seconds", field.Child("timeoutSeconds").String(), maxFreezeHookTimeoutSeconds),
+			Field:   field.Child("timeoutSeconds").String(),
+		})
+	}
+	switch hook.FailurePolicy {
+	case "", v1.FreezeHookFailurePolicyAbort, v1.FreezeHookFailurePolicyContinue:
+	default:
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueNotSupported,
+			Message: fmt.Sprintf("%s must be one of %s or %s", field.Child("failurePolicy").String(), v1.FreezeHookFailurePolicyAbort, v1.FreezeHookFailurePolicyContinue),
+			Field:   field.Child("failurePolicy").String(),
+		})
+	}
+	return causes
+}
+
 func appendStatusCauseForReadinessProbeNotAllowedWithNoPodNetworkPresent(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec, causes []metav1.StatusCause) []metav1.StatusCause {
 	if spec.ReadinessProbe != nil {
 		causes = append(causes, metav1.StatusCause{
@@ -693,6 +1086,10 @@ func appendStatusCauseForLivenessProbeNotAllowedWithNoPodNetworkPresent(field *k
 }
 
 func validateLiveMigration(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec, config *virtconfig.ClusterConfig) (causes []metav1.StatusCause) {
+	if spec.EvictionStrategy != nil && *spec.EvictionStrategy == v1.EvictionStrategyProtectUntilDrained {
+		// the VMI is shut down on drains, live migration is not involved
+		return causes
+	}
 	if !config.LiveMigrationEnabled() && spec.EvictionStrategy != nil {
 		causes = append(causes, metav1.StatusCause{
 			Type:    metav1.CauseTypeFieldValueInvalid,
@@ -734,6 +1131,50 @@ func validateFilesystemsWithVirtIOFSEnabled(field *k8sfield.Path, spec *v1.Virtu
 	return causes
 }
 
+// validateFilesystemsVirtiofs verifies the cache and sandbox modes requested
+// for virtiofsd are ones libvirt can pass on to it.
+func validateFilesystemsVirtiofs(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
+	supportedCaches := []v1.VirtiofsCache{v1.VirtiofsCacheNone, v1.VirtiofsCacheAuto, v1.VirtiofsCacheAlways}
+	supportedSandboxes := []v1.VirtiofsSandbox{v1.VirtiofsSandboxNamespace, v1.VirtiofsSandboxChroot}
+	for idx, fs := range spec.Domain.Devices.Filesystems {
+		if fs.Virtiofs == nil {
+			continue
+		}
+		virtiofsField := field.Child("domain", "devices", "filesystems").Index(idx).Child("virtiofs")
+		if cache := fs.Virtiofs.Cache; cache != "" {
+			isSupported := false
+			for _, supported := range supportedCaches {
+				if cache == supported {
+					isSupported = true
+				}
+			}
+			if !isSupported {
+				causes = append(causes, metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldValueNotSupported,
+					Message: fmt.Sprintf("%s is set with an unrecognized cache mode %s, must be one of: %v", virtiofsField.String(), cache, supportedCaches),
+					Field:   virtiofsField.Child("cache").String(),
+				})
+			}
+		}
+		if sandbox := fs.Virtiofs.Sandbox; sandbox != "" {
+			isSupported := false
+			for _, supported := range supportedSandboxes {
+				if sandbox == supported {
+					isSupported = true
+				}
+			}
+			if !isSupported {
+				causes = append(causes, metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldValueNotSupported,
+					Message: fmt.Sprintf("%s is set with an unrecognized sandbox mode %s, must be one of: %v", virtiofsField.String(), sandbox, supportedSandboxes),
+					Field:   virtiofsField.Child("sandbox").String(),
+				})
+			}
+		}
+	}
+	return causes
+}
+
 func validateHostDevicesWithPassthroughEnabled(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec, config *virtconfig.ClusterConfig) (causes []metav1.StatusCause) {
 	if spec.Domain.Devices.HostDevices != nil && !config.HostDevicesPassthroughEnabled() {
 		causes = append(causes, metav1.StatusCause{
@@ -777,6 +1218,97 @@ func validatePermittedHostDevices(field *k8sfield.Path, spec *v1.VirtualMachineI
 	return causes
 }
 
+func validateHostDevicesPciAddresses(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
+	for idx, hostDev := range spec.Domain.Devices.HostDevices {
+		if hostDev.PciAddress == "" {
+			continue
+		}
+		if _, err := hwutil.ParsePciAddress(hostDev.PciAddress); err != nil {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("host device %s has malformed PCI address (%s).", field.Child("domain", "devices", "hostDevices").Index(idx).Child("name").String(), hostDev.PciAddress),
+				Field:   field.Child("domain", "devices", "hostDevices").Index(idx).Child("pciAddress").String(),
+			})
+		}
+	}
+	return causes
+}
+
+// validateHostDevicesROMs requires every ROM override to reference exactly one
+// named config map or secret.
+func validateHostDevicesROMs(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
+	for idx, hostDev := range spec.Domain.Devices.HostDevices {
+		rom := hostDev.ROM
+		if rom == nil {
+			continue
+		}
+		romField := field.Child("domain", "devices", "hostDevices").Index(idx).Child("rom")
+		if (rom.ConfigMap == nil) == (rom.Secret == nil) {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s must reference exactly one of configMap or secret", romField.String()),
+				Field:   romField.String(),
+			})
+			continue
+		}
+		if rom.ConfigMap != nil && rom.ConfigMap.Name == "" {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueRequired,
+				Message: fmt.Sprintf("%s must not be empty", romField.Child("configMap", "name").String()),
+				Field:   romField.Child("configMap", "name").String(),
+			})
+		}
+		if rom.Secret != nil && rom.Secret.Name == "" {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueRequired,
+				Message: fmt.Sprintf("%s must not be empty", romField.Child("secret", "name").String()),
+				Field:   romField.Child("secret", "name").String(),
+			})
+		}
+	}
+	return causes
+}
+
+// validateGuestPciAddressCollisions rejects interfaces, disks and host devices
+// requesting the same guest PCI address. Malformed addresses are reported by the
+// per device validations and skipped here.
+func validateGuestPciAddressCollisions(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
+	requestedBy := map[string]string{}
+	checkAddress := func(pciAddress string, devField *k8sfield.Path) {
+		if pciAddress == "" {
+			return
+		}
+		dbsfFields, err := hwutil.ParsePciAddress(pciAddress)
+		if err != nil {
+			return
+		}
+		address := strings.ToLower(strings.Join(dbsfFields, ":"))
+		if other, exists := requestedBy[address]; exists {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueDuplicate,
+				Message: fmt.Sprintf("%s requests the PCI address %s which is already requested by %s.", devField.String(), pciAddress, other),
+				Field:   devField.Child("pciAddress").String(),
+			})
+			return
+		}
+		requestedBy[address] = devField.String()
+	}
+
+	devicesField := field.Child("domain", "devices")
+	for idx, iface := range spec.Domain.Devices.Interfaces {
+		checkAddress(iface.PciAddress, devicesField.Child("interfaces").Index(idx))
+	}
+	for idx, disk := range spec.Domain.Devices.Disks {
+		if disk.Disk != nil {
+			checkAddress(disk.Disk.PciAddress, devicesField.Child("disks").Index(idx).Child("disk"))
+		}
+	}
+	for idx, hostDev := range spec.Domain.Devices.HostDevices {
+		checkAddress(hostDev.PciAddress, devicesField.Child("hostDevices").Index(idx))
+	}
+	return causes
+}
+
 func appendStatusCauseForPodNetworkDefinedWithMultusDefaultNetworkDefined(field *k8sfield.Path, causes []metav1.StatusCause) []metav1.StatusCause {
 	return append(causes, metav1.StatusCause{
 		Type:    metav1.CauseTypeFieldValueInvalid,
@@ -1124,6 +1656,70 @@ func validateFirmwareSerial(field *k8sfield.Path, spec *v1.VirtualMachineInstanc
 	return causes
 }
 
+func validateClock(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
+	if spec.Domain.Clock == nil || spec.Domain.Clock.Timer == nil {
+		return causes
+	}
+	timer := spec.Domain.Clock.Timer
+	timerField := field.Child("domain", "clock", "timer")
+	if timer.RTC != nil {
+		causes = append(causes, validateTimer(timerField.Child("rtc"), timer.RTC.Enabled, string(timer.RTC.TickPolicy), timer.RTC.Catchup,
+			string(v1.RTCTickPolicyDelay), string(v1.RTCTickPolicyCatchup))...)
+		if timer.RTC.Track != "" && timer.RTC.Track != v1.TrackGuest && timer.RTC.Track != v1.TrackWall {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueNotSupported,
+				Message: fmt.Sprintf("%s is set with an unrecognized track %s, must be one of: %v", timerField.Child("rtc").String(), timer.RTC.Track, []v1.RTCTimerTrack{v1.TrackGuest, v1.TrackWall}),
+				Field:   timerField.Child("rtc", "track").String(),
+			})
+		}
+	}
+	if timer.PIT != nil {
+		causes = append(causes, validateTimer(timerField.Child("pit"), timer.PIT.Enabled, string(timer.PIT.TickPolicy), timer.PIT.Catchup,
+			string(v1.PITTickPolicyDelay), string(v1.PITTickPolicyCatchup), string(v1.PITTickPolicyDiscard))...)
+	}
+	if timer.HPET != nil {
+		causes = append(causes, validateTimer(timerField.Child("hpet"), timer.HPET.Enabled, string(timer.HPET.TickPolicy), timer.HPET.Catchup,
+			string(v1.HPETTickPolicyDelay), string(v1.HPETTickPolicyCatchup), string(v1.HPETTickPolicyMerge), string(v1.HPETTickPolicyDiscard))...)
+	}
+	return causes
+}
+
+// validateTimer verifies the tick policy of a timer is one of the supported
+// ones, and that the catchup settings come with the catchup policy on an
+// enabled timer.
+func validateTimer(field *k8sfield.Path, enabled *bool, tickPolicy string, catchup *v1.TimerCatchup, supported ...string) (causes []metav1.StatusCause) {
+	if tickPolicy != "" {
+		isSupported := false
+		for _, policy := range supported {
+			if policy == tickPolicy {
+				isSupported = true
+			}
+		}
+		if !isSupported {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueNotSupported,
+				Message: fmt.Sprintf("%s is set with an unrecognized tick policy %s, must be one of: %v", field.String(), tickPolicy, supported),
+				Field:   field.Child("tickPolicy").String(),
+			})
+		}
+	}
+	if catchup != nil && tickPolicy != "catchup" {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s can only be set with the catchup tick policy", field.Child("catchup").String()),
+			Field:   field.Child("catchup").String(),
+		})
+	}
+	if catchup != nil && enabled != nil && !*enabled {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s can't be set on a disabled timer", field.Child("catchup").String()),
+			Field:   field.Child("catchup").String(),
+		})
+	}
+	return causes
+}
+
 func validateEmulatedMachine(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec, config *virtconfig.ClusterConfig) (causes []metav1.StatusCause) {
 	if len(spec.Domain.Machine.Type) > 0 {
 		machine := spec.Domain.Machine.Type
@@ -1169,6 +1765,108 @@ func validateGuestMemoryLimit(field *k8sfield.Path, spec *v1.VirtualMachineInsta
 	return causes
 }
 
+func validateMemoryOvercommit(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec, config *virtconfig.ClusterConfig) (causes []metav1.StatusCause) {
+	if spec.Domain.Memory == nil || spec.Domain.Memory.OvercommitPercent == nil {
+		return causes
+	}
+	memory := spec.Domain.Memory
+	overcommitField := field.Child("domain", "memory", "overcommitPercent")
+	overcommit := int(*memory.OvercommitPercent)
+
+	if maxOvercommit := config.GetMaxMemoryOvercommit(); overcommit < 100 || overcommit > maxOvercommit {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s '%d' must be between 100 and the maximum memory overcommit of the cluster '%d'", overcommitField.String(), overcommit, maxOvercommit),
+			Field:   overcommitField.String(),
+		})
+	}
+	if memory.Guest == nil {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueRequired,
+			Message: fmt.Sprintf("%s requires %s to be set", overcommitField.String(), field.Child("domain", "memory", "guest").String()),
+			Field:   overcommitField.String(),
+		})
+	} else if request, ok := spec.Domain.Resources.Requests[k8sv1.ResourceMemory]; ok && overcommit > 0 {
+		if expected := virtconfig.OvercommittedMemoryRequest(*memory.Guest, overcommit); request.Cmp(expected) != 0 {
+			causes = append(causes, metav1.StatusCause{
+				Type: metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s '%s' must be '%s' or unset with %s '%d'",
+					field.Child("domain", "resources", "requests", "memory").String(),
+					request.String(),
+					expected.String(),
+					overcommitField.String(),
+					overcommit,
+				),
+				Field: field.Child("domain", "resources", "requests", "memory").String(),
+			})
+		}
+	}
+	if memory.Hugepages != nil {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s is not allowed with %s", overcommitField.String(), field.Child("domain", "memory", "hugepages").String()),
+			Field:   overcommitField.String(),
+		})
+	}
+	if memory.Locked {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s is not allowed with %s", overcommitField.String(), field.Child("domain", "memory", "locked").String()),
+			Field:   overcommitField.String(),
+		})
+	}
+	if spec.Domain.CPU != nil && spec.Domain.CPU.DedicatedCPUPlacement {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s is not allowed with %s", overcommitField.String(), field.Child("domain", "cpu", "dedicatedCpuPlacement").String()),
+			Field:   overcommitField.String(),
+		})
+	}
+	return causes
+}
+
+func validateBalloonFloor(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
+	if spec.Domain.Memory == nil || spec.Domain.Memory.BalloonFloor == nil {
+		return causes
+	}
+	floor := spec.Domain.Memory.BalloonFloor
+	guest := spec.Domain.Resources.Requests.Memory()
+	if spec.Domain.Memory.Guest != nil {
+		guest = spec.Domain.Memory.Guest
+	} else if spec.Domain.Resources.Limits.Memory().Value() != 0 {
+		guest = spec.Domain.Resources.Limits.Memory()
+	}
+
+	if floor.Value() <= 0 {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s '%s' must be greater than zero", field.Child("domain", "memory", "balloonFloor").String(), floor),
+			Field:   field.Child("domain", "memory", "balloonFloor").String(),
+		})
+	} else if floor.Cmp(*guest) > 0 {
+		causes = append(causes, metav1.StatusCause{
+			Type: metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s '%s' must be equal to or less than the guest memory '%s'",
+				field.Child("domain", "memory", "balloonFloor").String(),
+				floor,
+				guest,
+			),
+			Field: field.Child("domain", "memory", "balloonFloor").String(),
+		})
+	}
+	if spec.Domain.Devices.AutoattachMemBalloon != nil && !*spec.Domain.Devices.AutoattachMemBalloon {
+		causes = append(causes, metav1.StatusCause{
+			Type: metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s requires the memory balloon, %s must not be false",
+				field.Child("domain", "memory", "balloonFloor").String(),
+				field.Child("domain", "devices", "autoattachMemBalloon").String(),
+			),
+			Field: field.Child("domain", "memory", "balloonFloor").String(),
+		})
+	}
+	return causes
+}
+
 func validateHugepagesMemoryRequests(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
 	if spec.Domain.Memory != nil && spec.Domain.Memory.Hugepages != nil {
 		hugepagesSize, err := resource.ParseQuantity(spec.Domain.Memory.Hugepages.PageSize)
@@ -1207,11 +1905,98 @@ func validateHugepagesMemoryRequests(field *k8sfield.Path, spec *v1.VirtualMachi
 					Field: field.Child("domain", "resources", "requests", "memory").String(),
 				})
 			}
+			causes = append(causes, validatePerNUMANodeHugepages(field.Child("domain", "memory", "hugepages", "perNUMANode"), spec, hugepagesSize)...)
 		}
 	}
 	return causes
 }
 
+// validatePerNUMANodeHugepages verifies that the guest NUMA nodes are numbered
+// from 0 without gaps, that each of them gets a multiple of the page size and
+// that together they cover exactly the guest memory.
+func validatePerNUMANodeHugepages(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec, hugepagesSize resource.Quantity) (causes []metav1.StatusCause) {
+	nodes := spec.Domain.Memory.Hugepages.PerNUMANode
+	if len(nodes) == 0 {
+		return causes
+	}
+
+	guestNodes := map[uint32]bool{}
+	var nodesMemory int64
+	for i, node := range nodes {
+		if guestNodes[node.GuestNode] {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueDuplicate,
+				Message: fmt.Sprintf("%s guest NUMA node %d is defined more than once", field.Index(i).Child("guestNode").String(), node.GuestNode),
+				Field:   field.Index(i).Child("guestNode").String(),
+			})
+		} else if node.GuestNode >= uint32(len(nodes)) {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s guest NUMA nodes must be numbered from 0 to %d without gaps", field.Index(i).Child("guestNode").String(), len(nodes)-1),
+				Field:   field.Index(i).Child("guestNode").String(),
+			})
+		}
+		guestNodes[node.GuestNode] = true
+
+		memory := node.Memory.Value()
+		if memory <= 0 || memory%hugepagesSize.Value() != 0 {
+			causes = append(causes, metav1.StatusCause{
+				Type: metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s '%s' must be a positive multiple of the page size '%s'",
+					field.Index(i).Child("memory").String(),
+					node.Memory.String(),
+					spec.Domain.Memory.Hugepages.PageSize,
+				),
+				Field: field.Index(i).Child("memory").String(),
+			})
+		}
+		nodesMemory += memory
+	}
+
+	guestMemory := spec.Domain.Resources.Requests.Memory()
+	if spec.Domain.Memory.Guest != nil {
+		guestMemory = spec.Domain.Memory.Guest
+	} else if limit, ok := spec.Domain.Resources.Limits[k8sv1.ResourceMemory]; ok {
+		guestMemory = &limit
+	}
+	if nodesMemory != guestMemory.Value() {
+		causes = append(causes, metav1.StatusCause{
+			Type: metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s the memory of the guest NUMA nodes must add up to the guest memory '%s'",
+				field.String(),
+				guestMemory.String(),
+			),
+			Field: field.String(),
+		})
+	}
+
+	if vcpus := requestedVCPUs(spec); vcpus < int64(len(nodes)) {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s %d guest NUMA nodes need at least as many vCPUs, got %d", field.String(), len(nodes), vcpus),
+			Field:   field.String(),
+		})
+	}
+	return causes
+}
+
+// requestedVCPUs returns the number of vCPUs the guest will get, taking the
+// CPU topology first and the CPU resources second, like the domain conversion.
+func requestedVCPUs(spec *v1.VirtualMachineInstanceSpec) int64 {
+	if spec.Domain.CPU != nil {
+		if vcpus := hwutil.GetNumberOfVCPUs(spec.Domain.CPU); vcpus > 0 {
+			return vcpus
+		}
+	}
+	if limit, ok := spec.Domain.Resources.Limits[k8sv1.ResourceCPU]; ok && limit.Value() > 0 {
+		return limit.Value()
+	}
+	if request, ok := spec.Domain.Resources.Requests[k8sv1.ResourceCPU]; ok && request.Value() > 0 {
+		return request.Value()
+	}
+	return 1
+}
+
 func validateMemoryLimitsNegativeOrNull(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
 	if spec.Domain.Resources.Limits.Memory().Value() < 0 {
 		causes = append(causes, metav1.StatusCause{
@@ -1270,6 +2055,23 @@ func validateSubdomainDNSSubdomainRules(field *k8sfield.Path, spec *v1.VirtualMa
 	return causes
 }
 
+// validateWorkloadClass makes sure the workload class can be used as the
+// value of the label the pods of the class are selected by.
+func validateWorkloadClass(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
+	if spec.WorkloadClass != "" {
+		errors := validation.IsDNS1123Label(spec.WorkloadClass)
+		if len(errors) != 0 {
+			causes = append(causes, metav1.StatusCause{
+				Type: metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s does not conform to the kubernetes DNS_LABEL rules : %s",
+					field.Child("workloadClass").String(), strings.Join(errors, ", ")),
+				Field: field.Child("workloadClass").String(),
+			})
+		}
+	}
+	return causes
+}
+
 func validateHostNameNotConformingToDNSLabelRules(field *k8sfield.Path, spec *v1.VirtualMachineInstanceSpec) (causes []metav1.StatusCause) {
 	if spec.Hostname != "" {
 		errors := validation.IsDNS1123Label(spec.Hostname)
@@ -1363,6 +2165,30 @@ func ValidateVirtualMachineInstanceMetadata(field *k8sfield.Path, metadata *meta
 		})
 	}
 
+	// Validate the drain grace period against the cluster maximum
+	if value, exists := annotations[v1.DrainGracePeriodSecondsAnnotation]; exists {
+		if err := services.ValidateDrainGracePeriodSeconds(value, config.GetMaxDrainGracePeriodSeconds()); err != nil {
+			causes = append(causes, metav1.StatusCause{
+				Type: metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("invalid entry %s: %v",
+					field.Child("annotations", v1.DrainGracePeriodSecondsAnnotation).String(), err),
+				Field: field.Child("annotations").String(),
+			})
+		}
+	}
+
+	// Validate the qemu log items requested through the annotation
+	if value, exists := annotations[v1.QEMULogFiltersAnnotation]; exists {
+		if _, err := converter.ParseQEMULogFilters(value); err != nil {
+			causes = append(causes, metav1.StatusCause{
+				Type: metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("invalid entry %s: %v",
+					field.Child("annotations", v1.QEMULogFiltersAnnotation).String(), err),
+				Field: field.Child("annotations").String(),
+			})
+		}
+	}
+
 	return causes
 }
 
@@ -1485,6 +2311,46 @@ func validateBootloader(field *k8sfield.Path, bootloader *v1.Bootloader) []metav
 		})
 	}
 
+	if bootloader != nil && bootloader.EFI != nil {
+		causes = append(causes, validateSecureBootCertificates(field.Child("efi"), bootloader.EFI)...)
+	}
+
+	return causes
+}
+
+func validateSecureBootCertificates(field *k8sfield.Path, efi *v1.EFI) []metav1.StatusCause {
+	var causes []metav1.StatusCause
+
+	if efi.SecureBootCertificates == nil {
+		return causes
+	}
+	certificatesField := field.Child("secureBootCertificates")
+
+	if efi.SecureBoot != nil && !*efi.SecureBoot {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s requires %s to be enabled", certificatesField.String(), field.Child("secureBoot").String()),
+			Field:   certificatesField.String(),
+		})
+	}
+
+	for _, store := range []struct {
+		name string
+		ref  *k8sv1.LocalObjectReference
+	}{
+		{"pk", efi.SecureBootCertificates.PK},
+		{"kek", efi.SecureBootCertificates.KEK},
+		{"db", efi.SecureBootCertificates.DB},
+	} {
+		if store.ref == nil || store.ref.Name == "" {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueRequired,
+				Message: fmt.Sprintf("%s is a required field", certificatesField.Child(store.name, "name").String()),
+				Field:   certificatesField.Child(store.name, "name").String(),
+			})
+		}
+	}
+
 	return causes
 }
 
@@ -1493,6 +2359,14 @@ func validateFirmware(field *k8sfield.Path, firmware *v1.Firmware) []metav1.Stat
 
 	if firmware != nil {
 		causes = append(causes, validateBootloader(field.Child("bootloader"), firmware.Bootloader)...)
+
+		if firmware.SMBiosSecretRef != nil && firmware.SMBiosSecretRef.Name == "" {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s is a required field", field.Child("smbiosSecretRef", "name").String()),
+				Field:   field.Child("smbiosSecretRef", "name").String(),
+			})
+		}
 	}
 
 	return causes
@@ -1932,9 +2806,33 @@ func getNumberOfPodInterfaces(spec *v1.VirtualMachineInstanceSpec) int {
 	return nPodInterfaces
 }
 
+var diskWWNRegex = regexp.MustCompile(`^[0-9A-Fa-f]{16}$`)
+
+// validateDiskWWN verifies the WWN of a disk is made of 16 hex digits, and is
+// set on a device QEMU can report it for: a disk or a cdrom on the scsi bus.
+func validateDiskWWN(field *k8sfield.Path, disk v1.Disk, diskType string, bus string) (causes []metav1.StatusCause) {
+	if !diskWWNRegex.MatchString(disk.WWN) {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s must be made up of 16 hexadecimal digits, if specified", field.Child("wwn").String()),
+			Field:   field.Child("wwn").String(),
+		})
+	}
+	if (diskType != "disk" && diskType != "cdrom") || bus != "scsi" {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueNotSupported,
+			Message: fmt.Sprintf("%s is only supported for disks and cdroms on the scsi bus", field.Child("wwn").String()),
+			Field:   field.Child("wwn").String(),
+		})
+	}
+	return causes
+}
+
 func validateDisks(field *k8sfield.Path, disks []v1.Disk) []metav1.StatusCause {
 	var causes []metav1.StatusCause
 	nameMap := make(map[string]int)
+	serialMap := make(map[string]int)
+	wwnMap := make(map[string]int)
 
 	if len(disks) > arrayLenMax {
 		causes = append(causes, metav1.StatusCause{
@@ -2096,6 +2994,34 @@ func validateDisks(field *k8sfield.Path, disks []v1.Disk) []metav1.StatusCause {
 			})
 		}
 
+		// Verify serial number is unique, if provided
+		if disk.Serial != "" {
+			if otherIdx, exists := serialMap[disk.Serial]; exists {
+				causes = append(causes, metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldValueDuplicate,
+					Message: fmt.Sprintf("%s and %s must not have the same serial %s", field.Index(idx).String(), field.Index(otherIdx).String(), disk.Serial),
+					Field:   field.Index(idx).Child("serial").String(),
+				})
+			} else {
+				serialMap[disk.Serial] = idx
+			}
+		}
+
+		if disk.WWN != "" {
+			causes = append(causes, validateDiskWWN(field.Index(idx), disk, diskType, bus)...)
+			// WWNs are hex numbers, compare them regardless of the case
+			wwn := strings.ToLower(disk.WWN)
+			if otherIdx, exists := wwnMap[wwn]; exists {
+				causes = append(causes, metav1.StatusCause{
+					Type:    metav1.CauseTypeFieldValueDuplicate,
+					Message: fmt.Sprintf("%s and %s must not have the same WWN %s", field.Index(idx).String(), field.Index(otherIdx).String(), disk.WWN),
+					Field:   field.Index(idx).Child("wwn").String(),
+				})
+			} else {
+				wwnMap[wwn] = idx
+			}
+		}
+
 		// Verify if cache mode is valid
 		if disk.Cache != "" && disk.Cache != v1.CacheNone && disk.Cache != v1.CacheWriteThrough {
 			causes = append(causes, metav1.StatusCause{
@@ -2125,6 +3051,98 @@ func validateDisks(field *k8sfield.Path, disks []v1.Disk) []metav1.StatusCause {
 				Field:   field.Child("domain", "devices", "disks").Index(idx).Child("name").String(),
 			})
 		}
+
+		if disk.IOThrottleGroup != nil {
+			causes = append(causes, validateIOThrottleGroup(field.Index(idx).Child("ioThrottleGroup"), disk.IOThrottleGroup)...)
+		}
+	}
+
+	causes = append(causes, validateIOThrottleGroupMembers(field, disks)...)
+
+	return causes
+}
+
+func validateIOThrottleGroup(field *k8sfield.Path, group *v1.IOThrottleGroup) []metav1.StatusCause {
+	var causes []metav1.StatusCause
+
+	if group.Name == "" {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueRequired,
+			Message: fmt.Sprintf("%s must not be empty", field.Child("name").String()),
+			Field:   field.Child("name").String(),
+		})
+	}
+
+	limits := map[string]int64{
+		"totalIOPS":        group.TotalIOPS,
+		"readIOPS":         group.ReadIOPS,
+		"writeIOPS":        group.WriteIOPS,
+		"totalBytesPerSec": group.TotalBytesPerSec,
+		"readBytesPerSec":  group.ReadBytesPerSec,
+		"writeBytesPerSec": group.WriteBytesPerSec,
+	}
+	limited := false
+	for _, name := range []string{"totalIOPS", "readIOPS", "writeIOPS", "totalBytesPerSec", "readBytesPerSec", "writeBytesPerSec"} {
+		if limits[name] < 0 {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s must not be negative", field.Child(name).String()),
+				Field:   field.Child(name).String(),
+			})
+		}
+		if limits[name] != 0 {
+			limited = true
+		}
+	}
+	if !limited {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueRequired,
+			Message: fmt.Sprintf("%s must set at least one limit", field.String()),
+			Field:   field.String(),
+		})
+	}
+
+	// libvirt rejects a total limit combined with a read or write one
+	if group.TotalIOPS != 0 && (group.ReadIOPS != 0 || group.WriteIOPS != 0) {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s can't be combined with readIOPS or writeIOPS", field.Child("totalIOPS").String()),
+			Field:   field.Child("totalIOPS").String(),
+		})
+	}
+	if group.TotalBytesPerSec != 0 && (group.ReadBytesPerSec != 0 || group.WriteBytesPerSec != 0) {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s can't be combined with readBytesPerSec or writeBytesPerSec", field.Child("totalBytesPerSec").String()),
+			Field:   field.Child("totalBytesPerSec").String(),
+		})
+	}
+
+	return causes
+}
+
+// validateIOThrottleGroupMembers ensures that all disks of a throttle group set
+// the same limits, since the group shares a single budget.
+func validateIOThrottleGroupMembers(field *k8sfield.Path, disks []v1.Disk) []metav1.StatusCause {
+	var causes []metav1.StatusCause
+	groupIdx := make(map[string]int)
+
+	for idx, disk := range disks {
+		if disk.IOThrottleGroup == nil {
+			continue
+		}
+		otherIdx, exists := groupIdx[disk.IOThrottleGroup.Name]
+		if !exists {
+			groupIdx[disk.IOThrottleGroup.Name] = idx
+			continue
+		}
+		if *disks[otherIdx].IOThrottleGroup != *disk.IOThrottleGroup {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldValueInvalid,
+				Message: fmt.Sprintf("%s and %s are in the I/O throttle group %s and must set the same limits", field.Index(idx).String(), field.Index(otherIdx).String(), disk.IOThrottleGroup.Name),
+				Field:   field.Index(idx).Child("ioThrottleGroup").String(),
+			})
+		}
 	}
 
 	return causes
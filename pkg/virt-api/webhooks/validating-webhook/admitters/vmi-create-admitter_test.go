@@ -151,8 +151,17 @@ var _ = Describe("Validating VMICreate Admitter", func() {
 			Expect(resp).To(BeEmpty())
 		},
 			table.Entry("migration policy to be set", v1.EvictionStrategyLiveMigrate),
+			table.Entry("protect until drained policy to be set", v1.EvictionStrategyProtectUntilDrained),
 		)
 
+		It("should allow protecting VMIs until drained if the feature gate is disabled", func() {
+			disableFeatureGates()
+			policy := v1.EvictionStrategyProtectUntilDrained
+			vmi.Spec.EvictionStrategy = &policy
+			resp := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			Expect(resp).To(BeEmpty())
+		})
+
 		It("should block setting eviction policies if the feature gate is disabled", func() {
 			disableFeatureGates()
 			vmi.Spec.EvictionStrategy = &policy
@@ -260,6 +269,96 @@ var _ = Describe("Validating VMICreate Admitter", func() {
 			resp := vmiCreateAdmitter.Admit(ar)
 			Expect(resp.Allowed).To(BeTrue())
 		})
+		It("should accept guest agent based startup probes without Pod Network", func() {
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.Spec.StartupProbe = &v1.Probe{
+				Handler: v1.Handler{
+					Exec: &k8sv1.ExecAction{Command: []string{"systemctl", "is-active", "sshd"}},
+				},
+			}
+			Expect(ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)).To(BeEmpty())
+
+			vmi.Spec.StartupProbe = &v1.Probe{Handler: v1.Handler{GuestAgentPing: &v1.GuestAgentPing{}}}
+			Expect(ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)).To(BeEmpty())
+		})
+		table.DescribeTable("should reject startup probes", func(probe *v1.Probe, expectedMessages ...string) {
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.Spec.StartupProbe = probe
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			messages := []string{}
+			for _, cause := range causes {
+				messages = append(messages, cause.Message)
+			}
+			Expect(messages).To(Equal(expectedMessages))
+		},
+			table.Entry("without probe action", &v1.Probe{InitialDelaySeconds: 2},
+				"either fake.startupProbe.exec or fake.startupProbe.guestAgentPing must be set if a fake.startupProbe is specified"),
+			table.Entry("with more than one probe action", &v1.Probe{Handler: v1.Handler{
+				Exec:           &k8sv1.ExecAction{Command: []string{"true"}},
+				GuestAgentPing: &v1.GuestAgentPing{},
+			}}, "fake.startupProbe must have exactly one probe type set"),
+			table.Entry("with a network probe action", &v1.Probe{Handler: v1.Handler{
+				HTTPGet: &k8sv1.HTTPGetAction{Host: "test", Port: intstr.Parse("80")},
+			}},
+				"fake.startupProbe only supports fake.startupProbe.exec and fake.startupProbe.guestAgentPing",
+				"either fake.startupProbe.exec or fake.startupProbe.guestAgentPing must be set if a fake.startupProbe is specified"),
+			table.Entry("with an empty command", &v1.Probe{Handler: v1.Handler{Exec: &k8sv1.ExecAction{}}},
+				"fake.startupProbe.exec.command must not be empty"),
+			table.Entry("with a success threshold above 1", &v1.Probe{SuccessThreshold: 2, Handler: v1.Handler{GuestAgentPing: &v1.GuestAgentPing{}}},
+				"fake.startupProbe.successThreshold must be 1 for a startup probe"),
+		)
+		It("should accept freeze hooks", func() {
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.Spec.FreezeHooks = &v1.FreezeHooks{
+				PreFreeze: &v1.FreezeHook{Command: []string{"/usr/bin/fsync-db"}, TimeoutSeconds: 15},
+				PostThaw:  &v1.FreezeHook{Command: []string{"/usr/bin/resume-db"}, FailurePolicy: v1.FreezeHookFailurePolicyContinue},
+			}
+			Expect(ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)).To(BeEmpty())
+		})
+		table.DescribeTable("should reject freeze hooks", func(hooks *v1.FreezeHooks, expectedMessages ...string) {
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.Spec.FreezeHooks = hooks
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			messages := []string{}
+			for _, cause := range causes {
+				messages = append(messages, cause.Message)
+			}
+			Expect(messages).To(Equal(expectedMessages))
+		},
+			table.Entry("without a command", &v1.FreezeHooks{PreFreeze: &v1.FreezeHook{}},
+				"fake.freezeHooks.preFreeze.command must not be empty"),
+			table.Entry("with a timeout above the maximum", &v1.FreezeHooks{PostThaw: &v1.FreezeHook{Command: []string{"/bin/true"}, TimeoutSeconds: 16}},
+				"fake.freezeHooks.postThaw.timeoutSeconds must be between 1 and 15 seconds"),
+			table.Entry("with a negative timeout", &v1.FreezeHooks{PreFreeze: &v1.FreezeHook{Command: []string{"/bin/true"}, TimeoutSeconds: -1}},
+				"fake.freezeHooks.preFreeze.timeoutSeconds must be between 1 and 15 seconds"),
+			table.Entry("with an unknown failure policy", &v1.FreezeHooks{PostThaw: &v1.FreezeHook{Command: []string{"/bin/true"}, FailurePolicy: "Retry"}},
+				"fake.freezeHooks.postThaw.failurePolicy must be one of Abort or Continue"),
+		)
+		table.DescribeTable("should validate the liveness probe failure action", func(action v1.LivenessProbeFailureAction, withLivenessProbe, withReadinessProbe bool, expectedMessages ...string) {
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.Spec.LivenessProbeFailureAction = action
+			if withLivenessProbe {
+				vmi.Spec.LivenessProbe = &v1.Probe{Handler: v1.Handler{HTTPGet: &k8sv1.HTTPGetAction{Host: "test", Port: intstr.Parse("80")}}}
+			}
+			if withReadinessProbe {
+				vmi.Spec.ReadinessProbe = &v1.Probe{Handler: v1.Handler{TCPSocket: &k8sv1.TCPSocketAction{Host: "test", Port: intstr.Parse("80")}}}
+			}
+			messages := []string{}
+			for _, cause := range validateLivenessProbeFailureAction(k8sfield.NewPath("fake"), &vmi.Spec) {
+				messages = append(messages, cause.Message)
+			}
+			Expect(messages).To(Equal(expectedMessages))
+		},
+			table.Entry("accepting no action", v1.LivenessProbeFailureAction(""), false, false),
+			table.Entry("accepting Restart with a readiness probe", v1.LivenessProbeFailureActionRestart, true, true),
+			table.Entry("accepting Migrate with a liveness probe", v1.LivenessProbeFailureActionMigrate, true, false),
+			table.Entry("rejecting Migrate without liveness probe", v1.LivenessProbeFailureActionMigrate, false, false,
+				"fake.livenessProbe must be set if fake.livenessProbeFailureAction is Migrate"),
+			table.Entry("rejecting Migrate with a readiness probe", v1.LivenessProbeFailureActionMigrate, true, true,
+				"fake.readinessProbe is not supported if fake.livenessProbeFailureAction is Migrate"),
+			table.Entry("rejecting unknown actions", v1.LivenessProbeFailureAction("Pause"), true, false,
+				"fake.livenessProbeFailureAction must be one of Restart or Migrate"),
+		)
 		It("should reject properly configured readiness and liveness probes if no Pod Network is present", func() {
 			vmi := v1.NewMinimalVMI("testvmi")
 			vmi.Spec.ReadinessProbe = &v1.Probe{
@@ -427,6 +526,49 @@ var _ = Describe("Validating VMICreate Admitter", func() {
 			),
 		)
 
+		table.DescribeTable("should validate the drain grace period annotation", func(value string, expectedMsg string) {
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.ObjectMeta = metav1.ObjectMeta{
+				Annotations: map[string]string{v1.DrainGracePeriodSecondsAnnotation: value},
+			}
+
+			causes := ValidateVirtualMachineInstanceMetadata(k8sfield.NewPath("metadata"), &vmi.ObjectMeta, config, "fake-account")
+			if expectedMsg == "" {
+				Expect(causes).To(BeEmpty())
+				return
+			}
+			Expect(len(causes)).To(Equal(1))
+			Expect(causes[0].Type).To(Equal(metav1.CauseTypeFieldValueInvalid))
+			Expect(causes[0].Message).To(ContainSubstring(expectedMsg))
+		},
+			table.Entry("accepting a value below the cluster maximum", "600", ""),
+			table.Entry("accepting zero", "0", ""),
+			table.Entry("rejecting a negative value", "-1", "must not be negative"),
+			table.Entry("rejecting a non numeric value", "1h", "invalid drain grace period"),
+			table.Entry("rejecting a value above the cluster maximum", "3601", "exceeds the cluster maximum of 3600 seconds"),
+		)
+
+		table.DescribeTable("should validate the qemu log filters annotation", func(value string, expectedMsg string) {
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.ObjectMeta = metav1.ObjectMeta{
+				Annotations: map[string]string{v1.QEMULogFiltersAnnotation: value},
+			}
+
+			causes := ValidateVirtualMachineInstanceMetadata(k8sfield.NewPath("metadata"), &vmi.ObjectMeta, config, "fake-account")
+			if expectedMsg == "" {
+				Expect(causes).To(BeEmpty())
+				return
+			}
+			Expect(len(causes)).To(Equal(1))
+			Expect(causes[0].Type).To(Equal(metav1.CauseTypeFieldValueInvalid))
+			Expect(causes[0].Message).To(ContainSubstring(expectedMsg))
+		},
+			table.Entry("accepting known items", "guest_errors,unimp", ""),
+			table.Entry("accepting an empty value", "", ""),
+			table.Entry("rejecting an unknown item", "guest_errors,everything", `unknown qemu log item "everything"`),
+			table.Entry("rejecting a trace pattern", "trace:virtio*", `unknown qemu log item "trace:virtio*"`),
+		)
+
 		table.DescribeTable("should accept annotations which require feature gate enabled", func(annotations map[string]string, featureGate string) {
 			enableFeatureGate(featureGate)
 			vmi := v1.NewMinimalVMI("testvmi")
@@ -502,6 +644,26 @@ var _ = Describe("Validating VMICreate Admitter", func() {
 			Expect(len(causes)).To(Equal(1))
 			Expect(causes[0].Field).To(Equal("fake.subdomain"))
 		})
+		It("should accept a valid workload class", func() {
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.Spec.WorkloadClass = "control-plane"
+
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			Expect(causes).To(BeEmpty())
+		})
+		table.DescribeTable("should reject an invalid workload class", func(workloadClass string) {
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.Spec.WorkloadClass = workloadClass
+
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			Expect(causes).To(HaveLen(1))
+			Expect(causes[0].Field).To(Equal("fake.workloadClass"))
+			Expect(causes[0].Message).To(ContainSubstring("does not conform to the kubernetes DNS_LABEL rules : "))
+		},
+			table.Entry("with upper case letters", "ControlPlane"),
+			table.Entry("with dots", "control.plane"),
+			table.Entry("longer than a label value", strings.Repeat("a", 64)),
+		)
 		It("should accept disk and volume lists equal to max element length", func() {
 			vmi := v1.NewMinimalVMI("testvmi")
 
@@ -848,6 +1010,95 @@ var _ = Describe("Validating VMICreate Admitter", func() {
 			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
 			Expect(causes).To(BeEmpty())
 		})
+		noMemBalloon := false
+		table.DescribeTable("should validate the balloon floor", func(floor string, guest string, autoattach *bool, expectedMessage string) {
+			vmi := v1.NewMinimalVMI("testvmi")
+			balloonFloor := resource.MustParse(floor)
+
+			vmi.Spec.Domain.Resources.Requests = k8sv1.ResourceList{
+				k8sv1.ResourceMemory: resource.MustParse("128Mi"),
+			}
+			vmi.Spec.Domain.Memory = &v1.Memory{BalloonFloor: &balloonFloor}
+			if guest != "" {
+				guestMemory := resource.MustParse(guest)
+				vmi.Spec.Domain.Memory.Guest = &guestMemory
+			}
+			vmi.Spec.Domain.Devices.AutoattachMemBalloon = autoattach
+
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			if expectedMessage == "" {
+				Expect(causes).To(BeEmpty())
+				return
+			}
+			Expect(causes).To(HaveLen(1))
+			Expect(causes[0].Field).To(Equal("fake.domain.memory.balloonFloor"))
+			Expect(causes[0].Message).To(ContainSubstring(expectedMessage))
+		},
+			table.Entry("and accept a floor below the requested memory", "64Mi", "", nil, ""),
+			table.Entry("and accept a floor equal to the guest memory", "100Mi", "100Mi", nil, ""),
+			table.Entry("and reject a floor above the requested memory", "256Mi", "", nil, "must be equal to or less than the guest memory '128Mi'"),
+			table.Entry("and reject a floor above the guest memory", "110Mi", "100Mi", nil, "must be equal to or less than the guest memory '100Mi'"),
+			table.Entry("and reject a floor of zero", "0", "", nil, "must be greater than zero"),
+			table.Entry("and reject a floor without memory balloon", "64Mi", "", &noMemBalloon, "requires the memory balloon"),
+		)
+		table.DescribeTable("should validate the memory overcommit", func(overcommit uint32, guest, request string, configure func(*v1.Memory, *v1.DomainSpec), expectedField, expectedMessage string) {
+			kvConfig := kv.DeepCopy()
+			kvConfig.Spec.Configuration.DeveloperConfiguration.MaxMemoryOvercommit = 200
+			testutils.UpdateFakeKubeVirtClusterConfig(kvInformer, kvConfig)
+
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.Spec.Domain.Resources.Requests = k8sv1.ResourceList{}
+			if request != "" {
+				vmi.Spec.Domain.Resources.Requests[k8sv1.ResourceMemory] = resource.MustParse(request)
+			}
+			vmi.Spec.Domain.Memory = &v1.Memory{OvercommitPercent: &overcommit}
+			if guest != "" {
+				guestMemory := resource.MustParse(guest)
+				vmi.Spec.Domain.Memory.Guest = &guestMemory
+			}
+			if configure != nil {
+				configure(vmi.Spec.Domain.Memory, &vmi.Spec.Domain)
+			}
+
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			if expectedMessage == "" {
+				Expect(causes).To(BeEmpty())
+				return
+			}
+			Expect(causes).To(HaveLen(1))
+			Expect(causes[0].Field).To(Equal(expectedField))
+			Expect(causes[0].Message).To(ContainSubstring(expectedMessage))
+		},
+			table.Entry("and accept an overcommit without memory request", uint32(150), "3072M", "", nil, "", ""),
+			table.Entry("and accept an overcommit with the computed memory request", uint32(200), "3072M", "1536M", nil, "", ""),
+			table.Entry("and accept the maximum overcommit of the cluster", uint32(200), "3072M", "", nil, "", ""),
+			table.Entry("and reject an overcommit below 100 percent", uint32(50), "3072M", "", nil,
+				"fake.domain.memory.overcommitPercent", "must be between 100 and the maximum memory overcommit of the cluster '200'"),
+			table.Entry("and reject an overcommit above the maximum of the cluster", uint32(250), "3072M", "", nil,
+				"fake.domain.memory.overcommitPercent", "must be between 100 and the maximum memory overcommit of the cluster '200'"),
+			table.Entry("and reject an overcommit without guest memory", uint32(150), "", "1Gi", nil,
+				"fake.domain.memory.overcommitPercent", "requires fake.domain.memory.guest to be set"),
+			table.Entry("and reject a memory request differing from the computed one", uint32(150), "3072M", "3072M", nil,
+				"fake.domain.resources.requests.memory", "must be '2048M' or unset"),
+			table.Entry("and reject an overcommit with locked memory", uint32(150), "3072M", "", func(memory *v1.Memory, _ *v1.DomainSpec) {
+				memory.Locked = true
+			}, "fake.domain.memory.overcommitPercent", "is not allowed with fake.domain.memory.locked"),
+			table.Entry("and reject an overcommit with dedicated cpus", uint32(150), "3072M", "2048M", func(_ *v1.Memory, domain *v1.DomainSpec) {
+				domain.CPU = &v1.CPU{Cores: 2, DedicatedCPUPlacement: true}
+			}, "fake.domain.memory.overcommitPercent", "is not allowed with fake.domain.cpu.dedicatedCpuPlacement"),
+		)
+		It("should reject an overcommit above the memory overcommit of the cluster without a maximum", func() {
+			vmi := v1.NewMinimalVMI("testvmi")
+			guestMemory := resource.MustParse("3072M")
+			overcommit := uint32(150)
+			vmi.Spec.Domain.Resources.Requests = k8sv1.ResourceList{}
+			vmi.Spec.Domain.Memory = &v1.Memory{Guest: &guestMemory, OvercommitPercent: &overcommit}
+
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			Expect(causes).To(HaveLen(1))
+			Expect(causes[0].Field).To(Equal("fake.domain.memory.overcommitPercent"))
+			Expect(causes[0].Message).To(ContainSubstring("maximum memory overcommit of the cluster '100'"))
+		})
 		It("should reject not divisable by hugepages.size requests.memory", func() {
 			vmi := v1.NewMinimalVMI("testvmi")
 
@@ -906,6 +1157,61 @@ var _ = Describe("Validating VMICreate Admitter", func() {
 			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
 			Expect(len(causes)).To(Equal(0))
 		})
+		table.DescribeTable("should validate the per NUMA node hugepages", func(cores uint32, nodes []v1.NUMANodeHugepages, expectedFields []string) {
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.Spec.Domain.CPU = &v1.CPU{Cores: cores}
+			vmi.Spec.Domain.Resources.Requests = k8sv1.ResourceList{
+				k8sv1.ResourceMemory: resource.MustParse("2Gi"),
+			}
+			vmi.Spec.Domain.Memory = &v1.Memory{
+				Hugepages: &v1.Hugepages{PageSize: "1Gi", PerNUMANode: nodes},
+			}
+
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			fields := []string{}
+			for _, cause := range causes {
+				fields = append(fields, cause.Field)
+			}
+			Expect(fields).To(ConsistOf(expectedFields))
+		},
+			table.Entry("with a single node", uint32(1), []v1.NUMANodeHugepages{
+				{GuestNode: 0, HostNode: 1, Memory: resource.MustParse("2Gi")},
+			}, []string{}),
+			table.Entry("with two nodes", uint32(2), []v1.NUMANodeHugepages{
+				{GuestNode: 1, HostNode: 0, Memory: resource.MustParse("1Gi")},
+				{GuestNode: 0, HostNode: 1, Memory: resource.MustParse("1Gi")},
+			}, []string{}),
+			table.Entry("with two nodes on the same host node", uint32(2), []v1.NUMANodeHugepages{
+				{GuestNode: 0, HostNode: 0, Memory: resource.MustParse("1Gi")},
+				{GuestNode: 1, HostNode: 0, Memory: resource.MustParse("1Gi")},
+			}, []string{}),
+			table.Entry("with a single node not covering the guest memory", uint32(1), []v1.NUMANodeHugepages{
+				{GuestNode: 0, HostNode: 0, Memory: resource.MustParse("1Gi")},
+			}, []string{"fake.domain.memory.hugepages.perNUMANode"}),
+			table.Entry("with a single node not numbered 0", uint32(1), []v1.NUMANodeHugepages{
+				{GuestNode: 1, HostNode: 0, Memory: resource.MustParse("2Gi")},
+			}, []string{"fake.domain.memory.hugepages.perNUMANode[0].guestNode"}),
+			table.Entry("with a duplicate guest node", uint32(2), []v1.NUMANodeHugepages{
+				{GuestNode: 0, HostNode: 0, Memory: resource.MustParse("1Gi")},
+				{GuestNode: 0, HostNode: 1, Memory: resource.MustParse("1Gi")},
+			}, []string{"fake.domain.memory.hugepages.perNUMANode[1].guestNode"}),
+			table.Entry("with a gap between the guest nodes", uint32(2), []v1.NUMANodeHugepages{
+				{GuestNode: 0, HostNode: 0, Memory: resource.MustParse("1Gi")},
+				{GuestNode: 2, HostNode: 1, Memory: resource.MustParse("1Gi")},
+			}, []string{"fake.domain.memory.hugepages.perNUMANode[1].guestNode"}),
+			table.Entry("with node memory not a multiple of the page size", uint32(2), []v1.NUMANodeHugepages{
+				{GuestNode: 0, HostNode: 0, Memory: resource.MustParse("1536Mi")},
+				{GuestNode: 1, HostNode: 1, Memory: resource.MustParse("512Mi")},
+			}, []string{"fake.domain.memory.hugepages.perNUMANode[0].memory", "fake.domain.memory.hugepages.perNUMANode[1].memory"}),
+			table.Entry("with a node without memory", uint32(2), []v1.NUMANodeHugepages{
+				{GuestNode: 0, HostNode: 0, Memory: resource.MustParse("2Gi")},
+				{GuestNode: 1, HostNode: 1},
+			}, []string{"fake.domain.memory.hugepages.perNUMANode[1].memory"}),
+			table.Entry("with less vCPUs than nodes", uint32(1), []v1.NUMANodeHugepages{
+				{GuestNode: 0, HostNode: 0, Memory: resource.MustParse("1Gi")},
+				{GuestNode: 1, HostNode: 1, Memory: resource.MustParse("1Gi")},
+			}, []string{"fake.domain.memory.hugepages.perNUMANode"}),
+		)
 		table.DescribeTable("should verify LUN is mapped to PVC volume",
 			func(volume *v1.Volume, expectedErrors int) {
 				vmi := v1.NewMinimalVMI("testvmi")
@@ -1032,6 +1338,131 @@ var _ = Describe("Validating VMICreate Admitter", func() {
 			Expect(causes[0].Message).To(Equal("Boot order for " +
 				"fake.domain.devices.disks[1].bootOrder already set for a different device."))
 		})
+		Context("with a boot configuration", func() {
+			var vmi *v1.VirtualMachineInstance
+
+			bootOrder := func(order uint) *uint {
+				return &order
+			}
+
+			causeFields := func(causes []metav1.StatusCause) (fields []string) {
+				for _, cause := range causes {
+					fields = append(fields, cause.Field)
+				}
+				return fields
+			}
+
+			BeforeEach(func() {
+				vmi = v1.NewMinimalVMI("testvmi")
+				for _, name := range []string{"disk0", "disk1"} {
+					vmi.Spec.Domain.Devices.Disks = append(vmi.Spec.Domain.Devices.Disks, v1.Disk{
+						Name: name, DiskDevice: v1.DiskDevice{Disk: &v1.DiskTarget{}},
+					})
+					vmi.Spec.Volumes = append(vmi.Spec.Volumes, v1.Volume{
+						Name: name, VolumeSource: v1.VolumeSource{ContainerDisk: &v1.ContainerDiskSource{}},
+					})
+				}
+				vmi.Spec.Domain.Devices.Interfaces = []v1.Interface{{
+					Name:                   "default",
+					InterfaceBindingMethod: v1.InterfaceBindingMethod{Masquerade: &v1.InterfaceMasquerade{}},
+				}}
+				vmi.Spec.Networks = []v1.Network{*v1.DefaultPodNetwork()}
+			})
+
+			table.DescribeTable("should accept", func(boot *v1.Boot, diskOrders []*uint) {
+				vmi.Spec.Domain.Boot = boot
+				for i, order := range diskOrders {
+					vmi.Spec.Domain.Devices.Disks[i].BootOrder = order
+				}
+				causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+				Expect(causes).To(BeEmpty())
+			},
+				table.Entry("an order of disks and interfaces",
+					&v1.Boot{Order: []v1.BootDevice{{Disk: "disk1"}, {Interface: "default"}, {Disk: "disk0"}}}, nil),
+				table.Entry("the network boot after contiguous boot orders",
+					&v1.Boot{NetworkBootLast: true}, []*uint{bootOrder(2), bootOrder(1)}),
+				table.Entry("the network boot after an order",
+					&v1.Boot{Order: []v1.BootDevice{{Disk: "disk0"}}, NetworkBootLast: true}, nil),
+				table.Entry("the network boot without any boot order",
+					&v1.Boot{NetworkBootLast: true}, nil),
+				table.Entry("the maximal reboot timeout",
+					&v1.Boot{RebootTimeoutSeconds: func() *uint32 { t := uint32(65); return &t }()}, nil),
+			)
+
+			table.DescribeTable("should reject", func(boot *v1.Boot, diskOrders []*uint, field string) {
+				vmi.Spec.Domain.Boot = boot
+				for i, order := range diskOrders {
+					vmi.Spec.Domain.Devices.Disks[i].BootOrder = order
+				}
+				causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+				Expect(causes).To(HaveLen(1))
+				Expect(causes[0].Field).To(Equal(field))
+			},
+				table.Entry("a disk listed twice in the order",
+					&v1.Boot{Order: []v1.BootDevice{{Disk: "disk0"}, {Disk: "disk1"}, {Disk: "disk0"}}}, nil,
+					"fake.domain.boot.order[2].disk"),
+				table.Entry("an interface listed twice in the order",
+					&v1.Boot{Order: []v1.BootDevice{{Interface: "default"}, {Interface: "default"}}}, nil,
+					"fake.domain.boot.order[1].interface"),
+				table.Entry("an unknown disk in the order",
+					&v1.Boot{Order: []v1.BootDevice{{Disk: "disk2"}}}, nil,
+					"fake.domain.boot.order[0].disk"),
+				table.Entry("an unknown interface in the order",
+					&v1.Boot{Order: []v1.BootDevice{{Interface: "other"}}}, nil,
+					"fake.domain.boot.order[0].interface"),
+				table.Entry("an entry referencing no device",
+					&v1.Boot{Order: []v1.BootDevice{{}}}, nil,
+					"fake.domain.boot.order[0]"),
+				table.Entry("an entry referencing both a disk and an interface",
+					&v1.Boot{Order: []v1.BootDevice{{Disk: "disk0", Interface: "default"}}}, nil,
+					"fake.domain.boot.order[0]"),
+				table.Entry("an order combined with the boot order of a disk",
+					&v1.Boot{Order: []v1.BootDevice{{Disk: "disk0"}}}, []*uint{nil, bootOrder(1)},
+					"fake.domain.devices.disks[1].bootOrder"),
+				table.Entry("boot orders with a gap",
+					&v1.Boot{NetworkBootLast: true}, []*uint{bootOrder(1), bootOrder(3)},
+					"fake.domain.boot"),
+				table.Entry("boot orders not starting at 1",
+					&v1.Boot{NetworkBootLast: true}, []*uint{bootOrder(2), nil},
+					"fake.domain.boot"),
+				table.Entry("a reboot timeout beyond the libvirt limit",
+					&v1.Boot{RebootTimeoutSeconds: func() *uint32 { t := uint32(66); return &t }()}, nil,
+					"fake.domain.boot.rebootTimeoutSeconds"),
+			)
+
+			It("should reject duplicate boot orders only once", func() {
+				vmi.Spec.Domain.Boot = &v1.Boot{NetworkBootLast: true}
+				vmi.Spec.Domain.Devices.Disks[0].BootOrder = bootOrder(1)
+				vmi.Spec.Domain.Devices.Disks[1].BootOrder = bootOrder(1)
+				causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+				Expect(causes).To(HaveLen(1))
+				Expect(causes[0].Field).To(Equal("fake.domain.devices.disks[1].bootOrder"))
+			})
+
+			It("should not require contiguous boot orders without a boot configuration", func() {
+				vmi.Spec.Domain.Devices.Disks[0].BootOrder = bootOrder(1)
+				vmi.Spec.Domain.Devices.Disks[1].BootOrder = bootOrder(3)
+				causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+				Expect(causes).To(BeEmpty())
+			})
+
+			It("should reject a reboot timeout with EFI", func() {
+				timeout := uint32(5)
+				secureBoot := false
+				vmi.Spec.Domain.Boot = &v1.Boot{RebootTimeoutSeconds: &timeout}
+				vmi.Spec.Domain.Firmware = &v1.Firmware{Bootloader: &v1.Bootloader{EFI: &v1.EFI{SecureBoot: &secureBoot}}}
+				causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+				Expect(causeFields(causes)).To(ContainElement("fake.domain.boot.rebootTimeoutSeconds"))
+			})
+
+			It("should reject booting from a slirp interface", func() {
+				vmi.Spec.Domain.Devices.Interfaces[0].Masquerade = nil
+				vmi.Spec.Domain.Devices.Interfaces[0].Slirp = &v1.InterfaceSlirp{}
+				vmi.Spec.Domain.Boot = &v1.Boot{Order: []v1.BootDevice{{Interface: "default"}}}
+				causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+				Expect(causeFields(causes)).To(ContainElement("fake.domain.boot.order[0].interface"))
+			})
+		})
 		It("should reject interface lists with more than one interface with the same name", func() {
 			vm := v1.NewMinimalVMI("testvm")
 			vm.Spec.Domain.Devices.Interfaces = []v1.Interface{
@@ -1722,6 +2153,67 @@ var _ = Describe("Validating VMICreate Admitter", func() {
 				Expect(causes[0].Field).To(Equal("fake.domain.devices.interfaces[0].macAddress"))
 			}
 		})
+		It("should accept a configured MAC address pool", func() {
+			kvConfig := kv.DeepCopy()
+			kvConfig.Spec.Configuration.NetworkConfiguration = &v1.NetworkConfiguration{
+				MacAddressPools: []v1.MacAddressPool{{Name: "pool", RangeStart: "02:00:00:00:00:00", RangeEnd: "02:00:00:00:00:ff"}},
+			}
+			testutils.UpdateFakeKubeVirtClusterConfig(kvInformer, kvConfig)
+			vmi := v1.NewMinimalVMI("testvm")
+			vmi.Spec.Domain.Devices.Interfaces = []v1.Interface{*v1.DefaultBridgeNetworkInterface()}
+			vmi.Spec.Domain.Devices.Interfaces[0].MacAddressPool = "pool"
+			vmi.Spec.Networks = []v1.Network{*v1.DefaultPodNetwork()}
+
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			Expect(causes).To(BeEmpty())
+		})
+
+		It("should reject a MAC address pool which is not configured", func() {
+			vmi := v1.NewMinimalVMI("testvm")
+			vmi.Spec.Domain.Devices.Interfaces = []v1.Interface{*v1.DefaultBridgeNetworkInterface()}
+			vmi.Spec.Domain.Devices.Interfaces[0].MacAddressPool = "pool"
+			vmi.Spec.Networks = []v1.Network{*v1.DefaultPodNetwork()}
+
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			Expect(causes).To(HaveLen(1))
+			Expect(causes[0].Field).To(Equal("fake.domain.devices.interfaces[0].macAddressPool"))
+		})
+		table.DescribeTable("should validate the MTU of the interface", func(mtu uint, valid bool) {
+			vmi := v1.NewMinimalVMI("testvm")
+			vmi.Spec.Domain.Devices.Interfaces = []v1.Interface{*v1.DefaultBridgeNetworkInterface()}
+			vmi.Spec.Domain.Devices.Interfaces[0].MTU = &mtu
+			vmi.Spec.Networks = []v1.Network{*v1.DefaultPodNetwork()}
+
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			if valid {
+				Expect(causes).To(BeEmpty())
+			} else {
+				Expect(causes).To(HaveLen(1))
+				Expect(causes[0].Field).To(Equal("fake.domain.devices.interfaces[0].mtu"))
+			}
+		},
+			table.Entry("accept the smallest MTU", uint(68), true),
+			table.Entry("accept a jumbo frame MTU", uint(9000), true),
+			table.Entry("accept the largest MTU", uint(65535), true),
+			table.Entry("reject a zero MTU", uint(0), false),
+			table.Entry("reject an MTU below the IPv4 minimum", uint(67), false),
+			table.Entry("reject an MTU above the upper bound", uint(65536), false),
+		)
+
+		It("should reject an MTU on an interface with the slirp binding", func() {
+			mtu := uint(1400)
+			vmi := v1.NewMinimalVMI("testvm")
+			vmi.Spec.Domain.Devices.Interfaces = []v1.Interface{*v1.DefaultSlirpNetworkInterface()}
+			vmi.Spec.Domain.Devices.Interfaces[0].MTU = &mtu
+			vmi.Spec.Networks = []v1.Network{*v1.DefaultPodNetwork()}
+
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			var fields []string
+			for _, cause := range causes {
+				fields = append(fields, cause.Field)
+			}
+			Expect(fields).To(ContainElement("fake.domain.devices.interfaces[0].mtu"))
+		})
 		It("should accept valid PCI address", func() {
 			vmi := v1.NewMinimalVMI("testvm")
 			vmi.Spec.Domain.Devices.Interfaces = []v1.Interface{*v1.DefaultBridgeNetworkInterface()}
@@ -1886,6 +2378,45 @@ var _ = Describe("Validating VMICreate Admitter", func() {
 			Expect(causes[0].Message).To(Equal(fmt.Sprintf("Invalid IOThreadsPolicy (%s)", ioThreadPolicy)))
 		})
 
+		table.DescribeTable("should validate the crash action", func(action v1.CrashAction, valid bool) {
+			vmi := v1.NewMinimalVMI("testvm")
+			vmi.Spec.Domain.OnCrash = &action
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			if valid {
+				Expect(causes).To(BeEmpty())
+			} else {
+				Expect(causes).To(HaveLen(1))
+				Expect(causes[0].Type).To(Equal(metav1.CauseTypeFieldValueNotSupported))
+				Expect(causes[0].Field).To(Equal("fake.domain.onCrash"))
+			}
+		},
+			table.Entry("destroy", v1.CrashActionDestroy, true),
+			table.Entry("restart", v1.CrashActionRestart, true),
+			table.Entry("preserve", v1.CrashActionPreserve, true),
+			table.Entry("an empty action", v1.CrashAction(""), false),
+			table.Entry("a libvirt action not supported by KubeVirt", v1.CrashAction("coredump-destroy"), false),
+		)
+
+		table.DescribeTable("should validate the SELinux type", func(selinuxType string, valid bool) {
+			vmi := v1.NewMinimalVMI("testvm")
+			vmi.Spec.Domain.SELinux = &v1.DomainSELinux{LauncherType: selinuxType}
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			if valid {
+				Expect(causes).To(BeEmpty())
+			} else {
+				Expect(causes).To(HaveLen(1))
+				Expect(causes[0].Field).To(Equal("fake.domain.selinux.launcherType"))
+			}
+		},
+			table.Entry("accepting no type", "", true),
+			table.Entry("accepting a custom type", "virt_custom_t", true),
+			table.Entry("accepting a CIL block type", "virt_launcher.process", true),
+			table.Entry("rejecting a full label", "system_u:system_r:virt_custom_t:s0", false),
+			table.Entry("rejecting whitespaces", "virt custom_t", false),
+			table.Entry("rejecting a trailing underscore", "virt_custom_", false),
+			table.Entry("rejecting types too long for a node label", strings.Repeat("t", 64), false),
+		)
+
 		It("should reject GPU devices when feature gate is disabled", func() {
 			vmi := v1.NewMinimalVMI("testvm")
 			vmi.Spec.Domain.Devices.GPUs = []v1.GPU{
@@ -2067,6 +2598,116 @@ var _ = Describe("Validating VMICreate Admitter", func() {
 			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
 			Expect(len(causes)).To(Equal(0))
 		})
+		It("should accept host devices with distinct PCI addresses", func() {
+			kvConfig := kv.DeepCopy()
+			kvConfig.Spec.Configuration.DeveloperConfiguration.FeatureGates = []string{virtconfig.HostDevicesGate}
+			testutils.UpdateFakeKubeVirtClusterConfig(kvInformer, kvConfig)
+			vmi := v1.NewMinimalVMI("testvm")
+			vmi.Spec.Domain.Devices.HostDevices = []v1.HostDevice{
+				{Name: "hostdev1", DeviceName: "example.org/deadbeef", PciAddress: "0000:81:0a.0"},
+				{Name: "hostdev2", DeviceName: "example.org/deadbeef", PciAddress: "0000:81:0a.1"},
+				{Name: "hostdev3", DeviceName: "example.org/deadbeef"},
+			}
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			Expect(causes).To(BeEmpty())
+		})
+		table.DescribeTable("should validate the range of the guest agent connect timeout", func(timeout int32, valid bool) {
+			vmi := v1.NewMinimalVMI("testvm")
+			vmi.Spec.Domain.Devices.AgentConnectTimeoutSeconds = &timeout
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			if valid {
+				Expect(causes).To(BeEmpty())
+			} else {
+				Expect(causes).To(HaveLen(1))
+				Expect(causes[0].Field).To(Equal("fake.domain.devices.agentConnectTimeoutSeconds"))
+			}
+		},
+			table.Entry("with no timeout", int32(0), true),
+			table.Entry("with the largest timeout", int32(600), true),
+			table.Entry("with a negative timeout", int32(-1), false),
+			table.Entry("with a timeout above ten minutes", int32(601), false),
+		)
+		It("should reject host devices with malformed PCI addresses", func() {
+			vmi := v1.NewMinimalVMI("testvm")
+			vmi.Spec.Domain.Devices.HostDevices = []v1.HostDevice{
+				{Name: "hostdev1", DeviceName: "example.org/deadbeef", PciAddress: "0000:81:100.a"},
+			}
+			causes := validateHostDevicesPciAddresses(k8sfield.NewPath("fake"), &vmi.Spec)
+			Expect(causes).To(HaveLen(1))
+			Expect(causes[0].Field).To(Equal("fake.domain.devices.hostDevices[0].pciAddress"))
+		})
+		It("should accept the supported virtiofs cache and sandbox modes", func() {
+			vmi := v1.NewMinimalVMI("testvm")
+			vmi.Spec.Domain.Devices.Filesystems = []v1.Filesystem{
+				{Name: "shared1", Virtiofs: &v1.FilesystemVirtiofs{}},
+				{Name: "shared2", Virtiofs: &v1.FilesystemVirtiofs{Cache: v1.VirtiofsCacheAuto, Sandbox: v1.VirtiofsSandboxNamespace}},
+				{Name: "shared3", Virtiofs: &v1.FilesystemVirtiofs{Cache: v1.VirtiofsCacheAlways, Sandbox: v1.VirtiofsSandboxChroot}},
+			}
+			causes := validateFilesystemsVirtiofs(k8sfield.NewPath("fake"), &vmi.Spec)
+			Expect(causes).To(BeEmpty())
+		})
+		table.DescribeTable("should reject unsupported virtiofs modes", func(virtiofs *v1.FilesystemVirtiofs, expectedField string) {
+			vmi := v1.NewMinimalVMI("testvm")
+			vmi.Spec.Domain.Devices.Filesystems = []v1.Filesystem{
+				{Name: "shared", Virtiofs: virtiofs},
+			}
+			causes := validateFilesystemsVirtiofs(k8sfield.NewPath("fake"), &vmi.Spec)
+			Expect(causes).To(HaveLen(1))
+			Expect(causes[0].Type).To(Equal(metav1.CauseTypeFieldValueNotSupported))
+			Expect(causes[0].Field).To(Equal(expectedField))
+		},
+			table.Entry("with the writeback cache", &v1.FilesystemVirtiofs{Cache: "writeback"}, "fake.domain.devices.filesystems[0].virtiofs.cache"),
+			table.Entry("without a sandbox", &v1.FilesystemVirtiofs{Sandbox: "none"}, "fake.domain.devices.filesystems[0].virtiofs.sandbox"),
+		)
+		It("should accept a host device ROM from a config map", func() {
+			vmi := v1.NewMinimalVMI("testvm")
+			vmi.Spec.Domain.Devices.HostDevices = []v1.HostDevice{
+				{Name: "hostdev1", DeviceName: "example.org/deadbeef", ROM: &v1.HostDeviceROM{
+					ConfigMap: &k8sv1.LocalObjectReference{Name: "rom"},
+				}},
+			}
+			causes := validateHostDevicesROMs(k8sfield.NewPath("fake"), &vmi.Spec)
+			Expect(causes).To(BeEmpty())
+		})
+		table.DescribeTable("should reject invalid host device ROMs", func(rom *v1.HostDeviceROM, expectedField string) {
+			vmi := v1.NewMinimalVMI("testvm")
+			vmi.Spec.Domain.Devices.HostDevices = []v1.HostDevice{
+				{Name: "hostdev1", DeviceName: "example.org/deadbeef", ROM: rom},
+			}
+			causes := validateHostDevicesROMs(k8sfield.NewPath("fake"), &vmi.Spec)
+			Expect(causes).To(HaveLen(1))
+			Expect(causes[0].Field).To(Equal(expectedField))
+		},
+			table.Entry("without a source", &v1.HostDeviceROM{}, "fake.domain.devices.hostDevices[0].rom"),
+			table.Entry("with both sources", &v1.HostDeviceROM{
+				ConfigMap: &k8sv1.LocalObjectReference{Name: "rom"},
+				Secret:    &k8sv1.LocalObjectReference{Name: "rom"},
+			}, "fake.domain.devices.hostDevices[0].rom"),
+			table.Entry("with an unnamed secret", &v1.HostDeviceROM{
+				Secret: &k8sv1.LocalObjectReference{},
+			}, "fake.domain.devices.hostDevices[0].rom.secret.name"),
+		)
+		table.DescribeTable("should reject devices requesting the same PCI address", func(spec *v1.VirtualMachineInstanceSpec, expectedField string) {
+			causes := validateGuestPciAddressCollisions(k8sfield.NewPath("fake"), spec)
+			Expect(causes).To(HaveLen(1))
+			Expect(causes[0].Type).To(Equal(metav1.CauseTypeFieldValueDuplicate))
+			Expect(causes[0].Field).To(Equal(expectedField))
+		},
+			table.Entry("with two host devices", &v1.VirtualMachineInstanceSpec{Domain: v1.DomainSpec{Devices: v1.Devices{
+				HostDevices: []v1.HostDevice{
+					{Name: "hostdev1", PciAddress: "0000:81:0a.0"},
+					{Name: "hostdev2", PciAddress: "0000:81:0A.0"},
+				},
+			}}}, "fake.domain.devices.hostDevices[1].pciAddress"),
+			table.Entry("with a host device and an interface", &v1.VirtualMachineInstanceSpec{Domain: v1.DomainSpec{Devices: v1.Devices{
+				Interfaces:  []v1.Interface{{Name: "default", PciAddress: "0000:81:0a.0"}},
+				HostDevices: []v1.HostDevice{{Name: "hostdev1", PciAddress: "0000:81:0a.0"}},
+			}}}, "fake.domain.devices.hostDevices[0].pciAddress"),
+			table.Entry("with a host device and a disk", &v1.VirtualMachineInstanceSpec{Domain: v1.DomainSpec{Devices: v1.Devices{
+				Disks:       []v1.Disk{{Name: "disk1", DiskDevice: v1.DiskDevice{Disk: &v1.DiskTarget{Bus: "virtio", PciAddress: "0000:81:0a.0"}}}},
+				HostDevices: []v1.HostDevice{{Name: "hostdev1", PciAddress: "0000:81:0a.0"}},
+			}}}, "fake.domain.devices.hostDevices[0].pciAddress"),
+		)
 		table.DescribeTable("Should accept valid DNSPolicy and DNSConfig",
 			func(dnsPolicy k8sv1.DNSPolicy, dnsConfig *k8sv1.PodDNSConfig) {
 				vmi := v1.NewMinimalVMI("testvmi")
@@ -2487,6 +3128,56 @@ var _ = Describe("Validating VMICreate Admitter", func() {
 			),
 		)
 
+		table.DescribeTable("should validate I/O throttle groups",
+			func(groups []*v1.IOThrottleGroup, expectedFields ...string) {
+				var disks []v1.Disk
+				for i, group := range groups {
+					disks = append(disks, v1.Disk{
+						Name:            fmt.Sprintf("disk%d", i),
+						IOThrottleGroup: group,
+					})
+				}
+				causes := validateDisks(k8sfield.NewPath("fake"), disks)
+				Expect(causes).To(HaveLen(len(expectedFields)))
+				for i, field := range expectedFields {
+					Expect(causes[i].Field).To(Equal(field))
+				}
+			},
+			table.Entry("and accept a single member group",
+				[]*v1.IOThrottleGroup{{Name: "db", TotalIOPS: 100, ReadBytesPerSec: 1024}},
+			),
+			table.Entry("and accept members with the same limits",
+				[]*v1.IOThrottleGroup{{Name: "db", ReadIOPS: 100, WriteIOPS: 50}, nil, {Name: "db", ReadIOPS: 100, WriteIOPS: 50}},
+			),
+			table.Entry("and accept different groups with different limits",
+				[]*v1.IOThrottleGroup{{Name: "db", TotalIOPS: 100}, {Name: "logs", TotalIOPS: 10}},
+			),
+			table.Entry("and reject members with different limits",
+				[]*v1.IOThrottleGroup{{Name: "db", TotalIOPS: 100}, {Name: "logs", TotalIOPS: 10}, {Name: "db", TotalIOPS: 200}},
+				"fake[2].ioThrottleGroup",
+			),
+			table.Entry("and reject a group without name",
+				[]*v1.IOThrottleGroup{{TotalIOPS: 100}},
+				"fake[0].ioThrottleGroup.name",
+			),
+			table.Entry("and reject a group without limits",
+				[]*v1.IOThrottleGroup{{Name: "db"}},
+				"fake[0].ioThrottleGroup",
+			),
+			table.Entry("and reject negative limits",
+				[]*v1.IOThrottleGroup{{Name: "db", ReadIOPS: 10, WriteBytesPerSec: -1}},
+				"fake[0].ioThrottleGroup.writeBytesPerSec",
+			),
+			table.Entry("and reject a total IOPS combined with a read IOPS",
+				[]*v1.IOThrottleGroup{{Name: "db", TotalIOPS: 100, ReadIOPS: 10}},
+				"fake[0].ioThrottleGroup.totalIOPS",
+			),
+			table.Entry("and reject a total bandwidth combined with a write bandwidth",
+				[]*v1.IOThrottleGroup{{Name: "db", TotalBytesPerSec: 100, WriteBytesPerSec: 10}},
+				"fake[0].ioThrottleGroup.totalBytesPerSec",
+			),
+		)
+
 		It("should reject floppy disks", func() {
 			vmi := v1.NewMinimalVMI("testvmi")
 
@@ -2814,6 +3505,52 @@ var _ = Describe("Validating VMICreate Admitter", func() {
 			Expect(len(causes)).To(Equal(0))
 		})
 
+		It("should reject disks with the same serial", func() {
+			disks := []v1.Disk{
+				{Name: "testdisk1", Serial: "SN-1_a", DiskDevice: v1.DiskDevice{Disk: &v1.DiskTarget{}}},
+				{Name: "testdisk2", Serial: "SN-1_a", DiskDevice: v1.DiskDevice{Disk: &v1.DiskTarget{}}},
+			}
+
+			causes := validateDisks(k8sfield.NewPath("fake"), disks)
+			Expect(causes).To(HaveLen(1))
+			Expect(causes[0].Type).To(Equal(metav1.CauseTypeFieldValueDuplicate))
+			Expect(causes[0].Field).To(Equal("fake[1].serial"))
+		})
+
+		table.DescribeTable("should validate the WWN", func(wwn string, target v1.DiskDevice, expectedField string) {
+			disks := []v1.Disk{{Name: "testdisk", WWN: wwn, DiskDevice: target}}
+
+			causes := validateDisks(k8sfield.NewPath("fake"), disks)
+			if expectedField == "" {
+				Expect(causes).To(BeEmpty())
+			} else {
+				Expect(causes).To(HaveLen(1))
+				Expect(causes[0].Field).To(Equal(expectedField))
+			}
+		},
+			table.Entry("accepting a scsi disk", "5000c500a0b1c2d3", v1.DiskDevice{Disk: &v1.DiskTarget{Bus: "scsi"}}, ""),
+			table.Entry("accepting upper case digits", "5000C500A0B1C2D3", v1.DiskDevice{Disk: &v1.DiskTarget{Bus: "scsi"}}, ""),
+			table.Entry("accepting a scsi cdrom", "5000c500a0b1c2d3", v1.DiskDevice{CDRom: &v1.CDRomTarget{Bus: "scsi"}}, ""),
+			table.Entry("rejecting too few digits", "5000c500a0b1c2", v1.DiskDevice{Disk: &v1.DiskTarget{Bus: "scsi"}}, "fake[0].wwn"),
+			table.Entry("rejecting too many digits", "5000c500a0b1c2d3e4", v1.DiskDevice{Disk: &v1.DiskTarget{Bus: "scsi"}}, "fake[0].wwn"),
+			table.Entry("rejecting non hex digits", "5000c500a0b1c2dz", v1.DiskDevice{Disk: &v1.DiskTarget{Bus: "scsi"}}, "fake[0].wwn"),
+			table.Entry("rejecting a hex prefix", "0x5000c500a0b1c2d3", v1.DiskDevice{Disk: &v1.DiskTarget{Bus: "scsi"}}, "fake[0].wwn"),
+			table.Entry("rejecting a virtio disk", "5000c500a0b1c2d3", v1.DiskDevice{Disk: &v1.DiskTarget{Bus: "virtio"}}, "fake[0].wwn"),
+			table.Entry("rejecting a lun", "5000c500a0b1c2d3", v1.DiskDevice{LUN: &v1.LunTarget{Bus: "scsi"}}, "fake[0].wwn"),
+		)
+
+		It("should reject disks with the same WWN regardless of the case", func() {
+			disks := []v1.Disk{
+				{Name: "testdisk1", WWN: "5000c500a0b1c2d3", DiskDevice: v1.DiskDevice{Disk: &v1.DiskTarget{Bus: "scsi"}}},
+				{Name: "testdisk2", WWN: "5000C500A0B1C2D3", DiskDevice: v1.DiskDevice{Disk: &v1.DiskTarget{Bus: "scsi"}}},
+			}
+
+			causes := validateDisks(k8sfield.NewPath("fake"), disks)
+			Expect(causes).To(HaveLen(1))
+			Expect(causes[0].Type).To(Equal(metav1.CauseTypeFieldValueDuplicate))
+			Expect(causes[0].Field).To(Equal("fake[1].wwn"))
+		})
+
 		It("Should reject disk with DedicatedIOThread and SATA bus", func() {
 			vmi := v1.NewMinimalVMI("testvmi")
 			_true := true
@@ -2982,6 +3719,27 @@ var _ = Describe("Validating VMICreate Admitter", func() {
 			Expect(len(causes)).To(Equal(0))
 		})
 
+		It("should accept a SMBIOS secret reference", func() {
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.Spec.Domain.Firmware = &v1.Firmware{
+				SMBiosSecretRef: &k8sv1.LocalObjectReference{Name: "smbios"},
+			}
+
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			Expect(causes).To(BeEmpty())
+		})
+
+		It("should reject a SMBIOS secret reference without a name", func() {
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.Spec.Domain.Firmware = &v1.Firmware{
+				SMBiosSecretRef: &k8sv1.LocalObjectReference{},
+			}
+
+			causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+			Expect(causes).To(HaveLen(1))
+			Expect(causes[0].Field).To(Equal("fake.domain.firmware.smbiosSecretRef.name"))
+		})
+
 		It("should accept EFI with SMM", func() {
 			vmi := v1.NewMinimalVMI("testvmi")
 			vmi.Spec.Subdomain = "testsubdomain"
@@ -3033,6 +3791,66 @@ var _ = Describe("Validating VMICreate Admitter", func() {
 			Expect(len(causes)).To(Equal(0))
 		})
 
+		Context("with Secure Boot certificates", func() {
+			var vmi *v1.VirtualMachineInstance
+			var certificates *v1.SecureBootCertificates
+
+			BeforeEach(func() {
+				vmi = v1.NewMinimalVMI("testvmi")
+				_true := true
+				vmi.Spec.Domain.Features = &v1.Features{
+					SMM: &v1.FeatureState{
+						Enabled: &_true,
+					},
+				}
+				certificates = &v1.SecureBootCertificates{
+					PK:  &k8sv1.LocalObjectReference{Name: "pk"},
+					KEK: &k8sv1.LocalObjectReference{Name: "kek"},
+					DB:  &k8sv1.LocalObjectReference{Name: "db"},
+				}
+				vmi.Spec.Domain.Firmware = &v1.Firmware{
+					Bootloader: &v1.Bootloader{
+						EFI: &v1.EFI{
+							SecureBootCertificates: certificates,
+						},
+					},
+				}
+			})
+
+			It("should accept a complete set of certificates", func() {
+				causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+				Expect(causes).To(BeEmpty())
+			})
+
+			table.DescribeTable("should reject an incomplete set of certificates", func(clear func(), field string) {
+				clear()
+				causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+				Expect(causes).To(HaveLen(1))
+				Expect(causes[0].Type).To(Equal(metav1.CauseTypeFieldValueRequired))
+				Expect(causes[0].Field).To(Equal(field))
+			},
+				table.Entry("without a PK", func() { certificates.PK = nil }, "fake.domain.firmware.bootloader.efi.secureBootCertificates.pk.name"),
+				table.Entry("without a KEK", func() { certificates.KEK = nil }, "fake.domain.firmware.bootloader.efi.secureBootCertificates.kek.name"),
+				table.Entry("with a db without a name", func() { certificates.DB.Name = "" }, "fake.domain.firmware.bootloader.efi.secureBootCertificates.db.name"),
+			)
+
+			It("should reject an empty set of certificates", func() {
+				vmi.Spec.Domain.Firmware.Bootloader.EFI.SecureBootCertificates = &v1.SecureBootCertificates{}
+
+				causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+				Expect(causes).To(HaveLen(3))
+			})
+
+			It("should reject certificates with Secure Boot disabled", func() {
+				_false := false
+				vmi.Spec.Domain.Firmware.Bootloader.EFI.SecureBoot = &_false
+
+				causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), &vmi.Spec, config)
+				Expect(causes).To(HaveLen(1))
+				Expect(causes[0].Field).To(Equal("fake.domain.firmware.bootloader.efi.secureBootCertificates"))
+			})
+		})
+
 		It("should not accept BIOS and EFI together", func() {
 			vmi := v1.NewMinimalVMI("testvmi")
 			vmi.Spec.Subdomain = "testsubdomain"
@@ -3285,6 +4103,50 @@ var _ = Describe("Function getNumberOfPodInterfaces()", func() {
 		Expect(len(causes)).To(Equal(0))
 	})
 
+	table.DescribeTable("should validate the timers", func(timer *v1.Timer, expectedField string) {
+		spec := &v1.VirtualMachineInstanceSpec{}
+		spec.Domain.Clock = &v1.Clock{Timer: timer}
+
+		causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), spec, config)
+		if expectedField == "" {
+			Expect(causes).To(BeEmpty())
+		} else {
+			Expect(causes).To(HaveLen(1))
+			Expect(causes[0].Field).To(Equal(expectedField))
+		}
+	},
+		table.Entry("accepting the rtc catching up",
+			&v1.Timer{RTC: &v1.RTCTimer{TickPolicy: v1.RTCTickPolicyCatchup, Track: v1.TrackGuest, Catchup: &v1.TimerCatchup{Threshold: 123, Slew: 120, Limit: 10000}}}, ""),
+		table.Entry("accepting the pit discarding",
+			&v1.Timer{PIT: &v1.PITTimer{TickPolicy: v1.PITTickPolicyDiscard}}, ""),
+		table.Entry("accepting the hpet merging",
+			&v1.Timer{HPET: &v1.HPETTimer{TickPolicy: v1.HPETTickPolicyMerge}}, ""),
+		table.Entry("accepting a disabled timer with a tick policy",
+			&v1.Timer{PIT: &v1.PITTimer{Enabled: pointer.BoolPtr(false), TickPolicy: v1.PITTickPolicyDiscard}}, ""),
+		table.Entry("rejecting the rtc merging",
+			&v1.Timer{RTC: &v1.RTCTimer{TickPolicy: "merge"}}, "fake.domain.clock.timer.rtc.tickPolicy"),
+		table.Entry("rejecting the pit merging",
+			&v1.Timer{PIT: &v1.PITTimer{TickPolicy: "merge"}}, "fake.domain.clock.timer.pit.tickPolicy"),
+		table.Entry("rejecting an unknown rtc track",
+			&v1.Timer{RTC: &v1.RTCTimer{Track: "host"}}, "fake.domain.clock.timer.rtc.track"),
+		table.Entry("rejecting catchup settings without the catchup policy",
+			&v1.Timer{RTC: &v1.RTCTimer{TickPolicy: v1.RTCTickPolicyDelay, Catchup: &v1.TimerCatchup{Threshold: 123}}}, "fake.domain.clock.timer.rtc.catchup"),
+		table.Entry("rejecting catchup settings without a tick policy",
+			&v1.Timer{HPET: &v1.HPETTimer{Catchup: &v1.TimerCatchup{Slew: 120}}}, "fake.domain.clock.timer.hpet.catchup"),
+	)
+
+	It("should reject catchup settings on a disabled timer", func() {
+		spec := &v1.VirtualMachineInstanceSpec{}
+		spec.Domain.Clock = &v1.Clock{Timer: &v1.Timer{
+			PIT: &v1.PITTimer{Enabled: pointer.BoolPtr(false), TickPolicy: v1.PITTickPolicyCatchup, Catchup: &v1.TimerCatchup{Limit: 10}},
+		}}
+
+		causes := ValidateVirtualMachineInstanceSpec(k8sfield.NewPath("fake"), spec, config)
+		Expect(causes).To(HaveLen(1))
+		Expect(causes[0].Field).To(Equal("fake.domain.clock.timer.pit.catchup"))
+		Expect(causes[0].Message).To(ContainSubstring("disabled timer"))
+	})
+
 	It("Should validate VMIs without HyperV configuration", func() {
 		vmi := v1.NewMinimalVMI("testvmi")
 		Expect(vmi.Spec.Domain.Features).To(BeNil())
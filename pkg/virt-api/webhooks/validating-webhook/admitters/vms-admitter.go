@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"k8s.io/api/admission/v1beta1"
 	authv1 "k8s.io/api/authorization/v1"
@@ -38,6 +39,7 @@ import (
 	"kubevirt.io/client-go/kubecli"
 	cdiclone "kubevirt.io/containerized-data-importer/pkg/clone"
 	"kubevirt.io/kubevirt/pkg/controller"
+	"kubevirt.io/kubevirt/pkg/util/schedule"
 	webhookutils "kubevirt.io/kubevirt/pkg/util/webhooks"
 	"kubevirt.io/kubevirt/pkg/virt-api/webhooks"
 	virtconfig "kubevirt.io/kubevirt/pkg/virt-config"
@@ -289,6 +291,52 @@ func ValidateVirtualMachineSpec(field *k8sfield.Path, spec *v1.VirtualMachineSpe
 		}
 	}
 
+	if spec.Schedule != nil {
+		causes = append(causes, validateSchedule(field, spec)...)
+	}
+
+	return causes
+}
+
+// validateSchedule checks that the schedule parses, that its start and stop
+// never fire at the same time, and that the VirtualMachine uses a run
+// strategy the controller can switch.
+func validateSchedule(field *k8sfield.Path, spec *v1.VirtualMachineSpec) []metav1.StatusCause {
+	var causes []metav1.StatusCause
+
+	if spec.RunStrategy == nil || (*spec.RunStrategy != v1.RunStrategyAlways && *spec.RunStrategy != v1.RunStrategyHalted) {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s requires the %s or %s RunStrategy", field.Child("schedule").String(), v1.RunStrategyAlways, v1.RunStrategyHalted),
+			Field:   field.Child("runStrategy").String(),
+		})
+	}
+
+	s, err := schedule.Parse(spec.Schedule)
+	if err != nil {
+		return append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s is invalid: %v", field.Child("schedule").String(), err),
+			Field:   field.Child("schedule").String(),
+		})
+	}
+
+	now := time.Now()
+	for _, name := range s.Never(now) {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s never fires", field.Child("schedule", name).String()),
+			Field:   field.Child("schedule", name).String(),
+		})
+	}
+	if conflict, ok := s.Conflict(now); ok {
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: fmt.Sprintf("%s and %s both fire at %s", field.Child("schedule", "start").String(), field.Child("schedule", "stop").String(), conflict.Format(time.RFC3339)),
+			Field:   field.Child("schedule").String(),
+		})
+	}
+
 	return causes
 }
 
@@ -678,6 +678,48 @@ var _ = Describe("Validating VM Admitter", func() {
 		Expect(resp.Result.Details.Causes[0].Field).To(Equal("spec.dataVolumeTemplate[0]"))
 	})
 
+	Context("with a schedule", func() {
+		newVMSpec := func(runStrategy v1.VirtualMachineRunStrategy, schedule *v1.VirtualMachineSchedule) *v1.VirtualMachineSpec {
+			vmi := v1.NewMinimalVMI("testvmi")
+			return &v1.VirtualMachineSpec{
+				RunStrategy: &runStrategy,
+				Schedule:    schedule,
+				Template: &v1.VirtualMachineInstanceTemplateSpec{
+					Spec: vmi.Spec,
+				},
+			}
+		}
+
+		It("should accept valid schedules", func() {
+			spec := newVMSpec(v1.RunStrategyHalted, &v1.VirtualMachineSchedule{
+				Start:    "0 8 * * 1-5",
+				Stop:     "0 18 * * 1-5",
+				TimeZone: "Europe/Berlin",
+			})
+			Expect(ValidateVirtualMachineSpec(k8sfield.NewPath("spec"), spec, config, "fake-account")).To(BeEmpty())
+		})
+
+		table.DescribeTable("should reject", func(runStrategy v1.VirtualMachineRunStrategy, schedule *v1.VirtualMachineSchedule, field string, message string) {
+			causes := ValidateVirtualMachineSpec(k8sfield.NewPath("spec"), newVMSpec(runStrategy, schedule), config, "fake-account")
+			Expect(causes).To(HaveLen(1))
+			Expect(causes[0].Field).To(Equal(field))
+			Expect(causes[0].Message).To(ContainSubstring(message))
+		},
+			table.Entry("run strategies the controller doesn't switch", v1.RunStrategyManual,
+				&v1.VirtualMachineSchedule{Start: "0 8 * * *"}, "spec.runStrategy", "spec.schedule requires the Always or Halted RunStrategy"),
+			table.Entry("empty schedules", v1.RunStrategyAlways,
+				&v1.VirtualMachineSchedule{}, "spec.schedule", "at least one of the start and stop schedules is required"),
+			table.Entry("invalid cron schedules", v1.RunStrategyAlways,
+				&v1.VirtualMachineSchedule{Stop: "0 8 * *"}, "spec.schedule", "invalid stop schedule"),
+			table.Entry("unknown time zones", v1.RunStrategyAlways,
+				&v1.VirtualMachineSchedule{Start: "0 8 * * *", TimeZone: "Nowhere/Null_Island"}, "spec.schedule", `invalid time zone "Nowhere/Null_Island"`),
+			table.Entry("schedules which never fire", v1.RunStrategyAlways,
+				&v1.VirtualMachineSchedule{Start: "0 8 31 6 *"}, "spec.schedule.start", "spec.schedule.start never fires"),
+			table.Entry("starts and stops at the same time", v1.RunStrategyAlways,
+				&v1.VirtualMachineSchedule{Start: "0 8 * * *", Stop: "0 */8 * * 0,6"}, "spec.schedule", "spec.schedule.start and spec.schedule.stop both fire at"),
+		)
+	})
+
 	Context("VM rename", func() {
 		var (
 			vm         *v1.VirtualMachine
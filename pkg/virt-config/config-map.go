@@ -22,6 +22,7 @@ package virtconfig
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -48,6 +49,7 @@ const (
 	CPUModelKey                       = "default-cpu-model"
 	CPURequestKey                     = "cpu-request"
 	MemoryOvercommitKey               = "memory-overcommit"
+	MaxMemoryOvercommitKey            = "max-memory-overcommit"
 	LessPVCSpaceTolerationKey         = "pvc-tolerate-less-space-up-to-percent"
 	NodeSelectorsKey                  = "node-selectors"
 	NetworkInterfaceKey               = "default-network-interface"
@@ -59,6 +61,12 @@ const (
 	SupportedGuestAgentVersionsKey    = "supported-guest-agent"
 	OVMFPathKey                       = "ovmfPath"
 	MemBalloonStatsPeriod             = "memBalloonStatsPeriod"
+	MaxDrainGracePeriodSecondsKey     = "maxDrainGracePeriodSeconds"
+	CustomSELinuxLauncherTypesKey     = "customSELinuxLauncherTypes"
+	GuestAgentCommandAllowListKey     = "guestAgentCommandAllowList"
+	SELinuxRelabelParallelismKey      = "selinuxRelabelParallelism"
+	SELinuxRelabelTimeoutSecondsKey   = "selinuxRelabelTimeoutSeconds"
+	SELinuxRelabelSkipPathsKey        = "selinuxRelabelSkipPaths"
 	CPUAllocationRatio                = "cpu-allocation-ratio"
 	PermittedHostDevicesKey           = "permittedHostDevices"
 )
@@ -168,11 +176,15 @@ func defaultClusterConfig() *v1.KubeVirtConfiguration {
 	defaultUnsafeMigrationOverride := DefaultUnsafeMigrationOverride
 	progressTimeout := MigrationProgressTimeout
 	completionTimeoutPerGiB := MigrationCompletionTimeoutPerGiB
+	maxParallelMigrationThreadsDefault := MaxParallelMigrationThreadsDefault
 	cpuRequestDefault := resource.MustParse(DefaultCPURequest)
 	emulatedMachinesDefault := strings.Split(DefaultEmulatedMachines, ",")
 	nodeSelectorsDefault, _ := parseNodeSelectors(DefaultNodeSelectors)
 	defaultNetworkInterface := DefaultNetworkInterface
 	defaultMemBalloonStatsPeriod := DefaultMemBalloonStatsPeriod
+	defaultMaxDrainGracePeriodSeconds := DefaultMaxDrainGracePeriodSeconds
+	defaultSELinuxRelabelParallelism := DefaultSELinuxRelabelParallelism
+	defaultSELinuxRelabelTimeoutSeconds := DefaultSELinuxRelabelTimeoutSeconds
 	SmbiosDefaultConfig := &v1.SMBiosConfiguration{
 		Family:       SmbiosConfigDefaultFamily,
 		Manufacturer: SmbiosConfigDefaultManufacturer,
@@ -206,6 +218,7 @@ func defaultClusterConfig() *v1.KubeVirtConfiguration {
 			UnsafeMigrationOverride:           &defaultUnsafeMigrationOverride,
 			AllowAutoConverge:                 &allowAutoConverge,
 			AllowPostCopy:                     &allowPostCopy,
+			MaxParallelMigrationThreads:       &maxParallelMigrationThreadsDefault,
 		},
 		MachineType:      DefaultMachineType,
 		CPURequest:       &cpuRequestDefault,
@@ -215,11 +228,14 @@ func defaultClusterConfig() *v1.KubeVirtConfiguration {
 			PermitSlirpInterface:              pointer.BoolPtr(DefaultPermitSlirpInterface),
 			PermitBridgeInterfaceOnPodNetwork: pointer.BoolPtr(DefaultPermitBridgeInterfaceOnPodNetwork),
 		},
-		SMBIOSConfig:                SmbiosDefaultConfig,
-		SELinuxLauncherType:         DefaultSELinuxLauncherType,
-		SupportedGuestAgentVersions: supportedQEMUGuestAgentVersions,
-		OVMFPath:                    DefaultOVMFPath,
-		MemBalloonStatsPeriod:       &defaultMemBalloonStatsPeriod,
+		SMBIOSConfig:                 SmbiosDefaultConfig,
+		SELinuxLauncherType:          DefaultSELinuxLauncherType,
+		SupportedGuestAgentVersions:  supportedQEMUGuestAgentVersions,
+		OVMFPath:                     DefaultOVMFPath,
+		MemBalloonStatsPeriod:        &defaultMemBalloonStatsPeriod,
+		MaxDrainGracePeriodSeconds:   &defaultMaxDrainGracePeriodSeconds,
+		SELinuxRelabelParallelism:    &defaultSELinuxRelabelParallelism,
+		SELinuxRelabelTimeoutSeconds: &defaultSELinuxRelabelTimeoutSeconds,
 	}
 }
 
@@ -256,6 +272,8 @@ type migrationConfiguration struct {
 	ProgressTimeout                   *int64             `json:"progressTimeout,string,omitempty"`
 	UnsafeMigrationOverride           *bool              `json:"unsafeMigrationOverride,string,omitempty"`
 	AllowPostCopy                     *bool              `json:"allowPostCopy,string,omitempty"`
+	// only kept to convert to the current type, it can't be set in the config map
+	MaxParallelMigrationThreads *uint32 `json:"-"`
 }
 
 // setConfigFromConfigMap parses the provided config map and updates the provided config.
@@ -344,6 +362,14 @@ func setConfigFromConfigMap(config *v1.KubeVirtConfiguration, configMap *k8sv1.C
 		}
 	}
 
+	if maxMemoryOvercommit := strings.TrimSpace(configMap.Data[MaxMemoryOvercommitKey]); maxMemoryOvercommit != "" {
+		if value, err := strconv.Atoi(maxMemoryOvercommit); err == nil && value > 0 {
+			config.DeveloperConfiguration.MaxMemoryOvercommit = value
+		} else {
+			return fmt.Errorf("Invalid maxMemoryOvercommit in ConfigMap: %s", maxMemoryOvercommit)
+		}
+	}
+
 	if cpuOvercommit := strings.TrimSpace(configMap.Data[CPUAllocationRatio]); cpuOvercommit != "" {
 		if value, err := strconv.ParseInt(cpuOvercommit, 10, 32); err == nil && value > 0 {
 			config.DeveloperConfiguration.CPUAllocationRatio = int(value)
@@ -442,6 +468,67 @@ func setConfigFromConfigMap(config *v1.KubeVirtConfiguration, configMap *k8sv1.C
 		}
 	}
 
+	if maxDrainGracePeriodSeconds := strings.TrimSpace(configMap.Data[MaxDrainGracePeriodSecondsKey]); maxDrainGracePeriodSeconds != "" {
+		i, err := strconv.ParseInt(maxDrainGracePeriodSeconds, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid maxDrainGracePeriodSeconds in config, %s", maxDrainGracePeriodSeconds)
+		}
+		if i < 0 {
+			return fmt.Errorf("invalid maxDrainGracePeriodSeconds (negative) in config, %d", i)
+		}
+		config.MaxDrainGracePeriodSeconds = &i
+	}
+
+	if customSELinuxLauncherTypes := strings.TrimSpace(configMap.Data[CustomSELinuxLauncherTypesKey]); customSELinuxLauncherTypes != "" {
+		vals := strings.Split(strings.TrimRight(customSELinuxLauncherTypes, ","), ",")
+		for i := range vals {
+			vals[i] = strings.TrimSpace(vals[i])
+		}
+		config.CustomSELinuxLauncherTypes = vals
+	}
+
+	if guestAgentCommandAllowList := strings.TrimSpace(configMap.Data[GuestAgentCommandAllowListKey]); guestAgentCommandAllowList != "" {
+		vals := strings.Split(strings.TrimRight(guestAgentCommandAllowList, ","), ",")
+		for i := range vals {
+			vals[i] = strings.TrimSpace(vals[i])
+		}
+		config.GuestAgentCommandAllowList = vals
+	}
+
+	if selinuxRelabelParallelism := strings.TrimSpace(configMap.Data[SELinuxRelabelParallelismKey]); selinuxRelabelParallelism != "" {
+		i, err := strconv.ParseUint(selinuxRelabelParallelism, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid selinuxRelabelParallelism in config, %s", selinuxRelabelParallelism)
+		}
+		if i == 0 {
+			return fmt.Errorf("invalid selinuxRelabelParallelism (zero) in config, %d", i)
+		}
+		parallelism := uint32(i)
+		config.SELinuxRelabelParallelism = &parallelism
+	}
+
+	if selinuxRelabelTimeoutSeconds := strings.TrimSpace(configMap.Data[SELinuxRelabelTimeoutSecondsKey]); selinuxRelabelTimeoutSeconds != "" {
+		i, err := strconv.ParseInt(selinuxRelabelTimeoutSeconds, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid selinuxRelabelTimeoutSeconds in config, %s", selinuxRelabelTimeoutSeconds)
+		}
+		if i <= 0 {
+			return fmt.Errorf("invalid selinuxRelabelTimeoutSeconds (not positive) in config, %d", i)
+		}
+		config.SELinuxRelabelTimeoutSeconds = &i
+	}
+
+	if selinuxRelabelSkipPaths := strings.TrimSpace(configMap.Data[SELinuxRelabelSkipPathsKey]); selinuxRelabelSkipPaths != "" {
+		vals := strings.Split(strings.TrimRight(selinuxRelabelSkipPaths, ","), ",")
+		for i := range vals {
+			vals[i] = strings.TrimSpace(vals[i])
+		}
+		config.SELinuxRelabelSkipPaths = vals
+	}
+	if err := validateSELinuxRelabelSkipPaths(config.SELinuxRelabelSkipPaths); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -457,6 +544,21 @@ func setConfigFromKubeVirt(config *v1.KubeVirtConfiguration, kv *v1.KubeVirt) er
 		return err
 	}
 
+	return validateSELinuxRelabelSkipPaths(config.SELinuxRelabelSkipPaths)
+}
+
+// validateSELinuxRelabelSkipPaths makes sure that the skip paths are absolute
+// and that their globs are well formed, so that virt-handler never fails to
+// match them.
+func validateSELinuxRelabelSkipPaths(skipPaths []string) error {
+	for _, skipPath := range skipPaths {
+		if !filepath.IsAbs(skipPath) {
+			return fmt.Errorf("invalid selinuxRelabelSkipPaths in config, %s is not absolute", skipPath)
+		}
+		if _, err := filepath.Match(skipPath, ""); err != nil {
+			return fmt.Errorf("invalid selinuxRelabelSkipPaths in config, %s: %v", skipPath, err)
+		}
+	}
 	return nil
 }
 
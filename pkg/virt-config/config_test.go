@@ -3,11 +3,13 @@ package virtconfig_test
 import (
 	"encoding/json"
 	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	kubev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/utils/pointer"
@@ -35,6 +37,76 @@ var _ = Describe("ConfigMap", func() {
 		table.Entry("when unset, GetMemBalloonStatsPeriod should return 10", "", uint32(10)),
 		table.Entry("when invalid, GetMemBalloonStatsPeriod should return 10", "invalid", uint32(10)))
 
+	table.DescribeTable("when maxDrainGracePeriodSeconds", func(value string, result int64) {
+		clusterConfig, _, _, _ := testutils.NewFakeClusterConfig(&kubev1.ConfigMap{
+			Data: map[string]string{"maxDrainGracePeriodSeconds": value},
+		})
+
+		Expect(clusterConfig.GetMaxDrainGracePeriodSeconds()).To(Equal(result))
+	},
+		table.Entry("is positive, GetMaxDrainGracePeriodSeconds should return it", "600", int64(600)),
+		table.Entry("is negative, GetMaxDrainGracePeriodSeconds should return 3600", "-1", int64(3600)),
+		table.Entry("when unset, GetMaxDrainGracePeriodSeconds should return 3600", "", int64(3600)),
+		table.Entry("when invalid, GetMaxDrainGracePeriodSeconds should return 3600", "invalid", int64(3600)))
+
+	table.DescribeTable("when selinuxRelabelParallelism", func(value string, result uint32) {
+		clusterConfig, _, _, _ := testutils.NewFakeClusterConfig(&kubev1.ConfigMap{
+			Data: map[string]string{"selinuxRelabelParallelism": value},
+		})
+
+		Expect(clusterConfig.GetSELinuxRelabelParallelism()).To(Equal(result))
+	},
+		table.Entry("is positive, GetSELinuxRelabelParallelism should return it", "8", uint32(8)),
+		table.Entry("is zero, GetSELinuxRelabelParallelism should return 4", "0", uint32(4)),
+		table.Entry("is negative, GetSELinuxRelabelParallelism should return 4", "-1", uint32(4)),
+		table.Entry("when unset, GetSELinuxRelabelParallelism should return 4", "", uint32(4)),
+		table.Entry("when invalid, GetSELinuxRelabelParallelism should return 4", "invalid", uint32(4)))
+
+	table.DescribeTable("when selinuxRelabelTimeoutSeconds", func(value string, result time.Duration) {
+		clusterConfig, _, _, _ := testutils.NewFakeClusterConfig(&kubev1.ConfigMap{
+			Data: map[string]string{"selinuxRelabelTimeoutSeconds": value},
+		})
+
+		Expect(clusterConfig.GetSELinuxRelabelTimeout()).To(Equal(result))
+	},
+		table.Entry("is positive, GetSELinuxRelabelTimeout should return it", "60", time.Minute),
+		table.Entry("is zero, GetSELinuxRelabelTimeout should return 5m", "0", 5*time.Minute),
+		table.Entry("is negative, GetSELinuxRelabelTimeout should return 5m", "-1", 5*time.Minute),
+		table.Entry("when unset, GetSELinuxRelabelTimeout should return 5m", "", 5*time.Minute),
+		table.Entry("when invalid, GetSELinuxRelabelTimeout should return 5m", "invalid", 5*time.Minute))
+
+	table.DescribeTable("when selinuxRelabelSkipPaths", func(value string, result []string) {
+		clusterConfig, _, _, _ := testutils.NewFakeClusterConfig(&kubev1.ConfigMap{
+			Data: map[string]string{"selinuxRelabelSkipPaths": value},
+		})
+
+		Expect(clusterConfig.GetSELinuxRelabelSkipPaths()).To(Equal(result))
+	},
+		table.Entry("are prefixes and globs, GetSELinuxRelabelSkipPaths should return them", "/dev/shm/, /dev/vfio/[0-9]*", []string{"/dev/shm/", "/dev/vfio/[0-9]*"}),
+		table.Entry("when unset, GetSELinuxRelabelSkipPaths should return nil", "", nil),
+		table.Entry("hold a malformed glob, GetSELinuxRelabelSkipPaths should return nil", "/dev/shm/,/dev/vfio/[0-9", nil),
+		table.Entry("hold a relative path, GetSELinuxRelabelSkipPaths should return nil", "dev/shm", nil))
+
+	table.DescribeTable("when customSELinuxLauncherTypes", func(value string, result []string) {
+		clusterConfig, _, _, _ := testutils.NewFakeClusterConfig(&kubev1.ConfigMap{
+			Data: map[string]string{"customSELinuxLauncherTypes": value},
+		})
+
+		Expect(clusterConfig.GetCustomSELinuxLauncherTypes()).To(Equal(result))
+	},
+		table.Entry("is a list, GetCustomSELinuxLauncherTypes should return its types", "virt_custom_t, virt_hardened_t,", []string{"virt_custom_t", "virt_hardened_t"}),
+		table.Entry("when unset, GetCustomSELinuxLauncherTypes should return nil", "", nil))
+
+	table.DescribeTable("when guestAgentCommandAllowList", func(value string, result []string) {
+		clusterConfig, _, _, _ := testutils.NewFakeClusterConfig(&kubev1.ConfigMap{
+			Data: map[string]string{"guestAgentCommandAllowList": value},
+		})
+
+		Expect(clusterConfig.GetGuestAgentCommandAllowList()).To(Equal(result))
+	},
+		table.Entry("is a list, GetGuestAgentCommandAllowList should return its commands", "guest-ping, guest-info,", []string{"guest-ping", "guest-info"}),
+		table.Entry("when unset, GetGuestAgentCommandAllowList should return nil", "", nil))
+
 	table.DescribeTable(" when useEmulation", func(value string, result bool) {
 		clusterConfig, _, _, _ := testutils.NewFakeClusterConfig(&kubev1.ConfigMap{
 			Data: map[string]string{"debug.useEmulation": value},
@@ -165,6 +237,22 @@ var _ = Describe("ConfigMap", func() {
 		table.Entry("when unset, GetMemoryOvercommit should return the default", "", virtconfig.DefaultMemoryOvercommit),
 	)
 
+	table.DescribeTable(" when maxMemoryOvercommit", func(data map[string]string, result int) {
+		clusterConfig, _, _, _ := testutils.NewFakeClusterConfig(&kubev1.ConfigMap{
+			Data: data,
+		})
+		Expect(clusterConfig.GetMaxMemoryOvercommit()).To(Equal(result))
+	},
+		table.Entry("when set, GetMaxMemoryOvercommit should return the value",
+			map[string]string{virtconfig.MaxMemoryOvercommitKey: "200"}, 200),
+		table.Entry("when unset, GetMaxMemoryOvercommit should return the memory overcommit",
+			map[string]string{virtconfig.MemoryOvercommitKey: "150"}, 150),
+		table.Entry("when lower than the memory overcommit, GetMaxMemoryOvercommit should return the memory overcommit",
+			map[string]string{virtconfig.MemoryOvercommitKey: "150", virtconfig.MaxMemoryOvercommitKey: "120"}, 150),
+		table.Entry("when both are unset, GetMaxMemoryOvercommit should return the default memory overcommit",
+			map[string]string{}, virtconfig.DefaultMemoryOvercommit),
+	)
+
 	table.DescribeTable(" when emulatedMachines", func(value string, result []string) {
 		clusterConfig, _, _, _ := testutils.NewFakeClusterConfig(&kubev1.ConfigMap{
 			Data: map[string]string{virtconfig.EmulatedMachinesKey: value},
@@ -222,6 +310,7 @@ var _ = Describe("ConfigMap", func() {
 		Expect(*result.ParallelOutboundMigrationsPerNode).To(BeNumerically("==", 10))
 		Expect(*result.ParallelMigrationsPerCluster).To(BeNumerically("==", 5))
 		Expect(result.BandwidthPerMigration.String()).To(Equal("64Mi"))
+		Expect(*result.MaxParallelMigrationThreads).To(BeNumerically("==", 8))
 	})
 
 	It("Should update the config if a newer version is available", func() {
@@ -411,6 +500,22 @@ var _ = Describe("ConfigMap", func() {
 
 		Expect(string(partJson)).To(BeEquivalentTo(result))
 	},
+		table.Entry("when selinuxRelabelSkipPaths set, should equal to result",
+			v1.KubeVirtConfiguration{
+				SELinuxRelabelSkipPaths: []string{"/dev/shm/", "/dev/vfio/*"},
+			},
+			func(c *v1.KubeVirtConfiguration) interface{} {
+				return c.SELinuxRelabelSkipPaths
+			},
+			`["/dev/shm/","/dev/vfio/*"]`),
+		table.Entry("when selinuxRelabelSkipPaths hold a malformed glob, should fall back to the default",
+			v1.KubeVirtConfiguration{
+				SELinuxRelabelSkipPaths: []string{"/dev/vfio/[0-9"},
+			},
+			func(c *v1.KubeVirtConfiguration) interface{} {
+				return c.SELinuxRelabelSkipPaths
+			},
+			`null`),
 		table.Entry("when machineType set, should equal to result",
 			v1.KubeVirtConfiguration{
 				MachineType: "test",
@@ -482,4 +587,12 @@ var _ = Describe("ConfigMap", func() {
 		emulation = clusterConfig.IsUseEmulation()
 		Expect(emulation).To(BeFalse())
 	})
+
+	It("should compute the overcommitted memory request", func() {
+		guestMemory := resource.MustParse("2Gi")
+		Expect(virtconfig.OvercommittedMemoryRequest(guestMemory, 100)).To(Equal(guestMemory))
+		Expect(virtconfig.OvercommittedMemoryRequest(guestMemory, 0)).To(Equal(guestMemory))
+		request := virtconfig.OvercommittedMemoryRequest(guestMemory, 400)
+		Expect(request.Value()).To(Equal(int64(512 * 1024 * 1024)))
+	})
 })
@@ -37,6 +37,9 @@ const (
 	HostDiskGate          = "HostDisk"
 	VirtIOFSGate          = "ExperimentalVirtiofsSupport"
 	MacvtapGate           = "Macvtap"
+	// SELinuxPermissiveTransitionsGate allows virt-handler to run commands
+	// with the launcher type permissive, to troubleshoot denials
+	SELinuxPermissiveTransitionsGate = "SELinuxPermissiveTransitions"
 )
 
 func (c *ClusterConfig) isFeatureGateEnabled(featureGate string) bool {
@@ -99,3 +102,7 @@ func (config *ClusterConfig) MacvtapEnabled() bool {
 func (config *ClusterConfig) HostDevicesPassthroughEnabled() bool {
 	return config.isFeatureGateEnabled(HostDevicesGate)
 }
+
+func (config *ClusterConfig) SELinuxPermissiveTransitionsEnabled() bool {
+	return config.isFeatureGateEnabled(SELinuxPermissiveTransitionsGate)
+}
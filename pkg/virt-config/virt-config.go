@@ -25,6 +25,7 @@ package virtconfig
 
 import (
 	"runtime"
+	"time"
 
 	k8sv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -40,6 +41,7 @@ const (
 	MigrationAllowPostCopy                   bool   = false
 	MigrationProgressTimeout                 int64  = 150
 	MigrationCompletionTimeoutPerGiB         int64  = 800
+	MaxParallelMigrationThreadsDefault       uint32 = 8
 	DefaultAMD64MachineType                         = "q35"
 	DefaultPPC64LEMachineType                       = "pseries"
 	DefaultCPURequest                               = "100m"
@@ -61,6 +63,9 @@ const (
 	SupportedGuestAgentVersions                     = "2.*,3.*,4.*"
 	DefaultOVMFPath                                 = "/usr/share/OVMF"
 	DefaultMemBalloonStatsPeriod             uint32 = 10
+	DefaultMaxDrainGracePeriodSeconds        int64  = 3600
+	DefaultSELinuxRelabelParallelism         uint32 = 4
+	DefaultSELinuxRelabelTimeoutSeconds      int64  = 300
 	DefaultCPUAllocationRatio                       = 10
 	DefaultVirtAPILogVerbosity                      = 2
 	DefaultVirtControllerLogVerbosity               = 2
@@ -83,6 +88,26 @@ func (c *ClusterConfig) GetMemBalloonStatsPeriod() uint32 {
 	return *c.GetConfig().MemBalloonStatsPeriod
 }
 
+// GetMaxDrainGracePeriodSeconds returns the upper bound of the drain grace period VMIs can request
+func (c *ClusterConfig) GetMaxDrainGracePeriodSeconds() int64 {
+	return *c.GetConfig().MaxDrainGracePeriodSeconds
+}
+
+// GetSELinuxRelabelParallelism returns how many paths virt-handler relabels at once for a VMI
+func (c *ClusterConfig) GetSELinuxRelabelParallelism() uint32 {
+	return *c.GetConfig().SELinuxRelabelParallelism
+}
+
+// GetSELinuxRelabelSkipPaths returns the prefixes and globs of the paths virt-handler never relabels
+func (c *ClusterConfig) GetSELinuxRelabelSkipPaths() []string {
+	return c.GetConfig().SELinuxRelabelSkipPaths
+}
+
+// GetSELinuxRelabelTimeout returns how long virt-handler may take to apply the SELinux labels of a scheduled VMI
+func (c *ClusterConfig) GetSELinuxRelabelTimeout() time.Duration {
+	return time.Duration(*c.GetConfig().SELinuxRelabelTimeoutSeconds) * time.Second
+}
+
 func (c *ClusterConfig) IsUseEmulation() bool {
 	return c.GetConfig().DeveloperConfiguration.UseEmulation
 }
@@ -117,6 +142,27 @@ func (c *ClusterConfig) GetMemoryOvercommit() int {
 	return c.GetConfig().DeveloperConfiguration.MemoryOvercommit
 }
 
+// GetMaxMemoryOvercommit returns the highest overcommit percentage a vmi can
+// request for its memory. It is never lower than the memory overcommit of the
+// cluster, which is the limit if unset.
+func (c *ClusterConfig) GetMaxMemoryOvercommit() int {
+	config := c.GetConfig().DeveloperConfiguration
+	if config.MaxMemoryOvercommit > config.MemoryOvercommit {
+		return config.MaxMemoryOvercommit
+	}
+	return config.MemoryOvercommit
+}
+
+// OvercommittedMemoryRequest returns the memory to request for a guest
+// whose memory is overcommitted by the given percentage.
+func OvercommittedMemoryRequest(guest resource.Quantity, overcommit int) resource.Quantity {
+	if overcommit <= 0 || overcommit == 100 {
+		return guest
+	}
+	value := (guest.Value() * int64(100)) / int64(overcommit)
+	return *resource.NewQuantity(value, guest.Format)
+}
+
 func (c *ClusterConfig) GetEmulatedMachines() []string {
 	return c.GetConfig().EmulatedMachines
 }
@@ -137,6 +183,10 @@ func (c *ClusterConfig) IsSlirpInterfaceEnabled() bool {
 	return *c.GetConfig().NetworkConfiguration.PermitSlirpInterface
 }
 
+func (c *ClusterConfig) GetMacAddressPools() []v1.MacAddressPool {
+	return c.GetConfig().NetworkConfiguration.MacAddressPools
+}
+
 func (c *ClusterConfig) GetSMBIOS() *v1.SMBiosConfiguration {
 	return c.GetConfig().SMBIOSConfig
 }
@@ -153,6 +203,16 @@ func (c *ClusterConfig) GetSELinuxLauncherType() string {
 	return c.GetConfig().SELinuxLauncherType
 }
 
+// GetCustomSELinuxLauncherTypes returns the SELinux types, besides the launcher type, VMIs may request to run with
+func (c *ClusterConfig) GetCustomSELinuxLauncherTypes() []string {
+	return c.GetConfig().CustomSELinuxLauncherTypes
+}
+
+// GetGuestAgentCommandAllowList returns the guest agent commands virt-launcher may proxy, all commands are allowed if empty
+func (c *ClusterConfig) GetGuestAgentCommandAllowList() []string {
+	return c.GetConfig().GuestAgentCommandAllowList
+}
+
 func (c *ClusterConfig) GetSupportedAgentVersions() []string {
 	return c.GetConfig().SupportedGuestAgentVersions
 }
@@ -0,0 +1,77 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/client-go/log"
+)
+
+// ValidateDrainGracePeriodSeconds checks that the value of the drain grace period
+// annotation is a non-negative number of seconds not exceeding maxSeconds.
+func ValidateDrainGracePeriodSeconds(value string, maxSeconds int64) error {
+	seconds, err := parseDrainGracePeriodSeconds(value)
+	if err != nil {
+		return err
+	}
+	if seconds > maxSeconds {
+		return fmt.Errorf("the drain grace period of %d seconds exceeds the cluster maximum of %d seconds", seconds, maxSeconds)
+	}
+	return nil
+}
+
+// GetDrainGracePeriodSeconds returns the grace period virt-launcher observes before
+// killing the VMI when its pod is terminated. The drain grace period annotation,
+// capped to maxSeconds, takes precedence over the VMI termination grace period.
+func GetDrainGracePeriodSeconds(vmi *v1.VirtualMachineInstance, maxSeconds int64) int64 {
+	gracePeriodSeconds := v1.DefaultGracePeriodSeconds
+	if vmi.Spec.TerminationGracePeriodSeconds != nil {
+		gracePeriodSeconds = *vmi.Spec.TerminationGracePeriodSeconds
+	}
+
+	value, exists := vmi.Annotations[v1.DrainGracePeriodSecondsAnnotation]
+	if !exists {
+		return gracePeriodSeconds
+	}
+	seconds, err := parseDrainGracePeriodSeconds(value)
+	if err != nil {
+		log.Log.Object(vmi).Reason(err).Warningf("Ignoring the %s annotation", v1.DrainGracePeriodSecondsAnnotation)
+		return gracePeriodSeconds
+	}
+	if seconds > maxSeconds {
+		return maxSeconds
+	}
+	return seconds
+}
+
+func parseDrainGracePeriodSeconds(value string) (int64, error) {
+	seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid drain grace period %q: %v", value, err)
+	}
+	if seconds < 0 {
+		return 0, fmt.Errorf("invalid drain grace period %q: must not be negative", value)
+	}
+	return seconds, nil
+}
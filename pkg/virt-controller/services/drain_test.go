@@ -0,0 +1,72 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package services
+
+import (
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	v1 "kubevirt.io/client-go/api/v1"
+)
+
+var _ = Describe("Drain grace period", func() {
+
+	newVMI := func(annotations map[string]string, terminationGracePeriodSeconds *int64) *v1.VirtualMachineInstance {
+		vmi := v1.NewMinimalVMI("testvmi")
+		vmi.Annotations = annotations
+		vmi.Spec.TerminationGracePeriodSeconds = terminationGracePeriodSeconds
+		return vmi
+	}
+
+	table.DescribeTable("should validate", func(value string, valid bool) {
+		err := ValidateDrainGracePeriodSeconds(value, 600)
+		if valid {
+			Expect(err).ToNot(HaveOccurred())
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		table.Entry("a positive value", "300", true),
+		table.Entry("zero", "0", true),
+		table.Entry("the cluster maximum", "600", true),
+		table.Entry("a value above the cluster maximum", "601", false),
+		table.Entry("a negative value", "-1", false),
+		table.Entry("a non numeric value", "ten", false),
+		table.Entry("an empty value", "", false),
+	)
+
+	table.DescribeTable("should compute the effective grace period", func(annotations map[string]string, terminationGracePeriodSeconds *int64, expected int64) {
+		Expect(GetDrainGracePeriodSeconds(newVMI(annotations, terminationGracePeriodSeconds), 600)).To(Equal(expected))
+	},
+		table.Entry("falling back to the default without annotation", nil, nil, v1.DefaultGracePeriodSeconds),
+		table.Entry("falling back to the termination grace period without annotation", nil, int64Ptr(10), int64(10)),
+		table.Entry("using the annotation",
+			map[string]string{v1.DrainGracePeriodSecondsAnnotation: "300"}, int64Ptr(10), int64(300)),
+		table.Entry("clamping the annotation to the cluster maximum",
+			map[string]string{v1.DrainGracePeriodSecondsAnnotation: "3600"}, nil, int64(600)),
+		table.Entry("falling back to the termination grace period on invalid annotation",
+			map[string]string{v1.DrainGracePeriodSecondsAnnotation: "-1"}, int64Ptr(10), int64(10)),
+	)
+})
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
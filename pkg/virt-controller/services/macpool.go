@@ -0,0 +1,211 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package services
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"sync"
+
+	"k8s.io/client-go/tools/cache"
+
+	v1 "kubevirt.io/client-go/api/v1"
+
+	virtconfig "kubevirt.io/kubevirt/pkg/virt-config"
+)
+
+// MacAddressClaim is a MAC address drawn from a pool for an interface.
+type MacAddressClaim struct {
+	Interface  string
+	Pool       string
+	MacAddress string
+}
+
+// MacAddressPoolAllocator draws the MAC addresses of interfaces requesting a
+// macAddressPool, without an explicit macAddress, from the pools configured
+// in the KubeVirt CR. The MAC address of an interface is derived from the
+// VMI and interface names, so that a VMI recreated with the same name, e.g.
+// on a restart of its VM, gets the same MAC address again as long as it was
+// not handed out in the meantime. The MAC addresses in use are the ones in
+// the specs of the VMIs in vmiStore, which survive controller restarts, and the
+// ones claimed by VMIs not yet updated in vmiStore. A claim is released once
+// its VMI is deleted.
+type MacAddressPoolAllocator struct {
+	vmiStore      cache.Store
+	clusterConfig *virtconfig.ClusterConfig
+
+	lock sync.Mutex
+	// claims maps the claimed MAC addresses to the key of their VMI
+	claims map[uint64]string
+}
+
+func NewMacAddressPoolAllocator(vmiStore cache.Store, clusterConfig *virtconfig.ClusterConfig) *MacAddressPoolAllocator {
+	return &MacAddressPoolAllocator{
+		vmiStore:      vmiStore,
+		clusterConfig: clusterConfig,
+		claims:        map[uint64]string{},
+	}
+}
+
+// NeedsMacAddressClaims returns whether the VMI has interfaces waiting for a
+// MAC address from their pool.
+func NeedsMacAddressClaims(vmi *v1.VirtualMachineInstance) bool {
+	for _, iface := range vmi.Spec.Domain.Devices.Interfaces {
+		if iface.MacAddressPool != "" && iface.MacAddress == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Claim sets the MAC address of all interfaces of vmi waiting for one from
+// their pool and returns the claimed MAC addresses.
+func (a *MacAddressPoolAllocator) Claim(vmi *v1.VirtualMachineInstance) ([]MacAddressClaim, error) {
+	if !NeedsMacAddressClaims(vmi) {
+		return nil, nil
+	}
+	vmiKey, err := cache.MetaNamespaceKeyFunc(vmi)
+	if err != nil {
+		return nil, err
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	used := a.usedMacAddresses(vmiKey)
+	var claims []MacAddressClaim
+	for i, iface := range vmi.Spec.Domain.Devices.Interfaces {
+		if iface.MacAddressPool == "" || iface.MacAddress != "" {
+			continue
+		}
+		start, end, err := a.poolRange(iface.MacAddressPool)
+		if err != nil {
+			return nil, err
+		}
+		mac, ok := nextFreeMacAddress(start, end, vmiKey+"/"+iface.Name, used)
+		if !ok {
+			return nil, fmt.Errorf("MAC address pool %s is exhausted", iface.MacAddressPool)
+		}
+		used[mac] = true
+		a.claims[mac] = vmiKey
+		vmi.Spec.Domain.Devices.Interfaces[i].MacAddress = formatMacAddress(mac)
+		claims = append(claims, MacAddressClaim{
+			Interface:  iface.Name,
+			Pool:       iface.MacAddressPool,
+			MacAddress: vmi.Spec.Domain.Devices.Interfaces[i].MacAddress,
+		})
+	}
+	return claims, nil
+}
+
+// Release frees the MAC addresses claimed for the deleted VMI with vmiKey.
+func (a *MacAddressPoolAllocator) Release(vmiKey string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	for mac, key := range a.claims {
+		if key == vmiKey {
+			delete(a.claims, mac)
+		}
+	}
+}
+
+// usedMacAddresses returns the MAC addresses used by all VMIs but the one
+// with vmiKey, which may reclaim its own ones.
+func (a *MacAddressPoolAllocator) usedMacAddresses(vmiKey string) map[uint64]bool {
+	used := map[uint64]bool{}
+	for mac, key := range a.claims {
+		if key != vmiKey {
+			used[mac] = true
+		}
+	}
+	for _, obj := range a.vmiStore.List() {
+		vmi := obj.(*v1.VirtualMachineInstance)
+		if key, err := cache.MetaNamespaceKeyFunc(vmi); err != nil || key == vmiKey {
+			continue
+		}
+		for _, iface := range vmi.Spec.Domain.Devices.Interfaces {
+			if mac, err := parseMacAddress(iface.MacAddress); err == nil {
+				used[mac] = true
+			}
+		}
+	}
+	return used
+}
+
+func (a *MacAddressPoolAllocator) poolRange(name string) (start uint64, end uint64, err error) {
+	for _, pool := range a.clusterConfig.GetMacAddressPools() {
+		if pool.Name != name {
+			continue
+		}
+		if start, err = parseMacAddress(pool.RangeStart); err != nil {
+			return 0, 0, fmt.Errorf("invalid start of MAC address pool %s: %v", name, err)
+		}
+		if end, err = parseMacAddress(pool.RangeEnd); err != nil {
+			return 0, 0, fmt.Errorf("invalid end of MAC address pool %s: %v", name, err)
+		}
+		if start > end {
+			return 0, 0, fmt.Errorf("invalid MAC address pool %s: %s is after %s", name, pool.RangeStart, pool.RangeEnd)
+		}
+		return start, end, nil
+	}
+	return 0, 0, fmt.Errorf("MAC address pool %s is not configured", name)
+}
+
+// nextFreeMacAddress returns the first free MAC address of the range, starting
+// at the offset derived from seed.
+func nextFreeMacAddress(start, end uint64, seed string, used map[uint64]bool) (uint64, bool) {
+	size := end - start + 1
+	hash := fnv.New64a()
+	hash.Write([]byte(seed))
+	offset := hash.Sum64() % size
+	for i := uint64(0); i < size; i++ {
+		mac := start + (offset+i)%size
+		if !used[mac] {
+			return mac, true
+		}
+	}
+	return 0, false
+}
+
+func parseMacAddress(address string) (uint64, error) {
+	hw, err := net.ParseMAC(address)
+	if err != nil {
+		return 0, err
+	}
+	if len(hw) != 6 {
+		return 0, fmt.Errorf("%s is not a 48 bit MAC address", address)
+	}
+	var mac uint64
+	for _, b := range hw {
+		mac = mac<<8 | uint64(b)
+	}
+	return mac, nil
+}
+
+func formatMacAddress(mac uint64) string {
+	hw := make(net.HardwareAddr, 6)
+	for i := 5; i >= 0; i-- {
+		hw[i] = byte(mac)
+		mac >>= 8
+	}
+	return hw.String()
+}
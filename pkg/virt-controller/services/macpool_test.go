@@ -0,0 +1,162 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package services
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	k8smetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	v1 "kubevirt.io/client-go/api/v1"
+
+	"kubevirt.io/kubevirt/pkg/testutils"
+)
+
+var _ = Describe("MAC address pools", func() {
+	var vmiStore cache.Store
+	var allocator *MacAddressPoolAllocator
+
+	newAllocator := func(pools ...v1.MacAddressPool) *MacAddressPoolAllocator {
+		config, _, _, _ := testutils.NewFakeClusterConfigUsingKV(&v1.KubeVirt{
+			ObjectMeta: k8smetav1.ObjectMeta{Name: "kubevirt", Namespace: "kubevirt"},
+			Spec: v1.KubeVirtSpec{
+				Configuration: v1.KubeVirtConfiguration{
+					NetworkConfiguration: &v1.NetworkConfiguration{MacAddressPools: pools},
+				},
+			},
+			Status: v1.KubeVirtStatus{Phase: v1.KubeVirtPhaseDeploying},
+		})
+		return NewMacAddressPoolAllocator(vmiStore, config)
+	}
+
+	newVMI := func(name string, pools ...string) *v1.VirtualMachineInstance {
+		vmi := v1.NewMinimalVMI(name)
+		for i, pool := range pools {
+			vmi.Spec.Domain.Devices.Interfaces = append(vmi.Spec.Domain.Devices.Interfaces, v1.Interface{
+				Name:           string(rune('a' + i)),
+				MacAddressPool: pool,
+			})
+		}
+		return vmi
+	}
+
+	pool := v1.MacAddressPool{Name: "pool", RangeStart: "02:00:00:00:00:00", RangeEnd: "02:00:00:00:ff:ff"}
+
+	BeforeEach(func() {
+		vmiStore = cache.NewStore(cache.MetaNamespaceKeyFunc)
+		allocator = newAllocator(pool)
+	})
+
+	It("should claim MAC addresses from the pool for interfaces without one", func() {
+		vmi := newVMI("testvmi", "pool", "pool", "")
+		vmi.Spec.Domain.Devices.Interfaces[1].MacAddress = "de:ad:00:00:be:af"
+
+		claims, err := allocator.Claim(vmi)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(claims).To(HaveLen(1))
+		Expect(claims[0].Interface).To(Equal("a"))
+		Expect(claims[0].Pool).To(Equal("pool"))
+		Expect(claims[0].MacAddress).To(HavePrefix("02:00:00:00:"))
+		Expect(vmi.Spec.Domain.Devices.Interfaces[0].MacAddress).To(Equal(claims[0].MacAddress))
+		Expect(vmi.Spec.Domain.Devices.Interfaces[1].MacAddress).To(Equal("de:ad:00:00:be:af"))
+		Expect(vmi.Spec.Domain.Devices.Interfaces[2].MacAddress).To(BeEmpty())
+	})
+
+	It("should claim distinct MAC addresses", func() {
+		allocator = newAllocator(v1.MacAddressPool{Name: "pool", RangeStart: "02:00:00:00:00:00", RangeEnd: "02:00:00:00:00:02"})
+		vmi := newVMI("testvmi", "pool", "pool")
+		_, err := allocator.Claim(vmi)
+		Expect(err).ToNot(HaveOccurred())
+		other := newVMI("othervmi", "pool")
+		_, err = allocator.Claim(other)
+		Expect(err).ToNot(HaveOccurred())
+
+		macs := []string{
+			vmi.Spec.Domain.Devices.Interfaces[0].MacAddress,
+			vmi.Spec.Domain.Devices.Interfaces[1].MacAddress,
+			other.Spec.Domain.Devices.Interfaces[0].MacAddress,
+		}
+		Expect(macs).To(ConsistOf("02:00:00:00:00:00", "02:00:00:00:00:01", "02:00:00:00:00:02"))
+	})
+
+	It("should not claim MAC addresses used by other VMIs", func() {
+		allocator = newAllocator(v1.MacAddressPool{Name: "pool", RangeStart: "02:00:00:00:00:00", RangeEnd: "02:00:00:00:00:01"})
+		other := newVMI("othervmi", "")
+		other.Spec.Domain.Devices.Interfaces[0].MacAddress = "02-00-00-00-00-00"
+		Expect(vmiStore.Add(other)).To(Succeed())
+
+		vmi := newVMI("testvmi", "pool")
+		_, err := allocator.Claim(vmi)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(vmi.Spec.Domain.Devices.Interfaces[0].MacAddress).To(Equal("02:00:00:00:00:01"))
+	})
+
+	It("should reuse the MAC address across restarts", func() {
+		vmi := newVMI("testvmi", "pool")
+		_, err := allocator.Claim(vmi)
+		Expect(err).ToNot(HaveOccurred())
+		mac := vmi.Spec.Domain.Devices.Interfaces[0].MacAddress
+		Expect(vmiStore.Add(vmi)).To(Succeed())
+
+		By("restarting the controller")
+		allocator = newAllocator(pool)
+		other := newVMI("othervmi", "pool")
+		_, err = allocator.Claim(other)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(other.Spec.Domain.Devices.Interfaces[0].MacAddress).ToNot(Equal(mac))
+
+		By("restarting the VMI")
+		Expect(vmiStore.Delete(vmi)).To(Succeed())
+		allocator.Release("default/testvmi")
+		restarted := newVMI("testvmi", "pool")
+		_, err = allocator.Claim(restarted)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restarted.Spec.Domain.Devices.Interfaces[0].MacAddress).To(Equal(mac))
+	})
+
+	It("should only hand out the MAC address again once the VMI is deleted", func() {
+		allocator = newAllocator(v1.MacAddressPool{Name: "pool", RangeStart: "02:00:00:00:00:00", RangeEnd: "02:00:00:00:00:00"})
+		vmi := newVMI("testvmi", "pool")
+		_, err := allocator.Claim(vmi)
+		Expect(err).ToNot(HaveOccurred())
+
+		other := newVMI("othervmi", "pool")
+		_, err = allocator.Claim(other)
+		Expect(err).To(MatchError("MAC address pool pool is exhausted"))
+
+		allocator.Release("default/testvmi")
+		_, err = allocator.Claim(other)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(other.Spec.Domain.Devices.Interfaces[0].MacAddress).To(Equal("02:00:00:00:00:00"))
+	})
+
+	It("should fail on a pool which is not configured", func() {
+		_, err := allocator.Claim(newVMI("testvmi", "unknown"))
+		Expect(err).To(MatchError("MAC address pool unknown is not configured"))
+	})
+
+	It("should fail on an invalid pool", func() {
+		allocator = newAllocator(v1.MacAddressPool{Name: "pool", RangeStart: "02:00:00:00:00:01", RangeEnd: "02:00:00:00:00:00"})
+		_, err := allocator.Claim(newVMI("testvmi", "pool"))
+		Expect(err).To(MatchError("invalid MAC address pool pool: 02:00:00:00:00:01 is after 02:00:00:00:00:00"))
+	})
+})
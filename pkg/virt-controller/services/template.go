@@ -29,6 +29,7 @@ import (
 	"strings"
 
 	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -45,11 +46,13 @@ import (
 	"kubevirt.io/kubevirt/pkg/config"
 	containerdisk "kubevirt.io/kubevirt/pkg/container-disk"
 	"kubevirt.io/kubevirt/pkg/hooks"
+	hotplugdisk "kubevirt.io/kubevirt/pkg/hotplug-disk"
 	"kubevirt.io/kubevirt/pkg/util"
 	"kubevirt.io/kubevirt/pkg/util/hardware"
 	"kubevirt.io/kubevirt/pkg/util/net/dns"
 	"kubevirt.io/kubevirt/pkg/util/types"
 	virtconfig "kubevirt.io/kubevirt/pkg/virt-config"
+	cmdclient "kubevirt.io/kubevirt/pkg/virt-handler/cmd-client"
 )
 
 const configMapName = "kubevirt-config"
@@ -72,6 +75,10 @@ const CAP_SYS_NICE = "SYS_NICE"
 // Libvirt needs roughly 10 seconds to start.
 const LibvirtStartupDelay = 10
 
+// launcherReadinessPeriodSeconds is the period of the readiness probe gating
+// VMIs without a custom readiness probe on virt-launcher.
+const launcherReadinessPeriodSeconds = 2
+
 //These perfixes for node feature discovery, are used in a NodeSelector on the pod
 //to match a VirtualMachineInstance CPU model(Family) and/or features to nodes that support them.
 const NFD_CPU_MODEL_PREFIX = "feature.node.kubernetes.io/cpu-model-"
@@ -88,12 +95,16 @@ const ENV_VAR_LIBVIRT_DEBUG_LOGS = "LIBVIRT_DEBUG_LOGS"
 const ENV_VAR_VIRTIOFSD_DEBUG_LOGS = "VIRTIOFSD_DEBUG_LOGS"
 const ENV_VAR_VIRT_LAUNCHER_LOG_VERBOSITY = "VIRT_LAUNCHER_LOG_VERBOSITY"
 
+// MemoryDumpAppLabel is the app label of the pods mounting the claim of a memory dump
+const MemoryDumpAppLabel = "memory-dump"
+
 // extensive log verbosity threshold after which libvirt debug logs will be enabled
 const EXT_LOG_VERBOSITY_THRESHOLD = 5
 
 type TemplateService interface {
 	RenderLaunchManifest(*v1.VirtualMachineInstance) (*k8sv1.Pod, error)
 	RenderHotplugAttachmentPodTemplate(volume *v1.Volume, ownerPod *k8sv1.Pod, vmi *v1.VirtualMachineInstance, pvcName string, isBlock bool) (*k8sv1.Pod, error)
+	RenderMemoryDumpTargetPodTemplate(ownerPod *k8sv1.Pod, vmi *v1.VirtualMachineInstance, claimName string) (*k8sv1.Pod, error)
 	RenderLaunchManifestNoVm(*v1.VirtualMachineInstance) (*k8sv1.Pod, error)
 }
 
@@ -205,6 +216,24 @@ func CPUModelLabelFromCPUModel(vmi *v1.VirtualMachineInstance) (label string, er
 	return
 }
 
+// SELinuxLauncherTypeLabelFromVMI returns the node label advertising the
+// SELinux launcher type requested by the VMI.
+func SELinuxLauncherTypeLabelFromVMI(vmi *v1.VirtualMachineInstance) (label string, err error) {
+	if vmi.Spec.Domain.SELinux == nil || vmi.Spec.Domain.SELinux.LauncherType == "" {
+		err = fmt.Errorf("Cannot create SELinux launcher type label, vmi spec is missing an SELinux launcher type")
+		return
+	}
+	label = v1.SELinuxLauncherTypeLabel + vmi.Spec.Domain.SELinux.LauncherType
+	return
+}
+
+func getSELinuxLauncherType(vmi *v1.VirtualMachineInstance, clusterLauncherType string) string {
+	if vmi.Spec.Domain.SELinux != nil && vmi.Spec.Domain.SELinux.LauncherType != "" {
+		return vmi.Spec.Domain.SELinux.LauncherType
+	}
+	return clusterLauncherType
+}
+
 func CPUFeatureLabelsFromCPUFeatures(vmi *v1.VirtualMachineInstance) []string {
 	var labels []string
 	if vmi.Spec.Domain.CPU != nil && vmi.Spec.Domain.CPU.Features != nil {
@@ -217,6 +246,34 @@ func CPUFeatureLabelsFromCPUFeatures(vmi *v1.VirtualMachineInstance) []string {
 	return labels
 }
 
+// addWorkloadClassAntiAffinity makes the pod prefer the nodes not running a
+// pod of the same workload class, on top of the affinity of the VMI. The term
+// is only preferred so that a class with more VMIs than nodes still schedules.
+func addWorkloadClassAntiAffinity(workloadClass string, pod *k8sv1.Pod) {
+	term := k8sv1.WeightedPodAffinityTerm{
+		Weight: 100,
+		PodAffinityTerm: k8sv1.PodAffinityTerm{
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{v1.WorkloadClassLabel: workloadClass},
+			},
+			TopologyKey: k8sv1.LabelHostname,
+		},
+	}
+	if pod.Spec.Affinity == nil {
+		pod.Spec.Affinity = &k8sv1.Affinity{}
+	}
+	if pod.Spec.Affinity.PodAntiAffinity == nil {
+		pod.Spec.Affinity.PodAntiAffinity = &k8sv1.PodAntiAffinity{}
+	}
+	antiAffinity := pod.Spec.Affinity.PodAntiAffinity
+	for _, existing := range antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		if equality.Semantic.DeepEqual(existing, term) {
+			return
+		}
+	}
+	antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, term)
+}
+
 func SetNodeAffinityForForbiddenFeaturePolicy(vmi *v1.VirtualMachineInstance, pod *k8sv1.Pod) {
 
 	if vmi.Spec.Domain.CPU == nil || vmi.Spec.Domain.CPU.Features == nil {
@@ -338,10 +395,8 @@ func (t *templateService) renderLaunchManifest(vmi *v1.VirtualMachineInstance, t
 		privileged = true
 	}
 
-	gracePeriodSeconds := v1.DefaultGracePeriodSeconds
-	if vmi.Spec.TerminationGracePeriodSeconds != nil {
-		gracePeriodSeconds = *vmi.Spec.TerminationGracePeriodSeconds
-	}
+	drainGracePeriodSeconds := GetDrainGracePeriodSeconds(vmi, t.clusterConfig.GetMaxDrainGracePeriodSeconds())
+	gracePeriodSeconds := drainGracePeriodSeconds
 
 	volumeMounts = append(volumeMounts, k8sv1.VolumeMount{
 		Name:      "ephemeral-disks",
@@ -668,6 +723,82 @@ func (t *templateService) renderLaunchManifest(vmi *v1.VirtualMachineInstance, t
 		})
 	}
 
+	if vmi.Spec.Domain.Firmware != nil && vmi.Spec.Domain.Firmware.SMBiosSecretRef != nil {
+		volumeName := "smbios-secret"
+		volumes = append(volumes, k8sv1.Volume{
+			Name: volumeName,
+			VolumeSource: k8sv1.VolumeSource{
+				Secret: &k8sv1.SecretVolumeSource{
+					SecretName: vmi.Spec.Domain.Firmware.SMBiosSecretRef.Name,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, k8sv1.VolumeMount{
+			Name:      volumeName,
+			MountPath: config.SMBiosSecretSourceDir,
+			ReadOnly:  true,
+		})
+	}
+
+	if firmware := vmi.Spec.Domain.Firmware; firmware != nil && firmware.Bootloader != nil &&
+		firmware.Bootloader.EFI != nil && firmware.Bootloader.EFI.SecureBootCertificates != nil {
+		certificates := firmware.Bootloader.EFI.SecureBootCertificates
+		for _, secret := range []struct {
+			store string
+			ref   *k8sv1.LocalObjectReference
+		}{
+			{config.SecureBootPKStore, certificates.PK},
+			{config.SecureBootKEKStore, certificates.KEK},
+			{config.SecureBootDBStore, certificates.DB},
+		} {
+			if secret.ref == nil {
+				continue
+			}
+			volumeName := "secure-boot-" + secret.store
+			volumes = append(volumes, k8sv1.Volume{
+				Name: volumeName,
+				VolumeSource: k8sv1.VolumeSource{
+					Secret: &k8sv1.SecretVolumeSource{
+						SecretName: secret.ref.Name,
+					},
+				},
+			})
+			volumeMounts = append(volumeMounts, k8sv1.VolumeMount{
+				Name:      volumeName,
+				MountPath: config.GetSecureBootCertificatesSourcePath(secret.store),
+				ReadOnly:  true,
+			})
+		}
+	}
+
+	for _, hostDev := range vmi.Spec.Domain.Devices.HostDevices {
+		if hostDev.ROM == nil {
+			continue
+		}
+		volumeName := "host-device-rom-" + hostDev.Name
+		volumeSource := k8sv1.VolumeSource{}
+		if hostDev.ROM.ConfigMap != nil {
+			volumeSource.ConfigMap = &k8sv1.ConfigMapVolumeSource{
+				LocalObjectReference: *hostDev.ROM.ConfigMap,
+			}
+		} else if hostDev.ROM.Secret != nil {
+			volumeSource.Secret = &k8sv1.SecretVolumeSource{
+				SecretName: hostDev.ROM.Secret.Name,
+			}
+		} else {
+			continue
+		}
+		volumes = append(volumes, k8sv1.Volume{
+			Name:         volumeName,
+			VolumeSource: volumeSource,
+		})
+		volumeMounts = append(volumeMounts, k8sv1.VolumeMount{
+			Name:      volumeName,
+			MountPath: config.GetHostDeviceROMSourcePath(hostDev.Name),
+			ReadOnly:  true,
+		})
+	}
+
 	if t.imagePullSecret != "" {
 		imagePullSecrets = appendUniqueImagePullSecret(imagePullSecrets, k8sv1.LocalObjectReference{
 			Name: t.imagePullSecret,
@@ -718,6 +849,11 @@ func (t *templateService) renderLaunchManifest(vmi *v1.VirtualMachineInstance, t
 		resources.Limits[key] = value
 	}
 
+	// A vmi overcommitting its memory requests its share of the guest memory
+	if memory := vmi.Spec.Domain.Memory; memory != nil && memory.OvercommitPercent != nil && memory.Guest != nil {
+		resources.Requests[k8sv1.ResourceMemory] = virtconfig.OvercommittedMemoryRequest(*memory.Guest, int(*memory.OvercommitPercent))
+	}
+
 	// Consider hugepages resource for pod scheduling
 	if vmi.Spec.Domain.Memory != nil && vmi.Spec.Domain.Memory.Hugepages != nil {
 		hugepageType := k8sv1.ResourceName(k8sv1.ResourceHugePagesPrefix + vmi.Spec.Domain.Memory.Hugepages.PageSize)
@@ -880,6 +1016,10 @@ func (t *templateService) renderLaunchManifest(vmi *v1.VirtualMachineInstance, t
 		resources.Limits[KvmDevice] = resource.MustParse("1")
 	}
 
+	if allowList := t.clusterConfig.GetGuestAgentCommandAllowList(); len(allowList) > 0 && !tempPod {
+		command = append(command, "--allowed-guest-agent-commands", strings.Join(allowList, ","))
+	}
+
 	// Add ports from interfaces to the pod manifest
 	ports := getPortsFromVMI(vmi)
 
@@ -933,9 +1073,25 @@ func (t *templateService) renderLaunchManifest(vmi *v1.VirtualMachineInstance, t
 	if vmi.Spec.ReadinessProbe != nil {
 		compute.ReadinessProbe = copyProbe(vmi.Spec.ReadinessProbe)
 		compute.ReadinessProbe.InitialDelaySeconds = compute.ReadinessProbe.InitialDelaySeconds + LibvirtStartupDelay
+	} else if vmi.MigratesOnLivenessProbeFailure() {
+		// the kubelet must not kill the compute container of a VMI to migrate,
+		// virt-controller acts on the readiness of the pod instead
+		compute.ReadinessProbe = copyProbe(vmi.Spec.LivenessProbe)
+		compute.ReadinessProbe.InitialDelaySeconds = compute.ReadinessProbe.InitialDelaySeconds + LibvirtStartupDelay
+	} else if !tempPod {
+		// virt-launcher only creates the ready file once virt-handler confirmed
+		// that it applied the SELinux labels of the VMI
+		compute.ReadinessProbe = &k8sv1.Probe{
+			Handler: k8sv1.Handler{
+				Exec: &k8sv1.ExecAction{
+					Command: []string{"cat", filepath.Join(t.virtShareDir, "sockets", cmdclient.StandardLauncherReadyFileName)},
+				},
+			},
+			PeriodSeconds: launcherReadinessPeriodSeconds,
+		}
 	}
 
-	if vmi.Spec.LivenessProbe != nil {
+	if vmi.Spec.LivenessProbe != nil && !vmi.MigratesOnLivenessProbeFailure() {
 		compute.LivenessProbe = copyProbe(vmi.Spec.LivenessProbe)
 		compute.LivenessProbe.InitialDelaySeconds = compute.LivenessProbe.InitialDelaySeconds + LibvirtStartupDelay
 	}
@@ -1026,6 +1182,10 @@ func (t *templateService) renderLaunchManifest(vmi *v1.VirtualMachineInstance, t
 		}
 	}
 
+	if selinuxTypeLabel, err := SELinuxLauncherTypeLabelFromVMI(vmi); err == nil {
+		nodeSelector[selinuxTypeLabel] = "true"
+	}
+
 	nodeSelector[v1.NodeSchedulable] = "true"
 	nodeSelectors := t.clusterConfig.GetNodeSelectors()
 	for k, v := range nodeSelectors {
@@ -1039,6 +1199,9 @@ func (t *templateService) renderLaunchManifest(vmi *v1.VirtualMachineInstance, t
 	}
 	podLabels[v1.AppLabel] = "virt-launcher"
 	podLabels[v1.CreatedByLabel] = string(vmi.UID)
+	if vmi.Spec.WorkloadClass != "" {
+		podLabels[v1.WorkloadClassLabel] = vmi.Spec.WorkloadClass
+	}
 
 	for i, requestedHookSidecar := range requestedHookSidecarList {
 		resources := k8sv1.ResourceRequirements{}
@@ -1084,6 +1247,10 @@ func (t *templateService) renderLaunchManifest(vmi *v1.VirtualMachineInstance, t
 		}
 		annotationsList[k] = v
 	}
+	if _, exists := vmi.Annotations[v1.DrainGracePeriodSecondsAnnotation]; exists {
+		// let the pod carry the effective drain grace period, after validation and capping
+		annotationsList[v1.DrainGracePeriodSecondsAnnotation] = strconv.FormatInt(drainGracePeriodSeconds, 10)
+	}
 
 	cniAnnotations, err := getCniAnnotations(vmi)
 	if err != nil {
@@ -1178,7 +1345,7 @@ func (t *templateService) renderLaunchManifest(vmi *v1.VirtualMachineInstance, t
 	}
 
 	// If an SELinux type was specified, use that--otherwise don't set an SELinux type
-	selinuxType := t.clusterConfig.GetSELinuxLauncherType()
+	selinuxType := getSELinuxLauncherType(vmi, t.clusterConfig.GetSELinuxLauncherType())
 	if selinuxType != "" {
 		alignPodMultiCategorySecurity(&pod, selinuxType)
 	}
@@ -1191,6 +1358,10 @@ func (t *templateService) renderLaunchManifest(vmi *v1.VirtualMachineInstance, t
 		pod.Spec.Affinity = vmi.Spec.Affinity.DeepCopy()
 	}
 
+	if vmi.Spec.WorkloadClass != "" {
+		addWorkloadClassAntiAffinity(vmi.Spec.WorkloadClass, &pod)
+	}
+
 	if t.clusterConfig.CPUNodeDiscoveryEnabled() {
 		SetNodeAffinityForForbiddenFeaturePolicy(vmi, &pod)
 	}
@@ -1249,7 +1420,7 @@ func (t *templateService) RenderHotplugAttachmentPodTemplate(volume *v1.Volume,
 					SecurityContext: &k8sv1.SecurityContext{
 						SELinuxOptions: &k8sv1.SELinuxOptions{
 							Level: "s0",
-							Type:  t.clusterConfig.GetSELinuxLauncherType(),
+							Type:  getSELinuxLauncherType(vmi, t.clusterConfig.GetSELinuxLauncherType()),
 						},
 					},
 				},
@@ -1309,6 +1480,83 @@ func (t *templateService) RenderHotplugAttachmentPodTemplate(volume *v1.Volume,
 	return pod, nil
 }
 
+// RenderMemoryDumpTargetPodTemplate renders a pod next to the virt-launcher pod, which mounts the claim a memory dump is
+// written to. The pod marks the root of the claim, so that virt-handler can find its mount and bind it into virt-launcher.
+func (t *templateService) RenderMemoryDumpTargetPodTemplate(ownerPod *k8sv1.Pod, vmi *v1.VirtualMachineInstance, claimName string) (*k8sv1.Pod, error) {
+	zero := int64(0)
+	pod := &k8sv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "memory-dump-",
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(ownerPod, schema.GroupVersionKind{
+					Group:   k8sv1.SchemeGroupVersion.Group,
+					Version: k8sv1.SchemeGroupVersion.Version,
+					Kind:    "Pod",
+				}),
+			},
+			Labels: map[string]string{
+				v1.AppLabel: MemoryDumpAppLabel,
+			},
+		},
+		Spec: k8sv1.PodSpec{
+			Containers: []k8sv1.Container{
+				{
+					Name:    "memory-dump",
+					Image:   t.launcherImage,
+					Command: []string{"/bin/sh", "-c", fmt.Sprintf("touch /pvc/%s && tail -f /dev/null", hotplugdisk.MemoryDumpMarkerFileName)},
+					Resources: k8sv1.ResourceRequirements{
+						Limits: map[k8sv1.ResourceName]resource.Quantity{
+							k8sv1.ResourceCPU:    resource.MustParse("100m"),
+							k8sv1.ResourceMemory: resource.MustParse("80M"),
+						},
+						Requests: map[k8sv1.ResourceName]resource.Quantity{
+							k8sv1.ResourceCPU:    resource.MustParse("10m"),
+							k8sv1.ResourceMemory: resource.MustParse("2M"),
+						},
+					},
+					SecurityContext: &k8sv1.SecurityContext{
+						SELinuxOptions: &k8sv1.SELinuxOptions{
+							Level: "s0",
+							Type:  getSELinuxLauncherType(vmi, t.clusterConfig.GetSELinuxLauncherType()),
+						},
+					},
+					VolumeMounts: []k8sv1.VolumeMount{
+						{
+							Name:      "memory-dump",
+							MountPath: "/pvc",
+						},
+					},
+				},
+			},
+			Affinity: &k8sv1.Affinity{
+				PodAffinity: &k8sv1.PodAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: []k8sv1.PodAffinityTerm{
+						{
+							LabelSelector: &metav1.LabelSelector{
+								MatchLabels: ownerPod.GetLabels(),
+							},
+							TopologyKey: "kubernetes.io/hostname",
+						},
+					},
+				},
+			},
+			Volumes: []k8sv1.Volume{
+				{
+					Name: "memory-dump",
+					VolumeSource: k8sv1.VolumeSource{
+						PersistentVolumeClaim: &k8sv1.PersistentVolumeClaimVolumeSource{
+							ClaimName: claimName,
+						},
+					},
+				},
+			},
+			HostNetwork:                   true,
+			TerminationGracePeriodSeconds: &zero,
+		},
+	}
+	return pod, nil
+}
+
 func getRequiredCapabilities(vmi *v1.VirtualMachineInstance) []k8sv1.Capability {
 	res := []k8sv1.Capability{}
 	if (len(vmi.Spec.Domain.Devices.Interfaces) > 0) ||
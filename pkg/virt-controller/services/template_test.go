@@ -265,6 +265,37 @@ var _ = Describe("Template", func() {
 				Expect(pod.Spec.Subdomain).To(BeEmpty())
 			})
 		})
+		Context("with a drain grace period annotation", func() {
+			AfterEach(func() {
+				disableFeatureGates()
+			})
+
+			table.DescribeTable("should pass the effective grace period to virt-launcher", func(value string, expectedGracePeriod string, expectedKillAfter int64) {
+				testutils.UpdateFakeClusterConfig(configMapInformer, &kubev1.ConfigMap{
+					Data: map[string]string{virtconfig.MaxDrainGracePeriodSecondsKey: "600"},
+				})
+				vmi := v1.VirtualMachineInstance{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "testvmi", Namespace: "default", UID: "1234",
+						Annotations: map[string]string{v1.DrainGracePeriodSecondsAnnotation: value},
+					},
+					Spec: v1.VirtualMachineInstanceSpec{Volumes: []v1.Volume{}, Domain: v1.DomainSpec{
+						Devices: v1.Devices{
+							DisableHotplug: true,
+						},
+					}},
+				}
+				pod, err := svc.RenderLaunchManifest(&vmi)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(pod.Spec.Containers[0].Command).To(ContainElement(expectedGracePeriod))
+				Expect(*pod.Spec.TerminationGracePeriodSeconds).To(Equal(expectedKillAfter))
+				Expect(pod.Annotations).To(HaveKey(v1.DrainGracePeriodSecondsAnnotation))
+			},
+				table.Entry("extending the default grace period", "300", "315", int64(330)),
+				table.Entry("capped to the cluster maximum", "900", "615", int64(630)),
+				table.Entry("falling back to the default grace period if invalid", "-5", "45", int64(60)),
+			)
+		})
 		Context("with SELinux types", func() {
 			It("should run under the SELinux type virt_launcher.process if none specified", func() {
 				vmi := v1.VirtualMachineInstance{
@@ -304,6 +335,49 @@ var _ = Describe("Template", func() {
 				Expect(pod.Spec.SecurityContext.SELinuxOptions).ToNot(BeNil())
 				Expect(pod.Spec.SecurityContext.SELinuxOptions.Type).To(Equal("spc_t"))
 			})
+			It("should run under the SELinux type of the VMI and only schedule where it is available", func() {
+				testutils.UpdateFakeClusterConfig(configMapInformer, &kubev1.ConfigMap{
+					Data: map[string]string{virtconfig.SELinuxLauncherTypeKey: "spc_t"},
+				})
+				vmi := v1.VirtualMachineInstance{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "testvmi", Namespace: "default", UID: "1234",
+					},
+					Spec: v1.VirtualMachineInstanceSpec{Volumes: []v1.Volume{}, Domain: v1.DomainSpec{
+						Devices: v1.Devices{
+							DisableHotplug: true,
+						},
+						SELinux: &v1.DomainSELinux{LauncherType: "virt_custom_t"},
+					}},
+				}
+				pod, err := svc.RenderLaunchManifest(&vmi)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(pod.Spec.SecurityContext.SELinuxOptions.Type).To(Equal("virt_custom_t"))
+				Expect(pod.Spec.NodeSelector).To(HaveKeyWithValue("selinux-type.node.kubevirt.io/virt_custom_t", "true"))
+				for _, c := range pod.Spec.Containers {
+					if c.Name != "compute" {
+						Expect(c.SecurityContext.SELinuxOptions.Type).To(Equal("virt_custom_t"))
+					}
+				}
+			})
+			It("should not constrain the scheduling by SELinux type if the VMI doesn't request one", func() {
+				vmi := v1.VirtualMachineInstance{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "testvmi", Namespace: "default", UID: "1234",
+					},
+					Spec: v1.VirtualMachineInstanceSpec{Volumes: []v1.Volume{}, Domain: v1.DomainSpec{
+						Devices: v1.Devices{
+							DisableHotplug: true,
+						},
+						SELinux: &v1.DomainSELinux{},
+					}},
+				}
+				pod, err := svc.RenderLaunchManifest(&vmi)
+				Expect(err).ToNot(HaveOccurred())
+				for label := range pod.Spec.NodeSelector {
+					Expect(label).ToNot(HavePrefix(v1.SELinuxLauncherTypeLabel))
+				}
+			})
 			It("should have a level of s0 on all but compute if a type is specified", func() {
 				testutils.UpdateFakeClusterConfig(configMapInformer, &kubev1.ConfigMap{
 					Data: map[string]string{virtconfig.SELinuxLauncherTypeKey: "spc_t"},
@@ -560,6 +634,146 @@ var _ = Describe("Template", func() {
 			})
 		})
 
+		Context("with SMBIOS secret", func() {
+			It("should mount the secret referenced by the firmware", func() {
+				vmi := v1.VirtualMachineInstance{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "testvmi",
+						Namespace: "default",
+						UID:       "1234",
+					},
+					Spec: v1.VirtualMachineInstanceSpec{
+						Domain: v1.DomainSpec{
+							Firmware: &v1.Firmware{
+								SMBiosSecretRef: &kubev1.LocalObjectReference{Name: "my-smbios"},
+							},
+						},
+					},
+				}
+
+				pod, err := svc.RenderLaunchManifest(&vmi)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(pod.Spec.Volumes).To(ContainElement(kubev1.Volume{
+					Name: "smbios-secret",
+					VolumeSource: kubev1.VolumeSource{
+						Secret: &kubev1.SecretVolumeSource{SecretName: "my-smbios"},
+					},
+				}))
+				Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(kubev1.VolumeMount{
+					Name:      "smbios-secret",
+					MountPath: "/var/run/kubevirt-private/smbios-secret",
+					ReadOnly:  true,
+				}))
+			})
+		})
+
+		Context("with Secure Boot certificates", func() {
+			It("should mount the secret of each certificate store", func() {
+				vmi := v1.VirtualMachineInstance{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "testvmi",
+						Namespace: "default",
+						UID:       "1234",
+					},
+					Spec: v1.VirtualMachineInstanceSpec{
+						Domain: v1.DomainSpec{
+							Firmware: &v1.Firmware{
+								Bootloader: &v1.Bootloader{
+									EFI: &v1.EFI{
+										SecureBootCertificates: &v1.SecureBootCertificates{
+											PK:  &kubev1.LocalObjectReference{Name: "my-pk"},
+											KEK: &kubev1.LocalObjectReference{Name: "my-kek"},
+											DB:  &kubev1.LocalObjectReference{Name: "my-db"},
+										},
+									},
+								},
+							},
+						},
+					},
+				}
+
+				pod, err := svc.RenderLaunchManifest(&vmi)
+				Expect(err).ToNot(HaveOccurred())
+
+				for store, secretName := range map[string]string{"pk": "my-pk", "kek": "my-kek", "db": "my-db"} {
+					Expect(pod.Spec.Volumes).To(ContainElement(kubev1.Volume{
+						Name: "secure-boot-" + store,
+						VolumeSource: kubev1.VolumeSource{
+							Secret: &kubev1.SecretVolumeSource{SecretName: secretName},
+						},
+					}))
+					Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(kubev1.VolumeMount{
+						Name:      "secure-boot-" + store,
+						MountPath: "/var/run/kubevirt-private/secure-boot-certificates/" + store,
+						ReadOnly:  true,
+					}))
+				}
+			})
+		})
+
+		Context("with host device ROMs", func() {
+			It("should mount the config map or secret of each ROM", func() {
+				vmi := v1.VirtualMachineInstance{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "testvmi",
+						Namespace: "default",
+						UID:       "1234",
+					},
+					Spec: v1.VirtualMachineInstanceSpec{
+						Domain: v1.DomainSpec{
+							Devices: v1.Devices{
+								HostDevices: []v1.HostDevice{
+									{
+										Name:       "gpu1",
+										DeviceName: "vendor.com/gpu",
+										ROM:        &v1.HostDeviceROM{ConfigMap: &kubev1.LocalObjectReference{Name: "my-rom"}},
+									},
+									{
+										Name:       "nic1",
+										DeviceName: "vendor.com/nic",
+										ROM:        &v1.HostDeviceROM{Secret: &kubev1.LocalObjectReference{Name: "my-secret-rom"}},
+									},
+									{
+										Name:       "nic2",
+										DeviceName: "vendor.com/nic",
+									},
+								},
+							},
+						},
+					},
+				}
+
+				pod, err := svc.RenderLaunchManifest(&vmi)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(pod.Spec.Volumes).To(ContainElement(kubev1.Volume{
+					Name: "host-device-rom-gpu1",
+					VolumeSource: kubev1.VolumeSource{
+						ConfigMap: &kubev1.ConfigMapVolumeSource{
+							LocalObjectReference: kubev1.LocalObjectReference{Name: "my-rom"},
+						},
+					},
+				}))
+				Expect(pod.Spec.Volumes).To(ContainElement(kubev1.Volume{
+					Name: "host-device-rom-nic1",
+					VolumeSource: kubev1.VolumeSource{
+						Secret: &kubev1.SecretVolumeSource{SecretName: "my-secret-rom"},
+					},
+				}))
+				for _, name := range []string{"gpu1", "nic1"} {
+					Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(kubev1.VolumeMount{
+						Name:      "host-device-rom-" + name,
+						MountPath: "/var/run/kubevirt-private/host-device-rom/" + name,
+						ReadOnly:  true,
+					}))
+				}
+				for _, volume := range pod.Spec.Volumes {
+					Expect(volume.Name).ToNot(Equal("host-device-rom-nic2"))
+				}
+			})
+		})
+
 		Context("with cloud-init user secret", func() {
 			It("should add volume with secret referenced by cloud-init user secret ref", func() {
 				vmi := v1.VirtualMachineInstance{
@@ -1259,6 +1473,89 @@ var _ = Describe("Template", func() {
 				Expect(pod.Spec.Affinity).To(BeEquivalentTo(&kubev1.Affinity{PodAntiAffinity: &podAntiAffinity}))
 			})
 
+			Context("with a workload class", func() {
+				workloadClassTerm := kubev1.WeightedPodAffinityTerm{
+					Weight: 100,
+					PodAffinityTerm: kubev1.PodAffinityTerm{
+						LabelSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{v1.WorkloadClassLabel: "control-plane"},
+						},
+						TopologyKey: kubev1.LabelHostname,
+					},
+				}
+
+				newWorkloadClassVMI := func(affinity *kubev1.Affinity) *v1.VirtualMachineInstance {
+					return &v1.VirtualMachineInstance{
+						ObjectMeta: metav1.ObjectMeta{Name: "testvm", Namespace: "default", UID: "1234"},
+						Spec: v1.VirtualMachineInstanceSpec{
+							WorkloadClass: "control-plane",
+							Affinity:      affinity,
+							Domain: v1.DomainSpec{
+								Devices: v1.Devices{
+									DisableHotplug: true,
+								},
+							},
+						},
+					}
+				}
+
+				It("should label the pod and spread the pods of the class across nodes", func() {
+					pod, err := svc.RenderLaunchManifest(newWorkloadClassVMI(nil))
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(pod.Labels).To(HaveKeyWithValue(v1.WorkloadClassLabel, "control-plane"))
+					Expect(pod.Spec.Affinity).To(Equal(&kubev1.Affinity{
+						PodAntiAffinity: &kubev1.PodAntiAffinity{
+							PreferredDuringSchedulingIgnoredDuringExecution: []kubev1.WeightedPodAffinityTerm{workloadClassTerm},
+						},
+					}))
+				})
+
+				It("should keep the affinity of the VMI", func() {
+					userTerm := kubev1.PodAffinityTerm{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+						TopologyKey:   "topology.kubernetes.io/zone",
+					}
+					nodeAffinity := &kubev1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &kubev1.NodeSelector{
+							NodeSelectorTerms: []kubev1.NodeSelectorTerm{{
+								MatchExpressions: []kubev1.NodeSelectorRequirement{{Key: "disk", Operator: kubev1.NodeSelectorOpExists}},
+							}},
+						},
+					}
+					vmi := newWorkloadClassVMI(&kubev1.Affinity{
+						NodeAffinity: nodeAffinity,
+						PodAntiAffinity: &kubev1.PodAntiAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution:  []kubev1.PodAffinityTerm{userTerm},
+							PreferredDuringSchedulingIgnoredDuringExecution: []kubev1.WeightedPodAffinityTerm{{Weight: 10, PodAffinityTerm: userTerm}},
+						},
+					})
+					pod, err := svc.RenderLaunchManifest(vmi)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(pod.Spec.Affinity.NodeAffinity).To(Equal(nodeAffinity))
+					Expect(pod.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(Equal([]kubev1.PodAffinityTerm{userTerm}))
+					Expect(pod.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution).To(Equal([]kubev1.WeightedPodAffinityTerm{
+						{Weight: 10, PodAffinityTerm: userTerm},
+						workloadClassTerm,
+					}))
+					// the affinity of the VMI itself is left untouched
+					Expect(vmi.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution).To(HaveLen(1))
+				})
+
+				It("should not duplicate a term the VMI already has", func() {
+					vmi := newWorkloadClassVMI(&kubev1.Affinity{
+						PodAntiAffinity: &kubev1.PodAntiAffinity{
+							PreferredDuringSchedulingIgnoredDuringExecution: []kubev1.WeightedPodAffinityTerm{workloadClassTerm},
+						},
+					})
+					pod, err := svc.RenderLaunchManifest(vmi)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(pod.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution).To(HaveLen(1))
+				})
+			})
+
 			It("should add tolerations to pod", func() {
 				podToleration := kubev1.Toleration{Key: "test"}
 				var tolerationSeconds int64 = 14
@@ -1438,6 +1735,47 @@ var _ = Describe("Template", func() {
 				Expect(pod.Spec.Containers[0].Resources.Requests.Memory().String()).To(Equal("1G"))
 				Expect(pod.Spec.Containers[0].Resources.Limits.Memory().String()).To(Equal("2180211045"))
 			})
+			table.DescribeTable("should request the share of the guest memory of a vmi overcommitting its memory", func(overcommit uint32, overcommitGuestOverhead bool, expectedRequest string) {
+				guestMemory := resource.MustParse("3072M")
+				vmi := v1.VirtualMachineInstance{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "testvmi",
+						Namespace: "default",
+						UID:       "1234",
+					},
+					Spec: v1.VirtualMachineInstanceSpec{
+						Domain: v1.DomainSpec{
+							Devices: v1.Devices{
+								DisableHotplug: true,
+							},
+							Memory: &v1.Memory{
+								Guest:             &guestMemory,
+								OvercommitPercent: &overcommit,
+							},
+							Resources: v1.ResourceRequirements{
+								OvercommitGuestOverhead: overcommitGuestOverhead,
+							},
+						},
+					},
+				}
+
+				pod, err := svc.RenderLaunchManifest(&vmi)
+				Expect(err).ToNot(HaveOccurred())
+
+				expected := resource.MustParse(expectedRequest)
+				request := pod.Spec.Containers[0].Resources.Requests.Memory()
+				if overcommitGuestOverhead {
+					Expect(request.Cmp(expected)).To(BeZero())
+				} else {
+					Expect(request.Cmp(expected)).To(Equal(1))
+				}
+			},
+				table.Entry("of all of it at 100 percent", uint32(100), true, "3072M"),
+				table.Entry("of two thirds of it at 150 percent", uint32(150), true, "2048M"),
+				table.Entry("of half of it at 200 percent", uint32(200), true, "1536M"),
+				table.Entry("of a third of it at 300 percent", uint32(300), true, "1024M"),
+				table.Entry("with the overhead on top unless it is overcommitted too", uint32(200), false, "1536M"),
+			)
 			It("should not add unset resources", func() {
 
 				vmi := v1.VirtualMachineInstance{
@@ -2372,10 +2710,37 @@ var _ = Describe("Template", func() {
 				Expect(readinessProbe.FailureThreshold).To(Equal(vmi.Spec.ReadinessProbe.FailureThreshold))
 			})
 
-			It("should not set a readiness probe on the pod, if no one was specified on the vmi", func() {
+			It("should gate the readiness of the pod on virt-launcher, if no one was specified on the vmi", func() {
 				vmi.Spec.ReadinessProbe = nil
 				pod, err := svc.RenderLaunchManifest(vmi)
 				Expect(err).ToNot(HaveOccurred())
+				readinessProbe := pod.Spec.Containers[0].ReadinessProbe
+				Expect(readinessProbe).ToNot(BeNil())
+				Expect(readinessProbe.Handler.Exec).ToNot(BeNil())
+				Expect(readinessProbe.Handler.Exec.Command).To(Equal([]string{"cat", "/var/run/kubevirt/sockets/launcher-ready"}))
+				Expect(readinessProbe.Handler.HTTPGet).To(BeNil())
+				Expect(readinessProbe.Handler.TCPSocket).To(BeNil())
+			})
+
+			It("should evaluate the liveness probe as readiness probe, if the vmi migrates on liveness probe failures", func() {
+				vmi.Spec.ReadinessProbe = nil
+				vmi.Spec.LivenessProbeFailureAction = v1.LivenessProbeFailureActionMigrate
+				pod, err := svc.RenderLaunchManifest(vmi)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(pod.Spec.Containers[0].LivenessProbe).To(BeNil())
+				readinessProbe := pod.Spec.Containers[0].ReadinessProbe
+				Expect(readinessProbe).ToNot(BeNil())
+				Expect(readinessProbe.Handler.Exec).To(BeNil())
+				Expect(readinessProbe.Handler.TCPSocket).To(Equal(vmi.Spec.LivenessProbe.TCPSocket))
+				Expect(readinessProbe.Handler.HTTPGet).To(Equal(vmi.Spec.LivenessProbe.HTTPGet))
+				Expect(readinessProbe.InitialDelaySeconds).To(Equal(vmi.Spec.LivenessProbe.InitialDelaySeconds + LibvirtStartupDelay))
+				Expect(readinessProbe.FailureThreshold).To(Equal(vmi.Spec.LivenessProbe.FailureThreshold))
+			})
+
+			It("should not set a readiness probe on the temporary pod", func() {
+				vmi.Spec.ReadinessProbe = nil
+				pod, err := svc.RenderLaunchManifestNoVm(vmi)
+				Expect(err).ToNot(HaveOccurred())
 				Expect(pod.Spec.Containers[0].ReadinessProbe).To(BeNil())
 			})
 		})
@@ -2553,6 +2918,36 @@ var _ = Describe("Template", func() {
 			Expect(pod.Spec.Containers[0].Command).To(ContainElement("42"), "command arg value should be correct")
 		})
 
+		It("should add the guest agent command allow-list argument to the template", func() {
+			testutils.UpdateFakeClusterConfig(configMapInformer, &kubev1.ConfigMap{
+				Data: map[string]string{virtconfig.GuestAgentCommandAllowListKey: "guest-ping,guest-info"},
+			})
+
+			vmi := v1.VirtualMachineInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "testvmi", Namespace: "default", UID: "1234",
+				},
+				Spec: v1.VirtualMachineInstanceSpec{Volumes: []v1.Volume{}, Domain: v1.DomainSpec{}},
+			}
+			pod, err := svc.RenderLaunchManifest(&vmi)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(pod.Spec.Containers[0].Command).To(ContainElements("--allowed-guest-agent-commands", "guest-ping,guest-info"))
+		})
+
+		It("should not restrict guest agent commands if no allow-list is configured", func() {
+			vmi := v1.VirtualMachineInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "testvmi", Namespace: "default", UID: "1234",
+				},
+				Spec: v1.VirtualMachineInstanceSpec{Volumes: []v1.Volume{}, Domain: v1.DomainSpec{}},
+			}
+			pod, err := svc.RenderLaunchManifest(&vmi)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(pod.Spec.Containers[0].Command).ToNot(ContainElement("--allowed-guest-agent-commands"))
+		})
+
 		Context("with specified priorityClass", func() {
 			It("should add priorityClass", func() {
 				vmi := v1.VirtualMachineInstance{
@@ -2625,6 +3020,34 @@ var _ = Describe("Template", func() {
 		})
 
 	})
+
+	Describe("RenderMemoryDumpTargetPodTemplate", func() {
+
+		It("Should mount the claim next to the virt-launcher pod", func() {
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.Namespace = "default"
+			launcherPod := &kubev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "virt-launcher-testvmi",
+					Namespace: "default",
+					UID:       "launcher-uid",
+					Labels:    map[string]string{v1.CreatedByLabel: "1234"},
+				},
+			}
+
+			pod, err := svc.RenderMemoryDumpTargetPodTemplate(launcherPod, vmi, "dump-claim")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pod.Labels).To(HaveKeyWithValue(v1.AppLabel, MemoryDumpAppLabel))
+			Expect(pod.OwnerReferences).To(HaveLen(1))
+			Expect(pod.OwnerReferences[0].UID).To(Equal(launcherPod.UID))
+			Expect(*pod.OwnerReferences[0].Controller).To(BeTrue())
+			Expect(pod.Spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector.MatchLabels).To(Equal(launcherPod.Labels))
+			Expect(pod.Spec.Volumes).To(HaveLen(1))
+			Expect(pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal("dump-claim"))
+			Expect(pod.Spec.Containers[0].VolumeMounts).To(ConsistOf(kubev1.VolumeMount{Name: pod.Spec.Volumes[0].Name, MountPath: "/pvc"}))
+			Expect(pod.Spec.Containers[0].Command[2]).To(ContainSubstring("touch /pvc/.kubevirt-memory-dump"))
+		})
+	})
 })
 
 var _ = Describe("getResourceNameForNetwork", func() {
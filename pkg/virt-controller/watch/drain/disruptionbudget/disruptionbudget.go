@@ -332,17 +332,18 @@ func (c *DisruptionBudgetController) sync(key string, vmi *virtv1.VirtualMachine
 	if vmi == nil && pdb != nil {
 		delete = true
 	} else if vmi != nil {
-		wantsToMigrate := wantsToMigrateOnDrain(vmi)
-		if vmi.DeletionTimestamp != nil && pdb != nil {
+		wantsPDB := wantsPDBOnDrain(vmi)
+		if vmi.DeletionTimestamp != nil && pdb != nil && !(vmi.IsProtectedUntilDrained() && !vmi.IsFinal()) {
 			// pdb can already be deleted, shutdown already in process
+			// VMIs protected until drained keep their pdb until the graceful shutdown is done
 			delete = true
-		} else if !wantsToMigrate && pdb != nil {
+		} else if !wantsPDB && pdb != nil {
 			// We don't want migrations on evictions, if there is a pdb, remove it
 			delete = true
-		} else if wantsToMigrate && vmi.DeletionTimestamp == nil && pdb == nil {
-			// No pdb and we want migrations on evictions
+		} else if wantsPDB && vmi.DeletionTimestamp == nil && pdb == nil {
+			// No pdb and we want migrations or a graceful shutdown on evictions
 			create = true
-		} else if wantsToMigrate && pdb != nil {
+		} else if wantsPDB && pdb != nil {
 			if ownerRef := v1.GetControllerOf(pdb); ownerRef != nil && ownerRef.UID != vmi.UID {
 				// The pdb is from an old vmi with a different uid, delete and later create the correct one
 				// The VMI always has a minimum grace period, so normally this should not happen, therefore no optimizations
@@ -366,7 +367,21 @@ func (c *DisruptionBudgetController) sync(key string, vmi *virtv1.VirtualMachine
 		c.recorder.Eventf(vmi, v12.EventTypeNormal, SuccessfulDeletePodDisruptionBudgetReason, "Deleted PodDisruptionBudget %s", pdb.Name)
 		return nil
 	} else if create {
-		two := intstr.FromInt(2)
+		spec := v1beta1.PodDisruptionBudgetSpec{
+			Selector: &v1.LabelSelector{
+				MatchLabels: map[string]string{
+					virtv1.CreatedByLabel: string(vmi.UID),
+				},
+			},
+		}
+		if vmi.IsProtectedUntilDrained() {
+			// No disruption at all, the pod goes away once the VMI got shut down
+			zero := intstr.FromInt(0)
+			spec.MaxUnavailable = &zero
+		} else {
+			two := intstr.FromInt(2)
+			spec.MinAvailable = &two
+		}
 		c.podDisruptionBudgetExpectations.ExpectCreations(key, 1)
 		createdPDB, err := c.clientset.PolicyV1beta1().PodDisruptionBudgets(vmi.Namespace).Create(context.Background(), &v1beta1.PodDisruptionBudget{
 			ObjectMeta: v1.ObjectMeta{
@@ -375,14 +390,7 @@ func (c *DisruptionBudgetController) sync(key string, vmi *virtv1.VirtualMachine
 				},
 				GenerateName: "kubevirt-disruption-budget-",
 			},
-			Spec: v1beta1.PodDisruptionBudgetSpec{
-				MinAvailable: &two,
-				Selector: &v1.LabelSelector{
-					MatchLabels: map[string]string{
-						virtv1.CreatedByLabel: string(vmi.UID),
-					},
-				},
-			},
+			Spec: spec,
 		}, v1.CreateOptions{})
 		if err != nil {
 			c.podDisruptionBudgetExpectations.CreationObserved(key)
@@ -410,11 +418,12 @@ func (c *DisruptionBudgetController) pdbForVMI(namespace, name string) (*v1beta1
 	return nil, nil
 }
 
-func wantsToMigrateOnDrain(vmi *virtv1.VirtualMachineInstance) bool {
+func wantsPDBOnDrain(vmi *virtv1.VirtualMachineInstance) bool {
 	if vmi.Spec.EvictionStrategy == nil {
 		return false
 	}
-	if *vmi.Spec.EvictionStrategy == virtv1.EvictionStrategyLiveMigrate {
+	switch *vmi.Spec.EvictionStrategy {
+	case virtv1.EvictionStrategyLiveMigrate, virtv1.EvictionStrategyProtectUntilDrained:
 		return true
 	}
 	return false
@@ -79,6 +79,18 @@ var _ = Describe("Disruptionbudget", func() {
 		})
 	}
 
+	shouldExpectProtectingPDBCreation := func(uid types.UID) {
+		kubeClient.Fake.PrependReactor("create", "poddisruptionbudgets", func(action testing.Action) (handled bool, obj runtime.Object, err error) {
+			create, ok := action.(testing.CreateAction)
+			Expect(ok).To(BeTrue())
+			pdb := create.GetObject().(*v1beta1.PodDisruptionBudget)
+			Expect(pdb.Spec.MinAvailable).To(BeNil())
+			Expect(pdb.Spec.MaxUnavailable.String()).To(Equal("0"))
+			Expect(pdb.Spec.Selector.MatchLabels[v1.CreatedByLabel]).To(Equal(string(uid)))
+			return true, create.GetObject(), nil
+		})
+	}
+
 	BeforeEach(func() {
 		stop = make(chan struct{})
 		ctrl = gomock.NewController(GinkgoT())
@@ -263,6 +275,67 @@ var _ = Describe("Disruptionbudget", func() {
 		})
 	})
 
+	Context("A VirtualMachineInstance given which is protected until drained", func() {
+
+		newProtectedVirtualMachine := func() *v1.VirtualMachineInstance {
+			vmi := newVirtualMachine("testvm")
+			strategy := v1.EvictionStrategyProtectUntilDrained
+			vmi.Spec.EvictionStrategy = &strategy
+			vmi.Status.Phase = v1.Running
+			return vmi
+		}
+
+		It("should add a pdb which does not allow any disruption", func() {
+			vmi := newProtectedVirtualMachine()
+			addVirtualMachine(vmi)
+
+			shouldExpectProtectingPDBCreation(vmi.UID)
+			controller.Execute()
+			testutils.ExpectEvent(recorder, disruptionbudget.SuccessfulCreatePodDisruptionBudgetReason)
+		})
+
+		It("should keep the pdb while the VMI is shutting down", func() {
+			vmi := newProtectedVirtualMachine()
+			now := v13.Now()
+			vmi.DeletionTimestamp = &now
+			addVirtualMachine(vmi)
+			pdbFeeder.Add(newPodDisruptionBudget(vmi))
+
+			controller.Execute()
+		})
+
+		It("should remove the pdb once the VMI is shut down", func() {
+			vmi := newProtectedVirtualMachine()
+			now := v13.Now()
+			vmi.DeletionTimestamp = &now
+			addVirtualMachine(vmi)
+			pdb := newPodDisruptionBudget(vmi)
+			pdbFeeder.Add(pdb)
+
+			controller.Execute()
+
+			vmi.Status.Phase = v1.Succeeded
+			vmiFeeder.Modify(vmi)
+			shouldExpectPDBDeletion(pdb)
+			controller.Execute()
+			testutils.ExpectEvent(recorder, disruptionbudget.SuccessfulDeletePodDisruptionBudgetReason)
+		})
+
+		It("should remove the pdb if the VMI disappears", func() {
+			vmi := newProtectedVirtualMachine()
+			addVirtualMachine(vmi)
+			pdb := newPodDisruptionBudget(vmi)
+			pdbFeeder.Add(pdb)
+
+			controller.Execute()
+
+			vmiFeeder.Delete(vmi)
+			shouldExpectPDBDeletion(pdb)
+			controller.Execute()
+			testutils.ExpectEvent(recorder, disruptionbudget.SuccessfulDeletePodDisruptionBudgetReason)
+		})
+	})
+
 	AfterEach(func() {
 		close(stop)
 		// Ensure that we add checks for expected events to every test
@@ -8,6 +8,7 @@ import (
 
 	k8sv1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
@@ -28,6 +29,10 @@ const (
 	FailedCreateVirtualMachineInstanceMigrationReason = "FailedCreate"
 	// SuccessfulCreateVirtualMachineInstanceMigrationReason is added in an event if creating a VirtualMachineInstanceMigration succeeded.
 	SuccessfulCreateVirtualMachineInstanceMigrationReason = "SuccessfulCreate"
+	// FailedShutdownVirtualMachineInstanceReason is added in an event if shutting down a VirtualMachineInstance protected until drained failed.
+	FailedShutdownVirtualMachineInstanceReason = "FailedShutdown"
+	// SuccessfulShutdownVirtualMachineInstanceReason is added in an event if shutting down a VirtualMachineInstance protected until drained succeeded.
+	SuccessfulShutdownVirtualMachineInstanceReason = "SuccessfulShutdown"
 )
 
 type EvacuationController struct {
@@ -368,6 +373,15 @@ func (c *EvacuationController) sync(node *k8sv1.Node, vmisOnNode []*virtv1.Virtu
 		return nil
 	}
 
+	// a failed shutdown must not hold back the migrations of the other VMIs
+	shutdownErr := c.shutdownProtectedVMIs(vmisToMigrate)
+	migrationErr := c.migrateVMIs(node, vmisToMigrate, activeMigrations)
+	return utilerrors.NewAggregate([]error{shutdownErr, migrationErr})
+}
+
+// migrateVMIs creates the migrations of the VMIs to migrate away from the node, as many as the
+// parallel migrations of the cluster allow.
+func (c *EvacuationController) migrateVMIs(node *k8sv1.Node, vmisToMigrate []*virtv1.VirtualMachineInstance, activeMigrations []*virtv1.VirtualMachineInstanceMigration) error {
 	migrationCandidates, nonMigrateable := c.filterRunningNonMigratingVMIs(vmisToMigrate, activeMigrations)
 
 	// Don't create hundreds of pending migration objects.
@@ -441,6 +455,25 @@ func (c *EvacuationController) sync(node *k8sv1.Node, vmisOnNode []*virtv1.Virtu
 	return nil
 }
 
+// shutdownProtectedVMIs deletes the VMIs which are protected until drained.
+// Their finalizer keeps them, and with them their PDB, until virt-handler gracefully shut them down.
+func (c *EvacuationController) shutdownProtectedVMIs(vmis []*virtv1.VirtualMachineInstance) error {
+	var lastErr error
+	for _, vmi := range vmis {
+		if !vmi.IsProtectedUntilDrained() || vmi.IsFinal() || vmi.DeletionTimestamp != nil {
+			continue
+		}
+		err := c.clientset.VirtualMachineInstance(vmi.Namespace).Delete(vmi.Name, &v1.DeleteOptions{})
+		if err != nil {
+			c.recorder.Eventf(vmi, k8sv1.EventTypeWarning, FailedShutdownVirtualMachineInstanceReason, "Error shutting down the VirtualMachineInstance: %v", err)
+			lastErr = err
+			continue
+		}
+		c.recorder.Eventf(vmi, k8sv1.EventTypeNormal, SuccessfulShutdownVirtualMachineInstanceReason, "Shutting down the VirtualMachineInstance for the drain of its node")
+	}
+	return lastErr
+}
+
 func hasMigratedOnEviction(vmi *virtv1.VirtualMachineInstance) bool {
 	return vmi.Status.NodeName != vmi.Status.EvacuationNodeName
 }
@@ -1,6 +1,8 @@
 package evacuation_test
 
 import (
+	"fmt"
+
 	"github.com/golang/mock/gomock"
 	v12 "k8s.io/api/core/v1"
 	v13 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -25,6 +27,7 @@ var _ = Describe("Evacuation", func() {
 	var stop chan struct{}
 	var virtClient *kubecli.MockKubevirtClient
 	var migrationInterface *kubecli.MockVirtualMachineInstanceMigrationInterface
+	var vmiInterface *kubecli.MockVirtualMachineInstanceInterface
 	var vmiSource *framework.FakeControllerSource
 	var vmiInformer cache.SharedIndexInformer
 	var nodeSource *framework.FakeControllerSource
@@ -62,6 +65,7 @@ var _ = Describe("Evacuation", func() {
 		ctrl = gomock.NewController(GinkgoT())
 		virtClient = kubecli.NewMockKubevirtClient(ctrl)
 		migrationInterface = kubecli.NewMockVirtualMachineInstanceMigrationInterface(ctrl)
+		vmiInterface = kubecli.NewMockVirtualMachineInstanceInterface(ctrl)
 
 		vmiInformer, vmiSource = testutils.NewFakeInformerWithIndexersFor(&v1.VirtualMachineInstance{}, cache.Indexers{
 			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
@@ -82,6 +86,7 @@ var _ = Describe("Evacuation", func() {
 
 		// Set up mock client
 		virtClient.EXPECT().VirtualMachineInstanceMigration(v12.NamespaceDefault).Return(migrationInterface).AnyTimes()
+		virtClient.EXPECT().VirtualMachineInstance(v12.NamespaceDefault).Return(vmiInterface).AnyTimes()
 		kubeClient = fake.NewSimpleClientset()
 		virtClient.EXPECT().CoreV1().Return(kubeClient.CoreV1()).AnyTimes()
 		virtClient.EXPECT().PolicyV1beta1().Return(kubeClient.PolicyV1beta1()).AnyTimes()
@@ -348,6 +353,91 @@ var _ = Describe("Evacuation", func() {
 
 	})
 
+	Context("VMIs protected until drained", func() {
+
+		newProtectedVirtualMachine := func(nodeName string) *v1.VirtualMachineInstance {
+			vmi := newVirtualMachine("testvm", nodeName)
+			strategy := v1.EvictionStrategyProtectUntilDrained
+			vmi.Spec.EvictionStrategy = &strategy
+			vmi.Status.Phase = v1.Running
+			return vmi
+		}
+
+		It("should shut down a VMI marked for eviction", func() {
+			node := newNode("foo")
+			addNode(node)
+			vmi := newProtectedVirtualMachine(node.Name)
+			vmi.Status.EvacuationNodeName = node.Name
+			vmiFeeder.Add(vmi)
+
+			vmiInterface.EXPECT().Delete(vmi.Name, gomock.Any()).Return(nil)
+			controller.Execute()
+			testutils.ExpectEvent(recorder, evacuation.SuccessfulShutdownVirtualMachineInstanceReason)
+		})
+
+		It("should shut down a VMI on a node which is evacuated", func() {
+			node := newNode("foo")
+			node.Spec.Taints = append(node.Spec.Taints, *newTaint())
+			addNode(node)
+			vmiFeeder.Add(newProtectedVirtualMachine(node.Name))
+
+			vmiInterface.EXPECT().Delete("testvm", gomock.Any()).Return(nil)
+			controller.Execute()
+			testutils.ExpectEvent(recorder, evacuation.SuccessfulShutdownVirtualMachineInstanceReason)
+		})
+
+		It("should record a warning if the shutdown fails", func() {
+			node := newNode("foo")
+			addNode(node)
+			vmi := newProtectedVirtualMachine(node.Name)
+			vmi.Status.EvacuationNodeName = node.Name
+			vmiFeeder.Add(vmi)
+
+			vmiInterface.EXPECT().Delete(vmi.Name, gomock.Any()).Return(fmt.Errorf("error"))
+			controller.Execute()
+			Expect(mockQueue.GetRateLimitedEnqueueCount()).To(Equal(1))
+			testutils.ExpectEvent(recorder, evacuation.FailedShutdownVirtualMachineInstanceReason)
+		})
+
+		It("should still migrate the other VMIs if the shutdown fails", func() {
+			node := newNode("foo")
+			node.Spec.Taints = append(node.Spec.Taints, *newTaint())
+			addNode(node)
+			vmiFeeder.Add(newProtectedVirtualMachine(node.Name))
+			migratable := newVirtualMachine("migratable", node.Name)
+			migratable.Spec.EvictionStrategy = newEvictionStrategy()
+			vmiFeeder.Add(migratable)
+
+			vmiInterface.EXPECT().Delete("testvm", gomock.Any()).Return(fmt.Errorf("error"))
+			migrationInterface.EXPECT().Create(gomock.Any()).Do(func(migration *v1.VirtualMachineInstanceMigration) {
+				Expect(migration.Spec.VMIName).To(Equal("migratable"))
+			}).Return(&v1.VirtualMachineInstanceMigration{ObjectMeta: v13.ObjectMeta{Name: "something"}}, nil)
+			controller.Execute()
+			Expect(mockQueue.GetRateLimitedEnqueueCount()).To(Equal(1))
+			testutils.ExpectEvents(recorder, evacuation.FailedShutdownVirtualMachineInstanceReason, evacuation.SuccessfulCreateVirtualMachineInstanceMigrationReason)
+		})
+
+		It("should not shut down a VMI which is already shutting down", func() {
+			node := newNode("foo")
+			addNode(node)
+			vmi := newProtectedVirtualMachine(node.Name)
+			vmi.Status.EvacuationNodeName = node.Name
+			now := v13.Now()
+			vmi.DeletionTimestamp = &now
+			vmiFeeder.Add(vmi)
+
+			controller.Execute()
+		})
+
+		It("should not shut down a VMI which is not evicted", func() {
+			node := newNode("foo")
+			addNode(node)
+			vmiFeeder.Add(newProtectedVirtualMachine(node.Name))
+
+			controller.Execute()
+		})
+	})
+
 	AfterEach(func() {
 		close(stop)
 		// Ensure that we add checks for expected events to every test
@@ -28,6 +28,7 @@ import (
 	"time"
 
 	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
@@ -345,6 +346,10 @@ func (c *MigrationController) updateStatus(migration *virtv1.VirtualMachineInsta
 		case virtv1.MigrationScheduled:
 			if vmi.Status.MigrationState != nil && vmi.Status.MigrationState.TargetNode != "" {
 				migrationCopy.Status.Phase = virtv1.MigrationPreparingTarget
+				if vmi.Status.MigrationState.MigrationUID == migration.UID {
+					migrationCopy.Status.Bandwidth = copyQuantity(vmi.Status.MigrationState.Bandwidth)
+					migrationCopy.Status.ParallelMigrationThreads = copyUint32(vmi.Status.MigrationState.ParallelMigrationThreads)
+				}
 			}
 		case virtv1.MigrationPreparingTarget:
 			if vmi.Status.MigrationState.TargetNode != "" && vmi.Status.MigrationState.TargetNodeAddress != "" {
@@ -352,6 +357,19 @@ func (c *MigrationController) updateStatus(migration *virtv1.VirtualMachineInsta
 			}
 		case virtv1.MigrationTargetReady:
 			if vmi.Status.MigrationState.StartTimestamp != nil {
+				if migration.Spec.Warmup && !vmi.Status.MigrationState.CutoverRequested {
+					migrationCopy.Status.Phase = virtv1.MigrationWarmingUp
+				} else {
+					migrationCopy.Status.Phase = virtv1.MigrationRunning
+				}
+			}
+		case virtv1.MigrationWarmingUp:
+			migrationCopy.Status.Warmup = vmi.Status.MigrationState.WarmupStatus.DeepCopy()
+			if vmi.Status.MigrationState.Completed {
+				migrationCopy.Status.Phase = virtv1.MigrationSucceeded
+				c.recorder.Eventf(migration, k8sv1.EventTypeNormal, SuccessfulMigrationReason, "Source node reported migration succeeded")
+				log.Log.Object(migration).Infof("VMI reported migration succeeded.")
+			} else if vmi.Status.MigrationState.CutoverRequested {
 				migrationCopy.Status.Phase = virtv1.MigrationRunning
 			}
 		case virtv1.MigrationRunning:
@@ -434,22 +452,12 @@ func (c *MigrationController) sync(key string, migration *virtv1.VirtualMachineI
 	}
 
 	if vmi != nil && migration.DeletionTimestamp != nil &&
-		migration.Status.Phase == virtv1.MigrationRunning {
+		(migration.Status.Phase == virtv1.MigrationRunning || migration.Status.Phase == virtv1.MigrationWarmingUp) {
 		vmiCopy := vmi.DeepCopy()
 		if vmiCopy.Status.MigrationState != nil {
 			vmiCopy.Status.MigrationState.AbortRequested = true
 			if !reflect.DeepEqual(vmi.Status, vmiCopy.Status) {
-				newStatus, err := json.Marshal(vmiCopy.Status)
-				if err != nil {
-					return err
-				}
-				oldStatus, err := json.Marshal(vmi.Status)
-				if err != nil {
-					return err
-				}
-				test := fmt.Sprintf(`{ "op": "test", "path": "/status", "value": %s }`, string(oldStatus))
-				patch := fmt.Sprintf(`{ "op": "replace", "path": "/status", "value": %s }`, string(newStatus))
-				_, err = c.clientset.VirtualMachineInstance(vmi.Namespace).Patch(vmi.Name, types.JSONPatchType, []byte(fmt.Sprintf("[ %s, %s ]", test, patch)))
+				err := c.patchVMIStatus(vmi, vmiCopy)
 				if err != nil {
 					msg := fmt.Sprintf("failed to set MigrationState in VMI status. :%v", err)
 					c.recorder.Eventf(migration, k8sv1.EventTypeWarning, FailedAbortMigrationReason, msg)
@@ -461,6 +469,24 @@ func (c *MigrationController) sync(key string, migration *virtv1.VirtualMachineI
 		return nil
 	}
 
+	if vmi != nil && migration.Spec.Warmup && migration.Spec.CommitCutover &&
+		migration.Status.Phase == virtv1.MigrationWarmingUp {
+		vmiCopy := vmi.DeepCopy()
+		if vmiCopy.Status.MigrationState != nil && vmiCopy.Status.MigrationState.MigrationUID == migration.UID {
+			vmiCopy.Status.MigrationState.CutoverRequested = true
+			if !reflect.DeepEqual(vmi.Status, vmiCopy.Status) {
+				err := c.patchVMIStatus(vmi, vmiCopy)
+				if err != nil {
+					msg := fmt.Sprintf("failed to request the cutover in VMI status. :%v", err)
+					c.recorder.Eventf(migration, k8sv1.EventTypeWarning, FailedCommitCutoverReason, msg)
+					return fmt.Errorf(msg)
+				}
+				c.recorder.Eventf(migration, k8sv1.EventTypeNormal, SuccessfulCommitCutoverReason, "Migration is ready to be cut over by virt-handler.")
+			}
+		}
+		return nil
+	}
+
 	vmiDeleted := vmi == nil || vmi.DeletionTimestamp != nil
 	migrationDone := vmi.Status.MigrationState != nil && vmi.Status.MigrationState.MigrationUID == migration.UID && vmi.Status.MigrationState.EndTimestamp != nil
 
@@ -531,11 +557,15 @@ func (c *MigrationController) sync(key string, migration *virtv1.VirtualMachineI
 		// setting the target and source nodes. This kicks off the preparation stage.
 		if podExists && !podIsDown(pod) {
 			vmiCopy := vmi.DeepCopy()
+			bandwidth, parallelMigrationThreads := limitMigrationTuning(&migration.Spec, c.clusterConfig.GetMigrationConfiguration())
 			vmiCopy.Status.MigrationState = &virtv1.VirtualMachineInstanceMigrationState{
-				MigrationUID: migration.UID,
-				TargetNode:   pod.Spec.NodeName,
-				SourceNode:   vmi.Status.NodeName,
-				TargetPod:    pod.Name,
+				MigrationUID:             migration.UID,
+				TargetNode:               pod.Spec.NodeName,
+				SourceNode:               vmi.Status.NodeName,
+				TargetPod:                pod.Name,
+				Warmup:                   migration.Spec.Warmup,
+				Bandwidth:                bandwidth,
+				ParallelMigrationThreads: parallelMigrationThreads,
 			}
 
 			// By setting this label, virt-handler on the target node will receive
@@ -556,6 +586,66 @@ func (c *MigrationController) sync(key string, migration *virtv1.VirtualMachineI
 	return nil
 }
 
+// limitMigrationTuning returns the bandwidth and the number of parallel
+// connections requested by the migration, limited to the maxima of the
+// cluster. The bandwidth of the cluster is used if the migration doesn't
+// request one, a bandwidth of zero is unlimited. No parallel connections are
+// used unless requested, or if the cluster doesn't allow any.
+func limitMigrationTuning(spec *virtv1.VirtualMachineInstanceMigrationSpec, config *virtv1.MigrationConfiguration) (*resource.Quantity, *uint32) {
+	bandwidth := copyQuantity(config.BandwidthPerMigration)
+	if spec.Bandwidth != nil {
+		if bandwidth == nil || bandwidth.IsZero() ||
+			(!spec.Bandwidth.IsZero() && spec.Bandwidth.Cmp(*bandwidth) < 0) {
+			bandwidth = copyQuantity(spec.Bandwidth)
+		}
+	}
+
+	var parallelMigrationThreads *uint32
+	if spec.ParallelMigrationThreads != nil && config.MaxParallelMigrationThreads != nil {
+		threads := *spec.ParallelMigrationThreads
+		if threads > *config.MaxParallelMigrationThreads {
+			threads = *config.MaxParallelMigrationThreads
+		}
+		if threads > 0 {
+			parallelMigrationThreads = &threads
+		}
+	}
+	return bandwidth, parallelMigrationThreads
+}
+
+func copyQuantity(quantity *resource.Quantity) *resource.Quantity {
+	if quantity == nil {
+		return nil
+	}
+	copied := quantity.DeepCopy()
+	return &copied
+}
+
+func copyUint32(value *uint32) *uint32 {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+	return &copied
+}
+
+// patchVMIStatus replaces the status of the vmi with the one of vmiCopy,
+// failing if the status changed in the meantime.
+func (c *MigrationController) patchVMIStatus(vmi *virtv1.VirtualMachineInstance, vmiCopy *virtv1.VirtualMachineInstance) error {
+	newStatus, err := json.Marshal(vmiCopy.Status)
+	if err != nil {
+		return err
+	}
+	oldStatus, err := json.Marshal(vmi.Status)
+	if err != nil {
+		return err
+	}
+	test := fmt.Sprintf(`{ "op": "test", "path": "/status", "value": %s }`, string(oldStatus))
+	patch := fmt.Sprintf(`{ "op": "replace", "path": "/status", "value": %s }`, string(newStatus))
+	_, err = c.clientset.VirtualMachineInstance(vmi.Namespace).Patch(vmi.Name, types.JSONPatchType, []byte(fmt.Sprintf("[ %s, %s ]", test, patch)))
+	return err
+}
+
 func (c *MigrationController) listMatchingTargetPods(migration *virtv1.VirtualMachineInstanceMigration, vmi *virtv1.VirtualMachineInstance) ([]*k8sv1.Pod, error) {
 
 	selector, err := v1.LabelSelectorAsSelector(&v1.LabelSelector{
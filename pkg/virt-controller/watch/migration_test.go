@@ -27,6 +27,7 @@ import (
 	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -42,6 +43,7 @@ import (
 	"kubevirt.io/client-go/kubecli"
 	"kubevirt.io/client-go/log"
 	"kubevirt.io/kubevirt/pkg/testutils"
+	virtconfig "kubevirt.io/kubevirt/pkg/virt-config"
 	"kubevirt.io/kubevirt/pkg/virt-controller/services"
 )
 
@@ -582,11 +584,13 @@ var _ = Describe("Migration watcher", func() {
 			pod := newTargetPodForVirtualMachine(vmi, migration, k8sv1.PodPending)
 			pod.Spec.NodeName = "node01"
 
+			bandwidth := resource.MustParse(virtconfig.BandwithPerMigrationDefault)
 			vmi.Status.MigrationState = &v1.VirtualMachineInstanceMigrationState{
 				MigrationUID: migration.UID,
 				TargetNode:   "node01",
 				SourceNode:   "node02",
 				TargetPod:    pod.Name,
+				Bandwidth:    &bandwidth,
 			}
 			vmi.Labels[v1.MigrationTargetNodeNameLabel] = "node01"
 			addMigration(migration)
@@ -703,6 +707,271 @@ var _ = Describe("Migration watcher", func() {
 			testutils.ExpectEvent(recorder, SuccessfulAbortMigrationReason)
 		})
 	})
+
+	Context("Warm migration", func() {
+
+		var vmi *v1.VirtualMachineInstance
+		var migration *v1.VirtualMachineInstanceMigration
+		var pod *k8sv1.Pod
+
+		prepareWarmMigration := func(phase v1.VirtualMachineInstanceMigrationPhase) {
+			vmi = newVirtualMachine("testvmi", v1.Running)
+			vmi.Status.NodeName = "node02"
+			migration = newMigration("testmigration", vmi.Name, phase)
+			migration.Spec.Warmup = true
+			pod = newTargetPodForVirtualMachine(vmi, migration, k8sv1.PodPending)
+			pod.Spec.NodeName = "node01"
+			vmi.Status.MigrationState = &v1.VirtualMachineInstanceMigrationState{
+				MigrationUID:      migration.UID,
+				TargetNode:        "node01",
+				SourceNode:        "node02",
+				TargetNodeAddress: "10.10.10.10:1234",
+				StartTimestamp:    now(),
+				Warmup:            true,
+			}
+		}
+
+		addWarmMigration := func() {
+			addMigration(migration)
+			addVirtualMachineInstance(vmi)
+			podFeeder.Add(pod)
+		}
+
+		It("should hand the warmup request over to virt-handler", func() {
+			prepareWarmMigration(v1.MigrationScheduled)
+			vmi.Status.MigrationState = nil
+			addWarmMigration()
+
+			vmiInterface.EXPECT().Update(gomock.Any()).DoAndReturn(func(arg interface{}) (interface{}, interface{}) {
+				Expect(arg.(*v1.VirtualMachineInstance).Status.MigrationState.MigrationUID).To(Equal(migration.UID))
+				Expect(arg.(*v1.VirtualMachineInstance).Status.MigrationState.Warmup).To(BeTrue())
+				return arg, nil
+			})
+
+			controller.Execute()
+			testutils.ExpectEvent(recorder, SuccessfulHandOverPodReason)
+		})
+
+		It("should transition to warming up phase", func() {
+			prepareWarmMigration(v1.MigrationTargetReady)
+			addWarmMigration()
+
+			migrationInterface.EXPECT().UpdateStatus(gomock.Any()).DoAndReturn(func(arg interface{}) (interface{}, interface{}) {
+				Expect(arg.(*v1.VirtualMachineInstanceMigration).Status.Phase).To(Equal(v1.MigrationWarmingUp))
+				return arg, nil
+			})
+
+			controller.Execute()
+		})
+
+		It("should stay in warming up phase and report the warmup status until the cutover is committed", func() {
+			prepareWarmMigration(v1.MigrationWarmingUp)
+			vmi.Status.MigrationState.WarmupStatus = &v1.MigrationWarmupStatus{
+				Iteration:            3,
+				MemoryRemainingBytes: 1024,
+				Converging:           true,
+			}
+			addWarmMigration()
+
+			migrationInterface.EXPECT().UpdateStatus(gomock.Any()).DoAndReturn(func(arg interface{}) (interface{}, interface{}) {
+				status := arg.(*v1.VirtualMachineInstanceMigration).Status
+				Expect(status.Phase).To(Equal(v1.MigrationWarmingUp))
+				Expect(status.Warmup).To(Equal(vmi.Status.MigrationState.WarmupStatus))
+				return arg, nil
+			})
+
+			controller.Execute()
+		})
+
+		It("should request the cutover on the VMI once it is committed", func() {
+			prepareWarmMigration(v1.MigrationWarmingUp)
+			migration.Spec.CommitCutover = true
+			addWarmMigration()
+
+			vmiInterface.EXPECT().Patch(vmi.Name, types.JSONPatchType, gomock.Any()).DoAndReturn(func(name string, _ types.PatchType, data []byte, _ ...string) (*v1.VirtualMachineInstance, error) {
+				Expect(string(data)).To(ContainSubstring(`"cutoverRequested":true`))
+				return vmi, nil
+			})
+
+			controller.Execute()
+			testutils.ExpectEvent(recorder, SuccessfulCommitCutoverReason)
+		})
+
+		It("should report a failed cutover request", func() {
+			prepareWarmMigration(v1.MigrationWarmingUp)
+			migration.Spec.CommitCutover = true
+			addWarmMigration()
+
+			vmiInterface.EXPECT().Patch(vmi.Name, types.JSONPatchType, gomock.Any()).Return(nil, fmt.Errorf("conflict"))
+
+			controller.Execute()
+			testutils.ExpectEvent(recorder, FailedCommitCutoverReason)
+		})
+
+		It("should transition to running phase once the cutover was requested", func() {
+			prepareWarmMigration(v1.MigrationWarmingUp)
+			migration.Spec.CommitCutover = true
+			vmi.Status.MigrationState.CutoverRequested = true
+			addWarmMigration()
+
+			shouldExpectMigrationRunningState(migration)
+
+			controller.Execute()
+		})
+
+		It("should transition to running phase directly if the cutover was committed before the migration started", func() {
+			prepareWarmMigration(v1.MigrationTargetReady)
+			migration.Spec.CommitCutover = true
+			vmi.Status.MigrationState.CutoverRequested = true
+			addWarmMigration()
+
+			shouldExpectMigrationRunningState(migration)
+
+			controller.Execute()
+		})
+
+		It("should abort the migration while warming up", func() {
+			prepareWarmMigration(v1.MigrationWarmingUp)
+			migration.Status.Conditions = append(migration.Status.Conditions, v1.VirtualMachineInstanceMigrationCondition{
+				Type:          v1.VirtualMachineInstanceMigrationAbortRequested,
+				Status:        k8sv1.ConditionTrue,
+				LastProbeTime: *now(),
+			})
+			migration.DeletionTimestamp = now()
+			addWarmMigration()
+
+			vmiInterface.EXPECT().Patch(vmi.Name, types.JSONPatchType, gomock.Any()).DoAndReturn(func(name string, _ types.PatchType, data []byte, _ ...string) (*v1.VirtualMachineInstance, error) {
+				Expect(string(data)).To(ContainSubstring(`"abortRequested":true`))
+				return vmi, nil
+			})
+
+			controller.Execute()
+			testutils.ExpectEvent(recorder, SuccessfulAbortMigrationReason)
+		})
+	})
+
+	Context("Migration tuning", func() {
+
+		It("should hand the bandwidth and the parallel connections limited by the cluster over to virt-handler", func() {
+			vmi := newVirtualMachine("testvmi", v1.Running)
+			vmi.Status.NodeName = "node02"
+			migration := newMigration("testmigration", vmi.Name, v1.MigrationScheduled)
+			bandwidth := resource.MustParse("32Mi")
+			threads := uint32(16)
+			migration.Spec.Bandwidth = &bandwidth
+			migration.Spec.ParallelMigrationThreads = &threads
+			pod := newTargetPodForVirtualMachine(vmi, migration, k8sv1.PodPending)
+			pod.Spec.NodeName = "node01"
+
+			addMigration(migration)
+			addVirtualMachineInstance(vmi)
+			podFeeder.Add(pod)
+
+			vmiInterface.EXPECT().Update(gomock.Any()).DoAndReturn(func(arg interface{}) (interface{}, interface{}) {
+				migrationState := arg.(*v1.VirtualMachineInstance).Status.MigrationState
+				Expect(migrationState.Bandwidth.String()).To(Equal("32Mi"))
+				Expect(*migrationState.ParallelMigrationThreads).To(BeNumerically("==", virtconfig.MaxParallelMigrationThreadsDefault))
+				return arg, nil
+			})
+
+			controller.Execute()
+			testutils.ExpectEvent(recorder, SuccessfulHandOverPodReason)
+		})
+
+		It("should hand the bandwidth of the cluster over to virt-handler if none is requested", func() {
+			vmi := newVirtualMachine("testvmi", v1.Running)
+			vmi.Status.NodeName = "node02"
+			migration := newMigration("testmigration", vmi.Name, v1.MigrationScheduled)
+			pod := newTargetPodForVirtualMachine(vmi, migration, k8sv1.PodPending)
+			pod.Spec.NodeName = "node01"
+
+			addMigration(migration)
+			addVirtualMachineInstance(vmi)
+			podFeeder.Add(pod)
+
+			vmiInterface.EXPECT().Update(gomock.Any()).DoAndReturn(func(arg interface{}) (interface{}, interface{}) {
+				migrationState := arg.(*v1.VirtualMachineInstance).Status.MigrationState
+				Expect(migrationState.Bandwidth.String()).To(Equal(virtconfig.BandwithPerMigrationDefault))
+				Expect(migrationState.ParallelMigrationThreads).To(BeNil())
+				return arg, nil
+			})
+
+			controller.Execute()
+			testutils.ExpectEvent(recorder, SuccessfulHandOverPodReason)
+		})
+
+		It("should report the effective bandwidth and parallel connections in the migration status", func() {
+			vmi := newVirtualMachine("testvmi", v1.Running)
+			vmi.Status.NodeName = "node02"
+			migration := newMigration("testmigration", vmi.Name, v1.MigrationScheduled)
+			bandwidth := resource.MustParse("32Mi")
+			threads := uint32(4)
+			migration.Spec.Bandwidth = &bandwidth
+			migration.Spec.ParallelMigrationThreads = &threads
+			pod := newTargetPodForVirtualMachine(vmi, migration, k8sv1.PodPending)
+			pod.Spec.NodeName = "node01"
+			vmi.Status.MigrationState = &v1.VirtualMachineInstanceMigrationState{
+				MigrationUID:             migration.UID,
+				TargetNode:               "node01",
+				SourceNode:               "node02",
+				TargetPod:                pod.Name,
+				Bandwidth:                &bandwidth,
+				ParallelMigrationThreads: &threads,
+			}
+			vmi.Labels[v1.MigrationTargetNodeNameLabel] = "node01"
+
+			addMigration(migration)
+			addVirtualMachineInstance(vmi)
+			podFeeder.Add(pod)
+
+			migrationInterface.EXPECT().UpdateStatus(gomock.Any()).DoAndReturn(func(arg interface{}) (interface{}, interface{}) {
+				status := arg.(*v1.VirtualMachineInstanceMigration).Status
+				Expect(status.Phase).To(Equal(v1.MigrationPreparingTarget))
+				Expect(status.Bandwidth.String()).To(Equal("32Mi"))
+				Expect(*status.ParallelMigrationThreads).To(BeNumerically("==", 4))
+				return arg, nil
+			})
+
+			controller.Execute()
+		})
+
+		quantity := func(value string) *resource.Quantity {
+			q := resource.MustParse(value)
+			return &q
+		}
+		uint32Ptr := func(value uint32) *uint32 {
+			return &value
+		}
+
+		table.DescribeTable("should limit the bandwidth", func(requested, cluster *resource.Quantity, expected string) {
+			spec := &v1.VirtualMachineInstanceMigrationSpec{Bandwidth: requested}
+			config := &v1.MigrationConfiguration{BandwidthPerMigration: cluster}
+			bandwidth, _ := limitMigrationTuning(spec, config)
+			Expect(bandwidth.String()).To(Equal(expected))
+		},
+			table.Entry("to the one of the cluster if none is requested", nil, quantity("64Mi"), "64Mi"),
+			table.Entry("to the requested one if lower", quantity("16Mi"), quantity("64Mi"), "16Mi"),
+			table.Entry("to the one of the cluster if higher", quantity("1Gi"), quantity("64Mi"), "64Mi"),
+			table.Entry("to the one of the cluster if unlimited is requested", quantity("0"), quantity("64Mi"), "64Mi"),
+			table.Entry("to the requested one if the cluster is unlimited", quantity("1Gi"), quantity("0"), "1Gi"),
+		)
+
+		table.DescribeTable("should limit the parallel connections", func(requested, max *uint32, expected *uint32) {
+			spec := &v1.VirtualMachineInstanceMigrationSpec{ParallelMigrationThreads: requested}
+			config := &v1.MigrationConfiguration{MaxParallelMigrationThreads: max}
+			_, threads := limitMigrationTuning(spec, config)
+			if expected == nil {
+				Expect(threads).To(BeNil())
+			} else {
+				Expect(threads).To(Equal(expected))
+			}
+		},
+			table.Entry("to none if none are requested", nil, uint32Ptr(8), nil),
+			table.Entry("to the requested ones if fewer", uint32Ptr(4), uint32Ptr(8), uint32Ptr(4)),
+			table.Entry("to the maximum of the cluster if more", uint32Ptr(16), uint32Ptr(8), uint32Ptr(8)),
+			table.Entry("to none if the cluster doesn't allow any", uint32Ptr(4), uint32Ptr(0), nil),
+		)
+	})
 })
 
 func newMigration(name string, vmiName string, phase v1.VirtualMachineInstanceMigrationPhase) *v1.VirtualMachineInstanceMigration {
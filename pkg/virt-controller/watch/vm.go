@@ -32,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
@@ -43,6 +44,7 @@ import (
 	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
 	cdiclone "kubevirt.io/containerized-data-importer/pkg/clone"
 	"kubevirt.io/kubevirt/pkg/controller"
+	"kubevirt.io/kubevirt/pkg/util/schedule"
 	"kubevirt.io/kubevirt/pkg/util/status"
 )
 
@@ -57,6 +59,13 @@ const (
 	failureDeletingVmiErrFormat           = "Failure attempting to delete VMI: %v"
 )
 
+const (
+	// ScheduledStartReason is the reason of the event recorded when the schedule of a VM starts it
+	ScheduledStartReason = "ScheduledStart"
+	// ScheduledStopReason is the reason of the event recorded when the schedule of a VM stops it
+	ScheduledStopReason = "ScheduledStop"
+)
+
 func NewVMController(vmiInformer cache.SharedIndexInformer,
 	vmiVMInformer cache.SharedIndexInformer,
 	dataVolumeInformer cache.SharedIndexInformer,
@@ -80,6 +89,7 @@ func NewVMController(vmiInformer cache.SharedIndexInformer,
 			return cdiclone.CanServiceAccountClonePVC(proxy, pvcNamespace, pvcName, saNamespace, saName)
 		},
 		statusUpdater: status.NewVMStatusUpdater(clientset),
+		clock:         clock.RealClock{},
 	}
 
 	c.vmiVMInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -123,6 +133,7 @@ type VMController struct {
 	dataVolumeExpectations *controller.UIDTrackingControllerExpectations
 	cloneAuthFunc          CloneAuthFunc
 	statusUpdater          *status.VMStatusUpdater
+	clock                  clock.Clock
 }
 
 func (c *VMController) Run(threadiness int, stopCh <-chan struct{}) {
@@ -204,6 +215,12 @@ func (c *VMController) execute(key string) error {
 		return nil
 	}
 
+	if vm.Spec.Schedule != nil && vm.ObjectMeta.DeletionTimestamp == nil {
+		if updated, err := c.handleSchedule(vm, key); updated || err != nil {
+			return err
+		}
+	}
+
 	vmKey, err := controller.KeyFunc(vm)
 	if err != nil {
 		return err
@@ -307,6 +324,59 @@ func (c *VMController) execute(key string) error {
 	return nil
 }
 
+// handleSchedule switches the run strategy of the VM to the one of the latest
+// start or stop of its schedule which was not handled yet, and requeues the VM
+// for the next one. Each transition is applied once, so that a run strategy
+// chosen by the user in between sticks until the next transition. It returns
+// true if the VM was updated, since the update triggers the next sync.
+func (c *VMController) handleSchedule(vm *virtv1.VirtualMachine, key string) (bool, error) {
+	logger := log.Log.Object(vm)
+
+	s, err := schedule.Parse(vm.Spec.Schedule)
+	if err != nil {
+		// the schedule is validated on admission, retrying won't fix it
+		logger.Reason(err).Error("Ignoring the invalid schedule of the VirtualMachine")
+		return false, nil
+	}
+
+	since := vm.ObjectMeta.CreationTimestamp.Time
+	if vm.Status.LastScheduledTransition != nil {
+		since = vm.Status.LastScheduledTransition.Time
+	}
+	now := c.clock.Now()
+	transition, next, ok := s.Evaluate(since, now)
+	if ok {
+		c.Queue.AddAfter(key, next.Sub(now))
+	}
+	if transition == nil {
+		return false, nil
+	}
+
+	runStrategy := transition.RunStrategy()
+	if vm.Spec.RunStrategy == nil || *vm.Spec.RunStrategy != runStrategy {
+		logger.Infof("Switching the RunStrategy to %s as scheduled at %s", runStrategy, transition.Time.Format(time.RFC3339))
+		vmCopy := vm.DeepCopy()
+		vmCopy.Spec.RunStrategy = &runStrategy
+		if _, err := c.clientset.VirtualMachine(vm.Namespace).Update(vmCopy); err != nil {
+			return false, err
+		}
+		if transition.Start {
+			c.recorder.Eventf(vm, k8score.EventTypeNormal, ScheduledStartReason, "Started the VirtualMachine as scheduled at %s", transition.Time.Format(time.RFC3339))
+		} else {
+			c.recorder.Eventf(vm, k8score.EventTypeNormal, ScheduledStopReason, "Stopped the VirtualMachine as scheduled at %s", transition.Time.Format(time.RFC3339))
+		}
+		// the transition is recorded once the update is observed
+		return true, nil
+	}
+
+	vmCopy := vm.DeepCopy()
+	vmCopy.Status.LastScheduledTransition = &v1.Time{Time: transition.Time}
+	if err := c.statusUpdater.UpdateStatus(vmCopy); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // Handles VM rename requests
 // First return value is a boolean indicating if the controller should retry the request
 func (c *VMController) handleVMRenameRequest(vm *virtv1.VirtualMachine, newName string) (bool, error) {
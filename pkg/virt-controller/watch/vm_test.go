@@ -2,6 +2,7 @@ package watch
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/go-openapi/errors"
 	"github.com/golang/mock/gomock"
@@ -13,6 +14,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
 	framework "k8s.io/client-go/tools/cache/testing"
@@ -1091,6 +1093,87 @@ var _ = Describe("VirtualMachine", func() {
 			controller.Execute()
 		})
 
+		Context("with a schedule", func() {
+			// a monday
+			now := time.Date(2021, time.March, 1, 10, 30, 0, 0, time.UTC)
+			officeHours := &v1.VirtualMachineSchedule{
+				Start: "0 8 * * 1-5",
+				Stop:  "0 18 * * 1-5",
+			}
+
+			BeforeEach(func() {
+				controller.clock = clock.NewFakeClock(now)
+			})
+
+			newScheduledVM := func(runStrategy v1.VirtualMachineRunStrategy, created time.Time) *v1.VirtualMachine {
+				vm, _ := DefaultVirtualMachine(false)
+				vm.Spec.Running = nil
+				vm.Spec.RunStrategy = &runStrategy
+				vm.Spec.Schedule = officeHours
+				vm.CreationTimestamp = metav1.NewTime(created)
+				return vm
+			}
+
+			It("should switch the RunStrategy on a due transition", func() {
+				vm := newScheduledVM(v1.RunStrategyHalted, now.Add(-24*time.Hour))
+				addVirtualMachine(vm)
+
+				vmInterface.EXPECT().Update(gomock.Any()).Do(func(arg interface{}) {
+					Expect(*arg.(*v1.VirtualMachine).Spec.RunStrategy).To(Equal(v1.RunStrategyAlways))
+				}).Return(vm, nil)
+
+				controller.Execute()
+				testutils.ExpectEvent(recorder, ScheduledStartReason)
+				Expect(mockQueue.GetAddAfterEnqueueCount()).To(Equal(1))
+			})
+
+			It("should record the transition once the RunStrategy is switched", func() {
+				vm := newScheduledVM(v1.RunStrategyAlways, now.Add(-24*time.Hour))
+				addVirtualMachine(vm)
+
+				vmInterface.EXPECT().UpdateStatus(gomock.Any()).Do(func(arg interface{}) {
+					transition := arg.(*v1.VirtualMachine).Status.LastScheduledTransition
+					Expect(transition).ToNot(BeNil())
+					Expect(transition.Time).To(Equal(time.Date(2021, time.March, 1, 8, 0, 0, 0, time.UTC)))
+				}).Return(vm, nil)
+
+				controller.Execute()
+				Expect(mockQueue.GetAddAfterEnqueueCount()).To(Equal(1))
+			})
+
+			It("should not apply transitions which were handled already", func() {
+				// the user stopped the VM after the scheduled start
+				vm := newScheduledVM(v1.RunStrategyHalted, now.Add(-24*time.Hour))
+				lastTransition := metav1.NewTime(time.Date(2021, time.March, 1, 8, 0, 0, 0, time.UTC))
+				vm.Status.LastScheduledTransition = &lastTransition
+				addVirtualMachine(vm)
+
+				controller.Execute()
+				Expect(mockQueue.GetAddAfterEnqueueCount()).To(Equal(1))
+			})
+
+			It("should not apply the transitions from before the creation of the VM", func() {
+				vm := newScheduledVM(v1.RunStrategyHalted, now.Add(-time.Hour))
+				addVirtualMachine(vm)
+
+				controller.Execute()
+				Expect(mockQueue.GetAddAfterEnqueueCount()).To(Equal(1))
+			})
+
+			It("should stop the VM on a due stop", func() {
+				vm := newScheduledVM(v1.RunStrategyAlways, now.Add(-time.Hour))
+				controller.clock = clock.NewFakeClock(time.Date(2021, time.March, 1, 18, 0, 30, 0, time.UTC))
+				addVirtualMachine(vm)
+
+				vmInterface.EXPECT().Update(gomock.Any()).Do(func(arg interface{}) {
+					Expect(*arg.(*v1.VirtualMachine).Spec.RunStrategy).To(Equal(v1.RunStrategyHalted))
+				}).Return(vm, nil)
+
+				controller.Execute()
+				testutils.ExpectEvent(recorder, ScheduledStopReason)
+			})
+		})
+
 		Context("VM rename", func() {
 			Context("source VM", func() {
 				var vm *v1.VirtualMachine
@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -43,6 +44,7 @@ import (
 	cdiv1 "kubevirt.io/containerized-data-importer/pkg/apis/core/v1alpha1"
 	"kubevirt.io/kubevirt/pkg/controller"
 	kubevirttypes "kubevirt.io/kubevirt/pkg/util/types"
+	virtconfig "kubevirt.io/kubevirt/pkg/virt-config"
 	"kubevirt.io/kubevirt/pkg/virt-controller/services"
 )
 
@@ -102,12 +104,27 @@ const (
 	SuccessfulAbortMigrationReason = "SuccessfulAbortMigration"
 	// FailedAbortMigrationReason is added when an attempt to abort migration fails
 	FailedAbortMigrationReason = "FailedAbortMigration"
+	// SuccessfulCommitCutoverReason is added when the cutover of a warm migration was requested successfully
+	SuccessfulCommitCutoverReason = "SuccessfulCommitCutover"
+	// FailedCommitCutoverReason is added when the cutover of a warm migration could not be requested
+	FailedCommitCutoverReason = "FailedCommitCutover"
 	// MissingAttachmentPodReason is set when we have a hotplugged volume, but the attachment pod is missing
 	MissingAttachmentPodReason = "MissingAttachmentPod"
 	// PVCNotReadyReason is set when the PVC is not ready to be hot plugged.
 	PVCNotReadyReason = "PVCNotReady"
 	// FailedHotplugSyncReason is set when a hotplug specific failure occurs during sync
 	FailedHotplugSyncReason = "FailedHotplugSync"
+	// FailedMemoryDumpSyncReason is set when a memory dump specific failure occurs during sync
+	FailedMemoryDumpSyncReason = "FailedMemoryDumpSync"
+	// LivenessProbeFailedReason is added in an event when the liveness probe of a VMI
+	// which migrates on liveness probe failures failed, naming the chosen action
+	LivenessProbeFailedReason = "LivenessProbeFailed"
+	// FailedMacAddressClaimReason is added in an event when no MAC address could be
+	// drawn from the pool requested by an interface
+	FailedMacAddressClaimReason = "FailedMacAddressClaim"
+	// SuccessfulMacAddressClaimReason is added in an event when a MAC address was
+	// drawn from the pool requested by an interface
+	SuccessfulMacAddressClaimReason = "SuccessfulMacAddressClaim"
 )
 
 const failedToRenderLaunchManifestErrFormat = "failed to render launch manifest: %v"
@@ -118,7 +135,8 @@ func NewVMIController(templateService services.TemplateService,
 	pvcInformer cache.SharedIndexInformer,
 	recorder record.EventRecorder,
 	clientset kubecli.KubevirtClient,
-	dataVolumeInformer cache.SharedIndexInformer) *VMIController {
+	dataVolumeInformer cache.SharedIndexInformer,
+	clusterConfig *virtconfig.ClusterConfig) *VMIController {
 
 	c := &VMIController{
 		templateService:    templateService,
@@ -130,6 +148,8 @@ func NewVMIController(templateService services.TemplateService,
 		clientset:          clientset,
 		podExpectations:    controller.NewUIDTrackingControllerExpectations(controller.NewControllerExpectations()),
 		dataVolumeInformer: dataVolumeInformer,
+		clusterConfig:      clusterConfig,
+		macPool:            services.NewMacAddressPoolAllocator(vmiInformer.GetStore(), clusterConfig),
 	}
 
 	c.vmiInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -181,6 +201,8 @@ type VMIController struct {
 	recorder           record.EventRecorder
 	podExpectations    *controller.UIDTrackingControllerExpectations
 	dataVolumeInformer cache.SharedIndexInformer
+	clusterConfig      *virtconfig.ClusterConfig
+	macPool            *services.MacAddressPoolAllocator
 }
 
 func (c *VMIController) Run(threadiness int, stopCh <-chan struct{}) {
@@ -235,6 +257,7 @@ func (c *VMIController) execute(key string) error {
 	// Once all finalizers are removed the vmi gets deleted and we can clean all expectations
 	if !exists {
 		c.podExpectations.DeleteExpectations(key)
+		c.macPool.Release(key)
 		return nil
 	}
 	vmi := obj.(*virtv1.VirtualMachineInstance)
@@ -250,6 +273,11 @@ func (c *VMIController) execute(key string) error {
 		return err
 	}
 
+	// The MAC addresses drawn from pools have to be part of the spec before the pod is rendered
+	if vmi.IsUnprocessed() && vmi.DeletionTimestamp == nil && services.NeedsMacAddressClaims(vmi) {
+		return c.claimMacAddresses(vmi)
+	}
+
 	// Only consider pods which belong to this vmi
 	// excluding unfinalized migration targets from this list.
 	pod, err := c.currentPod(vmi)
@@ -285,6 +313,24 @@ func (c *VMIController) execute(key string) error {
 
 }
 
+// claimMacAddresses draws the MAC addresses of the interfaces requesting a pool
+// and records them in the VMI spec.
+func (c *VMIController) claimMacAddresses(vmi *virtv1.VirtualMachineInstance) error {
+	vmiCopy := vmi.DeepCopy()
+	claims, err := c.macPool.Claim(vmiCopy)
+	if err != nil {
+		c.recorder.Eventf(vmi, k8sv1.EventTypeWarning, FailedMacAddressClaimReason, "Failed to claim a MAC address: %v", err)
+		return err
+	}
+	if _, err := c.clientset.VirtualMachineInstance(vmi.Namespace).Update(vmiCopy); err != nil {
+		return err
+	}
+	for _, claim := range claims {
+		c.recorder.Eventf(vmi, k8sv1.EventTypeNormal, SuccessfulMacAddressClaimReason, "Claimed MAC address %s from pool %s for interface %s", claim.MacAddress, claim.Pool, claim.Interface)
+	}
+	return nil
+}
+
 // verifies all conditions match even if they are not in the same order
 func conditionsEqual(a []virtv1.VirtualMachineInstanceCondition, b []virtv1.VirtualMachineInstanceCondition) bool {
 	if len(a) != len(b) {
@@ -394,6 +440,7 @@ func (c *VMIController) updateStatus(vmi *virtv1.VirtualMachineInstance, pod *k8
 			} else {
 				vmiCopy.Status.QOSClass = &pod.Status.QOSClass
 			}
+			vmiCopy.Status.MemoryOvercommit = memoryOvercommitStatus(vmi, pod)
 
 			// Add PodScheduled False condition to the VM
 			if cond := conditionManager.GetPodConditionWithStatus(pod, k8sv1.PodScheduled, k8sv1.ConditionFalse); cond != nil {
@@ -418,6 +465,7 @@ func (c *VMIController) updateStatus(vmi *virtv1.VirtualMachineInstance, pod *k8
 					}
 					vmiCopy.ObjectMeta.Labels[virtv1.NodeNameLabel] = pod.Spec.NodeName
 					vmiCopy.Status.NodeName = pod.Spec.NodeName
+					vmiCopy.Status.DrainGracePeriodSeconds = getDrainGracePeriodSeconds(vmi, pod)
 				}
 			} else if isPodDownOrGoingDown(pod) {
 				vmiCopy.Status.Phase = virtv1.Failed
@@ -440,6 +488,11 @@ func (c *VMIController) updateStatus(vmi *virtv1.VirtualMachineInstance, pod *k8
 		conditionManager.RemoveCondition(vmiCopy, virtv1.VirtualMachineInstanceConditionType(k8sv1.PodReady))
 
 	case vmi.IsRunning():
+		// React on liveness probe failures before the VMI is marked as not ready,
+		// so that a failed attempt is retried on the next sync
+		if err := c.handleLivenessProbeFailure(vmi, pod); err != nil {
+			return err
+		}
 		// Keep PodReady condition in sync with the VMI
 		if !vmiPodExists {
 			// Remove PodScheduling condition from the VM
@@ -460,7 +513,7 @@ func (c *VMIController) updateStatus(vmi *virtv1.VirtualMachineInstance, pod *k8
 			}
 		} else if cond := conditionManager.GetPodCondition(pod, k8sv1.PodReady); cond != nil {
 			conditionManager.RemoveCondition(vmiCopy, virtv1.VirtualMachineInstanceConditionType(k8sv1.PodReady))
-			conditionManager.AddPodCondition(vmiCopy, cond)
+			conditionManager.AddPodCondition(vmiCopy, gateReadyOnStartupProbe(vmiCopy, cond))
 		} else if conditionManager.HasCondition(vmiCopy, virtv1.VirtualMachineInstanceConditionType(k8sv1.PodReady)) {
 			// Remove PodScheduling condition from the VM
 			conditionManager.RemoveCondition(vmiCopy, virtv1.VirtualMachineInstanceConditionType(k8sv1.PodReady))
@@ -469,6 +522,7 @@ func (c *VMIController) updateStatus(vmi *virtv1.VirtualMachineInstance, pod *k8
 		patchOps := []string{}
 		if vmiPodExists {
 			c.updateVolumeStatus(vmiCopy, pod)
+			c.updateMemoryDumpStatus(vmiCopy, pod)
 		}
 		logger := log.Log.Object(vmi)
 		if !reflect.DeepEqual(vmiCopy.Status.VolumeStatus, vmi.Status.VolumeStatus) {
@@ -491,6 +545,20 @@ func (c *VMIController) updateStatus(vmi *virtv1.VirtualMachineInstance, pod *k8
 			}
 			log.Log.V(3).Object(vmi).Infof("Patching Volume Status")
 		}
+		if !reflect.DeepEqual(vmiCopy.Status.MemoryDump, vmi.Status.MemoryDump) {
+			// Only a requested memory dump is updated, hence there always is an old one to test
+			newMemoryDump, err := json.Marshal(vmiCopy.Status.MemoryDump)
+			if err != nil {
+				return err
+			}
+			oldMemoryDump, err := json.Marshal(vmi.Status.MemoryDump)
+			if err != nil {
+				return err
+			}
+			patchOps = append(patchOps, fmt.Sprintf(`{ "op": "test", "path": "/status/memoryDump", "value": %s }`, string(oldMemoryDump)))
+			patchOps = append(patchOps, fmt.Sprintf(`{ "op": "replace", "path": "/status/memoryDump", "value": %s }`, string(newMemoryDump)))
+			log.Log.V(3).Object(vmi).Infof("Patching Memory Dump Status")
+		}
 		// We don't own the object anymore, so patch instead of update
 		if !conditionsEqual(vmiCopy.Status.Conditions, vmi.Status.Conditions) {
 
@@ -546,8 +614,9 @@ func (c *VMIController) updateStatus(vmi *virtv1.VirtualMachineInstance, pod *k8
 		}
 		return nil
 	case vmi.IsScheduled():
-		// Don't process states where the vmi is clearly owned by virt-handler
-		return nil
+		// Don't process states where the vmi is clearly owned by virt-handler,
+		// besides failing it once virt-handler missed the relabel timeout
+		return c.checkSELinuxRelabelTimeout(vmi, pod)
 	default:
 		return fmt.Errorf("unknown vmi phase %v", vmi.Status.Phase)
 	}
@@ -597,6 +666,181 @@ func isPodReady(pod *k8sv1.Pod) bool {
 	return pod.Status.Phase == k8sv1.PodRunning
 }
 
+// memoryOvercommitStatus reports how the memory of a VMI overcommitting it was
+// requested: the share of the guest memory requested for the guest, and the
+// request of the compute container of its pod, which adds the memory overhead
+// of virt-launcher to it unless the overhead is overcommitted too. It returns
+// nil for the VMIs not overcommitting their memory.
+func memoryOvercommitStatus(vmi *virtv1.VirtualMachineInstance, pod *k8sv1.Pod) *virtv1.MemoryOvercommitStatus {
+	memory := vmi.Spec.Domain.Memory
+	if memory == nil || memory.OvercommitPercent == nil || memory.Guest == nil {
+		return nil
+	}
+	status := &virtv1.MemoryOvercommitStatus{
+		Percent:     *memory.OvercommitPercent,
+		GuestMemory: *memory.Guest,
+	}
+	if request, ok := vmi.Spec.Domain.Resources.Requests[k8sv1.ResourceMemory]; ok {
+		status.MemoryRequest = request
+	} else {
+		status.MemoryRequest = virtconfig.OvercommittedMemoryRequest(*memory.Guest, int(*memory.OvercommitPercent))
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Name == "compute" {
+			status.PodMemoryRequest = container.Resources.Requests[k8sv1.ResourceMemory]
+		}
+	}
+	return status
+}
+
+// gateReadyOnStartupProbe holds the Ready condition of a VMI with a startup
+// probe back, until virt-handler reported the probe as succeeded.
+func gateReadyOnStartupProbe(vmi *virtv1.VirtualMachineInstance, podReady *k8sv1.PodCondition) *k8sv1.PodCondition {
+	if vmi.Spec.StartupProbe == nil || podReady.Status != k8sv1.ConditionTrue {
+		return podReady
+	}
+	probeCond := controller.NewVirtualMachineInstanceConditionManager().GetCondition(vmi, virtv1.VirtualMachineInstanceStartupProbeSucceeded)
+	if probeCond != nil && probeCond.Status == k8sv1.ConditionTrue {
+		return podReady
+	}
+	gated := podReady.DeepCopy()
+	gated.Status = k8sv1.ConditionFalse
+	gated.Reason = virtv1.VirtualMachineInstanceReasonStartupProbePending
+	gated.Message = "The startup probe did not succeed yet"
+	if probeCond != nil {
+		if probeCond.Reason != "" {
+			gated.Reason = probeCond.Reason
+		}
+		if probeCond.Message != "" {
+			gated.Message = probeCond.Message
+		}
+		gated.LastProbeTime = probeCond.LastProbeTime
+		gated.LastTransitionTime = probeCond.LastTransitionTime
+	}
+	return gated
+}
+
+// checkSELinuxRelabelTimeout fails a scheduled VMI, once virt-handler did not
+// confirm the SELinux relabeling to virt-launcher within the relabel timeout
+// since the start of the compute container. The confirmation opens the
+// readiness gate of the compute container, which VMIs with a custom readiness
+// probe don't have.
+func (c *VMIController) checkSELinuxRelabelTimeout(vmi *virtv1.VirtualMachineInstance, pod *k8sv1.Pod) error {
+	if !podExists(pod) || vmi.Spec.ReadinessProbe != nil || vmi.MigratesOnLivenessProbeFailure() || vmi.DeletionTimestamp != nil {
+		return nil
+	}
+	var startedAt time.Time
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name == "compute" {
+			if containerStatus.Ready || containerStatus.State.Running == nil {
+				return nil
+			}
+			startedAt = containerStatus.State.Running.StartedAt.Time
+		}
+	}
+	if startedAt.IsZero() {
+		return nil
+	}
+
+	timeout := c.clusterConfig.GetSELinuxRelabelTimeout()
+	if remaining := timeout - time.Since(startedAt); remaining > 0 {
+		key, err := controller.KeyFunc(vmi)
+		if err != nil {
+			return err
+		}
+		c.Queue.AddAfter(key, remaining)
+		return nil
+	}
+
+	message := fmt.Sprintf("virt-handler did not confirm the SELinux relabeling within %v", timeout)
+	vmiCopy := vmi.DeepCopy()
+	conditionManager := controller.NewVirtualMachineInstanceConditionManager()
+	conditionManager.RemoveCondition(vmiCopy, virtv1.VirtualMachineInstanceSELinuxLabelsApplied)
+	vmiCopy.Status.Conditions = append(vmiCopy.Status.Conditions, virtv1.VirtualMachineInstanceCondition{
+		Type:               virtv1.VirtualMachineInstanceSELinuxLabelsApplied,
+		Status:             k8sv1.ConditionFalse,
+		LastProbeTime:      v1.Now(),
+		LastTransitionTime: v1.Now(),
+		Reason:             virtv1.VirtualMachineInstanceReasonSELinuxRelabelTimedOut,
+		Message:            message,
+	})
+
+	oldConditions, err := json.Marshal(vmi.Status.Conditions)
+	if err != nil {
+		return err
+	}
+	newConditions, err := json.Marshal(vmiCopy.Status.Conditions)
+	if err != nil {
+		return err
+	}
+	// virt-handler owns the VMI, only fail it if it did not move on meanwhile
+	patch := fmt.Sprintf(`[ { "op": "test", "path": "/status/phase", "value": "%s" }, { "op": "replace", "path": "/status/phase", "value": "%s" }, { "op": "test", "path": "/status/conditions", "value": %s }, { "op": "replace", "path": "/status/conditions", "value": %s } ]`,
+		virtv1.Scheduled, virtv1.Failed, string(oldConditions), string(newConditions))
+	if _, err := c.clientset.VirtualMachineInstance(vmi.Namespace).Patch(vmi.Name, types.JSONPatchType, []byte(patch)); err != nil {
+		return fmt.Errorf("failing the vmi after the SELinux relabel timeout failed: %v", err)
+	}
+	c.recorder.Event(vmi, k8sv1.EventTypeWarning, virtv1.VirtualMachineInstanceReasonSELinuxRelabelTimedOut, message)
+	return nil
+}
+
+// handleLivenessProbeFailure migrates a ready VMI away, once its liveness
+// probe, which then backs the readiness probe of the compute container,
+// failed. VMIs which can't be live migrated are restarted instead by deleting
+// their pod.
+func (c *VMIController) handleLivenessProbeFailure(vmi *virtv1.VirtualMachineInstance, pod *k8sv1.Pod) error {
+	if !vmi.MigratesOnLivenessProbeFailure() || !podExists(pod) || isPodDownOrGoingDown(pod) || vmi.DeletionTimestamp != nil {
+		return nil
+	}
+	if vmi.Status.MigrationState != nil && !vmi.Status.MigrationState.Completed && !vmi.Status.MigrationState.Failed {
+		return nil
+	}
+	conditionManager := controller.NewVirtualMachineInstanceConditionManager()
+	if !conditionManager.HasConditionWithStatus(vmi, virtv1.VirtualMachineInstanceConditionType(k8sv1.PodReady), k8sv1.ConditionTrue) || !isComputeContainerNotReady(pod) {
+		return nil
+	}
+
+	if !vmi.IsMigratable() {
+		key, err := controller.KeyFunc(vmi)
+		if err != nil {
+			return err
+		}
+		c.podExpectations.ExpectDeletions(key, []string{controller.PodKey(pod)})
+		if err := c.clientset.CoreV1().Pods(vmi.Namespace).Delete(context.Background(), pod.Name, v1.DeleteOptions{}); err != nil {
+			c.podExpectations.DeletionObserved(key, controller.PodKey(pod))
+			c.recorder.Eventf(vmi, k8sv1.EventTypeWarning, FailedDeletePodReason, "Failed to delete virtual machine pod %s after a liveness probe failure: %v", pod.Name, err)
+			return err
+		}
+		c.recorder.Eventf(vmi, k8sv1.EventTypeWarning, LivenessProbeFailedReason, "The liveness probe failed, restarting the VMI since it is not live migratable")
+		return nil
+	}
+
+	migration := &virtv1.VirtualMachineInstanceMigration{
+		ObjectMeta: v1.ObjectMeta{
+			GenerateName: "kubevirt-liveness-",
+		},
+		Spec: virtv1.VirtualMachineInstanceMigrationSpec{
+			VMIName: vmi.Name,
+		},
+	}
+	if _, err := c.clientset.VirtualMachineInstanceMigration(vmi.Namespace).Create(migration); err != nil {
+		c.recorder.Eventf(vmi, k8sv1.EventTypeWarning, FailedMigrationReason, "Failed to create a migration after a liveness probe failure: %v", err)
+		return err
+	}
+	c.recorder.Eventf(vmi, k8sv1.EventTypeWarning, LivenessProbeFailedReason, "The liveness probe failed, migrating the VMI away from node %s", vmi.Status.NodeName)
+	return nil
+}
+
+// isComputeContainerNotReady reports a running compute container which is
+// not ready.
+func isComputeContainerNotReady(pod *k8sv1.Pod) bool {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name == "compute" {
+			return containerStatus.State.Running != nil && !containerStatus.Ready
+		}
+	}
+	return false
+}
+
 func isPodDownOrGoingDown(pod *k8sv1.Pod) bool {
 	return podIsDown(pod) || isComputeContainerDown(pod) || pod.DeletionTimestamp != nil
 }
@@ -621,6 +865,20 @@ func podExists(pod *k8sv1.Pod) bool {
 	return false
 }
 
+// getDrainGracePeriodSeconds returns the grace period the virt-launcher pod was created with
+func getDrainGracePeriodSeconds(vmi *virtv1.VirtualMachineInstance, pod *k8sv1.Pod) *int64 {
+	if value, exists := pod.Annotations[virtv1.DrainGracePeriodSecondsAnnotation]; exists {
+		if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return &seconds
+		}
+	}
+	gracePeriodSeconds := virtv1.DefaultGracePeriodSeconds
+	if vmi.Spec.TerminationGracePeriodSeconds != nil {
+		gracePeriodSeconds = *vmi.Spec.TerminationGracePeriodSeconds
+	}
+	return &gracePeriodSeconds
+}
+
 func (c *VMIController) sync(vmi *virtv1.VirtualMachineInstance, pod *k8sv1.Pod, dataVolumes []*cdiv1.DataVolume) syncError {
 	if vmi.DeletionTimestamp != nil {
 		err := c.deleteAllMatchingPods(vmi)
@@ -710,6 +968,11 @@ func (c *VMIController) sync(vmi *virtv1.VirtualMachineInstance, pod *k8sv1.Pod,
 			}
 		}
 	}
+	if pod.DeletionTimestamp == nil && !isWaitForFirstConsumer {
+		if memoryDumpSyncErr := c.handleMemoryDump(vmi, pod); memoryDumpSyncErr != nil {
+			return memoryDumpSyncErr
+		}
+	}
 	return nil
 }
 
@@ -1237,12 +1500,124 @@ func (c *VMIController) virtlauncherAttachmentPods(virtlauncherPod *k8sv1.Pod) (
 		if ownerRef == nil || ownerRef.UID != virtlauncherPod.UID {
 			continue
 		}
+		// The claim of a memory dump is not a hotplugged volume
+		if pod.Labels[virtv1.AppLabel] == services.MemoryDumpAppLabel {
+			continue
+		}
 		attachmentPods = append(attachmentPods, pod)
 	}
 
 	return attachmentPods, nil
 }
 
+// virtlauncherMemoryDumpPod returns the pod mounting the claim of a memory dump next to the virt-launcher pod, if any.
+func (c *VMIController) virtlauncherMemoryDumpPod(virtlauncherPod *k8sv1.Pod) (*k8sv1.Pod, error) {
+	pods, err := c.listPodsFromNamespace(virtlauncherPod.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pod := range pods {
+		ownerRef := controller.GetControllerOf(pod)
+		if ownerRef == nil || ownerRef.UID != virtlauncherPod.UID {
+			continue
+		}
+		if pod.Labels[virtv1.AppLabel] == services.MemoryDumpAppLabel {
+			return pod, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func memoryDumpPodClaimName(pod *k8sv1.Pod) string {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			return volume.PersistentVolumeClaim.ClaimName
+		}
+	}
+	return ""
+}
+
+// handleMemoryDump keeps a pod mounting the claim of a requested memory dump next to the virt-launcher pod, until the
+// dump finished. A pod which went away during the dump is not recreated, updateMemoryDumpStatus fails the dump instead.
+func (c *VMIController) handleMemoryDump(vmi *virtv1.VirtualMachineInstance, virtlauncherPod *k8sv1.Pod) syncError {
+	dumpPod, err := c.virtlauncherMemoryDumpPod(virtlauncherPod)
+	if err != nil {
+		return &syncErrorImpl{fmt.Errorf("failed to get memory dump pod: %v", err), FailedMemoryDumpSyncReason}
+	}
+	dump := vmi.Status.MemoryDump
+	dumpRequested := dump != nil && !dump.IsFinished()
+	vmiKey := controller.VirtualMachineKey(vmi)
+
+	if dumpPod != nil {
+		if (dumpRequested && memoryDumpPodClaimName(dumpPod) == dump.ClaimName) || dumpPod.DeletionTimestamp != nil {
+			return nil
+		}
+		zero := int64(0)
+		c.podExpectations.ExpectDeletions(vmiKey, []string{controller.PodKey(dumpPod)})
+		err := c.clientset.CoreV1().Pods(dumpPod.Namespace).Delete(context.Background(), dumpPod.Name, v1.DeleteOptions{
+			GracePeriodSeconds: &zero,
+		})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			c.podExpectations.DeletionObserved(vmiKey, controller.PodKey(dumpPod))
+			c.recorder.Eventf(vmi, k8sv1.EventTypeWarning, FailedDeletePodReason, "Failed to delete memory dump pod %s", dumpPod.Name)
+			return &syncErrorImpl{fmt.Errorf("failed to delete memory dump pod: %v", err), FailedDeletePodReason}
+		}
+		c.recorder.Eventf(vmi, k8sv1.EventTypeNormal, SuccessfulDeletePodReason, "Deleted memory dump pod %s", dumpPod.Name)
+		return nil
+	}
+
+	if !dumpRequested || dump.TargetPodName != "" {
+		return nil
+	}
+
+	templatePod, err := c.templateService.RenderMemoryDumpTargetPodTemplate(virtlauncherPod, vmi, dump.ClaimName)
+	if err != nil {
+		return &syncErrorImpl{fmt.Errorf("Error creating memory dump pod template %v", err), FailedCreatePodReason}
+	}
+	c.podExpectations.ExpectCreations(vmiKey, 1)
+	pod, err := c.clientset.CoreV1().Pods(vmi.GetNamespace()).Create(context.Background(), templatePod, v1.CreateOptions{})
+	if err != nil {
+		c.podExpectations.CreationObserved(vmiKey)
+		c.recorder.Eventf(vmi, k8sv1.EventTypeWarning, FailedCreatePodReason, "Error creating memory dump pod for claim %s: %v", dump.ClaimName, err)
+		return &syncErrorImpl{fmt.Errorf("Error creating memory dump pod %v", err), FailedCreatePodReason}
+	}
+	c.recorder.Eventf(vmi, k8sv1.EventTypeNormal, SuccessfulCreatePodReason, "Created memory dump pod %s for claim %s", pod.Name, dump.ClaimName)
+	return nil
+}
+
+// updateMemoryDumpStatus records the pod mounting the claim of a requested memory dump, which virt-handler needs to
+// bind the claim into virt-launcher. The dump fails if that pod goes away before the dump finished.
+func (c *VMIController) updateMemoryDumpStatus(vmi *virtv1.VirtualMachineInstance, virtlauncherPod *k8sv1.Pod) error {
+	dump := vmi.Status.MemoryDump
+	if dump == nil || dump.IsFinished() {
+		return nil
+	}
+	dumpPod, err := c.virtlauncherMemoryDumpPod(virtlauncherPod)
+	if err != nil {
+		return err
+	}
+	if dumpPod != nil && memoryDumpPodClaimName(dumpPod) != dump.ClaimName {
+		// Left over from a previous dump
+		dumpPod = nil
+	}
+	if dumpPod == nil || dumpPod.DeletionTimestamp != nil {
+		if dump.TargetPodName != "" {
+			now := v1.Now()
+			dump.Phase = virtv1.MemoryDumpFailed
+			dump.Message = fmt.Sprintf("Memory dump pod %s went away before the dump finished", dump.TargetPodName)
+			dump.EndTimestamp = &now
+		}
+		return nil
+	}
+	dump.TargetPodName = dumpPod.Name
+	if len(dumpPod.Status.ContainerStatuses) == 1 && dumpPod.Status.ContainerStatuses[0].Ready {
+		dump.TargetPodUID = dumpPod.UID
+	}
+	return nil
+}
+
 func (c *VMIController) needsHandleHotplug(hotplugVolumes []*virtv1.Volume, currentAttachmentPods []*k8sv1.Pod) bool {
 	// If lengths don't match, need to handle for sure. This captures single adds/deletes
 	if len(hotplugVolumes) != len(currentAttachmentPods) {
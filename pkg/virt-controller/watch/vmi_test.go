@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/golang/mock/gomock"
@@ -200,6 +201,7 @@ var _ = Describe("VirtualMachineInstance watcher", func() {
 			recorder,
 			virtClient,
 			dataVolumeInformer,
+			config,
 		)
 		// Wrap our workqueue to have a way to detect when we are done processing updates
 		mockQueue = testutils.NewMockWorkQueue(controller.Queue)
@@ -626,6 +628,75 @@ var _ = Describe("VirtualMachineInstance watcher", func() {
 
 			testutils.ExpectEvent(recorder, SuccessfulCreatePodReason)
 		})
+		Context("with interfaces requesting a MAC address pool", func() {
+			const poolMacAddress = "02:00:00:00:00:00"
+
+			newVMIRequestingPool := func(name, pool string) *v1.VirtualMachineInstance {
+				vmi := NewPendingVirtualMachine(name)
+				vmi.Spec.Domain.Devices.Interfaces = []v1.Interface{*v1.DefaultBridgeNetworkInterface()}
+				vmi.Spec.Domain.Devices.Interfaces[0].MacAddressPool = pool
+				vmi.Spec.Networks = []v1.Network{*v1.DefaultPodNetwork()}
+				return vmi
+			}
+
+			expectMacAddressUpdate := func(macAddress string) {
+				vmiInterface.EXPECT().Update(gomock.Any()).DoAndReturn(func(vmi *v1.VirtualMachineInstance) (*v1.VirtualMachineInstance, error) {
+					Expect(vmi.Spec.Domain.Devices.Interfaces[0].MacAddress).To(Equal(macAddress))
+					return vmi, nil
+				})
+			}
+
+			BeforeEach(func() {
+				config, _, _, _ := testutils.NewFakeClusterConfigUsingKV(&v1.KubeVirt{
+					ObjectMeta: metav1.ObjectMeta{Name: "kubevirt", Namespace: "kubevirt"},
+					Spec: v1.KubeVirtSpec{
+						Configuration: v1.KubeVirtConfiguration{
+							NetworkConfiguration: &v1.NetworkConfiguration{
+								MacAddressPools: []v1.MacAddressPool{{Name: "pool", RangeStart: poolMacAddress, RangeEnd: poolMacAddress}},
+							},
+						},
+					},
+					Status: v1.KubeVirtStatus{Phase: v1.KubeVirtPhaseDeploying},
+				})
+				controller.macPool = services.NewMacAddressPoolAllocator(vmiInformer.GetStore(), config)
+			})
+
+			It("should claim the MAC address before creating the Pod", func() {
+				addVirtualMachine(newVMIRequestingPool("testvmi", "pool"))
+
+				expectMacAddressUpdate(poolMacAddress)
+
+				controller.Execute()
+
+				testutils.ExpectEvent(recorder, SuccessfulMacAddressClaimReason)
+			})
+
+			It("should release the MAC address on VirtualMachineInstance deletion", func() {
+				vmi := newVMIRequestingPool("testvmi", "pool")
+				addVirtualMachine(vmi)
+				expectMacAddressUpdate(poolMacAddress)
+				controller.Execute()
+				testutils.ExpectEvent(recorder, SuccessfulMacAddressClaimReason)
+
+				mockQueue.ExpectAdds(1)
+				vmiSource.Delete(vmi)
+				mockQueue.Wait()
+				controller.Execute()
+
+				addVirtualMachine(newVMIRequestingPool("othervmi", "pool"))
+				expectMacAddressUpdate(poolMacAddress)
+				controller.Execute()
+				testutils.ExpectEvent(recorder, SuccessfulMacAddressClaimReason)
+			})
+
+			It("should not create the Pod if no MAC address could be claimed", func() {
+				addVirtualMachine(newVMIRequestingPool("testvmi", "unknown"))
+
+				controller.Execute()
+
+				testutils.ExpectEvent(recorder, FailedMacAddressClaimReason)
+			})
+		})
 		table.DescribeTable("should delete the corresponding Pods on VirtualMachineInstance deletion with vmi", func(phase v1.VirtualMachineInstancePhase) {
 			vmi := NewPendingVirtualMachine("testvmi")
 			vmi.Status.Phase = phase
@@ -1105,6 +1176,27 @@ var _ = Describe("VirtualMachineInstance watcher", func() {
 
 			controller.Execute()
 		})
+		table.DescribeTable("should surface the effective drain grace period on hand over", func(podAnnotation string, expected int64) {
+			vmi := NewPendingVirtualMachine("testvmi")
+			vmi.Status.Phase = v1.Scheduling
+			pod := NewPodForVirtualMachine(vmi, k8sv1.PodRunning)
+			if podAnnotation != "" {
+				pod.Annotations[v1.DrainGracePeriodSecondsAnnotation] = podAnnotation
+			}
+
+			addVirtualMachine(vmi)
+			podFeeder.Add(pod)
+
+			vmiInterface.EXPECT().Update(gomock.Any()).Do(func(arg interface{}) {
+				Expect(arg.(*v1.VirtualMachineInstance).Status.Phase).To(Equal(v1.Scheduled))
+				Expect(*arg.(*v1.VirtualMachineInstance).Status.DrainGracePeriodSeconds).To(Equal(expected))
+			}).Return(vmi, nil)
+
+			controller.Execute()
+		},
+			table.Entry("from the pod annotation", "600", int64(600)),
+			table.Entry("defaulting to the termination grace period", "", v1.DefaultGracePeriodSeconds),
+		)
 		It("should update the virtual machine QOS class if the pod finally has a QOS class assigned", func() {
 			vmi := NewPendingVirtualMachine("testvmi")
 			vmi.Status.Phase = v1.Scheduling
@@ -1120,6 +1212,35 @@ var _ = Describe("VirtualMachineInstance watcher", func() {
 
 			controller.Execute()
 		})
+		It("should report the memory requests of a vmi overcommitting its memory", func() {
+			vmi := NewPendingVirtualMachine("testvmi")
+			vmi.Status.Phase = v1.Scheduling
+			guestMemory := resource.MustParse("3072M")
+			overcommit := uint32(150)
+			vmi.Spec.Domain.Memory = &v1.Memory{Guest: &guestMemory, OvercommitPercent: &overcommit}
+			vmi.Spec.Domain.Resources.Requests = k8sv1.ResourceList{}
+			pod := NewPodForVirtualMachine(vmi, k8sv1.PodPending)
+			pod.Spec.Containers = []k8sv1.Container{{
+				Name: "compute",
+				Resources: k8sv1.ResourceRequirements{
+					Requests: k8sv1.ResourceList{k8sv1.ResourceMemory: resource.MustParse("2248M")},
+				},
+			}}
+
+			addVirtualMachine(vmi)
+			podFeeder.Add(pod)
+
+			vmiInterface.EXPECT().Update(gomock.Any()).Do(func(arg interface{}) {
+				status := arg.(*v1.VirtualMachineInstance).Status.MemoryOvercommit
+				Expect(status).ToNot(BeNil())
+				Expect(status.Percent).To(Equal(uint32(150)))
+				Expect(status.GuestMemory.String()).To(Equal("3072M"))
+				Expect(status.MemoryRequest.String()).To(Equal("2048M"))
+				Expect(status.PodMemoryRequest.String()).To(Equal("2248M"))
+			}).Return(vmi, nil)
+
+			controller.Execute()
+		})
 		It("should update the virtual machine to scheduled if pod is ready, triggered by pod change", func() {
 			vmi := NewPendingVirtualMachine("testvmi")
 			vmi.Status.Phase = v1.Scheduling
@@ -1236,6 +1357,132 @@ var _ = Describe("VirtualMachineInstance watcher", func() {
 			controller.Execute()
 		})
 
+		table.DescribeTable("should gate the ready condition on the startup probe", func(probeCond *v1.VirtualMachineInstanceCondition, expectedStatus k8sv1.ConditionStatus, expectedReason string) {
+			vmi := NewPendingVirtualMachine("testvmi")
+			vmi.Status.Phase = v1.Running
+			vmi.Spec.StartupProbe = &v1.Probe{Handler: v1.Handler{GuestAgentPing: &v1.GuestAgentPing{}}}
+			if probeCond != nil {
+				vmi.Status.Conditions = append(vmi.Status.Conditions, *probeCond)
+			}
+			pod := NewPodForVirtualMachine(vmi, k8sv1.PodRunning)
+			pod.Status.Conditions = []k8sv1.PodCondition{{Type: k8sv1.PodReady, Status: k8sv1.ConditionTrue}}
+
+			addVirtualMachine(vmi)
+			addActivePods(vmi, pod.UID, "")
+			podFeeder.Add(pod)
+
+			vmiInterface.EXPECT().Patch(vmi.Name, types.JSONPatchType, gomock.Any()).DoAndReturn(func(_ string, _ interface{}, patchBytes []byte) (*v1.VirtualMachineInstance, error) {
+				patch, err := jsonpatch.DecodePatch(patchBytes)
+				Expect(err).ToNot(HaveOccurred())
+				vmiBytes, err := json.Marshal(vmi)
+				Expect(err).ToNot(HaveOccurred())
+				vmiBytes, err = patch.Apply(vmiBytes)
+				Expect(err).ToNot(HaveOccurred())
+				patchedVMI := &v1.VirtualMachineInstance{}
+				err = json.Unmarshal(vmiBytes, patchedVMI)
+				Expect(err).ToNot(HaveOccurred())
+				cond := kvcontroller.NewVirtualMachineInstanceConditionManager().GetCondition(patchedVMI, v1.VirtualMachineInstanceReady)
+				Expect(cond).ToNot(BeNil())
+				Expect(cond.Status).To(Equal(expectedStatus))
+				Expect(cond.Reason).To(Equal(expectedReason))
+				return patchedVMI, nil
+			})
+			controller.Execute()
+		},
+			table.Entry("while the probe did not report yet", nil, k8sv1.ConditionFalse, v1.VirtualMachineInstanceReasonStartupProbePending),
+			table.Entry("while the guest agent is unavailable",
+				&v1.VirtualMachineInstanceCondition{Type: v1.VirtualMachineInstanceStartupProbeSucceeded, Status: k8sv1.ConditionFalse, Reason: v1.VirtualMachineInstanceReasonGuestAgentUnavailable},
+				k8sv1.ConditionFalse, v1.VirtualMachineInstanceReasonGuestAgentUnavailable),
+			table.Entry("once the probe failed",
+				&v1.VirtualMachineInstanceCondition{Type: v1.VirtualMachineInstanceStartupProbeSucceeded, Status: k8sv1.ConditionFalse, Reason: v1.VirtualMachineInstanceReasonStartupProbeFailed},
+				k8sv1.ConditionFalse, v1.VirtualMachineInstanceReasonStartupProbeFailed),
+			table.Entry("and open it once the probe succeeded",
+				&v1.VirtualMachineInstanceCondition{Type: v1.VirtualMachineInstanceStartupProbeSucceeded, Status: k8sv1.ConditionTrue},
+				k8sv1.ConditionTrue, ""),
+		)
+
+		Context("with a liveness probe which migrates on failures", func() {
+			var vmi *v1.VirtualMachineInstance
+			var pod *k8sv1.Pod
+
+			BeforeEach(func() {
+				vmi = NewPendingVirtualMachine("testvmi")
+				vmi.Status.Phase = v1.Running
+				vmi.Status.NodeName = "node01"
+				vmi.Spec.LivenessProbe = &v1.Probe{Handler: v1.Handler{GuestAgentPing: &v1.GuestAgentPing{}}}
+				vmi.Spec.LivenessProbeFailureAction = v1.LivenessProbeFailureActionMigrate
+				vmi.Status.Conditions = []v1.VirtualMachineInstanceCondition{{Type: v1.VirtualMachineInstanceReady, Status: k8sv1.ConditionTrue}}
+				pod = NewPodForVirtualMachine(vmi, k8sv1.PodRunning)
+				pod.Spec.NodeName = "node01"
+				pod.Status.Conditions = []k8sv1.PodCondition{{Type: k8sv1.PodReady, Status: k8sv1.ConditionFalse}}
+			})
+
+			It("should migrate a migratable VMI once the probe failed", func() {
+				vmi.Status.Conditions = append(vmi.Status.Conditions, v1.VirtualMachineInstanceCondition{Type: v1.VirtualMachineInstanceIsMigratable, Status: k8sv1.ConditionTrue})
+				addVirtualMachine(vmi)
+				addActivePods(vmi, pod.UID, "")
+				podFeeder.Add(pod)
+
+				migrationInterface := kubecli.NewMockVirtualMachineInstanceMigrationInterface(ctrl)
+				virtClient.EXPECT().VirtualMachineInstanceMigration(k8sv1.NamespaceDefault).Return(migrationInterface)
+				migrationInterface.EXPECT().Create(gomock.Any()).DoAndReturn(func(migration *v1.VirtualMachineInstanceMigration) (*v1.VirtualMachineInstanceMigration, error) {
+					Expect(migration.Spec.VMIName).To(Equal(vmi.Name))
+					return migration, nil
+				})
+				vmiInterface.EXPECT().Patch(vmi.Name, types.JSONPatchType, gomock.Any()).Return(vmi, nil)
+
+				controller.Execute()
+				Expect(recorder.Events).To(Receive(ContainSubstring("migrating the VMI away from node node01")))
+			})
+
+			It("should restart a non-migratable VMI once the probe failed", func() {
+				addVirtualMachine(vmi)
+				addActivePods(vmi, pod.UID, "")
+				podFeeder.Add(pod)
+
+				shouldExpectPodDeletion(pod)
+				vmiInterface.EXPECT().Patch(vmi.Name, types.JSONPatchType, gomock.Any()).Return(vmi, nil)
+
+				controller.Execute()
+				Expect(recorder.Events).To(Receive(ContainSubstring("restarting the VMI since it is not live migratable")))
+			})
+
+			It("should keep the VMI ready if the migration could not be created", func() {
+				vmi.Status.Conditions = append(vmi.Status.Conditions, v1.VirtualMachineInstanceCondition{Type: v1.VirtualMachineInstanceIsMigratable, Status: k8sv1.ConditionTrue})
+				addVirtualMachine(vmi)
+				addActivePods(vmi, pod.UID, "")
+				podFeeder.Add(pod)
+
+				migrationInterface := kubecli.NewMockVirtualMachineInstanceMigrationInterface(ctrl)
+				virtClient.EXPECT().VirtualMachineInstanceMigration(k8sv1.NamespaceDefault).Return(migrationInterface)
+				migrationInterface.EXPECT().Create(gomock.Any()).Return(nil, fmt.Errorf("failure"))
+
+				controller.Execute()
+				testutils.ExpectEvent(recorder, FailedMigrationReason)
+			})
+
+			table.DescribeTable("should only mark the VMI as not ready", func(modify func(vmi *v1.VirtualMachineInstance)) {
+				modify(vmi)
+				addVirtualMachine(vmi)
+				addActivePods(vmi, pod.UID, "")
+				podFeeder.Add(pod)
+
+				vmiInterface.EXPECT().Patch(vmi.Name, types.JSONPatchType, gomock.Any()).Return(vmi, nil)
+
+				controller.Execute()
+			},
+				table.Entry("if the VMI restarts on failures", func(vmi *v1.VirtualMachineInstance) {
+					vmi.Spec.LivenessProbeFailureAction = v1.LivenessProbeFailureActionRestart
+				}),
+				table.Entry("if the VMI was never ready", func(vmi *v1.VirtualMachineInstance) {
+					vmi.Status.Conditions = nil
+				}),
+				table.Entry("while the VMI migrates", func(vmi *v1.VirtualMachineInstance) {
+					vmi.Status.MigrationState = &v1.VirtualMachineInstanceMigrationState{}
+				}),
+			)
+		})
+
 		It("should indicate on the ready condition if the pod is terminating", func() {
 			vmi := NewPendingVirtualMachine("testvmi")
 			vmi.Status.Phase = v1.Running
@@ -1316,6 +1563,69 @@ var _ = Describe("VirtualMachineInstance watcher", func() {
 			controller.Execute()
 		})
 
+		Context("with the compute container waiting for the SELinux relabeling", func() {
+			newRelabelingPod := func(vmi *v1.VirtualMachineInstance, startedAt time.Time) *k8sv1.Pod {
+				pod := NewPodForVirtualMachine(vmi, k8sv1.PodRunning)
+				pod.Status.ContainerStatuses[0].State.Running.StartedAt = metav1.NewTime(startedAt)
+				return pod
+			}
+
+			It("should fail the vmi if virt-handler did not confirm the relabeling within the timeout", func() {
+				vmi := NewPendingVirtualMachine("testvmi")
+				vmi.Status.Phase = v1.Scheduled
+				pod := newRelabelingPod(vmi, time.Now().Add(-10*time.Minute))
+
+				addVirtualMachine(vmi)
+				podFeeder.Add(pod)
+				addActivePods(vmi, pod.UID, "")
+
+				vmiInterface.EXPECT().Patch(vmi.Name, types.JSONPatchType, gomock.Any()).DoAndReturn(func(_ string, _ interface{}, patchBytes []byte) (*v1.VirtualMachineInstance, error) {
+					patch := string(patchBytes)
+					Expect(patch).To(ContainSubstring(`{ "op": "test", "path": "/status/phase", "value": "Scheduled" }`))
+					Expect(patch).To(ContainSubstring(`{ "op": "replace", "path": "/status/phase", "value": "Failed" }`))
+					Expect(patch).To(ContainSubstring(v1.VirtualMachineInstanceReasonSELinuxRelabelTimedOut))
+					return vmi, nil
+				})
+
+				controller.Execute()
+				testutils.ExpectEvent(recorder, v1.VirtualMachineInstanceReasonSELinuxRelabelTimedOut)
+			})
+
+			It("should requeue the vmi while the timeout did not pass", func() {
+				vmi := NewPendingVirtualMachine("testvmi")
+				vmi.Status.Phase = v1.Scheduled
+				pod := newRelabelingPod(vmi, time.Now().Add(-time.Minute))
+
+				addVirtualMachine(vmi)
+				podFeeder.Add(pod)
+				addActivePods(vmi, pod.UID, "")
+
+				controller.Execute()
+				Expect(mockQueue.GetAddAfterEnqueueCount()).To(Equal(1))
+			})
+
+			table.DescribeTable("should not fail the vmi", func(prepare func(vmi *v1.VirtualMachineInstance, pod *k8sv1.Pod)) {
+				vmi := NewPendingVirtualMachine("testvmi")
+				vmi.Status.Phase = v1.Scheduled
+				pod := newRelabelingPod(vmi, time.Now().Add(-10*time.Minute))
+				prepare(vmi, pod)
+
+				addVirtualMachine(vmi)
+				podFeeder.Add(pod)
+				addActivePods(vmi, pod.UID, "")
+
+				controller.Execute()
+				Expect(mockQueue.GetAddAfterEnqueueCount()).To(BeZero())
+			},
+				table.Entry("if virt-handler confirmed the relabeling", func(_ *v1.VirtualMachineInstance, pod *k8sv1.Pod) {
+					pod.Status.ContainerStatuses[0].Ready = true
+				}),
+				table.Entry("if the vmi has a readiness probe", func(vmi *v1.VirtualMachineInstance, _ *k8sv1.Pod) {
+					vmi.Spec.ReadinessProbe = &v1.Probe{Handler: v1.Handler{GuestAgentPing: &v1.GuestAgentPing{}}}
+				}),
+			)
+		})
+
 		table.DescribeTable("should do nothing if the vmi is handed over to virt-handler, the pod disappears", func(phase v1.VirtualMachineInstancePhase) {
 			vmi := NewPendingVirtualMachine("testvmi")
 			vmi.Status.Phase = phase
@@ -2212,6 +2522,139 @@ var _ = Describe("VirtualMachineInstance watcher", func() {
 			Expect(vmi.Status.Phase).To(Equal(v1.Running))
 		})
 	})
+	Context("memory dump", func() {
+		var vmi *v1.VirtualMachineInstance
+		var virtlauncherPod *k8sv1.Pod
+
+		newMemoryDumpPod := func(claimName string, ready bool) *k8sv1.Pod {
+			dumpPod := NewPodForVirtlauncher(virtlauncherPod, "memory-dump-abcd", "abcd", k8sv1.PodRunning)
+			dumpPod.Labels = map[string]string{v1.AppLabel: services.MemoryDumpAppLabel}
+			dumpPod.Spec.Volumes = []k8sv1.Volume{{
+				Name: "memory-dump",
+				VolumeSource: k8sv1.VolumeSource{
+					PersistentVolumeClaim: &k8sv1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+				},
+			}}
+			dumpPod.Status.ContainerStatuses = []k8sv1.ContainerStatus{{Name: "memory-dump", Ready: ready}}
+			return dumpPod
+		}
+
+		expectMemoryDumpPatch := func(matcher func(dump *v1.MemoryDumpStatus)) {
+			vmiInterface.EXPECT().Patch(vmi.Name, types.JSONPatchType, gomock.Any()).DoAndReturn(func(_ string, _ interface{}, patchBytes []byte) (*v1.VirtualMachineInstance, error) {
+				var patch []struct {
+					Op    string              `json:"op"`
+					Path  string              `json:"path"`
+					Value v1.MemoryDumpStatus `json:"value"`
+				}
+				Expect(json.Unmarshal(patchBytes, &patch)).To(Succeed())
+				Expect(patch).To(HaveLen(2))
+				Expect(patch[1].Op).To(Equal("replace"))
+				Expect(patch[1].Path).To(Equal("/status/memoryDump"))
+				matcher(&patch[1].Value)
+				return vmi, nil
+			})
+		}
+
+		BeforeEach(func() {
+			vmi = NewPendingVirtualMachine("testvmi")
+			vmi.Status.Phase = v1.Running
+			vmi.Status.ActivePods["virt-launch-uid"] = ""
+			vmi.Status.MemoryDump = &v1.MemoryDumpStatus{
+				ClaimName: "dump-claim",
+				Phase:     v1.MemoryDumpPending,
+				FileName:  "testvmi-20210101-000000.memory.dump",
+			}
+			virtlauncherPod = NewPodForVirtualMachine(vmi, k8sv1.PodRunning)
+		})
+
+		It("should create a pod mounting the claim of a requested memory dump", func() {
+			kubeClient.Fake.PrependReactor("create", "pods", func(action testing.Action) (handled bool, obj runtime.Object, err error) {
+				update, ok := action.(testing.CreateAction)
+				Expect(ok).To(BeTrue())
+				pod := update.GetObject().(*k8sv1.Pod)
+				Expect(pod.GenerateName).To(Equal("memory-dump-"))
+				Expect(pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal("dump-claim"))
+				return true, pod, nil
+			})
+			addVirtualMachine(vmi)
+			podInformer.GetIndexer().Add(virtlauncherPod)
+
+			controller.Execute()
+			testutils.ExpectEvent(recorder, SuccessfulCreatePodReason)
+		})
+
+		It("should record the pod mounting the claim once it is ready", func() {
+			addVirtualMachine(vmi)
+			podInformer.GetIndexer().Add(virtlauncherPod)
+			podInformer.GetIndexer().Add(newMemoryDumpPod("dump-claim", true))
+			expectMemoryDumpPatch(func(dump *v1.MemoryDumpStatus) {
+				Expect(dump.Phase).To(Equal(v1.MemoryDumpPending))
+				Expect(dump.TargetPodName).To(Equal("memory-dump-abcd"))
+				Expect(dump.TargetPodUID).To(Equal(types.UID("abcd")))
+			})
+
+			controller.Execute()
+		})
+
+		It("should not hand the pod mounting the claim to virt-handler before it is ready", func() {
+			addVirtualMachine(vmi)
+			podInformer.GetIndexer().Add(virtlauncherPod)
+			podInformer.GetIndexer().Add(newMemoryDumpPod("dump-claim", false))
+			expectMemoryDumpPatch(func(dump *v1.MemoryDumpStatus) {
+				Expect(dump.TargetPodName).To(Equal("memory-dump-abcd"))
+				Expect(dump.TargetPodUID).To(BeEmpty())
+			})
+
+			controller.Execute()
+		})
+
+		It("should fail the memory dump when the pod mounting the claim went away", func() {
+			vmi.Status.MemoryDump.Phase = v1.MemoryDumpInProgress
+			vmi.Status.MemoryDump.TargetPodName = "memory-dump-abcd"
+			vmi.Status.MemoryDump.TargetPodUID = "abcd"
+			addVirtualMachine(vmi)
+			podInformer.GetIndexer().Add(virtlauncherPod)
+			expectMemoryDumpPatch(func(dump *v1.MemoryDumpStatus) {
+				Expect(dump.Phase).To(Equal(v1.MemoryDumpFailed))
+				Expect(dump.Message).To(ContainSubstring("memory-dump-abcd"))
+				Expect(dump.EndTimestamp).ToNot(BeNil())
+			})
+
+			controller.Execute()
+		})
+
+		table.DescribeTable("should delete the pod mounting the claim", func(phase v1.MemoryDumpPhase, claimName string) {
+			vmi.Status.MemoryDump.Phase = phase
+			dumpPod := newMemoryDumpPod(claimName, true)
+			if phase == v1.MemoryDumpPending {
+				// The pod of the previous dump was not recorded for the pending one
+				dumpPod.Name = "memory-dump-previous"
+			} else {
+				vmi.Status.MemoryDump.TargetPodName = dumpPod.Name
+				vmi.Status.MemoryDump.TargetPodUID = dumpPod.UID
+			}
+			addVirtualMachine(vmi)
+			podInformer.GetIndexer().Add(virtlauncherPod)
+			podInformer.GetIndexer().Add(dumpPod)
+			shouldExpectPodDeletion(dumpPod)
+
+			controller.Execute()
+			testutils.ExpectEvent(recorder, SuccessfulDeletePodReason)
+		},
+			table.Entry("once the memory dump completed", v1.MemoryDumpCompleted, "dump-claim"),
+			table.Entry("once the memory dump failed", v1.MemoryDumpFailed, "dump-claim"),
+			table.Entry("when it is left over from a dump to another claim", v1.MemoryDumpPending, "other-claim"),
+		)
+
+		It("should not treat the pod mounting the claim as an attachment pod", func() {
+			podInformer.GetIndexer().Add(virtlauncherPod)
+			podInformer.GetIndexer().Add(newMemoryDumpPod("dump-claim", true))
+
+			attachmentPods, err := controller.virtlauncherAttachmentPods(virtlauncherPod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(attachmentPods).To(BeEmpty())
+		})
+	})
 })
 
 func NewDv(namespace string, name string, phase cdiv1.DataVolumePhase) *cdiv1.DataVolume {
@@ -67,6 +67,8 @@ var (
 const StandardLauncherSocketFileName = "launcher-sock"
 const StandardInitLauncherSocketFileName = "launcher-init-sock"
 const StandardLauncherUnresponsiveFileName = "launcher-unresponsive"
+const StandardLauncherSELinuxRelabeledFileName = "launcher-selinux-relabeled"
+const StandardLauncherReadyFileName = "launcher-ready"
 
 type MigrationOptions struct {
 	Bandwidth               resource.Quantity
@@ -75,12 +77,19 @@ type MigrationOptions struct {
 	UnsafeMigration         bool
 	AllowAutoConverge       bool
 	AllowPostCopy           bool
+	// ParallelMigrationThreads is the number of parallel connections
+	// migrating the memory, zero migrates it over a single one
+	ParallelMigrationThreads uint32
 }
 
 type LauncherClient interface {
 	SyncVirtualMachine(vmi *v1.VirtualMachineInstance, options *cmdv1.VirtualMachineOptions) error
 	PauseVirtualMachine(vmi *v1.VirtualMachineInstance) error
 	UnpauseVirtualMachine(vmi *v1.VirtualMachineInstance) error
+	SoftRebootVirtualMachine(vmi *v1.VirtualMachineInstance) error
+	FreezeVirtualMachine(vmi *v1.VirtualMachineInstance) error
+	UnfreezeVirtualMachine(vmi *v1.VirtualMachineInstance) error
+	VirtualMachineMemoryDump(vmi *v1.VirtualMachineInstance, dumpPath string) error
 	SyncMigrationTarget(vmi *v1.VirtualMachineInstance) error
 	ShutdownVirtualMachine(vmi *v1.VirtualMachineInstance) error
 	KillVirtualMachine(vmi *v1.VirtualMachineInstance) error
@@ -215,6 +224,24 @@ func MarkSocketUnresponsive(socket string) error {
 	return nil
 }
 
+// MarkSELinuxRelabeled tells the launcher serving socket that virt-handler
+// applied all the SELinux labels its VMI needs.
+func MarkSELinuxRelabeled(socket string) error {
+	file := filepath.Join(filepath.Dir(socket), StandardLauncherSELinuxRelabeledFileName)
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return nil
+}
+
+func IsSELinuxRelabeled(socket string) bool {
+	file := filepath.Join(filepath.Dir(socket), StandardLauncherSELinuxRelabeledFileName)
+	exists, _ := diskutils.FileExists(file)
+	return exists
+}
+
 func SocketDirectoryOnHost(podUID string) string {
 	return fmt.Sprintf("/%s/%s/volumes/kubernetes.io~empty-dir/sockets", podsBaseDir, string(podUID))
 }
@@ -288,6 +315,14 @@ func UninitializedSocketOnGuest() string {
 	return filepath.Join(LegacySocketsDirectory(), sockFile)
 }
 
+func SELinuxRelabeledFileOnGuest() string {
+	return filepath.Join(LegacySocketsDirectory(), StandardLauncherSELinuxRelabeledFileName)
+}
+
+func ReadyFileOnGuest() string {
+	return filepath.Join(LegacySocketsDirectory(), StandardLauncherReadyFileName)
+}
+
 func NewClient(socketPath string) (LauncherClient, error) {
 	// dial socket
 	conn, err := grpcutil.DialSocket(socketPath)
@@ -414,6 +449,39 @@ func (c *VirtLauncherClient) UnpauseVirtualMachine(vmi *v1.VirtualMachineInstanc
 	return c.genericSendVMICmd("Unpause", c.v1client.UnpauseVirtualMachine, vmi, &cmdv1.VirtualMachineOptions{})
 }
 
+func (c *VirtLauncherClient) SoftRebootVirtualMachine(vmi *v1.VirtualMachineInstance) error {
+	return c.genericSendVMICmd("SoftReboot", c.v1client.SoftRebootVirtualMachine, vmi, &cmdv1.VirtualMachineOptions{})
+}
+
+func (c *VirtLauncherClient) FreezeVirtualMachine(vmi *v1.VirtualMachineInstance) error {
+	return c.genericSendVMICmd("Freeze", c.v1client.FreezeVirtualMachine, vmi, &cmdv1.VirtualMachineOptions{})
+}
+
+func (c *VirtLauncherClient) UnfreezeVirtualMachine(vmi *v1.VirtualMachineInstance) error {
+	return c.genericSendVMICmd("Unfreeze", c.v1client.UnfreezeVirtualMachine, vmi, &cmdv1.VirtualMachineOptions{})
+}
+
+func (c *VirtLauncherClient) VirtualMachineMemoryDump(vmi *v1.VirtualMachineInstance, dumpPath string) error {
+	vmiJson, err := json.Marshal(vmi)
+	if err != nil {
+		return err
+	}
+
+	request := &cmdv1.MemoryDumpRequest{
+		Vmi: &cmdv1.VMI{
+			VmiJson: vmiJson,
+		},
+		DumpPath: dumpPath,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shortTimeout)
+	defer cancel()
+	response, err := c.v1client.VirtualMachineMemoryDump(ctx, request)
+
+	err = handleError(err, "MemoryDump", response)
+	return err
+}
+
 func (c *VirtLauncherClient) ShutdownVirtualMachine(vmi *v1.VirtualMachineInstance) error {
 	return c.genericSendVMICmd("Shutdown", c.v1client.ShutdownVirtualMachine, vmi, &cmdv1.VirtualMachineOptions{})
 }
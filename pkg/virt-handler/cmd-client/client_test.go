@@ -137,6 +137,17 @@ var _ = Describe("Virt remote commands", func() {
 			Expect(unresponsive).To(BeTrue())
 		})
 
+		It("Mark a socket as SELinux relabeled", func() {
+			sock, err := FindSocketOnHost(vmi)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(IsSELinuxRelabeled(sock)).To(BeFalse())
+
+			Expect(MarkSELinuxRelabeled(sock)).To(Succeed())
+			Expect(IsSELinuxRelabeled(sock)).To(BeTrue())
+			Expect(filepath.Join(filepath.Dir(sock), StandardLauncherSELinuxRelabeledFileName)).To(BeAnExistingFile())
+		})
+
 		It("Determine legacy sockets vs new socket paths", func() {
 			legacy := IsLegacySocket("/some/path/something_sock")
 			Expect(legacy).To(BeTrue())
@@ -8,6 +8,7 @@ import (
 	types "k8s.io/apimachinery/pkg/types"
 
 	v1 "kubevirt.io/client-go/api/v1"
+	selinux "kubevirt.io/kubevirt/pkg/virt-handler/selinux"
 )
 
 // Mock of VolumeMounter interface
@@ -71,3 +72,55 @@ func (_m *MockVolumeMounter) IsMounted(vmi *v1.VirtualMachineInstance, volume st
 func (_mr *_MockVolumeMounterRecorder) IsMounted(arg0, arg1, arg2 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "IsMounted", arg0, arg1, arg2)
 }
+
+func (_m *MockVolumeMounter) ReconcileSELinuxLabels(vmi *v1.VirtualMachineInstance) error {
+	ret := _m.ctrl.Call(_m, "ReconcileSELinuxLabels", vmi)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockVolumeMounterRecorder) ReconcileSELinuxLabels(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ReconcileSELinuxLabels", arg0)
+}
+
+func (_m *MockVolumeMounter) RelabelSELinuxLabels(vmi *v1.VirtualMachineInstance) ([]selinux.FileRelabel, error) {
+	ret := _m.ctrl.Call(_m, "RelabelSELinuxLabels", vmi)
+	ret0, _ := ret[0].([]selinux.FileRelabel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockVolumeMounterRecorder) RelabelSELinuxLabels(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "RelabelSELinuxLabels", arg0)
+}
+
+func (_m *MockVolumeMounter) VerifySELinuxLabels(vmi *v1.VirtualMachineInstance) ([]selinux.LabelMismatch, error) {
+	ret := _m.ctrl.Call(_m, "VerifySELinuxLabels", vmi)
+	ret0, _ := ret[0].([]selinux.LabelMismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockVolumeMounterRecorder) VerifySELinuxLabels(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "VerifySELinuxLabels", arg0)
+}
+
+func (_m *MockVolumeMounter) MountMemoryDump(vmi *v1.VirtualMachineInstance) error {
+	ret := _m.ctrl.Call(_m, "MountMemoryDump", vmi)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockVolumeMounterRecorder) MountMemoryDump(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "MountMemoryDump", arg0)
+}
+
+func (_m *MockVolumeMounter) UnmountMemoryDump(vmi *v1.VirtualMachineInstance) error {
+	ret := _m.ctrl.Call(_m, "UnmountMemoryDump", vmi)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockVolumeMounterRecorder) UnmountMemoryDump(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "UnmountMemoryDump", arg0)
+}
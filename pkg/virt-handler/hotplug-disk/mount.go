@@ -15,11 +15,14 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	diskutils "kubevirt.io/kubevirt/pkg/ephemeral-disk-utils"
 	hotplugdisk "kubevirt.io/kubevirt/pkg/hotplug-disk"
 	"kubevirt.io/kubevirt/pkg/util"
+	"kubevirt.io/kubevirt/pkg/util/fdhygiene"
 	"kubevirt.io/kubevirt/pkg/virt-handler/isolation"
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux"
 
 	"github.com/opencontainers/runc/libcontainer/cgroups/devices"
 	"github.com/opencontainers/runc/libcontainer/cgroups/fscommon"
@@ -45,18 +48,22 @@ var (
 	}
 
 	statCommand = func(fileName string) ([]byte, error) {
+		fdhygiene.PreventLeakOntoChild()
 		return exec.Command("/usr/bin/stat", fileName, "-L", "-c%t,%T,%a,%F").CombinedOutput()
 	}
 
 	mknodCommand = func(deviceName string, major, minor int64, blockDevicePermissions string) ([]byte, error) {
+		fdhygiene.PreventLeakOntoChild()
 		return exec.Command("/usr/bin/mknod", "--mode", fmt.Sprintf("0%s", blockDevicePermissions), deviceName, "b", strconv.FormatInt(major, 10), strconv.FormatInt(minor, 10)).CombinedOutput()
 	}
 
 	mountCommand = func(sourcePath, targetPath string) ([]byte, error) {
+		fdhygiene.PreventLeakOntoChild()
 		return exec.Command("/usr/bin/virt-chroot", "--mount", "/proc/1/ns/mnt", "mount", "-o", "bind", strings.TrimPrefix(sourcePath, isolation.NodeIsolationResult().MountRoot()), targetPath).CombinedOutput()
 	}
 
 	unmountCommand = func(diskPath string) ([]byte, error) {
+		fdhygiene.PreventLeakOntoChild()
 		return exec.Command("/usr/bin/virt-chroot", "--mount", "/proc/1/ns/mnt", "umount", diskPath).CombinedOutput()
 	}
 
@@ -77,6 +84,12 @@ type volumeMounter struct {
 	mountRecords         map[types.UID]*vmiMountTargetRecord
 	mountRecordsLock     sync.Mutex
 	skipSafetyCheck      bool
+	lastRelabels         map[types.UID]time.Time
+	lastRelabelsLock     sync.Mutex
+	// relabelParallelism returns how many volumes are relabeled at once, one if nil
+	relabelParallelism func() int
+	// relabelSkipPaths returns the paths never relabeled, none if nil
+	relabelSkipPaths func() []string
 }
 
 // VolumeMounter is the interface used to mount and unmount volumes to/from a running virtlauncher pod.
@@ -89,6 +102,17 @@ type VolumeMounter interface {
 	UnmountAll(vmi *v1.VirtualMachineInstance) error
 	//IsMounted returns if the volume is mounted or not.
 	IsMounted(vmi *v1.VirtualMachineInstance, volume string, sourceUID types.UID) (bool, error)
+	// ReconcileSELinuxLabels re-applies the launcher selinux label on the mounted volumes which drifted from it
+	ReconcileSELinuxLabels(vmi *v1.VirtualMachineInstance) error
+	// RelabelSELinuxLabels re-applies the launcher selinux label on the mounted volumes regardless of the last
+	// reconciliation, and returns the outcome for each of them
+	RelabelSELinuxLabels(vmi *v1.VirtualMachineInstance) ([]selinux.FileRelabel, error)
+	// VerifySELinuxLabels returns the mounted volumes not carrying the launcher selinux label, without relabeling them
+	VerifySELinuxLabels(vmi *v1.VirtualMachineInstance) ([]selinux.LabelMismatch, error)
+	// MountMemoryDump mounts the claim of the memory dump of the VMI, attached by its target pod, into the virtlauncher pod
+	MountMemoryDump(vmi *v1.VirtualMachineInstance) error
+	// UnmountMemoryDump unmounts the claim of the memory dump of the VMI from the virtlauncher pod
+	UnmountMemoryDump(vmi *v1.VirtualMachineInstance) error
 }
 
 type vmiMountTargetEntry struct {
@@ -99,12 +123,16 @@ type vmiMountTargetRecord struct {
 	MountTargetEntries []vmiMountTargetEntry `json:"mountTargetEntries"`
 }
 
-// NewVolumeMounter creates a new VolumeMounter
-func NewVolumeMounter(isoDetector isolation.PodIsolationDetector, mountStateDir string) VolumeMounter {
+// NewVolumeMounter creates a new VolumeMounter, relabeling up to relabelParallelism() volumes of a VMI at once,
+// except the ones matching relabelSkipPaths()
+func NewVolumeMounter(isoDetector isolation.PodIsolationDetector, mountStateDir string, relabelParallelism func() int, relabelSkipPaths func() []string) VolumeMounter {
 	return &volumeMounter{
 		podIsolationDetector: isoDetector,
 		mountRecords:         make(map[types.UID]*vmiMountTargetRecord),
 		mountStateDir:        mountStateDir,
+		lastRelabels:         make(map[types.UID]time.Time),
+		relabelParallelism:   relabelParallelism,
+		relabelSkipPaths:     relabelSkipPaths,
 	}
 }
 
@@ -136,6 +164,10 @@ func (m *volumeMounter) deleteMountTargetRecord(vmi *v1.VirtualMachineInstance)
 	defer m.mountRecordsLock.Unlock()
 	delete(m.mountRecords, vmi.UID)
 
+	m.lastRelabelsLock.Lock()
+	defer m.lastRelabelsLock.Unlock()
+	delete(m.lastRelabels, vmi.UID)
+
 	return nil
 }
 
@@ -583,6 +615,102 @@ func (m *volumeMounter) getSourcePodFilePath(sourceUID types.UID) (string, error
 	return diskPath, nil
 }
 
+func (m *volumeMounter) getMemoryDumpSourcePath(sourceUID types.UID) (string, error) {
+	claimPath := ""
+	if sourceUID != types.UID("") {
+		basepath := sourcePodBasePath(sourceUID)
+		err := filepath.Walk(basepath, func(filePath string, info os.FileInfo, err error) error {
+			if path.Base(filePath) == hotplugdisk.MemoryDumpMarkerFileName {
+				// Found the root of the claim
+				claimPath = path.Dir(filePath)
+				return io.EOF
+			}
+			return nil
+		})
+		if err != nil && err != io.EOF {
+			return claimPath, err
+		}
+	}
+	if claimPath == "" {
+		return claimPath, fmt.Errorf("Unable to find the memory dump claim path for pod %s", sourceUID)
+	}
+	return claimPath, nil
+}
+
+// MountMemoryDump bind mounts the claim of the memory dump, found by the marker file its target pod creates, into
+// the virtlauncher pod.
+func (m *volumeMounter) MountMemoryDump(vmi *v1.VirtualMachineInstance) error {
+	if vmi.Status.MemoryDump == nil || vmi.Status.MemoryDump.TargetPodUID == types.UID("") {
+		return nil
+	}
+	record, err := m.getMountTargetRecord(vmi)
+	if err != nil {
+		return err
+	}
+	sourcePath, err := m.getMemoryDumpSourcePath(vmi.Status.MemoryDump.TargetPodUID)
+	if err != nil {
+		return err
+	}
+
+	virtlauncherUID := m.findVirtlauncherUID(vmi)
+	if virtlauncherUID == "" {
+		// This is not the node the pod is running on.
+		return nil
+	}
+	targetPath, err := hotplugdisk.GetMemoryDumpTargetPathFromHostView(virtlauncherUID, true)
+	if err != nil {
+		return err
+	}
+
+	if isMounted, err := isMounted(targetPath); err != nil {
+		return fmt.Errorf("failed to determine if %s is already mounted: %v", targetPath, err)
+	} else if !isMounted {
+		if err := m.writePathToMountRecord(targetPath, vmi, record); err != nil {
+			return err
+		}
+		if out, err := mountCommand(sourcePath, targetPath); err != nil {
+			return fmt.Errorf("failed to bindmount memory dump claim %s: %v : %v", vmi.Status.MemoryDump.ClaimName, string(out), err)
+		}
+	}
+	return nil
+}
+
+// UnmountMemoryDump unmounts the claim of the memory dump from the virtlauncher pod.
+func (m *volumeMounter) UnmountMemoryDump(vmi *v1.VirtualMachineInstance) error {
+	virtlauncherUID := m.findVirtlauncherUID(vmi)
+	if virtlauncherUID == "" {
+		// This is not the node the pod is running on.
+		return nil
+	}
+	targetPath, err := hotplugdisk.GetMemoryDumpTargetPathFromHostView(virtlauncherUID, false)
+	if err != nil {
+		return err
+	}
+	if err := m.unmountFileSystemHotplugVolumes(targetPath); err != nil {
+		return err
+	}
+
+	record, err := m.getMountTargetRecord(vmi)
+	if err != nil {
+		return err
+	}
+	newRecord := vmiMountTargetRecord{
+		MountTargetEntries: make([]vmiMountTargetEntry, 0),
+	}
+	for _, entry := range record.MountTargetEntries {
+		if entry.TargetFile != targetPath {
+			newRecord.MountTargetEntries = append(newRecord.MountTargetEntries, entry)
+		}
+	}
+	if len(newRecord.MountTargetEntries) == len(record.MountTargetEntries) {
+		return nil
+	}
+	if len(newRecord.MountTargetEntries) > 0 {
+		return m.setMountTargetRecord(vmi, &newRecord)
+	}
+	return m.deleteMountTargetRecord(vmi)
+}
+
 // Unmount unmounts all hotplug disk that are no longer part of the VMI
 func (m *volumeMounter) Unmount(vmi *v1.VirtualMachineInstance) error {
 	if vmi.UID != "" {
@@ -619,6 +747,10 @@ func (m *volumeMounter) Unmount(vmi *v1.VirtualMachineInstance) error {
 				currentHotplugPaths[path] = virtlauncherUID
 			}
 		}
+		// The claim of a memory dump is unmounted by UnmountMemoryDump once the dump finished
+		if memoryDumpPath, err := hotplugdisk.GetMemoryDumpTargetPathFromHostView(virtlauncherUID, false); err == nil {
+			currentHotplugPaths[memoryDumpPath] = virtlauncherUID
+		}
 		newRecord := vmiMountTargetRecord{
 			MountTargetEntries: make([]vmiMountTargetEntry, 0),
 		}
@@ -685,6 +685,90 @@ var _ = Describe("HotplugVolume filesystem volumes", func() {
 		Expect(err).To(HaveOccurred())
 	})
 
+	It("should mount and unmount the claim of a memory dump", func() {
+		sourcePodUID := "mnbvc"
+		path := filepath.Join(tempDir, sourcePodUID, "volumes", "kubernetes.io~csi", "pvc-1234", "mount")
+		err = os.MkdirAll(path, 0755)
+		Expect(err).ToNot(HaveOccurred())
+		sourcePodBasePath = func(podUID types.UID) string {
+			return filepath.Join(tempDir, string(podUID), "volumes")
+		}
+		_, err = os.Create(filepath.Join(path, hotplugdisk.MemoryDumpMarkerFileName))
+		Expect(err).ToNot(HaveOccurred())
+		hotplugdisk.SetKubeletPodsDirectory(tempDir)
+		targetPodPath := filepath.Join(tempDir, string(m.findVirtlauncherUID(vmi)), "volumes/kubernetes.io~empty-dir/hotplug-disks")
+		err = os.MkdirAll(targetPodPath, 0755)
+		Expect(err).ToNot(HaveOccurred())
+		targetDirPath := filepath.Join(targetPodPath, ".memory-dump")
+		vmi.Status.MemoryDump = &v1.MemoryDumpStatus{
+			ClaimName:    "dump-pvc",
+			Phase:        v1.MemoryDumpPending,
+			TargetPodUID: types.UID(sourcePodUID),
+		}
+
+		mounted := false
+		isMounted = func(diskPath string) (bool, error) {
+			Expect(diskPath).To(Equal(targetDirPath))
+			return mounted, nil
+		}
+		mountCommand = func(sourcePath, targetPath string) ([]byte, error) {
+			Expect(sourcePath).To(Equal(path))
+			Expect(targetPath).To(Equal(targetDirPath))
+			mounted = true
+			return []byte("Success"), nil
+		}
+		Expect(m.MountMemoryDump(vmi)).To(Succeed())
+		Expect(mounted).To(BeTrue())
+		record, err := m.getMountTargetRecord(vmi)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(record.MountTargetEntries).To(ConsistOf(vmiMountTargetEntry{TargetFile: targetDirPath}))
+
+		By("keeping the claim mounted when the hotplug volumes are unmounted")
+		Expect(m.Unmount(vmi)).To(Succeed())
+		record, err = m.getMountTargetRecord(vmi)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(record.MountTargetEntries).To(HaveLen(1))
+
+		unmountCommand = func(diskPath string) ([]byte, error) {
+			Expect(diskPath).To(Equal(targetDirPath))
+			mounted = false
+			return []byte("Success"), nil
+		}
+		Expect(m.UnmountMemoryDump(vmi)).To(Succeed())
+		Expect(mounted).To(BeFalse())
+		Expect(targetDirPath).ToNot(BeADirectory())
+		record, err = m.getMountTargetRecord(vmi)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(record.MountTargetEntries).To(BeEmpty())
+	})
+
+	It("should not mount the claim of a memory dump before it is attached", func() {
+		vmi.Status.MemoryDump = &v1.MemoryDumpStatus{
+			ClaimName: "dump-pvc",
+			Phase:     v1.MemoryDumpPending,
+		}
+		mountCommand = func(sourcePath, targetPath string) ([]byte, error) {
+			Fail("the claim should not be mounted")
+			return nil, nil
+		}
+		Expect(m.MountMemoryDump(vmi)).To(Succeed())
+	})
+
+	It("should fail mounting the claim of a memory dump without the marker file", func() {
+		path := filepath.Join(tempDir, "mnbvc", "volumes")
+		err = os.MkdirAll(path, 0755)
+		Expect(err).ToNot(HaveOccurred())
+		sourcePodBasePath = func(podUID types.UID) string {
+			return path
+		}
+		vmi.Status.MemoryDump = &v1.MemoryDumpStatus{
+			ClaimName:    "dump-pvc",
+			Phase:        v1.MemoryDumpPending,
+			TargetPodUID: "mnbvc",
+		}
+		Expect(m.MountMemoryDump(vmi)).To(MatchError(ContainSubstring("Unable to find the memory dump claim path")))
+	})
+
 	It("unmountFileSystemHotplugVolumes should return error if isMounted returns error", func() {
 		testPath := "test"
 		isMounted = func(diskPath string) (bool, error) {
@@ -0,0 +1,182 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package hotplug_volume
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/client-go/log"
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux"
+)
+
+// minRelabelInterval rate limits the label reconciliation of a single VMI
+const minRelabelInterval = 1 * time.Minute
+
+// launcherLabelReadTimeout bounds the label reads of the launcher, so that a
+// stuck proc filesystem fails the reconciliation instead of hanging it
+const launcherLabelReadTimeout = 10 * time.Second
+
+type fileLabeler interface {
+	EnsureFilesLabeled(paths ...string) ([]string, error)
+	EnsureFilesLabeledWithResults(paths ...string) []selinux.FileRelabel
+	VerifyFileLabels(paths ...string) []selinux.LabelMismatch
+}
+
+var (
+	// launcherLabelManager applies the labels of the hotplugged volumes, the
+	// selinux of the host if nil
+	launcherLabelManager selinux.LabelManager
+
+	newLauncherFileLabeler = func(launcherPID int, parallelism int, skipPaths []string) (fileLabeler, error) {
+		return selinux.NewContextExecutor(launcherPID, nil, selinux.WithLabelManager(launcherLabelManager), selinux.WithRelabelParallelism(parallelism), selinux.WithRelabelSkipPaths(skipPaths), selinux.WithLabelReadTimeout(launcherLabelReadTimeout))
+	}
+
+	timeNow = time.Now
+)
+
+func (m *volumeMounter) ReconcileSELinuxLabels(vmi *v1.VirtualMachineInstance) error {
+	if !hasHotplugVolumes(vmi) || !m.shouldReconcileLabels(vmi) {
+		return nil
+	}
+
+	labeler, targetFiles, err := m.newVolumeFileLabeler(vmi)
+	if labeler == nil || err != nil {
+		return err
+	}
+	relabeled, err := labeler.EnsureFilesLabeled(targetFiles...)
+	for _, path := range relabeled {
+		log.Log.Object(vmi).Infof("Restored the selinux label of hotplugged volume %s", path)
+	}
+	return err
+}
+
+func (m *volumeMounter) RelabelSELinuxLabels(vmi *v1.VirtualMachineInstance) ([]selinux.FileRelabel, error) {
+	results := []selinux.FileRelabel{}
+	if !hasHotplugVolumes(vmi) {
+		return results, nil
+	}
+
+	labeler, targetFiles, err := m.newVolumeFileLabeler(vmi)
+	if labeler == nil || err != nil {
+		return results, err
+	}
+	for _, result := range labeler.EnsureFilesLabeledWithResults(targetFiles...) {
+		if result.Relabeled {
+			log.Log.Object(vmi).Infof("Restored the selinux label of hotplugged volume %s on demand", result.Path)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// VerifySELinuxLabels compares the file label of the launcher of the VMI, the
+// container file context with the level of the launcher, with the label of
+// each of its mounted volumes, like the block device nodes of the hotplugged
+// volumes, e.g. after a relabel.
+func (m *volumeMounter) VerifySELinuxLabels(vmi *v1.VirtualMachineInstance) ([]selinux.LabelMismatch, error) {
+	if !hasHotplugVolumes(vmi) {
+		return nil, nil
+	}
+
+	labeler, targetFiles, err := m.newVolumeFileLabeler(vmi)
+	if labeler == nil || err != nil {
+		return nil, err
+	}
+	mismatches := labeler.VerifyFileLabels(targetFiles...)
+	for _, mismatch := range mismatches {
+		log.Log.Object(vmi).Warningf("Hotplugged volume %s", mismatch)
+	}
+	return mismatches, nil
+}
+
+// newVolumeFileLabeler returns the labeler of the launcher of the VMI with the
+// mounted volumes which still exist, or a nil labeler if there is nothing to
+// relabel.
+func (m *volumeMounter) newVolumeFileLabeler(vmi *v1.VirtualMachineInstance) (fileLabeler, []string, error) {
+	record, err := m.getMountTargetRecord(vmi)
+	if err != nil {
+		return nil, nil, err
+	}
+	var targetFiles []string
+	for _, entry := range record.MountTargetEntries {
+		if _, err := os.Stat(entry.TargetFile); err == nil {
+			targetFiles = append(targetFiles, entry.TargetFile)
+		}
+	}
+	if len(targetFiles) == 0 {
+		return nil, nil, nil
+	}
+
+	res, err := m.podIsolationDetector.Detect(vmi)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to detect the launcher pid: %v", err)
+	}
+	labeler, err := newLauncherFileLabeler(res.Pid(), m.getRelabelParallelism(), m.getRelabelSkipPaths())
+	if selinux.IsLabelErrorKind(err, selinux.SELinuxUnavailable) || selinux.IsLabelErrorKind(err, selinux.PIDNotFound) {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+	return labeler, targetFiles, nil
+}
+
+func (m *volumeMounter) getRelabelParallelism() int {
+	if m.relabelParallelism == nil {
+		return 1
+	}
+	return m.relabelParallelism()
+}
+
+func (m *volumeMounter) getRelabelSkipPaths() []string {
+	if m.relabelSkipPaths == nil {
+		return nil
+	}
+	return m.relabelSkipPaths()
+}
+
+// shouldReconcileLabels records the reconciliation attempt of the VMI, unless
+// the previous one happened less than minRelabelInterval ago.
+func (m *volumeMounter) shouldReconcileLabels(vmi *v1.VirtualMachineInstance) bool {
+	m.lastRelabelsLock.Lock()
+	defer m.lastRelabelsLock.Unlock()
+	if m.lastRelabels == nil {
+		m.lastRelabels = make(map[types.UID]time.Time)
+	}
+	now := timeNow()
+	if last, exists := m.lastRelabels[vmi.UID]; exists && now.Sub(last) < minRelabelInterval {
+		return false
+	}
+	m.lastRelabels[vmi.UID] = now
+	return true
+}
+
+func hasHotplugVolumes(vmi *v1.VirtualMachineInstance) bool {
+	for _, volumeStatus := range vmi.Status.VolumeStatus {
+		if volumeStatus.HotplugVolume != nil {
+			return true
+		}
+	}
+	return false
+}
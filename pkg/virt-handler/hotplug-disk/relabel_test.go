@@ -0,0 +1,325 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package hotplug_volume
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux"
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+const (
+	launcherProcessLabel = "system_u:system_r:container_t:s0:c1,c2"
+	// the label the volumes of the launcher with launcherProcessLabel get
+	launcherFileLabel = "system_u:object_r:container_file_t:s0:c1,c2"
+)
+
+// fakeFileLabeler keeps the labels of the files in memory and records every relabel
+type fakeFileLabeler struct {
+	labels    map[string]string
+	relabeled []string
+}
+
+func (l *fakeFileLabeler) EnsureFilesLabeled(paths ...string) ([]string, error) {
+	var relabeled []string
+	for _, path := range paths {
+		if l.labels[path] != launcherFileLabel {
+			l.labels[path] = launcherFileLabel
+			relabeled = append(relabeled, path)
+		}
+	}
+	l.relabeled = append(l.relabeled, relabeled...)
+	return relabeled, nil
+}
+
+func (l *fakeFileLabeler) EnsureFilesLabeledWithResults(paths ...string) []selinux.FileRelabel {
+	var results []selinux.FileRelabel
+	for _, path := range paths {
+		result := selinux.FileRelabel{Path: path, PreviousLabel: l.labels[path], Label: launcherFileLabel}
+		if l.labels[path] != launcherFileLabel {
+			l.labels[path] = launcherFileLabel
+			l.relabeled = append(l.relabeled, path)
+			result.Relabeled = true
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (l *fakeFileLabeler) VerifyFileLabels(paths ...string) []selinux.LabelMismatch {
+	var mismatches []selinux.LabelMismatch
+	for _, path := range paths {
+		if l.labels[path] != launcherFileLabel {
+			mismatches = append(mismatches, selinux.LabelMismatch{Path: path, Label: l.labels[path], LauncherLabel: launcherFileLabel})
+		}
+	}
+	return mismatches
+}
+
+var _ = Describe("HotplugVolume selinux label reconciliation", func() {
+	var (
+		m                         *volumeMounter
+		vmi                       *v1.VirtualMachineInstance
+		labeler                   *fakeFileLabeler
+		targetFile                string
+		now                       time.Time
+		orgNewLauncherFileLabeler = newLauncherFileLabeler
+		orgTimeNow                = timeNow
+	)
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "hotplug-relabel-test")
+		Expect(err).ToNot(HaveOccurred())
+
+		vmi = v1.NewMinimalVMI("fake-vmi")
+		vmi.UID = "1234"
+		vmi.Status.VolumeStatus = []v1.VolumeStatus{
+			{
+				Name:          "hotplug",
+				HotplugVolume: &v1.HotplugVolumeStatus{},
+			},
+		}
+
+		m = &volumeMounter{
+			podIsolationDetector: &mockIsolationDetector{pid: 1},
+			mountRecords:         make(map[types.UID]*vmiMountTargetRecord),
+			mountStateDir:        tempDir,
+		}
+		targetFile = filepath.Join(tempDir, "disk.img")
+		Expect(ioutil.WriteFile(targetFile, []byte{}, 0644)).To(Succeed())
+		Expect(m.setMountTargetRecord(vmi, &vmiMountTargetRecord{
+			MountTargetEntries: []vmiMountTargetEntry{
+				{TargetFile: targetFile},
+				{TargetFile: filepath.Join(tempDir, "gone.img")},
+			},
+		})).To(Succeed())
+
+		labeler = &fakeFileLabeler{labels: map[string]string{targetFile: launcherFileLabel}}
+		newLauncherFileLabeler = func(launcherPID int, parallelism int, skipPaths []string) (fileLabeler, error) {
+			return labeler, nil
+		}
+		now = time.Now()
+		timeNow = func() time.Time {
+			return now
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+		newLauncherFileLabeler = orgNewLauncherFileLabeler
+		timeNow = orgTimeNow
+	})
+
+	It("should relabel a drifted volume exactly once", func() {
+		labeler.labels[targetFile] = "system_u:object_r:tmp_t:s0"
+
+		Expect(m.ReconcileSELinuxLabels(vmi)).To(Succeed())
+		Expect(labeler.relabeled).To(Equal([]string{targetFile}))
+
+		now = now.Add(minRelabelInterval)
+		Expect(m.ReconcileSELinuxLabels(vmi)).To(Succeed())
+		Expect(labeler.relabeled).To(Equal([]string{targetFile}))
+	})
+
+	It("should relabel the volumes with the configured parallelism", func() {
+		var parallelisms []int
+		newLauncherFileLabeler = func(launcherPID int, parallelism int, skipPaths []string) (fileLabeler, error) {
+			parallelisms = append(parallelisms, parallelism)
+			return labeler, nil
+		}
+
+		Expect(m.ReconcileSELinuxLabels(vmi)).To(Succeed())
+		m.relabelParallelism = func() int { return 8 }
+		now = now.Add(minRelabelInterval)
+		Expect(m.ReconcileSELinuxLabels(vmi)).To(Succeed())
+		Expect(parallelisms).To(Equal([]int{1, 8}))
+	})
+
+	It("should not relabel volumes with the expected label", func() {
+		Expect(m.ReconcileSELinuxLabels(vmi)).To(Succeed())
+		Expect(labeler.relabeled).To(BeEmpty())
+	})
+
+	It("should not reconcile the same vmi more often than the minimal interval", func() {
+		Expect(m.ReconcileSELinuxLabels(vmi)).To(Succeed())
+		labeler.labels[targetFile] = "system_u:object_r:tmp_t:s0"
+
+		now = now.Add(minRelabelInterval / 2)
+		Expect(m.ReconcileSELinuxLabels(vmi)).To(Succeed())
+		Expect(labeler.relabeled).To(BeEmpty())
+
+		now = now.Add(minRelabelInterval / 2)
+		Expect(m.ReconcileSELinuxLabels(vmi)).To(Succeed())
+		Expect(labeler.relabeled).To(Equal([]string{targetFile}))
+	})
+
+	It("should skip vmis without hotplugged volumes", func() {
+		vmi.Status.VolumeStatus = nil
+		newLauncherFileLabeler = func(launcherPID int, parallelism int, skipPaths []string) (fileLabeler, error) {
+			Fail("the launcher labels should not be looked up")
+			return nil, nil
+		}
+		Expect(m.ReconcileSELinuxLabels(vmi)).To(Succeed())
+	})
+
+	It("should restore drifted labels through the launcher selinux context", func() {
+		manager := testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(1, launcherProcessLabel)
+		manager.SetProcessLabel(os.Getpid(), "system_u:system_r:spc_t:s0")
+		manager.SetFileLabel(targetFile, "system_u:object_r:tmp_t:s0")
+		launcherLabelManager = manager
+		defer func() {
+			launcherLabelManager = nil
+		}()
+		newLauncherFileLabeler = orgNewLauncherFileLabeler
+
+		Expect(m.ReconcileSELinuxLabels(vmi)).To(Succeed())
+		Expect(manager.FileLabel(targetFile)).To(Equal(launcherFileLabel))
+	})
+
+	It("should leave the volumes carrying the file label of the launcher untouched", func() {
+		manager := testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(1, launcherProcessLabel)
+		manager.SetProcessLabel(os.Getpid(), "system_u:system_r:spc_t:s0")
+		manager.SetFileLabel(targetFile, launcherFileLabel)
+		launcherLabelManager = manager
+		defer func() {
+			launcherLabelManager = nil
+		}()
+		newLauncherFileLabeler = orgNewLauncherFileLabeler
+
+		Expect(m.VerifySELinuxLabels(vmi)).To(BeEmpty())
+		results, err := m.RelabelSELinuxLabels(vmi)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(Equal([]selinux.FileRelabel{
+			{Path: targetFile, PreviousLabel: launcherFileLabel, Label: launcherFileLabel},
+		}))
+		Expect(manager.FileLabel(targetFile)).To(Equal(launcherFileLabel))
+	})
+
+	It("should not restore the labels of volumes matching the skip paths", func() {
+		manager := testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(1, launcherProcessLabel)
+		manager.SetProcessLabel(os.Getpid(), "system_u:system_r:spc_t:s0")
+		manager.SetFileLabel(targetFile, "system_u:object_r:tmp_t:s0")
+		launcherLabelManager = manager
+		defer func() {
+			launcherLabelManager = nil
+		}()
+		newLauncherFileLabeler = orgNewLauncherFileLabeler
+		m.relabelSkipPaths = func() []string { return []string{filepath.Dir(targetFile)} }
+
+		Expect(m.ReconcileSELinuxLabels(vmi)).To(Succeed())
+		Expect(manager.FileLabel(targetFile)).To(Equal("system_u:object_r:tmp_t:s0"))
+	})
+
+	It("should do nothing when the launcher is gone", func() {
+		launcherLabelManager = testutils.NewFakeLabelManager()
+		defer func() {
+			launcherLabelManager = nil
+		}()
+		newLauncherFileLabeler = orgNewLauncherFileLabeler
+
+		Expect(m.ReconcileSELinuxLabels(vmi)).To(Succeed())
+	})
+
+	It("should relabel on demand regardless of the last reconciliation", func() {
+		Expect(m.ReconcileSELinuxLabels(vmi)).To(Succeed())
+		labeler.labels[targetFile] = "system_u:object_r:tmp_t:s0"
+
+		results, err := m.RelabelSELinuxLabels(vmi)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(Equal([]selinux.FileRelabel{
+			{Path: targetFile, PreviousLabel: "system_u:object_r:tmp_t:s0", Label: launcherFileLabel, Relabeled: true},
+		}))
+		Expect(labeler.relabeled).To(Equal([]string{targetFile}))
+	})
+
+	It("should report no path on demand for vmis without hotplugged volumes", func() {
+		vmi.Status.VolumeStatus = nil
+		results, err := m.RelabelSELinuxLabels(vmi)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(BeEmpty())
+	})
+
+	It("should not report the volumes carrying the file label of the launcher", func() {
+		mismatches, err := m.VerifySELinuxLabels(vmi)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mismatches).To(BeEmpty())
+	})
+
+	It("should report the volumes not carrying the file label of the launcher without relabeling them", func() {
+		labeler.labels[targetFile] = "system_u:object_r:container_file_t:s0:c3,c4"
+
+		mismatches, err := m.VerifySELinuxLabels(vmi)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mismatches).To(Equal([]selinux.LabelMismatch{
+			{Path: targetFile, Label: "system_u:object_r:container_file_t:s0:c3,c4", LauncherLabel: launcherFileLabel},
+		}))
+		Expect(labeler.relabeled).To(BeEmpty())
+	})
+
+	It("should compare the volumes with the label of the launcher pid", func() {
+		manager := testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(1, launcherProcessLabel)
+		manager.SetProcessLabel(os.Getpid(), "system_u:system_r:spc_t:s0")
+		manager.SetFileLabel(targetFile, "system_u:object_r:container_file_t:s0:c3,c4")
+		launcherLabelManager = manager
+		defer func() {
+			launcherLabelManager = nil
+		}()
+		newLauncherFileLabeler = orgNewLauncherFileLabeler
+
+		mismatches, err := m.VerifySELinuxLabels(vmi)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mismatches).To(HaveLen(1))
+		Expect(mismatches[0].Difference).To(Equal("level s0:c1,c2 -> s0:c3,c4"))
+
+		Expect(m.ReconcileSELinuxLabels(vmi)).To(Succeed())
+		Expect(m.VerifySELinuxLabels(vmi)).To(BeEmpty())
+	})
+
+	It("should not verify vmis without hotplugged volumes", func() {
+		vmi.Status.VolumeStatus = nil
+		newLauncherFileLabeler = func(launcherPID int, parallelism int, skipPaths []string) (fileLabeler, error) {
+			Fail("the launcher labels should not be looked up")
+			return nil, nil
+		}
+		Expect(m.VerifySELinuxLabels(vmi)).To(BeEmpty())
+	})
+
+	It("should do nothing when selinux is not available", func() {
+		newLauncherFileLabeler = func(launcherPID int, parallelism int, skipPaths []string) (fileLabeler, error) {
+			return nil, &selinux.LabelError{PID: launcherPID, Kind: selinux.SELinuxUnavailable}
+		}
+		Expect(m.ReconcileSELinuxLabels(vmi)).To(Succeed())
+	})
+})
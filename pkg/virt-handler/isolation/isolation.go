@@ -33,6 +33,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -141,10 +142,25 @@ func prLimit(pid int, limit uintptr, rlimit *unix.Rlimit) error {
 	return nil
 }
 
+func getPrLimit(pid int, limit uintptr) (*unix.Rlimit, error) {
+	rlimit := &unix.Rlimit{}
+	_, _, errno := unix.RawSyscall6(unix.SYS_PRLIMIT64,
+		uintptr(pid),
+		limit,
+		0,
+		uintptr(unsafe.Pointer(rlimit)), // #nosec used in unix RawSyscall6
+		0, 0)
+	if errno != 0 {
+		return nil, fmt.Errorf("Error getting prlimit: %v", errno)
+	}
+	return rlimit, nil
+}
+
 func (s *socketBasedIsolationDetector) AdjustResources(vm *v1.VirtualMachineInstance) error {
-	// only VFIO attached domains require MEMLOCK adjustment
-	if !util.IsVFIOVMI(vm) {
-		return nil
+	// only VFIO attached domains and domains with locked memory require MEMLOCK adjustment
+	memlockSize, needed, err := getRequiredMemlockSize(vm)
+	if err != nil || !needed {
+		return err
 	}
 
 	// bump memlock ulimit for libvirtd
@@ -170,25 +186,67 @@ func (s *socketBasedIsolationDetector) AdjustResources(vm *v1.VirtualMachineInst
 			continue
 		}
 
-		// make the best estimate for memory required by libvirt
-		memlockSize, err := getMemlockSize(vm)
-		if err != nil {
-			return err
-		}
 		rLimit := unix.Rlimit{
-			Max: uint64(memlockSize),
-			Cur: uint64(memlockSize),
+			Max: memlockSize,
+			Cur: memlockSize,
 		}
 		err = prLimit(process.Pid(), unix.RLIMIT_MEMLOCK, &rLimit)
 		if err != nil {
 			return fmt.Errorf("failed to set rlimit for memory lock: %v", err)
 		}
-		// we assume a single process should match
-		break
+		if !vmiHasLockedMemory(vm) {
+			// we assume a single process should match
+			break
+		}
+
+		// qemu can't start with locked memory unless it inherits an unlimited memory lock limit
+		currentLimit, err := getPrLimit(process.Pid(), unix.RLIMIT_MEMLOCK)
+		if err != nil {
+			return fmt.Errorf("failed to read rlimit for memory lock: %v", err)
+		}
+		return checkMemlockLimit(currentLimit, memlockSize)
 	}
 	return nil
 }
 
+func vmiHasLockedMemory(vm *v1.VirtualMachineInstance) bool {
+	return vm.Spec.Domain.Memory != nil && vm.Spec.Domain.Memory.Locked
+}
+
+// getRequiredMemlockSize returns the memory lock limit libvirt needs for the
+// domain of vm, if it needs one. libvirt requires an unlimited one to lock the
+// guest memory, since it has no better estimate of the memory qemu locks.
+func getRequiredMemlockSize(vm *v1.VirtualMachineInstance) (uint64, bool, error) {
+	if vmiHasLockedMemory(vm) {
+		return unix.RLIM_INFINITY, true, nil
+	}
+	if !util.IsVFIOVMI(vm) {
+		return 0, false, nil
+	}
+	// make the best estimate for memory required by libvirt
+	memlockSize, err := getMemlockSize(vm)
+	if err != nil {
+		return 0, false, err
+	}
+	return uint64(memlockSize), true, nil
+}
+
+// checkMemlockLimit rejects a memory lock limit below the required size,
+// which qemu would fail to start with.
+func checkMemlockLimit(limit *unix.Rlimit, required uint64) error {
+	if limit.Cur >= required && limit.Max >= required {
+		return nil
+	}
+	return fmt.Errorf("the memory lock limit of libvirtd is %s, locking the guest memory requires %s", formatRlimit(limit.Cur), formatRlimit(required))
+}
+
+func formatRlimit(value uint64) string {
+	if value == unix.RLIM_INFINITY {
+		return "unlimited"
+	}
+	return strconv.FormatUint(value, 10)
+}
+
 // consider reusing getMemoryOverhead()
 // This is not scientific, but neither what libvirtd does is. See details in:
 // https://www.redhat.com/archives/libvirt-users/2019-August/msg00051.html
@@ -9,6 +9,7 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"golang.org/x/sys/unix"
 
 	"k8s.io/apimachinery/pkg/types"
 
@@ -169,3 +170,52 @@ var _ = Describe("getMemlockSize", func() {
 		Expect(int(bytes_)).To(Equal(1264389000))
 	})
 })
+
+var _ = Describe("Memory lock limit", func() {
+
+	It("should not be required by default", func() {
+		_, needed, err := getRequiredMemlockSize(v1.NewMinimalVMIWithNS("default", "testvm"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(needed).To(BeFalse())
+	})
+
+	It("should be estimated for VFIO devices", func() {
+		vm := v1.NewMinimalVMIWithNS("default", "testvm")
+		vm.Spec.Domain.Devices.GPUs = []v1.GPU{{Name: "gpu1", DeviceName: "vendor.com/gpu"}}
+		size, needed, err := getRequiredMemlockSize(vm)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(needed).To(BeTrue())
+		Expect(size).To(Equal(uint64(1264389000)))
+	})
+
+	It("should be unlimited for locked memory", func() {
+		vm := v1.NewMinimalVMIWithNS("default", "testvm")
+		vm.Spec.Domain.Memory = &v1.Memory{Locked: true}
+		size, needed, err := getRequiredMemlockSize(vm)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(needed).To(BeTrue())
+		Expect(size).To(Equal(uint64(unix.RLIM_INFINITY)))
+	})
+
+	It("should accept a sufficient limit", func() {
+		Expect(checkMemlockLimit(&unix.Rlimit{Cur: unix.RLIM_INFINITY, Max: unix.RLIM_INFINITY}, unix.RLIM_INFINITY)).To(Succeed())
+		Expect(checkMemlockLimit(&unix.Rlimit{Cur: 2048, Max: 4096}, 1024)).To(Succeed())
+	})
+
+	It("should reject an insufficient limit", func() {
+		err := checkMemlockLimit(&unix.Rlimit{Cur: 65536, Max: unix.RLIM_INFINITY}, unix.RLIM_INFINITY)
+		Expect(err).To(MatchError("the memory lock limit of libvirtd is 65536, locking the guest memory requires unlimited"))
+	})
+
+	It("should reject an insufficient hard limit", func() {
+		Expect(checkMemlockLimit(&unix.Rlimit{Cur: 1024, Max: 512}, 1024)).ToNot(Succeed())
+	})
+
+	It("should read the limit of a process", func() {
+		expected := &unix.Rlimit{}
+		Expect(unix.Getrlimit(unix.RLIMIT_MEMLOCK, expected)).To(Succeed())
+		limit, err := getPrLimit(os.Getpid(), unix.RLIMIT_MEMLOCK)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(limit).To(Equal(expected))
+	})
+})
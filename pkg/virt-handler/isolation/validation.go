@@ -6,6 +6,7 @@ import (
 	"os/exec"
 
 	containerdisk "kubevirt.io/kubevirt/pkg/container-disk"
+	"kubevirt.io/kubevirt/pkg/util/fdhygiene"
 )
 
 const (
@@ -13,6 +14,7 @@ const (
 )
 
 func GetImageInfo(imagePath string, context IsolationResult) (*containerdisk.DiskInfo, error) {
+	fdhygiene.PreventLeakOntoChild()
 	// #nosec g204 no risk to use MountNamespace()  argument as it returns a fixed string of "/proc/<pid>/ns/mnt"
 	out, err := exec.Command(
 		"/usr/bin/virt-chroot", "--user", "qemu", "--memory", "1000", "--cpu", "10", "--mount", context.MountNamespace(), "exec", "--",
@@ -0,0 +1,74 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package virthandler
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"kubevirt.io/client-go/log"
+)
+
+// bootIDPath holds the random id the kernel generates on every boot
+var bootIDPath = "/proc/sys/kernel/random/boot_id"
+
+// DetectNodeReboots makes the controller reconcile the selinux labels of all
+// its VMIs on start, if the node booted since the boot id was persisted to
+// stateFile. The labels of the devices may have been reset by the reboot.
+func (c *VirtualMachineController) DetectNodeReboots(stateFile string) {
+	c.bootIDStateFile = stateFile
+}
+
+// reconcileSELinuxLabelsAfterReboot relabels the VMIs of the node unless the
+// persisted boot id is the current one. The boot id is only persisted once
+// all VMIs were relabeled, so that a failed pass is retried on the next start.
+func (c *VirtualMachineController) reconcileSELinuxLabelsAfterReboot() {
+	bootID, err := readBootID(bootIDPath)
+	if err != nil {
+		log.Log.Reason(err).Warning("failed to read the boot id of the node, not detecting reboots")
+		return
+	}
+	lastBootID, err := readBootID(c.bootIDStateFile)
+	if err == nil && lastBootID == bootID {
+		return
+	} else if err != nil && !os.IsNotExist(err) {
+		log.Log.Reason(err).Warningf("failed to read the last boot id of the node from %s", c.bootIDStateFile)
+	}
+
+	if c.isSELinuxEnabled != nil && c.isSELinuxEnabled() {
+		log.Log.Infof("The node booted since boot id %q, reconciling the selinux labels of all VMIs", lastBootID)
+		if failures := c.reconcileHotplugVolumeLabels(); failures > 0 {
+			log.Log.Warningf("failed to reconcile the selinux labels of %d VMIs after the reboot of the node", failures)
+			return
+		}
+	}
+	if err := ioutil.WriteFile(c.bootIDStateFile, []byte(bootID), 0644); err != nil {
+		log.Log.Reason(err).Errorf("failed to persist the boot id of the node to %s", c.bootIDStateFile)
+	}
+}
+
+func readBootID(path string) (string, error) {
+	bootID, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(bootID)), nil
+}
@@ -0,0 +1,210 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/emicklei/go-restful"
+
+	"k8s.io/client-go/tools/cache"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/client-go/log"
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux"
+)
+
+// Relabeler re-applies the launcher selinux label on the files of a VMI, or
+// verifies that they carry it. The returned error covers the failures before
+// any file was relabeled or verified, the ones of the single files are part
+// of their result.
+type Relabeler interface {
+	RelabelSELinuxLabels(vmi *v1.VirtualMachineInstance) ([]selinux.FileRelabel, error)
+	VerifySELinuxLabels(vmi *v1.VirtualMachineInstance) ([]selinux.LabelMismatch, error)
+}
+
+// RelabelResult lists the outcome of an on-demand relabel for each file.
+type RelabelResult struct {
+	Files []selinux.FileRelabel `json:"files"`
+}
+
+// LabelVerificationResult lists the files not carrying the launcher label.
+type LabelVerificationResult struct {
+	Mismatches []selinux.LabelMismatch `json:"mismatches"`
+}
+
+type peerCredentialsKey struct{}
+
+type RelabelHandler struct {
+	relabeler   Relabeler
+	vmiInformer cache.SharedIndexInformer
+	host        string
+	// allowedUID is the only uid allowed to call the handler
+	allowedUID uint32
+}
+
+// NewRelabelHandler returns a handler relabeling and verifying the labels of
+// the VMIs running on host, for callers running as root on the node.
+func NewRelabelHandler(relabeler Relabeler, vmiInformer cache.SharedIndexInformer, host string) *RelabelHandler {
+	return &RelabelHandler{
+		relabeler:   relabeler,
+		vmiInformer: vmiInformer,
+		host:        host,
+	}
+}
+
+// RelabelHandler relabels the files of the VMI on demand, without waiting for
+// the periodic reconciliation.
+func (h *RelabelHandler) RelabelHandler(request *restful.Request, response *restful.Response) {
+	vmi := h.getLocalVMI(request, response)
+	if vmi == nil {
+		return
+	}
+
+	files, err := h.relabeler.RelabelSELinuxLabels(vmi)
+	if err != nil {
+		log.Log.Object(vmi).Reason(err).Error("Failed to relabel the VMI on demand")
+		response.WriteError(http.StatusInternalServerError, err)
+		return
+	}
+	response.WriteEntity(RelabelResult{Files: files})
+}
+
+// VerifyHandler verifies on demand that the files of the VMI carry the label
+// of its launcher, without relabeling them.
+func (h *RelabelHandler) VerifyHandler(request *restful.Request, response *restful.Response) {
+	vmi := h.getLocalVMI(request, response)
+	if vmi == nil {
+		return
+	}
+
+	mismatches, err := h.relabeler.VerifySELinuxLabels(vmi)
+	if err != nil {
+		log.Log.Object(vmi).Reason(err).Error("Failed to verify the selinux labels of the VMI on demand")
+		response.WriteError(http.StatusInternalServerError, err)
+		return
+	}
+	if mismatches == nil {
+		mismatches = []selinux.LabelMismatch{}
+	}
+	response.WriteEntity(LabelVerificationResult{Mismatches: mismatches})
+}
+
+// getLocalVMI returns the VMI of the request if it runs on the host, or
+// writes the error response and returns nil.
+func (h *RelabelHandler) getLocalVMI(request *restful.Request, response *restful.Response) *v1.VirtualMachineInstance {
+	vmi, code, err := getVMI(request, h.vmiInformer)
+	if err != nil {
+		log.Log.Reason(err).Error("Failed to retrieve VMI")
+		response.WriteError(code, err)
+		return nil
+	}
+	if vmi.Status.NodeName != h.host {
+		response.WriteError(http.StatusForbidden, fmt.Errorf("VMI %s/%s does not run on node %s", vmi.Namespace, vmi.Name, h.host))
+		return nil
+	}
+	return vmi
+}
+
+// authorizePeer rejects the requests of callers not running with the
+// allowed uid, as reported by the peer credentials of the unix socket.
+func (h *RelabelHandler) authorizePeer(request *restful.Request, response *restful.Response, chain *restful.FilterChain) {
+	creds, ok := request.Request.Context().Value(peerCredentialsKey{}).(*syscall.Ucred)
+	if !ok {
+		response.WriteErrorString(http.StatusUnauthorized, "the peer credentials of the caller are unknown")
+		return
+	}
+	if creds.Uid != h.allowedUID {
+		log.Log.Warningf("Rejected the relabel request of pid %d running with uid %d", creds.Pid, creds.Uid)
+		response.WriteErrorString(http.StatusForbidden, fmt.Sprintf("uid %d is not allowed to relabel VMIs", creds.Uid))
+		return
+	}
+	chain.ProcessFilter(request, response)
+}
+
+// NewRelabelServer returns a server exposing handler, to be served on a unix
+// socket so that the callers can be identified by their peer credentials.
+func NewRelabelServer(handler *RelabelHandler) *http.Server {
+	ws := new(restful.WebService)
+	ws.Filter(handler.authorizePeer)
+	ws.Route(ws.PUT("/v1/namespaces/{namespace}/virtualmachineinstances/{name}/relabel").To(handler.RelabelHandler).Produces(restful.MIME_JSON).Returns(http.StatusOK, "OK", RelabelResult{}))
+	ws.Route(ws.GET("/v1/namespaces/{namespace}/virtualmachineinstances/{name}/selinuxlabels").To(handler.VerifyHandler).Produces(restful.MIME_JSON).Returns(http.StatusOK, "OK", LabelVerificationResult{}))
+	container := restful.NewContainer()
+	container.Add(ws)
+	return &http.Server{
+		Handler:     container,
+		ConnContext: withPeerCredentials,
+	}
+}
+
+// withPeerCredentials adds the credentials of the process on the other end of
+// the unix socket to the context of its requests.
+func withPeerCredentials(ctx context.Context, conn net.Conn) context.Context {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return ctx
+	}
+	rawConn, err := unixConn.SyscallConn()
+	if err != nil {
+		return ctx
+	}
+	var creds *syscall.Ucred
+	controlErr := rawConn.Control(func(fd uintptr) {
+		creds, err = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if controlErr != nil || err != nil {
+		log.Log.Reason(err).Warning("failed to read the peer credentials of a relabel request")
+		return ctx
+	}
+	return context.WithValue(ctx, peerCredentialsKey{}, creds)
+}
+
+// ListenRelabelSocket creates the unix socket at socketPath, only accessible
+// by root, replacing the one left by a previous virt-handler. The directory of
+// socketPath is dedicated to the socket: it is made only accessible by root
+// before listening, the socket being created with the umask of the process.
+func ListenRelabelSocket(socketPath string) (net.Listener, error) {
+	socketDir := filepath.Dir(socketPath)
+	if err := os.MkdirAll(socketDir, 0700); err != nil {
+		return nil, err
+	}
+	// the directory may be left by a previous virt-handler
+	if err := os.Chmod(socketDir, 0700); err != nil {
+		return nil, err
+	}
+	if err := os.RemoveAll(socketPath); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
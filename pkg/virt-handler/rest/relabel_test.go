@@ -0,0 +1,249 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/tools/cache"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/kubevirt/pkg/testutils"
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux"
+)
+
+// fakeRelabeler records the relabeled and verified VMIs and returns the configured result
+type fakeRelabeler struct {
+	relabeled  []string
+	verified   []string
+	files      []selinux.FileRelabel
+	mismatches []selinux.LabelMismatch
+	err        error
+}
+
+func (r *fakeRelabeler) RelabelSELinuxLabels(vmi *v1.VirtualMachineInstance) ([]selinux.FileRelabel, error) {
+	r.relabeled = append(r.relabeled, vmi.Name)
+	return r.files, r.err
+}
+
+func (r *fakeRelabeler) VerifySELinuxLabels(vmi *v1.VirtualMachineInstance) ([]selinux.LabelMismatch, error) {
+	r.verified = append(r.verified, vmi.Name)
+	return r.mismatches, r.err
+}
+
+var _ = Describe("On-demand relabel", func() {
+	const host = "node01"
+
+	var (
+		tempDir     string
+		relabeler   *fakeRelabeler
+		vmiInformer cache.SharedIndexInformer
+		handler     *RelabelHandler
+		server      *http.Server
+		client      *http.Client
+	)
+
+	addVMI := func(name string, nodeName string) {
+		vmi := v1.NewMinimalVMI(name)
+		vmi.Namespace = "default"
+		vmi.Status.NodeName = nodeName
+		Expect(vmiInformer.GetStore().Add(vmi)).To(Succeed())
+	}
+
+	relabel := func(name string) (int, []byte) {
+		request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("http://relabel/v1/namespaces/default/virtualmachineinstances/%s/relabel", name), nil)
+		Expect(err).ToNot(HaveOccurred())
+		response, err := client.Do(request)
+		Expect(err).ToNot(HaveOccurred())
+		defer response.Body.Close()
+		body, err := ioutil.ReadAll(response.Body)
+		Expect(err).ToNot(HaveOccurred())
+		return response.StatusCode, body
+	}
+
+	verify := func(name string) (int, []byte) {
+		response, err := client.Get(fmt.Sprintf("http://relabel/v1/namespaces/default/virtualmachineinstances/%s/selinuxlabels", name))
+		Expect(err).ToNot(HaveOccurred())
+		defer response.Body.Close()
+		body, err := ioutil.ReadAll(response.Body)
+		Expect(err).ToNot(HaveOccurred())
+		return response.StatusCode, body
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "relabel-socket")
+		Expect(err).ToNot(HaveOccurred())
+
+		relabeler = &fakeRelabeler{}
+		vmiInformer, _ = testutils.NewFakeInformerFor(&v1.VirtualMachineInstance{})
+		handler = NewRelabelHandler(relabeler, vmiInformer, host)
+		handler.allowedUID = uint32(os.Getuid())
+
+		socketPath := filepath.Join(tempDir, "relabel", "relabel.sock")
+		listener, err := ListenRelabelSocket(socketPath)
+		Expect(err).ToNot(HaveOccurred())
+		server = NewRelabelServer(handler)
+		go server.Serve(listener)
+
+		client = &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+			},
+		}}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(tempDir)
+	})
+
+	It("should only make the socket accessible by its owner", func() {
+		info, err := os.Stat(filepath.Join(tempDir, "relabel"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0700)))
+		info, err = os.Stat(filepath.Join(tempDir, "relabel", "relabel.sock"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("should restrict the access to a socket directory left by a previous run", func() {
+		socketPath := filepath.Join(tempDir, "previous", "relabel.sock")
+		Expect(os.Mkdir(filepath.Dir(socketPath), 0755)).To(Succeed())
+		listener, err := ListenRelabelSocket(socketPath)
+		Expect(err).ToNot(HaveOccurred())
+		defer listener.Close()
+		info, err := os.Stat(filepath.Dir(socketPath))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0700)))
+	})
+
+	It("should return the result of every file", func() {
+		addVMI("testvmi", host)
+		relabeler.files = []selinux.FileRelabel{
+			{Path: "/dev/vfio/1", PreviousLabel: "system_u:object_r:tmp_t:s0", Label: "system_u:object_r:container_file_t:s0:c1,c2", Relabeled: true},
+			{Path: "/dev/vfio/2", Error: "failed to relabel /dev/vfio/2"},
+		}
+
+		code, body := relabel("testvmi")
+		Expect(code).To(Equal(http.StatusOK))
+		result := RelabelResult{}
+		Expect(json.Unmarshal(body, &result)).To(Succeed())
+		Expect(result.Files).To(Equal(relabeler.files))
+		Expect(relabeler.relabeled).To(Equal([]string{"testvmi"}))
+	})
+
+	It("should refuse VMIs of other nodes", func() {
+		addVMI("testvmi", "node02")
+
+		code, _ := relabel("testvmi")
+		Expect(code).To(Equal(http.StatusForbidden))
+		Expect(relabeler.relabeled).To(BeEmpty())
+	})
+
+	It("should fail for unknown VMIs", func() {
+		code, _ := relabel("testvmi")
+		Expect(code).To(Equal(http.StatusNotFound))
+		Expect(relabeler.relabeled).To(BeEmpty())
+	})
+
+	It("should fail if the VMI could not be relabeled", func() {
+		addVMI("testvmi", host)
+		relabeler.err = fmt.Errorf("failed to detect the launcher pid")
+
+		code, body := relabel("testvmi")
+		Expect(code).To(Equal(http.StatusInternalServerError))
+		Expect(string(body)).To(ContainSubstring("failed to detect the launcher pid"))
+	})
+
+	It("should refuse callers running with another uid", func() {
+		addVMI("testvmi", host)
+		handler.allowedUID = uint32(os.Getuid()) + 1
+
+		code, body := relabel("testvmi")
+		Expect(code).To(Equal(http.StatusForbidden))
+		Expect(string(body)).To(ContainSubstring(fmt.Sprintf("uid %d is not allowed", os.Getuid())))
+		Expect(relabeler.relabeled).To(BeEmpty())
+	})
+
+	It("should return the files not carrying the launcher label", func() {
+		addVMI("testvmi", host)
+		relabeler.mismatches = []selinux.LabelMismatch{{
+			Path:          "/var/run/kubevirt/hotplug-disks/disk0",
+			Label:         "system_u:object_r:container_file_t:s0:c3,c4",
+			LauncherLabel: "system_u:object_r:container_file_t:s0:c1,c2",
+			Difference:    "level s0:c1,c2 -> s0:c3,c4",
+		}}
+
+		code, body := verify("testvmi")
+		Expect(code).To(Equal(http.StatusOK))
+		result := LabelVerificationResult{}
+		Expect(json.Unmarshal(body, &result)).To(Succeed())
+		Expect(result.Mismatches).To(Equal(relabeler.mismatches))
+		Expect(relabeler.verified).To(Equal([]string{"testvmi"}))
+		Expect(relabeler.relabeled).To(BeEmpty())
+	})
+
+	It("should return no mismatch if all labels match", func() {
+		addVMI("testvmi", host)
+
+		code, body := verify("testvmi")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(string(body)).To(MatchJSON(`{"mismatches": []}`))
+	})
+
+	It("should refuse to verify VMIs of other nodes", func() {
+		addVMI("testvmi", "node02")
+
+		code, _ := verify("testvmi")
+		Expect(code).To(Equal(http.StatusForbidden))
+		Expect(relabeler.verified).To(BeEmpty())
+	})
+
+	It("should fail if the labels of the VMI could not be verified", func() {
+		addVMI("testvmi", host)
+		relabeler.err = fmt.Errorf("failed to detect the launcher pid")
+
+		code, body := verify("testvmi")
+		Expect(code).To(Equal(http.StatusInternalServerError))
+		Expect(string(body)).To(ContainSubstring("failed to detect the launcher pid"))
+	})
+
+	It("should refuse callers without peer credentials", func() {
+		addVMI("testvmi", host)
+		request := httptest.NewRequest(http.MethodPut, "/v1/namespaces/default/virtualmachineinstances/testvmi/relabel", nil)
+		recorder := httptest.NewRecorder()
+
+		NewRelabelServer(handler).Handler.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		Expect(relabeler.relabeled).To(BeEmpty())
+	})
+})
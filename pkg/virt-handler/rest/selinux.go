@@ -0,0 +1,64 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package rest
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+
+	"k8s.io/client-go/tools/cache"
+
+	"kubevirt.io/client-go/log"
+	"kubevirt.io/kubevirt/pkg/virt-handler/isolation"
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux"
+)
+
+type SELinuxHandler struct {
+	podIsolationDetector isolation.PodIsolationDetector
+	vmiInformer          cache.SharedIndexInformer
+}
+
+func NewSELinuxHandler(podIsolationDetector isolation.PodIsolationDetector, vmiInformer cache.SharedIndexInformer) *SELinuxHandler {
+	return &SELinuxHandler{
+		podIsolationDetector: podIsolationDetector,
+		vmiInformer:          vmiInformer,
+	}
+}
+
+// LabelsHandler returns the effective selinux labels of virt-handler, of the
+// launcher of the VMI and of its devices.
+func (h *SELinuxHandler) LabelsHandler(request *restful.Request, response *restful.Response) {
+	vmi, code, err := getVMI(request, h.vmiInformer)
+	if err != nil {
+		log.Log.Object(vmi).Reason(err).Error("Failed to retrieve VMI")
+		response.WriteError(code, err)
+		return
+	}
+
+	result, err := h.podIsolationDetector.Detect(vmi)
+	if err != nil {
+		log.Log.Object(vmi).Reason(err).Error("Failed to detect the launcher of the VMI")
+		response.WriteError(http.StatusInternalServerError, err)
+		return
+	}
+
+	response.WriteEntity(selinux.NewLabelReport(result.Pid(), result.MountRoot(), nil))
+}
@@ -0,0 +1,114 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// shellMetacharacters are the characters a shell gives a meaning to, besides
+// whitespace.
+const shellMetacharacters = "|&;<>()$`\\\"'*?[]#~=%!{}"
+
+// WithArgvValidation makes the creation of the executor, and ExecuteMany,
+// fail with an *UnsafeArgumentError if an argument of a command contains a
+// NUL byte or a control character, see ValidateArgv. It is meant for the
+// commands built from strings taken from the VMI, e.g. volume names or paths.
+func WithArgvValidation() Option {
+	return func(ce *ContextExecutor) {
+		ce.validateArgv = true
+	}
+}
+
+// ValidateArgv checks the arguments of a command before it is executed. The
+// arguments are passed to execve as they are, without a shell, so only NUL
+// bytes, which would truncate them, and control characters, e.g. newlines
+// which could forge log or config lines, are rejected. Arguments interpolated
+// into a shell script have to be checked with ValidateShellWord as well.
+func ValidateArgv(argv []string) error {
+	if len(argv) == 0 {
+		return fmt.Errorf("refusing to execute an empty command")
+	}
+	for i, arg := range argv {
+		if err := validateArgChars(arg); err != nil {
+			return fmt.Errorf("argv[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ValidateShellWord checks that word can be interpolated into a shell script
+// without quoting, i.e. that it contains neither whitespace nor any character
+// a shell gives a meaning to.
+func ValidateShellWord(word string) error {
+	if err := validateArgChars(word); err != nil {
+		return err
+	}
+	if word == "" {
+		return &UnsafeArgumentError{Arg: word, Reason: "empty shell word"}
+	}
+	if i := strings.IndexAny(word, shellMetacharacters+" "); i >= 0 {
+		return &UnsafeArgumentError{Arg: word, Reason: fmt.Sprintf("shell metacharacter %q", word[i])}
+	}
+	return nil
+}
+
+// ValidatePathArg checks that path can be passed as a path argument: besides
+// the checks of ValidateArgv, it has to be absolute and clean, so that it
+// neither escapes its parent with .. nor is taken for an option.
+func ValidatePathArg(path string) error {
+	if err := validateArgChars(path); err != nil {
+		return err
+	}
+	if !filepath.IsAbs(path) {
+		return &UnsafeArgumentError{Arg: path, Reason: "path is not absolute"}
+	}
+	if filepath.Clean(path) != path {
+		return &UnsafeArgumentError{Arg: path, Reason: fmt.Sprintf("path is not clean, expected %q", filepath.Clean(path))}
+	}
+	return nil
+}
+
+func validateArgChars(arg string) error {
+	for i := 0; i < len(arg); i++ {
+		switch c := arg[i]; {
+		case c == 0:
+			return &UnsafeArgumentError{Arg: arg, Reason: fmt.Sprintf("NUL byte at offset %d", i)}
+		case c < 0x20 || c == 0x7f:
+			return &UnsafeArgumentError{Arg: arg, Reason: fmt.Sprintf("control character %#x at offset %d", c, i)}
+		}
+	}
+	return nil
+}
+
+func validateCommandArgv(cmds ...*exec.Cmd) error {
+	for _, cmd := range cmds {
+		if cmd == nil {
+			continue
+		}
+		if err := ValidateArgv(cmd.Args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
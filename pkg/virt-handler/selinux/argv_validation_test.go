@@ -0,0 +1,157 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+var _ = Describe("Argv validation", func() {
+
+	expectUnsafe := func(err error, reason string) {
+		var unsafeErr *UnsafeArgumentError
+		ExpectWithOffset(1, errors.As(err, &unsafeErr)).To(BeTrue(), "unexpected error: %v", err)
+		ExpectWithOffset(1, unsafeErr.Reason).To(ContainSubstring(reason))
+	}
+
+	table.DescribeTable("should accept the argv", func(argv ...string) {
+		Expect(ValidateArgv(argv)).To(Succeed())
+	},
+		table.Entry("of a plain command", "virt-chroot", "--mount", "/proc/1/ns/mnt", "mount", "-o", "ro", "/dev/vda", "/mnt"),
+		table.Entry("with spaces and shell metacharacters", "sh", "-c", "echo $HOME; ls *"),
+		table.Entry("with an empty argument", "true", ""),
+		table.Entry("with unicode", "touch", "/var/run/kubevirt/disk-é.img"),
+	)
+
+	table.DescribeTable("should reject the argv", func(reason string, argv ...string) {
+		expectUnsafe(ValidateArgv(argv), reason)
+	},
+		table.Entry("with a NUL byte", "NUL byte at offset 4", "rm", "/tmp\x00/etc"),
+		table.Entry("with a newline", "control character 0xa", "echo", "a\nb"),
+		table.Entry("with a tab", "control character 0x9", "echo", "a\tb"),
+		table.Entry("with an escape sequence", "control character 0x1b", "echo", "\x1b[2J"),
+		table.Entry("with a DEL", "control character 0x7f", "echo", "a\x7f"),
+		table.Entry("with a control character in the command", "control character 0xd", "tr\r", "a"),
+	)
+
+	It("should reject an empty argv", func() {
+		Expect(ValidateArgv(nil)).To(MatchError(ContainSubstring("empty command")))
+	})
+
+	It("should tell which argument is unsafe", func() {
+		Expect(ValidateArgv([]string{"echo", "a", "b\n"})).To(MatchError(HavePrefix("argv[2]: unsafe argument")))
+	})
+
+	table.DescribeTable("should validate shell words", func(word string, reason string) {
+		err := ValidateShellWord(word)
+		if reason == "" {
+			Expect(err).ToNot(HaveOccurred())
+		} else {
+			expectUnsafe(err, reason)
+		}
+	},
+		table.Entry("accepting a volume name", "my-volume_1.img", ""),
+		table.Entry("accepting a path", "/var/run/kubevirt/hotplug-disks/disk0", ""),
+		table.Entry("rejecting an empty word", "", "empty shell word"),
+		table.Entry("rejecting a space", "my volume", `shell metacharacter ' '`),
+		table.Entry("rejecting a command separator", "disk;reboot", `shell metacharacter ';'`),
+		table.Entry("rejecting a command substitution", "$(reboot)", `shell metacharacter '$'`),
+		table.Entry("rejecting backquotes", "`reboot`", "shell metacharacter '`'"),
+		table.Entry("rejecting a glob", "disk*", `shell metacharacter '*'`),
+		table.Entry("rejecting a quote", "disk'", `shell metacharacter '\''`),
+		table.Entry("rejecting a newline", "disk\nreboot", "control character 0xa"),
+	)
+
+	table.DescribeTable("should validate path arguments", func(path string, reason string) {
+		err := ValidatePathArg(path)
+		if reason == "" {
+			Expect(err).ToNot(HaveOccurred())
+		} else {
+			expectUnsafe(err, reason)
+		}
+	},
+		table.Entry("accepting an absolute clean path", "/var/run/kubevirt/container-disks/disk_0.img", ""),
+		table.Entry("accepting the root", "/", ""),
+		table.Entry("rejecting a relative path", "disk.img", "path is not absolute"),
+		table.Entry("rejecting an option", "-rf", "path is not absolute"),
+		table.Entry("rejecting a parent reference", "/var/run/kubevirt/../../etc/shadow", `path is not clean, expected "/var/etc/shadow"`),
+		table.Entry("rejecting a trailing slash", "/var/run/kubevirt/", "path is not clean"),
+		table.Entry("rejecting a double slash", "/var//run", "path is not clean"),
+		table.Entry("rejecting a NUL byte", "/var/run\x00", "NUL byte"),
+	)
+
+	Context("with the executor", func() {
+		const launcherPID = 1234
+
+		var manager *testutils.FakeLabelManager
+		var restoreProcRoot func()
+
+		BeforeEach(func() {
+			restoreProcRoot = fakeProcRoot(launcherPID)
+			manager = testutils.NewFakeLabelManager()
+			manager.SetProcessLabel(launcherPID, testLauncherLabel)
+			manager.SetProcessLabel(os.Getpid(), testOriginalLabel)
+			detectSELinux = func() (SELinux, bool, error) {
+				return nil, true, nil
+			}
+			ResetSELinuxDetectionForTest()
+		})
+
+		AfterEach(func() {
+			restoreProcRoot()
+			detectSELinux = NewSELinux
+			ResetSELinuxDetectionForTest()
+		})
+
+		It("should refuse to create an executor for a command with unsafe arguments", func() {
+			_, err := NewContextExecutor(launcherPID, exec.Command("echo", "a\nb"), WithLabelManager(manager), WithArgvValidation())
+			expectUnsafe(err, "control character")
+		})
+
+		It("should not validate the arguments unless asked to", func() {
+			ce, err := NewContextExecutor(launcherPID, exec.Command("echo", "a\nb"), WithLabelManager(manager))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ce.Execute()).To(Succeed())
+		})
+
+		It("should refuse to create a batch executor for a command with unsafe arguments", func() {
+			cmds := []*exec.Cmd{exec.Command("true"), exec.Command("echo", "a\x00b")}
+			_, err := NewBatchContextExecutor(launcherPID, cmds, WithLabelManager(manager), WithArgvValidation())
+			expectUnsafe(err, "NUL byte")
+		})
+
+		It("should not run any of the commands of ExecuteMany if one has unsafe arguments", func() {
+			ce, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager), WithArgvValidation())
+			Expect(err).ToNot(HaveOccurred())
+
+			results, err := ce.ExecuteMany([]*exec.Cmd{exec.Command("true"), exec.Command("echo", "\x1b[2J")}, FailSoft)
+			expectUnsafe(err, "control character")
+			Expect(results).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,140 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// auditLock serializes the writes of all executors, so that the lines of
+// concurrent commands sharing a sink never interleave.
+var auditLock sync.Mutex
+
+// auditLine is the JSON record written for each command run.
+type auditLine struct {
+	// Timestamp is the start of the command, in RFC 3339 with nanoseconds
+	Timestamp       string   `json:"timestamp"`
+	PID             int      `json:"pid"`
+	DesiredLabel    string   `json:"desiredLabel"`
+	OriginalLabel   string   `json:"originalLabel"`
+	Args            []string `json:"args"`
+	Outcome         string   `json:"outcome"`
+	ExitCode        int      `json:"exitCode"`
+	DurationSeconds float64  `json:"durationSeconds"`
+	Error           string   `json:"error,omitempty"`
+}
+
+// WithAuditSink makes the executor write one line to w for every command it
+// runs, dry runs included: a JSON object with the launcher pid, the labels,
+// the arguments, the outcome and the duration of the command. Each line is
+// handed to w in a single write, e.g. to an audit file opened with O_APPEND.
+// A failed write doesn't fail the command, it is logged and counted by the
+// kubevirt_selinux_audit_write_failed_total metric.
+func WithAuditSink(w io.Writer) Option {
+	return func(ce *ContextExecutor) {
+		ce.auditSink = w
+	}
+}
+
+// auditRun audits the run of cmd started at start, which returned *err and
+// left the state of the child in exit.
+func (ce ContextExecutor) auditRun(cmd *exec.Cmd, exit *childExit, start time.Time, err *error) {
+	result := ce.newResult(cmd)
+	result.Duration = time.Since(start)
+	if ce.dryRun {
+		result.Err = *err
+	} else {
+		result.ExitCode, result.Err = exit.code(*err)
+	}
+	ce.auditAt(result, start)
+}
+
+// runAudited runs cmd like run, auditing the run if an audit sink is set.
+func (ce ContextExecutor) runAudited(ctx context.Context, cmd *exec.Cmd) (err error) {
+	if ce.auditSink != nil {
+		exit := &childExit{}
+		ce.exit = exit
+		defer ce.auditRun(cmd, exit, time.Now(), &err)
+	}
+	_, _, err = ce.run(ctx, cmd)
+	return err
+}
+
+// logAuditedDryRun logs the dry run of cmd like logDryRun, auditing it if an
+// audit sink is set.
+func (ce ContextExecutor) logAuditedDryRun(cmd *exec.Cmd) (err error) {
+	if ce.auditSink != nil {
+		defer ce.auditRun(cmd, nil, time.Now(), &err)
+	}
+	return ce.logDryRun(cmd)
+}
+
+// audit writes the audit line of result, which just finished.
+func (ce ContextExecutor) audit(result *ExecuteResult) {
+	ce.auditAt(result, time.Now().Add(-result.Duration))
+}
+
+func (ce ContextExecutor) auditAt(result *ExecuteResult, start time.Time) {
+	if ce.auditSink == nil {
+		return
+	}
+	if err := writeAuditLine(ce.auditSink, result, start); err != nil {
+		countAuditWriteFailure()
+		ce.getLogger().Reason(err).Errorf("failed to audit %q in the selinux context of launcher pid %d", result.Args, result.PID)
+	}
+}
+
+func writeAuditLine(w io.Writer, result *ExecuteResult, start time.Time) error {
+	line := auditLine{
+		Timestamp:       start.UTC().Format(time.RFC3339Nano),
+		PID:             result.PID,
+		DesiredLabel:    result.DesiredLabel,
+		OriginalLabel:   result.OriginalLabel,
+		Args:            result.Args,
+		Outcome:         result.Outcome(),
+		ExitCode:        result.ExitCode,
+		DurationSeconds: result.Duration.Seconds(),
+	}
+	if result.Err != nil {
+		line.Error = result.Err.Error()
+	}
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	auditLock.Lock()
+	defer auditLock.Unlock()
+	n, err := w.Write(data)
+	if err != nil {
+		return err
+	}
+	if n != len(data) {
+		return fmt.Errorf("%v: wrote %d of %d bytes", io.ErrShortWrite, n, len(data))
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _ = Describe("Audit sink", func() {
+	const (
+		desiredLabel  = "system_u:system_r:container_t:s0:c1,c2"
+		originalLabel = "system_u:system_r:spc_t:s0"
+	)
+
+	var sink *bytes.Buffer
+
+	newExecutor := func(args ...string) ContextExecutor {
+		return ContextExecutor{
+			pid:           1,
+			desiredLabel:  desiredLabel,
+			originalLabel: originalLabel,
+			cmdToExecute:  exec.Command(args[0], args[1:]...),
+			auditSink:     sink,
+		}
+	}
+
+	auditLines := func() []auditLine {
+		Expect(sink.String()).To(HaveSuffix("\n"))
+		var lines []auditLine
+		for _, data := range strings.Split(strings.TrimSuffix(sink.String(), "\n"), "\n") {
+			line := auditLine{}
+			Expect(json.Unmarshal([]byte(data), &line)).To(Succeed(), data)
+			lines = append(lines, line)
+		}
+		return lines
+	}
+
+	BeforeEach(func() {
+		sink = &bytes.Buffer{}
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, false, nil
+		}
+		ResetSELinuxDetectionForTest()
+	})
+
+	AfterEach(func() {
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+	})
+
+	It("should write one audit line per successful Execute", func() {
+		Expect(newExecutor("true").Execute()).To(Succeed())
+		lines := auditLines()
+		Expect(lines).To(HaveLen(1))
+		line := lines[0]
+		Expect(line.PID).To(Equal(1))
+		Expect(line.DesiredLabel).To(Equal(desiredLabel))
+		Expect(line.OriginalLabel).To(Equal(originalLabel))
+		Expect(line.Args).To(Equal([]string{"true"}))
+		Expect(line.Outcome).To(Equal("succeeded"))
+		Expect(line.ExitCode).To(Equal(0))
+		Expect(line.DurationSeconds).To(BeNumerically(">", 0))
+		Expect(line.Error).To(BeEmpty())
+		timestamp, err := time.Parse(time.RFC3339Nano, line.Timestamp)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(timestamp).To(BeTemporally("~", time.Now(), time.Minute))
+	})
+
+	It("should audit the exit code and the error of a failed command", func() {
+		code, err := newExecutor("sh", "-c", "exit 3").ExecuteWithExitCode()
+		Expect(err).To(HaveOccurred())
+		Expect(code).To(Equal(3))
+		lines := auditLines()
+		Expect(lines).To(HaveLen(1))
+		Expect(lines[0].Outcome).To(Equal("failed"))
+		Expect(lines[0].ExitCode).To(Equal(3))
+		Expect(lines[0].Error).To(ContainSubstring("exit status 3"))
+	})
+
+	It("should audit commands which could not be started", func() {
+		Expect(newExecutor("/non/existing/command").Execute()).ToNot(Succeed())
+		lines := auditLines()
+		Expect(lines).To(HaveLen(1))
+		Expect(lines[0].Outcome).To(Equal("failed"))
+		Expect(lines[0].ExitCode).To(Equal(-1))
+	})
+
+	It("should audit dry runs", func() {
+		ce := newExecutor("true")
+		ce.dryRun = true
+		Expect(ce.Execute()).To(Succeed())
+		lines := auditLines()
+		Expect(lines).To(HaveLen(1))
+		Expect(lines[0].Outcome).To(Equal("dry-run"))
+	})
+
+	It("should audit each command run by ExecuteMany", func() {
+		ce := newExecutor("true")
+		_, err := ce.ExecuteMany([]*exec.Cmd{exec.Command("true"), exec.Command("false")}, FailSoft)
+		Expect(err).To(HaveOccurred())
+		lines := auditLines()
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[0].Args).To(Equal([]string{"true"}))
+		Expect(lines[0].Outcome).To(Equal("succeeded"))
+		Expect(lines[1].Args).To(Equal([]string{"false"}))
+		Expect(lines[1].ExitCode).To(Equal(1))
+	})
+
+	It("should audit each command run by a batch", func() {
+		bce := BatchContextExecutor{
+			ContextExecutor: newExecutor("true"),
+			cmdsToExecute:   []*exec.Cmd{exec.Command("true"), exec.Command("sh", "-c", "exit 2")},
+		}
+		Expect(bce.Execute()).ToNot(Succeed())
+		lines := auditLines()
+		Expect(lines).To(HaveLen(2))
+		Expect(lines[1].ExitCode).To(Equal(2))
+	})
+
+	It("should not interleave the lines of concurrent commands", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(newExecutor("true").Execute()).To(Succeed())
+			}()
+		}
+		wg.Wait()
+		Expect(auditLines()).To(HaveLen(10))
+	})
+
+	It("should run the command and count the failure if the audit line can't be written", func() {
+		const nodeName = "testnode"
+		registry := prometheus.NewRegistry()
+		Expect(RegisterMetrics(registry, nodeName)).To(Succeed())
+		failures := func() float64 {
+			families, err := registry.Gather()
+			Expect(err).ToNot(HaveOccurred())
+			for _, family := range families {
+				if family.GetName() != "kubevirt_selinux_audit_write_failed_total" {
+					continue
+				}
+				for _, metric := range family.GetMetric() {
+					if hasNodeLabel(metric, nodeName) {
+						return metric.GetCounter().GetValue()
+					}
+				}
+			}
+			return 0
+		}
+		before := failures()
+
+		ce := newExecutor("true")
+		ce.auditSink = failingWriter{}
+		Expect(ce.Execute()).To(Succeed())
+		Expect(failures()).To(Equal(before + 1))
+	})
+
+	It("should report short writes", func() {
+		result := &ExecuteResult{Args: []string{"true"}}
+		err := writeAuditLine(shortWriter{}, result, time.Now())
+		Expect(err).To(MatchError(ContainSubstring("short write")))
+	})
+})
+
+type shortWriter struct{}
+
+func (shortWriter) Write(p []byte) (int, error) {
+	return len(p) / 2, nil
+}
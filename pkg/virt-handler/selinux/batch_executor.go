@@ -0,0 +1,178 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"context"
+	"os/exec"
+	"time"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// BatchContextExecutor runs several commands in the selinux context of the
+// same launcher, locking the OS thread and switching the label only once.
+type BatchContextExecutor struct {
+	ContextExecutor
+	cmdsToExecute []*exec.Cmd
+}
+
+func NewBatchContextExecutor(pid int, cmds []*exec.Cmd, options ...Option) (*BatchContextExecutor, error) {
+	ce, err := NewContextExecutor(pid, nil, options...)
+	if err != nil {
+		return nil, err
+	}
+	if ce.validateArgv {
+		if err := validateCommandArgv(cmds...); err != nil {
+			return nil, err
+		}
+	}
+	return &BatchContextExecutor{
+		ContextExecutor: *ce,
+		cmdsToExecute:   cmds,
+	}, nil
+}
+
+// Execute runs the commands in order, stopping at the first failure. The
+// thread context is restored even if a command fails. The post-exec hooks run
+// once, after the last command.
+func (bce BatchContextExecutor) Execute() error {
+	return bce.ExecuteContext(context.Background())
+}
+
+func (bce BatchContextExecutor) ExecuteContext(ctx context.Context) error {
+	if bce.dryRun {
+		for _, cmd := range bce.cmdsToExecute {
+			if err := bce.logAuditedDryRun(cmd); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return bce.inExecutionContext(func() error {
+		return bce.runInNamespaces(ctx)
+	})
+}
+
+func (bce BatchContextExecutor) runInNamespaces(ctx context.Context) (err error) {
+	restoreNamespaces, err := bce.enterNamespaces()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if restoreErr := restoreNamespaces(); restoreErr != nil && err == nil {
+			err = restoreErr
+		}
+	}()
+
+	return bce.runPostExecHooks(bce.runCommands(ctx))
+}
+
+func (bce BatchContextExecutor) runCommands(ctx context.Context) error {
+	for _, cmd := range bce.cmdsToExecute {
+		if err := bce.runAudited(ctx, cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Mode tells ExecuteMany how to handle a failing command.
+type Mode int
+
+const (
+	// FailFast stops at the first failing command.
+	FailFast Mode = iota
+	// FailSoft runs all commands and aggregates their errors.
+	FailSoft
+)
+
+// ExecuteMany runs cmds in order like a BatchContextExecutor, switching the
+// label and entering the namespaces of the launcher once for all of them, and
+// records a result for each command which ran. With FailFast it stops at the
+// first failing command and returns its error, with FailSoft it runs all
+// commands and returns the aggregate of their errors. Errors of switching the
+// thread context or of the post-exec hooks are returned as well.
+func (ce ContextExecutor) ExecuteMany(cmds []*exec.Cmd, mode Mode) ([]*ExecuteResult, error) {
+	if ce.validateArgv {
+		if err := validateCommandArgv(cmds...); err != nil {
+			return nil, err
+		}
+	}
+	results := make([]*ExecuteResult, 0, len(cmds))
+	runAll := func(runOne func(cmd *exec.Cmd) *ExecuteResult) error {
+		var errs []error
+		for _, cmd := range cmds {
+			result := runOne(cmd)
+			results = append(results, result)
+			if result.Err == nil {
+				continue
+			}
+			if mode == FailFast {
+				return result.Err
+			}
+			errs = append(errs, result.Err)
+		}
+		return utilerrors.NewAggregate(errs)
+	}
+
+	if ce.dryRun {
+		return results, runAll(func(cmd *exec.Cmd) *ExecuteResult {
+			result := ce.newResult(cmd)
+			result.Err = ce.logDryRun(cmd)
+			ce.audit(result)
+			return result
+		})
+	}
+
+	err := ce.inExecutionContext(func() (err error) {
+		restoreNamespaces, err := ce.enterNamespaces()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if restoreErr := restoreNamespaces(); restoreErr != nil && err == nil {
+				err = restoreErr
+			}
+		}()
+
+		return ce.runPostExecHooks(runAll(func(cmd *exec.Cmd) *ExecuteResult {
+			return ce.runWithResult(cmd)
+		}))
+	})
+	return results, err
+}
+
+// runWithResult runs cmd in the current thread context and records the run.
+func (ce ContextExecutor) runWithResult(cmd *exec.Cmd) *ExecuteResult {
+	result := ce.newResult(cmd)
+	exit := &childExit{}
+	ce.exit = exit
+	output := ce.newRingBuffer()
+	ce.ringBuffer = output
+	start := time.Now()
+	_, _, err := ce.run(context.Background(), cmd)
+	result.ExitCode, result.Err = exit.code(err)
+	result.Duration = time.Since(start)
+	result.setOutput(output)
+	ce.audit(result)
+	return result
+}
@@ -0,0 +1,170 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"os/exec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+var _ = Describe("BatchContextExecutor", func() {
+
+	var execLabels []string
+	var currentLabel string
+
+	BeforeEach(func() {
+		execLabels = nil
+		currentLabel = testOriginalLabel
+		defaultLabelManager = execLabelFunc(func(label string) error {
+			execLabels = append(execLabels, label)
+			currentLabel = label
+			return nil
+		})
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, true, nil
+		}
+		ResetSELinuxDetectionForTest()
+	})
+
+	AfterEach(func() {
+		defaultLabelManager = NewLabelManager()
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+	})
+
+	newBatch := func(cmds ...*exec.Cmd) BatchContextExecutor {
+		return BatchContextExecutor{
+			ContextExecutor: ContextExecutor{pid: 1, desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel},
+			cmdsToExecute:   cmds,
+		}
+	}
+
+	// labelObservingCmd records the exec label in effect while the command runs
+	labelObservingCmd := func(observed *string) *exec.Cmd {
+		cmd := exec.Command("echo", "running")
+		cmd.Stdout = writerFunc(func(p []byte) (int, error) {
+			*observed = currentLabel
+			return len(p), nil
+		})
+		return cmd
+	}
+
+	It("should switch the label only once for all commands", func() {
+		observed := make([]string, 3)
+		batch := newBatch(labelObservingCmd(&observed[0]), labelObservingCmd(&observed[1]), labelObservingCmd(&observed[2]))
+		Expect(batch.Execute()).To(Succeed())
+		Expect(execLabels).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+		Expect(observed).To(Equal([]string{testLauncherLabel, testLauncherLabel, testLauncherLabel}))
+	})
+
+	It("should stop at the first failing command and still restore the label", func() {
+		batch := newBatch(exec.Command("true"), exec.Command("false"), exec.Command("true"))
+		Expect(batch.Execute()).ToNot(Succeed())
+		Expect(execLabels).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+		Expect(batch.cmdsToExecute[1].ProcessState).ToNot(BeNil())
+		Expect(batch.cmdsToExecute[2].ProcessState).To(BeNil())
+	})
+
+	Context("ExecuteMany", func() {
+		var ce ContextExecutor
+
+		BeforeEach(func() {
+			ce = ContextExecutor{pid: 1, desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+		})
+
+		exitCodes := func(results []*ExecuteResult) []int {
+			var codes []int
+			for _, result := range results {
+				codes = append(codes, result.ExitCode)
+			}
+			return codes
+		}
+
+		It("should run all commands with the label switched only once", func() {
+			observed := make([]string, 2)
+			results, err := ce.ExecuteMany([]*exec.Cmd{labelObservingCmd(&observed[0]), labelObservingCmd(&observed[1])}, FailFast)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(2))
+			Expect(exitCodes(results)).To(Equal([]int{0, 0}))
+			Expect(results[0].Args).To(Equal([]string{"echo", "running"}))
+			Expect(results[0].DesiredLabel).To(Equal(testLauncherLabel))
+			Expect(execLabels).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+			Expect(observed).To(Equal([]string{testLauncherLabel, testLauncherLabel}))
+		})
+
+		It("should stop at a mid-sequence failure in fail-fast mode", func() {
+			cmds := []*exec.Cmd{exec.Command("true"), exec.Command("sh", "-c", "exit 3"), exec.Command("true")}
+			results, err := ce.ExecuteMany(cmds, FailFast)
+			Expect(err).To(HaveOccurred())
+			Expect(exitCodes(results)).To(Equal([]int{0, 3}))
+			Expect(results[1].Err).To(Equal(err))
+			Expect(cmds[2].ProcessState).To(BeNil())
+			Expect(execLabels).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+		})
+
+		It("should run all commands and aggregate the failures in fail-soft mode", func() {
+			cmds := []*exec.Cmd{exec.Command("false"), exec.Command("true"), exec.Command("sh", "-c", "exit 3"), exec.Command("true")}
+			results, err := ce.ExecuteMany(cmds, FailSoft)
+			Expect(err).To(HaveOccurred())
+			Expect(exitCodes(results)).To(Equal([]int{1, 0, 3, 0}))
+			Expect(err.(utilerrors.Aggregate).Errors()).To(Equal([]error{results[0].Err, results[2].Err}))
+			Expect(results[1].Err).ToNot(HaveOccurred())
+			Expect(results[3].Err).ToNot(HaveOccurred())
+			Expect(execLabels).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+		})
+
+		It("should not fail in fail-soft mode if all commands succeed", func() {
+			results, err := ce.ExecuteMany([]*exec.Cmd{exec.Command("true"), exec.Command("true")}, FailSoft)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(exitCodes(results)).To(Equal([]int{0, 0}))
+		})
+
+		It("should run the post-exec hooks once after the last command", func() {
+			hookRuns := 0
+			ce.postExecHooks = []func() error{func() error {
+				hookRuns++
+				return nil
+			}}
+			_, err := ce.ExecuteMany([]*exec.Cmd{exec.Command("false"), exec.Command("true")}, FailSoft)
+			Expect(err).To(HaveOccurred())
+			Expect(hookRuns).To(Equal(1))
+		})
+
+		It("should only record the commands in dry run mode", func() {
+			ce.dryRun = true
+			cmds := []*exec.Cmd{exec.Command("false"), exec.Command("true")}
+			results, err := ce.ExecuteMany(cmds, FailFast)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(2))
+			Expect(results[0].DryRun).To(BeTrue())
+			Expect(cmds[0].ProcessState).To(BeNil())
+			Expect(execLabels).To(BeEmpty())
+		})
+	})
+})
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
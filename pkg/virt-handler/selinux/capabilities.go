@@ -0,0 +1,172 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	capSetPCAP = 8
+
+	capLastCapPath = "/proc/sys/kernel/cap_last_cap"
+)
+
+// WithCapabilities runs the executed commands with only the keep capabilities,
+// e.g. unix.CAP_CHOWN, all others are dropped from their bounding set. It
+// composes with WithCredentials: a child switching to a non-root uid keeps
+// the capabilities as ambient capabilities. The capabilities have to be held
+// by virt-handler, which additionally needs CAP_SETPCAP to drop the others.
+// The OS thread forking the child is destroyed afterwards, since the
+// capabilities dropped from its bounding set can't be raised again.
+func WithCapabilities(keep []uintptr) Option {
+	return func(ce *ContextExecutor) {
+		ce.restrictCapabilities = true
+		ce.capabilities = append([]uintptr{}, keep...)
+	}
+}
+
+// applyCapabilities makes cmd raise the kept capabilities as ambient ones, so
+// that they survive the switch to the credentials of the executor.
+func (ce ContextExecutor) applyCapabilities(cmd *exec.Cmd) {
+	if !ce.restrictCapabilities || len(ce.capabilities) == 0 {
+		return
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.AmbientCaps = append([]uintptr{}, ce.capabilities...)
+}
+
+// inRestrictedThread runs f on a dedicated goroutine, locked to an OS thread
+// restricted to the kept capabilities, the priority, the scheduling policy
+// and the umask, and waits for it. The thread is never unlocked, so that the
+// runtime destroys it when the goroutine exits.
+func (ce ContextExecutor) inRestrictedThread(f func() error) error {
+	var err error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runtime.LockOSThread()
+		defer observeThreadLock(time.Now())
+		if err = ce.restrictThread(); err != nil {
+			return
+		}
+		err = f()
+	}()
+	<-done
+	return err
+}
+
+// restrictsThread reports whether the thread forking the children has to be
+// restricted in a way which can't be undone.
+func (ce ContextExecutor) restrictsThread() bool {
+	return ce.restrictCapabilities || ce.priority != nil || ce.schedPolicy != nil || ce.umask != nil
+}
+
+// restrictThread applies the priority, the scheduling policy, the umask and
+// the kept capabilities of the executor to the calling OS thread, which has
+// to be locked.
+func (ce ContextExecutor) restrictThread() error {
+	if ce.priority != nil {
+		if err := ce.priority.applyToThread(); err != nil {
+			return err
+		}
+	}
+	if ce.schedPolicy != nil {
+		if err := ce.schedPolicy.applyToThread(); err != nil {
+			return err
+		}
+	}
+	if ce.umask != nil {
+		if err := applyUmaskToThread(*ce.umask); err != nil {
+			return err
+		}
+	}
+	if ce.restrictCapabilities {
+		return ce.restrictThreadCapabilities()
+	}
+	return nil
+}
+
+// restrictThreadCapabilities drops all but the kept capabilities from the
+// bounding set of the calling OS thread, which has to be locked, and makes
+// them inheritable. The effective capabilities of the thread are left as is,
+// since the child still needs them to switch to its credentials.
+func (ce ContextExecutor) restrictThreadCapabilities() error {
+	header := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	data := [2]unix.CapUserData{}
+	if err := unix.Capget(&header, &data[0]); err != nil {
+		return fmt.Errorf("failed to read the capabilities of virt-handler: %v", err)
+	}
+	lastCap, err := readLastCapability()
+	if err != nil {
+		return err
+	}
+
+	keep := map[uintptr]bool{}
+	var inheritable [2]uint32
+	for _, c := range ce.capabilities {
+		if c > lastCap || data[c/32].Effective&(1<<(c%32)) == 0 {
+			return fmt.Errorf("capability %d is not held by virt-handler and can't be kept for the command executed in launcher namespace %d", c, ce.pid)
+		}
+		keep[c] = true
+		inheritable[c/32] |= 1 << (c % 32)
+	}
+	if data[0].Effective&(1<<capSetPCAP) == 0 {
+		return fmt.Errorf("restricting the capabilities of the command executed in launcher namespace %d requires the CAP_SETPCAP capability", ce.pid)
+	}
+
+	for c := uintptr(0); c <= lastCap; c++ {
+		if keep[c] {
+			continue
+		}
+		if err := unix.Prctl(unix.PR_CAPBSET_DROP, c, 0, 0, 0); err != nil {
+			return fmt.Errorf("failed to drop capability %d from the bounding set: %v", c, err)
+		}
+	}
+	data[0].Inheritable = inheritable[0]
+	data[1].Inheritable = inheritable[1]
+	if err := unix.Capset(&header, &data[0]); err != nil {
+		return fmt.Errorf("failed to make the kept capabilities inheritable: %v", err)
+	}
+	return nil
+}
+
+func readLastCapability() (uintptr, error) {
+	content, err := ioutil.ReadFile(capLastCapPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the last capability of the kernel: %v", err)
+	}
+	lastCap, err := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse the last capability of the kernel: %v", err)
+	}
+	return uintptr(lastCap), nil
+}
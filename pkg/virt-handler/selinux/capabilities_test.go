@@ -0,0 +1,138 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"golang.org/x/sys/unix"
+)
+
+var _ = Describe("Running commands with restricted capabilities", func() {
+	var tempDir string
+
+	readCapabilities := func(status, field string) uint64 {
+		for _, line := range strings.Split(status, "\n") {
+			if strings.HasPrefix(line, field+":") {
+				caps, err := strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, field+":")), 16, 64)
+				Expect(err).ToNot(HaveOccurred())
+				return caps
+			}
+		}
+		Fail(fmt.Sprintf("no %s found in the status", field))
+		return 0
+	}
+
+	newExecutor := func(cmd *exec.Cmd, options ...Option) *ContextExecutor {
+		ce := &ContextExecutor{pid: 1, cmdToExecute: cmd}
+		for _, option := range options {
+			option(ce)
+		}
+		return ce
+	}
+
+	childStatus := func(options ...Option) string {
+		ce := newExecutor(exec.Command("cat", "/proc/self/status"), options...)
+		stdout, _, err := ce.ExecuteWithOutput()
+		Expect(err).ToNot(HaveOccurred())
+		return stdout.String()
+	}
+
+	BeforeEach(func() {
+		caps, err := readEffectiveCapabilities()
+		if err != nil || caps&(1<<capSetPCAP) == 0 || caps&(1<<unix.CAP_CHOWN) == 0 {
+			Skip("restricting capabilities requires CAP_SETPCAP and CAP_CHOWN")
+		}
+		tempDir, err = ioutil.TempDir("", "kubevirt-capabilities")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("should only leave the kept capabilities to the child", func() {
+		status := childStatus(WithCapabilities([]uintptr{unix.CAP_CHOWN, unix.CAP_DAC_OVERRIDE}))
+
+		keep := uint64(1<<unix.CAP_CHOWN | 1<<unix.CAP_DAC_OVERRIDE)
+		Expect(readCapabilities(status, "CapEff")).To(Equal(keep))
+		Expect(readCapabilities(status, "CapBnd")).To(Equal(keep))
+	})
+
+	It("should make a dropped capability unavailable to the child", func() {
+		file := filepath.Join(tempDir, "disk.img")
+		Expect(ioutil.WriteFile(file, nil, 0644)).To(Succeed())
+
+		ce := newExecutor(exec.Command("chown", "65534", file), WithCapabilities(nil))
+		Expect(ce.Execute()).ToNot(Succeed())
+
+		ce = newExecutor(exec.Command("chown", "65534", file), WithCapabilities([]uintptr{unix.CAP_CHOWN}))
+		Expect(ce.Execute()).To(Succeed())
+	})
+
+	It("should keep the capabilities across the switch of the credentials", func() {
+		caps, err := readEffectiveCapabilities()
+		Expect(err).ToNot(HaveOccurred())
+		if caps&(1<<capSetUID) == 0 || caps&(1<<capSetGID) == 0 {
+			Skip("switching credentials requires CAP_SETUID and CAP_SETGID")
+		}
+		status := childStatus(WithCredentials(107, 107, nil), WithCapabilities([]uintptr{unix.CAP_CHOWN}))
+
+		Expect(status).To(MatchRegexp(`(?m)^Uid:\s+107\s`))
+		Expect(readCapabilities(status, "CapEff")).To(Equal(uint64(1 << unix.CAP_CHOWN)))
+		Expect(readCapabilities(status, "CapAmb")).To(Equal(uint64(1 << unix.CAP_CHOWN)))
+	})
+
+	It("should refuse to keep a capability virt-handler does not hold", func() {
+		lastCap, err := readLastCapability()
+		Expect(err).ToNot(HaveOccurred())
+		ce := newExecutor(exec.Command("true"), WithCapabilities([]uintptr{lastCap + 1}))
+
+		Expect(ce.Execute()).To(MatchError(fmt.Sprintf("capability %d is not held by virt-handler and can't be kept for the command executed in launcher namespace 1", lastCap+1)))
+	})
+
+	It("should leave the capabilities of virt-handler untouched", func() {
+		before, err := ioutil.ReadFile("/proc/self/status")
+		Expect(err).ToNot(HaveOccurred())
+		childStatus(WithCapabilities(nil))
+
+		after, err := ioutil.ReadFile("/proc/self/status")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readCapabilities(string(after), "CapBnd")).To(Equal(readCapabilities(string(before), "CapBnd")))
+		Expect(readCapabilities(string(after), "CapEff")).To(Equal(readCapabilities(string(before), "CapEff")))
+	})
+
+	It("should not restrict the capabilities by default", func() {
+		status := childStatus()
+
+		caps, err := readEffectiveCapabilities()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readCapabilities(status, "CapEff")).To(Equal(caps))
+	})
+})
@@ -0,0 +1,133 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const launcherComm = "virt-launcher"
+
+var (
+	cgroupRoot = "/sys/fs/cgroup"
+	procRoot   = "/proc"
+
+	// cgroupV1Hierarchies are the cgroup v1 hierarchies tried in order to find
+	// the processes of a pod, all of them track every process
+	cgroupV1Hierarchies = []string{"pids", "memory", "cpu,cpuacct"}
+)
+
+// ResolveLauncherPID returns the pid of the virt-launcher process of the pod
+// owning the given cgroup, to be passed to NewContextExecutor. cgroupPath is
+// either the pod or the compute container cgroup, relative to the root of the
+// hierarchy as found in /proc/<pid>/cgroup, or an absolute path below
+// /sys/fs/cgroup. Both the cgroup v1 and the unified v2 layouts are supported.
+func ResolveLauncherPID(cgroupPath string) (int, error) {
+	dir, err := cgroupDir(cgroupPath)
+	if err != nil {
+		return 0, err
+	}
+
+	var launcherPIDs []int
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || info.Name() != "cgroup.procs" {
+			return nil
+		}
+		pids, err := readCgroupProcs(path)
+		if err != nil {
+			return err
+		}
+		for _, pid := range pids {
+			if isLauncherProcess(pid) {
+				launcherPIDs = append(launcherPIDs, pid)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list the processes of cgroup %s: %v", cgroupPath, err)
+	}
+
+	switch len(launcherPIDs) {
+	case 0:
+		return 0, fmt.Errorf("no %s process found in cgroup %s", launcherComm, cgroupPath)
+	case 1:
+		return launcherPIDs[0], nil
+	}
+	return 0, fmt.Errorf("several %s processes found in cgroup %s: %v", launcherComm, cgroupPath, launcherPIDs)
+}
+
+// cgroupDir returns the directory of the given cgroup in the unified
+// hierarchy, or in the first cgroup v1 hierarchy holding it.
+func cgroupDir(cgroupPath string) (string, error) {
+	if strings.HasPrefix(cgroupPath, cgroupRoot+"/") {
+		return cgroupPath, nil
+	}
+
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err == nil {
+		// cgroup v2, the unified hierarchy is mounted on the root
+		dir := filepath.Join(cgroupRoot, cgroupPath)
+		if _, err := os.Stat(dir); err != nil {
+			return "", err
+		}
+		return dir, nil
+	}
+
+	for _, hierarchy := range cgroupV1Hierarchies {
+		dir := filepath.Join(cgroupRoot, hierarchy, cgroupPath)
+		if _, err := os.Stat(dir); err == nil {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("cgroup %s not found in any of the %v hierarchies", cgroupPath, cgroupV1Hierarchies)
+}
+
+func readCgroupProcs(path string) ([]int, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, line := range strings.Fields(string(content)) {
+		pid, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pid %q in %s", line, path)
+		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
+
+func isLauncherProcess(pid int) bool {
+	comm, err := ioutil.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "comm"))
+	if err != nil {
+		// the process is gone
+		return false
+	}
+	return strings.TrimSpace(string(comm)) == launcherComm
+}
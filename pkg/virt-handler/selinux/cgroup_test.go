@@ -0,0 +1,160 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Resolving the launcher pid", func() {
+	const (
+		pausePID    = 100
+		launcherPID = 101
+		qemuPID     = 150
+	)
+
+	var orgCgroupRoot, orgProcRoot string
+	var tempDir string
+
+	addProcess := func(pid int, comm string) {
+		dir := filepath.Join(procRoot, strconv.Itoa(pid))
+		Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "comm"), []byte(comm+"\n"), 0644)).To(Succeed())
+	}
+
+	addCgroup := func(path string, pids ...int) {
+		dir := filepath.Join(cgroupRoot, path)
+		Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+		var procs []string
+		for _, pid := range pids {
+			procs = append(procs, strconv.Itoa(pid))
+		}
+		Expect(ioutil.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte(strings.Join(procs, "\n")), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "cgroup")
+		Expect(err).ToNot(HaveOccurred())
+		orgCgroupRoot, orgProcRoot = cgroupRoot, procRoot
+		cgroupRoot = filepath.Join(tempDir, "cgroup")
+		procRoot = filepath.Join(tempDir, "proc")
+
+		addProcess(pausePID, "pause")
+		addProcess(launcherPID, "virt-launcher")
+		addProcess(qemuPID, "qemu-kvm")
+	})
+
+	AfterEach(func() {
+		cgroupRoot, procRoot = orgCgroupRoot, orgProcRoot
+		os.RemoveAll(tempDir)
+	})
+
+	Context("with cgroup v1", func() {
+		const podCgroup = "/kubepods/burstable/pod1234"
+
+		BeforeEach(func() {
+			for _, hierarchy := range []string{"memory", "pids"} {
+				addCgroup(filepath.Join(hierarchy, podCgroup))
+				addCgroup(filepath.Join(hierarchy, podCgroup, "pause-container"), pausePID)
+				addCgroup(filepath.Join(hierarchy, podCgroup, "compute-container"), launcherPID, qemuPID)
+			}
+		})
+
+		table.DescribeTable("should find the launcher", func(cgroupPath func() string) {
+			pid, err := ResolveLauncherPID(cgroupPath())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pid).To(Equal(launcherPID))
+		},
+			table.Entry("from the pod cgroup", func() string { return podCgroup }),
+			table.Entry("from the compute container cgroup", func() string { return podCgroup + "/compute-container" }),
+			table.Entry("from an absolute cgroup path", func() string { return filepath.Join(cgroupRoot, "memory", podCgroup) }),
+		)
+
+		It("should fall back to the other hierarchies", func() {
+			Expect(os.RemoveAll(filepath.Join(cgroupRoot, "pids"))).To(Succeed())
+			pid, err := ResolveLauncherPID(podCgroup)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pid).To(Equal(launcherPID))
+		})
+
+		It("should fail if the cgroup does not exist", func() {
+			_, err := ResolveLauncherPID("/kubepods/burstable/pod5678")
+			Expect(err).To(MatchError(ContainSubstring("not found")))
+		})
+	})
+
+	Context("with cgroup v2", func() {
+		const podCgroup = "/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1234.slice"
+
+		BeforeEach(func() {
+			addCgroup("")
+			Expect(ioutil.WriteFile(filepath.Join(cgroupRoot, "cgroup.controllers"), []byte("cpu memory pids\n"), 0644)).To(Succeed())
+			addCgroup(podCgroup)
+			addCgroup(filepath.Join(podCgroup, "cri-containerd-pause.scope"), pausePID)
+			addCgroup(filepath.Join(podCgroup, "cri-containerd-compute.scope"), launcherPID, qemuPID)
+		})
+
+		It("should find the launcher from the pod cgroup", func() {
+			pid, err := ResolveLauncherPID(podCgroup)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pid).To(Equal(launcherPID))
+		})
+
+		It("should find the launcher from the compute container cgroup", func() {
+			pid, err := ResolveLauncherPID(filepath.Join(podCgroup, "cri-containerd-compute.scope"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pid).To(Equal(launcherPID))
+		})
+
+		It("should skip processes which are gone", func() {
+			addCgroup(filepath.Join(podCgroup, "cri-containerd-other.scope"), 999)
+			pid, err := ResolveLauncherPID(podCgroup)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pid).To(Equal(launcherPID))
+		})
+
+		It("should fail if there is no launcher", func() {
+			_, err := ResolveLauncherPID(filepath.Join(podCgroup, "cri-containerd-pause.scope"))
+			Expect(err).To(MatchError(ContainSubstring("no virt-launcher process found")))
+		})
+
+		It("should fail if there are several launchers", func() {
+			addProcess(200, "virt-launcher")
+			addCgroup(filepath.Join(podCgroup, "cri-containerd-other.scope"), 200)
+			_, err := ResolveLauncherPID(podCgroup)
+			Expect(err).To(MatchError(ContainSubstring("several virt-launcher processes")))
+		})
+
+		It("should fail on invalid cgroup.procs content", func() {
+			Expect(ioutil.WriteFile(filepath.Join(cgroupRoot, podCgroup, "cgroup.procs"), []byte("abc\n"), 0644)).To(Succeed())
+			_, err := ResolveLauncherPID(podCgroup)
+			Expect(err).To(MatchError(ContainSubstring(`invalid pid "abc"`)))
+		})
+	})
+})
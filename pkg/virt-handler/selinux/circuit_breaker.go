@@ -0,0 +1,157 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+
+	v1 "kubevirt.io/client-go/api/v1"
+)
+
+const (
+	defaultCircuitBreakerThreshold  = 5
+	defaultCircuitBreakerBackoff    = time.Minute
+	defaultCircuitBreakerMaxBackoff = 30 * time.Minute
+)
+
+// CircuitBreaker stops retrying the syncs of a VMI once they failed to apply
+// its selinux labels a number of consecutive times, e.g. because of a broken
+// policy or a missing policy module. The breaker opens for a backoff doubling
+// with each trip, after which a single sync is let through; its success
+// closes the breaker, its failure opens it again. Changing the spec of the VMI
+// resets its breaker. The number of tripped breakers is exposed as the
+// kubevirt_selinux_circuit_breakers_open metric.
+type CircuitBreaker struct {
+	threshold  int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	now        func() time.Time
+
+	lock     sync.Mutex
+	circuits map[types.UID]*circuit
+}
+
+type circuit struct {
+	spec      *v1.VirtualMachineInstanceSpec
+	failures  int
+	trips     int
+	openUntil time.Time
+	lastErr   error
+}
+
+func NewCircuitBreaker() *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold:  defaultCircuitBreakerThreshold,
+		backoff:    defaultCircuitBreakerBackoff,
+		maxBackoff: defaultCircuitBreakerMaxBackoff,
+		now:        time.Now,
+		circuits:   map[types.UID]*circuit{},
+	}
+}
+
+// Allow returns a CircuitOpenError if the breaker of vmi is open, nil if it
+// may be synced.
+func (b *CircuitBreaker) Allow(vmi *v1.VirtualMachineInstance) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	c := b.circuit(vmi)
+	if c == nil || c.failures < b.threshold {
+		return nil
+	}
+	if remaining := c.openUntil.Sub(b.now()); remaining > 0 {
+		return &CircuitOpenError{Failures: c.failures, RetryAfter: remaining, Err: c.lastErr}
+	}
+	return nil
+}
+
+// Record accounts for the result of a sync of vmi. Selinux failures are
+// counted, and returned as a CircuitOpenError once they trip the breaker. A
+// success closes it, other failures leave it as it is.
+func (b *CircuitBreaker) Record(vmi *v1.VirtualMachineInstance, err error) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	defer b.updateMetric()
+
+	c := b.circuit(vmi)
+	if err == nil {
+		delete(b.circuits, vmi.UID)
+		return nil
+	}
+	if !IsSELinuxError(err) {
+		return err
+	}
+	if c == nil {
+		c = &circuit{spec: vmi.Spec.DeepCopy()}
+		b.circuits[vmi.UID] = c
+	}
+	c.failures++
+	c.lastErr = err
+	if c.failures < b.threshold {
+		return err
+	}
+	c.trips++
+	backoff := b.backoff
+	for i := 1; i < c.trips && backoff < b.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > b.maxBackoff {
+		backoff = b.maxBackoff
+	}
+	c.openUntil = b.now().Add(backoff)
+	return &CircuitOpenError{Failures: c.failures, RetryAfter: backoff, Err: err}
+}
+
+// Forget drops the breaker of the VMI with the given UID.
+func (b *CircuitBreaker) Forget(uid types.UID) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	delete(b.circuits, uid)
+	b.updateMetric()
+}
+
+// circuit returns the breaker of vmi, dropping it if the spec of vmi changed
+// since its first failure.
+func (b *CircuitBreaker) circuit(vmi *v1.VirtualMachineInstance) *circuit {
+	c, exists := b.circuits[vmi.UID]
+	if !exists {
+		return nil
+	}
+	if !equality.Semantic.DeepEqual(c.spec, &vmi.Spec) {
+		delete(b.circuits, vmi.UID)
+		b.updateMetric()
+		return nil
+	}
+	return c
+}
+
+func (b *CircuitBreaker) updateMetric() {
+	open := 0
+	for _, c := range b.circuits {
+		if c.failures >= b.threshold {
+			open++
+		}
+	}
+	setCircuitBreakersOpen(open)
+}
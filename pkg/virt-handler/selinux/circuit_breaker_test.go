@@ -0,0 +1,186 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	v1 "kubevirt.io/client-go/api/v1"
+)
+
+var _ = Describe("Circuit breaker", func() {
+	const nodeName = "testnode"
+
+	var registry *prometheus.Registry
+	var breaker *CircuitBreaker
+	var vmi *v1.VirtualMachineInstance
+	var now time.Time
+
+	labelErr := &LabelError{Kind: ProcNotReadable, PID: 1, Err: errors.New("permission denied")}
+
+	openValue := func() float64 {
+		families, err := registry.Gather()
+		Expect(err).ToNot(HaveOccurred())
+		for _, family := range families {
+			if family.GetName() != "kubevirt_selinux_circuit_breakers_open" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				if hasNodeLabel(metric, nodeName) {
+					return metric.GetGauge().GetValue()
+				}
+			}
+		}
+		return -1
+	}
+
+	trip := func() *CircuitOpenError {
+		for i := 1; i < defaultCircuitBreakerThreshold; i++ {
+			Expect(breaker.Allow(vmi)).To(Succeed())
+			Expect(breaker.Record(vmi, labelErr)).To(Equal(labelErr))
+		}
+		Expect(breaker.Allow(vmi)).To(Succeed())
+		err := breaker.Record(vmi, labelErr)
+		var circuitErr *CircuitOpenError
+		Expect(errors.As(err, &circuitErr)).To(BeTrue())
+		return circuitErr
+	}
+
+	BeforeEach(func() {
+		registry = prometheus.NewRegistry()
+		Expect(RegisterMetrics(registry, nodeName)).To(Succeed())
+		now = time.Now()
+		breaker = NewCircuitBreaker()
+		breaker.now = func() time.Time { return now }
+		vmi = v1.NewMinimalVMI("testvmi")
+		vmi.UID = "1234"
+	})
+
+	It("should trip after the threshold of consecutive selinux failures", func() {
+		circuitErr := trip()
+		Expect(circuitErr.Failures).To(Equal(defaultCircuitBreakerThreshold))
+		Expect(circuitErr.RetryAfter).To(Equal(defaultCircuitBreakerBackoff))
+		Expect(circuitErr).To(MatchError(ContainSubstring("stopped retrying after 5 consecutive selinux failures")))
+		Expect(IsSELinuxError(circuitErr)).To(BeTrue())
+		Expect(openValue()).To(Equal(1.0))
+
+		err := breaker.Allow(vmi)
+		Expect(errors.As(err, &circuitErr)).To(BeTrue())
+		Expect(circuitErr.Err).To(Equal(labelErr))
+	})
+
+	It("should neither count nor reset on other failures", func() {
+		otherErr := errors.New("launcher unresponsive")
+		for i := 1; i < defaultCircuitBreakerThreshold; i++ {
+			Expect(breaker.Record(vmi, labelErr)).To(Equal(labelErr))
+			Expect(breaker.Record(vmi, otherErr)).To(Equal(otherErr))
+		}
+		var circuitErr *CircuitOpenError
+		Expect(errors.As(breaker.Record(vmi, labelErr), &circuitErr)).To(BeTrue())
+	})
+
+	It("should reset on success", func() {
+		for i := 1; i < defaultCircuitBreakerThreshold; i++ {
+			Expect(breaker.Record(vmi, labelErr)).To(Equal(labelErr))
+		}
+		Expect(breaker.Record(vmi, nil)).To(Succeed())
+		Expect(breaker.Record(vmi, labelErr)).To(Equal(labelErr))
+		Expect(breaker.Allow(vmi)).To(Succeed())
+		Expect(openValue()).To(Equal(0.0))
+	})
+
+	It("should let a single sync through after the backoff and double it on failure", func() {
+		trip()
+
+		now = now.Add(defaultCircuitBreakerBackoff / 2)
+		err := breaker.Allow(vmi)
+		var circuitErr *CircuitOpenError
+		Expect(errors.As(err, &circuitErr)).To(BeTrue())
+		Expect(circuitErr.RetryAfter).To(Equal(defaultCircuitBreakerBackoff / 2))
+
+		now = now.Add(defaultCircuitBreakerBackoff / 2)
+		Expect(breaker.Allow(vmi)).To(Succeed())
+		err = breaker.Record(vmi, labelErr)
+		Expect(errors.As(err, &circuitErr)).To(BeTrue())
+		Expect(circuitErr.Failures).To(Equal(defaultCircuitBreakerThreshold + 1))
+		Expect(circuitErr.RetryAfter).To(Equal(2 * defaultCircuitBreakerBackoff))
+		Expect(breaker.Allow(vmi)).ToNot(Succeed())
+		Expect(openValue()).To(Equal(1.0))
+	})
+
+	It("should not back off longer than the maximum backoff", func() {
+		trip()
+		var circuitErr *CircuitOpenError
+		for i := 0; i < 10; i++ {
+			now = now.Add(defaultCircuitBreakerMaxBackoff)
+			Expect(breaker.Allow(vmi)).To(Succeed())
+			Expect(errors.As(breaker.Record(vmi, labelErr), &circuitErr)).To(BeTrue())
+		}
+		Expect(circuitErr.RetryAfter).To(Equal(defaultCircuitBreakerMaxBackoff))
+	})
+
+	It("should close after a successful sync following the backoff", func() {
+		trip()
+		now = now.Add(defaultCircuitBreakerBackoff)
+		Expect(breaker.Allow(vmi)).To(Succeed())
+		Expect(breaker.Record(vmi, nil)).To(Succeed())
+		Expect(openValue()).To(Equal(0.0))
+		Expect(breaker.Record(vmi, labelErr)).To(Equal(labelErr))
+	})
+
+	It("should reset when the spec of the vmi changes", func() {
+		trip()
+		Expect(breaker.Allow(vmi)).ToNot(Succeed())
+
+		vmi.Spec.Domain.Resources.Requests[k8sv1.ResourceMemory] = resource.MustParse("64Mi")
+		Expect(breaker.Allow(vmi)).To(Succeed())
+		Expect(openValue()).To(Equal(0.0))
+		Expect(breaker.Record(vmi, labelErr)).To(Equal(labelErr))
+	})
+
+	It("should not reset on status changes", func() {
+		trip()
+		vmi.Status.Phase = v1.Running
+		Expect(breaker.Allow(vmi)).ToNot(Succeed())
+	})
+
+	It("should keep the breakers of the vmis apart", func() {
+		trip()
+		other := v1.NewMinimalVMI("othervmi")
+		other.UID = "5678"
+		Expect(breaker.Allow(other)).To(Succeed())
+		Expect(breaker.Record(other, labelErr)).To(Equal(labelErr))
+		Expect(breaker.Allow(vmi)).ToNot(Succeed())
+	})
+
+	It("should forget the breaker of a vmi", func() {
+		trip()
+		breaker.Forget(vmi.UID)
+		Expect(breaker.Allow(vmi)).To(Succeed())
+		Expect(openValue()).To(Equal(0.0))
+	})
+})
@@ -20,89 +20,751 @@
 package selinux
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
-	"github.com/opencontainers/selinux/go-selinux"
+	k8sv1 "k8s.io/api/core/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/client-go/log"
+	"kubevirt.io/kubevirt/pkg/util/fdhygiene"
 )
 
 const (
-	minFDToCloseOnExec = 3
-	maxFDToCloseOnExec = 256
+	maxStderrTailBytes = 512
+
+	// SELinuxContextSwitchFailedReason is the reason of the event recorded
+	// when the launcher selinux context can't be switched to.
+	SELinuxContextSwitchFailedReason = "SELinuxContextSwitchFailed"
+
+	logComponent = "selinux"
+	// debugVerbosity is the log verbosity of the label switch and relabel traces
+	debugVerbosity = 4
 )
 
+// workingDirPrefixes are the directories, shared between virt-handler and the
+// launchers, the executed commands may be run from.
+var workingDirPrefixes = []string{
+	"/var/run/kubevirt",
+	"/var/run/kubevirt-private",
+	"/var/run/kubevirt-ephemeral-disks",
+}
+
+// setExecLabelBackoff bounds the retries of transient SetExecLabel failures,
+// e.g. when the thread attributes are contended on busy nodes.
+var setExecLabelBackoff = wait.Backoff{
+	Steps:    3,
+	Duration: 10 * time.Millisecond,
+	Factor:   2.0,
+}
+
+// Executor runs a command in the context of a launcher. It is implemented by
+// ContextExecutor, callers depending on it can be tested with a fake.
+type Executor interface {
+	Execute() error
+	ExecuteContext(ctx context.Context) error
+	Close() error
+}
+
+var _ Executor = ContextExecutor{}
+
+// ContextExecutor runs a command in the selinux context of a launcher. Its
+// executions aren't reentrant, see ErrNestedLabelSwitch.
 type ContextExecutor struct {
 	cmdToExecute  *exec.Cmd
 	desiredLabel  string
 	originalLabel string
 	pid           int
+	dryRun        bool
+	env           []string
+	workingDir    string
+	credential    *syscall.Credential
+	namespaces    []NSType
+	sharedMCSPIDs []int
+	fileLabel     string
+	labelManager  LabelManager
+	recorder      record.EventRecorder
+	eventObject   k8sruntime.Object
+	logger        *log.FilteredLogger
+
+	// forwardSignals relays the termination signals of virt-handler to the child
+	forwardSignals         bool
+	terminationGracePeriod time.Duration
+	// newSession runs the child in its own session and process group
+	newSession bool
+	// launcherLabelAttr is the attr file of the launcher its label is read from
+	launcherLabelAttr LabelAttr
+	// transitionType replaces the type of the launcher label the child runs with
+	transitionType string
+	// postExecHooks run after the child, still in its thread context
+	postExecHooks []func() error
+	// relabelWorkers bounds the paths relabeled at once
+	relabelWorkers int
+	// relabelSkipPaths are the prefixes and globs of the paths never relabeled
+	relabelSkipPaths []string
+	// filesystemInspector describes the filesystems of the paths to relabel
+	filesystemInspector FilesystemInspector
+	// skipUnrelabelableFilesystems skips the paths on read-only or xattr-less
+	// filesystems instead of failing them
+	skipUnrelabelableFilesystems bool
+	// verifyExecLabel reads back the label applied to the thread and the child
+	verifyExecLabel bool
+	// stdin is fed to the child instead of the stdin of cmd
+	stdin io.Reader
+	// capabilities are the only ones the child keeps if restrictCapabilities is set
+	restrictCapabilities bool
+	capabilities         []uintptr
+	// priority is the nice value and I/O priority the child runs with
+	priority *priority
+	// schedPolicy is the realtime scheduling policy the child runs with
+	schedPolicy *schedPolicy
+	// umask is the file mode creation mask the child runs with
+	umask *int
+	// rlimits are the resource limits the child runs with, by resource
+	rlimits map[int]syscall.Rlimit
+	// inheritFDs stay open in the child, under the same numbers
+	inheritFDs []int
+	// resetMode tells whether the thread is reset to the virt-handler label or destroyed
+	resetMode ResetMode
+	// restoreLabel replaces originalLabel as the label the thread is reset to, if hasRestoreLabel is set
+	restoreLabel    string
+	hasRestoreLabel bool
+	// fsCreateLabel is the file creation context of the thread starting the child, if hasFSCreateLabel is set
+	fsCreateLabel    string
+	hasFSCreateLabel bool
+	// labelReadTimeout bounds the reads of process labels, unbounded if zero
+	labelReadTimeout time.Duration
+	// permissiveManager marks the type of the child permissive while it runs
+	permissiveManager      PermissiveDomainManager
+	permissiveAuditLogPath string
+	// outputWriter receives the output of the child, prefixed with outputPrefix
+	outputWriter io.Writer
+	outputPrefix string
+	// ringBufferSize is the number of bytes of the output of the child kept for ExecuteResult
+	ringBufferSize int
+	// auditSink receives an audit line for each command run
+	auditSink io.Writer
+	// checkPIDNamespace requires the launcher pid to be in the pid namespace of virt-handler
+	checkPIDNamespace bool
+	// validateArgv rejects the commands with unsafe arguments
+	validateArgv bool
+
+	// manual tracks the context entered with Enter until Leave
+	manual *manualContext
+	// nsHandles keeps the namespaces of the launcher open until Close
+	nsHandles *namespaceHandles
+	// exit receives the state of the child for ExecuteWithExitCode
+	exit *childExit
+	// ringBuffer keeps the tail of the output of the child for ExecuteResult
+	ringBuffer *ringBuffer
+	// heartbeat kills the child once the file it touches goes stale
+	heartbeat *heartbeat
+	// defaultContextLookup resolves the policy labels of RestoreDefaultFileLabels
+	defaultContextLookup DefaultContextLookup
+	// labelTranslator translates the mcstrans levels of the labels read
+	labelTranslator LabelTranslator
 }
 
-func NewContextExecutor(pid int, cmd *exec.Cmd) (*ContextExecutor, error) {
-	desiredLabel, err := getLabelForPID(pid)
-	if err != nil {
+// Option customizes a ContextExecutor created by NewContextExecutor.
+type Option func(ce *ContextExecutor)
+
+// WithDryRun makes Execute log the label switch and the command it would run,
+// without touching the thread context or spawning the child.
+func WithDryRun() Option {
+	return func(ce *ContextExecutor) {
+		ce.dryRun = true
+	}
+}
+
+// WithEnv replaces the environment of the executed commands with env, so that
+// none of the virt-handler environment leaks into the launcher namespace. An
+// empty env keeps the previous behaviour of inheriting the cmd environment.
+func WithEnv(env []string) Option {
+	return func(ce *ContextExecutor) {
+		ce.env = append([]string(nil), env...)
+	}
+}
+
+// WithStdin feeds r to the child as its stdin, e.g. the JSON options of
+// qemu-img. It composes with the FD hygiene of the executor: the FDs flagged
+// close-on-exec before forking are only closed in the child, so an *os.File
+// passed as r stays open in virt-handler and is still handed to the child, its
+// FD being duplicated onto fd 0 which is never flagged. Other readers are
+// copied to the child through a pipe until they are drained or the child
+// exits. r is consumed by the first run of the command.
+func WithStdin(r io.Reader) Option {
+	return func(ce *ContextExecutor) {
+		ce.stdin = r
+	}
+}
+
+// WithWorkingDir runs the executed commands from dir, which has to be one of
+// the launcher directories, either as seen from virt-handler or below
+// /proc/<pid>/root. The commands inherit the virt-handler working directory
+// if unset.
+func WithWorkingDir(dir string) Option {
+	return func(ce *ContextExecutor) {
+		ce.workingDir = dir
+	}
+}
+
+// WithEventRecorder makes the executor record a warning event against object,
+// usually the VMI owning the launcher, when the selinux context switch fails.
+func WithEventRecorder(recorder record.EventRecorder, object k8sruntime.Object) Option {
+	return func(ce *ContextExecutor) {
+		ce.recorder = recorder
+		ce.eventObject = object
+	}
+}
+
+// WithVMI adds the identity of the VMI owning the launcher to the executor logs.
+func WithVMI(vmi *v1.VirtualMachineInstance) Option {
+	return func(ce *ContextExecutor) {
+		ce.logger = log.Logger(logComponent).Object(vmi)
+	}
+}
+
+// NewContextExecutor returns an executor running cmd with the SELinux label of
+// the given pid. Failures to resolve a label are returned as *LabelError.
+func NewContextExecutor(pid int, cmd *exec.Cmd, options ...Option) (*ContextExecutor, error) {
+	return NewContextExecutorWithLabelManager(nil, pid, cmd, options...)
+}
+
+// NewContextExecutorWithLabelManager returns an executor like
+// NewContextExecutor, reading and applying all labels through manager. A nil
+// manager keeps the selinux of the host and its process label cache.
+func NewContextExecutorWithLabelManager(manager LabelManager, pid int, cmd *exec.Cmd, options ...Option) (*ContextExecutor, error) {
+	ce := &ContextExecutor{
+		pid:          pid,
+		cmdToExecute: cmd,
+		logger:       log.Logger(logComponent),
+		labelManager: manager,
+		manual:       &manualContext{},
+	}
+	for _, option := range options {
+		option(ce)
+	}
+	if ce.validateArgv {
+		if err := validateCommandArgv(cmd); err != nil {
+			return nil, err
+		}
+	}
+	if ce.checkPIDNamespace {
+		if err := checkSamePIDNamespace(pid); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	if ce.desiredLabel, err = ce.getLauncherLabel(pid); err != nil {
 		return nil, err
 	}
-	originalLabel, err := getLabelForPID(os.Getpid())
-	if err != nil {
+	if ce.originalLabel, err = ce.getLabelForPID(os.Getpid()); err != nil {
+		return nil, err
+	}
+	for _, label := range []string{ce.desiredLabel, ce.originalLabel} {
+		if err := validateLabel(label); err != nil {
+			return nil, err
+		}
+	}
+	if len(ce.sharedMCSPIDs) > 0 {
+		if ce.fileLabel, err = sharedMCSLabel(ce.getLabelForPID, append([]int{pid}, ce.sharedMCSPIDs...)...); err != nil {
+			return nil, err
+		}
+	}
+	if ce.transitionType != "" {
+		if ce.fileLabel == "" {
+			ce.fileLabel = ce.desiredLabel
+		}
+		if ce.desiredLabel, err = transitionLabelType(ce.desiredLabel, ce.transitionType); err != nil {
+			return nil, err
+		}
+	}
+	if err := ce.resolveRestoreLabel(); err != nil {
+		return nil, err
+	}
+	if err := ce.resolveFSCreateLabel(); err != nil {
+		return nil, err
+	}
+	return ce, nil
+}
+
+// NewContextExecutorWithLabel returns an executor running cmd with
+// desiredLabel, for callers knowing the label of the launcher before its
+// process exists. No pid label is looked up besides the one of virt-handler.
+// A desiredLabel carrying the levels of mcstrans is translated into its raw
+// form.
+func NewContextExecutorWithLabel(desiredLabel string, cmd *exec.Cmd) (*ContextExecutor, error) {
+	ce := &ContextExecutor{
+		cmdToExecute: cmd,
+		logger:       log.Logger(logComponent),
+		manual:       &manualContext{},
+	}
+	var err error
+	if ce.desiredLabel, err = ce.normalizeLabel(desiredLabel); err != nil {
+		return nil, err
+	}
+	if err := validateLabel(ce.desiredLabel); err != nil {
+		return nil, err
+	}
+	if ce.originalLabel, err = ce.getLabelForPID(os.Getpid()); err != nil {
 		return nil, err
 	}
-	return &ContextExecutor{
-		pid:           pid,
-		cmdToExecute:  cmd,
-		desiredLabel:  desiredLabel,
-		originalLabel: originalLabel,
-	}, nil
+	if err := validateLabel(ce.originalLabel); err != nil {
+		return nil, err
+	}
+	return ce, nil
+}
+
+// Close releases the handles on the launcher namespaces the executor opened.
+// It is safe to call Close several times, a later Execute opens the handles
+// again.
+func (ce ContextExecutor) Close() error {
+	if ce.nsHandles == nil {
+		return nil
+	}
+	return ce.nsHandles.close()
 }
 
 func (ce ContextExecutor) Execute() error {
-	if isSELinuxEnabled() {
-		if err := ce.setDesiredContext(); err != nil {
-			return err
+	_, _, err := ce.ExecuteWithOutput()
+	return err
+}
+
+// ExecuteContext runs the command like Execute, but kills the child with
+// SIGKILL and returns ctx.Err() once the context is cancelled or its deadline
+// is exceeded.
+func (ce ContextExecutor) ExecuteContext(ctx context.Context) error {
+	_, _, err := ce.execute(ctx)
+	return err
+}
+
+// ExecuteWithOutput runs the command like Execute, capturing its stdout and
+// stderr unless the caller already wired them. The returned buffers are nil
+// for streams not captured by the executor.
+func (ce ContextExecutor) ExecuteWithOutput() (stdout *bytes.Buffer, stderr *bytes.Buffer, err error) {
+	return ce.execute(context.Background())
+}
+
+func (ce ContextExecutor) execute(ctx context.Context) (stdout *bytes.Buffer, stderr *bytes.Buffer, err error) {
+	if ce.auditSink != nil {
+		if ce.exit == nil {
+			ce.exit = &childExit{}
+		}
+		defer ce.auditRun(ce.cmdToExecute, ce.exit, time.Now(), &err)
+	}
+	if ce.dryRun {
+		return nil, nil, ce.logDryRun(ce.cmdToExecute)
+	}
+
+	err = ce.inExecutionContext(func() (err error) {
+		stdout, stderr, err = ce.executeInNamespaces(ctx)
+		return err
+	})
+	return stdout, stderr, err
+}
+
+// inExecutionContext runs f in the thread context the children of the
+// executor are started from: the launcher label if selinux is enabled, and
+// the restricted capabilities, the priority, the scheduling policy and the
+// umask if requested. The launcher type is permissive meanwhile if
+// WithDangerousPermissiveTransition is set.
+func (ce ContextExecutor) inExecutionContext(f func() error) error {
+	if err := ce.checkNotEntered(); err != nil {
+		return err
+	}
+	if !isSELinuxEnabled() {
+		if !ce.restrictsThread() {
+			return f()
+		}
+		return ce.inRestrictedThread(f)
+	}
+	return ce.inPermissiveDomain(func() error {
+		return ce.inDesiredContext(f)
+	})
+}
+
+func (ce ContextExecutor) executeInNamespaces(ctx context.Context) (stdout *bytes.Buffer, stderr *bytes.Buffer, err error) {
+	restoreNamespaces, err := ce.enterNamespaces()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if restoreErr := restoreNamespaces(); restoreErr != nil && err == nil {
+			err = restoreErr
+		}
+	}()
+
+	stdout, stderr, err = ce.run(ctx, ce.cmdToExecute)
+	return stdout, stderr, ce.runPostExecHooks(err)
+}
+
+// checkLauncherExists returns a LauncherExitedError if the launcher pid is
+// gone, since the label read on the creation of the executor is stale then.
+func checkLauncherExists(pid int) error {
+	if _, err := os.Stat(filepath.Join(procRoot, strconv.Itoa(pid))); os.IsNotExist(err) {
+		return &LauncherExitedError{PID: pid}
+	}
+	return nil
+}
+
+// inDesiredContext runs f on a dedicated goroutine, locked to an OS thread
+// switched to the launcher label, and waits for it. If the thread can't be
+// switched back, or isn't meant to be with ResetSkip, the goroutine exits
+// without unlocking it, so that the runtime destroys the thread instead of
+// scheduling other goroutines on it.
+// It refuses to switch the thread if the label of virt-handler is unknown and
+// no restore label is set, since resetting the thread to an empty label could
+// leave it poisoned or running with a weaker label. A failed reset is returned alongside the
+// error of f.
+// It isn't reentrant: called from a thread already switched, e.g. by f or a
+// post-exec hook, it fails with ErrNestedLabelSwitch instead of stacking a
+// second switch whose resets the callers would have to order.
+// A launcher sharing the label of virt-handler doesn't get the thread switched
+// at all, see needsLabelSwitch.
+func (ce ContextExecutor) inDesiredContext(f func() error) error {
+	if ce.getRestoreLabel() == "" {
+		return fmt.Errorf("refusing to switch the selinux exec context to %s for launcher pid %d: the selinux label of virt-handler is unknown and could not be restored", ce.desiredLabel, ce.pid)
+	}
+	if activePID, active := activeLabelSwitch(); active {
+		return fmt.Errorf("%w: refusing to switch to the context of launcher pid %d from the context of launcher pid %d", ErrNestedLabelSwitch, ce.pid, activePID)
+	}
+	if !ce.needsLabelSwitch() {
+		return ce.inSharedContext(f)
+	}
+	var err error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err = checkLauncherExists(ce.pid); err != nil {
+			return
+		}
+		// also covers threads which are destroyed instead of unlocked
+		defer observeThreadLock(time.Now())
+		err = ce.setDesiredContext()
+		// the thread is locked either way, until reset or destroyed
+		defer endLabelSwitch(beginLabelSwitch(ce.pid))
+		if err != nil {
+			// the label of the still locked thread is unknown, let it be destroyed
+			return
+		}
+		if err = ce.setFSCreateContext(); err != nil {
+			// the thread is switched already, let it be destroyed
+			return
+		}
+		if ce.restrictsThread() {
+			// the thread can't get its capabilities, priority or filesystem
+			// attributes back, let it be destroyed instead of resetting it
+			if err = ce.restrictThread(); err == nil {
+				err = f()
+			}
+			return
+		}
+		err = f()
+		if ce.resetMode == ResetSkip {
+			// the goroutine exits with the thread still locked, the runtime
+			// destroys it instead of resetting its label
+			ce.getLogger().V(debugVerbosity).Infof("leaving the OS thread in the selinux context of launcher pid %d to be destroyed", ce.pid)
+			return
+		}
+		if resetErr := ce.resetContext(); resetErr != nil {
+			// never hide that the thread was left in the launcher context
+			if err == nil {
+				err = resetErr
+			} else {
+				err = utilerrors.NewAggregate([]error{err, resetErr})
+			}
+			if errors.Is(resetErr, errPoisonedThread) {
+				ce.getLogger().Reason(resetErr).Errorf("terminating the OS thread left in the selinux context of launcher pid %d", ce.pid)
+				runtime.Goexit()
+			}
+		}
+	}()
+	<-done
+	return err
+}
+
+// run executes cmd in the current thread context, capturing its output
+// unless the caller already wired it. A command which already ran is executed
+// again through a copy of it.
+func (ce ContextExecutor) run(ctx context.Context, cmd *exec.Cmd) (stdout *bytes.Buffer, stderr *bytes.Buffer, err error) {
+	if cmd.Process != nil {
+		cmd = commandCopy(cmd)
+	}
+	if cmd.Stdout == nil {
+		stdout = &bytes.Buffer{}
+		cmd.Stdout = stdout
+		defer func() { cmd.Stdout = nil }()
+	}
+	if cmd.Stderr == nil {
+		stderr = &bytes.Buffer{}
+		cmd.Stderr = stderr
+		defer func() { cmd.Stderr = nil }()
+	}
+	if ce.outputWriter != nil {
+		defer ce.prefixOutput(cmd)()
+	}
+	if ce.ringBuffer != nil {
+		defer ce.teeRingBuffer(cmd)()
+	}
+	if ce.workingDir != "" {
+		if err := ce.validateWorkingDir(); err != nil {
+			return nil, nil, err
+		}
+		cmd.Dir = ce.workingDir
+	}
+	if len(ce.env) > 0 {
+		cmd.Env = append([]string(nil), ce.env...)
+	}
+	if ce.stdin != nil {
+		cmd.Stdin = ce.stdin
+	}
+	if err := ce.applyCredentials(cmd); err != nil {
+		return nil, nil, err
+	}
+	ce.applySession(cmd)
+	ce.applyCapabilities(cmd)
+	restoreExtraFiles, err := ce.applyInheritedFDs(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer restoreExtraFiles()
+	restoreCommand, err := ce.applyShim(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer restoreCommand()
+
+	terminate, stopWatching := ce.watchTermination()
+	defer stopWatching()
+
+	// we want to share the parent process std{in|out|err} - fds 0 through 2.
+	// Since the FDs are inherited on fork / exec, we close on exec all others.
+	fdhygiene.PreventLeakOntoChild()
+	err = ce.runContext(ctx, cmd, terminate)
+	ce.recordExit(cmd)
+	if err != nil {
+		var mismatchErr *ExecLabelMismatchError
+		var staleErr *HeartbeatStaleError
+		if err == ctx.Err() || errors.As(err, &mismatchErr) || errors.As(err, &staleErr) {
+			return stdout, stderr, err
+		}
+		if tail := stderrTail(stderr); tail != "" {
+			return stdout, stderr, fmt.Errorf("failed to execute command in launcher namespace %d: %v, stderr: %q", ce.pid, err, tail)
+		}
+		return stdout, stderr, fmt.Errorf("failed to execute command in launcher namespace %d: %v", ce.pid, err)
+	}
+	return stdout, stderr, nil
+}
+
+// validateWorkingDir checks that the working directory exists and doesn't
+// escape the allowed prefixes. It runs after entering the launcher namespaces,
+// so that the directory is resolved the way the child will see it.
+func (ce ContextExecutor) validateWorkingDir() error {
+	if !filepath.IsAbs(ce.workingDir) {
+		return fmt.Errorf("working directory %s is not an absolute path", ce.workingDir)
+	}
+	dir := filepath.Clean(ce.workingDir)
+	if !ce.isAllowedWorkingDir(dir) {
+		return fmt.Errorf("working directory %s is not within the launcher directories %v", ce.workingDir, workingDirPrefixes)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to access working directory %s: %v", ce.workingDir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("working directory %s is not a directory", ce.workingDir)
+	}
+	return nil
+}
+
+func (ce ContextExecutor) isAllowedWorkingDir(dir string) bool {
+	launcherRoot := filepath.Join("/proc", strconv.Itoa(ce.pid), "root")
+	for _, prefix := range workingDirPrefixes {
+		for _, allowed := range []string{prefix, filepath.Join(launcherRoot, prefix)} {
+			if dir == allowed || strings.HasPrefix(dir, allowed+string(filepath.Separator)) {
+				return true
+			}
 		}
-		defer ce.resetContext()
 	}
+	return false
+}
 
-	preventFDLeakOntoChild()
-	if err := ce.cmdToExecute.Run(); err != nil {
-		return fmt.Errorf("failed to execute command in launcher namespace %d: %v", ce.pid, err)
+func (ce ContextExecutor) logDryRun(cmd *exec.Cmd) error {
+	selinuxEnabled := isSELinuxEnabled()
+	if selinuxEnabled && (ce.desiredLabel == "" || ce.originalLabel == "") {
+		return fmt.Errorf("dry-run: failed to resolve the selinux labels for launcher pid %d: desired %q, original %q", ce.pid, ce.desiredLabel, ce.originalLabel)
 	}
+	ce.getLogger().Infof("dry-run: would execute %q in launcher namespace %d, switching selinux context from %q to %q (selinux enabled: %t)",
+		cmd.Args, ce.pid, ce.originalLabel, ce.desiredLabel, selinuxEnabled)
 	return nil
 }
 
+func (ce ContextExecutor) getLogger() *log.FilteredLogger {
+	if ce.logger == nil {
+		return log.Logger(logComponent)
+	}
+	return ce.logger
+}
+
 func (ce ContextExecutor) setDesiredContext() error {
 	runtime.LockOSThread()
-	if err := selinux.SetExecLabel(ce.desiredLabel); err != nil {
-		return fmt.Errorf("failed to switch selinux context to %s. Reason: %v", ce.desiredLabel, err)
+	ce.getLogger().V(debugVerbosity).Infof("switching the selinux exec context from %s to %s (%s) for launcher pid %d", ce.originalLabel, ce.desiredLabel, diffLabels(ce.originalLabel, ce.desiredLabel), ce.pid)
+	err := ce.setExecLabelWithRetry(ce.desiredLabel)
+	countContextSwitch(err != nil)
+	observeExecLabelSupport(err)
+	if err != nil {
+		ce.getLogger().Reason(err).Errorf("failed to switch the selinux exec context to %s for launcher pid %d", ce.desiredLabel, ce.pid)
+		ce.recordContextSwitchFailure(err)
+		return &ContextSwitchError{Label: ce.desiredLabel, Err: err}
+	}
+	if ce.verifyExecLabel {
+		if err := ce.verifyThreadExecLabel(); err != nil {
+			ce.getLogger().Reason(err).Errorf("the selinux exec context of launcher pid %d could not be verified", ce.pid)
+			return err
+		}
 	}
 	return nil
 }
 
-func (ce ContextExecutor) resetContext() error {
-	defer runtime.UnlockOSThread()
-	return selinux.SetExecLabel(ce.originalLabel)
+// setExecLabelWithRetry sets the exec label, retrying transient syscall
+// failures with a short backoff. Any other failure is returned right away.
+func (ce ContextExecutor) setExecLabelWithRetry(label string) error {
+	var lastErr error
+	err := wait.ExponentialBackoff(setExecLabelBackoff, func() (bool, error) {
+		lastErr = ce.getLabelManager().SetExecLabel(label)
+		if lastErr == nil {
+			return true, nil
+		}
+		if !isTransientSyscallError(lastErr) {
+			return false, lastErr
+		}
+		ce.getLogger().V(debugVerbosity).Reason(lastErr).Infof("transient failure to switch the selinux exec context to %s for launcher pid %d, retrying", label, ce.pid)
+		return false, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return lastErr
+	}
+	return err
 }
 
-func isSELinuxEnabled() bool {
-	_, selinuxEnabled, err := NewSELinux()
-	return err == nil && selinuxEnabled
+func (ce ContextExecutor) recordContextSwitchFailure(err error) {
+	if ce.recorder == nil || ce.eventObject == nil {
+		return
+	}
+	ce.recorder.Eventf(ce.eventObject, k8sv1.EventTypeWarning, SELinuxContextSwitchFailedReason,
+		"Failed to switch the selinux context from %s to %s: %v", ce.originalLabel, ce.desiredLabel, err)
+}
+
+// resetContext switches the thread back to the virt-handler label, or to the
+// one of WithRestoreLabel, clears the file creation context of
+// WithFSCreateLabel, and unlocks it. If that fails, the thread stays
+// locked and errPoisonedThread is returned.
+func (ce ContextExecutor) resetContext() error {
+	restoreLabel := ce.getRestoreLabel()
+	ce.getLogger().V(debugVerbosity).Infof("resetting the selinux exec context to %s after running in launcher pid %d context", restoreLabel, ce.pid)
+	if err := ce.resetFSCreateContext(); err != nil {
+		return fmt.Errorf("%w: %v", errPoisonedThread, err)
+	}
+	if err := ce.getLabelManager().SetExecLabel(restoreLabel); err != nil {
+		return fmt.Errorf("%w: failed to reset the selinux exec context to %s: %v", errPoisonedThread, restoreLabel, err)
+	}
+	runtime.UnlockOSThread()
+	return nil
 }
 
 func getLabelForPID(pid int) (string, error) {
-	fileLabel, err := selinux.FileLabel(fmt.Sprintf("/proc/%d/attr/current", pid))
-	if err != nil {
-		return "", fmt.Errorf("could not retrieve pid %d selinux label: %v", pid, err)
+	return defaultLabelCache.get(pid)
+}
+
+func readLabelForPID(pid int) (string, error) {
+	return readLabelForPIDWith(defaultLabelManager, pid)
+}
+
+func readLabelForPIDWith(manager LabelManager, pid int) (string, error) {
+	return readAttrLabelForPIDWith(manager, pid, LabelAttrCurrent)
+}
+
+// runContext starts the command on the calling - possibly locked - OS thread
+// and waits for it to finish, killing it if the context is done first, or
+// terminating it once a signal is received on terminate.
+func (ce ContextExecutor) runContext(ctx context.Context, cmd *exec.Cmd, terminate <-chan os.Signal) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	waitDone := make(chan error, 1)
+	go func() {
+		waitDone <- cmd.Wait()
+	}()
+	if ce.verifyExecLabel && isSELinuxEnabled() {
+		if err := ce.verifyChildLabel(cmd.Process.Pid); err != nil {
+			ce.signalChild(cmd, syscall.SIGKILL)
+			<-waitDone
+			return err
+		}
+	}
+
+	stale, stopWatching := ce.watchHeartbeat()
+	defer stopWatching()
+
+	select {
+	case err := <-waitDone:
+		return err
+	case <-ctx.Done():
+		ce.signalChild(cmd, syscall.SIGKILL)
+		<-waitDone
+		return ctx.Err()
+	case err := <-stale:
+		ce.getLogger().Reason(err).Warningf("killing the command running in launcher namespace %d", ce.pid)
+		ce.signalChild(cmd, syscall.SIGKILL)
+		<-waitDone
+		return err
+	case sig := <-terminate:
+		return ce.terminate(cmd, sig, waitDone)
 	}
-	return fileLabel, nil
 }
 
-func preventFDLeakOntoChild() {
-	// we want to share the parent process std{in|out|err} - fds 0 through 2.
-	// Since the FDs are inherited on fork / exec, we close on exec all others.
-	for fd := minFDToCloseOnExec; fd < maxFDToCloseOnExec; fd++ {
-		syscall.CloseOnExec(fd)
+// commandCopy returns a command configured like cmd, which has not been
+// started yet.
+func commandCopy(cmd *exec.Cmd) *exec.Cmd {
+	c := &exec.Cmd{
+		Path:       cmd.Path,
+		Args:       cmd.Args,
+		Env:        cmd.Env,
+		Dir:        cmd.Dir,
+		Stdin:      cmd.Stdin,
+		Stdout:     cmd.Stdout,
+		Stderr:     cmd.Stderr,
+		ExtraFiles: cmd.ExtraFiles,
+	}
+	if cmd.SysProcAttr != nil {
+		attr := *cmd.SysProcAttr
+		c.SysProcAttr = &attr
+	}
+	return c
+}
+
+func stderrTail(stderr *bytes.Buffer) string {
+	if stderr == nil {
+		return ""
+	}
+	tail := bytes.TrimSpace(stderr.Bytes())
+	if len(tail) > maxStderrTailBytes {
+		tail = tail[len(tail)-maxStderrTailBytes:]
 	}
+	return string(tail)
 }
@@ -0,0 +1,729 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"golang.org/x/sys/unix"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/client-go/log"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+// fakeProcRoot points procRoot to a temporary directory with entries for the
+// given pids and returns a func restoring it.
+func fakeProcRoot(pids ...int) func() {
+	orgProcRoot := procRoot
+	tempDir, err := ioutil.TempDir("", "kubevirt-proc")
+	ExpectWithOffset(1, err).ToNot(HaveOccurred())
+	for _, pid := range pids {
+		ExpectWithOffset(1, os.Mkdir(filepath.Join(tempDir, strconv.Itoa(pid)), 0755)).To(Succeed())
+	}
+	procRoot = tempDir
+	return func() {
+		procRoot = orgProcRoot
+		os.RemoveAll(tempDir)
+	}
+}
+
+var _ = Describe("ContextExecutor", func() {
+
+	Context("capturing the child output", func() {
+		It("should return stdout and stderr separately", func() {
+			ce := ContextExecutor{
+				pid:          1,
+				cmdToExecute: exec.Command("sh", "-c", "echo out; echo err >&2"),
+			}
+			stdout, stderr, err := ce.ExecuteWithOutput()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout.String()).To(Equal("out\n"))
+			Expect(stderr.String()).To(Equal("err\n"))
+		})
+
+		It("should surface stderr on non-zero exit", func() {
+			ce := ContextExecutor{
+				pid:          1,
+				cmdToExecute: exec.Command("sh", "-c", "echo boom >&2; exit 1"),
+			}
+			err := ce.Execute()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("exit status 1"))
+			Expect(err.Error()).To(ContainSubstring("boom"))
+		})
+
+		It("should only keep the tail of a long stderr in the error", func() {
+			ce := ContextExecutor{
+				pid:          1,
+				cmdToExecute: exec.Command("sh", "-c", "head -c 4096 /dev/zero | tr '\\0' x >&2; echo END >&2; exit 1"),
+			}
+			err := ce.Execute()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("END"))
+			Expect(strings.Count(err.Error(), "x")).To(BeNumerically("<=", maxStderrTailBytes))
+		})
+
+		It("should not capture streams already wired by the caller", func() {
+			out := &bytes.Buffer{}
+			cmd := exec.Command("sh", "-c", "echo out")
+			cmd.Stdout = out
+			ce := ContextExecutor{pid: 1, cmdToExecute: cmd}
+			stdout, stderr, err := ce.ExecuteWithOutput()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout).To(BeNil())
+			Expect(stderr).ToNot(BeNil())
+			Expect(out.String()).To(Equal("out\n"))
+		})
+
+		It("should capture the output of every execution", func() {
+			ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("echo", "out")}
+			for i := 0; i < 2; i++ {
+				stdout, _, err := ce.ExecuteWithOutput()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stdout.String()).To(Equal("out\n"))
+			}
+		})
+	})
+
+	Context("with a context", func() {
+		It("should kill the child once the context deadline is exceeded", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			cmd := exec.Command("sleep", "5")
+			ce := ContextExecutor{pid: 1, cmdToExecute: cmd}
+
+			start := time.Now()
+			err := ce.ExecuteContext(ctx)
+			Expect(err).To(Equal(context.DeadlineExceeded))
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+			Expect(cmd.ProcessState).ToNot(BeNil())
+			Expect(cmd.ProcessState.Sys().(syscall.WaitStatus).Signal()).To(Equal(syscall.SIGKILL))
+		})
+
+		It("should return the command result if it finishes in time", func() {
+			ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("true")}
+			Expect(ce.ExecuteContext(context.Background())).To(Succeed())
+		})
+	})
+
+	Context("with an environment", func() {
+		const hostEnvVar = "KUBEVIRT_SELINUX_TEST_HOST_VAR"
+
+		BeforeEach(func() {
+			Expect(os.Setenv(hostEnvVar, "leaked")).To(Succeed())
+		})
+
+		AfterEach(func() {
+			os.Unsetenv(hostEnvVar)
+		})
+
+		It("should only pass the provided variables to the child", func() {
+			ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("env")}
+			WithEnv([]string{"LIBGUESTFS_PATH=/usr/lib/guestfs"})(&ce)
+			stdout, _, err := ce.ExecuteWithOutput()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout.String()).To(Equal("LIBGUESTFS_PATH=/usr/lib/guestfs\n"))
+		})
+
+		It("should inherit the environment if none is provided", func() {
+			ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("env")}
+			WithEnv(nil)(&ce)
+			stdout, _, err := ce.ExecuteWithOutput()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout.String()).To(ContainSubstring(hostEnvVar + "=leaked"))
+		})
+	})
+
+	Context("with an unknown virt-handler label", func() {
+		var execLabels []string
+
+		BeforeEach(func() {
+			execLabels = nil
+			defaultLabelManager = execLabelFunc(func(label string) error {
+				execLabels = append(execLabels, label)
+				return nil
+			})
+		})
+
+		AfterEach(func() {
+			defaultLabelManager = NewLabelManager()
+			detectSELinux = NewSELinux
+			ResetSELinuxDetectionForTest()
+		})
+
+		detectSELinuxAs := func(enabled bool) {
+			detectSELinux = func() (SELinux, bool, error) {
+				return nil, enabled, nil
+			}
+			ResetSELinuxDetectionForTest()
+		}
+
+		It("should refuse to execute if selinux is enabled", func() {
+			detectSELinuxAs(true)
+			ce := ContextExecutor{pid: 1, desiredLabel: testLauncherLabel, cmdToExecute: exec.Command("true")}
+			err := ce.Execute()
+			Expect(err).To(MatchError(ContainSubstring("the selinux label of virt-handler is unknown")))
+			Expect(execLabels).To(BeEmpty())
+		})
+
+		It("should execute if selinux is disabled", func() {
+			detectSELinuxAs(false)
+			ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("true")}
+			Expect(ce.Execute()).To(Succeed())
+			Expect(execLabels).To(BeEmpty())
+		})
+	})
+
+	Context("with a launcher exiting before the execution", func() {
+		var restoreProcRoot func()
+		var manager *testutils.FakeLabelManager
+
+		BeforeEach(func() {
+			restoreProcRoot = fakeProcRoot(1)
+			manager = testutils.NewFakeLabelManager()
+			manager.SetProcessLabel(1, testLauncherLabel)
+			manager.SetProcessLabel(os.Getpid(), testOriginalLabel)
+			detectSELinux = func() (SELinux, bool, error) {
+				return nil, true, nil
+			}
+			ResetSELinuxDetectionForTest()
+		})
+
+		AfterEach(func() {
+			restoreProcRoot()
+			detectSELinux = NewSELinux
+			ResetSELinuxDetectionForTest()
+		})
+
+		It("should refuse to switch to the stale label", func() {
+			ce, err := NewContextExecutor(1, exec.Command("true"), WithLabelManager(manager))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.Remove(filepath.Join(procRoot, "1"))).To(Succeed())
+
+			err = ce.Execute()
+			Expect(err).To(MatchError("the launcher pid 1 exited before the command could be executed"))
+			Expect(IsLauncherExited(err)).To(BeTrue())
+			Expect(manager.ExecLabels()).To(BeEmpty())
+		})
+
+		It("should execute while the launcher exists", func() {
+			ce, err := NewContextExecutor(1, exec.Command("true"), WithLabelManager(manager))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ce.Execute()).To(Succeed())
+			Expect(manager.ExecLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+		})
+	})
+
+	Context("with stdin", func() {
+		const input = `{"driver":"qcow2","file":{"driver":"file","filename":"/var/run/kubevirt/disk.img"}}`
+
+		It("should feed a reader to the child", func() {
+			ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("cat")}
+			WithStdin(strings.NewReader(input))(&ce)
+			stdout, _, err := ce.ExecuteWithOutput()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout.String()).To(Equal(input))
+		})
+
+		It("should hand a file to the child without closing it in virt-handler", func() {
+			r, w, err := os.Pipe()
+			Expect(err).ToNot(HaveOccurred())
+			defer r.Close()
+			_, err = w.Write([]byte(input))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(w.Close()).To(Succeed())
+
+			ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("cat")}
+			WithStdin(r)(&ce)
+			stdout, _, err := ce.ExecuteWithOutput()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout.String()).To(Equal(input))
+			_, err = r.Stat()
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("with a working directory", func() {
+		var orgWorkingDirPrefixes []string
+		var launcherDir string
+
+		BeforeEach(func() {
+			orgWorkingDirPrefixes = workingDirPrefixes
+			var err error
+			launcherDir, err = ioutil.TempDir("", "kubevirt-ephemeral-disks")
+			Expect(err).ToNot(HaveOccurred())
+			workingDirPrefixes = []string{launcherDir}
+		})
+
+		AfterEach(func() {
+			workingDirPrefixes = orgWorkingDirPrefixes
+			os.RemoveAll(launcherDir)
+		})
+
+		It("should run the child from the working directory", func() {
+			dir := filepath.Join(launcherDir, "disks")
+			Expect(os.Mkdir(dir, 0755)).To(Succeed())
+			ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("pwd")}
+			WithWorkingDir(dir)(&ce)
+
+			stdout, _, err := ce.ExecuteWithOutput()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout.String()).To(Equal(dir + "\n"))
+		})
+
+		It("should inherit the working directory if none is provided", func() {
+			cwd, err := os.Getwd()
+			Expect(err).ToNot(HaveOccurred())
+			ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("pwd")}
+
+			stdout, _, err := ce.ExecuteWithOutput()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout.String()).To(Equal(cwd + "\n"))
+		})
+
+		table.DescribeTable("should fail before running the child", func(dir func() string, expectedError string) {
+			marker := filepath.Join(launcherDir, "marker")
+			ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("touch", marker)}
+			WithWorkingDir(dir())(&ce)
+
+			Expect(ce.Execute()).To(MatchError(ContainSubstring(expectedError)))
+			_, err := os.Stat(marker)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		},
+			table.Entry("if the directory doesn't exist", func() string {
+				return filepath.Join(launcherDir, "missing")
+			}, "failed to access working directory"),
+			table.Entry("if the path is not a directory", func() string {
+				file := filepath.Join(launcherDir, "file")
+				Expect(ioutil.WriteFile(file, nil, 0644)).To(Succeed())
+				return file
+			}, "is not a directory"),
+			table.Entry("if the directory is outside of the launcher directories", func() string {
+				return os.TempDir()
+			}, "is not within the launcher directories"),
+			table.Entry("if the directory escapes the launcher directories", func() string {
+				return launcherDir + "/../"
+			}, "is not within the launcher directories"),
+			table.Entry("if the directory only shares a name prefix with a launcher directory", func() string {
+				return launcherDir + "-private"
+			}, "is not within the launcher directories"),
+			table.Entry("if the path is relative", func() string {
+				return "disks"
+			}, "is not an absolute path"),
+		)
+
+		It("should accept launcher directories below the launcher root", func() {
+			ce := ContextExecutor{pid: 1234}
+			Expect(ce.isAllowedWorkingDir(filepath.Join("/proc/1234/root", launcherDir, "disks"))).To(BeTrue())
+			Expect(ce.isAllowedWorkingDir(filepath.Join("/proc/4321/root", launcherDir, "disks"))).To(BeFalse())
+		})
+	})
+
+	Context("in dry-run mode", func() {
+		It("should neither run the command nor fail", func() {
+			marker := filepath.Join(os.TempDir(), fmt.Sprintf("kubevirt-dry-run-%d", os.Getpid()))
+			defer os.Remove(marker)
+			ce, err := NewContextExecutor(os.Getpid(), exec.Command("touch", marker), WithDryRun())
+			if err != nil {
+				Skip("the selinux label of the test process can't be resolved: " + err.Error())
+			}
+
+			Expect(ce.Execute()).To(Succeed())
+			_, err = os.Stat(marker)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("should not capture any output", func() {
+			ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("echo", "out"), dryRun: true}
+			stdout, stderr, err := ce.ExecuteWithOutput()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout).To(BeNil())
+			Expect(stderr).To(BeNil())
+		})
+	})
+
+	Context("detecting selinux", func() {
+		var detections int
+
+		BeforeEach(func() {
+			detections = 0
+			detectSELinux = func() (SELinux, bool, error) {
+				detections++
+				return nil, false, nil
+			}
+			ResetSELinuxDetectionForTest()
+		})
+
+		AfterEach(func() {
+			detectSELinux = NewSELinux
+			ResetSELinuxDetectionForTest()
+		})
+
+		It("should only happen once across many executions", func() {
+			for i := 0; i < 5; i++ {
+				ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("true")}
+				Expect(ce.Execute()).To(Succeed())
+			}
+			Expect(detections).To(Equal(1))
+		})
+
+		It("should happen again once reset", func() {
+			Expect(isSELinuxEnabled()).To(BeFalse())
+			ResetSELinuxDetectionForTest()
+			Expect(isSELinuxEnabled()).To(BeFalse())
+			Expect(detections).To(Equal(2))
+		})
+	})
+
+	Context("with an event recorder", func() {
+		var recorder *record.FakeRecorder
+		var vmi *v1.VirtualMachineInstance
+
+		BeforeEach(func() {
+			recorder = record.NewFakeRecorder(10)
+			vmi = v1.NewMinimalVMI("testvmi")
+		})
+
+		AfterEach(func() {
+			defaultLabelManager = NewLabelManager()
+		})
+
+		It("should record a warning event when the context switch fails", func() {
+			defaultLabelManager = execLabelFunc(func(label string) error {
+				return syscall.EACCES
+			})
+			ce := ContextExecutor{desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+			WithEventRecorder(recorder, vmi)(&ce)
+
+			Expect(ce.setDesiredContext()).ToNot(Succeed())
+			ce.resetContext()
+			Expect(recorder.Events).To(HaveLen(1))
+			event := <-recorder.Events
+			Expect(event).To(ContainSubstring(SELinuxContextSwitchFailedReason))
+			Expect(event).To(ContainSubstring(testLauncherLabel))
+			Expect(event).To(ContainSubstring(testOriginalLabel))
+		})
+
+		It("should not record any event when the context switch succeeds", func() {
+			defaultLabelManager = execLabelFunc(func(label string) error {
+				return nil
+			})
+			ce := ContextExecutor{desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+			WithEventRecorder(recorder, vmi)(&ce)
+
+			Expect(ce.setDesiredContext()).To(Succeed())
+			Expect(ce.resetContext()).To(Succeed())
+			Expect(recorder.Events).To(BeEmpty())
+		})
+	})
+
+	Context("with transient context switch failures", func() {
+		var orgBackoff wait.Backoff
+		var attempts int
+
+		failingSetExecLabel := func(failures int, err error) func(string) error {
+			return func(label string) error {
+				attempts++
+				if attempts <= failures {
+					return &os.PathError{Op: "write", Path: "/proc/thread-self/attr/exec", Err: err}
+				}
+				return nil
+			}
+		}
+
+		BeforeEach(func() {
+			attempts = 0
+			orgBackoff = setExecLabelBackoff
+			setExecLabelBackoff = wait.Backoff{Steps: 3, Duration: time.Millisecond, Factor: 1.0}
+		})
+
+		AfterEach(func() {
+			setExecLabelBackoff = orgBackoff
+			defaultLabelManager = NewLabelManager()
+		})
+
+		It("should succeed if the context switch fails twice then succeeds", func() {
+			defaultLabelManager = execLabelFunc(failingSetExecLabel(2, syscall.EAGAIN))
+			ce := ContextExecutor{desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+
+			Expect(ce.setDesiredContext()).To(Succeed())
+			ce.resetContext()
+			Expect(attempts).To(Equal(4))
+		})
+
+		It("should give up after the bounded number of attempts", func() {
+			defaultLabelManager = execLabelFunc(failingSetExecLabel(3, syscall.EINTR))
+			ce := ContextExecutor{desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+
+			err := ce.setDesiredContext()
+			ce.resetContext()
+			Expect(err).To(BeAssignableToTypeOf(&ContextSwitchError{}))
+			Expect(errors.Is(err, syscall.EINTR)).To(BeTrue())
+			Expect(attempts).To(Equal(4))
+		})
+
+		table.DescribeTable("should not retry", func(err error) {
+			defaultLabelManager = execLabelFunc(failingSetExecLabel(1, err))
+			ce := ContextExecutor{desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+
+			Expect(ce.setDesiredContext()).ToNot(Succeed())
+			ce.resetContext()
+			Expect(attempts).To(Equal(2))
+		},
+			table.Entry("permission errors", syscall.EACCES),
+			table.Entry("malformed label errors", syscall.EINVAL),
+		)
+	})
+
+	Context("when the context can't be reset", func() {
+		var threadLabels map[int]string
+		var failingResets int
+		var poisonedTIDs []int
+		var mislabeledRuns int
+
+		BeforeEach(func() {
+			threadLabels = map[int]string{}
+			failingResets = 0
+			poisonedTIDs = nil
+			mislabeledRuns = 0
+			defaultLabelManager = execLabelFunc(func(label string) error {
+				tid := unix.Gettid()
+				if label == testOriginalLabel && failingResets > 0 {
+					failingResets--
+					poisonedTIDs = append(poisonedTIDs, tid)
+					return syscall.EACCES
+				}
+				if label == testLauncherLabel && threadLabels[tid] == testLauncherLabel {
+					mislabeledRuns++
+				}
+				threadLabels[tid] = label
+				return nil
+			})
+			detectSELinux = func() (SELinux, bool, error) {
+				return nil, true, nil
+			}
+			ResetSELinuxDetectionForTest()
+		})
+
+		AfterEach(func() {
+			defaultLabelManager = NewLabelManager()
+			detectSELinux = NewSELinux
+			ResetSELinuxDetectionForTest()
+		})
+
+		taskExists := func(tid int) func() bool {
+			return func() bool {
+				_, err := os.Stat(fmt.Sprintf("/proc/self/task/%d", tid))
+				return err == nil
+			}
+		}
+
+		It("should still return the command result", func() {
+			failingResets = 1
+			ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("sh", "-c", "echo out"), desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+
+			stdout, _, err := ce.ExecuteWithOutput()
+			Expect(errors.Is(err, errPoisonedThread)).To(BeTrue())
+			Expect(stdout.String()).To(Equal("out\n"))
+		})
+
+		It("should return the reset error after a successful command", func() {
+			failingResets = 1
+			ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("true"), desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+
+			err := ce.Execute()
+			Expect(errors.Is(err, errPoisonedThread)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring(testOriginalLabel)))
+		})
+
+		It("should return both the command and the reset error after a failed command", func() {
+			failingResets = 1
+			ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("false"), desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+
+			err := ce.Execute()
+			Expect(errors.Is(err, errPoisonedThread)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("exit status 1")))
+		})
+
+		It("should return the reset error of a batch of commands", func() {
+			failingResets = 1
+			ce := ContextExecutor{pid: 1, desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+
+			results, err := ce.ExecuteMany([]*exec.Cmd{exec.Command("true"), exec.Command("true")}, FailSoft)
+			Expect(errors.Is(err, errPoisonedThread)).To(BeTrue())
+			Expect(results).To(HaveLen(2))
+		})
+
+		It("should destroy the poisoned thread instead of reusing it", func() {
+			failingResets = 3
+			failures := 0
+			for i := 0; i < 10; i++ {
+				ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("true"), desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+				if err := ce.Execute(); err != nil {
+					Expect(errors.Is(err, errPoisonedThread)).To(BeTrue())
+					failures++
+				}
+			}
+
+			Expect(failures).To(Equal(3))
+			Expect(poisonedTIDs).To(HaveLen(3))
+			Expect(mislabeledRuns).To(BeZero())
+			for _, tid := range poisonedTIDs {
+				Eventually(taskExists(tid)).Should(BeFalse())
+			}
+		})
+
+		It("should report the thread as poisoned", func() {
+			failingResets = 1
+			ce := ContextExecutor{desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+
+			errs := make(chan error, 1)
+			go func() {
+				Expect(ce.setDesiredContext()).To(Succeed())
+				errs <- ce.resetContext()
+			}()
+			err := <-errs
+			Expect(errors.Is(err, errPoisonedThread)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring(testOriginalLabel)))
+		})
+	})
+
+	Context("with an explicit label", func() {
+		var orgLabelCache *labelCache
+		var lookedUpPIDs []int
+
+		BeforeEach(func() {
+			orgLabelCache = defaultLabelCache
+			lookedUpPIDs = nil
+			defaultLabelCache = newLabelCache(func(pid int) (uint64, error) {
+				return 1, nil
+			}, func(pid int) (string, error) {
+				lookedUpPIDs = append(lookedUpPIDs, pid)
+				if pid != os.Getpid() {
+					return "", newLabelError(pid, syscall.ENOENT)
+				}
+				return testOriginalLabel, nil
+			})
+		})
+
+		AfterEach(func() {
+			defaultLabelCache = orgLabelCache
+		})
+
+		It("should use the explicit label without looking up a launcher pid", func() {
+			ce, err := NewContextExecutorWithLabel(testLauncherLabel, exec.Command("true"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ce.desiredLabel).To(Equal(testLauncherLabel))
+			Expect(ce.originalLabel).To(Equal(testOriginalLabel))
+			Expect(lookedUpPIDs).To(Equal([]int{os.Getpid()}))
+		})
+
+		table.DescribeTable("should reject a malformed label", func(label string) {
+			_, err := NewContextExecutorWithLabel(label, exec.Command("true"))
+			Expect(err).To(MatchError(ContainSubstring("malformed selinux label")))
+			Expect(lookedUpPIDs).To(BeEmpty())
+		},
+			table.Entry("without type", "system_u:system_r"),
+			table.Entry("with a level without sensitivity", "system_u:system_r:container_t:c1"),
+		)
+
+		It("should fail when the label of virt-handler can't be read", func() {
+			defaultLabelCache = newLabelCache(func(pid int) (uint64, error) {
+				return 1, nil
+			}, func(pid int) (string, error) {
+				return "", newLabelError(pid, syscall.EACCES)
+			})
+			_, err := NewContextExecutorWithLabel(testLauncherLabel, exec.Command("true"))
+			Expect(err).To(BeAssignableToTypeOf(&LabelError{}))
+		})
+	})
+
+	Context("logging", func() {
+		var logs *bytes.Buffer
+
+		BeforeEach(func() {
+			logs = &bytes.Buffer{}
+			log.Logger(logComponent).SetIOWriter(logs)
+			defaultLabelManager = execLabelFunc(func(label string) error {
+				return nil
+			})
+		})
+
+		AfterEach(func() {
+			log.Logger(logComponent).SetIOWriter(GinkgoWriter)
+			log.Logger(logComponent).SetVerbosityLevel(2)
+			defaultLabelManager = NewLabelManager()
+		})
+
+		It("should trace the context switches with the selinux component and the VMI identity", func() {
+			log.Logger(logComponent).SetVerbosityLevel(debugVerbosity)
+			vmi := v1.NewMinimalVMIWithNS("testns", "testvmi")
+			ce := ContextExecutor{pid: 1234, desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+			WithVMI(vmi)(&ce)
+
+			Expect(ce.setDesiredContext()).To(Succeed())
+			Expect(ce.resetContext()).To(Succeed())
+
+			lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+			for _, line := range lines {
+				entry := map[string]interface{}{}
+				Expect(json.Unmarshal([]byte(line), &entry)).To(Succeed())
+				Expect(entry["component"]).To(Equal("selinux"))
+				Expect(entry["name"]).To(Equal("testvmi"))
+				Expect(entry["namespace"]).To(Equal("testns"))
+				Expect(entry["msg"]).To(ContainSubstring("1234"))
+			}
+			Expect(lines[0]).To(ContainSubstring(testLauncherLabel))
+			entry := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(lines[0]), &entry)).To(Succeed())
+			Expect(entry["msg"]).To(ContainSubstring("(role object_r -> system_r, type tmp_t -> container_t, level s0 -> s0:c1,c2)"))
+			Expect(lines[1]).To(ContainSubstring(testOriginalLabel))
+		})
+
+		It("should not trace the context switches at the default verbosity", func() {
+			ce := ContextExecutor{pid: 1234, desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+
+			Expect(ce.setDesiredContext()).To(Succeed())
+			Expect(ce.resetContext()).To(Succeed())
+			Expect(logs.String()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,88 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	capSetGID = 6
+	capSetUID = 7
+
+	procSelfStatus = "/proc/self/status"
+)
+
+var effectiveCapabilities = readEffectiveCapabilities
+
+// WithCredentials runs the executed commands as uid and gid, with the given
+// supplementary groups, e.g. so that the files they create are owned by the
+// qemu user of the launcher. The credentials are dropped in the child, before
+// it executes the command in the launcher selinux context.
+func WithCredentials(uid, gid uint32, groups []uint32) Option {
+	return func(ce *ContextExecutor) {
+		ce.credential = &syscall.Credential{
+			Uid:    uid,
+			Gid:    gid,
+			Groups: append([]uint32{}, groups...),
+		}
+	}
+}
+
+// applyCredentials makes cmd run with the credentials of the executor, if
+// virt-handler is privileged enough to switch to them.
+func (ce ContextExecutor) applyCredentials(cmd *exec.Cmd) error {
+	if ce.credential == nil {
+		return nil
+	}
+	caps, err := effectiveCapabilities()
+	if err != nil {
+		return fmt.Errorf("failed to check the privileges to run as uid %d and gid %d: %v", ce.credential.Uid, ce.credential.Gid, err)
+	}
+	if caps&(1<<capSetUID) == 0 || caps&(1<<capSetGID) == 0 {
+		return fmt.Errorf("running commands as uid %d and gid %d requires the CAP_SETUID and CAP_SETGID capabilities", ce.credential.Uid, ce.credential.Gid)
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	credential := *ce.credential
+	cmd.SysProcAttr.Credential = &credential
+	return nil
+}
+
+// readEffectiveCapabilities returns the effective capability set of the
+// process, as reported by /proc/self/status.
+func readEffectiveCapabilities() (uint64, error) {
+	status, err := ioutil.ReadFile(procSelfStatus)
+	if err != nil {
+		return 0, err
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		if strings.HasPrefix(line, "CapEff:") {
+			return strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "CapEff:")), 16, 64)
+		}
+	}
+	return 0, fmt.Errorf("no effective capabilities found in %s", procSelfStatus)
+}
@@ -0,0 +1,138 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+var _ = Describe("Running commands with credentials", func() {
+	const (
+		qemuUID = 107
+		qemuGID = 107
+		kvmGID  = 36
+	)
+
+	var tempDir string
+
+	BeforeEach(func() {
+		caps, err := readEffectiveCapabilities()
+		if err != nil || caps&(1<<capSetUID) == 0 || caps&(1<<capSetGID) == 0 {
+			Skip("switching credentials requires CAP_SETUID and CAP_SETGID")
+		}
+		tempDir, err = ioutil.TempDir("", "kubevirt-credentials")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.Chmod(tempDir, 0777)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("should create the child artifacts with the given owner", func() {
+		disk := filepath.Join(tempDir, "disk.img")
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("touch", disk)}
+		WithCredentials(qemuUID, qemuGID, nil)(&ce)
+
+		Expect(ce.Execute()).To(Succeed())
+		info, err := os.Stat(disk)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Sys().(*syscall.Stat_t).Uid).To(BeEquivalentTo(qemuUID))
+		Expect(info.Sys().(*syscall.Stat_t).Gid).To(BeEquivalentTo(qemuGID))
+	})
+
+	It("should only give the child the given supplementary groups", func() {
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("id", "-G")}
+		WithCredentials(qemuUID, qemuGID, []uint32{kvmGID})(&ce)
+
+		stdout, _, err := ce.ExecuteWithOutput()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stdout.String()).To(Equal("107 36\n"))
+	})
+
+	It("should keep the selinux context switch around the child", func() {
+		manager := testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(1, testLauncherLabel)
+		manager.SetProcessLabel(os.Getpid(), testOriginalLabel)
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, true, nil
+		}
+		ResetSELinuxDetectionForTest()
+		defer func() {
+			detectSELinux = NewSELinux
+			ResetSELinuxDetectionForTest()
+		}()
+
+		disk := filepath.Join(tempDir, "disk.img")
+		ce, err := NewContextExecutor(1, exec.Command("touch", disk), WithLabelManager(manager), WithCredentials(qemuUID, qemuGID, nil))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ce.Execute()).To(Succeed())
+		Expect(manager.ExecLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+		info, err := os.Stat(disk)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Sys().(*syscall.Stat_t).Uid).To(BeEquivalentTo(qemuUID))
+	})
+})
+
+var _ = Describe("Credentials privileges", func() {
+	var orgEffectiveCapabilities = effectiveCapabilities
+
+	AfterEach(func() {
+		effectiveCapabilities = orgEffectiveCapabilities
+	})
+
+	It("should fail before running the child without CAP_SETUID", func() {
+		effectiveCapabilities = func() (uint64, error) {
+			return 1 << capSetGID, nil
+		}
+		marker := filepath.Join(os.TempDir(), "kubevirt-credentials-marker")
+		defer os.Remove(marker)
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("touch", marker)}
+		WithCredentials(107, 107, nil)(&ce)
+
+		Expect(ce.Execute()).To(MatchError("running commands as uid 107 and gid 107 requires the CAP_SETUID and CAP_SETGID capabilities"))
+		_, err := os.Stat(marker)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("should not check the privileges without credentials", func() {
+		effectiveCapabilities = func() (uint64, error) {
+			Fail("the capabilities should not be checked")
+			return 0, nil
+		}
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("true")}
+		Expect(ce.Execute()).To(Succeed())
+	})
+
+	It("should read the effective capabilities of the process", func() {
+		_, err := readEffectiveCapabilities()
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
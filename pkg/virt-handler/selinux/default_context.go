@@ -0,0 +1,109 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContextLookup returns the label the selinux policy defines for a
+// path, the one restorecon would apply to it.
+type DefaultContextLookup interface {
+	DefaultFileContext(path string) (string, error)
+}
+
+// matchpathconLookup looks the default contexts up with matchpathcon, in the
+// mount namespace of the host so that the policy of the host is used.
+type matchpathconLookup struct {
+	execFunc execFunc
+}
+
+func (l matchpathconLookup) DefaultFileContext(path string) (string, error) {
+	out, err := l.execFunc("/usr/bin/virt-chroot", "--mount", "/proc/1/ns/mnt", "exec", "--", "/usr/sbin/matchpathcon", "-n", path)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the default selinux context of %s: %v: %s", path, err, strings.TrimSpace(string(out)))
+	}
+	label := strings.TrimSpace(string(out))
+	if label == "" || label == "<<none>>" {
+		return "", fmt.Errorf("the selinux policy defines no default context for %s", path)
+	}
+	if err := validateLabel(label); err != nil {
+		return "", fmt.Errorf("invalid default selinux context for %s: %v", path, err)
+	}
+	return label, nil
+}
+
+// NewDefaultContextLookup returns the DefaultContextLookup backed by the
+// selinux policy of the host.
+func NewDefaultContextLookup() DefaultContextLookup {
+	return matchpathconLookup{execFunc: defaultExecFunc}
+}
+
+// WithDefaultContextLookup makes RestoreDefaultFileLabels take the default
+// contexts from lookup instead of the policy of the host.
+func WithDefaultContextLookup(lookup DefaultContextLookup) Option {
+	return func(ce *ContextExecutor) {
+		ce.defaultContextLookup = lookup
+	}
+}
+
+func (ce ContextExecutor) getDefaultContextLookup() DefaultContextLookup {
+	if ce.defaultContextLookup == nil {
+		return NewDefaultContextLookup()
+	}
+	return ce.defaultContextLookup
+}
+
+// RestoreDefaultFileLabels resets each of the given paths, as seen from the
+// host, to the label the policy defines for it instead of the launcher label,
+// e.g. to hand a device back to the host once unplugged. It returns the paths
+// which did not carry their default label.
+func (ce ContextExecutor) RestoreDefaultFileLabels(paths ...string) (restored []string, err error) {
+	lookup := ce.getDefaultContextLookup()
+	results := ce.forEachPath(len(paths), func(manager fileLabelManager, i int) relabelResult {
+		path := paths[i]
+		if result := ce.guardFilesystem(path); result != nil {
+			return *result
+		}
+		defaultLabel, err := lookup.DefaultFileContext(path)
+		if err != nil {
+			return relabelResult{err: err}
+		}
+		currentLabel, err := manager.FileLabel(path)
+		if err != nil {
+			return relabelResult{err: fmt.Errorf("failed to retrieve the selinux label of %s: %v", path, err)}
+		}
+		if currentLabel == defaultLabel {
+			return relabelResult{previousLabel: currentLabel}
+		}
+		if err := manager.SetFileLabel(path, defaultLabel); err != nil {
+			return relabelResult{previousLabel: currentLabel, err: fmt.Errorf("failed to restore the default label %s of %s: %v", defaultLabel, path, err)}
+		}
+		ce.getLogger().V(debugVerbosity).Infof("restored %s from %s to its default label %s", path, currentLabel, defaultLabel)
+		return relabelResult{previousLabel: currentLabel, relabeled: true}
+	})
+	for i, result := range results {
+		if result.relabeled {
+			restored = append(restored, paths[i])
+		}
+	}
+	return restored, aggregateRelabelErrors(results)
+}
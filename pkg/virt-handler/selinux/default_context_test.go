@@ -0,0 +1,141 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+const (
+	testDeviceDefaultLabel = "system_u:object_r:device_t:s0"
+	testVFIODefaultLabel   = "system_u:object_r:vfio_device_t:s0"
+)
+
+// fakePolicy returns the label of the first pattern matching the path, like
+// the file contexts of a policy.
+type fakePolicy struct {
+	patterns []string
+	labels   map[string]string
+}
+
+func (p fakePolicy) DefaultFileContext(path string) (string, error) {
+	for _, pattern := range p.patterns {
+		if matched, _ := filepath.Match(pattern, path); matched {
+			return p.labels[pattern], nil
+		}
+	}
+	return "", fmt.Errorf("the selinux policy defines no default context for %s", path)
+}
+
+var _ = Describe("Restoring the default file labels", func() {
+
+	var manager *testutils.FakeLabelManager
+	var ce ContextExecutor
+
+	BeforeEach(func() {
+		manager = testutils.NewFakeLabelManager()
+		policy := fakePolicy{
+			patterns: []string{"/dev/vfio/*", "/dev/*"},
+			labels: map[string]string{
+				"/dev/vfio/*": testVFIODefaultLabel,
+				"/dev/*":      testDeviceDefaultLabel,
+			},
+		}
+		ce = ContextExecutor{desiredLabel: testLauncherLabel}
+		WithLabelManager(manager)(&ce)
+		WithDefaultContextLookup(policy)(&ce)
+	})
+
+	It("should apply the default label of the policy instead of the launcher label", func() {
+		Expect(manager.SetFileLabel("/dev/vfio/42", testLauncherLabel)).To(Succeed())
+		Expect(manager.SetFileLabel("/dev/sdb", testLauncherLabel)).To(Succeed())
+
+		restored, err := ce.RestoreDefaultFileLabels("/dev/vfio/42", "/dev/sdb")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restored).To(Equal([]string{"/dev/vfio/42", "/dev/sdb"}))
+		Expect(manager.FileLabel("/dev/vfio/42")).To(Equal(testVFIODefaultLabel))
+		Expect(manager.FileLabel("/dev/sdb")).To(Equal(testDeviceDefaultLabel))
+	})
+
+	It("should leave the paths already carrying their default label alone", func() {
+		Expect(manager.SetFileLabel("/dev/vfio/42", testVFIODefaultLabel)).To(Succeed())
+		Expect(manager.SetFileLabel("/dev/sdb", testLauncherLabel)).To(Succeed())
+
+		restored, err := ce.RestoreDefaultFileLabels("/dev/vfio/42", "/dev/sdb")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restored).To(Equal([]string{"/dev/sdb"}))
+	})
+
+	It("should restore the other paths if the policy has no default for one of them", func() {
+		Expect(manager.SetFileLabel("/var/run/kubevirt/disk.img", testLauncherLabel)).To(Succeed())
+		Expect(manager.SetFileLabel("/dev/sdb", testLauncherLabel)).To(Succeed())
+
+		restored, err := ce.RestoreDefaultFileLabels("/var/run/kubevirt/disk.img", "/dev/sdb")
+		Expect(err).To(MatchError(ContainSubstring("no default context for /var/run/kubevirt/disk.img")))
+		Expect(restored).To(Equal([]string{"/dev/sdb"}))
+		Expect(manager.FileLabel("/var/run/kubevirt/disk.img")).To(Equal(testLauncherLabel))
+	})
+
+	It("should report the paths whose label cannot be read", func() {
+		restored, err := ce.RestoreDefaultFileLabels("/dev/missing")
+		Expect(err).To(MatchError(ContainSubstring("failed to retrieve the selinux label of /dev/missing")))
+		Expect(restored).To(BeEmpty())
+	})
+
+	Context("with matchpathcon", func() {
+		lookupReturning := func(out string, err error) (matchpathconLookup, *[]string) {
+			var args []string
+			return matchpathconLookup{execFunc: func(binary string, a ...string) ([]byte, error) {
+				args = append([]string{binary}, a...)
+				return []byte(out), err
+			}}, &args
+		}
+
+		It("should query the policy of the host", func() {
+			lookup, args := lookupReturning(testDeviceDefaultLabel+"\n", nil)
+			Expect(lookup.DefaultFileContext("/dev/sdb")).To(Equal(testDeviceDefaultLabel))
+			Expect(*args).To(Equal([]string{"/usr/bin/virt-chroot", "--mount", "/proc/1/ns/mnt", "exec", "--", "/usr/sbin/matchpathcon", "-n", "/dev/sdb"}))
+		})
+
+		It("should fail if the policy defines no context for the path", func() {
+			lookup, _ := lookupReturning("<<none>>\n", nil)
+			_, err := lookup.DefaultFileContext("/dev/sdb")
+			Expect(err).To(MatchError(ContainSubstring("no default context for /dev/sdb")))
+		})
+
+		It("should fail on unexpected output", func() {
+			lookup, _ := lookupReturning("garbage", nil)
+			_, err := lookup.DefaultFileContext("/dev/sdb")
+			Expect(err).To(MatchError(ContainSubstring("invalid default selinux context for /dev/sdb")))
+		})
+
+		It("should report the output of a failed matchpathcon", func() {
+			lookup, _ := lookupReturning("matchpathcon: not found", fmt.Errorf("exit status 1"))
+			_, err := lookup.DefaultFileContext("/dev/sdb")
+			Expect(err).To(MatchError(ContainSubstring("exit status 1: matchpathcon: not found")))
+		})
+	})
+})
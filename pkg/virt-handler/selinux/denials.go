@@ -0,0 +1,289 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/client-go/log"
+)
+
+const (
+	defaultDenialPollInterval = 2 * time.Second
+	// defaultDenialAnnotationInterval is the minimal time between two
+	// annotations of the same VMI, later denials are kept until it elapsed
+	defaultDenialAnnotationInterval = time.Minute
+)
+
+var auditTimestampRegex = regexp.MustCompile(`audit\((\d+)\.(\d+):\d+\)`)
+
+// AVCDenial is an access vector cache denial, as logged by the kernel in the
+// audit log.
+type AVCDenial struct {
+	Timestamp   time.Time
+	Permissions []string
+	Comm        string
+	Name        string
+	SContext    string
+	TContext    string
+	TClass      string
+}
+
+// ParseAVCDenial parses an audit log line, reporting false if the line is
+// not an AVC denial.
+func ParseAVCDenial(line string) (*AVCDenial, bool) {
+	avc := strings.Index(line, "avc:")
+	if avc < 0 {
+		return nil, false
+	}
+	rest := line[avc+len("avc:"):]
+	if !strings.HasPrefix(strings.TrimSpace(rest), "denied") {
+		return nil, false
+	}
+	start, end := strings.Index(rest, "{"), strings.Index(rest, "}")
+	if start < 0 || end < start {
+		return nil, false
+	}
+
+	denial := &AVCDenial{Permissions: strings.Fields(rest[start+1 : end])}
+	if match := auditTimestampRegex.FindStringSubmatch(line); match != nil {
+		secs, _ := strconv.ParseInt(match[1], 10, 64)
+		millis, _ := strconv.ParseInt(match[2], 10, 64)
+		denial.Timestamp = time.Unix(secs, millis*int64(time.Millisecond)).UTC()
+	}
+	for _, field := range strings.Fields(rest[end+1:]) {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value := strings.Trim(kv[1], `"`)
+		switch kv[0] {
+		case "comm":
+			denial.Comm = value
+		case "name":
+			denial.Name = value
+		case "scontext":
+			denial.SContext = value
+		case "tcontext":
+			denial.TContext = value
+		case "tclass":
+			denial.TClass = value
+		}
+	}
+	if denial.SContext == "" || denial.TContext == "" || denial.TClass == "" {
+		return nil, false
+	}
+	return denial, true
+}
+
+// Summary returns a one line description of the denial.
+func (d *AVCDenial) Summary() string {
+	summary := fmt.Sprintf("denied { %s } for comm=%q", strings.Join(d.Permissions, " "), d.Comm)
+	if d.Name != "" {
+		summary += fmt.Sprintf(" name=%q", d.Name)
+	}
+	summary += fmt.Sprintf(" scontext=%s tcontext=%s tclass=%s", d.SContext, d.TContext, d.TClass)
+	if !d.Timestamp.IsZero() {
+		summary = d.Timestamp.Format(time.RFC3339) + ": " + summary
+	}
+	return summary
+}
+
+// LauncherLookup returns the VMIs of the launchers running on the node, keyed
+// by the selinux label of the launchers.
+type LauncherLookup func() (map[string]*v1.VirtualMachineInstance, error)
+
+// DenialAnnotator records the summary of the last denial of a launcher on its
+// VMI.
+type DenialAnnotator func(vmi *v1.VirtualMachineInstance, summary string) error
+
+type pendingDenial struct {
+	vmi     *v1.VirtualMachineInstance
+	summary string
+}
+
+// DenialWatcher tails the audit log for the AVC denials of launchers and
+// annotates their VMI with the last one, at most once per annotation interval.
+type DenialWatcher struct {
+	auditLogPath       string
+	lookup             LauncherLookup
+	annotate           DenialAnnotator
+	pollInterval       time.Duration
+	annotationInterval time.Duration
+	now                func() time.Time
+
+	auditLog *os.File
+	offset   int64
+	partial  string
+
+	lastAnnotated map[types.UID]time.Time
+	pending       map[types.UID]pendingDenial
+}
+
+func NewDenialWatcher(auditLogPath string, lookup LauncherLookup, annotate DenialAnnotator) *DenialWatcher {
+	return &DenialWatcher{
+		auditLogPath:       auditLogPath,
+		lookup:             lookup,
+		annotate:           annotate,
+		pollInterval:       defaultDenialPollInterval,
+		annotationInterval: defaultDenialAnnotationInterval,
+		now:                time.Now,
+		lastAnnotated:      map[types.UID]time.Time{},
+		pending:            map[types.UID]pendingDenial{},
+	}
+}
+
+// Run watches the denials logged from now on until stop is closed.
+func (w *DenialWatcher) Run(stop <-chan struct{}) {
+	logger := log.Logger(logComponent)
+	if err := w.openAuditLog(true); err != nil {
+		logger.Reason(err).Warningf("failed to open the audit log %s, retrying", w.auditLogPath)
+	}
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+	defer w.closeAuditLog()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			lines, err := w.readLines()
+			if err != nil {
+				logger.Reason(err).Warningf("failed to read the audit log %s", w.auditLogPath)
+			}
+			w.handleLines(lines)
+		}
+	}
+}
+
+// handleLines remembers the last denial of every launcher found in lines and
+// annotates the VMIs whose annotation interval elapsed.
+func (w *DenialWatcher) handleLines(lines []string) {
+	var denials []*AVCDenial
+	for _, line := range lines {
+		if denial, ok := ParseAVCDenial(line); ok {
+			denials = append(denials, denial)
+		}
+	}
+
+	if len(denials) > 0 {
+		launchers, err := w.lookup()
+		if err != nil {
+			log.Logger(logComponent).Reason(err).Warning("failed to look up the launchers of the denials")
+		}
+		for _, denial := range denials {
+			if vmi, exists := launchers[denial.SContext]; exists {
+				w.pending[vmi.UID] = pendingDenial{vmi: vmi, summary: denial.Summary()}
+			}
+		}
+	}
+	w.flush()
+}
+
+func (w *DenialWatcher) flush() {
+	now := w.now()
+	for uid, last := range w.lastAnnotated {
+		if _, exists := w.pending[uid]; !exists && now.Sub(last) >= w.annotationInterval {
+			delete(w.lastAnnotated, uid)
+		}
+	}
+	for uid, denial := range w.pending {
+		if last, exists := w.lastAnnotated[uid]; exists && now.Sub(last) < w.annotationInterval {
+			continue
+		}
+		if err := w.annotate(denial.vmi, denial.summary); err != nil {
+			log.Logger(logComponent).Object(denial.vmi).Reason(err).Warning("failed to annotate the VMI with its last selinux denial")
+			continue
+		}
+		w.lastAnnotated[uid] = now
+		delete(w.pending, uid)
+	}
+}
+
+// openAuditLog opens the audit log, at its end if atEnd is set so that
+// the denials logged before virt-handler started are skipped.
+func (w *DenialWatcher) openAuditLog(atEnd bool) error {
+	f, err := os.Open(w.auditLogPath)
+	if err != nil {
+		return err
+	}
+	w.offset = 0
+	if atEnd {
+		if w.offset, err = f.Seek(0, io.SeekEnd); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	w.auditLog = f
+	w.partial = ""
+	return nil
+}
+
+func (w *DenialWatcher) closeAuditLog() {
+	if w.auditLog != nil {
+		w.auditLog.Close()
+		w.auditLog = nil
+	}
+}
+
+// readLines returns the complete lines appended to the audit log since the
+// last call. The log is read again from its start once it got rotated or
+// truncated.
+func (w *DenialWatcher) readLines() ([]string, error) {
+	info, err := os.Stat(w.auditLogPath)
+	if err != nil {
+		return nil, err
+	}
+	if w.auditLog != nil {
+		current, err := w.auditLog.Stat()
+		if err != nil || !os.SameFile(current, info) || info.Size() < w.offset {
+			w.closeAuditLog()
+		}
+	}
+	if w.auditLog == nil {
+		if err := w.openAuditLog(false); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := w.auditLog.Seek(w.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(w.auditLog)
+	if err != nil {
+		return nil, err
+	}
+	w.offset += int64(len(data))
+
+	lines := strings.Split(w.partial+string(data), "\n")
+	w.partial = lines[len(lines)-1]
+	return lines[:len(lines)-1], nil
+}
@@ -0,0 +1,231 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "kubevirt.io/client-go/api/v1"
+)
+
+const (
+	launcherLabel = "system_u:system_r:container_t:s0:c1,c2"
+	otherLabel    = "system_u:system_r:container_t:s0:c3,c4"
+)
+
+func avcLine(label string, serial int) string {
+	return fmt.Sprintf(`type=AVC msg=audit(1610000000.250:%d): avc:  denied  { read write } for  pid=1234 comm="qemu-kvm" name="disk.img" dev="sda1" ino=42 scontext=%s tcontext=system_u:object_r:container_file_t:s0:c5,c6 tclass=file permissive=0`, serial, label)
+}
+
+var _ = Describe("SELinux denials", func() {
+
+	Context("parsing audit lines", func() {
+		It("should parse an AVC denial", func() {
+			denial, ok := ParseAVCDenial(avcLine(launcherLabel, 1))
+			Expect(ok).To(BeTrue())
+			Expect(*denial).To(Equal(AVCDenial{
+				Timestamp:   time.Unix(1610000000, 250*int64(time.Millisecond)).UTC(),
+				Permissions: []string{"read", "write"},
+				Comm:        "qemu-kvm",
+				Name:        "disk.img",
+				SContext:    launcherLabel,
+				TContext:    "system_u:object_r:container_file_t:s0:c5,c6",
+				TClass:      "file",
+			}))
+			Expect(denial.Summary()).To(Equal(`2021-01-07T06:13:20Z: denied { read write } for comm="qemu-kvm" name="disk.img" ` +
+				"scontext=system_u:system_r:container_t:s0:c1,c2 tcontext=system_u:object_r:container_file_t:s0:c5,c6 tclass=file"))
+		})
+
+		It("should parse a denial forwarded to the kernel log", func() {
+			denial, ok := ParseAVCDenial(`audit: type=1400 audit(1610000000.250:7): avc:  denied  { open } for  pid=1 comm="virt-launcher" scontext=` + launcherLabel + ` tcontext=system_u:object_r:kvm_device_t:s0 tclass=chr_file permissive=1`)
+			Expect(ok).To(BeTrue())
+			Expect(denial.Permissions).To(Equal([]string{"open"}))
+			Expect(denial.Summary()).ToNot(ContainSubstring("name="))
+		})
+
+		table.DescribeTable("should ignore", func(line string) {
+			_, ok := ParseAVCDenial(line)
+			Expect(ok).To(BeFalse())
+		},
+			table.Entry("non AVC records", `type=SYSCALL msg=audit(1610000000.250:1): arch=c000003e syscall=257 success=no exit=-13 comm="qemu-kvm"`),
+			table.Entry("granted accesses", `type=AVC msg=audit(1610000000.250:1): avc:  granted  { setsecparam } for  pid=1 comm="load_policy" scontext=a tcontext=b tclass=security`),
+			table.Entry("denials without contexts", `type=AVC msg=audit(1610000000.250:1): avc:  denied  { read } for  pid=1 comm="qemu-kvm"`),
+			table.Entry("empty lines", ""),
+		)
+	})
+
+	Context("watching the audit log", func() {
+		var tempDir, auditLog string
+		var watcher *DenialWatcher
+		var now time.Time
+		var launchers map[string]*v1.VirtualMachineInstance
+		var lookups int
+		var annotations map[string][]string
+		var annotateErr error
+
+		appendLines := func(lines ...string) {
+			f, err := os.OpenFile(auditLog, os.O_APPEND|os.O_WRONLY, 0644)
+			Expect(err).ToNot(HaveOccurred())
+			defer f.Close()
+			for _, line := range lines {
+				_, err = f.WriteString(line + "\n")
+				Expect(err).ToNot(HaveOccurred())
+			}
+		}
+
+		poll := func() {
+			lines, err := watcher.readLines()
+			Expect(err).ToNot(HaveOccurred())
+			watcher.handleLines(lines)
+		}
+
+		BeforeEach(func() {
+			var err error
+			tempDir, err = ioutil.TempDir("", "audit")
+			Expect(err).ToNot(HaveOccurred())
+			auditLog = filepath.Join(tempDir, "audit.log")
+			Expect(ioutil.WriteFile(auditLog, []byte(avcLine(launcherLabel, 0)+"\n"), 0644)).To(Succeed())
+
+			now = time.Now()
+			launchers = map[string]*v1.VirtualMachineInstance{
+				launcherLabel: {ObjectMeta: metav1.ObjectMeta{Name: "testvmi", UID: "1234"}},
+			}
+			lookups = 0
+			annotations = map[string][]string{}
+			annotateErr = nil
+
+			watcher = NewDenialWatcher(auditLog,
+				func() (map[string]*v1.VirtualMachineInstance, error) {
+					lookups++
+					return launchers, nil
+				},
+				func(vmi *v1.VirtualMachineInstance, summary string) error {
+					if annotateErr != nil {
+						return annotateErr
+					}
+					annotations[vmi.Name] = append(annotations[vmi.Name], summary)
+					return nil
+				})
+			watcher.now = func() time.Time { return now }
+			Expect(watcher.openAuditLog(true)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			watcher.closeAuditLog()
+			os.RemoveAll(tempDir)
+		})
+
+		It("should skip the denials logged before it started", func() {
+			poll()
+			Expect(annotations).To(BeEmpty())
+			Expect(lookups).To(BeZero())
+		})
+
+		It("should annotate the VMI of the launcher with its last denial", func() {
+			appendLines(avcLine(launcherLabel, 1), avcLine(otherLabel, 2), avcLine(launcherLabel, 3))
+			poll()
+			Expect(lookups).To(Equal(1))
+			Expect(annotations).To(HaveLen(1))
+			Expect(annotations["testvmi"]).To(HaveLen(1))
+			Expect(annotations["testvmi"][0]).To(ContainSubstring(`comm="qemu-kvm"`))
+		})
+
+		It("should only handle complete lines", func() {
+			line := avcLine(launcherLabel, 1)
+			f, err := os.OpenFile(auditLog, os.O_APPEND|os.O_WRONLY, 0644)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = f.WriteString(line[:40])
+			Expect(err).ToNot(HaveOccurred())
+			poll()
+			Expect(annotations).To(BeEmpty())
+
+			_, err = f.WriteString(line[40:] + "\n")
+			Expect(err).ToNot(HaveOccurred())
+			f.Close()
+			poll()
+			Expect(annotations["testvmi"]).To(HaveLen(1))
+		})
+
+		It("should rate limit the annotations of a VMI", func() {
+			appendLines(avcLine(launcherLabel, 1))
+			poll()
+			Expect(annotations["testvmi"]).To(HaveLen(1))
+
+			now = now.Add(10 * time.Second)
+			appendLines(avcLine(launcherLabel, 2))
+			poll()
+			Expect(annotations["testvmi"]).To(HaveLen(1))
+
+			By("annotating the last denial once the interval elapsed")
+			now = now.Add(defaultDenialAnnotationInterval)
+			poll()
+			Expect(annotations["testvmi"]).To(HaveLen(2))
+			Expect(lookups).To(Equal(2))
+		})
+
+		It("should retry failed annotations", func() {
+			annotateErr = fmt.Errorf("conflict")
+			appendLines(avcLine(launcherLabel, 1))
+			poll()
+			Expect(annotations).To(BeEmpty())
+
+			annotateErr = nil
+			poll()
+			Expect(annotations["testvmi"]).To(HaveLen(1))
+		})
+
+		It("should follow a rotated audit log", func() {
+			Expect(os.Rename(auditLog, auditLog+".1")).To(Succeed())
+			Expect(ioutil.WriteFile(auditLog, []byte(avcLine(launcherLabel, 1)+"\n"), 0644)).To(Succeed())
+			poll()
+			Expect(annotations["testvmi"]).To(HaveLen(1))
+		})
+
+		It("should follow a truncated audit log", func() {
+			Expect(ioutil.WriteFile(auditLog, nil, 0644)).To(Succeed())
+			poll()
+			appendLines(avcLine(launcherLabel, 1))
+			poll()
+			Expect(annotations["testvmi"]).To(HaveLen(1))
+		})
+
+		It("should stop when asked to", func() {
+			watcher.closeAuditLog()
+			watcher.pollInterval = 10 * time.Millisecond
+			stop := make(chan struct{})
+			done := make(chan struct{})
+			go func() {
+				watcher.Run(stop)
+				close(done)
+			}()
+			close(stop)
+			Eventually(done).Should(BeClosed())
+		})
+	})
+})
@@ -0,0 +1,382 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+type LabelErrorKind int
+
+const (
+	// PIDNotFound means the process is gone, e.g. the VMI pod already terminated.
+	PIDNotFound LabelErrorKind = iota
+	// ProcNotReadable means the label exists but could not be read, e.g. permission denied.
+	ProcNotReadable
+	// SELinuxUnavailable means the host does not expose SELinux labels.
+	SELinuxUnavailable
+)
+
+func (k LabelErrorKind) String() string {
+	switch k {
+	case PIDNotFound:
+		return "PIDNotFound"
+	case ProcNotReadable:
+		return "ProcNotReadable"
+	case SELinuxUnavailable:
+		return "SELinuxUnavailable"
+	}
+	return fmt.Sprintf("LabelErrorKind(%d)", int(k))
+}
+
+// LabelError is returned when the SELinux label of a process can't be retrieved.
+type LabelError struct {
+	PID  int
+	Kind LabelErrorKind
+	Err  error
+}
+
+func (e *LabelError) Error() string {
+	return fmt.Sprintf("could not retrieve pid %d selinux label (%s): %v", e.PID, e.Kind, e.Err)
+}
+
+func (e *LabelError) Unwrap() error {
+	return e.Err
+}
+
+func newLabelError(pid int, err error) *LabelError {
+	return &LabelError{
+		PID:  pid,
+		Kind: labelErrorKindFor(err),
+		Err:  err,
+	}
+}
+
+func labelErrorKindFor(err error) LabelErrorKind {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return ProcNotReadable
+	}
+	switch errno {
+	case syscall.ENOENT, syscall.ESRCH:
+		return PIDNotFound
+	case syscall.EOPNOTSUPP, syscall.ENODATA:
+		return SELinuxUnavailable
+	}
+	return ProcNotReadable
+}
+
+// isTransientSyscallError reports whether err is a syscall failure worth
+// retrying, as opposed to e.g. a permission or malformed label error.
+func isTransientSyscallError(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	switch errno {
+	case syscall.EAGAIN, syscall.EINTR, syscall.EBUSY:
+		return true
+	}
+	return false
+}
+
+// IsLabelErrorKind reports whether err is a LabelError of the given kind.
+func IsLabelErrorKind(err error, kind LabelErrorKind) bool {
+	var labelErr *LabelError
+	return errors.As(err, &labelErr) && labelErr.Kind == kind
+}
+
+// errSELinuxDisabled is wrapped by the errors of lookups skipped because
+// selinux is not enabled on the node.
+var errSELinuxDisabled = errors.New("selinux is not enabled on the node")
+
+// errPoisonedThread is returned when a thread can't be switched back from the
+// launcher selinux context. Such a thread must never be reused.
+var errPoisonedThread = errors.New("the OS thread is poisoned")
+
+// ErrNestedLabelSwitch is returned by the executors run from a thread already
+// switched to the context of a launcher, e.g. from a post-exec hook: the
+// executors aren't reentrant.
+var ErrNestedLabelSwitch = errors.New("the OS thread is already switched to the selinux context of a launcher")
+
+// ErrManualContext is returned while the context of an executor is entered
+// with Enter: by a second Enter, and by the executions of the executor.
+var ErrManualContext = errors.New("the selinux context of the launcher is entered manually")
+
+// ErrNotEntered is returned by Leave if the context of the executor wasn't
+// entered by the calling thread.
+var ErrNotEntered = errors.New("the selinux context of the launcher was not entered")
+
+// ErrUnboundedLabel is wrapped by the ContextSwitchError of a thread the
+// kernel refused to switch to the launcher label with EPERM: the threads of a
+// multithreaded process can only switch to a label bounded by their own, see
+// RunInContext.
+var ErrUnboundedLabel = errors.New("the launcher label is not bounded by the selinux label of virt-handler")
+
+// ContextSwitchError is returned when the thread can't be switched to the
+// selinux context of the launcher.
+type ContextSwitchError struct {
+	Label string
+	Err   error
+}
+
+func (e *ContextSwitchError) Error() string {
+	return fmt.Sprintf("failed to switch selinux context to %s. Reason: %v", e.Label, e.Err)
+}
+
+func (e *ContextSwitchError) Unwrap() error {
+	return e.Err
+}
+
+// CircuitOpenError is returned by the CircuitBreaker for the VMIs it stopped
+// retrying, it wraps the last selinux failure of the VMI.
+type CircuitOpenError struct {
+	Failures   int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("stopped retrying after %d consecutive selinux failures: %v", e.Failures, e.Err)
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return e.Err
+}
+
+// ExecTransitionBlockedError is returned by CheckExecTransition when the
+// policy does not let virt-handler switch its exec label to the launcher type.
+type ExecTransitionBlockedError struct {
+	From string
+	To   string
+	Err  error
+}
+
+func (e *ExecTransitionBlockedError) Error() string {
+	return fmt.Sprintf("the selinux policy does not allow virt-handler to switch its exec context from %s to %s: %v", e.From, e.To, e.Err)
+}
+
+func (e *ExecTransitionBlockedError) Unwrap() error {
+	return e.Err
+}
+
+// FileNotCreatedError is returned by ExecuteAndWaitForFile when the command
+// succeeded, but the file signaling its completion did not show up in time.
+type FileNotCreatedError struct {
+	Path    string
+	Timeout time.Duration
+}
+
+func (e *FileNotCreatedError) Error() string {
+	return fmt.Sprintf("the command succeeded but %s did not appear within %v", e.Path, e.Timeout)
+}
+
+// ExecuteTimeoutError is returned by ExecuteWithTimeoutKillGroup when the
+// command did not finish in time and its process group was killed.
+type ExecuteTimeoutError struct {
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *ExecuteTimeoutError) Error() string {
+	return fmt.Sprintf("the command did not finish within %v, its process group was killed: %v", e.Timeout, e.Err)
+}
+
+func (e *ExecuteTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// ExecLabelMismatchError is returned when the label read back from the thread
+// or the child differs from the launcher label, e.g. because the policy
+// silently ignored the exec label.
+type ExecLabelMismatchError struct {
+	Expected string
+	Actual   string
+	// Source tells where the label was read from
+	Source string
+}
+
+func (e *ExecLabelMismatchError) Error() string {
+	return fmt.Sprintf("selinux label mismatch on the %s: expected %s, got %s", e.Source, e.Expected, e.Actual)
+}
+
+// ContextSwitchIneffectiveError is returned when SetExecLabel succeeded, but
+// the exec label read back from the thread is not the launcher label, e.g. on
+// kernels whose lockdown or selinux namespace turn the write into a no-op.
+// It wraps the mismatch of the labels.
+type ContextSwitchIneffectiveError struct {
+	Label    string
+	Mismatch *ExecLabelMismatchError
+}
+
+func (e *ContextSwitchIneffectiveError) Error() string {
+	return fmt.Sprintf("the selinux context switch to %s was ineffective: the exec label of the thread is %q", e.Label, e.Mismatch.Actual)
+}
+
+func (e *ContextSwitchIneffectiveError) Unwrap() error {
+	return e.Mismatch
+}
+
+// HeartbeatStaleError is returned when the child was killed because it did
+// not update its heartbeat file within the window given to WithHeartbeat.
+type HeartbeatStaleError struct {
+	Path   string
+	Window time.Duration
+	// LastBeat is the last modification of the file, or the start of the
+	// child if it never beat
+	LastBeat time.Time
+}
+
+func (e *HeartbeatStaleError) Error() string {
+	return fmt.Sprintf("the heartbeat file %s was not updated for more than %v, last beat at %s", e.Path, e.Window, e.LastBeat.Format(time.RFC3339))
+}
+
+// LauncherExitedError is returned when the launcher process exited between
+// the creation of the executor and the switch to its selinux context. Callers
+// can skip the command, since there is nothing left to run it for.
+type LauncherExitedError struct {
+	PID int
+}
+
+func (e *LauncherExitedError) Error() string {
+	return fmt.Sprintf("the launcher pid %d exited before the command could be executed", e.PID)
+}
+
+// IsLauncherExited reports whether err is a LauncherExitedError.
+func IsLauncherExited(err error) bool {
+	var exitedErr *LauncherExitedError
+	return errors.As(err, &exitedErr)
+}
+
+// PIDNamespaceMismatchError is returned when the pid given to the executor
+// belongs to another pid namespace than the one of virt-handler. Such a pid
+// was most likely read inside a container and names another process, or no
+// process at all, in the pid namespace of virt-handler.
+type PIDNamespaceMismatchError struct {
+	PID int
+	// Namespace is the pid namespace of the process, e.g. pid:[4026532448]
+	Namespace string
+	// Expected is the pid namespace of virt-handler
+	Expected string
+}
+
+func (e *PIDNamespaceMismatchError) Error() string {
+	return fmt.Sprintf("pid %d is in the pid namespace %s instead of %s of virt-handler: translate it into the pid namespace of virt-handler first, e.g. with the NSpid field of /proc/<pid>/status", e.PID, e.Namespace, e.Expected)
+}
+
+// IsPIDNamespaceMismatch reports whether err is a PIDNamespaceMismatchError.
+func IsPIDNamespaceMismatch(err error) bool {
+	var mismatchErr *PIDNamespaceMismatchError
+	return errors.As(err, &mismatchErr)
+}
+
+// UnsafeArgumentError is returned for an argument which is unsafe to pass to
+// a command, see ValidateArgv.
+type UnsafeArgumentError struct {
+	Arg    string
+	Reason string
+}
+
+func (e *UnsafeArgumentError) Error() string {
+	return fmt.Sprintf("unsafe argument %q: %s", e.Arg, e.Reason)
+}
+
+// ChildSignaledError is returned by ExecuteWithExitCode when the child was
+// terminated by a signal instead of exiting.
+type ChildSignaledError struct {
+	Signal syscall.Signal
+	Err    error
+}
+
+func (e *ChildSignaledError) Error() string {
+	return fmt.Sprintf("the child was terminated by signal %d (%v): %v", int(e.Signal), e.Signal, e.Err)
+}
+
+func (e *ChildSignaledError) Unwrap() error {
+	return e.Err
+}
+
+type FilesystemErrorKind int
+
+const (
+	// ReadOnlyFilesystem means the path is on a read-only mount.
+	ReadOnlyFilesystem FilesystemErrorKind = iota
+	// XattrsUnsupported means the filesystem can't store selinux labels, e.g.
+	// a network filesystem without labeling support.
+	XattrsUnsupported
+)
+
+func (k FilesystemErrorKind) String() string {
+	switch k {
+	case ReadOnlyFilesystem:
+		return "ReadOnlyFilesystem"
+	case XattrsUnsupported:
+		return "XattrsUnsupported"
+	}
+	return fmt.Sprintf("FilesystemErrorKind(%d)", int(k))
+}
+
+// FilesystemRelabelError is returned by the relabel helpers for the paths
+// living on a filesystem the labels can't be applied to.
+type FilesystemRelabelError struct {
+	Path       string
+	Filesystem string
+	Kind       FilesystemErrorKind
+}
+
+func (e *FilesystemRelabelError) Error() string {
+	switch e.Kind {
+	case ReadOnlyFilesystem:
+		return fmt.Sprintf("cannot relabel %s: its %s filesystem is mounted read-only", e.Path, e.Filesystem)
+	case XattrsUnsupported:
+		return fmt.Sprintf("cannot relabel %s: its %s filesystem does not support selinux labels", e.Path, e.Filesystem)
+	}
+	return fmt.Sprintf("cannot relabel %s on its %s filesystem (%s)", e.Path, e.Filesystem, e.Kind)
+}
+
+// IsFilesystemRelabelErrorKind reports whether err is a FilesystemRelabelError
+// of the given kind. For the aggregated errors of the relabel helpers, it
+// reports whether any of the paths failed with one.
+func IsFilesystemRelabelErrorKind(err error, kind FilesystemErrorKind) bool {
+	if aggregate, ok := err.(utilerrors.Aggregate); ok {
+		for _, err := range aggregate.Errors() {
+			if IsFilesystemRelabelErrorKind(err, kind) {
+				return true
+			}
+		}
+		return false
+	}
+	var fsErr *FilesystemRelabelError
+	return errors.As(err, &fsErr) && fsErr.Kind == kind
+}
+
+// IsSELinuxError reports whether err was caused by a failure to resolve or
+// apply a selinux label.
+func IsSELinuxError(err error) bool {
+	var labelErr *LabelError
+	var switchErr *ContextSwitchError
+	var mismatchErr *ExecLabelMismatchError
+	return errors.As(err, &labelErr) || errors.As(err, &switchErr) || errors.As(err, &mismatchErr)
+}
@@ -0,0 +1,73 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LabelError", func() {
+
+	table.DescribeTable("should classify the underlying error", func(err error, expectedKind LabelErrorKind) {
+		labelErr := newLabelError(1234, err)
+		Expect(labelErr.Kind).To(Equal(expectedKind))
+		Expect(labelErr.PID).To(Equal(1234))
+		Expect(IsLabelErrorKind(labelErr, expectedKind)).To(BeTrue())
+	},
+		table.Entry("missing proc entry", syscall.ENOENT, PIDNotFound),
+		table.Entry("no such process", syscall.ESRCH, PIDNotFound),
+		table.Entry("xattrs not supported", syscall.EOPNOTSUPP, SELinuxUnavailable),
+		table.Entry("no selinux xattr", syscall.ENODATA, SELinuxUnavailable),
+		table.Entry("permission denied", syscall.EACCES, ProcNotReadable),
+		table.Entry("wrapped errno", fmt.Errorf("lgetxattr: %w", syscall.ENOENT), PIDNotFound),
+		table.Entry("non errno error", errors.New("unexpected"), ProcNotReadable),
+	)
+
+	It("should be retrievable with errors.As when wrapped", func() {
+		err := fmt.Errorf("failed to build executor: %w", newLabelError(42, syscall.ESRCH))
+
+		var labelErr *LabelError
+		Expect(errors.As(err, &labelErr)).To(BeTrue())
+		Expect(labelErr.PID).To(Equal(42))
+		Expect(labelErr.Kind).To(Equal(PIDNotFound))
+		Expect(errors.Is(err, syscall.ESRCH)).To(BeTrue())
+		Expect(IsLabelErrorKind(err, ProcNotReadable)).To(BeFalse())
+	})
+
+	It("should report the pid and kind in its message", func() {
+		Expect(newLabelError(7, syscall.EACCES).Error()).To(Equal("could not retrieve pid 7 selinux label (ProcNotReadable): permission denied"))
+	})
+
+	table.DescribeTable("should tell selinux errors apart", func(err error, expected bool) {
+		Expect(IsSELinuxError(err)).To(Equal(expected))
+	},
+		table.Entry("label error", newLabelError(1, syscall.EACCES), true),
+		table.Entry("wrapped context switch error", fmt.Errorf("tap device: %w", &ContextSwitchError{Label: "l", Err: syscall.EACCES}), true),
+		table.Entry("exec label mismatch", &ExecLabelMismatchError{Expected: "a", Actual: "b", Source: "child pid 1"}, true),
+		table.Entry("unrelated error", errors.New("unrelated"), false),
+		table.Entry("nil", nil, false),
+	)
+})
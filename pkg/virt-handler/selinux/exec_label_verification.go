@@ -0,0 +1,81 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+)
+
+// ExecLabelReader is implemented by the label managers able to read back the
+// exec label of the calling thread.
+type ExecLabelReader interface {
+	ExecLabel() (string, error)
+}
+
+// WithExecLabelVerification makes the executor confirm that the launcher label
+// was actually applied, instead of trusting SetExecLabel. The exec label of
+// the thread is read back before the child is started, and the label of the
+// child is read right after it started, unless it already exited. A thread
+// which didn't get the launcher label, e.g. because kernel lockdown turned
+// SetExecLabel into a silent no-op, fails the execution with a
+// *ContextSwitchIneffectiveError before the child is started. A child with
+// another label fails it with an *ExecLabelMismatchError, the child is then
+// killed.
+func WithExecLabelVerification() Option {
+	return func(ce *ContextExecutor) {
+		ce.verifyExecLabel = true
+	}
+}
+
+// verifyThreadExecLabel reads back the exec label of the calling thread and
+// compares it with the launcher label.
+func (ce ContextExecutor) verifyThreadExecLabel() error {
+	reader, ok := ce.getLabelManager().(ExecLabelReader)
+	if !ok {
+		return fmt.Errorf("the selinux label manager can't read back the exec label of the thread")
+	}
+	label, err := reader.ExecLabel()
+	if err != nil {
+		return fmt.Errorf("failed to read back the selinux exec label of the thread: %v", err)
+	}
+	if label != ce.desiredLabel {
+		countIneffectiveContextSwitch()
+		return &ContextSwitchIneffectiveError{
+			Label:    ce.desiredLabel,
+			Mismatch: &ExecLabelMismatchError{Expected: ce.desiredLabel, Actual: label, Source: "exec label of the thread"},
+		}
+	}
+	return nil
+}
+
+// verifyChildLabel compares the label of the started child with the launcher
+// label. A child whose label can't be read anymore, e.g. because it already
+// exited, is left unverified.
+func (ce ContextExecutor) verifyChildLabel(pid int) error {
+	label, err := readLabelForPIDWith(ce.getLabelManager(), pid)
+	if err != nil {
+		ce.getLogger().V(debugVerbosity).Reason(err).Infof("could not verify the selinux label of child pid %d", pid)
+		return nil
+	}
+	if label != ce.desiredLabel {
+		return &ExecLabelMismatchError{Expected: ce.desiredLabel, Actual: label, Source: fmt.Sprintf("child pid %d", pid)}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+// childLabelManager reports childLabel for the processes unknown to the fake
+// label manager, i.e. the children started by the executor.
+type childLabelManager struct {
+	*testutils.FakeLabelManager
+	childLabel string
+}
+
+func (m childLabelManager) FileLabel(path string) (string, error) {
+	label, err := m.FakeLabelManager.FileLabel(path)
+	if err != nil && filepath.Base(path) == "current" {
+		return m.childLabel, nil
+	}
+	return label, err
+}
+
+// writeOnlyLabelManager hides the ExecLabel method of the fake label manager.
+type writeOnlyLabelManager struct {
+	LabelManager
+}
+
+var _ = Describe("Exec label verification", func() {
+	const launcherPID = 1234
+
+	var manager *testutils.FakeLabelManager
+	var restoreProcRoot func()
+	var dir, marker string
+
+	BeforeEach(func() {
+		restoreProcRoot = fakeProcRoot(launcherPID)
+		manager = testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(launcherPID, testLauncherLabel)
+		manager.SetProcessLabel(os.Getpid(), testOriginalLabel)
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, true, nil
+		}
+		ResetSELinuxDetectionForTest()
+
+		var err error
+		dir, err = ioutil.TempDir("", "kubevirt-exec-label")
+		Expect(err).ToNot(HaveOccurred())
+		marker = filepath.Join(dir, "marker")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+		restoreProcRoot()
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+	})
+
+	expectMismatch := func(err error, actual string) *ExecLabelMismatchError {
+		var mismatchErr *ExecLabelMismatchError
+		ExpectWithOffset(1, errors.As(err, &mismatchErr)).To(BeTrue(), fmt.Sprintf("unexpected error: %v", err))
+		ExpectWithOffset(1, mismatchErr.Expected).To(Equal(testLauncherLabel))
+		ExpectWithOffset(1, mismatchErr.Actual).To(Equal(actual))
+		ExpectWithOffset(1, IsSELinuxError(err)).To(BeTrue())
+		return mismatchErr
+	}
+
+	It("should run the command once the exec label was read back", func() {
+		ce, err := NewContextExecutor(launcherPID, exec.Command("touch", marker), WithLabelManager(manager), WithExecLabelVerification())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ce.Execute()).To(Succeed())
+		Expect(marker).To(BeAnExistingFile())
+		Expect(manager.ExecLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+	})
+
+	It("should not read back the exec label unless asked to", func() {
+		manager.ReportExecLabel(testOriginalLabel)
+		ce, err := NewContextExecutor(launcherPID, exec.Command("touch", marker), WithLabelManager(manager))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ce.Execute()).To(Succeed())
+		Expect(marker).To(BeAnExistingFile())
+	})
+
+	It("should not run the command if the thread exec label differs", func() {
+		manager.ReportExecLabel(testOriginalLabel)
+		ce, err := NewContextExecutor(launcherPID, exec.Command("touch", marker), WithLabelManager(manager), WithExecLabelVerification())
+		Expect(err).ToNot(HaveOccurred())
+
+		err = ce.Execute()
+		mismatchErr := expectMismatch(err, testOriginalLabel)
+		Expect(mismatchErr.Source).To(Equal("exec label of the thread"))
+		Expect(marker).ToNot(BeAnExistingFile())
+
+		var ineffectiveErr *ContextSwitchIneffectiveError
+		Expect(errors.As(err, &ineffectiveErr)).To(BeTrue())
+		Expect(ineffectiveErr.Label).To(Equal(testLauncherLabel))
+		Expect(err).To(MatchError(ContainSubstring("context switch to " + testLauncherLabel + " was ineffective")))
+	})
+
+	It("should count the context switches which silently didn't take effect", func() {
+		const nodeName = "testnode"
+		registry := prometheus.NewRegistry()
+		Expect(RegisterMetrics(registry, nodeName)).To(Succeed())
+		ineffective := func() float64 {
+			families, err := registry.Gather()
+			Expect(err).ToNot(HaveOccurred())
+			for _, family := range families {
+				if family.GetName() != "kubevirt_selinux_context_switch_ineffective_total" {
+					continue
+				}
+				for _, metric := range family.GetMetric() {
+					if hasNodeLabel(metric, nodeName) {
+						return metric.GetCounter().GetValue()
+					}
+				}
+			}
+			return 0
+		}
+		before := ineffective()
+
+		ce, err := NewContextExecutor(launcherPID, exec.Command("touch", marker), WithLabelManager(manager), WithExecLabelVerification())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ce.Execute()).To(Succeed())
+		Expect(ineffective()).To(Equal(before))
+
+		manager.ReportExecLabel(testOriginalLabel)
+		Expect(ce.Execute()).ToNot(Succeed())
+		Expect(ineffective()).To(Equal(before + 1))
+	})
+
+	It("should fail if the label manager can't read back the exec label", func() {
+		ce, err := NewContextExecutor(launcherPID, exec.Command("touch", marker), WithLabelManager(writeOnlyLabelManager{manager}), WithExecLabelVerification())
+		Expect(err).ToNot(HaveOccurred())
+
+		err = ce.Execute()
+		Expect(err).To(MatchError(ContainSubstring("can't read back the exec label")))
+		Expect(marker).ToNot(BeAnExistingFile())
+	})
+
+	It("should kill a child running with another label", func() {
+		const childLabel = "system_u:system_r:unconfined_t:s0"
+		ce, err := NewContextExecutor(launcherPID, exec.Command("sleep", "30"), WithLabelManager(childLabelManager{manager, childLabel}), WithExecLabelVerification())
+		Expect(err).ToNot(HaveOccurred())
+
+		start := time.Now()
+		err = ce.Execute()
+		Expect(time.Since(start)).To(BeNumerically("<", 10*time.Second))
+		mismatchErr := expectMismatch(err, childLabel)
+		Expect(mismatchErr.Source).To(HavePrefix("child pid "))
+	})
+
+	It("should accept a child running with the launcher label", func() {
+		ce, err := NewContextExecutor(launcherPID, exec.Command("touch", marker), WithLabelManager(childLabelManager{manager, testLauncherLabel}), WithExecLabelVerification())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ce.Execute()).To(Succeed())
+		Expect(marker).To(BeAnExistingFile())
+	})
+})
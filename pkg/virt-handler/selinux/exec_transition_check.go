@@ -0,0 +1,80 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"os"
+	"runtime"
+
+	"kubevirt.io/client-go/log"
+)
+
+// CheckExecTransition verifies that virt-handler may switch its exec label to
+// its own label with the type replaced by launcherType, so that a policy
+// forbidding the switch is reported once at startup instead of failing every
+// Execute. Nothing is executed and the exec label is reset afterwards. The
+// outcome is exposed as the kubevirt_selinux_exec_transition_blocked metric. A
+// nil manager uses the selinux of the host.
+func CheckExecTransition(manager LabelManager, launcherType string) error {
+	ce := ContextExecutor{
+		pid:          os.Getpid(),
+		labelManager: manager,
+		logger:       log.Logger(logComponent),
+	}
+	var err error
+	if ce.originalLabel, err = ce.getLabelForPID(ce.pid); err != nil {
+		return err
+	}
+	if err := validateLabel(ce.originalLabel); err != nil {
+		return err
+	}
+	if ce.originalLabel == "" {
+		return nil
+	}
+	if ce.desiredLabel, err = transitionLabelType(ce.originalLabel, launcherType); err != nil {
+		return err
+	}
+
+	// the thread is dropped by the runtime if its label can't be reset, since
+	// the goroutine exits while still locked to it
+	result := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		setErr := ce.setExecLabelWithRetry(ce.desiredLabel)
+		if err := ce.resetContext(); err != nil {
+			result <- err
+			return
+		}
+		if setErr != nil {
+			result <- &ExecTransitionBlockedError{From: ce.originalLabel, To: ce.desiredLabel, Err: setErr}
+			return
+		}
+		result <- nil
+	}()
+	err = <-result
+
+	setExecTransitionBlocked(err != nil)
+	if err != nil {
+		ce.getLogger().Reason(err).Errorf("virt-handler can't switch to the selinux context of the launchers, fix the selinux policy of the node before scheduling VMIs on it")
+		return err
+	}
+	ce.getLogger().V(debugVerbosity).Infof("virt-handler may switch its selinux exec context from %s to %s", ce.originalLabel, ce.desiredLabel)
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"os"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+var _ = Describe("Exec transition self-check", func() {
+	const nodeName = "testnode"
+	const handlerLabel = "system_u:system_r:spc_t:s0"
+	const launcherLabel = "system_u:system_r:virt_launcher.process:s0"
+
+	var manager *testutils.FakeLabelManager
+	var registry *prometheus.Registry
+
+	blockedValue := func() float64 {
+		families, err := registry.Gather()
+		Expect(err).ToNot(HaveOccurred())
+		for _, family := range families {
+			if family.GetName() != "kubevirt_selinux_exec_transition_blocked" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				if hasNodeLabel(metric, nodeName) {
+					return metric.GetGauge().GetValue()
+				}
+			}
+		}
+		return -1
+	}
+
+	BeforeEach(func() {
+		registry = prometheus.NewRegistry()
+		Expect(RegisterMetrics(registry, nodeName)).To(Succeed())
+		manager = testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(os.Getpid(), handlerLabel)
+	})
+
+	It("should switch to the launcher type and back", func() {
+		Expect(CheckExecTransition(manager, "virt_launcher.process")).To(Succeed())
+		Expect(manager.ExecLabels()).To(Equal([]string{launcherLabel, handlerLabel}))
+		Expect(blockedValue()).To(BeZero())
+	})
+
+	It("should report a policy denying the transition and still reset the label", func() {
+		manager.DenyExecLabel(launcherLabel, syscall.EACCES)
+
+		err := CheckExecTransition(manager, "virt_launcher.process")
+		var blockedErr *ExecTransitionBlockedError
+		Expect(errors.As(err, &blockedErr)).To(BeTrue())
+		Expect(blockedErr.From).To(Equal(handlerLabel))
+		Expect(blockedErr.To).To(Equal(launcherLabel))
+		Expect(errors.Is(err, syscall.EACCES)).To(BeTrue())
+		Expect(manager.ExecLabels()).To(Equal([]string{handlerLabel}))
+		Expect(blockedValue()).To(Equal(1.0))
+	})
+
+	It("should clear the metric once the transition is allowed again", func() {
+		manager.DenyExecLabel(launcherLabel, syscall.EACCES)
+		Expect(CheckExecTransition(manager, "virt_launcher.process")).ToNot(Succeed())
+		Expect(blockedValue()).To(Equal(1.0))
+
+		manager.DenyExecLabel(launcherLabel, nil)
+		Expect(CheckExecTransition(manager, "virt_launcher.process")).To(Succeed())
+		Expect(blockedValue()).To(BeZero())
+	})
+
+	It("should report a label which can't be reset as a poisoned thread", func() {
+		manager.DenyExecLabel(handlerLabel, syscall.EACCES)
+
+		err := CheckExecTransition(manager, "virt_launcher.process")
+		Expect(errors.Is(err, errPoisonedThread)).To(BeTrue())
+		Expect(blockedValue()).To(Equal(1.0))
+	})
+
+	It("should reject an invalid launcher type", func() {
+		Expect(CheckExecTransition(manager, "not a type")).To(MatchError(ContainSubstring("invalid selinux type")))
+		Expect(manager.ExecLabels()).To(BeEmpty())
+	})
+
+	It("should skip the check without selinux label", func() {
+		manager.SetProcessLabel(os.Getpid(), "")
+		Expect(CheckExecTransition(manager, "virt_launcher.process")).To(Succeed())
+		Expect(manager.ExecLabels()).To(BeEmpty())
+	})
+})
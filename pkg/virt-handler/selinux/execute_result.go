@@ -0,0 +1,91 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// ExecuteResult records a single run of the executor, so that callers can log
+// a consistent audit line for each command executed against a launcher.
+type ExecuteResult struct {
+	// PID is the launcher pid the command ran against
+	PID           int
+	DesiredLabel  string
+	OriginalLabel string
+	Args          []string
+	Duration      time.Duration
+	DryRun        bool
+	// ExitCode is the code the child exited with, -1 if it never ran or was
+	// terminated by a signal
+	ExitCode int
+	// Err is the error Execute would have returned
+	Err error
+	// Output is the tail of the combined stdout and stderr of the child kept
+	// by WithRingBufferOutput, OutputTruncated tells whether it is partial
+	Output          []byte
+	OutputTruncated bool
+}
+
+// ExecuteWithResult runs the command like ExecuteWithExitCode and records the
+// run, whether it succeeded or not.
+func (ce ContextExecutor) ExecuteWithResult() *ExecuteResult {
+	result := ce.newResult(ce.cmdToExecute)
+	output := ce.newRingBuffer()
+	ce.ringBuffer = output
+	start := time.Now()
+	result.ExitCode, result.Err = ce.ExecuteWithExitCode()
+	result.Duration = time.Since(start)
+	result.setOutput(output)
+	return result
+}
+
+func (ce ContextExecutor) newResult(cmd *exec.Cmd) *ExecuteResult {
+	return &ExecuteResult{
+		PID:           ce.pid,
+		DesiredLabel:  ce.desiredLabel,
+		OriginalLabel: ce.originalLabel,
+		Args:          append([]string(nil), cmd.Args...),
+		DryRun:        ce.dryRun,
+	}
+}
+
+// Outcome summarizes the run as succeeded, failed or dry-run.
+func (r *ExecuteResult) Outcome() string {
+	switch {
+	case r.Err != nil:
+		return "failed"
+	case r.DryRun:
+		return "dry-run"
+	default:
+		return "succeeded"
+	}
+}
+
+func (r *ExecuteResult) String() string {
+	line := fmt.Sprintf("executed %q in launcher namespace %d with selinux context %q (from %q): %s after %v, exit code %d",
+		r.Args, r.PID, r.DesiredLabel, r.OriginalLabel, r.Outcome(), r.Duration, r.ExitCode)
+	if r.Err != nil {
+		line += fmt.Sprintf(": %v", r.Err)
+	}
+	return line
+}
@@ -0,0 +1,87 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"os/exec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Recording the result of an execution", func() {
+
+	const (
+		desiredLabel  = "system_u:system_r:container_t:s0:c1,c2"
+		originalLabel = "system_u:system_r:spc_t:s0"
+	)
+
+	newExecutor := func(args ...string) ContextExecutor {
+		return ContextExecutor{
+			pid:           1,
+			desiredLabel:  desiredLabel,
+			originalLabel: originalLabel,
+			cmdToExecute:  exec.Command(args[0], args[1:]...),
+		}
+	}
+
+	expectCommonFields := func(result *ExecuteResult, args ...string) {
+		Expect(result.PID).To(Equal(1))
+		Expect(result.DesiredLabel).To(Equal(desiredLabel))
+		Expect(result.OriginalLabel).To(Equal(originalLabel))
+		Expect(result.Args).To(Equal(args))
+		Expect(result.Duration).To(BeNumerically(">", 0))
+	}
+
+	It("should populate the result on success", func() {
+		result := newExecutor("true").ExecuteWithResult()
+		expectCommonFields(result, "true")
+		Expect(result.Err).ToNot(HaveOccurred())
+		Expect(result.ExitCode).To(Equal(0))
+		Expect(result.Outcome()).To(Equal("succeeded"))
+		Expect(result.String()).To(ContainSubstring(`executed ["true"] in launcher namespace 1`))
+	})
+
+	It("should populate the result if the child failed", func() {
+		result := newExecutor("sh", "-c", "exit 3").ExecuteWithResult()
+		expectCommonFields(result, "sh", "-c", "exit 3")
+		Expect(result.Err).To(MatchError(ContainSubstring("exit status 3")))
+		Expect(result.ExitCode).To(Equal(3))
+		Expect(result.Outcome()).To(Equal("failed"))
+		Expect(result.String()).To(HaveSuffix(result.Err.Error()))
+	})
+
+	It("should populate the result if the child could not be started", func() {
+		result := newExecutor("/non/existing/command").ExecuteWithResult()
+		expectCommonFields(result, "/non/existing/command")
+		Expect(result.Err).To(HaveOccurred())
+		Expect(result.ExitCode).To(Equal(-1))
+		Expect(result.Outcome()).To(Equal("failed"))
+	})
+
+	It("should mark dry runs", func() {
+		ce := newExecutor("true")
+		ce.dryRun = true
+		result := ce.ExecuteWithResult()
+		Expect(result.Err).ToNot(HaveOccurred())
+		Expect(result.DryRun).To(BeTrue())
+		Expect(result.Outcome()).To(Equal("dry-run"))
+	})
+})
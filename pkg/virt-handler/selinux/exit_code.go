@@ -0,0 +1,61 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// childExit holds the state of the last child run by the executor.
+type childExit struct {
+	state *os.ProcessState
+}
+
+// ExecuteWithExitCode runs the command like Execute and returns the exit code
+// of the child alongside the error, so that callers can branch on specific
+// codes. The code is -1 if the child never ran, or if it was terminated by a
+// signal, which is then surfaced as a *ChildSignaledError.
+func (ce ContextExecutor) ExecuteWithExitCode() (int, error) {
+	exit := &childExit{}
+	ce.exit = exit
+	err := ce.Execute()
+	if ce.dryRun {
+		return 0, err
+	}
+	return exit.code(err)
+}
+
+func (ce ContextExecutor) recordExit(cmd *exec.Cmd) {
+	if ce.exit != nil {
+		ce.exit.state = cmd.ProcessState
+	}
+}
+
+func (e *childExit) code(err error) (int, error) {
+	if e.state == nil {
+		return -1, err
+	}
+	if status, ok := e.state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return -1, &ChildSignaledError{Signal: status.Signal(), Err: err}
+	}
+	return e.state.ExitCode(), err
+}
@@ -0,0 +1,97 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Capturing the exit code of the child", func() {
+
+	table.DescribeTable("should return the code the child exited with", func(script string, expectedCode int) {
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("sh", "-c", script)}
+		code, err := ce.ExecuteWithExitCode()
+		Expect(code).To(Equal(expectedCode))
+		if expectedCode == 0 {
+			Expect(err).ToNot(HaveOccurred())
+		} else {
+			Expect(err).To(MatchError(ContainSubstring("exit status")))
+		}
+	},
+		table.Entry("on success", "exit 0", 0),
+		table.Entry("on failure", "exit 1", 1),
+		table.Entry("on a specific code", "exit 2", 2),
+		table.Entry("on the highest code", "exit 255", 255),
+	)
+
+	It("should surface the signal which terminated the child", func() {
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("sh", "-c", "kill -TERM $$")}
+		code, err := ce.ExecuteWithExitCode()
+		Expect(code).To(Equal(-1))
+		var signaledErr *ChildSignaledError
+		Expect(errors.As(err, &signaledErr)).To(BeTrue())
+		Expect(signaledErr.Signal).To(Equal(syscall.SIGTERM))
+		Expect(err.Error()).To(ContainSubstring("signal 15"))
+	})
+
+	It("should keep the context error of a killed child", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		exit := &childExit{}
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("sleep", "5"), exit: exit}
+		_, _, err := ce.execute(ctx)
+		code, err := exit.code(err)
+		Expect(code).To(Equal(-1))
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		var signaledErr *ChildSignaledError
+		Expect(errors.As(err, &signaledErr)).To(BeTrue())
+		Expect(signaledErr.Signal).To(Equal(syscall.SIGKILL))
+	})
+
+	It("should return -1 if the child could not be started", func() {
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("/non/existing/command")}
+		code, err := ce.ExecuteWithExitCode()
+		Expect(code).To(Equal(-1))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should not report a code of a former execution", func() {
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("sh", "-c", "exit 3")}
+		code, _ := ce.ExecuteWithExitCode()
+		Expect(code).To(Equal(3))
+		Expect(ce.Execute()).ToNot(Succeed())
+		Expect(ce.exit).To(BeNil())
+	})
+
+	It("should return 0 on dry run", func() {
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("sh", "-c", "exit 3"), dryRun: true}
+		code, err := ce.ExecuteWithExitCode()
+		Expect(code).To(BeZero())
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,145 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+const selinuxXattr = "security.selinux"
+
+// FilesystemInfo describes the filesystem a path lives on.
+type FilesystemInfo struct {
+	// Type is the magic number statfs reports for the filesystem
+	Type     int64
+	ReadOnly bool
+	// XattrsUnsupported is set if the filesystem can't store selinux labels
+	XattrsUnsupported bool
+}
+
+// FilesystemInspector describes the filesystems of the paths to relabel.
+type FilesystemInspector interface {
+	Inspect(path string) (FilesystemInfo, error)
+}
+
+// hostFilesystemInspector statfs the paths, and probes the selinux xattr to
+// tell whether their filesystem can store labels at all.
+type hostFilesystemInspector struct{}
+
+func (hostFilesystemInspector) Inspect(path string) (FilesystemInfo, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return FilesystemInfo{}, fmt.Errorf("failed to statfs %s: %v", path, err)
+	}
+	info := FilesystemInfo{
+		Type:     int64(st.Type),
+		ReadOnly: int64(st.Flags)&unix.ST_RDONLY != 0,
+	}
+	// ENODATA only means the path is not labeled yet
+	if _, err := unix.Lgetxattr(path, selinuxXattr, nil); errors.Is(err, unix.ENOTSUP) {
+		info.XattrsUnsupported = true
+	}
+	return info, nil
+}
+
+// NewFilesystemInspector returns the FilesystemInspector backed by the
+// filesystems of the host.
+func NewFilesystemInspector() FilesystemInspector {
+	return hostFilesystemInspector{}
+}
+
+// WithFilesystemInspector makes the relabel helpers describe the filesystems
+// of the paths through inspector, instead of the filesystems of the host.
+func WithFilesystemInspector(inspector FilesystemInspector) Option {
+	return func(ce *ContextExecutor) {
+		ce.filesystemInspector = inspector
+	}
+}
+
+// WithSkipUnrelabelableFilesystems makes the relabel helpers leave the paths
+// on read-only or xattr-less filesystems untouched with a warning, instead of
+// failing them with a FilesystemRelabelError.
+func WithSkipUnrelabelableFilesystems() Option {
+	return func(ce *ContextExecutor) {
+		ce.skipUnrelabelableFilesystems = true
+	}
+}
+
+func (ce ContextExecutor) getFilesystemInspector() FilesystemInspector {
+	if ce.filesystemInspector == nil {
+		return NewFilesystemInspector()
+	}
+	return ce.filesystemInspector
+}
+
+// filesystemNames are the filesystems worth naming in the errors, the ones
+// the relabeled paths commonly live on.
+var filesystemNames = map[int64]string{
+	unix.EXT4_SUPER_MAGIC:      "ext4",
+	unix.XFS_SUPER_MAGIC:       "xfs",
+	unix.TMPFS_MAGIC:           "tmpfs",
+	unix.OVERLAYFS_SUPER_MAGIC: "overlay",
+	unix.NFS_SUPER_MAGIC:       "nfs",
+	0xff534d42:                 "cifs",
+	0xfe534d42:                 "smb2",
+	0x65735546:                 "fuse",
+	unix.V9FS_MAGIC:            "9p",
+	unix.MSDOS_SUPER_MAGIC:     "vfat",
+	unix.ISOFS_SUPER_MAGIC:     "iso9660",
+	unix.SQUASHFS_MAGIC:        "squashfs",
+}
+
+// filesystemName returns the name of the filesystem type, or its magic number
+// if it's not a known one.
+func filesystemName(fsType int64) string {
+	if name, ok := filesystemNames[fsType]; ok {
+		return name
+	}
+	return fmt.Sprintf("%#x", fsType)
+}
+
+// guardFilesystem returns the result of path if its filesystem can't be
+// relabeled, or nil if the relabel can go on. Paths which can't be inspected,
+// e.g. missing ones, are left to the relabel to fail on.
+func (ce ContextExecutor) guardFilesystem(path string) *relabelResult {
+	info, err := ce.getFilesystemInspector().Inspect(path)
+	if err != nil {
+		ce.getLogger().V(debugVerbosity).Infof("not checking the filesystem of %s: %v", path, err)
+		return nil
+	}
+	var kind FilesystemErrorKind
+	switch {
+	case info.ReadOnly:
+		kind = ReadOnlyFilesystem
+	case info.XattrsUnsupported:
+		kind = XattrsUnsupported
+	default:
+		return nil
+	}
+	fsErr := &FilesystemRelabelError{Path: path, Filesystem: filesystemName(info.Type), Kind: kind}
+	if ce.skipUnrelabelableFilesystems {
+		ce.getLogger().Warningf("not relabeling %s: %v", path, fsErr)
+		return &relabelResult{skipped: true}
+	}
+	return &relabelResult{err: fsErr}
+}
@@ -0,0 +1,183 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"golang.org/x/sys/unix"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+// fakeFilesystems describes the paths below the registered mount points, and
+// fails to inspect the others like statfs on a missing path.
+type fakeFilesystems struct {
+	mounts    map[string]FilesystemInfo
+	inspected []string
+}
+
+func (f *fakeFilesystems) Inspect(path string) (FilesystemInfo, error) {
+	f.inspected = append(f.inspected, path)
+	for mount, info := range f.mounts {
+		if path == mount || filepath.Dir(path) == mount {
+			return info, nil
+		}
+	}
+	return FilesystemInfo{}, fmt.Errorf("failed to statfs %s: %v", path, unix.ENOENT)
+}
+
+var _ = Describe("Guarding the relabel of paths by their filesystem", func() {
+
+	var manager *testutils.FakeLabelManager
+	var filesystems *fakeFilesystems
+	var ce ContextExecutor
+
+	BeforeEach(func() {
+		manager = testutils.NewFakeLabelManager()
+		filesystems = &fakeFilesystems{mounts: map[string]FilesystemInfo{
+			"/var/run/kubevirt/hotplug":  {Type: unix.EXT4_SUPER_MAGIC},
+			"/var/run/kubevirt/readonly": {Type: unix.XFS_SUPER_MAGIC, ReadOnly: true},
+			"/var/run/kubevirt/nfs":      {Type: unix.NFS_SUPER_MAGIC, XattrsUnsupported: true},
+		}}
+		ce = ContextExecutor{desiredLabel: testLauncherLabel}
+		WithLabelManager(manager)(&ce)
+		WithFilesystemInspector(filesystems)(&ce)
+	})
+
+	table.DescribeTable("should fail the paths which can't be relabeled with a typed error", func(path string, kind FilesystemErrorKind, message string) {
+		Expect(manager.SetFileLabel(path, testOriginalLabel)).To(Succeed())
+
+		_, err := ce.RelabelFiles(path)
+		Expect(err).To(MatchError(ContainSubstring(message)))
+		Expect(IsFilesystemRelabelErrorKind(err, kind)).To(BeTrue())
+		Expect(manager.FileLabel(path)).To(Equal(testOriginalLabel))
+	},
+		table.Entry("on a read-only mount", "/var/run/kubevirt/readonly/disk.img", ReadOnlyFilesystem,
+			"cannot relabel /var/run/kubevirt/readonly/disk.img: its xfs filesystem is mounted read-only"),
+		table.Entry("on a filesystem without xattrs", "/var/run/kubevirt/nfs/disk.img", XattrsUnsupported,
+			"cannot relabel /var/run/kubevirt/nfs/disk.img: its nfs filesystem does not support selinux labels"),
+	)
+
+	It("should relabel the other paths", func() {
+		Expect(manager.SetFileLabel("/var/run/kubevirt/hotplug/disk.img", testOriginalLabel)).To(Succeed())
+
+		relabeled, err := ce.EnsureFilesLabeled("/var/run/kubevirt/readonly/disk.img", "/var/run/kubevirt/hotplug/disk.img")
+		Expect(IsFilesystemRelabelErrorKind(err, ReadOnlyFilesystem)).To(BeTrue())
+		Expect(relabeled).To(Equal([]string{"/var/run/kubevirt/hotplug/disk.img"}))
+		Expect(manager.FileLabel("/var/run/kubevirt/hotplug/disk.img")).To(Equal(testLauncherFileLabel))
+	})
+
+	It("should leave the relabel of the paths which can't be inspected to fail", func() {
+		_, err := ce.RelabelFiles("/dev/missing")
+		Expect(err).To(MatchError(ContainSubstring("failed to retrieve the selinux label of /dev/missing")))
+		Expect(IsFilesystemRelabelErrorKind(err, ReadOnlyFilesystem)).To(BeFalse())
+	})
+
+	It("should name the unknown filesystems by their magic number", func() {
+		filesystems.mounts["/var/run/kubevirt/custom"] = FilesystemInfo{Type: 0x1234, XattrsUnsupported: true}
+
+		_, err := ce.RelabelFiles("/var/run/kubevirt/custom/disk.img")
+		Expect(err).To(MatchError(ContainSubstring("its 0x1234 filesystem does not support selinux labels")))
+	})
+
+	It("should skip the paths which can't be relabeled if asked to", func() {
+		WithSkipUnrelabelableFilesystems()(&ce)
+		Expect(manager.SetFileLabel("/var/run/kubevirt/readonly/disk.img", testOriginalLabel)).To(Succeed())
+
+		results := ce.EnsureFilesLabeledWithResults("/var/run/kubevirt/readonly/disk.img", "/var/run/kubevirt/nfs/disk.img")
+		Expect(results).To(HaveLen(2))
+		for _, result := range results {
+			Expect(result.Skipped).To(BeTrue())
+			Expect(result.Relabeled).To(BeFalse())
+			Expect(result.Error).To(BeEmpty())
+		}
+		Expect(manager.FileLabel("/var/run/kubevirt/readonly/disk.img")).To(Equal(testOriginalLabel))
+	})
+
+	It("should not restore the default labels on a read-only mount", func() {
+		WithDefaultContextLookup(fakePolicy{
+			patterns: []string{"/var/run/kubevirt/*/*"},
+			labels:   map[string]string{"/var/run/kubevirt/*/*": testDeviceDefaultLabel},
+		})(&ce)
+		Expect(manager.SetFileLabel("/var/run/kubevirt/readonly/disk.img", testLauncherFileLabel)).To(Succeed())
+
+		restored, err := ce.RestoreDefaultFileLabels("/var/run/kubevirt/readonly/disk.img")
+		Expect(IsFilesystemRelabelErrorKind(err, ReadOnlyFilesystem)).To(BeTrue())
+		Expect(restored).To(BeEmpty())
+		Expect(manager.FileLabel("/var/run/kubevirt/readonly/disk.img")).To(Equal(testLauncherFileLabel))
+	})
+
+	Context("with a directory tree", func() {
+
+		var root string
+
+		BeforeEach(func() {
+			var err error
+			root, err = ioutil.TempDir("", "kubevirt-relabel-tree")
+			Expect(err).ToNot(HaveOccurred())
+			touch(filepath.Join(root, "a"))
+			touch(filepath.Join(root, "b"))
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(root)
+		})
+
+		It("should inspect each filesystem once and report a single error", func() {
+			filesystems.mounts[root] = FilesystemInfo{Type: unix.ISOFS_SUPER_MAGIC, ReadOnly: true}
+
+			err := ce.RelabelTree(root)
+			Expect(err).To(MatchError(fmt.Sprintf("cannot relabel %s: its iso9660 filesystem is mounted read-only", root)))
+			Expect(filesystems.inspected).To(Equal([]string{root}))
+			for _, path := range []string{root, filepath.Join(root, "a"), filepath.Join(root, "b")} {
+				_, err := manager.FileLabel(path)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+
+		It("should relabel the tree on a filesystem which can be relabeled", func() {
+			filesystems.mounts[root] = FilesystemInfo{Type: unix.TMPFS_MAGIC}
+
+			Expect(ce.RelabelTree(root)).To(Succeed())
+			Expect(manager.FileLabel(filepath.Join(root, "b"))).To(Equal(testLauncherFileLabel))
+		})
+	})
+
+	It("should describe the filesystems of the host", func() {
+		dir, err := ioutil.TempDir("", "kubevirt-filesystem")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		info, err := NewFilesystemInspector().Inspect(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.ReadOnly).To(BeFalse())
+		Expect(info.Type).ToNot(BeZero())
+
+		_, err = NewFilesystemInspector().Inspect(filepath.Join(dir, "missing"))
+		Expect(err).To(MatchError(ContainSubstring("failed to statfs")))
+	})
+})
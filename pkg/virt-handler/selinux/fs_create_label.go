@@ -0,0 +1,107 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+)
+
+// FSCreateLabelSetter is implemented by the label managers able to set the
+// label the files created by the calling thread get, as written to its
+// attr/fscreate file.
+type FSCreateLabelSetter interface {
+	SetFSCreateLabel(label string) error
+}
+
+// WithFSCreateLabel sets the file creation context of the OS thread starting
+// the child to label, or to the file label of the launcher if label is empty,
+// and clears it again before the thread is reset. The files and directories
+// created from that thread while the child runs, by the executor and its
+// post-exec hooks, get label instead of one computed from their parent
+// directory. The kernel clears the file creation context on execve: the
+// programs the child executes label their files by the type transitions of
+// the policy, or by setting their own file creation context. The label is
+// validated when the executor is created. It has no effect without selinux.
+func WithFSCreateLabel(label string) Option {
+	return func(ce *ContextExecutor) {
+		ce.fsCreateLabel = label
+		ce.hasFSCreateLabel = true
+	}
+}
+
+// resolveFSCreateLabel validates the label set by WithFSCreateLabel, once the
+// label the child runs with is known, and stores its raw form.
+func (ce *ContextExecutor) resolveFSCreateLabel() error {
+	if !ce.hasFSCreateLabel || ce.fsCreateLabel == "" {
+		return nil
+	}
+	label, err := ce.normalizeLabel(ce.fsCreateLabel)
+	if err != nil {
+		return fmt.Errorf("invalid file creation label: %v", err)
+	}
+	if err := validateLabel(label); err != nil {
+		return fmt.Errorf("invalid file creation label: %v", err)
+	}
+	ce.fsCreateLabel = label
+	return nil
+}
+
+// getFSCreateLabel returns the file creation context of the thread starting
+// the child.
+func (ce ContextExecutor) getFSCreateLabel() string {
+	if ce.fsCreateLabel == "" {
+		return ce.getFileLabel()
+	}
+	return ce.fsCreateLabel
+}
+
+// setFSCreateContext sets the file creation context of the calling OS thread,
+// which has to be switched to the launcher label already.
+func (ce ContextExecutor) setFSCreateContext() error {
+	if !ce.hasFSCreateLabel {
+		return nil
+	}
+	setter, ok := ce.getLabelManager().(FSCreateLabelSetter)
+	if !ok {
+		return fmt.Errorf("the selinux label manager can't set the file creation context of the thread")
+	}
+	label := ce.getFSCreateLabel()
+	ce.getLogger().V(debugVerbosity).Infof("setting the selinux file creation context to %s for launcher pid %d", label, ce.pid)
+	if err := setter.SetFSCreateLabel(label); err != nil {
+		return fmt.Errorf("failed to set the selinux file creation context to %s for launcher pid %d: %v", label, ce.pid, err)
+	}
+	return nil
+}
+
+// resetFSCreateContext clears the file creation context of the calling OS
+// thread, so that its files are labeled by the policy again.
+func (ce ContextExecutor) resetFSCreateContext() error {
+	if !ce.hasFSCreateLabel {
+		return nil
+	}
+	setter, ok := ce.getLabelManager().(FSCreateLabelSetter)
+	if !ok {
+		return fmt.Errorf("the selinux label manager can't clear the file creation context of the thread")
+	}
+	if err := setter.SetFSCreateLabel(""); err != nil {
+		return fmt.Errorf("failed to clear the selinux file creation context: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/sys/unix"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+// fsCreateLabelManager tracks the file creation context of every thread and
+// labels the files created through it accordingly, like the kernel does.
+type fsCreateLabelManager struct {
+	*testutils.FakeLabelManager
+	lock                sync.Mutex
+	threadLabels        map[int]string
+	setFSCreateLabelErr error
+}
+
+func (m *fsCreateLabelManager) SetFSCreateLabel(label string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.setFSCreateLabelErr != nil {
+		return m.setFSCreateLabelErr
+	}
+	m.threadLabels[unix.Gettid()] = label
+	return m.FakeLabelManager.SetFSCreateLabel(label)
+}
+
+func (m *fsCreateLabelManager) threadLabel(tid int) string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.threadLabels[tid]
+}
+
+// createFile creates path from the calling thread, labeled with its file
+// creation context if set, or with defaultLabel.
+func (m *fsCreateLabelManager) createFile(path string, defaultLabel string) error {
+	if err := ioutil.WriteFile(path, nil, 0600); err != nil {
+		return err
+	}
+	label := m.threadLabel(unix.Gettid())
+	if label == "" {
+		label = defaultLabel
+	}
+	return m.SetFileLabel(path, label)
+}
+
+var _ = Describe("File creation label", func() {
+	const launcherPID = 1234
+	const fileLabel = "system_u:object_r:container_file_t:s0:c1,c2"
+	const defaultFileLabel = "system_u:object_r:tmp_t:s0"
+
+	var manager *fsCreateLabelManager
+	var restoreProcRoot func()
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "fscreate")
+		Expect(err).ToNot(HaveOccurred())
+		restoreProcRoot = fakeProcRoot(launcherPID)
+		manager = &fsCreateLabelManager{FakeLabelManager: testutils.NewFakeLabelManager(), threadLabels: map[int]string{}}
+		manager.SetProcessLabel(launcherPID, testLauncherLabel)
+		manager.SetProcessLabel(os.Getpid(), testOriginalLabel)
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, true, nil
+		}
+		ResetSELinuxDetectionForTest()
+	})
+
+	AfterEach(func() {
+		restoreProcRoot()
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+		os.RemoveAll(tmpDir)
+	})
+
+	// execute runs true and creates a file from the thread which started it,
+	// returning the label of the file and the thread id
+	execute := func(options ...Option) (string, int, error) {
+		path := filepath.Join(tmpDir, "file")
+		var tid int
+		createFile := WithPostExecHook(func() error {
+			tid = unix.Gettid()
+			return manager.createFile(path, defaultFileLabel)
+		})
+		ce, err := NewContextExecutor(launcherPID, exec.Command("true"), append([]Option{WithLabelManager(manager), createFile}, options...)...)
+		Expect(err).ToNot(HaveOccurred())
+		if err := ce.Execute(); err != nil {
+			return "", tid, err
+		}
+		label, err := manager.FileLabel(path)
+		Expect(err).ToNot(HaveOccurred())
+		return label, tid, nil
+	}
+
+	It("should label the files created around the child with the file label of the launcher by default", func() {
+		label, tid, err := execute(WithFSCreateLabel(""))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(label).To(Equal(testLauncherFileLabel))
+		Expect(manager.FSCreateLabels()).To(Equal([]string{testLauncherFileLabel, ""}))
+		Expect(manager.threadLabel(tid)).To(BeEmpty())
+		Expect(manager.ExecLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+	})
+
+	It("should label the files created around the child with the given label", func() {
+		label, _, err := execute(WithFSCreateLabel(fileLabel))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(label).To(Equal(fileLabel))
+		Expect(manager.FSCreateLabels()).To(Equal([]string{fileLabel, ""}))
+	})
+
+	It("should leave the file creation context alone without the option", func() {
+		label, _, err := execute()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(label).To(Equal(defaultFileLabel))
+		Expect(manager.FSCreateLabels()).To(BeEmpty())
+	})
+
+	It("should not reset the exec context of a thread whose file creation context could not be set", func() {
+		manager.setFSCreateLabelErr = fmt.Errorf("permission denied")
+		_, _, err := execute(WithFSCreateLabel(""))
+		Expect(err).To(MatchError(fmt.Sprintf("failed to set the selinux file creation context to %s for launcher pid %d: permission denied", testLauncherFileLabel, launcherPID)))
+		Expect(manager.ExecLabels()).To(Equal([]string{testLauncherLabel}))
+	})
+
+	It("should reject an invalid label", func() {
+		_, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager), WithFSCreateLabel("container_file_t"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("invalid file creation label:"))
+	})
+
+	It("should fail with a label manager which can't set the file creation context", func() {
+		ce, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(writeOnlyLabelManager{manager.FakeLabelManager}), WithFSCreateLabel(""))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ce.Execute()).To(MatchError("the selinux label manager can't set the file creation context of the thread"))
+	})
+
+	It("should not set the file creation context without selinux", func() {
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, false, nil
+		}
+		ResetSELinuxDetectionForTest()
+		label, _, err := execute(WithFSCreateLabel(""))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(label).To(Equal(defaultFileLabel))
+		Expect(manager.FSCreateLabels()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,98 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"os"
+	"time"
+)
+
+// heartbeatChecksPerWindow is how often the heartbeat file is checked within
+// the window it may go stale for
+const heartbeatChecksPerWindow = 5
+
+type heartbeat struct {
+	path   string
+	window time.Duration
+}
+
+// WithHeartbeat kills the child once path, which the child touches while it
+// makes progress, was not modified for longer than window. Unlike a context
+// deadline, it lets slow commands like disk conversions run for as long as
+// they need, and only stops the ones which hang. The window starts with the
+// child, which doesn't need to create the file before its first beat. path is
+// resolved by virt-handler, not in the launcher namespaces, e.g. below
+// /proc/<pid>/root. A window which isn't positive disables the heartbeat.
+func WithHeartbeat(path string, window time.Duration) Option {
+	return func(ce *ContextExecutor) {
+		if window <= 0 {
+			ce.heartbeat = nil
+			return
+		}
+		ce.heartbeat = &heartbeat{path: path, window: window}
+	}
+}
+
+// watchHeartbeat returns a channel receiving a *HeartbeatStaleError once the
+// heartbeat of the child went stale, and a function to stop watching. The
+// channel never receives anything without a heartbeat.
+func (ce ContextExecutor) watchHeartbeat() (<-chan error, func()) {
+	if ce.heartbeat == nil {
+		return nil, func() {}
+	}
+	stale := make(chan error, 1)
+	stop := make(chan struct{})
+	go func() {
+		interval := ce.heartbeat.window / heartbeatChecksPerWindow
+		if interval <= 0 {
+			interval = ce.heartbeat.window
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		lastBeat := time.Now()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				if modTime, err := ce.heartbeat.lastModification(); err == nil && modTime.After(lastBeat) {
+					lastBeat = modTime
+				} else if err != nil && !os.IsNotExist(err) {
+					ce.getLogger().Reason(err).Warningf("failed to check the heartbeat file %s of the command running in launcher namespace %d", ce.heartbeat.path, ce.pid)
+				}
+				if now.Sub(lastBeat) > ce.heartbeat.window {
+					stale <- &HeartbeatStaleError{Path: ce.heartbeat.path, Window: ce.heartbeat.window, LastBeat: lastBeat}
+					return
+				}
+			}
+		}
+	}()
+	return stale, func() {
+		close(stop)
+	}
+}
+
+func (h *heartbeat) lastModification() (time.Time, error) {
+	info, err := os.Stat(h.path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
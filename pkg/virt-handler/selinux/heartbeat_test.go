@@ -0,0 +1,104 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Heartbeat", func() {
+	const window = 200 * time.Millisecond
+
+	var (
+		tempDir       string
+		heartbeatFile string
+	)
+
+	newExecutor := func(script string) ContextExecutor {
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("sh", "-c", script, "heartbeat", heartbeatFile)}
+		WithHeartbeat(heartbeatFile, window)(&ce)
+		return ce
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "kubevirt-heartbeat")
+		Expect(err).ToNot(HaveOccurred())
+		heartbeatFile = filepath.Join(tempDir, "heartbeat")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("should let a slow child with steady heartbeats complete", func() {
+		ce := newExecutor(`for i in $(seq 1 12); do touch "$1"; sleep 0.05; done`)
+
+		start := time.Now()
+		Expect(ce.Execute()).To(Succeed())
+		Expect(time.Since(start)).To(BeNumerically(">", window))
+	})
+
+	It("should kill the child once its heartbeat went stale", func() {
+		ce := newExecutor(`touch "$1"; exec sleep 10`)
+
+		start := time.Now()
+		err := ce.Execute()
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		staleErr := &HeartbeatStaleError{}
+		Expect(errors.As(err, &staleErr)).To(BeTrue())
+		Expect(staleErr.Path).To(Equal(heartbeatFile))
+		Expect(staleErr.Window).To(Equal(window))
+		Expect(err.Error()).To(ContainSubstring("was not updated for more than 200ms"))
+	})
+
+	It("should kill the child which stopped beating after a while", func() {
+		ce := newExecutor(`for i in $(seq 1 6); do touch "$1"; sleep 0.05; done; exec sleep 10`)
+
+		start := time.Now()
+		err := ce.Execute()
+		Expect(time.Since(start)).To(BeNumerically(">", 300*time.Millisecond))
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		Expect(err).To(BeAssignableToTypeOf(&HeartbeatStaleError{}))
+	})
+
+	It("should count the window from the start of a child which never beats", func() {
+		ce := newExecutor(`exec sleep 10`)
+
+		err := ce.Execute()
+		Expect(err).To(BeAssignableToTypeOf(&HeartbeatStaleError{}))
+		Expect(time.Since(err.(*HeartbeatStaleError).LastBeat)).To(BeNumerically("<", 5*time.Second))
+	})
+
+	It("should be disabled by a window which isn't positive", func() {
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("sleep", "0.3")}
+		WithHeartbeat(heartbeatFile, 0)(&ce)
+		Expect(ce.heartbeat).To(BeNil())
+		Expect(ce.Execute()).To(Succeed())
+	})
+})
@@ -0,0 +1,97 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"golang.org/x/sys/unix"
+
+	"kubevirt.io/kubevirt/pkg/util/fdhygiene"
+)
+
+// WithInheritFDs keeps the given FDs of virt-handler open in the executed
+// commands, under the same numbers, e.g. to pass a memfd or a pre-opened
+// device to qemu. All other FDs but std{in|out|err} are still closed on exec.
+// The FDs have to be open when the command is executed.
+func WithInheritFDs(fds ...int) Option {
+	return func(ce *ContextExecutor) {
+		ce.inheritFDs = append([]int{}, fds...)
+	}
+}
+
+// applyInheritedFDs places the inherited FDs in the extra files of cmd, at
+// their own numbers. The extra files wrap duplicates of the FDs, so that
+// releasing them doesn't close the FDs of the caller. The returned func
+// restores the extra files of cmd and closes the duplicates, once the child
+// was started.
+func (ce ContextExecutor) applyInheritedFDs(cmd *exec.Cmd) (func(), error) {
+	if len(ce.inheritFDs) == 0 {
+		return func() {}, nil
+	}
+	extraFiles := append([]*os.File{}, cmd.ExtraFiles...)
+	var dups []*os.File
+	closeDups := func() {
+		for _, dup := range dups {
+			dup.Close()
+		}
+	}
+
+	inherited := map[int]bool{}
+	for _, fd := range ce.inheritFDs {
+		if inherited[fd] {
+			continue
+		}
+		if fd < fdhygiene.MinFDToCloseOnExec {
+			closeDups()
+			return nil, fmt.Errorf("fd %d can't be inherited, the std{in|out|err} of the child are set by the command", fd)
+		}
+		slot := fd - fdhygiene.MinFDToCloseOnExec
+		if slot < len(cmd.ExtraFiles) && cmd.ExtraFiles[slot] != nil {
+			closeDups()
+			return nil, fmt.Errorf("fd %d can't be inherited, it is already set by the extra files of the command", fd)
+		}
+		// the duplicate is closed on exec, the child gets it moved to fd
+		dupFD, err := unix.FcntlInt(uintptr(fd), unix.F_DUPFD_CLOEXEC, 0)
+		if err == unix.EBADF {
+			closeDups()
+			return nil, fmt.Errorf("fd %d can't be inherited, it is not open", fd)
+		} else if err != nil {
+			closeDups()
+			return nil, fmt.Errorf("failed to duplicate fd %d to be inherited: %v", fd, err)
+		}
+		dup := os.NewFile(uintptr(dupFD), fmt.Sprintf("inherited-fd-%d", fd))
+		dups = append(dups, dup)
+		for len(extraFiles) <= slot {
+			extraFiles = append(extraFiles, nil)
+		}
+		extraFiles[slot] = dup
+		inherited[fd] = true
+	}
+
+	origExtraFiles := cmd.ExtraFiles
+	cmd.ExtraFiles = extraFiles
+	return func() {
+		cmd.ExtraFiles = origExtraFiles
+		closeDups()
+	}, nil
+}
@@ -0,0 +1,137 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/sys/unix"
+
+	"kubevirt.io/kubevirt/pkg/util/fdhygiene"
+)
+
+var _ = Describe("Inheriting FDs", func() {
+	var file *os.File
+
+	BeforeEach(func() {
+		var err error
+		file, err = ioutil.TempFile("", "kubevirt-inherit-fd")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = file.WriteString("inherited")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		file.Close()
+		os.Remove(file.Name())
+	})
+
+	openFDCount := func() int {
+		fds, err := fdhygiene.OpenFDs(fdhygiene.ProcSelfFDDir)
+		Expect(err).ToNot(HaveOccurred())
+		return len(fds)
+	}
+
+	It("should make the FD readable in the child under the same number", func() {
+		fd := int(file.Fd())
+		ce := ContextExecutor{pid: os.Getpid(), cmdToExecute: exec.Command("cat", fmt.Sprintf("/proc/self/fd/%d", fd))}
+		WithInheritFDs(fd)(&ce)
+
+		stdout, _, err := ce.ExecuteWithOutput()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stdout.String()).To(Equal("inherited"))
+	})
+
+	It("should still close the FDs which are not inherited", func() {
+		leaked, err := os.Open(file.Name())
+		Expect(err).ToNot(HaveOccurred())
+		defer leaked.Close()
+		_, err = unix.FcntlInt(leaked.Fd(), unix.F_SETFD, 0)
+		Expect(err).ToNot(HaveOccurred())
+
+		fd := int(file.Fd())
+		script := fmt.Sprintf("test -e /proc/self/fd/%d && ! test -e /proc/self/fd/%d", fd, leaked.Fd())
+		ce := ContextExecutor{pid: os.Getpid(), cmdToExecute: exec.Command("sh", "-c", script)}
+		WithInheritFDs(fd)(&ce)
+
+		Expect(ce.Execute()).To(Succeed())
+	})
+
+	It("should keep the FDs of the caller open and not leak the duplicates", func() {
+		cmd := exec.Command("true")
+		ce := ContextExecutor{pid: os.Getpid(), cmdToExecute: cmd}
+		WithInheritFDs(int(file.Fd()), int(file.Fd()))(&ce)
+		before := openFDCount()
+
+		Expect(ce.Execute()).To(Succeed())
+		Expect(openFDCount()).To(Equal(before))
+		Expect(cmd.ExtraFiles).To(BeEmpty())
+		_, err := file.Seek(0, 0)
+		Expect(err).ToNot(HaveOccurred())
+		content, err := ioutil.ReadAll(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal("inherited"))
+	})
+
+	It("should keep the extra files of the command", func() {
+		cmd := exec.Command("sh", "-c", "cat /proc/self/fd/3")
+		cmd.ExtraFiles = []*os.File{file}
+		other, err := os.Open(file.Name())
+		Expect(err).ToNot(HaveOccurred())
+		defer other.Close()
+		ce := ContextExecutor{pid: os.Getpid(), cmdToExecute: cmd}
+		WithInheritFDs(int(other.Fd()))(&ce)
+
+		stdout, _, err := ce.ExecuteWithOutput()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stdout.String()).To(Equal("inherited"))
+		Expect(cmd.ExtraFiles).To(Equal([]*os.File{file}))
+	})
+
+	It("should reject FDs which are not open", func() {
+		fd := int(file.Fd())
+		Expect(file.Close()).To(Succeed())
+		ce := ContextExecutor{pid: os.Getpid(), cmdToExecute: exec.Command("true")}
+		WithInheritFDs(fd)(&ce)
+
+		Expect(ce.Execute()).To(MatchError(fmt.Sprintf("fd %d can't be inherited, it is not open", fd)))
+	})
+
+	It("should reject std{in|out|err}", func() {
+		ce := ContextExecutor{pid: os.Getpid(), cmdToExecute: exec.Command("true")}
+		WithInheritFDs(1)(&ce)
+
+		Expect(ce.Execute()).To(MatchError(ContainSubstring("fd 1 can't be inherited")))
+	})
+
+	It("should reject FDs set by the extra files of the command", func() {
+		cmd := exec.Command("true")
+		cmd.ExtraFiles = []*os.File{file}
+		ce := ContextExecutor{pid: os.Getpid(), cmdToExecute: cmd}
+		WithInheritFDs(3)(&ce)
+
+		Expect(ce.Execute()).To(MatchError("fd 3 can't be inherited, it is already set by the extra files of the command"))
+	})
+})
@@ -0,0 +1,84 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+)
+
+// LabelAttr names the /proc/<pid>/attr file the label of a process is read
+// from.
+type LabelAttr string
+
+const (
+	// LabelAttrCurrent is the label the process runs with.
+	LabelAttrCurrent LabelAttr = "current"
+	// LabelAttrExec is the label the process set for the programs it executes.
+	LabelAttrExec LabelAttr = "exec"
+)
+
+// WithLauncherLabelAttr makes the executor read the launcher label from the
+// attr file of the launcher named by attr instead of attr/current. With
+// LabelAttrExec the child runs with the exec context the launcher set for its
+// own children. Only the launcher label is affected, the label of virt-handler
+// and the labels of the pids sharing their MCS are still the current ones.
+func WithLauncherLabelAttr(attr LabelAttr) Option {
+	return func(ce *ContextExecutor) {
+		ce.launcherLabelAttr = attr
+	}
+}
+
+// getLauncherLabel returns the label the child of the executor runs with, as
+// read from the configured attr file of the launcher pid.
+func (ce ContextExecutor) getLauncherLabel(pid int) (string, error) {
+	switch ce.launcherLabelAttr {
+	case "", LabelAttrCurrent:
+		return ce.getLabelForPID(pid)
+	case LabelAttrExec:
+	default:
+		return "", fmt.Errorf("unsupported attr file %q for the label of the launcher", ce.launcherLabelAttr)
+	}
+	// The exec context can be changed by the launcher at any time, it is
+	// never cached.
+	label, err := readAttrLabelForPIDWith(ce.getLabelManager(), pid, ce.launcherLabelAttr)
+	if err != nil {
+		return "", err
+	}
+	if label != "" {
+		return ce.normalizeLabel(label)
+	}
+	current, err := ce.getLabelForPID(pid)
+	if err != nil {
+		return "", err
+	}
+	if current != "" {
+		return "", fmt.Errorf("the launcher pid %d did not set an exec context in attr/%s", pid, ce.launcherLabelAttr)
+	}
+	// Without selinux all labels are empty.
+	return "", nil
+}
+
+func readAttrLabelForPIDWith(manager LabelManager, pid int, attr LabelAttr) (string, error) {
+	fileLabel, err := manager.FileLabel(fmt.Sprintf("/proc/%d/attr/%s", pid, attr))
+	if err != nil {
+		return "", newLabelError(pid, err)
+	}
+	return fileLabel, nil
+}
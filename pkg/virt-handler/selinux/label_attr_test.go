@@ -0,0 +1,115 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"os"
+	"os/exec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+var _ = Describe("Launcher label attr file", func() {
+	const launcherPID = 1234
+	const launcherExecLabel = "system_u:system_r:virt_launcher_child_t:s0:c1,c2"
+
+	var manager *testutils.FakeLabelManager
+
+	BeforeEach(func() {
+		manager = testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(launcherPID, testLauncherLabel)
+		manager.SetProcessLabel(os.Getpid(), testOriginalLabel)
+	})
+
+	newExecutor := func(options ...Option) (*ContextExecutor, error) {
+		return NewContextExecutorWithLabelManager(manager, launcherPID, exec.Command("true"), options...)
+	}
+
+	It("should read the launcher label from attr/current by default", func() {
+		manager.SetProcessExecLabel(launcherPID, launcherExecLabel)
+		ce, err := newExecutor()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ce.desiredLabel).To(Equal(testLauncherLabel))
+		Expect(ce.originalLabel).To(Equal(testOriginalLabel))
+	})
+
+	It("should read the launcher label from attr/current if asked to", func() {
+		manager.SetProcessExecLabel(launcherPID, launcherExecLabel)
+		ce, err := newExecutor(WithLauncherLabelAttr(LabelAttrCurrent))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ce.desiredLabel).To(Equal(testLauncherLabel))
+	})
+
+	It("should read the launcher label from attr/exec", func() {
+		manager.SetProcessExecLabel(launcherPID, launcherExecLabel)
+		ce, err := newExecutor(WithLauncherLabelAttr(LabelAttrExec))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ce.desiredLabel).To(Equal(launcherExecLabel))
+		Expect(ce.originalLabel).To(Equal(testOriginalLabel))
+	})
+
+	It("should keep reading the labels of the pids sharing their MCS from attr/current", func() {
+		manager.SetProcessExecLabel(launcherPID, launcherExecLabel)
+		manager.SetProcessLabel(2, "system_u:system_r:container_t:s0:c1,c2")
+		ce, err := newExecutor(WithLauncherLabelAttr(LabelAttrExec), WithSharedMCS(2))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ce.desiredLabel).To(Equal(launcherExecLabel))
+		Expect(ce.fileLabel).To(Equal(testLauncherLabel))
+	})
+
+	It("should apply the type transition to the label read from attr/exec", func() {
+		manager.SetProcessExecLabel(launcherPID, launcherExecLabel)
+		ce, err := newExecutor(WithLauncherLabelAttr(LabelAttrExec), WithTypeTransition("spc_t"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ce.desiredLabel).To(Equal("system_u:system_r:spc_t:s0:c1,c2"))
+		Expect(ce.fileLabel).To(Equal(launcherExecLabel))
+	})
+
+	It("should fail if the launcher did not set an exec context", func() {
+		manager.SetProcessExecLabel(launcherPID, "")
+		_, err := newExecutor(WithLauncherLabelAttr(LabelAttrExec))
+		Expect(err).To(MatchError(ContainSubstring("the launcher pid 1234 did not set an exec context in attr/exec")))
+	})
+
+	It("should accept an empty exec context without selinux", func() {
+		manager.SetProcessLabel(launcherPID, "")
+		manager.SetProcessLabel(os.Getpid(), "")
+		manager.SetProcessExecLabel(launcherPID, "")
+		ce, err := newExecutor(WithLauncherLabelAttr(LabelAttrExec))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ce.desiredLabel).To(BeEmpty())
+	})
+
+	It("should fail if attr/exec can't be read", func() {
+		_, err := newExecutor(WithLauncherLabelAttr(LabelAttrExec))
+		Expect(err).To(HaveOccurred())
+		labelErr, ok := err.(*LabelError)
+		Expect(ok).To(BeTrue())
+		Expect(labelErr.PID).To(Equal(launcherPID))
+	})
+
+	It("should reject unsupported attr files", func() {
+		_, err := newExecutor(WithLauncherLabelAttr("prev"))
+		Expect(err).To(MatchError(`unsupported attr file "prev" for the label of the launcher`))
+	})
+})
@@ -0,0 +1,147 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+// startTimeFieldIndex is the index of the starttime field in /proc/<pid>/stat,
+// counting from the field following the command name.
+const startTimeFieldIndex = 19
+
+// procStatBufferSize is large enough for the fields up to the start time,
+// given that the command name is at most 16 characters.
+const procStatBufferSize = 512
+
+type labelCacheEntry struct {
+	startTime uint64
+	label     string
+}
+
+// labelCache remembers the selinux label of live processes. Entries are keyed
+// by pid and validated against the process start time, so a recycled pid
+// never gets the label of the process which previously held it.
+type labelCache struct {
+	lock      sync.Mutex
+	entries   map[int]labelCacheEntry
+	startTime func(pid int) (uint64, error)
+	readLabel func(pid int) (string, error)
+	// pending are the reads started by getContext which did not return yet
+	pending map[int]*pendingLabelRead
+}
+
+var defaultLabelCache = newLabelCache(processStartTime, readLabelForPID)
+
+func newLabelCache(startTime func(pid int) (uint64, error), readLabel func(pid int) (string, error)) *labelCache {
+	return &labelCache{
+		entries:   map[int]labelCacheEntry{},
+		startTime: startTime,
+		readLabel: readLabel,
+		pending:   map[int]*pendingLabelRead{},
+	}
+}
+
+func (c *labelCache) get(pid int) (string, error) {
+	return c.getWith(pid, c.readLabel)
+}
+
+func (c *labelCache) getWith(pid int, readLabel func(pid int) (string, error)) (string, error) {
+	startTime, err := c.startTime(pid)
+	if err != nil {
+		return "", newLabelError(pid, err)
+	}
+
+	c.lock.Lock()
+	entry, exists := c.entries[pid]
+	c.lock.Unlock()
+	if exists && entry.startTime == startTime {
+		return entry.label, nil
+	}
+
+	label, err := readLabel(pid)
+	if err != nil {
+		return "", err
+	}
+
+	c.lock.Lock()
+	c.entries[pid] = labelCacheEntry{startTime: startTime, label: label}
+	c.lock.Unlock()
+	return label, nil
+}
+
+func (c *labelCache) flush(pid int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.entries, pid)
+}
+
+// FlushLabelCache drops the cached selinux label of the given pid, e.g. once
+// the VMI owning the launcher process got deleted.
+func FlushLabelCache(pid int) {
+	defaultLabelCache.flush(pid)
+}
+
+// LabelForPID returns the selinux label the given process runs with, e.g.
+// the one of a launcher, going through the label cache. The error is a
+// *LabelError of kind PIDNotFound if the process is gone, ProcNotReadable if
+// its label can't be read, and SELinuxUnavailable if selinux is not enabled
+// on the node, in which case /proc isn't read at all.
+func LabelForPID(pid int) (string, error) {
+	if !isSELinuxEnabled() {
+		return "", &LabelError{PID: pid, Kind: SELinuxUnavailable, Err: errSELinuxDisabled}
+	}
+	return getLabelForPID(pid)
+}
+
+// processStartTime reads the start time of a process, in clock ticks since
+// boot. It goes through raw syscalls to keep the cache validation cheap.
+func processStartTime(pid int) (uint64, error) {
+	fd, err := syscall.Open(fmt.Sprintf("/proc/%d/stat", pid), syscall.O_RDONLY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer syscall.Close(fd)
+
+	buf := make([]byte, procStatBufferSize)
+	n, err := syscall.Read(fd, buf)
+	if err != nil {
+		return 0, err
+	}
+	return parseStartTime(buf[:n])
+}
+
+func parseStartTime(stat []byte) (uint64, error) {
+	// the command name is wrapped in parenthesis and may contain spaces,
+	// so only the fields following the last closing one can be split safely.
+	commEnd := bytes.LastIndexByte(stat, ')')
+	if commEnd < 0 {
+		return 0, fmt.Errorf("malformed process stat: missing command name")
+	}
+	fields := bytes.Fields(stat[commEnd+1:])
+	if len(fields) <= startTimeFieldIndex {
+		return 0, fmt.Errorf("malformed process stat: only %d fields after the command name", len(fields))
+	}
+	return strconv.ParseUint(string(fields[startTimeFieldIndex]), 10, 64)
+}
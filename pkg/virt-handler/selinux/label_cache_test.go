@@ -0,0 +1,158 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Label cache", func() {
+
+	var cache *labelCache
+	var startTimes map[int]uint64
+	var labelReads int
+
+	BeforeEach(func() {
+		labelReads = 0
+		startTimes = map[int]uint64{1234: 100}
+		cache = newLabelCache(func(pid int) (uint64, error) {
+			startTime, exists := startTimes[pid]
+			if !exists {
+				return 0, syscall.ENOENT
+			}
+			return startTime, nil
+		}, func(pid int) (string, error) {
+			labelReads++
+			return fmt.Sprintf("system_u:system_r:container_t:s0:c%d", labelReads), nil
+		})
+	})
+
+	It("should only read the label once for the same live process", func() {
+		for i := 0; i < 10; i++ {
+			Expect(cache.get(1234)).To(Equal("system_u:system_r:container_t:s0:c1"))
+		}
+		Expect(labelReads).To(Equal(1))
+	})
+
+	It("should read the label again when the pid got recycled", func() {
+		Expect(cache.get(1234)).To(Equal("system_u:system_r:container_t:s0:c1"))
+		startTimes[1234] = 200
+		Expect(cache.get(1234)).To(Equal("system_u:system_r:container_t:s0:c2"))
+		Expect(labelReads).To(Equal(2))
+	})
+
+	It("should read the label again once flushed", func() {
+		Expect(cache.get(1234)).To(Equal("system_u:system_r:container_t:s0:c1"))
+		cache.flush(1234)
+		Expect(cache.get(1234)).To(Equal("system_u:system_r:container_t:s0:c2"))
+	})
+
+	It("should report a gone process as PIDNotFound", func() {
+		_, err := cache.get(4321)
+		Expect(IsLabelErrorKind(err, PIDNotFound)).To(BeTrue())
+		Expect(labelReads).To(BeZero())
+	})
+
+	Context("parsing the process start time", func() {
+		It("should handle command names with spaces and parenthesis", func() {
+			stat := []byte("1234 (qemu (kvm) x) S 1 1234 1234 0 -1 4194560 5000 0 0 0 10 20 0 0 20 0 4 0 98765 1000000 500 18446744073709551615")
+			Expect(parseStartTime(stat)).To(Equal(uint64(98765)))
+		})
+
+		It("should fail on truncated stat content", func() {
+			_, err := parseStartTime([]byte("1234 (qemu) S 1 1234"))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should read the start time of the current process", func() {
+			startTime, err := processStartTime(os.Getpid())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(startTime).ToNot(BeZero())
+		})
+	})
+
+	Context("looking up the label of a pid", func() {
+		var orgLabelCache *labelCache
+		var enabled bool
+
+		BeforeEach(func() {
+			orgLabelCache = defaultLabelCache
+			defaultLabelCache = newLabelCache(processStartTime, func(pid int) (string, error) {
+				labelReads++
+				return testLauncherLabel, nil
+			})
+			enabled = true
+			detectSELinux = func() (SELinux, bool, error) {
+				return nil, enabled, nil
+			}
+			ResetSELinuxDetectionForTest()
+		})
+
+		AfterEach(func() {
+			defaultLabelCache = orgLabelCache
+			detectSELinux = NewSELinux
+			ResetSELinuxDetectionForTest()
+		})
+
+		It("should return the label of an existing pid", func() {
+			Expect(LabelForPID(os.Getpid())).To(Equal(testLauncherLabel))
+			Expect(LabelForPID(os.Getpid())).To(Equal(testLauncherLabel))
+			Expect(labelReads).To(Equal(1))
+		})
+
+		It("should report a missing pid as PIDNotFound", func() {
+			_, err := LabelForPID(1 << 30)
+			Expect(IsLabelErrorKind(err, PIDNotFound)).To(BeTrue())
+			Expect(labelReads).To(BeZero())
+		})
+
+		It("should report SELinuxUnavailable without reading the label on a non-selinux host", func() {
+			enabled = false
+			ResetSELinuxDetectionForTest()
+
+			_, err := LabelForPID(os.Getpid())
+			Expect(IsLabelErrorKind(err, SELinuxUnavailable)).To(BeTrue())
+			Expect(errors.Is(err, errSELinuxDisabled)).To(BeTrue())
+			Expect(labelReads).To(BeZero())
+		})
+	})
+})
+
+func BenchmarkLabelLookup(b *testing.B) {
+	pid := os.Getpid()
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			readLabelForPID(pid)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		cache := newLabelCache(processStartTime, readLabelForPID)
+		for i := 0; i < b.N; i++ {
+			cache.get(pid)
+		}
+	})
+}
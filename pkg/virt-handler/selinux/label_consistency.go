@@ -0,0 +1,97 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LabelMismatch is a path whose label doesn't match the label of the launcher
+// using it, e.g. a device node carrying the MCS categories of another VMI.
+type LabelMismatch struct {
+	Path string `json:"path"`
+	// Label is the label of the path, empty if it couldn't be read
+	Label string `json:"label,omitempty"`
+	// LauncherLabel is the label the path is expected to carry
+	LauncherLabel string `json:"launcherLabel"`
+	// Difference lists the components of Label differing from LauncherLabel
+	Difference string `json:"difference,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (m LabelMismatch) String() string {
+	if m.Error != "" {
+		return fmt.Sprintf("%s: %s", m.Path, m.Error)
+	}
+	return fmt.Sprintf("%s is labeled %s instead of the file label of the launcher %s (%s)", m.Path, m.Label, m.LauncherLabel, m.Difference)
+}
+
+// VerifyFileLabels compares the label of each of the paths with the label of
+// the launcher, the one the relabels apply, and returns the paths which don't
+// match it. The paths matching the relabel skip paths aren't verified. Nothing
+// is verified without selinux.
+func (ce ContextExecutor) VerifyFileLabels(paths ...string) []LabelMismatch {
+	launcherLabel := ce.getFileLabel()
+	if launcherLabel == "" {
+		return nil
+	}
+	mismatches := make([]*LabelMismatch, len(paths))
+	ce.forEachPath(len(paths), func(manager fileLabelManager, i int) relabelResult {
+		path := paths[i]
+		if ce.skipsRelabel(path) {
+			return relabelResult{skipped: true}
+		}
+		label, err := manager.FileLabel(path)
+		if err != nil {
+			mismatches[i] = &LabelMismatch{
+				Path:          path,
+				LauncherLabel: launcherLabel,
+				Error:         fmt.Sprintf("failed to retrieve the selinux label: %v", err),
+			}
+		} else if label != launcherLabel {
+			mismatches[i] = &LabelMismatch{
+				Path:          path,
+				Label:         label,
+				LauncherLabel: launcherLabel,
+				Difference:    diffLabels(launcherLabel, label),
+			}
+		}
+		return relabelResult{}
+	})
+
+	var result []LabelMismatch
+	for _, mismatch := range mismatches {
+		if mismatch != nil {
+			result = append(result, *mismatch)
+		}
+	}
+	return result
+}
+
+// FormatLabelMismatches describes the mismatches in a single line, e.g. for
+// the message of a condition.
+func FormatLabelMismatches(mismatches []LabelMismatch) string {
+	descriptions := make([]string, 0, len(mismatches))
+	for _, mismatch := range mismatches {
+		descriptions = append(descriptions, mismatch.String())
+	}
+	return strings.Join(descriptions, "; ")
+}
@@ -0,0 +1,98 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+var _ = Describe("Label consistency", func() {
+	const launcherPID = 1234
+	const matchingDevice = "/var/run/kubevirt/hotplug-disks/matching"
+	const mismatchingDevice = "/var/run/kubevirt/hotplug-disks/mismatching"
+	const otherVMILabel = "system_u:object_r:container_file_t:s0:c3,c4"
+
+	var manager *testutils.FakeLabelManager
+
+	BeforeEach(func() {
+		manager = testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(launcherPID, testLauncherLabel)
+		manager.SetProcessLabel(os.Getpid(), testOriginalLabel)
+		Expect(manager.SetFileLabel(matchingDevice, testLauncherFileLabel)).To(Succeed())
+		Expect(manager.SetFileLabel(mismatchingDevice, otherVMILabel)).To(Succeed())
+	})
+
+	newExecutor := func(options ...Option) *ContextExecutor {
+		ce, err := NewContextExecutorWithLabelManager(manager, launcherPID, nil, options...)
+		Expect(err).ToNot(HaveOccurred())
+		return ce
+	}
+
+	It("should not report the devices carrying the file label of the launcher", func() {
+		Expect(newExecutor().VerifyFileLabels(matchingDevice)).To(BeEmpty())
+	})
+
+	It("should report the devices carrying the process label of the launcher", func() {
+		Expect(manager.SetFileLabel(matchingDevice, testLauncherLabel)).To(Succeed())
+		mismatches := newExecutor().VerifyFileLabels(matchingDevice)
+		Expect(mismatches).To(HaveLen(1))
+		Expect(mismatches[0].Difference).To(Equal("role object_r -> system_r, type container_file_t -> container_t"))
+	})
+
+	It("should report the devices carrying another label", func() {
+		mismatches := newExecutor().VerifyFileLabels(matchingDevice, mismatchingDevice)
+		Expect(mismatches).To(Equal([]LabelMismatch{{
+			Path:          mismatchingDevice,
+			Label:         otherVMILabel,
+			LauncherLabel: testLauncherFileLabel,
+			Difference:    "level s0:c1,c2 -> s0:c3,c4",
+		}}))
+		Expect(FormatLabelMismatches(mismatches)).To(Equal(
+			mismatchingDevice + " is labeled " + otherVMILabel + " instead of the file label of the launcher " + testLauncherFileLabel + " (level s0:c1,c2 -> s0:c3,c4)"))
+	})
+
+	It("should report the devices whose label can't be read", func() {
+		mismatches := newExecutor(WithRelabelParallelism(2)).VerifyFileLabels("/var/run/kubevirt/hotplug-disks/missing", mismatchingDevice)
+		Expect(mismatches).To(HaveLen(2))
+		Expect(mismatches[0].Path).To(Equal("/var/run/kubevirt/hotplug-disks/missing"))
+		Expect(mismatches[0].Error).To(ContainSubstring("failed to retrieve the selinux label"))
+		Expect(mismatches[1].Path).To(Equal(mismatchingDevice))
+	})
+
+	It("should compare the devices with the label shared with other pids", func() {
+		manager.SetProcessLabel(2, "system_u:system_r:container_t:s0:c3,c4")
+		mismatches := newExecutor(WithSharedMCS(2)).VerifyFileLabels(matchingDevice)
+		Expect(mismatches).To(HaveLen(1))
+		Expect(mismatches[0].LauncherLabel).To(Equal("system_u:object_r:container_file_t:s0:c1.c4"))
+	})
+
+	It("should not verify the relabel skip paths", func() {
+		Expect(newExecutor(WithRelabelSkipPaths([]string{mismatchingDevice})).VerifyFileLabels(mismatchingDevice)).To(BeEmpty())
+	})
+
+	It("should not verify anything without selinux", func() {
+		Expect(ContextExecutor{}.VerifyFileLabels(mismatchingDevice)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,99 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// pendingLabelRead is a read of /proc/<pid>/attr/current callers wait for
+// until their deadline.
+type pendingLabelRead struct {
+	done  chan struct{}
+	label string
+	err   error
+}
+
+// WithLabelReadTimeout makes the executor give up on the label reads of the
+// launcher and of virt-handler taking longer than timeout, e.g. on a stuck
+// proc filesystem, instead of hanging its creation. Only the reads through the
+// label cache are bounded, the ones of a LabelManager given with
+// WithLabelManager are not.
+func WithLabelReadTimeout(timeout time.Duration) Option {
+	return func(ce *ContextExecutor) {
+		ce.labelReadTimeout = timeout
+	}
+}
+
+// LabelForPIDContext returns the selinux label of the given process like
+// LabelForPID, but gives up once ctx is done. The error is then a
+// *LabelError of kind ProcNotReadable wrapping the error of ctx.
+func LabelForPIDContext(ctx context.Context, pid int) (string, error) {
+	if !isSELinuxEnabled() {
+		return "", &LabelError{PID: pid, Kind: SELinuxUnavailable, Err: errSELinuxDisabled}
+	}
+	return defaultLabelCache.getContext(ctx, pid)
+}
+
+func getLabelForPIDWithTimeout(pid int, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return defaultLabelCache.getContext(ctx, pid)
+}
+
+// getContext returns the label of pid like get, reading it on a goroutine the
+// caller stops waiting for once ctx is done. A read blocked in the kernel
+// can't be interrupted, so the goroutine keeps waiting for it, but the callers
+// reading the label of the same pid until then join that read instead of
+// starting another one: at most one goroutine per pid is ever left behind, and
+// it exits as soon as the read returns.
+func (c *labelCache) getContext(ctx context.Context, pid int) (string, error) {
+	return c.getWith(pid, func(pid int) (string, error) {
+		read := c.startPendingRead(pid)
+		select {
+		case <-read.done:
+			return read.label, read.err
+		case <-ctx.Done():
+			return "", &LabelError{PID: pid, Kind: ProcNotReadable, Err: fmt.Errorf("reading the label of the process timed out: %w", ctx.Err())}
+		}
+	})
+}
+
+// startPendingRead returns the pending read of pid, starting it if there is
+// none.
+func (c *labelCache) startPendingRead(pid int) *pendingLabelRead {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if read, exists := c.pending[pid]; exists {
+		return read
+	}
+	read := &pendingLabelRead{done: make(chan struct{})}
+	c.pending[pid] = read
+	go func() {
+		read.label, read.err = c.readLabel(pid)
+		c.lock.Lock()
+		delete(c.pending, pid)
+		c.lock.Unlock()
+		close(read.done)
+	}()
+	return read
+}
@@ -0,0 +1,148 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Label reads with a deadline", func() {
+
+	var orgLabelCache *labelCache
+	var release chan struct{}
+	var labelReads int32
+
+	BeforeEach(func() {
+		orgLabelCache = defaultLabelCache
+		release = make(chan struct{})
+		atomic.StoreInt32(&labelReads, 0)
+		// reads block until released, like on a stuck proc filesystem. The
+		// channel of the test is captured, reads left pending by a test must
+		// not pick up the one of the next test.
+		released := release
+		defaultLabelCache = newLabelCache(processStartTime, func(pid int) (string, error) {
+			atomic.AddInt32(&labelReads, 1)
+			<-released
+			return testLauncherLabel, nil
+		})
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, true, nil
+		}
+		ResetSELinuxDetectionForTest()
+	})
+
+	AfterEach(func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+		// the pending reads update the cache of the test, let them finish
+		// before putting back the default one
+		Eventually(func() int {
+			defaultLabelCache.lock.Lock()
+			defer defaultLabelCache.lock.Unlock()
+			return len(defaultLabelCache.pending)
+		}).Should(BeZero())
+		defaultLabelCache = orgLabelCache
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+	})
+
+	It("should give up on a slow read once the deadline fires", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := LabelForPIDContext(ctx, os.Getpid())
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		Expect(IsLabelErrorKind(err, ProcNotReadable)).To(BeTrue())
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+
+	It("should return the label when it is read before the deadline", func() {
+		close(release)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		Expect(LabelForPIDContext(ctx, os.Getpid())).To(Equal(testLauncherLabel))
+		// later lookups are served by the cache
+		Expect(LabelForPID(os.Getpid())).To(Equal(testLauncherLabel))
+		Expect(atomic.LoadInt32(&labelReads)).To(Equal(int32(1)))
+	})
+
+	It("should join the pending read instead of leaking a goroutine per caller", func() {
+		for i := 0; i < 20; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+			_, err := LabelForPIDContext(ctx, os.Getpid())
+			cancel()
+			Expect(err).To(HaveOccurred())
+		}
+		Expect(atomic.LoadInt32(&labelReads)).To(Equal(int32(1)))
+
+		close(release)
+		Eventually(func() int {
+			defaultLabelCache.lock.Lock()
+			defer defaultLabelCache.lock.Unlock()
+			return len(defaultLabelCache.pending)
+		}).Should(BeZero())
+	})
+
+	It("should start a new read once the pending one returned", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		_, err := LabelForPIDContext(ctx, os.Getpid())
+		Expect(err).To(HaveOccurred())
+
+		close(release)
+		Eventually(func() int {
+			defaultLabelCache.lock.Lock()
+			defer defaultLabelCache.lock.Unlock()
+			return len(defaultLabelCache.pending)
+		}).Should(BeZero())
+
+		Expect(LabelForPIDContext(context.Background(), os.Getpid())).To(Equal(testLauncherLabel))
+		Expect(atomic.LoadInt32(&labelReads)).To(Equal(int32(2)))
+	})
+
+	It("should not read the label on a non-selinux host", func() {
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, false, nil
+		}
+		ResetSELinuxDetectionForTest()
+
+		_, err := LabelForPIDContext(context.Background(), os.Getpid())
+		Expect(IsLabelErrorKind(err, SELinuxUnavailable)).To(BeTrue())
+		Expect(atomic.LoadInt32(&labelReads)).To(BeZero())
+	})
+
+	It("should fail the creation of an executor whose label reads time out", func() {
+		start := time.Now()
+		_, err := NewContextExecutor(os.Getpid(), exec.Command("true"), WithLabelReadTimeout(50*time.Millisecond))
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+	})
+})
@@ -0,0 +1,163 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	labelIdentifierRegex = regexp.MustCompile(`^[A-Za-z0-9_.]+$`)
+	// a level is a sensitivity optionally followed by a category set, e.g. s0:c1,c5.c10
+	levelRegex = regexp.MustCompile(`^s[0-9]+(:c[0-9]+(\.c[0-9]+)?(,c[0-9]+(\.c[0-9]+)?)*)?$`)
+)
+
+// Label is a parsed selinux label. Level is empty for labels without one.
+type Label struct {
+	User  string
+	Role  string
+	Type  string
+	Level string
+}
+
+// ParseLabel parses a selinux label of the user:role:type[:level] shape,
+// where level may be a low-high MLS range. An empty label parses into an
+// empty Label, since it is what hosts without selinux report.
+func ParseLabel(label string) (Label, error) {
+	parsed, hasLevel, err := splitLabel(label)
+	if err != nil {
+		return Label{}, err
+	}
+	if hasLevel {
+		if err := validateLevel(parsed.Level); err != nil {
+			return Label{}, fmt.Errorf("malformed selinux label %q: %v", label, err)
+		}
+	}
+	return parsed, nil
+}
+
+// splitLabel splits label into its components, checking all of them but the
+// level, which may be missing or empty.
+func splitLabel(label string) (parsed Label, hasLevel bool, err error) {
+	if label == "" {
+		return Label{}, false, nil
+	}
+	parts := strings.SplitN(label, ":", 4)
+	if len(parts) < 3 {
+		return Label{}, false, fmt.Errorf("malformed selinux label %q: expected user:role:type[:level]", label)
+	}
+	for i, component := range labelComponents[:3] {
+		if !labelIdentifierRegex.MatchString(parts[i]) {
+			return Label{}, false, fmt.Errorf("malformed selinux label %q: invalid %s %q", label, component, parts[i])
+		}
+	}
+	parsed = Label{User: parts[0], Role: parts[1], Type: parts[2]}
+	if len(parts) == 4 {
+		parsed.Level = parts[3]
+		hasLevel = true
+	}
+	return parsed, hasLevel, nil
+}
+
+// String formats the label as user:role:type[:level], the inverse of
+// ParseLabel.
+func (l Label) String() string {
+	if l == (Label{}) {
+		return ""
+	}
+	label := l.User + ":" + l.Role + ":" + l.Type
+	if l.Level != "" {
+		label += ":" + l.Level
+	}
+	return label
+}
+
+// validateLabel checks that a selinux label is well formed, see ParseLabel.
+func validateLabel(label string) error {
+	_, err := ParseLabel(label)
+	return err
+}
+
+func validateLevel(level string) error {
+	levels := strings.Split(level, "-")
+	if len(levels) > 2 {
+		return fmt.Errorf("invalid level range %q", level)
+	}
+	for _, l := range levels {
+		if !levelRegex.MatchString(l) {
+			return fmt.Errorf("invalid level %q", l)
+		}
+	}
+	return nil
+}
+
+var labelComponents = [...]string{"user", "role", "type", "level"}
+
+// diffLabels lists the components which differ between two selinux labels,
+// e.g. "type spc_t -> container_t, level s0 -> s0:c1,c2", or "no difference".
+// Components missing from a label are shown as "". The labels are walked in
+// place, only the result is allocated.
+func diffLabels(from string, to string) string {
+	if from == to {
+		return "no difference"
+	}
+	var diff strings.Builder
+	// enough for all the components to differ
+	diff.Grow(len(from) + len(to) + 64)
+	for i, component := range labelComponents {
+		last := i == len(labelComponents)-1
+		var fromPart, toPart string
+		fromPart, from = nextLabelComponent(from, last)
+		toPart, to = nextLabelComponent(to, last)
+		if fromPart == toPart {
+			continue
+		}
+		if diff.Len() > 0 {
+			diff.WriteString(", ")
+		}
+		diff.WriteString(component)
+		diff.WriteByte(' ')
+		writeLabelComponent(&diff, fromPart)
+		diff.WriteString(" -> ")
+		writeLabelComponent(&diff, toPart)
+	}
+	return diff.String()
+}
+
+// nextLabelComponent splits the first component off the rest of a label. The
+// last component, the level, spans until the end since it contains colons.
+func nextLabelComponent(rest string, last bool) (component string, remaining string) {
+	if !last {
+		if i := strings.IndexByte(rest, ':'); i >= 0 {
+			return rest[:i], rest[i+1:]
+		}
+	}
+	return rest, ""
+}
+
+func writeLabelComponent(diff *strings.Builder, component string) {
+	if component == "" {
+		diff.WriteString(`""`)
+		return
+	}
+	diff.WriteString(component)
+}
@@ -0,0 +1,65 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"testing"
+)
+
+// FuzzParseLabel checks that ParseLabel never panics, and that the labels it
+// accepts are formatted back unchanged and parse into the same components.
+func FuzzParseLabel(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"kernel",
+		"system_u:system_r:container_t",
+		"system_u:system_r:container_t:",
+		"system_u:system_r:container_t:s0:c1,c2",
+		"system_u:system_r:spc_t:s0:c0.c1023",
+		"system_u:system_r:virt_launcher.process:s0-s0:c0.c1023",
+		"system_u:system_r:container_t:s0-s1-s2",
+		"system_u::container_t:s0",
+		"system_u:system_r:container_t:s0\x00",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, label string) {
+		parsed, err := ParseLabel(label)
+		if err != nil {
+			if parsed != (Label{}) {
+				t.Fatalf("ParseLabel(%q) returned %+v along with the error %v", label, parsed, err)
+			}
+			return
+		}
+		if formatted := parsed.String(); formatted != label {
+			t.Fatalf("ParseLabel(%q) doesn't round trip, formatted back as %q", label, formatted)
+		}
+		reparsed, err := ParseLabel(parsed.String())
+		if err != nil {
+			t.Fatalf("the formatted label %q of %q doesn't parse: %v", parsed.String(), label, err)
+		}
+		if reparsed != parsed {
+			t.Fatalf("the formatted label %q of %q parses into %+v instead of %+v", parsed.String(), label, reparsed, parsed)
+		}
+	})
+}
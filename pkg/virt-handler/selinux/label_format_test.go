@@ -0,0 +1,102 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Label format", func() {
+
+	table.DescribeTable("should accept", func(label string) {
+		Expect(validateLabel(label)).To(Succeed())
+	},
+		table.Entry("an empty label", ""),
+		table.Entry("a label without level", "system_u:system_r:container_t"),
+		table.Entry("a single sensitivity", "system_u:system_r:container_t:s0"),
+		table.Entry("categories", "system_u:system_r:container_t:s0:c1,c2"),
+		table.Entry("a category range", "system_u:system_r:spc_t:s0:c0.c1023"),
+		table.Entry("an MLS range", "system_u:system_r:virt_launcher.process:s0-s0:c0.c1023"),
+		table.Entry("an MLS range with categories on both ends", "unconfined_u:unconfined_r:unconfined_t:s0:c1-s15:c0.c1023"),
+	)
+
+	table.DescribeTable("should reject", func(label string) {
+		Expect(validateLabel(label)).ToNot(Succeed())
+	},
+		table.Entry("a kernel thread like label", "kernel"),
+		table.Entry("a missing type", "system_u:system_r"),
+		table.Entry("an empty role", "system_u::container_t:s0"),
+		table.Entry("an empty level", "system_u:system_r:container_t:"),
+		table.Entry("a level without sensitivity", "system_u:system_r:container_t:c1"),
+		table.Entry("a trailing category separator", "system_u:system_r:container_t:s0:c1,"),
+		table.Entry("a malformed category range", "system_u:system_r:container_t:s0:c1.c"),
+		table.Entry("an open MLS range", "system_u:system_r:container_t:s0-"),
+		table.Entry("a double MLS range", "system_u:system_r:container_t:s0-s1-s2"),
+		table.Entry("whitespace", "system_u:system_r:container t:s0"),
+	)
+
+	table.DescribeTable("should parse", func(label string, expected Label) {
+		parsed, err := ParseLabel(label)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsed).To(Equal(expected))
+		Expect(parsed.String()).To(Equal(label))
+	},
+		table.Entry("an empty label", "", Label{}),
+		table.Entry("a label without level", "system_u:system_r:container_t",
+			Label{User: "system_u", Role: "system_r", Type: "container_t"}),
+		table.Entry("a label with categories", "system_u:system_r:container_t:s0:c1,c2",
+			Label{User: "system_u", Role: "system_r", Type: "container_t", Level: "s0:c1,c2"}),
+		table.Entry("a label with an MLS range", "system_u:system_r:virt_launcher.process:s0-s0:c0.c1023",
+			Label{User: "system_u", Role: "system_r", Type: "virt_launcher.process", Level: "s0-s0:c0.c1023"}),
+	)
+
+	It("should not return the components of a malformed label", func() {
+		parsed, err := ParseLabel("system_u:system_r:container_t:c1")
+		Expect(err).To(MatchError(ContainSubstring(`invalid level "c1"`)))
+		Expect(parsed).To(Equal(Label{}))
+	})
+
+	table.DescribeTable("should diff", func(from string, to string, expectedDiff string) {
+		Expect(diffLabels(from, to)).To(Equal(expectedDiff))
+	},
+		table.Entry("equal labels", "system_u:system_r:container_t:s0:c1,c2", "system_u:system_r:container_t:s0:c1,c2", "no difference"),
+		table.Entry("the user", "system_u:system_r:container_t:s0", "unconfined_u:system_r:container_t:s0", "user system_u -> unconfined_u"),
+		table.Entry("the role", "system_u:object_r:container_t:s0", "system_u:system_r:container_t:s0", "role object_r -> system_r"),
+		table.Entry("the type", "system_u:system_r:spc_t:s0", "system_u:system_r:container_t:s0", "type spc_t -> container_t"),
+		table.Entry("the level", "system_u:system_r:container_t:s0:c1,c2", "system_u:system_r:container_t:s0:c3,c4", "level s0:c1,c2 -> s0:c3,c4"),
+		table.Entry("an MLS range level", "system_u:system_r:spc_t:s0-s0:c0.c1023", "system_u:system_r:spc_t:s0", "level s0-s0:c0.c1023 -> s0"),
+		table.Entry("several components", "system_u:system_r:spc_t:s0", "system_u:system_r:container_t:s0:c1,c2", "type spc_t -> container_t, level s0 -> s0:c1,c2"),
+		table.Entry("a missing level", "system_u:system_r:container_t", "system_u:system_r:container_t:s0", `level "" -> s0`),
+		table.Entry("an empty label", "", "system_u:system_r:container_t:s0", `user "" -> system_u, role "" -> system_r, type "" -> container_t, level "" -> s0`),
+	)
+
+	It("should only allocate the diff", func() {
+		Expect(testing.AllocsPerRun(100, func() {
+			diffLabels("system_u:system_r:spc_t:s0", "system_u:system_r:container_t:s0:c1,c2")
+		})).To(BeNumerically("<=", 1))
+		Expect(testing.AllocsPerRun(100, func() {
+			diffLabels("system_u:system_r:spc_t:s0", "system_u:system_r:spc_t:s0")
+		})).To(BeZero())
+	})
+})
@@ -0,0 +1,104 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"github.com/opencontainers/selinux/go-selinux"
+)
+
+// LabelManager reads and applies the selinux labels of files and of the
+// calling thread. The labels of processes are read from their
+// /proc/<pid>/attr/current file, or the attr file set by WithLauncherLabelAttr.
+type LabelManager interface {
+	FileLabel(path string) (string, error)
+	SetFileLabel(path string, label string) error
+	SetExecLabel(label string) error
+}
+
+// hostLabelManager applies the labels through the selinux of the host.
+type hostLabelManager struct{}
+
+func (hostLabelManager) FileLabel(path string) (string, error) {
+	return selinux.FileLabel(path)
+}
+
+func (hostLabelManager) SetFileLabel(path string, label string) error {
+	return selinux.SetFileLabel(path, label)
+}
+
+func (hostLabelManager) SetExecLabel(label string) error {
+	return selinux.SetExecLabel(label)
+}
+
+func (hostLabelManager) SetCurrentLabel(label string) error {
+	return selinux.SetTaskLabel(label)
+}
+
+func (hostLabelManager) SetFSCreateLabel(label string) error {
+	return selinux.SetFSCreateLabel(label)
+}
+
+func (hostLabelManager) ExecLabel() (string, error) {
+	return selinux.ExecLabel()
+}
+
+// NewLabelManager returns the LabelManager backed by the selinux of the host.
+func NewLabelManager() LabelManager {
+	return hostLabelManager{}
+}
+
+var defaultLabelManager = NewLabelManager()
+
+// WithLabelManager makes the executor read and apply all labels through
+// manager, instead of the selinux of the host. The launcher labels are then
+// read from the manager as well, bypassing the process label cache. A nil
+// manager keeps the selinux of the host.
+func WithLabelManager(manager LabelManager) Option {
+	return func(ce *ContextExecutor) {
+		ce.labelManager = manager
+	}
+}
+
+func (ce ContextExecutor) getLabelManager() LabelManager {
+	if ce.labelManager == nil {
+		return defaultLabelManager
+	}
+	return ce.labelManager
+}
+
+// getLabelForPID returns the raw label of pid, as seen by the label manager
+// of the executor.
+func (ce ContextExecutor) getLabelForPID(pid int) (string, error) {
+	label, err := ce.readLabelForPID(pid)
+	if err != nil {
+		return "", err
+	}
+	return ce.normalizeLabel(label)
+}
+
+func (ce ContextExecutor) readLabelForPID(pid int) (string, error) {
+	if ce.labelManager == nil {
+		if ce.labelReadTimeout > 0 {
+			return getLabelForPIDWithTimeout(pid, ce.labelReadTimeout)
+		}
+		return getLabelForPID(pid)
+	}
+	return readLabelForPIDWith(ce.labelManager, pid)
+}
@@ -0,0 +1,194 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+// execLabelFunc switches the exec label with a function, and leaves the file
+// labels to the host.
+type execLabelFunc func(label string) error
+
+func (f execLabelFunc) SetExecLabel(label string) error {
+	return f(label)
+}
+
+func (execLabelFunc) FileLabel(path string) (string, error) {
+	return hostLabelManager{}.FileLabel(path)
+}
+
+func (execLabelFunc) SetFileLabel(path string, label string) error {
+	return hostLabelManager{}.SetFileLabel(path, label)
+}
+
+var _ = Describe("ContextExecutor with a fake label manager", func() {
+	const launcherPID = 1234
+
+	var manager *testutils.FakeLabelManager
+	var restoreProcRoot func()
+
+	BeforeEach(func() {
+		restoreProcRoot = fakeProcRoot(launcherPID)
+		manager = testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(launcherPID, testLauncherLabel)
+		manager.SetProcessLabel(os.Getpid(), testOriginalLabel)
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, true, nil
+		}
+		ResetSELinuxDetectionForTest()
+	})
+
+	AfterEach(func() {
+		restoreProcRoot()
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+	})
+
+	It("should read the process labels from the label manager", func() {
+		ce, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ce.desiredLabel).To(Equal(testLauncherLabel))
+		Expect(ce.originalLabel).To(Equal(testOriginalLabel))
+	})
+
+	It("should report launchers unknown to the label manager as gone", func() {
+		_, err := NewContextExecutor(4321, exec.Command("true"), WithLabelManager(manager))
+		Expect(IsLabelErrorKind(err, PIDNotFound)).To(BeTrue())
+	})
+
+	It("should run the command in the launcher context and switch back", func() {
+		ce, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ce.Execute()).To(Succeed())
+		Expect(manager.ExecLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+	})
+
+	It("should not run the command if the context switch fails", func() {
+		dir, err := ioutil.TempDir("", "kubevirt-label-manager")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		marker := filepath.Join(dir, "marker")
+		ce, err := NewContextExecutor(launcherPID, exec.Command("touch", marker), WithLabelManager(manager))
+		Expect(err).ToNot(HaveOccurred())
+		manager.FailSetExecLabel(syscall.EACCES)
+
+		err = ce.Execute()
+		Expect(IsSELinuxError(err)).To(BeTrue())
+		_, err = os.Stat(marker)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("should relabel files and restore their labels", func() {
+		manager.SetFileLabel("/dev/vfio/1", testOriginalLabel)
+		ce, err := NewContextExecutor(launcherPID, nil, WithLabelManager(manager))
+		Expect(err).ToNot(HaveOccurred())
+
+		restore, err := ce.RelabelFiles("/dev/vfio/1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manager.FileLabel("/dev/vfio/1")).To(Equal(testLauncherFileLabel))
+
+		Expect(restore()).To(Succeed())
+		Expect(manager.FileLabel("/dev/vfio/1")).To(Equal(testOriginalLabel))
+	})
+
+	It("should report the relabel result of every path", func() {
+		manager.SetFileLabel("/dev/vfio/1", testOriginalLabel)
+		manager.SetFileLabel("/dev/vfio/2", testLauncherFileLabel)
+		ce, err := NewContextExecutor(launcherPID, nil, WithLabelManager(manager))
+		Expect(err).ToNot(HaveOccurred())
+
+		results := ce.EnsureFilesLabeledWithResults("/dev/vfio/1", "/dev/vfio/2", "/dev/vfio/3")
+		Expect(results).To(HaveLen(3))
+		Expect(results[0]).To(Equal(FileRelabel{Path: "/dev/vfio/1", PreviousLabel: testOriginalLabel, Label: testLauncherFileLabel, Relabeled: true}))
+		Expect(results[1]).To(Equal(FileRelabel{Path: "/dev/vfio/2", PreviousLabel: testLauncherFileLabel, Label: testLauncherFileLabel}))
+		Expect(results[2].Relabeled).To(BeFalse())
+		Expect(results[2].Error).To(ContainSubstring("failed to retrieve the selinux label of /dev/vfio/3"))
+		Expect(manager.FileLabel("/dev/vfio/1")).To(Equal(testLauncherFileLabel))
+	})
+
+	It("should merge the categories of shared pids from the label manager", func() {
+		manager.SetProcessLabel(5678, "system_u:system_r:container_t:s0:c3,c9")
+		ce, err := NewContextExecutor(launcherPID, nil, WithLabelManager(manager), WithSharedMCS(5678))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ce.getFileLabel()).To(Equal("system_u:object_r:container_file_t:s0:c1.c3,c9"))
+	})
+
+	Context("injected through the constructor", func() {
+		It("should run the command in the launcher context and switch back", func() {
+			ce, err := NewContextExecutorWithLabelManager(manager, launcherPID, exec.Command("true"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ce.desiredLabel).To(Equal(testLauncherLabel))
+			Expect(ce.originalLabel).To(Equal(testOriginalLabel))
+
+			Expect(ce.Execute()).To(Succeed())
+			Expect(manager.ExecLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+		})
+
+		It("should report launchers unknown to the label manager as gone", func() {
+			_, err := NewContextExecutorWithLabelManager(manager, 4321, exec.Command("true"))
+			Expect(IsLabelErrorKind(err, PIDNotFound)).To(BeTrue())
+		})
+
+		It("should reject a malformed launcher label", func() {
+			manager.SetProcessLabel(launcherPID, "container_t")
+			_, err := NewContextExecutorWithLabelManager(manager, launcherPID, exec.Command("true"))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail without running the command if the context switch fails", func() {
+			ce, err := NewContextExecutorWithLabelManager(manager, launcherPID, exec.Command("true"))
+			Expect(err).ToNot(HaveOccurred())
+			manager.FailSetExecLabel(syscall.EACCES)
+
+			err = ce.Execute()
+			Expect(IsSELinuxError(err)).To(BeTrue())
+			Expect(errors.Is(err, syscall.EACCES)).To(BeTrue())
+			Expect(manager.ExecLabels()).To(BeEmpty())
+		})
+
+		It("should apply the options after the label manager", func() {
+			manager.SetProcessLabel(5678, "system_u:system_r:container_t:s0:c3,c9")
+			ce, err := NewContextExecutorWithLabelManager(manager, launcherPID, nil, WithSharedMCS(5678))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ce.getFileLabel()).To(Equal("system_u:object_r:container_file_t:s0:c1.c3,c9"))
+		})
+
+		It("should keep the selinux of the host by default", func() {
+			ce, err := NewContextExecutor(os.Getpid(), exec.Command("true"))
+			if err != nil {
+				Skip("the labels of the host can't be read: " + err.Error())
+			}
+			Expect(ce.labelManager).To(BeNil())
+			Expect(ce.getLabelManager()).To(Equal(defaultLabelManager))
+		})
+	})
+})
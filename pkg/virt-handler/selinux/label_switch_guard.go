@@ -0,0 +1,61 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// activeLabelSwitches are the OS threads switched to the selinux context of a
+// launcher, by thread id, with the pid of the launcher.
+var activeLabelSwitches = struct {
+	sync.Mutex
+	launcherPIDs map[int]int
+}{launcherPIDs: map[int]int{}}
+
+// beginLabelSwitch records that the calling thread, which has to be locked,
+// is switched to the context of the launcher pid, and returns its thread id.
+func beginLabelSwitch(pid int) int {
+	tid := unix.Gettid()
+	activeLabelSwitches.Lock()
+	defer activeLabelSwitches.Unlock()
+	activeLabelSwitches.launcherPIDs[tid] = pid
+	return tid
+}
+
+// endLabelSwitch forgets the switch of the thread tid, once reset or about to
+// be destroyed.
+func endLabelSwitch(tid int) {
+	activeLabelSwitches.Lock()
+	defer activeLabelSwitches.Unlock()
+	delete(activeLabelSwitches.launcherPIDs, tid)
+}
+
+// activeLabelSwitch returns the launcher the calling thread is switched to,
+// if any. Only a goroutine locked to a switched thread can run on it, so that
+// an unlocked caller never finds an active switch.
+func activeLabelSwitch() (pid int, active bool) {
+	activeLabelSwitches.Lock()
+	defer activeLabelSwitches.Unlock()
+	pid, active = activeLabelSwitches.launcherPIDs[unix.Gettid()]
+	return pid, active
+}
@@ -0,0 +1,107 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"os/exec"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Label switch guard", func() {
+
+	BeforeEach(func() {
+		defaultLabelManager = execLabelFunc(func(label string) error {
+			return nil
+		})
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, true, nil
+		}
+		ResetSELinuxDetectionForTest()
+	})
+
+	AfterEach(func() {
+		defaultLabelManager = NewLabelManager()
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+	})
+
+	newExecutor := func(hooks ...func() error) ContextExecutor {
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("true"), desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+		for _, hook := range hooks {
+			WithPostExecHook(hook)(&ce)
+		}
+		return ce
+	}
+
+	It("should reject a nested Execute with a clear error", func() {
+		var nestedErr error
+		outer := newExecutor(func() error {
+			nestedErr = newExecutor().Execute()
+			return nil
+		})
+		Expect(outer.Execute()).To(Succeed())
+		Expect(errors.Is(nestedErr, ErrNestedLabelSwitch)).To(BeTrue())
+		Expect(nestedErr.Error()).To(ContainSubstring("launcher pid 1"))
+	})
+
+	It("should fail the outer Execute if it propagates the nested error", func() {
+		outer := newExecutor(func() error {
+			return newExecutor().Execute()
+		})
+		Expect(outer.Execute()).To(MatchError(ContainSubstring(ErrNestedLabelSwitch.Error())))
+	})
+
+	It("should allow executing again once the switch ended", func() {
+		Expect(newExecutor().Execute()).To(Succeed())
+		Expect(newExecutor().Execute()).To(Succeed())
+		_, active := activeLabelSwitch()
+		Expect(active).To(BeFalse())
+	})
+
+	It("should forget the switches of the destroyed threads", func() {
+		ce := newExecutor()
+		WithResetMode(ResetSkip)(&ce)
+		Expect(ce.Execute()).To(Succeed())
+		activeLabelSwitches.Lock()
+		defer activeLabelSwitches.Unlock()
+		Expect(activeLabelSwitches.launcherPIDs).To(BeEmpty())
+	})
+
+	It("should allow concurrent executions from different goroutines", func() {
+		var wg sync.WaitGroup
+		errs := make([]error, 10)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				errs[i] = newExecutor().Execute()
+			}(i)
+		}
+		wg.Wait()
+		for _, err := range errs {
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+})
@@ -0,0 +1,184 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"kubevirt.io/client-go/log"
+)
+
+const (
+	// setransSocket is the socket of mcstransd, the daemon of mcstrans
+	// translating the levels of labels to human readable names, e.g. s0 to
+	// SystemLow.
+	setransSocket = "/var/run/setrans/.setrans-unix"
+	// setransTimeout bounds a translation, mcstransd answers from memory
+	setransTimeout = 2 * time.Second
+
+	// the request of the setrans protocol translating to a raw label
+	setransTransToRawContext uint32 = 3
+	// maxSetransLabelSize is the largest message mcstransd handles
+	maxSetransLabelSize = 8192
+)
+
+// LabelTranslator translates labels carrying the human readable levels of
+// mcstrans back into raw labels.
+type LabelTranslator interface {
+	TransToRaw(label string) (string, error)
+}
+
+// mcstransTranslator translates labels with mcstransd, like the _raw
+// functions of libselinux do.
+type mcstransTranslator struct {
+	socketPath string
+	timeout    time.Duration
+}
+
+// NewLabelTranslator returns the LabelTranslator asking the mcstransd of the
+// host.
+func NewLabelTranslator() LabelTranslator {
+	return mcstransTranslator{socketPath: setransSocket, timeout: setransTimeout}
+}
+
+var defaultLabelTranslator = NewLabelTranslator()
+
+// errMcstransUnavailable is wrapped by the errors of the translations asked
+// while mcstransd isn't running, e.g. on hosts without mcstrans.
+var errMcstransUnavailable = errors.New("mcstrans is not running")
+
+// nativeEndian is the byte order of the host, mcstransd reads and writes the
+// sizes of its messages as they are in memory.
+var nativeEndian = func() binary.ByteOrder {
+	one := uint16(1)
+	if *(*byte)(unsafe.Pointer(&one)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+// WithLabelTranslator makes the executor translate the launcher and
+// virt-handler labels carrying mcstrans levels through translator, instead of
+// the mcstransd of the host.
+func WithLabelTranslator(translator LabelTranslator) Option {
+	return func(ce *ContextExecutor) {
+		ce.labelTranslator = translator
+	}
+}
+
+func (ce ContextExecutor) getLabelTranslator() LabelTranslator {
+	if ce.labelTranslator == nil {
+		return defaultLabelTranslator
+	}
+	return ce.labelTranslator
+}
+
+// TransToRaw sends a request of the setrans protocol: the request type and
+// the sizes of its two strings in native byte order, followed by the
+// NUL terminated strings. The answer echoes the request type, followed by
+// the size of the translated label and the result of the translation.
+func (t mcstransTranslator) TransToRaw(label string) (string, error) {
+	conn, err := net.DialTimeout("unix", t.socketPath, t.timeout)
+	if err != nil {
+		if errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.ECONNREFUSED) {
+			return "", fmt.Errorf("%w: %v", errMcstransUnavailable, err)
+		}
+		return "", err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(t.timeout)); err != nil {
+		return "", err
+	}
+
+	var request bytes.Buffer
+	for _, field := range []uint32{setransTransToRawContext, uint32(len(label) + 1), 1} {
+		binary.Write(&request, nativeEndian, field)
+	}
+	request.WriteString(label)
+	request.Write([]byte{0, 0})
+	if _, err := conn.Write(request.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to send the translation of %q to mcstrans: %v", label, err)
+	}
+
+	var response struct {
+		Function uint32
+		Size     uint32
+		Result   int32
+	}
+	if err := binary.Read(conn, nativeEndian, &response); err != nil {
+		return "", fmt.Errorf("failed to read the translation of %q from mcstrans: %v", label, err)
+	}
+	if response.Function != setransTransToRawContext {
+		return "", fmt.Errorf("mcstrans answered request %d instead of %d", response.Function, setransTransToRawContext)
+	}
+	if response.Size > maxSetransLabelSize {
+		return "", fmt.Errorf("mcstrans answered a label of %d bytes", response.Size)
+	}
+	raw := make([]byte, response.Size)
+	if _, err := io.ReadFull(conn, raw); err != nil {
+		return "", fmt.Errorf("failed to read the translation of %q from mcstrans: %v", label, err)
+	}
+	if response.Result < 0 {
+		return "", fmt.Errorf("mcstrans failed to translate %q", label)
+	}
+	return strings.TrimRight(string(raw), "\x00"), nil
+}
+
+func (ce ContextExecutor) normalizeLabel(label string) (string, error) {
+	return normalizeLabel(ce.getLabelTranslator(), label)
+}
+
+// hasTranslatedLevel tells whether label is well formed but for its level,
+// which is not a raw MLS level, like the levels mcstrans shows.
+func hasTranslatedLevel(label string) bool {
+	parsed, hasLevel, err := splitLabel(label)
+	return err == nil && hasLevel && validateLevel(parsed.Level) != nil
+}
+
+// normalizeLabel returns the raw form of label, the only one SetExecLabel and
+// the label parsing accept. Raw labels are returned as they are, without
+// asking mcstrans. Without mcstrans the level can't be translated, label is
+// returned as it is and rejected as malformed by the label validation.
+func normalizeLabel(translator LabelTranslator, label string) (string, error) {
+	if !hasTranslatedLevel(label) {
+		return label, nil
+	}
+	raw, err := translator.TransToRaw(label)
+	if errors.Is(err, errMcstransUnavailable) {
+		log.Logger(logComponent).V(4).Infof("not translating the selinux label %q: %v", label, err)
+		return label, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to translate the selinux label %q into its raw form: %w", label, err)
+	}
+	if hasTranslatedLevel(raw) {
+		return "", fmt.Errorf("the selinux label %q translated into %q, which is not a raw label", label, raw)
+	}
+	return raw, nil
+}
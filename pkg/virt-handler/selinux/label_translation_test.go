@@ -0,0 +1,233 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+// fakeMcstransd answers the setrans requests translating to raw labels with
+// its translations, or with an error for the unknown labels.
+type fakeMcstransd struct {
+	listener     net.Listener
+	translations map[string]string
+	requests     chan string
+}
+
+func newFakeMcstransd(socketPath string, translations map[string]string) *fakeMcstransd {
+	listener, err := net.Listen("unix", socketPath)
+	Expect(err).ToNot(HaveOccurred())
+	d := &fakeMcstransd{
+		listener:     listener,
+		translations: translations,
+		requests:     make(chan string, 10),
+	}
+	go d.serve()
+	return d
+}
+
+func (d *fakeMcstransd) serve() {
+	for {
+		conn, err := d.listener.Accept()
+		if err != nil {
+			return
+		}
+		d.answer(conn)
+		conn.Close()
+	}
+}
+
+func (d *fakeMcstransd) answer(conn net.Conn) {
+	var header [3]uint32
+	if err := binary.Read(conn, nativeEndian, &header); err != nil {
+		return
+	}
+	data := make([]byte, header[1]+header[2])
+	if _, err := io.ReadFull(conn, data); err != nil {
+		return
+	}
+	label := strings.TrimRight(string(data[:header[1]]), "\x00")
+	d.requests <- label
+
+	var result int32
+	raw, ok := d.translations[label]
+	if !ok {
+		result = -1
+	}
+	raw += "\x00"
+	binary.Write(conn, nativeEndian, header[0])
+	binary.Write(conn, nativeEndian, uint32(len(raw)))
+	binary.Write(conn, nativeEndian, result)
+	conn.Write([]byte(raw))
+}
+
+func (d *fakeMcstransd) close() {
+	d.listener.Close()
+}
+
+var _ = Describe("Label translation", func() {
+	const translatedLabel = "system_u:system_r:container_t:SystemLow:c1,c2"
+	const rawLabel = "system_u:system_r:container_t:s0:c1,c2"
+	const translatedRange = "system_u:system_r:spc_t:SystemLow-SystemHigh"
+	const rawRange = "system_u:system_r:spc_t:s0-s0:c0.c1023"
+
+	var tmpDir string
+	var daemon *fakeMcstransd
+	var translator LabelTranslator
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "setrans")
+		Expect(err).ToNot(HaveOccurred())
+		socketPath := filepath.Join(tmpDir, "setrans.sock")
+		daemon = newFakeMcstransd(socketPath, map[string]string{
+			translatedLabel: rawLabel,
+			translatedRange: rawRange,
+		})
+		translator = mcstransTranslator{socketPath: socketPath, timeout: time.Second}
+	})
+
+	AfterEach(func() {
+		daemon.close()
+		os.RemoveAll(tmpDir)
+	})
+
+	Context("with mcstrans", func() {
+		It("should translate the levels of mcstrans into raw levels", func() {
+			label, err := normalizeLabel(translator, translatedLabel)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(label).To(Equal(rawLabel))
+			Expect(daemon.requests).To(Receive(Equal(translatedLabel)))
+		})
+
+		It("should translate the level ranges of mcstrans into raw ranges", func() {
+			label, err := normalizeLabel(translator, translatedRange)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(label).To(Equal(rawRange))
+		})
+
+		It("should keep the raw labels without asking mcstrans", func() {
+			for _, raw := range []string{rawLabel, rawRange, "system_u:system_r:spc_t", ""} {
+				label, err := normalizeLabel(translator, raw)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(label).To(Equal(raw))
+			}
+			Expect(daemon.requests).ToNot(Receive())
+		})
+
+		It("should not ask mcstrans to translate malformed labels", func() {
+			label, err := normalizeLabel(translator, "system u:system_r:spc_t:SystemLow")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(validateLabel(label)).To(HaveOccurred())
+			Expect(daemon.requests).ToNot(Receive())
+		})
+
+		It("should fail on the labels mcstrans can't translate", func() {
+			_, err := normalizeLabel(translator, "system_u:system_r:spc_t:Unknown")
+			Expect(err).To(MatchError(ContainSubstring("mcstrans failed to translate")))
+		})
+
+		It("should fail on translations which aren't raw labels", func() {
+			daemon.translations["system_u:system_r:spc_t:Loop"] = "system_u:system_r:spc_t:Loop"
+			_, err := normalizeLabel(translator, "system_u:system_r:spc_t:Loop")
+			Expect(err).To(MatchError(ContainSubstring("which is not a raw label")))
+		})
+	})
+
+	Context("without mcstrans", func() {
+		BeforeEach(func() {
+			translator = mcstransTranslator{socketPath: filepath.Join(tmpDir, "missing.sock"), timeout: time.Second}
+		})
+
+		It("should keep the raw labels", func() {
+			label, err := normalizeLabel(translator, rawLabel)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(label).To(Equal(rawLabel))
+		})
+
+		It("should keep the levels of mcstrans for the label validation to reject them", func() {
+			label, err := normalizeLabel(translator, translatedLabel)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(label).To(Equal(translatedLabel))
+			Expect(validateLabel(label)).To(MatchError(ContainSubstring("malformed selinux label")))
+		})
+	})
+
+	Context("with an executor", func() {
+		const launcherPID = 1234
+
+		var manager *testutils.FakeLabelManager
+
+		BeforeEach(func() {
+			manager = testutils.NewFakeLabelManager()
+			manager.SetProcessLabel(os.Getpid(), translatedRange)
+		})
+
+		newExecutor := func(options ...Option) (*ContextExecutor, error) {
+			options = append(options, WithLabelTranslator(translator))
+			return NewContextExecutorWithLabelManager(manager, launcherPID, exec.Command("true"), options...)
+		}
+
+		It("should run the child with the raw launcher label", func() {
+			manager.SetProcessLabel(launcherPID, translatedLabel)
+			ce, err := newExecutor()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ce.desiredLabel).To(Equal(rawLabel))
+			Expect(ce.originalLabel).To(Equal(rawRange))
+		})
+
+		It("should translate the launcher label read from attr/exec", func() {
+			manager.SetProcessLabel(launcherPID, rawLabel)
+			manager.SetProcessExecLabel(launcherPID, translatedLabel)
+			ce, err := newExecutor(WithLauncherLabelAttr(LabelAttrExec))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ce.desiredLabel).To(Equal(rawLabel))
+		})
+
+		It("should keep the raw launcher label", func() {
+			manager.SetProcessLabel(launcherPID, rawLabel)
+			manager.SetProcessLabel(os.Getpid(), rawRange)
+			ce, err := newExecutor()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ce.desiredLabel).To(Equal(rawLabel))
+			Expect(daemon.requests).ToNot(Receive())
+		})
+
+		It("should reject the launcher label if mcstrans stopped", func() {
+			manager.SetProcessLabel(launcherPID, translatedLabel)
+			daemon.close()
+			_, err := newExecutor()
+			Expect(err).To(MatchError(ContainSubstring("malformed selinux label")))
+		})
+	})
+})
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"kubevirt.io/client-go/log"
+	"kubevirt.io/kubevirt/pkg/util/fdhygiene"
 )
 
 const procOnePrefix = "/proc/1/root"
@@ -17,6 +18,7 @@ type execFunc = func(binary string, args ...string) ([]byte, error)
 type copyPolicy = func(policyName string, dir string) (err error)
 
 func defaultExecFunc(binary string, args ...string) ([]byte, error) {
+	fdhygiene.PreventLeakOntoChild()
 	// #nosec No risk for attacket injection. args get specific selinux exec parameters
 	return exec.Command(binary, args...).CombinedOutput()
 }
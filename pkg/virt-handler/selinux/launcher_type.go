@@ -0,0 +1,38 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+
+	"github.com/opencontainers/selinux/go-selinux"
+)
+
+var checkContext = selinux.SecurityCheckContext
+
+// IsLauncherTypeAvailable reports whether the loaded policy of the node
+// defines the given launcher type. Any type is available on nodes without
+// SELinux, since the launcher labels are ignored there.
+func IsLauncherTypeAvailable(selinuxType string) bool {
+	if !isSELinuxEnabled() {
+		return true
+	}
+	return checkContext(fmt.Sprintf("system_u:system_r:%s:s0", selinuxType)) == nil
+}
@@ -0,0 +1,69 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/selinux/go-selinux"
+)
+
+var _ = Describe("Launcher type availability", func() {
+	var checkedContexts []string
+
+	setSELinuxEnabled := func(enabled bool) {
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, enabled, nil
+		}
+		ResetSELinuxDetectionForTest()
+	}
+
+	BeforeEach(func() {
+		checkedContexts = nil
+		checkContext = func(context string) error {
+			checkedContexts = append(checkedContexts, context)
+			if context == "system_u:system_r:virt_custom_t:s0" {
+				return nil
+			}
+			return syscall.EINVAL
+		}
+	})
+
+	AfterEach(func() {
+		checkContext = selinux.SecurityCheckContext
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+	})
+
+	It("should check the type against the loaded policy", func() {
+		setSELinuxEnabled(true)
+		Expect(IsLauncherTypeAvailable("virt_custom_t")).To(BeTrue())
+		Expect(IsLauncherTypeAvailable("virt_missing_t")).To(BeFalse())
+		Expect(checkedContexts).To(Equal([]string{"system_u:system_r:virt_custom_t:s0", "system_u:system_r:virt_missing_t:s0"}))
+	})
+
+	It("should consider all types available without SELinux", func() {
+		setSELinuxEnabled(false)
+		Expect(IsLauncherTypeAvailable("virt_missing_t")).To(BeTrue())
+		Expect(checkedContexts).To(BeEmpty())
+	})
+})
@@ -0,0 +1,191 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// manualContext tracks the thread entered with Enter, shared by the copies of
+// the executor.
+type manualContext struct {
+	lock     sync.Mutex
+	entered  bool
+	switched bool
+	tid      int
+	lockedAt time.Time
+}
+
+func (m *manualContext) isEntered() bool {
+	if m == nil {
+		return false
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.entered
+}
+
+// checkNotEntered refuses the executions of an executor while its context is
+// entered manually, since their thread switches would interleave with the
+// one of Enter.
+func (ce ContextExecutor) checkNotEntered() error {
+	if ce.manual.isEntered() {
+		return fmt.Errorf("%w: refusing to run in the context of launcher pid %d until Leave is called", ErrManualContext, ce.pid)
+	}
+	return nil
+}
+
+// Enter locks the calling goroutine to its OS thread and switches the current
+// label of the thread to the launcher label, like RunInContext does around its
+// function, so that the caller can do several operations under the launcher
+// label before calling Leave from the same goroutine.
+//
+// Enter and Leave have to be paired: Enter fails with ErrManualContext if the
+// context is entered already, and Leave with ErrNotEntered if it isn't entered
+// or was entered by another thread. Execute, RunInContext and the other
+// executions of the executor fail with ErrManualContext meanwhile. The copies
+// of the executor share its manual context, the executors have to be created
+// by one of the NewContextExecutor functions.
+//
+// Like for RunInContext, the type of the launcher label has to be bounded by
+// the one of virt-handler with a typebounds statement of the policy, the thread
+// of a multithreaded process can't switch to it otherwise. The kernel refuses
+// such a switch with EPERM, which leaves the label of the thread untouched:
+// Enter then unlocks the thread and returns a ContextSwitchError wrapping
+// ErrUnboundedLabel.
+//
+// If the thread can't be switched for another reason, or can't be switched
+// back by Leave, it stays locked and the error is returned: the caller can't
+// tell the label of the thread anymore and has to return from its goroutine
+// without doing anything else, for the runtime to destroy the thread. Neither
+// Enter nor Leave ends the goroutine of the caller, callers should therefore
+// call Enter from a goroutine dedicated to the work done in the context.
+// Without selinux, only the thread is locked.
+func (ce ContextExecutor) Enter() error {
+	if ce.manual == nil {
+		return fmt.Errorf("the executor of launcher pid %d has no manual context, it has to be created by NewContextExecutor", ce.pid)
+	}
+	ce.manual.lock.Lock()
+	defer ce.manual.lock.Unlock()
+	if ce.manual.entered {
+		return fmt.Errorf("%w: the context of launcher pid %d is entered already by thread %d", ErrManualContext, ce.pid, ce.manual.tid)
+	}
+	if ce.dryRun {
+		ce.getLogger().Infof("dry-run: would enter the selinux context %s of launcher pid %d", ce.desiredLabel, ce.pid)
+		ce.manual.entered = true
+		return nil
+	}
+
+	switchLabel := isSELinuxEnabled()
+	var setter CurrentLabelSetter
+	restoreLabel := ce.getRestoreLabel()
+	if switchLabel {
+		var ok bool
+		if setter, ok = ce.getLabelManager().(CurrentLabelSetter); !ok {
+			return fmt.Errorf("the selinux label manager can't switch the current label of the thread")
+		}
+		if restoreLabel == "" {
+			return fmt.Errorf("refusing to switch the selinux context to %s for launcher pid %d: the selinux label of virt-handler is unknown and could not be restored", ce.desiredLabel, ce.pid)
+		}
+		if activePID, active := activeLabelSwitch(); active {
+			return fmt.Errorf("%w: refusing to switch to the context of launcher pid %d from the context of launcher pid %d", ErrNestedLabelSwitch, ce.pid, activePID)
+		}
+		if err := checkLauncherExists(ce.pid); err != nil {
+			return err
+		}
+	}
+
+	runtime.LockOSThread()
+	lockedAt := time.Now()
+	if switchLabel {
+		tid := beginLabelSwitch(ce.pid)
+		ce.getLogger().V(debugVerbosity).Infof("entering the selinux context %s of launcher pid %d from %s", ce.desiredLabel, ce.pid, restoreLabel)
+		setErr := setter.SetCurrentLabel(ce.desiredLabel)
+		countContextSwitch(setErr != nil)
+		if unboundedErr := unboundedLabelError(setErr); unboundedErr != nil {
+			// the kernel refused the switch, the thread kept its label
+			endLabelSwitch(tid)
+			observeThreadLock(lockedAt)
+			ce.getLogger().Reason(unboundedErr).Errorf("failed to switch the selinux context of the thread to %s for launcher pid %d", ce.desiredLabel, ce.pid)
+			ce.recordContextSwitchFailure(unboundedErr)
+			runtime.UnlockOSThread()
+			return &ContextSwitchError{Label: ce.desiredLabel, Err: unboundedErr}
+		}
+		if setErr != nil {
+			// the label of the still locked thread is unknown, the goroutine
+			// of the caller has to exit for it to be destroyed
+			endLabelSwitch(tid)
+			observeThreadLock(lockedAt)
+			ce.getLogger().Reason(setErr).Errorf("failed to switch the selinux context of the thread to %s for launcher pid %d", ce.desiredLabel, ce.pid)
+			ce.recordContextSwitchFailure(setErr)
+			return &ContextSwitchError{Label: ce.desiredLabel, Err: setErr}
+		}
+	}
+	ce.manual.entered = true
+	ce.manual.switched = switchLabel
+	ce.manual.tid = unix.Gettid()
+	ce.manual.lockedAt = lockedAt
+	return nil
+}
+
+// Leave switches the thread entered with Enter back to the label of
+// virt-handler, or the restore label, and unlocks it. It has to be called
+// from the goroutine which called Enter. If the thread can't be switched back,
+// the error is returned with the thread still locked, and the caller has to
+// return from its goroutine, see Enter.
+func (ce ContextExecutor) Leave() error {
+	if ce.manual == nil {
+		return fmt.Errorf("%w: the executor of launcher pid %d has no manual context", ErrNotEntered, ce.pid)
+	}
+	ce.manual.lock.Lock()
+	defer ce.manual.lock.Unlock()
+	if !ce.manual.entered {
+		return fmt.Errorf("%w: Leave called for launcher pid %d without Enter", ErrNotEntered, ce.pid)
+	}
+	if ce.dryRun {
+		ce.getLogger().Infof("dry-run: would leave the selinux context %s of launcher pid %d", ce.desiredLabel, ce.pid)
+		ce.manual.entered = false
+		return nil
+	}
+	if tid := unix.Gettid(); tid != ce.manual.tid {
+		return fmt.Errorf("%w: the context of launcher pid %d was entered by thread %d, not by thread %d", ErrNotEntered, ce.pid, ce.manual.tid, tid)
+	}
+
+	ce.manual.entered = false
+	defer observeThreadLock(ce.manual.lockedAt)
+	if ce.manual.switched {
+		defer endLabelSwitch(ce.manual.tid)
+		restoreLabel := ce.getRestoreLabel()
+		ce.getLogger().V(debugVerbosity).Infof("leaving the selinux context of launcher pid %d for %s", ce.pid, restoreLabel)
+		if err := ce.getLabelManager().(CurrentLabelSetter).SetCurrentLabel(restoreLabel); err != nil {
+			// the thread stays locked, see Enter
+			err = fmt.Errorf("%w: failed to reset the selinux context of the thread to %s: %v", errPoisonedThread, restoreLabel, err)
+			ce.getLogger().Reason(err).Errorf("the OS thread left in the selinux context of launcher pid %d has to be terminated", ce.pid)
+			return err
+		}
+	}
+	runtime.UnlockOSThread()
+	return nil
+}
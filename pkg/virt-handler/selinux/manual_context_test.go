@@ -0,0 +1,278 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/sys/unix"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+var _ = Describe("Enter and Leave", func() {
+	const launcherPID = 1234
+
+	var manager *threadLabelManager
+	var restoreProcRoot func()
+
+	BeforeEach(func() {
+		restoreProcRoot = fakeProcRoot(launcherPID)
+		manager = &threadLabelManager{FakeLabelManager: testutils.NewFakeLabelManager(), threadLabels: map[int]string{}}
+		manager.SetProcessLabel(launcherPID, testLauncherLabel)
+		manager.SetProcessLabel(os.Getpid(), testOriginalLabel)
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, true, nil
+		}
+		ResetSELinuxDetectionForTest()
+	})
+
+	AfterEach(func() {
+		restoreProcRoot()
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+	})
+
+	newExecutor := func(options ...Option) *ContextExecutor {
+		ce, err := NewContextExecutor(launcherPID, exec.Command("true"), append([]Option{WithLabelManager(manager)}, options...)...)
+		Expect(err).ToNot(HaveOccurred())
+		return ce
+	}
+
+	// inGoroutine runs f on a dedicated goroutine, so that the threads left
+	// locked by the failures get destroyed instead of locking the test
+	inGoroutine := func(f func()) {
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			f()
+		}()
+		<-done
+	}
+
+	It("should keep the thread in the launcher label between Enter and Leave", func() {
+		ce := newExecutor()
+		inGoroutine(func() {
+			Expect(ce.Enter()).To(Succeed())
+			tid := unix.Gettid()
+			Expect(manager.threadLabel(tid)).To(Equal(testLauncherLabel))
+			_, active := activeLabelSwitch()
+			Expect(active).To(BeTrue())
+
+			Expect(ce.Leave()).To(Succeed())
+			Expect(manager.threadLabel(tid)).To(Equal(testOriginalLabel))
+			_, active = activeLabelSwitch()
+			Expect(active).To(BeFalse())
+		})
+		Expect(manager.CurrentLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+		Expect(manager.ExecLabels()).To(BeEmpty())
+	})
+
+	It("should allow entering again after leaving", func() {
+		ce := newExecutor()
+		inGoroutine(func() {
+			for i := 0; i < 2; i++ {
+				Expect(ce.Enter()).To(Succeed())
+				Expect(ce.Leave()).To(Succeed())
+			}
+		})
+		Expect(manager.CurrentLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel, testLauncherLabel, testOriginalLabel}))
+	})
+
+	It("should switch the thread back to the restore label", func() {
+		const restoreLabel = "system_u:system_r:spc_t:s0"
+		ce := newExecutor(WithRestoreLabel(restoreLabel))
+		inGoroutine(func() {
+			Expect(ce.Enter()).To(Succeed())
+			Expect(ce.Leave()).To(Succeed())
+		})
+		Expect(manager.CurrentLabels()).To(Equal([]string{testLauncherLabel, restoreLabel}))
+	})
+
+	It("should share the context with the copies of the executor", func() {
+		ce := newExecutor()
+		inGoroutine(func() {
+			Expect(ce.Enter()).To(Succeed())
+			copied := *ce
+			Expect(errors.Is(copied.Enter(), ErrManualContext)).To(BeTrue())
+			Expect(copied.Leave()).To(Succeed())
+		})
+	})
+
+	Context("misuse", func() {
+		It("should refuse entering twice", func() {
+			ce := newExecutor()
+			inGoroutine(func() {
+				Expect(ce.Enter()).To(Succeed())
+				defer func() {
+					Expect(ce.Leave()).To(Succeed())
+				}()
+				Expect(errors.Is(ce.Enter(), ErrManualContext)).To(BeTrue())
+			})
+			Expect(manager.CurrentLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+		})
+
+		It("should refuse leaving without entering", func() {
+			ce := newExecutor()
+			Expect(errors.Is(ce.Leave(), ErrNotEntered)).To(BeTrue())
+		})
+
+		It("should refuse leaving twice", func() {
+			ce := newExecutor()
+			inGoroutine(func() {
+				Expect(ce.Enter()).To(Succeed())
+				Expect(ce.Leave()).To(Succeed())
+				Expect(errors.Is(ce.Leave(), ErrNotEntered)).To(BeTrue())
+			})
+		})
+
+		It("should refuse leaving from another thread", func() {
+			ce := newExecutor()
+			inGoroutine(func() {
+				Expect(ce.Enter()).To(Succeed())
+				inGoroutine(func() {
+					err := ce.Leave()
+					Expect(errors.Is(err, ErrNotEntered)).To(BeTrue())
+					Expect(err).To(MatchError(ContainSubstring("was entered by thread")))
+				})
+				Expect(ce.Leave()).To(Succeed())
+			})
+			Expect(manager.CurrentLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+		})
+
+		It("should refuse the executions of the executor until leaving", func() {
+			ce := newExecutor()
+			inGoroutine(func() {
+				Expect(ce.Enter()).To(Succeed())
+				inGoroutine(func() {
+					Expect(errors.Is(ce.Execute(), ErrManualContext)).To(BeTrue())
+					_, err := ce.ExecuteMany([]*exec.Cmd{exec.Command("true")}, FailFast)
+					Expect(errors.Is(err, ErrManualContext)).To(BeTrue())
+					Expect(errors.Is(ce.RunInContext(func() error {
+						Fail("the function should not run")
+						return nil
+					}), ErrManualContext)).To(BeTrue())
+				})
+				Expect(ce.Leave()).To(Succeed())
+			})
+			Expect(ce.Execute()).To(Succeed())
+		})
+
+		It("should refuse the batches of the executor until leaving", func() {
+			ce := newExecutor()
+			batch := BatchContextExecutor{ContextExecutor: *ce, cmdsToExecute: []*exec.Cmd{exec.Command("true")}}
+			inGoroutine(func() {
+				Expect(ce.Enter()).To(Succeed())
+				inGoroutine(func() {
+					Expect(errors.Is(batch.Execute(), ErrManualContext)).To(BeTrue())
+				})
+				Expect(ce.Leave()).To(Succeed())
+			})
+			Expect(manager.CurrentLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+			Expect(batch.Execute()).To(Succeed())
+		})
+
+		It("should refuse entering from a thread switched by RunInContext", func() {
+			ce := newExecutor()
+			Expect(ce.RunInContext(func() error {
+				Expect(errors.Is(newExecutor().Enter(), ErrNestedLabelSwitch)).To(BeTrue())
+				return nil
+			})).To(Succeed())
+		})
+
+		It("should refuse executors not created by NewContextExecutor", func() {
+			ce := ContextExecutor{pid: launcherPID, desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel, labelManager: manager}
+			Expect(ce.Enter()).To(MatchError(ContainSubstring("has no manual context")))
+			Expect(errors.Is(ce.Leave(), ErrNotEntered)).To(BeTrue())
+		})
+	})
+
+	It("should not enter if the thread can't be switched", func() {
+		manager.DenyCurrentLabel(testLauncherLabel, syscall.EACCES)
+		ce := newExecutor()
+		inGoroutine(func() {
+			err := ce.Enter()
+			Expect(IsSELinuxError(err)).To(BeTrue())
+			_, active := activeLabelSwitch()
+			Expect(active).To(BeFalse())
+		})
+		Expect(errors.Is(ce.Leave(), ErrNotEntered)).To(BeTrue())
+	})
+
+	It("should fail up front and unlock the thread if the launcher label is not bounded", func() {
+		manager.DenyCurrentLabel(testLauncherLabel, &os.PathError{Op: "write", Path: "/proc/thread-self/attr/current", Err: syscall.EPERM})
+		ce := newExecutor()
+		inGoroutine(func() {
+			err := ce.Enter()
+			var switchErr *ContextSwitchError
+			Expect(errors.As(err, &switchErr)).To(BeTrue())
+			Expect(errors.Is(err, ErrUnboundedLabel)).To(BeTrue())
+			_, active := activeLabelSwitch()
+			Expect(active).To(BeFalse())
+		})
+		Expect(errors.Is(ce.Leave(), ErrNotEntered)).To(BeTrue())
+		Expect(ce.Execute()).To(Succeed())
+	})
+
+	It("should report a thread which can't be switched back as poisoned to the caller", func() {
+		manager.DenyCurrentLabel(testOriginalLabel, syscall.EACCES)
+		ce := newExecutor()
+		returned := false
+		inGoroutine(func() {
+			Expect(ce.Enter()).To(Succeed())
+			err := ce.Leave()
+			// the goroutine of the caller keeps running, it decides to end
+			returned = true
+			Expect(errors.Is(err, errPoisonedThread)).To(BeTrue())
+			_, active := activeLabelSwitch()
+			Expect(active).To(BeFalse())
+		})
+		Expect(returned).To(BeTrue())
+		Expect(ce.Execute()).To(Succeed())
+	})
+
+	It("should only pair the calls without selinux", func() {
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, false, nil
+		}
+		ResetSELinuxDetectionForTest()
+		ce := newExecutor()
+		inGoroutine(func() {
+			Expect(ce.Enter()).To(Succeed())
+			Expect(errors.Is(ce.Enter(), ErrManualContext)).To(BeTrue())
+			Expect(ce.Leave()).To(Succeed())
+		})
+		Expect(manager.CurrentLabels()).To(BeEmpty())
+	})
+
+	It("should not switch the thread in dry-run", func() {
+		ce := newExecutor(WithDryRun())
+		Expect(ce.Enter()).To(Succeed())
+		Expect(ce.Leave()).To(Succeed())
+		Expect(errors.Is(ce.Leave(), ErrNotEntered)).To(BeTrue())
+		Expect(manager.CurrentLabels()).To(BeEmpty())
+	})
+})
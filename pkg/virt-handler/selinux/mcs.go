@@ -0,0 +1,212 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// mcsLevel is a parsed selinux level, e.g. s0:c1,c5.c10.
+type mcsLevel struct {
+	sensitivity string
+	categories  []int
+}
+
+// mcsLabel is a parsed user:role:type[:low[-high]] selinux label.
+type mcsLabel struct {
+	user, role, typ string
+	low, high       *mcsLevel
+}
+
+// WithSharedMCS makes the executor relabel files with a label whose MCS
+// categories are shared by the launcher and the given pids, so that all of
+// them can access the relabeled paths, e.g. an RWX volume shared by VMIs.
+func WithSharedMCS(pids ...int) Option {
+	return func(ce *ContextExecutor) {
+		ce.sharedMCSPIDs = append([]int(nil), pids...)
+	}
+}
+
+// SharedMCSLabel returns the label carrying the union of the MCS categories of
+// the given pids. The pids have to run with the same user, role and type.
+func SharedMCSLabel(pids ...int) (string, error) {
+	return sharedMCSLabel(getLabelForPID, pids...)
+}
+
+func sharedMCSLabel(getLabel func(pid int) (string, error), pids ...int) (string, error) {
+	labels := make([]string, 0, len(pids))
+	for _, pid := range pids {
+		label, err := getLabel(pid)
+		if err != nil {
+			return "", err
+		}
+		labels = append(labels, label)
+	}
+	return MergeMCSLabels(labels...)
+}
+
+// MergeMCSLabels merges the levels of labels differing only by their MCS
+// categories. The low and high levels are merged separately and have to use
+// the same sensitivity across all labels.
+func MergeMCSLabels(labels ...string) (string, error) {
+	if len(labels) == 0 {
+		return "", fmt.Errorf("no selinux label to merge")
+	}
+	var merged *mcsLabel
+	for _, label := range labels {
+		parsed, err := parseMCSLabel(label)
+		if err != nil {
+			return "", err
+		}
+		if merged == nil {
+			merged = parsed
+			continue
+		}
+		if parsed.user != merged.user || parsed.role != merged.role || parsed.typ != merged.typ {
+			return "", fmt.Errorf("can't merge the categories of selinux labels %q and %q: user, role and type differ", merged.String(), label)
+		}
+		low, err := mergeLevels(merged.low, parsed.low)
+		if err != nil {
+			return "", fmt.Errorf("can't merge the low levels of selinux labels %q and %q: %v", merged.String(), label, err)
+		}
+		high, err := mergeLevels(merged.high, parsed.high)
+		if err != nil {
+			return "", fmt.Errorf("can't merge the high levels of selinux labels %q and %q: %v", merged.String(), label, err)
+		}
+		merged.low, merged.high = low, high
+	}
+	return merged.String(), nil
+}
+
+func parseMCSLabel(label string) (*mcsLabel, error) {
+	components, err := ParseLabel(label)
+	if err != nil {
+		return nil, err
+	}
+	if components.Level == "" {
+		return nil, fmt.Errorf("selinux label %q has no level", label)
+	}
+	parsed := &mcsLabel{user: components.User, role: components.Role, typ: components.Type}
+	levels := strings.Split(components.Level, "-")
+	if parsed.low, err = parseLevel(levels[0]); err != nil {
+		return nil, fmt.Errorf("malformed selinux label %q: %v", label, err)
+	}
+	parsed.high = parsed.low
+	if len(levels) == 2 {
+		if parsed.high, err = parseLevel(levels[1]); err != nil {
+			return nil, fmt.Errorf("malformed selinux label %q: %v", label, err)
+		}
+	}
+	return parsed, nil
+}
+
+func parseLevel(level string) (*mcsLevel, error) {
+	parts := strings.SplitN(level, ":", 2)
+	parsed := &mcsLevel{sensitivity: parts[0]}
+	if len(parts) == 1 {
+		return parsed, nil
+	}
+	categories := map[int]struct{}{}
+	for _, set := range strings.Split(parts[1], ",") {
+		bounds := strings.Split(set, ".")
+		first, err := parseCategory(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = parseCategory(bounds[1]); err != nil {
+				return nil, err
+			}
+			if last <= first {
+				return nil, fmt.Errorf("invalid category range %q", set)
+			}
+		}
+		for c := first; c <= last; c++ {
+			categories[c] = struct{}{}
+		}
+	}
+	parsed.categories = sortedCategories(categories)
+	return parsed, nil
+}
+
+func parseCategory(category string) (int, error) {
+	c, err := strconv.Atoi(strings.TrimPrefix(category, "c"))
+	if err != nil || !strings.HasPrefix(category, "c") {
+		return 0, fmt.Errorf("invalid category %q", category)
+	}
+	return c, nil
+}
+
+func mergeLevels(a, b *mcsLevel) (*mcsLevel, error) {
+	if a.sensitivity != b.sensitivity {
+		return nil, fmt.Errorf("sensitivities %s and %s differ", a.sensitivity, b.sensitivity)
+	}
+	categories := map[int]struct{}{}
+	for _, c := range append(append([]int(nil), a.categories...), b.categories...) {
+		categories[c] = struct{}{}
+	}
+	return &mcsLevel{sensitivity: a.sensitivity, categories: sortedCategories(categories)}, nil
+}
+
+func sortedCategories(categories map[int]struct{}) []int {
+	sorted := make([]int, 0, len(categories))
+	for c := range categories {
+		sorted = append(sorted, c)
+	}
+	sort.Ints(sorted)
+	return sorted
+}
+
+// String formats the level like the kernel does, folding runs of more than
+// two consecutive categories into a range.
+func (l *mcsLevel) String() string {
+	if len(l.categories) == 0 {
+		return l.sensitivity
+	}
+	var sets []string
+	for i := 0; i < len(l.categories); {
+		j := i
+		for j+1 < len(l.categories) && l.categories[j+1] == l.categories[j]+1 {
+			j++
+		}
+		switch j - i {
+		case 0:
+			sets = append(sets, fmt.Sprintf("c%d", l.categories[i]))
+		case 1:
+			sets = append(sets, fmt.Sprintf("c%d", l.categories[i]), fmt.Sprintf("c%d", l.categories[j]))
+		default:
+			sets = append(sets, fmt.Sprintf("c%d.c%d", l.categories[i], l.categories[j]))
+		}
+		i = j + 1
+	}
+	return l.sensitivity + ":" + strings.Join(sets, ",")
+}
+
+func (l *mcsLabel) String() string {
+	level := l.low.String()
+	if high := l.high.String(); high != level {
+		level += "-" + high
+	}
+	return strings.Join([]string{l.user, l.role, l.typ, level}, ":")
+}
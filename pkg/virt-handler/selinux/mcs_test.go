@@ -0,0 +1,130 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MCS categories", func() {
+
+	table.DescribeTable("should merge", func(labels []string, expected string) {
+		Expect(MergeMCSLabels(labels...)).To(Equal(expected))
+	},
+		table.Entry("a single label",
+			[]string{"system_u:system_r:container_t:s0:c1,c2"},
+			"system_u:system_r:container_t:s0:c1,c2"),
+		table.Entry("labels without categories",
+			[]string{"system_u:system_r:container_t:s0", "system_u:system_r:container_t:s0"},
+			"system_u:system_r:container_t:s0"),
+		table.Entry("distinct categories",
+			[]string{"system_u:system_r:container_t:s0:c1,c5", "system_u:system_r:container_t:s0:c2,c5"},
+			"system_u:system_r:container_t:s0:c1,c2,c5"),
+		table.Entry("categories into a range",
+			[]string{"system_u:system_r:container_t:s0:c1,c3", "system_u:system_r:container_t:s0:c2,c4"},
+			"system_u:system_r:container_t:s0:c1.c4"),
+		table.Entry("category ranges",
+			[]string{"system_u:system_r:container_t:s0:c0.c3,c10", "system_u:system_r:container_t:s0:c2.c5"},
+			"system_u:system_r:container_t:s0:c0.c5,c10"),
+		table.Entry("s0 ranges",
+			[]string{"system_u:system_r:spc_t:s0-s0:c0.c511", "system_u:system_r:spc_t:s0-s0:c512.c1023"},
+			"system_u:system_r:spc_t:s0-s0:c0.c1023"),
+		table.Entry("a label with a range and a single level",
+			[]string{"system_u:system_r:spc_t:s0-s0:c0.c1023", "system_u:system_r:spc_t:s0:c7"},
+			"system_u:system_r:spc_t:s0:c7-s0:c0.c1023"),
+	)
+
+	table.DescribeTable("should reject", func(labels []string, expectedError string) {
+		_, err := MergeMCSLabels(labels...)
+		Expect(err).To(MatchError(ContainSubstring(expectedError)))
+	},
+		table.Entry("no label", []string{}, "no selinux label to merge"),
+		table.Entry("a label without level", []string{"system_u:system_r:container_t"}, "has no level"),
+		table.Entry("a malformed label", []string{"system_u:system_r"}, "malformed selinux label"),
+		table.Entry("a reversed category range", []string{"system_u:system_r:container_t:s0:c5.c1"}, "invalid category range"),
+		table.Entry("different users",
+			[]string{"system_u:system_r:container_t:s0:c1", "unconfined_u:system_r:container_t:s0:c2"},
+			"user, role and type differ"),
+		table.Entry("different roles",
+			[]string{"system_u:system_r:container_t:s0:c1", "system_u:object_r:container_t:s0:c2"},
+			"user, role and type differ"),
+		table.Entry("different types",
+			[]string{"system_u:system_r:container_t:s0:c1", "system_u:system_r:virt_launcher.process:s0:c2"},
+			"user, role and type differ"),
+		table.Entry("different sensitivities",
+			[]string{"system_u:system_r:container_t:s0:c1", "system_u:system_r:container_t:s1:c2"},
+			"sensitivities s0 and s1 differ"),
+	)
+
+	Context("for pids", func() {
+		var orgLabelCache *labelCache
+
+		BeforeEach(func() {
+			orgLabelCache = defaultLabelCache
+			labels := map[int]string{
+				1:           "system_u:system_r:container_t:s0:c1,c2",
+				2:           "system_u:system_r:container_t:s0:c3,c9",
+				os.Getpid(): "system_u:system_r:spc_t:s0",
+			}
+			defaultLabelCache = newLabelCache(func(pid int) (uint64, error) {
+				return 1, nil
+			}, func(pid int) (string, error) {
+				label, exists := labels[pid]
+				if !exists {
+					return "", newLabelError(pid, syscall.ENOENT)
+				}
+				return label, nil
+			})
+		})
+
+		AfterEach(func() {
+			defaultLabelCache = orgLabelCache
+		})
+
+		It("should merge the categories of all pids", func() {
+			Expect(SharedMCSLabel(1, 2)).To(Equal("system_u:system_r:container_t:s0:c1.c3,c9"))
+		})
+
+		It("should fail when a pid label can't be read", func() {
+			_, err := SharedMCSLabel(1, 3)
+			Expect(err).To(HaveOccurred())
+			Expect(err).To(BeAssignableToTypeOf(&LabelError{}))
+		})
+
+		It("should make the executor relabel files with the shared label", func() {
+			ce, err := NewContextExecutor(1, exec.Command("true"), WithSharedMCS(2))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ce.desiredLabel).To(Equal("system_u:system_r:container_t:s0:c1,c2"))
+			Expect(ce.getFileLabel()).To(Equal("system_u:object_r:container_file_t:s0:c1.c3,c9"))
+		})
+
+		It("should keep relabeling files with the launcher label by default", func() {
+			ce, err := NewContextExecutor(1, exec.Command("true"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ce.getFileLabel()).To(Equal("system_u:object_r:container_file_t:s0:c1,c2"))
+		})
+	})
+})
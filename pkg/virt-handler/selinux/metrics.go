@@ -0,0 +1,183 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	contextSwitchTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubevirt_selinux_context_switch_total",
+			Help: "Number of selinux exec context switches attempted by virt-handler to run commands in launcher contexts.",
+		},
+		[]string{"node"},
+	)
+
+	contextSwitchFailedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubevirt_selinux_context_switch_failed_total",
+			Help: "Number of selinux exec context switches to launcher contexts which failed.",
+		},
+		[]string{"node"},
+	)
+
+	contextSwitchIneffectiveTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubevirt_selinux_context_switch_ineffective_total",
+			Help: "Number of selinux exec context switches to launcher contexts which reported success, but left the exec label of the thread unchanged.",
+		},
+		[]string{"node"},
+	)
+
+	execTransitionBlocked = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubevirt_selinux_exec_transition_blocked",
+			Help: "Whether the startup self-check of virt-handler found the selinux policy blocking the switch to the launcher context (1) or not (0).",
+		},
+		[]string{"node"},
+	)
+
+	policyModuleMissing = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubevirt_selinux_policy_module_missing",
+			Help: "Whether a selinux policy module required by KubeVirt is missing from the policy of the node (1) or loaded (0).",
+		},
+		[]string{"node", "module"},
+	)
+
+	threadLockDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kubevirt_selinux_thread_lock_duration_seconds",
+			Help:    "Time virt-handler held an OS thread locked to run commands in a launcher context.",
+			Buckets: prometheus.ExponentialBuckets(0.001, 4, 10),
+		},
+		[]string{"node"},
+	)
+
+	auditWriteFailedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubevirt_selinux_audit_write_failed_total",
+			Help: "Number of audit lines of commands run in launcher contexts which could not be written to the audit sink.",
+		},
+		[]string{"node"},
+	)
+
+	circuitBreakersOpen = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubevirt_selinux_circuit_breakers_open",
+			Help: "Number of VMIs virt-handler stopped retrying after repeated selinux failures, until their spec changes.",
+		},
+		[]string{"node"},
+	)
+
+	metricsLock     sync.RWMutex
+	metricsNodeName string
+)
+
+// RegisterMetrics registers the selinux context switch metrics with
+// registerer, labeling them with the name of the node virt-handler runs on.
+func RegisterMetrics(registerer prometheus.Registerer, nodeName string) error {
+	metricsLock.Lock()
+	metricsNodeName = nodeName
+	metricsLock.Unlock()
+
+	for _, collector := range []prometheus.Collector{contextSwitchTotal, contextSwitchFailedTotal, contextSwitchIneffectiveTotal, execTransitionBlocked, policyModuleMissing, threadLockDuration, auditWriteFailedTotal, circuitBreakersOpen} {
+		if err := registerer.Register(collector); err != nil {
+			if _, alreadyRegistered := err.(prometheus.AlreadyRegisteredError); !alreadyRegistered {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func countContextSwitch(failed bool) {
+	metricsLock.RLock()
+	nodeName := metricsNodeName
+	metricsLock.RUnlock()
+
+	contextSwitchTotal.WithLabelValues(nodeName).Inc()
+	if failed {
+		contextSwitchFailedTotal.WithLabelValues(nodeName).Inc()
+	}
+}
+
+func countIneffectiveContextSwitch() {
+	metricsLock.RLock()
+	nodeName := metricsNodeName
+	metricsLock.RUnlock()
+
+	contextSwitchIneffectiveTotal.WithLabelValues(nodeName).Inc()
+}
+
+func countAuditWriteFailure() {
+	metricsLock.RLock()
+	nodeName := metricsNodeName
+	metricsLock.RUnlock()
+
+	auditWriteFailedTotal.WithLabelValues(nodeName).Inc()
+}
+
+func setExecTransitionBlocked(blocked bool) {
+	metricsLock.RLock()
+	nodeName := metricsNodeName
+	metricsLock.RUnlock()
+
+	value := 0.0
+	if blocked {
+		value = 1
+	}
+	execTransitionBlocked.WithLabelValues(nodeName).Set(value)
+}
+
+func setPolicyModuleMissing(module string, missing bool) {
+	metricsLock.RLock()
+	nodeName := metricsNodeName
+	metricsLock.RUnlock()
+
+	value := 0.0
+	if missing {
+		value = 1
+	}
+	policyModuleMissing.WithLabelValues(nodeName, module).Set(value)
+}
+
+func setCircuitBreakersOpen(open int) {
+	metricsLock.RLock()
+	nodeName := metricsNodeName
+	metricsLock.RUnlock()
+
+	circuitBreakersOpen.WithLabelValues(nodeName).Set(float64(open))
+}
+
+// observeThreadLock records how long the OS thread has been locked since
+// lockedAt.
+func observeThreadLock(lockedAt time.Time) {
+	metricsLock.RLock()
+	nodeName := metricsNodeName
+	metricsLock.RUnlock()
+
+	threadLockDuration.WithLabelValues(nodeName).Observe(time.Since(lockedAt).Seconds())
+}
@@ -0,0 +1,158 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"os/exec"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var _ = Describe("Context switch metrics", func() {
+	const nodeName = "testnode"
+
+	var registry *prometheus.Registry
+
+	counterValue := func(name string) float64 {
+		families, err := registry.Gather()
+		Expect(err).ToNot(HaveOccurred())
+		for _, family := range families {
+			if family.GetName() != name {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				if hasNodeLabel(metric, nodeName) {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+		return 0
+	}
+
+	threadLockSamples := func() uint64 {
+		families, err := registry.Gather()
+		Expect(err).ToNot(HaveOccurred())
+		for _, family := range families {
+			if family.GetName() != "kubevirt_selinux_thread_lock_duration_seconds" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				if hasNodeLabel(metric, nodeName) {
+					return metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+		return 0
+	}
+
+	BeforeEach(func() {
+		registry = prometheus.NewRegistry()
+		Expect(RegisterMetrics(registry, nodeName)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		defaultLabelManager = NewLabelManager()
+	})
+
+	It("should count successful context switches", func() {
+		defaultLabelManager = execLabelFunc(func(label string) error {
+			return nil
+		})
+		switches := counterValue("kubevirt_selinux_context_switch_total")
+		failures := counterValue("kubevirt_selinux_context_switch_failed_total")
+
+		ce := ContextExecutor{desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+		Expect(ce.setDesiredContext()).To(Succeed())
+		Expect(ce.resetContext()).To(Succeed())
+
+		Expect(counterValue("kubevirt_selinux_context_switch_total")).To(Equal(switches + 1))
+		Expect(counterValue("kubevirt_selinux_context_switch_failed_total")).To(Equal(failures))
+	})
+
+	It("should count failed context switches", func() {
+		defaultLabelManager = execLabelFunc(func(label string) error {
+			return syscall.EACCES
+		})
+		switches := counterValue("kubevirt_selinux_context_switch_total")
+		failures := counterValue("kubevirt_selinux_context_switch_failed_total")
+
+		ce := ContextExecutor{desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+		Expect(ce.setDesiredContext()).ToNot(Succeed())
+		ce.resetContext()
+
+		Expect(counterValue("kubevirt_selinux_context_switch_total")).To(Equal(switches + 1))
+		Expect(counterValue("kubevirt_selinux_context_switch_failed_total")).To(Equal(failures + 1))
+	})
+
+	Context("with selinux enabled", func() {
+		BeforeEach(func() {
+			detectSELinux = func() (SELinux, bool, error) {
+				return nil, true, nil
+			}
+			ResetSELinuxDetectionForTest()
+		})
+
+		AfterEach(func() {
+			detectSELinux = NewSELinux
+			ResetSELinuxDetectionForTest()
+		})
+
+		It("should observe how long the thread was locked to run a command", func() {
+			defaultLabelManager = execLabelFunc(func(label string) error {
+				return nil
+			})
+			samples := threadLockSamples()
+
+			ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("true"), desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+			Expect(ce.Execute()).To(Succeed())
+
+			Expect(threadLockSamples()).To(Equal(samples + 1))
+		})
+
+		It("should observe how long the thread was locked when the context switch fails", func() {
+			defaultLabelManager = execLabelFunc(func(label string) error {
+				return syscall.EACCES
+			})
+			samples := threadLockSamples()
+
+			ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("true"), desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+			Expect(ce.Execute()).ToNot(Succeed())
+
+			Expect(threadLockSamples()).To(Equal(samples + 1))
+		})
+	})
+
+	It("should tolerate being registered twice", func() {
+		Expect(RegisterMetrics(registry, nodeName)).To(Succeed())
+	})
+})
+
+func hasNodeLabel(metric *dto.Metric, nodeName string) bool {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == "node" && label.GetValue() == nodeName {
+			return true
+		}
+	}
+	return false
+}
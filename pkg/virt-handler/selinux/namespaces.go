@@ -0,0 +1,207 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+
+	"golang.org/x/sys/unix"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// NSType is the name of a namespace type, as found in /proc/<pid>/ns.
+type NSType string
+
+const (
+	NSMount NSType = "mnt"
+	NSNet   NSType = "net"
+	NSIPC   NSType = "ipc"
+	NSUTS   NSType = "uts"
+)
+
+var nsCloneFlags = map[NSType]int{
+	NSMount: unix.CLONE_NEWNS,
+	NSNet:   unix.CLONE_NEWNET,
+	NSIPC:   unix.CLONE_NEWIPC,
+	NSUTS:   unix.CLONE_NEWUTS,
+}
+
+var setns = unix.Setns
+
+// EnterNamespaces makes the executor run the commands in the given namespaces
+// of the launcher, on top of its selinux context. The namespaces are entered
+// on the locked OS thread spawning the commands and left once they finished.
+// The namespaces of the launcher are opened by the first Execute and kept
+// open until Close, so that later commands enter the very same namespaces.
+//
+// The kernel refuses to enter a mount namespace from a thread sharing its
+// filesystem attributes with other threads, which is the case of every thread
+// of the Go runtime. NSMount is entered by the child instead: the command is
+// executed through virt-chroot, which joins the mount namespace of the
+// launcher pid right before executing the command. The path of the command
+// has to exist in the mount namespace of the launcher, and the command can't
+// be given a working directory, joining a mount namespace moves the child to
+// its root.
+func EnterNamespaces(types ...NSType) Option {
+	return func(ce *ContextExecutor) {
+		ce.namespaces = append([]NSType(nil), types...)
+		ce.nsHandles = newNamespaceHandles()
+	}
+}
+
+// namespaceHandles holds the file descriptors on the namespaces of a
+// launcher, shared by the copies of an executor.
+type namespaceHandles struct {
+	lock sync.Mutex
+	fds  map[NSType]int
+}
+
+func newNamespaceHandles() *namespaceHandles {
+	return &namespaceHandles{fds: map[NSType]int{}}
+}
+
+// get returns the file descriptor on the given namespace of pid, opening it
+// if needed.
+func (h *namespaceHandles) get(pid int, nsType NSType) (int, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if fd, exists := h.fds[nsType]; exists {
+		return fd, nil
+	}
+	fd, err := unix.Open(fmt.Sprintf("/proc/%d/ns/%s", pid, nsType), unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return -1, fmt.Errorf("failed to open the %s namespace of launcher pid %d: %v", nsType, pid, err)
+	}
+	h.fds[nsType] = fd
+	return fd, nil
+}
+
+func (h *namespaceHandles) close() error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	var errs []error
+	for nsType, fd := range h.fds {
+		if err := unix.Close(fd); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close the %s namespace: %v", nsType, err))
+		}
+		delete(h.fds, nsType)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+type enteredNamespace struct {
+	nsType     NSType
+	originalFD int
+}
+
+// enterNamespaces locks the OS thread and switches it to the requested
+// namespaces of the launcher. The returned function switches the thread back
+// to its original namespaces. The thread is only unlocked if that succeeded,
+// so that a thread stuck in the launcher namespaces is never reused.
+func (ce ContextExecutor) enterNamespaces() (restore func() error, err error) {
+	if len(ce.namespaces) == 0 {
+		return func() error { return nil }, nil
+	}
+
+	handles := ce.nsHandles
+	if handles == nil {
+		handles = newNamespaceHandles()
+		defer handles.close()
+	}
+
+	runtime.LockOSThread()
+	var entered []enteredNamespace
+	restore = func() error {
+		var errs []error
+		for i := len(entered) - 1; i >= 0; i-- {
+			ns := entered[i]
+			if err := setns(ns.originalFD, nsCloneFlags[ns.nsType]); err != nil {
+				errs = append(errs, fmt.Errorf("failed to restore the %s namespace: %v", ns.nsType, err))
+			}
+			unix.Close(ns.originalFD)
+		}
+		if len(errs) > 0 {
+			ce.getLogger().Reason(utilerrors.NewAggregate(errs)).Error("the OS thread could not be moved back to the virt-handler namespaces")
+			return utilerrors.NewAggregate(errs)
+		}
+		runtime.UnlockOSThread()
+		return nil
+	}
+
+	for _, nsType := range ce.namespaces {
+		if nsType == NSMount {
+			// entered by the child, see mountNamespaceArgs
+			continue
+		}
+		originalFD, err := ce.enterNamespace(handles, nsType)
+		if err != nil {
+			if restoreErr := restore(); restoreErr != nil {
+				return nil, utilerrors.NewAggregate([]error{err, restoreErr})
+			}
+			return nil, err
+		}
+		entered = append(entered, enteredNamespace{nsType: nsType, originalFD: originalFD})
+	}
+	return restore, nil
+}
+
+// enterNamespace switches the calling thread to the given namespace of the
+// launcher and returns a file descriptor on the namespace it left.
+func (ce ContextExecutor) enterNamespace(handles *namespaceHandles, nsType NSType) (int, error) {
+	flag, ok := nsCloneFlags[nsType]
+	if !ok {
+		return -1, fmt.Errorf("unsupported namespace type %q", nsType)
+	}
+	originalFD, err := unix.Open(fmt.Sprintf("/proc/%d/task/%d/ns/%s", os.Getpid(), unix.Gettid(), nsType), unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return -1, fmt.Errorf("failed to open the current %s namespace: %v", nsType, err)
+	}
+	targetFD, err := handles.get(ce.pid, nsType)
+	if err != nil {
+		unix.Close(originalFD)
+		return -1, err
+	}
+
+	ce.getLogger().V(debugVerbosity).Infof("entering the %s namespace of launcher pid %d", nsType, ce.pid)
+	if err := setns(targetFD, flag); err != nil {
+		unix.Close(originalFD)
+		return -1, fmt.Errorf("failed to enter the %s namespace of launcher pid %d: %v", nsType, ce.pid, err)
+	}
+	return originalFD, nil
+}
+
+// mountNamespaceArgs returns the arguments making the shim join the mount
+// namespace of the launcher, if it was requested.
+func (ce ContextExecutor) mountNamespaceArgs() ([]string, error) {
+	entersMountNamespace := false
+	for _, nsType := range ce.namespaces {
+		entersMountNamespace = entersMountNamespace || nsType == NSMount
+	}
+	if !entersMountNamespace {
+		return nil, nil
+	}
+	if ce.workingDir != "" {
+		return nil, fmt.Errorf("the commands entering the mnt namespace of launcher pid %d can't be given a working directory", ce.pid)
+	}
+	return []string{"--mount", fmt.Sprintf("/proc/%d/ns/%s", ce.pid, NSMount)}, nil
+}
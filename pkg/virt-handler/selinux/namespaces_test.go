@@ -0,0 +1,235 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/sys/unix"
+
+	"kubevirt.io/kubevirt/pkg/util/fdhygiene"
+)
+
+func hasCapSysAdmin() bool {
+	caps, err := readEffectiveCapabilities()
+	return err == nil && caps&(1<<capSysAdmin) != 0
+}
+
+var _ = Describe("Entering the launcher namespaces", func() {
+
+	var orgSetns = setns
+
+	AfterEach(func() {
+		setns = orgSetns
+	})
+
+	Context("with CAP_SYS_ADMIN", func() {
+		const launcherHostname = "kubevirt-ns-test"
+		var launcher *exec.Cmd
+		var mountDir string
+
+		BeforeEach(func() {
+			if !hasCapSysAdmin() {
+				Skip("entering namespaces requires CAP_SYS_ADMIN")
+			}
+			var err error
+			mountDir, err = ioutil.TempDir("", "launcher-mnt")
+			Expect(err).ToNot(HaveOccurred())
+			// the launcher mounts a tmpfs only visible in its mount namespace
+			launcher = exec.Command("sh", "-c", fmt.Sprintf("mount --make-rprivate / && mount -t tmpfs tmpfs %[1]s && touch %[1]s/marker && hostname %[2]s && echo ready && exec sleep 30", mountDir, launcherHostname))
+			launcher.SysProcAttr = &syscall.SysProcAttr{Cloneflags: unix.CLONE_NEWUTS | unix.CLONE_NEWNS}
+			stdout, err := launcher.StdoutPipe()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(launcher.Start()).To(Succeed())
+			line, err := bufio.NewReader(stdout).ReadString('\n')
+			Expect(err).ToNot(HaveOccurred())
+			Expect(line).To(Equal("ready\n"))
+		})
+
+		AfterEach(func() {
+			launcher.Process.Kill()
+			launcher.Wait()
+			os.RemoveAll(mountDir)
+			shimPath = "/usr/bin/virt-chroot"
+		})
+
+		It("should run the command in the launcher namespace and move back", func() {
+			hostname, err := os.Hostname()
+			Expect(err).ToNot(HaveOccurred())
+
+			ce := ContextExecutor{pid: launcher.Process.Pid, cmdToExecute: exec.Command("hostname")}
+			EnterNamespaces(NSUTS)(&ce)
+			stdout, _, err := ce.ExecuteWithOutput()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout.String()).To(Equal(launcherHostname + "\n"))
+
+			out, err := exec.Command("hostname").Output()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(out)).To(Equal(hostname + "\n"))
+		})
+
+		It("should run the command in the launcher mount namespace through the shim", func() {
+			shim, err := os.Executable()
+			Expect(err).ToNot(HaveOccurred())
+			shimPath = shim
+			cmd := exec.Command("sh", "-c", fmt.Sprintf("ls %s && hostname", mountDir))
+			cmd.Env = append(os.Environ(), shimEnv+"=true")
+
+			ce := ContextExecutor{pid: launcher.Process.Pid, cmdToExecute: cmd}
+			EnterNamespaces(NSMount, NSUTS)(&ce)
+			stdout, _, err := ce.ExecuteWithOutput()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stdout.String()).To(Equal("marker\n" + launcherHostname + "\n"))
+			Expect(ce.Close()).To(Succeed())
+
+			files, err := ioutil.ReadDir(mountDir)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files).To(BeEmpty())
+		})
+	})
+
+	It("should enter the mount namespace through the shim", func() {
+		setns = func(fd int, nstype int) error {
+			Fail("the mount namespace should be entered by the child")
+			return nil
+		}
+		shimPath = "/non/existent"
+		defer func() {
+			shimPath = "/usr/bin/virt-chroot"
+		}()
+
+		ce := ContextExecutor{pid: 1234, cmdToExecute: exec.Command("true")}
+		EnterNamespaces(NSMount)(&ce)
+		Expect(ce.Execute()).To(MatchError(ContainSubstring("/non/existent")))
+		Expect(ce.mountNamespaceArgs()).To(Equal([]string{"--mount", "/proc/1234/ns/mnt"}))
+	})
+
+	It("should not give a working directory to the commands entering the mount namespace", func() {
+		ce := ContextExecutor{pid: 1234, cmdToExecute: exec.Command("true"), workingDir: "/var/run/kubevirt"}
+		EnterNamespaces(NSMount)(&ce)
+		_, err := ce.mountNamespaceArgs()
+		Expect(err).To(MatchError("the commands entering the mnt namespace of launcher pid 1234 can't be given a working directory"))
+	})
+
+	It("should restore the entered namespaces if a later one can't be entered", func() {
+		var calls []int
+		setns = func(fd int, nstype int) error {
+			calls = append(calls, nstype)
+			if len(calls) == 2 {
+				return fmt.Errorf("setns failure")
+			}
+			return nil
+		}
+
+		ce := ContextExecutor{pid: os.Getpid(), cmdToExecute: exec.Command("true")}
+		EnterNamespaces(NSUTS, NSNet)(&ce)
+		err := ce.Execute()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("failed to enter the net namespace"))
+		Expect(calls).To(Equal([]int{unix.CLONE_NEWUTS, unix.CLONE_NEWNET, unix.CLONE_NEWUTS}))
+	})
+
+	It("should reject unsupported namespace types", func() {
+		ce := ContextExecutor{pid: os.Getpid(), cmdToExecute: exec.Command("true")}
+		EnterNamespaces(NSType("pid"))(&ce)
+		Expect(ce.Execute()).To(MatchError(ContainSubstring(`unsupported namespace type "pid"`)))
+	})
+
+	Context("holding the launcher namespaces", func() {
+		openFDCount := func() int {
+			fds, err := fdhygiene.OpenFDs(fdhygiene.ProcSelfFDDir)
+			Expect(err).ToNot(HaveOccurred())
+			return len(fds)
+		}
+
+		BeforeEach(func() {
+			setns = func(fd int, nstype int) error {
+				return nil
+			}
+		})
+
+		It("should keep the namespaces open until Close", func() {
+			ce := ContextExecutor{pid: os.Getpid(), cmdToExecute: exec.Command("true")}
+			EnterNamespaces(NSUTS, NSNet)(&ce)
+			before := openFDCount()
+
+			Expect(ce.Execute()).To(Succeed())
+			Expect(openFDCount()).To(Equal(before + 2))
+			Expect(ce.Execute()).To(Succeed())
+			Expect(openFDCount()).To(Equal(before + 2))
+
+			Expect(ce.Close()).To(Succeed())
+			Expect(openFDCount()).To(Equal(before))
+			Expect(ce.Close()).To(Succeed())
+		})
+
+		It("should not leak file descriptors across Execute and Close cycles", func() {
+			ce := ContextExecutor{pid: os.Getpid(), cmdToExecute: exec.Command("true")}
+			EnterNamespaces(NSUTS, NSIPC)(&ce)
+			before := openFDCount()
+
+			for i := 0; i < 10; i++ {
+				Expect(ce.Execute()).To(Succeed())
+				Expect(ce.Execute()).To(Succeed())
+				Expect(ce.Close()).To(Succeed())
+			}
+			Expect(openFDCount()).To(Equal(before))
+		})
+
+		It("should close the namespaces of batches", func() {
+			bce := BatchContextExecutor{
+				ContextExecutor: ContextExecutor{pid: os.Getpid()},
+				cmdsToExecute:   []*exec.Cmd{exec.Command("true"), exec.Command("true")},
+			}
+			EnterNamespaces(NSUTS)(&bce.ContextExecutor)
+			before := openFDCount()
+
+			Expect(bce.Execute()).To(Succeed())
+			Expect(openFDCount()).To(Equal(before + 1))
+			Expect(bce.Close()).To(Succeed())
+			Expect(openFDCount()).To(Equal(before))
+		})
+
+		It("should not keep the namespaces open if they are not held", func() {
+			ce := ContextExecutor{pid: os.Getpid(), cmdToExecute: exec.Command("true"), namespaces: []NSType{NSUTS}}
+			before := openFDCount()
+
+			Expect(ce.Execute()).To(Succeed())
+			Expect(openFDCount()).To(Equal(before))
+			Expect(ce.Close()).To(Succeed())
+		})
+	})
+
+	It("should not touch the namespaces when none is requested", func() {
+		setns = func(fd int, nstype int) error {
+			Fail("setns should not be called")
+			return nil
+		}
+		ce := ContextExecutor{pid: os.Getpid(), cmdToExecute: exec.Command("true")}
+		Expect(ce.Execute()).To(Succeed())
+	})
+})
@@ -0,0 +1,131 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"sync"
+)
+
+// maxPrefixedLineBytes bounds the partial line kept by a prefixing writer, a
+// longer line is written in chunks, each of them prefixed.
+const maxPrefixedLineBytes = 4096
+
+// WithOutputWriter copies the stdout and stderr of the child to w, each line
+// prefixed with prefix, e.g. the VMI name and launcher pid for correlating the
+// log lines. The output is still captured as without the option. A line not
+// terminated by the child is terminated once it exited, and a failure to
+// write to w drops the rest of the output without failing the command.
+func WithOutputWriter(w io.Writer, prefix string) Option {
+	return func(ce *ContextExecutor) {
+		ce.outputWriter = w
+		ce.outputPrefix = prefix
+	}
+}
+
+// prefixOutput tees the output of cmd through prefixing writers and returns
+// the function flushing them, and restoring the writers of cmd, once the
+// child exited.
+func (ce ContextExecutor) prefixOutput(cmd *exec.Cmd) func() {
+	out := &prefixedOutput{w: ce.outputWriter, prefix: []byte(ce.outputPrefix)}
+	stdout := &linePrefixWriter{out: out}
+	stderr := &linePrefixWriter{out: out}
+	origStdout, origStderr := cmd.Stdout, cmd.Stderr
+	cmd.Stdout = teeWriter(origStdout, stdout)
+	cmd.Stderr = teeWriter(origStderr, stderr)
+	return func() {
+		stdout.flush()
+		stderr.flush()
+		cmd.Stdout, cmd.Stderr = origStdout, origStderr
+	}
+}
+
+func teeWriter(orig io.Writer, w io.Writer) io.Writer {
+	if orig == nil {
+		return w
+	}
+	return io.MultiWriter(orig, w)
+}
+
+// prefixedOutput serializes the lines of stdout and stderr written to w.
+type prefixedOutput struct {
+	lock   sync.Mutex
+	w      io.Writer
+	prefix []byte
+	err    error
+}
+
+func (o *prefixedOutput) writeLine(line []byte) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if o.err != nil {
+		return
+	}
+	buf := make([]byte, 0, len(o.prefix)+len(line)+1)
+	buf = append(append(append(buf, o.prefix...), line...), '\n')
+	_, o.err = o.w.Write(buf)
+}
+
+// linePrefixWriter splits the output of a stream into lines. It never fails,
+// so that the child doesn't block or fail on a broken output writer.
+type linePrefixWriter struct {
+	out     *prefixedOutput
+	partial []byte
+}
+
+func (l *linePrefixWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			l.partial = append(l.partial, p...)
+			break
+		}
+		l.writeLine(p[:i])
+		p = p[i+1:]
+	}
+	for len(l.partial) > maxPrefixedLineBytes {
+		l.out.writeLine(l.partial[:maxPrefixedLineBytes])
+		l.partial = append(l.partial[:0], l.partial[maxPrefixedLineBytes:]...)
+	}
+	return n, nil
+}
+
+func (l *linePrefixWriter) writeLine(line []byte) {
+	if len(l.partial) > 0 {
+		line = append(l.partial, line...)
+		l.partial = l.partial[:0]
+	}
+	for len(line) > maxPrefixedLineBytes {
+		l.out.writeLine(line[:maxPrefixedLineBytes])
+		line = line[maxPrefixedLineBytes:]
+	}
+	l.out.writeLine(line)
+}
+
+// flush writes the line the child did not terminate.
+func (l *linePrefixWriter) flush() {
+	if len(l.partial) > 0 {
+		l.out.writeLine(l.partial)
+		l.partial = nil
+	}
+}
@@ -0,0 +1,149 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const testOutputPrefix = "testvmi[1234]: "
+
+// lockedBuffer is written concurrently by the output streams of the child.
+type lockedBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken log")
+}
+
+var _ = Describe("Prefixing the output of the child", func() {
+
+	var output *lockedBuffer
+
+	BeforeEach(func() {
+		output = &lockedBuffer{}
+	})
+
+	newExecutor := func(cmd *exec.Cmd, w io.Writer) ContextExecutor {
+		ce := ContextExecutor{pid: 1, cmdToExecute: cmd}
+		WithOutputWriter(w, testOutputPrefix)(&ce)
+		return ce
+	}
+
+	// lines returns the lines written, failing if one misses the prefix
+	lines := func() []string {
+		out := output.String()
+		ExpectWithOffset(1, out).To(HaveSuffix("\n"))
+		lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+		for _, line := range lines {
+			ExpectWithOffset(1, line).To(HavePrefix(testOutputPrefix))
+		}
+		return lines
+	}
+
+	It("should prefix every line of stdout and stderr", func() {
+		ce := newExecutor(exec.Command("sh", "-c", "echo first; echo second >&2; printf last"), output)
+		stdout, stderr, err := ce.ExecuteWithOutput()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(lines()).To(ConsistOf(testOutputPrefix+"first", testOutputPrefix+"second", testOutputPrefix+"last"))
+		Expect(stdout.String()).To(Equal("first\nlast"))
+		Expect(stderr.String()).To(Equal("second\n"))
+	})
+
+	It("should join the partial lines across writes", func() {
+		w := &linePrefixWriter{out: &prefixedOutput{w: output, prefix: []byte(testOutputPrefix)}}
+		for _, chunk := range []string{"ab", "c\nd", "", "e\nf"} {
+			n, err := w.Write([]byte(chunk))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(len(chunk)))
+		}
+		Expect(output.String()).To(Equal(testOutputPrefix + "abc\n" + testOutputPrefix + "de\n"))
+		w.flush()
+		Expect(lines()).To(Equal([]string{testOutputPrefix + "abc", testOutputPrefix + "de", testOutputPrefix + "f"}))
+	})
+
+	It("should split lines longer than the bound", func() {
+		w := &linePrefixWriter{out: &prefixedOutput{w: output, prefix: []byte(testOutputPrefix)}}
+		w.Write(bytes.Repeat([]byte("x"), 2*maxPrefixedLineBytes+1))
+		w.flush()
+		Expect(lines()).To(Equal([]string{
+			testOutputPrefix + strings.Repeat("x", maxPrefixedLineBytes),
+			testOutputPrefix + strings.Repeat("x", maxPrefixedLineBytes),
+			testOutputPrefix + "x",
+		}))
+	})
+
+	It("should not hang on large binary output", func() {
+		ce := newExecutor(exec.Command("head", "-c", "4194304", "/dev/urandom"), output)
+		done := make(chan error, 1)
+		go func() {
+			_, _, err := ce.ExecuteWithOutput()
+			done <- err
+		}()
+		Eventually(done, 30*time.Second).Should(Receive(BeNil()))
+		for _, line := range lines() {
+			Expect(len(line)).To(BeNumerically("<=", len(testOutputPrefix)+maxPrefixedLineBytes))
+		}
+	})
+
+	It("should not fail the command on a broken output writer", func() {
+		ce := newExecutor(exec.Command("seq", "1", "10000"), failingWriter{})
+		stdout, _, err := ce.ExecuteWithOutput()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(strings.Count(stdout.String(), "\n")).To(Equal(10000))
+	})
+
+	It("should keep and restore the writers wired by the caller", func() {
+		callerStdout := &bytes.Buffer{}
+		cmd := exec.Command("echo", "hello")
+		cmd.Stdout = callerStdout
+		ce := newExecutor(cmd, output)
+		Expect(ce.Execute()).To(Succeed())
+		Expect(callerStdout.String()).To(Equal("hello\n"))
+		Expect(lines()).To(Equal([]string{testOutputPrefix + "hello"}))
+		Expect(cmd.Stdout).To(BeIdenticalTo(callerStdout))
+		Expect(cmd.Stderr).To(BeNil())
+	})
+})
@@ -0,0 +1,92 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+)
+
+// IsPathLabeledFor reports whether path carries the file label of the
+// launcher pid, the one RelabelFiles applies, without relabeling it. Only the type and the level, whose categories
+// isolate the VMIs from each other, are compared: the user and the role the
+// path was labeled with don't matter, nor does the notation of the level.
+// Every path matches without selinux.
+func IsPathLabeledFor(vmiPid int, path string) (bool, error) {
+	return isPathLabeledWith(nil, vmiPid, path)
+}
+
+// isPathLabeledWith is IsPathLabeledFor reading the labels through manager,
+// or through the selinux of the host and its process label cache if nil.
+func isPathLabeledWith(manager LabelManager, pid int, path string) (bool, error) {
+	if !isSELinuxEnabled() {
+		return true, nil
+	}
+	ce := ContextExecutor{pid: pid, labelManager: manager}
+	launcherLabel, err := ce.getLabelForPID(pid)
+	if err != nil {
+		return false, err
+	}
+	if launcherLabel == "" {
+		return false, fmt.Errorf("the selinux label of launcher pid %d is empty", pid)
+	}
+	fileLabel, err := launcherFileLabel(launcherLabel)
+	if err != nil {
+		return false, err
+	}
+	label, err := ce.getLabelManager().FileLabel(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve the selinux label of %s: %v", path, err)
+	}
+	if label == "" {
+		return false, nil
+	}
+	return sameTypeAndLevel(fileLabel, label)
+}
+
+// sameTypeAndLevel compares the types and the levels of two labels, the
+// levels by their sensitivities and categories.
+func sameTypeAndLevel(label string, other string) (bool, error) {
+	typ, level, err := typeAndLevel(label)
+	if err != nil {
+		return false, err
+	}
+	otherTyp, otherLevel, err := typeAndLevel(other)
+	if err != nil {
+		return false, err
+	}
+	return typ == otherTyp && level == otherLevel, nil
+}
+
+// typeAndLevel returns the type of label and its level in canonical form, as
+// a low-high range.
+func typeAndLevel(label string) (typ string, level string, err error) {
+	components, err := ParseLabel(label)
+	if err != nil {
+		return "", "", err
+	}
+	if components.Level == "" {
+		return components.Type, "", nil
+	}
+	parsed, err := parseMCSLabel(label)
+	if err != nil {
+		return "", "", err
+	}
+	return parsed.typ, parsed.low.String() + "-" + parsed.high.String(), nil
+}
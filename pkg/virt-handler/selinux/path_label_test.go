@@ -0,0 +1,88 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+var _ = Describe("Path label", func() {
+	const launcherPID = 1234
+	const path = "/dev/vfio/1"
+
+	var manager *testutils.FakeLabelManager
+
+	BeforeEach(func() {
+		manager = testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(launcherPID, "system_u:system_r:container_t:s0:c1,c2")
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, true, nil
+		}
+		ResetSELinuxDetectionForTest()
+	})
+
+	AfterEach(func() {
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+	})
+
+	table.DescribeTable("should compare the type and the level of the path with the file label of the launcher", func(label string, matches bool) {
+		Expect(manager.SetFileLabel(path, label)).To(Succeed())
+		labeled, err := isPathLabeledWith(manager, launcherPID, path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(labeled).To(Equal(matches))
+	},
+		table.Entry("with the file label of the launcher", "system_u:object_r:container_file_t:s0:c1,c2", true),
+		table.Entry("with another user and role", "unconfined_u:system_r:container_file_t:s0:c1,c2", true),
+		table.Entry("with the categories in another notation", "system_u:object_r:container_file_t:s0:c2,c1-s0:c1.c2", true),
+		table.Entry("with the process label of the launcher", "system_u:system_r:container_t:s0:c1,c2", false),
+		table.Entry("with the categories of another launcher", "system_u:object_r:container_file_t:s0:c1,c3", false),
+		table.Entry("with a wider range of categories", "system_u:object_r:container_file_t:s0-s0:c0.c1023", false),
+		table.Entry("without categories", "system_u:object_r:container_file_t:s0", false),
+		table.Entry("without level", "system_u:object_r:container_file_t", false),
+		table.Entry("unlabeled", "", false),
+	)
+
+	It("should fail if the label of the path can't be read", func() {
+		_, err := isPathLabeledWith(manager, launcherPID, path)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("failed to retrieve the selinux label of /dev/vfio/1:"))
+	})
+
+	It("should fail on a malformed label", func() {
+		Expect(manager.SetFileLabel(path, "container_t")).To(Succeed())
+		_, err := isPathLabeledWith(manager, launcherPID, path)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should match every path without selinux", func() {
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, false, nil
+		}
+		ResetSELinuxDetectionForTest()
+		labeled, err := isPathLabeledWith(manager, launcherPID, path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(labeled).To(BeTrue())
+	})
+})
@@ -0,0 +1,391 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// permissiveTransitionsAllowed is set by virt-handler when the cluster allows
+// permissive transitions, see AllowPermissiveTransitions.
+var permissiveTransitionsAllowed int32
+
+// AllowPermissiveTransitions tells if the cluster allows the executors created
+// WithDangerousPermissiveTransition to run their child permissive. Executors
+// requesting it while not allowed fail without running the child.
+func AllowPermissiveTransitions(allowed bool) {
+	var value int32
+	if allowed {
+		value = 1
+	}
+	atomic.StoreInt32(&permissiveTransitionsAllowed, value)
+}
+
+func permissiveTransitionsAreAllowed() bool {
+	return atomic.LoadInt32(&permissiveTransitionsAllowed) == 1
+}
+
+// PermissiveDomainManager marks selinux types permissive on the node: the
+// denials of the processes of a permissive type are logged but not enforced.
+type PermissiveDomainManager interface {
+	IsPermissiveDomain(domain string) (bool, error)
+	AddPermissiveDomain(domain string) error
+	RemovePermissiveDomain(domain string) error
+}
+
+// IsPermissiveDomain tells if domain is listed by the semanage of the host as
+// permissive, by the policy or by an administrator.
+func (se *SELinuxImpl) IsPermissiveDomain(domain string) (bool, error) {
+	path, exists, err := lookupPath("semanage", se.procOnePrefix, se.Paths)
+	if err != nil {
+		return false, err
+	} else if !exists {
+		return false, fmt.Errorf("could not find 'semanage' binary")
+	}
+
+	out, err := se.execFunc("/usr/bin/virt-chroot", "--mount", "/proc/1/ns/mnt", "exec", "--", path, "permissive", "-l", "-n")
+	if err != nil {
+		return false, fmt.Errorf("failed to run semanage permissive -l - out: %q, error: %v", string(out), err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		// the headings of the builtin and customized types are skipped too
+		if strings.TrimSpace(line) == domain {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AddPermissiveDomain marks domain permissive with the semanage of the host.
+func (se *SELinuxImpl) AddPermissiveDomain(domain string) error {
+	return se.semanagePermissive("-a", domain)
+}
+
+// RemovePermissiveDomain puts domain back to enforcing.
+func (se *SELinuxImpl) RemovePermissiveDomain(domain string) error {
+	return se.semanagePermissive("-d", domain)
+}
+
+func (se *SELinuxImpl) semanagePermissive(action string, domain string) error {
+	path, exists, err := lookupPath("semanage", se.procOnePrefix, se.Paths)
+	if err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("could not find 'semanage' binary")
+	}
+
+	out, err := se.execFunc("/usr/bin/virt-chroot", "--mount", "/proc/1/ns/mnt", "exec", "--", path, "permissive", action, domain)
+	if err != nil {
+		return fmt.Errorf("failed to run semanage permissive %s %s - out: %q, error: %v", action, domain, string(out), err)
+	}
+	return nil
+}
+
+// permissiveDomains tracks the types marked permissive by the executors of
+// virt-handler, so that the executors running at the same time with the same
+// type mark it once, and that the types an administrator marked permissive are
+// left alone. semanage runs without holding the lock, the executors of a type
+// being marked or put back to enforcing wait for it, see permissiveDomain.
+var permissiveDomains = struct {
+	sync.Mutex
+	domains map[string]*permissiveDomain
+	// stateDir persists the types marked by virt-handler, see
+	// ReconcilePermissiveDomains
+	stateDir string
+}{domains: map[string]*permissiveDomain{}}
+
+type permissiveDomain struct {
+	// users is the number of executors running their child with the type
+	users int
+	// preexisting is set if the type was permissive before the first of them
+	preexisting bool
+	// inFlight is closed once semanage is done marking the type or putting it
+	// back to enforcing, it is nil otherwise
+	inFlight chan struct{}
+}
+
+// ReconcilePermissiveDomains puts the types virt-handler marked permissive
+// before it restarted back to enforcing: the executors running them are gone,
+// but the marking survives them. The marked types are persisted in stateDir,
+// which is expected to survive reboots like the marking itself. It has to be
+// called before the first executor runs. A type which can't be put back to
+// enforcing is retried once the next executor using it is done.
+func ReconcilePermissiveDomains(manager PermissiveDomainManager, stateDir string) error {
+	permissiveDomains.Lock()
+	permissiveDomains.stateDir = stateDir
+	permissiveDomains.Unlock()
+
+	entries, err := ioutil.ReadDir(stateDir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read the permissive types marked by virt-handler from %s: %v", stateDir, err)
+	}
+	var errs []error
+	for _, entry := range entries {
+		domain := entry.Name()
+		if err := removeStalePermissiveDomain(manager, domain); err != nil {
+			errs = append(errs, fmt.Errorf("failed to put %s back to enforcing: %v", domain, err))
+			permissiveDomains.Lock()
+			permissiveDomains.domains[domain] = &permissiveDomain{}
+			permissiveDomains.Unlock()
+			continue
+		}
+		if err := forgetPermissiveDomain(stateDir, domain); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func removeStalePermissiveDomain(manager PermissiveDomainManager, domain string) error {
+	permissive, err := manager.IsPermissiveDomain(domain)
+	if err != nil || !permissive {
+		return err
+	}
+	return manager.RemovePermissiveDomain(domain)
+}
+
+// recordPermissiveDomain persists that virt-handler marks domain permissive,
+// before it does so.
+func recordPermissiveDomain(stateDir string, domain string) error {
+	if stateDir == "" {
+		return nil
+	}
+	if filepath.Base(domain) != domain {
+		return fmt.Errorf("invalid selinux type %q", domain)
+	}
+	if err := os.MkdirAll(stateDir, 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(stateDir, domain), nil, 0600)
+}
+
+// forgetPermissiveDomain drops the record of domain once it is back to
+// enforcing.
+func forgetPermissiveDomain(stateDir string, domain string) error {
+	if stateDir == "" {
+		return nil
+	}
+	if err := os.Remove(filepath.Join(stateDir, domain)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// waitPermissiveDomain returns the tracked domain once semanage is not
+// running for it, or nil if it is not tracked. It is called with the lock
+// held, which is released while waiting.
+func waitPermissiveDomain(domain string) *permissiveDomain {
+	for {
+		tracked, exists := permissiveDomains.domains[domain]
+		if !exists {
+			return nil
+		}
+		if tracked.inFlight == nil {
+			return tracked
+		}
+		inFlight := tracked.inFlight
+		permissiveDomains.Unlock()
+		<-inFlight
+		permissiveDomains.Lock()
+	}
+}
+
+// acquirePermissiveDomain marks domain permissive for an executor, unless
+// another executor did already or it was permissive before.
+func acquirePermissiveDomain(manager PermissiveDomainManager, domain string) error {
+	permissiveDomains.Lock()
+	if tracked := waitPermissiveDomain(domain); tracked != nil {
+		// also covers the types whose removal failed, they are still ours
+		tracked.users++
+		permissiveDomains.Unlock()
+		return nil
+	}
+	tracked := &permissiveDomain{users: 1, inFlight: make(chan struct{})}
+	permissiveDomains.domains[domain] = tracked
+	stateDir := permissiveDomains.stateDir
+	permissiveDomains.Unlock()
+
+	permissive, err := manager.IsPermissiveDomain(domain)
+	if err == nil && !permissive {
+		err = recordPermissiveDomain(stateDir, domain)
+		if err == nil {
+			err = manager.AddPermissiveDomain(domain)
+		}
+		if err != nil {
+			// nothing was marked, dropping the record can't fail the executor
+			forgetPermissiveDomain(stateDir, domain)
+		}
+	}
+
+	permissiveDomains.Lock()
+	defer permissiveDomains.Unlock()
+	close(tracked.inFlight)
+	tracked.inFlight = nil
+	if err != nil {
+		delete(permissiveDomains.domains, domain)
+		return err
+	}
+	tracked.preexisting = permissive
+	return nil
+}
+
+// releasePermissiveDomain puts domain back to enforcing once the last executor
+// using it is done, unless it was permissive before.
+func releasePermissiveDomain(manager PermissiveDomainManager, domain string) error {
+	permissiveDomains.Lock()
+	tracked := waitPermissiveDomain(domain)
+	if tracked == nil {
+		permissiveDomains.Unlock()
+		return nil
+	}
+	tracked.users--
+	if tracked.users > 0 {
+		permissiveDomains.Unlock()
+		return nil
+	}
+	if tracked.preexisting {
+		delete(permissiveDomains.domains, domain)
+		permissiveDomains.Unlock()
+		return nil
+	}
+	tracked.inFlight = make(chan struct{})
+	stateDir := permissiveDomains.stateDir
+	permissiveDomains.Unlock()
+
+	err := manager.RemovePermissiveDomain(domain)
+
+	permissiveDomains.Lock()
+	defer permissiveDomains.Unlock()
+	close(tracked.inFlight)
+	tracked.inFlight = nil
+	if err != nil {
+		// still permissive, and known to be marked by virt-handler
+		return err
+	}
+	delete(permissiveDomains.domains, domain)
+	// a stale record is dropped on the next start, the type being enforcing
+	return forgetPermissiveDomain(stateDir, domain)
+}
+
+// WithDangerousPermissiveTransition makes the executor mark the type the child
+// runs with permissive for the duration of the execution, to troubleshoot the
+// denials of a command without switching the whole node to permissive. Every
+// would-be denial of the child found in the audit log is logged.
+//
+// This is dangerous: the type is permissive for every process running with it
+// on the node while the child runs, e.g. for all the launchers sharing the
+// launcher type. Combine it with WithTypeTransition to confine it to a type
+// dedicated to the child. The executors running at the same time with the same
+// type share the marking, the type is put back to enforcing once the last one
+// is done. A type already permissive on the node, e.g. marked by an
+// administrator, is left as is. A type left permissive by a restart of
+// virt-handler is put back to enforcing by ReconcilePermissiveDomains. The
+// cluster has to allow it with AllowPermissiveTransitions, and it is ignored
+// without selinux.
+func WithDangerousPermissiveTransition(manager PermissiveDomainManager, auditLogPath string) Option {
+	return func(ce *ContextExecutor) {
+		ce.permissiveManager = manager
+		ce.permissiveAuditLogPath = auditLogPath
+	}
+}
+
+// inPermissiveDomain runs f with the type of the desired label permissive, if
+// requested, and logs the denials of that label logged in the meantime. The
+// type is put back to enforcing even if f failed, see releasePermissiveDomain.
+func (ce ContextExecutor) inPermissiveDomain(f func() error) (err error) {
+	if ce.permissiveManager == nil {
+		return f()
+	}
+	if !permissiveTransitionsAreAllowed() {
+		return fmt.Errorf("refusing to run the child of launcher pid %d permissive: permissive transitions are not allowed by the cluster", ce.pid)
+	}
+	domain, err := labelType(ce.desiredLabel)
+	if err != nil {
+		return err
+	}
+
+	logger := ce.getLogger()
+	denials := NewDenialWatcher(ce.permissiveAuditLogPath, nil, nil)
+	if err := denials.openAuditLog(true); err != nil {
+		logger.Reason(err).Warningf("failed to open the audit log %s, the would-be denials of %s won't be logged", ce.permissiveAuditLogPath, domain)
+		denials = nil
+	} else {
+		defer denials.closeAuditLog()
+	}
+
+	if err := acquirePermissiveDomain(ce.permissiveManager, domain); err != nil {
+		return fmt.Errorf("failed to mark %s permissive: %v", domain, err)
+	}
+	logger.Warningf("running the child of launcher pid %d with %s permissive", ce.pid, domain)
+	defer func() {
+		var errs []error
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if removeErr := releasePermissiveDomain(ce.permissiveManager, domain); removeErr != nil {
+			errs = append(errs, fmt.Errorf("failed to put %s back to enforcing: %v", domain, removeErr))
+		}
+		err = utilerrors.NewAggregate(errs)
+	}()
+
+	err = f()
+	if denials != nil {
+		ce.logWouldBeDenials(denials)
+	}
+	return err
+}
+
+// logWouldBeDenials logs the denials of the desired label appended to the
+// audit log since it was opened.
+func (ce ContextExecutor) logWouldBeDenials(denials *DenialWatcher) {
+	logger := ce.getLogger()
+	lines, err := denials.readLines()
+	if err != nil {
+		logger.Reason(err).Warningf("failed to read the audit log %s", ce.permissiveAuditLogPath)
+		return
+	}
+	for _, line := range lines {
+		if denial, ok := ParseAVCDenial(line); ok && denial.SContext == ce.desiredLabel {
+			logger.Warningf("would-be denial of the child of launcher pid %d: %s", ce.pid, denial.Summary())
+		}
+	}
+}
+
+// labelType returns the type of label.
+func labelType(label string) (string, error) {
+	parsed, err := ParseLabel(label)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Type == "" {
+		return "", fmt.Errorf("no selinux type in the label %q", label)
+	}
+	return parsed.Type, nil
+}
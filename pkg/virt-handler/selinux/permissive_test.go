@@ -0,0 +1,404 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/client-go/log"
+)
+
+type fakePermissiveDomainManager struct {
+	lock       sync.Mutex
+	calls      []string
+	permissive bool
+	listErr    error
+	addErr     error
+	removeErr  error
+	// onAdd is called by AddPermissiveDomain if set, e.g. to block semanage
+	onAdd func(domain string)
+}
+
+func (m *fakePermissiveDomainManager) IsPermissiveDomain(domain string) (bool, error) {
+	return m.permissive, m.listErr
+}
+
+func (m *fakePermissiveDomainManager) AddPermissiveDomain(domain string) error {
+	m.record("add " + domain)
+	if m.onAdd != nil {
+		m.onAdd(domain)
+	}
+	return m.addErr
+}
+
+func (m *fakePermissiveDomainManager) RemovePermissiveDomain(domain string) error {
+	m.record("remove " + domain)
+	return m.removeErr
+}
+
+func (m *fakePermissiveDomainManager) record(call string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.calls = append(m.calls, call)
+}
+
+func (m *fakePermissiveDomainManager) recorded() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append([]string(nil), m.calls...)
+}
+
+var _ = Describe("Permissive transitions", func() {
+	var tempDir string
+	var auditLogPath string
+	var manager *fakePermissiveDomainManager
+	var logs *bytes.Buffer
+
+	newExecutor := func(opts ...Option) ContextExecutor {
+		ce := ContextExecutor{pid: 1234, desiredLabel: launcherLabel}
+		for _, opt := range opts {
+			opt(&ce)
+		}
+		return ce
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "kubevirt-permissive")
+		Expect(err).ToNot(HaveOccurred())
+		auditLogPath = filepath.Join(tempDir, "audit.log")
+		Expect(ioutil.WriteFile(auditLogPath, []byte(avcLine(launcherLabel, 1)+"\n"), 0600)).To(Succeed())
+		manager = &fakePermissiveDomainManager{}
+		logs = &bytes.Buffer{}
+		log.Logger(logComponent).SetIOWriter(logs)
+	})
+
+	AfterEach(func() {
+		permissiveDomains.domains = map[string]*permissiveDomain{}
+		permissiveDomains.stateDir = ""
+		AllowPermissiveTransitions(false)
+		log.Logger(logComponent).SetIOWriter(GinkgoWriter)
+		os.RemoveAll(tempDir)
+	})
+
+	It("should not mark anything permissive without the option", func() {
+		AllowPermissiveTransitions(true)
+		executed := false
+		Expect(newExecutor().inPermissiveDomain(func() error {
+			executed = true
+			return nil
+		})).To(Succeed())
+		Expect(executed).To(BeTrue())
+		Expect(manager.calls).To(BeEmpty())
+	})
+
+	It("should refuse to run the child when the cluster does not allow it", func() {
+		executed := false
+		ce := newExecutor(WithDangerousPermissiveTransition(manager, auditLogPath))
+		err := ce.inPermissiveDomain(func() error {
+			executed = true
+			return nil
+		})
+		Expect(err).To(MatchError(ContainSubstring("not allowed by the cluster")))
+		Expect(executed).To(BeFalse())
+		Expect(manager.calls).To(BeEmpty())
+	})
+
+	It("should mark the launcher type permissive only while the child runs", func() {
+		AllowPermissiveTransitions(true)
+		ce := newExecutor(WithDangerousPermissiveTransition(manager, auditLogPath))
+		Expect(ce.inPermissiveDomain(func() error {
+			Expect(manager.calls).To(Equal([]string{"add container_t"}))
+			return nil
+		})).To(Succeed())
+		Expect(manager.calls).To(Equal([]string{"add container_t", "remove container_t"}))
+	})
+
+	It("should put the type back to enforcing when the child fails", func() {
+		AllowPermissiveTransitions(true)
+		manager.removeErr = fmt.Errorf("semanage failed")
+		ce := newExecutor(WithDangerousPermissiveTransition(manager, auditLogPath))
+		err := ce.inPermissiveDomain(func() error {
+			return fmt.Errorf("exit status 1")
+		})
+		Expect(err).To(MatchError(ContainSubstring("exit status 1")))
+		Expect(err).To(MatchError(ContainSubstring("failed to put container_t back to enforcing")))
+		Expect(manager.calls).To(Equal([]string{"add container_t", "remove container_t"}))
+	})
+
+	It("should not run the child if the type can't be marked permissive", func() {
+		AllowPermissiveTransitions(true)
+		manager.addErr = fmt.Errorf("semanage failed")
+		executed := false
+		ce := newExecutor(WithDangerousPermissiveTransition(manager, auditLogPath))
+		Expect(ce.inPermissiveDomain(func() error {
+			executed = true
+			return nil
+		})).ToNot(Succeed())
+		Expect(executed).To(BeFalse())
+		Expect(manager.calls).To(Equal([]string{"add container_t"}))
+	})
+
+	It("should leave a type permissive before alone", func() {
+		AllowPermissiveTransitions(true)
+		manager.permissive = true
+		executed := false
+		ce := newExecutor(WithDangerousPermissiveTransition(manager, auditLogPath))
+		Expect(ce.inPermissiveDomain(func() error {
+			executed = true
+			return nil
+		})).To(Succeed())
+		Expect(executed).To(BeTrue())
+		Expect(manager.calls).To(BeEmpty())
+	})
+
+	It("should not run the child if the permissive types can't be listed", func() {
+		AllowPermissiveTransitions(true)
+		manager.listErr = fmt.Errorf("semanage failed")
+		ce := newExecutor(WithDangerousPermissiveTransition(manager, auditLogPath))
+		Expect(ce.inPermissiveDomain(func() error {
+			Fail("the child should not run")
+			return nil
+		})).To(MatchError(ContainSubstring("failed to mark container_t permissive: semanage failed")))
+		Expect(manager.calls).To(BeEmpty())
+	})
+
+	It("should mark the type once for the executors running at the same time", func() {
+		AllowPermissiveTransitions(true)
+		running := make(chan struct{})
+		release := make(chan struct{})
+		done := make(chan error)
+		go func() {
+			ce := newExecutor(WithDangerousPermissiveTransition(manager, auditLogPath))
+			done <- ce.inPermissiveDomain(func() error {
+				close(running)
+				<-release
+				return nil
+			})
+		}()
+		<-running
+
+		ce := newExecutor(WithDangerousPermissiveTransition(manager, auditLogPath))
+		Expect(ce.inPermissiveDomain(func() error {
+			return nil
+		})).To(Succeed())
+		Expect(manager.recorded()).To(Equal([]string{"add container_t"}))
+
+		close(release)
+		Expect(<-done).To(Succeed())
+		Expect(manager.calls).To(Equal([]string{"add container_t", "remove container_t"}))
+	})
+
+	It("should retry putting the type back to enforcing with the next executor", func() {
+		AllowPermissiveTransitions(true)
+		manager.removeErr = fmt.Errorf("semanage failed")
+		ce := newExecutor(WithDangerousPermissiveTransition(manager, auditLogPath))
+		Expect(ce.inPermissiveDomain(func() error {
+			return nil
+		})).ToNot(Succeed())
+
+		// the type is still permissive, but not because of an administrator
+		manager.permissive = true
+		manager.removeErr = nil
+		Expect(ce.inPermissiveDomain(func() error {
+			return nil
+		})).To(Succeed())
+		Expect(manager.calls).To(Equal([]string{"add container_t", "remove container_t", "remove container_t"}))
+	})
+
+	It("should not stall the executors of other types while semanage runs", func() {
+		AllowPermissiveTransitions(true)
+		// the executors log concurrently
+		log.Logger(logComponent).SetIOWriter(GinkgoWriter)
+		adding := make(chan struct{})
+		added := make(chan struct{})
+		manager.onAdd = func(domain string) {
+			if domain == "container_t" {
+				close(adding)
+				<-added
+			}
+		}
+		done := make(chan error, 2)
+		childRan := make(chan struct{})
+		release := make(chan struct{})
+		go func() {
+			ce := newExecutor(WithDangerousPermissiveTransition(manager, auditLogPath))
+			done <- ce.inPermissiveDomain(func() error {
+				<-release
+				return nil
+			})
+		}()
+		<-adding
+
+		By("running an executor with another type")
+		ce := newExecutor(WithDangerousPermissiveTransition(manager, auditLogPath))
+		ce.desiredLabel = "system_u:system_r:virt_launcher_t:s0"
+		Expect(ce.inPermissiveDomain(func() error {
+			return nil
+		})).To(Succeed())
+
+		By("waiting for the type being marked with an executor of the same type")
+		go func() {
+			ce := newExecutor(WithDangerousPermissiveTransition(manager, auditLogPath))
+			done <- ce.inPermissiveDomain(func() error {
+				close(childRan)
+				return nil
+			})
+		}()
+		Consistently(childRan, 100*time.Millisecond).ShouldNot(BeClosed())
+
+		close(added)
+		Eventually(childRan).Should(BeClosed())
+		Expect(<-done).To(Succeed())
+		close(release)
+		Expect(<-done).To(Succeed())
+		Expect(manager.recorded()).To(ConsistOf("add virt_launcher_t", "remove virt_launcher_t", "add container_t", "remove container_t"))
+	})
+
+	Context("with a state dir", func() {
+		var stateDir string
+
+		BeforeEach(func() {
+			stateDir = filepath.Join(tempDir, "state")
+		})
+
+		It("should persist the type marked permissive until it is back to enforcing", func() {
+			AllowPermissiveTransitions(true)
+			Expect(ReconcilePermissiveDomains(manager, stateDir)).To(Succeed())
+			ce := newExecutor(WithDangerousPermissiveTransition(manager, auditLogPath))
+			Expect(ce.inPermissiveDomain(func() error {
+				Expect(filepath.Join(stateDir, "container_t")).To(BeAnExistingFile())
+				return nil
+			})).To(Succeed())
+			Expect(filepath.Join(stateDir, "container_t")).ToNot(BeAnExistingFile())
+		})
+
+		It("should not persist a type permissive before", func() {
+			AllowPermissiveTransitions(true)
+			manager.permissive = true
+			Expect(ReconcilePermissiveDomains(manager, stateDir)).To(Succeed())
+			ce := newExecutor(WithDangerousPermissiveTransition(manager, auditLogPath))
+			Expect(ce.inPermissiveDomain(func() error {
+				Expect(filepath.Join(stateDir, "container_t")).ToNot(BeAnExistingFile())
+				return nil
+			})).To(Succeed())
+		})
+
+		It("should put the types left permissive by a restart back to enforcing", func() {
+			Expect(recordPermissiveDomain(stateDir, "container_t")).To(Succeed())
+			manager.permissive = true
+			Expect(ReconcilePermissiveDomains(manager, stateDir)).To(Succeed())
+			Expect(manager.recorded()).To(Equal([]string{"remove container_t"}))
+			Expect(filepath.Join(stateDir, "container_t")).ToNot(BeAnExistingFile())
+		})
+
+		It("should drop the records of the types already back to enforcing", func() {
+			Expect(recordPermissiveDomain(stateDir, "container_t")).To(Succeed())
+			Expect(ReconcilePermissiveDomains(manager, stateDir)).To(Succeed())
+			Expect(manager.recorded()).To(BeEmpty())
+			Expect(filepath.Join(stateDir, "container_t")).ToNot(BeAnExistingFile())
+		})
+
+		It("should retry putting a type left permissive back to enforcing with the next executor", func() {
+			AllowPermissiveTransitions(true)
+			Expect(recordPermissiveDomain(stateDir, "container_t")).To(Succeed())
+			manager.permissive = true
+			manager.removeErr = fmt.Errorf("semanage failed")
+			Expect(ReconcilePermissiveDomains(manager, stateDir)).To(MatchError(ContainSubstring("failed to put container_t back to enforcing: semanage failed")))
+			Expect(filepath.Join(stateDir, "container_t")).To(BeAnExistingFile())
+
+			manager.removeErr = nil
+			ce := newExecutor(WithDangerousPermissiveTransition(manager, auditLogPath))
+			Expect(ce.inPermissiveDomain(func() error {
+				return nil
+			})).To(Succeed())
+			Expect(manager.recorded()).To(Equal([]string{"remove container_t", "remove container_t"}))
+			Expect(filepath.Join(stateDir, "container_t")).ToNot(BeAnExistingFile())
+		})
+
+		It("should succeed without a state dir yet", func() {
+			Expect(ReconcilePermissiveDomains(manager, stateDir)).To(Succeed())
+			Expect(manager.recorded()).To(BeEmpty())
+		})
+	})
+
+	It("should log the would-be denials of the child only", func() {
+		AllowPermissiveTransitions(true)
+		ce := newExecutor(WithDangerousPermissiveTransition(manager, auditLogPath))
+		Expect(ce.inPermissiveDomain(func() error {
+			f, err := os.OpenFile(auditLogPath, os.O_APPEND|os.O_WRONLY, 0)
+			Expect(err).ToNot(HaveOccurred())
+			defer f.Close()
+			_, err = fmt.Fprintf(f, "%s\n%s\n", avcLine(launcherLabel, 2), avcLine(otherLabel, 3))
+			Expect(err).ToNot(HaveOccurred())
+			return nil
+		})).To(Succeed())
+		Expect(bytes.Count(logs.Bytes(), []byte("would-be denial"))).To(Equal(1))
+		Expect(logs.String()).To(ContainSubstring("scontext=" + launcherLabel))
+		Expect(logs.String()).ToNot(ContainSubstring("scontext=" + otherLabel))
+	})
+
+	It("should mark and unmark the domain with semanage", func() {
+		Expect(os.MkdirAll(filepath.Join(tempDir, "/usr/sbin"), 0777)).To(Succeed())
+		touch(filepath.Join(tempDir, "/usr/sbin", "semanage"))
+		var executed [][]string
+		selinux := &SELinuxImpl{
+			Paths:         []string{"/usr/bin", "/usr/sbin"},
+			procOnePrefix: tempDir,
+			execFunc: func(binary string, args ...string) ([]byte, error) {
+				executed = append(executed, append([]string{binary}, args...))
+				return nil, nil
+			},
+		}
+		Expect(selinux.AddPermissiveDomain("container_t")).To(Succeed())
+		Expect(selinux.RemovePermissiveDomain("container_t")).To(Succeed())
+		Expect(executed).To(Equal([][]string{
+			{"/usr/bin/virt-chroot", "--mount", "/proc/1/ns/mnt", "exec", "--", "/usr/sbin/semanage", "permissive", "-a", "container_t"},
+			{"/usr/bin/virt-chroot", "--mount", "/proc/1/ns/mnt", "exec", "--", "/usr/sbin/semanage", "permissive", "-d", "container_t"},
+		}))
+	})
+
+	It("should list the permissive domains with semanage", func() {
+		Expect(os.MkdirAll(filepath.Join(tempDir, "/usr/sbin"), 0777)).To(Succeed())
+		touch(filepath.Join(tempDir, "/usr/sbin", "semanage"))
+		var executed [][]string
+		selinux := &SELinuxImpl{
+			Paths:         []string{"/usr/bin", "/usr/sbin"},
+			procOnePrefix: tempDir,
+			execFunc: func(binary string, args ...string) ([]byte, error) {
+				executed = append(executed, append([]string{binary}, args...))
+				return []byte("\nBuiltin Permissive Types \n\nsanlock_t\n\nCustomized Permissive Types\n\ncontainer_t\n"), nil
+			},
+		}
+		Expect(selinux.IsPermissiveDomain("container_t")).To(BeTrue())
+		Expect(selinux.IsPermissiveDomain("sanlock_t")).To(BeTrue())
+		Expect(selinux.IsPermissiveDomain("virt_launcher_t")).To(BeFalse())
+		Expect(executed[0]).To(Equal([]string{"/usr/bin/virt-chroot", "--mount", "/proc/1/ns/mnt", "exec", "--", "/usr/sbin/semanage", "permissive", "-l", "-n"}))
+	})
+})
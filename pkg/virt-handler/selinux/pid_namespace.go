@@ -0,0 +1,60 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+var readlink = os.Readlink
+
+// WithPIDNamespaceCheck makes the creation of the executor fail with a
+// *PIDNamespaceMismatchError if the given pid isn't in the pid namespace of
+// virt-handler, instead of reading the labels of whatever process the number
+// names there. It is meant for the pids expected to share the pid namespace of
+// virt-handler, launchers running in a pid namespace of their own fail the
+// check.
+func WithPIDNamespaceCheck() Option {
+	return func(ce *ContextExecutor) {
+		ce.checkPIDNamespace = true
+	}
+}
+
+// checkSamePIDNamespace compares the pid namespace of pid with the one of
+// virt-handler, as found in /proc/<pid>/ns/pid.
+func checkSamePIDNamespace(pid int) error {
+	expected, err := readlink(filepath.Join(procRoot, "self", "ns", "pid"))
+	if err != nil {
+		return fmt.Errorf("failed to read the pid namespace of virt-handler: %v", err)
+	}
+	namespace, err := readlink(filepath.Join(procRoot, strconv.Itoa(pid), "ns", "pid"))
+	if os.IsNotExist(err) {
+		return &LauncherExitedError{PID: pid}
+	} else if err != nil {
+		return fmt.Errorf("failed to read the pid namespace of pid %d: %v", pid, err)
+	}
+	if namespace != expected {
+		return &PIDNamespaceMismatchError{PID: pid, Namespace: namespace, Expected: expected}
+	}
+	return nil
+}
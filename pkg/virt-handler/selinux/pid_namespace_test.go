@@ -0,0 +1,112 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+var _ = Describe("PID namespace check", func() {
+	const launcherPID = 1234
+	const handlerNamespace = "pid:[4026531836]"
+
+	var manager *testutils.FakeLabelManager
+	var restoreProcRoot func()
+	var namespaces map[string]string
+
+	BeforeEach(func() {
+		restoreProcRoot = fakeProcRoot(launcherPID)
+		manager = testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(launcherPID, testLauncherLabel)
+		manager.SetProcessLabel(os.Getpid(), testOriginalLabel)
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, true, nil
+		}
+		ResetSELinuxDetectionForTest()
+
+		namespaces = map[string]string{
+			filepath.Join(procRoot, "self", "ns", "pid"): handlerNamespace,
+		}
+		readlink = func(path string) (string, error) {
+			if namespace, exists := namespaces[path]; exists {
+				return namespace, nil
+			}
+			return "", &os.PathError{Op: "readlink", Path: path, Err: syscall.ENOENT}
+		}
+	})
+
+	AfterEach(func() {
+		readlink = os.Readlink
+		restoreProcRoot()
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+	})
+
+	setLauncherNamespace := func(namespace string) {
+		namespaces[filepath.Join(procRoot, "1234", "ns", "pid")] = namespace
+	}
+
+	It("should accept a pid in the pid namespace of virt-handler", func() {
+		setLauncherNamespace(handlerNamespace)
+		ce, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager), WithPIDNamespaceCheck())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ce.desiredLabel).To(Equal(testLauncherLabel))
+	})
+
+	It("should refuse a pid in another pid namespace", func() {
+		setLauncherNamespace("pid:[4026532448]")
+		_, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager), WithPIDNamespaceCheck())
+		Expect(IsPIDNamespaceMismatch(err)).To(BeTrue())
+
+		var mismatchErr *PIDNamespaceMismatchError
+		Expect(errors.As(err, &mismatchErr)).To(BeTrue())
+		Expect(mismatchErr.PID).To(Equal(launcherPID))
+		Expect(mismatchErr.Namespace).To(Equal("pid:[4026532448]"))
+		Expect(mismatchErr.Expected).To(Equal(handlerNamespace))
+		Expect(err).To(MatchError(ContainSubstring("translate it into the pid namespace of virt-handler")))
+	})
+
+	It("should report a pid without process as exited", func() {
+		_, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager), WithPIDNamespaceCheck())
+		Expect(IsLauncherExited(err)).To(BeTrue())
+	})
+
+	It("should fail if the pid namespace of virt-handler can't be read", func() {
+		setLauncherNamespace(handlerNamespace)
+		delete(namespaces, filepath.Join(procRoot, "self", "ns", "pid"))
+		_, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager), WithPIDNamespaceCheck())
+		Expect(err).To(MatchError(ContainSubstring("failed to read the pid namespace of virt-handler")))
+	})
+
+	It("should not compare the pid namespaces unless asked to", func() {
+		setLauncherNamespace("pid:[4026532448]")
+		_, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager))
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
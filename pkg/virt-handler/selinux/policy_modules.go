@@ -0,0 +1,143 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"kubevirt.io/client-go/log"
+)
+
+// RequiredPolicyModules are the policy modules of the node defining the
+// container and svirt types the launchers run with.
+var RequiredPolicyModules = []string{"container", "virt"}
+
+// PolicyModuleEnumerator lists the policy modules loaded on the node.
+type PolicyModuleEnumerator interface {
+	LoadedPolicyModules() ([]string, error)
+}
+
+// LoadedPolicyModules lists the enabled policy modules with the semodule of
+// the host, the modules aren't exposed by selinuxfs.
+func (se *SELinuxImpl) LoadedPolicyModules() ([]string, error) {
+	path, exists, err := lookupPath("semodule", se.procOnePrefix, se.Paths)
+	if err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("could not find 'semodule' binary")
+	}
+
+	out, err := se.execFunc("/usr/bin/virt-chroot", "--mount", "/proc/1/ns/mnt", "exec", "--", path, "-l")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the policy modules - out: %q, error: %v", string(out), err)
+	}
+	return parseModuleList(string(out)), nil
+}
+
+// parseModuleList returns the module names of the output of semodule -l, older
+// versions of which print the version of the module after its name.
+func parseModuleList(out string) []string {
+	var modules []string
+	for _, line := range strings.Split(out, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			modules = append(modules, fields[0])
+		}
+	}
+	return modules
+}
+
+// PolicyModuleCheck reports the required policy modules missing on the node,
+// so that operators can fix the policy before VMIs fail with denials. The
+// result is cached and refreshed once older than the interval of the check,
+// and exposed as the kubevirt_selinux_policy_module_missing metric.
+type PolicyModuleCheck struct {
+	enumerator PolicyModuleEnumerator
+	required   []string
+	interval   time.Duration
+	now        func() time.Time
+
+	lock      sync.Mutex
+	checkedAt time.Time
+	missing   []string
+}
+
+func NewPolicyModuleCheck(enumerator PolicyModuleEnumerator, required []string, interval time.Duration) *PolicyModuleCheck {
+	return &PolicyModuleCheck{
+		enumerator: enumerator,
+		required:   required,
+		interval:   interval,
+		now:        time.Now,
+	}
+}
+
+// MissingModules returns the sorted required modules which aren't loaded. A
+// failed enumeration is retried on the next call.
+func (c *PolicyModuleCheck) MissingModules() ([]string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.now()
+	if !c.checkedAt.IsZero() && now.Sub(c.checkedAt) < c.interval {
+		return append([]string{}, c.missing...), nil
+	}
+
+	loaded, err := c.enumerator.LoadedPolicyModules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to enumerate the selinux policy modules: %v", err)
+	}
+	loadedSet := map[string]bool{}
+	for _, module := range loaded {
+		loadedSet[module] = true
+	}
+	missing := []string{}
+	for _, module := range c.required {
+		setPolicyModuleMissing(module, !loadedSet[module])
+		if !loadedSet[module] {
+			missing = append(missing, module)
+		}
+	}
+	sort.Strings(missing)
+
+	logger := log.Logger(logComponent)
+	if len(missing) > 0 && (c.checkedAt.IsZero() || !equalModules(missing, c.missing)) {
+		logger.Errorf("the selinux policy modules %s required by the launchers are not loaded, VMIs will fail with selinux denials on this node", strings.Join(missing, ", "))
+	} else if len(missing) == 0 && len(c.missing) > 0 {
+		logger.Infof("the required selinux policy modules %s are loaded now", strings.Join(c.missing, ", "))
+	}
+	c.checkedAt = now
+	c.missing = missing
+	return append([]string{}, missing...), nil
+}
+
+func equalModules(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,168 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type fakeModuleEnumerator struct {
+	modules []string
+	err     error
+	calls   int
+}
+
+func (e *fakeModuleEnumerator) LoadedPolicyModules() ([]string, error) {
+	e.calls++
+	return e.modules, e.err
+}
+
+var _ = Describe("Policy module check", func() {
+	const nodeName = "testnode"
+
+	var registry *prometheus.Registry
+	var enumerator *fakeModuleEnumerator
+	var check *PolicyModuleCheck
+	var now time.Time
+
+	missingValue := func(module string) float64 {
+		families, err := registry.Gather()
+		Expect(err).ToNot(HaveOccurred())
+		for _, family := range families {
+			if family.GetName() != "kubevirt_selinux_policy_module_missing" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				if !hasNodeLabel(metric, nodeName) {
+					continue
+				}
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "module" && label.GetValue() == module {
+						return metric.GetGauge().GetValue()
+					}
+				}
+			}
+		}
+		return -1
+	}
+
+	BeforeEach(func() {
+		registry = prometheus.NewRegistry()
+		Expect(RegisterMetrics(registry, nodeName)).To(Succeed())
+		enumerator = &fakeModuleEnumerator{}
+		now = time.Now()
+		check = NewPolicyModuleCheck(enumerator, []string{"virt", "container"}, time.Minute)
+		check.now = func() time.Time { return now }
+	})
+
+	It("should report nothing missing when the required modules are loaded", func() {
+		enumerator.modules = []string{"base", "container", "virt", "virt_launcher"}
+		Expect(check.MissingModules()).To(BeEmpty())
+		Expect(missingValue("virt")).To(Equal(0.0))
+		Expect(missingValue("container")).To(Equal(0.0))
+	})
+
+	It("should report the sorted modules missing", func() {
+		enumerator.modules = []string{"base"}
+		Expect(check.MissingModules()).To(Equal([]string{"container", "virt"}))
+		Expect(missingValue("virt")).To(Equal(1.0))
+		Expect(missingValue("container")).To(Equal(1.0))
+	})
+
+	It("should cache the result until the interval elapsed", func() {
+		enumerator.modules = []string{"container"}
+		Expect(check.MissingModules()).To(Equal([]string{"virt"}))
+
+		enumerator.modules = []string{"container", "virt"}
+		now = now.Add(30 * time.Second)
+		Expect(check.MissingModules()).To(Equal([]string{"virt"}))
+		Expect(enumerator.calls).To(Equal(1))
+
+		now = now.Add(time.Minute)
+		Expect(check.MissingModules()).To(BeEmpty())
+		Expect(enumerator.calls).To(Equal(2))
+		Expect(missingValue("virt")).To(Equal(0.0))
+	})
+
+	It("should retry a failed enumeration on the next call", func() {
+		enumerator.err = fmt.Errorf("semodule failure")
+		_, err := check.MissingModules()
+		Expect(err).To(MatchError(ContainSubstring("semodule failure")))
+
+		enumerator.err = nil
+		enumerator.modules = []string{"virt"}
+		Expect(check.MissingModules()).To(Equal([]string{"container"}))
+		Expect(enumerator.calls).To(Equal(2))
+	})
+
+	Context("enumerating the modules with semodule", func() {
+		var tempDir string
+		var selinux *SELinuxImpl
+
+		BeforeEach(func() {
+			var err error
+			tempDir, err = ioutil.TempDir("", "kubevirt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.MkdirAll(filepath.Join(tempDir, "/usr/sbin"), 0777)).To(Succeed())
+			selinux = &SELinuxImpl{
+				Paths:         []string{"/usr/bin", "/usr/sbin"},
+				procOnePrefix: tempDir,
+			}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tempDir)
+		})
+
+		It("should list the names of the modules", func() {
+			touch(filepath.Join(tempDir, "/usr/sbin", "semodule"))
+			var executed []string
+			selinux.execFunc = func(binary string, args ...string) ([]byte, error) {
+				executed = append([]string{binary}, args...)
+				return []byte("container\t2.137.0\nvirt 1.5.0\n\nvirt_launcher\n"), nil
+			}
+			Expect(selinux.LoadedPolicyModules()).To(Equal([]string{"container", "virt", "virt_launcher"}))
+			Expect(executed).To(Equal([]string{"/usr/bin/virt-chroot", "--mount", "/proc/1/ns/mnt", "exec", "--", "/usr/sbin/semodule", "-l"}))
+		})
+
+		It("should fail if semodule fails", func() {
+			touch(filepath.Join(tempDir, "/usr/sbin", "semodule"))
+			selinux.execFunc = func(binary string, args ...string) ([]byte, error) {
+				return []byte("libsemanage.semanage_direct_connect: access denied"), fmt.Errorf("exit status 1")
+			}
+			_, err := selinux.LoadedPolicyModules()
+			Expect(err).To(MatchError(ContainSubstring("access denied")))
+		})
+
+		It("should fail if semodule does not exist, even when permissive", func() {
+			selinux.mode = "permissive"
+			_, err := selinux.LoadedPolicyModules()
+			Expect(err).To(MatchError("could not find 'semodule' binary"))
+		})
+	})
+})
@@ -0,0 +1,56 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// WithPostExecHook makes the executor call hook once the child finished, on
+// the OS thread still carrying the launcher label and namespaces, before the
+// virt-handler context is restored. This allows cleaning up after the child,
+// e.g. removing its temporary files, with the same permissions it had. Hooks
+// run in the order they were added, also if the child failed, and are skipped
+// in dry-run mode.
+func WithPostExecHook(hook func() error) Option {
+	return func(ce *ContextExecutor) {
+		ce.postExecHooks = append(ce.postExecHooks, hook)
+	}
+}
+
+// runPostExecHooks calls the post-exec hooks and aggregates their errors with
+// err, the result of the command. err is returned as is if all hooks succeed.
+func (ce ContextExecutor) runPostExecHooks(err error) error {
+	var hookErrs []error
+	for i, hook := range ce.postExecHooks {
+		if hookErr := hook(); hookErr != nil {
+			hookErrs = append(hookErrs, fmt.Errorf("post-exec hook %d failed in launcher pid %d context: %v", i, ce.pid, hookErr))
+		}
+	}
+	if len(hookErrs) == 0 {
+		return err
+	}
+	if err != nil {
+		hookErrs = append([]error{err}, hookErrs...)
+	}
+	return utilerrors.NewAggregate(hookErrs)
+}
@@ -0,0 +1,171 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/sys/unix"
+)
+
+var _ = Describe("Post-exec hooks", func() {
+
+	var events []string
+	var currentLabel string
+	var labelTID int
+
+	BeforeEach(func() {
+		events = nil
+		currentLabel = testOriginalLabel
+		defaultLabelManager = execLabelFunc(func(label string) error {
+			events = append(events, "label "+label)
+			currentLabel = label
+			labelTID = unix.Gettid()
+			return nil
+		})
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, true, nil
+		}
+		ResetSELinuxDetectionForTest()
+	})
+
+	AfterEach(func() {
+		defaultLabelManager = NewLabelManager()
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+	})
+
+	newExecutor := func(cmd *exec.Cmd, hooks ...func() error) ContextExecutor {
+		ce := ContextExecutor{pid: 1, cmdToExecute: cmd, desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+		for _, hook := range hooks {
+			WithPostExecHook(hook)(&ce)
+		}
+		return ce
+	}
+
+	// recordingCmd records its run in the events once it wrote its output
+	recordingCmd := func(args ...string) *exec.Cmd {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdout = writerFunc(func(p []byte) (int, error) {
+			events = append(events, "child")
+			return len(p), nil
+		})
+		return cmd
+	}
+
+	recordingHook := func(name string, err error) func() error {
+		return func() error {
+			events = append(events, "hook "+name)
+			return err
+		}
+	}
+
+	It("should run the hooks in order after the child and before resetting the context", func() {
+		ce := newExecutor(recordingCmd("echo", "running"), recordingHook("first", nil), recordingHook("second", nil))
+		Expect(ce.Execute()).To(Succeed())
+		Expect(events).To(Equal([]string{
+			"label " + testLauncherLabel,
+			"child",
+			"hook first",
+			"hook second",
+			"label " + testOriginalLabel,
+		}))
+	})
+
+	It("should run the hooks on the thread and with the label of the child", func() {
+		var hookLabel string
+		var hookTID, childTID int
+		cmd := exec.Command("echo", "running")
+		cmd.Stdout = writerFunc(func(p []byte) (int, error) {
+			childTID = labelTID
+			return len(p), nil
+		})
+		ce := newExecutor(cmd, func() error {
+			hookLabel = currentLabel
+			hookTID = unix.Gettid()
+			return nil
+		})
+		Expect(ce.Execute()).To(Succeed())
+		Expect(hookLabel).To(Equal(testLauncherLabel))
+		Expect(hookTID).To(Equal(childTID))
+	})
+
+	It("should surface the hook errors", func() {
+		ce := newExecutor(exec.Command("true"), recordingHook("first", fmt.Errorf("unlink failed")), recordingHook("second", nil))
+		err := ce.Execute()
+		Expect(err).To(MatchError(ContainSubstring("post-exec hook 0 failed in launcher pid 1 context: unlink failed")))
+		Expect(events).To(ContainElement("hook second"))
+		Expect(events[len(events)-1]).To(Equal("label " + testOriginalLabel))
+	})
+
+	It("should aggregate the hook errors with the command error", func() {
+		ce := newExecutor(exec.Command("false"), recordingHook("first", fmt.Errorf("unlink failed")), recordingHook("second", fmt.Errorf("relabel failed")))
+		err := ce.Execute()
+		Expect(err).To(MatchError(ContainSubstring("exit status 1")))
+		Expect(err).To(MatchError(ContainSubstring("unlink failed")))
+		Expect(err).To(MatchError(ContainSubstring("relabel failed")))
+	})
+
+	It("should keep the command error as is if the hooks succeed", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		ce := newExecutor(exec.Command("sleep", "10"), recordingHook("cleanup", nil))
+		Expect(ce.ExecuteContext(ctx)).To(Equal(context.DeadlineExceeded))
+		Expect(events).To(ContainElement("hook cleanup"))
+	})
+
+	It("should not run the hooks in dry-run mode", func() {
+		ce := newExecutor(exec.Command("true"), recordingHook("cleanup", nil))
+		ce.dryRun = true
+		Expect(ce.Execute()).To(Succeed())
+		Expect(events).To(BeEmpty())
+	})
+
+	It("should run the hooks once after all the commands of a batch", func() {
+		batch := BatchContextExecutor{
+			ContextExecutor: newExecutor(nil, recordingHook("cleanup", nil)),
+			cmdsToExecute:   []*exec.Cmd{recordingCmd("echo", "first"), recordingCmd("echo", "second")},
+		}
+		Expect(batch.Execute()).To(Succeed())
+		Expect(events).To(Equal([]string{
+			"label " + testLauncherLabel,
+			"child",
+			"child",
+			"hook cleanup",
+			"label " + testOriginalLabel,
+		}))
+	})
+
+	It("should run the hooks of a batch even if a command failed", func() {
+		batch := BatchContextExecutor{
+			ContextExecutor: newExecutor(nil, recordingHook("cleanup", fmt.Errorf("unlink failed"))),
+			cmdsToExecute:   []*exec.Cmd{exec.Command("false")},
+		}
+		err := batch.Execute()
+		Expect(err).To(MatchError(ContainSubstring("exit status 1")))
+		Expect(err).To(MatchError(ContainSubstring("unlink failed")))
+		Expect(events).To(ContainElement("hook cleanup"))
+	})
+})
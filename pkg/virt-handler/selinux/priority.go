@@ -0,0 +1,122 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	capSysAdmin = 21
+	capSysNice  = 23
+
+	minNice        = -20
+	maxNice        = 19
+	maxIOPrioLevel = 7
+
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+)
+
+// IOPrioClass is the I/O scheduling class of ioprio_set(2).
+type IOPrioClass int
+
+const (
+	// IOPrioClassNone leaves the I/O priority of the child as is.
+	IOPrioClassNone IOPrioClass = iota
+	// IOPrioClassRealtime is served before any other class.
+	IOPrioClassRealtime
+	// IOPrioClassBestEffort is the default class of processes.
+	IOPrioClassBestEffort
+	// IOPrioClassIdle is only served when no other class needs the disk.
+	IOPrioClassIdle
+)
+
+// IOPrio is the I/O scheduling class and the level within it, 0 being the
+// highest priority and 7 the lowest. The level is ignored by the idle class.
+type IOPrio struct {
+	Class IOPrioClass
+	Level int
+}
+
+type priority struct {
+	nice   int
+	ioprio IOPrio
+}
+
+// WithPriority runs the executed commands with the nice value and the I/O
+// priority, e.g. so that a disk conversion doesn't compete with qemu. Both
+// are applied to the OS thread forking the child, which inherits them with
+// all of its threads. That thread is destroyed afterwards, like the one of
+// WithCapabilities, which this composes with. A negative nice value requires
+// CAP_SYS_NICE, the realtime I/O class CAP_SYS_NICE or CAP_SYS_ADMIN.
+func WithPriority(nice int, ioprio IOPrio) Option {
+	return func(ce *ContextExecutor) {
+		ce.priority = &priority{nice: nice, ioprio: ioprio}
+	}
+}
+
+func (p *priority) validate() error {
+	if p.nice < minNice || p.nice > maxNice {
+		return fmt.Errorf("nice value %d is out of range [%d, %d]", p.nice, minNice, maxNice)
+	}
+	switch p.ioprio.Class {
+	case IOPrioClassNone, IOPrioClassRealtime, IOPrioClassBestEffort, IOPrioClassIdle:
+	default:
+		return fmt.Errorf("unknown I/O priority class %d", p.ioprio.Class)
+	}
+	if p.ioprio.Level < 0 || p.ioprio.Level > maxIOPrioLevel {
+		return fmt.Errorf("I/O priority level %d is out of range [0, %d]", p.ioprio.Level, maxIOPrioLevel)
+	}
+
+	caps, err := effectiveCapabilities()
+	if err != nil {
+		return fmt.Errorf("failed to check the privileges to change the priority of the command: %v", err)
+	}
+	if p.nice < 0 && caps&(1<<capSysNice) == 0 {
+		return fmt.Errorf("running commands with the negative nice value %d requires the CAP_SYS_NICE capability", p.nice)
+	}
+	if p.ioprio.Class == IOPrioClassRealtime && caps&(1<<capSysNice) == 0 && caps&(1<<capSysAdmin) == 0 {
+		return fmt.Errorf("running commands in the realtime I/O class requires the CAP_SYS_NICE or CAP_SYS_ADMIN capability")
+	}
+	return nil
+}
+
+// applyToThread sets the priority of the calling OS thread, which has to be
+// locked, since both the nice value and the I/O priority are per thread.
+func (p *priority) applyToThread() error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	tid := unix.Gettid()
+	if err := unix.Setpriority(unix.PRIO_PROCESS, tid, p.nice); err != nil {
+		return fmt.Errorf("failed to set the nice value %d: %v", p.nice, err)
+	}
+	if p.ioprio.Class == IOPrioClassNone {
+		return nil
+	}
+	value := int(p.ioprio.Class)<<ioprioClassShift | p.ioprio.Level
+	if _, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), uintptr(value)); errno != 0 {
+		return fmt.Errorf("failed to set the I/O priority %d of class %d: %v", p.ioprio.Level, p.ioprio.Class, errno)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"golang.org/x/sys/unix"
+)
+
+var _ = Describe("Running commands with a priority", func() {
+	var orgEffectiveCapabilities = effectiveCapabilities
+
+	// the nice value is the 19th field of the stat file, counted after the
+	// command name which may contain spaces
+	niceOf := func(stat string) int {
+		fields := strings.Fields(stat[strings.LastIndex(stat, ")")+1:])
+		nice, err := strconv.Atoi(fields[16])
+		Expect(err).ToNot(HaveOccurred())
+		return nice
+	}
+
+	childNice := func(options ...Option) int {
+		ce := &ContextExecutor{pid: 1, cmdToExecute: exec.Command("cat", "/proc/self/stat")}
+		for _, option := range options {
+			option(ce)
+		}
+		stdout, _, err := ce.ExecuteWithOutput()
+		Expect(err).ToNot(HaveOccurred())
+		return niceOf(stdout.String())
+	}
+
+	ownNice := func() int {
+		stat, err := ioutil.ReadFile("/proc/self/stat")
+		Expect(err).ToNot(HaveOccurred())
+		return niceOf(string(stat))
+	}
+
+	AfterEach(func() {
+		effectiveCapabilities = orgEffectiveCapabilities
+	})
+
+	It("should run the child with the nice value", func() {
+		Expect(childNice(WithPriority(10, IOPrio{}))).To(Equal(10))
+	})
+
+	It("should leave the nice value of virt-handler untouched", func() {
+		before := ownNice()
+		childNice(WithPriority(15, IOPrio{}))
+		Expect(ownNice()).To(Equal(before))
+	})
+
+	It("should not change the nice value by default", func() {
+		Expect(childNice()).To(Equal(ownNice()))
+	})
+
+	It("should compose with the credentials", func() {
+		caps, err := readEffectiveCapabilities()
+		Expect(err).ToNot(HaveOccurred())
+		if caps&(1<<capSetUID) == 0 || caps&(1<<capSetGID) == 0 {
+			Skip("switching credentials requires CAP_SETUID and CAP_SETGID")
+		}
+		ce := &ContextExecutor{pid: 1, cmdToExecute: exec.Command("sh", "-c", "id -u; cat /proc/self/stat")}
+		WithCredentials(107, 107, nil)(ce)
+		WithPriority(5, IOPrio{})(ce)
+
+		stdout, _, err := ce.ExecuteWithOutput()
+		Expect(err).ToNot(HaveOccurred())
+		lines := strings.SplitN(stdout.String(), "\n", 2)
+		Expect(lines[0]).To(Equal("107"))
+		Expect(niceOf(lines[1])).To(Equal(5))
+	})
+
+	It("should set the I/O priority of the thread forking the child", func() {
+		p := &priority{nice: 0, ioprio: IOPrio{Class: IOPrioClassBestEffort, Level: 6}}
+
+		values := make(chan uintptr, 1)
+		go func() {
+			// the thread is left locked, so that it is destroyed
+			runtime.LockOSThread()
+			defer GinkgoRecover()
+			Expect(p.applyToThread()).To(Succeed())
+			value, _, errno := unix.Syscall(unix.SYS_IOPRIO_GET, ioprioWhoProcess, uintptr(unix.Gettid()), 0)
+			Expect(errno).To(BeZero())
+			values <- value
+		}()
+		Expect(<-values).To(Equal(uintptr(IOPrioClassBestEffort)<<ioprioClassShift | 6))
+	})
+
+	table.DescribeTable("should reject", func(nice int, ioprio IOPrio, message string) {
+		ce := &ContextExecutor{pid: 1, cmdToExecute: exec.Command("true")}
+		WithPriority(nice, ioprio)(ce)
+		Expect(ce.Execute()).To(MatchError(message))
+	},
+		table.Entry("a nice value below the range", -21, IOPrio{}, "nice value -21 is out of range [-20, 19]"),
+		table.Entry("a nice value above the range", 20, IOPrio{}, "nice value 20 is out of range [-20, 19]"),
+		table.Entry("an unknown I/O class", 0, IOPrio{Class: 4}, "unknown I/O priority class 4"),
+		table.Entry("an I/O level above the range", 0, IOPrio{Class: IOPrioClassBestEffort, Level: 8}, "I/O priority level 8 is out of range [0, 7]"),
+		table.Entry("a negative I/O level", 0, IOPrio{Class: IOPrioClassBestEffort, Level: -1}, "I/O priority level -1 is out of range [0, 7]"),
+	)
+
+	Context("without privileges", func() {
+		BeforeEach(func() {
+			effectiveCapabilities = func() (uint64, error) {
+				return 0, nil
+			}
+		})
+
+		It("should refuse a negative nice value", func() {
+			ce := &ContextExecutor{pid: 1, cmdToExecute: exec.Command("true")}
+			WithPriority(-5, IOPrio{})(ce)
+			Expect(ce.Execute()).To(MatchError("running commands with the negative nice value -5 requires the CAP_SYS_NICE capability"))
+		})
+
+		It("should refuse the realtime I/O class", func() {
+			ce := &ContextExecutor{pid: 1, cmdToExecute: exec.Command("true")}
+			WithPriority(0, IOPrio{Class: IOPrioClassRealtime})(ce)
+			Expect(ce.Execute()).To(MatchError("running commands in the realtime I/O class requires the CAP_SYS_NICE or CAP_SYS_ADMIN capability"))
+		})
+
+		It("should allow lowering the priority", func() {
+			Expect(childNice(WithPriority(10, IOPrio{Class: IOPrioClassIdle}))).To(Equal(10))
+		})
+	})
+})
@@ -0,0 +1,69 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+)
+
+// ProcessesWithLabel returns the pids of the processes currently running with
+// label, e.g. to find the processes left behind by a launcher. The processes
+// exiting while /proc is scanned are skipped.
+func ProcessesWithLabel(label string) ([]int, error) {
+	return processesWithLabelWith(defaultLabelManager, label)
+}
+
+func processesWithLabelWith(manager LabelManager, label string) ([]int, error) {
+	if label == "" {
+		return nil, fmt.Errorf("no selinux label to look up the processes of")
+	}
+	if err := validateLabel(label); err != nil {
+		return nil, err
+	}
+	entries, err := ioutil.ReadDir(procRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the processes: %v", err)
+	}
+	var pids []int
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid <= 0 || !entry.IsDir() {
+			continue
+		}
+		current, err := readAttrLabelForPIDWith(manager, pid, LabelAttrCurrent)
+		if err != nil {
+			var labelErr *LabelError
+			if errors.As(err, &labelErr) && labelErr.Kind == PIDNotFound {
+				// the process exited since /proc was listed
+				continue
+			}
+			return nil, err
+		}
+		if current == label {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+	return pids, nil
+}
@@ -0,0 +1,112 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+// scanLabelManager makes the process exitingPID exit right before its label
+// is read, and fails reading the label of unreadablePID.
+type scanLabelManager struct {
+	*testutils.FakeLabelManager
+	exitingPID    int
+	unreadablePID int
+}
+
+func (m scanLabelManager) FileLabel(path string) (string, error) {
+	switch path {
+	case fmt.Sprintf("/proc/%d/attr/current", m.exitingPID):
+		if err := os.RemoveAll(filepath.Join(procRoot, strconv.Itoa(m.exitingPID))); err != nil {
+			return "", err
+		}
+		return "", &os.PathError{Op: "lgetxattr", Path: path, Err: syscall.ENOENT}
+	case fmt.Sprintf("/proc/%d/attr/current", m.unreadablePID):
+		return "", &os.PathError{Op: "lgetxattr", Path: path, Err: syscall.EACCES}
+	}
+	return m.FakeLabelManager.FileLabel(path)
+}
+
+var _ = Describe("Processes with label", func() {
+	const otherLauncherLabel = "system_u:system_r:container_t:s0:c3,c4"
+
+	var manager *testutils.FakeLabelManager
+	var restoreProcRoot func()
+
+	BeforeEach(func() {
+		restoreProcRoot = fakeProcRoot(1, 12, 123, 1234, 2000)
+		Expect(ioutil.WriteFile(filepath.Join(procRoot, "uptime"), nil, 0644)).To(Succeed())
+		Expect(os.Mkdir(filepath.Join(procRoot, "sys"), 0755)).To(Succeed())
+		manager = testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(1, testOriginalLabel)
+		manager.SetProcessLabel(12, testLauncherLabel)
+		manager.SetProcessLabel(123, otherLauncherLabel)
+		manager.SetProcessLabel(1234, testLauncherLabel)
+		manager.SetProcessLabel(2000, testLauncherLabel)
+	})
+
+	AfterEach(func() {
+		restoreProcRoot()
+	})
+
+	It("should return the pids of the processes running with the label", func() {
+		pids, err := processesWithLabelWith(manager, testLauncherLabel)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pids).To(Equal([]int{12, 1234, 2000}))
+	})
+
+	It("should return no pid if no process runs with the label", func() {
+		pids, err := processesWithLabelWith(manager, "system_u:system_r:container_t:s0:c5,c6")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pids).To(BeEmpty())
+	})
+
+	It("should skip the processes exiting during the scan", func() {
+		pids, err := processesWithLabelWith(scanLabelManager{FakeLabelManager: manager, exitingPID: 1234}, testLauncherLabel)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pids).To(Equal([]int{12, 2000}))
+	})
+
+	It("should fail if the label of a process can't be read", func() {
+		_, err := processesWithLabelWith(scanLabelManager{FakeLabelManager: manager, unreadablePID: 123}, testLauncherLabel)
+		var labelErr *LabelError
+		Expect(errors.As(err, &labelErr)).To(BeTrue())
+		Expect(labelErr.PID).To(Equal(123))
+		Expect(labelErr.Kind).To(Equal(ProcNotReadable))
+	})
+
+	It("should reject an empty or malformed label", func() {
+		_, err := processesWithLabelWith(manager, "")
+		Expect(err).To(HaveOccurred())
+		_, err = processesWithLabelWith(manager, "container_t")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,301 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+type pathLabel struct {
+	path  string
+	label string
+}
+
+// fileLabelManager is the part of LabelManager safe to use from any thread:
+// unlike the exec label, file labels are attributes of the inodes, not of the
+// calling thread.
+type fileLabelManager interface {
+	FileLabel(path string) (string, error)
+	SetFileLabel(path string, label string) error
+}
+
+// relabelResult is the outcome of the relabel of a single path.
+type relabelResult struct {
+	// previousLabel is the label the path had before the relabel
+	previousLabel string
+	relabeled     bool
+	// skipped is set if the path matched the relabel skip paths, or lives on
+	// a filesystem skipped by WithSkipUnrelabelableFilesystems
+	skipped bool
+	err     error
+}
+
+// WithRelabelParallelism makes the executor relabel up to workers paths at
+// once. The paths are relabeled one after the other if workers is below 2.
+func WithRelabelParallelism(workers int) Option {
+	return func(ce *ContextExecutor) {
+		ce.relabelWorkers = workers
+	}
+}
+
+// WithRelabelSkipPaths makes the executor leave the paths matching any of
+// skipPaths untouched, e.g. tmpfs backed or already labeled devices. Entries
+// containing glob characters are matched with filepath.Match, the others are
+// prefixes matching the path itself and everything below it.
+func WithRelabelSkipPaths(skipPaths []string) Option {
+	return func(ce *ContextExecutor) {
+		ce.relabelSkipPaths = append([]string{}, skipPaths...)
+	}
+}
+
+// skipsRelabel returns whether path matches the relabel skip paths.
+func (ce ContextExecutor) skipsRelabel(path string) bool {
+	path = filepath.Clean(path)
+	for _, skipPath := range ce.relabelSkipPaths {
+		if strings.ContainsAny(skipPath, "*?[") {
+			if matched, _ := filepath.Match(skipPath, path); matched {
+				return true
+			}
+			continue
+		}
+		prefix := filepath.Clean(skipPath)
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// skipRelabel returns the result of a skipped path.
+func (ce ContextExecutor) skipRelabel(path string) relabelResult {
+	ce.getLogger().V(debugVerbosity).Infof("not relabeling %s matching the relabel skip paths", path)
+	return relabelResult{skipped: true}
+}
+
+// forEachPath calls relabel for each of the count paths, with at most
+// relabelWorkers calls running at once, and returns the results by path index.
+// The workers only get the file label operations, so that none of them can
+// change the exec label of the thread it happens to run on.
+func (ce ContextExecutor) forEachPath(count int, relabel func(manager fileLabelManager, i int) relabelResult) []relabelResult {
+	var manager fileLabelManager = ce.getLabelManager()
+	results := make([]relabelResult, count)
+	workers := ce.relabelWorkers
+	if workers > count {
+		workers = count
+	}
+	if workers < 2 {
+		for i := range results {
+			results[i] = relabel(manager, i)
+		}
+		return results
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				results[i] = relabel(manager, i)
+			}
+		}()
+	}
+	for i := range results {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	return results
+}
+
+// The file context the files of the launchers are labeled with, the one of
+// the files of unprivileged containers.
+const (
+	launcherFileUser = "system_u"
+	launcherFileRole = "object_r"
+	launcherFileType = "container_file_t"
+)
+
+// launcherFileLabel returns the file label matching the process label of a
+// launcher: the container_file_t file context, with the level of the launcher
+// so that only the launcher with its categories gets access. A process type
+// can't be applied to files, the policy denies associating it with a
+// filesystem.
+func launcherFileLabel(processLabel string) (string, error) {
+	parsed, err := ParseLabel(processLabel)
+	if err != nil || parsed == (Label{}) {
+		return "", err
+	}
+	parsed.User, parsed.Role, parsed.Type = launcherFileUser, launcherFileRole, launcherFileType
+	return parsed.String(), nil
+}
+
+// getFileLabel returns the label applied to relabeled files, derived from the
+// launcher label, or from the label shared with other launchers if
+// WithSharedMCS was requested.
+func (ce ContextExecutor) getFileLabel() string {
+	processLabel := ce.fileLabel
+	if processLabel == "" {
+		processLabel = ce.desiredLabel
+	}
+	// the labels are validated when the executor is created
+	label, _ := launcherFileLabel(processLabel)
+	return label
+}
+
+// RelabelFiles applies the file label of the launcher to each of the given
+// paths. The returned function puts back the labels the paths had before the
+// change; paths which failed to be relabeled are left untouched by it.
+func (ce ContextExecutor) RelabelFiles(paths ...string) (restore func() error, err error) {
+	desiredLabel := ce.getFileLabel()
+	results := ce.forEachPath(len(paths), func(manager fileLabelManager, i int) relabelResult {
+		path := paths[i]
+		if ce.skipsRelabel(path) {
+			return ce.skipRelabel(path)
+		}
+		if result := ce.guardFilesystem(path); result != nil {
+			return *result
+		}
+		previousLabel, err := manager.FileLabel(path)
+		if err != nil {
+			return relabelResult{err: fmt.Errorf("failed to retrieve the selinux label of %s: %v", path, err)}
+		}
+		if err := manager.SetFileLabel(path, desiredLabel); err != nil {
+			return relabelResult{err: fmt.Errorf("failed to relabel %s to %s: %v", path, desiredLabel, err)}
+		}
+		ce.getLogger().V(debugVerbosity).Infof("relabeled %s from %s to %s", path, previousLabel, desiredLabel)
+		return relabelResult{previousLabel: previousLabel, relabeled: true}
+	})
+
+	var previousLabels []pathLabel
+	for i, result := range results {
+		if result.relabeled {
+			previousLabels = append(previousLabels, pathLabel{path: paths[i], label: result.previousLabel})
+		}
+	}
+	return func() error {
+		return ce.restoreFileLabels(previousLabels)
+	}, aggregateRelabelErrors(results)
+}
+
+func (ce ContextExecutor) restoreFileLabels(labels []pathLabel) error {
+	results := ce.forEachPath(len(labels), func(manager fileLabelManager, i int) relabelResult {
+		fl := labels[i]
+		if err := manager.SetFileLabel(fl.path, fl.label); err != nil {
+			return relabelResult{err: fmt.Errorf("failed to restore the selinux label of %s to %s: %v", fl.path, fl.label, err)}
+		}
+		ce.getLogger().V(debugVerbosity).Infof("restored the label of %s to %s", fl.path, fl.label)
+		return relabelResult{relabeled: true}
+	})
+	return aggregateRelabelErrors(results)
+}
+
+// EnsureFilesLabeled relabels the paths not carrying the launcher label, e.g.
+// device nodes reset by udev, and returns the ones which had drifted.
+func (ce ContextExecutor) EnsureFilesLabeled(paths ...string) (relabeled []string, err error) {
+	_, results := ce.ensureFilesLabeled(paths)
+	for i, result := range results {
+		if result.relabeled {
+			relabeled = append(relabeled, paths[i])
+		}
+	}
+	return relabeled, aggregateRelabelErrors(results)
+}
+
+// FileRelabel is the outcome of the relabel of a single path by
+// EnsureFilesLabeledWithResults.
+type FileRelabel struct {
+	Path string `json:"path"`
+	// PreviousLabel is the label the path had before the relabel
+	PreviousLabel string `json:"previousLabel,omitempty"`
+	Label         string `json:"label,omitempty"`
+	Relabeled     bool   `json:"relabeled"`
+	// Skipped is set if the path matched the relabel skip paths, or lives on
+	// a filesystem skipped by WithSkipUnrelabelableFilesystems
+	Skipped bool   `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// EnsureFilesLabeledWithResults relabels the paths like EnsureFilesLabeled,
+// reporting the failures of each path in its result instead of returning them.
+func (ce ContextExecutor) EnsureFilesLabeledWithResults(paths ...string) []FileRelabel {
+	desiredLabel, results := ce.ensureFilesLabeled(paths)
+	fileRelabels := make([]FileRelabel, 0, len(results))
+	for i, result := range results {
+		fileRelabel := FileRelabel{
+			Path:          paths[i],
+			PreviousLabel: result.previousLabel,
+			Label:         desiredLabel,
+			Relabeled:     result.relabeled,
+			Skipped:       result.skipped,
+		}
+		if result.err != nil {
+			fileRelabel.Error = result.err.Error()
+		}
+		fileRelabels = append(fileRelabels, fileRelabel)
+	}
+	return fileRelabels
+}
+
+func (ce ContextExecutor) ensureFilesLabeled(paths []string) (desiredLabel string, results []relabelResult) {
+	desiredLabel = ce.getFileLabel()
+	if desiredLabel == "" {
+		return "", nil
+	}
+	results = ce.forEachPath(len(paths), func(manager fileLabelManager, i int) relabelResult {
+		path := paths[i]
+		if ce.skipsRelabel(path) {
+			return ce.skipRelabel(path)
+		}
+		if result := ce.guardFilesystem(path); result != nil {
+			return *result
+		}
+		currentLabel, err := manager.FileLabel(path)
+		if err != nil {
+			return relabelResult{err: fmt.Errorf("failed to retrieve the selinux label of %s: %v", path, err)}
+		}
+		if currentLabel == desiredLabel {
+			return relabelResult{previousLabel: currentLabel}
+		}
+		if err := manager.SetFileLabel(path, desiredLabel); err != nil {
+			return relabelResult{previousLabel: currentLabel, err: fmt.Errorf("failed to relabel %s to %s: %v", path, desiredLabel, err)}
+		}
+		ce.getLogger().V(debugVerbosity).Infof("relabeled drifted %s from %s to %s", path, currentLabel, desiredLabel)
+		return relabelResult{previousLabel: currentLabel, relabeled: true}
+	})
+	return desiredLabel, results
+}
+
+// aggregateRelabelErrors returns the errors of the results, in path order.
+func aggregateRelabelErrors(results []relabelResult) error {
+	var errs []error
+	for _, result := range results {
+		if result.err != nil {
+			errs = append(errs, result.err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,367 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/selinux/go-selinux"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+const (
+	testLauncherLabel = "system_u:system_r:container_t:s0:c1,c2"
+	// the file label RelabelFiles derives from testLauncherLabel
+	testLauncherFileLabel = "system_u:object_r:container_file_t:s0:c1,c2"
+	testOriginalLabel     = "system_u:object_r:tmp_t:s0"
+)
+
+var _ = Describe("Relabeling files", func() {
+
+	var tempDir string
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "kubevirt-relabel")
+		Expect(err).ToNot(HaveOccurred())
+		skipIfLabelsCantBeStored(tempDir)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("should apply the launcher label and restore the previous one", func() {
+		file := filepath.Join(tempDir, "disk.img")
+		touch(file)
+		Expect(selinux.SetFileLabel(file, testOriginalLabel)).To(Succeed())
+
+		ce := ContextExecutor{desiredLabel: testLauncherLabel}
+		restore, err := ce.RelabelFiles(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(selinux.FileLabel(file)).To(Equal(testLauncherFileLabel))
+
+		Expect(restore()).To(Succeed())
+		Expect(selinux.FileLabel(file)).To(Equal(testOriginalLabel))
+	})
+
+	It("should report every path that failed and still relabel the others", func() {
+		file := filepath.Join(tempDir, "disk.img")
+		touch(file)
+		Expect(selinux.SetFileLabel(file, testOriginalLabel)).To(Succeed())
+
+		ce := ContextExecutor{desiredLabel: testLauncherLabel}
+		restore, err := ce.RelabelFiles(filepath.Join(tempDir, "missing1"), file, filepath.Join(tempDir, "missing2"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("missing1"))
+		Expect(err.Error()).To(ContainSubstring("missing2"))
+		Expect(selinux.FileLabel(file)).To(Equal(testLauncherFileLabel))
+
+		Expect(restore()).To(Succeed())
+		Expect(selinux.FileLabel(file)).To(Equal(testOriginalLabel))
+	})
+})
+
+var _ = Describe("Ensuring files are labeled", func() {
+
+	var tempDir string
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "kubevirt-relabel")
+		Expect(err).ToNot(HaveOccurred())
+		skipIfLabelsCantBeStored(tempDir)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("should only relabel the drifted paths", func() {
+		drifted := filepath.Join(tempDir, "drifted")
+		labeled := filepath.Join(tempDir, "labeled")
+		touch(drifted)
+		touch(labeled)
+		Expect(selinux.SetFileLabel(drifted, testOriginalLabel)).To(Succeed())
+		Expect(selinux.SetFileLabel(labeled, testLauncherFileLabel)).To(Succeed())
+
+		ce := ContextExecutor{desiredLabel: testLauncherLabel}
+		relabeled, err := ce.EnsureFilesLabeled(drifted, labeled)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(relabeled).To(ConsistOf(drifted))
+		Expect(selinux.FileLabel(drifted)).To(Equal(testLauncherFileLabel))
+
+		relabeled, err = ce.EnsureFilesLabeled(drifted, labeled)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(relabeled).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Relabeling files in parallel", func() {
+
+	var manager *concurrencyTrackingLabelManager
+
+	newExecutor := func(workers int) ContextExecutor {
+		ce := ContextExecutor{desiredLabel: testLauncherLabel}
+		WithLabelManager(manager)(&ce)
+		WithRelabelParallelism(workers)(&ce)
+		return ce
+	}
+
+	paths := func(count int) []string {
+		var paths []string
+		for i := 0; i < count; i++ {
+			path := fmt.Sprintf("/dev/hotplug%d", i)
+			manager.labels[path] = testOriginalLabel
+			paths = append(paths, path)
+		}
+		return paths
+	}
+
+	// expectBoundedConcurrency checks that the paths were relabeled in parallel
+	// without exceeding the bound
+	expectBoundedConcurrency := func(bound int) {
+		Expect(manager.maxInFlight).To(BeNumerically("<=", bound))
+		if bound > 1 {
+			Expect(manager.maxInFlight).To(BeNumerically(">", 1))
+		}
+	}
+
+	BeforeEach(func() {
+		manager = &concurrencyTrackingLabelManager{
+			labels:  map[string]string{},
+			failing: map[string]bool{},
+			delay:   10 * time.Millisecond,
+		}
+	})
+
+	table.DescribeTable("should never relabel more paths at once than workers", func(workers, count, expectedMaxInFlight int) {
+		files := paths(count)
+		ce := newExecutor(workers)
+		restore, err := ce.RelabelFiles(files...)
+		Expect(err).ToNot(HaveOccurred())
+		expectBoundedConcurrency(expectedMaxInFlight)
+		for _, path := range files {
+			Expect(manager.labels[path]).To(Equal(testLauncherFileLabel))
+		}
+
+		manager.maxInFlight = 0
+		Expect(restore()).To(Succeed())
+		expectBoundedConcurrency(expectedMaxInFlight)
+		for _, path := range files {
+			Expect(manager.labels[path]).To(Equal(testOriginalLabel))
+		}
+	},
+		table.Entry("one after the other without parallelism", 0, 5, 1),
+		table.Entry("one after the other with a single worker", 1, 5, 1),
+		table.Entry("with fewer workers than paths", 3, 9, 3),
+		table.Entry("with more workers than paths", 8, 2, 2),
+	)
+
+	It("should report every failed path in order and only restore the relabeled ones", func() {
+		files := paths(6)
+		manager.failing[files[1]] = true
+		manager.failing[files[4]] = true
+		ce := newExecutor(3)
+
+		restore, err := ce.RelabelFiles(files...)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(utilerrors.Aggregate).Errors()).To(HaveLen(2))
+		Expect(err.(utilerrors.Aggregate).Errors()[0]).To(MatchError(ContainSubstring(files[1])))
+		Expect(err.(utilerrors.Aggregate).Errors()[1]).To(MatchError(ContainSubstring(files[4])))
+		Expect(manager.labels[files[0]]).To(Equal(testLauncherFileLabel))
+		Expect(manager.labels[files[5]]).To(Equal(testLauncherFileLabel))
+
+		delete(manager.failing, files[1])
+		delete(manager.failing, files[4])
+		manager.setCalls = nil
+		Expect(restore()).To(Succeed())
+		Expect(manager.setCalls).To(ConsistOf(files[0], files[2], files[3], files[5]))
+	})
+
+	It("should return the drifted paths in order", func() {
+		files := paths(6)
+		manager.labels[files[0]] = testLauncherFileLabel
+		manager.labels[files[3]] = testLauncherFileLabel
+		manager.failing[files[5]] = true
+		ce := newExecutor(4)
+
+		relabeled, err := ce.EnsureFilesLabeled(files...)
+		Expect(err).To(MatchError(ContainSubstring(files[5])))
+		Expect(relabeled).To(Equal([]string{files[1], files[2], files[4]}))
+		Expect(manager.maxInFlight).To(BeNumerically("<=", 4))
+	})
+
+	It("should not touch the exec label from the workers", func() {
+		ce := newExecutor(4)
+		_, err := ce.RelabelFiles(paths(8)...)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manager.execLabelCalls).To(BeZero())
+	})
+})
+
+// concurrencyTrackingLabelManager records how many file labels are set at
+// once, each taking delay.
+type concurrencyTrackingLabelManager struct {
+	lock           sync.Mutex
+	labels         map[string]string
+	failing        map[string]bool
+	delay          time.Duration
+	inFlight       int
+	maxInFlight    int
+	setCalls       []string
+	execLabelCalls int
+}
+
+func (m *concurrencyTrackingLabelManager) FileLabel(path string) (string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	label, exists := m.labels[path]
+	if !exists {
+		return "", fmt.Errorf("no such file %s", path)
+	}
+	return label, nil
+}
+
+func (m *concurrencyTrackingLabelManager) SetFileLabel(path string, label string) error {
+	m.lock.Lock()
+	m.inFlight++
+	if m.inFlight > m.maxInFlight {
+		m.maxInFlight = m.inFlight
+	}
+	m.lock.Unlock()
+
+	time.Sleep(m.delay)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.inFlight--
+	m.setCalls = append(m.setCalls, path)
+	if m.failing[path] {
+		return fmt.Errorf("failed to set the label of %s", path)
+	}
+	m.labels[path] = label
+	return nil
+}
+
+func (m *concurrencyTrackingLabelManager) SetExecLabel(label string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.execLabelCalls++
+	return nil
+}
+
+// skipIfLabelsCantBeStored skips the current test when the filesystem backing
+// dir can't hold selinux labels, e.g. when not built with the selinux tag.
+func skipIfLabelsCantBeStored(dir string) {
+	probe := filepath.Join(dir, "probe")
+	touch(probe)
+	defer os.Remove(probe)
+	if err := selinux.SetFileLabel(probe, testOriginalLabel); err != nil {
+		Skip("selinux labels can't be stored: " + err.Error())
+	}
+	if label, err := selinux.FileLabel(probe); err != nil || label != testOriginalLabel {
+		Skip("selinux labels can't be stored")
+	}
+}
+
+var _ = Describe("Relabeling with skip paths", func() {
+	const (
+		shmFile   = "/dev/shm/vhostuser"
+		shmxFile  = "/dev/shmx"
+		vfioFile  = "/dev/vfio/42"
+		diskImage = "/var/run/kubevirt/hotplug-disks/disk.img"
+	)
+
+	var manager *testutils.FakeLabelManager
+	var ce ContextExecutor
+
+	BeforeEach(func() {
+		manager = testutils.NewFakeLabelManager()
+		for _, path := range []string{shmFile, shmxFile, vfioFile, diskImage} {
+			Expect(manager.SetFileLabel(path, testOriginalLabel)).To(Succeed())
+		}
+		ce = ContextExecutor{desiredLabel: testLauncherLabel}
+		WithLabelManager(manager)(&ce)
+		WithRelabelSkipPaths([]string{"/dev/shm/", "/dev/vfio/[0-9]*"})(&ce)
+	})
+
+	expectLabels := func(relabeled ...string) {
+		for _, path := range []string{shmFile, shmxFile, vfioFile, diskImage} {
+			expected := testOriginalLabel
+			for _, r := range relabeled {
+				if r == path {
+					expected = testLauncherFileLabel
+				}
+			}
+			label, err := manager.FileLabel(path)
+			ExpectWithOffset(1, err).ToNot(HaveOccurred())
+			ExpectWithOffset(1, label).To(Equal(expected), path)
+		}
+	}
+
+	It("should only relabel the paths not matching a prefix or glob", func() {
+		_, err := ce.RelabelFiles(shmFile, shmxFile, vfioFile, diskImage)
+		Expect(err).ToNot(HaveOccurred())
+		expectLabels(shmxFile, diskImage)
+	})
+
+	It("should report the skipped paths when ensuring the labels", func() {
+		results := ce.EnsureFilesLabeledWithResults(shmFile, diskImage)
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].Skipped).To(BeTrue())
+		Expect(results[0].Relabeled).To(BeFalse())
+		Expect(results[1].Skipped).To(BeFalse())
+		Expect(results[1].Relabeled).To(BeTrue())
+		expectLabels(diskImage)
+	})
+
+	It("should relabel everything without skip paths", func() {
+		WithRelabelSkipPaths(nil)(&ce)
+		relabeled, err := ce.EnsureFilesLabeled(shmFile, shmxFile, vfioFile, diskImage)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(relabeled).To(HaveLen(4))
+		expectLabels(shmFile, shmxFile, vfioFile, diskImage)
+	})
+
+	It("should not relabel the skipped paths of a tree", func() {
+		tempDir, err := ioutil.TempDir("", "kubevirt-relabel-skip")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tempDir)
+		touch(filepath.Join(tempDir, "disk.iso"))
+		touch(filepath.Join(tempDir, "meta-data"))
+		WithRelabelSkipPaths([]string{filepath.Join(tempDir, "*.iso")})(&ce)
+
+		Expect(ce.RelabelTree(tempDir)).To(Succeed())
+		Expect(manager.FileLabel(filepath.Join(tempDir, "meta-data"))).To(Equal(testLauncherFileLabel))
+		_, err = manager.FileLabel(filepath.Join(tempDir, "disk.iso"))
+		Expect(err).To(HaveOccurred())
+	})
+})
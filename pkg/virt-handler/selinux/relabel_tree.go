@@ -0,0 +1,92 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// RelabelTree applies the launcher label to root and all the files and
+// directories below it, e.g. the tree backing a config or ISO disk. Symlinks
+// are neither relabeled nor followed, so that the walk never leaves root, nor
+// relabels the target of a link pointing out of it. Failures don't stop the
+// walk, they are returned aggregated, the ones of the walk first. The
+// filesystems are checked once per device: the entries on one which can't be
+// relabeled are all left untouched, with a single error for the first of them.
+func (ce ContextExecutor) RelabelTree(root string) error {
+	var paths []string
+	var devices []uint64
+	var walkErrs []relabelResult
+	// filepath.Walk lstats the entries, links to directories are not descended into
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			walkErrs = append(walkErrs, relabelResult{err: fmt.Errorf("failed to walk %s: %v", path, err)})
+			return nil
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			ce.getLogger().V(debugVerbosity).Infof("not relabeling symlink %s below %s", path, root)
+			return nil
+		}
+		paths = append(paths, path)
+		devices = append(devices, deviceOf(info))
+		return nil
+	})
+
+	// the checks run before the workers, in walk order, so that the error is
+	// always reported for the first entry of a device
+	guards := map[uint64]*relabelResult{}
+	firstOfDevice := make([]bool, len(paths))
+	for i, path := range paths {
+		if _, checked := guards[devices[i]]; !checked {
+			guards[devices[i]] = ce.guardFilesystem(path)
+			firstOfDevice[i] = true
+		}
+	}
+
+	desiredLabel := ce.getFileLabel()
+	results := ce.forEachPath(len(paths), func(manager fileLabelManager, i int) relabelResult {
+		if ce.skipsRelabel(paths[i]) {
+			return ce.skipRelabel(paths[i])
+		}
+		if guard := guards[devices[i]]; guard != nil {
+			if firstOfDevice[i] || guard.skipped {
+				return *guard
+			}
+			return relabelResult{}
+		}
+		if err := manager.SetFileLabel(paths[i], desiredLabel); err != nil {
+			return relabelResult{err: fmt.Errorf("failed to relabel %s to %s: %v", paths[i], desiredLabel, err)}
+		}
+		return relabelResult{relabeled: true}
+	})
+	ce.getLogger().V(debugVerbosity).Infof("relabeled the tree below %s to %s", root, desiredLabel)
+	return aggregateRelabelErrors(append(walkErrs, results...))
+}
+
+// deviceOf returns the device the entry lives on, as reported by lstat.
+func deviceOf(info os.FileInfo) uint64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(st.Dev)
+	}
+	return 0
+}
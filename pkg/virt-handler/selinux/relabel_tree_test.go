@@ -0,0 +1,120 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+var _ = Describe("Relabeling a directory tree", func() {
+
+	var tempDir string
+	var outsideDir string
+	var manager *failingFileLabelManager
+	var ce ContextExecutor
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "kubevirt-relabel-tree")
+		Expect(err).ToNot(HaveOccurred())
+		outsideDir, err = ioutil.TempDir("", "kubevirt-relabel-outside")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(tempDir, "openstack", "latest"), 0755)).To(Succeed())
+		touch(filepath.Join(tempDir, "openstack", "latest", "meta_data.json"))
+		touch(filepath.Join(tempDir, "openstack", "latest", "user_data"))
+		touch(filepath.Join(outsideDir, "secret"))
+		Expect(os.Symlink(outsideDir, filepath.Join(tempDir, "openstack", "escape"))).To(Succeed())
+		Expect(os.Symlink(filepath.Join(outsideDir, "secret"), filepath.Join(tempDir, "secret"))).To(Succeed())
+
+		manager = &failingFileLabelManager{FakeLabelManager: testutils.NewFakeLabelManager(), failing: map[string]bool{}}
+		ce = ContextExecutor{desiredLabel: testLauncherLabel, labelManager: manager}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+		os.RemoveAll(outsideDir)
+	})
+
+	It("should relabel every directory and file of the tree", func() {
+		Expect(ce.RelabelTree(tempDir)).To(Succeed())
+		for _, path := range []string{
+			tempDir,
+			filepath.Join(tempDir, "openstack"),
+			filepath.Join(tempDir, "openstack", "latest"),
+			filepath.Join(tempDir, "openstack", "latest", "meta_data.json"),
+			filepath.Join(tempDir, "openstack", "latest", "user_data"),
+		} {
+			Expect(manager.FileLabel(path)).To(Equal(testLauncherFileLabel), path)
+		}
+	})
+
+	It("should neither relabel nor follow symlinks", func() {
+		Expect(ce.RelabelTree(tempDir)).To(Succeed())
+		for _, path := range []string{
+			filepath.Join(tempDir, "openstack", "escape"),
+			filepath.Join(tempDir, "secret"),
+			outsideDir,
+			filepath.Join(outsideDir, "secret"),
+			filepath.Join(tempDir, "openstack", "escape", "secret"),
+		} {
+			_, err := manager.FileLabel(path)
+			Expect(os.IsNotExist(err)).To(BeTrue(), path)
+		}
+	})
+
+	It("should relabel the rest of the tree and report every failure", func() {
+		brokenDir := filepath.Join(tempDir, "openstack")
+		brokenFile := filepath.Join(tempDir, "openstack", "latest", "user_data")
+		manager.failing[brokenDir] = true
+		manager.failing[brokenFile] = true
+
+		err := ce.RelabelTree(tempDir)
+		Expect(err).To(MatchError(ContainSubstring("failed to relabel " + brokenDir)))
+		Expect(err).To(MatchError(ContainSubstring("failed to relabel " + brokenFile)))
+		Expect(manager.FileLabel(filepath.Join(tempDir, "openstack", "latest", "meta_data.json"))).To(Equal(testLauncherFileLabel))
+	})
+
+	It("should report a missing root", func() {
+		err := ce.RelabelTree(filepath.Join(tempDir, "missing"))
+		Expect(err).To(MatchError(ContainSubstring("failed to walk")))
+	})
+})
+
+// failingFileLabelManager fails to relabel the paths flagged as failing.
+type failingFileLabelManager struct {
+	*testutils.FakeLabelManager
+	failing map[string]bool
+}
+
+func (m *failingFileLabelManager) SetFileLabel(path string, label string) error {
+	if m.failing[path] {
+		return fmt.Errorf("relabel of %s denied", path)
+	}
+	return m.FakeLabelManager.SetFileLabel(path, label)
+}
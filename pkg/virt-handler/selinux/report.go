@@ -0,0 +1,99 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// LabelReport lists the effective selinux labels involved in running the
+// commands of virt-handler against a launcher, to debug relabeling issues.
+type LabelReport struct {
+	Handler  ProcessLabel  `json:"handler"`
+	Launcher ProcessLabel  `json:"launcher"`
+	Devices  []DeviceLabel `json:"devices"`
+	// Error is set if the devices of the launcher couldn't be listed
+	Error string `json:"error,omitempty"`
+}
+
+type ProcessLabel struct {
+	PID   int    `json:"pid"`
+	Label string `json:"label,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type DeviceLabel struct {
+	// Path is the path of the device as seen from the launcher
+	Path  string `json:"path"`
+	Label string `json:"label,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// NewLabelReport reads the labels of virt-handler, of the launcher pid and of
+// all the files below the /dev directory of launcherRoot, usually
+// /proc/<pid>/root. The labels are read through manager, or through the
+// selinux of the host if nil, bypassing the process label cache. Labels which
+// can't be read are reported with their error instead of failing the report.
+func NewLabelReport(launcherPID int, launcherRoot string, manager LabelManager) *LabelReport {
+	if manager == nil {
+		manager = defaultLabelManager
+	}
+	report := &LabelReport{
+		Handler:  newProcessLabel(manager, os.Getpid()),
+		Launcher: newProcessLabel(manager, launcherPID),
+		Devices:  []DeviceLabel{},
+	}
+
+	devDir := filepath.Join(launcherRoot, "dev")
+	err := filepath.Walk(devDir, func(path string, info os.FileInfo, err error) error {
+		if path == devDir {
+			return err
+		}
+		device := DeviceLabel{Path: filepath.Join("/dev", path[len(devDir):])}
+		switch {
+		case err != nil:
+			device.Error = err.Error()
+		case info.IsDir() || info.Mode()&os.ModeSymlink != 0:
+			return nil
+		default:
+			if device.Label, err = manager.FileLabel(path); err != nil {
+				device.Error = err.Error()
+			}
+		}
+		report.Devices = append(report.Devices, device)
+		return nil
+	})
+	if err != nil {
+		report.Error = err.Error()
+	}
+	return report
+}
+
+func newProcessLabel(manager LabelManager, pid int) ProcessLabel {
+	processLabel := ProcessLabel{PID: pid}
+	label, err := readLabelForPIDWith(manager, pid)
+	if err != nil {
+		processLabel.Error = err.Error()
+	} else {
+		processLabel.Label = label
+	}
+	return processLabel
+}
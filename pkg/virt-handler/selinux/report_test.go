@@ -0,0 +1,116 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+var _ = Describe("Label report", func() {
+	const (
+		launcherPID = 1234
+		kvmLabel    = "system_u:object_r:kvm_device_t:s0"
+		tunLabel    = "system_u:object_r:tun_tap_device_t:s0"
+	)
+
+	var manager *testutils.FakeLabelManager
+	var launcherRoot string
+
+	createDevice := func(path string, label string) {
+		path = filepath.Join(launcherRoot, path)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		f, err := os.Create(path)
+		Expect(err).ToNot(HaveOccurred())
+		f.Close()
+		if label != "" {
+			Expect(manager.SetFileLabel(path, label)).To(Succeed())
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		launcherRoot, err = ioutil.TempDir("", "launcher-root")
+		Expect(err).ToNot(HaveOccurred())
+		manager = testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(os.Getpid(), testOriginalLabel)
+		manager.SetProcessLabel(launcherPID, testLauncherLabel)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(launcherRoot)
+	})
+
+	It("should report the labels of virt-handler, the launcher and its devices", func() {
+		createDevice("dev/kvm", kvmLabel)
+		createDevice("dev/net/tun", tunLabel)
+		Expect(os.Symlink("/proc/self/fd", filepath.Join(launcherRoot, "dev", "fd"))).To(Succeed())
+
+		report := NewLabelReport(launcherPID, launcherRoot, manager)
+		Expect(report.Handler).To(Equal(ProcessLabel{PID: os.Getpid(), Label: testOriginalLabel}))
+		Expect(report.Launcher).To(Equal(ProcessLabel{PID: launcherPID, Label: testLauncherLabel}))
+		Expect(report.Devices).To(Equal([]DeviceLabel{
+			{Path: "/dev/kvm", Label: kvmLabel},
+			{Path: "/dev/net/tun", Label: tunLabel},
+		}))
+		Expect(report.Error).To(BeEmpty())
+	})
+
+	It("should report the labels which can't be read", func() {
+		createDevice("dev/vhost-net", "")
+		manager = testutils.NewFakeLabelManager()
+
+		report := NewLabelReport(launcherPID, launcherRoot, manager)
+		Expect(report.Handler.Label).To(BeEmpty())
+		Expect(report.Handler.Error).To(ContainSubstring("no such file or directory"))
+		Expect(report.Launcher.Label).To(BeEmpty())
+		Expect(report.Launcher.Error).To(ContainSubstring("no such file or directory"))
+		Expect(report.Devices).To(HaveLen(1))
+		Expect(report.Devices[0].Path).To(Equal("/dev/vhost-net"))
+		Expect(report.Devices[0].Error).To(ContainSubstring("no such file or directory"))
+	})
+
+	It("should report a launcher without devices", func() {
+		report := NewLabelReport(launcherPID, launcherRoot, manager)
+		Expect(report.Launcher.Label).To(Equal(testLauncherLabel))
+		Expect(report.Devices).To(BeEmpty())
+		Expect(report.Error).To(ContainSubstring("no such file or directory"))
+	})
+
+	It("should serialize to JSON", func() {
+		createDevice("dev/kvm", kvmLabel)
+
+		data, err := json.Marshal(NewLabelReport(launcherPID, launcherRoot, manager))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(MatchJSON(`{
+			"handler": {"pid": ` + strconv.Itoa(os.Getpid()) + `, "label": "` + testOriginalLabel + `"},
+			"launcher": {"pid": 1234, "label": "` + testLauncherLabel + `"},
+			"devices": [{"path": "/dev/kvm", "label": "` + kvmLabel + `"}]
+		}`))
+	})
+})
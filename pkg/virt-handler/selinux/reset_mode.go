@@ -0,0 +1,47 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+// ResetMode tells what happens to the OS thread switched to the launcher
+// context once the command ran.
+type ResetMode int
+
+const (
+	// ResetRestore switches the thread back to the label of virt-handler and
+	// returns it to the runtime. It is the default.
+	ResetRestore ResetMode = iota
+	// ResetSkip leaves the thread in the launcher context and lets the
+	// runtime destroy it, trading the reset syscall for a new thread.
+	ResetSkip
+)
+
+// WithResetMode sets how the executor treats the thread it switched to the
+// launcher context once the command ran. ResetSkip is safe because the
+// goroutine locked to the thread exits without unlocking it, so the runtime
+// terminates the thread instead of scheduling other goroutines on it. The
+// caller guarantees in exchange that nothing needs the thread once the
+// command ran: the post-exec hooks still run in the launcher context, and the
+// children must not rely on the thread which forked them, e.g. through a
+// Pdeathsig, since their parent thread is gone right after they exited.
+func WithResetMode(mode ResetMode) Option {
+	return func(ce *ContextExecutor) {
+		ce.resetMode = mode
+	}
+}
@@ -0,0 +1,135 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/sys/unix"
+)
+
+var _ = Describe("Reset mode", func() {
+
+	var lock sync.Mutex
+	var labels []string
+	var threadLabels map[int]string
+	var labelTIDs []int
+	var mislabeledRuns int
+
+	BeforeEach(func() {
+		labels = nil
+		threadLabels = map[int]string{}
+		labelTIDs = nil
+		mislabeledRuns = 0
+		defaultLabelManager = execLabelFunc(func(label string) error {
+			lock.Lock()
+			defer lock.Unlock()
+			tid := unix.Gettid()
+			if label == testLauncherLabel && threadLabels[tid] == testLauncherLabel {
+				mislabeledRuns++
+			}
+			threadLabels[tid] = label
+			labels = append(labels, label)
+			labelTIDs = append(labelTIDs, tid)
+			return nil
+		})
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, true, nil
+		}
+		ResetSELinuxDetectionForTest()
+	})
+
+	AfterEach(func() {
+		defaultLabelManager = NewLabelManager()
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+	})
+
+	newExecutor := func(cmd *exec.Cmd, options ...Option) ContextExecutor {
+		ce := ContextExecutor{pid: 1, cmdToExecute: cmd, desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel}
+		for _, option := range options {
+			option(&ce)
+		}
+		return ce
+	}
+
+	taskExists := func(tid int) func() bool {
+		return func() bool {
+			_, err := os.Stat(fmt.Sprintf("/proc/self/task/%d", tid))
+			return err == nil
+		}
+	}
+
+	It("should restore the context of the thread by default", func() {
+		ce := newExecutor(exec.Command("true"))
+		Expect(ce.Execute()).To(Succeed())
+		Expect(labels).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+		Expect(labelTIDs[1]).To(Equal(labelTIDs[0]))
+		Consistently(taskExists(labelTIDs[0])).Should(BeTrue())
+	})
+
+	It("should restore the context of the thread with ResetRestore", func() {
+		ce := newExecutor(exec.Command("true"), WithResetMode(ResetRestore))
+		Expect(ce.Execute()).To(Succeed())
+		Expect(labels).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+	})
+
+	It("should destroy the thread instead of resetting it with ResetSkip", func() {
+		ce := newExecutor(exec.Command("true"), WithResetMode(ResetSkip))
+		Expect(ce.Execute()).To(Succeed())
+		Expect(labels).To(Equal([]string{testLauncherLabel}))
+		Eventually(taskExists(labelTIDs[0])).Should(BeFalse())
+	})
+
+	It("should return the output and the error of the command with ResetSkip", func() {
+		ce := newExecutor(exec.Command("sh", "-c", "echo out; exit 3"), WithResetMode(ResetSkip))
+		stdout, _, err := ce.ExecuteWithOutput()
+		Expect(err).To(MatchError(ContainSubstring("exit status 3")))
+		Expect(stdout.String()).To(Equal("out\n"))
+	})
+
+	It("should still run the post-exec hooks in the launcher context with ResetSkip", func() {
+		var hookLabel string
+		ce := newExecutor(exec.Command("true"), WithResetMode(ResetSkip), WithPostExecHook(func() error {
+			lock.Lock()
+			defer lock.Unlock()
+			hookLabel = threadLabels[unix.Gettid()]
+			return nil
+		}))
+		Expect(ce.Execute()).To(Succeed())
+		Expect(hookLabel).To(Equal(testLauncherLabel))
+	})
+
+	It("should never run a command on a thread left in the launcher context", func() {
+		for i := 0; i < 10; i++ {
+			ce := newExecutor(exec.Command("true"), WithResetMode(ResetSkip))
+			Expect(ce.Execute()).To(Succeed())
+		}
+		Expect(mislabeledRuns).To(BeZero())
+		for _, tid := range labelTIDs {
+			Eventually(taskExists(tid)).Should(BeFalse())
+		}
+	})
+})
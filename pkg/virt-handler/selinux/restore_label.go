@@ -0,0 +1,70 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+)
+
+// WithRestoreLabel makes the executor reset the thread to label after the
+// child, instead of the label virt-handler had when the executor was created.
+// It is meant for recovery tooling knowing the label the thread has to get
+// back, e.g. after the label of virt-handler was changed externally. The label
+// is validated when the executor is created: it has to be a well formed label
+// other than the one the child runs with, levels shown by mcstrans are
+// translated to their raw form. It has no effect on threads which are
+// destroyed instead of reset, see WithResetMode.
+func WithRestoreLabel(label string) Option {
+	return func(ce *ContextExecutor) {
+		ce.restoreLabel = label
+		ce.hasRestoreLabel = true
+	}
+}
+
+// resolveRestoreLabel validates the label set by WithRestoreLabel, once the
+// label the child runs with is known, and stores its raw form.
+func (ce *ContextExecutor) resolveRestoreLabel() error {
+	if !ce.hasRestoreLabel {
+		return nil
+	}
+	if ce.restoreLabel == "" {
+		return fmt.Errorf("invalid restore label: the thread can't be reset to an empty selinux label")
+	}
+	label, err := ce.normalizeLabel(ce.restoreLabel)
+	if err != nil {
+		return fmt.Errorf("invalid restore label: %v", err)
+	}
+	if err := validateLabel(label); err != nil {
+		return fmt.Errorf("invalid restore label: %v", err)
+	}
+	if label == ce.desiredLabel {
+		return fmt.Errorf("invalid restore label %s: the thread would be left in the selinux context the child runs with", label)
+	}
+	ce.restoreLabel = label
+	return nil
+}
+
+// getRestoreLabel returns the label the thread is reset to after the child.
+func (ce ContextExecutor) getRestoreLabel() string {
+	if ce.hasRestoreLabel {
+		return ce.restoreLabel
+	}
+	return ce.originalLabel
+}
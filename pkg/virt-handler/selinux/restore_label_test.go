@@ -0,0 +1,108 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"os"
+	"os/exec"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+var _ = Describe("Restore label", func() {
+	const launcherPID = 1234
+	const restoreLabel = "system_u:system_r:spc_t:s0"
+
+	var manager *testutils.FakeLabelManager
+	var restoreProcRoot func()
+
+	BeforeEach(func() {
+		restoreProcRoot = fakeProcRoot(launcherPID)
+		manager = testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(launcherPID, testLauncherLabel)
+		manager.SetProcessLabel(os.Getpid(), testOriginalLabel)
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, true, nil
+		}
+		ResetSELinuxDetectionForTest()
+	})
+
+	AfterEach(func() {
+		restoreProcRoot()
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+	})
+
+	It("should reset the thread to the restore label instead of the label of virt-handler", func() {
+		ce, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager), WithRestoreLabel(restoreLabel))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ce.originalLabel).To(Equal(testOriginalLabel))
+
+		Expect(ce.Execute()).To(Succeed())
+		Expect(manager.ExecLabels()).To(Equal([]string{testLauncherLabel, restoreLabel}))
+	})
+
+	It("should reset the thread to the label of virt-handler without restore label", func() {
+		ce, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ce.Execute()).To(Succeed())
+		Expect(manager.ExecLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+	})
+
+	It("should switch the thread with a restore label while the label of virt-handler is unknown", func() {
+		manager.SetProcessLabel(os.Getpid(), "")
+		ce, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager), WithRestoreLabel(restoreLabel))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ce.Execute()).To(Succeed())
+		Expect(manager.ExecLabels()).To(Equal([]string{testLauncherLabel, restoreLabel}))
+	})
+
+	It("should report a poisoned thread if the restore label can't be applied", func() {
+		manager.DenyExecLabel(restoreLabel, os.ErrPermission)
+		ce, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager), WithRestoreLabel(restoreLabel))
+		Expect(err).ToNot(HaveOccurred())
+
+		err = ce.Execute()
+		Expect(err).To(MatchError(ContainSubstring("failed to reset the selinux exec context to " + restoreLabel)))
+	})
+
+	table.DescribeTable("should reject", func(label, expectedError string) {
+		_, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager), WithRestoreLabel(label))
+		Expect(err).To(MatchError(ContainSubstring(expectedError)))
+		Expect(manager.ExecLabels()).To(BeEmpty())
+	},
+		table.Entry("an empty label", "", "can't be reset to an empty selinux label"),
+		table.Entry("a label without type", "system_u:system_r", "malformed selinux label"),
+		table.Entry("a label with an invalid level", "system_u:system_r:spc_t:s0:c1:c2", "malformed selinux label"),
+		table.Entry("the label the child runs with", testLauncherLabel, "would be left in the selinux context the child runs with"),
+	)
+
+	It("should reject the label the child runs with after a type transition", func() {
+		_, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager),
+			WithTypeTransition("virt_launcher_child_t"), WithRestoreLabel("system_u:system_r:virt_launcher_child_t:s0:c1,c2"))
+		Expect(err).To(MatchError(ContainSubstring("would be left in the selinux context the child runs with")))
+	})
+})
@@ -0,0 +1,105 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"os/exec"
+	"sync"
+)
+
+// WithRingBufferOutput keeps the last size bytes of the combined stdout and
+// stderr of the child, which ExecuteWithResult and ExecuteMany return in
+// ExecuteResult.Output, e.g. for crash diagnostics of a command with an
+// unbounded output. The output is still captured as without the option.
+// Nothing is kept if size is not positive.
+func WithRingBufferOutput(size int) Option {
+	return func(ce *ContextExecutor) {
+		ce.ringBufferSize = size
+	}
+}
+
+// newRingBuffer returns the buffer of a run, nil if the option is not set.
+func (ce ContextExecutor) newRingBuffer() *ringBuffer {
+	if ce.ringBufferSize <= 0 {
+		return nil
+	}
+	return &ringBuffer{buf: make([]byte, ce.ringBufferSize)}
+}
+
+// setOutput records the output kept by the buffer of the run, if any.
+func (r *ExecuteResult) setOutput(output *ringBuffer) {
+	if output != nil {
+		r.Output = output.Bytes()
+		r.OutputTruncated = output.truncated()
+	}
+}
+
+// teeRingBuffer tees the output of cmd to the ring buffer and returns the
+// function restoring the writers of cmd once the child exited.
+func (ce ContextExecutor) teeRingBuffer(cmd *exec.Cmd) func() {
+	origStdout, origStderr := cmd.Stdout, cmd.Stderr
+	cmd.Stdout = teeWriter(origStdout, ce.ringBuffer)
+	cmd.Stderr = teeWriter(origStderr, ce.ringBuffer)
+	return func() {
+		cmd.Stdout, cmd.Stderr = origStdout, origStderr
+	}
+}
+
+// ringBuffer keeps the last len(buf) bytes written to it, by stdout and
+// stderr at once.
+type ringBuffer struct {
+	lock sync.Mutex
+	buf  []byte
+	// next is the index the next byte is written to
+	next int
+	// written counts all the bytes written, kept or not
+	written int64
+}
+
+func (r *ringBuffer) Write(p []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	n := len(p)
+	r.written += int64(n)
+	if len(p) > len(r.buf) {
+		p = p[len(p)-len(r.buf):]
+	}
+	copied := copy(r.buf[r.next:], p)
+	copy(r.buf, p[copied:])
+	r.next = (r.next + len(p)) % len(r.buf)
+	return n, nil
+}
+
+// Bytes returns a copy of the bytes kept, oldest first.
+func (r *ringBuffer) Bytes() []byte {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.written < int64(len(r.buf)) {
+		return append([]byte(nil), r.buf[:r.written]...)
+	}
+	return append(append(make([]byte, 0, len(r.buf)), r.buf[r.next:]...), r.buf[:r.next]...)
+}
+
+// truncated tells whether older bytes were dropped.
+func (r *ringBuffer) truncated() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.written > int64(len(r.buf))
+}
@@ -0,0 +1,130 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Keeping the tail of the output of the child", func() {
+
+	Context("the ring buffer", func() {
+		It("should keep all the output smaller than the buffer", func() {
+			r := &ringBuffer{buf: make([]byte, 8)}
+			r.Write([]byte("abc"))
+			r.Write([]byte("de"))
+			Expect(string(r.Bytes())).To(Equal("abcde"))
+			Expect(r.truncated()).To(BeFalse())
+		})
+
+		It("should keep the output filling the buffer exactly", func() {
+			r := &ringBuffer{buf: make([]byte, 4)}
+			r.Write([]byte("ab"))
+			r.Write([]byte("cd"))
+			Expect(string(r.Bytes())).To(Equal("abcd"))
+			Expect(r.truncated()).To(BeFalse())
+		})
+
+		It("should keep the tail of writes wrapping around", func() {
+			r := &ringBuffer{buf: make([]byte, 4)}
+			for _, p := range []string{"abc", "def", "g"} {
+				n, err := r.Write([]byte(p))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(n).To(Equal(len(p)))
+			}
+			Expect(string(r.Bytes())).To(Equal("defg"))
+			Expect(r.truncated()).To(BeTrue())
+		})
+
+		It("should keep the tail of a single write larger than the buffer", func() {
+			r := &ringBuffer{buf: make([]byte, 4)}
+			r.Write([]byte("a"))
+			n, err := r.Write([]byte("bcdefghij"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(9))
+			Expect(string(r.Bytes())).To(Equal("ghij"))
+			Expect(r.truncated()).To(BeTrue())
+		})
+	})
+
+	newExecutor := func(cmd *exec.Cmd, options ...Option) ContextExecutor {
+		ce := ContextExecutor{pid: 1, cmdToExecute: cmd}
+		for _, option := range options {
+			option(&ce)
+		}
+		return ce
+	}
+
+	It("should return the whole combined output smaller than the buffer", func() {
+		result := newExecutor(exec.Command("sh", "-c", "echo out; echo err >&2"), WithRingBufferOutput(1024)).ExecuteWithResult()
+		Expect(result.Err).ToNot(HaveOccurred())
+		Expect(strings.Fields(string(result.Output))).To(ConsistOf("out", "err"))
+		Expect(result.OutputTruncated).To(BeFalse())
+	})
+
+	It("should return only the tail of the output, within the size limit", func() {
+		var expected strings.Builder
+		for i := 1; i <= 10000; i++ {
+			expected.WriteString(strconv.Itoa(i) + "\n")
+		}
+		result := newExecutor(exec.Command("seq", "1", "10000"), WithRingBufferOutput(16)).ExecuteWithResult()
+		Expect(result.Err).ToNot(HaveOccurred())
+		Expect(result.Output).To(HaveLen(16))
+		Expect(expected.String()).To(HaveSuffix(string(result.Output)))
+		Expect(result.OutputTruncated).To(BeTrue())
+	})
+
+	It("should keep the output of a failing child", func() {
+		result := newExecutor(exec.Command("sh", "-c", "echo failing >&2; exit 3"), WithRingBufferOutput(1024)).ExecuteWithResult()
+		Expect(result.Err).To(HaveOccurred())
+		Expect(result.ExitCode).To(Equal(3))
+		Expect(string(result.Output)).To(Equal("failing\n"))
+	})
+
+	It("should keep the output of each command run by ExecuteMany", func() {
+		ce := newExecutor(nil, WithRingBufferOutput(1024))
+		results, err := ce.ExecuteMany([]*exec.Cmd{exec.Command("echo", "first"), exec.Command("echo", "second")}, FailFast)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results).To(HaveLen(2))
+		Expect(string(results[0].Output)).To(Equal("first\n"))
+		Expect(string(results[1].Output)).To(Equal("second\n"))
+	})
+
+	It("should still capture the output for the caller", func() {
+		cmd := exec.Command("echo", "captured")
+		var stdout strings.Builder
+		cmd.Stdout = &stdout
+		result := newExecutor(cmd, WithRingBufferOutput(1024)).ExecuteWithResult()
+		Expect(result.Err).ToNot(HaveOccurred())
+		Expect(stdout.String()).To(Equal("captured\n"))
+		Expect(string(result.Output)).To(Equal("captured\n"))
+	})
+
+	It("should keep nothing without the option", func() {
+		result := newExecutor(exec.Command("echo", "dropped")).ExecuteWithResult()
+		Expect(result.Err).ToNot(HaveOccurred())
+		Expect(result.Output).To(BeNil())
+	})
+})
@@ -0,0 +1,149 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"syscall"
+
+	"kubevirt.io/kubevirt/pkg/util/rlimit"
+)
+
+const capSysResource = 24
+
+// shimPath is executed in place of the command to apply the resource limits
+// of the executor or to enter the mount namespace of the launcher, right
+// before it executes the command itself.
+var shimPath = "/usr/bin/virt-chroot"
+
+var getrlimit = syscall.Getrlimit
+
+// WithRLimits runs the executed commands with the given resource limits, by
+// resource, e.g. unix.RLIMIT_NOFILE or unix.RLIMIT_CORE. The limits of a
+// process are shared by all its threads and can't be set for a child before it
+// executes its command, so that the command is executed through virt-chroot,
+// which applies the limits to itself before executing it. The command then
+// runs with its path as argv[0]. Raising a hard limit above the one of
+// virt-handler requires the CAP_SYS_RESOURCE capability, which a child
+// switching to a non-root uid has to keep, see WithCapabilities.
+func WithRLimits(limits map[int]syscall.Rlimit) Option {
+	return func(ce *ContextExecutor) {
+		ce.rlimits = make(map[int]syscall.Rlimit, len(limits))
+		for resource, limit := range limits {
+			ce.rlimits[resource] = limit
+		}
+	}
+}
+
+// applyShim makes cmd execute through the shim, if it has to enter the mount
+// namespace of the launcher or if virt-handler is privileged enough to apply
+// the resource limits. The returned function puts back the path and the
+// arguments of cmd.
+func (ce ContextExecutor) applyShim(cmd *exec.Cmd) (func(), error) {
+	mountArgs, err := ce.mountNamespaceArgs()
+	if err != nil {
+		return nil, err
+	}
+	rlimitArgs, err := ce.rlimitArgs()
+	if err != nil {
+		return nil, err
+	}
+	if len(mountArgs) == 0 && len(rlimitArgs) == 0 {
+		return func() {}, nil
+	}
+
+	args := append([]string{shimPath}, mountArgs...)
+	args = append(args, rlimitArgs...)
+	args = append(args, "exec", "--", cmd.Path)
+	if len(cmd.Args) > 1 {
+		args = append(args, cmd.Args[1:]...)
+	}
+	path, originalArgs := cmd.Path, cmd.Args
+	cmd.Path, cmd.Args = shimPath, args
+	return func() {
+		cmd.Path, cmd.Args = path, originalArgs
+	}, nil
+}
+
+// rlimitArgs returns the arguments making the shim apply the resource limits
+// of the executor.
+func (ce ContextExecutor) rlimitArgs() ([]string, error) {
+	if len(ce.rlimits) == 0 {
+		return nil, nil
+	}
+	resources := make([]int, 0, len(ce.rlimits))
+	for resource := range ce.rlimits {
+		resources = append(resources, resource)
+	}
+	sort.Ints(resources)
+	if err := ce.checkRLimits(resources); err != nil {
+		return nil, err
+	}
+
+	var args []string
+	for _, resource := range resources {
+		args = append(args, "--rlimit", rlimit.Format(resource, ce.rlimits[resource]))
+	}
+	return args, nil
+}
+
+// checkRLimits rejects soft limits above their hard limit, and hard limits
+// above the ones of virt-handler unless it holds CAP_SYS_RESOURCE and the
+// child keeps it.
+func (ce ContextExecutor) checkRLimits(resources []int) error {
+	for _, resource := range resources {
+		limit := ce.rlimits[resource]
+		if limit.Cur > limit.Max {
+			return fmt.Errorf("the soft limit %d of resource %d exceeds its hard limit %d", limit.Cur, resource, limit.Max)
+		}
+		var current syscall.Rlimit
+		if err := getrlimit(resource, &current); err != nil {
+			return fmt.Errorf("failed to read the limit of resource %d of virt-handler: %v", resource, err)
+		}
+		if limit.Max <= current.Max {
+			continue
+		}
+		caps, err := effectiveCapabilities()
+		if err != nil {
+			return fmt.Errorf("failed to check the privileges to raise the hard limit of resource %d: %v", resource, err)
+		}
+		if caps&(1<<capSysResource) == 0 {
+			return fmt.Errorf("raising the hard limit of resource %d from %d to %d requires the CAP_SYS_RESOURCE capability", resource, current.Max, limit.Max)
+		}
+		if ce.credential != nil && ce.credential.Uid != 0 && !ce.keepsCapability(capSysResource) {
+			return fmt.Errorf("raising the hard limit of resource %d from %d to %d as uid %d requires the child to keep the CAP_SYS_RESOURCE capability", resource, current.Max, limit.Max, ce.credential.Uid)
+		}
+	}
+	return nil
+}
+
+func (ce ContextExecutor) keepsCapability(capability uintptr) bool {
+	if !ce.restrictCapabilities {
+		return false
+	}
+	for _, c := range ce.capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/sys/unix"
+
+	"kubevirt.io/kubevirt/pkg/util/rlimit"
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+const shimEnv = "SELINUX_TEST_SHIM"
+
+// the test binary executed with shimEnv set acts as the shim
+func init() {
+	if os.Getenv(shimEnv) != "" {
+		runShim(os.Args[1:])
+	}
+}
+
+// runShim joins the mount namespace, applies the limits and executes the
+// command like virt-chroot [--mount <namespace>] [--rlimit <limit>...] exec --
+// <command>. It runs in init, on the main thread, which executes the command.
+func runShim(args []string) {
+	if len(args) >= 2 && args[0] == "--mount" {
+		fd, err := unix.Open(args[1], unix.O_RDONLY|unix.O_CLOEXEC, 0)
+		if err == nil {
+			err = unix.Unshare(unix.CLONE_NEWNS)
+		}
+		if err == nil {
+			err = unix.Setns(fd, unix.CLONE_NEWNS)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		args = args[2:]
+	}
+	for len(args) >= 2 && args[0] == "--rlimit" {
+		resource, limit, err := rlimit.Parse(args[1])
+		if err == nil {
+			err = syscall.Setrlimit(resource, &limit)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		args = args[2:]
+	}
+	if len(args) < 3 || args[0] != "exec" || args[1] != "--" {
+		fmt.Fprintf(os.Stderr, "unexpected arguments %v\n", args)
+		os.Exit(2)
+	}
+	err := syscall.Exec(args[2], args[2:], os.Environ())
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
+
+var _ = Describe("Resource limits", func() {
+	const launcherPID = 1234
+
+	var manager *testutils.FakeLabelManager
+	var restoreProcRoot func()
+	var current syscall.Rlimit
+
+	BeforeEach(func() {
+		restoreProcRoot = fakeProcRoot(launcherPID)
+		manager = testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(launcherPID, testLauncherLabel)
+		manager.SetProcessLabel(os.Getpid(), testOriginalLabel)
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, false, nil
+		}
+		ResetSELinuxDetectionForTest()
+		shim, err := os.Executable()
+		Expect(err).ToNot(HaveOccurred())
+		shimPath = shim
+		Expect(syscall.Getrlimit(unix.RLIMIT_NOFILE, &current)).To(Succeed())
+		Expect(current.Max).To(BeNumerically(">=", 512))
+	})
+
+	AfterEach(func() {
+		restoreProcRoot()
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+		shimPath = "/usr/bin/virt-chroot"
+		getrlimit = syscall.Getrlimit
+		effectiveCapabilities = readEffectiveCapabilities
+	})
+
+	// execute prints the soft and the hard limit of open files of the child
+	execute := func(options ...Option) (string, error) {
+		cmd := exec.Command("sh", "-c", "ulimit -Sn; ulimit -Hn")
+		cmd.Env = append(os.Environ(), shimEnv+"=true")
+		ce, err := NewContextExecutor(launcherPID, cmd, append([]Option{WithLabelManager(manager)}, options...)...)
+		Expect(err).ToNot(HaveOccurred())
+		stdout, _, err := ce.ExecuteWithOutput()
+		if err != nil {
+			return "", err
+		}
+		Expect(cmd.Path).ToNot(Equal(shimPath))
+		return strings.TrimSpace(stdout.String()), nil
+	}
+
+	It("should run the child with the configured limit of open files", func() {
+		out, err := execute(WithRLimits(map[int]syscall.Rlimit{
+			unix.RLIMIT_NOFILE: {Cur: 256, Max: 512},
+		}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal("256\n512"))
+	})
+
+	It("should not execute through the shim without limits", func() {
+		shimPath = "/non/existent"
+		_, err := execute()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should reject a soft limit above the hard limit", func() {
+		_, err := execute(WithRLimits(map[int]syscall.Rlimit{
+			unix.RLIMIT_NOFILE: {Cur: 512, Max: 256},
+		}))
+		Expect(err).To(MatchError("the soft limit 512 of resource 7 exceeds its hard limit 256"))
+	})
+
+	Context("raising a hard limit", func() {
+		BeforeEach(func() {
+			getrlimit = func(resource int, limit *syscall.Rlimit) error {
+				*limit = syscall.Rlimit{Cur: 128, Max: 256}
+				return nil
+			}
+		})
+
+		It("should require CAP_SYS_RESOURCE", func() {
+			effectiveCapabilities = func() (uint64, error) {
+				return 0, nil
+			}
+			_, err := execute(WithRLimits(map[int]syscall.Rlimit{
+				unix.RLIMIT_NOFILE: {Cur: 256, Max: 512},
+			}))
+			Expect(err).To(MatchError("raising the hard limit of resource 7 from 256 to 512 requires the CAP_SYS_RESOURCE capability"))
+		})
+
+		It("should require a child switching to a non-root uid to keep CAP_SYS_RESOURCE", func() {
+			effectiveCapabilities = func() (uint64, error) {
+				return 1<<capSysResource | 1<<capSetUID | 1<<capSetGID, nil
+			}
+			_, err := execute(WithCredentials(107, 107, nil), WithRLimits(map[int]syscall.Rlimit{
+				unix.RLIMIT_NOFILE: {Cur: 256, Max: 512},
+			}))
+			Expect(err).To(MatchError("raising the hard limit of resource 7 from 256 to 512 as uid 107 requires the child to keep the CAP_SYS_RESOURCE capability"))
+		})
+	})
+})
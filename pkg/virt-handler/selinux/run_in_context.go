@@ -0,0 +1,144 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"syscall"
+	"time"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// CurrentLabelSetter is implemented by the label managers able to switch the
+// label the calling thread runs with, as written to its attr/current file.
+type CurrentLabelSetter interface {
+	SetCurrentLabel(label string) error
+}
+
+// RunInContext runs fn on an OS thread running with the launcher label, for
+// the operations virt-handler does itself, like opening a device of the
+// launcher, instead of through a child.
+//
+// Execute sets the exec label of the thread, which the kernel only applies to
+// the programs the thread executes: the thread itself keeps running with the
+// label of virt-handler. RunInContext instead switches the current label of
+// the thread around fn, which the policy has to allow as a dyntransition, from
+// the label of virt-handler to the launcher label and back. The files fn
+// creates and the accesses it does are checked against the launcher label.
+//
+// virt-handler is multithreaded, and the kernel only lets the thread of a
+// multithreaded process switch to a type bounded by its current one: the
+// policy has to declare the type of the launcher label with a typebounds
+// statement on the type of virt-handler, and the level of the launcher label
+// has to be dominated by the one of virt-handler. Without it, the switch fails
+// with EPERM and RunInContext returns a ContextSwitchError wrapping
+// ErrUnboundedLabel without running fn.
+//
+// The thread is locked for the duration of fn, which must not hand its work
+// over to other goroutines since they run on other threads, with the label of
+// virt-handler. If the thread can't be switched back, it is destroyed and the
+// error returned alongside the one of fn. Only the label of the thread is
+// switched: the namespaces, credentials and restrictions of WithCapabilities,
+// WithPriority, WithSchedPolicy or WithUmask only apply to the children run by
+// Execute. Without selinux, fn is run as is.
+func (ce ContextExecutor) RunInContext(fn func() error) error {
+	if ce.dryRun {
+		ce.getLogger().Infof("dry-run: would run a function in the selinux context %s of launcher pid %d", ce.desiredLabel, ce.pid)
+		return nil
+	}
+	if err := ce.checkNotEntered(); err != nil {
+		return err
+	}
+	if !isSELinuxEnabled() {
+		return fn()
+	}
+	setter, ok := ce.getLabelManager().(CurrentLabelSetter)
+	if !ok {
+		return fmt.Errorf("the selinux label manager can't switch the current label of the thread")
+	}
+	restoreLabel := ce.getRestoreLabel()
+	if restoreLabel == "" {
+		return fmt.Errorf("refusing to switch the selinux context to %s for launcher pid %d: the selinux label of virt-handler is unknown and could not be restored", ce.desiredLabel, ce.pid)
+	}
+	if activePID, active := activeLabelSwitch(); active {
+		return fmt.Errorf("%w: refusing to switch to the context of launcher pid %d from the context of launcher pid %d", ErrNestedLabelSwitch, ce.pid, activePID)
+	}
+
+	var err error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err = checkLauncherExists(ce.pid); err != nil {
+			return
+		}
+		runtime.LockOSThread()
+		// also covers threads which are destroyed instead of unlocked
+		defer observeThreadLock(time.Now())
+		defer endLabelSwitch(beginLabelSwitch(ce.pid))
+		ce.getLogger().V(debugVerbosity).Infof("switching the selinux context of the thread from %s to %s for launcher pid %d", restoreLabel, ce.desiredLabel, ce.pid)
+		setErr := setter.SetCurrentLabel(ce.desiredLabel)
+		countContextSwitch(setErr != nil)
+		if unboundedErr := unboundedLabelError(setErr); unboundedErr != nil {
+			// the kernel refused the switch, the thread kept its label
+			ce.getLogger().Reason(unboundedErr).Errorf("failed to switch the selinux context of the thread to %s for launcher pid %d", ce.desiredLabel, ce.pid)
+			ce.recordContextSwitchFailure(unboundedErr)
+			err = &ContextSwitchError{Label: ce.desiredLabel, Err: unboundedErr}
+			runtime.UnlockOSThread()
+			return
+		}
+		if setErr != nil {
+			// the label of the still locked thread is unknown, let it be destroyed
+			ce.getLogger().Reason(setErr).Errorf("failed to switch the selinux context of the thread to %s for launcher pid %d", ce.desiredLabel, ce.pid)
+			ce.recordContextSwitchFailure(setErr)
+			err = &ContextSwitchError{Label: ce.desiredLabel, Err: setErr}
+			return
+		}
+
+		err = fn()
+
+		if resetErr := setter.SetCurrentLabel(restoreLabel); resetErr != nil {
+			resetErr = fmt.Errorf("%w: failed to reset the selinux context of the thread to %s: %v", errPoisonedThread, restoreLabel, resetErr)
+			// never hide that the thread was left in the launcher context
+			if err == nil {
+				err = resetErr
+			} else {
+				err = utilerrors.NewAggregate([]error{err, resetErr})
+			}
+			ce.getLogger().Reason(resetErr).Errorf("terminating the OS thread left in the selinux context of launcher pid %d", ce.pid)
+			runtime.Goexit()
+		}
+		runtime.UnlockOSThread()
+	}()
+	<-done
+	return err
+}
+
+// unboundedLabelError wraps the error of a switch of the current label the
+// kernel refused with EPERM, which leaves the label of the thread untouched,
+// in ErrUnboundedLabel. It returns nil for any other outcome of the switch.
+func unboundedLabelError(setErr error) error {
+	if !errors.Is(setErr, syscall.EPERM) {
+		return nil
+	}
+	return fmt.Errorf("%w: %v", ErrUnboundedLabel, setErr)
+}
@@ -0,0 +1,242 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/sys/unix"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+// threadLabelManager tracks the current label of every thread, like the
+// attr/current files of the threads on a real host.
+type threadLabelManager struct {
+	*testutils.FakeLabelManager
+	lock         sync.Mutex
+	threadLabels map[int]string
+}
+
+func (m *threadLabelManager) SetCurrentLabel(label string) error {
+	if err := m.FakeLabelManager.SetCurrentLabel(label); err != nil {
+		return err
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.threadLabels[unix.Gettid()] = label
+	return nil
+}
+
+func (m *threadLabelManager) threadLabel(tid int) string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.threadLabels[tid]
+}
+
+// failingLabelSetter fails the switches to the labels of errs and records the
+// thread of every switch.
+type failingLabelSetter struct {
+	*threadLabelManager
+	errs map[string]error
+	tids []int
+}
+
+func (s *failingLabelSetter) SetCurrentLabel(label string) error {
+	s.tids = append(s.tids, unix.Gettid())
+	if err := s.errs[label]; err != nil {
+		return err
+	}
+	return s.threadLabelManager.SetCurrentLabel(label)
+}
+
+var _ = Describe("RunInContext", func() {
+	const launcherPID = 1234
+
+	var manager *threadLabelManager
+	var restoreProcRoot func()
+
+	BeforeEach(func() {
+		restoreProcRoot = fakeProcRoot(launcherPID)
+		manager = &threadLabelManager{FakeLabelManager: testutils.NewFakeLabelManager(), threadLabels: map[int]string{}}
+		manager.SetProcessLabel(launcherPID, testLauncherLabel)
+		manager.SetProcessLabel(os.Getpid(), testOriginalLabel)
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, true, nil
+		}
+		ResetSELinuxDetectionForTest()
+	})
+
+	AfterEach(func() {
+		restoreProcRoot()
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+	})
+
+	newExecutor := func(options ...Option) *ContextExecutor {
+		ce, err := NewContextExecutor(launcherPID, nil, append([]Option{WithLabelManager(manager)}, options...)...)
+		Expect(err).ToNot(HaveOccurred())
+		return ce
+	}
+
+	taskExists := func(tid int) func() bool {
+		return func() bool {
+			_, err := os.Stat(fmt.Sprintf("/proc/self/task/%d", tid))
+			return err == nil
+		}
+	}
+
+	It("should run the function on a thread switched to the launcher label and switch it back", func() {
+		var tid int
+		var observed string
+		Expect(newExecutor().RunInContext(func() error {
+			tid = unix.Gettid()
+			observed = manager.threadLabel(tid)
+			return nil
+		})).To(Succeed())
+
+		Expect(observed).To(Equal(testLauncherLabel))
+		Expect(manager.threadLabel(tid)).To(Equal(testOriginalLabel))
+		Expect(manager.CurrentLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+		Expect(manager.ExecLabels()).To(BeEmpty())
+		Consistently(taskExists(tid)).Should(BeTrue())
+	})
+
+	It("should switch the thread back to the restore label", func() {
+		const restoreLabel = "system_u:system_r:spc_t:s0"
+		Expect(newExecutor(WithRestoreLabel(restoreLabel)).RunInContext(func() error {
+			return nil
+		})).To(Succeed())
+		Expect(manager.CurrentLabels()).To(Equal([]string{testLauncherLabel, restoreLabel}))
+	})
+
+	It("should return the error of the function once the thread is switched back", func() {
+		fnErr := errors.New("device busy")
+		Expect(newExecutor().RunInContext(func() error {
+			return fnErr
+		})).To(MatchError(fnErr))
+		Expect(manager.CurrentLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+	})
+
+	It("should not run the function if the thread can't be switched", func() {
+		manager.DenyCurrentLabel(testLauncherLabel, syscall.EACCES)
+		called := false
+		err := newExecutor().RunInContext(func() error {
+			called = true
+			return nil
+		})
+		Expect(IsSELinuxError(err)).To(BeTrue())
+		Expect(called).To(BeFalse())
+	})
+
+	It("should destroy the thread if it can't be switched back", func() {
+		manager.DenyCurrentLabel(testOriginalLabel, syscall.EACCES)
+		var tid int
+		err := newExecutor().RunInContext(func() error {
+			tid = unix.Gettid()
+			return errors.New("device busy")
+		})
+		Expect(err).To(MatchError(ContainSubstring("device busy")))
+		Expect(err).To(MatchError(ContainSubstring("failed to reset the selinux context of the thread to " + testOriginalLabel)))
+		Eventually(taskExists(tid)).Should(BeFalse())
+	})
+
+	It("should fail up front and keep the thread if the launcher label is not bounded", func() {
+		setter := &failingLabelSetter{threadLabelManager: manager, errs: map[string]error{testLauncherLabel: &os.PathError{Op: "write", Path: "/proc/thread-self/attr/current", Err: syscall.EPERM}}}
+		ce := newExecutor(WithLabelManager(setter))
+		err := ce.RunInContext(func() error {
+			Fail("the function should not run")
+			return nil
+		})
+		var switchErr *ContextSwitchError
+		Expect(errors.As(err, &switchErr)).To(BeTrue())
+		Expect(errors.Is(err, ErrUnboundedLabel)).To(BeTrue())
+		Expect(errors.Is(err, errPoisonedThread)).To(BeFalse())
+		Expect(setter.tids).To(HaveLen(1))
+		Consistently(taskExists(setter.tids[0])).Should(BeTrue())
+	})
+
+	It("should return the reset failure and discard the thread even if the function succeeded", func() {
+		setter := &failingLabelSetter{threadLabelManager: manager, errs: map[string]error{testOriginalLabel: syscall.EACCES}}
+		ce := newExecutor(WithLabelManager(setter))
+		err := ce.RunInContext(func() error {
+			return nil
+		})
+		Expect(errors.Is(err, errPoisonedThread)).To(BeTrue())
+		Expect(setter.tids).To(HaveLen(2))
+		discarded := setter.tids[1]
+		Eventually(taskExists(discarded)).Should(BeFalse())
+
+		delete(setter.errs, testOriginalLabel)
+		var tid int
+		Expect(ce.RunInContext(func() error {
+			tid = unix.Gettid()
+			return nil
+		})).To(Succeed())
+		Expect(tid).ToNot(Equal(discarded))
+		Expect(manager.threadLabel(tid)).To(Equal(testOriginalLabel))
+	})
+
+	It("should refuse nested switches from the function", func() {
+		ce := newExecutor()
+		var nestedRunErr, nestedExecErr error
+		Expect(ce.RunInContext(func() error {
+			nestedRunErr = ce.RunInContext(func() error {
+				return nil
+			})
+			nestedExecErr = ContextExecutor{pid: launcherPID, cmdToExecute: exec.Command("true"), desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel, labelManager: manager}.Execute()
+			return nil
+		})).To(Succeed())
+		Expect(errors.Is(nestedRunErr, ErrNestedLabelSwitch)).To(BeTrue())
+		Expect(errors.Is(nestedExecErr, ErrNestedLabelSwitch)).To(BeTrue())
+		Expect(manager.CurrentLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+	})
+
+	It("should run the function as is without selinux", func() {
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, false, nil
+		}
+		ResetSELinuxDetectionForTest()
+		called := false
+		Expect(newExecutor().RunInContext(func() error {
+			called = true
+			return nil
+		})).To(Succeed())
+		Expect(called).To(BeTrue())
+		Expect(manager.CurrentLabels()).To(BeEmpty())
+	})
+
+	It("should fail with a label manager unable to switch the current label", func() {
+		ce := ContextExecutor{pid: launcherPID, desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel, labelManager: execLabelFunc(func(string) error {
+			return nil
+		})}
+		Expect(ce.RunInContext(func() error {
+			Fail("the function should not run")
+			return nil
+		})).To(MatchError(ContainSubstring("can't switch the current label of the thread")))
+	})
+})
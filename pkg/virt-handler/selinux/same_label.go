@@ -0,0 +1,45 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+// needsLabelSwitch reports whether the thread starting the child has to be
+// switched to the launcher label. It doesn't when virt-handler already runs
+// with that label and the thread is reset to it, unless the file creation
+// context of the thread is set too.
+func (ce ContextExecutor) needsLabelSwitch() bool {
+	return ce.desiredLabel == "" || ce.desiredLabel != ce.originalLabel ||
+		ce.getRestoreLabel() != ce.desiredLabel || ce.hasFSCreateLabel
+}
+
+// inSharedContext runs f without switching the exec label of the thread, for
+// launchers sharing the label of virt-handler: the child gets the label of
+// virt-handler as computed by the policy. The thread is only locked if the
+// priority, the scheduling policy, the umask or the capabilities of the child
+// are restricted.
+func (ce ContextExecutor) inSharedContext(f func() error) error {
+	if err := checkLauncherExists(ce.pid); err != nil {
+		return err
+	}
+	ce.getLogger().V(debugVerbosity).Infof("launcher pid %d shares the selinux label %s, leaving the exec context alone", ce.pid, ce.desiredLabel)
+	if ce.restrictsThread() {
+		return ce.inRestrictedThread(f)
+	}
+	return f()
+}
@@ -0,0 +1,96 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"os"
+	"os/exec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+var _ = Describe("Shared label", func() {
+	const launcherPID = 1234
+
+	var manager *fsCreateLabelManager
+	var restoreProcRoot func()
+
+	BeforeEach(func() {
+		restoreProcRoot = fakeProcRoot(launcherPID)
+		manager = &fsCreateLabelManager{FakeLabelManager: testutils.NewFakeLabelManager(), threadLabels: map[int]string{}}
+		manager.SetProcessLabel(launcherPID, testLauncherLabel)
+		manager.SetProcessLabel(os.Getpid(), testLauncherLabel)
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, true, nil
+		}
+		ResetSELinuxDetectionForTest()
+	})
+
+	AfterEach(func() {
+		restoreProcRoot()
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+	})
+
+	execute := func(options ...Option) error {
+		ce, err := NewContextExecutor(launcherPID, exec.Command("true"), append([]Option{WithLabelManager(manager)}, options...)...)
+		Expect(err).ToNot(HaveOccurred())
+		return ce.Execute()
+	}
+
+	It("should not switch the exec context of the thread if the labels are equal", func() {
+		Expect(execute()).To(Succeed())
+		Expect(manager.ExecLabels()).To(BeEmpty())
+	})
+
+	It("should still restrict the thread if the labels are equal", func() {
+		Expect(execute(WithUmask(0077))).To(Succeed())
+		Expect(manager.ExecLabels()).To(BeEmpty())
+	})
+
+	It("should still fail if the launcher is gone", func() {
+		ce, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager))
+		Expect(err).ToNot(HaveOccurred())
+		restoreProcRoot()
+		restoreProcRoot = func() {}
+		Expect(ce.Execute()).To(MatchError(&LauncherExitedError{PID: launcherPID}))
+		Expect(manager.ExecLabels()).To(BeEmpty())
+	})
+
+	It("should switch the exec context of the thread if it is reset to another label", func() {
+		Expect(execute(WithRestoreLabel(testOriginalLabel))).To(Succeed())
+		Expect(manager.ExecLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+	})
+
+	It("should switch the exec context of the thread if its file creation context is set", func() {
+		Expect(execute(WithFSCreateLabel(""))).To(Succeed())
+		Expect(manager.ExecLabels()).To(Equal([]string{testLauncherLabel, testLauncherLabel}))
+		Expect(manager.FSCreateLabels()).To(Equal([]string{testLauncherFileLabel, ""}))
+	})
+
+	It("should switch the exec context of the thread if the labels differ", func() {
+		manager.SetProcessLabel(os.Getpid(), testOriginalLabel)
+		Expect(execute()).To(Succeed())
+		Expect(manager.ExecLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+	})
+})
@@ -0,0 +1,137 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// SchedPolicy is a realtime scheduling policy of sched_setscheduler(2).
+type SchedPolicy int
+
+const (
+	// SchedFIFO runs the child until it blocks or a task of a higher
+	// priority preempts it.
+	SchedFIFO SchedPolicy = 1
+	// SchedRR is SchedFIFO with a time slice shared with the tasks of the
+	// same priority.
+	SchedRR SchedPolicy = 2
+)
+
+func (p SchedPolicy) String() string {
+	switch p {
+	case SchedFIFO:
+		return "SCHED_FIFO"
+	case SchedRR:
+		return "SCHED_RR"
+	}
+	return fmt.Sprintf("scheduling policy %d", int(p))
+}
+
+type schedPolicy struct {
+	policy   SchedPolicy
+	priority int
+}
+
+// schedParam is the struct sched_param of sched_setscheduler(2)
+type schedParam struct {
+	priority int32
+}
+
+// getRTPrioLimit returns the soft RLIMIT_RTPRIO of virt-handler, the highest
+// realtime priority usable without CAP_SYS_NICE.
+var getRTPrioLimit = func() (uint64, error) {
+	var limit unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_RTPRIO, &limit); err != nil {
+		return 0, err
+	}
+	return limit.Cur, nil
+}
+
+// WithSchedPolicy runs the executed commands with the realtime scheduling
+// policy and priority, e.g. so that the auxiliary tasks of a realtime VMI
+// neither preempt its vCPU threads nor are starved by them. Like the ones of
+// WithPriority, which this composes with, the policy is applied to the OS
+// thread forking the child and inherited by all of its threads, and that
+// thread is destroyed afterwards. The policy requires CAP_SYS_NICE, unless
+// the priority is within the RLIMIT_RTPRIO of virt-handler. Without this
+// option the child inherits the policy of virt-handler.
+func WithSchedPolicy(policy SchedPolicy, priority int) Option {
+	return func(ce *ContextExecutor) {
+		ce.schedPolicy = &schedPolicy{policy: policy, priority: priority}
+	}
+}
+
+func (p *schedPolicy) validate() error {
+	if p.policy != SchedFIFO && p.policy != SchedRR {
+		return fmt.Errorf("unsupported %s, only SCHED_FIFO and SCHED_RR are supported", p.policy)
+	}
+	minPriority, err := schedPriorityBound(unix.SYS_SCHED_GET_PRIORITY_MIN, p.policy)
+	if err != nil {
+		return err
+	}
+	maxPriority, err := schedPriorityBound(unix.SYS_SCHED_GET_PRIORITY_MAX, p.policy)
+	if err != nil {
+		return err
+	}
+	if p.priority < minPriority || p.priority > maxPriority {
+		return fmt.Errorf("%s priority %d is out of range [%d, %d]", p.policy, p.priority, minPriority, maxPriority)
+	}
+
+	caps, err := effectiveCapabilities()
+	if err != nil {
+		return fmt.Errorf("failed to check the privileges to change the scheduling policy of the command: %v", err)
+	}
+	if caps&(1<<capSysNice) != 0 {
+		return nil
+	}
+	limit, err := getRTPrioLimit()
+	if err != nil {
+		return fmt.Errorf("failed to read the realtime priority limit of virt-handler: %v", err)
+	}
+	if uint64(p.priority) > limit {
+		return fmt.Errorf("running commands with the %s priority %d requires the CAP_SYS_NICE capability or a RLIMIT_RTPRIO of at least %d", p.policy, p.priority, p.priority)
+	}
+	return nil
+}
+
+func schedPriorityBound(trap uintptr, policy SchedPolicy) (int, error) {
+	bound, _, errno := unix.Syscall(trap, uintptr(policy), 0, 0)
+	if errno != 0 {
+		return 0, fmt.Errorf("failed to read the priority range of %s: %v", policy, errno)
+	}
+	return int(bound), nil
+}
+
+// applyToThread sets the scheduling policy of the calling OS thread, which
+// has to be locked, since the policy is per thread.
+func (p *schedPolicy) applyToThread() error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	param := schedParam{priority: int32(p.priority)}
+	if _, _, errno := unix.Syscall(unix.SYS_SCHED_SETSCHEDULER, uintptr(unix.Gettid()), uintptr(p.policy), uintptr(unsafe.Pointer(&param))); errno != 0 {
+		return fmt.Errorf("failed to set the %s priority %d: %v", p.policy, p.priority, errno)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Running commands with a scheduling policy", func() {
+	var orgEffectiveCapabilities = effectiveCapabilities
+	var orgGetRTPrioLimit = getRTPrioLimit
+
+	// the stat fields of a task, counted from its state after the command
+	// name which may contain spaces
+	const (
+		niceField       = 16
+		rtPriorityField = 37
+		policyField     = 38
+	)
+
+	statField := func(stat string, field int) int {
+		fields := strings.Fields(stat[strings.LastIndex(stat, ")")+1:])
+		value, err := strconv.Atoi(fields[field])
+		Expect(err).ToNot(HaveOccurred())
+		return value
+	}
+
+	childStat := func(options ...Option) string {
+		ce := &ContextExecutor{pid: 1, cmdToExecute: exec.Command("cat", "/proc/self/stat")}
+		for _, option := range options {
+			option(ce)
+		}
+		stdout, _, err := ce.ExecuteWithOutput()
+		Expect(err).ToNot(HaveOccurred())
+		return stdout.String()
+	}
+
+	ownStat := func() string {
+		stat, err := ioutil.ReadFile("/proc/self/stat")
+		Expect(err).ToNot(HaveOccurred())
+		return string(stat)
+	}
+
+	skipWithoutRealtimePrivileges := func() {
+		caps, err := readEffectiveCapabilities()
+		Expect(err).ToNot(HaveOccurred())
+		if caps&(1<<capSysNice) == 0 {
+			Skip("realtime scheduling policies require CAP_SYS_NICE")
+		}
+	}
+
+	AfterEach(func() {
+		effectiveCapabilities = orgEffectiveCapabilities
+		getRTPrioLimit = orgGetRTPrioLimit
+	})
+
+	table.DescribeTable("should run the child with", func(policy SchedPolicy, priority int) {
+		skipWithoutRealtimePrivileges()
+		stat := childStat(WithSchedPolicy(policy, priority))
+		Expect(statField(stat, policyField)).To(Equal(int(policy)))
+		Expect(statField(stat, rtPriorityField)).To(Equal(priority))
+	},
+		table.Entry("SCHED_FIFO", SchedFIFO, 10),
+		table.Entry("SCHED_RR", SchedRR, 1),
+	)
+
+	It("should compose with the nice value", func() {
+		skipWithoutRealtimePrivileges()
+		stat := childStat(WithPriority(5, IOPrio{}), WithSchedPolicy(SchedRR, 20))
+		Expect(statField(stat, policyField)).To(Equal(int(SchedRR)))
+		Expect(statField(stat, rtPriorityField)).To(Equal(20))
+		Expect(statField(stat, niceField)).To(Equal(5))
+	})
+
+	It("should leave the scheduling policy of virt-handler untouched", func() {
+		skipWithoutRealtimePrivileges()
+		before := statField(ownStat(), policyField)
+		childStat(WithSchedPolicy(SchedFIFO, 10))
+		Expect(statField(ownStat(), policyField)).To(Equal(before))
+	})
+
+	It("should keep the inherited scheduling policy by default", func() {
+		stat := childStat()
+		Expect(statField(stat, policyField)).To(Equal(statField(ownStat(), policyField)))
+		Expect(statField(stat, rtPriorityField)).To(Equal(statField(ownStat(), rtPriorityField)))
+	})
+
+	table.DescribeTable("should reject", func(policy SchedPolicy, priority int, message string) {
+		ce := &ContextExecutor{pid: 1, cmdToExecute: exec.Command("true")}
+		WithSchedPolicy(policy, priority)(ce)
+		Expect(ce.Execute()).To(MatchError(message))
+	},
+		table.Entry("a non realtime policy", SchedPolicy(0), 0, "unsupported scheduling policy 0, only SCHED_FIFO and SCHED_RR are supported"),
+		table.Entry("a priority below the range", SchedFIFO, 0, "SCHED_FIFO priority 0 is out of range [1, 99]"),
+		table.Entry("a priority above the range", SchedRR, 100, "SCHED_RR priority 100 is out of range [1, 99]"),
+	)
+
+	Context("without CAP_SYS_NICE", func() {
+		BeforeEach(func() {
+			effectiveCapabilities = func() (uint64, error) {
+				return 0, nil
+			}
+		})
+
+		It("should refuse priorities above the RLIMIT_RTPRIO", func() {
+			getRTPrioLimit = func() (uint64, error) {
+				return 5, nil
+			}
+			ce := &ContextExecutor{pid: 1, cmdToExecute: exec.Command("true")}
+			WithSchedPolicy(SchedFIFO, 10)(ce)
+			Expect(ce.Execute()).To(MatchError("running commands with the SCHED_FIFO priority 10 requires the CAP_SYS_NICE capability or a RLIMIT_RTPRIO of at least 10"))
+		})
+
+		It("should allow priorities within the RLIMIT_RTPRIO", func() {
+			getRTPrioLimit = func() (uint64, error) {
+				return 10, nil
+			}
+			Expect((&schedPolicy{policy: SchedRR, priority: 10}).validate()).To(Succeed())
+		})
+	})
+})
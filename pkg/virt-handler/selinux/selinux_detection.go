@@ -0,0 +1,121 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"kubevirt.io/client-go/log"
+)
+
+// unsupportedExecLabelThreshold is the number of consecutive context switches
+// failing with ENOTSUP after which SELinux is detected again.
+const unsupportedExecLabelThreshold = 3
+
+var (
+	selinuxDetectionLock  sync.Mutex
+	selinuxDetectedAt     time.Time
+	selinuxDetected       bool
+	selinuxDetectionError error
+	detectSELinux         = NewSELinux
+
+	// selinuxRedetectionInterval is how long a detected SELinux enablement is
+	// trusted, so that SELinux being disabled while virt-handler runs is
+	// eventually noticed. Zero trusts it forever.
+	selinuxRedetectionInterval = 5 * time.Minute
+
+	// unsupportedExecLabels counts the consecutive context switches failing
+	// with ENOTSUP.
+	unsupportedExecLabels int32
+)
+
+// isSELinuxEnabled detects SELinux on first use and again once the last
+// detection is older than selinuxRedetectionInterval, or after repeated
+// context switches failed as unsupported. Executors skip the label switch
+// while it reports SELinux as disabled.
+func isSELinuxEnabled() bool {
+	selinuxDetectionLock.Lock()
+	defer selinuxDetectionLock.Unlock()
+	if selinuxDetectedAt.IsZero() || (selinuxRedetectionInterval > 0 && time.Since(selinuxDetectedAt) >= selinuxRedetectionInterval) {
+		detectSELinuxLocked()
+	}
+	return selinuxDetectionError == nil && selinuxDetected
+}
+
+// IsSELinuxEnabled reports whether SELinux is enabled on the node.
+func IsSELinuxEnabled() bool {
+	return isSELinuxEnabled()
+}
+
+// redetectSELinux detects SELinux again, regardless of the age of the last
+// detection.
+func redetectSELinux() {
+	selinuxDetectionLock.Lock()
+	defer selinuxDetectionLock.Unlock()
+	detectSELinuxLocked()
+}
+
+func detectSELinuxLocked() {
+	wasDetected := !selinuxDetectedAt.IsZero()
+	wasEnabled := selinuxDetectionError == nil && selinuxDetected
+	_, selinuxDetected, selinuxDetectionError = detectSELinux()
+	selinuxDetectedAt = time.Now()
+
+	enabled := selinuxDetectionError == nil && selinuxDetected
+	if !wasDetected || enabled == wasEnabled {
+		return
+	}
+	if enabled {
+		log.Logger(logComponent).Info("selinux was enabled on the node, the commands run in the launchers switch their selinux context again")
+		return
+	}
+	log.Logger(logComponent).Reason(selinuxDetectionError).Warning("selinux was disabled on the node, the commands run in the launchers no longer switch their selinux context")
+}
+
+// observeExecLabelSupport detects SELinux again once unsupportedExecLabelThreshold
+// context switches in a row failed with ENOTSUP, as they do once SELinux got
+// disabled on the node, so that the following executions skip the switch.
+func observeExecLabelSupport(err error) {
+	if !errors.Is(err, syscall.ENOTSUP) {
+		atomic.StoreInt32(&unsupportedExecLabels, 0)
+		return
+	}
+	if atomic.AddInt32(&unsupportedExecLabels, 1) < unsupportedExecLabelThreshold {
+		return
+	}
+	atomic.StoreInt32(&unsupportedExecLabels, 0)
+	log.Logger(logComponent).Reason(err).Warningf("%d selinux context switches in a row were not supported, detecting selinux again", unsupportedExecLabelThreshold)
+	redetectSELinux()
+}
+
+// ResetSELinuxDetectionForTest clears the cached SELinux detection result, so
+// that the next executor detects it again.
+func ResetSELinuxDetectionForTest() {
+	selinuxDetectionLock.Lock()
+	defer selinuxDetectionLock.Unlock()
+	selinuxDetectedAt = time.Time{}
+	selinuxDetected = false
+	selinuxDetectionError = nil
+	atomic.StoreInt32(&unsupportedExecLabels, 0)
+}
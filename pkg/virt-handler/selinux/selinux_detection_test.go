@@ -0,0 +1,129 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+var _ = Describe("SELinux detection", func() {
+	const launcherPID = 1234
+
+	var manager *testutils.FakeLabelManager
+	var restoreProcRoot func()
+	var selinuxEnabled bool
+	var detections int
+
+	BeforeEach(func() {
+		restoreProcRoot = fakeProcRoot(launcherPID)
+		manager = testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(launcherPID, testLauncherLabel)
+		manager.SetProcessLabel(os.Getpid(), testOriginalLabel)
+		selinuxEnabled = true
+		detections = 0
+		detectSELinux = func() (SELinux, bool, error) {
+			detections++
+			return nil, selinuxEnabled, nil
+		}
+		ResetSELinuxDetectionForTest()
+	})
+
+	AfterEach(func() {
+		restoreProcRoot()
+		detectSELinux = NewSELinux
+		selinuxRedetectionInterval = 5 * time.Minute
+		ResetSELinuxDetectionForTest()
+	})
+
+	It("should trust the detected enablement until the redetection interval elapsed", func() {
+		Expect(isSELinuxEnabled()).To(BeTrue())
+		selinuxEnabled = false
+		Expect(isSELinuxEnabled()).To(BeTrue())
+		Expect(detections).To(Equal(1))
+
+		selinuxRedetectionInterval = 10 * time.Millisecond
+		Eventually(isSELinuxEnabled).Should(BeFalse())
+	})
+
+	It("should detect selinux again after repeated unsupported context switches", func() {
+		dir, err := ioutil.TempDir("", "kubevirt-selinux-detection")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		marker := filepath.Join(dir, "marker")
+		ce, err := NewContextExecutor(launcherPID, exec.Command("touch", marker), WithLabelManager(manager))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(isSELinuxEnabled()).To(BeTrue())
+
+		By("disabling selinux while virt-handler runs")
+		selinuxEnabled = false
+		manager.FailSetExecLabel(syscall.ENOTSUP)
+		for i := 0; i < unsupportedExecLabelThreshold; i++ {
+			Expect(IsSELinuxError(ce.Execute())).To(BeTrue())
+		}
+		Expect(marker).ToNot(BeAnExistingFile())
+		Expect(detections).To(Equal(2))
+
+		By("running the next command without switching the selinux context")
+		Expect(ce.Execute()).To(Succeed())
+		Expect(marker).To(BeAnExistingFile())
+		Expect(manager.ExecLabels()).To(BeEmpty())
+	})
+
+	It("should not detect selinux again after occasional unsupported context switches", func() {
+		ce, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager))
+		Expect(err).ToNot(HaveOccurred())
+
+		for i := 0; i < 2*unsupportedExecLabelThreshold; i++ {
+			if i%unsupportedExecLabelThreshold == 0 {
+				manager.FailSetExecLabel(nil)
+				Expect(ce.Execute()).To(Succeed())
+				continue
+			}
+			manager.FailSetExecLabel(syscall.ENOTSUP)
+			Expect(ce.Execute()).ToNot(Succeed())
+		}
+		Expect(detections).To(Equal(1))
+	})
+
+	It("should keep switching the selinux context if selinux is still enabled", func() {
+		ce, err := NewContextExecutor(launcherPID, exec.Command("true"), WithLabelManager(manager))
+		Expect(err).ToNot(HaveOccurred())
+
+		manager.FailSetExecLabel(syscall.ENOTSUP)
+		for i := 0; i < unsupportedExecLabelThreshold; i++ {
+			Expect(ce.Execute()).ToNot(Succeed())
+		}
+		Expect(detections).To(Equal(2))
+
+		manager.FailSetExecLabel(nil)
+		Expect(ce.Execute()).To(Succeed())
+		Expect(manager.ExecLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+	})
+})
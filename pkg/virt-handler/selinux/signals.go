@@ -0,0 +1,109 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+var (
+	notifyTermination = func(signals chan<- os.Signal) {
+		signal.Notify(signals, syscall.SIGTERM)
+	}
+	stopTermination = func(signals chan<- os.Signal) {
+		signal.Stop(signals)
+	}
+)
+
+// WithSignalForwarding makes the executor relay SIGTERM, received by
+// virt-handler while a command runs, to the child. The child is killed if it
+// still runs gracePeriod later. The signal keeps being delivered to the other
+// virt-handler handlers. SIGINT isn't relayed: virt-handler doesn't handle it,
+// and notifying it would keep it from terminating virt-handler.
+func WithSignalForwarding(gracePeriod time.Duration) Option {
+	return func(ce *ContextExecutor) {
+		ce.forwardSignals = true
+		ce.terminationGracePeriod = gracePeriod
+	}
+}
+
+// WithNewSession runs the child as the leader of a new session, so that it and
+// the processes it spawns form their own process group and don't receive the
+// signals meant for virt-handler. The forwarded signals are sent to the whole
+// group.
+func WithNewSession() Option {
+	return func(ce *ContextExecutor) {
+		ce.newSession = true
+	}
+}
+
+func (ce ContextExecutor) applySession(cmd *exec.Cmd) {
+	if !ce.newSession {
+		return
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setsid = true
+}
+
+// signalChild sends sig to the child, or to its whole process group if it
+// leads a new session.
+func (ce ContextExecutor) signalChild(cmd *exec.Cmd, sig syscall.Signal) {
+	if ce.newSession {
+		// the child is the leader, the group id is its pid
+		syscall.Kill(-cmd.Process.Pid, sig)
+		return
+	}
+	cmd.Process.Signal(sig)
+}
+
+// watchTermination returns the channel the SIGTERM of virt-handler is
+// delivered to while the child runs, nil if it is not forwarded, and the
+// function to stop watching it.
+func (ce ContextExecutor) watchTermination() (<-chan os.Signal, func()) {
+	if !ce.forwardSignals {
+		return nil, func() {}
+	}
+	signals := make(chan os.Signal, 1)
+	notifyTermination(signals)
+	return signals, func() {
+		stopTermination(signals)
+	}
+}
+
+// terminate asks the child to stop with SIGTERM, and kills it if it didn't
+// within the grace period.
+func (ce ContextExecutor) terminate(cmd *exec.Cmd, sig os.Signal, waitDone <-chan error) error {
+	ce.getLogger().Infof("forwarding %v to the command running in launcher namespace %d", sig, ce.pid)
+	ce.signalChild(cmd, syscall.SIGTERM)
+	select {
+	case err := <-waitDone:
+		return err
+	case <-time.After(ce.terminationGracePeriod):
+		ce.getLogger().Warningf("killing the command running in launcher namespace %d after a grace period of %v", ce.pid, ce.terminationGracePeriod)
+		ce.signalChild(cmd, syscall.SIGKILL)
+		return <-waitDone
+	}
+}
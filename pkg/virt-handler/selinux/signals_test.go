@@ -0,0 +1,212 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+var _ = Describe("Forwarding termination signals", func() {
+	var (
+		orgNotifyTermination = notifyTermination
+		orgStopTermination   = stopTermination
+		watched              chan chan<- os.Signal
+		stopped              chan chan<- os.Signal
+		manager              *testutils.FakeLabelManager
+		tempDir              string
+	)
+
+	BeforeEach(func() {
+		// the real signals would interrupt the test suite itself
+		watched = make(chan chan<- os.Signal, 1)
+		stopped = make(chan chan<- os.Signal, 1)
+		notifyTermination = func(signals chan<- os.Signal) {
+			watched <- signals
+		}
+		stopTermination = func(signals chan<- os.Signal) {
+			stopped <- signals
+		}
+
+		manager = testutils.NewFakeLabelManager()
+		manager.SetProcessLabel(1, testLauncherLabel)
+		manager.SetProcessLabel(os.Getpid(), testOriginalLabel)
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, true, nil
+		}
+		ResetSELinuxDetectionForTest()
+
+		var err error
+		tempDir, err = ioutil.TempDir("", "kubevirt-signals")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		notifyTermination = orgNotifyTermination
+		stopTermination = orgStopTermination
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+		os.RemoveAll(tempDir)
+	})
+
+	// startChild runs script through an executor forwarding the signals, and
+	// returns once the child is running
+	startChild := func(script string, gracePeriod time.Duration, options ...Option) (*exec.Cmd, chan<- os.Signal, <-chan error) {
+		ready := filepath.Join(tempDir, "ready")
+		cmd := exec.Command("sh", "-c", fmt.Sprintf("%s; touch %s; exec sleep 30", script, ready))
+		options = append([]Option{WithLabelManager(manager), WithSignalForwarding(gracePeriod)}, options...)
+		ce, err := NewContextExecutor(1, cmd, options...)
+		Expect(err).ToNot(HaveOccurred())
+
+		done := make(chan error, 1)
+		go func() {
+			done <- ce.Execute()
+		}()
+		var signals chan<- os.Signal
+		Eventually(watched).Should(Receive(&signals))
+		Eventually(func() error {
+			_, err := os.Stat(ready)
+			return err
+		}, 5*time.Second).Should(Succeed())
+		return cmd, signals, done
+	}
+
+	It("should terminate the child and restore the thread context", func() {
+		cmd, signals, done := startChild("true", time.Minute)
+
+		signals <- syscall.SIGTERM
+		var err error
+		Eventually(done, 5*time.Second).Should(Receive(&err))
+		Expect(err).To(HaveOccurred())
+		Expect(cmd.ProcessState.Sys().(syscall.WaitStatus).Signal()).To(Equal(syscall.SIGTERM))
+		Expect(manager.ExecLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+		Expect(stopped).To(Receive(Equal(signals)))
+	})
+
+	It("should kill the child if it ignores the termination after the grace period", func() {
+		cmd, signals, done := startChild("trap '' TERM", 100*time.Millisecond)
+
+		signals <- syscall.SIGTERM
+		var err error
+		Eventually(done, 5*time.Second).Should(Receive(&err))
+		Expect(err).To(HaveOccurred())
+		Expect(cmd.ProcessState.Sys().(syscall.WaitStatus).Signal()).To(Equal(syscall.SIGKILL))
+		Expect(manager.ExecLabels()).To(Equal([]string{testLauncherLabel, testOriginalLabel}))
+	})
+
+	It("should forward the termination to the process group of a child in a new session", func() {
+		grandchildPIDFile := filepath.Join(tempDir, "grandchild")
+		cmd, signals, done := startChild(fmt.Sprintf("sleep 30 & echo $! > %s", grandchildPIDFile), time.Minute, WithNewSession())
+		content, err := ioutil.ReadFile(grandchildPIDFile)
+		Expect(err).ToNot(HaveOccurred())
+		grandchildPID, err := strconv.Atoi(strings.TrimSpace(string(content)))
+		Expect(err).ToNot(HaveOccurred())
+
+		signals <- syscall.SIGTERM
+		Eventually(done, 5*time.Second).Should(Receive(HaveOccurred()))
+		Expect(cmd.ProcessState.Sys().(syscall.WaitStatus).Signal()).To(Equal(syscall.SIGTERM))
+		Eventually(func() bool {
+			// the orphaned grandchild is gone or waits to be reaped by init
+			state, err := processState(grandchildPID)
+			return err != nil || state == "Z"
+		}, 5*time.Second).Should(BeTrue())
+	})
+
+	It("should not watch the signals unless requested", func() {
+		ce := ContextExecutor{pid: 1, desiredLabel: testLauncherLabel, originalLabel: testOriginalLabel, cmdToExecute: exec.Command("true")}
+		Expect(ce.Execute()).To(Succeed())
+		Expect(watched).ToNot(Receive())
+	})
+})
+
+var _ = Describe("Running the child in a new session", func() {
+
+	// childProcessGroup returns the pid and the process group id of the
+	// executed child
+	childProcessGroup := func(options ...Option) (int, int) {
+		cmd := exec.Command("sh", "-c", "exec cat /proc/self/stat")
+		ce := ContextExecutor{pid: 1, cmdToExecute: cmd}
+		for _, option := range options {
+			option(&ce)
+		}
+		stdout, _, err := ce.ExecuteWithOutput()
+		Expect(err).ToNot(HaveOccurred())
+		pgid, err := processGroupFromStat(stdout.String())
+		Expect(err).ToNot(HaveOccurred())
+		return cmd.Process.Pid, pgid
+	}
+
+	It("should make the child lead its own process group", func() {
+		pid, pgid := childProcessGroup(WithNewSession())
+		Expect(pgid).To(Equal(pid))
+		Expect(pgid).ToNot(Equal(syscall.Getpgrp()))
+	})
+
+	It("should keep the child in the process group of virt-handler by default", func() {
+		_, pgid := childProcessGroup()
+		Expect(pgid).To(Equal(syscall.Getpgrp()))
+	})
+})
+
+// statFields returns the fields of /proc/<pid>/stat following the command
+// name, starting with the state
+func statFields(stat string) ([]string, error) {
+	end := strings.LastIndex(stat, ")")
+	if end < 0 {
+		return nil, fmt.Errorf("invalid stat %q", stat)
+	}
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("invalid stat %q", stat)
+	}
+	return fields, nil
+}
+
+func processGroupFromStat(stat string) (int, error) {
+	fields, err := statFields(stat)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(fields[2])
+}
+
+func processState(pid int) (string, error) {
+	stat, err := ioutil.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return "", err
+	}
+	fields, err := statFields(string(stat))
+	if err != nil {
+		return "", err
+	}
+	return fields[0], nil
+}
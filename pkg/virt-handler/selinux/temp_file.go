@@ -0,0 +1,69 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// CreateLabeledTemp creates a temporary file in dir like ioutil.TempFile, and
+// applies label to it before returning it, so that the children it is meant
+// for can use it right away. The returned function closes and removes the
+// file.
+func CreateLabeledTemp(dir, pattern string, label string) (*os.File, func() error, error) {
+	return createLabeledTemp(defaultLabelManager, dir, pattern, label)
+}
+
+// CreateLabeledTemp creates a temporary file like the package level
+// CreateLabeledTemp, labeled for the launcher: label defaults to the label
+// RelabelFiles applies when empty.
+func (ce ContextExecutor) CreateLabeledTemp(dir, pattern string, label string) (*os.File, func() error, error) {
+	if label == "" {
+		label = ce.getFileLabel()
+	}
+	return createLabeledTemp(ce.getLabelManager(), dir, pattern, label)
+}
+
+func createLabeledTemp(manager fileLabelManager, dir, pattern string, label string) (*os.File, func() error, error) {
+	if label == "" {
+		return nil, nil, fmt.Errorf("no selinux label to apply to the temporary file")
+	}
+	if err := validateLabel(label); err != nil {
+		return nil, nil, err
+	}
+	file, err := ioutil.TempFile(dir, pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() error {
+		closeErr := file.Close()
+		if err := os.Remove(file.Name()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return closeErr
+	}
+	if err := manager.SetFileLabel(file.Name(), label); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to label %s to %s: %v", file.Name(), label, err)
+	}
+	return file, cleanup, nil
+}
@@ -0,0 +1,102 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/selinux/go-selinux"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+var _ = Describe("Creating labeled temporary files", func() {
+
+	var tempDir string
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "kubevirt-temp")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("should create the file with the requested label", func() {
+		skipIfLabelsCantBeStored(tempDir)
+
+		file, cleanup, err := CreateLabeledTemp(tempDir, "config-*.xml", testLauncherLabel)
+		Expect(err).ToNot(HaveOccurred())
+		defer cleanup()
+		Expect(selinux.FileLabel(file.Name())).To(Equal(testLauncherLabel))
+	})
+
+	It("should default to the label of the launcher through the executor", func() {
+		manager := testutils.NewFakeLabelManager()
+		ce := ContextExecutor{desiredLabel: testLauncherLabel}
+		WithLabelManager(manager)(&ce)
+
+		file, cleanup, err := ce.CreateLabeledTemp(tempDir, "config-*.xml", "")
+		Expect(err).ToNot(HaveOccurred())
+		defer cleanup()
+		Expect(manager.FileLabel(file.Name())).To(Equal(testLauncherFileLabel))
+	})
+
+	It("should apply the shared MCS label through the executor", func() {
+		manager := testutils.NewFakeLabelManager()
+		ce := ContextExecutor{desiredLabel: testLauncherLabel, fileLabel: "system_u:system_r:container_t:s0:c1.c3,c9"}
+		WithLabelManager(manager)(&ce)
+
+		file, cleanup, err := ce.CreateLabeledTemp(tempDir, "config-*.xml", "")
+		Expect(err).ToNot(HaveOccurred())
+		defer cleanup()
+		Expect(manager.FileLabel(file.Name())).To(Equal("system_u:object_r:container_file_t:s0:c1.c3,c9"))
+	})
+
+	It("should remove the file on cleanup", func() {
+		manager := testutils.NewFakeLabelManager()
+		ce := ContextExecutor{desiredLabel: testLauncherLabel}
+		WithLabelManager(manager)(&ce)
+
+		file, cleanup, err := ce.CreateLabeledTemp(tempDir, "config-*.xml", "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cleanup()).To(Succeed())
+		_, err = os.Stat(file.Name())
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("should refuse to create an unlabeled file", func() {
+		_, _, err := ContextExecutor{}.CreateLabeledTemp(tempDir, "config-*.xml", "")
+		Expect(err).To(HaveOccurred())
+		files, err := ioutil.ReadDir(tempDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(BeEmpty())
+	})
+
+	It("should reject a malformed label", func() {
+		_, _, err := CreateLabeledTemp(tempDir, "config-*.xml", "container_t")
+		Expect(err).To(MatchError(ContainSubstring("malformed selinux label")))
+	})
+})
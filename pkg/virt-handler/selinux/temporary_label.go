@@ -0,0 +1,85 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// WithTemporaryLabel applies label to each of the paths, runs fn and puts
+// back the labels the paths had before, also when fn fails or panics. The
+// labels of all paths are read before any of them is changed: if one can't be
+// read, or one of the relabels fails, the paths are left with their prior
+// labels and fn is not run. The errors of fn and of the restore are returned
+// together; fn runs on unchanged paths without selinux.
+func WithTemporaryLabel(paths []string, label string, fn func() error) error {
+	return withTemporaryLabelWith(nil, paths, label, fn)
+}
+
+// withTemporaryLabelWith is WithTemporaryLabel applying the labels through
+// manager, or through the selinux of the host if nil.
+func withTemporaryLabelWith(manager LabelManager, paths []string, label string, fn func() error) (err error) {
+	if !isSELinuxEnabled() {
+		return fn()
+	}
+	if label == "" {
+		return fmt.Errorf("the paths can't be temporarily relabeled to an empty selinux label")
+	}
+	if err := validateLabel(label); err != nil {
+		return err
+	}
+	ce := ContextExecutor{labelManager: manager}
+	fileManager := ce.getLabelManager()
+
+	previousLabels := make([]pathLabel, 0, len(paths))
+	for _, path := range paths {
+		previousLabel, err := fileManager.FileLabel(path)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve the selinux label of %s: %v", path, err)
+		}
+		previousLabels = append(previousLabels, pathLabel{path: path, label: previousLabel})
+	}
+
+	var relabeled []pathLabel
+	defer func() {
+		r := recover()
+		restoreErr := ce.restoreFileLabels(relabeled)
+		if r != nil {
+			if restoreErr != nil {
+				ce.getLogger().Reason(restoreErr).Error("failed to restore the selinux labels after a panic")
+			}
+			panic(r)
+		}
+		err = utilerrors.NewAggregate([]error{err, restoreErr})
+	}()
+	for _, previous := range previousLabels {
+		if previous.label == label {
+			continue
+		}
+		if err := fileManager.SetFileLabel(previous.path, label); err != nil {
+			return fmt.Errorf("failed to relabel %s to %s: %v", previous.path, label, err)
+		}
+		ce.getLogger().V(debugVerbosity).Infof("temporarily relabeled %s from %s to %s", previous.path, previous.label, label)
+		relabeled = append(relabeled, previous)
+	}
+	return fn()
+}
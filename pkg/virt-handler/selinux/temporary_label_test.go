@@ -0,0 +1,136 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
+)
+
+// readOnlyLabelManager fails to relabel readOnlyPath.
+type readOnlyLabelManager struct {
+	*testutils.FakeLabelManager
+	readOnlyPath string
+}
+
+func (m readOnlyLabelManager) SetFileLabel(path string, label string) error {
+	if path == m.readOnlyPath {
+		return fmt.Errorf("read-only file system")
+	}
+	return m.FakeLabelManager.SetFileLabel(path, label)
+}
+
+var _ = Describe("Temporary label", func() {
+	const launcherLabel = "system_u:system_r:container_t:s0:c1,c2"
+	const diskLabel = "system_u:object_r:container_file_t:s0"
+	const deviceLabel = "system_u:object_r:vfio_device_t:s0"
+
+	var manager *testutils.FakeLabelManager
+	var paths []string
+
+	expectPriorLabels := func() {
+		Expect(manager.FileLabel("/var/run/disk.img")).To(Equal(diskLabel))
+		Expect(manager.FileLabel("/dev/vfio/1")).To(Equal(deviceLabel))
+	}
+
+	BeforeEach(func() {
+		manager = testutils.NewFakeLabelManager()
+		Expect(manager.SetFileLabel("/var/run/disk.img", diskLabel)).To(Succeed())
+		Expect(manager.SetFileLabel("/dev/vfio/1", deviceLabel)).To(Succeed())
+		paths = []string{"/var/run/disk.img", "/dev/vfio/1"}
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, true, nil
+		}
+		ResetSELinuxDetectionForTest()
+	})
+
+	AfterEach(func() {
+		detectSELinux = NewSELinux
+		ResetSELinuxDetectionForTest()
+	})
+
+	It("should run fn with the paths relabeled and restore their labels", func() {
+		ran := false
+		err := withTemporaryLabelWith(manager, paths, launcherLabel, func() error {
+			ran = true
+			Expect(manager.FileLabel("/var/run/disk.img")).To(Equal(launcherLabel))
+			Expect(manager.FileLabel("/dev/vfio/1")).To(Equal(launcherLabel))
+			return nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ran).To(BeTrue())
+		expectPriorLabels()
+	})
+
+	It("should restore the labels and return the error of fn", func() {
+		err := withTemporaryLabelWith(manager, paths, launcherLabel, func() error {
+			return fmt.Errorf("qemu-img failed")
+		})
+		Expect(err).To(MatchError(ContainSubstring("qemu-img failed")))
+		expectPriorLabels()
+	})
+
+	It("should restore the labels when fn panics", func() {
+		Expect(func() {
+			withTemporaryLabelWith(manager, paths, launcherLabel, func() error {
+				panic("fn panicked")
+			})
+		}).To(PanicWith("fn panicked"))
+		expectPriorLabels()
+	})
+
+	It("should not touch any path nor run fn if a label can't be read", func() {
+		ran := false
+		err := withTemporaryLabelWith(manager, append(paths, "/dev/vfio/2"), launcherLabel, func() error {
+			ran = true
+			return nil
+		})
+		Expect(err).To(MatchError(ContainSubstring("failed to retrieve the selinux label of /dev/vfio/2")))
+		Expect(ran).To(BeFalse())
+		expectPriorLabels()
+	})
+
+	It("should restore the relabeled paths and not run fn if a relabel fails", func() {
+		ran := false
+		err := withTemporaryLabelWith(readOnlyLabelManager{FakeLabelManager: manager, readOnlyPath: "/dev/vfio/1"}, paths, launcherLabel, func() error {
+			ran = true
+			return nil
+		})
+		Expect(err).To(MatchError(ContainSubstring("failed to relabel /dev/vfio/1")))
+		Expect(ran).To(BeFalse())
+		expectPriorLabels()
+	})
+
+	It("should only run fn without selinux", func() {
+		detectSELinux = func() (SELinux, bool, error) {
+			return nil, false, nil
+		}
+		ResetSELinuxDetectionForTest()
+		err := withTemporaryLabelWith(manager, paths, launcherLabel, func() error {
+			expectPriorLabels()
+			return nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
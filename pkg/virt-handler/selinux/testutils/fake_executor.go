@@ -0,0 +1,112 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package testutils
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+)
+
+// ExecutedCommand is a command a FakeExecutor would have run in the context
+// of the launcher PID.
+type ExecutedCommand struct {
+	PID  int
+	Path string
+	Args []string
+}
+
+// FakeCommandRecorder creates FakeExecutors, recording the commands they would
+// have run instead of executing them.
+type FakeCommandRecorder struct {
+	lock       sync.Mutex
+	executed   []ExecutedCommand
+	executeErr error
+	closed     int
+}
+
+func NewFakeCommandRecorder() *FakeCommandRecorder {
+	return &FakeCommandRecorder{}
+}
+
+// NewExecutor returns a FakeExecutor of cmd in the context of pid, a drop-in
+// replacement of selinux.NewContextExecutor.
+func (r *FakeCommandRecorder) NewExecutor(pid int, cmd *exec.Cmd) *FakeExecutor {
+	return &FakeExecutor{recorder: r, pid: pid, cmd: cmd}
+}
+
+// SetExecuteError makes the executors return err after recording the command.
+func (r *FakeCommandRecorder) SetExecuteError(err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.executeErr = err
+}
+
+// ExecutedCommands returns the commands executed so far, in order.
+func (r *FakeCommandRecorder) ExecutedCommands() []ExecutedCommand {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]ExecutedCommand{}, r.executed...)
+}
+
+// ClosedExecutors returns how many times the executors were closed.
+func (r *FakeCommandRecorder) ClosedExecutors() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.closed
+}
+
+func (r *FakeCommandRecorder) record(pid int, cmd *exec.Cmd) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	executed := ExecutedCommand{PID: pid}
+	if cmd != nil {
+		executed.Path = cmd.Path
+		executed.Args = append([]string{}, cmd.Args...)
+	}
+	r.executed = append(r.executed, executed)
+	return r.executeErr
+}
+
+// FakeExecutor implements selinux.Executor without executing anything.
+type FakeExecutor struct {
+	recorder *FakeCommandRecorder
+	pid      int
+	cmd      *exec.Cmd
+}
+
+func (e *FakeExecutor) Execute() error {
+	return e.ExecuteContext(context.Background())
+}
+
+// ExecuteContext records the command, unless ctx is done already.
+func (e *FakeExecutor) ExecuteContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return e.recorder.record(e.pid, e.cmd)
+}
+
+func (e *FakeExecutor) Close() error {
+	e.recorder.lock.Lock()
+	defer e.recorder.lock.Unlock()
+	e.recorder.closed++
+	return nil
+}
@@ -0,0 +1,209 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package testutils
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// FakeLabelManager is a memory backed selinux LabelManager, for testing code
+// relying on selinux labels on hosts without selinux. Like on a real host, the
+// label of a process is the label of its /proc/<pid>/attr/current file.
+type FakeLabelManager struct {
+	lock            sync.Mutex
+	fileLabels      map[string]string
+	execLabels      []string
+	setExecLabelErr error
+	// deniedExecLabels fail SetExecLabel, like a policy denying the transition
+	deniedExecLabels map[string]error
+	// reportedExecLabel is read back instead of the last exec label if set
+	reportedExecLabel *string
+	// currentLabels are the labels set through SetCurrentLabel, in order
+	currentLabels []string
+	// deniedCurrentLabels fail SetCurrentLabel, like a policy denying the dyntransition
+	deniedCurrentLabels map[string]error
+	// fsCreateLabels are the labels set through SetFSCreateLabel, in order
+	fsCreateLabels []string
+}
+
+func NewFakeLabelManager() *FakeLabelManager {
+	return &FakeLabelManager{
+		fileLabels: map[string]string{},
+	}
+}
+
+// SetProcessLabel makes the process pid run with label.
+func (m *FakeLabelManager) SetProcessLabel(pid int, label string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.fileLabels[fmt.Sprintf("/proc/%d/attr/current", pid)] = label
+}
+
+// SetProcessExecLabel makes the process pid execute its programs with label,
+// as set in its /proc/<pid>/attr/exec file.
+func (m *FakeLabelManager) SetProcessExecLabel(pid int, label string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.fileLabels[fmt.Sprintf("/proc/%d/attr/exec", pid)] = label
+}
+
+func (m *FakeLabelManager) FileLabel(path string) (string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	label, exists := m.fileLabels[path]
+	if !exists {
+		return "", &os.PathError{Op: "lgetxattr", Path: path, Err: syscall.ENOENT}
+	}
+	return label, nil
+}
+
+func (m *FakeLabelManager) SetFileLabel(path string, label string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.fileLabels[path] = label
+	return nil
+}
+
+func (m *FakeLabelManager) SetExecLabel(label string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.setExecLabelErr != nil {
+		return m.setExecLabelErr
+	}
+	if err, denied := m.deniedExecLabels[label]; denied {
+		return err
+	}
+	m.execLabels = append(m.execLabels, label)
+	return nil
+}
+
+// ExecLabel returns the last exec label successfully set, unless another one
+// is reported through ReportExecLabel.
+func (m *FakeLabelManager) ExecLabel() (string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.reportedExecLabel != nil {
+		return *m.reportedExecLabel, nil
+	}
+	if len(m.execLabels) == 0 {
+		return "", nil
+	}
+	return m.execLabels[len(m.execLabels)-1], nil
+}
+
+// ReportExecLabel makes ExecLabel return label whatever was set, like a policy
+// silently ignoring SetExecLabel.
+func (m *FakeLabelManager) ReportExecLabel(label string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.reportedExecLabel = &label
+}
+
+// ExecLabels returns the exec labels successfully set so far, in order.
+func (m *FakeLabelManager) ExecLabels() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append([]string(nil), m.execLabels...)
+}
+
+// SetCurrentLabel records label as the current label of the calling thread.
+func (m *FakeLabelManager) SetCurrentLabel(label string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if err, denied := m.deniedCurrentLabels[label]; denied {
+		return err
+	}
+	m.currentLabels = append(m.currentLabels, label)
+	return nil
+}
+
+// CurrentLabel returns the last current label successfully set.
+func (m *FakeLabelManager) CurrentLabel() (string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if len(m.currentLabels) == 0 {
+		return "", nil
+	}
+	return m.currentLabels[len(m.currentLabels)-1], nil
+}
+
+// CurrentLabels returns the current labels successfully set so far, in order.
+func (m *FakeLabelManager) CurrentLabels() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append([]string(nil), m.currentLabels...)
+}
+
+// SetFSCreateLabel records label as the file creation context of the calling
+// thread, an empty label clearing it.
+func (m *FakeLabelManager) SetFSCreateLabel(label string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.fsCreateLabels = append(m.fsCreateLabels, label)
+	return nil
+}
+
+// FSCreateLabels returns the file creation contexts set so far, in order.
+func (m *FakeLabelManager) FSCreateLabels() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append([]string(nil), m.fsCreateLabels...)
+}
+
+// DenyCurrentLabel makes SetCurrentLabel fail with err for label only, like a
+// policy denying the dyntransition to it, or allows label again if err is nil.
+func (m *FakeLabelManager) DenyCurrentLabel(label string, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if err == nil {
+		delete(m.deniedCurrentLabels, label)
+		return
+	}
+	if m.deniedCurrentLabels == nil {
+		m.deniedCurrentLabels = map[string]error{}
+	}
+	m.deniedCurrentLabels[label] = err
+}
+
+// FailSetExecLabel makes SetExecLabel fail with err, or succeed again if err
+// is nil.
+func (m *FakeLabelManager) FailSetExecLabel(err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.setExecLabelErr = err
+}
+
+// DenyExecLabel makes SetExecLabel fail with err for label only, like a policy
+// denying the transition to it, or allows label again if err is nil.
+func (m *FakeLabelManager) DenyExecLabel(label string, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if err == nil {
+		delete(m.deniedExecLabels, label)
+		return
+	}
+	if m.deniedExecLabels == nil {
+		m.deniedExecLabels = map[string]error{}
+	}
+	m.deniedExecLabels[label] = err
+}
@@ -0,0 +1,44 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ExecuteWithTimeoutKillGroup runs the command like Execute, as the leader of a
+// new session, and sends SIGKILL to its whole process group if it is still
+// running after timeout. Unlike ExecuteContext, which only kills the child,
+// this also reaps the processes the child spawned, as long as they did not
+// leave the group. The error is then an *ExecuteTimeoutError wrapping
+// context.DeadlineExceeded.
+func (ce ContextExecutor) ExecuteWithTimeoutKillGroup(timeout time.Duration) error {
+	ce.newSession = true
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := ce.ExecuteContext(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &ExecuteTimeoutError{Timeout: timeout, Err: err}
+	}
+	return err
+}
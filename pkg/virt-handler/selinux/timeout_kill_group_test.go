@@ -0,0 +1,131 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Killing the process group on timeout", func() {
+
+	var tempDir string
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "kill-group")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	readPID := func(path string) int {
+		var pid int
+		Eventually(func() error {
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			pid, err = strconv.Atoi(strings.TrimSpace(string(content)))
+			return err
+		}, 5*time.Second).Should(Succeed())
+		return pid
+	}
+
+	isGone := func(pid int) func() bool {
+		return func() bool {
+			// a process orphaned by the kill may wait to be reaped by init
+			state, err := processState(pid)
+			return err != nil || state == "Z"
+		}
+	}
+
+	// forkingCmd runs a child which forks a long running grandchild and waits
+	// for it, recording both pids
+	forkingCmd := func(grandchildRedirect string) *exec.Cmd {
+		script := fmt.Sprintf("sleep 30 %s & echo $! > %s; echo $$ > %s; wait",
+			grandchildRedirect, filepath.Join(tempDir, "grandchild"), filepath.Join(tempDir, "child"))
+		return exec.Command("sh", "-c", script)
+	}
+
+	It("should kill the child and the grandchild once the timeout expired", func() {
+		ce := ContextExecutor{pid: 1, cmdToExecute: forkingCmd("")}
+
+		start := time.Now()
+		err := ce.ExecuteWithTimeoutKillGroup(500 * time.Millisecond)
+		Expect(time.Since(start)).To(BeNumerically("<", 10*time.Second))
+
+		var timeoutErr *ExecuteTimeoutError
+		Expect(errors.As(err, &timeoutErr)).To(BeTrue())
+		Expect(timeoutErr.Timeout).To(Equal(500 * time.Millisecond))
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+
+		Eventually(isGone(readPID(filepath.Join(tempDir, "child"))), 5*time.Second).Should(BeTrue())
+		Eventually(isGone(readPID(filepath.Join(tempDir, "grandchild"))), 5*time.Second).Should(BeTrue())
+	})
+
+	It("should return the result of a command finishing in time", func() {
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("sh", "-c", "exit 3")}
+		err := ce.ExecuteWithTimeoutKillGroup(time.Minute)
+		Expect(err).To(MatchError(ContainSubstring("exit status 3")))
+		var timeoutErr *ExecuteTimeoutError
+		Expect(errors.As(err, &timeoutErr)).To(BeFalse())
+
+		ce = ContextExecutor{pid: 1, cmdToExecute: exec.Command("true")}
+		Expect(ce.ExecuteWithTimeoutKillGroup(time.Minute)).To(Succeed())
+	})
+
+	It("should run the child in a new session", func() {
+		cmd := exec.Command("sh", "-c", "exec cat /proc/self/stat")
+		ce := ContextExecutor{pid: 1, cmdToExecute: cmd}
+		stdout := &strings.Builder{}
+		cmd.Stdout = stdout
+		Expect(ce.ExecuteWithTimeoutKillGroup(time.Minute)).To(Succeed())
+		pgid, err := processGroupFromStat(stdout.String())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pgid).To(Equal(cmd.Process.Pid))
+	})
+
+	It("should only kill the child with a plain context timeout", func() {
+		// the grandchild must not hold the output pipes, Wait would wait for it otherwise
+		ce := ContextExecutor{pid: 1, cmdToExecute: forkingCmd(">/dev/null 2>&1")}
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+
+		Expect(ce.ExecuteContext(ctx)).To(MatchError(context.DeadlineExceeded))
+		grandchildPID := readPID(filepath.Join(tempDir, "grandchild"))
+		defer syscall.Kill(grandchildPID, syscall.SIGKILL)
+		Expect(isGone(grandchildPID)()).To(BeFalse())
+	})
+})
@@ -0,0 +1,51 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+)
+
+// WithTypeTransition makes the executor run the child with the launcher label
+// carrying newType instead of the launcher type. User, role and level are kept,
+// so that the child stays confined to the MCS categories of the launcher.
+// Files are still relabeled with the launcher label.
+func WithTypeTransition(newType string) Option {
+	return func(ce *ContextExecutor) {
+		ce.transitionType = newType
+	}
+}
+
+// transitionLabelType returns label with its type replaced by newType. An
+// empty label, as reported without selinux, is returned unchanged.
+func transitionLabelType(label, newType string) (string, error) {
+	if !labelIdentifierRegex.MatchString(newType) {
+		return "", fmt.Errorf("invalid selinux type %q for the type transition", newType)
+	}
+	if label == "" {
+		return label, nil
+	}
+	parsed, err := ParseLabel(label)
+	if err != nil {
+		return "", err
+	}
+	parsed.Type = newType
+	return parsed.String(), nil
+}
@@ -0,0 +1,107 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Type transition", func() {
+
+	table.DescribeTable("should only replace the type of", func(label, expected string) {
+		Expect(transitionLabelType(label, "virt_launcher_child_t")).To(Equal(expected))
+	},
+		table.Entry("a label with categories",
+			"system_u:system_r:container_t:s0:c1,c2",
+			"system_u:system_r:virt_launcher_child_t:s0:c1,c2"),
+		table.Entry("a label with a level range",
+			"system_u:system_r:spc_t:s0-s0:c0.c1023",
+			"system_u:system_r:virt_launcher_child_t:s0-s0:c0.c1023"),
+		table.Entry("a label without level",
+			"unconfined_u:unconfined_r:container_t",
+			"unconfined_u:unconfined_r:virt_launcher_child_t"),
+		table.Entry("the empty label of hosts without selinux", "", ""),
+	)
+
+	table.DescribeTable("should reject", func(label, newType, expectedError string) {
+		_, err := transitionLabelType(label, newType)
+		Expect(err).To(MatchError(ContainSubstring(expectedError)))
+	},
+		table.Entry("an empty type", "system_u:system_r:container_t:s0:c1", "", "invalid selinux type"),
+		table.Entry("a type with a level", "system_u:system_r:container_t:s0:c1", "container_t:s0", "invalid selinux type"),
+		table.Entry("a type with spaces", "system_u:system_r:container_t:s0:c1", "container t", "invalid selinux type"),
+		table.Entry("a type on hosts without selinux", "", "container_t:s0", "invalid selinux type"),
+		table.Entry("a malformed label", "system_u:system_r", "container_t", "malformed selinux label"),
+	)
+
+	Context("for pids", func() {
+		var orgLabelCache *labelCache
+
+		BeforeEach(func() {
+			orgLabelCache = defaultLabelCache
+			labels := map[int]string{
+				1:           "system_u:system_r:container_t:s0:c1,c2",
+				2:           "system_u:system_r:container_t:s0:c3,c9",
+				os.Getpid(): "system_u:system_r:spc_t:s0",
+			}
+			defaultLabelCache = newLabelCache(func(pid int) (uint64, error) {
+				return 1, nil
+			}, func(pid int) (string, error) {
+				label, exists := labels[pid]
+				if !exists {
+					return "", newLabelError(pid, syscall.ENOENT)
+				}
+				return label, nil
+			})
+		})
+
+		AfterEach(func() {
+			defaultLabelCache = orgLabelCache
+		})
+
+		It("should run the child with the derived label", func() {
+			ce, err := NewContextExecutor(1, exec.Command("true"), WithTypeTransition("virt_launcher_child_t"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ce.desiredLabel).To(Equal("system_u:system_r:virt_launcher_child_t:s0:c1,c2"))
+			Expect(ce.originalLabel).To(Equal("system_u:system_r:spc_t:s0"))
+		})
+
+		It("should keep relabeling files with the launcher label", func() {
+			ce, err := NewContextExecutor(1, exec.Command("true"), WithTypeTransition("virt_launcher_child_t"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ce.getFileLabel()).To(Equal("system_u:object_r:container_file_t:s0:c1,c2"))
+
+			ce, err = NewContextExecutor(1, exec.Command("true"), WithTypeTransition("virt_launcher_child_t"), WithSharedMCS(2))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ce.getFileLabel()).To(Equal("system_u:object_r:container_file_t:s0:c1.c3,c9"))
+		})
+
+		It("should reject an invalid type", func() {
+			_, err := NewContextExecutor(1, exec.Command("true"), WithTypeTransition("container_t:s0"))
+			Expect(err).To(MatchError(ContainSubstring("invalid selinux type")))
+		})
+	})
+})
@@ -0,0 +1,55 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+const maxUmask = 0777
+
+// WithUmask runs the executed commands with the file mode creation mask,
+// instead of the one of virt-handler, so that the files they create get
+// predictable permissions. The umask is shared by all the threads of a
+// process, so the OS thread forking the child first stops sharing its
+// filesystem attributes and sets the mask on its own copy: the umask of
+// virt-handler is never changed and is left as is once the child started.
+// That thread is destroyed afterwards, like the one of WithPriority, which
+// this composes with.
+func WithUmask(mask int) Option {
+	return func(ce *ContextExecutor) {
+		ce.umask = &mask
+	}
+}
+
+// applyUmaskToThread sets the umask of the calling OS thread, which has to be
+// locked, after unsharing its filesystem attributes from the other threads.
+func applyUmaskToThread(mask int) error {
+	if mask < 0 || mask > maxUmask {
+		return fmt.Errorf("umask %#o is out of range [0, %#o]", mask, maxUmask)
+	}
+	if err := unix.Unshare(unix.CLONE_FS); err != nil {
+		return fmt.Errorf("failed to unshare the filesystem attributes of the thread to set the umask %#o: %v", mask, err)
+	}
+	unix.Umask(mask)
+	return nil
+}
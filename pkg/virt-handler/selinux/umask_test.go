@@ -0,0 +1,105 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Running commands with a umask", func() {
+	var tmpDir string
+
+	ownUmask := func() string {
+		status, err := ioutil.ReadFile("/proc/self/status")
+		Expect(err).ToNot(HaveOccurred())
+		for _, line := range strings.Split(string(status), "\n") {
+			if strings.HasPrefix(line, "Umask:") {
+				return strings.TrimSpace(strings.TrimPrefix(line, "Umask:"))
+			}
+		}
+		Skip("the kernel does not report the umask of processes")
+		return ""
+	}
+
+	execute := func(cmd *exec.Cmd, options ...Option) {
+		ce := &ContextExecutor{pid: 1, cmdToExecute: cmd}
+		for _, option := range options {
+			option(ce)
+		}
+		Expect(ce.Execute()).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "umask")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	table.DescribeTable("should create files with permissions reflecting the umask", func(mask int, expected os.FileMode) {
+		file := filepath.Join(tmpDir, "file")
+		dir := filepath.Join(tmpDir, "dir")
+		execute(exec.Command("sh", "-c", "touch "+file+" && mkdir "+dir), WithUmask(mask))
+
+		info, err := os.Stat(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(expected))
+		info, err = os.Stat(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(0777 &^ os.FileMode(mask)))
+	},
+		table.Entry("0077", 0077, os.FileMode(0600)),
+		table.Entry("0022", 0022, os.FileMode(0644)),
+		table.Entry("0", 0, os.FileMode(0666)),
+	)
+
+	It("should compose with the nice value", func() {
+		file := filepath.Join(tmpDir, "file")
+		execute(exec.Command("touch", file), WithPriority(5, IOPrio{}), WithUmask(0027))
+
+		info, err := os.Stat(file)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0640)))
+	})
+
+	It("should leave the umask of virt-handler untouched", func() {
+		before := ownUmask()
+		execute(exec.Command("true"), WithUmask(0077))
+		execute(exec.Command("true"), WithUmask(0))
+		Expect(ownUmask()).To(Equal(before))
+	})
+
+	It("should reject a umask out of range", func() {
+		ce := &ContextExecutor{pid: 1, cmdToExecute: exec.Command("true")}
+		WithUmask(01000)(ce)
+		Expect(ce.Execute()).To(MatchError("umask 01000 is out of range [0, 0777]"))
+	})
+})
@@ -0,0 +1,58 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// fileWaitPollInterval is the interval ExecuteAndWaitForFile checks for the
+// file at
+var fileWaitPollInterval = 100 * time.Millisecond
+
+// ExecuteAndWaitForFile runs the command like Execute, then waits up to
+// timeout for path to exist, for commands whose completion is signaled by a
+// file, e.g. a socket or a pid file, rather than by exiting. A
+// *FileNotCreatedError is returned if the command succeeded but path did
+// not appear in time.
+func (ce ContextExecutor) ExecuteAndWaitForFile(path string, timeout time.Duration) error {
+	if err := ce.Execute(); err != nil {
+		return err
+	}
+	if ce.dryRun {
+		return nil
+	}
+	err := wait.PollImmediate(fileWaitPollInterval, timeout, func() (bool, error) {
+		if _, err := os.Lstat(path); err != nil {
+			if os.IsNotExist(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return &FileNotCreatedError{Path: path, Timeout: timeout}
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package selinux
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Waiting for a file created by the command", func() {
+	var tempDir, path string
+	var orgPollInterval time.Duration
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "wait-for-file")
+		Expect(err).ToNot(HaveOccurred())
+		path = filepath.Join(tempDir, "launcher.pid")
+		orgPollInterval = fileWaitPollInterval
+		fileWaitPollInterval = 10 * time.Millisecond
+	})
+
+	AfterEach(func() {
+		fileWaitPollInterval = orgPollInterval
+		os.RemoveAll(tempDir)
+	})
+
+	It("should succeed once the file appears in time", func() {
+		// the command returns right away, leaving the file to a background child
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("sh", "-c", "(sleep 0.1; touch "+path+") >/dev/null 2>&1 &")}
+		Expect(ce.ExecuteAndWaitForFile(path, 5*time.Second)).To(Succeed())
+		Expect(path).To(BeAnExistingFile())
+	})
+
+	It("should succeed if the command created the file before exiting", func() {
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("touch", path)}
+		Expect(ce.ExecuteAndWaitForFile(path, 50*time.Millisecond)).To(Succeed())
+	})
+
+	It("should fail with a distinct error if the file never shows", func() {
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("true")}
+		start := time.Now()
+		err := ce.ExecuteAndWaitForFile(path, 50*time.Millisecond)
+		Expect(err).To(Equal(&FileNotCreatedError{Path: path, Timeout: 50 * time.Millisecond}))
+		Expect(err.Error()).To(ContainSubstring("the command succeeded but"))
+		Expect(time.Since(start)).To(BeNumerically(">=", 50*time.Millisecond))
+	})
+
+	It("should return the command error without waiting", func() {
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("false")}
+		start := time.Now()
+		err := ce.ExecuteAndWaitForFile(path, time.Minute)
+		Expect(err).To(HaveOccurred())
+		Expect(err).ToNot(BeAssignableToTypeOf(&FileNotCreatedError{}))
+		Expect(time.Since(start)).To(BeNumerically("<", time.Minute))
+	})
+
+	It("should not wait in dry-run mode", func() {
+		ce := ContextExecutor{pid: 1, cmdToExecute: exec.Command("touch", path), dryRun: true}
+		Expect(ce.ExecuteAndWaitForFile(path, time.Minute)).To(Succeed())
+		Expect(path).ToNot(BeAnExistingFile())
+	})
+})
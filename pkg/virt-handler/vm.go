@@ -33,6 +33,7 @@ import (
 	"reflect"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -42,6 +43,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
@@ -60,19 +62,35 @@ import (
 	diskutils "kubevirt.io/kubevirt/pkg/ephemeral-disk-utils"
 	cmdv1 "kubevirt.io/kubevirt/pkg/handler-launcher-com/cmd/v1"
 	hostdisk "kubevirt.io/kubevirt/pkg/host-disk"
+	hotplugdisk "kubevirt.io/kubevirt/pkg/hotplug-disk"
 	virtutil "kubevirt.io/kubevirt/pkg/util"
 	clusterutils "kubevirt.io/kubevirt/pkg/util/cluster"
+	"kubevirt.io/kubevirt/pkg/util/hardware"
 	pvcutils "kubevirt.io/kubevirt/pkg/util/types"
 	virtconfig "kubevirt.io/kubevirt/pkg/virt-config"
 	virtcache "kubevirt.io/kubevirt/pkg/virt-handler/cache"
 	cmdclient "kubevirt.io/kubevirt/pkg/virt-handler/cmd-client"
 	"kubevirt.io/kubevirt/pkg/virt-handler/isolation"
 	migrationproxy "kubevirt.io/kubevirt/pkg/virt-handler/migration-proxy"
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux"
 	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/api"
 	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/network"
 	"kubevirt.io/kubevirt/pkg/watchdog"
 )
 
+// hotplugVolumeLabelsReconcileInterval is the period at which the selinux labels of the hotplugged volumes are verified
+const hotplugVolumeLabelsReconcileInterval = 30 * time.Second
+
+// selinuxPolicyModuleCheckInterval is the period at which the loaded selinux policy modules are enumerated again
+const selinuxPolicyModuleCheckInterval = 10 * time.Minute
+
+const (
+	// memoryDumpCompletedReason is the reason of the event emitted once a memory dump was written to its claim
+	memoryDumpCompletedReason = "MemoryDumpCompleted"
+	// memoryDumpFailedReason is the reason of the event emitted when a memory dump failed
+	memoryDumpFailedReason = "MemoryDumpFailed"
+)
+
 type launcherClientInfo struct {
 	client              cmdclient.LauncherClient
 	socketFile          string
@@ -101,6 +119,12 @@ func NewController(
 ) *VirtualMachineController {
 
 	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	relabelParallelism := func() int {
+		return int(clusterConfig.GetSELinuxRelabelParallelism())
+	}
+	relabelSkipPaths := func() []string {
+		return clusterConfig.GetSELinuxRelabelSkipPaths()
+	}
 
 	c := &VirtualMachineController{
 		Queue:                    queue,
@@ -118,8 +142,14 @@ func NewController(
 		migrationProxy:           migrationproxy.NewMigrationProxyManager(serverTLSConfig, clientTLSConfig),
 		podIsolationDetector:     podIsolationDetector,
 		containerDiskMounter:     container_disk.NewMounter(podIsolationDetector, virtPrivateDir+"/container-disk-mount-state"),
-		hotplugVolumeMounter:     hotplug_volume.NewVolumeMounter(podIsolationDetector, virtPrivateDir+"/hotplug-volume-mount-state"),
+		hotplugVolumeMounter:     hotplug_volume.NewVolumeMounter(podIsolationDetector, virtPrivateDir+"/hotplug-volume-mount-state", relabelParallelism, relabelSkipPaths),
 		clusterConfig:            clusterConfig,
+		isSELinuxEnabled:         selinux.IsSELinuxEnabled,
+		isLauncherTypeAvailable:  selinux.IsLauncherTypeAvailable,
+		detectSELinux:            selinux.NewSELinux,
+		flushSELinuxLabelCache:   selinux.FlushLabelCache,
+		selinuxCircuitBreaker:    selinux.NewCircuitBreaker(),
+		numaNodesDir:             hardware.NUMA_NODES_PATH,
 	}
 
 	vmiSourceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -179,6 +209,27 @@ type VirtualMachineController struct {
 	containerDiskMounter     container_disk.Mounter
 	hotplugVolumeMounter     hotplug_volume.VolumeMounter
 	clusterConfig            *virtconfig.ClusterConfig
+	isSELinuxEnabled         func() bool
+	isLauncherTypeAvailable  func(selinuxType string) bool
+	detectSELinux            func() (selinux.SELinux, bool, error)
+	flushSELinuxLabelCache   func(pid int)
+	// the sysfs directory of the host NUMA nodes
+	numaNodesDir string
+	// the SELinux mode last published on the node, nil until the first publication
+	publishedSELinuxMode *string
+	// nil unless the SELinux denials of the launchers are watched
+	selinuxDenialWatcher *selinux.DenialWatcher
+	// nil unless the SELinux policy modules of the node are checked
+	selinuxPolicyModuleCheck *selinux.PolicyModuleCheck
+	// the missing SELinux policy modules last published on the node, nil until the first publication
+	publishedMissingPolicyModules *string
+	// the file persisting the last boot id of the node, empty unless reboots are detected
+	bootIDStateFile string
+	// the SELinux label mismatches last found on the devices of the VMIs with hotplugged volumes
+	selinuxLabelMismatches     map[types.UID][]selinux.LabelMismatch
+	selinuxLabelMismatchesLock sync.Mutex
+	// stops retrying the VMIs whose SELinux labels repeatedly failed to apply
+	selinuxCircuitBreaker *selinux.CircuitBreaker
 
 	// records if pod network phase1 has completed
 	// phase1 involves cycling an entire posix thread
@@ -197,10 +248,13 @@ type VirtualMachineController struct {
 
 type virtLauncherCriticalNetworkError struct {
 	msg string
+	err error
 }
 
 func (e *virtLauncherCriticalNetworkError) Error() string { return e.msg }
 
+func (e *virtLauncherCriticalNetworkError) Unwrap() error { return e.err }
+
 type virtLauncherCriticalSecurebootError struct {
 	msg string
 }
@@ -491,6 +545,19 @@ func canUpdateToUnmounted(currentPhase v1.VolumePhase) bool {
 	return currentPhase == v1.VolumeReady || currentPhase == v1.HotplugVolumeMounted || currentPhase == v1.HotplugVolumeAttachedToNode
 }
 
+// getVCPUPinning converts the vCPU pins of a domain, which are all pinned to a single dedicated CPU
+func getVCPUPinning(pins []api.CPUTuneVCPUPin) ([]v1.VCPUPin, error) {
+	pinning := make([]v1.VCPUPin, 0, len(pins))
+	for _, pin := range pins {
+		cpu, err := strconv.Atoi(pin.CPUSet)
+		if err != nil {
+			return nil, fmt.Errorf("vCPU %d is not pinned to a single CPU: %s", pin.VCPU, pin.CPUSet)
+		}
+		pinning = append(pinning, v1.VCPUPin{VCPU: uint32(pin.VCPU), CPU: cpu})
+	}
+	return pinning, nil
+}
+
 func (d *VirtualMachineController) updateVMIStatus(vmi *v1.VirtualMachineInstance, domain *api.Domain, syncError error) (err error) {
 	condManager := controller.NewVirtualMachineInstanceConditionManager()
 	hasHotplug := false
@@ -721,6 +788,29 @@ func (d *VirtualMachineController) updateVMIStatus(vmi *v1.VirtualMachineInstanc
 			vmi.Status.MigrationState.Completed = migrationMetadata.Completed
 			vmi.Status.MigrationState.Failed = migrationMetadata.Failed
 			vmi.Status.MigrationState.Mode = migrationMetadata.Mode
+			if warmup := migrationMetadata.WarmupStatus; warmup != nil {
+				vmi.Status.MigrationState.WarmupStatus = &v1.MigrationWarmupStatus{
+					Iteration:                     warmup.Iteration,
+					MemoryRemainingBytes:          warmup.MemoryRemainingBytes,
+					DirtyRateBytesPerSecond:       warmup.DirtyRateBytesPerSecond,
+					TransferRateBytesPerSecond:    warmup.TransferRateBytesPerSecond,
+					Converging:                    warmup.Converging,
+					EstimatedDowntimeMilliseconds: warmup.EstimatedDowntimeMilliseconds,
+					LastUpdateTimestamp:           warmup.LastUpdateTimestamp.DeepCopy(),
+				}
+			}
+		}
+	}
+
+	// Report the pinning the vCPUs were rebalanced to on the target once the migration completed.
+	if domain != nil && domain.Spec.Metadata.KubeVirt.VCPURebalance != nil && domain.Spec.CPUTune != nil && vmi.Status.MigrationState != nil {
+		if domain.Spec.Metadata.KubeVirt.VCPURebalance.MigrationUID == vmi.Status.MigrationState.MigrationUID {
+			pinning, err := getVCPUPinning(domain.Spec.CPUTune.VCPUPin)
+			if err != nil {
+				log.Log.Object(vmi).Reason(err).Error("failed to parse the vCPU pinning of the domain")
+			} else {
+				vmi.Status.MigrationState.TargetVCPUPinning = pinning
+			}
 		}
 	}
 
@@ -758,6 +848,14 @@ func (d *VirtualMachineController) updateVMIStatus(vmi *v1.VirtualMachineInstanc
 		}
 	}
 
+	d.updateSELinuxLabelsAppliedCondition(vmi, domain, syncError)
+	d.updateSELinuxLabelsConsistentCondition(vmi)
+	d.markSELinuxRelabeled(vmi, domain, syncError)
+	d.updateStartupProbeCondition(vmi, domain)
+	d.updateHostDevicesHealthyCondition(vmi, domain)
+	updateLastSoftReboot(vmi, domain)
+	d.updateMemoryDumpStatus(vmi, domain)
+
 	// handle migrations differently than normal status updates.
 	//
 	// When a successful migration is detected, we must transfer ownership of the VMI
@@ -925,6 +1023,26 @@ func (d *VirtualMachineController) updateVMIStatus(vmi *v1.VirtualMachineInstanc
 		condManager.RemoveCondition(vmi, v1.VirtualMachineInstancePaused)
 	}
 
+	// Update crashed condition in case the guest crashed and is preserved for inspection
+	if isCrashPreserved(domain) {
+		if !condManager.HasCondition(vmi, v1.VirtualMachineInstanceCrashed) {
+			log.Log.Object(vmi).V(3).Info("Adding crashed condition")
+			now := metav1.NewTime(time.Now())
+			vmi.Status.Conditions = append(vmi.Status.Conditions, v1.VirtualMachineInstanceCondition{
+				Type:               v1.VirtualMachineInstanceCrashed,
+				Status:             k8sv1.ConditionTrue,
+				LastProbeTime:      now,
+				LastTransitionTime: now,
+				Reason:             v1.VirtualMachineInstanceReasonGuestPanicked,
+				Message:            "The guest crashed and is preserved for inspection until the VMI is deleted",
+			})
+			d.recorder.Event(vmi, k8sv1.EventTypeWarning, v1.VirtualMachineInstanceReasonGuestPanicked, "The guest crashed and is preserved for inspection")
+		}
+	} else if condManager.HasCondition(vmi, v1.VirtualMachineInstanceCrashed) {
+		log.Log.Object(vmi).V(3).Info("Removing crashed condition")
+		condManager.RemoveCondition(vmi, v1.VirtualMachineInstanceCrashed)
+	}
+
 	if _, ok := syncError.(*virtLauncherCriticalNetworkError); ok {
 		log.Log.Errorf("virt-launcher crashed due to a network error. Updating VMI %s status to Failed", vmi.Name)
 		vmi.Status.Phase = v1.Failed
@@ -1018,6 +1136,14 @@ func (c *VirtualMachineController) Run(threadiness int, stopCh chan struct{}) {
 	cache.WaitForCacheSync(stopCh, c.domainInformer.HasSynced, c.vmiSourceInformer.HasSynced, c.vmiTargetInformer.HasSynced, c.gracefulShutdownInformer.HasSynced)
 
 	go c.heartBeat(c.heartBeatInterval, stopCh)
+	if c.bootIDStateFile != "" {
+		// before the periodic reconciliation, which would rate limit the first pass
+		c.reconcileSELinuxLabelsAfterReboot()
+	}
+	go wait.Until(func() { c.reconcileHotplugVolumeLabels() }, hotplugVolumeLabelsReconcileInterval, stopCh)
+	if c.selinuxDenialWatcher != nil {
+		go c.selinuxDenialWatcher.Run(stopCh)
+	}
 
 	// Start the actual work
 	for i := 0; i < threadiness; i++ {
@@ -1028,6 +1154,163 @@ func (c *VirtualMachineController) Run(threadiness int, stopCh chan struct{}) {
 	log.Log.Info("Stopping virt-handler controller.")
 }
 
+// reconcileHotplugVolumeLabels restores the selinux label of the hotplugged volumes of the
+// running VMIs, in case it was changed by something else than virt-handler. It returns
+// the number of VMIs which failed.
+func (c *VirtualMachineController) reconcileHotplugVolumeLabels() (failures int) {
+	for _, obj := range c.vmiSourceInformer.GetStore().List() {
+		vmi, ok := obj.(*v1.VirtualMachineInstance)
+		if !ok || !vmi.IsRunning() || vmi.Status.NodeName != c.host {
+			continue
+		}
+		if err := c.hotplugVolumeMounter.ReconcileSELinuxLabels(vmi); err != nil {
+			log.Log.Object(vmi).Reason(err).Warning("failed to reconcile the selinux labels of the hotplugged volumes")
+			failures++
+			continue
+		}
+		if _, err := c.VerifySELinuxLabels(vmi); err != nil {
+			log.Log.Object(vmi).Reason(err).Warning("failed to verify the selinux labels of the hotplugged volumes")
+		}
+	}
+	return failures
+}
+
+// RelabelSELinuxLabels restores the selinux label of the hotplugged volumes of the VMI on
+// demand, without waiting for the periodic reconciliation.
+func (c *VirtualMachineController) RelabelSELinuxLabels(vmi *v1.VirtualMachineInstance) ([]selinux.FileRelabel, error) {
+	results, err := c.hotplugVolumeMounter.RelabelSELinuxLabels(vmi)
+	if err != nil {
+		return results, err
+	}
+	if _, err := c.VerifySELinuxLabels(vmi); err != nil {
+		log.Log.Object(vmi).Reason(err).Warning("failed to verify the selinux labels of the hotplugged volumes")
+	}
+	return results, nil
+}
+
+// VerifySELinuxLabels compares the selinux label of the launcher of the VMI with the labels
+// of its hotplugged volumes, and records the mismatches for the SELinuxLabelsConsistent
+// condition of the VMI.
+func (c *VirtualMachineController) VerifySELinuxLabels(vmi *v1.VirtualMachineInstance) ([]selinux.LabelMismatch, error) {
+	mismatches, err := c.hotplugVolumeMounter.VerifySELinuxLabels(vmi)
+	if err != nil {
+		return nil, err
+	}
+	c.recordSELinuxLabelMismatches(vmi, mismatches)
+	return mismatches, nil
+}
+
+// recordSELinuxLabelMismatches keeps the mismatches of the VMI and enqueues it when they
+// changed, for the next sync to update its condition.
+func (c *VirtualMachineController) recordSELinuxLabelMismatches(vmi *v1.VirtualMachineInstance, mismatches []selinux.LabelMismatch) {
+	c.selinuxLabelMismatchesLock.Lock()
+	defer c.selinuxLabelMismatchesLock.Unlock()
+	if c.selinuxLabelMismatches == nil {
+		c.selinuxLabelMismatches = make(map[types.UID][]selinux.LabelMismatch)
+	}
+	if !hasHotplugVolumes(vmi) {
+		// the sync removing the last hotplugged volume removes the condition
+		delete(c.selinuxLabelMismatches, vmi.UID)
+		return
+	}
+	if mismatches == nil {
+		mismatches = []selinux.LabelMismatch{}
+	}
+	previous, exists := c.selinuxLabelMismatches[vmi.UID]
+	c.selinuxLabelMismatches[vmi.UID] = mismatches
+	if !exists || !reflect.DeepEqual(previous, mismatches) {
+		c.Queue.Add(controller.VirtualMachineKey(vmi))
+	}
+}
+
+// getSELinuxLabelMismatches returns the mismatches last recorded for the VMI, and whether
+// its labels were verified at all.
+func (c *VirtualMachineController) getSELinuxLabelMismatches(vmi *v1.VirtualMachineInstance) ([]selinux.LabelMismatch, bool) {
+	c.selinuxLabelMismatchesLock.Lock()
+	defer c.selinuxLabelMismatchesLock.Unlock()
+	mismatches, exists := c.selinuxLabelMismatches[vmi.UID]
+	return mismatches, exists
+}
+
+func (c *VirtualMachineController) forgetSELinuxLabelMismatches(vmi *v1.VirtualMachineInstance) {
+	c.selinuxLabelMismatchesLock.Lock()
+	defer c.selinuxLabelMismatchesLock.Unlock()
+	delete(c.selinuxLabelMismatches, vmi.UID)
+}
+
+// flushLauncherSELinuxLabel drops the cached selinux label of the launcher of the VMI. A launcher
+// which is gone already can't be detected anymore, its cached label is invalidated anyway once
+// its pid is reused, since the start time of the process changes.
+func (c *VirtualMachineController) flushLauncherSELinuxLabel(vmi *v1.VirtualMachineInstance) {
+	res, err := c.podIsolationDetector.Detect(vmi)
+	if err != nil {
+		log.Log.Object(vmi).Reason(err).V(4).Info("Not flushing the cached selinux label of the launcher, it can't be detected")
+		return
+	}
+	c.flushSELinuxLabelCache(res.Pid())
+}
+
+func hasHotplugVolumes(vmi *v1.VirtualMachineInstance) bool {
+	for _, volumeStatus := range vmi.Status.VolumeStatus {
+		if volumeStatus.HotplugVolume != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// WatchSELinuxDenials makes the controller annotate the VMIs with the last
+// SELinux denial of their launcher found in the given audit log, once started.
+func (c *VirtualMachineController) WatchSELinuxDenials(auditLogPath string) {
+	c.selinuxDenialWatcher = selinux.NewDenialWatcher(auditLogPath, c.lookupLaunchersByLabel, c.annotateSELinuxDenial)
+}
+
+// CheckSELinuxPolicyModules makes the heartbeat report the SELinux policy
+// modules required by the launchers which are missing on the node as a node
+// condition, once started.
+func (c *VirtualMachineController) CheckSELinuxPolicyModules(enumerator selinux.PolicyModuleEnumerator) {
+	c.selinuxPolicyModuleCheck = selinux.NewPolicyModuleCheck(enumerator, selinux.RequiredPolicyModules, selinuxPolicyModuleCheckInterval)
+}
+
+// lookupLaunchersByLabel returns the running VMIs of the host keyed by the
+// selinux label of their launcher.
+func (c *VirtualMachineController) lookupLaunchersByLabel() (map[string]*v1.VirtualMachineInstance, error) {
+	launchers := map[string]*v1.VirtualMachineInstance{}
+	for _, obj := range c.vmiSourceInformer.GetStore().List() {
+		vmi, ok := obj.(*v1.VirtualMachineInstance)
+		if !ok || !vmi.IsRunning() || vmi.Status.NodeName != c.host {
+			continue
+		}
+		res, err := c.podIsolationDetector.Detect(vmi)
+		if err != nil {
+			log.Log.Object(vmi).Reason(err).V(3).Info("failed to detect the launcher of the VMI")
+			continue
+		}
+		label, err := selinux.LabelForPID(res.Pid())
+		if err != nil {
+			log.Log.Object(vmi).Reason(err).V(3).Info("failed to read the selinux label of the launcher")
+			continue
+		}
+		launchers[label] = vmi
+	}
+	return launchers, nil
+}
+
+func (c *VirtualMachineController) annotateSELinuxDenial(vmi *v1.VirtualMachineInstance, summary string) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				v1.LastSELinuxDenialAnnotation: summary,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.clientset.VirtualMachineInstance(vmi.Namespace).Patch(vmi.Name, types.MergePatchType, data)
+	return err
+}
+
 func (c *VirtualMachineController) runWorker() {
 	for c.Execute() {
 	}
@@ -1305,9 +1588,10 @@ func (d *VirtualMachineController) defaultExecute(key string,
 		log.Log.Info("Domain does not exist")
 	}
 
+	// a guest preserved in its crashed state still has to be killed
 	domainAlive := domainExists &&
 		domain.Status.Status != api.Shutoff &&
-		domain.Status.Status != api.Crashed &&
+		(domain.Status.Status != api.Crashed || isCrashPreserved(domain)) &&
 		domain.Status.Status != ""
 
 	domainMigrated := domainExists && domainMigrated(domain)
@@ -1424,13 +1708,26 @@ func (d *VirtualMachineController) defaultExecute(key string,
 		log.Log.Object(vmi).V(3).Info("Processing local ephemeral data cleanup for shutdown domain.")
 		syncErr = d.processVmCleanup(vmi)
 	case shouldUpdate:
+		if syncErr = d.selinuxCircuitBreaker.Allow(vmi); syncErr != nil {
+			log.Log.Object(vmi).Reason(syncErr).V(3).Info("Skipping vmi update: selinux circuit breaker open")
+			break
+		}
 		log.Log.Object(vmi).V(3).Info("Processing vmi update")
 		syncErr = d.processVmUpdate(vmi)
+		if syncErr == nil {
+			syncErr = d.handleMemoryDump(vmi, domain)
+		}
+		syncErr = d.selinuxCircuitBreaker.Record(vmi, syncErr)
+		if circuitErr := asCircuitOpenError(syncErr); circuitErr != nil {
+			d.recorder.Event(vmi, k8sv1.EventTypeWarning, v1.VirtualMachineInstanceReasonSELinuxCircuitOpen,
+				fmt.Sprintf("%v, retrying in %s or once the spec changes", circuitErr, circuitErr.RetryAfter))
+		}
 	default:
 		log.Log.Object(vmi).V(3).Info("No update processing required")
 	}
 
-	if syncErr != nil && !vmi.IsFinal() {
+	circuitErr := asCircuitOpenError(syncErr)
+	if syncErr != nil && !vmi.IsFinal() && circuitErr == nil {
 		d.recorder.Event(vmi, k8sv1.EventTypeWarning, v1.SyncFailed.String(), syncErr.Error())
 		log.Log.Object(vmi).Reason(syncErr).Error("Synchronizing the VirtualMachineInstance failed.")
 	}
@@ -1444,6 +1741,11 @@ func (d *VirtualMachineController) defaultExecute(key string,
 		}
 	}
 
+	if circuitErr != nil {
+		// retried after the backoff of the breaker instead of the rate limiter, or on spec changes
+		d.Queue.AddAfter(controller.VirtualMachineKey(vmi), circuitErr.RetryAfter)
+		return nil
+	}
 	if syncErr != nil {
 		return syncErr
 	}
@@ -1572,6 +1874,9 @@ func (d *VirtualMachineController) processVmCleanup(vmi *v1.VirtualMachineInstan
 	}
 
 	d.clearPodNetworkPhase1(vmi.UID)
+	d.forgetSELinuxLabelMismatches(vmi)
+	d.selinuxCircuitBreaker.Forget(vmi.UID)
+	d.flushLauncherSELinuxLabel(vmi)
 
 	// Watch dog file and command client must be the last things removed here
 	err = d.closeLauncherClient(vmi)
@@ -1759,7 +2064,10 @@ func (d *VirtualMachineController) processVmShutdown(vmi *v1.VirtualMachineInsta
 	}
 
 	// Only attempt to gracefully shutdown if the domain has the ACPI feature enabled
-	if isACPIEnabled(vmi, domain) {
+	// and the guest did not crash
+	if isCrashPreserved(domain) {
+		log.Log.Object(vmi).Infof("Guest crashed, killing deleted VirtualMachineInstance %s", vmi.GetObjectMeta().GetName())
+	} else if isACPIEnabled(vmi, domain) {
 		expired, timeLeft := d.hasGracePeriodExpired(domain)
 		if !expired {
 			if domain.Status.Status != api.Shutdown {
@@ -2077,13 +2385,18 @@ func (d *VirtualMachineController) processVmUpdate(origVMI *v1.VirtualMachineIns
 			criticalNetworkError, err := d.setPodNetworkPhase1(vmi)
 			if err != nil {
 				if criticalNetworkError {
-					return &virtLauncherCriticalNetworkError{fmt.Sprintf("failed to configure vmi network for migration target: %v", err)}
+					return &virtLauncherCriticalNetworkError{msg: fmt.Sprintf("failed to configure vmi network for migration target: %v", err), err: err}
 				} else {
-					return fmt.Errorf("failed to configure vmi network for migration target: %v", err)
+					return fmt.Errorf("failed to configure vmi network for migration target: %w", err)
 				}
 
 			}
 
+			// the incoming qemu needs the same runtime limits, e.g. to lock its memory
+			if err := d.podIsolationDetector.AdjustResources(vmi); err != nil {
+				return fmt.Errorf("failed to adjust resources for migration target: %v", err)
+			}
+
 			if err := client.SyncMigrationTarget(vmi); err != nil {
 				return fmt.Errorf("syncing migration target failed: %v", err)
 
@@ -2115,6 +2428,13 @@ func (d *VirtualMachineController) processVmUpdate(origVMI *v1.VirtualMachineIns
 				AllowAutoConverge:       *migrationConfiguration.AllowAutoConverge,
 				AllowPostCopy:           *migrationConfiguration.AllowPostCopy,
 			}
+			// the controller already limited the tuning of the migration to the cluster maxima
+			if bandwidth := vmi.Status.MigrationState.Bandwidth; bandwidth != nil {
+				options.Bandwidth = *bandwidth
+			}
+			if threads := vmi.Status.MigrationState.ParallelMigrationThreads; threads != nil {
+				options.ParallelMigrationThreads = *threads
+			}
 
 			err = client.MigrateVirtualMachine(vmi, options)
 			if err != nil {
@@ -2125,6 +2445,12 @@ func (d *VirtualMachineController) processVmUpdate(origVMI *v1.VirtualMachineIns
 	} else {
 		if !vmi.IsRunning() && !vmi.IsFinal() {
 
+			if vmi.Spec.Domain.Memory != nil {
+				if err := hardware.VerifyPerNUMANodeHugepages(d.numaNodesDir, vmi.Spec.Domain.Memory.Hugepages); err != nil {
+					return fmt.Errorf("failed to place the hugepages on the host NUMA nodes: %v", err)
+				}
+			}
+
 			// give containerDisks some time to become ready before throwing errors on retries
 			info := d.getLauncherClinetInfo(vmi)
 			if ready, err := d.containerDiskMounter.ContainerDisksReady(vmi, info.notInitializedSince); !ready {
@@ -2142,9 +2468,9 @@ func (d *VirtualMachineController) processVmUpdate(origVMI *v1.VirtualMachineIns
 			criticalNetworkError, err := d.setPodNetworkPhase1(vmi)
 			if err != nil {
 				if criticalNetworkError {
-					return &virtLauncherCriticalNetworkError{fmt.Sprintf("failed to configure vmi network: %v", err)}
+					return &virtLauncherCriticalNetworkError{msg: fmt.Sprintf("failed to configure vmi network: %v", err), err: err}
 				} else {
-					return fmt.Errorf("failed to configure vmi network: %v", err)
+					return fmt.Errorf("failed to configure vmi network: %w", err)
 				}
 
 			}
@@ -2194,6 +2520,292 @@ func (d *VirtualMachineController) processVmUpdate(origVMI *v1.VirtualMachineIns
 	return err
 }
 
+// updateSELinuxLabelsAppliedCondition reports whether the SELinux relabels done
+// by virt-handler for the VMI, like the ones of the tap devices, succeeded.
+func (d *VirtualMachineController) updateSELinuxLabelsAppliedCondition(vmi *v1.VirtualMachineInstance, domain *api.Domain, syncError error) {
+	if d.isSELinuxEnabled == nil || !d.isSELinuxEnabled() {
+		return
+	}
+
+	var status k8sv1.ConditionStatus
+	var reason, message string
+	switch {
+	case asCircuitOpenError(syncError) != nil:
+		status = k8sv1.ConditionFalse
+		reason = v1.VirtualMachineInstanceReasonSELinuxCircuitOpen
+		message = syncError.Error()
+	case syncError != nil && selinux.IsSELinuxError(syncError):
+		status = k8sv1.ConditionFalse
+		reason = v1.VirtualMachineInstanceReasonSELinuxRelabelFailed
+		message = syncError.Error()
+	case syncError == nil && domain != nil:
+		// the domain is only defined once all the relabels preceding its creation succeeded
+		status = k8sv1.ConditionTrue
+	default:
+		return
+	}
+
+	condManager := controller.NewVirtualMachineInstanceConditionManager()
+	condition := condManager.GetCondition(vmi, v1.VirtualMachineInstanceSELinuxLabelsApplied)
+	if condition != nil && condition.Status == status && condition.Reason == reason && condition.Message == message {
+		return
+	}
+	now := metav1.NewTime(time.Now())
+	transitionTime := now
+	if condition != nil && condition.Status == status {
+		// not a transition, e.g. another relabel error on a retry
+		transitionTime = condition.LastTransitionTime
+	}
+	condManager.RemoveCondition(vmi, v1.VirtualMachineInstanceSELinuxLabelsApplied)
+	vmi.Status.Conditions = append(vmi.Status.Conditions, v1.VirtualMachineInstanceCondition{
+		Type:               v1.VirtualMachineInstanceSELinuxLabelsApplied,
+		Status:             status,
+		LastProbeTime:      now,
+		LastTransitionTime: transitionTime,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+func asCircuitOpenError(err error) *selinux.CircuitOpenError {
+	var circuitErr *selinux.CircuitOpenError
+	if goerror.As(err, &circuitErr) {
+		return circuitErr
+	}
+	return nil
+}
+
+// updateSELinuxLabelsConsistentCondition reports the SELinux label mismatches last found
+// between the launcher of the VMI and its hotplugged volumes. The condition is left as is
+// until the labels are verified, and removed once the VMI has no hotplugged volume anymore.
+func (d *VirtualMachineController) updateSELinuxLabelsConsistentCondition(vmi *v1.VirtualMachineInstance) {
+	if d.isSELinuxEnabled == nil || !d.isSELinuxEnabled() {
+		return
+	}
+
+	condManager := controller.NewVirtualMachineInstanceConditionManager()
+	if !hasHotplugVolumes(vmi) {
+		condManager.RemoveCondition(vmi, v1.VirtualMachineInstanceSELinuxLabelsConsistent)
+		return
+	}
+	mismatches, verified := d.getSELinuxLabelMismatches(vmi)
+	if !verified {
+		return
+	}
+
+	status := k8sv1.ConditionTrue
+	var reason, message string
+	if len(mismatches) > 0 {
+		status = k8sv1.ConditionFalse
+		reason = v1.VirtualMachineInstanceReasonSELinuxLabelMismatch
+		message = selinux.FormatLabelMismatches(mismatches)
+	}
+
+	condition := condManager.GetCondition(vmi, v1.VirtualMachineInstanceSELinuxLabelsConsistent)
+	if condition != nil && condition.Status == status && condition.Reason == reason && condition.Message == message {
+		return
+	}
+	now := metav1.NewTime(time.Now())
+	transitionTime := now
+	if condition != nil && condition.Status == status {
+		transitionTime = condition.LastTransitionTime
+	}
+	condManager.RemoveCondition(vmi, v1.VirtualMachineInstanceSELinuxLabelsConsistent)
+	vmi.Status.Conditions = append(vmi.Status.Conditions, v1.VirtualMachineInstanceCondition{
+		Type:               v1.VirtualMachineInstanceSELinuxLabelsConsistent,
+		Status:             status,
+		LastProbeTime:      now,
+		LastTransitionTime: transitionTime,
+		Reason:             reason,
+		Message:            message,
+	})
+	if status == k8sv1.ConditionFalse && (condition == nil || condition.Status != status) {
+		d.recorder.Event(vmi, k8sv1.EventTypeWarning, v1.VirtualMachineInstanceReasonSELinuxLabelMismatch, message)
+	}
+}
+
+// updateStartupProbeCondition reflects the result of the startup probe
+// evaluated by virt-launcher. The condition is left as is while the domain
+// doesn't report any result, like on migration targets.
+func (d *VirtualMachineController) updateStartupProbeCondition(vmi *v1.VirtualMachineInstance, domain *api.Domain) {
+	if vmi.Spec.StartupProbe == nil || domain == nil || domain.Status.StartupProbe == nil {
+		return
+	}
+	result := domain.Status.StartupProbe
+	status := k8sv1.ConditionFalse
+	if result.Succeeded {
+		status = k8sv1.ConditionTrue
+	}
+
+	condManager := controller.NewVirtualMachineInstanceConditionManager()
+	condition := condManager.GetCondition(vmi, v1.VirtualMachineInstanceStartupProbeSucceeded)
+	if condition != nil && condition.Status == status && condition.Reason == result.Reason && condition.Message == result.Message {
+		return
+	}
+	now := metav1.NewTime(time.Now())
+	transitionTime := now
+	if condition != nil && condition.Status == status {
+		transitionTime = condition.LastTransitionTime
+	}
+	condManager.RemoveCondition(vmi, v1.VirtualMachineInstanceStartupProbeSucceeded)
+	vmi.Status.Conditions = append(vmi.Status.Conditions, v1.VirtualMachineInstanceCondition{
+		Type:               v1.VirtualMachineInstanceStartupProbeSucceeded,
+		Status:             status,
+		LastProbeTime:      now,
+		LastTransitionTime: transitionTime,
+		Reason:             result.Reason,
+		Message:            result.Message,
+	})
+	if result.Reason == v1.VirtualMachineInstanceReasonStartupProbeFailed {
+		d.recorder.Event(vmi, k8sv1.EventTypeWarning, v1.VirtualMachineInstanceReasonStartupProbeFailed, result.Message)
+	}
+}
+
+// updateHostDevicesHealthyCondition reflects the health of the host devices
+// checked by virt-launcher. The condition is left as is while the domain
+// doesn't report the devices, e.g. before their first check.
+func (d *VirtualMachineController) updateHostDevicesHealthyCondition(vmi *v1.VirtualMachineInstance, domain *api.Domain) {
+	if domain == nil || domain.Status.HostDevices == nil {
+		return
+	}
+	status := k8sv1.ConditionTrue
+	reason := ""
+	var degraded []string
+	for _, device := range domain.Status.HostDevices {
+		if !device.Healthy {
+			degraded = append(degraded, fmt.Sprintf("%s (%s): %s", device.Name, device.Address, device.Message))
+		}
+	}
+	if len(degraded) > 0 {
+		status = k8sv1.ConditionFalse
+		reason = v1.VirtualMachineInstanceReasonHostDeviceDegraded
+	}
+	message := strings.Join(degraded, ", ")
+
+	condManager := controller.NewVirtualMachineInstanceConditionManager()
+	condition := condManager.GetCondition(vmi, v1.VirtualMachineInstanceHostDevicesHealthy)
+	if condition != nil && condition.Status == status && condition.Message == message {
+		return
+	}
+	now := metav1.NewTime(time.Now())
+	transitionTime := now
+	if condition != nil && condition.Status == status {
+		transitionTime = condition.LastTransitionTime
+	}
+	condManager.RemoveCondition(vmi, v1.VirtualMachineInstanceHostDevicesHealthy)
+	vmi.Status.Conditions = append(vmi.Status.Conditions, v1.VirtualMachineInstanceCondition{
+		Type:               v1.VirtualMachineInstanceHostDevicesHealthy,
+		Status:             status,
+		LastProbeTime:      now,
+		LastTransitionTime: transitionTime,
+		Reason:             reason,
+		Message:            message,
+	})
+	if status == k8sv1.ConditionFalse {
+		d.recorder.Event(vmi, k8sv1.EventTypeWarning, v1.VirtualMachineInstanceReasonHostDeviceDegraded, message)
+	}
+}
+
+// updateLastSoftReboot reports the mechanism of the last soft reboot virt-launcher
+// recorded on the domain.
+func updateLastSoftReboot(vmi *v1.VirtualMachineInstance, domain *api.Domain) {
+	if domain == nil || domain.Spec.Metadata.KubeVirt.SoftReboot == nil {
+		return
+	}
+	softReboot := domain.Spec.Metadata.KubeVirt.SoftReboot
+	vmi.Status.LastSoftReboot = &v1.SoftRebootStatus{
+		Mechanism: v1.SoftRebootMechanism(softReboot.Mechanism),
+		Timestamp: softReboot.Timestamp,
+	}
+}
+
+// handleMemoryDump starts the memory dump requested for a running VMI, once
+// virt-controller attached its claim to the node, by mounting the claim into
+// virt-launcher and asking virt-launcher to dump the memory of the guest to it.
+func (d *VirtualMachineController) handleMemoryDump(vmi *v1.VirtualMachineInstance, domain *api.Domain) error {
+	memoryDump := vmi.Status.MemoryDump
+	if memoryDump == nil || memoryDump.IsFinished() || memoryDump.TargetPodUID == "" || !vmi.IsRunning() || domain == nil {
+		return nil
+	}
+	if started := domain.Spec.Metadata.KubeVirt.MemoryDump; started != nil && started.FileName == memoryDump.FileName {
+		// the progress and the outcome of the dump are reported by updateMemoryDumpStatus
+		return nil
+	}
+
+	if err := d.hotplugVolumeMounter.MountMemoryDump(vmi); err != nil {
+		return err
+	}
+	client, err := d.getLauncherClient(vmi)
+	if err != nil {
+		return fmt.Errorf("unable to create virt-launcher client connection: %v", err)
+	}
+	dumpPath := filepath.Join(hotplugdisk.GetMemoryDumpTargetPath(), memoryDump.FileName)
+	if err := client.VirtualMachineMemoryDump(vmi, dumpPath); err != nil {
+		return fmt.Errorf("failed to dump the memory to claim %s: %v", memoryDump.ClaimName, err)
+	}
+	return nil
+}
+
+// updateMemoryDumpStatus reports the progress of the memory dump virt-launcher
+// recorded on the domain. Once the dump ended, its claim is unmounted from
+// virt-launcher before the outcome is reported, since virt-controller detaches
+// the claim from the node as soon as the dump is finished.
+func (d *VirtualMachineController) updateMemoryDumpStatus(vmi *v1.VirtualMachineInstance, domain *api.Domain) {
+	memoryDump := vmi.Status.MemoryDump
+	if memoryDump == nil || memoryDump.IsFinished() || domain == nil {
+		return
+	}
+	dumpMetadata := domain.Spec.Metadata.KubeVirt.MemoryDump
+	if dumpMetadata == nil || dumpMetadata.FileName != memoryDump.FileName {
+		return
+	}
+
+	memoryDump.BytesWritten = dumpMetadata.BytesWritten
+	memoryDump.BytesTotal = dumpMetadata.BytesTotal
+	memoryDump.Phase = v1.MemoryDumpInProgress
+	if dumpMetadata.EndTimestamp == nil {
+		return
+	}
+
+	if err := d.hotplugVolumeMounter.UnmountMemoryDump(vmi); err != nil {
+		log.Log.Object(vmi).Reason(err).Errorf("Failed to unmount the memory dump claim %s", memoryDump.ClaimName)
+		d.Queue.AddAfter(controller.VirtualMachineKey(vmi), time.Second*1)
+		return
+	}
+	memoryDump.EndTimestamp = dumpMetadata.EndTimestamp
+	if dumpMetadata.Failed {
+		memoryDump.Phase = v1.MemoryDumpFailed
+		memoryDump.Message = dumpMetadata.FailureReason
+		d.recorder.Event(vmi, k8sv1.EventTypeWarning, memoryDumpFailedReason, fmt.Sprintf("Dumping the memory to claim %s failed: %s", memoryDump.ClaimName, dumpMetadata.FailureReason))
+		return
+	}
+	memoryDump.Phase = v1.MemoryDumpCompleted
+	d.recorder.Event(vmi, k8sv1.EventTypeNormal, memoryDumpCompletedReason, fmt.Sprintf("Dumped the memory to %s on claim %s", memoryDump.FileName, memoryDump.ClaimName))
+}
+
+// markSELinuxRelabeled lets the readiness gate of virt-launcher open, once
+// all relabels of the VMI succeeded. Without SELinux on the node, there is
+// nothing to relabel and the gate opens as soon as the domain exists.
+func (d *VirtualMachineController) markSELinuxRelabeled(vmi *v1.VirtualMachineInstance, domain *api.Domain, syncError error) {
+	if syncError != nil || domain == nil {
+		return
+	}
+	if d.isSELinuxEnabled != nil && d.isSELinuxEnabled() {
+		condition := controller.NewVirtualMachineInstanceConditionManager().GetCondition(vmi, v1.VirtualMachineInstanceSELinuxLabelsApplied)
+		if condition == nil || condition.Status != k8sv1.ConditionTrue {
+			return
+		}
+	}
+
+	socket, err := cmdclient.FindSocketOnHost(vmi)
+	if err != nil || cmdclient.IsLegacySocket(socket) || cmdclient.IsSELinuxRelabeled(socket) {
+		return
+	}
+	if err := cmdclient.MarkSELinuxRelabeled(socket); err != nil {
+		log.Log.Object(vmi).Reason(err).Error("failed to confirm the SELinux relabeling to virt-launcher")
+	}
+}
+
 func (d *VirtualMachineController) setVmPhaseForStatusReason(domain *api.Domain, vmi *v1.VirtualMachineInstance) error {
 	phase, err := d.calculateVmPhaseForStatusReason(domain, vmi)
 	if err != nil {
@@ -2232,6 +2844,10 @@ func (d *VirtualMachineController) calculateVmPhaseForStatusReason(domain *api.D
 
 		switch domain.Status.Status {
 		case api.Shutoff, api.Crashed:
+			if isCrashPreserved(domain) {
+				// the guest is kept in its crashed state for inspection until the vmi is deleted
+				return v1.Running, nil
+			}
 			switch domain.Status.Reason {
 			case api.ReasonCrashed, api.ReasonPanicked:
 				return v1.Failed, nil
@@ -2355,6 +2971,9 @@ func (d *VirtualMachineController) heartBeat(interval time.Duration, stopCh chan
 			if d.clusterConfig.CPUManagerEnabled() {
 				d.updateNodeCpuManagerLabel(cpuManagerPath)
 			}
+			d.updateNodeSELinuxTypeLabels()
+			d.updateNodeSELinuxModeLabel()
+			d.updateNodeSELinuxPolicyModulesCondition()
 		}, interval, 1.2, true, stopCh)
 	}
 }
@@ -2389,6 +3008,129 @@ func (d *VirtualMachineController) updateNodeCpuManagerLabel(cpuManagerPath stri
 
 }
 
+// updateNodeSELinuxTypeLabels advertises which of the SELinux launcher types
+// VMIs may request are defined by the policy of the node
+func (d *VirtualMachineController) updateNodeSELinuxTypeLabels() {
+	labels := map[string]string{}
+	selinuxTypes := append([]string{d.clusterConfig.GetSELinuxLauncherType()}, d.clusterConfig.GetCustomSELinuxLauncherTypes()...)
+	for _, selinuxType := range selinuxTypes {
+		if selinuxType == "" {
+			continue
+		}
+		label := v1.SELinuxLauncherTypeLabel + selinuxType
+		if errs := validation.IsQualifiedName(label); len(errs) > 0 {
+			log.DefaultLogger().Errorf("can't advertise the SELinux launcher type %s on host %s: %s", selinuxType, d.host, strings.Join(errs, ", "))
+			continue
+		}
+		labels[label] = fmt.Sprintf("%t", d.isLauncherTypeAvailable(selinuxType))
+	}
+	if len(labels) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": labels,
+		},
+	})
+	if err != nil {
+		log.DefaultLogger().Reason(err).Errorf("failed to set the SELinux launcher type labels on host %s", d.host)
+		return
+	}
+	_, err = d.clientset.CoreV1().Nodes().Patch(context.Background(), d.host, types.StrategicMergePatchType, data, metav1.PatchOptions{})
+	if err != nil {
+		log.DefaultLogger().Reason(err).Errorf("failed to set the SELinux launcher type labels on host %s", d.host)
+		return
+	}
+	log.DefaultLogger().V(4).Infof("Node SELinux launcher types: %v", labels)
+}
+
+// updateNodeSELinuxModeLabel publishes the SELinux mode of the node, or
+// removes the label if SELinux is absent. The node is only patched if the
+// mode changed since the last publication.
+func (d *VirtualMachineController) updateNodeSELinuxModeLabel() {
+	se, present, err := d.detectSELinux()
+	if err != nil {
+		log.DefaultLogger().Reason(err).Errorf("failed to detect the SELinux mode of host %s", d.host)
+		return
+	}
+	mode := ""
+	if present {
+		mode = se.Mode()
+		if errs := validation.IsValidLabelValue(mode); len(errs) > 0 {
+			log.DefaultLogger().Errorf("can't publish the SELinux mode %q of host %s: %s", mode, d.host, strings.Join(errs, ", "))
+			return
+		}
+	}
+	if d.publishedSELinuxMode != nil && *d.publishedSELinuxMode == mode {
+		return
+	}
+
+	var data []byte
+	if mode != "" {
+		data = []byte(fmt.Sprintf(`{"metadata": { "labels": {"%s": "%s"}}}`, v1.SELinuxModeLabel, mode))
+	} else {
+		data = []byte(fmt.Sprintf(`{"metadata": { "labels": {"%s": null}}}`, v1.SELinuxModeLabel))
+	}
+	_, err = d.clientset.CoreV1().Nodes().Patch(context.Background(), d.host, types.StrategicMergePatchType, data, metav1.PatchOptions{})
+	if err != nil {
+		log.DefaultLogger().Reason(err).Errorf("failed to set the SELinux mode label on host %s", d.host)
+		return
+	}
+	if d.publishedSELinuxMode != nil {
+		log.DefaultLogger().Infof("SELinux mode of host %s changed from %q to %q", d.host, *d.publishedSELinuxMode, mode)
+	}
+	d.publishedSELinuxMode = &mode
+}
+
+// updateNodeSELinuxPolicyModulesCondition publishes whether required SELinux
+// policy modules are missing on the node as a node condition. The node is only
+// patched if the missing modules changed since the last publication.
+func (d *VirtualMachineController) updateNodeSELinuxPolicyModulesCondition() {
+	if d.selinuxPolicyModuleCheck == nil {
+		return
+	}
+	missing, err := d.selinuxPolicyModuleCheck.MissingModules()
+	if err != nil {
+		log.DefaultLogger().Reason(err).Errorf("failed to check the SELinux policy modules of host %s", d.host)
+		return
+	}
+	missingModules := strings.Join(missing, ", ")
+	if d.publishedMissingPolicyModules != nil && *d.publishedMissingPolicyModules == missingModules {
+		return
+	}
+
+	now := metav1.Now()
+	condition := k8sv1.NodeCondition{
+		Type:               k8sv1.NodeConditionType(v1.NodeSELinuxPolicyModulesMissing),
+		Status:             k8sv1.ConditionFalse,
+		Reason:             "SELinuxPolicyModulesLoaded",
+		Message:            "The SELinux policy modules required by KubeVirt are loaded",
+		LastHeartbeatTime:  now,
+		LastTransitionTime: now,
+	}
+	if len(missing) > 0 {
+		condition.Status = k8sv1.ConditionTrue
+		condition.Reason = "SELinuxPolicyModulesMissing"
+		condition.Message = fmt.Sprintf("The SELinux policy modules %s required by KubeVirt are not loaded", missingModules)
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []k8sv1.NodeCondition{condition},
+		},
+	})
+	if err != nil {
+		log.DefaultLogger().Reason(err).Errorf("failed to set the SELinux policy modules condition on host %s", d.host)
+		return
+	}
+	_, err = d.clientset.CoreV1().Nodes().PatchStatus(context.Background(), d.host, data)
+	if err != nil {
+		log.DefaultLogger().Reason(err).Errorf("failed to set the SELinux policy modules condition on host %s", d.host)
+		return
+	}
+	d.publishedMissingPolicyModules = &missingModules
+}
+
 func (d *VirtualMachineController) setVMIGuestTime(vmi *v1.VirtualMachineInstance) error {
 	// update the vmi guest with the current time
 	client, err := d.getVerifiedLauncherClient(vmi)
@@ -2403,6 +3145,14 @@ func (d *VirtualMachineController) setVMIGuestTime(vmi *v1.VirtualMachineInstanc
 	return nil
 }
 
+// isCrashPreserved reports whether the guest crashed and its domain is kept in
+// the crashed state by the preserve crash action.
+func isCrashPreserved(domain *api.Domain) bool {
+	return domain != nil &&
+		domain.Status.Status == api.Crashed &&
+		domain.Spec.OnCrash == string(v1.CrashActionPreserve)
+}
+
 func isACPIEnabled(vmi *v1.VirtualMachineInstance, domain *api.Domain) bool {
 	zero := int64(0)
 	return vmi.Spec.TerminationGracePeriodSeconds != &zero &&
@@ -60,10 +60,12 @@ import (
 	"kubevirt.io/client-go/log"
 	"kubevirt.io/client-go/precond"
 	diskutils "kubevirt.io/kubevirt/pkg/ephemeral-disk-utils"
+	hotplugdisk "kubevirt.io/kubevirt/pkg/hotplug-disk"
 	"kubevirt.io/kubevirt/pkg/testutils"
 	virtcache "kubevirt.io/kubevirt/pkg/virt-handler/cache"
 	cmdclient "kubevirt.io/kubevirt/pkg/virt-handler/cmd-client"
 	"kubevirt.io/kubevirt/pkg/virt-handler/isolation"
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux"
 	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/api"
 	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/network"
 	"kubevirt.io/kubevirt/pkg/watchdog"
@@ -197,6 +199,7 @@ var _ = Describe("VirtualMachineInstance", func() {
 			mockIsolationDetector,
 		)
 		controller.hotplugVolumeMounter = mockHotplugVolumeMounter
+		controller.isSELinuxEnabled = func() bool { return false }
 
 		vmiTestUUID = uuid.NewUUID()
 		podTestUUID = uuid.NewUUID()
@@ -972,6 +975,120 @@ var _ = Describe("VirtualMachineInstance", func() {
 			expectEvent(string(v1.AccessCredentialsSyncFailed), true)
 		})
 
+		Context("with a crashed guest", func() {
+			var vmi *v1.VirtualMachineInstance
+			var domain *api.Domain
+
+			BeforeEach(func() {
+				vmi = v1.NewMinimalVMI("testvmi")
+				vmi.UID = vmiTestUUID
+				vmi.ObjectMeta.ResourceVersion = "1"
+				vmi.Status.Phase = v1.Running
+				vmi = addActivePods(vmi, podTestUUID, host)
+				mockWatchdog.CreateFile(vmi)
+
+				domain = api.NewMinimalDomainWithUUID("testvmi", vmiTestUUID)
+			})
+
+			table.DescribeTable("should calculate the phase according to the crash action", func(onCrash v1.CrashAction, status api.LifeCycle, reason api.StateChangeReason, expectedPhase v1.VirtualMachineInstancePhase) {
+				domain.Spec.OnCrash = string(onCrash)
+				domain.Status.Status = status
+				domain.Status.Reason = reason
+
+				phase, err := controller.calculateVmPhaseForStatusReason(domain, vmi)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(phase).To(Equal(expectedPhase))
+			},
+				table.Entry("default", v1.CrashAction(""), api.Shutoff, api.ReasonCrashed, v1.Failed),
+				table.Entry("destroy", v1.CrashActionDestroy, api.Shutoff, api.ReasonCrashed, v1.Failed),
+				table.Entry("restart", v1.CrashActionRestart, api.Running, api.ReasonUnknown, v1.Running),
+				table.Entry("preserve", v1.CrashActionPreserve, api.Crashed, api.ReasonPanicked, v1.Running),
+				table.Entry("preserve once the guest was killed", v1.CrashActionPreserve, api.Shutoff, api.ReasonDestroyed, v1.Succeeded),
+			)
+
+			It("should add and remove the crashed condition of a preserved guest", func() {
+				By("crashing the guest")
+				domain.Spec.OnCrash = string(v1.CrashActionPreserve)
+				domain.Status.Status = api.Crashed
+				domain.Status.Reason = api.ReasonPanicked
+
+				updatedVMI := vmi.DeepCopy()
+				updatedVMI.Status.Conditions = []v1.VirtualMachineInstanceCondition{
+					{
+						Type:   v1.VirtualMachineInstanceIsMigratable,
+						Status: k8sv1.ConditionTrue,
+					},
+					{
+						Type:   v1.VirtualMachineInstanceCrashed,
+						Status: k8sv1.ConditionTrue,
+						Reason: v1.VirtualMachineInstanceReasonGuestPanicked,
+					},
+				}
+
+				vmiFeeder.Add(vmi)
+				domainFeeder.Add(domain)
+
+				client.EXPECT().SyncVirtualMachine(vmi, gomock.Any())
+				mockHotplugVolumeMounter.EXPECT().Unmount(gomock.Any()).Return(nil)
+				mockHotplugVolumeMounter.EXPECT().Mount(gomock.Any()).Return(nil)
+				vmiInterface.EXPECT().Update(NewVMICondMatcher(*updatedVMI)).Do(func(vmi *v1.VirtualMachineInstance) {
+					Expect(vmi.Status.Phase).To(Equal(v1.Running))
+				})
+
+				controller.Execute()
+				testutils.ExpectEvents(recorder, v1.Created.String(), v1.VirtualMachineInstanceReasonGuestPanicked)
+
+				By("restarting the guest")
+				crashedVMI := updatedVMI
+				domain.Status.Status = api.Running
+				domain.Status.Reason = ""
+
+				updatedVMI = crashedVMI.DeepCopy()
+				updatedVMI.Status.Conditions = []v1.VirtualMachineInstanceCondition{
+					{
+						Type:   v1.VirtualMachineInstanceIsMigratable,
+						Status: k8sv1.ConditionTrue,
+					},
+				}
+
+				vmiFeeder.Modify(crashedVMI)
+				domainFeeder.Modify(domain)
+
+				client.EXPECT().SyncVirtualMachine(crashedVMI, gomock.Any())
+				mockHotplugVolumeMounter.EXPECT().Unmount(gomock.Any()).Return(nil)
+				mockHotplugVolumeMounter.EXPECT().Mount(gomock.Any()).Return(nil)
+				vmiInterface.EXPECT().Update(NewVMICondMatcher(*updatedVMI))
+
+				controller.Execute()
+			})
+
+			It("should kill a preserved guest without graceful shutdown once the vmi is deleted", func() {
+				domain.Spec.OnCrash = string(v1.CrashActionPreserve)
+				domain.Status.Status = api.Crashed
+				domain.Status.Reason = api.ReasonPanicked
+				initGracePeriodHelper(30, vmi, domain)
+				domainFeeder.Add(domain)
+
+				client.EXPECT().Ping()
+				client.EXPECT().KillVirtualMachine(v1.NewVMIReferenceWithUUID(metav1.NamespaceDefault, "testvmi", vmiTestUUID))
+
+				controller.Execute()
+			}, 3)
+
+			It("should delete a destroyed crashed guest once the vmi is deleted", func() {
+				domain.Spec.OnCrash = string(v1.CrashActionDestroy)
+				domain.Status.Status = api.Crashed
+				domain.Status.Reason = api.ReasonPanicked
+				initGracePeriodHelper(30, vmi, domain)
+				domainFeeder.Add(domain)
+
+				client.EXPECT().Ping()
+				client.EXPECT().DeleteDomain(v1.NewVMIReferenceWithUUID(metav1.NamespaceDefault, "testvmi", vmiTestUUID))
+
+				controller.Execute()
+			}, 3)
+		})
+
 		It("should add and remove paused condition", func() {
 			vmi := v1.NewMinimalVMI("testvmi")
 			vmi.UID = vmiTestUUID
@@ -1227,6 +1344,20 @@ var _ = Describe("VirtualMachineInstance", func() {
 				client.EXPECT().Close()
 				controller.processVmCleanup(vmi)
 			})
+
+			It("should flush the cached selinux label of the launcher from processVmCleanup", func() {
+				vmi := v1.NewMinimalVMI("testvmi")
+				vmi.UID = vmiTestUUID
+				vmi.Status.Phase = v1.Running
+				var flushed []int
+				controller.flushSELinuxLabelCache = func(pid int) {
+					flushed = append(flushed, pid)
+				}
+				mockHotplugVolumeMounter.EXPECT().UnmountAll(gomock.Any()).Return(nil)
+				client.EXPECT().Close()
+				controller.processVmCleanup(vmi)
+				Expect(flushed).To(Equal([]int{1}))
+			})
 		})
 
 		table.DescribeTable("should leave the VirtualMachineInstance alone if it is in the final phase", func(phase v1.VirtualMachineInstancePhase) {
@@ -2445,6 +2576,747 @@ var _ = Describe("VirtualMachineInstance", func() {
 			controller.Execute()
 		})
 	})
+
+	Context("VirtualMachineInstance controller reports the SELinux labels condition", func() {
+		relabelError := &virtLauncherCriticalNetworkError{
+			msg: "failed to configure vmi network",
+			err: &selinux.ContextSwitchError{Label: "system_u:system_r:container_t:s0", Err: fmt.Errorf("permission denied")},
+		}
+
+		getCondition := func(vmi *v1.VirtualMachineInstance) *v1.VirtualMachineInstanceCondition {
+			for i := range vmi.Status.Conditions {
+				if vmi.Status.Conditions[i].Type == v1.VirtualMachineInstanceSELinuxLabelsApplied {
+					return &vmi.Status.Conditions[i]
+				}
+			}
+			return nil
+		}
+
+		BeforeEach(func() {
+			controller.isSELinuxEnabled = func() bool { return true }
+		})
+
+		table.DescribeTable("should transition", func(initialStatus k8sv1.ConditionStatus, withDomain bool, syncError error, expectedStatus k8sv1.ConditionStatus, expectedReason string) {
+			vmi := v1.NewMinimalVMI("testvmi")
+			if initialStatus != "" {
+				vmi.Status.Conditions = []v1.VirtualMachineInstanceCondition{
+					{Type: v1.VirtualMachineInstanceSELinuxLabelsApplied, Status: initialStatus},
+				}
+			}
+			var domain *api.Domain
+			if withDomain {
+				domain = api.NewMinimalDomain("testvmi")
+			}
+
+			controller.updateSELinuxLabelsAppliedCondition(vmi, domain, syncError)
+
+			condition := getCondition(vmi)
+			if expectedStatus == "" {
+				Expect(condition).To(BeNil())
+				return
+			}
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(expectedStatus))
+			Expect(condition.Reason).To(Equal(expectedReason))
+		},
+			table.Entry("to True once the domain is defined", k8sv1.ConditionStatus(""), true, nil, k8sv1.ConditionTrue, ""),
+			table.Entry("to False when a relabel fails", k8sv1.ConditionStatus(""), false, relabelError, k8sv1.ConditionFalse, v1.VirtualMachineInstanceReasonSELinuxRelabelFailed),
+			table.Entry("from False to True once the relabels succeed", k8sv1.ConditionFalse, true, nil, k8sv1.ConditionTrue, ""),
+			table.Entry("from True to False when a later relabel fails", k8sv1.ConditionTrue, true, relabelError, k8sv1.ConditionFalse, v1.VirtualMachineInstanceReasonSELinuxRelabelFailed),
+			table.Entry("to False once the circuit breaker opened", k8sv1.ConditionFalse, false, &selinux.CircuitOpenError{Failures: 5, RetryAfter: time.Minute, Err: relabelError}, k8sv1.ConditionFalse, v1.VirtualMachineInstanceReasonSELinuxCircuitOpen),
+			table.Entry("nowhere before the domain is defined", k8sv1.ConditionStatus(""), false, nil, k8sv1.ConditionStatus(""), ""),
+			table.Entry("nowhere on unrelated errors", k8sv1.ConditionTrue, true, fmt.Errorf("unrelated"), k8sv1.ConditionTrue, ""),
+		)
+
+		It("should keep the transition time when only the failure changes", func() {
+			transitionTime := metav1.NewTime(time.Now().Add(-time.Hour))
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.Status.Conditions = []v1.VirtualMachineInstanceCondition{
+				{
+					Type:               v1.VirtualMachineInstanceSELinuxLabelsApplied,
+					Status:             k8sv1.ConditionFalse,
+					Reason:             v1.VirtualMachineInstanceReasonSELinuxRelabelFailed,
+					Message:            "an earlier relabel failure",
+					LastProbeTime:      transitionTime,
+					LastTransitionTime: transitionTime,
+				},
+			}
+
+			controller.updateSELinuxLabelsAppliedCondition(vmi, nil, relabelError)
+
+			Expect(vmi.Status.Conditions).To(HaveLen(1))
+			condition := getCondition(vmi)
+			Expect(condition.Status).To(Equal(k8sv1.ConditionFalse))
+			Expect(condition.Message).To(Equal(relabelError.Error()))
+			Expect(condition.LastTransitionTime).To(Equal(transitionTime))
+			Expect(condition.LastProbeTime.After(transitionTime.Time)).To(BeTrue())
+		})
+
+		It("should not report the condition when SELinux is disabled", func() {
+			controller.isSELinuxEnabled = func() bool { return false }
+			vmi := v1.NewMinimalVMI("testvmi")
+			controller.updateSELinuxLabelsAppliedCondition(vmi, api.NewMinimalDomain("testvmi"), nil)
+			Expect(getCondition(vmi)).To(BeNil())
+		})
+
+		table.DescribeTable("should confirm the relabeling to virt-launcher", func(selinuxEnabled bool, withDomain bool, syncError error, expectConfirmed bool) {
+			controller.isSELinuxEnabled = func() bool { return selinuxEnabled }
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.UID = vmiTestUUID
+			vmi = addActivePods(vmi, podTestUUID, host)
+			var domain *api.Domain
+			if withDomain {
+				domain = api.NewMinimalDomain("testvmi")
+			}
+
+			controller.updateSELinuxLabelsAppliedCondition(vmi, domain, syncError)
+			controller.markSELinuxRelabeled(vmi, domain, syncError)
+
+			Expect(cmdclient.IsSELinuxRelabeled(sockFile)).To(Equal(expectConfirmed))
+		},
+			table.Entry("once the labels are applied", true, true, nil, true),
+			table.Entry("not before the domain is defined", true, false, nil, false),
+			table.Entry("not when a relabel fails", true, true, relabelError, false),
+			table.Entry("once the domain is defined without SELinux", false, true, nil, true),
+		)
+	})
+
+	Context("VirtualMachineInstance controller reports the startup probe condition", func() {
+		newVMIWithStartupProbe := func() *v1.VirtualMachineInstance {
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.Spec.StartupProbe = &v1.Probe{Handler: v1.Handler{GuestAgentPing: &v1.GuestAgentPing{}}}
+			return vmi
+		}
+
+		domainWithProbeResult := func(result *api.StartupProbeStatus) *api.Domain {
+			domain := api.NewMinimalDomain("testvmi")
+			domain.Status.StartupProbe = result
+			return domain
+		}
+
+		getCondition := func(vmi *v1.VirtualMachineInstance) *v1.VirtualMachineInstanceCondition {
+			for i := range vmi.Status.Conditions {
+				if vmi.Status.Conditions[i].Type == v1.VirtualMachineInstanceStartupProbeSucceeded {
+					return &vmi.Status.Conditions[i]
+				}
+			}
+			return nil
+		}
+
+		table.DescribeTable("should reflect the result reported by virt-launcher", func(result api.StartupProbeStatus, expectedStatus k8sv1.ConditionStatus) {
+			vmi := newVMIWithStartupProbe()
+			controller.updateStartupProbeCondition(vmi, domainWithProbeResult(&result))
+
+			condition := getCondition(vmi)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(expectedStatus))
+			Expect(condition.Reason).To(Equal(result.Reason))
+			Expect(condition.Message).To(Equal(result.Message))
+		},
+			table.Entry("when the probe succeeded", api.StartupProbeStatus{Succeeded: true}, k8sv1.ConditionTrue),
+			table.Entry("while the probe is pending", api.StartupProbeStatus{Reason: v1.VirtualMachineInstanceReasonStartupProbePending, Message: "pending"}, k8sv1.ConditionFalse),
+			table.Entry("while the guest agent is unavailable", api.StartupProbeStatus{Reason: v1.VirtualMachineInstanceReasonGuestAgentUnavailable, Message: "not connected"}, k8sv1.ConditionFalse),
+			table.Entry("once the probe failed", api.StartupProbeStatus{Reason: v1.VirtualMachineInstanceReasonStartupProbeFailed, Message: "timed out"}, k8sv1.ConditionFalse),
+		)
+
+		It("should record an event once the probe failed", func() {
+			vmi := newVMIWithStartupProbe()
+			controller.updateStartupProbeCondition(vmi, domainWithProbeResult(&api.StartupProbeStatus{Reason: v1.VirtualMachineInstanceReasonStartupProbeFailed, Message: "timed out"}))
+			testutils.ExpectEvent(recorder, v1.VirtualMachineInstanceReasonStartupProbeFailed)
+		})
+
+		It("should keep the condition while the domain reports no result", func() {
+			vmi := newVMIWithStartupProbe()
+			controller.updateStartupProbeCondition(vmi, domainWithProbeResult(&api.StartupProbeStatus{Succeeded: true}))
+			controller.updateStartupProbeCondition(vmi, domainWithProbeResult(nil))
+			controller.updateStartupProbeCondition(vmi, nil)
+			Expect(getCondition(vmi).Status).To(Equal(k8sv1.ConditionTrue))
+		})
+
+		It("should not report the condition without startup probe", func() {
+			vmi := v1.NewMinimalVMI("testvmi")
+			controller.updateStartupProbeCondition(vmi, domainWithProbeResult(&api.StartupProbeStatus{Succeeded: true}))
+			Expect(getCondition(vmi)).To(BeNil())
+		})
+	})
+
+	Context("VirtualMachineInstance controller reports the host devices health condition", func() {
+		domainWithHostDevices := func(health ...api.HostDeviceHealth) *api.Domain {
+			domain := api.NewMinimalDomain("testvmi")
+			domain.Status.HostDevices = health
+			return domain
+		}
+
+		getCondition := func(vmi *v1.VirtualMachineInstance) *v1.VirtualMachineInstanceCondition {
+			for i := range vmi.Status.Conditions {
+				if vmi.Status.Conditions[i].Type == v1.VirtualMachineInstanceHostDevicesHealthy {
+					return &vmi.Status.Conditions[i]
+				}
+			}
+			return nil
+		}
+
+		It("should report healthy host devices", func() {
+			vmi := v1.NewMinimalVMI("testvmi")
+			controller.updateHostDevicesHealthyCondition(vmi, domainWithHostDevices(api.HostDeviceHealth{Name: "nic", Address: "0000:81:00.1", Healthy: true}))
+			condition := getCondition(vmi)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(k8sv1.ConditionTrue))
+		})
+
+		It("should report the degraded host devices and record an event", func() {
+			vmi := v1.NewMinimalVMI("testvmi")
+			controller.updateHostDevicesHealthyCondition(vmi, domainWithHostDevices(
+				api.HostDeviceHealth{Name: "nic", Address: "0000:81:00.1", Healthy: true},
+				api.HostDeviceHealth{Name: "gpu", Address: "0000:82:00.0", Message: "the PCIe link of the device is down"},
+			))
+			condition := getCondition(vmi)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(k8sv1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(v1.VirtualMachineInstanceReasonHostDeviceDegraded))
+			Expect(condition.Message).To(Equal("gpu (0000:82:00.0): the PCIe link of the device is down"))
+			testutils.ExpectEvent(recorder, v1.VirtualMachineInstanceReasonHostDeviceDegraded)
+		})
+
+		It("should not report the condition before the devices were checked", func() {
+			vmi := v1.NewMinimalVMI("testvmi")
+			controller.updateHostDevicesHealthyCondition(vmi, domainWithHostDevices())
+			controller.updateHostDevicesHealthyCondition(vmi, nil)
+			Expect(vmi.Status.Conditions).To(BeEmpty())
+		})
+	})
+
+	Context("VirtualMachineInstance controller reports the last soft reboot", func() {
+		It("should report the mechanism recorded by virt-launcher", func() {
+			vmi := v1.NewMinimalVMI("testvmi")
+			domain := api.NewMinimalDomain("testvmi")
+			now := metav1.Now()
+			domain.Spec.Metadata.KubeVirt.SoftReboot = &api.SoftRebootMetadata{Mechanism: string(v1.SoftRebootGuestAgent), Timestamp: &now}
+
+			updateLastSoftReboot(vmi, domain)
+			Expect(vmi.Status.LastSoftReboot).To(Equal(&v1.SoftRebootStatus{Mechanism: v1.SoftRebootGuestAgent, Timestamp: &now}))
+		})
+
+		It("should keep the last soft reboot while the domain reports none", func() {
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.Status.LastSoftReboot = &v1.SoftRebootStatus{Mechanism: v1.SoftRebootACPI}
+
+			updateLastSoftReboot(vmi, api.NewMinimalDomain("testvmi"))
+			updateLastSoftReboot(vmi, nil)
+			Expect(vmi.Status.LastSoftReboot.Mechanism).To(Equal(v1.SoftRebootACPI))
+		})
+	})
+
+	Context("VirtualMachineInstance controller dumps the memory", func() {
+		newMemoryDumpVMI := func(phase v1.MemoryDumpPhase) *v1.VirtualMachineInstance {
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.UID = vmiTestUUID
+			vmi.Status.Phase = v1.Running
+			vmi.Status.MemoryDump = &v1.MemoryDumpStatus{
+				ClaimName:    "dump-pvc",
+				Phase:        phase,
+				FileName:     "testvmi-20211006-101010.memory.dump",
+				TargetPodUID: "dump-pod-uid",
+			}
+			return vmi
+		}
+
+		It("should mount the claim and start the dump once the claim is attached", func() {
+			vmi := newMemoryDumpVMI(v1.MemoryDumpPending)
+			mockHotplugVolumeMounter.EXPECT().MountMemoryDump(vmi).Return(nil)
+			client.EXPECT().VirtualMachineMemoryDump(vmi, filepath.Join(hotplugdisk.GetMemoryDumpTargetPath(), "testvmi-20211006-101010.memory.dump")).Return(nil)
+
+			Expect(controller.handleMemoryDump(vmi, api.NewMinimalDomain("testvmi"))).To(Succeed())
+		})
+
+		It("should not start the dump before the claim is attached", func() {
+			vmi := newMemoryDumpVMI(v1.MemoryDumpPending)
+			vmi.Status.MemoryDump.TargetPodUID = ""
+
+			Expect(controller.handleMemoryDump(vmi, api.NewMinimalDomain("testvmi"))).To(Succeed())
+		})
+
+		It("should not start the dump again once virt-launcher recorded it", func() {
+			vmi := newMemoryDumpVMI(v1.MemoryDumpInProgress)
+			domain := api.NewMinimalDomain("testvmi")
+			domain.Spec.Metadata.KubeVirt.MemoryDump = &api.MemoryDumpMetadata{FileName: "testvmi-20211006-101010.memory.dump"}
+
+			Expect(controller.handleMemoryDump(vmi, domain)).To(Succeed())
+		})
+
+		It("should report the progress of a running dump", func() {
+			vmi := newMemoryDumpVMI(v1.MemoryDumpPending)
+			domain := api.NewMinimalDomain("testvmi")
+			now := metav1.Now()
+			domain.Spec.Metadata.KubeVirt.MemoryDump = &api.MemoryDumpMetadata{
+				FileName:       "testvmi-20211006-101010.memory.dump",
+				StartTimestamp: &now,
+				BytesWritten:   40,
+				BytesTotal:     100,
+			}
+
+			controller.updateMemoryDumpStatus(vmi, domain)
+			Expect(vmi.Status.MemoryDump.Phase).To(Equal(v1.MemoryDumpInProgress))
+			Expect(vmi.Status.MemoryDump.BytesWritten).To(Equal(int64(40)))
+			Expect(vmi.Status.MemoryDump.BytesTotal).To(Equal(int64(100)))
+		})
+
+		table.DescribeTable("should unmount the claim and report the outcome of the dump", func(failed bool, phase v1.MemoryDumpPhase, message, eventReason string) {
+			vmi := newMemoryDumpVMI(v1.MemoryDumpInProgress)
+			domain := api.NewMinimalDomain("testvmi")
+			now := metav1.Now()
+			domain.Spec.Metadata.KubeVirt.MemoryDump = &api.MemoryDumpMetadata{
+				FileName:       "testvmi-20211006-101010.memory.dump",
+				StartTimestamp: &now,
+				EndTimestamp:   &now,
+				Completed:      !failed,
+				Failed:         failed,
+				FailureReason:  message,
+			}
+			mockHotplugVolumeMounter.EXPECT().UnmountMemoryDump(vmi).Return(nil)
+
+			controller.updateMemoryDumpStatus(vmi, domain)
+			Expect(vmi.Status.MemoryDump.Phase).To(Equal(phase))
+			Expect(vmi.Status.MemoryDump.Message).To(Equal(message))
+			Expect(vmi.Status.MemoryDump.EndTimestamp).To(Equal(&now))
+			testutils.ExpectEvent(recorder, eventReason)
+		},
+			table.Entry("completed", false, v1.MemoryDumpCompleted, "", memoryDumpCompletedReason),
+			table.Entry("failed", true, v1.MemoryDumpFailed, "disk full", memoryDumpFailedReason),
+		)
+
+		It("should keep the dump in progress while the claim can't be unmounted", func() {
+			vmi := newMemoryDumpVMI(v1.MemoryDumpInProgress)
+			domain := api.NewMinimalDomain("testvmi")
+			now := metav1.Now()
+			domain.Spec.Metadata.KubeVirt.MemoryDump = &api.MemoryDumpMetadata{
+				FileName:     "testvmi-20211006-101010.memory.dump",
+				EndTimestamp: &now,
+				Completed:    true,
+			}
+			mockHotplugVolumeMounter.EXPECT().UnmountMemoryDump(vmi).Return(fmt.Errorf("device busy"))
+
+			controller.updateMemoryDumpStatus(vmi, domain)
+			Expect(vmi.Status.MemoryDump.Phase).To(Equal(v1.MemoryDumpInProgress))
+			Expect(vmi.Status.MemoryDump.EndTimestamp).To(BeNil())
+		})
+
+		It("should ignore the metadata of a previous dump", func() {
+			vmi := newMemoryDumpVMI(v1.MemoryDumpPending)
+			domain := api.NewMinimalDomain("testvmi")
+			now := metav1.Now()
+			domain.Spec.Metadata.KubeVirt.MemoryDump = &api.MemoryDumpMetadata{
+				FileName:     "testvmi-20211005-101010.memory.dump",
+				EndTimestamp: &now,
+				Completed:    true,
+			}
+
+			controller.updateMemoryDumpStatus(vmi, domain)
+			Expect(vmi.Status.MemoryDump.Phase).To(Equal(v1.MemoryDumpPending))
+		})
+	})
+
+	Context("VirtualMachineInstance controller reconciles the selinux labels of hotplugged volumes", func() {
+		It("should only reconcile running VMIs on the node", func() {
+			vmi := v1.NewMinimalVMI("testvmi")
+			vmi.UID = vmiTestUUID
+			vmi.Status.Phase = v1.Running
+			vmi.Status.NodeName = host
+			otherNodeVMI := v1.NewMinimalVMI("othervmi")
+			otherNodeVMI.Status.Phase = v1.Running
+			otherNodeVMI.Status.NodeName = "othernode"
+			stoppedVMI := v1.NewMinimalVMI("stoppedvmi")
+			stoppedVMI.Status.Phase = v1.Succeeded
+			stoppedVMI.Status.NodeName = host
+			vmiFeeder.Add(vmi)
+			vmiFeeder.Add(otherNodeVMI)
+			vmiFeeder.Add(stoppedVMI)
+
+			mockHotplugVolumeMounter.EXPECT().ReconcileSELinuxLabels(vmi).Return(fmt.Errorf("relabel failure"))
+
+			controller.reconcileHotplugVolumeLabels()
+		})
+	})
+
+	Context("VirtualMachineInstance controller verifies the selinux labels of hotplugged volumes", func() {
+		mismatch := selinux.LabelMismatch{
+			Path:          "/var/run/kubevirt/hotplug-disks/disk0",
+			Label:         "system_u:object_r:container_file_t:s0:c3,c4",
+			LauncherLabel: "system_u:object_r:container_file_t:s0:c1,c2",
+			Difference:    "level s0:c1,c2 -> s0:c3,c4",
+		}
+		var vmi *v1.VirtualMachineInstance
+
+		getCondition := func(vmi *v1.VirtualMachineInstance) *v1.VirtualMachineInstanceCondition {
+			for i := range vmi.Status.Conditions {
+				if vmi.Status.Conditions[i].Type == v1.VirtualMachineInstanceSELinuxLabelsConsistent {
+					return &vmi.Status.Conditions[i]
+				}
+			}
+			return nil
+		}
+
+		BeforeEach(func() {
+			controller.isSELinuxEnabled = func() bool { return true }
+			vmi = v1.NewMinimalVMI("testvmi")
+			vmi.UID = vmiTestUUID
+			vmi.Status.Phase = v1.Running
+			vmi.Status.NodeName = host
+			vmi.Status.VolumeStatus = []v1.VolumeStatus{
+				{Name: "hotplug", HotplugVolume: &v1.HotplugVolumeStatus{}},
+			}
+		})
+
+		It("should verify the labels after reconciling them", func() {
+			vmiFeeder.Add(vmi)
+			mockHotplugVolumeMounter.EXPECT().ReconcileSELinuxLabels(vmi).Return(nil)
+			mockHotplugVolumeMounter.EXPECT().VerifySELinuxLabels(vmi).Return([]selinux.LabelMismatch{mismatch}, nil)
+
+			Expect(controller.reconcileHotplugVolumeLabels()).To(BeZero())
+			mismatches, verified := controller.getSELinuxLabelMismatches(vmi)
+			Expect(verified).To(BeTrue())
+			Expect(mismatches).To(Equal([]selinux.LabelMismatch{mismatch}))
+		})
+
+		It("should verify the labels after relabeling them on demand", func() {
+			mockHotplugVolumeMounter.EXPECT().RelabelSELinuxLabels(vmi).Return(nil, nil)
+			mockHotplugVolumeMounter.EXPECT().VerifySELinuxLabels(vmi).Return(nil, nil)
+
+			_, err := controller.RelabelSELinuxLabels(vmi)
+			Expect(err).ToNot(HaveOccurred())
+			_, verified := controller.getSELinuxLabelMismatches(vmi)
+			Expect(verified).To(BeTrue())
+		})
+
+		It("should report the mismatches in the condition", func() {
+			mockHotplugVolumeMounter.EXPECT().VerifySELinuxLabels(vmi).Return([]selinux.LabelMismatch{mismatch}, nil)
+			_, err := controller.VerifySELinuxLabels(vmi)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mockQueue.Len()).To(Equal(1))
+
+			controller.updateSELinuxLabelsConsistentCondition(vmi)
+			condition := getCondition(vmi)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(k8sv1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(v1.VirtualMachineInstanceReasonSELinuxLabelMismatch))
+			Expect(condition.Message).To(Equal(selinux.FormatLabelMismatches([]selinux.LabelMismatch{mismatch})))
+			testutils.ExpectEvent(recorder, v1.VirtualMachineInstanceReasonSELinuxLabelMismatch)
+		})
+
+		It("should report matching labels in the condition", func() {
+			mockHotplugVolumeMounter.EXPECT().VerifySELinuxLabels(vmi).Return(nil, nil)
+			_, err := controller.VerifySELinuxLabels(vmi)
+			Expect(err).ToNot(HaveOccurred())
+
+			controller.updateSELinuxLabelsConsistentCondition(vmi)
+			condition := getCondition(vmi)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(k8sv1.ConditionTrue))
+			Expect(condition.Reason).To(BeEmpty())
+		})
+
+		It("should only enqueue the VMI when the mismatches change", func() {
+			mockHotplugVolumeMounter.EXPECT().VerifySELinuxLabels(vmi).Return([]selinux.LabelMismatch{mismatch}, nil).Times(2)
+			controller.VerifySELinuxLabels(vmi)
+			Expect(mockQueue.Len()).To(Equal(1))
+			key, _ := mockQueue.Get()
+			mockQueue.Done(key)
+
+			controller.VerifySELinuxLabels(vmi)
+			Expect(mockQueue.Len()).To(BeZero())
+		})
+
+		It("should not report the condition before the labels are verified", func() {
+			controller.updateSELinuxLabelsConsistentCondition(vmi)
+			Expect(getCondition(vmi)).To(BeNil())
+		})
+
+		It("should remove the condition once the VMI has no hotplugged volume", func() {
+			vmi.Status.Conditions = []v1.VirtualMachineInstanceCondition{
+				{Type: v1.VirtualMachineInstanceSELinuxLabelsConsistent, Status: k8sv1.ConditionFalse},
+			}
+			vmi.Status.VolumeStatus = nil
+			controller.updateSELinuxLabelsConsistentCondition(vmi)
+			Expect(getCondition(vmi)).To(BeNil())
+		})
+
+		It("should not report the condition when SELinux is disabled", func() {
+			controller.isSELinuxEnabled = func() bool { return false }
+			mockHotplugVolumeMounter.EXPECT().VerifySELinuxLabels(vmi).Return([]selinux.LabelMismatch{mismatch}, nil)
+			controller.VerifySELinuxLabels(vmi)
+
+			controller.updateSELinuxLabelsConsistentCondition(vmi)
+			Expect(getCondition(vmi)).To(BeNil())
+		})
+	})
+
+	Context("VirtualMachineInstance controller detects node reboots", func() {
+		const bootID = "0b1fcb3c-5d95-4a6b-9a3d-27c0fcd7b1a4"
+		var orgBootIDPath, stateFile string
+		var vmi *v1.VirtualMachineInstance
+
+		BeforeEach(func() {
+			orgBootIDPath = bootIDPath
+			bootIDPath = filepath.Join(privateDir, "boot_id")
+			Expect(ioutil.WriteFile(bootIDPath, []byte(bootID+"\n"), 0644)).To(Succeed())
+			stateFile = filepath.Join(privateDir, "boot-id")
+			controller.DetectNodeReboots(stateFile)
+			controller.isSELinuxEnabled = func() bool { return true }
+
+			vmi = v1.NewMinimalVMI("testvmi")
+			vmi.UID = vmiTestUUID
+			vmi.Status.Phase = v1.Running
+			vmi.Status.NodeName = host
+			vmiFeeder.Add(vmi)
+		})
+
+		AfterEach(func() {
+			bootIDPath = orgBootIDPath
+		})
+
+		expectPersistedBootID := func(expected string) {
+			persisted, err := ioutil.ReadFile(stateFile)
+			ExpectWithOffset(1, err).ToNot(HaveOccurred())
+			ExpectWithOffset(1, string(persisted)).To(Equal(expected))
+		}
+
+		It("should relabel the VMIs and persist the boot id when it changed", func() {
+			Expect(ioutil.WriteFile(stateFile, []byte("a-previous-boot"), 0644)).To(Succeed())
+			mockHotplugVolumeMounter.EXPECT().ReconcileSELinuxLabels(vmi).Return(nil)
+			mockHotplugVolumeMounter.EXPECT().VerifySELinuxLabels(vmi).Return(nil, nil)
+
+			controller.reconcileSELinuxLabelsAfterReboot()
+			expectPersistedBootID(bootID)
+		})
+
+		It("should relabel the VMIs if no boot id was persisted yet", func() {
+			mockHotplugVolumeMounter.EXPECT().ReconcileSELinuxLabels(vmi).Return(nil)
+			mockHotplugVolumeMounter.EXPECT().VerifySELinuxLabels(vmi).Return(nil, nil)
+
+			controller.reconcileSELinuxLabelsAfterReboot()
+			expectPersistedBootID(bootID)
+		})
+
+		It("should not relabel the VMIs if the boot id is unchanged", func() {
+			Expect(ioutil.WriteFile(stateFile, []byte(bootID), 0644)).To(Succeed())
+			mockHotplugVolumeMounter.EXPECT().ReconcileSELinuxLabels(gomock.Any()).Times(0)
+
+			controller.reconcileSELinuxLabelsAfterReboot()
+			expectPersistedBootID(bootID)
+		})
+
+		It("should keep the previous boot id if a relabel fails", func() {
+			Expect(ioutil.WriteFile(stateFile, []byte("a-previous-boot"), 0644)).To(Succeed())
+			mockHotplugVolumeMounter.EXPECT().ReconcileSELinuxLabels(vmi).Return(fmt.Errorf("relabel failure"))
+
+			controller.reconcileSELinuxLabelsAfterReboot()
+			expectPersistedBootID("a-previous-boot")
+		})
+
+		It("should only persist the boot id without selinux", func() {
+			controller.isSELinuxEnabled = func() bool { return false }
+			mockHotplugVolumeMounter.EXPECT().ReconcileSELinuxLabels(gomock.Any()).Times(0)
+
+			controller.reconcileSELinuxLabelsAfterReboot()
+			expectPersistedBootID(bootID)
+		})
+	})
+
+	Context("VirtualMachineInstance controller advertises the SELinux launcher types", func() {
+		It("should label the node with the availability of each launcher type", func() {
+			config, _, _, _ := testutils.NewFakeClusterConfig(&k8sv1.ConfigMap{
+				Data: map[string]string{virtconfig.CustomSELinuxLauncherTypesKey: "virt_custom_t,virt_missing_t,invalid:type"},
+			})
+			controller.clusterConfig = config
+			controller.isLauncherTypeAvailable = func(selinuxType string) bool {
+				return selinuxType != "virt_missing_t"
+			}
+			kubeClient := fake.NewSimpleClientset(&k8sv1.Node{ObjectMeta: metav1.ObjectMeta{Name: host}})
+			virtClient.EXPECT().CoreV1().Return(kubeClient.CoreV1()).AnyTimes()
+
+			controller.updateNodeSELinuxTypeLabels()
+
+			node, err := kubeClient.CoreV1().Nodes().Get(context.Background(), host, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(node.Labels).To(Equal(map[string]string{
+				v1.SELinuxLauncherTypeLabel + "virt_launcher.process": "true",
+				v1.SELinuxLauncherTypeLabel + "virt_custom_t":         "true",
+				v1.SELinuxLauncherTypeLabel + "virt_missing_t":        "false",
+			}))
+		})
+	})
+
+	Context("VirtualMachineInstance controller publishes the SELinux mode", func() {
+		var kubeClient *fake.Clientset
+		var detectedMode string
+		var detectionErr error
+
+		BeforeEach(func() {
+			detectedMode = ""
+			detectionErr = nil
+			controller.detectSELinux = func() (selinux.SELinux, bool, error) {
+				return &fakeSELinux{mode: detectedMode}, detectedMode != "" && detectedMode != "disabled", detectionErr
+			}
+			kubeClient = fake.NewSimpleClientset(&k8sv1.Node{ObjectMeta: metav1.ObjectMeta{
+				Name:   host,
+				Labels: map[string]string{"unrelated": "label"},
+			}})
+			virtClient.EXPECT().CoreV1().Return(kubeClient.CoreV1()).AnyTimes()
+		})
+
+		nodeLabels := func() map[string]string {
+			node, err := kubeClient.CoreV1().Nodes().Get(context.Background(), host, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			return node.Labels
+		}
+
+		countPatches := func() int {
+			patches := 0
+			for _, action := range kubeClient.Actions() {
+				if action.GetVerb() == "patch" {
+					patches++
+				}
+			}
+			return patches
+		}
+
+		It("should set the label and only update it when the mode changes", func() {
+			detectedMode = "enforcing"
+			controller.updateNodeSELinuxModeLabel()
+			Expect(nodeLabels()).To(HaveKeyWithValue(v1.SELinuxModeLabel, "enforcing"))
+
+			controller.updateNodeSELinuxModeLabel()
+			Expect(countPatches()).To(Equal(1))
+
+			detectedMode = "permissive"
+			controller.updateNodeSELinuxModeLabel()
+			Expect(nodeLabels()).To(HaveKeyWithValue(v1.SELinuxModeLabel, "permissive"))
+			Expect(countPatches()).To(Equal(2))
+		})
+
+		It("should remove the label when SELinux becomes absent", func() {
+			detectedMode = "enforcing"
+			controller.updateNodeSELinuxModeLabel()
+			Expect(nodeLabels()).To(HaveKey(v1.SELinuxModeLabel))
+
+			detectedMode = "disabled"
+			controller.updateNodeSELinuxModeLabel()
+			Expect(nodeLabels()).To(Equal(map[string]string{"unrelated": "label"}))
+		})
+
+		It("should not set the label on nodes without SELinux", func() {
+			controller.updateNodeSELinuxModeLabel()
+			Expect(nodeLabels()).To(Equal(map[string]string{"unrelated": "label"}))
+		})
+
+		It("should keep the published label when the mode can't be detected", func() {
+			detectedMode = "enforcing"
+			controller.updateNodeSELinuxModeLabel()
+
+			detectedMode = ""
+			detectionErr = fmt.Errorf("getenforce failure")
+			controller.updateNodeSELinuxModeLabel()
+			Expect(nodeLabels()).To(HaveKeyWithValue(v1.SELinuxModeLabel, "enforcing"))
+			Expect(countPatches()).To(Equal(1))
+		})
+	})
+
+	Context("VirtualMachineInstance controller reports the missing SELinux policy modules", func() {
+		var kubeClient *fake.Clientset
+		var enumerator *fakePolicyModuleEnumerator
+
+		BeforeEach(func() {
+			enumerator = &fakePolicyModuleEnumerator{}
+			controller.CheckSELinuxPolicyModules(enumerator)
+			kubeClient = fake.NewSimpleClientset(&k8sv1.Node{ObjectMeta: metav1.ObjectMeta{Name: host}})
+			virtClient.EXPECT().CoreV1().Return(kubeClient.CoreV1()).AnyTimes()
+		})
+
+		nodeCondition := func() *k8sv1.NodeCondition {
+			node, err := kubeClient.CoreV1().Nodes().Get(context.Background(), host, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			for _, condition := range node.Status.Conditions {
+				if condition.Type == k8sv1.NodeConditionType(v1.NodeSELinuxPolicyModulesMissing) {
+					return &condition
+				}
+			}
+			return nil
+		}
+
+		It("should set the condition when required modules are missing", func() {
+			enumerator.modules = []string{"base"}
+			controller.updateNodeSELinuxPolicyModulesCondition()
+
+			condition := nodeCondition()
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(k8sv1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("SELinuxPolicyModulesMissing"))
+			Expect(condition.Message).To(ContainSubstring("container, virt"))
+		})
+
+		It("should clear the condition when the required modules are present", func() {
+			enumerator.modules = selinux.RequiredPolicyModules
+			controller.updateNodeSELinuxPolicyModulesCondition()
+
+			condition := nodeCondition()
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(k8sv1.ConditionFalse))
+		})
+
+		It("should only patch the node when the missing modules change", func() {
+			enumerator.modules = []string{"virt"}
+			controller.updateNodeSELinuxPolicyModulesCondition()
+			controller.updateNodeSELinuxPolicyModulesCondition()
+
+			patches := 0
+			for _, action := range kubeClient.Actions() {
+				if action.GetVerb() == "patch" {
+					patches++
+				}
+			}
+			Expect(patches).To(Equal(1))
+		})
+
+		It("should not set the condition when the modules can't be enumerated", func() {
+			enumerator.err = fmt.Errorf("semodule failure")
+			controller.updateNodeSELinuxPolicyModulesCondition()
+			Expect(nodeCondition()).To(BeNil())
+		})
+	})
+})
+
+type fakePolicyModuleEnumerator struct {
+	modules []string
+	err     error
+}
+
+func (e *fakePolicyModuleEnumerator) LoadedPolicyModules() ([]string, error) {
+	return e.modules, e.err
+}
+
+type fakeSELinux struct {
+	mode string
+}
+
+func (se *fakeSELinux) InstallPolicy(dir string) error {
+	return nil
+}
+
+func (se *fakeSELinux) Mode() string {
+	return se.mode
+}
+
+var _ = Describe("getVCPUPinning", func() {
+	It("should convert the vCPU pins of the domain", func() {
+		pinning, err := getVCPUPinning([]api.CPUTuneVCPUPin{{VCPU: 0, CPUSet: "4"}, {VCPU: 1, CPUSet: "6"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pinning).To(Equal([]v1.VCPUPin{{VCPU: 0, CPU: 4}, {VCPU: 1, CPU: 6}}))
+	})
+
+	It("should fail when a vCPU is pinned to a range of CPUs", func() {
+		_, err := getVCPUPinning([]api.CPUTuneVCPUPin{{VCPU: 0, CPUSet: "4-6"}})
+		Expect(err).To(HaveOccurred())
+	})
 })
 
 var _ = Describe("DomainNotifyServerRestarts", func() {
@@ -29,6 +29,8 @@ import (
 	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/cli"
 	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/converter"
 	domainerrors "kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/errors"
+	hostdevicehealth "kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/hostdevice-health"
+	startupprobe "kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/startup-probe"
 	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/util"
 )
 
@@ -199,7 +201,8 @@ func newWatchEventError(err error) watch.Event {
 }
 
 func eventCallback(c cli.Connection, domain *api.Domain, libvirtEvent libvirtEvent, client *Notifier, events chan watch.Event,
-	interfaceStatus []api.InterfaceStatus, osInfo *api.GuestOSInfo, vmi *v1.VirtualMachineInstance) {
+	interfaceStatus []api.InterfaceStatus, osInfo *api.GuestOSInfo, startupProbe *api.StartupProbeStatus, hostDeviceHealth []api.HostDeviceHealth,
+	vmi *v1.VirtualMachineInstance) {
 	d, err := c.LookupDomainByName(util.DomainFromNamespaceName(domain.ObjectMeta.Namespace, domain.ObjectMeta.Name))
 	if err != nil {
 		if !domainerrors.IsNotFound(err) {
@@ -288,6 +291,8 @@ func eventCallback(c cli.Connection, domain *api.Domain, libvirtEvent libvirtEve
 		if osInfo != nil {
 			domain.Status.OSInfo = *osInfo
 		}
+		domain.Status.StartupProbe = startupProbe
+		domain.Status.HostDevices = hostDeviceHealth
 
 		err := client.SendDomainEvent(watch.Event{Type: watch.Modified, Object: domain})
 		if err != nil {
@@ -313,6 +318,37 @@ func updateEventsClosure() func(event watch.Event, domain *api.Domain, events ch
 	}
 }
 
+// hasHostDevices tells whether host devices are passed through to the VMI,
+// directly, as GPUs or as SR-IOV interfaces.
+func hasHostDevices(vmi *v1.VirtualMachineInstance) bool {
+	if len(vmi.Spec.Domain.Devices.HostDevices) > 0 || len(vmi.Spec.Domain.Devices.GPUs) > 0 {
+		return true
+	}
+	for _, iface := range vmi.Spec.Domain.Devices.Interfaces {
+		if iface.SRIOV != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func listHostDevices(domainConn cli.Connection, domainName string) ([]hostdevicehealth.Device, error) {
+	dom, err := domainConn.LookupDomainByName(domainName)
+	if err != nil {
+		return nil, err
+	}
+	defer dom.Free()
+	state, _, err := dom.GetState()
+	if err != nil {
+		return nil, err
+	}
+	spec, err := util.GetDomainSpec(state, dom)
+	if err != nil {
+		return nil, err
+	}
+	return hostdevicehealth.DevicesFromDomainSpec(spec), nil
+}
+
 func (n *Notifier) StartDomainNotifier(
 	domainConn cli.Connection,
 	deleteNotificationSent chan watch.Event,
@@ -323,6 +359,7 @@ func (n *Notifier) StartDomainNotifier(
 	qemuAgentFileInterval time.Duration,
 	qemuAgentUserInterval time.Duration,
 	qemuAgentVersionInterval time.Duration,
+	hostDeviceHealthInterval time.Duration,
 ) error {
 
 	eventChan := make(chan libvirtEvent, 10)
@@ -344,16 +381,58 @@ func (n *Notifier) StartDomainNotifier(
 		qemuAgentVersionInterval,
 	)
 
+	// the startup probe is evaluated once the domain booted, but not again
+	// on migration targets
+	var startupProber *startupprobe.Prober
+	startupProbeStatuses := make(chan api.StartupProbeStatus, 10)
+	if vmi.Spec.StartupProbe != nil {
+		startupProber = startupprobe.NewProber(vmi.Spec.StartupProbe,
+			func(command string) (string, error) {
+				return domainConn.QemuAgentCommand(command, domainName)
+			},
+			domainerrors.IsAgentUnresponsive,
+			func(status api.StartupProbeStatus) {
+				startupProbeStatuses <- status
+			},
+		)
+	}
+
+	// the host devices are checked from the start of the domain on
+	var hostDeviceMonitor *hostdevicehealth.Monitor
+	hostDeviceHealthUpdates := make(chan []api.HostDeviceHealth, 10)
+	if hostDeviceHealthInterval > 0 && hasHostDevices(vmi) {
+		hostDeviceMonitor = hostdevicehealth.NewMonitor(hostDeviceHealthInterval,
+			func() ([]hostdevicehealth.Device, error) {
+				return listHostDevices(domainConn, domainName)
+			},
+			func(health []api.HostDeviceHealth) {
+				hostDeviceHealthUpdates <- health
+			},
+		)
+	}
+
 	// Run the event process logic in a separate go-routine to not block libvirt
 	go func() {
 		var interfaceStatuses []api.InterfaceStatus
 		var guestOsInfo *api.GuestOSInfo
+		var startupProbeStatus *api.StartupProbeStatus
+		var hostDeviceHealth []api.HostDeviceHealth
 		for {
 			select {
 			case event := <-eventChan:
 				domainCache = util.NewDomainFromName(event.Domain, vmi.UID)
-				eventCallback(domainConn, domainCache, event, n, deleteNotificationSent, interfaceStatuses, guestOsInfo, vmi)
+				eventCallback(domainConn, domainCache, event, n, deleteNotificationSent, interfaceStatuses, guestOsInfo, startupProbeStatus, hostDeviceHealth, vmi)
 				log.Log.Infof("Domain name event: %v", domainCache.Spec.Name)
+				if startupProber != nil && event.Event != nil && event.Event.Event == libvirt.DOMAIN_EVENT_STARTED {
+					if libvirt.DomainEventStartedDetailType(event.Event.Detail) != libvirt.DOMAIN_EVENT_STARTED_MIGRATED {
+						go startupProber.Run(make(chan struct{}))
+					}
+					startupProber = nil
+				}
+				if hostDeviceMonitor != nil && event.Event != nil && event.Event.Event == libvirt.DOMAIN_EVENT_STARTED {
+					go hostDeviceMonitor.Run(make(chan struct{}))
+					hostDeviceMonitor = nil
+				}
 				if event.AgentEvent != nil {
 					if event.AgentEvent.State == libvirt.CONNECT_DOMAIN_EVENT_AGENT_LIFECYCLE_STATE_CONNECTED {
 						agentPoller.Start()
@@ -369,7 +448,19 @@ func (n *Notifier) StartDomainNotifier(
 				}
 
 				eventCallback(domainConn, domainCache, libvirtEvent{}, n, deleteNotificationSent,
-					interfaceStatuses, guestOsInfo, vmi)
+					interfaceStatuses, guestOsInfo, startupProbeStatus, hostDeviceHealth, vmi)
+			case status := <-startupProbeStatuses:
+				startupProbeStatus = &status
+				if domainCache != nil {
+					eventCallback(domainConn, domainCache, libvirtEvent{}, n, deleteNotificationSent,
+						interfaceStatuses, guestOsInfo, startupProbeStatus, hostDeviceHealth, vmi)
+				}
+			case health := <-hostDeviceHealthUpdates:
+				hostDeviceHealth = health
+				if domainCache != nil {
+					eventCallback(domainConn, domainCache, libvirtEvent{}, n, deleteNotificationSent,
+						interfaceStatuses, guestOsInfo, startupProbeStatus, hostDeviceHealth, vmi)
+				}
 			case <-reconnectChan:
 				n.SendDomainEvent(newWatchEventError(fmt.Errorf("Libvirt reconnect, domain %s", domainName)))
 			}
@@ -0,0 +1,80 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package virtlauncher
+
+import (
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"kubevirt.io/client-go/log"
+	diskutils "kubevirt.io/kubevirt/pkg/ephemeral-disk-utils"
+)
+
+const defaultReadinessPollInterval = 1 * time.Second
+
+// ReadinessGate holds the readiness of the launcher until virt-handler
+// confirmed that it applied the SELinux labels of the VMI. virt-handler
+// confirms it by creating the relabeled file, once it reported the
+// SELinuxLabelsApplied condition of the VMI. The gate then creates the ready
+// file, which the readiness probe of the compute container checks.
+type ReadinessGate struct {
+	relabeledFile string
+	readyFile     string
+	pollInterval  time.Duration
+}
+
+func NewReadinessGate(relabeledFile string, readyFile string) *ReadinessGate {
+	return &ReadinessGate{
+		relabeledFile: relabeledFile,
+		readyFile:     readyFile,
+		pollInterval:  defaultReadinessPollInterval,
+	}
+}
+
+// Run blocks until virt-handler confirmed the relabeling and the ready file
+// is created, or until stopChan is closed.
+func (g *ReadinessGate) Run(stopChan <-chan struct{}) error {
+	if err := os.Remove(g.readyFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	err := wait.PollImmediateUntil(g.pollInterval, func() (bool, error) {
+		return diskutils.FileExists(g.relabeledFile)
+	}, stopChan)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(g.readyFile)
+	if err != nil {
+		return err
+	}
+	f.Close()
+	log.Log.Info("SELinux relabeling confirmed, marked the launcher as ready")
+	return nil
+}
+
+// IsOpen returns whether the gate already let the launcher become ready.
+func (g *ReadinessGate) IsOpen() bool {
+	exists, _ := diskutils.FileExists(g.readyFile)
+	return exists
+}
@@ -0,0 +1,118 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package virtlauncher
+
+import (
+	"io/ioutil"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	cmdclient "kubevirt.io/kubevirt/pkg/virt-handler/cmd-client"
+)
+
+var _ = Describe("Readiness gate", func() {
+	var shareDir string
+	var gate *ReadinessGate
+	var stopChan chan struct{}
+	var done chan error
+
+	// fakeHandler confirms the relabeling the way virt-handler does, through
+	// the socket of the launcher
+	fakeHandler := func(delay time.Duration) {
+		go func() {
+			defer GinkgoRecover()
+			time.Sleep(delay)
+			Expect(cmdclient.MarkSELinuxRelabeled(cmdclient.SocketOnGuest())).To(Succeed())
+		}()
+	}
+
+	runGate := func() {
+		go func() {
+			done <- gate.Run(stopChan)
+		}()
+	}
+
+	BeforeEach(func() {
+		var err error
+		shareDir, err = ioutil.TempDir("", "launcher-readiness")
+		Expect(err).ToNot(HaveOccurred())
+		cmdclient.SetLegacyBaseDir(shareDir)
+		Expect(os.MkdirAll(cmdclient.LegacySocketsDirectory(), 0755)).To(Succeed())
+		f, err := os.Create(cmdclient.SocketOnGuest())
+		Expect(err).ToNot(HaveOccurred())
+		f.Close()
+
+		gate = NewReadinessGate(cmdclient.SELinuxRelabeledFileOnGuest(), cmdclient.ReadyFileOnGuest())
+		gate.pollInterval = 10 * time.Millisecond
+		stopChan = make(chan struct{})
+		done = make(chan error, 1)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(shareDir)
+	})
+
+	It("should hold the readiness until the handler confirms the relabeling", func() {
+		runGate()
+		Consistently(gate.IsOpen, 100*time.Millisecond, 10*time.Millisecond).Should(BeFalse())
+		Expect(cmdclient.ReadyFileOnGuest()).ToNot(BeAnExistingFile())
+
+		fakeHandler(0)
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(gate.IsOpen()).To(BeTrue())
+		Expect(cmdclient.ReadyFileOnGuest()).To(BeAnExistingFile())
+	})
+
+	It("should become ready right away if the handler already confirmed the relabeling", func() {
+		Expect(cmdclient.MarkSELinuxRelabeled(cmdclient.SocketOnGuest())).To(Succeed())
+		runGate()
+		Eventually(done).Should(Receive(BeNil()))
+		Expect(gate.IsOpen()).To(BeTrue())
+	})
+
+	It("should wait for a handler confirming the relabeling late", func() {
+		fakeHandler(200 * time.Millisecond)
+		runGate()
+		Eventually(done, 5*time.Second).Should(Receive(BeNil()))
+		Expect(gate.IsOpen()).To(BeTrue())
+	})
+
+	It("should drop a stale ready file", func() {
+		f, err := os.Create(cmdclient.ReadyFileOnGuest())
+		Expect(err).ToNot(HaveOccurred())
+		f.Close()
+
+		runGate()
+		Eventually(gate.IsOpen).Should(BeFalse())
+		close(stopChan)
+		Eventually(done).Should(Receive(Equal(wait.ErrWaitTimeout)))
+	})
+
+	It("should stay closed when stopped before the handler confirmed the relabeling", func() {
+		runGate()
+		close(stopChan)
+		Eventually(done).Should(Receive(Equal(wait.ErrWaitTimeout)))
+		Expect(gate.IsOpen()).To(BeFalse())
+	})
+})
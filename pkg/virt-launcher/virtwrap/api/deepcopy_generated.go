@@ -421,7 +421,9 @@ func (in *Clock) DeepCopyInto(out *Clock) {
 	if in.Timer != nil {
 		in, out := &in.Timer, &out.Timer
 		*out = make([]Timer, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
@@ -691,6 +693,11 @@ func (in *Devices) DeepCopyInto(out *Devices) {
 		*out = new(Rng)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Panic != nil {
+		in, out := &in.Panic, &out.Panic
+		*out = new(PanicDevice)
+		**out = **in
+	}
 	if in.Filesystems != nil {
 		in, out := &in.Filesystems, &out.Filesystems
 		*out = make([]FilesystemDevice, len(*in))
@@ -751,6 +758,11 @@ func (in *Disk) DeepCopyInto(out *Disk) {
 		*out = new(Address)
 		**out = **in
 	}
+	if in.IOTune != nil {
+		in, out := &in.IOTune, &out.IOTune
+		*out = new(IOTune)
+		**out = **in
+	}
 	return
 }
 
@@ -1018,6 +1030,11 @@ func (in *DomainSpec) DeepCopyInto(out *DomainSpec) {
 		*out = new(CPUTune)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NUMATune != nil {
+		in, out := &in.NUMATune, &out.NUMATune
+		*out = new(NUMATune)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.IOThreads != nil {
 		in, out := &in.IOThreads, &out.IOThreads
 		*out = new(IOThreads)
@@ -1047,6 +1064,16 @@ func (in *DomainStatus) DeepCopyInto(out *DomainStatus) {
 		}
 	}
 	out.OSInfo = in.OSInfo
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = new(StartupProbeStatus)
+		**out = **in
+	}
+	if in.HostDevices != nil {
+		in, out := &in.HostDevices, &out.HostDevices
+		*out = make([]HostDeviceHealth, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1372,6 +1399,11 @@ func (in *FilesystemBinary) DeepCopyInto(out *FilesystemBinary) {
 		*out = new(FilesystemBinaryCache)
 		**out = **in
 	}
+	if in.Sandbox != nil {
+		in, out := &in.Sandbox, &out.Sandbox
+		*out = new(FilesystemBinarySandbox)
+		**out = **in
+	}
 	if in.Lock != nil {
 		in, out := &in.Lock, &out.Lock
 		*out = new(FilesystemBinaryLock)
@@ -1422,6 +1454,22 @@ func (in *FilesystemBinaryLock) DeepCopy() *FilesystemBinaryLock {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FilesystemBinarySandbox) DeepCopyInto(out *FilesystemBinarySandbox) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemBinarySandbox.
+func (in *FilesystemBinarySandbox) DeepCopy() *FilesystemBinarySandbox {
+	if in == nil {
+		return nil
+	}
+	out := new(FilesystemBinarySandbox)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FilesystemDevice) DeepCopyInto(out *FilesystemDevice) {
 	*out = *in
@@ -1619,6 +1667,11 @@ func (in *HostDevice) DeepCopyInto(out *HostDevice) {
 		*out = new(Alias)
 		**out = **in
 	}
+	if in.Rom != nil {
+		in, out := &in.Rom, &out.Rom
+		*out = new(Rom)
+		**out = **in
+	}
 	return
 }
 
@@ -1632,6 +1685,22 @@ func (in *HostDevice) DeepCopy() *HostDevice {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostDeviceHealth) DeepCopyInto(out *HostDeviceHealth) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostDeviceHealth.
+func (in *HostDeviceHealth) DeepCopy() *HostDeviceHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(HostDeviceHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostDeviceSource) DeepCopyInto(out *HostDeviceSource) {
 	*out = *in
@@ -1706,6 +1775,22 @@ func (in *IOThreads) DeepCopy() *IOThreads {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IOTune) DeepCopyInto(out *IOTune) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IOTune.
+func (in *IOTune) DeepCopy() *IOTune {
+	if in == nil {
+		return nil
+	}
+	out := new(IOTune)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Input) DeepCopyInto(out *Input) {
 	*out = *in
@@ -1906,6 +1991,21 @@ func (in *KubeVirtMetadata) DeepCopyInto(out *KubeVirtMetadata) {
 		*out = new(AccessCredentialMetadata)
 		**out = **in
 	}
+	if in.SoftReboot != nil {
+		in, out := &in.SoftReboot, &out.SoftReboot
+		*out = new(SoftRebootMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MemoryDump != nil {
+		in, out := &in.MemoryDump, &out.MemoryDump
+		*out = new(MemoryDumpMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VCPURebalance != nil {
+		in, out := &in.VCPURebalance, &out.VCPURebalance
+		*out = new(VCPURebalanceMetadata)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2009,6 +2109,22 @@ func (in *MemBalloon) DeepCopy() *MemBalloon {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemNode) DeepCopyInto(out *MemNode) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemNode.
+func (in *MemNode) DeepCopy() *MemNode {
+	if in == nil {
+		return nil
+	}
+	out := new(MemNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Memory) DeepCopyInto(out *Memory) {
 	*out = *in
@@ -2043,6 +2159,11 @@ func (in *MemoryBacking) DeepCopyInto(out *MemoryBacking) {
 		*out = new(MemoryBackingAccess)
 		**out = **in
 	}
+	if in.Locked != nil {
+		in, out := &in.Locked, &out.Locked
+		*out = new(MemoryBackingLocked)
+		**out = **in
+	}
 	return
 }
 
@@ -2072,6 +2193,22 @@ func (in *MemoryBackingAccess) DeepCopy() *MemoryBackingAccess {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemoryBackingLocked) DeepCopyInto(out *MemoryBackingLocked) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemoryBackingLocked.
+func (in *MemoryBackingLocked) DeepCopy() *MemoryBackingLocked {
+	if in == nil {
+		return nil
+	}
+	out := new(MemoryBackingLocked)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MemoryBackingSource) DeepCopyInto(out *MemoryBackingSource) {
 	*out = *in
@@ -2088,6 +2225,30 @@ func (in *MemoryBackingSource) DeepCopy() *MemoryBackingSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemoryDumpMetadata) DeepCopyInto(out *MemoryDumpMetadata) {
+	*out = *in
+	if in.StartTimestamp != nil {
+		in, out := &in.StartTimestamp, &out.StartTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.EndTimestamp != nil {
+		in, out := &in.EndTimestamp, &out.EndTimestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemoryDumpMetadata.
+func (in *MemoryDumpMetadata) DeepCopy() *MemoryDumpMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(MemoryDumpMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Metadata) DeepCopyInto(out *Metadata) {
 	*out = *in
@@ -2116,6 +2277,11 @@ func (in *MigrationMetadata) DeepCopyInto(out *MigrationMetadata) {
 		in, out := &in.EndTimestamp, &out.EndTimestamp
 		*out = (*in).DeepCopy()
 	}
+	if in.WarmupStatus != nil {
+		in, out := &in.WarmupStatus, &out.WarmupStatus
+		*out = new(MigrationWarmupMetadata)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2129,6 +2295,26 @@ func (in *MigrationMetadata) DeepCopy() *MigrationMetadata {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationWarmupMetadata) DeepCopyInto(out *MigrationWarmupMetadata) {
+	*out = *in
+	if in.LastUpdateTimestamp != nil {
+		in, out := &in.LastUpdateTimestamp, &out.LastUpdateTimestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationWarmupMetadata.
+func (in *MigrationWarmupMetadata) DeepCopy() *MigrationWarmupMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationWarmupMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Model) DeepCopyInto(out *Model) {
 	*out = *in
@@ -2182,6 +2368,44 @@ func (in *NUMACell) DeepCopy() *NUMACell {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NUMATune) DeepCopyInto(out *NUMATune) {
+	*out = *in
+	out.Memory = in.Memory
+	if in.MemNodes != nil {
+		in, out := &in.MemNodes, &out.MemNodes
+		*out = make([]MemNode, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NUMATune.
+func (in *NUMATune) DeepCopy() *NUMATune {
+	if in == nil {
+		return nil
+	}
+	out := new(NUMATune)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NUMATuneMemory) DeepCopyInto(out *NUMATuneMemory) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NUMATuneMemory.
+func (in *NUMATuneMemory) DeepCopy() *NUMATuneMemory {
+	if in == nil {
+		return nil
+	}
+	out := new(NUMATuneMemory)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NVRam) DeepCopyInto(out *NVRam) {
 	*out = *in
@@ -2198,6 +2422,27 @@ func (in *NVRam) DeepCopy() *NVRam {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OEMStrings) DeepCopyInto(out *OEMStrings) {
+	*out = *in
+	if in.Entries != nil {
+		in, out := &in.Entries, &out.Entries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OEMStrings.
+func (in *OEMStrings) DeepCopy() *OEMStrings {
+	if in == nil {
+		return nil
+	}
+	out := new(OEMStrings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OS) DeepCopyInto(out *OS) {
 	*out = *in
@@ -2261,6 +2506,22 @@ func (in *OSType) DeepCopy() *OSType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PanicDevice) DeepCopyInto(out *PanicDevice) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PanicDevice.
+func (in *PanicDevice) DeepCopy() *PanicDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(PanicDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReadOnly) DeepCopyInto(out *ReadOnly) {
 	*out = *in
@@ -2485,6 +2746,42 @@ func (in *SerialTarget) DeepCopy() *SerialTarget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SoftRebootMetadata) DeepCopyInto(out *SoftRebootMetadata) {
+	*out = *in
+	if in.Timestamp != nil {
+		in, out := &in.Timestamp, &out.Timestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SoftRebootMetadata.
+func (in *SoftRebootMetadata) DeepCopy() *SoftRebootMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(SoftRebootMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StartupProbeStatus) DeepCopyInto(out *StartupProbeStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartupProbeStatus.
+func (in *StartupProbeStatus) DeepCopy() *StartupProbeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StartupProbeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Stats) DeepCopyInto(out *Stats) {
 	*out = *in
@@ -2524,6 +2821,11 @@ func (in *SysInfo) DeepCopyInto(out *SysInfo) {
 		*out = make([]Entry, len(*in))
 		copy(*out, *in)
 	}
+	if in.OEMStrings != nil {
+		in, out := &in.OEMStrings, &out.OEMStrings
+		*out = new(OEMStrings)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2540,6 +2842,11 @@ func (in *SysInfo) DeepCopy() *SysInfo {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Timer) DeepCopyInto(out *Timer) {
 	*out = *in
+	if in.Catchup != nil {
+		in, out := &in.Catchup, &out.Catchup
+		*out = new(TimerCatchup)
+		**out = **in
+	}
 	return
 }
 
@@ -2553,6 +2860,22 @@ func (in *Timer) DeepCopy() *Timer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimerCatchup) DeepCopyInto(out *TimerCatchup) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimerCatchup.
+func (in *TimerCatchup) DeepCopy() *TimerCatchup {
+	if in == nil {
+		return nil
+	}
+	out := new(TimerCatchup)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Timezone) DeepCopyInto(out *Timezone) {
 	*out = *in
@@ -2601,6 +2924,26 @@ func (in *VCPU) DeepCopy() *VCPU {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VCPURebalanceMetadata) DeepCopyInto(out *VCPURebalanceMetadata) {
+	*out = *in
+	if in.Timestamp != nil {
+		in, out := &in.Timestamp, &out.Timestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VCPURebalanceMetadata.
+func (in *VCPURebalanceMetadata) DeepCopy() *VCPURebalanceMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(VCPURebalanceMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Video) DeepCopyInto(out *Video) {
 	*out = *in
@@ -106,6 +106,30 @@ type DomainStatus struct {
 	Reason     StateChangeReason
 	Interfaces []InterfaceStatus
 	OSInfo     GuestOSInfo
+
+	// StartupProbe is the last result of the startup probe of the VMI, nil
+	// until the probe is evaluated
+	StartupProbe *StartupProbeStatus
+	// HostDevices is the last health of the host devices assigned to the
+	// domain, nil until the devices are checked
+	HostDevices []HostDeviceHealth
+}
+
+// HostDeviceHealth is the health of a host device assigned to the domain, as
+// seen in the sysfs of the node. Message explains why a device is degraded.
+type HostDeviceHealth struct {
+	Name    string
+	Address string
+	Healthy bool
+	Message string
+}
+
+// StartupProbeStatus is the result of a startup probe evaluated through the
+// guest agent. Reason and Message explain why the probe did not succeed yet.
+type StartupProbeStatus struct {
+	Succeeded bool
+	Reason    string
+	Message   string
 }
 
 type DomainSysInfo struct {
@@ -187,7 +211,9 @@ type DomainSpec struct {
 	CPU           CPU            `xml:"cpu"`
 	VCPU          *VCPU          `xml:"vcpu"`
 	CPUTune       *CPUTune       `xml:"cputune"`
+	NUMATune      *NUMATune      `xml:"numatune,omitempty"`
 	IOThreads     *IOThreads     `xml:"iothreads,omitempty"`
+	OnCrash       string         `xml:"on_crash,omitempty"`
 }
 
 type CPUTune struct {
@@ -210,6 +236,22 @@ type CPUEmulatorPin struct {
 	CPUSet string `xml:"cpuset,attr"`
 }
 
+type NUMATune struct {
+	Memory   NUMATuneMemory `xml:"memory"`
+	MemNodes []MemNode      `xml:"memnode"`
+}
+
+type NUMATuneMemory struct {
+	Mode    string `xml:"mode,attr"`
+	NodeSet string `xml:"nodeset,attr"`
+}
+
+type MemNode struct {
+	CellID  uint32 `xml:"cellid,attr"`
+	Mode    string `xml:"mode,attr"`
+	NodeSet string `xml:"nodeset,attr"`
+}
+
 type VCPU struct {
 	Placement string `xml:"placement,attr"`
 	CPUs      uint32 `xml:",chardata"`
@@ -308,6 +350,33 @@ type KubeVirtMetadata struct {
 	GracePeriod      *GracePeriodMetadata      `xml:"graceperiod,omitempty"`
 	Migration        *MigrationMetadata        `xml:"migration,omitempty"`
 	AccessCredential *AccessCredentialMetadata `xml:"accessCredential,omitempty"`
+	SoftReboot       *SoftRebootMetadata       `xml:"softReboot,omitempty"`
+	MemoryDump       *MemoryDumpMetadata       `xml:"memoryDump,omitempty"`
+	VCPURebalance    *VCPURebalanceMetadata    `xml:"vcpuRebalance,omitempty"`
+}
+
+// VCPURebalanceMetadata records the migration the vCPU pinning was last rebalanced after
+type VCPURebalanceMetadata struct {
+	MigrationUID types.UID    `xml:"migrationUid,omitempty"`
+	Timestamp    *metav1.Time `xml:"timestamp,omitempty"`
+}
+
+// MemoryDumpMetadata records the progress and the outcome of the last memory dump of the guest
+type MemoryDumpMetadata struct {
+	FileName       string       `xml:"fileName,omitempty"`
+	StartTimestamp *metav1.Time `xml:"startTimestamp,omitempty"`
+	EndTimestamp   *metav1.Time `xml:"endTimestamp,omitempty"`
+	Completed      bool         `xml:"completed,omitempty"`
+	Failed         bool         `xml:"failed,omitempty"`
+	FailureReason  string       `xml:"failureReason,omitempty"`
+	BytesWritten   int64        `xml:"bytesWritten,omitempty"`
+	BytesTotal     int64        `xml:"bytesTotal,omitempty"`
+}
+
+// SoftRebootMetadata records how the last soft reboot was signaled to the guest
+type SoftRebootMetadata struct {
+	Mechanism string       `xml:"mechanism"`
+	Timestamp *metav1.Time `xml:"timestamp,omitempty"`
 }
 
 type AccessCredentialMetadata struct {
@@ -324,6 +393,20 @@ type MigrationMetadata struct {
 	FailureReason  string           `xml:"failureReason,omitempty"`
 	AbortStatus    string           `xml:"abortStatus,omitempty"`
 	Mode           v1.MigrationMode `xml:"mode,omitempty"`
+	// Warmup keeps the migration in pre-copy until CutoverCommitted is set
+	Warmup           bool                     `xml:"warmup,omitempty"`
+	CutoverCommitted bool                     `xml:"cutoverCommitted,omitempty"`
+	WarmupStatus     *MigrationWarmupMetadata `xml:"warmupStatus,omitempty"`
+}
+
+type MigrationWarmupMetadata struct {
+	Iteration                     int64        `xml:"iteration"`
+	MemoryRemainingBytes          int64        `xml:"memoryRemainingBytes"`
+	DirtyRateBytesPerSecond       int64        `xml:"dirtyRateBytesPerSecond"`
+	TransferRateBytesPerSecond    int64        `xml:"transferRateBytesPerSecond"`
+	Converging                    bool         `xml:"converging,omitempty"`
+	EstimatedDowntimeMilliseconds int64        `xml:"estimatedDowntimeMilliseconds"`
+	LastUpdateTimestamp           *metav1.Time `xml:"lastUpdateTimestamp,omitempty"`
 }
 
 type GracePeriodMetadata struct {
@@ -360,6 +443,10 @@ type MemoryBacking struct {
 	HugePages *HugePages           `xml:"hugepages,omitempty"`
 	Source    *MemoryBackingSource `xml:"source,omitempty"`
 	Access    *MemoryBackingAccess `xml:"access,omitempty"`
+	Locked    *MemoryBackingLocked `xml:"locked,omitempty"`
+}
+
+type MemoryBackingLocked struct {
 }
 
 type MemoryBackingSource struct {
@@ -373,8 +460,9 @@ type HugePages struct {
 
 // HugePage mirroring libvirt XML under hugepages
 type HugePage struct {
-	Size string `xml:"size,attr"`
-	Unit string `xml:"unit,attr"`
+	Size    string `xml:"size,attr"`
+	Unit    string `xml:"unit,attr"`
+	NodeSet string `xml:"nodeset,attr,omitempty"`
 }
 
 type MemoryBackingAccess struct {
@@ -397,6 +485,7 @@ type Devices struct {
 	Watchdog    *Watchdog          `xml:"watchdog,omitempty"`
 	Rng         *Rng               `xml:"rng,omitempty"`
 	Filesystems []FilesystemDevice `xml:"filesystem,omitempty"`
+	Panic       *PanicDevice       `xml:"panic,omitempty"`
 }
 
 type FilesystemDevice struct {
@@ -422,16 +511,21 @@ type FilesystemDriver struct {
 }
 
 type FilesystemBinary struct {
-	Path  string                 `xml:"path,attr,omitempty"`
-	Xattr string                 `xml:"xattr,attr,omitempty"`
-	Cache *FilesystemBinaryCache `xml:"cache,omitempty"`
-	Lock  *FilesystemBinaryLock  `xml:"lock,omitempty"`
+	Path    string                   `xml:"path,attr,omitempty"`
+	Xattr   string                   `xml:"xattr,attr,omitempty"`
+	Cache   *FilesystemBinaryCache   `xml:"cache,omitempty"`
+	Sandbox *FilesystemBinarySandbox `xml:"sandbox,omitempty"`
+	Lock    *FilesystemBinaryLock    `xml:"lock,omitempty"`
 }
 
 type FilesystemBinaryCache struct {
 	Mode string `xml:"mode,attr,omitempty"`
 }
 
+type FilesystemBinarySandbox struct {
+	Mode string `xml:"mode,attr,omitempty"`
+}
+
 type FilesystemBinaryLock struct {
 	Posix string `xml:"posix,attr,omitempty"`
 	Flock string `xml:"flock,attr,omitempty"`
@@ -456,6 +550,7 @@ type HostDevice struct {
 	Model     string           `xml:"model,attr,omitempty"`
 	Address   *Address         `xml:"address,emitempty"`
 	Alias     *Alias           `xml:"alias,omitempty"`
+	Rom       *Rom             `xml:"rom,omitempty"`
 }
 
 type HostDeviceSource struct {
@@ -494,6 +589,7 @@ type Disk struct {
 	Source       DiskSource    `xml:"source"`
 	Target       DiskTarget    `xml:"target"`
 	Serial       string        `xml:"serial,omitempty"`
+	WWN          string        `xml:"wwn,omitempty"`
 	Driver       *DiskDriver   `xml:"driver,omitempty"`
 	ReadOnly     *ReadOnly     `xml:"readonly,omitempty"`
 	Auth         *DiskAuth     `xml:"auth,omitempty"`
@@ -502,6 +598,17 @@ type Disk struct {
 	BootOrder    *BootOrder    `xml:"boot,omitempty"`
 	Address      *Address      `xml:"address,omitempty"`
 	Model        string        `xml:"model,attr,omitempty"`
+	IOTune       *IOTune       `xml:"iotune,omitempty"`
+}
+
+type IOTune struct {
+	TotalBytesSec uint64 `xml:"total_bytes_sec,omitempty"`
+	ReadBytesSec  uint64 `xml:"read_bytes_sec,omitempty"`
+	WriteBytesSec uint64 `xml:"write_bytes_sec,omitempty"`
+	TotalIopsSec  uint64 `xml:"total_iops_sec,omitempty"`
+	ReadIopsSec   uint64 `xml:"read_iops_sec,omitempty"`
+	WriteIopsSec  uint64 `xml:"write_iops_sec,omitempty"`
+	GroupName     string `xml:"group_name,omitempty"`
 }
 
 type DiskAuth struct {
@@ -677,7 +784,9 @@ type userAliasMarshal struct {
 }
 
 type Rom struct {
-	Enabled string `xml:"enabled,attr"`
+	Enabled string `xml:"enabled,attr,omitempty"`
+	Bar     string `xml:"bar,attr,omitempty"`
+	File    string `xml:"file,attr,omitempty"`
 }
 
 func NewUserDefinedAlias(aliasName string) *Alias {
@@ -777,15 +886,21 @@ type Loader struct {
 
 // TODO <bios rebootTimeout='0'/>
 type BIOS struct {
-	UseSerial string `xml:"useserial,attr,omitempty"`
+	UseSerial     string `xml:"useserial,attr,omitempty"`
+	RebootTimeout string `xml:"rebootTimeout,attr,omitempty"`
 }
 
 type SysInfo struct {
-	Type      string  `xml:"type,attr"`
-	System    []Entry `xml:"system>entry"`
-	BIOS      []Entry `xml:"bios>entry"`
-	BaseBoard []Entry `xml:"baseBoard>entry"`
-	Chassis   []Entry `xml:"chassis>entry"`
+	Type       string      `xml:"type,attr"`
+	System     []Entry     `xml:"system>entry"`
+	BIOS       []Entry     `xml:"bios>entry"`
+	BaseBoard  []Entry     `xml:"baseBoard>entry"`
+	Chassis    []Entry     `xml:"chassis>entry"`
+	OEMStrings *OEMStrings `xml:"oemStrings,omitempty"`
+}
+
+type OEMStrings struct {
+	Entries []string `xml:"entry"`
 }
 
 type Entry struct {
@@ -805,10 +920,17 @@ type Clock struct {
 }
 
 type Timer struct {
-	Name       string `xml:"name,attr"`
-	TickPolicy string `xml:"tickpolicy,attr,omitempty"`
-	Present    string `xml:"present,attr,omitempty"`
-	Track      string `xml:"track,attr,omitempty"`
+	Name       string        `xml:"name,attr"`
+	TickPolicy string        `xml:"tickpolicy,attr,omitempty"`
+	Present    string        `xml:"present,attr,omitempty"`
+	Track      string        `xml:"track,attr,omitempty"`
+	Catchup    *TimerCatchup `xml:"catchup,omitempty"`
+}
+
+type TimerCatchup struct {
+	Threshold uint32 `xml:"threshold,attr,omitempty"`
+	Slew      uint32 `xml:"slew,attr,omitempty"`
+	Limit     uint32 `xml:"limit,attr,omitempty"`
 }
 
 //END Clock --------------------
@@ -894,6 +1016,11 @@ type MemBalloon struct {
 	Address *Address `xml:"address,emitempty"`
 }
 
+// PanicDevice is the device through which the guest reports its crashes to the hypervisor
+type PanicDevice struct {
+	Model string `xml:"model,attr,omitempty"`
+}
+
 type Watchdog struct {
 	Model   string   `xml:"model,attr"`
 	Action  string   `xml:"action,attr"`
@@ -0,0 +1,214 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package balloon
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"libvirt.org/libvirt-go"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/client-go/log"
+	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/api"
+	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/cli"
+	domainerrors "kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/errors"
+)
+
+const defaultPressureFile = "/sys/fs/cgroup/memory.pressure"
+
+var (
+	// pressureThreshold is the share of time in percent, averaged over ten
+	// seconds, the pod stalled on memory above which guest memory is reclaimed
+	pressureThreshold = 10.0
+	// releaseThreshold is the memory pressure below which the reclaimed guest
+	// memory is handed back
+	releaseThreshold = 1.0
+	// reclaimStepPercent is the share of the guest memory reclaimed or handed
+	// back at once
+	reclaimStepPercent uint64 = 10
+)
+
+// BalloonManager reclaims guest memory through the memory balloon when the
+// memory of the pod comes under pressure, before the kernel OOM-kills qemu.
+type BalloonManager struct {
+	virConn cli.Connection
+
+	pressureFile  string
+	checkInterval time.Duration
+
+	lock    sync.Mutex
+	started bool
+	stopCh  chan struct{}
+}
+
+func NewManager(connection cli.Connection) *BalloonManager {
+	return &BalloonManager{
+		virConn:       connection,
+		pressureFile:  defaultPressureFile,
+		checkInterval: 5 * time.Second,
+		stopCh:        make(chan struct{}),
+	}
+}
+
+// HandleBalloonFloor starts watching the memory pressure of the pod if the
+// VMI has a balloon floor. It does nothing if the watch already started.
+func (m *BalloonManager) HandleBalloonFloor(vmi *v1.VirtualMachineInstance) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.started || vmi.Spec.Domain.Memory == nil || vmi.Spec.Domain.Memory.BalloonFloor == nil {
+		return nil
+	}
+
+	if _, err := readPressure(m.pressureFile); err != nil {
+		// cgroups v1 or a kernel without pressure stall information
+		log.Log.Object(vmi).Reason(err).Warning("The memory pressure of the pod can't be read, guest memory won't be reclaimed")
+		return nil
+	}
+
+	floorKiB := uint64(vmi.Spec.Domain.Memory.BalloonFloor.Value() / 1024)
+	go m.watchPressure(vmi, api.VMINamespaceKeyFunc(vmi), floorKiB)
+	m.started = true
+	return nil
+}
+
+func (m *BalloonManager) watchPressure(vmi *v1.VirtualMachineInstance, domName string, floorKiB uint64) {
+	logger := log.Log.Object(vmi)
+	ticker := time.NewTicker(m.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			if err := m.adjustBalloon(domName, floorKiB); err != nil {
+				logger.Reason(err).Warning("Failed to adjust the memory balloon to the memory pressure")
+			}
+		}
+	}
+}
+
+// adjustBalloon inflates the balloon by one step if the memory pressure is
+// above pressureThreshold, without leaving less than floorKiB to the guest,
+// and deflates it by one step once the pressure is below releaseThreshold.
+func (m *BalloonManager) adjustBalloon(domName string, floorKiB uint64) error {
+	pressure, err := readPressure(m.pressureFile)
+	if err != nil {
+		return err
+	}
+	if pressure < pressureThreshold && pressure > releaseThreshold {
+		return nil
+	}
+
+	dom, err := m.virConn.LookupDomainByName(domName)
+	if err != nil {
+		if domainerrors.IsNotFound(err) {
+			// the domain is not started yet or already gone
+			return nil
+		}
+		return err
+	}
+	defer dom.Free()
+
+	maxKiB, err := dom.GetMaxMemory()
+	if err != nil {
+		return err
+	}
+	currentKiB, err := actualBalloon(dom)
+	if err != nil {
+		return err
+	}
+
+	target := nextBalloonTarget(pressure, currentKiB, maxKiB, floorKiB)
+	if target == currentKiB {
+		return nil
+	}
+	log.Log.V(2).Infof("Memory pressure of %.2f%%, resizing the memory balloon of %s from %dKiB to %dKiB", pressure, domName, currentKiB, target)
+	return dom.SetMemory(target)
+}
+
+func nextBalloonTarget(pressure float64, currentKiB uint64, maxKiB uint64, floorKiB uint64) uint64 {
+	if floorKiB >= maxKiB {
+		// nothing can be reclaimed
+		return currentKiB
+	}
+	step := maxKiB * reclaimStepPercent / 100
+	switch {
+	case pressure >= pressureThreshold:
+		if currentKiB <= floorKiB+step {
+			return floorKiB
+		}
+		return currentKiB - step
+	case pressure <= releaseThreshold:
+		if currentKiB+step >= maxKiB {
+			return maxKiB
+		}
+		return currentKiB + step
+	}
+	return currentKiB
+}
+
+func actualBalloon(dom cli.VirDomain) (uint64, error) {
+	stats, err := dom.MemoryStats(uint32(libvirt.DOMAIN_MEMORY_STAT_NR), 0)
+	if err != nil {
+		return 0, err
+	}
+	for _, stat := range stats {
+		if libvirt.DomainMemoryStatTags(stat.Tag) == libvirt.DOMAIN_MEMORY_STAT_ACTUAL_BALLOON {
+			return stat.Val, nil
+		}
+	}
+	return 0, fmt.Errorf("the domain does not report the size of its memory balloon")
+}
+
+// readPressure returns the share of time in percent the tasks of the cgroup
+// stalled on memory over the last ten seconds, read from the "some avg10"
+// field of the given memory.pressure file.
+func readPressure(path string) (float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "some" {
+			continue
+		}
+		for _, field := range fields[1:] {
+			if strings.HasPrefix(field, "avg10=") {
+				return strconv.ParseFloat(strings.TrimPrefix(field, "avg10="), 64)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("no memory pressure found in %s", path)
+}
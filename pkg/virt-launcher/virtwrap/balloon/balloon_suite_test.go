@@ -0,0 +1,13 @@
+package balloon_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestBalloon(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Balloon Suite")
+}
@@ -0,0 +1,188 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package balloon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"libvirt.org/libvirt-go"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/cli"
+)
+
+var _ = Describe("Balloon", func() {
+	const (
+		domName  = "default_testvmi"
+		maxKiB   = 1048576
+		floorKiB = 524288
+	)
+
+	var ctrl *gomock.Controller
+	var mockConn *cli.MockConnection
+	var mockDomain *cli.MockVirDomain
+	var manager *BalloonManager
+	var tmpDir string
+
+	setPressure := func(avg10 float64) {
+		content := fmt.Sprintf("some avg10=%.2f avg60=0.00 avg300=0.00 total=1234\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n", avg10)
+		Expect(ioutil.WriteFile(manager.pressureFile, []byte(content), 0644)).To(Succeed())
+	}
+
+	expectBalloon := func(currentKiB uint64) {
+		mockConn.EXPECT().LookupDomainByName(domName).Return(mockDomain, nil)
+		mockDomain.EXPECT().Free()
+		mockDomain.EXPECT().GetMaxMemory().Return(uint64(maxKiB), nil)
+		mockDomain.EXPECT().MemoryStats(uint32(libvirt.DOMAIN_MEMORY_STAT_NR), uint32(0)).Return([]libvirt.DomainMemoryStat{
+			{Tag: int32(libvirt.DOMAIN_MEMORY_STAT_AVAILABLE), Val: maxKiB},
+			{Tag: int32(libvirt.DOMAIN_MEMORY_STAT_ACTUAL_BALLOON), Val: currentKiB},
+		}, nil)
+	}
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		mockConn = cli.NewMockConnection(ctrl)
+		mockDomain = cli.NewMockVirDomain(ctrl)
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "balloon")
+		Expect(err).ToNot(HaveOccurred())
+		manager = NewManager(mockConn)
+		manager.pressureFile = filepath.Join(tmpDir, "memory.pressure")
+	})
+
+	AfterEach(func() {
+		close(manager.stopCh)
+		os.RemoveAll(tmpDir)
+		ctrl.Finish()
+	})
+
+	Context("past the pressure threshold", func() {
+		BeforeEach(func() {
+			setPressure(pressureThreshold + 5)
+		})
+
+		It("should inflate the balloon by one step", func() {
+			expectBalloon(maxKiB)
+			mockDomain.EXPECT().SetMemory(uint64(maxKiB - maxKiB/10)).Return(nil)
+
+			Expect(manager.adjustBalloon(domName, floorKiB)).To(Succeed())
+		})
+
+		It("should not inflate the balloon below the floor", func() {
+			expectBalloon(floorKiB + 1024)
+			mockDomain.EXPECT().SetMemory(uint64(floorKiB)).Return(nil)
+
+			Expect(manager.adjustBalloon(domName, floorKiB)).To(Succeed())
+		})
+
+		It("should leave the balloon alone once the floor is reached", func() {
+			expectBalloon(floorKiB)
+
+			Expect(manager.adjustBalloon(domName, floorKiB)).To(Succeed())
+		})
+
+		It("should return the failures to resize the balloon", func() {
+			expectBalloon(maxKiB)
+			mockDomain.EXPECT().SetMemory(gomock.Any()).Return(fmt.Errorf("balloon failure"))
+
+			Expect(manager.adjustBalloon(domName, floorKiB)).To(MatchError("balloon failure"))
+		})
+
+		It("should ignore domains which don't exist yet", func() {
+			mockConn.EXPECT().LookupDomainByName(domName).Return(nil, libvirt.Error{Code: libvirt.ERR_NO_DOMAIN})
+
+			Expect(manager.adjustBalloon(domName, floorKiB)).To(Succeed())
+		})
+	})
+
+	It("should not touch the balloon under moderate pressure", func() {
+		setPressure((pressureThreshold + releaseThreshold) / 2)
+
+		Expect(manager.adjustBalloon(domName, floorKiB)).To(Succeed())
+	})
+
+	It("should hand the reclaimed memory back once the pressure is gone", func() {
+		setPressure(0)
+		expectBalloon(maxKiB - 1024)
+		mockDomain.EXPECT().SetMemory(uint64(maxKiB)).Return(nil)
+
+		Expect(manager.adjustBalloon(domName, floorKiB)).To(Succeed())
+	})
+
+	table.DescribeTable("should compute the next balloon size", func(pressure float64, currentKiB uint64, floor uint64, expectedKiB uint64) {
+		Expect(nextBalloonTarget(pressure, currentKiB, maxKiB, floor)).To(Equal(expectedKiB))
+	},
+		table.Entry("inflating by one step", 50.0, uint64(maxKiB), uint64(floorKiB), uint64(maxKiB-maxKiB/10)),
+		table.Entry("inflating up to the floor", 50.0, uint64(floorKiB+1), uint64(floorKiB), uint64(floorKiB)),
+		table.Entry("never going below the floor", 50.0, uint64(floorKiB-1), uint64(floorKiB), uint64(floorKiB)),
+		table.Entry("deflating by one step", 0.0, uint64(floorKiB), uint64(floorKiB), uint64(floorKiB+maxKiB/10)),
+		table.Entry("deflating up to the maximum", 0.0, uint64(maxKiB-1), uint64(floorKiB), uint64(maxKiB)),
+		table.Entry("with a floor above the maximum", 50.0, uint64(maxKiB), uint64(2*maxKiB), uint64(maxKiB)),
+	)
+
+	It("should read the memory pressure of the pod", func() {
+		setPressure(12.34)
+		pressure, err := readPressure(manager.pressureFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pressure).To(Equal(12.34))
+	})
+
+	It("should fail reading a memory pressure file without pressure", func() {
+		Expect(ioutil.WriteFile(manager.pressureFile, []byte("full avg10=1.00\n"), 0644)).To(Succeed())
+		_, err := readPressure(manager.pressureFile)
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("handling the balloon floor", func() {
+		newVMI := func() *v1.VirtualMachineInstance {
+			vmi := v1.NewMinimalVMI("testvmi")
+			floor := resource.MustParse("512Mi")
+			vmi.Spec.Domain.Memory = &v1.Memory{BalloonFloor: &floor}
+			return vmi
+		}
+
+		It("should not watch the memory pressure without balloon floor", func() {
+			setPressure(0)
+			Expect(manager.HandleBalloonFloor(v1.NewMinimalVMI("testvmi"))).To(Succeed())
+			Expect(manager.started).To(BeFalse())
+		})
+
+		It("should not watch the memory pressure if it can't be read", func() {
+			Expect(manager.HandleBalloonFloor(newVMI())).To(Succeed())
+			Expect(manager.started).To(BeFalse())
+		})
+
+		It("should watch the memory pressure once", func() {
+			setPressure(0)
+			Expect(manager.HandleBalloonFloor(newVMI())).To(Succeed())
+			Expect(manager.started).To(BeTrue())
+			Expect(manager.HandleBalloonFloor(newVMI())).To(Succeed())
+		})
+	})
+})
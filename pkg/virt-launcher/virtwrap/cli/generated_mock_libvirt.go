@@ -333,6 +333,16 @@ func (_mr *_MockVirDomainRecorder) ShutdownFlags(arg0 interface{}) *gomock.Call
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "ShutdownFlags", arg0)
 }
 
+func (_m *MockVirDomain) Reboot(flags libvirt_go.DomainRebootFlagValues) error {
+	ret := _m.ctrl.Call(_m, "Reboot", flags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockVirDomainRecorder) Reboot(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Reboot", arg0)
+}
+
 func (_m *MockVirDomain) UndefineFlags(flags libvirt_go.DomainUndefineFlagsValues) error {
 	ret := _m.ctrl.Call(_m, "UndefineFlags", flags)
 	ret0, _ := ret[0].(error)
@@ -417,6 +427,27 @@ func (_mr *_MockVirDomainRecorder) MigrateStartPostCopy(arg0 interface{}) *gomoc
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "MigrateStartPostCopy", arg0)
 }
 
+func (_m *MockVirDomain) MigrateSetMaxDowntime(downtime uint64, flags uint32) error {
+	ret := _m.ctrl.Call(_m, "MigrateSetMaxDowntime", downtime, flags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockVirDomainRecorder) MigrateSetMaxDowntime(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "MigrateSetMaxDowntime", arg0, arg1)
+}
+
+func (_m *MockVirDomain) MigrateGetMaxDowntime(flags uint32) (uint64, error) {
+	ret := _m.ctrl.Call(_m, "MigrateGetMaxDowntime", flags)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockVirDomainRecorder) MigrateGetMaxDowntime(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "MigrateGetMaxDowntime", arg0)
+}
+
 func (_m *MockVirDomain) MemoryStats(nrStats uint32, flags uint32) ([]libvirt_go.DomainMemoryStat, error) {
 	ret := _m.ctrl.Call(_m, "MemoryStats", nrStats, flags)
 	ret0, _ := ret[0].([]libvirt_go.DomainMemoryStat)
@@ -428,6 +459,27 @@ func (_mr *_MockVirDomainRecorder) MemoryStats(arg0, arg1 interface{}) *gomock.C
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "MemoryStats", arg0, arg1)
 }
 
+func (_m *MockVirDomain) GetMaxMemory() (uint64, error) {
+	ret := _m.ctrl.Call(_m, "GetMaxMemory")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockVirDomainRecorder) GetMaxMemory() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetMaxMemory")
+}
+
+func (_m *MockVirDomain) SetMemory(memory uint64) error {
+	ret := _m.ctrl.Call(_m, "SetMemory", memory)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockVirDomainRecorder) SetMemory(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetMemory", arg0)
+}
+
 func (_m *MockVirDomain) GetJobStats(flags libvirt_go.DomainGetJobStatsFlags) (*libvirt_go.DomainJobInfo, error) {
 	ret := _m.ctrl.Call(_m, "GetJobStats", flags)
 	ret0, _ := ret[0].(*libvirt_go.DomainJobInfo)
@@ -492,6 +544,36 @@ func (_mr *_MockVirDomainRecorder) AbortJob() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "AbortJob")
 }
 
+func (_m *MockVirDomain) CoreDumpWithFormat(to string, format libvirt_go.DomainCoreDumpFormat, flags libvirt_go.DomainCoreDumpFlags) error {
+	ret := _m.ctrl.Call(_m, "CoreDumpWithFormat", to, format, flags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockVirDomainRecorder) CoreDumpWithFormat(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "CoreDumpWithFormat", arg0, arg1, arg2)
+}
+
+func (_m *MockVirDomain) PinVcpuFlags(vcpu uint, cpuMap []bool, flags libvirt_go.DomainModificationImpact) error {
+	ret := _m.ctrl.Call(_m, "PinVcpuFlags", vcpu, cpuMap, flags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockVirDomainRecorder) PinVcpuFlags(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "PinVcpuFlags", arg0, arg1, arg2)
+}
+
+func (_m *MockVirDomain) PinEmulator(cpumap []bool, flags libvirt_go.DomainModificationImpact) error {
+	ret := _m.ctrl.Call(_m, "PinEmulator", cpumap, flags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockVirDomainRecorder) PinEmulator(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "PinEmulator", arg0, arg1)
+}
+
 func (_m *MockVirDomain) Free() error {
 	ret := _m.ctrl.Call(_m, "Free")
 	ret0, _ := ret[0].(error)
@@ -22,6 +22,7 @@ package cli
 //go:generate mockgen -source $GOFILE -imports "libvirt=libvirt.org/libvirt-go" -package=$GOPACKAGE -destination=generated_mock_$GOFILE
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"sync"
@@ -84,6 +85,9 @@ type LibvirtConnection struct {
 	domainDeviceRemovedEventCallbacks      []libvirt.DomainEventDeviceRemovedCallback
 	domainEventMigrationIterationCallbacks []libvirt.DomainEventMigrationIterationCallback
 	agentEventCallbacks                    []libvirt.DomainEventAgentLifecycleCallback
+
+	// guest agent commands which may be proxied, all commands are allowed if empty
+	allowedAgentCommands map[string]struct{}
 }
 
 func (s *VirStream) Write(p []byte) (n int, err error) {
@@ -222,6 +226,9 @@ func (l *LibvirtConnection) ListAllDomains(flags libvirt.ConnectListAllDomainsFl
 // command - the qemu command, for example this gets the interfaces: {"execute":"guest-network-get-interfaces"}
 // domainName -  the qemu domain name
 func (l *LibvirtConnection) QemuAgentCommand(command string, domainName string) (string, error) {
+	if err := l.checkAgentCommand(command); err != nil {
+		return "", err
+	}
 	if err := l.reconnectIfNecessary(); err != nil {
 		return "", err
 	}
@@ -234,6 +241,35 @@ func (l *LibvirtConnection) QemuAgentCommand(command string, domainName string)
 	return result, err
 }
 
+// checkAgentCommand rejects guest agent commands which are not in the allow-list
+func (l *LibvirtConnection) checkAgentCommand(command string) error {
+	if len(l.allowedAgentCommands) == 0 {
+		return nil
+	}
+
+	agentCommand := struct {
+		Execute string `json:"execute"`
+	}{}
+	if err := json.Unmarshal([]byte(command), &agentCommand); err != nil {
+		return fmt.Errorf("failed to parse guest agent command: %v", err)
+	}
+	if _, allowed := l.allowedAgentCommands[agentCommand.Execute]; !allowed {
+		return fmt.Errorf("guest agent command %q is not in the allow-list of this VMI", agentCommand.Execute)
+	}
+	return nil
+}
+
+func newAgentCommandAllowList(commands []string) map[string]struct{} {
+	if len(commands) == 0 {
+		return nil
+	}
+	allowList := make(map[string]struct{}, len(commands))
+	for _, command := range commands {
+		allowList[command] = struct{}{}
+	}
+	return allowList
+}
+
 func (l *LibvirtConnection) GetAllDomainStats(statsTypes libvirt.DomainStatsTypes, flags libvirt.ConnectGetAllDomainStatsFlags) ([]libvirt.DomainStats, error) {
 	if err := l.reconnectIfNecessary(); err != nil {
 		return nil, err
@@ -396,6 +432,7 @@ type VirDomain interface {
 	DetachDevice(xml string) error
 	DestroyFlags(flags libvirt.DomainDestroyFlags) error
 	ShutdownFlags(flags libvirt.DomainShutdownFlags) error
+	Reboot(flags libvirt.DomainRebootFlagValues) error
 	UndefineFlags(flags libvirt.DomainUndefineFlagsValues) error
 	GetName() (string, error)
 	GetUUIDString() (string, error)
@@ -404,17 +441,26 @@ type VirDomain interface {
 	OpenConsole(devname string, stream *libvirt.Stream, flags libvirt.DomainConsoleFlags) error
 	MigrateToURI3(string, *libvirt.DomainMigrateParameters, libvirt.DomainMigrateFlags) error
 	MigrateStartPostCopy(flags uint32) error
+	MigrateSetMaxDowntime(downtime uint64, flags uint32) error
+	MigrateGetMaxDowntime(flags uint32) (uint64, error)
 	MemoryStats(nrStats uint32, flags uint32) ([]libvirt.DomainMemoryStat, error)
+	GetMaxMemory() (uint64, error)
+	SetMemory(memory uint64) error
 	GetJobStats(flags libvirt.DomainGetJobStatsFlags) (*libvirt.DomainJobInfo, error)
 	GetJobInfo() (*libvirt.DomainJobInfo, error)
 	GetDiskErrors(flags uint32) ([]libvirt.DomainDiskError, error)
 	SetTime(secs int64, nsecs uint, flags libvirt.DomainSetTimeFlags) error
 	IsPersistent() (bool, error)
 	AbortJob() error
+	CoreDumpWithFormat(to string, format libvirt.DomainCoreDumpFormat, flags libvirt.DomainCoreDumpFlags) error
+	PinVcpuFlags(vcpu uint, cpuMap []bool, flags libvirt.DomainModificationImpact) error
+	PinEmulator(cpumap []bool, flags libvirt.DomainModificationImpact) error
 	Free() error
 }
 
-func NewConnection(uri string, user string, pass string, checkInterval time.Duration) (Connection, error) {
+// NewConnection connects to libvirt. Guest agent commands not in allowedAgentCommands are rejected,
+// unless allowedAgentCommands is empty.
+func NewConnection(uri string, user string, pass string, checkInterval time.Duration, allowedAgentCommands []string) (Connection, error) {
 	logger := log.Log
 	logger.V(1).Infof("Connecting to libvirt daemon: %s", uri)
 
@@ -436,8 +482,9 @@ func NewConnection(uri string, user string, pass string, checkInterval time.Dura
 
 	lvConn := &LibvirtConnection{
 		Connect: virConn, user: user, pass: pass, uri: uri, alive: true,
-		reconnectLock: &sync.Mutex{},
-		stop:          make(chan struct{}),
+		reconnectLock:        &sync.Mutex{},
+		stop:                 make(chan struct{}),
+		allowedAgentCommands: newAgentCommandAllowList(allowedAgentCommands),
 	}
 	lvConn.installWatchdog(checkInterval)
 
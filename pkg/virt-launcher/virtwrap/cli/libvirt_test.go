@@ -24,16 +24,57 @@ import (
 	"time"
 
 	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("Libvirt Suite", func() {
 	Context("Upon attempt to connect to Libvirt", func() {
 		It("should time out while waiting for libvirt", func() {
-			_, err := NewConnection("http://", "", "", 1*time.Microsecond)
+			_, err := NewConnection("http://", "", "", 1*time.Microsecond, nil)
 			msg := fmt.Sprintf("%v", err)
 			Expect(err).To(HaveOccurred())
 			Expect(msg).To(Equal("cannot connect to libvirt daemon: timed out waiting for the condition"))
 		})
 	})
+
+	Context("with a guest agent command allow-list", func() {
+		var conn *LibvirtConnection
+
+		BeforeEach(func() {
+			conn = &LibvirtConnection{
+				allowedAgentCommands: newAgentCommandAllowList([]string{"guest-ping", "guest-info"}),
+			}
+		})
+
+		table.DescribeTable("should allow listed commands", func(command string) {
+			Expect(conn.checkAgentCommand(command)).To(Succeed())
+		},
+			table.Entry("without arguments", `{"execute":"guest-ping"}`),
+			table.Entry("with arguments", `{"execute":"guest-info", "arguments":{}}`),
+		)
+
+		table.DescribeTable("should reject", func(command string, expectedError string) {
+			Expect(conn.checkAgentCommand(command)).To(MatchError(expectedError))
+		},
+			table.Entry("commands not in the list", `{"execute":"guest-exec","arguments":{"path":"/bin/sh"}}`,
+				`guest agent command "guest-exec" is not in the allow-list of this VMI`),
+			table.Entry("commands without a name", `{"arguments":{}}`,
+				`guest agent command "" is not in the allow-list of this VMI`),
+		)
+
+		It("should reject malformed commands", func() {
+			Expect(conn.checkAgentCommand(`{"execute":`)).To(MatchError(HavePrefix("failed to parse guest agent command")))
+		})
+
+		It("should reject commands before reaching libvirt", func() {
+			_, err := conn.QemuAgentCommand(`{"execute":"guest-fsfreeze-freeze"}`, "default_testvmi")
+			Expect(err).To(MatchError(`guest agent command "guest-fsfreeze-freeze" is not in the allow-list of this VMI`))
+		})
+
+		It("should allow all commands without an allow-list", func() {
+			conn.allowedAgentCommands = newAgentCommandAllowList(nil)
+			Expect(conn.checkAgentCommand(`{"execute":"guest-exec"}`)).To(Succeed())
+		})
+	})
 })
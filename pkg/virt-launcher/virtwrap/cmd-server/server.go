@@ -202,6 +202,74 @@ func (l *Launcher) UnpauseVirtualMachine(ctx context.Context, request *cmdv1.VMI
 	return response, nil
 }
 
+func (l *Launcher) SoftRebootVirtualMachine(ctx context.Context, request *cmdv1.VMIRequest) (*cmdv1.Response, error) {
+	vmi, response := getVMIFromRequest(request.Vmi)
+	if !response.Success {
+		return response, nil
+	}
+
+	if err := l.domainManager.SoftRebootVMI(vmi); err != nil {
+		log.Log.Object(vmi).Reason(err).Errorf("Failed to soft reboot vmi")
+		response.Success = false
+		response.Message = getErrorMessage(err)
+		return response, nil
+	}
+
+	log.Log.Object(vmi).Info("Soft rebooted vmi")
+	return response, nil
+}
+
+func (l *Launcher) FreezeVirtualMachine(ctx context.Context, request *cmdv1.VMIRequest) (*cmdv1.Response, error) {
+	vmi, response := getVMIFromRequest(request.Vmi)
+	if !response.Success {
+		return response, nil
+	}
+
+	if err := l.domainManager.FreezeVMI(vmi); err != nil {
+		log.Log.Object(vmi).Reason(err).Errorf("Failed to freeze vmi")
+		response.Success = false
+		response.Message = getErrorMessage(err)
+		return response, nil
+	}
+
+	log.Log.Object(vmi).Info("Froze vmi")
+	return response, nil
+}
+
+func (l *Launcher) UnfreezeVirtualMachine(ctx context.Context, request *cmdv1.VMIRequest) (*cmdv1.Response, error) {
+	vmi, response := getVMIFromRequest(request.Vmi)
+	if !response.Success {
+		return response, nil
+	}
+
+	if err := l.domainManager.UnfreezeVMI(vmi); err != nil {
+		log.Log.Object(vmi).Reason(err).Errorf("Failed to unfreeze vmi")
+		response.Success = false
+		response.Message = getErrorMessage(err)
+		return response, nil
+	}
+
+	log.Log.Object(vmi).Info("Unfroze vmi")
+	return response, nil
+}
+
+func (l *Launcher) VirtualMachineMemoryDump(ctx context.Context, request *cmdv1.MemoryDumpRequest) (*cmdv1.Response, error) {
+	vmi, response := getVMIFromRequest(request.Vmi)
+	if !response.Success {
+		return response, nil
+	}
+
+	if err := l.domainManager.MemoryDumpVMI(vmi, request.DumpPath); err != nil {
+		log.Log.Object(vmi).Reason(err).Errorf("Failed to dump the memory of vmi")
+		response.Success = false
+		response.Message = getErrorMessage(err)
+		return response, nil
+	}
+
+	log.Log.Object(vmi).Infof("Dumping the memory of vmi to %s", request.DumpPath)
+	return response, nil
+}
+
 func (l *Launcher) KillVirtualMachine(ctx context.Context, request *cmdv1.VMIRequest) (*cmdv1.Response, error) {
 
 	vmi, response := getVMIFromRequest(request.Vmi)
@@ -20,6 +20,7 @@
 package cmdserver
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -120,6 +121,48 @@ var _ = Describe("Virt remote commands", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		It("should soft reboot a vmi", func() {
+			vmi := v1.NewVMIReferenceFromName("testvmi")
+			domainManager.EXPECT().SoftRebootVMI(vmi)
+			err := client.SoftRebootVirtualMachine(vmi)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should report a failed soft reboot", func() {
+			vmi := v1.NewVMIReferenceFromName("testvmi")
+			domainManager.EXPECT().SoftRebootVMI(vmi).Return(fmt.Errorf("domain is not running"))
+			err := client.SoftRebootVirtualMachine(vmi)
+			Expect(err).To(MatchError(ContainSubstring("domain is not running")))
+		})
+
+		It("should freeze a vmi", func() {
+			vmi := v1.NewVMIReferenceFromName("testvmi")
+			domainManager.EXPECT().FreezeVMI(vmi)
+			err := client.FreezeVirtualMachine(vmi)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should report a failed freeze", func() {
+			vmi := v1.NewVMIReferenceFromName("testvmi")
+			domainManager.EXPECT().FreezeVMI(vmi).Return(fmt.Errorf("the guest agent is not connected"))
+			err := client.FreezeVirtualMachine(vmi)
+			Expect(err).To(MatchError(ContainSubstring("the guest agent is not connected")))
+		})
+
+		It("should unfreeze a vmi", func() {
+			vmi := v1.NewVMIReferenceFromName("testvmi")
+			domainManager.EXPECT().UnfreezeVMI(vmi)
+			err := client.UnfreezeVirtualMachine(vmi)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should dump the memory of a vmi", func() {
+			vmi := v1.NewVMIReferenceFromName("testvmi")
+			domainManager.EXPECT().MemoryDumpVMI(vmi, "/var/run/kubevirt/hotplug-disks/.memory-dump/testvmi.memory.dump")
+			err := client.VirtualMachineMemoryDump(vmi, "/var/run/kubevirt/hotplug-disks/.memory-dump/testvmi.memory.dump")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
 		It("should list domains", func() {
 			var list []*api.Domain
 			list = append(list, api.NewMinimalDomain("testvmi1"))
@@ -0,0 +1,90 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package converter
+
+import (
+	v1 "kubevirt.io/client-go/api/v1"
+)
+
+// ResolveBootOrder returns the vmi with the boot order of its disks and
+// interfaces set according to spec.domain.boot, so that the devices can be
+// converted one by one. The given vmi is never modified, a copy is returned
+// if the boot configuration changes any boot order.
+func ResolveBootOrder(vmi *v1.VirtualMachineInstance) *v1.VirtualMachineInstance {
+	boot := vmi.Spec.Domain.Boot
+	if boot == nil || (len(boot.Order) == 0 && !boot.NetworkBootLast) {
+		return vmi
+	}
+
+	vmi = vmi.DeepCopy()
+	devices := &vmi.Spec.Domain.Devices
+
+	var lastOrder uint
+	if len(boot.Order) > 0 {
+		// the order replaces the boot order of the devices
+		for i, device := range boot.Order {
+			order := uint(i + 1)
+			if device.Disk != "" {
+				for j := range devices.Disks {
+					if devices.Disks[j].Name == device.Disk {
+						devices.Disks[j].BootOrder = &order
+					}
+				}
+			} else if device.Interface != "" {
+				for j := range devices.Interfaces {
+					if devices.Interfaces[j].Name == device.Interface {
+						devices.Interfaces[j].BootOrder = &order
+					}
+				}
+			}
+			lastOrder = order
+		}
+	} else {
+		for _, disk := range devices.Disks {
+			if disk.BootOrder != nil && *disk.BootOrder > lastOrder {
+				lastOrder = *disk.BootOrder
+			}
+		}
+		for _, iface := range devices.Interfaces {
+			if iface.BootOrder != nil && *iface.BootOrder > lastOrder {
+				lastOrder = *iface.BootOrder
+			}
+		}
+	}
+
+	if boot.NetworkBootLast {
+		for i := range devices.Interfaces {
+			if devices.Interfaces[i].BootOrder != nil || !isNetworkBootable(&devices.Interfaces[i]) {
+				continue
+			}
+			lastOrder++
+			order := lastOrder
+			devices.Interfaces[i].BootOrder = &order
+		}
+	}
+	return vmi
+}
+
+// isNetworkBootable returns true if the guest can boot from the network
+// through the interface. The boot order of slirp interfaces is not passed
+// to qemu, their network can't be booted from.
+func isNetworkBootable(iface *v1.Interface) bool {
+	return iface.Slirp == nil
+}
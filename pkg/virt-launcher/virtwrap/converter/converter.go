@@ -133,6 +133,21 @@ func Convert_HostDevices_And_GPU(devices v1.Devices, domain *api.Domain, c *Conv
 		if err != nil {
 			return err
 		}
+		// Add a guest pciAddress if specified
+		if hostDev.PciAddress != "" {
+			addr, err := device.NewPciAddressField(hostDev.PciAddress)
+			if err != nil {
+				return fmt.Errorf("failed to configure host device %s: %v", hostDev.Name, err)
+			}
+			hostDevice.Address = addr
+		}
+		// Expose the ROM image copied by the launcher instead of the ROM of the device
+		if hostDev.ROM != nil {
+			if hostDevice.Type != "pci" {
+				return fmt.Errorf("failed to configure host device %s: a ROM can only be set on PCI host devices", hostDev.Name)
+			}
+			hostDevice.Rom = &api.Rom{Bar: "on", File: config.GetHostDeviceROMPath(hostDev.Name)}
+		}
 		domain.Spec.Devices.HostDevices = append(domain.Spec.Devices.HostDevices, hostDevice)
 	}
 	for _, gpu := range devices.GPUs {
@@ -181,6 +196,7 @@ func Convert_v1_Disk_To_api_Disk(c *ConverterContext, diskDevice *v1.Disk, disk
 		}
 		disk.ReadOnly = toApiReadOnly(diskDevice.Disk.ReadOnly)
 		disk.Serial = diskDevice.Serial
+		disk.WWN = diskDevice.WWN
 	} else if diskDevice.LUN != nil {
 		disk.Device = "lun"
 		disk.Target.Bus = diskDevice.LUN.Bus
@@ -202,6 +218,7 @@ func Convert_v1_Disk_To_api_Disk(c *ConverterContext, diskDevice *v1.Disk, disk
 		} else {
 			disk.ReadOnly = toApiReadOnly(true)
 		}
+		disk.WWN = diskDevice.WWN
 	}
 	disk.Driver = &api.DiskDriver{
 		Name:        "qemu",
@@ -217,10 +234,48 @@ func Convert_v1_Disk_To_api_Disk(c *ConverterContext, diskDevice *v1.Disk, disk
 	if diskDevice.BootOrder != nil {
 		disk.BootOrder = &api.BootOrder{Order: *diskDevice.BootOrder}
 	}
+	disk.IOTune = toApiIOTune(diskDevice.IOThrottleGroup)
 
 	return nil
 }
 
+// toApiIOTune maps a throttle group to the iotune of a disk. QEMU shares the
+// limits among all disks with the same group name.
+func toApiIOTune(group *v1.IOThrottleGroup) *api.IOTune {
+	if group == nil {
+		return nil
+	}
+	return &api.IOTune{
+		TotalBytesSec: uint64(group.TotalBytesPerSec),
+		ReadBytesSec:  uint64(group.ReadBytesPerSec),
+		WriteBytesSec: uint64(group.WriteBytesPerSec),
+		TotalIopsSec:  uint64(group.TotalIOPS),
+		ReadIopsSec:   uint64(group.ReadIOPS),
+		WriteIopsSec:  uint64(group.WriteIOPS),
+		GroupName:     group.Name,
+	}
+}
+
+// checkIOThrottleGroups ensures that all disks of a throttle group set the
+// same limits, since QEMU would silently apply the limits of the last one.
+func checkIOThrottleGroups(disks []v1.Disk) error {
+	groups := map[string]v1.Disk{}
+	for _, disk := range disks {
+		if disk.IOThrottleGroup == nil {
+			continue
+		}
+		other, exists := groups[disk.IOThrottleGroup.Name]
+		if !exists {
+			groups[disk.IOThrottleGroup.Name] = disk
+			continue
+		}
+		if *other.IOThrottleGroup != *disk.IOThrottleGroup {
+			return fmt.Errorf("disks %s and %s of the I/O throttle group %s set different limits", other.Name, disk.Name, disk.IOThrottleGroup.Name)
+		}
+	}
+	return nil
+}
+
 func checkDirectIOFlag(path string) bool {
 	// check if fs where disk.img file is located or block device
 	// support direct i/o
@@ -733,12 +788,14 @@ func Convert_v1_Clock_To_api_Clock(source *v1.Clock, clock *api.Clock, c *Conver
 			newTimer.Track = string(source.Timer.RTC.Track)
 			newTimer.TickPolicy = string(source.Timer.RTC.TickPolicy)
 			newTimer.Present = boolToYesNo(source.Timer.RTC.Enabled, true)
+			newTimer.Catchup = toApiTimerCatchup(source.Timer.RTC.Catchup)
 			clock.Timer = append(clock.Timer, newTimer)
 		}
 		if source.Timer.PIT != nil {
 			newTimer := api.Timer{Name: "pit"}
 			newTimer.Present = boolToYesNo(source.Timer.PIT.Enabled, true)
 			newTimer.TickPolicy = string(source.Timer.PIT.TickPolicy)
+			newTimer.Catchup = toApiTimerCatchup(source.Timer.PIT.Catchup)
 			clock.Timer = append(clock.Timer, newTimer)
 		}
 		if source.Timer.KVM != nil {
@@ -750,6 +807,7 @@ func Convert_v1_Clock_To_api_Clock(source *v1.Clock, clock *api.Clock, c *Conver
 			newTimer := api.Timer{Name: "hpet"}
 			newTimer.Present = boolToYesNo(source.Timer.HPET.Enabled, true)
 			newTimer.TickPolicy = string(source.Timer.HPET.TickPolicy)
+			newTimer.Catchup = toApiTimerCatchup(source.Timer.HPET.Catchup)
 			clock.Timer = append(clock.Timer, newTimer)
 		}
 		if source.Timer.Hyperv != nil {
@@ -762,6 +820,32 @@ func Convert_v1_Clock_To_api_Clock(source *v1.Clock, clock *api.Clock, c *Conver
 	return nil
 }
 
+func toApiTimerCatchup(catchup *v1.TimerCatchup) *api.TimerCatchup {
+	if catchup == nil {
+		return nil
+	}
+	return &api.TimerCatchup{
+		Threshold: catchup.Threshold,
+		Slew:      catchup.Slew,
+		Limit:     catchup.Limit,
+	}
+}
+
+// convertVirtiofsCache maps the virtiofs cache mode to the libvirt cache element.
+// libvirt has no auto mode, it is virtiofsd's own default and is selected by
+// leaving the cache element out.
+func convertVirtiofsCache(cache v1.VirtiofsCache) *api.FilesystemBinaryCache {
+	switch cache {
+	case v1.VirtiofsCacheAuto:
+		return nil
+	case "":
+		cache = v1.VirtiofsCacheNone
+	}
+	return &api.FilesystemBinaryCache{
+		Mode: string(cache),
+	}
+}
+
 func convertFeatureState(source *v1.FeatureState) *api.FeatureState {
 	if source != nil {
 		return &api.FeatureState{
@@ -869,11 +953,28 @@ func getInterfaceType(iface *v1.Interface) string {
 	return "virtio"
 }
 
+// setSysInfoEntry replaces the value of the named sysinfo entry or appends it if it is not present.
+// Empty values leave the entries untouched.
+func setSysInfoEntry(entries []api.Entry, name string, value string) []api.Entry {
+	if value == "" {
+		return entries
+	}
+	for i := range entries {
+		if entries[i].Name == name {
+			entries[i].Value = value
+			return entries
+		}
+	}
+	return append(entries, api.Entry{Name: name, Value: value})
+}
+
 func Convert_v1_VirtualMachine_To_api_Domain(vmi *v1.VirtualMachineInstance, domain *api.Domain, c *ConverterContext) (err error) {
 	precond.MustNotBeNil(vmi)
 	precond.MustNotBeNil(domain)
 	precond.MustNotBeNil(c)
 
+	vmi = ResolveBootOrder(vmi)
+
 	domain.Spec.Name = api.VMINamespaceKeyFunc(vmi)
 	domain.ObjectMeta.Name = vmi.ObjectMeta.Name
 	domain.ObjectMeta.Namespace = vmi.ObjectMeta.Namespace
@@ -950,6 +1051,10 @@ func Convert_v1_VirtualMachine_To_api_Domain(vmi *v1.VirtualMachineInstance, dom
 					NVRam:    filepath.Join("/tmp", domain.Spec.Name),
 					Template: filepath.Join(c.OVMFPath, EFIVarsSecureBoot),
 				}
+				if vmi.Spec.Domain.Firmware.Bootloader.EFI.SecureBootCertificates != nil {
+					// the template with the custom certificates enrolled is created by the pre start hook
+					domain.Spec.OS.NVRam.Template = GetEFIVarsSecureBootCertificatesTemplatePath(domain.Spec.Name)
+				}
 			} else {
 				domain.Spec.OS.BootLoader = &api.Loader{
 					Path:     filepath.Join(c.OVMFPath, EFICode),
@@ -977,6 +1082,13 @@ func Convert_v1_VirtualMachine_To_api_Domain(vmi *v1.VirtualMachineInstance, dom
 			domain.Spec.SysInfo.System = append(domain.Spec.SysInfo.System, api.Entry{Name: "serial", Value: string(vmi.Spec.Domain.Firmware.Serial)})
 		}
 	}
+	if vmi.Spec.Domain.Boot != nil && vmi.Spec.Domain.Boot.RebootTimeoutSeconds != nil {
+		if domain.Spec.OS.BIOS == nil {
+			domain.Spec.OS.BIOS = &api.BIOS{}
+		}
+		// libvirt expects milliseconds
+		domain.Spec.OS.BIOS.RebootTimeout = strconv.FormatUint(uint64(*vmi.Spec.Domain.Boot.RebootTimeoutSeconds)*1000, 10)
+	}
 	if c.SMBios != nil {
 		domain.Spec.SysInfo.System = append(domain.Spec.SysInfo.System,
 			api.Entry{
@@ -1002,6 +1114,19 @@ func Convert_v1_VirtualMachine_To_api_Domain(vmi *v1.VirtualMachineInstance, dom
 		)
 	}
 
+	if vmi.Spec.Domain.Firmware != nil && vmi.Spec.Domain.Firmware.SMBiosSecretRef != nil {
+		smbios, err := config.ReadSMBiosSecret()
+		if err != nil {
+			return fmt.Errorf("failed to read the SMBIOS secret %s: %v", vmi.Spec.Domain.Firmware.SMBiosSecretRef.Name, err)
+		}
+		// Values from the secret take precedence over the cluster wide SMBIOS configuration
+		domain.Spec.SysInfo.System = setSysInfoEntry(domain.Spec.SysInfo.System, "manufacturer", smbios.Manufacturer)
+		domain.Spec.SysInfo.System = setSysInfoEntry(domain.Spec.SysInfo.System, "product", smbios.Product)
+		if len(smbios.OEMStrings) > 0 {
+			domain.Spec.SysInfo.OEMStrings = &api.OEMStrings{Entries: smbios.OEMStrings}
+		}
+	}
+
 	// Take SMBios values from the VirtualMachineOptions
 	// SMBios option does not work in Power, attempting to set it will result in the following error message:
 	// "Option not supported for this target" issued by qemu-system-ppc64, so don't set it in case GOARCH is ppc64le
@@ -1048,6 +1173,11 @@ func Convert_v1_VirtualMachine_To_api_Domain(vmi *v1.VirtualMachineInstance, dom
 		if val := vmi.Annotations[v1.MemfdMemoryBackend]; val != "false" {
 			isMemfdRequired = true
 		}
+		if len(vmi.Spec.Domain.Memory.Hugepages.PerNUMANode) > 0 {
+			if err := setPerNUMANodeHugepages(vmi.Spec.Domain.Memory.Hugepages, domain); err != nil {
+				return err
+			}
+		}
 	}
 	// virtiofs require shared access
 	if util.IsVMIVirtiofsEnabled(vmi) {
@@ -1060,20 +1190,29 @@ func Convert_v1_VirtualMachine_To_api_Domain(vmi *v1.VirtualMachineInstance, dom
 		isMemfdRequired = true
 	}
 
+	if vmi.Spec.Domain.Memory != nil && vmi.Spec.Domain.Memory.Locked {
+		if domain.Spec.MemoryBacking == nil {
+			domain.Spec.MemoryBacking = &api.MemoryBacking{}
+		}
+		domain.Spec.MemoryBacking.Locked = &api.MemoryBackingLocked{}
+	}
+
 	if isMemfdRequired {
 		// Set memfd as memory backend to solve SELinux restrictions
 		// See the issue: https://github.com/kubevirt/kubevirt/issues/3781
 		domain.Spec.MemoryBacking.Source = &api.MemoryBackingSource{Type: "memfd"}
 		// NUMA is required in order to use memfd
-		domain.Spec.CPU.NUMA = &api.NUMA{
-			Cells: []api.NUMACell{
-				{
-					ID:     "0",
-					CPUs:   fmt.Sprintf("0-%d", domain.Spec.VCPU.CPUs-1),
-					Memory: fmt.Sprintf("%d", getVirtualMemory(vmi).Value()/int64(1024)),
-					Unit:   "KiB",
+		if domain.Spec.CPU.NUMA == nil {
+			domain.Spec.CPU.NUMA = &api.NUMA{
+				Cells: []api.NUMACell{
+					{
+						ID:     "0",
+						CPUs:   fmt.Sprintf("0-%d", domain.Spec.VCPU.CPUs-1),
+						Memory: fmt.Sprintf("%d", getVirtualMemory(vmi).Value()/int64(1024)),
+						Unit:   "KiB",
+					},
 				},
-			},
+			}
 		}
 	}
 
@@ -1153,6 +1292,9 @@ func Convert_v1_VirtualMachine_To_api_Domain(vmi *v1.VirtualMachineInstance, dom
 		numBlkQueues = &vcpus
 	}
 
+	if err := checkIOThrottleGroups(vmi.Spec.Domain.Devices.Disks); err != nil {
+		return err
+	}
 	prefixMap := newDeviceNamer(vmi.Status.VolumeStatus, vmi.Spec.Domain.Devices.Disks)
 	for i, disk := range vmi.Spec.Domain.Devices.Disks {
 		newDisk := api.Disk{}
@@ -1214,14 +1356,17 @@ func Convert_v1_VirtualMachine_To_api_Domain(vmi *v1.VirtualMachineInstance, dom
 			newFS.Binary = &api.FilesystemBinary{
 				Path:  "/usr/libexec/virtiofsd",
 				Xattr: "on",
-				Cache: &api.FilesystemBinaryCache{
-					Mode: "none",
-				},
+				Cache: convertVirtiofsCache(fs.Virtiofs.Cache),
 				Lock: &api.FilesystemBinaryLock{
 					Posix: "on",
 					Flock: "on",
 				},
 			}
+			if fs.Virtiofs.Sandbox != "" {
+				newFS.Binary.Sandbox = &api.FilesystemBinarySandbox{
+					Mode: string(fs.Virtiofs.Sandbox),
+				}
+			}
 			newFS.Target = &api.FilesystemTarget{
 				Dir: fs.Name,
 			}
@@ -1246,6 +1391,15 @@ func Convert_v1_VirtualMachine_To_api_Domain(vmi *v1.VirtualMachineInstance, dom
 		domain.Spec.Devices.Watchdog = newWatchdog
 	}
 
+	if vmi.Spec.Domain.OnCrash != nil {
+		domain.Spec.OnCrash = string(*vmi.Spec.Domain.OnCrash)
+		// qemu only learns about crashes of the guest through a panic device,
+		// which pseries and s390 machines already provide
+		if c.Architecture == "amd64" {
+			domain.Spec.Devices.Panic = &api.PanicDevice{Model: "isa"}
+		}
+	}
+
 	if vmi.Spec.Domain.Devices.Rng != nil {
 		newRng := &api.Rng{}
 		err := Convert_v1_Rng_To_api_Rng(vmi.Spec.Domain.Devices.Rng, newRng, c)
@@ -1564,6 +1718,10 @@ func Convert_v1_VirtualMachine_To_api_Domain(vmi *v1.VirtualMachineInstance, dom
 		domain.Spec.QEMUCmd.QEMUArg = append(domain.Spec.QEMUCmd.QEMUArg, api.Arg{Value: fmt.Sprintf("name=opt/com.coreos/config,file=%s", ignitionpath)})
 	}
 
+	if err := addQEMULogFilters(vmi, domain); err != nil {
+		return err
+	}
+
 	if val := vmi.Annotations[v1.PlacePCIDevicesOnRootComplex]; val == "true" {
 		if err := PlacePCIDevicesOnRootComplex(&domain.Spec); err != nil {
 			return err
@@ -1573,6 +1731,12 @@ func Convert_v1_VirtualMachine_To_api_Domain(vmi *v1.VirtualMachineInstance, dom
 	return nil
 }
 
+// GetEFIVarsSecureBootCertificatesTemplatePath returns the path of the variable store template
+// where the custom Secure Boot certificates of the domain are enrolled
+func GetEFIVarsSecureBootCertificatesTemplatePath(domainName string) string {
+	return filepath.Join("/tmp", domainName+"-"+EFIVarsSecureBoot)
+}
+
 func CheckEFI_OVMFRoms(vmi *v1.VirtualMachineInstance, c *ConverterContext) (err error) {
 	if vmi.Spec.Domain.Firmware != nil {
 		if vmi.Spec.Domain.Firmware.Bootloader != nil && vmi.Spec.Domain.Firmware.Bootloader.EFI != nil {
@@ -22,6 +22,7 @@ package converter
 import (
 	"encoding/xml"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
 	"reflect"
@@ -34,6 +35,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	k8smeta "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"kubevirt.io/kubevirt/pkg/config"
 	"kubevirt.io/kubevirt/pkg/testutils"
 	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/api"
 
@@ -65,6 +67,48 @@ var _ = Describe("Converter", func() {
 		})
 	})
 
+	Context("with timers", func() {
+		timerXML := func(timer *v1.Timer) string {
+			var convertClock api.Clock
+			Expect(Convert_v1_Clock_To_api_Clock(&v1.Clock{Timer: timer}, &convertClock, &ConverterContext{})).To(Succeed())
+			Expect(convertClock.Timer).To(HaveLen(1))
+			data, err := xml.Marshal(convertClock.Timer[0])
+			Expect(err).ToNot(HaveOccurred())
+			return string(data)
+		}
+
+		catchup := &v1.TimerCatchup{Threshold: 123, Slew: 120, Limit: 10000}
+
+		table.DescribeTable("should set the tick policy", func(timer *v1.Timer, expected string) {
+			Expect(timerXML(timer)).To(Equal(expected))
+		},
+			table.Entry("of the rtc with delay",
+				&v1.Timer{RTC: &v1.RTCTimer{TickPolicy: v1.RTCTickPolicyDelay}},
+				`<Timer name="rtc" tickpolicy="delay" present="yes"></Timer>`),
+			table.Entry("of the rtc with catchup",
+				&v1.Timer{RTC: &v1.RTCTimer{TickPolicy: v1.RTCTickPolicyCatchup, Track: v1.TrackWall, Catchup: catchup}},
+				`<Timer name="rtc" tickpolicy="catchup" present="yes" track="wall"><catchup threshold="123" slew="120" limit="10000"></catchup></Timer>`),
+			table.Entry("of the pit with delay",
+				&v1.Timer{PIT: &v1.PITTimer{TickPolicy: v1.PITTickPolicyDelay}},
+				`<Timer name="pit" tickpolicy="delay" present="yes"></Timer>`),
+			table.Entry("of the pit with discard",
+				&v1.Timer{PIT: &v1.PITTimer{TickPolicy: v1.PITTickPolicyDiscard}},
+				`<Timer name="pit" tickpolicy="discard" present="yes"></Timer>`),
+			table.Entry("of the pit with catchup",
+				&v1.Timer{PIT: &v1.PITTimer{TickPolicy: v1.PITTickPolicyCatchup, Catchup: &v1.TimerCatchup{Threshold: 10}}},
+				`<Timer name="pit" tickpolicy="catchup" present="yes"><catchup threshold="10"></catchup></Timer>`),
+			table.Entry("of the hpet with merge",
+				&v1.Timer{HPET: &v1.HPETTimer{TickPolicy: v1.HPETTickPolicyMerge}},
+				`<Timer name="hpet" tickpolicy="merge" present="yes"></Timer>`),
+			table.Entry("of the hpet with catchup",
+				&v1.Timer{HPET: &v1.HPETTimer{TickPolicy: v1.HPETTickPolicyCatchup, Catchup: &v1.TimerCatchup{Slew: 100}}},
+				`<Timer name="hpet" tickpolicy="catchup" present="yes"><catchup slew="100"></catchup></Timer>`),
+			table.Entry("of none for the kvmclock",
+				&v1.Timer{KVM: &v1.KVMTimer{Enabled: False()}},
+				`<Timer name="kvmclock" present="no"></Timer>`),
+		)
+	})
+
 	Context("with v1.Disk", func() {
 		It("Should add boot order when provided", func() {
 			order := uint(1)
@@ -133,6 +177,42 @@ var _ = Describe("Converter", func() {
 			Expect(xml).To(Equal(convertedDisk))
 		})
 
+		It("should set the iotune of a single member I/O throttle group", func() {
+			kubevirtDisk := &v1.Disk{
+				Name: "mydisk",
+				DiskDevice: v1.DiskDevice{
+					Disk: &v1.DiskTarget{
+						Bus: "virtio",
+					},
+				},
+				IOThrottleGroup: &v1.IOThrottleGroup{
+					Name:             "db",
+					TotalBytesPerSec: 10485760,
+					ReadIOPS:         400,
+					WriteIOPS:        200,
+				},
+			}
+			var convertedDisk = `<Disk device="disk" type="" model="virtio-non-transitional">
+  <source></source>
+  <target bus="virtio" dev="vda"></target>
+  <driver error_policy="stop" name="qemu" type=""></driver>
+  <alias name="ua-mydisk"></alias>
+  <iotune>
+    <total_bytes_sec>10485760</total_bytes_sec>
+    <read_iops_sec>400</read_iops_sec>
+    <write_iops_sec>200</write_iops_sec>
+    <group_name>db</group_name>
+  </iotune>
+</Disk>`
+			xml := diskToDiskXML(kubevirtDisk)
+			Expect(xml).To(Equal(convertedDisk))
+		})
+
+		It("should not set an iotune without I/O throttle group", func() {
+			xml := diskToDiskXML(&v1.Disk{})
+			Expect(xml).ToNot(ContainSubstring("iotune"))
+		})
+
 	})
 
 	Context("with v1.VirtualMachineInstance", func() {
@@ -1328,6 +1408,55 @@ var _ = Describe("Converter", func() {
 			}))
 		})
 
+		Context("with an I/O throttle group shared by multiple disks", func() {
+			var group v1.IOThrottleGroup
+
+			BeforeEach(func() {
+				v1.SetObjectDefaults_VirtualMachineInstance(vmi)
+				group = v1.IOThrottleGroup{
+					Name:            "shared",
+					TotalIOPS:       1000,
+					ReadBytesPerSec: 1048576,
+				}
+				for _, i := range []int{0, 1} {
+					g := group
+					vmi.Spec.Domain.Devices.Disks[i].IOThrottleGroup = &g
+				}
+			})
+
+			It("should set the same iotune on all members", func() {
+				expected := &api.IOTune{
+					TotalIopsSec: 1000,
+					ReadBytesSec: 1048576,
+					GroupName:    "shared",
+				}
+				domain := vmiToDomain(vmi, c)
+				Expect(domain.Spec.Devices.Disks[0].IOTune).To(Equal(expected))
+				Expect(domain.Spec.Devices.Disks[1].IOTune).To(Equal(expected))
+				for _, disk := range domain.Spec.Devices.Disks[2:] {
+					Expect(disk.IOTune).To(BeNil())
+				}
+			})
+
+			It("should keep the group in the domain xml", func() {
+				domainSpec := vmiToDomainXMLToDomainSpec(vmi, c)
+				Expect(domainSpec.Devices.Disks[0].IOTune).To(Equal(domainSpec.Devices.Disks[1].IOTune))
+				Expect(domainSpec.Devices.Disks[0].IOTune.GroupName).To(Equal("shared"))
+			})
+
+			It("should fail if the members set different limits", func() {
+				vmi.Spec.Domain.Devices.Disks[1].IOThrottleGroup.TotalIOPS = 500
+				err := Convert_v1_VirtualMachine_To_api_Domain(vmi, &api.Domain{}, c)
+				Expect(err).To(MatchError(ContainSubstring("of the I/O throttle group shared set different limits")))
+			})
+
+			It("should accept different groups with different limits", func() {
+				vmi.Spec.Domain.Devices.Disks[1].IOThrottleGroup.Name = "other"
+				vmi.Spec.Domain.Devices.Disks[1].IOThrottleGroup.TotalIOPS = 500
+				Expect(Convert_v1_VirtualMachine_To_api_Domain(vmi, &api.Domain{}, c)).To(Succeed())
+			})
+		})
+
 		It("should not add a virtio-scsi controller if no scsi disk is present", func() {
 			v1.SetObjectDefaults_VirtualMachineInstance(vmi)
 			vmi.Spec.Domain.Devices.Disks[0].Disk.Bus = "sata"
@@ -1500,6 +1629,33 @@ var _ = Describe("Converter", func() {
 			Expect(domainSpec.Memory.Unit).To(Equal("b"))
 		})
 
+		It("should lock the guest memory", func() {
+			v1.SetObjectDefaults_VirtualMachineInstance(vmi)
+			vmi.Spec.Domain.Memory = &v1.Memory{
+				Locked: true,
+			}
+			Expect(vmiToDomainXML(vmi, c)).To(ContainSubstring("<memoryBacking>\n    <locked></locked>\n  </memoryBacking>"))
+		})
+
+		It("should lock the guest memory backed by hugepages", func() {
+			v1.SetObjectDefaults_VirtualMachineInstance(vmi)
+			vmi.Spec.Domain.Memory = &v1.Memory{
+				Hugepages: &v1.Hugepages{},
+				Locked:    true,
+			}
+			domainSpec := vmiToDomainXMLToDomainSpec(vmi, c)
+			Expect(domainSpec.MemoryBacking.HugePages).ToNot(BeNil())
+			Expect(domainSpec.MemoryBacking.Locked).ToNot(BeNil())
+		})
+
+		It("should not lock the guest memory by default", func() {
+			v1.SetObjectDefaults_VirtualMachineInstance(vmi)
+			vmi.Spec.Domain.Memory = &v1.Memory{
+				Hugepages: &v1.Hugepages{},
+			}
+			Expect(vmiToDomainXMLToDomainSpec(vmi, c).MemoryBacking.Locked).To(BeNil())
+		})
+
 		It("should use guest memory instead of requested memory if present", func() {
 			guestMemory := resource.MustParse("123Mi")
 			vmi.Spec.Domain.Memory = &v1.Memory{
@@ -2203,6 +2359,41 @@ var _ = Describe("Converter", func() {
 			Expect(apiDisk.Driver.Queues).To(BeNil(), "expected no queues to be requested")
 		})
 
+		It("should map the WWN and serial of a disk", func() {
+			v1Disk := &v1.Disk{
+				Name:   "mydisk",
+				Serial: "D23YZ9W6WA5DJ487",
+				WWN:    "5000c500a0b1c2d3",
+				DiskDevice: v1.DiskDevice{
+					Disk: &v1.DiskTarget{Bus: "scsi"},
+				},
+			}
+			data := diskToDiskXML(v1Disk)
+			Expect(data).To(ContainSubstring("<serial>D23YZ9W6WA5DJ487</serial>"))
+			Expect(data).To(ContainSubstring("<wwn>5000c500a0b1c2d3</wwn>"))
+		})
+
+		It("should map the WWN of a cdrom", func() {
+			v1Disk := &v1.Disk{
+				Name: "mycdrom",
+				WWN:  "5000c500a0b1c2d3",
+				DiskDevice: v1.DiskDevice{
+					CDRom: &v1.CDRomTarget{Bus: "scsi"},
+				},
+			}
+			Expect(diskToDiskXML(v1Disk)).To(ContainSubstring("<wwn>5000c500a0b1c2d3</wwn>"))
+		})
+
+		It("should not set a WWN if omitted", func() {
+			v1Disk := &v1.Disk{
+				Name: "mydisk",
+				DiskDevice: v1.DiskDevice{
+					Disk: &v1.DiskTarget{Bus: "scsi"},
+				},
+			}
+			Expect(diskToDiskXML(v1Disk)).ToNot(ContainSubstring("<wwn>"))
+		})
+
 		It("should honor multiQueue setting", func() {
 			var expectedQueues uint = 2
 			vmi.Spec.Domain.CPU = &v1.CPU{
@@ -2214,6 +2405,79 @@ var _ = Describe("Converter", func() {
 				"expected number of queues to equal number of requested vCPUs")
 		})
 	})
+	Context("with a SMBIOS secret", func() {
+		var vmi *v1.VirtualMachineInstance
+		var c *ConverterContext
+		var origSMBiosSecretSourceDir string
+
+		BeforeEach(func() {
+			origSMBiosSecretSourceDir = config.SMBiosSecretSourceDir
+			var err error
+			config.SMBiosSecretSourceDir, err = ioutil.TempDir("", "smbios-secret")
+			Expect(err).ToNot(HaveOccurred())
+
+			vmi = v1.NewMinimalVMI("testvmi")
+			vmi.Spec.Domain.Firmware = &v1.Firmware{
+				UUID:            "e4686d2c-6e8d-4335-b8fd-81bee22f4814",
+				SMBiosSecretRef: &k8sv1.LocalObjectReference{Name: "smbios"},
+			}
+			v1.SetObjectDefaults_VirtualMachineInstance(vmi)
+			c = &ConverterContext{
+				UseEmulation: true,
+				SMBios:       &cmdv1.SMBios{Manufacturer: "KubeVirt", Product: "None"},
+			}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(config.SMBiosSecretSourceDir)
+			config.SMBiosSecretSourceDir = origSMBiosSecretSourceDir
+		})
+
+		writeKey := func(key, value string) {
+			Expect(ioutil.WriteFile(path.Join(config.SMBiosSecretSourceDir, key), []byte(value), 0644)).To(Succeed())
+		}
+
+		It("should take the SMBIOS fields and OEM strings from the secret", func() {
+			writeKey(config.SMBiosManufacturerKey, "ACME")
+			writeKey(config.SMBiosProductKey, "Appliance")
+			writeKey(config.SMBiosOEMStringsKey, "app:license-key\nother:value\n")
+
+			domain := vmiToDomain(vmi, c)
+			Expect(domain.Spec.SysInfo.System).To(ContainElement(api.Entry{Name: "manufacturer", Value: "ACME"}))
+			Expect(domain.Spec.SysInfo.System).To(ContainElement(api.Entry{Name: "product", Value: "Appliance"}))
+			Expect(domain.Spec.SysInfo.System).ToNot(ContainElement(api.Entry{Name: "manufacturer", Value: "KubeVirt"}))
+			Expect(domain.Spec.SysInfo.OEMStrings.Entries).To(Equal([]string{"app:license-key", "other:value"}))
+
+			data, err := xml.Marshal(domain.Spec.SysInfo)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("<oemStrings><entry>app:license-key</entry><entry>other:value</entry></oemStrings>"))
+		})
+
+		It("should keep the cluster wide SMBIOS fields for keys missing from the secret", func() {
+			writeKey(config.SMBiosOEMStringsKey, "app:license-key")
+
+			domain := vmiToDomain(vmi, c)
+			Expect(domain.Spec.SysInfo.System).To(ContainElement(api.Entry{Name: "manufacturer", Value: "KubeVirt"}))
+			Expect(domain.Spec.SysInfo.System).To(ContainElement(api.Entry{Name: "product", Value: "None"}))
+			Expect(domain.Spec.SysInfo.OEMStrings.Entries).To(Equal([]string{"app:license-key"}))
+		})
+
+		It("should not add OEM strings if the secret has none", func() {
+			writeKey(config.SMBiosManufacturerKey, "ACME")
+
+			data, err := xml.Marshal(vmiToDomain(vmi, c).Spec.SysInfo)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).ToNot(ContainSubstring("oemStrings"))
+		})
+
+		It("should fail if the secret is not mounted", func() {
+			Expect(os.RemoveAll(config.SMBiosSecretSourceDir)).To(Succeed())
+
+			err := Convert_v1_VirtualMachine_To_api_Domain(vmi, &api.Domain{}, c)
+			Expect(err).To(MatchError(ContainSubstring("failed to read the SMBIOS secret smbios")))
+		})
+	})
+
 	Context("Correctly handle iothreads with dedicated cpus", func() {
 		var vmi *v1.VirtualMachineInstance
 
@@ -2430,6 +2694,250 @@ var _ = Describe("Converter", func() {
 				Expect(path.Base(domainSpec.OS.NVRam.Template)).To(Equal(EFIVarsSecureBoot))
 				Expect(domainSpec.OS.NVRam.NVRam).To(Equal("/tmp/mynamespace_testvmi"))
 			})
+
+			It("should use the template with the custom certificates if Secure Boot certificates are set", func() {
+				vmi.Spec.Domain.Firmware = &v1.Firmware{
+					Bootloader: &v1.Bootloader{
+						EFI: &v1.EFI{
+							SecureBootCertificates: &v1.SecureBootCertificates{
+								PK:  &k8sv1.LocalObjectReference{Name: "pk"},
+								KEK: &k8sv1.LocalObjectReference{Name: "kek"},
+								DB:  &k8sv1.LocalObjectReference{Name: "db"},
+							},
+						},
+					},
+				}
+				domainSpec := vmiToDomainXMLToDomainSpec(vmi, c)
+				Expect(domainSpec.OS.BootLoader.Secure).To(Equal("yes"))
+				Expect(path.Base(domainSpec.OS.BootLoader.Path)).To(Equal(EFICodeSecureBoot))
+				Expect(domainSpec.OS.NVRam.Template).To(Equal("/tmp/mynamespace_testvmi-" + EFIVarsSecureBoot))
+				Expect(domainSpec.OS.NVRam.NVRam).To(Equal("/tmp/mynamespace_testvmi"))
+			})
+		})
+	})
+
+	Context("Crash action", func() {
+		var vmi *v1.VirtualMachineInstance
+
+		BeforeEach(func() {
+			vmi = &v1.VirtualMachineInstance{
+				ObjectMeta: k8smeta.ObjectMeta{
+					Name:      "testvmi",
+					Namespace: "mynamespace",
+				},
+			}
+			v1.SetObjectDefaults_VirtualMachineInstance(vmi)
+		})
+
+		table.DescribeTable("should map the crash action to on_crash", func(action v1.CrashAction) {
+			vmi.Spec.Domain.OnCrash = &action
+			domainXML := vmiToDomainXML(vmi, &ConverterContext{VirtualMachine: vmi, UseEmulation: true, Architecture: "amd64"})
+			Expect(domainXML).To(ContainSubstring("<on_crash>" + string(action) + "</on_crash>"))
+			Expect(domainXML).To(ContainSubstring("<panic model=\"isa\"></panic>"))
+		},
+			table.Entry("destroy", v1.CrashActionDestroy),
+			table.Entry("restart", v1.CrashActionRestart),
+			table.Entry("preserve", v1.CrashActionPreserve),
+		)
+
+		It("should leave the default crash action and no panic device if unset", func() {
+			domainXML := vmiToDomainXML(vmi, &ConverterContext{VirtualMachine: vmi, UseEmulation: true, Architecture: "amd64"})
+			Expect(domainXML).ToNot(ContainSubstring("on_crash"))
+			Expect(domainXML).ToNot(ContainSubstring("<panic"))
+		})
+
+		It("should not add an isa panic device on ppc64le", func() {
+			action := v1.CrashActionPreserve
+			vmi.Spec.Domain.OnCrash = &action
+			domainSpec := vmiToDomainXMLToDomainSpec(vmi, &ConverterContext{VirtualMachine: vmi, UseEmulation: true, Architecture: "ppc64le"})
+			Expect(domainSpec.OnCrash).To(Equal("preserve"))
+			Expect(domainSpec.Devices.Panic).To(BeNil())
+		})
+	})
+
+	Context("virtiofs", func() {
+		var vmi *v1.VirtualMachineInstance
+
+		BeforeEach(func() {
+			vmi = &v1.VirtualMachineInstance{
+				ObjectMeta: k8smeta.ObjectMeta{
+					Name:      "testvmi",
+					Namespace: "mynamespace",
+				},
+			}
+			v1.SetObjectDefaults_VirtualMachineInstance(vmi)
+		})
+
+		table.DescribeTable("should configure virtiofsd", func(virtiofs v1.FilesystemVirtiofs, expectedBinary string) {
+			vmi.Spec.Domain.Devices.Filesystems = []v1.Filesystem{
+				{Name: "shared", Virtiofs: &virtiofs},
+			}
+			vmi.Spec.Volumes = []v1.Volume{
+				{
+					Name: "shared",
+					VolumeSource: v1.VolumeSource{
+						PersistentVolumeClaim: &k8sv1.PersistentVolumeClaimVolumeSource{ClaimName: "shared"},
+					},
+				},
+			}
+			domainSpec := vmiToDomainXMLToDomainSpec(vmi, &ConverterContext{VirtualMachine: vmi, UseEmulation: true, Architecture: "amd64"})
+			Expect(domainSpec.Devices.Filesystems).To(HaveLen(1))
+			data, err := xml.Marshal(domainSpec.Devices.Filesystems[0].Binary)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(Equal(expectedBinary))
+		},
+			table.Entry("with the defaults", v1.FilesystemVirtiofs{},
+				`<FilesystemBinary path="/usr/libexec/virtiofsd" xattr="on"><cache mode="none"></cache><lock posix="on" flock="on"></lock></FilesystemBinary>`),
+			table.Entry("with cache none", v1.FilesystemVirtiofs{Cache: v1.VirtiofsCacheNone},
+				`<FilesystemBinary path="/usr/libexec/virtiofsd" xattr="on"><cache mode="none"></cache><lock posix="on" flock="on"></lock></FilesystemBinary>`),
+			table.Entry("with cache auto", v1.FilesystemVirtiofs{Cache: v1.VirtiofsCacheAuto},
+				`<FilesystemBinary path="/usr/libexec/virtiofsd" xattr="on"><lock posix="on" flock="on"></lock></FilesystemBinary>`),
+			table.Entry("with cache always", v1.FilesystemVirtiofs{Cache: v1.VirtiofsCacheAlways},
+				`<FilesystemBinary path="/usr/libexec/virtiofsd" xattr="on"><cache mode="always"></cache><lock posix="on" flock="on"></lock></FilesystemBinary>`),
+			table.Entry("with the namespace sandbox", v1.FilesystemVirtiofs{Sandbox: v1.VirtiofsSandboxNamespace},
+				`<FilesystemBinary path="/usr/libexec/virtiofsd" xattr="on"><cache mode="none"></cache><sandbox mode="namespace"></sandbox><lock posix="on" flock="on"></lock></FilesystemBinary>`),
+			table.Entry("with the chroot sandbox and cache always", v1.FilesystemVirtiofs{Cache: v1.VirtiofsCacheAlways, Sandbox: v1.VirtiofsSandboxChroot},
+				`<FilesystemBinary path="/usr/libexec/virtiofsd" xattr="on"><cache mode="always"></cache><sandbox mode="chroot"></sandbox><lock posix="on" flock="on"></lock></FilesystemBinary>`),
+		)
+	})
+
+	Context("Boot configuration", func() {
+		var vmi *v1.VirtualMachineInstance
+
+		bootOrder := func(order uint) *uint {
+			return &order
+		}
+
+		BeforeEach(func() {
+			vmi = &v1.VirtualMachineInstance{
+				ObjectMeta: k8smeta.ObjectMeta{
+					Name:      "testvmi",
+					Namespace: "mynamespace",
+				},
+			}
+			v1.SetObjectDefaults_VirtualMachineInstance(vmi)
+			for _, name := range []string{"disk0", "disk1"} {
+				vmi.Spec.Domain.Devices.Disks = append(vmi.Spec.Domain.Devices.Disks, v1.Disk{Name: name})
+				vmi.Spec.Volumes = append(vmi.Spec.Volumes, v1.Volume{
+					Name: name,
+					VolumeSource: v1.VolumeSource{
+						EmptyDisk: &v1.EmptyDiskSource{Capacity: resource.MustParse("1Gi")},
+					},
+				})
+			}
+			for _, name := range []string{"net0", "net1"} {
+				iface := v1.DefaultBridgeNetworkInterface()
+				iface.Name = name
+				net := v1.DefaultPodNetwork()
+				net.Name = name
+				vmi.Spec.Domain.Devices.Interfaces = append(vmi.Spec.Domain.Devices.Interfaces, *iface)
+				vmi.Spec.Networks = append(vmi.Spec.Networks, *net)
+			}
+		})
+
+		// expectBootOrder expects the boot orders of the converted disks and
+		// interfaces, 0 standing for no boot order
+		expectBootOrder := func(domain *api.Domain, disks []uint, interfaces []uint) {
+			Expect(domain.Spec.Devices.Disks).To(HaveLen(len(disks)))
+			for i, order := range disks {
+				if order == 0 {
+					Expect(domain.Spec.Devices.Disks[i].BootOrder).To(BeNil())
+				} else {
+					Expect(domain.Spec.Devices.Disks[i].BootOrder).To(Equal(&api.BootOrder{Order: order}))
+				}
+			}
+			Expect(domain.Spec.Devices.Interfaces).To(HaveLen(len(interfaces)))
+			for i, order := range interfaces {
+				if order == 0 {
+					Expect(domain.Spec.Devices.Interfaces[i].BootOrder).To(BeNil())
+					Expect(domain.Spec.Devices.Interfaces[i].Rom).To(Equal(&api.Rom{Enabled: "no"}))
+				} else {
+					Expect(domain.Spec.Devices.Interfaces[i].BootOrder).To(Equal(&api.BootOrder{Order: order}))
+					Expect(domain.Spec.Devices.Interfaces[i].Rom).To(BeNil())
+				}
+			}
+		}
+
+		table.DescribeTable("should set the boot order of the devices", func(boot *v1.Boot, diskOrders []*uint, disks []uint, interfaces []uint) {
+			vmi.Spec.Domain.Boot = boot
+			for i, order := range diskOrders {
+				vmi.Spec.Domain.Devices.Disks[i].BootOrder = order
+			}
+			domain := vmiToDomain(vmi, &ConverterContext{VirtualMachine: vmi, UseEmulation: true, Architecture: "amd64"})
+			expectBootOrder(domain, disks, interfaces)
+		},
+			table.Entry("without a boot configuration",
+				nil, []*uint{bootOrder(2), bootOrder(1)}, []uint{2, 1}, []uint{0, 0}),
+			table.Entry("from the order of the boot configuration",
+				&v1.Boot{Order: []v1.BootDevice{{Disk: "disk1"}, {Interface: "net1"}, {Disk: "disk0"}}},
+				nil, []uint{3, 1}, []uint{0, 2}),
+			table.Entry("with the network boot after the per device boot orders",
+				&v1.Boot{NetworkBootLast: true},
+				[]*uint{bootOrder(2), bootOrder(1)}, []uint{2, 1}, []uint{3, 4}),
+			table.Entry("with the network boot after the order of the boot configuration",
+				&v1.Boot{Order: []v1.BootDevice{{Disk: "disk1"}}, NetworkBootLast: true},
+				nil, []uint{0, 1}, []uint{2, 3}),
+			table.Entry("with only the network boot",
+				&v1.Boot{NetworkBootLast: true},
+				nil, []uint{0, 0}, []uint{1, 2}),
+		)
+
+		It("should append the network boot after an interface already in the order", func() {
+			vmi.Spec.Domain.Boot = &v1.Boot{
+				Order:           []v1.BootDevice{{Interface: "net1"}, {Disk: "disk0"}},
+				NetworkBootLast: true,
+			}
+			domain := vmiToDomain(vmi, &ConverterContext{VirtualMachine: vmi, UseEmulation: true, Architecture: "amd64"})
+			expectBootOrder(domain, []uint{2, 0}, []uint{3, 1})
+		})
+
+		It("should not modify the vmi", func() {
+			vmi.Spec.Domain.Boot = &v1.Boot{
+				Order:           []v1.BootDevice{{Disk: "disk1"}},
+				NetworkBootLast: true,
+			}
+			vmiToDomain(vmi, &ConverterContext{VirtualMachine: vmi, UseEmulation: true, Architecture: "amd64"})
+			for _, disk := range vmi.Spec.Domain.Devices.Disks {
+				Expect(disk.BootOrder).To(BeNil())
+			}
+			for _, iface := range vmi.Spec.Domain.Devices.Interfaces {
+				Expect(iface.BootOrder).To(BeNil())
+			}
+		})
+
+		It("should not boot from the network through slirp interfaces", func() {
+			vmi.Spec.Domain.Devices.Interfaces[1].Bridge = nil
+			vmi.Spec.Domain.Devices.Interfaces[1].Slirp = &v1.InterfaceSlirp{}
+			vmi.Spec.Domain.Boot = &v1.Boot{NetworkBootLast: true}
+			resolved := ResolveBootOrder(vmi)
+			Expect(resolved.Spec.Domain.Devices.Interfaces[0].BootOrder).To(Equal(bootOrder(1)))
+			Expect(resolved.Spec.Domain.Devices.Interfaces[1].BootOrder).To(BeNil())
+		})
+
+		It("should return the vmi as is without a boot order configuration", func() {
+			timeout := uint32(5)
+			vmi.Spec.Domain.Boot = &v1.Boot{RebootTimeoutSeconds: &timeout}
+			Expect(ResolveBootOrder(vmi)).To(BeIdenticalTo(vmi))
+		})
+
+		It("should set the reboot timeout of the BIOS in milliseconds", func() {
+			timeout := uint32(5)
+			vmi.Spec.Domain.Boot = &v1.Boot{RebootTimeoutSeconds: &timeout}
+			domainXML := vmiToDomainXML(vmi, &ConverterContext{VirtualMachine: vmi, UseEmulation: true, Architecture: "amd64"})
+			Expect(domainXML).To(ContainSubstring(`<bios rebootTimeout="5000"></bios>`))
+		})
+
+		It("should keep the serial BIOS output along with the reboot timeout", func() {
+			timeout := uint32(0)
+			vmi.Spec.Domain.Firmware = &v1.Firmware{Bootloader: &v1.Bootloader{BIOS: &v1.BIOS{UseSerial: True()}}}
+			vmi.Spec.Domain.Boot = &v1.Boot{RebootTimeoutSeconds: &timeout}
+			domain := vmiToDomain(vmi, &ConverterContext{VirtualMachine: vmi, UseEmulation: true, Architecture: "amd64"})
+			Expect(domain.Spec.OS.BIOS).To(Equal(&api.BIOS{UseSerial: "yes", RebootTimeout: "0"}))
+		})
+
+		It("should not set a reboot timeout by default", func() {
+			domain := vmiToDomain(vmi, &ConverterContext{VirtualMachine: vmi, UseEmulation: true, Architecture: "amd64"})
+			Expect(domain.Spec.OS.BIOS).To(BeNil())
 		})
 	})
 
@@ -2679,6 +3187,119 @@ var _ = Describe("Converter", func() {
 			Expect(domain.Spec.Devices.HostDevices[1].Mode).To(Equal("subsystem"))
 			Expect(domain.Spec.Devices.HostDevices[1].Model).To(Equal("vfio-pci"))
 			Expect(domain.Spec.Devices.HostDevices[1].Alias.GetName()).To(Equal("mdev_name"))
+			Expect(domain.Spec.Devices.HostDevices[0].Address).To(BeNil())
+			Expect(domain.Spec.Devices.HostDevices[1].Address).To(BeNil())
+		})
+
+		It("should place host devices on the requested guest pci addresses", func() {
+			c := &ConverterContext{
+				UseEmulation: true,
+				HostDevices: map[string]HostDevicesList{
+					"vendor.com/pci_name": HostDevicesList{
+						Type:     HostDevicePCI,
+						AddrList: []string{"2609:19:90.0"},
+					},
+					"vendor.com/mdev_name": HostDevicesList{
+						Type:     HostDeviceMDEV,
+						AddrList: []string{"aa618089-8b16-4d01-a136-25a0f3c73123"},
+					},
+				},
+			}
+			vmiWithAddresses := vmi.DeepCopy()
+			vmiWithAddresses.Spec.Domain.Devices.HostDevices[0].PciAddress = "0000:81:0a.0"
+			vmiWithAddresses.Spec.Domain.Devices.HostDevices[1].PciAddress = "0000:81:0b.1"
+			domain := vmiToDomain(vmiWithAddresses, c)
+
+			Expect(domain.Spec.Devices.HostDevices[0].Address).To(Equal(&api.Address{
+				Type:     "pci",
+				Domain:   "0x0000",
+				Bus:      "0x81",
+				Slot:     "0x0a",
+				Function: "0x0",
+			}))
+			Expect(domain.Spec.Devices.HostDevices[0].Source.Address.Domain).To(Equal("0x2609"))
+			Expect(domain.Spec.Devices.HostDevices[1].Address).To(Equal(&api.Address{
+				Type:     "pci",
+				Domain:   "0x0000",
+				Bus:      "0x81",
+				Slot:     "0x0b",
+				Function: "0x1",
+			}))
+		})
+
+		It("should fail on a malformed guest pci address", func() {
+			c := &ConverterContext{
+				UseEmulation: true,
+				HostDevices: map[string]HostDevicesList{
+					"vendor.com/pci_name": HostDevicesList{
+						Type:     HostDevicePCI,
+						AddrList: []string{"2609:19:90.0"},
+					},
+					"vendor.com/mdev_name": HostDevicesList{
+						Type:     HostDeviceMDEV,
+						AddrList: []string{"aa618089-8b16-4d01-a136-25a0f3c73123"},
+					},
+				},
+			}
+			vmiWithAddresses := vmi.DeepCopy()
+			vmiWithAddresses.Spec.Domain.Devices.HostDevices[0].PciAddress = "81:0a.0"
+			domain := &api.Domain{}
+			err := Convert_HostDevices_And_GPU(vmiWithAddresses.Spec.Domain.Devices, domain, c)
+			Expect(err).To(MatchError(ContainSubstring("failed to configure host device pci_name")))
+		})
+
+		It("should point PCI host devices with a ROM at the copied ROM image", func() {
+			c := &ConverterContext{
+				UseEmulation: true,
+				HostDevices: map[string]HostDevicesList{
+					"vendor.com/pci_name": HostDevicesList{
+						Type:     HostDevicePCI,
+						AddrList: []string{"2609:19:90.0"},
+					},
+					"vendor.com/mdev_name": HostDevicesList{
+						Type:     HostDeviceMDEV,
+						AddrList: []string{"aa618089-8b16-4d01-a136-25a0f3c73123"},
+					},
+				},
+			}
+			vmiWithROM := vmi.DeepCopy()
+			vmiWithROM.Spec.Domain.Devices.HostDevices[0].ROM = &v1.HostDeviceROM{
+				ConfigMap: &k8sv1.LocalObjectReference{Name: "my-rom"},
+			}
+			domain := vmiToDomain(vmiWithROM, c)
+
+			Expect(domain.Spec.Devices.HostDevices[0].Rom).To(Equal(&api.Rom{
+				Bar:  "on",
+				File: "/var/run/kubevirt-private/host-device-roms/pci_name.rom",
+			}))
+			Expect(domain.Spec.Devices.HostDevices[1].Rom).To(BeNil())
+
+			hostDevXML, err := xml.Marshal(domain.Spec.Devices.HostDevices[0])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(hostDevXML)).To(ContainSubstring(`<rom bar="on" file="/var/run/kubevirt-private/host-device-roms/pci_name.rom"></rom>`))
+		})
+
+		It("should fail to set a ROM on a mediated device", func() {
+			c := &ConverterContext{
+				UseEmulation: true,
+				HostDevices: map[string]HostDevicesList{
+					"vendor.com/pci_name": HostDevicesList{
+						Type:     HostDevicePCI,
+						AddrList: []string{"2609:19:90.0"},
+					},
+					"vendor.com/mdev_name": HostDevicesList{
+						Type:     HostDeviceMDEV,
+						AddrList: []string{"aa618089-8b16-4d01-a136-25a0f3c73123"},
+					},
+				},
+			}
+			vmiWithROM := vmi.DeepCopy()
+			vmiWithROM.Spec.Domain.Devices.HostDevices[1].ROM = &v1.HostDeviceROM{
+				Secret: &k8sv1.LocalObjectReference{Name: "my-rom"},
+			}
+			domain := &api.Domain{}
+			err := Convert_HostDevices_And_GPU(vmiWithROM.Spec.Domain.Devices, domain, c)
+			Expect(err).To(MatchError(ContainSubstring("failed to configure host device mdev_name")))
 		})
 	})
 
@@ -2723,6 +3344,233 @@ var _ = Describe("Converter", func() {
 		})
 	})
 
+	Context("qemu log filters", func() {
+		var vmi *v1.VirtualMachineInstance
+		var c *ConverterContext
+
+		BeforeEach(func() {
+			vmi = &v1.VirtualMachineInstance{
+				ObjectMeta: k8smeta.ObjectMeta{
+					Name:        "testvmi",
+					Namespace:   "mynamespace",
+					Annotations: map[string]string{},
+				},
+			}
+
+			v1.SetObjectDefaults_VirtualMachineInstance(vmi)
+
+			c = &ConverterContext{
+				VirtualMachine: vmi,
+				UseEmulation:   true,
+			}
+		})
+
+		table.DescribeTable("should pass exactly the requested items to qemu", func(filters string, expectedItems string) {
+			vmi.Annotations[v1.QEMULogFiltersAnnotation] = filters
+			domain := vmiToDomain(vmi, c)
+			Expect(domain.Spec.QEMUCmd).ToNot(BeNil())
+			Expect(domain.Spec.QEMUCmd.QEMUArg).To(Equal([]api.Arg{{Value: "-d"}, {Value: expectedItems}}))
+		},
+			table.Entry("with a single item", "guest_errors", "guest_errors"),
+			table.Entry("with several items", "guest_errors,unimp", "guest_errors,unimp"),
+			table.Entry("in the requested order", "unimp,cpu_reset,guest_errors", "unimp,cpu_reset,guest_errors"),
+			table.Entry("without spaces and duplicates", " guest_errors , unimp,guest_errors,", "guest_errors,unimp"),
+		)
+
+		It("should not pass any log flag without annotation", func() {
+			domain := vmiToDomain(vmi, c)
+			Expect(domain.Spec.QEMUCmd).To(BeNil())
+		})
+
+		It("should not pass any log flag for an empty annotation", func() {
+			vmi.Annotations[v1.QEMULogFiltersAnnotation] = ""
+			domain := vmiToDomain(vmi, c)
+			Expect(domain.Spec.QEMUCmd).To(BeNil())
+		})
+
+		It("should reject unknown items", func() {
+			vmi.Annotations[v1.QEMULogFiltersAnnotation] = "guest_errors,everything"
+			err := Convert_v1_VirtualMachine_To_api_Domain(vmi, &api.Domain{}, c)
+			Expect(err).To(MatchError(ContainSubstring(`unknown qemu log item "everything"`)))
+		})
+	})
+
+	Context("per NUMA node hugepages", func() {
+		var vmi *v1.VirtualMachineInstance
+		var c *ConverterContext
+
+		newVMI := func(cores uint32, memory string, nodes ...v1.NUMANodeHugepages) *v1.VirtualMachineInstance {
+			vmi := &v1.VirtualMachineInstance{
+				ObjectMeta: k8smeta.ObjectMeta{
+					Name:      "testvmi",
+					Namespace: "mynamespace",
+				},
+			}
+			v1.SetObjectDefaults_VirtualMachineInstance(vmi)
+			vmi.Spec.Domain.CPU = &v1.CPU{Cores: cores}
+			vmi.Spec.Domain.Resources.Requests = k8sv1.ResourceList{
+				k8sv1.ResourceMemory: resource.MustParse(memory),
+			}
+			vmi.Spec.Domain.Memory = &v1.Memory{
+				Hugepages: &v1.Hugepages{PageSize: "2Mi", PerNUMANode: nodes},
+			}
+			return vmi
+		}
+
+		node := func(guestNode, hostNode uint32, memory string) v1.NUMANodeHugepages {
+			return v1.NUMANodeHugepages{GuestNode: guestNode, HostNode: hostNode, Memory: resource.MustParse(memory)}
+		}
+
+		BeforeEach(func() {
+			c = &ConverterContext{UseEmulation: true}
+		})
+
+		It("should place a single guest node on a host node", func() {
+			vmi = newVMI(2, "1Gi", node(0, 1, "1Gi"))
+			c.VirtualMachine = vmi
+			domainSpec := vmiToDomainXMLToDomainSpec(vmi, c)
+
+			Expect(domainSpec.CPU.NUMA.Cells).To(Equal([]api.NUMACell{
+				{ID: "0", CPUs: "0-1", Memory: "1048576", Unit: "KiB"},
+			}))
+			Expect(domainSpec.MemoryBacking.HugePages.HugePage).To(Equal([]api.HugePage{
+				{Size: "2048", Unit: "KiB", NodeSet: "0"},
+			}))
+			Expect(domainSpec.MemoryBacking.Source.Type).To(Equal("memfd"))
+			Expect(domainSpec.NUMATune).To(Equal(&api.NUMATune{
+				Memory:   api.NUMATuneMemory{Mode: "strict", NodeSet: "1"},
+				MemNodes: []api.MemNode{{CellID: 0, Mode: "strict", NodeSet: "1"}},
+			}))
+		})
+
+		It("should spread the guest over two host nodes", func() {
+			vmi = newVMI(4, "3Gi", node(1, 1, "1Gi"), node(0, 0, "2Gi"))
+			c.VirtualMachine = vmi
+			domainSpec := vmiToDomainXMLToDomainSpec(vmi, c)
+
+			Expect(domainSpec.CPU.NUMA.Cells).To(Equal([]api.NUMACell{
+				{ID: "0", CPUs: "0-1", Memory: "2097152", Unit: "KiB"},
+				{ID: "1", CPUs: "2-3", Memory: "1048576", Unit: "KiB"},
+			}))
+			Expect(domainSpec.MemoryBacking.HugePages.HugePage).To(Equal([]api.HugePage{
+				{Size: "2048", Unit: "KiB", NodeSet: "0,1"},
+			}))
+			Expect(domainSpec.NUMATune).To(Equal(&api.NUMATune{
+				Memory: api.NUMATuneMemory{Mode: "strict", NodeSet: "0,1"},
+				MemNodes: []api.MemNode{
+					{CellID: 0, Mode: "strict", NodeSet: "0"},
+					{CellID: 1, Mode: "strict", NodeSet: "1"},
+				},
+			}))
+		})
+
+		It("should spread the guest over two nodes of the same host node", func() {
+			vmi = newVMI(3, "2Gi", node(0, 0, "1Gi"), node(1, 0, "1Gi"))
+			c.VirtualMachine = vmi
+			domainSpec := vmiToDomainXMLToDomainSpec(vmi, c)
+
+			Expect(domainSpec.CPU.NUMA.Cells).To(Equal([]api.NUMACell{
+				{ID: "0", CPUs: "0-1", Memory: "1048576", Unit: "KiB"},
+				{ID: "1", CPUs: "2", Memory: "1048576", Unit: "KiB"},
+			}))
+			Expect(domainSpec.NUMATune.Memory).To(Equal(api.NUMATuneMemory{Mode: "strict", NodeSet: "0"}))
+			Expect(domainSpec.NUMATune.MemNodes).To(HaveLen(2))
+		})
+
+		It("should render the numatune element", func() {
+			vmi = newVMI(2, "2Gi", node(0, 0, "1Gi"), node(1, 1, "1Gi"))
+			c.VirtualMachine = vmi
+			domainXML := vmiToDomainXML(vmi, c)
+
+			Expect(domainXML).To(ContainSubstring(`<page size="2048" unit="KiB" nodeset="0,1"></page>`))
+			Expect(domainXML).To(ContainSubstring(`<memory mode="strict" nodeset="0,1"></memory>`))
+			Expect(domainXML).To(ContainSubstring(`<memnode cellid="1" mode="strict" nodeset="1"></memnode>`))
+		})
+
+		It("should fail with less vCPUs than guest nodes", func() {
+			vmi = newVMI(1, "2Gi", node(0, 0, "1Gi"), node(1, 1, "1Gi"))
+			c.VirtualMachine = vmi
+			err := Convert_v1_VirtualMachine_To_api_Domain(vmi, &api.Domain{}, c)
+			Expect(err).To(MatchError(ContainSubstring("2 guest NUMA nodes need at least as many vCPUs")))
+		})
+
+		It("should not add numatune without per NUMA node hugepages", func() {
+			vmi = newVMI(2, "1Gi")
+			c.VirtualMachine = vmi
+			domainSpec := vmiToDomainXMLToDomainSpec(vmi, c)
+
+			Expect(domainSpec.NUMATune).To(BeNil())
+			Expect(domainSpec.MemoryBacking.HugePages.HugePage).To(BeEmpty())
+			Expect(domainSpec.CPU.NUMA.Cells).To(HaveLen(1))
+		})
+	})
+
+	Context("vCPU rebalancing", func() {
+		// two host NUMA nodes with eight CPUs each
+		nodeCPUs := map[uint32][]int{
+			0: {0, 1, 2, 3, 4, 5, 6, 7},
+			1: {8, 9, 10, 11, 12, 13, 14, 15},
+		}
+
+		newSpec := func(vcpus uint32, cells ...api.NUMACell) *api.DomainSpec {
+			spec := &api.DomainSpec{VCPU: &api.VCPU{Placement: "static", CPUs: vcpus}}
+			if len(cells) > 0 {
+				spec.CPU.NUMA = &api.NUMA{Cells: cells}
+			}
+			return spec
+		}
+
+		bindCells := func(spec *api.DomainSpec, hostNodes ...string) *api.DomainSpec {
+			spec.NUMATune = &api.NUMATune{}
+			for cell, hostNode := range hostNodes {
+				spec.NUMATune.MemNodes = append(spec.NUMATune.MemNodes, api.MemNode{CellID: uint32(cell), Mode: "strict", NodeSet: hostNode})
+			}
+			return spec
+		}
+
+		pinnedCPUs := func(pins []api.CPUTuneVCPUPin) []string {
+			var cpus []string
+			for i, pin := range pins {
+				ExpectWithOffset(1, pin.VCPU).To(Equal(uint(i)))
+				cpus = append(cpus, pin.CPUSet)
+			}
+			return cpus
+		}
+
+		table.DescribeTable("should pin the vCPUs along the NUMA layout of the node", func(spec *api.DomainSpec, cpuSet []int, expected []string) {
+			pins, err := RebalanceVCPUPinning(spec, cpuSet, nodeCPUs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pinnedCPUs(pins)).To(Equal(expected))
+		},
+			table.Entry("on the only node fitting all of them",
+				newSpec(4), []int{2, 3, 9, 10, 11, 12}, []string{"9", "10", "11", "12"}),
+			table.Entry("on the fitting node with the fewest CPUs left",
+				newSpec(4), []int{12, 11, 10, 9, 8, 3, 2, 1, 0}, []string{"0", "1", "2", "3"}),
+			table.Entry("on the nodes with the most CPUs first if none fits them",
+				newSpec(5), []int{0, 1, 2, 8, 9}, []string{"0", "1", "2", "8", "9"}),
+			table.Entry("on the host nodes the guest cells are bound to",
+				bindCells(newSpec(4, api.NUMACell{ID: "0", CPUs: "0-1"}, api.NUMACell{ID: "1", CPUs: "2-3"}), "1", "0"),
+				[]int{0, 1, 8, 9}, []string{"8", "9", "0", "1"}),
+			table.Entry("keeping each unbound guest cell on a single node",
+				newSpec(4, api.NUMACell{ID: "0", CPUs: "0-1"}, api.NUMACell{ID: "1", CPUs: "2-3"}),
+				[]int{0, 1, 2, 8, 9, 10}, []string{"0", "1", "8", "9"}),
+			table.Entry("falling back to other nodes if the bound node lacks CPUs",
+				bindCells(newSpec(2, api.NUMACell{ID: "0", CPUs: "0-1"}), "1"),
+				[]int{0, 1, 8}, []string{"0", "1"}),
+			table.Entry("using the CPUs on no node last",
+				newSpec(3), []int{0, 1, 42}, []string{"0", "1", "42"}),
+		)
+
+		It("should fail if the pod has fewer CPUs than vCPUs", func() {
+			_, err := RebalanceVCPUPinning(newSpec(4), []int{0, 1, 2}, nodeCPUs)
+			Expect(err).To(MatchError("4 vCPUs need as many dedicated CPUs, the pod has 3"))
+		})
+
+		It("should fail on malformed guest cells", func() {
+			_, err := RebalanceVCPUPinning(newSpec(2, api.NUMACell{ID: "0", CPUs: "0-a"}), []int{0, 1}, nodeCPUs)
+			Expect(err).To(MatchError(ContainSubstring("failed to parse the vCPUs of guest NUMA cell 0")))
+		})
+	})
 })
 
 var _ = Describe("disk device naming", func() {
@@ -0,0 +1,105 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+package converter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/api"
+)
+
+// setPerNUMANodeHugepages creates a guest NUMA cell for every entry of
+// Hugepages.PerNUMANode, backs it with hugepages and binds its memory strictly
+// to the requested host NUMA node. The vCPUs are spread evenly across the
+// cells in contiguous ranges.
+func setPerNUMANodeHugepages(hugepages *v1.Hugepages, domain *api.Domain) error {
+	pageSize, err := resource.ParseQuantity(hugepages.PageSize)
+	if err != nil {
+		return fmt.Errorf("invalid hugepage size %q: %v", hugepages.PageSize, err)
+	}
+
+	nodes := append([]v1.NUMANodeHugepages(nil), hugepages.PerNUMANode...)
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].GuestNode < nodes[j].GuestNode
+	})
+
+	vcpus := domain.Spec.VCPU.CPUs
+	if vcpus < uint32(len(nodes)) {
+		return fmt.Errorf("%d guest NUMA nodes need at least as many vCPUs, got %d", len(nodes), vcpus)
+	}
+
+	numa := &api.NUMA{}
+	numaTune := &api.NUMATune{}
+	var guestNodes, hostNodes []string
+	seenHostNodes := map[uint32]bool{}
+	firstCPU := uint32(0)
+	for i, node := range nodes {
+		cpus := vcpus / uint32(len(nodes))
+		if uint32(i) < vcpus%uint32(len(nodes)) {
+			cpus++
+		}
+		guestNode := strconv.FormatUint(uint64(node.GuestNode), 10)
+		hostNode := strconv.FormatUint(uint64(node.HostNode), 10)
+		numa.Cells = append(numa.Cells, api.NUMACell{
+			ID:     guestNode,
+			CPUs:   cpuRange(firstCPU, firstCPU+cpus-1),
+			Memory: fmt.Sprintf("%d", node.Memory.Value()/int64(1024)),
+			Unit:   "KiB",
+		})
+		numaTune.MemNodes = append(numaTune.MemNodes, api.MemNode{
+			CellID:  node.GuestNode,
+			Mode:    "strict",
+			NodeSet: hostNode,
+		})
+		guestNodes = append(guestNodes, guestNode)
+		if !seenHostNodes[node.HostNode] {
+			seenHostNodes[node.HostNode] = true
+			hostNodes = append(hostNodes, hostNode)
+		}
+		firstCPU += cpus
+	}
+	numaTune.Memory = api.NUMATuneMemory{
+		Mode:    "strict",
+		NodeSet: strings.Join(hostNodes, ","),
+	}
+
+	domain.Spec.CPU.NUMA = numa
+	domain.Spec.NUMATune = numaTune
+	domain.Spec.MemoryBacking.HugePages.HugePage = []api.HugePage{
+		{
+			Size:    fmt.Sprintf("%d", pageSize.Value()/int64(1024)),
+			Unit:    "KiB",
+			NodeSet: strings.Join(guestNodes, ","),
+		},
+	}
+	return nil
+}
+
+func cpuRange(first, last uint32) string {
+	if first == last {
+		return fmt.Sprintf("%d", first)
+	}
+	return fmt.Sprintf("%d-%d", first, last)
+}
@@ -0,0 +1,96 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/api"
+)
+
+// validQEMULogItems are the items of the qemu -d flag which may be requested
+// through the QEMULogFiltersAnnotation.
+var validQEMULogItems = map[string]struct{}{
+	"out_asm":      {},
+	"in_asm":       {},
+	"op":           {},
+	"op_opt":       {},
+	"op_ind":       {},
+	"int":          {},
+	"exec":         {},
+	"cpu":          {},
+	"fpu":          {},
+	"mmu":          {},
+	"pcall":        {},
+	"cpu_reset":    {},
+	"unimp":        {},
+	"guest_errors": {},
+	"page":         {},
+	"nochain":      {},
+	"strace":       {},
+}
+
+// ParseQEMULogFilters parses the comma separated qemu log items of the
+// QEMULogFiltersAnnotation. Unknown items are rejected, duplicates are dropped
+// and the order of the remaining items is kept.
+func ParseQEMULogFilters(value string) ([]string, error) {
+	var items []string
+	seen := map[string]struct{}{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if _, valid := validQEMULogItems[item]; !valid {
+			return nil, fmt.Errorf("unknown qemu log item %q", item)
+		}
+		if _, duplicate := seen[item]; duplicate {
+			continue
+		}
+		seen[item] = struct{}{}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// addQEMULogFilters passes the qemu log items requested by the
+// QEMULogFiltersAnnotation of the VMI to qemu.
+func addQEMULogFilters(vmi *v1.VirtualMachineInstance, domain *api.Domain) error {
+	value, exists := vmi.Annotations[v1.QEMULogFiltersAnnotation]
+	if !exists {
+		return nil
+	}
+	items, err := ParseQEMULogFilters(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s annotation: %v", v1.QEMULogFiltersAnnotation, err)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	if domain.Spec.QEMUCmd == nil {
+		domain.Spec.QEMUCmd = &api.Commandline{}
+	}
+	domain.Spec.QEMUCmd.QEMUArg = append(domain.Spec.QEMUCmd.QEMUArg,
+		api.Arg{Value: "-d"},
+		api.Arg{Value: strings.Join(items, ",")},
+	)
+	return nil
+}
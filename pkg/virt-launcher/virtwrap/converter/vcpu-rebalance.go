@@ -0,0 +1,204 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package converter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"kubevirt.io/kubevirt/pkg/util/hardware"
+	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/api"
+)
+
+// vcpuGroup is a set of vCPUs which should share a host NUMA node, the one
+// hostNode points to if their guest NUMA cell is bound to it.
+type vcpuGroup struct {
+	vcpus    []int
+	hostNode *uint32
+}
+
+// RebalanceVCPUPinning recomputes the pinning of the vCPUs of spec on cpuSet,
+// the dedicated CPUs of the pod, given the CPUs of every host NUMA node. Each
+// guest NUMA cell, or all the vCPUs without guest NUMA cells, goes to the host
+// NUMA node its memory is bound to, or else to the node with the fewest CPUs
+// left which fits it, and is spread over the nodes with the most CPUs left
+// only if none fits it.
+func RebalanceVCPUPinning(spec *api.DomainSpec, cpuSet []int, nodeCPUs map[uint32][]int) ([]api.CPUTuneVCPUPin, error) {
+	if spec.VCPU == nil {
+		return nil, fmt.Errorf("the domain has no vCPUs")
+	}
+	vcpus := int(spec.VCPU.CPUs)
+	if len(cpuSet) < vcpus {
+		return nil, fmt.Errorf("%d vCPUs need as many dedicated CPUs, the pod has %d", vcpus, len(cpuSet))
+	}
+	groups, err := getVCPUGroups(spec, vcpus)
+	if err != nil {
+		return nil, err
+	}
+
+	free := newFreeCPUs(cpuSet, nodeCPUs)
+	pins := make([]api.CPUTuneVCPUPin, 0, vcpus)
+	for _, group := range groups {
+		for i, cpu := range free.take(len(group.vcpus), group.hostNode) {
+			pins = append(pins, api.CPUTuneVCPUPin{
+				VCPU:   uint(group.vcpus[i]),
+				CPUSet: strconv.Itoa(cpu),
+			})
+		}
+	}
+	sort.Slice(pins, func(i, j int) bool {
+		return pins[i].VCPU < pins[j].VCPU
+	})
+	return pins, nil
+}
+
+// getVCPUGroups groups the vCPUs by guest NUMA cell, vCPUs outside of any cell
+// forming a group of their own.
+func getVCPUGroups(spec *api.DomainSpec, vcpus int) ([]vcpuGroup, error) {
+	hostNodes := map[uint32]uint32{}
+	if spec.NUMATune != nil {
+		for _, memNode := range spec.NUMATune.MemNodes {
+			if node, err := strconv.ParseUint(memNode.NodeSet, 10, 32); err == nil {
+				hostNodes[memNode.CellID] = uint32(node)
+			}
+		}
+	}
+
+	var groups []vcpuGroup
+	grouped := map[int]bool{}
+	if spec.CPU.NUMA != nil {
+		for _, cell := range spec.CPU.NUMA.Cells {
+			cellVCPUs, err := hardware.ParseCPUSetLine(cell.CPUs)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse the vCPUs of guest NUMA cell %s: %v", cell.ID, err)
+			}
+			group := vcpuGroup{}
+			for _, vcpu := range cellVCPUs {
+				if vcpu < vcpus && !grouped[vcpu] {
+					grouped[vcpu] = true
+					group.vcpus = append(group.vcpus, vcpu)
+				}
+			}
+			if id, err := strconv.ParseUint(cell.ID, 10, 32); err == nil {
+				if node, exists := hostNodes[uint32(id)]; exists {
+					group.hostNode = &node
+				}
+			}
+			if len(group.vcpus) > 0 {
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	rest := vcpuGroup{}
+	for vcpu := 0; vcpu < vcpus; vcpu++ {
+		if !grouped[vcpu] {
+			rest.vcpus = append(rest.vcpus, vcpu)
+		}
+	}
+	if len(rest.vcpus) > 0 {
+		groups = append(groups, rest)
+	}
+	return groups, nil
+}
+
+// freeCPUs are the CPUs of the pod not pinned yet, by host NUMA node.
+type freeCPUs struct {
+	nodes  []uint32
+	byNode map[uint32][]int
+	// other are the CPUs of the pod found on no host NUMA node
+	other []int
+}
+
+func newFreeCPUs(cpuSet []int, nodeCPUs map[uint32][]int) *freeCPUs {
+	nodeOfCPU := map[int]uint32{}
+	for node, cpus := range nodeCPUs {
+		for _, cpu := range cpus {
+			nodeOfCPU[cpu] = node
+		}
+	}
+	free := &freeCPUs{byNode: map[uint32][]int{}}
+	sortedCPUs := append([]int(nil), cpuSet...)
+	sort.Ints(sortedCPUs)
+	for _, cpu := range sortedCPUs {
+		node, exists := nodeOfCPU[cpu]
+		if !exists {
+			free.other = append(free.other, cpu)
+			continue
+		}
+		if _, seen := free.byNode[node]; !seen {
+			free.nodes = append(free.nodes, node)
+		}
+		free.byNode[node] = append(free.byNode[node], cpu)
+	}
+	sort.Slice(free.nodes, func(i, j int) bool {
+		return free.nodes[i] < free.nodes[j]
+	})
+	return free
+}
+
+// take removes and returns count CPUs, from preferredNode if it has enough of
+// them, else from the node with the fewest CPUs which has enough of them,
+// else from the nodes with the most CPUs first. The caller makes sure that
+// there are enough CPUs left.
+func (f *freeCPUs) take(count int, preferredNode *uint32) []int {
+	if preferredNode != nil && len(f.byNode[*preferredNode]) >= count {
+		return f.takeFrom(*preferredNode, count)
+	}
+
+	bestFit, fits := uint32(0), false
+	for _, node := range f.nodes {
+		left := len(f.byNode[node])
+		if left >= count && (!fits || left < len(f.byNode[bestFit])) {
+			bestFit, fits = node, true
+		}
+	}
+	if fits {
+		return f.takeFrom(bestFit, count)
+	}
+
+	nodes := append([]uint32(nil), f.nodes...)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return len(f.byNode[nodes[i]]) > len(f.byNode[nodes[j]])
+	})
+	var cpus []int
+	for _, node := range nodes {
+		if len(cpus) == count {
+			break
+		}
+		cpus = append(cpus, f.takeFrom(node, count-len(cpus))...)
+	}
+	if missing := count - len(cpus); missing > 0 {
+		cpus = append(cpus, f.other[:missing]...)
+		f.other = f.other[missing:]
+	}
+	return cpus
+}
+
+// takeFrom removes and returns up to count of the lowest CPUs of node.
+func (f *freeCPUs) takeFrom(node uint32, count int) []int {
+	cpus := f.byNode[node]
+	if count > len(cpus) {
+		count = len(cpus)
+	}
+	f.byNode[node] = cpus[count:]
+	return cpus[:count]
+}
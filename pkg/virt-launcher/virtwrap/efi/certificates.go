@@ -0,0 +1,105 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package efi
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"kubevirt.io/kubevirt/pkg/config"
+)
+
+const pemCertificateType = "CERTIFICATE"
+
+// Certificates holds the certificates enrolled in each Secure Boot certificate store
+type Certificates struct {
+	PK  *x509.Certificate
+	KEK []*x509.Certificate
+	DB  []*x509.Certificate
+}
+
+// ReadCertificates reads and validates the certificates of all Secure Boot certificate stores
+// from the secrets mounted on the pod
+func ReadCertificates() (*Certificates, error) {
+	secrets, err := config.ReadSecureBootCertificatesSecrets()
+	if err != nil {
+		return nil, err
+	}
+	return NewCertificates(secrets)
+}
+
+// NewCertificates parses and validates the certificates of all Secure Boot certificate stores.
+// The Platform Key store must hold exactly one certificate, the other stores at least one.
+func NewCertificates(secrets *config.SecureBootCertificatesSecrets) (*Certificates, error) {
+	pk, err := ParseCertificates(secrets.PK)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificates in the Secure Boot %s store: %v", config.SecureBootPKStore, err)
+	}
+	if len(pk) != 1 {
+		return nil, fmt.Errorf("the Secure Boot %s store must hold exactly one certificate, found %d", config.SecureBootPKStore, len(pk))
+	}
+	kek, err := ParseCertificates(secrets.KEK)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificates in the Secure Boot %s store: %v", config.SecureBootKEKStore, err)
+	}
+	db, err := ParseCertificates(secrets.DB)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificates in the Secure Boot %s store: %v", config.SecureBootDBStore, err)
+	}
+	return &Certificates{PK: pk[0], KEK: kek, DB: db}, nil
+}
+
+// ParseCertificates parses PEM encoded X.509 certificates, or a single DER encoded one.
+// Any PEM block which is not a certificate, and any trailing data, is rejected.
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	rest := bytes.TrimSpace(data)
+	if len(rest) == 0 {
+		return nil, fmt.Errorf("no certificates found")
+	}
+
+	if !bytes.HasPrefix(rest, []byte("-----BEGIN")) {
+		certificate, err := x509.ParseCertificate(rest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse DER encoded certificate: %v", err)
+		}
+		return []*x509.Certificate{certificate}, nil
+	}
+
+	var certificates []*x509.Certificate
+	for len(rest) > 0 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, fmt.Errorf("failed to decode PEM data after %d certificates", len(certificates))
+		}
+		if block.Type != pemCertificateType {
+			return nil, fmt.Errorf("unexpected PEM block of type %q", block.Type)
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PEM encoded certificate: %v", err)
+		}
+		certificates = append(certificates, certificate)
+		rest = bytes.TrimSpace(rest)
+	}
+	return certificates, nil
+}
@@ -0,0 +1,132 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package efi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/config"
+)
+
+func newTestCertificate(commonName string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+	return der
+}
+
+func encodePEM(blockType string, ders ...[]byte) []byte {
+	var data []byte
+	for _, der := range ders {
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})...)
+	}
+	return data
+}
+
+var _ = Describe("Secure Boot certificates", func() {
+
+	var pk, kek, db, db2 []byte
+
+	BeforeEach(func() {
+		pk = newTestCertificate("pk")
+		kek = newTestCertificate("kek")
+		db = newTestCertificate("db")
+		db2 = newTestCertificate("db2")
+	})
+
+	Context("parsing", func() {
+		It("should accept PEM encoded certificates", func() {
+			certificates, err := ParseCertificates(encodePEM(pemCertificateType, db, db2))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(certificates).To(HaveLen(2))
+			Expect(certificates[0].Subject.CommonName).To(Equal("db"))
+			Expect(certificates[1].Subject.CommonName).To(Equal("db2"))
+		})
+
+		It("should accept a single DER encoded certificate", func() {
+			certificates, err := ParseCertificates(db)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(certificates).To(HaveLen(1))
+			Expect(certificates[0].Raw).To(Equal(db))
+		})
+
+		table.DescribeTable("should reject", func(data func() []byte) {
+			_, err := ParseCertificates(data())
+			Expect(err).To(HaveOccurred())
+		},
+			table.Entry("empty data", func() []byte { return []byte("\n") }),
+			table.Entry("garbage", func() []byte { return []byte("not a certificate") }),
+			table.Entry("a PEM private key", func() []byte { return encodePEM("PRIVATE KEY", []byte("key")) }),
+			table.Entry("an invalid PEM certificate", func() []byte { return encodePEM(pemCertificateType, []byte("invalid")) }),
+			table.Entry("trailing garbage after PEM certificates", func() []byte {
+				return append(encodePEM(pemCertificateType, db), []byte("garbage")...)
+			}),
+		)
+	})
+
+	Context("validation", func() {
+		It("should accept a complete set of certificates", func() {
+			certificates, err := NewCertificates(&config.SecureBootCertificatesSecrets{
+				PK:  encodePEM(pemCertificateType, pk),
+				KEK: kek,
+				DB:  encodePEM(pemCertificateType, db, db2),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(certificates.PK.Raw).To(Equal(pk))
+			Expect(certificates.KEK).To(HaveLen(1))
+			Expect(certificates.DB).To(HaveLen(2))
+		})
+
+		It("should reject more than one Platform Key", func() {
+			_, err := NewCertificates(&config.SecureBootCertificatesSecrets{
+				PK:  encodePEM(pemCertificateType, pk, kek),
+				KEK: kek,
+				DB:  db,
+			})
+			Expect(err).To(MatchError(ContainSubstring("exactly one certificate")))
+		})
+
+		It("should reject an empty store", func() {
+			_, err := NewCertificates(&config.SecureBootCertificatesSecrets{
+				PK:  pk,
+				KEK: kek,
+			})
+			Expect(err).To(MatchError(ContainSubstring("db store")))
+		})
+	})
+})
@@ -0,0 +1,339 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package efi
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"unicode/utf16"
+)
+
+// The layout of the variable store follows the authenticated variable store of EDK2,
+// as found in the OVMF_VARS.secboot.fd firmware volume shipped with OVMF.
+const (
+	firmwareVolumeSignature = "_FVH"
+	// offsets of the EFI_FIRMWARE_VOLUME_HEADER fields
+	firmwareVolumeLengthOffset       = 32
+	firmwareVolumeSignatureOffset    = 40
+	firmwareVolumeHeaderLengthOffset = 48
+	firmwareVolumeMinHeaderLength    = 56
+
+	variableStoreHeaderSize  = 28
+	variableStoreFormatted   = 0x5a
+	variableStoreHealthy     = 0xfe
+	variableHeaderSize       = 60
+	variableStartID          = 0x55aa
+	variableAdded            = 0x3f
+	variableInDeletedTransit = 0xfe
+	variableHeaderAlignment  = 4
+
+	// EFI_VARIABLE_NON_VOLATILE | EFI_VARIABLE_BOOTSERVICE_ACCESS | EFI_VARIABLE_RUNTIME_ACCESS |
+	// EFI_VARIABLE_TIME_BASED_AUTHENTICATED_WRITE_ACCESS
+	secureBootVariableAttributes = 0x27
+
+	signatureListHeaderSize = 28
+	signatureOwnerSize      = 16
+
+	pkVariable  = "PK"
+	kekVariable = "KEK"
+	dbVariable  = "db"
+)
+
+var (
+	authenticatedVariableStoreGUID = mustParseGUID("aaf32c78-947b-439a-a180-2e144ec37792")
+	globalVariableGUID             = mustParseGUID("8be4df61-93ca-11d2-aa0d-00e098032b8c")
+	imageSecurityDatabaseGUID      = mustParseGUID("d719b2cb-3d3a-4596-a3bc-dad00e67656f")
+	certX509GUID                   = mustParseGUID("a5c059a1-94e4-4aa7-87b5-ab155c2bf072")
+	// signatureOwnerGUID identifies KubeVirt as the owner of the enrolled certificates
+	signatureOwnerGUID = mustParseGUID("a0baa8a3-041d-48a8-bc87-c36d121b5e3d")
+)
+
+type guid [16]byte
+
+// mustParseGUID encodes a GUID the way EFI stores it, with the first three fields in little endian
+func mustParseGUID(s string) guid {
+	raw, err := hex.DecodeString(strings.Replace(s, "-", "", -1))
+	if err != nil || len(raw) != 16 {
+		panic(fmt.Sprintf("invalid GUID %q", s))
+	}
+	var g guid
+	binary.LittleEndian.PutUint32(g[0:], binary.BigEndian.Uint32(raw[0:]))
+	binary.LittleEndian.PutUint16(g[4:], binary.BigEndian.Uint16(raw[4:]))
+	binary.LittleEndian.PutUint16(g[6:], binary.BigEndian.Uint16(raw[6:]))
+	copy(g[8:], raw[8:])
+	return g
+}
+
+type variable struct {
+	state          byte
+	attributes     uint32
+	monotonicCount uint64
+	timestamp      [16]byte
+	pubKeyIndex    uint32
+	vendor         guid
+	name           string
+	data           []byte
+}
+
+type variableStore struct {
+	// start and end of the variable area in the firmware volume
+	start, end int
+	variables  []variable
+}
+
+func alignVariable(offset int) int {
+	return (offset + variableHeaderAlignment - 1) &^ (variableHeaderAlignment - 1)
+}
+
+func parseVariableStore(volume []byte) (*variableStore, error) {
+	if len(volume) < firmwareVolumeMinHeaderLength ||
+		string(volume[firmwareVolumeSignatureOffset:firmwareVolumeSignatureOffset+4]) != firmwareVolumeSignature {
+		return nil, fmt.Errorf("not an EFI firmware volume")
+	}
+	if length := binary.LittleEndian.Uint64(volume[firmwareVolumeLengthOffset:]); length > uint64(len(volume)) {
+		return nil, fmt.Errorf("firmware volume length %d exceeds the file size %d", length, len(volume))
+	}
+
+	storeStart := int(binary.LittleEndian.Uint16(volume[firmwareVolumeHeaderLengthOffset:]))
+	if storeStart < firmwareVolumeMinHeaderLength || storeStart+variableStoreHeaderSize > len(volume) {
+		return nil, fmt.Errorf("invalid firmware volume header length %d", storeStart)
+	}
+	header := volume[storeStart : storeStart+variableStoreHeaderSize]
+	if !bytes.Equal(header[0:16], authenticatedVariableStoreGUID[:]) {
+		return nil, fmt.Errorf("the firmware volume does not hold an authenticated variable store")
+	}
+	if header[20] != variableStoreFormatted || header[21] != variableStoreHealthy {
+		return nil, fmt.Errorf("the variable store is not formatted or not healthy")
+	}
+	storeSize := int(binary.LittleEndian.Uint32(header[16:]))
+	if storeSize < variableStoreHeaderSize || storeStart+storeSize > len(volume) {
+		return nil, fmt.Errorf("invalid variable store size %d", storeSize)
+	}
+
+	store := &variableStore{
+		start: storeStart + variableStoreHeaderSize,
+		end:   storeStart + storeSize,
+	}
+	offset := store.start
+	for offset+variableHeaderSize <= store.end && binary.LittleEndian.Uint16(volume[offset:]) == variableStartID {
+		raw := volume[offset:]
+		v := variable{
+			state:          raw[2],
+			attributes:     binary.LittleEndian.Uint32(raw[4:]),
+			monotonicCount: binary.LittleEndian.Uint64(raw[8:]),
+			pubKeyIndex:    binary.LittleEndian.Uint32(raw[32:]),
+		}
+		copy(v.timestamp[:], raw[16:32])
+		copy(v.vendor[:], raw[44:60])
+		nameSize := int(binary.LittleEndian.Uint32(raw[36:]))
+		dataSize := int(binary.LittleEndian.Uint32(raw[40:]))
+
+		nameStart := offset + variableHeaderSize
+		dataStart := alignVariable(nameStart + nameSize)
+		if nameSize < 0 || dataSize < 0 || dataStart+dataSize > store.end {
+			return nil, fmt.Errorf("variable at offset %d exceeds the variable store", offset)
+		}
+		v.name = decodeVariableName(volume[nameStart : nameStart+nameSize])
+		v.data = append([]byte{}, volume[dataStart:dataStart+dataSize]...)
+		store.variables = append(store.variables, v)
+
+		offset = alignVariable(dataStart + dataSize)
+	}
+	return store, nil
+}
+
+// liveVariables returns the variables which are still valid, dropping the deleted ones
+func (s *variableStore) liveVariables() []variable {
+	added := map[string]bool{}
+	for _, v := range s.variables {
+		if v.state == variableAdded {
+			added[v.key()] = true
+		}
+	}
+	var live []variable
+	for _, v := range s.variables {
+		switch v.state {
+		case variableAdded:
+			live = append(live, v)
+		case variableAdded & variableInDeletedTransit:
+			if !added[v.key()] {
+				v.state = variableAdded
+				live = append(live, v)
+			}
+		}
+	}
+	return live
+}
+
+func (v *variable) key() string {
+	return string(v.vendor[:]) + v.name
+}
+
+func (v *variable) is(vendor guid, name string) bool {
+	return v.vendor == vendor && v.name == name
+}
+
+func (v *variable) encode() []byte {
+	name := encodeVariableName(v.name)
+	dataStart := alignVariable(variableHeaderSize + len(name))
+	raw := make([]byte, alignVariable(dataStart+len(v.data)))
+	for i := range raw {
+		raw[i] = 0xff
+	}
+	binary.LittleEndian.PutUint16(raw[0:], variableStartID)
+	raw[2] = v.state
+	raw[3] = 0
+	binary.LittleEndian.PutUint32(raw[4:], v.attributes)
+	binary.LittleEndian.PutUint64(raw[8:], v.monotonicCount)
+	copy(raw[16:32], v.timestamp[:])
+	binary.LittleEndian.PutUint32(raw[32:], v.pubKeyIndex)
+	binary.LittleEndian.PutUint32(raw[36:], uint32(len(name)))
+	binary.LittleEndian.PutUint32(raw[40:], uint32(len(v.data)))
+	copy(raw[44:60], v.vendor[:])
+	copy(raw[variableHeaderSize:], name)
+	copy(raw[dataStart:], v.data)
+	return raw
+}
+
+func encodeVariableName(name string) []byte {
+	chars := append(utf16.Encode([]rune(name)), 0)
+	raw := make([]byte, 2*len(chars))
+	for i, c := range chars {
+		binary.LittleEndian.PutUint16(raw[2*i:], c)
+	}
+	return raw
+}
+
+func decodeVariableName(raw []byte) string {
+	var chars []uint16
+	for i := 0; i+1 < len(raw); i += 2 {
+		c := binary.LittleEndian.Uint16(raw[i:])
+		if c == 0 {
+			break
+		}
+		chars = append(chars, c)
+	}
+	return string(utf16.Decode(chars))
+}
+
+// newSignatureList encodes the certificates as X.509 EFI_SIGNATURE_LISTs, one per certificate
+// since each list only holds signatures of the same size
+func newSignatureList(certificates ...[]byte) []byte {
+	var data []byte
+	for _, certificate := range certificates {
+		signatureSize := signatureOwnerSize + len(certificate)
+		list := make([]byte, signatureListHeaderSize+signatureSize)
+		copy(list[0:16], certX509GUID[:])
+		binary.LittleEndian.PutUint32(list[16:], uint32(len(list)))
+		binary.LittleEndian.PutUint32(list[20:], 0)
+		binary.LittleEndian.PutUint32(list[24:], uint32(signatureSize))
+		copy(list[signatureListHeaderSize:], signatureOwnerGUID[:])
+		copy(list[signatureListHeaderSize+signatureOwnerSize:], certificate)
+		data = append(data, list...)
+	}
+	return data
+}
+
+// EnrollCertificates returns a copy of the firmware volume holding the variable store, where the
+// PK, KEK and db variables are replaced with the given certificates. The other variables, like
+// the forbidden signature database, are kept; the deleted ones are reclaimed.
+func EnrollCertificates(volume []byte, certificates *Certificates) ([]byte, error) {
+	store, err := parseVariableStore(volume)
+	if err != nil {
+		return nil, err
+	}
+
+	enrolled := []variable{
+		newSecureBootVariable(globalVariableGUID, pkVariable, newSignatureList(certificates.PK.Raw)),
+		newSecureBootVariable(globalVariableGUID, kekVariable, newSignatureList(rawCertificates(certificates.KEK)...)),
+		newSecureBootVariable(imageSecurityDatabaseGUID, dbVariable, newSignatureList(rawCertificates(certificates.DB)...)),
+	}
+
+	var variables []variable
+	for _, v := range store.liveVariables() {
+		replaced := false
+		for _, e := range enrolled {
+			if v.is(e.vendor, e.name) {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			variables = append(variables, v)
+		}
+	}
+	variables = append(variables, enrolled...)
+
+	var area []byte
+	for _, v := range variables {
+		area = append(area, v.encode()...)
+	}
+	if len(area) > store.end-store.start {
+		return nil, fmt.Errorf("the certificates need %d bytes but the variable store only has %d", len(area), store.end-store.start)
+	}
+
+	enrolledVolume := append([]byte{}, volume...)
+	copy(enrolledVolume[store.start:], area)
+	for i := store.start + len(area); i < store.end; i++ {
+		enrolledVolume[i] = 0xff
+	}
+	return enrolledVolume, nil
+}
+
+func newSecureBootVariable(vendor guid, name string, data []byte) variable {
+	return variable{
+		state:      variableAdded,
+		attributes: secureBootVariableAttributes,
+		vendor:     vendor,
+		name:       name,
+		data:       data,
+	}
+}
+
+func rawCertificates(certificates []*x509.Certificate) [][]byte {
+	raw := make([][]byte, 0, len(certificates))
+	for _, certificate := range certificates {
+		raw = append(raw, certificate.Raw)
+	}
+	return raw
+}
+
+// CreateVarsTemplate writes to target a copy of the source variable store template where the
+// certificates of the secrets mounted on the pod are enrolled
+func CreateVarsTemplate(source, target string) error {
+	certificates, err := ReadCertificates()
+	if err != nil {
+		return err
+	}
+	volume, err := ioutil.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	enrolled, err := EnrollCertificates(volume, certificates)
+	if err != nil {
+		return fmt.Errorf("failed to enroll the Secure Boot certificates in %s: %v", source, err)
+	}
+	return ioutil.WriteFile(target, enrolled, 0644)
+}
@@ -0,0 +1,236 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package efi
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/config"
+)
+
+const (
+	testHeaderLength = 72
+	testStoreSize    = 4096
+)
+
+var vendorGUID = mustParseGUID("4c64b9cc-9d5a-4d24-a1a6-6bc0f3e9e3f5")
+
+// newTestVolume builds a firmware volume holding an authenticated variable store with the given variables
+func newTestVolume(storeSize int, variables ...variable) []byte {
+	volume := make([]byte, testHeaderLength+storeSize+1024)
+	for i := range volume {
+		volume[i] = 0xff
+	}
+	for i := 0; i < firmwareVolumeSignatureOffset; i++ {
+		volume[i] = 0
+	}
+	binary.LittleEndian.PutUint64(volume[firmwareVolumeLengthOffset:], uint64(len(volume)))
+	copy(volume[firmwareVolumeSignatureOffset:], firmwareVolumeSignature)
+	binary.LittleEndian.PutUint16(volume[firmwareVolumeHeaderLengthOffset:], testHeaderLength)
+
+	header := volume[testHeaderLength:]
+	copy(header[0:16], authenticatedVariableStoreGUID[:])
+	binary.LittleEndian.PutUint32(header[16:], uint32(storeSize))
+	header[20] = variableStoreFormatted
+	header[21] = variableStoreHealthy
+	for i := 22; i < variableStoreHeaderSize; i++ {
+		header[i] = 0
+	}
+
+	offset := testHeaderLength + variableStoreHeaderSize
+	for _, v := range variables {
+		offset += copy(volume[offset:], v.encode())
+	}
+	return volume
+}
+
+func findVariable(variables []variable, vendor guid, name string) *variable {
+	for i := range variables {
+		if variables[i].is(vendor, name) {
+			return &variables[i]
+		}
+	}
+	return nil
+}
+
+// parseSignatureLists returns the certificates of X.509 EFI_SIGNATURE_LISTs
+func parseSignatureLists(data []byte) [][]byte {
+	var certificates [][]byte
+	for len(data) > 0 {
+		Expect(len(data)).To(BeNumerically(">=", signatureListHeaderSize))
+		Expect(data[0:16]).To(Equal(certX509GUID[:]))
+		listSize := int(binary.LittleEndian.Uint32(data[16:]))
+		signatureSize := int(binary.LittleEndian.Uint32(data[24:]))
+		Expect(listSize).To(Equal(signatureListHeaderSize + signatureSize))
+		Expect(data[signatureListHeaderSize : signatureListHeaderSize+signatureOwnerSize]).To(Equal(signatureOwnerGUID[:]))
+		certificates = append(certificates, data[signatureListHeaderSize+signatureOwnerSize:listSize])
+		data = data[listSize:]
+	}
+	return certificates
+}
+
+var _ = Describe("Variable store", func() {
+
+	var certificates *Certificates
+	var volume []byte
+
+	BeforeEach(func() {
+		var err error
+		certificates, err = NewCertificates(&config.SecureBootCertificatesSecrets{
+			PK:  newTestCertificate("pk"),
+			KEK: encodePEM(pemCertificateType, newTestCertificate("kek")),
+			DB:  encodePEM(pemCertificateType, newTestCertificate("db"), newTestCertificate("db2")),
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		deletedBoot := newSecureBootVariable(vendorGUID, "Boot0001", []byte("old"))
+		deletedBoot.state = variableAdded & 0xfd
+		volume = newTestVolume(testStoreSize,
+			newSecureBootVariable(globalVariableGUID, pkVariable, newSignatureList([]byte("default-pk"))),
+			newSecureBootVariable(globalVariableGUID, kekVariable, newSignatureList([]byte("default-kek"))),
+			newSecureBootVariable(imageSecurityDatabaseGUID, dbVariable, newSignatureList([]byte("default-db"))),
+			newSecureBootVariable(imageSecurityDatabaseGUID, "dbx", []byte("forbidden")),
+			deletedBoot,
+			variable{state: variableAdded, attributes: 0x7, vendor: vendorGUID, name: "Boot0000", data: []byte("boot")},
+		)
+	})
+
+	It("should parse the variables of the store", func() {
+		store, err := parseVariableStore(volume)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(store.variables).To(HaveLen(6))
+		Expect(store.liveVariables()).To(HaveLen(5))
+		Expect(findVariable(store.liveVariables(), vendorGUID, "Boot0001")).To(BeNil())
+	})
+
+	It("should replace the certificate stores and keep the other variables", func() {
+		enrolled, err := EnrollCertificates(volume, certificates)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(enrolled).To(HaveLen(len(volume)))
+		Expect(enrolled[:testHeaderLength]).To(Equal(volume[:testHeaderLength]))
+		Expect(enrolled[testHeaderLength+testStoreSize:]).To(Equal(volume[testHeaderLength+testStoreSize:]))
+
+		store, err := parseVariableStore(enrolled)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(store.variables).To(HaveLen(5))
+
+		pk := findVariable(store.variables, globalVariableGUID, pkVariable)
+		Expect(pk).ToNot(BeNil())
+		Expect(pk.attributes).To(Equal(uint32(secureBootVariableAttributes)))
+		Expect(parseSignatureLists(pk.data)).To(Equal([][]byte{certificates.PK.Raw}))
+
+		kek := findVariable(store.variables, globalVariableGUID, kekVariable)
+		Expect(kek).ToNot(BeNil())
+		Expect(parseSignatureLists(kek.data)).To(Equal([][]byte{certificates.KEK[0].Raw}))
+
+		db := findVariable(store.variables, imageSecurityDatabaseGUID, dbVariable)
+		Expect(db).ToNot(BeNil())
+		Expect(parseSignatureLists(db.data)).To(Equal([][]byte{certificates.DB[0].Raw, certificates.DB[1].Raw}))
+
+		dbx := findVariable(store.variables, imageSecurityDatabaseGUID, "dbx")
+		Expect(dbx).ToNot(BeNil())
+		Expect(dbx.data).To(Equal([]byte("forbidden")))
+		boot := findVariable(store.variables, vendorGUID, "Boot0000")
+		Expect(boot).ToNot(BeNil())
+		Expect(boot.attributes).To(Equal(uint32(0x7)))
+		Expect(findVariable(store.variables, vendorGUID, "Boot0001")).To(BeNil())
+	})
+
+	It("should enroll the certificates in an empty store", func() {
+		enrolled, err := EnrollCertificates(newTestVolume(testStoreSize), certificates)
+		Expect(err).ToNot(HaveOccurred())
+
+		store, err := parseVariableStore(enrolled)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(store.variables).To(HaveLen(3))
+	})
+
+	It("should fail if the certificates do not fit in the store", func() {
+		_, err := EnrollCertificates(newTestVolume(512), certificates)
+		Expect(err).To(MatchError(ContainSubstring("variable store only has")))
+	})
+
+	It("should reject a volume which is not a firmware volume", func() {
+		_, err := EnrollCertificates(make([]byte, 4096), certificates)
+		Expect(err).To(MatchError(ContainSubstring("not an EFI firmware volume")))
+	})
+
+	It("should reject a variable store which is not authenticated", func() {
+		variableStoreGUID := mustParseGUID("ddcf3616-3275-4164-98b6-fe85707ffe7d")
+		copy(volume[testHeaderLength:], variableStoreGUID[:])
+		_, err := EnrollCertificates(volume, certificates)
+		Expect(err).To(MatchError(ContainSubstring("authenticated variable store")))
+	})
+
+	Context("with the certificates secrets mounted", func() {
+		var origSourceDir, tmpDir string
+
+		BeforeEach(func() {
+			var err error
+			origSourceDir = config.SecureBootCertificatesSourceDir
+			tmpDir, err = ioutil.TempDir("", "efi")
+			Expect(err).ToNot(HaveOccurred())
+			config.SecureBootCertificatesSourceDir = filepath.Join(tmpDir, "secrets")
+
+			for store, data := range map[string][]byte{
+				config.SecureBootPKStore:  encodePEM(pemCertificateType, certificates.PK.Raw),
+				config.SecureBootKEKStore: encodePEM(pemCertificateType, certificates.KEK[0].Raw),
+				config.SecureBootDBStore:  encodePEM(pemCertificateType, certificates.DB[0].Raw),
+			} {
+				Expect(os.MkdirAll(config.GetSecureBootCertificatesSourcePath(store), 0755)).To(Succeed())
+				Expect(ioutil.WriteFile(filepath.Join(config.GetSecureBootCertificatesSourcePath(store), config.SecureBootCertificatesKey), data, 0644)).To(Succeed())
+			}
+			Expect(ioutil.WriteFile(filepath.Join(tmpDir, "OVMF_VARS.secboot.fd"), volume, 0644)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			config.SecureBootCertificatesSourceDir = origSourceDir
+			os.RemoveAll(tmpDir)
+		})
+
+		It("should create a variable store template with the certificates enrolled", func() {
+			target := filepath.Join(tmpDir, "template.fd")
+			Expect(CreateVarsTemplate(filepath.Join(tmpDir, "OVMF_VARS.secboot.fd"), target)).To(Succeed())
+
+			enrolled, err := ioutil.ReadFile(target)
+			Expect(err).ToNot(HaveOccurred())
+			store, err := parseVariableStore(enrolled)
+			Expect(err).ToNot(HaveOccurred())
+			db := findVariable(store.variables, imageSecurityDatabaseGUID, dbVariable)
+			Expect(db).ToNot(BeNil())
+			Expect(parseSignatureLists(db.data)).To(Equal([][]byte{certificates.DB[0].Raw}))
+		})
+
+		It("should fail if a certificate store is incomplete", func() {
+			Expect(os.RemoveAll(config.GetSecureBootCertificatesSourcePath(config.SecureBootDBStore))).To(Succeed())
+			target := filepath.Join(tmpDir, "template.fd")
+
+			Expect(CreateVarsTemplate(filepath.Join(tmpDir, "OVMF_VARS.secboot.fd"), target)).ToNot(Succeed())
+			_, err := os.Stat(target)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+})
@@ -47,6 +47,12 @@ func IsInvalidOperation(err error) bool {
 	return checkError(err, libvirt.ERR_OPERATION_INVALID)
 }
 
+// IsAgentUnresponsive detects libvirt's VIR_ERR_AGENT_UNRESPONSIVE, returned when the guest agent is not connected or
+// does not answer. It accepts both error and libvirt.Error (as returned by GetLastError function).
+func IsAgentUnresponsive(err error) bool {
+	return checkError(err, libvirt.ERR_AGENT_UNRESPONSIVE)
+}
+
 // IsOk detects libvirt's ERR_OK. It accepts both error and libvirt.Error (as returned by GetLastError function).
 func IsOk(err error) bool {
 	return checkError(err, libvirt.ERR_OK)
@@ -0,0 +1,166 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package freezehooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/client-go/log"
+)
+
+const (
+	defaultTimeoutSeconds = 10
+
+	freezeCommand = `{"execute":"guest-fsfreeze-freeze"}`
+	thawCommand   = `{"execute":"guest-fsfreeze-thaw"}`
+)
+
+var execStatusPollInterval = 100 * time.Millisecond
+
+// AgentCommandExecutor runs a guest agent command and returns its JSON reply.
+type AgentCommandExecutor func(command string) (string, error)
+
+type execReturn struct {
+	Return execReturnData `json:"return"`
+}
+type execReturnData struct {
+	Pid int `json:"pid"`
+}
+
+type execStatusReturn struct {
+	Return execStatusReturnData `json:"return"`
+}
+type execStatusReturnData struct {
+	Exited   bool `json:"exited"`
+	ExitCode int  `json:"exitcode"`
+}
+
+// Freeze runs the pre-freeze hook and freezes the guest filesystems. A failed
+// hook fails the freeze before the filesystems are touched, unless its failure
+// policy is Continue. postThawPending is set once the guest got past the
+// pre-freeze hook, also if the freeze itself failed: the post-thaw hook has to
+// run after the next thaw to undo what the pre-freeze hook did.
+func Freeze(execAgentCommand AgentCommandExecutor, hooks *v1.FreezeHooks) (postThawPending bool, err error) {
+	if hooks != nil && hooks.PreFreeze != nil {
+		if err := runHook(execAgentCommand, "pre-freeze", hooks.PreFreeze); err != nil {
+			return false, err
+		}
+	}
+	if _, err := execAgentCommand(freezeCommand); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// Thaw thaws the guest filesystems and, if runPostThaw is set, runs the
+// post-thaw hook afterwards. The hook is not run if the thaw failed, thawed
+// tells whether the filesystems were thawed when an error is returned.
+func Thaw(execAgentCommand AgentCommandExecutor, hooks *v1.FreezeHooks, runPostThaw bool) (thawed bool, err error) {
+	if _, err := execAgentCommand(thawCommand); err != nil {
+		return false, err
+	}
+	if runPostThaw && hooks != nil && hooks.PostThaw != nil {
+		return true, runHook(execAgentCommand, "post-thaw", hooks.PostThaw)
+	}
+	return true, nil
+}
+
+// runHook executes hook in the guest and applies its failure policy to the
+// outcome.
+func runHook(execAgentCommand AgentCommandExecutor, name string, hook *v1.FreezeHook) error {
+	timeout := time.Duration(hook.TimeoutSeconds) * time.Second
+	if hook.TimeoutSeconds <= 0 {
+		timeout = defaultTimeoutSeconds * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := exec(ctx, execAgentCommand, hook.Command)
+	if err == context.DeadlineExceeded {
+		err = fmt.Errorf("command %v timed out after %v", hook.Command, timeout)
+	}
+	if err == nil {
+		log.Log.Infof("The %s hook %v succeeded", name, hook.Command)
+		return nil
+	}
+	if hook.FailurePolicy == v1.FreezeHookFailurePolicyContinue {
+		log.Log.Reason(err).Warningf("The %s hook failed, continuing", name)
+		return nil
+	}
+	return fmt.Errorf("the %s hook failed: %v", name, err)
+}
+
+// exec runs command in the guest and waits for it to exit with 0. The guest
+// agent can't kill the command, a timed out command keeps running in the
+// guest.
+func exec(ctx context.Context, execAgentCommand AgentCommandExecutor, command []string) error {
+	if len(command) == 0 {
+		return fmt.Errorf("the hook has no command")
+	}
+	cmdExec, err := json.Marshal(map[string]interface{}{
+		"execute": "guest-exec",
+		"arguments": map[string]interface{}{
+			"path": command[0],
+			"arg":  command[1:],
+		},
+	})
+	if err != nil {
+		return err
+	}
+	output, err := execAgentCommand(string(cmdExec))
+	if err != nil {
+		return err
+	}
+	execRes := &execReturn{}
+	if err := json.Unmarshal([]byte(output), execRes); err != nil {
+		return err
+	}
+	if execRes.Return.Pid <= 0 {
+		return fmt.Errorf("invalid pid %d returned by the guest agent for %v: %s", execRes.Return.Pid, command, output)
+	}
+
+	cmdExecStatus := fmt.Sprintf(`{"execute":"guest-exec-status","arguments":{"pid":%d}}`, execRes.Return.Pid)
+	for {
+		output, err := execAgentCommand(cmdExecStatus)
+		if err != nil {
+			return err
+		}
+		execStatusRes := &execStatusReturn{}
+		if err := json.Unmarshal([]byte(output), execStatusRes); err != nil {
+			return err
+		}
+		if execStatusRes.Return.Exited {
+			if execStatusRes.Return.ExitCode == 0 {
+				return nil
+			}
+			return fmt.Errorf("command %v exited with %d", command, execStatusRes.Return.ExitCode)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(execStatusPollInterval):
+		}
+	}
+}
@@ -0,0 +1,189 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package freezehooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v1 "kubevirt.io/client-go/api/v1"
+)
+
+var _ = Describe("Freeze hooks", func() {
+	const (
+		preFreezePid = 10
+		postThawPid  = 20
+	)
+
+	var agentCommands []string
+	// exitCodes are the exit codes of the hooks by pid, hooks without one never exit
+	var exitCodes map[int]int
+	var failingCommands map[string]bool
+
+	// sentCommands shortens the guest-exec commands to the hook path
+	sentCommands := func() []string {
+		var commands []string
+		for _, command := range agentCommands {
+			if strings.Contains(command, `"guest-exec"`) {
+				request := struct {
+					Arguments struct {
+						Path string `json:"path"`
+					} `json:"arguments"`
+				}{}
+				Expect(json.Unmarshal([]byte(command), &request)).To(Succeed())
+				command = "exec " + request.Arguments.Path
+			}
+			commands = append(commands, command)
+		}
+		return commands
+	}
+
+	execAgentCommand := func(command string) (string, error) {
+		agentCommands = append(agentCommands, command)
+		switch {
+		case failingCommands[command]:
+			return "", fmt.Errorf("the guest agent failed to run %s", command)
+		case strings.Contains(command, `"guest-exec"`) && strings.Contains(command, "/usr/bin/pre-freeze"):
+			return fmt.Sprintf(`{"return":{"pid":%d}}`, preFreezePid), nil
+		case strings.Contains(command, `"guest-exec"`) && strings.Contains(command, "/usr/bin/post-thaw"):
+			return fmt.Sprintf(`{"return":{"pid":%d}}`, postThawPid), nil
+		case strings.Contains(command, `"guest-exec-status"`):
+			for pid, exitCode := range exitCodes {
+				if strings.Contains(command, fmt.Sprintf(`"pid":%d`, pid)) {
+					return fmt.Sprintf(`{"return":{"exited":true,"exitcode":%d}}`, exitCode), nil
+				}
+			}
+			return `{"return":{"exited":false}}`, nil
+		}
+		return `{"return":0}`, nil
+	}
+
+	hooks := func(failurePolicy v1.FreezeHookFailurePolicy) *v1.FreezeHooks {
+		return &v1.FreezeHooks{
+			PreFreeze: &v1.FreezeHook{Command: []string{"/usr/bin/pre-freeze", "--flush"}, TimeoutSeconds: 1, FailurePolicy: failurePolicy},
+			PostThaw:  &v1.FreezeHook{Command: []string{"/usr/bin/post-thaw"}, TimeoutSeconds: 1, FailurePolicy: failurePolicy},
+		}
+	}
+
+	BeforeEach(func() {
+		agentCommands = nil
+		exitCodes = map[int]int{preFreezePid: 0, postThawPid: 0}
+		failingCommands = map[string]bool{}
+		execStatusPollInterval = 10 * time.Millisecond
+	})
+
+	AfterEach(func() {
+		execStatusPollInterval = 100 * time.Millisecond
+	})
+
+	It("should run the pre-freeze hook before the freeze and the post-thaw hook after the thaw", func() {
+		postThawPending, err := Freeze(execAgentCommand, hooks(""))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(postThawPending).To(BeTrue())
+		thawed, err := Thaw(execAgentCommand, hooks(""), postThawPending)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(thawed).To(BeTrue())
+		Expect(sentCommands()).To(Equal([]string{
+			"exec /usr/bin/pre-freeze",
+			`{"execute":"guest-exec-status","arguments":{"pid":10}}`,
+			freezeCommand,
+			thawCommand,
+			"exec /usr/bin/post-thaw",
+			`{"execute":"guest-exec-status","arguments":{"pid":20}}`,
+		}))
+	})
+
+	It("should only freeze and thaw without hooks", func() {
+		postThawPending, err := Freeze(execAgentCommand, nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = Thaw(execAgentCommand, nil, postThawPending)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sentCommands()).To(Equal([]string{freezeCommand, thawCommand}))
+	})
+
+	It("should pass the arguments of the hook to the guest agent", func() {
+		_, err := Freeze(execAgentCommand, hooks(""))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(agentCommands[0]).To(MatchJSON(`{"execute":"guest-exec","arguments":{"path":"/usr/bin/pre-freeze","arg":["--flush"]}}`))
+	})
+
+	It("should not freeze if the pre-freeze hook fails with the Abort policy", func() {
+		exitCodes[preFreezePid] = 1
+		postThawPending, err := Freeze(execAgentCommand, hooks(v1.FreezeHookFailurePolicyAbort))
+		Expect(err).To(MatchError("the pre-freeze hook failed: command [/usr/bin/pre-freeze --flush] exited with 1"))
+		Expect(postThawPending).To(BeFalse())
+		Expect(sentCommands()).ToNot(ContainElement(freezeCommand))
+	})
+
+	It("should not freeze if the pre-freeze hook times out", func() {
+		delete(exitCodes, preFreezePid)
+		_, err := Freeze(execAgentCommand, hooks(""))
+		Expect(err).To(MatchError("the pre-freeze hook failed: command [/usr/bin/pre-freeze --flush] timed out after 1s"))
+		Expect(sentCommands()).ToNot(ContainElement(freezeCommand))
+	})
+
+	It("should freeze anyway if the pre-freeze hook fails with the Continue policy", func() {
+		failingCommands[`{"execute":"guest-exec-status","arguments":{"pid":10}}`] = true
+		postThawPending, err := Freeze(execAgentCommand, hooks(v1.FreezeHookFailurePolicyContinue))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(postThawPending).To(BeTrue())
+		Expect(sentCommands()).To(ContainElement(freezeCommand))
+	})
+
+	It("should keep the post-thaw hook pending if the freeze fails after the pre-freeze hook", func() {
+		failingCommands[freezeCommand] = true
+		postThawPending, err := Freeze(execAgentCommand, hooks(""))
+		Expect(err).To(HaveOccurred())
+		Expect(postThawPending).To(BeTrue())
+	})
+
+	It("should fail the thaw if the post-thaw hook fails with the Abort policy", func() {
+		exitCodes[postThawPid] = 2
+		thawed, err := Thaw(execAgentCommand, hooks(""), true)
+		Expect(err).To(MatchError("the post-thaw hook failed: command [/usr/bin/post-thaw] exited with 2"))
+		Expect(thawed).To(BeTrue())
+	})
+
+	It("should ignore the failure of the post-thaw hook with the Continue policy", func() {
+		exitCodes[postThawPid] = 2
+		thawed, err := Thaw(execAgentCommand, hooks(v1.FreezeHookFailurePolicyContinue), true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(thawed).To(BeTrue())
+	})
+
+	It("should not run the post-thaw hook if the thaw fails", func() {
+		failingCommands[thawCommand] = true
+		thawed, err := Thaw(execAgentCommand, hooks(""), true)
+		Expect(err).To(HaveOccurred())
+		Expect(thawed).To(BeFalse())
+		Expect(sentCommands()).To(Equal([]string{thawCommand}))
+	})
+
+	It("should not run the post-thaw hook if it is not pending", func() {
+		_, err := Thaw(execAgentCommand, hooks(""), false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sentCommands()).To(Equal([]string{thawCommand}))
+	})
+})
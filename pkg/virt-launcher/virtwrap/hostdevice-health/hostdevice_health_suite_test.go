@@ -0,0 +1,13 @@
+package hostdevicehealth_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestHostDeviceHealth(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "HostDeviceHealth Suite")
+}
@@ -0,0 +1,258 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package hostdevicehealth
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"kubevirt.io/client-go/log"
+	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/api"
+)
+
+const (
+	sysfsRoot = "/sys"
+	vfioPCI   = "vfio-pci"
+
+	// invalidVendorID is read from the config space of a PCI function which
+	// stopped answering, e.g. after a failed function level reset
+	invalidVendorID = 0xffff
+)
+
+// Device is a host device assigned to the domain. Mediated devices only have
+// a UUID, PCI devices only a PCI address.
+type Device struct {
+	Name       string
+	PCIAddress string
+	MDEVUUID   string
+}
+
+// address returns the address the device is known as on the host.
+func (d Device) address() string {
+	if d.MDEVUUID != "" {
+		return d.MDEVUUID
+	}
+	return d.PCIAddress
+}
+
+// Monitor periodically checks the health of the host devices of a domain in
+// sysfs. The checks only read a few small attributes per device, so that they
+// stay cheap even on short intervals.
+type Monitor struct {
+	sysfsRoot   string
+	interval    time.Duration
+	listDevices func() ([]Device, error)
+	report      func(health []api.HostDeviceHealth)
+
+	// fatalAERBaselines are the fatal AER errors of the PCI devices at their
+	// first check, errors reported before the domain started are not counted
+	fatalAERBaselines map[string]uint64
+}
+
+// NewMonitor returns a monitor checking the devices returned by listDevices
+// every interval. listDevices is called until it returned the devices once,
+// report is called on every change of their health.
+func NewMonitor(interval time.Duration, listDevices func() ([]Device, error), report func(health []api.HostDeviceHealth)) *Monitor {
+	return &Monitor{
+		sysfsRoot:         sysfsRoot,
+		interval:          interval,
+		listDevices:       listDevices,
+		report:            report,
+		fatalAERBaselines: map[string]uint64{},
+	}
+}
+
+// Run checks the devices every interval until stop is closed.
+func (m *Monitor) Run(stop <-chan struct{}) {
+	var devices []Device
+	var last []api.HostDeviceHealth
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		if devices == nil {
+			var err error
+			if devices, err = m.listDevices(); err != nil {
+				log.Log.Reason(err).V(3).Info("Failed to list the host devices to check")
+				devices = nil
+			}
+		}
+		if devices != nil {
+			health := m.check(devices)
+			if last == nil || !reflect.DeepEqual(last, health) {
+				last = health
+				m.report(health)
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Monitor) check(devices []Device) []api.HostDeviceHealth {
+	health := make([]api.HostDeviceHealth, 0, len(devices))
+	for _, device := range devices {
+		var err error
+		if device.MDEVUUID != "" {
+			err = m.checkMDEV(device.MDEVUUID)
+		} else {
+			err = m.checkPCI(device.PCIAddress)
+		}
+		deviceHealth := api.HostDeviceHealth{Name: device.Name, Address: device.address(), Healthy: err == nil}
+		if err != nil {
+			deviceHealth.Message = err.Error()
+		}
+		health = append(health, deviceHealth)
+	}
+	return health
+}
+
+func (m *Monitor) checkMDEV(uuid string) error {
+	if _, err := os.Stat(filepath.Join(m.sysfsRoot, "bus", "mdev", "devices", uuid)); err != nil {
+		return fmt.Errorf("the mediated device is gone from the host: %v", err)
+	}
+	return nil
+}
+
+func (m *Monitor) checkPCI(address string) error {
+	devicePath := filepath.Join(m.sysfsRoot, "bus", "pci", "devices", address)
+	if _, err := os.Stat(devicePath); err != nil {
+		return fmt.Errorf("the device is gone from the host: %v", err)
+	}
+
+	driver, err := os.Readlink(filepath.Join(devicePath, "driver"))
+	if err != nil {
+		return fmt.Errorf("the device is not bound to any driver")
+	}
+	if driver = filepath.Base(driver); driver != vfioPCI {
+		return fmt.Errorf("the device is bound to %s instead of %s", driver, vfioPCI)
+	}
+
+	config, err := readHead(filepath.Join(devicePath, "config"), 2)
+	if err != nil {
+		return fmt.Errorf("failed to read the PCI config space of the device: %v", err)
+	}
+	if len(config) < 2 || uint16(config[0])|uint16(config[1])<<8 == invalidVendorID {
+		return fmt.Errorf("the device does not answer on its PCI config space, e.g. after a failed function level reset")
+	}
+
+	// virtual functions and conventional PCI devices have no link of their own
+	if width, err := ioutil.ReadFile(filepath.Join(devicePath, "current_link_width")); err == nil && strings.TrimSpace(string(width)) == "0" {
+		return fmt.Errorf("the PCIe link of the device is down")
+	}
+
+	if fatal, ok := readAERCounter(filepath.Join(devicePath, "aer_dev_fatal"), "TOTAL_ERR_FATAL"); ok {
+		baseline, exists := m.fatalAERBaselines[address]
+		if !exists {
+			m.fatalAERBaselines[address] = fatal
+		} else if fatal > baseline {
+			return fmt.Errorf("the device reported %d fatal AER errors", fatal-baseline)
+		}
+	}
+	return nil
+}
+
+func readHead(path string, n int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	head := make([]byte, n)
+	read, err := f.Read(head)
+	if err != nil {
+		return nil, err
+	}
+	return head[:read], nil
+}
+
+// readAERCounter returns the value of counter in an AER statistics file, made
+// of "<counter> <value>" lines.
+func readAERCounter(path string, counter string) (uint64, bool) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != counter {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return value, true
+	}
+	return 0, false
+}
+
+// DevicesFromDomainSpec returns the host devices assigned to the domain.
+func DevicesFromDomainSpec(spec *api.DomainSpec) []Device {
+	devices := []Device{}
+	for _, hostDevice := range spec.Devices.HostDevices {
+		address := hostDevice.Source.Address
+		if address == nil {
+			continue
+		}
+		device := Device{}
+		if hostDevice.Alias != nil {
+			device.Name = hostDevice.Alias.GetName()
+		}
+		switch hostDevice.Type {
+		case "mdev":
+			device.MDEVUUID = address.UUID
+		case "pci":
+			pciAddress, err := formatPCIAddress(address)
+			if err != nil {
+				log.Log.Reason(err).Warningf("Not checking the health of host device %s", device.Name)
+				continue
+			}
+			device.PCIAddress = pciAddress
+		default:
+			continue
+		}
+		devices = append(devices, device)
+	}
+	return devices
+}
+
+// formatPCIAddress formats the hex fields of a libvirt PCI address the way
+// sysfs names the device, e.g. 0000:81:00.1.
+func formatPCIAddress(address *api.Address) (string, error) {
+	var fields [4]uint64
+	for i, field := range []string{address.Domain, address.Bus, address.Slot, address.Function} {
+		value, err := strconv.ParseUint(field, 0, 16)
+		if err != nil {
+			return "", fmt.Errorf("invalid PCI address %+v: %v", *address, err)
+		}
+		fields[i] = value
+	}
+	return fmt.Sprintf("%04x:%02x:%02x.%x", fields[0], fields[1], fields[2], fields[3]), nil
+}
@@ -0,0 +1,208 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package hostdevicehealth
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/api"
+)
+
+const (
+	testPCIAddress = "0000:81:00.1"
+	testMDEVUUID   = "c4ab3e5a-3e4d-4bd0-9a2b-1c7a29d9f7e8"
+)
+
+var _ = Describe("Host device health monitor", func() {
+
+	var sysfs string
+	var devicePath string
+	var stop chan struct{}
+	var reports chan []api.HostDeviceHealth
+
+	writeFile := func(path string, content []byte) {
+		Expect(ioutil.WriteFile(path, content, 0644)).To(Succeed())
+	}
+
+	bindDriver := func(driver string) {
+		driverPath := filepath.Join(sysfs, "bus", "pci", "drivers", driver)
+		Expect(os.MkdirAll(driverPath, 0755)).To(Succeed())
+		os.Remove(filepath.Join(devicePath, "driver"))
+		Expect(os.Symlink(driverPath, filepath.Join(devicePath, "driver"))).To(Succeed())
+	}
+
+	aerFatal := func(count int) []byte {
+		return []byte(fmt.Sprintf("Undefined 0\nDLP %d\nSDES 0\nTOTAL_ERR_FATAL %d\n", count, count))
+	}
+
+	startMonitor := func(devices ...Device) {
+		// a monitor still finishing its last check must not report to the next test
+		reports := reports
+		monitor := NewMonitor(10*time.Millisecond, func() ([]Device, error) {
+			return devices, nil
+		}, func(health []api.HostDeviceHealth) {
+			reports <- health
+		})
+		monitor.sysfsRoot = sysfs
+		go monitor.Run(stop)
+	}
+
+	BeforeEach(func() {
+		var err error
+		sysfs, err = ioutil.TempDir("", "kubevirt-sysfs")
+		Expect(err).ToNot(HaveOccurred())
+		devicePath = filepath.Join(sysfs, "bus", "pci", "devices", testPCIAddress)
+		Expect(os.MkdirAll(devicePath, 0755)).To(Succeed())
+		bindDriver(vfioPCI)
+		// vendor 0x15b3, device 0x1018
+		writeFile(filepath.Join(devicePath, "config"), []byte{0xb3, 0x15, 0x18, 0x10})
+		writeFile(filepath.Join(devicePath, "current_link_width"), []byte("16\n"))
+		writeFile(filepath.Join(devicePath, "aer_dev_fatal"), aerFatal(1))
+		Expect(os.MkdirAll(filepath.Join(sysfs, "bus", "mdev", "devices", testMDEVUUID), 0755)).To(Succeed())
+
+		stop = make(chan struct{})
+		reports = make(chan []api.HostDeviceHealth, 100)
+	})
+
+	AfterEach(func() {
+		close(stop)
+		os.RemoveAll(sysfs)
+	})
+
+	table.DescribeTable("should report a device turning unhealthy", func(degrade func(), expectedMessage string) {
+		startMonitor(Device{Name: "nic", PCIAddress: testPCIAddress})
+		Eventually(reports).Should(Receive(Equal([]api.HostDeviceHealth{
+			{Name: "nic", Address: testPCIAddress, Healthy: true},
+		})))
+
+		degrade()
+		var health []api.HostDeviceHealth
+		Eventually(reports).Should(Receive(&health))
+		Expect(health).To(HaveLen(1))
+		Expect(health[0].Healthy).To(BeFalse())
+		Expect(health[0].Message).To(ContainSubstring(expectedMessage))
+	},
+		table.Entry("once it is gone", func() {
+			Expect(os.RemoveAll(devicePath)).To(Succeed())
+		}, "the device is gone from the host"),
+		table.Entry("once it is bound to another driver", func() {
+			bindDriver("mlx5_core")
+		}, "the device is bound to mlx5_core instead of vfio-pci"),
+		table.Entry("once it is not bound to any driver", func() {
+			Expect(os.Remove(filepath.Join(devicePath, "driver"))).To(Succeed())
+		}, "the device is not bound to any driver"),
+		table.Entry("once its config space does not answer", func() {
+			writeFile(filepath.Join(devicePath, "config"), []byte{0xff, 0xff, 0xff, 0xff})
+		}, "the device does not answer on its PCI config space"),
+		table.Entry("once its link is down", func() {
+			writeFile(filepath.Join(devicePath, "current_link_width"), []byte("0\n"))
+		}, "the PCIe link of the device is down"),
+		table.Entry("once it reported fatal AER errors", func() {
+			writeFile(filepath.Join(devicePath, "aer_dev_fatal"), aerFatal(3))
+		}, "the device reported 2 fatal AER errors"),
+	)
+
+	It("should report a mediated device once it is gone", func() {
+		startMonitor(Device{Name: "gpu", MDEVUUID: testMDEVUUID})
+		Eventually(reports).Should(Receive(Equal([]api.HostDeviceHealth{
+			{Name: "gpu", Address: testMDEVUUID, Healthy: true},
+		})))
+
+		Expect(os.RemoveAll(filepath.Join(sysfs, "bus", "mdev", "devices", testMDEVUUID))).To(Succeed())
+		var health []api.HostDeviceHealth
+		Eventually(reports).Should(Receive(&health))
+		Expect(health[0].Healthy).To(BeFalse())
+		Expect(health[0].Message).To(ContainSubstring("the mediated device is gone from the host"))
+	})
+
+	It("should report a device turning healthy again", func() {
+		startMonitor(Device{Name: "nic", PCIAddress: testPCIAddress})
+		Eventually(reports).Should(Receive())
+		var health []api.HostDeviceHealth
+		writeFile(filepath.Join(devicePath, "current_link_width"), []byte("0\n"))
+		Eventually(reports).Should(Receive(&health))
+		Expect(health[0].Healthy).To(BeFalse())
+
+		writeFile(filepath.Join(devicePath, "current_link_width"), []byte("16\n"))
+		Eventually(reports).Should(Receive(&health))
+		Expect(health[0].Healthy).To(BeTrue())
+	})
+
+	It("should only report changes of the health", func() {
+		startMonitor(Device{Name: "nic", PCIAddress: testPCIAddress})
+		Eventually(reports).Should(Receive())
+		Consistently(reports, 100*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("should list the devices again until it succeeded", func() {
+		listings := 0
+		reports := reports
+		monitor := NewMonitor(10*time.Millisecond, func() ([]Device, error) {
+			listings++
+			if listings < 3 {
+				return nil, fmt.Errorf("the domain does not exist yet")
+			}
+			return []Device{{Name: "nic", PCIAddress: testPCIAddress}}, nil
+		}, func(health []api.HostDeviceHealth) {
+			reports <- health
+		})
+		monitor.sysfsRoot = sysfs
+		go monitor.Run(stop)
+
+		Eventually(reports).Should(Receive(HaveLen(1)))
+		Consistently(reports, 100*time.Millisecond).ShouldNot(Receive())
+	})
+})
+
+var _ = Describe("Host devices of the domain", func() {
+
+	It("should list the PCI and mediated devices with their host addresses", func() {
+		spec := &api.DomainSpec{}
+		spec.Devices.HostDevices = []api.HostDevice{
+			{
+				Type:   "pci",
+				Source: api.HostDeviceSource{Address: &api.Address{Type: "pci", Domain: "0x0000", Bus: "0x81", Slot: "0x00", Function: "0x1"}},
+				Alias:  api.NewUserDefinedAlias("nic"),
+			},
+			{
+				Type:   "mdev",
+				Source: api.HostDeviceSource{Address: &api.Address{UUID: testMDEVUUID}},
+				Alias:  api.NewUserDefinedAlias("gpu"),
+			},
+			{
+				Type:   "pci",
+				Source: api.HostDeviceSource{Address: &api.Address{Type: "pci", Domain: "0x0000", Bus: "invalid", Slot: "0x00", Function: "0x1"}},
+				Alias:  api.NewUserDefinedAlias("broken"),
+			},
+		}
+		Expect(DevicesFromDomainSpec(spec)).To(Equal([]Device{
+			{Name: "nic", PCIAddress: testPCIAddress},
+			{Name: "gpu", MDEVUUID: testMDEVUUID},
+		}))
+	})
+})
@@ -42,6 +42,7 @@ import (
 	eventsclient "kubevirt.io/kubevirt/pkg/virt-launcher/notify-client"
 	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/converter"
 
+	"golang.org/x/sys/unix"
 	k8sv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -61,14 +62,19 @@ import (
 	hostdisk "kubevirt.io/kubevirt/pkg/host-disk"
 	"kubevirt.io/kubevirt/pkg/ignition"
 	kutil "kubevirt.io/kubevirt/pkg/util"
+	"kubevirt.io/kubevirt/pkg/util/hardware"
 	"kubevirt.io/kubevirt/pkg/util/net/ip"
+	kubevirttypes "kubevirt.io/kubevirt/pkg/util/types"
 	migrationproxy "kubevirt.io/kubevirt/pkg/virt-handler/migration-proxy"
 	accesscredentials "kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/access-credentials"
 	agentpoller "kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/agent-poller"
 	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/api"
+	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/balloon"
 	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/cli"
 	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/device/sriov"
+	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/efi"
 	domainerrors "kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/errors"
+	freezehooks "kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/freeze-hooks"
 	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/network"
 	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/stats"
 	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/util"
@@ -80,8 +86,35 @@ const (
 	vgpuEnvPrefix              = "VGPU_PASSTHROUGH_DEVICES"
 	PCI_RESOURCE_PREFIX        = "PCI_RESOURCE"
 	MDEV_RESOURCE_PREFIX       = "MDEV_PCI_RESOURCE"
+
+	// a warm migration is kept from converging by allowing as little
+	// downtime as possible until its cutover gets committed
+	warmupMaxDowntimeMilliseconds = 1
+	warmupReportIntervalSeconds   = 5
 )
 
+// maxGuestFreezeDuration bounds how long the guest filesystems stay frozen after a
+// freeze which nobody thaws, a frozen guest can't write to its disks.
+var maxGuestFreezeDuration = 5 * time.Minute
+
+// agentConnectPollInterval is how often the guest agent channel of a domain is
+// checked while waiting for the guest agent to connect.
+var agentConnectPollInterval = time.Second
+
+// memoryDumpReportInterval is how often the progress of a running memory dump is
+// recorded in the domain metadata.
+var memoryDumpReportInterval = 5 * time.Second
+
+// availableBytes returns the space available to unprivileged users on the
+// filesystem holding path.
+var availableBytes = func(path string) (int64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * stat.Bsize, nil
+}
+
 type contextStore struct {
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -91,6 +124,10 @@ type DomainManager interface {
 	SyncVMI(*v1.VirtualMachineInstance, bool, *cmdv1.VirtualMachineOptions) (*api.DomainSpec, error)
 	PauseVMI(*v1.VirtualMachineInstance) error
 	UnpauseVMI(*v1.VirtualMachineInstance) error
+	SoftRebootVMI(*v1.VirtualMachineInstance) error
+	FreezeVMI(*v1.VirtualMachineInstance) error
+	UnfreezeVMI(*v1.VirtualMachineInstance) error
+	MemoryDumpVMI(vmi *v1.VirtualMachineInstance, dumpPath string) error
 	KillVMI(*v1.VirtualMachineInstance) error
 	DeleteVMI(*v1.VirtualMachineInstance) error
 	SignalShutdownVMI(*v1.VirtualMachineInstance) error
@@ -114,7 +151,8 @@ type LibvirtDomainManager struct {
 	// mutex to control access to the guest time context
 	setGuestTimeLock sync.Mutex
 
-	credManager *accesscredentials.AccessCredentialManager
+	credManager    *accesscredentials.AccessCredentialManager
+	balloonManager *balloon.BalloonManager
 
 	virtShareDir           string
 	notifier               *eventsclient.Notifier
@@ -124,6 +162,14 @@ type LibvirtDomainManager struct {
 	cloudInitDataStore     *cloudinit.CloudInitData
 	setGuestTimeContextPtr *contextStore
 	ovmfPath               string
+
+	// serializes freezes and thaws of the guest filesystems
+	freezeLock sync.Mutex
+	// thaws the guest filesystems if nobody does it in time after a freeze
+	autoThawTimer *time.Timer
+	// the guest got past the pre-freeze hook, the post-thaw hook has to run
+	// after the next thaw
+	postThawPending bool
 }
 
 type migrationDisks struct {
@@ -172,6 +218,7 @@ func NewLibvirtDomainManager(connection cli.Connection, virtShareDir string, not
 		ovmfPath:  ovmfPath,
 	}
 	manager.credManager = accesscredentials.NewManager(connection, &manager.domainModifyLock)
+	manager.balloonManager = balloon.NewManager(connection)
 
 	return &manager, nil
 }
@@ -196,7 +243,17 @@ func (l *LibvirtDomainManager) initializeMigrationMetadata(vmi *v1.VirtualMachin
 	migrationMetadata := domainSpec.Metadata.KubeVirt.Migration
 	if migrationMetadata != nil && migrationMetadata.UID == vmi.Status.MigrationState.MigrationUID {
 		if migrationMetadata.EndTimestamp == nil {
-			// don't stomp on currently executing migrations
+			// don't stomp on currently executing migrations, only let a
+			// warm migration know that its cutover got committed
+			if migrationMetadata.Warmup && !migrationMetadata.CutoverCommitted && vmi.Status.MigrationState.CutoverRequested {
+				migrationMetadata.CutoverCommitted = true
+				d, err := l.setDomainSpecWithHooks(vmi, domainSpec)
+				if err != nil {
+					return true, err
+				}
+				defer d.Free()
+				log.Log.Object(vmi).Info("The cutover of the warm migration has been committed.")
+			}
 			return true, nil
 
 		} else {
@@ -208,9 +265,11 @@ func (l *LibvirtDomainManager) initializeMigrationMetadata(vmi *v1.VirtualMachin
 
 	now := metav1.Now()
 	domainSpec.Metadata.KubeVirt.Migration = &api.MigrationMetadata{
-		UID:            vmi.Status.MigrationState.MigrationUID,
-		StartTimestamp: &now,
-		Mode:           migrationMode,
+		UID:              vmi.Status.MigrationState.MigrationUID,
+		StartTimestamp:   &now,
+		Mode:             migrationMode,
+		Warmup:           vmi.Status.MigrationState.Warmup,
+		CutoverCommitted: vmi.Status.MigrationState.CutoverRequested,
 	}
 	d, err := l.setDomainSpecWithHooks(vmi, domainSpec)
 	if err != nil {
@@ -317,6 +376,58 @@ func (l *LibvirtDomainManager) setMigrationResultHelper(vmi *v1.VirtualMachineIn
 
 }
 
+func (l *LibvirtDomainManager) setMigrationWarmupStatus(vmi *v1.VirtualMachineInstance, warmupStatus *api.MigrationWarmupMetadata) error {
+	l.domainModifyLock.Lock()
+	defer l.domainModifyLock.Unlock()
+
+	domName := api.VMINamespaceKeyFunc(vmi)
+	dom, err := l.virConn.LookupDomainByName(domName)
+	if err != nil {
+		return err
+	}
+	defer dom.Free()
+	domainSpec, err := l.getDomainSpec(dom)
+	if err != nil {
+		return err
+	}
+	migrationMetadata := domainSpec.Metadata.KubeVirt.Migration
+	if migrationMetadata == nil || migrationMetadata.EndTimestamp != nil {
+		// nothing to report once the migration is over
+		return nil
+	}
+
+	migrationMetadata.WarmupStatus = warmupStatus
+	d, err := l.setDomainSpecWithHooks(vmi, domainSpec)
+	if err != nil {
+		return err
+	}
+	defer d.Free()
+	return nil
+}
+
+// getWarmupStatus summarizes the progress of a warming up migration
+// from the job stats libvirt reports for it.
+func getWarmupStatus(stats *libvirt.DomainJobInfo, now metav1.Time) *api.MigrationWarmupMetadata {
+	dirtyRate := int64(stats.MemDirtyRate * stats.MemPageSize)
+	transferRate := int64(stats.MemBps)
+	remaining := int64(stats.MemRemaining)
+
+	estimatedDowntime := int64(stats.Downtime)
+	if !stats.DowntimeSet && transferRate > 0 {
+		estimatedDowntime = remaining * 1000 / transferRate
+	}
+
+	return &api.MigrationWarmupMetadata{
+		Iteration:                     int64(stats.MemIteration),
+		MemoryRemainingBytes:          remaining,
+		DirtyRateBytesPerSecond:       dirtyRate,
+		TransferRateBytesPerSecond:    transferRate,
+		Converging:                    transferRate > dirtyRate,
+		EstimatedDowntimeMilliseconds: estimatedDowntime,
+		LastUpdateTimestamp:           &now,
+	}
+}
+
 func prepareMigrationFlags(isBlockMigration, isUnsafeMigration, allowAutoConverge, allowPostyCopy bool) libvirt.DomainMigrateFlags {
 	migrateFlags := libvirt.MIGRATE_LIVE | libvirt.MIGRATE_PEER2PEER
 
@@ -337,6 +448,17 @@ func prepareMigrationFlags(isBlockMigration, isUnsafeMigration, allowAutoConverg
 
 }
 
+// prepareParallelMigration makes the migration transfer the memory over the
+// given number of parallel connections (multifd), zero keeps a single one.
+func prepareParallelMigration(params *libvirt.DomainMigrateParameters, migrateFlags libvirt.DomainMigrateFlags, threads uint32) libvirt.DomainMigrateFlags {
+	if threads == 0 {
+		return migrateFlags
+	}
+	params.ParallelConnections = int(threads)
+	params.ParallelConnectionsSet = true
+	return migrateFlags | libvirt.MIGRATE_PARALLEL
+}
+
 func (d *migrationDisks) isSharedVolume(name string) bool {
 	_, shared := d.shared[name]
 	return shared
@@ -486,6 +608,7 @@ func (l *LibvirtDomainManager) asyncMigrate(vmi *v1.VirtualMachineInstance, opti
 			URI:       migrURI,
 			URISet:    true,
 		}
+		migrateFlags = prepareParallelMigration(params, migrateFlags, options.ParallelMigrationThreads)
 		copyDisks := getDiskTargetsForMigration(dom, vmi)
 		if len(copyDisks) != 0 {
 			params.MigrateDisks = copyDisks
@@ -645,6 +768,12 @@ func liveMigrationMonitor(vmi *v1.VirtualMachineInstance, l *LibvirtDomainManage
 	completionTimeoutPerGiB := options.CompletionTimeoutPerGiB
 
 	acceptableCompletionTime := completionTimeoutPerGiB * getVMIMigrationDataSize(vmi)
+
+	var warmup struct {
+		holding     bool
+		maxDowntime uint64
+		lastReport  int64
+	}
 monitorLoop:
 	for {
 
@@ -687,6 +816,48 @@ monitorLoop:
 				break
 			}
 
+			// keep a warm migration in pre-copy until its cutover gets committed
+			migrationMetadata := domainSpec.Metadata.KubeVirt.Migration
+			if migrationMetadata != nil && migrationMetadata.Warmup {
+				if !migrationMetadata.CutoverCommitted {
+					if !warmup.holding {
+						warmup.holding = true
+						warmup.maxDowntime, err = dom.MigrateGetMaxDowntime(0)
+						if err != nil {
+							logger.Reason(err).Warning("failed to get the max downtime of the warm migration")
+						}
+						err = dom.MigrateSetMaxDowntime(warmupMaxDowntimeMilliseconds, 0)
+						if err != nil {
+							logger.Reason(err).Error("failed to hold the warm migration in pre-copy")
+						}
+						logger.Info("Warm migration is warming up until the cutover is committed")
+					}
+					if now-warmup.lastReport >= warmupReportIntervalSeconds {
+						warmup.lastReport = now
+						jobStats, err := dom.GetJobStats(0)
+						if err != nil {
+							logger.Reason(err).Warning("failed to get the job stats of the warm migration")
+						} else if err := l.setMigrationWarmupStatus(vmi, getWarmupStatus(jobStats, metav1.Now())); err != nil {
+							logger.Reason(err).Warning("failed to report the warmup status of the migration")
+						}
+					}
+					// the timeouts only apply to the migration after the cutover
+					start = now
+					lastProgressUpdate = now
+					progressWatermark = 0
+					break
+				} else if warmup.holding {
+					warmup.holding = false
+					if warmup.maxDowntime != 0 {
+						err = dom.MigrateSetMaxDowntime(warmup.maxDowntime, 0)
+						if err != nil {
+							logger.Reason(err).Error("failed to restore the max downtime of the warm migration")
+						}
+					}
+					logger.Info("Warm migration is cutting over")
+				}
+			}
+
 			// check if the migration is progressing
 			progressDelay := now - lastProgressUpdate
 			if progressTimeout != 0 &&
@@ -1080,6 +1251,17 @@ func (l *LibvirtDomainManager) preStartHook(vmi *v1.VirtualMachineInstance, doma
 	if err := config.CreateServiceAccountDisk(vmi); err != nil {
 		return domain, fmt.Errorf("creating service account disk failed: %v", err)
 	}
+	// copy the ROM images of host devices if they exist
+	if err := config.CreateHostDeviceROMs(vmi); err != nil {
+		return domain, fmt.Errorf("creating host device ROMs failed: %v", err)
+	}
+	// create the variable store template with the custom Secure Boot certificates enrolled if requested
+	if domain.Spec.OS.NVRam != nil && hasSecureBootCertificates(vmi) {
+		source := filepath.Join(l.ovmfPath, converter.EFIVarsSecureBoot)
+		if err := efi.CreateVarsTemplate(source, domain.Spec.OS.NVRam.Template); err != nil {
+			return domain, fmt.Errorf("enrolling the Secure Boot certificates failed: %v", err)
+		}
+	}
 
 	// set drivers cache mode
 	for i := range domain.Spec.Devices.Disks {
@@ -1094,9 +1276,19 @@ func (l *LibvirtDomainManager) preStartHook(vmi *v1.VirtualMachineInstance, doma
 		return domain, fmt.Errorf("Starting qemu agent access credential propagation failed: %v", err)
 	}
 
+	if err := l.balloonManager.HandleBalloonFloor(vmi); err != nil {
+		return domain, fmt.Errorf("Starting memory reclaim through the memory balloon failed: %v", err)
+	}
+
 	return domain, err
 }
 
+func hasSecureBootCertificates(vmi *v1.VirtualMachineInstance) bool {
+	firmware := vmi.Spec.Domain.Firmware
+	return firmware != nil && firmware.Bootloader != nil && firmware.Bootloader.EFI != nil &&
+		firmware.Bootloader.EFI.SecureBootCertificates != nil
+}
+
 // This function parses variables that are set by SR-IOV device plugin listing
 // PCI IDs for devices allocated to the pod. It also parses variables that
 // virt-controller sets mapping network names to their respective resource
@@ -1264,7 +1456,8 @@ func (l *LibvirtDomainManager) SyncVMI(vmi *v1.VirtualMachineInstance, useEmulat
 		}
 	}
 
-	sriovDevices, err := sriov.CreateHostDevices(vmi)
+	// the boot order of the sriov interfaces is set on their host devices
+	sriovDevices, err := sriov.CreateHostDevices(converter.ResolveBootOrder(vmi))
 	if err != nil {
 		return nil, err
 	}
@@ -1420,10 +1613,86 @@ func (l *LibvirtDomainManager) SyncVMI(vmi *v1.VirtualMachineInstance, useEmulat
 		}
 	}
 
+	if !cli.IsDown(domState) && needsVCPURebalance(vmi, &oldSpec) {
+		// a failed rebalance is retried on the next sync, the domain keeps its current pinning meanwhile
+		if err := l.rebalanceVCPUs(vmi, dom, &oldSpec, podCPUSet, emulatorThreadCpu); err != nil {
+			logger.Reason(err).Warning("rebalancing the vCPUs after the migration failed")
+		}
+	}
+
 	// TODO: check if VirtualMachineInstance Spec and Domain Spec are equal or if we have to sync
 	return &oldSpec, nil
 }
 
+var getNUMANodeCPUs = func() (map[uint32][]int, error) {
+	return hardware.GetNUMANodeCPUs(hardware.NUMA_NODES_PATH)
+}
+
+// needsVCPURebalance tells whether the dedicated vCPUs of a domain which just
+// migrated to this node were not pinned along its NUMA layout yet.
+func needsVCPURebalance(vmi *v1.VirtualMachineInstance, spec *api.DomainSpec) bool {
+	migrationState := vmi.Status.MigrationState
+	if !vmi.IsCPUDedicated() || migrationState == nil || !migrationState.Completed || migrationState.Failed {
+		return false
+	}
+	if spec.CPUTune == nil {
+		return false
+	}
+	rebalance := spec.Metadata.KubeVirt.VCPURebalance
+	return rebalance == nil || rebalance.MigrationUID != migrationState.MigrationUID
+}
+
+// rebalanceVCPUs repins the vCPUs and the emulator thread of the live domain
+// on the dedicated CPUs of the pod, following the NUMA layout of this node, and
+// records the migration the pinning was computed after in the domain metadata.
+func (l *LibvirtDomainManager) rebalanceVCPUs(vmi *v1.VirtualMachineInstance, dom cli.VirDomain, spec *api.DomainSpec, cpuSet []int, emulatorThreadCpu *int) error {
+	nodeCPUs, err := getNUMANodeCPUs()
+	if err != nil {
+		return err
+	}
+	pins, err := converter.RebalanceVCPUPinning(spec, cpuSet, nodeCPUs)
+	if err != nil {
+		return err
+	}
+
+	for _, pin := range pins {
+		cpu, err := strconv.Atoi(pin.CPUSet)
+		if err != nil {
+			return err
+		}
+		if err := dom.PinVcpuFlags(pin.VCPU, cpuMap(cpu), libvirt.DOMAIN_AFFECT_LIVE); err != nil {
+			return fmt.Errorf("pinning vCPU %d to CPU %d failed: %v", pin.VCPU, cpu, err)
+		}
+	}
+	spec.CPUTune.VCPUPin = pins
+	if emulatorThreadCpu != nil {
+		if err := dom.PinEmulator(cpuMap(*emulatorThreadCpu), libvirt.DOMAIN_AFFECT_LIVE); err != nil {
+			return fmt.Errorf("pinning the emulator thread to CPU %d failed: %v", *emulatorThreadCpu, err)
+		}
+		spec.CPUTune.EmulatorPin = &api.CPUEmulatorPin{CPUSet: strconv.Itoa(*emulatorThreadCpu)}
+	}
+
+	now := metav1.Now()
+	spec.Metadata.KubeVirt.VCPURebalance = &api.VCPURebalanceMetadata{
+		MigrationUID: vmi.Status.MigrationState.MigrationUID,
+		Timestamp:    &now,
+	}
+	d, err := l.setDomainSpecWithHooks(vmi, spec)
+	if err != nil {
+		return err
+	}
+	defer d.Free()
+	log.Log.Object(vmi).Infof("Rebalanced the vCPUs after migration %s", vmi.Status.MigrationState.MigrationUID)
+	return nil
+}
+
+// cpuMap returns the libvirt CPU map selecting only cpu
+func cpuMap(cpu int) []bool {
+	cpus := make([]bool, cpu+1)
+	cpus[cpu] = true
+	return cpus
+}
+
 func getSourceFile(disk api.Disk) string {
 	file := disk.Source.File
 	if disk.Source.File == "" {
@@ -1645,6 +1914,376 @@ func (l *LibvirtDomainManager) UnpauseVMI(vmi *v1.VirtualMachineInstance) error
 	return nil
 }
 
+// SoftRebootVMI reboots the guest through the guest agent when it is connected, which
+// lets the guest OS shut down cleanly, and presses the ACPI power button otherwise or
+// if the agent fails to reboot the guest. The mechanism used is recorded in the domain
+// metadata, for virt-handler to report it in the VMI status.
+func (l *LibvirtDomainManager) SoftRebootVMI(vmi *v1.VirtualMachineInstance) error {
+	l.domainModifyLock.Lock()
+	defer l.domainModifyLock.Unlock()
+
+	logger := log.Log.Object(vmi)
+
+	domName := util.VMINamespaceKeyFunc(vmi)
+	dom, err := l.virConn.LookupDomainByName(domName)
+	if err != nil {
+		if domainerrors.IsNotFound(err) {
+			return fmt.Errorf("Domain not found.")
+		}
+		logger.Reason(err).Error("Getting the domain failed during soft reboot.")
+		return err
+	}
+	defer dom.Free()
+
+	domState, _, err := dom.GetState()
+	if err != nil {
+		logger.Reason(err).Error("Getting the domain state failed.")
+		return err
+	}
+	if domState != libvirt.DOMAIN_RUNNING {
+		return fmt.Errorf("domain is not running, it can't be soft rebooted")
+	}
+
+	domainSpec, err := l.getDomainSpec(dom)
+	if err != nil {
+		return err
+	}
+
+	mechanism := v1.SoftRebootACPI
+	if isGuestAgentConnected(domainSpec) {
+		if err := dom.Reboot(libvirt.DOMAIN_REBOOT_GUEST_AGENT); err != nil {
+			logger.Reason(err).Warning("The guest agent failed to reboot the guest, falling back to ACPI.")
+		} else {
+			mechanism = v1.SoftRebootGuestAgent
+		}
+	}
+	if mechanism == v1.SoftRebootACPI {
+		if err := dom.Reboot(libvirt.DOMAIN_REBOOT_ACPI_POWER_BTN); err != nil {
+			logger.Reason(err).Error("Signalling the ACPI reboot failed.")
+			return err
+		}
+	}
+	logger.Infof("Signaled soft reboot for %s through %s", vmi.GetObjectMeta().GetName(), mechanism)
+
+	now := metav1.Now()
+	domainSpec.Metadata.KubeVirt.SoftReboot = &api.SoftRebootMetadata{
+		Mechanism: string(mechanism),
+		Timestamp: &now,
+	}
+	d, err := l.setDomainSpecWithHooks(vmi, domainSpec)
+	if err != nil {
+		logger.Reason(err).Error("Unable to record the soft reboot on domain xml")
+		return err
+	}
+	defer d.Free()
+	return nil
+}
+
+// FreezeVMI freezes the guest filesystems through the guest agent, for a consistent
+// snapshot of the disks to be taken, after running the pre-freeze hook of the VMI.
+// The filesystems are thawed again automatically after maxGuestFreezeDuration, in
+// case the caller never calls UnfreezeVMI.
+func (l *LibvirtDomainManager) FreezeVMI(vmi *v1.VirtualMachineInstance) error {
+	l.freezeLock.Lock()
+	defer l.freezeLock.Unlock()
+
+	logger := log.Log.Object(vmi)
+
+	domName := util.VMINamespaceKeyFunc(vmi)
+	dom, err := l.virConn.LookupDomainByName(domName)
+	if err != nil {
+		if domainerrors.IsNotFound(err) {
+			return fmt.Errorf("Domain not found.")
+		}
+		logger.Reason(err).Error("Getting the domain failed during freeze.")
+		return err
+	}
+	defer dom.Free()
+
+	domState, _, err := dom.GetState()
+	if err != nil {
+		logger.Reason(err).Error("Getting the domain state failed.")
+		return err
+	}
+	if domState != libvirt.DOMAIN_RUNNING {
+		return fmt.Errorf("domain is not running, its filesystems can't be frozen")
+	}
+
+	if err := l.waitForGuestAgent(vmi, dom); err != nil {
+		return fmt.Errorf("%v, the guest filesystems can't be frozen", err)
+	}
+
+	execAgentCommand := func(command string) (string, error) {
+		return l.virConn.QemuAgentCommand(command, domName)
+	}
+	postThawPending, err := freezehooks.Freeze(execAgentCommand, vmi.Spec.FreezeHooks)
+	if postThawPending {
+		l.postThawPending = true
+	}
+	if err != nil {
+		logger.Reason(err).Error("Freezing the guest filesystems failed.")
+		return err
+	}
+
+	if l.autoThawTimer != nil {
+		l.autoThawTimer.Stop()
+	}
+	l.autoThawTimer = time.AfterFunc(maxGuestFreezeDuration, func() {
+		logger.Warningf("The guest filesystems are still frozen after %s, thawing them", maxGuestFreezeDuration)
+		if err := l.UnfreezeVMI(vmi); err != nil {
+			logger.Reason(err).Error("Thawing the guest filesystems failed.")
+		}
+	})
+	logger.Info("Froze the guest filesystems")
+	return nil
+}
+
+// UnfreezeVMI thaws the guest filesystems frozen by FreezeVMI and runs the post-thaw
+// hook of the VMI if its pre-freeze hook ran. Thawing filesystems which are not frozen
+// is not an error, which lets callers thaw unconditionally.
+func (l *LibvirtDomainManager) UnfreezeVMI(vmi *v1.VirtualMachineInstance) error {
+	l.freezeLock.Lock()
+	defer l.freezeLock.Unlock()
+
+	domName := util.VMINamespaceKeyFunc(vmi)
+	execAgentCommand := func(command string) (string, error) {
+		return l.virConn.QemuAgentCommand(command, domName)
+	}
+	thawed, err := freezehooks.Thaw(execAgentCommand, vmi.Spec.FreezeHooks, l.postThawPending)
+	if thawed {
+		l.postThawPending = false
+		if l.autoThawTimer != nil {
+			l.autoThawTimer.Stop()
+			l.autoThawTimer = nil
+		}
+		log.Log.Object(vmi).Info("Thawed the guest filesystems")
+	}
+	if err != nil {
+		log.Log.Object(vmi).Reason(err).Error("Thawing the guest filesystems failed.")
+		return err
+	}
+	return nil
+}
+
+// MemoryDumpVMI starts dumping the memory of the guest to dumpPath and returns. The
+// progress and the outcome of the dump are recorded in the domain metadata, for
+// virt-handler to report them in the VMI status. Requesting the last dump again is a
+// no-op, which lets virt-handler retry a request it doesn't know the outcome of.
+func (l *LibvirtDomainManager) MemoryDumpVMI(vmi *v1.VirtualMachineInstance, dumpPath string) error {
+	l.domainModifyLock.Lock()
+	defer l.domainModifyLock.Unlock()
+
+	logger := log.Log.Object(vmi)
+	fileName := filepath.Base(dumpPath)
+
+	domName := api.VMINamespaceKeyFunc(vmi)
+	dom, err := l.virConn.LookupDomainByName(domName)
+	if err != nil {
+		if domainerrors.IsNotFound(err) {
+			return fmt.Errorf("Domain not found.")
+		}
+		logger.Reason(err).Error("Getting the domain failed during memory dump.")
+		return err
+	}
+	defer dom.Free()
+
+	domainSpec, err := l.getDomainSpec(dom)
+	if err != nil {
+		return err
+	}
+	if lastDump := domainSpec.Metadata.KubeVirt.MemoryDump; lastDump != nil {
+		if lastDump.FileName == fileName {
+			return nil
+		}
+		if lastDump.EndTimestamp == nil {
+			return fmt.Errorf("memory dump %s is still in progress", lastDump.FileName)
+		}
+	}
+
+	now := metav1.Now()
+	memoryDump := &api.MemoryDumpMetadata{
+		FileName:       fileName,
+		StartTimestamp: &now,
+	}
+	if err := checkMemoryDumpSpace(vmi, dumpPath); err != nil {
+		memoryDump.EndTimestamp = &now
+		memoryDump.Failed = true
+		memoryDump.FailureReason = err.Error()
+	}
+	domainSpec.Metadata.KubeVirt.MemoryDump = memoryDump
+	d, err := l.setDomainSpecWithHooks(vmi, domainSpec)
+	if err != nil {
+		logger.Reason(err).Error("Unable to record the memory dump on domain xml")
+		return err
+	}
+	defer d.Free()
+
+	if memoryDump.Failed {
+		logger.Warningf("Not dumping the memory to %s: %s", dumpPath, memoryDump.FailureReason)
+		return nil
+	}
+	go l.dumpMemory(vmi, dumpPath)
+	return nil
+}
+
+// checkMemoryDumpSpace fails if the filesystem dumpPath is on lacks the space for a
+// memory dump of the guest.
+func checkMemoryDumpSpace(vmi *v1.VirtualMachineInstance, dumpPath string) error {
+	required := kubevirttypes.GetMemoryDumpSize(vmi)
+	available, err := availableBytes(filepath.Dir(dumpPath))
+	if err != nil {
+		return fmt.Errorf("unable to determine the space available for the memory dump: %v", err)
+	}
+	if available < required.Value() {
+		return fmt.Errorf("insufficient space for the memory dump, %d bytes are available, %s are needed", available, required.String())
+	}
+	return nil
+}
+
+// dumpMemory dumps the memory of the guest to dumpPath, reporting the progress of the
+// dump every memoryDumpReportInterval. A partially written dump is removed.
+func (l *LibvirtDomainManager) dumpMemory(vmi *v1.VirtualMachineInstance, dumpPath string) {
+	logger := log.Log.Object(vmi)
+	fileName := filepath.Base(dumpPath)
+
+	dom, err := l.virConn.LookupDomainByName(api.VMINamespaceKeyFunc(vmi))
+	if err != nil {
+		l.finishMemoryDump(vmi, fileName, err)
+		return
+	}
+	defer dom.Free()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- dom.CoreDumpWithFormat(dumpPath, libvirt.DOMAIN_CORE_DUMP_FORMAT_RAW, libvirt.DUMP_MEMORY_ONLY)
+	}()
+
+	ticker := time.NewTicker(memoryDumpReportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				logger.Reason(err).Errorf("Dumping the memory to %s failed", dumpPath)
+				if removeErr := os.Remove(dumpPath); removeErr != nil && !os.IsNotExist(removeErr) {
+					logger.Reason(removeErr).Errorf("Unable to remove the partial memory dump %s", dumpPath)
+				}
+			} else {
+				logger.Infof("Dumped the memory to %s", dumpPath)
+			}
+			l.finishMemoryDump(vmi, fileName, err)
+			return
+		case <-ticker.C:
+			jobInfo, err := dom.GetJobInfo()
+			if err != nil {
+				logger.Reason(err).V(3).Info("Unable to get the progress of the memory dump")
+				continue
+			}
+			written, total := getMemoryDumpProgress(jobInfo)
+			err = l.updateMemoryDumpMetadata(vmi, fileName, func(memoryDump *api.MemoryDumpMetadata) {
+				memoryDump.BytesWritten = written
+				memoryDump.BytesTotal = total
+			})
+			if err != nil {
+				logger.Reason(err).Error("Unable to record the progress of the memory dump")
+			}
+		}
+	}
+}
+
+// getMemoryDumpProgress returns how many bytes of how many a running memory dump wrote
+// so far, from the job stats libvirt reports for it.
+func getMemoryDumpProgress(jobInfo *libvirt.DomainJobInfo) (int64, int64) {
+	if jobInfo.DataTotalSet {
+		return int64(jobInfo.DataProcessed), int64(jobInfo.DataTotal)
+	}
+	return int64(jobInfo.MemProcessed), int64(jobInfo.MemTotal)
+}
+
+func (l *LibvirtDomainManager) finishMemoryDump(vmi *v1.VirtualMachineInstance, fileName string, dumpErr error) {
+	err := l.updateMemoryDumpMetadata(vmi, fileName, func(memoryDump *api.MemoryDumpMetadata) {
+		now := metav1.Now()
+		memoryDump.EndTimestamp = &now
+		if dumpErr != nil {
+			memoryDump.Failed = true
+			memoryDump.FailureReason = dumpErr.Error()
+			return
+		}
+		memoryDump.Completed = true
+		if memoryDump.BytesTotal > 0 {
+			memoryDump.BytesWritten = memoryDump.BytesTotal
+		}
+	})
+	if err != nil {
+		log.Log.Object(vmi).Reason(err).Error("Unable to record the outcome of the memory dump")
+	}
+}
+
+// updateMemoryDumpMetadata updates the metadata of the running memory dump to fileName.
+func (l *LibvirtDomainManager) updateMemoryDumpMetadata(vmi *v1.VirtualMachineInstance, fileName string, update func(*api.MemoryDumpMetadata)) error {
+	l.domainModifyLock.Lock()
+	defer l.domainModifyLock.Unlock()
+
+	dom, err := l.virConn.LookupDomainByName(api.VMINamespaceKeyFunc(vmi))
+	if err != nil {
+		return err
+	}
+	defer dom.Free()
+	domainSpec, err := l.getDomainSpec(dom)
+	if err != nil {
+		return err
+	}
+	memoryDump := domainSpec.Metadata.KubeVirt.MemoryDump
+	if memoryDump == nil || memoryDump.FileName != fileName || memoryDump.EndTimestamp != nil {
+		return nil
+	}
+
+	update(memoryDump)
+	d, err := l.setDomainSpecWithHooks(vmi, domainSpec)
+	if err != nil {
+		return err
+	}
+	defer d.Free()
+	return nil
+}
+
+// isGuestAgentConnected tells whether the guest agent channel of the domain is connected
+func isGuestAgentConnected(domainSpec *api.DomainSpec) bool {
+	for _, channel := range domainSpec.Devices.Channels {
+		if channel.Target != nil && channel.Target.Name == "org.qemu.guest_agent.0" {
+			return channel.Target.State == "connected"
+		}
+	}
+	return false
+}
+
+// waitForGuestAgent waits up to the agent connect timeout of the vmi for the
+// guest agent of the domain to connect.
+func (l *LibvirtDomainManager) waitForGuestAgent(vmi *v1.VirtualMachineInstance, dom cli.VirDomain) error {
+	domainSpec, err := l.getDomainSpec(dom)
+	if err != nil {
+		return err
+	}
+	if isGuestAgentConnected(domainSpec) {
+		return nil
+	}
+	timeout := vmi.Spec.Domain.Devices.AgentConnectTimeoutSeconds
+	if timeout == nil || *timeout <= 0 {
+		return fmt.Errorf("the guest agent is not connected")
+	}
+	err = utilwait.Poll(agentConnectPollInterval, time.Duration(*timeout)*time.Second, func() (bool, error) {
+		domainSpec, err := l.getDomainSpec(dom)
+		if err != nil {
+			return false, err
+		}
+		return isGuestAgentConnected(domainSpec), nil
+	})
+	if err == utilwait.ErrWaitTimeout {
+		return fmt.Errorf("the guest agent did not connect within %ds", *timeout)
+	}
+	return err
+}
+
 func (l *LibvirtDomainManager) MarkGracefulShutdownVMI(vmi *v1.VirtualMachineInstance) error {
 	l.domainModifyLock.Lock()
 	defer l.domainModifyLock.Unlock()
@@ -1760,7 +2399,8 @@ func (l *LibvirtDomainManager) KillVMI(vmi *v1.VirtualMachineInstance) error {
 		return err
 	}
 
-	if domState == libvirt.DOMAIN_RUNNING || domState == libvirt.DOMAIN_PAUSED || domState == libvirt.DOMAIN_SHUTDOWN {
+	// a guest preserved in its crashed state by its crash action is still running in qemu
+	if domState == libvirt.DOMAIN_RUNNING || domState == libvirt.DOMAIN_PAUSED || domState == libvirt.DOMAIN_SHUTDOWN || domState == libvirt.DOMAIN_CRASHED {
 		err = dom.DestroyFlags(libvirt.DOMAIN_DESTROY_GRACEFUL)
 		if err != nil {
 			if domainerrors.IsNotFound(err) {
@@ -25,8 +25,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang/mock/gomock"
@@ -778,6 +780,379 @@ var _ = Describe("Manager", func() {
 			manager.MarkGracefulShutdownVMI(vmi)
 		})
 	})
+	expectDomainWithGuestAgent := func(vmi *v1.VirtualMachineInstance, agentState string) {
+		domainSpec := expectIsolationDetectionForVMI(vmi)
+		domainSpec.Devices.Channels = []api.Channel{{
+			Type:   "unix",
+			Target: &api.ChannelTarget{Name: "org.qemu.guest_agent.0", Type: "virtio", State: agentState},
+		}}
+		domainXML, err := xml.MarshalIndent(domainSpec, "", "\t")
+		Expect(err).ToNot(HaveOccurred())
+
+		mockDomain.EXPECT().Free().AnyTimes()
+		mockConn.EXPECT().LookupDomainByName(testDomainName).Return(mockDomain, nil)
+		mockDomain.EXPECT().GetState().AnyTimes().Return(libvirt.DOMAIN_RUNNING, 1, nil)
+		mockDomain.EXPECT().IsPersistent().AnyTimes().Return(true, nil)
+		mockDomain.EXPECT().GetXMLDesc(libvirt.DomainXMLFlags(0)).AnyTimes().Return(string(domainXML), nil)
+		mockDomain.EXPECT().
+			GetMetadata(libvirt.DOMAIN_METADATA_ELEMENT, "http://kubevirt.io", libvirt.DOMAIN_AFFECT_CONFIG).
+			AnyTimes().
+			Return("<kubevirt></kubevirt>", nil)
+	}
+
+	Context("test soft reboot", func() {
+		expectSoftRebootRecorded := func(mechanism v1.SoftRebootMechanism) {
+			mockConn.EXPECT().DomainDefineXML(gomock.Any()).DoAndReturn(func(xml string) (cli.VirDomain, error) {
+				Expect(xml).To(ContainSubstring(fmt.Sprintf("<mechanism>%s</mechanism>", mechanism)))
+				return mockDomain, nil
+			})
+		}
+
+		It("should reboot through the guest agent when it is connected", func() {
+			vmi := newVMI(testNamespace, testVmName)
+			expectDomainWithGuestAgent(vmi, "connected")
+			mockDomain.EXPECT().Reboot(libvirt.DOMAIN_REBOOT_GUEST_AGENT).Return(nil)
+			expectSoftRebootRecorded(v1.SoftRebootGuestAgent)
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			Expect(manager.SoftRebootVMI(vmi)).To(Succeed())
+		})
+
+		It("should fall back to ACPI when the guest agent refuses the reboot", func() {
+			vmi := newVMI(testNamespace, testVmName)
+			expectDomainWithGuestAgent(vmi, "connected")
+			mockDomain.EXPECT().Reboot(libvirt.DOMAIN_REBOOT_GUEST_AGENT).Return(libvirt.Error{Code: libvirt.ERR_AGENT_UNRESPONSIVE})
+			mockDomain.EXPECT().Reboot(libvirt.DOMAIN_REBOOT_ACPI_POWER_BTN).Return(nil)
+			expectSoftRebootRecorded(v1.SoftRebootACPI)
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			Expect(manager.SoftRebootVMI(vmi)).To(Succeed())
+		})
+
+		It("should reboot through ACPI when the guest agent is not connected", func() {
+			vmi := newVMI(testNamespace, testVmName)
+			expectDomainWithGuestAgent(vmi, "disconnected")
+			mockDomain.EXPECT().Reboot(libvirt.DOMAIN_REBOOT_ACPI_POWER_BTN).Return(nil)
+			expectSoftRebootRecorded(v1.SoftRebootACPI)
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			Expect(manager.SoftRebootVMI(vmi)).To(Succeed())
+		})
+
+		It("should not reboot a domain which is not running", func() {
+			mockDomain.EXPECT().Free()
+			vmi := newVMI(testNamespace, testVmName)
+			mockConn.EXPECT().LookupDomainByName(testDomainName).Return(mockDomain, nil)
+			mockDomain.EXPECT().GetState().Return(libvirt.DOMAIN_PAUSED, 1, nil)
+			// no call to reboot
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			Expect(manager.SoftRebootVMI(vmi)).ToNot(Succeed())
+		})
+	})
+	Context("test vCPU rebalancing after migration", func() {
+		var vmi *v1.VirtualMachineInstance
+		var domainSpec *api.DomainSpec
+		origGetNUMANodeCPUs := getNUMANodeCPUs
+
+		BeforeEach(func() {
+			vmi = newVMI(testNamespace, testVmName)
+			vmi.Spec.Domain.CPU = &v1.CPU{Cores: 2, DedicatedCPUPlacement: true}
+			vmi.Status.MigrationState = &v1.VirtualMachineInstanceMigrationState{
+				MigrationUID: "111222333",
+				Completed:    true,
+			}
+			domainSpec = &api.DomainSpec{
+				VCPU: &api.VCPU{CPUs: 2},
+				CPUTune: &api.CPUTune{
+					VCPUPin: []api.CPUTuneVCPUPin{{VCPU: 0, CPUSet: "2"}, {VCPU: 1, CPUSet: "5"}},
+				},
+			}
+			getNUMANodeCPUs = func() (map[uint32][]int, error) {
+				return map[uint32][]int{0: {0, 1, 2, 3}, 1: {4, 5, 6, 7}}, nil
+			}
+		})
+
+		AfterEach(func() {
+			getNUMANodeCPUs = origGetNUMANodeCPUs
+		})
+
+		It("should be needed once per completed migration", func() {
+			Expect(needsVCPURebalance(vmi, domainSpec)).To(BeTrue())
+			domainSpec.Metadata.KubeVirt.VCPURebalance = &api.VCPURebalanceMetadata{MigrationUID: "111222333"}
+			Expect(needsVCPURebalance(vmi, domainSpec)).To(BeFalse())
+			vmi.Status.MigrationState.MigrationUID = "444555666"
+			Expect(needsVCPURebalance(vmi, domainSpec)).To(BeTrue())
+		})
+
+		table.DescribeTable("should not be needed", func(update func()) {
+			update()
+			Expect(needsVCPURebalance(vmi, domainSpec)).To(BeFalse())
+		},
+			table.Entry("without dedicated CPUs", func() { vmi.Spec.Domain.CPU.DedicatedCPUPlacement = false }),
+			table.Entry("without a migration", func() { vmi.Status.MigrationState = nil }),
+			table.Entry("while the migration runs", func() { vmi.Status.MigrationState.Completed = false }),
+			table.Entry("after a failed migration", func() { vmi.Status.MigrationState.Failed = true }),
+		)
+
+		It("should pin the vCPUs on one NUMA node of the target and record the migration", func() {
+			mockDomain.EXPECT().PinVcpuFlags(uint(0), []bool{false, true}, libvirt.DOMAIN_AFFECT_LIVE).Return(nil)
+			mockDomain.EXPECT().PinVcpuFlags(uint(1), []bool{false, false, false, true}, libvirt.DOMAIN_AFFECT_LIVE).Return(nil)
+			mockDomain.EXPECT().PinEmulator([]bool{false, false, false, false, false, true}, libvirt.DOMAIN_AFFECT_LIVE).Return(nil)
+			mockConn.EXPECT().DomainDefineXML(gomock.Any()).DoAndReturn(func(xml string) (cli.VirDomain, error) {
+				Expect(xml).To(ContainSubstring("<migrationUid>111222333</migrationUid>"))
+				Expect(xml).To(ContainSubstring(`<vcpupin vcpu="0" cpuset="1"></vcpupin>`))
+				Expect(xml).To(ContainSubstring(`<vcpupin vcpu="1" cpuset="3"></vcpupin>`))
+				Expect(xml).To(ContainSubstring(`<emulatorpin cpuset="5"></emulatorpin>`))
+				return mockDomain, nil
+			})
+			mockDomain.EXPECT().Free()
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			emulatorThreadCpu := 5
+			Expect(manager.(*LibvirtDomainManager).rebalanceVCPUs(vmi, mockDomain, domainSpec, []int{1, 3, 4}, &emulatorThreadCpu)).To(Succeed())
+			Expect(domainSpec.CPUTune.VCPUPin).To(Equal([]api.CPUTuneVCPUPin{{VCPU: 0, CPUSet: "1"}, {VCPU: 1, CPUSet: "3"}}))
+		})
+
+		It("should keep the pinning when the pod has not enough dedicated CPUs", func() {
+			// no call to pin or define the domain
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			Expect(manager.(*LibvirtDomainManager).rebalanceVCPUs(vmi, mockDomain, domainSpec, []int{1}, nil)).ToNot(Succeed())
+			Expect(domainSpec.Metadata.KubeVirt.VCPURebalance).To(BeNil())
+		})
+
+		It("should not record the migration when a vCPU can not be pinned", func() {
+			mockDomain.EXPECT().PinVcpuFlags(uint(0), gomock.Any(), libvirt.DOMAIN_AFFECT_LIVE).Return(libvirt.Error{Code: libvirt.ERR_OPERATION_INVALID})
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			Expect(manager.(*LibvirtDomainManager).rebalanceVCPUs(vmi, mockDomain, domainSpec, []int{1, 3}, nil)).ToNot(Succeed())
+			Expect(domainSpec.Metadata.KubeVirt.VCPURebalance).To(BeNil())
+		})
+	})
+
+	Context("test guest filesystem freeze", func() {
+		It("should freeze and thaw the guest filesystems through the guest agent", func() {
+			vmi := newVMI(testNamespace, testVmName)
+			expectDomainWithGuestAgent(vmi, "connected")
+			mockConn.EXPECT().QemuAgentCommand(`{"execute":"guest-fsfreeze-freeze"}`, testDomainName).Return("", nil)
+			mockConn.EXPECT().QemuAgentCommand(`{"execute":"guest-fsfreeze-thaw"}`, testDomainName).Return("", nil)
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			Expect(manager.FreezeVMI(vmi)).To(Succeed())
+			Expect(manager.UnfreezeVMI(vmi)).To(Succeed())
+		})
+
+		It("should not freeze the guest filesystems when the guest agent is not connected", func() {
+			vmi := newVMI(testNamespace, testVmName)
+			expectDomainWithGuestAgent(vmi, "disconnected")
+			// no call to the guest agent
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			Expect(manager.FreezeVMI(vmi)).To(MatchError(ContainSubstring("guest agent is not connected")))
+		})
+
+		It("should thaw the guest filesystems which were not frozen", func() {
+			vmi := newVMI(testNamespace, testVmName)
+			mockConn.EXPECT().QemuAgentCommand(`{"execute":"guest-fsfreeze-thaw"}`, testDomainName).Return("", nil)
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			Expect(manager.UnfreezeVMI(vmi)).To(Succeed())
+		})
+
+		It("should thaw the guest filesystems when nobody does it in time", func() {
+			defer func(duration time.Duration) { maxGuestFreezeDuration = duration }(maxGuestFreezeDuration)
+			maxGuestFreezeDuration = 10 * time.Millisecond
+
+			vmi := newVMI(testNamespace, testVmName)
+			expectDomainWithGuestAgent(vmi, "connected")
+			thawed := make(chan struct{})
+			mockConn.EXPECT().QemuAgentCommand(`{"execute":"guest-fsfreeze-freeze"}`, testDomainName).Return("", nil)
+			mockConn.EXPECT().QemuAgentCommand(`{"execute":"guest-fsfreeze-thaw"}`, testDomainName).DoAndReturn(func(_, _ string) (string, error) {
+				close(thawed)
+				return "", nil
+			})
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			Expect(manager.FreezeVMI(vmi)).To(Succeed())
+			Eventually(thawed).Should(BeClosed())
+		})
+	})
+	Context("test memory dump", func() {
+		var dumpDir string
+		var dumpPath string
+		var metadataXML string
+		var metadataLock sync.Mutex
+		var origAvailableBytes func(string) (int64, error)
+
+		getMemoryDump := func() *api.MemoryDumpMetadata {
+			metadataLock.Lock()
+			defer metadataLock.Unlock()
+			metadata := &api.KubeVirtMetadata{}
+			Expect(xml.Unmarshal([]byte(metadataXML), metadata)).To(Succeed())
+			return metadata.MemoryDump
+		}
+
+		expectDomainRecordingMetadata := func(vmi *v1.VirtualMachineInstance) {
+			domainSpec := expectIsolationDetectionForVMI(vmi)
+			domainXML, err := xml.MarshalIndent(domainSpec, "", "\t")
+			Expect(err).ToNot(HaveOccurred())
+
+			mockDomain.EXPECT().Free().AnyTimes()
+			mockConn.EXPECT().LookupDomainByName(testDomainName).AnyTimes().Return(mockDomain, nil)
+			mockDomain.EXPECT().GetState().AnyTimes().Return(libvirt.DOMAIN_RUNNING, 1, nil)
+			mockDomain.EXPECT().IsPersistent().AnyTimes().Return(true, nil)
+			mockDomain.EXPECT().GetXMLDesc(libvirt.DomainXMLFlags(0)).AnyTimes().Return(string(domainXML), nil)
+			mockDomain.EXPECT().
+				GetMetadata(libvirt.DOMAIN_METADATA_ELEMENT, "http://kubevirt.io", libvirt.DOMAIN_AFFECT_CONFIG).
+				AnyTimes().
+				DoAndReturn(func(_ libvirt.DomainMetadataType, _ string, _ libvirt.DomainModificationImpact) (string, error) {
+					metadataLock.Lock()
+					defer metadataLock.Unlock()
+					return metadataXML, nil
+				})
+			mockConn.EXPECT().DomainDefineXML(gomock.Any()).AnyTimes().DoAndReturn(func(domXML string) (cli.VirDomain, error) {
+				spec := &api.DomainSpec{}
+				Expect(xml.Unmarshal([]byte(domXML), spec)).To(Succeed())
+				metadata, err := xml.Marshal(spec.Metadata.KubeVirt)
+				Expect(err).ToNot(HaveOccurred())
+				metadataLock.Lock()
+				defer metadataLock.Unlock()
+				metadataXML = string(metadata)
+				return mockDomain, nil
+			})
+		}
+
+		BeforeEach(func() {
+			var err error
+			dumpDir, err = ioutil.TempDir("", "memorydump")
+			Expect(err).ToNot(HaveOccurred())
+			dumpPath = filepath.Join(dumpDir, "testvmi.memory.dump")
+			metadataXML = "<kubevirt></kubevirt>"
+			origAvailableBytes = availableBytes
+			availableBytes = func(string) (int64, error) { return 1 << 40, nil }
+		})
+
+		AfterEach(func() {
+			availableBytes = origAvailableBytes
+			os.RemoveAll(dumpDir)
+		})
+
+		It("should dump the memory and record the outcome", func() {
+			vmi := newVMI(testNamespace, testVmName)
+			expectDomainRecordingMetadata(vmi)
+			mockDomain.EXPECT().CoreDumpWithFormat(dumpPath, libvirt.DOMAIN_CORE_DUMP_FORMAT_RAW, libvirt.DUMP_MEMORY_ONLY).Return(nil)
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			Expect(manager.MemoryDumpVMI(vmi, dumpPath)).To(Succeed())
+			Eventually(func() bool {
+				memoryDump := getMemoryDump()
+				return memoryDump != nil && memoryDump.EndTimestamp != nil
+			}).Should(BeTrue())
+			memoryDump := getMemoryDump()
+			Expect(memoryDump.FileName).To(Equal("testvmi.memory.dump"))
+			Expect(memoryDump.Completed).To(BeTrue())
+			Expect(memoryDump.Failed).To(BeFalse())
+		})
+
+		It("should record the progress of a running dump", func() {
+			defer func(interval time.Duration) { memoryDumpReportInterval = interval }(memoryDumpReportInterval)
+			memoryDumpReportInterval = 10 * time.Millisecond
+
+			vmi := newVMI(testNamespace, testVmName)
+			expectDomainRecordingMetadata(vmi)
+			finish := make(chan struct{})
+			mockDomain.EXPECT().CoreDumpWithFormat(dumpPath, libvirt.DOMAIN_CORE_DUMP_FORMAT_RAW, libvirt.DUMP_MEMORY_ONLY).DoAndReturn(
+				func(_ string, _ libvirt.DomainCoreDumpFormat, _ libvirt.DomainCoreDumpFlags) error {
+					<-finish
+					return nil
+				})
+			mockDomain.EXPECT().GetJobInfo().AnyTimes().Return(&libvirt.DomainJobInfo{DataTotalSet: true, DataTotal: 100, DataProcessed: 40}, nil)
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			Expect(manager.MemoryDumpVMI(vmi, dumpPath)).To(Succeed())
+			Eventually(func() int64 {
+				if memoryDump := getMemoryDump(); memoryDump != nil {
+					return memoryDump.BytesWritten
+				}
+				return 0
+			}).Should(Equal(int64(40)))
+			Expect(getMemoryDump().BytesTotal).To(Equal(int64(100)))
+			Expect(getMemoryDump().EndTimestamp).To(BeNil())
+
+			close(finish)
+			Eventually(func() bool {
+				return getMemoryDump().Completed
+			}).Should(BeTrue())
+			Expect(getMemoryDump().BytesWritten).To(Equal(int64(100)))
+		})
+
+		It("should record a failed dump and remove the partial file", func() {
+			vmi := newVMI(testNamespace, testVmName)
+			expectDomainRecordingMetadata(vmi)
+			mockDomain.EXPECT().CoreDumpWithFormat(dumpPath, libvirt.DOMAIN_CORE_DUMP_FORMAT_RAW, libvirt.DUMP_MEMORY_ONLY).DoAndReturn(
+				func(path string, _ libvirt.DomainCoreDumpFormat, _ libvirt.DomainCoreDumpFlags) error {
+					Expect(ioutil.WriteFile(path, []byte("partial"), 0644)).To(Succeed())
+					return fmt.Errorf("disk full")
+				})
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			Expect(manager.MemoryDumpVMI(vmi, dumpPath)).To(Succeed())
+			Eventually(func() bool {
+				memoryDump := getMemoryDump()
+				return memoryDump != nil && memoryDump.EndTimestamp != nil
+			}).Should(BeTrue())
+			Expect(getMemoryDump().Failed).To(BeTrue())
+			Expect(getMemoryDump().FailureReason).To(ContainSubstring("disk full"))
+			Expect(dumpPath).ToNot(BeAnExistingFile())
+		})
+
+		It("should not dump the memory when the claim lacks the space", func() {
+			availableBytes = func(string) (int64, error) { return 1024, nil }
+
+			vmi := newVMI(testNamespace, testVmName)
+			expectDomainRecordingMetadata(vmi)
+			// no call to CoreDumpWithFormat
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			Expect(manager.MemoryDumpVMI(vmi, dumpPath)).To(Succeed())
+			memoryDump := getMemoryDump()
+			Expect(memoryDump.Failed).To(BeTrue())
+			Expect(memoryDump.FailureReason).To(ContainSubstring("insufficient space"))
+			Expect(memoryDump.EndTimestamp).ToNot(BeNil())
+		})
+
+		It("should not dump the memory again for the same file", func() {
+			vmi := newVMI(testNamespace, testVmName)
+			expectDomainRecordingMetadata(vmi)
+			now := metav1.Now()
+			metadata, err := xml.Marshal(api.KubeVirtMetadata{MemoryDump: &api.MemoryDumpMetadata{
+				FileName:       "testvmi.memory.dump",
+				StartTimestamp: &now,
+			}})
+			Expect(err).ToNot(HaveOccurred())
+			metadataXML = string(metadata)
+			// no call to CoreDumpWithFormat
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			Expect(manager.MemoryDumpVMI(vmi, dumpPath)).To(Succeed())
+		})
+
+		It("should refuse a dump while another one is in progress", func() {
+			vmi := newVMI(testNamespace, testVmName)
+			expectDomainRecordingMetadata(vmi)
+			now := metav1.Now()
+			metadata, err := xml.Marshal(api.KubeVirtMetadata{MemoryDump: &api.MemoryDumpMetadata{
+				FileName:       "previous.memory.dump",
+				StartTimestamp: &now,
+			}})
+			Expect(err).ToNot(HaveOccurred())
+			metadataXML = string(metadata)
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			Expect(manager.MemoryDumpVMI(vmi, dumpPath)).To(MatchError(ContainSubstring("still in progress")))
+		})
+	})
 	Context("test migration monitor", func() {
 		It("migration should be canceled if it's not progressing", func() {
 			migrationErrorChan := make(chan error)
@@ -1096,6 +1471,78 @@ var _ = Describe("Manager", func() {
 			}, 20*time.Second, 2).Should(BeTrue(), "failed migration result wasn't set")
 		})
 
+		It("should pass the bandwidth and the parallel connections to the migration", func() {
+			isMigrationFailedSet := make(chan bool, 1)
+
+			defer close(isMigrationFailedSet)
+
+			// Make sure that we always free the domain after use
+			mockDomain.EXPECT().Free().AnyTimes()
+			fake_jobinfo := func() *libvirt.DomainJobInfo {
+				return &libvirt.DomainJobInfo{
+					Type:          libvirt.DOMAIN_JOB_NONE,
+					DataRemaining: uint64(32479827394),
+				}
+			}()
+
+			vmi := newVMI(testNamespace, testVmName)
+			vmi.Status.MigrationState = &v1.VirtualMachineInstanceMigrationState{
+				MigrationUID: "111222333",
+			}
+
+			domainSpec := expectIsolationDetectionForVMI(vmi)
+			domainSpec.Metadata.KubeVirt.Migration = &api.MigrationMetadata{}
+
+			manager, _ := NewLibvirtDomainManager(mockConn, "fake", nil, 0, nil, "/usr/share/OVMF")
+
+			mockConn.EXPECT().LookupDomainByName(testDomainName).AnyTimes().Return(mockDomain, nil)
+			mockDomain.EXPECT().GetState().AnyTimes().Return(libvirt.DOMAIN_RUNNING, 1, nil)
+
+			domainXml, err := xml.MarshalIndent(domainSpec, "", "\t")
+			Expect(err).To(BeNil())
+			mockDomain.EXPECT().GetJobInfo().AnyTimes().Return(fake_jobinfo, nil)
+			gomock.InOrder(
+				mockConn.EXPECT().DomainDefineXML(gomock.Any()).Return(mockDomain, nil),
+				mockConn.EXPECT().DomainDefineXML(gomock.Any()).DoAndReturn(func(domainXml string) (cli.VirDomain, error) {
+					isMigrationFailedSet <- true
+					return mockDomain, nil
+				}),
+			)
+			mockDomain.EXPECT().GetXMLDesc(gomock.Any()).AnyTimes().Return(string(domainXml), nil)
+
+			metadataXml, err := xml.MarshalIndent(domainSpec.Metadata.KubeVirt, "", "\t")
+			Expect(err).NotTo(HaveOccurred())
+			mockDomain.EXPECT().
+				GetMetadata(libvirt.DOMAIN_METADATA_ELEMENT, "http://kubevirt.io", libvirt.DOMAIN_AFFECT_CONFIG).
+				AnyTimes().
+				Return(string(metadataXml), nil)
+
+			mockDomain.EXPECT().MigrateToURI3(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ string, params *libvirt.DomainMigrateParameters, flags libvirt.DomainMigrateFlags) error {
+				defer GinkgoRecover()
+				Expect(params.Bandwidth).To(Equal(uint64(32)))
+				Expect(params.ParallelConnectionsSet).To(BeTrue())
+				Expect(params.ParallelConnections).To(Equal(4))
+				Expect(flags & libvirt.MIGRATE_PARALLEL).To(Equal(libvirt.MIGRATE_PARALLEL))
+				return fmt.Errorf("MigrationFailed")
+			})
+			options := &cmdclient.MigrationOptions{
+				Bandwidth:                resource.MustParse("32Mi"),
+				ProgressTimeout:          150,
+				CompletionTimeoutPerGiB:  300,
+				ParallelMigrationThreads: 4,
+			}
+			err = manager.MigrateVMI(vmi, options)
+			Expect(err).To(BeNil())
+			Eventually(func() bool {
+				select {
+				case isSet := <-isMigrationFailedSet:
+					return isSet
+				default:
+				}
+				return false
+			}, 20*time.Second, 2).Should(BeTrue(), "failed migration result wasn't set")
+		})
+
 		It("should detect inprogress migration job", func() {
 			// Make sure that we always free the domain after use
 			mockDomain.EXPECT().Free()
@@ -1244,6 +1691,7 @@ var _ = Describe("Manager", func() {
 			table.Entry("shuttingDown", libvirt.DOMAIN_SHUTDOWN),
 			table.Entry("running", libvirt.DOMAIN_RUNNING),
 			table.Entry("paused", libvirt.DOMAIN_PAUSED),
+			table.Entry("crashed", libvirt.DOMAIN_CRASHED),
 		)
 	})
 	table.DescribeTable("check migration flags",
@@ -1276,6 +1724,18 @@ var _ = Describe("Manager", func() {
 		table.Entry("migration using postcopy", "postCopy"),
 	)
 
+	table.DescribeTable("check parallel migration",
+		func(threads uint32, expectedFlags libvirt.DomainMigrateFlags, expectedConnections int) {
+			params := &libvirt.DomainMigrateParameters{}
+			flags := prepareParallelMigration(params, libvirt.MIGRATE_LIVE, threads)
+			Expect(flags).To(Equal(expectedFlags))
+			Expect(params.ParallelConnectionsSet).To(Equal(expectedConnections != 0))
+			Expect(params.ParallelConnections).To(Equal(expectedConnections))
+		},
+		table.Entry("without parallel connections", uint32(0), libvirt.MIGRATE_LIVE, 0),
+		table.Entry("with parallel connections", uint32(4), libvirt.MIGRATE_LIVE|libvirt.MIGRATE_PARALLEL, 4),
+	)
+
 	table.DescribeTable("on successful list all domains",
 		func(state libvirt.DomainState, kubevirtState api.LifeCycle, libvirtReason int, kubevirtReason api.StateChangeReason) {
 
@@ -65,10 +65,13 @@ type VIF struct {
 
 type CriticalNetworkError struct {
 	Msg string
+	Err error
 }
 
 func (e *CriticalNetworkError) Error() string { return e.Msg }
 
+func (e *CriticalNetworkError) Unwrap() error { return e.Err }
+
 func (vif VIF) String() string {
 	return fmt.Sprintf(
 		"VIF: { Name: %s, IP: %s, Mask: %s, IPv6: %s, MAC: %s, Gateway: %s, MTU: %d, IPAMDisabled: %t}",
@@ -411,15 +414,25 @@ func (h *NetworkUtilsHandler) CreateTapDevice(tapName string, queueNumber uint32
 	if err != nil {
 		return err
 	}
+	defer tapDeviceSELinuxCmdExecutor.Close()
 	if err := tapDeviceSELinuxCmdExecutor.Execute(); err != nil {
-		return fmt.Errorf("error creating tap device named %s; %v", tapName, err)
+		return fmt.Errorf("error creating tap device named %s; %w", tapName, err)
 	}
 
 	log.Log.Infof("Created tap device: %s in PID: %d", tapName, launcherPID)
 	return nil
 }
 
-func buildTapDeviceMaker(tapName string, queueNumber uint32, virtLauncherPID int, mtu int) (*selinux.ContextExecutor, error) {
+// newLauncherExecutor runs cmd in the selinux context of the launcher
+var newLauncherExecutor = func(launcherPID int, cmd *exec.Cmd) (selinux.Executor, error) {
+	executor, err := selinux.NewContextExecutor(launcherPID, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return executor, nil
+}
+
+func buildTapDeviceMaker(tapName string, queueNumber uint32, virtLauncherPID int, mtu int) (selinux.Executor, error) {
 	createTapDeviceArgs := []string{
 		"create-tap",
 		"--tap-name", tapName,
@@ -430,7 +443,7 @@ func buildTapDeviceMaker(tapName string, queueNumber uint32, virtLauncherPID int
 	}
 	// #nosec No risk for attacket injection. createTapDeviceArgs includes predefined strings
 	cmd := exec.Command("virt-chroot", createTapDeviceArgs...)
-	return selinux.NewContextExecutor(virtLauncherPID, cmd)
+	return newLauncherExecutor(virtLauncherPID, cmd)
 }
 
 func (h *NetworkUtilsHandler) BindTapDeviceToBridge(tapName string, bridgeName string) error {
@@ -24,6 +24,7 @@ import (
 	"io/ioutil"
 	"net"
 	"os"
+	"os/exec"
 	"strings"
 
 	. "github.com/onsi/ginkgo"
@@ -31,6 +32,8 @@ import (
 	"github.com/vishvananda/netlink"
 	"k8s.io/apimachinery/pkg/types"
 
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux"
+	"kubevirt.io/kubevirt/pkg/virt-handler/selinux/testutils"
 	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/api"
 )
 
@@ -110,6 +113,59 @@ var _ = Describe("Common Methods", func() {
 			Expect(strings.HasPrefix(mac.String(), "02:00:00")).To(BeTrue())
 		})
 	})
+	Context("CreateTapDevice function", func() {
+		var recorder *testutils.FakeCommandRecorder
+		var originalNewLauncherExecutor func(int, *exec.Cmd) (selinux.Executor, error)
+
+		BeforeEach(func() {
+			recorder = testutils.NewFakeCommandRecorder()
+			originalNewLauncherExecutor = newLauncherExecutor
+			newLauncherExecutor = func(launcherPID int, cmd *exec.Cmd) (selinux.Executor, error) {
+				return recorder.NewExecutor(launcherPID, cmd), nil
+			}
+		})
+
+		AfterEach(func() {
+			newLauncherExecutor = originalNewLauncherExecutor
+		})
+
+		It("should create the tap device in the context of the launcher", func() {
+			networkHandler := NetworkUtilsHandler{}
+			Expect(networkHandler.CreateTapDevice("tap0", 4, 1234, 1410)).To(Succeed())
+
+			Expect(recorder.ExecutedCommands()).To(ConsistOf(testutils.ExecutedCommand{
+				PID:  1234,
+				Path: exec.Command("virt-chroot").Path,
+				Args: []string{
+					"virt-chroot", "create-tap",
+					"--tap-name", "tap0",
+					"--uid", tapOwnerUID,
+					"--gid", tapOwnerGID,
+					"--queue-number", "4",
+					"--mtu", "1410",
+				},
+			}))
+			Expect(recorder.ClosedExecutors()).To(Equal(1))
+		})
+
+		It("should fail when the tap device can't be created", func() {
+			recorder.SetExecuteError(fmt.Errorf("exit status 1"))
+			networkHandler := NetworkUtilsHandler{}
+			err := networkHandler.CreateTapDevice("tap0", 0, 1234, 1500)
+			Expect(err).To(MatchError("error creating tap device named tap0; exit status 1"))
+			Expect(recorder.ExecutedCommands()).To(HaveLen(1))
+			Expect(recorder.ClosedExecutors()).To(Equal(1))
+		})
+
+		It("should not create the tap device when the executor can't be created", func() {
+			newLauncherExecutor = func(launcherPID int, cmd *exec.Cmd) (selinux.Executor, error) {
+				return nil, fmt.Errorf("launcher %d not found", launcherPID)
+			}
+			networkHandler := NetworkUtilsHandler{}
+			Expect(networkHandler.CreateTapDevice("tap0", 0, 1234, 1500)).To(MatchError("launcher 1234 not found"))
+			Expect(recorder.ExecutedCommands()).To(BeEmpty())
+		})
+	})
 })
 
 var _ = Describe("VIF", func() {
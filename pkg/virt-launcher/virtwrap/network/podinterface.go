@@ -222,7 +222,7 @@ func (l *podNICImpl) PlugPhase1(vmi *v1.VirtualMachineInstance, iface *v1.Interf
 }
 
 func createCriticalNetworkError(err error) *CriticalNetworkError {
-	return &CriticalNetworkError{fmt.Sprintf("Critical network error: %v", err)}
+	return &CriticalNetworkError{Msg: fmt.Sprintf("Critical network error: %v", err), Err: err}
 }
 
 func ensureDHCP(vmi *v1.VirtualMachineInstance, bindMechanism BindMechanism, podInterfaceName string) error {
@@ -414,7 +414,11 @@ func (b *BridgeBindMechanism) discoverPodNetworkInterface() error {
 	}
 
 	// Get interface MTU
-	b.vif.Mtu = uint16(b.podNicLink.Attrs().MTU)
+	b.vif.Mtu, err = guestMTU(b.iface, b.podNicLink.Attrs().MTU)
+	if err != nil {
+		log.Log.Reason(err).Errorf("failed to set the MTU of interface %s", b.iface.Name)
+		return err
+	}
 
 	if !b.vif.IPAMDisabled {
 		// Handle interface routes
@@ -506,7 +510,7 @@ func (b *BridgeBindMechanism) preparePodNetworkInterfaces(queueNumber uint32, la
 		return err
 	}
 
-	b.virtIface.MTU = &api.MTU{Size: strconv.Itoa(b.podNicLink.Attrs().MTU)}
+	b.virtIface.MTU = &api.MTU{Size: strconv.Itoa(int(b.vif.Mtu))}
 	b.virtIface.MAC = &api.MAC{MAC: b.vif.MAC.String()}
 	b.virtIface.Target = &api.InterfaceTarget{
 		Device:  tapDeviceName,
@@ -709,7 +713,11 @@ func (b *MasqueradeBindMechanism) discoverPodNetworkInterface() error {
 	}
 
 	// Get interface MTU
-	b.vif.Mtu = uint16(b.podNicLink.Attrs().MTU)
+	b.vif.Mtu, err = guestMTU(b.iface, b.podNicLink.Attrs().MTU)
+	if err != nil {
+		log.Log.Reason(err).Errorf("failed to set the MTU of interface %s", b.iface.Name)
+		return err
+	}
 
 	err = configureVifV4Addresses(b, err)
 	if err != nil {
@@ -857,7 +865,7 @@ func (b *MasqueradeBindMechanism) preparePodNetworkInterfaces(queueNumber uint32
 		}
 	}
 
-	b.virtIface.MTU = &api.MTU{Size: strconv.Itoa(b.podNicLink.Attrs().MTU)}
+	b.virtIface.MTU = &api.MTU{Size: strconv.Itoa(int(b.vif.Mtu))}
 	if b.vif.MAC != nil {
 		b.virtIface.MAC = &api.MAC{MAC: b.vif.MAC.String()}
 	}
@@ -1318,6 +1326,19 @@ func (b *MacvtapBindMechanism) startDHCP(vmi *v1.VirtualMachineInstance) error {
 	return nil
 }
 
+// guestMTU returns the MTU of the interface in the guest. It defaults to the
+// MTU of the pod interface and may not exceed it, as the pod network would
+// drop the larger packets the guest sends otherwise.
+func guestMTU(iface *v1.Interface, podMTU int) (uint16, error) {
+	if iface.MTU == nil {
+		return uint16(podMTU), nil
+	}
+	if *iface.MTU > uint(podMTU) {
+		return 0, fmt.Errorf("MTU %d of interface %s exceeds the MTU %d of the pod network", *iface.MTU, iface.Name, podMTU)
+	}
+	return uint16(*iface.MTU), nil
+}
+
 func createAndBindTapToBridge(deviceName string, bridgeIfaceName string, queueNumber uint32, launcherPID int, mtu int) error {
 	err := Handler.CreateTapDevice(deviceName, queueNumber, launcherPID, mtu)
 	if err != nil {
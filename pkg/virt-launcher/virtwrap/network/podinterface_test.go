@@ -588,6 +588,48 @@ var _ = Describe("Pod Network", func() {
 				Expect(domain.Spec.QEMUCmd.QEMUArg[1]).To(Equal(api.Arg{Value: "e1000,netdev=default,id=default"}))
 			})
 		})
+		Context("Bridge plug with an MTU set on the interface", func() {
+			It("should set the MTU on the tap device and in the domain", func() {
+				domain := NewDomainWithBridgeInterface()
+				vmi := newVMIBridgeInterface("testnamespace", "testVmName")
+				guestMTU := uint(1400)
+				vmi.Spec.Domain.Devices.Interfaces[0].MTU = &guestMTU
+
+				api.NewDefaulter(runtime.GOARCH).SetObjectDefaults_Domain(domain)
+
+				driver, err := getPhase2Binding(vmi, &vmi.Spec.Domain.Devices.Interfaces[0], &vmi.Spec.Networks[0], domain, primaryPodInterfaceName)
+				Expect(err).ToNot(HaveOccurred())
+				mockNetwork.EXPECT().LinkByName(primaryPodInterfaceName).Return(primaryPodInterface, nil)
+				mockNetwork.EXPECT().AddrList(primaryPodInterface, netlink.FAMILY_V4).Return(nil, nil)
+				mockNetwork.EXPECT().GetMacDetails(primaryPodInterfaceName).Return(fakeMac, nil)
+				mockNetwork.EXPECT().LinkSetDown(primaryPodInterface).Return(nil)
+				mockNetwork.EXPECT().SetRandomMac(primaryPodInterfaceName).Return(updateFakeMac, nil)
+				mockNetwork.EXPECT().LinkAdd(bridgeTest).Return(nil)
+				mockNetwork.EXPECT().LinkSetMaster(primaryPodInterface, bridgeTest).Return(nil)
+				mockNetwork.EXPECT().LinkSetUp(bridgeTest).Return(nil)
+				mockNetwork.EXPECT().ParseAddr(fmt.Sprintf(bridgeFakeIP, 0)).Return(bridgeAddr, nil)
+				mockNetwork.EXPECT().AddrAdd(bridgeTest, bridgeAddr).Return(nil)
+				mockNetwork.EXPECT().DisableTXOffloadChecksum(bridgeTest.Name).Return(nil)
+				mockNetwork.EXPECT().CreateTapDevice(tapDeviceName, queueNumber, pid, 1400).Return(nil)
+				mockNetwork.EXPECT().BindTapDeviceToBridge(tapDeviceName, "k6t-eth0").Return(nil)
+				mockNetwork.EXPECT().LinkSetUp(primaryPodInterface).Return(nil)
+				mockNetwork.EXPECT().LinkSetLearningOff(primaryPodInterface).Return(nil)
+				TestRunPlug(driver)
+				Expect(domain.Spec.Devices.Interfaces[0].MTU).To(Equal(&api.MTU{Size: "1400"}), "should have the MTU of the interface")
+			})
+
+			It("should refuse an MTU exceeding the one of the pod network", func() {
+				vmi := newVMIMasqueradeInterface("testnamespace", "testVmName")
+				guestMTU := uint(9000)
+				vmi.Spec.Domain.Devices.Interfaces[0].MTU = &guestMTU
+
+				driver, err := getPhase1Binding(vmi, &vmi.Spec.Domain.Devices.Interfaces[0], &vmi.Spec.Networks[0], primaryPodInterfaceName)
+				Expect(err).ToNot(HaveOccurred())
+				mockNetwork.EXPECT().LinkByName(primaryPodInterfaceName).Return(primaryPodInterface, nil)
+				err = driver.discoverPodNetworkInterface()
+				Expect(err).To(MatchError("MTU 9000 of interface default exceeds the MTU 1410 of the pod network"))
+			})
+		})
 		Context("Macvtap plug", func() {
 			It("Should pass a non-privileged macvtap interface to qemu", func() {
 				ifaceName := "macvtap0"
@@ -0,0 +1,258 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package startupprobe
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/client-go/log"
+	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/api"
+)
+
+const (
+	defaultTimeoutSeconds   = 1
+	defaultPeriodSeconds    = 10
+	defaultFailureThreshold = 3
+
+	execStatusPollInterval = 100 * time.Millisecond
+	// maxErrDataBytes bounds the stderr of a failed exec probe kept in the status
+	maxErrDataBytes = 256
+)
+
+// AgentCommandExecutor runs a guest agent command and returns its JSON reply.
+type AgentCommandExecutor func(command string) (string, error)
+
+type execReturn struct {
+	Return execReturnData `json:"return"`
+}
+type execReturnData struct {
+	Pid int `json:"pid"`
+}
+
+type execStatusReturn struct {
+	Return execStatusReturnData `json:"return"`
+}
+type execStatusReturnData struct {
+	Exited   bool   `json:"exited"`
+	ExitCode int    `json:"exitcode"`
+	ErrData  string `json:"err-data"`
+}
+
+// Prober evaluates the startup probe of a VMI through the guest agent.
+type Prober struct {
+	probe              *v1.Probe
+	execAgentCommand   AgentCommandExecutor
+	isAgentUnavailable func(err error) bool
+	report             func(status api.StartupProbeStatus)
+
+	initialDelay     time.Duration
+	timeout          time.Duration
+	period           time.Duration
+	failureThreshold int
+}
+
+// NewProber returns a prober evaluating probe with execAgentCommand.
+// isAgentUnavailable tells the errors returned when the guest agent is not
+// connected, report is called on every change of the probe status.
+func NewProber(probe *v1.Probe, execAgentCommand AgentCommandExecutor, isAgentUnavailable func(err error) bool, report func(status api.StartupProbeStatus)) *Prober {
+	p := &Prober{
+		probe:              probe,
+		execAgentCommand:   execAgentCommand,
+		isAgentUnavailable: isAgentUnavailable,
+		report:             report,
+		initialDelay:       time.Duration(probe.InitialDelaySeconds) * time.Second,
+		timeout:            defaultTimeoutSeconds * time.Second,
+		period:             defaultPeriodSeconds * time.Second,
+		failureThreshold:   defaultFailureThreshold,
+	}
+	if probe.TimeoutSeconds > 0 {
+		p.timeout = time.Duration(probe.TimeoutSeconds) * time.Second
+	}
+	if probe.PeriodSeconds > 0 {
+		p.period = time.Duration(probe.PeriodSeconds) * time.Second
+	}
+	if probe.FailureThreshold > 0 {
+		p.failureThreshold = int(probe.FailureThreshold)
+	}
+	return p
+}
+
+// Run evaluates the probe every period until it succeeds, fails
+// failureThreshold times in a row or stop is closed.
+func (p *Prober) Run(stop <-chan struct{}) {
+	var last *api.StartupProbeStatus
+	report := func(status api.StartupProbeStatus) {
+		if last == nil || !reflect.DeepEqual(*last, status) {
+			last = &status
+			p.report(status)
+		}
+	}
+
+	report(api.StartupProbeStatus{
+		Reason:  v1.VirtualMachineInstanceReasonStartupProbePending,
+		Message: "The startup probe did not run yet",
+	})
+	if !wait(p.initialDelay, stop) {
+		return
+	}
+
+	for failures := 1; ; failures++ {
+		err := p.probeOnce()
+		if err == nil {
+			report(api.StartupProbeStatus{Succeeded: true})
+			return
+		}
+		log.Log.Reason(err).V(3).Infof("Startup probe failed %d time(s)", failures)
+
+		if failures >= p.failureThreshold {
+			report(api.StartupProbeStatus{
+				Reason:  v1.VirtualMachineInstanceReasonStartupProbeFailed,
+				Message: fmt.Sprintf("The startup probe failed %d times, last error: %v", failures, err),
+			})
+			return
+		}
+		reason := v1.VirtualMachineInstanceReasonStartupProbePending
+		if p.isAgentUnavailable(err) {
+			reason = v1.VirtualMachineInstanceReasonGuestAgentUnavailable
+		}
+		report(api.StartupProbeStatus{Reason: reason, Message: err.Error()})
+
+		if !wait(p.period, stop) {
+			return
+		}
+	}
+}
+
+func wait(d time.Duration, stop <-chan struct{}) bool {
+	select {
+	case <-stop:
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// probeOnce evaluates the probe, failing if it did not complete within the
+// timeout of the probe.
+func (p *Prober) probeOnce() error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() {
+		switch {
+		case p.probe.GuestAgentPing != nil:
+			result <- p.ping()
+		case p.probe.Exec != nil:
+			result <- p.exec(ctx, p.probe.Exec.Command)
+		default:
+			result <- fmt.Errorf("the startup probe has neither guestAgentPing nor exec set")
+		}
+	}()
+
+	select {
+	case err := <-result:
+		if err == nil || ctx.Err() == nil {
+			return err
+		}
+	case <-ctx.Done():
+	}
+	return fmt.Errorf("the startup probe timed out after %v", p.timeout)
+}
+
+func (p *Prober) ping() error {
+	_, err := p.execAgentCommand(`{"execute":"guest-ping"}`)
+	return err
+}
+
+// exec runs command in the guest and waits for it to exit with 0.
+func (p *Prober) exec(ctx context.Context, command []string) error {
+	if len(command) == 0 {
+		return fmt.Errorf("the exec startup probe has no command")
+	}
+	cmdExec, err := json.Marshal(map[string]interface{}{
+		"execute": "guest-exec",
+		"arguments": map[string]interface{}{
+			"path":           command[0],
+			"arg":            command[1:],
+			"capture-output": true,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	output, err := p.execAgentCommand(string(cmdExec))
+	if err != nil {
+		return err
+	}
+	execRes := &execReturn{}
+	if err := json.Unmarshal([]byte(output), execRes); err != nil {
+		return err
+	}
+	if execRes.Return.Pid <= 0 {
+		return fmt.Errorf("invalid pid %d returned by the guest agent for %v: %s", execRes.Return.Pid, command, output)
+	}
+
+	cmdExecStatus := fmt.Sprintf(`{"execute":"guest-exec-status","arguments":{"pid":%d}}`, execRes.Return.Pid)
+	for {
+		output, err := p.execAgentCommand(cmdExecStatus)
+		if err != nil {
+			return err
+		}
+		execStatusRes := &execStatusReturn{}
+		if err := json.Unmarshal([]byte(output), execStatusRes); err != nil {
+			return err
+		}
+		if execStatusRes.Return.Exited {
+			if execStatusRes.Return.ExitCode == 0 {
+				return nil
+			}
+			return fmt.Errorf("command %v exited with %d%s", command, execStatusRes.Return.ExitCode, errDataSuffix(execStatusRes.Return.ErrData))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(execStatusPollInterval):
+		}
+	}
+}
+
+func errDataSuffix(errData string) string {
+	stderr, err := base64.StdEncoding.DecodeString(errData)
+	if err != nil {
+		return ""
+	}
+	tail := strings.TrimSpace(string(stderr))
+	if tail == "" {
+		return ""
+	}
+	if len(tail) > maxErrDataBytes {
+		tail = tail[len(tail)-maxErrDataBytes:]
+	}
+	return fmt.Sprintf(": %s", tail)
+}
@@ -0,0 +1,13 @@
+package startupprobe_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestStartupProbe(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "StartupProbe Suite")
+}
@@ -0,0 +1,239 @@
+/*
+ * This file is part of the KubeVirt project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ *
+ */
+
+package startupprobe
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	k8sv1 "k8s.io/api/core/v1"
+
+	v1 "kubevirt.io/client-go/api/v1"
+	"kubevirt.io/kubevirt/pkg/virt-launcher/virtwrap/api"
+)
+
+var _ = Describe("Startup probe", func() {
+	var errAgentUnavailable = fmt.Errorf("QEMU guest agent is not connected")
+
+	// the commands of timed out probes may still run while the next ones start
+	var lock sync.Mutex
+	var agentCommands []string
+	var agentReply func(command string, calls int) (string, error)
+	var statuses []api.StartupProbeStatus
+
+	sentCommands := func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]string(nil), agentCommands...)
+	}
+
+	replyWith := func(reply func(command string, calls int) (string, error)) {
+		lock.Lock()
+		defer lock.Unlock()
+		agentReply = reply
+	}
+
+	newProber := func(probe *v1.Probe) *Prober {
+		p := NewProber(probe,
+			func(command string) (string, error) {
+				lock.Lock()
+				agentCommands = append(agentCommands, command)
+				calls := len(agentCommands)
+				reply := agentReply
+				lock.Unlock()
+				return reply(command, calls)
+			},
+			func(err error) bool {
+				return err == errAgentUnavailable
+			},
+			func(status api.StartupProbeStatus) {
+				statuses = append(statuses, status)
+			})
+		p.period = 10 * time.Millisecond
+		return p
+	}
+
+	pingProbe := func(failureThreshold int32) *v1.Probe {
+		return &v1.Probe{
+			Handler:          v1.Handler{GuestAgentPing: &v1.GuestAgentPing{}},
+			FailureThreshold: failureThreshold,
+		}
+	}
+
+	execProbe := func(command ...string) *v1.Probe {
+		return &v1.Probe{
+			Handler:          v1.Handler{Exec: &k8sv1.ExecAction{Command: command}},
+			FailureThreshold: 1,
+		}
+	}
+
+	lastStatus := func() api.StartupProbeStatus {
+		Expect(statuses).ToNot(BeEmpty())
+		return statuses[len(statuses)-1]
+	}
+
+	BeforeEach(func() {
+		lock.Lock()
+		defer lock.Unlock()
+		agentCommands = nil
+		statuses = nil
+	})
+
+	It("should derive its timings from the probe", func() {
+		p := NewProber(&v1.Probe{InitialDelaySeconds: 5, TimeoutSeconds: 2, PeriodSeconds: 3, FailureThreshold: 4}, nil, nil, nil)
+		Expect(p.initialDelay).To(Equal(5 * time.Second))
+		Expect(p.timeout).To(Equal(2 * time.Second))
+		Expect(p.period).To(Equal(3 * time.Second))
+		Expect(p.failureThreshold).To(Equal(4))
+
+		p = NewProber(&v1.Probe{}, nil, nil, nil)
+		Expect(p.initialDelay).To(BeZero())
+		Expect(p.timeout).To(Equal(time.Second))
+		Expect(p.period).To(Equal(10 * time.Second))
+		Expect(p.failureThreshold).To(Equal(3))
+	})
+
+	Context("pinging the guest agent", func() {
+		It("should succeed once the agent answers", func() {
+			replyWith(func(string, int) (string, error) {
+				return `{"return":{}}`, nil
+			})
+			newProber(pingProbe(3)).Run(make(chan struct{}))
+			Expect(sentCommands()).To(Equal([]string{`{"execute":"guest-ping"}`}))
+			Expect(statuses).To(Equal([]api.StartupProbeStatus{
+				{Reason: v1.VirtualMachineInstanceReasonStartupProbePending, Message: "The startup probe did not run yet"},
+				{Succeeded: true},
+			}))
+		})
+
+		It("should report the agent as unavailable until it connects", func() {
+			replyWith(func(_ string, calls int) (string, error) {
+				if calls < 3 {
+					return "", errAgentUnavailable
+				}
+				return `{"return":{}}`, nil
+			})
+			newProber(pingProbe(5)).Run(make(chan struct{}))
+			Expect(statuses).To(HaveLen(3))
+			Expect(statuses[1]).To(Equal(api.StartupProbeStatus{
+				Reason:  v1.VirtualMachineInstanceReasonGuestAgentUnavailable,
+				Message: errAgentUnavailable.Error(),
+			}))
+			Expect(lastStatus().Succeeded).To(BeTrue())
+		})
+
+		It("should fail once the agent was unavailable failureThreshold times", func() {
+			replyWith(func(string, int) (string, error) {
+				return "", errAgentUnavailable
+			})
+			newProber(pingProbe(3)).Run(make(chan struct{}))
+			Expect(sentCommands()).To(HaveLen(3))
+			Expect(lastStatus().Succeeded).To(BeFalse())
+			Expect(lastStatus().Reason).To(Equal(v1.VirtualMachineInstanceReasonStartupProbeFailed))
+			Expect(lastStatus().Message).To(ContainSubstring("failed 3 times"))
+			Expect(lastStatus().Message).To(ContainSubstring(errAgentUnavailable.Error()))
+		})
+
+		It("should fail if the agent does not answer within the timeout", func() {
+			block := make(chan struct{})
+			defer close(block)
+			replyWith(func(string, int) (string, error) {
+				<-block
+				return `{"return":{}}`, nil
+			})
+			p := newProber(pingProbe(2))
+			p.timeout = 20 * time.Millisecond
+			p.Run(make(chan struct{}))
+			Expect(lastStatus().Reason).To(Equal(v1.VirtualMachineInstanceReasonStartupProbeFailed))
+			Expect(lastStatus().Message).To(ContainSubstring("timed out"))
+		})
+
+		It("should stop during the initial delay", func() {
+			p := newProber(&v1.Probe{Handler: v1.Handler{GuestAgentPing: &v1.GuestAgentPing{}}, InitialDelaySeconds: 60})
+			stop := make(chan struct{})
+			close(stop)
+			p.Run(stop)
+			Expect(sentCommands()).To(BeEmpty())
+			Expect(statuses).To(HaveLen(1))
+		})
+	})
+
+	Context("executing a command in the guest", func() {
+		execStatus := func(exited bool, exitCode int, stderr string) string {
+			return fmt.Sprintf(`{"return":{"exited":%t,"exitcode":%d,"err-data":"%s"}}`, exited, exitCode, base64.StdEncoding.EncodeToString([]byte(stderr)))
+		}
+
+		It("should succeed once the command exited with 0", func() {
+			polls := 0
+			replyWith(func(command string, _ int) (string, error) {
+				if strings.Contains(command, "guest-exec-status") {
+					polls++
+					return execStatus(polls > 1, 0, ""), nil
+				}
+				return `{"return":{"pid":42}}`, nil
+			})
+			newProber(execProbe("systemctl", "is-active", "sshd")).Run(make(chan struct{}))
+			commands := sentCommands()
+			Expect(commands).To(HaveLen(3))
+			Expect(commands[0]).To(MatchJSON(`{"execute":"guest-exec","arguments":{"path":"systemctl","arg":["is-active","sshd"],"capture-output":true}}`))
+			Expect(commands[1]).To(MatchJSON(`{"execute":"guest-exec-status","arguments":{"pid":42}}`))
+			Expect(lastStatus().Succeeded).To(BeTrue())
+		})
+
+		It("should fail if the command exited with another code", func() {
+			replyWith(func(command string, _ int) (string, error) {
+				if strings.Contains(command, "guest-exec-status") {
+					return execStatus(true, 3, "inactive\n"), nil
+				}
+				return `{"return":{"pid":42}}`, nil
+			})
+			newProber(execProbe("systemctl", "is-active", "sshd")).Run(make(chan struct{}))
+			Expect(lastStatus().Reason).To(Equal(v1.VirtualMachineInstanceReasonStartupProbeFailed))
+			Expect(lastStatus().Message).To(ContainSubstring("exited with 3: inactive"))
+		})
+
+		It("should fail if the command did not exit within the timeout", func() {
+			replyWith(func(command string, _ int) (string, error) {
+				if strings.Contains(command, "guest-exec-status") {
+					return execStatus(false, 0, ""), nil
+				}
+				return `{"return":{"pid":42}}`, nil
+			})
+			p := newProber(execProbe("sleep", "60"))
+			p.timeout = 300 * time.Millisecond
+			p.Run(make(chan struct{}))
+			Expect(lastStatus().Reason).To(Equal(v1.VirtualMachineInstanceReasonStartupProbeFailed))
+			Expect(lastStatus().Message).To(ContainSubstring("timed out"))
+		})
+
+		It("should fail if the agent returns an invalid pid", func() {
+			replyWith(func(string, int) (string, error) {
+				return `{"return":{"pid":0}}`, nil
+			})
+			newProber(execProbe("true")).Run(make(chan struct{}))
+			Expect(lastStatus().Message).To(ContainSubstring("invalid pid 0"))
+		})
+	})
+})
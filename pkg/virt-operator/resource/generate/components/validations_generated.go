@@ -260,6 +260,10 @@ var CRDsValidation map[string]string = map[string]string{
               - type: string
               pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
               x-kubernetes-int-or-string: true
+            customSELinuxLauncherTypes:
+              items:
+                type: string
+              type: array
             developerConfiguration:
               description: DeveloperConfiguration holds developer options
               properties:
@@ -288,6 +292,8 @@ var CRDsValidation map[string]string = map[string]string{
                     virtOperator:
                       type: integer
                   type: object
+                maxMemoryOvercommit:
+                  type: integer
                 memoryOvercommit:
                   type: integer
                 nodeSelectors:
@@ -303,11 +309,18 @@ var CRDsValidation map[string]string = map[string]string{
               items:
                 type: string
               type: array
+            guestAgentCommandAllowList:
+              items:
+                type: string
+              type: array
             imagePullPolicy:
               description: PullPolicy describes a policy for if/when to pull a container image
               type: string
             machineType:
               type: string
+            maxDrainGracePeriodSeconds:
+              format: int64
+              type: integer
             memBalloonStatsPeriod:
               format: int32
               type: integer
@@ -327,6 +340,9 @@ var CRDsValidation map[string]string = map[string]string{
                 completionTimeoutPerGiB:
                   format: int64
                   type: integer
+                maxParallelMigrationThreads:
+                  format: int32
+                  type: integer
                 nodeDrainTaintKey:
                   type: string
                 parallelMigrationsPerCluster:
@@ -346,6 +362,26 @@ var CRDsValidation map[string]string = map[string]string{
               properties:
                 defaultNetworkInterface:
                   type: string
+                macAddressPools:
+                  description: MacAddressPools interfaces can draw their MAC address from.
+                  items:
+                    description: MacAddressPool is a range of MAC addresses handed out to the interfaces requesting it.
+                    properties:
+                      name:
+                        description: Name of the pool, referenced by the macAddressPool of interfaces.
+                        type: string
+                      rangeEnd:
+                        description: 'Last MAC address of the pool. For example: 02:00:00:00:ff:ff.'
+                        type: string
+                      rangeStart:
+                        description: 'First MAC address of the pool. For example: 02:00:00:00:00:00.'
+                        type: string
+                    required:
+                    - name
+                    - rangeEnd
+                    - rangeStart
+                    type: object
+                  type: array
                 permitBridgeInterfaceOnPodNetwork:
                   type: boolean
                 permitSlirpInterface:
@@ -391,6 +427,17 @@ var CRDsValidation map[string]string = map[string]string{
               type: object
             selinuxLauncherType:
               type: string
+            selinuxRelabelParallelism:
+              format: int32
+              type: integer
+            selinuxRelabelSkipPaths:
+              description: SELinuxRelabelSkipPaths are the paths virt-handler never relabels, e.g. tmpfs backed devices. Entries with glob characters are matched as globs, the others as path prefixes.
+              items:
+                type: string
+              type: array
+            selinuxRelabelTimeoutSeconds:
+              format: int64
+              type: integer
             smbios:
               properties:
                 family:
@@ -1509,6 +1556,19 @@ var CRDsValidation map[string]string = map[string]string{
         running:
           description: Running controls whether the associatied VirtualMachineInstance is created or not Mutually exclusive with RunStrategy
           type: boolean
+        schedule:
+          description: Schedule starts and stops the VirtualMachine at the given times, by switching its RunStrategy between Always and Halted.
+          properties:
+            start:
+              description: Start is the cron schedule, in the five field format "minute hour day-of-month month day-of-week", at which the VirtualMachine is started.
+              type: string
+            stop:
+              description: Stop is the cron schedule, in the same format as Start, at which the VirtualMachine is stopped.
+              type: string
+            timeZone:
+              description: TimeZone is the IANA name of the time zone the schedules are evaluated in. Defaults to UTC.
+              type: string
+          type: object
         template:
           description: Template is the direct specification of VirtualMachineInstance
           properties:
@@ -1966,6 +2026,31 @@ var CRDsValidation map[string]string = map[string]string{
                 domain:
                   description: Specification of the desired behavior of the VirtualMachineInstance on the host.
                   properties:
+                    boot:
+                      description: Boot configures the devices the guest boots from and the order in which the firmware tries them.
+                      properties:
+                        networkBootLast:
+                          description: NetworkBootLast boots from the network (PXE) through the interfaces without a boot order, after all the other boot devices were tried.
+                          type: boolean
+                        order:
+                          description: Order lists the disks and interfaces to boot from. The firmware tries them in this order and falls back to the next one if a device is not bootable. It can't be combined with bootOrder on the disks and interfaces.
+                          items:
+                            description: BootDevice references a disk or an interface to boot from. Exactly one of its fields has to be set.
+                            properties:
+                              disk:
+                                description: Disk is the name of the disk to boot from.
+                                type: string
+                              interface:
+                                description: Interface is the name of the interface to boot from.
+                                type: string
+                            type: object
+                          type: array
+                          x-kubernetes-list-type: atomic
+                        rebootTimeoutSeconds:
+                          description: RebootTimeoutSeconds makes the firmware retry to boot after this many seconds if none of the boot devices is bootable, instead of halting. Only supported with BIOS.
+                          format: int32
+                          type: integer
+                      type: object
                     chassis:
                       description: Chassis specifies the chassis info passed to the domain.
                       properties:
@@ -1989,6 +2074,22 @@ var CRDsValidation map[string]string = map[string]string{
                             hpet:
                               description: HPET (High Precision Event Timer) - multiple timers with periodic interrupts.
                               properties:
+                                catchup:
+                                  description: Catchup tunes the catchup tick policy, only valid with it.
+                                  properties:
+                                    limit:
+                                      description: Limit is the number of missed ticks beyond which the timer stops catching up.
+                                      format: int32
+                                      type: integer
+                                    slew:
+                                      description: Slew is the number of missed ticks injected at a higher rate at once.
+                                      format: int32
+                                      type: integer
+                                    threshold:
+                                      description: Threshold is the number of missed ticks from which the timer catches up.
+                                      format: int32
+                                      type: integer
+                                  type: object
                                 present:
                                   description: Enabled set to false makes sure that the machine type or a preset can't add the timer. Defaults to true.
                                   type: boolean
@@ -2013,6 +2114,22 @@ var CRDsValidation map[string]string = map[string]string{
                             pit:
                               description: PIT (Programmable Interval Timer) - a timer with periodic interrupts.
                               properties:
+                                catchup:
+                                  description: Catchup tunes the catchup tick policy, only valid with it.
+                                  properties:
+                                    limit:
+                                      description: Limit is the number of missed ticks beyond which the timer stops catching up.
+                                      format: int32
+                                      type: integer
+                                    slew:
+                                      description: Slew is the number of missed ticks injected at a higher rate at once.
+                                      format: int32
+                                      type: integer
+                                    threshold:
+                                      description: Threshold is the number of missed ticks from which the timer catches up.
+                                      format: int32
+                                      type: integer
+                                  type: object
                                 present:
                                   description: Enabled set to false makes sure that the machine type or a preset can't add the timer. Defaults to true.
                                   type: boolean
@@ -2023,6 +2140,22 @@ var CRDsValidation map[string]string = map[string]string{
                             rtc:
                               description: RTC (Real Time Clock) - a continuously running timer with periodic interrupts.
                               properties:
+                                catchup:
+                                  description: Catchup tunes the catchup tick policy, only valid with it.
+                                  properties:
+                                    limit:
+                                      description: Limit is the number of missed ticks beyond which the timer stops catching up.
+                                      format: int32
+                                      type: integer
+                                    slew:
+                                      description: Slew is the number of missed ticks injected at a higher rate at once.
+                                      format: int32
+                                      type: integer
+                                    threshold:
+                                      description: Threshold is the number of missed ticks from which the timer catches up.
+                                      format: int32
+                                      type: integer
+                                  type: object
                                 present:
                                   description: Enabled set to false makes sure that the machine type or a preset can't add the timer. Defaults to true.
                                   type: boolean
@@ -2088,6 +2221,10 @@ var CRDsValidation map[string]string = map[string]string{
                     devices:
                       description: Devices allows adding disks, network interfaces, and others
                       properties:
+                        agentConnectTimeoutSeconds:
+                          description: AgentConnectTimeoutSeconds is how long the operations requiring the guest agent, like freezing the guest filesystems or reading the guest OS info, wait for it to connect before failing. They fail right away if the guest agent isn't connected and no timeout is set. Must be between 0 and 600.
+                          format: int32
+                          type: integer
                         autoattachGraphicsDevice:
                           description: Whether to attach the default graphics device or not. VNC will not be available if set to false. Defaults to true.
                           type: boolean
@@ -2158,6 +2295,39 @@ var CRDsValidation map[string]string = map[string]string{
                               io:
                                 description: 'IO specifies which QEMU disk IO mode should be used. Supported values are: native, default, threads.'
                                 type: string
+                              ioThrottleGroup:
+                                description: IOThrottleGroup makes the disk share the I/O limits of the group with all other disks of the vmi in the same group.
+                                properties:
+                                  name:
+                                    description: Name of the throttle group.
+                                    type: string
+                                  readBytesPerSec:
+                                    description: ReadBytesPerSec limits the read bytes per second.
+                                    format: int64
+                                    type: integer
+                                  readIOPS:
+                                    description: ReadIOPS limits the read operations per second.
+                                    format: int64
+                                    type: integer
+                                  totalBytesPerSec:
+                                    description: TotalBytesPerSec limits the read and written bytes per second. Can't be combined with ReadBytesPerSec or WriteBytesPerSec.
+                                    format: int64
+                                    type: integer
+                                  totalIOPS:
+                                    description: TotalIOPS limits the read and write operations per second. Can't be combined with ReadIOPS or WriteIOPS.
+                                    format: int64
+                                    type: integer
+                                  writeBytesPerSec:
+                                    description: WriteBytesPerSec limits the written bytes per second.
+                                    format: int64
+                                    type: integer
+                                  writeIOPS:
+                                    description: WriteIOPS limits the write operations per second.
+                                    format: int64
+                                    type: integer
+                                required:
+                                - name
+                                type: object
                               lun:
                                 description: Attach a volume as a LUN to the vmi.
                                 properties:
@@ -2177,6 +2347,9 @@ var CRDsValidation map[string]string = map[string]string{
                               tag:
                                 description: If specified, disk address and its tag will be provided to the guest via config drive metadata
                                 type: string
+                              wwn:
+                                description: WWN provides the ability to specify the World Wide Name of the disk device, made up of 16 hexadecimal digits. Only supported on the scsi bus.
+                                type: string
                             required:
                             - name
                             type: object
@@ -2190,6 +2363,13 @@ var CRDsValidation map[string]string = map[string]string{
                                 type: string
                               virtiofs:
                                 description: Virtiofs is supported
+                                properties:
+                                  cache:
+                                    description: 'Cache selects how virtiofsd caches the shared data and metadata. Supported values: none, auto, always. Defaults to none.'
+                                    type: string
+                                  sandbox:
+                                    description: 'Sandbox selects how virtiofsd isolates itself from the rest of the host. Supported values: namespace, chroot. Defaults to namespace.'
+                                    type: string
                                 type: object
                             required:
                             - name
@@ -2221,6 +2401,27 @@ var CRDsValidation map[string]string = map[string]string{
                                 type: string
                               name:
                                 type: string
+                              pciAddress:
+                                description: 'If specified, the host device will be placed on the guests pci address with the specified PCI address. For example: 0000:81:01.1'
+                                type: string
+                              rom:
+                                description: If specified, the ROM image of the referenced config map or secret is exposed to the guest instead of the ROM of the host device. Only supported by PCI host devices.
+                                properties:
+                                  configMap:
+                                    description: ConfigMap references a config map holding the ROM image as binary data.
+                                    properties:
+                                      name:
+                                        description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                        type: string
+                                    type: object
+                                  secret:
+                                    description: Secret references a secret holding the ROM image.
+                                    properties:
+                                      name:
+                                        description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                        type: string
+                                    type: object
+                                type: object
                             required:
                             - deviceName
                             - name
@@ -2288,6 +2489,9 @@ var CRDsValidation map[string]string = map[string]string{
                               macAddress:
                                 description: 'Interface MAC address. For example: de:ad:00:00:be:af or DE-AD-00-00-BE-AF.'
                                 type: string
+                              macAddressPool:
+                                description: Name of a MAC address pool configured in the KubeVirt CR, to draw the MAC address of the interface from if macAddress is not set.
+                                type: string
                               macvtap:
                                 type: object
                               masquerade:
@@ -2295,6 +2499,9 @@ var CRDsValidation map[string]string = map[string]string{
                               model:
                                 description: 'Interface model. One of: e1000, e1000e, ne2k_pci, pcnet, rtl8139, virtio. Defaults to virtio. TODO:(ihar) switch to enums once opengen-api supports them. See: https://github.com/kubernetes/kube-openapi/issues/51'
                                 type: string
+                              mtu:
+                                description: MTU of the interface in the guest, for example 9000 on jumbo-frame networks. Must not exceed the MTU of the pod network, which it defaults to. Only supported with the bridge and masquerade bindings.
+                                type: integer
                               name:
                                 description: Logical name of the interface as well as a reference to the associated networks. Must match the Name of a Network.
                                 type: string
@@ -2527,11 +2734,43 @@ var CRDsValidation map[string]string = map[string]string{
                                 secureBoot:
                                   description: If set, SecureBoot will be enabled and the OVMF roms will be swapped for SecureBoot-enabled ones. Requires SMM to be enabled. Defaults to true
                                   type: boolean
+                                secureBootCertificates:
+                                  description: If set, the certificates of the referenced secrets are enrolled in the Secure Boot variable store instead of the default keys of the firmware. Requires SecureBoot.
+                                  properties:
+                                    db:
+                                      description: DB references the secret holding the certificates of the allowed signature database.
+                                      properties:
+                                        name:
+                                          description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                          type: string
+                                      type: object
+                                    kek:
+                                      description: KEK references the secret holding the Key Exchange Keys.
+                                      properties:
+                                        name:
+                                          description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                          type: string
+                                      type: object
+                                    pk:
+                                      description: PK references the secret holding the Platform Key, a single certificate.
+                                      properties:
+                                        name:
+                                          description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                          type: string
+                                      type: object
+                                  type: object
                               type: object
                           type: object
                         serial:
                           description: The system-serial-number in SMBIOS
                           type: string
+                        smbiosSecretRef:
+                          description: SMBiosSecretRef references a k8s secret in the namespace of the vmi that contains SMBIOS system information. The keys manufacturer and product set the matching SMBIOS system fields and the key oemStrings holds one SMBIOS OEM string per line. Missing keys are ignored.
+                          properties:
+                            name:
+                              description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                              type: string
+                          type: object
                         uuid:
                           description: UUID reported by the vmi bios. Defaults to a random generated uid.
                           type: string
@@ -2551,6 +2790,13 @@ var CRDsValidation map[string]string = map[string]string{
                     memory:
                       description: Memory allow specifying the VMI memory features.
                       properties:
+                        balloonFloor:
+                          anyOf:
+                          - type: integer
+                          - type: string
+                          description: BalloonFloor is the least amount of memory left to the Guest OS when virt-launcher reclaims memory through the memory balloon, because the memory of the pod comes under pressure. Memory is only reclaimed if set, which requires the memory balloon device.
+                          pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                          x-kubernetes-int-or-string: true
                         guest:
                           anyOf:
                           - type: integer
@@ -2564,8 +2810,44 @@ var CRDsValidation map[string]string = map[string]string{
                             pageSize:
                               description: PageSize specifies the hugepage size, for x86_64 architecture valid values are 1Gi and 2Mi.
                               type: string
+                            perNUMANode:
+                              description: PerNUMANode splits the guest memory into guest NUMA nodes, each backed by the hugepages of a host NUMA node. The memory of all nodes has to add up to the guest memory, the vCPUs are spread evenly across the nodes.
+                              items:
+                                description: NUMANodeHugepages places the hugepages of a guest NUMA node on a host NUMA node.
+                                properties:
+                                  guestNode:
+                                    description: GuestNode is the ID of the guest NUMA node. The guest nodes are numbered from 0 without gaps.
+                                    format: int32
+                                    type: integer
+                                  hostNode:
+                                    description: HostNode is the ID of the host NUMA node the hugepages are allocated on.
+                                    format: int32
+                                    type: integer
+                                  memory:
+                                    anyOf:
+                                    - type: integer
+                                    - type: string
+                                    description: Memory is the guest memory of the node, a multiple of the page size.
+                                    pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                                    x-kubernetes-int-or-string: true
+                                required:
+                                - guestNode
+                                - hostNode
+                                - memory
+                                type: object
+                              type: array
                           type: object
+                        locked:
+                          description: Locked locks the guest memory in host memory, so that it is never swapped out or reclaimed, e.g. for latency sensitive or confidential workloads. virt-handler raises the memory lock limit of the pod accordingly, the locked memory still counts against its memory limit.
+                          type: boolean
+                        overcommitPercent:
+                          description: OvercommitPercent is the percentage of the memory requested for the pod the guest is given, e.g. 150 requests two thirds of the guest memory. It replaces the memoryOvercommit of the cluster for the vmi and is limited to its maxMemoryOvercommit. It requires the guest memory, which the memory request is computed from.
+                          format: int32
+                          type: integer
                       type: object
+                    onCrash:
+                      description: 'OnCrash is the action taken when the guest crashes, e.g. on a kernel panic. One of: destroy, restart, preserve. Defaults to destroy.'
+                      type: string
                     resources:
                       description: Resources describes the Compute Resources required by this vmi.
                       properties:
@@ -2591,22 +2873,81 @@ var CRDsValidation map[string]string = map[string]string{
                           description: Requests is a description of the initial vmi resources. Valid resource keys are "memory" and "cpu".
                           type: object
                       type: object
+                    selinux:
+                      description: SELinux configures the SELinux context of the virt-launcher running the domain.
+                      properties:
+                        launcherType:
+                          description: LauncherType is the SELinux type the virt-launcher runs with, in place of the cluster wide launcher type. The VMI is only scheduled on nodes advertising this type as available.
+                          type: string
+                      type: object
                   required:
                   - devices
                   type: object
                 evictionStrategy:
-                  description: EvictionStrategy can be set to "LiveMigrate" if the VirtualMachineInstance should be migrated instead of shut-off in case of a node drain.
+                  description: EvictionStrategy can be set to "LiveMigrate" if the VirtualMachineInstance should be migrated instead of shut-off in case of a node drain, or to "ProtectUntilDrained" if it should be shut down gracefully once its node is drained.
                   type: string
+                freezeHooks:
+                  description: Commands run in the guest through the guest agent around the freeze of its filesystems, e.g. to flush and quiesce applications before the disks are snapshotted.
+                  properties:
+                    postThaw:
+                      description: PostThaw is run after the guest filesystems are thawed again
+                      properties:
+                        command:
+                          description: Command is the command line to execute in the guest, starting with the absolute path of the executable. It is not run in a shell.
+                          items:
+                            type: string
+                          type: array
+                        failurePolicy:
+                          description: 'FailurePolicy tells what happens when the hook fails or times out: Abort fails the freeze, respectively the thaw, Continue only logs the failure. Defaults to Abort.'
+                          type: string
+                        timeoutSeconds:
+                          description: Number of seconds after which the hook is considered failed. Defaults to 10 seconds. Minimum value is 1, maximum value is 15.
+                          format: int32
+                          type: integer
+                      required:
+                      - command
+                      type: object
+                    preFreeze:
+                      description: PreFreeze is run before the guest filesystems are frozen
+                      properties:
+                        command:
+                          description: Command is the command line to execute in the guest, starting with the absolute path of the executable. It is not run in a shell.
+                          items:
+                            type: string
+                          type: array
+                        failurePolicy:
+                          description: 'FailurePolicy tells what happens when the hook fails or times out: Abort fails the freeze, respectively the thaw, Continue only logs the failure. Defaults to Abort.'
+                          type: string
+                        timeoutSeconds:
+                          description: Number of seconds after which the hook is considered failed. Defaults to 10 seconds. Minimum value is 1, maximum value is 15.
+                          format: int32
+                          type: integer
+                      required:
+                      - command
+                      type: object
+                  type: object
                 hostname:
                   description: Specifies the hostname of the vmi If not specified, the hostname will be set to the name of the vmi, if dhcp or cloud-init is configured properly.
                   type: string
                 livenessProbe:
                   description: 'Periodic probe of VirtualMachineInstance liveness. VirtualmachineInstances will be stopped if the probe fails. Cannot be updated. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes'
                   properties:
+                    exec:
+                      description: Exec specifies a command run in the guest through the guest agent, the probe succeeds if the command exits with 0. Only supported by startup probes.
+                      properties:
+                        command:
+                          description: Command is the command line to execute inside the container, the working directory for the command  is root ('/') in the container's filesystem. The command is simply exec'd, it is not run inside a shell, so traditional shell instructions ('|', etc) won't work. To use a shell, you need to explicitly call out to that shell. Exit status of 0 is treated as live/healthy and non-zero is unhealthy.
+                          items:
+                            type: string
+                          type: array
+                      type: object
                     failureThreshold:
                       description: Minimum consecutive failures for the probe to be considered failed after having succeeded. Defaults to 3. Minimum value is 1.
                       format: int32
                       type: integer
+                    guestAgentPing:
+                      description: GuestAgentPing contacts the guest agent, the probe succeeds if it answers. Only supported by startup probes.
+                      type: object
                     httpGet:
                       description: HTTPGet specifies the http request to perform.
                       properties:
@@ -2676,6 +3017,9 @@ var CRDsValidation map[string]string = map[string]string{
                       format: int32
                       type: integer
                   type: object
+                livenessProbeFailureAction:
+                  description: Action taken once the liveness probe failed. Restart, the default, stops the VirtualMachineInstance. Migrate live migrates it off its node instead, or stops it if it is not live migratable. Migrate requires a livenessProbe and no readinessProbe, the liveness probe then drives the readiness of the VirtualMachineInstance. Cannot be updated.
+                  type: string
                 networks:
                   description: List of networks that can be attached to a vm's virtual interface.
                   items:
@@ -2718,10 +3062,22 @@ var CRDsValidation map[string]string = map[string]string{
                 readinessProbe:
                   description: 'Periodic probe of VirtualMachineInstance service readiness. VirtualmachineInstances will be removed from service endpoints if the probe fails. Cannot be updated. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes'
                   properties:
+                    exec:
+                      description: Exec specifies a command run in the guest through the guest agent, the probe succeeds if the command exits with 0. Only supported by startup probes.
+                      properties:
+                        command:
+                          description: Command is the command line to execute inside the container, the working directory for the command  is root ('/') in the container's filesystem. The command is simply exec'd, it is not run inside a shell, so traditional shell instructions ('|', etc) won't work. To use a shell, you need to explicitly call out to that shell. Exit status of 0 is treated as live/healthy and non-zero is unhealthy.
+                          items:
+                            type: string
+                          type: array
+                      type: object
                     failureThreshold:
                       description: Minimum consecutive failures for the probe to be considered failed after having succeeded. Defaults to 3. Minimum value is 1.
                       format: int32
                       type: integer
+                    guestAgentPing:
+                      description: GuestAgentPing contacts the guest agent, the probe succeeds if it answers. Only supported by startup probes.
+                      type: object
                     httpGet:
                       description: HTTPGet specifies the http request to perform.
                       properties:
@@ -2794,62 +3150,150 @@ var CRDsValidation map[string]string = map[string]string{
                 schedulerName:
                   description: If specified, the VMI will be dispatched by specified scheduler. If not specified, the VMI will be dispatched by default scheduler.
                   type: string
-                subdomain:
-                  description: If specified, the fully qualified vmi hostname will be "<hostname>.<subdomain>.<pod namespace>.svc.<cluster domain>". If not specified, the vmi will not have a domainname at all. The DNS entry will resolve to the vmi, no matter if the vmi itself can pick up a hostname.
-                  type: string
-                terminationGracePeriodSeconds:
-                  description: Grace period observed after signalling a VirtualMachineInstance to stop after which the VirtualMachineInstance is force terminated.
-                  format: int64
-                  type: integer
-                tolerations:
-                  description: If toleration is specified, obey all the toleration rules.
-                  items:
-                    description: The pod this Toleration is attached to tolerates any taint that matches the triple <key,value,effect> using the matching operator <operator>.
-                    properties:
-                      effect:
-                        description: Effect indicates the taint effect to match. Empty means match all taint effects. When specified, allowed values are NoSchedule, PreferNoSchedule and NoExecute.
-                        type: string
-                      key:
-                        description: Key is the taint key that the toleration applies to. Empty means match all taint keys. If the key is empty, operator must be Exists; this combination means to match all values and all keys.
-                        type: string
-                      operator:
-                        description: Operator represents a key's relationship to the value. Valid operators are Exists and Equal. Defaults to Equal. Exists is equivalent to wildcard for value, so that a pod can tolerate all taints of a particular category.
-                        type: string
-                      tolerationSeconds:
-                        description: TolerationSeconds represents the period of time the toleration (which must be of effect NoExecute, otherwise this field is ignored) tolerates the taint. By default, it is not set, which means tolerate the taint forever (do not evict). Zero and negative values will be treated as 0 (evict immediately) by the system.
-                        format: int64
-                        type: integer
-                      value:
-                        description: Value is the taint value the toleration matches to. If the operator is Exists, the value should be empty, otherwise just a regular string.
-                        type: string
-                    type: object
-                  type: array
-                volumes:
-                  description: List of volumes that can be mounted by disks belonging to the vmi.
-                  items:
-                    description: Volume represents a named volume in a vmi.
-                    properties:
-                      cloudInitConfigDrive:
-                        description: 'CloudInitConfigDrive represents a cloud-init Config Drive user-data source. The Config Drive data will be added as a disk to the vmi. A proper cloud-init installation is required inside the guest. More info: https://cloudinit.readthedocs.io/en/latest/topics/datasources/configdrive.html'
-                        properties:
-                          networkData:
-                            description: NetworkData contains config drive inline cloud-init networkdata.
-                            type: string
-                          networkDataBase64:
-                            description: NetworkDataBase64 contains config drive cloud-init networkdata as a base64 encoded string.
+                startupProbe:
+                  description: Probe of the VirtualMachineInstance startup, evaluated through the guest agent. The VirtualMachineInstance is not ready until the probe succeeded. Only guestAgentPing and exec probes are supported. Cannot be updated.
+                  properties:
+                    exec:
+                      description: Exec specifies a command run in the guest through the guest agent, the probe succeeds if the command exits with 0. Only supported by startup probes.
+                      properties:
+                        command:
+                          description: Command is the command line to execute inside the container, the working directory for the command  is root ('/') in the container's filesystem. The command is simply exec'd, it is not run inside a shell, so traditional shell instructions ('|', etc) won't work. To use a shell, you need to explicitly call out to that shell. Exit status of 0 is treated as live/healthy and non-zero is unhealthy.
+                          items:
                             type: string
-                          networkDataSecretRef:
-                            description: NetworkDataSecretRef references a k8s secret that contains config drive networkdata.
+                          type: array
+                      type: object
+                    failureThreshold:
+                      description: Minimum consecutive failures for the probe to be considered failed after having succeeded. Defaults to 3. Minimum value is 1.
+                      format: int32
+                      type: integer
+                    guestAgentPing:
+                      description: GuestAgentPing contacts the guest agent, the probe succeeds if it answers. Only supported by startup probes.
+                      type: object
+                    httpGet:
+                      description: HTTPGet specifies the http request to perform.
+                      properties:
+                        host:
+                          description: Host name to connect to, defaults to the pod IP. You probably want to set "Host" in httpHeaders instead.
+                          type: string
+                        httpHeaders:
+                          description: Custom headers to set in the request. HTTP allows repeated headers.
+                          items:
+                            description: HTTPHeader describes a custom header to be used in HTTP probes
                             properties:
                               name:
-                                description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                description: The header field name
                                 type: string
-                            type: object
-                          secretRef:
-                            description: UserDataSecretRef references a k8s secret that contains config drive userdata.
-                            properties:
-                              name:
-                                description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                              value:
+                                description: The header field value
+                                type: string
+                            required:
+                            - name
+                            - value
+                            type: object
+                          type: array
+                        path:
+                          description: Path to access on the HTTP server.
+                          type: string
+                        port:
+                          anyOf:
+                          - type: integer
+                          - type: string
+                          description: Name or number of the port to access on the container. Number must be in the range 1 to 65535. Name must be an IANA_SVC_NAME.
+                          x-kubernetes-int-or-string: true
+                        scheme:
+                          description: Scheme to use for connecting to the host. Defaults to HTTP.
+                          type: string
+                      required:
+                      - port
+                      type: object
+                    initialDelaySeconds:
+                      description: 'Number of seconds after the VirtualMachineInstance has started before liveness probes are initiated. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes'
+                      format: int32
+                      type: integer
+                    periodSeconds:
+                      description: How often (in seconds) to perform the probe. Default to 10 seconds. Minimum value is 1.
+                      format: int32
+                      type: integer
+                    successThreshold:
+                      description: Minimum consecutive successes for the probe to be considered successful after having failed. Defaults to 1. Must be 1 for liveness. Minimum value is 1.
+                      format: int32
+                      type: integer
+                    tcpSocket:
+                      description: 'TCPSocket specifies an action involving a TCP port. TCP hooks not yet supported TODO: implement a realistic TCP lifecycle hook'
+                      properties:
+                        host:
+                          description: 'Optional: Host name to connect to, defaults to the pod IP.'
+                          type: string
+                        port:
+                          anyOf:
+                          - type: integer
+                          - type: string
+                          description: Number or name of the port to access on the container. Number must be in the range 1 to 65535. Name must be an IANA_SVC_NAME.
+                          x-kubernetes-int-or-string: true
+                      required:
+                      - port
+                      type: object
+                    timeoutSeconds:
+                      description: 'Number of seconds after which the probe times out. Defaults to 1 second. Minimum value is 1. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes'
+                      format: int32
+                      type: integer
+                  type: object
+                subdomain:
+                  description: If specified, the fully qualified vmi hostname will be "<hostname>.<subdomain>.<pod namespace>.svc.<cluster domain>". If not specified, the vmi will not have a domainname at all. The DNS entry will resolve to the vmi, no matter if the vmi itself can pick up a hostname.
+                  type: string
+                terminationGracePeriodSeconds:
+                  description: Grace period observed after signalling a VirtualMachineInstance to stop after which the VirtualMachineInstance is force terminated.
+                  format: int64
+                  type: integer
+                tolerations:
+                  description: If toleration is specified, obey all the toleration rules.
+                  items:
+                    description: The pod this Toleration is attached to tolerates any taint that matches the triple <key,value,effect> using the matching operator <operator>.
+                    properties:
+                      effect:
+                        description: Effect indicates the taint effect to match. Empty means match all taint effects. When specified, allowed values are NoSchedule, PreferNoSchedule and NoExecute.
+                        type: string
+                      key:
+                        description: Key is the taint key that the toleration applies to. Empty means match all taint keys. If the key is empty, operator must be Exists; this combination means to match all values and all keys.
+                        type: string
+                      operator:
+                        description: Operator represents a key's relationship to the value. Valid operators are Exists and Equal. Defaults to Equal. Exists is equivalent to wildcard for value, so that a pod can tolerate all taints of a particular category.
+                        type: string
+                      tolerationSeconds:
+                        description: TolerationSeconds represents the period of time the toleration (which must be of effect NoExecute, otherwise this field is ignored) tolerates the taint. By default, it is not set, which means tolerate the taint forever (do not evict). Zero and negative values will be treated as 0 (evict immediately) by the system.
+                        format: int64
+                        type: integer
+                      value:
+                        description: Value is the taint value the toleration matches to. If the operator is Exists, the value should be empty, otherwise just a regular string.
+                        type: string
+                    type: object
+                  type: array
+                volumes:
+                  description: List of volumes that can be mounted by disks belonging to the vmi.
+                  items:
+                    description: Volume represents a named volume in a vmi.
+                    properties:
+                      cloudInitConfigDrive:
+                        description: 'CloudInitConfigDrive represents a cloud-init Config Drive user-data source. The Config Drive data will be added as a disk to the vmi. A proper cloud-init installation is required inside the guest. More info: https://cloudinit.readthedocs.io/en/latest/topics/datasources/configdrive.html'
+                        properties:
+                          networkData:
+                            description: NetworkData contains config drive inline cloud-init networkdata.
+                            type: string
+                          networkDataBase64:
+                            description: NetworkDataBase64 contains config drive cloud-init networkdata as a base64 encoded string.
+                            type: string
+                          networkDataSecretRef:
+                            description: NetworkDataSecretRef references a k8s secret that contains config drive networkdata.
+                            properties:
+                              name:
+                                description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                type: string
+                            type: object
+                          secretRef:
+                            description: UserDataSecretRef references a k8s secret that contains config drive userdata.
+                            properties:
+                              name:
+                                description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
                                 type: string
                             type: object
                           userData:
@@ -3074,6 +3518,9 @@ var CRDsValidation map[string]string = map[string]string{
                     - name
                     type: object
                   type: array
+                workloadClass:
+                  description: WorkloadClass groups the VMIs to spread across nodes. The pods of the VMIs of the same class in a namespace prefer not to be scheduled on the same node. It has to be a DNS-1123 label.
+                  type: string
               required:
               - domain
               type: object
@@ -3113,6 +3560,11 @@ var CRDsValidation map[string]string = map[string]string{
         created:
           description: Created indicates if the virtual machine is created in the cluster
           type: boolean
+        lastScheduledTransition:
+          description: LastScheduledTransition is the time of the latest start or stop of the schedule handled by the controller.
+          format: date-time
+          nullable: true
+          type: string
         ready:
           description: Ready indicates if the virtual machine is running and ready
           type: boolean
@@ -3196,6 +3648,39 @@ var CRDsValidation map[string]string = map[string]string{
                       io:
                         description: 'IO specifies which QEMU disk IO mode should be used. Supported values are: native, default, threads.'
                         type: string
+                      ioThrottleGroup:
+                        description: IOThrottleGroup makes the disk share the I/O limits of the group with all other disks of the vmi in the same group.
+                        properties:
+                          name:
+                            description: Name of the throttle group.
+                            type: string
+                          readBytesPerSec:
+                            description: ReadBytesPerSec limits the read bytes per second.
+                            format: int64
+                            type: integer
+                          readIOPS:
+                            description: ReadIOPS limits the read operations per second.
+                            format: int64
+                            type: integer
+                          totalBytesPerSec:
+                            description: TotalBytesPerSec limits the read and written bytes per second. Can't be combined with ReadBytesPerSec or WriteBytesPerSec.
+                            format: int64
+                            type: integer
+                          totalIOPS:
+                            description: TotalIOPS limits the read and write operations per second. Can't be combined with ReadIOPS or WriteIOPS.
+                            format: int64
+                            type: integer
+                          writeBytesPerSec:
+                            description: WriteBytesPerSec limits the written bytes per second.
+                            format: int64
+                            type: integer
+                          writeIOPS:
+                            description: WriteIOPS limits the write operations per second.
+                            format: int64
+                            type: integer
+                        required:
+                        - name
+                        type: object
                       lun:
                         description: Attach a volume as a LUN to the vmi.
                         properties:
@@ -3215,6 +3700,9 @@ var CRDsValidation map[string]string = map[string]string{
                       tag:
                         description: If specified, disk address and its tag will be provided to the guest via config drive metadata
                         type: string
+                      wwn:
+                        description: WWN provides the ability to specify the World Wide Name of the disk device, made up of 16 hexadecimal digits. Only supported on the scsi bus.
+                        type: string
                     required:
                     - name
                     type: object
@@ -3747,6 +4235,31 @@ var CRDsValidation map[string]string = map[string]string{
         domain:
           description: Specification of the desired behavior of the VirtualMachineInstance on the host.
           properties:
+            boot:
+              description: Boot configures the devices the guest boots from and the order in which the firmware tries them.
+              properties:
+                networkBootLast:
+                  description: NetworkBootLast boots from the network (PXE) through the interfaces without a boot order, after all the other boot devices were tried.
+                  type: boolean
+                order:
+                  description: Order lists the disks and interfaces to boot from. The firmware tries them in this order and falls back to the next one if a device is not bootable. It can't be combined with bootOrder on the disks and interfaces.
+                  items:
+                    description: BootDevice references a disk or an interface to boot from. Exactly one of its fields has to be set.
+                    properties:
+                      disk:
+                        description: Disk is the name of the disk to boot from.
+                        type: string
+                      interface:
+                        description: Interface is the name of the interface to boot from.
+                        type: string
+                    type: object
+                  type: array
+                  x-kubernetes-list-type: atomic
+                rebootTimeoutSeconds:
+                  description: RebootTimeoutSeconds makes the firmware retry to boot after this many seconds if none of the boot devices is bootable, instead of halting. Only supported with BIOS.
+                  format: int32
+                  type: integer
+              type: object
             chassis:
               description: Chassis specifies the chassis info passed to the domain.
               properties:
@@ -3770,6 +4283,22 @@ var CRDsValidation map[string]string = map[string]string{
                     hpet:
                       description: HPET (High Precision Event Timer) - multiple timers with periodic interrupts.
                       properties:
+                        catchup:
+                          description: Catchup tunes the catchup tick policy, only valid with it.
+                          properties:
+                            limit:
+                              description: Limit is the number of missed ticks beyond which the timer stops catching up.
+                              format: int32
+                              type: integer
+                            slew:
+                              description: Slew is the number of missed ticks injected at a higher rate at once.
+                              format: int32
+                              type: integer
+                            threshold:
+                              description: Threshold is the number of missed ticks from which the timer catches up.
+                              format: int32
+                              type: integer
+                          type: object
                         present:
                           description: Enabled set to false makes sure that the machine type or a preset can't add the timer. Defaults to true.
                           type: boolean
@@ -3794,6 +4323,22 @@ var CRDsValidation map[string]string = map[string]string{
                     pit:
                       description: PIT (Programmable Interval Timer) - a timer with periodic interrupts.
                       properties:
+                        catchup:
+                          description: Catchup tunes the catchup tick policy, only valid with it.
+                          properties:
+                            limit:
+                              description: Limit is the number of missed ticks beyond which the timer stops catching up.
+                              format: int32
+                              type: integer
+                            slew:
+                              description: Slew is the number of missed ticks injected at a higher rate at once.
+                              format: int32
+                              type: integer
+                            threshold:
+                              description: Threshold is the number of missed ticks from which the timer catches up.
+                              format: int32
+                              type: integer
+                          type: object
                         present:
                           description: Enabled set to false makes sure that the machine type or a preset can't add the timer. Defaults to true.
                           type: boolean
@@ -3804,6 +4349,22 @@ var CRDsValidation map[string]string = map[string]string{
                     rtc:
                       description: RTC (Real Time Clock) - a continuously running timer with periodic interrupts.
                       properties:
+                        catchup:
+                          description: Catchup tunes the catchup tick policy, only valid with it.
+                          properties:
+                            limit:
+                              description: Limit is the number of missed ticks beyond which the timer stops catching up.
+                              format: int32
+                              type: integer
+                            slew:
+                              description: Slew is the number of missed ticks injected at a higher rate at once.
+                              format: int32
+                              type: integer
+                            threshold:
+                              description: Threshold is the number of missed ticks from which the timer catches up.
+                              format: int32
+                              type: integer
+                          type: object
                         present:
                           description: Enabled set to false makes sure that the machine type or a preset can't add the timer. Defaults to true.
                           type: boolean
@@ -3869,6 +4430,10 @@ var CRDsValidation map[string]string = map[string]string{
             devices:
               description: Devices allows adding disks, network interfaces, and others
               properties:
+                agentConnectTimeoutSeconds:
+                  description: AgentConnectTimeoutSeconds is how long the operations requiring the guest agent, like freezing the guest filesystems or reading the guest OS info, wait for it to connect before failing. They fail right away if the guest agent isn't connected and no timeout is set. Must be between 0 and 600.
+                  format: int32
+                  type: integer
                 autoattachGraphicsDevice:
                   description: Whether to attach the default graphics device or not. VNC will not be available if set to false. Defaults to true.
                   type: boolean
@@ -3939,6 +4504,39 @@ var CRDsValidation map[string]string = map[string]string{
                       io:
                         description: 'IO specifies which QEMU disk IO mode should be used. Supported values are: native, default, threads.'
                         type: string
+                      ioThrottleGroup:
+                        description: IOThrottleGroup makes the disk share the I/O limits of the group with all other disks of the vmi in the same group.
+                        properties:
+                          name:
+                            description: Name of the throttle group.
+                            type: string
+                          readBytesPerSec:
+                            description: ReadBytesPerSec limits the read bytes per second.
+                            format: int64
+                            type: integer
+                          readIOPS:
+                            description: ReadIOPS limits the read operations per second.
+                            format: int64
+                            type: integer
+                          totalBytesPerSec:
+                            description: TotalBytesPerSec limits the read and written bytes per second. Can't be combined with ReadBytesPerSec or WriteBytesPerSec.
+                            format: int64
+                            type: integer
+                          totalIOPS:
+                            description: TotalIOPS limits the read and write operations per second. Can't be combined with ReadIOPS or WriteIOPS.
+                            format: int64
+                            type: integer
+                          writeBytesPerSec:
+                            description: WriteBytesPerSec limits the written bytes per second.
+                            format: int64
+                            type: integer
+                          writeIOPS:
+                            description: WriteIOPS limits the write operations per second.
+                            format: int64
+                            type: integer
+                        required:
+                        - name
+                        type: object
                       lun:
                         description: Attach a volume as a LUN to the vmi.
                         properties:
@@ -3958,6 +4556,9 @@ var CRDsValidation map[string]string = map[string]string{
                       tag:
                         description: If specified, disk address and its tag will be provided to the guest via config drive metadata
                         type: string
+                      wwn:
+                        description: WWN provides the ability to specify the World Wide Name of the disk device, made up of 16 hexadecimal digits. Only supported on the scsi bus.
+                        type: string
                     required:
                     - name
                     type: object
@@ -3971,6 +4572,13 @@ var CRDsValidation map[string]string = map[string]string{
                         type: string
                       virtiofs:
                         description: Virtiofs is supported
+                        properties:
+                          cache:
+                            description: 'Cache selects how virtiofsd caches the shared data and metadata. Supported values: none, auto, always. Defaults to none.'
+                            type: string
+                          sandbox:
+                            description: 'Sandbox selects how virtiofsd isolates itself from the rest of the host. Supported values: namespace, chroot. Defaults to namespace.'
+                            type: string
                         type: object
                     required:
                     - name
@@ -4002,6 +4610,27 @@ var CRDsValidation map[string]string = map[string]string{
                         type: string
                       name:
                         type: string
+                      pciAddress:
+                        description: 'If specified, the host device will be placed on the guests pci address with the specified PCI address. For example: 0000:81:01.1'
+                        type: string
+                      rom:
+                        description: If specified, the ROM image of the referenced config map or secret is exposed to the guest instead of the ROM of the host device. Only supported by PCI host devices.
+                        properties:
+                          configMap:
+                            description: ConfigMap references a config map holding the ROM image as binary data.
+                            properties:
+                              name:
+                                description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                type: string
+                            type: object
+                          secret:
+                            description: Secret references a secret holding the ROM image.
+                            properties:
+                              name:
+                                description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                type: string
+                            type: object
+                        type: object
                     required:
                     - deviceName
                     - name
@@ -4069,6 +4698,9 @@ var CRDsValidation map[string]string = map[string]string{
                       macAddress:
                         description: 'Interface MAC address. For example: de:ad:00:00:be:af or DE-AD-00-00-BE-AF.'
                         type: string
+                      macAddressPool:
+                        description: Name of a MAC address pool configured in the KubeVirt CR, to draw the MAC address of the interface from if macAddress is not set.
+                        type: string
                       macvtap:
                         type: object
                       masquerade:
@@ -4076,6 +4708,9 @@ var CRDsValidation map[string]string = map[string]string{
                       model:
                         description: 'Interface model. One of: e1000, e1000e, ne2k_pci, pcnet, rtl8139, virtio. Defaults to virtio. TODO:(ihar) switch to enums once opengen-api supports them. See: https://github.com/kubernetes/kube-openapi/issues/51'
                         type: string
+                      mtu:
+                        description: MTU of the interface in the guest, for example 9000 on jumbo-frame networks. Must not exceed the MTU of the pod network, which it defaults to. Only supported with the bridge and masquerade bindings.
+                        type: integer
                       name:
                         description: Logical name of the interface as well as a reference to the associated networks. Must match the Name of a Network.
                         type: string
@@ -4308,11 +4943,43 @@ var CRDsValidation map[string]string = map[string]string{
                         secureBoot:
                           description: If set, SecureBoot will be enabled and the OVMF roms will be swapped for SecureBoot-enabled ones. Requires SMM to be enabled. Defaults to true
                           type: boolean
+                        secureBootCertificates:
+                          description: If set, the certificates of the referenced secrets are enrolled in the Secure Boot variable store instead of the default keys of the firmware. Requires SecureBoot.
+                          properties:
+                            db:
+                              description: DB references the secret holding the certificates of the allowed signature database.
+                              properties:
+                                name:
+                                  description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                  type: string
+                              type: object
+                            kek:
+                              description: KEK references the secret holding the Key Exchange Keys.
+                              properties:
+                                name:
+                                  description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                  type: string
+                              type: object
+                            pk:
+                              description: PK references the secret holding the Platform Key, a single certificate.
+                              properties:
+                                name:
+                                  description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                  type: string
+                              type: object
+                          type: object
                       type: object
                   type: object
                 serial:
                   description: The system-serial-number in SMBIOS
                   type: string
+                smbiosSecretRef:
+                  description: SMBiosSecretRef references a k8s secret in the namespace of the vmi that contains SMBIOS system information. The keys manufacturer and product set the matching SMBIOS system fields and the key oemStrings holds one SMBIOS OEM string per line. Missing keys are ignored.
+                  properties:
+                    name:
+                      description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                      type: string
+                  type: object
                 uuid:
                   description: UUID reported by the vmi bios. Defaults to a random generated uid.
                   type: string
@@ -4332,6 +4999,13 @@ var CRDsValidation map[string]string = map[string]string{
             memory:
               description: Memory allow specifying the VMI memory features.
               properties:
+                balloonFloor:
+                  anyOf:
+                  - type: integer
+                  - type: string
+                  description: BalloonFloor is the least amount of memory left to the Guest OS when virt-launcher reclaims memory through the memory balloon, because the memory of the pod comes under pressure. Memory is only reclaimed if set, which requires the memory balloon device.
+                  pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                  x-kubernetes-int-or-string: true
                 guest:
                   anyOf:
                   - type: integer
@@ -4345,8 +5019,44 @@ var CRDsValidation map[string]string = map[string]string{
                     pageSize:
                       description: PageSize specifies the hugepage size, for x86_64 architecture valid values are 1Gi and 2Mi.
                       type: string
+                    perNUMANode:
+                      description: PerNUMANode splits the guest memory into guest NUMA nodes, each backed by the hugepages of a host NUMA node. The memory of all nodes has to add up to the guest memory, the vCPUs are spread evenly across the nodes.
+                      items:
+                        description: NUMANodeHugepages places the hugepages of a guest NUMA node on a host NUMA node.
+                        properties:
+                          guestNode:
+                            description: GuestNode is the ID of the guest NUMA node. The guest nodes are numbered from 0 without gaps.
+                            format: int32
+                            type: integer
+                          hostNode:
+                            description: HostNode is the ID of the host NUMA node the hugepages are allocated on.
+                            format: int32
+                            type: integer
+                          memory:
+                            anyOf:
+                            - type: integer
+                            - type: string
+                            description: Memory is the guest memory of the node, a multiple of the page size.
+                            pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                            x-kubernetes-int-or-string: true
+                        required:
+                        - guestNode
+                        - hostNode
+                        - memory
+                        type: object
+                      type: array
                   type: object
+                locked:
+                  description: Locked locks the guest memory in host memory, so that it is never swapped out or reclaimed, e.g. for latency sensitive or confidential workloads. virt-handler raises the memory lock limit of the pod accordingly, the locked memory still counts against its memory limit.
+                  type: boolean
+                overcommitPercent:
+                  description: OvercommitPercent is the percentage of the memory requested for the pod the guest is given, e.g. 150 requests two thirds of the guest memory. It replaces the memoryOvercommit of the cluster for the vmi and is limited to its maxMemoryOvercommit. It requires the guest memory, which the memory request is computed from.
+                  format: int32
+                  type: integer
               type: object
+            onCrash:
+              description: 'OnCrash is the action taken when the guest crashes, e.g. on a kernel panic. One of: destroy, restart, preserve. Defaults to destroy.'
+              type: string
             resources:
               description: Resources describes the Compute Resources required by this vmi.
               properties:
@@ -4372,22 +5082,81 @@ var CRDsValidation map[string]string = map[string]string{
                   description: Requests is a description of the initial vmi resources. Valid resource keys are "memory" and "cpu".
                   type: object
               type: object
+            selinux:
+              description: SELinux configures the SELinux context of the virt-launcher running the domain.
+              properties:
+                launcherType:
+                  description: LauncherType is the SELinux type the virt-launcher runs with, in place of the cluster wide launcher type. The VMI is only scheduled on nodes advertising this type as available.
+                  type: string
+              type: object
           required:
           - devices
           type: object
         evictionStrategy:
-          description: EvictionStrategy can be set to "LiveMigrate" if the VirtualMachineInstance should be migrated instead of shut-off in case of a node drain.
+          description: EvictionStrategy can be set to "LiveMigrate" if the VirtualMachineInstance should be migrated instead of shut-off in case of a node drain, or to "ProtectUntilDrained" if it should be shut down gracefully once its node is drained.
           type: string
+        freezeHooks:
+          description: Commands run in the guest through the guest agent around the freeze of its filesystems, e.g. to flush and quiesce applications before the disks are snapshotted.
+          properties:
+            postThaw:
+              description: PostThaw is run after the guest filesystems are thawed again
+              properties:
+                command:
+                  description: Command is the command line to execute in the guest, starting with the absolute path of the executable. It is not run in a shell.
+                  items:
+                    type: string
+                  type: array
+                failurePolicy:
+                  description: 'FailurePolicy tells what happens when the hook fails or times out: Abort fails the freeze, respectively the thaw, Continue only logs the failure. Defaults to Abort.'
+                  type: string
+                timeoutSeconds:
+                  description: Number of seconds after which the hook is considered failed. Defaults to 10 seconds. Minimum value is 1, maximum value is 15.
+                  format: int32
+                  type: integer
+              required:
+              - command
+              type: object
+            preFreeze:
+              description: PreFreeze is run before the guest filesystems are frozen
+              properties:
+                command:
+                  description: Command is the command line to execute in the guest, starting with the absolute path of the executable. It is not run in a shell.
+                  items:
+                    type: string
+                  type: array
+                failurePolicy:
+                  description: 'FailurePolicy tells what happens when the hook fails or times out: Abort fails the freeze, respectively the thaw, Continue only logs the failure. Defaults to Abort.'
+                  type: string
+                timeoutSeconds:
+                  description: Number of seconds after which the hook is considered failed. Defaults to 10 seconds. Minimum value is 1, maximum value is 15.
+                  format: int32
+                  type: integer
+              required:
+              - command
+              type: object
+          type: object
         hostname:
           description: Specifies the hostname of the vmi If not specified, the hostname will be set to the name of the vmi, if dhcp or cloud-init is configured properly.
           type: string
         livenessProbe:
           description: 'Periodic probe of VirtualMachineInstance liveness. VirtualmachineInstances will be stopped if the probe fails. Cannot be updated. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes'
           properties:
+            exec:
+              description: Exec specifies a command run in the guest through the guest agent, the probe succeeds if the command exits with 0. Only supported by startup probes.
+              properties:
+                command:
+                  description: Command is the command line to execute inside the container, the working directory for the command  is root ('/') in the container's filesystem. The command is simply exec'd, it is not run inside a shell, so traditional shell instructions ('|', etc) won't work. To use a shell, you need to explicitly call out to that shell. Exit status of 0 is treated as live/healthy and non-zero is unhealthy.
+                  items:
+                    type: string
+                  type: array
+              type: object
             failureThreshold:
               description: Minimum consecutive failures for the probe to be considered failed after having succeeded. Defaults to 3. Minimum value is 1.
               format: int32
               type: integer
+            guestAgentPing:
+              description: GuestAgentPing contacts the guest agent, the probe succeeds if it answers. Only supported by startup probes.
+              type: object
             httpGet:
               description: HTTPGet specifies the http request to perform.
               properties:
@@ -4457,6 +5226,9 @@ var CRDsValidation map[string]string = map[string]string{
               format: int32
               type: integer
           type: object
+        livenessProbeFailureAction:
+          description: Action taken once the liveness probe failed. Restart, the default, stops the VirtualMachineInstance. Migrate live migrates it off its node instead, or stops it if it is not live migratable. Migrate requires a livenessProbe and no readinessProbe, the liveness probe then drives the readiness of the VirtualMachineInstance. Cannot be updated.
+          type: string
         networks:
           description: List of networks that can be attached to a vm's virtual interface.
           items:
@@ -4499,10 +5271,22 @@ var CRDsValidation map[string]string = map[string]string{
         readinessProbe:
           description: 'Periodic probe of VirtualMachineInstance service readiness. VirtualmachineInstances will be removed from service endpoints if the probe fails. Cannot be updated. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes'
           properties:
+            exec:
+              description: Exec specifies a command run in the guest through the guest agent, the probe succeeds if the command exits with 0. Only supported by startup probes.
+              properties:
+                command:
+                  description: Command is the command line to execute inside the container, the working directory for the command  is root ('/') in the container's filesystem. The command is simply exec'd, it is not run inside a shell, so traditional shell instructions ('|', etc) won't work. To use a shell, you need to explicitly call out to that shell. Exit status of 0 is treated as live/healthy and non-zero is unhealthy.
+                  items:
+                    type: string
+                  type: array
+              type: object
             failureThreshold:
               description: Minimum consecutive failures for the probe to be considered failed after having succeeded. Defaults to 3. Minimum value is 1.
               format: int32
               type: integer
+            guestAgentPing:
+              description: GuestAgentPing contacts the guest agent, the probe succeeds if it answers. Only supported by startup probes.
+              type: object
             httpGet:
               description: HTTPGet specifies the http request to perform.
               properties:
@@ -4575,7 +5359,95 @@ var CRDsValidation map[string]string = map[string]string{
         schedulerName:
           description: If specified, the VMI will be dispatched by specified scheduler. If not specified, the VMI will be dispatched by default scheduler.
           type: string
-        subdomain:
+        startupProbe:
+          description: Probe of the VirtualMachineInstance startup, evaluated through the guest agent. The VirtualMachineInstance is not ready until the probe succeeded. Only guestAgentPing and exec probes are supported. Cannot be updated.
+          properties:
+            exec:
+              description: Exec specifies a command run in the guest through the guest agent, the probe succeeds if the command exits with 0. Only supported by startup probes.
+              properties:
+                command:
+                  description: Command is the command line to execute inside the container, the working directory for the command  is root ('/') in the container's filesystem. The command is simply exec'd, it is not run inside a shell, so traditional shell instructions ('|', etc) won't work. To use a shell, you need to explicitly call out to that shell. Exit status of 0 is treated as live/healthy and non-zero is unhealthy.
+                  items:
+                    type: string
+                  type: array
+              type: object
+            failureThreshold:
+              description: Minimum consecutive failures for the probe to be considered failed after having succeeded. Defaults to 3. Minimum value is 1.
+              format: int32
+              type: integer
+            guestAgentPing:
+              description: GuestAgentPing contacts the guest agent, the probe succeeds if it answers. Only supported by startup probes.
+              type: object
+            httpGet:
+              description: HTTPGet specifies the http request to perform.
+              properties:
+                host:
+                  description: Host name to connect to, defaults to the pod IP. You probably want to set "Host" in httpHeaders instead.
+                  type: string
+                httpHeaders:
+                  description: Custom headers to set in the request. HTTP allows repeated headers.
+                  items:
+                    description: HTTPHeader describes a custom header to be used in HTTP probes
+                    properties:
+                      name:
+                        description: The header field name
+                        type: string
+                      value:
+                        description: The header field value
+                        type: string
+                    required:
+                    - name
+                    - value
+                    type: object
+                  type: array
+                path:
+                  description: Path to access on the HTTP server.
+                  type: string
+                port:
+                  anyOf:
+                  - type: integer
+                  - type: string
+                  description: Name or number of the port to access on the container. Number must be in the range 1 to 65535. Name must be an IANA_SVC_NAME.
+                  x-kubernetes-int-or-string: true
+                scheme:
+                  description: Scheme to use for connecting to the host. Defaults to HTTP.
+                  type: string
+              required:
+              - port
+              type: object
+            initialDelaySeconds:
+              description: 'Number of seconds after the VirtualMachineInstance has started before liveness probes are initiated. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes'
+              format: int32
+              type: integer
+            periodSeconds:
+              description: How often (in seconds) to perform the probe. Default to 10 seconds. Minimum value is 1.
+              format: int32
+              type: integer
+            successThreshold:
+              description: Minimum consecutive successes for the probe to be considered successful after having failed. Defaults to 1. Must be 1 for liveness. Minimum value is 1.
+              format: int32
+              type: integer
+            tcpSocket:
+              description: 'TCPSocket specifies an action involving a TCP port. TCP hooks not yet supported TODO: implement a realistic TCP lifecycle hook'
+              properties:
+                host:
+                  description: 'Optional: Host name to connect to, defaults to the pod IP.'
+                  type: string
+                port:
+                  anyOf:
+                  - type: integer
+                  - type: string
+                  description: Number or name of the port to access on the container. Number must be in the range 1 to 65535. Name must be an IANA_SVC_NAME.
+                  x-kubernetes-int-or-string: true
+              required:
+              - port
+              type: object
+            timeoutSeconds:
+              description: 'Number of seconds after which the probe times out. Defaults to 1 second. Minimum value is 1. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes'
+              format: int32
+              type: integer
+          type: object
+        subdomain:
           description: If specified, the fully qualified vmi hostname will be "<hostname>.<subdomain>.<pod namespace>.svc.<cluster domain>". If not specified, the vmi will not have a domainname at all. The DNS entry will resolve to the vmi, no matter if the vmi itself can pick up a hostname.
           type: string
         terminationGracePeriodSeconds:
@@ -4855,6 +5727,9 @@ var CRDsValidation map[string]string = map[string]string{
             - name
             type: object
           type: array
+        workloadClass:
+          description: WorkloadClass groups the VMIs to spread across nodes. The pods of the VMIs of the same class in a namespace prefer not to be scheduled on the same node. It has to be a DNS-1123 label.
+          type: string
       required:
       - domain
       type: object
@@ -4891,6 +5766,10 @@ var CRDsValidation map[string]string = map[string]string{
             - type
             type: object
           type: array
+        drainGracePeriodSeconds:
+          description: DrainGracePeriodSeconds is the effective grace period observed by virt-launcher before the VirtualMachineInstance is killed when its pod is terminated, for instance during a node drain.
+          format: int64
+          type: integer
         evacuationNodeName:
           description: EvacuationNodeName is used to track the eviction process of a VMI. It stores the name of the node that we want to evacuate. It is meant to be used by KubeVirt core components only and can't be set or modified by users.
           type: string
@@ -4945,6 +5824,96 @@ var CRDsValidation map[string]string = map[string]string{
                 type: string
             type: object
           type: array
+        lastSoftReboot:
+          description: LastSoftReboot describes the last soft reboot requested through the softreboot subresource
+          properties:
+            mechanism:
+              description: Mechanism is the way the reboot was signaled to the guest, GuestAgent or ACPI
+              type: string
+            timestamp:
+              description: Timestamp is the time the reboot was signaled
+              format: date-time
+              nullable: true
+              type: string
+          required:
+          - mechanism
+          type: object
+        memoryDump:
+          description: MemoryDump describes the last memory dump requested through the memorydump subresource
+          properties:
+            bytesTotal:
+              description: BytesTotal is the amount of guest memory to write to the claim
+              format: int64
+              type: integer
+            bytesWritten:
+              description: BytesWritten is the amount of guest memory written to the claim so far
+              format: int64
+              type: integer
+            claimName:
+              description: ClaimName is the name of the PersistentVolumeClaim the dump is written to
+              type: string
+            endTimestamp:
+              description: EndTimestamp is the time the dump completed or failed
+              format: date-time
+              type: string
+            fileName:
+              description: FileName is the name of the dump file, at the root of the claim
+              type: string
+            message:
+              description: Message is a human readable description of the failure of the dump
+              type: string
+            phase:
+              description: Phase is the phase of the dump
+              type: string
+            startTimestamp:
+              description: StartTimestamp is the time the dump was requested
+              format: date-time
+              type: string
+            targetPodName:
+              description: TargetPodName is the name of the pod attaching the claim to the node of the VirtualMachineInstance
+              type: string
+            targetPodUID:
+              description: TargetPodUID is the UID of the pod attaching the claim, set once the claim is attached to the node
+              type: string
+          required:
+          - claimName
+          - fileName
+          - phase
+          type: object
+        memoryOvercommit:
+          description: MemoryOvercommit describes the memory requested for a VirtualMachineInstance with an overcommit percentage
+          properties:
+            guestMemory:
+              anyOf:
+              - type: integer
+              - type: string
+              description: GuestMemory is the memory of the guest
+              pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+              x-kubernetes-int-or-string: true
+            memoryRequest:
+              anyOf:
+              - type: integer
+              - type: string
+              description: MemoryRequest is the memory requested for the guest, its memory scaled down by the overcommit percentage
+              pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+              x-kubernetes-int-or-string: true
+            percent:
+              description: Percent is the overcommit percentage applied to the guest memory
+              format: int32
+              type: integer
+            podMemoryRequest:
+              anyOf:
+              - type: integer
+              - type: string
+              description: PodMemoryRequest is the memory requested by the compute container of the pod, including the overhead of virt-launcher
+              pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+              x-kubernetes-int-or-string: true
+          required:
+          - guestMemory
+          - memoryRequest
+          - percent
+          - podMemoryRequest
+          type: object
         migrationMethod:
           description: 'Represents the method using which the vmi can be migrated: live migration or block migration'
           type: string
@@ -4957,9 +5926,19 @@ var CRDsValidation map[string]string = map[string]string{
             abortStatus:
               description: Indicates the final status of the live migration abortion
               type: string
+            bandwidth:
+              anyOf:
+              - type: integer
+              - type: string
+              description: The bandwidth the migration is allowed to use, per second
+              pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+              x-kubernetes-int-or-string: true
             completed:
               description: Indicates the migration completed
               type: boolean
+            cutoverRequested:
+              description: Indicates that the cutover of a warm migration has been requested
+              type: boolean
             endTimestamp:
               description: The time the migration action ended
               format: date-time
@@ -4974,6 +5953,10 @@ var CRDsValidation map[string]string = map[string]string{
             mode:
               description: Lets us know if the vmi is currently running pre or post copy migration
               type: string
+            parallelMigrationThreads:
+              description: The number of parallel connections migrating the memory
+              format: int32
+              type: integer
             sourceNode:
               description: The source node that the VMI originated on
               type: string
@@ -4999,6 +5982,60 @@ var CRDsValidation map[string]string = map[string]string{
             targetPod:
               description: The target pod that the VMI is moving to
               type: string
+            targetVCPUPinning:
+              description: The pinning of the dedicated vCPUs, as rebalanced along the NUMA layout of the target node once the migration completed
+              items:
+                description: VCPUPin is the host CPU a vCPU of the vmi is pinned to
+                properties:
+                  cpu:
+                    description: The host CPU the vCPU is pinned to
+                    type: integer
+                  vcpu:
+                    description: The vCPU of the vmi
+                    format: int32
+                    type: integer
+                required:
+                - cpu
+                - vcpu
+                type: object
+              type: array
+              x-kubernetes-list-type: atomic
+            warmup:
+              description: Indicates that the migration pre-copies the memory without cutting over until the cutover is requested
+              type: boolean
+            warmupStatus:
+              description: The convergence of the warmup, as reported by the source node
+              nullable: true
+              properties:
+                converging:
+                  description: Indicates that the memory is transferred faster than it is dirtied
+                  type: boolean
+                dirtyRateBytesPerSecond:
+                  description: The rate the guest dirties its memory at
+                  format: int64
+                  type: integer
+                estimatedDowntimeMilliseconds:
+                  description: The time the vmi is expected to be paused for if the cutover is requested now, at the current transfer rate
+                  format: int64
+                  type: integer
+                iteration:
+                  description: The number of pre-copy passes over the memory of the vmi
+                  format: int64
+                  type: integer
+                lastUpdateTimestamp:
+                  description: The time the estimates were last reported
+                  format: date-time
+                  nullable: true
+                  type: string
+                memoryRemainingBytes:
+                  description: The memory still to be transferred to the target
+                  format: int64
+                  type: integer
+                transferRateBytesPerSecond:
+                  description: The rate the memory is transferred to the target at
+                  format: int64
+                  type: integer
+              type: object
           type: object
         nodeName:
           description: NodeName is the name where the VirtualMachineInstance is currently running.
@@ -5066,13 +6103,37 @@ var CRDsValidation map[string]string = map[string]string{
       type: object
     spec:
       properties:
+        bandwidth:
+          anyOf:
+          - type: integer
+          - type: string
+          description: Bandwidth is the bandwidth the migration may use at most, per second. It is limited to the bandwidthPerMigration of the cluster, which is used if unset.
+          pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+          x-kubernetes-int-or-string: true
+        commitCutover:
+          description: CommitCutover ends the warmup of a warm migration and lets it complete. It can only be set on migrations with warmup, and not be unset.
+          type: boolean
+        parallelMigrationThreads:
+          description: ParallelMigrationThreads is the number of parallel connections migrating the memory (multifd). It is limited to the maxParallelMigrationThreads of the cluster. The memory is migrated over a single connection if unset.
+          format: int32
+          type: integer
         vmiName:
           description: The name of the VMI to perform the migration on. VMI must exist in the migration objects namespace
           type: string
+        warmup:
+          description: Warmup pre-copies the memory of the VMI to the target without cutting over, so that the cutover pauses the VMI for less time. The migration stays in the WarmingUp phase until commitCutover is set.
+          type: boolean
       type: object
     status:
       description: VirtualMachineInstanceMigration reprents information pertaining to a VMI's migration.
       properties:
+        bandwidth:
+          anyOf:
+          - type: integer
+          - type: string
+          description: The bandwidth the migration is allowed to use, per second, once the limits of the cluster were applied
+          pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+          x-kubernetes-int-or-string: true
         conditions:
           items:
             properties:
@@ -5097,9 +6158,45 @@ var CRDsValidation map[string]string = map[string]string{
             - type
             type: object
           type: array
+        parallelMigrationThreads:
+          description: The number of parallel connections migrating the memory, once the limits of the cluster were applied
+          format: int32
+          type: integer
         phase:
           description: VirtualMachineInstanceMigrationPhase is a label for the condition of a VirtualMachineInstanceMigration at the current time.
           type: string
+        warmup:
+          description: The convergence estimates of the warmup of a warm migration
+          properties:
+            converging:
+              description: Indicates that the memory is transferred faster than it is dirtied
+              type: boolean
+            dirtyRateBytesPerSecond:
+              description: The rate the guest dirties its memory at
+              format: int64
+              type: integer
+            estimatedDowntimeMilliseconds:
+              description: The time the vmi is expected to be paused for if the cutover is requested now, at the current transfer rate
+              format: int64
+              type: integer
+            iteration:
+              description: The number of pre-copy passes over the memory of the vmi
+              format: int64
+              type: integer
+            lastUpdateTimestamp:
+              description: The time the estimates were last reported
+              format: date-time
+              nullable: true
+              type: string
+            memoryRemainingBytes:
+              description: The memory still to be transferred to the target
+              format: int64
+              type: integer
+            transferRateBytesPerSecond:
+              description: The rate the memory is transferred to the target at
+              format: int64
+              type: integer
+          type: object
       type: object
   required:
   - spec
@@ -5121,6 +6218,31 @@ var CRDsValidation map[string]string = map[string]string{
         domain:
           description: Domain is the same object type as contained in VirtualMachineInstanceSpec
           properties:
+            boot:
+              description: Boot configures the devices the guest boots from and the order in which the firmware tries them.
+              properties:
+                networkBootLast:
+                  description: NetworkBootLast boots from the network (PXE) through the interfaces without a boot order, after all the other boot devices were tried.
+                  type: boolean
+                order:
+                  description: Order lists the disks and interfaces to boot from. The firmware tries them in this order and falls back to the next one if a device is not bootable. It can't be combined with bootOrder on the disks and interfaces.
+                  items:
+                    description: BootDevice references a disk or an interface to boot from. Exactly one of its fields has to be set.
+                    properties:
+                      disk:
+                        description: Disk is the name of the disk to boot from.
+                        type: string
+                      interface:
+                        description: Interface is the name of the interface to boot from.
+                        type: string
+                    type: object
+                  type: array
+                  x-kubernetes-list-type: atomic
+                rebootTimeoutSeconds:
+                  description: RebootTimeoutSeconds makes the firmware retry to boot after this many seconds if none of the boot devices is bootable, instead of halting. Only supported with BIOS.
+                  format: int32
+                  type: integer
+              type: object
             chassis:
               description: Chassis specifies the chassis info passed to the domain.
               properties:
@@ -5144,6 +6266,22 @@ var CRDsValidation map[string]string = map[string]string{
                     hpet:
                       description: HPET (High Precision Event Timer) - multiple timers with periodic interrupts.
                       properties:
+                        catchup:
+                          description: Catchup tunes the catchup tick policy, only valid with it.
+                          properties:
+                            limit:
+                              description: Limit is the number of missed ticks beyond which the timer stops catching up.
+                              format: int32
+                              type: integer
+                            slew:
+                              description: Slew is the number of missed ticks injected at a higher rate at once.
+                              format: int32
+                              type: integer
+                            threshold:
+                              description: Threshold is the number of missed ticks from which the timer catches up.
+                              format: int32
+                              type: integer
+                          type: object
                         present:
                           description: Enabled set to false makes sure that the machine type or a preset can't add the timer. Defaults to true.
                           type: boolean
@@ -5168,6 +6306,22 @@ var CRDsValidation map[string]string = map[string]string{
                     pit:
                       description: PIT (Programmable Interval Timer) - a timer with periodic interrupts.
                       properties:
+                        catchup:
+                          description: Catchup tunes the catchup tick policy, only valid with it.
+                          properties:
+                            limit:
+                              description: Limit is the number of missed ticks beyond which the timer stops catching up.
+                              format: int32
+                              type: integer
+                            slew:
+                              description: Slew is the number of missed ticks injected at a higher rate at once.
+                              format: int32
+                              type: integer
+                            threshold:
+                              description: Threshold is the number of missed ticks from which the timer catches up.
+                              format: int32
+                              type: integer
+                          type: object
                         present:
                           description: Enabled set to false makes sure that the machine type or a preset can't add the timer. Defaults to true.
                           type: boolean
@@ -5178,6 +6332,22 @@ var CRDsValidation map[string]string = map[string]string{
                     rtc:
                       description: RTC (Real Time Clock) - a continuously running timer with periodic interrupts.
                       properties:
+                        catchup:
+                          description: Catchup tunes the catchup tick policy, only valid with it.
+                          properties:
+                            limit:
+                              description: Limit is the number of missed ticks beyond which the timer stops catching up.
+                              format: int32
+                              type: integer
+                            slew:
+                              description: Slew is the number of missed ticks injected at a higher rate at once.
+                              format: int32
+                              type: integer
+                            threshold:
+                              description: Threshold is the number of missed ticks from which the timer catches up.
+                              format: int32
+                              type: integer
+                          type: object
                         present:
                           description: Enabled set to false makes sure that the machine type or a preset can't add the timer. Defaults to true.
                           type: boolean
@@ -5243,6 +6413,10 @@ var CRDsValidation map[string]string = map[string]string{
             devices:
               description: Devices allows adding disks, network interfaces, and others
               properties:
+                agentConnectTimeoutSeconds:
+                  description: AgentConnectTimeoutSeconds is how long the operations requiring the guest agent, like freezing the guest filesystems or reading the guest OS info, wait for it to connect before failing. They fail right away if the guest agent isn't connected and no timeout is set. Must be between 0 and 600.
+                  format: int32
+                  type: integer
                 autoattachGraphicsDevice:
                   description: Whether to attach the default graphics device or not. VNC will not be available if set to false. Defaults to true.
                   type: boolean
@@ -5313,6 +6487,39 @@ var CRDsValidation map[string]string = map[string]string{
                       io:
                         description: 'IO specifies which QEMU disk IO mode should be used. Supported values are: native, default, threads.'
                         type: string
+                      ioThrottleGroup:
+                        description: IOThrottleGroup makes the disk share the I/O limits of the group with all other disks of the vmi in the same group.
+                        properties:
+                          name:
+                            description: Name of the throttle group.
+                            type: string
+                          readBytesPerSec:
+                            description: ReadBytesPerSec limits the read bytes per second.
+                            format: int64
+                            type: integer
+                          readIOPS:
+                            description: ReadIOPS limits the read operations per second.
+                            format: int64
+                            type: integer
+                          totalBytesPerSec:
+                            description: TotalBytesPerSec limits the read and written bytes per second. Can't be combined with ReadBytesPerSec or WriteBytesPerSec.
+                            format: int64
+                            type: integer
+                          totalIOPS:
+                            description: TotalIOPS limits the read and write operations per second. Can't be combined with ReadIOPS or WriteIOPS.
+                            format: int64
+                            type: integer
+                          writeBytesPerSec:
+                            description: WriteBytesPerSec limits the written bytes per second.
+                            format: int64
+                            type: integer
+                          writeIOPS:
+                            description: WriteIOPS limits the write operations per second.
+                            format: int64
+                            type: integer
+                        required:
+                        - name
+                        type: object
                       lun:
                         description: Attach a volume as a LUN to the vmi.
                         properties:
@@ -5332,6 +6539,9 @@ var CRDsValidation map[string]string = map[string]string{
                       tag:
                         description: If specified, disk address and its tag will be provided to the guest via config drive metadata
                         type: string
+                      wwn:
+                        description: WWN provides the ability to specify the World Wide Name of the disk device, made up of 16 hexadecimal digits. Only supported on the scsi bus.
+                        type: string
                     required:
                     - name
                     type: object
@@ -5345,6 +6555,13 @@ var CRDsValidation map[string]string = map[string]string{
                         type: string
                       virtiofs:
                         description: Virtiofs is supported
+                        properties:
+                          cache:
+                            description: 'Cache selects how virtiofsd caches the shared data and metadata. Supported values: none, auto, always. Defaults to none.'
+                            type: string
+                          sandbox:
+                            description: 'Sandbox selects how virtiofsd isolates itself from the rest of the host. Supported values: namespace, chroot. Defaults to namespace.'
+                            type: string
                         type: object
                     required:
                     - name
@@ -5376,6 +6593,27 @@ var CRDsValidation map[string]string = map[string]string{
                         type: string
                       name:
                         type: string
+                      pciAddress:
+                        description: 'If specified, the host device will be placed on the guests pci address with the specified PCI address. For example: 0000:81:01.1'
+                        type: string
+                      rom:
+                        description: If specified, the ROM image of the referenced config map or secret is exposed to the guest instead of the ROM of the host device. Only supported by PCI host devices.
+                        properties:
+                          configMap:
+                            description: ConfigMap references a config map holding the ROM image as binary data.
+                            properties:
+                              name:
+                                description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                type: string
+                            type: object
+                          secret:
+                            description: Secret references a secret holding the ROM image.
+                            properties:
+                              name:
+                                description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                type: string
+                            type: object
+                        type: object
                     required:
                     - deviceName
                     - name
@@ -5443,6 +6681,9 @@ var CRDsValidation map[string]string = map[string]string{
                       macAddress:
                         description: 'Interface MAC address. For example: de:ad:00:00:be:af or DE-AD-00-00-BE-AF.'
                         type: string
+                      macAddressPool:
+                        description: Name of a MAC address pool configured in the KubeVirt CR, to draw the MAC address of the interface from if macAddress is not set.
+                        type: string
                       macvtap:
                         type: object
                       masquerade:
@@ -5450,6 +6691,9 @@ var CRDsValidation map[string]string = map[string]string{
                       model:
                         description: 'Interface model. One of: e1000, e1000e, ne2k_pci, pcnet, rtl8139, virtio. Defaults to virtio. TODO:(ihar) switch to enums once opengen-api supports them. See: https://github.com/kubernetes/kube-openapi/issues/51'
                         type: string
+                      mtu:
+                        description: MTU of the interface in the guest, for example 9000 on jumbo-frame networks. Must not exceed the MTU of the pod network, which it defaults to. Only supported with the bridge and masquerade bindings.
+                        type: integer
                       name:
                         description: Logical name of the interface as well as a reference to the associated networks. Must match the Name of a Network.
                         type: string
@@ -5682,11 +6926,43 @@ var CRDsValidation map[string]string = map[string]string{
                         secureBoot:
                           description: If set, SecureBoot will be enabled and the OVMF roms will be swapped for SecureBoot-enabled ones. Requires SMM to be enabled. Defaults to true
                           type: boolean
+                        secureBootCertificates:
+                          description: If set, the certificates of the referenced secrets are enrolled in the Secure Boot variable store instead of the default keys of the firmware. Requires SecureBoot.
+                          properties:
+                            db:
+                              description: DB references the secret holding the certificates of the allowed signature database.
+                              properties:
+                                name:
+                                  description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                  type: string
+                              type: object
+                            kek:
+                              description: KEK references the secret holding the Key Exchange Keys.
+                              properties:
+                                name:
+                                  description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                  type: string
+                              type: object
+                            pk:
+                              description: PK references the secret holding the Platform Key, a single certificate.
+                              properties:
+                                name:
+                                  description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                  type: string
+                              type: object
+                          type: object
                       type: object
                   type: object
                 serial:
                   description: The system-serial-number in SMBIOS
                   type: string
+                smbiosSecretRef:
+                  description: SMBiosSecretRef references a k8s secret in the namespace of the vmi that contains SMBIOS system information. The keys manufacturer and product set the matching SMBIOS system fields and the key oemStrings holds one SMBIOS OEM string per line. Missing keys are ignored.
+                  properties:
+                    name:
+                      description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                      type: string
+                  type: object
                 uuid:
                   description: UUID reported by the vmi bios. Defaults to a random generated uid.
                   type: string
@@ -5706,6 +6982,13 @@ var CRDsValidation map[string]string = map[string]string{
             memory:
               description: Memory allow specifying the VMI memory features.
               properties:
+                balloonFloor:
+                  anyOf:
+                  - type: integer
+                  - type: string
+                  description: BalloonFloor is the least amount of memory left to the Guest OS when virt-launcher reclaims memory through the memory balloon, because the memory of the pod comes under pressure. Memory is only reclaimed if set, which requires the memory balloon device.
+                  pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                  x-kubernetes-int-or-string: true
                 guest:
                   anyOf:
                   - type: integer
@@ -5719,8 +7002,44 @@ var CRDsValidation map[string]string = map[string]string{
                     pageSize:
                       description: PageSize specifies the hugepage size, for x86_64 architecture valid values are 1Gi and 2Mi.
                       type: string
+                    perNUMANode:
+                      description: PerNUMANode splits the guest memory into guest NUMA nodes, each backed by the hugepages of a host NUMA node. The memory of all nodes has to add up to the guest memory, the vCPUs are spread evenly across the nodes.
+                      items:
+                        description: NUMANodeHugepages places the hugepages of a guest NUMA node on a host NUMA node.
+                        properties:
+                          guestNode:
+                            description: GuestNode is the ID of the guest NUMA node. The guest nodes are numbered from 0 without gaps.
+                            format: int32
+                            type: integer
+                          hostNode:
+                            description: HostNode is the ID of the host NUMA node the hugepages are allocated on.
+                            format: int32
+                            type: integer
+                          memory:
+                            anyOf:
+                            - type: integer
+                            - type: string
+                            description: Memory is the guest memory of the node, a multiple of the page size.
+                            pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                            x-kubernetes-int-or-string: true
+                        required:
+                        - guestNode
+                        - hostNode
+                        - memory
+                        type: object
+                      type: array
                   type: object
+                locked:
+                  description: Locked locks the guest memory in host memory, so that it is never swapped out or reclaimed, e.g. for latency sensitive or confidential workloads. virt-handler raises the memory lock limit of the pod accordingly, the locked memory still counts against its memory limit.
+                  type: boolean
+                overcommitPercent:
+                  description: OvercommitPercent is the percentage of the memory requested for the pod the guest is given, e.g. 150 requests two thirds of the guest memory. It replaces the memoryOvercommit of the cluster for the vmi and is limited to its maxMemoryOvercommit. It requires the guest memory, which the memory request is computed from.
+                  format: int32
+                  type: integer
               type: object
+            onCrash:
+              description: 'OnCrash is the action taken when the guest crashes, e.g. on a kernel panic. One of: destroy, restart, preserve. Defaults to destroy.'
+              type: string
             resources:
               description: Resources describes the Compute Resources required by this vmi.
               properties:
@@ -5746,6 +7065,13 @@ var CRDsValidation map[string]string = map[string]string{
                   description: Requests is a description of the initial vmi resources. Valid resource keys are "memory" and "cpu".
                   type: object
               type: object
+            selinux:
+              description: SELinux configures the SELinux context of the virt-launcher running the domain.
+              properties:
+                launcherType:
+                  description: LauncherType is the SELinux type the virt-launcher runs with, in place of the cluster wide launcher type. The VMI is only scheduled on nodes advertising this type as available.
+                  type: string
+              type: object
           required:
           - devices
           type: object
@@ -6292,6 +7618,31 @@ var CRDsValidation map[string]string = map[string]string{
                 domain:
                   description: Specification of the desired behavior of the VirtualMachineInstance on the host.
                   properties:
+                    boot:
+                      description: Boot configures the devices the guest boots from and the order in which the firmware tries them.
+                      properties:
+                        networkBootLast:
+                          description: NetworkBootLast boots from the network (PXE) through the interfaces without a boot order, after all the other boot devices were tried.
+                          type: boolean
+                        order:
+                          description: Order lists the disks and interfaces to boot from. The firmware tries them in this order and falls back to the next one if a device is not bootable. It can't be combined with bootOrder on the disks and interfaces.
+                          items:
+                            description: BootDevice references a disk or an interface to boot from. Exactly one of its fields has to be set.
+                            properties:
+                              disk:
+                                description: Disk is the name of the disk to boot from.
+                                type: string
+                              interface:
+                                description: Interface is the name of the interface to boot from.
+                                type: string
+                            type: object
+                          type: array
+                          x-kubernetes-list-type: atomic
+                        rebootTimeoutSeconds:
+                          description: RebootTimeoutSeconds makes the firmware retry to boot after this many seconds if none of the boot devices is bootable, instead of halting. Only supported with BIOS.
+                          format: int32
+                          type: integer
+                      type: object
                     chassis:
                       description: Chassis specifies the chassis info passed to the domain.
                       properties:
@@ -6315,6 +7666,22 @@ var CRDsValidation map[string]string = map[string]string{
                             hpet:
                               description: HPET (High Precision Event Timer) - multiple timers with periodic interrupts.
                               properties:
+                                catchup:
+                                  description: Catchup tunes the catchup tick policy, only valid with it.
+                                  properties:
+                                    limit:
+                                      description: Limit is the number of missed ticks beyond which the timer stops catching up.
+                                      format: int32
+                                      type: integer
+                                    slew:
+                                      description: Slew is the number of missed ticks injected at a higher rate at once.
+                                      format: int32
+                                      type: integer
+                                    threshold:
+                                      description: Threshold is the number of missed ticks from which the timer catches up.
+                                      format: int32
+                                      type: integer
+                                  type: object
                                 present:
                                   description: Enabled set to false makes sure that the machine type or a preset can't add the timer. Defaults to true.
                                   type: boolean
@@ -6339,6 +7706,22 @@ var CRDsValidation map[string]string = map[string]string{
                             pit:
                               description: PIT (Programmable Interval Timer) - a timer with periodic interrupts.
                               properties:
+                                catchup:
+                                  description: Catchup tunes the catchup tick policy, only valid with it.
+                                  properties:
+                                    limit:
+                                      description: Limit is the number of missed ticks beyond which the timer stops catching up.
+                                      format: int32
+                                      type: integer
+                                    slew:
+                                      description: Slew is the number of missed ticks injected at a higher rate at once.
+                                      format: int32
+                                      type: integer
+                                    threshold:
+                                      description: Threshold is the number of missed ticks from which the timer catches up.
+                                      format: int32
+                                      type: integer
+                                  type: object
                                 present:
                                   description: Enabled set to false makes sure that the machine type or a preset can't add the timer. Defaults to true.
                                   type: boolean
@@ -6349,6 +7732,22 @@ var CRDsValidation map[string]string = map[string]string{
                             rtc:
                               description: RTC (Real Time Clock) - a continuously running timer with periodic interrupts.
                               properties:
+                                catchup:
+                                  description: Catchup tunes the catchup tick policy, only valid with it.
+                                  properties:
+                                    limit:
+                                      description: Limit is the number of missed ticks beyond which the timer stops catching up.
+                                      format: int32
+                                      type: integer
+                                    slew:
+                                      description: Slew is the number of missed ticks injected at a higher rate at once.
+                                      format: int32
+                                      type: integer
+                                    threshold:
+                                      description: Threshold is the number of missed ticks from which the timer catches up.
+                                      format: int32
+                                      type: integer
+                                  type: object
                                 present:
                                   description: Enabled set to false makes sure that the machine type or a preset can't add the timer. Defaults to true.
                                   type: boolean
@@ -6414,6 +7813,10 @@ var CRDsValidation map[string]string = map[string]string{
                     devices:
                       description: Devices allows adding disks, network interfaces, and others
                       properties:
+                        agentConnectTimeoutSeconds:
+                          description: AgentConnectTimeoutSeconds is how long the operations requiring the guest agent, like freezing the guest filesystems or reading the guest OS info, wait for it to connect before failing. They fail right away if the guest agent isn't connected and no timeout is set. Must be between 0 and 600.
+                          format: int32
+                          type: integer
                         autoattachGraphicsDevice:
                           description: Whether to attach the default graphics device or not. VNC will not be available if set to false. Defaults to true.
                           type: boolean
@@ -6484,6 +7887,39 @@ var CRDsValidation map[string]string = map[string]string{
                               io:
                                 description: 'IO specifies which QEMU disk IO mode should be used. Supported values are: native, default, threads.'
                                 type: string
+                              ioThrottleGroup:
+                                description: IOThrottleGroup makes the disk share the I/O limits of the group with all other disks of the vmi in the same group.
+                                properties:
+                                  name:
+                                    description: Name of the throttle group.
+                                    type: string
+                                  readBytesPerSec:
+                                    description: ReadBytesPerSec limits the read bytes per second.
+                                    format: int64
+                                    type: integer
+                                  readIOPS:
+                                    description: ReadIOPS limits the read operations per second.
+                                    format: int64
+                                    type: integer
+                                  totalBytesPerSec:
+                                    description: TotalBytesPerSec limits the read and written bytes per second. Can't be combined with ReadBytesPerSec or WriteBytesPerSec.
+                                    format: int64
+                                    type: integer
+                                  totalIOPS:
+                                    description: TotalIOPS limits the read and write operations per second. Can't be combined with ReadIOPS or WriteIOPS.
+                                    format: int64
+                                    type: integer
+                                  writeBytesPerSec:
+                                    description: WriteBytesPerSec limits the written bytes per second.
+                                    format: int64
+                                    type: integer
+                                  writeIOPS:
+                                    description: WriteIOPS limits the write operations per second.
+                                    format: int64
+                                    type: integer
+                                required:
+                                - name
+                                type: object
                               lun:
                                 description: Attach a volume as a LUN to the vmi.
                                 properties:
@@ -6503,6 +7939,9 @@ var CRDsValidation map[string]string = map[string]string{
                               tag:
                                 description: If specified, disk address and its tag will be provided to the guest via config drive metadata
                                 type: string
+                              wwn:
+                                description: WWN provides the ability to specify the World Wide Name of the disk device, made up of 16 hexadecimal digits. Only supported on the scsi bus.
+                                type: string
                             required:
                             - name
                             type: object
@@ -6516,6 +7955,13 @@ var CRDsValidation map[string]string = map[string]string{
                                 type: string
                               virtiofs:
                                 description: Virtiofs is supported
+                                properties:
+                                  cache:
+                                    description: 'Cache selects how virtiofsd caches the shared data and metadata. Supported values: none, auto, always. Defaults to none.'
+                                    type: string
+                                  sandbox:
+                                    description: 'Sandbox selects how virtiofsd isolates itself from the rest of the host. Supported values: namespace, chroot. Defaults to namespace.'
+                                    type: string
                                 type: object
                             required:
                             - name
@@ -6547,6 +7993,27 @@ var CRDsValidation map[string]string = map[string]string{
                                 type: string
                               name:
                                 type: string
+                              pciAddress:
+                                description: 'If specified, the host device will be placed on the guests pci address with the specified PCI address. For example: 0000:81:01.1'
+                                type: string
+                              rom:
+                                description: If specified, the ROM image of the referenced config map or secret is exposed to the guest instead of the ROM of the host device. Only supported by PCI host devices.
+                                properties:
+                                  configMap:
+                                    description: ConfigMap references a config map holding the ROM image as binary data.
+                                    properties:
+                                      name:
+                                        description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                        type: string
+                                    type: object
+                                  secret:
+                                    description: Secret references a secret holding the ROM image.
+                                    properties:
+                                      name:
+                                        description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                        type: string
+                                    type: object
+                                type: object
                             required:
                             - deviceName
                             - name
@@ -6614,6 +8081,9 @@ var CRDsValidation map[string]string = map[string]string{
                               macAddress:
                                 description: 'Interface MAC address. For example: de:ad:00:00:be:af or DE-AD-00-00-BE-AF.'
                                 type: string
+                              macAddressPool:
+                                description: Name of a MAC address pool configured in the KubeVirt CR, to draw the MAC address of the interface from if macAddress is not set.
+                                type: string
                               macvtap:
                                 type: object
                               masquerade:
@@ -6621,6 +8091,9 @@ var CRDsValidation map[string]string = map[string]string{
                               model:
                                 description: 'Interface model. One of: e1000, e1000e, ne2k_pci, pcnet, rtl8139, virtio. Defaults to virtio. TODO:(ihar) switch to enums once opengen-api supports them. See: https://github.com/kubernetes/kube-openapi/issues/51'
                                 type: string
+                              mtu:
+                                description: MTU of the interface in the guest, for example 9000 on jumbo-frame networks. Must not exceed the MTU of the pod network, which it defaults to. Only supported with the bridge and masquerade bindings.
+                                type: integer
                               name:
                                 description: Logical name of the interface as well as a reference to the associated networks. Must match the Name of a Network.
                                 type: string
@@ -6853,11 +8326,43 @@ var CRDsValidation map[string]string = map[string]string{
                                 secureBoot:
                                   description: If set, SecureBoot will be enabled and the OVMF roms will be swapped for SecureBoot-enabled ones. Requires SMM to be enabled. Defaults to true
                                   type: boolean
+                                secureBootCertificates:
+                                  description: If set, the certificates of the referenced secrets are enrolled in the Secure Boot variable store instead of the default keys of the firmware. Requires SecureBoot.
+                                  properties:
+                                    db:
+                                      description: DB references the secret holding the certificates of the allowed signature database.
+                                      properties:
+                                        name:
+                                          description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                          type: string
+                                      type: object
+                                    kek:
+                                      description: KEK references the secret holding the Key Exchange Keys.
+                                      properties:
+                                        name:
+                                          description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                          type: string
+                                      type: object
+                                    pk:
+                                      description: PK references the secret holding the Platform Key, a single certificate.
+                                      properties:
+                                        name:
+                                          description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                          type: string
+                                      type: object
+                                  type: object
                               type: object
                           type: object
                         serial:
                           description: The system-serial-number in SMBIOS
                           type: string
+                        smbiosSecretRef:
+                          description: SMBiosSecretRef references a k8s secret in the namespace of the vmi that contains SMBIOS system information. The keys manufacturer and product set the matching SMBIOS system fields and the key oemStrings holds one SMBIOS OEM string per line. Missing keys are ignored.
+                          properties:
+                            name:
+                              description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                              type: string
+                          type: object
                         uuid:
                           description: UUID reported by the vmi bios. Defaults to a random generated uid.
                           type: string
@@ -6877,6 +8382,13 @@ var CRDsValidation map[string]string = map[string]string{
                     memory:
                       description: Memory allow specifying the VMI memory features.
                       properties:
+                        balloonFloor:
+                          anyOf:
+                          - type: integer
+                          - type: string
+                          description: BalloonFloor is the least amount of memory left to the Guest OS when virt-launcher reclaims memory through the memory balloon, because the memory of the pod comes under pressure. Memory is only reclaimed if set, which requires the memory balloon device.
+                          pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                          x-kubernetes-int-or-string: true
                         guest:
                           anyOf:
                           - type: integer
@@ -6890,8 +8402,44 @@ var CRDsValidation map[string]string = map[string]string{
                             pageSize:
                               description: PageSize specifies the hugepage size, for x86_64 architecture valid values are 1Gi and 2Mi.
                               type: string
+                            perNUMANode:
+                              description: PerNUMANode splits the guest memory into guest NUMA nodes, each backed by the hugepages of a host NUMA node. The memory of all nodes has to add up to the guest memory, the vCPUs are spread evenly across the nodes.
+                              items:
+                                description: NUMANodeHugepages places the hugepages of a guest NUMA node on a host NUMA node.
+                                properties:
+                                  guestNode:
+                                    description: GuestNode is the ID of the guest NUMA node. The guest nodes are numbered from 0 without gaps.
+                                    format: int32
+                                    type: integer
+                                  hostNode:
+                                    description: HostNode is the ID of the host NUMA node the hugepages are allocated on.
+                                    format: int32
+                                    type: integer
+                                  memory:
+                                    anyOf:
+                                    - type: integer
+                                    - type: string
+                                    description: Memory is the guest memory of the node, a multiple of the page size.
+                                    pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                                    x-kubernetes-int-or-string: true
+                                required:
+                                - guestNode
+                                - hostNode
+                                - memory
+                                type: object
+                              type: array
                           type: object
+                        locked:
+                          description: Locked locks the guest memory in host memory, so that it is never swapped out or reclaimed, e.g. for latency sensitive or confidential workloads. virt-handler raises the memory lock limit of the pod accordingly, the locked memory still counts against its memory limit.
+                          type: boolean
+                        overcommitPercent:
+                          description: OvercommitPercent is the percentage of the memory requested for the pod the guest is given, e.g. 150 requests two thirds of the guest memory. It replaces the memoryOvercommit of the cluster for the vmi and is limited to its maxMemoryOvercommit. It requires the guest memory, which the memory request is computed from.
+                          format: int32
+                          type: integer
                       type: object
+                    onCrash:
+                      description: 'OnCrash is the action taken when the guest crashes, e.g. on a kernel panic. One of: destroy, restart, preserve. Defaults to destroy.'
+                      type: string
                     resources:
                       description: Resources describes the Compute Resources required by this vmi.
                       properties:
@@ -6917,22 +8465,81 @@ var CRDsValidation map[string]string = map[string]string{
                           description: Requests is a description of the initial vmi resources. Valid resource keys are "memory" and "cpu".
                           type: object
                       type: object
+                    selinux:
+                      description: SELinux configures the SELinux context of the virt-launcher running the domain.
+                      properties:
+                        launcherType:
+                          description: LauncherType is the SELinux type the virt-launcher runs with, in place of the cluster wide launcher type. The VMI is only scheduled on nodes advertising this type as available.
+                          type: string
+                      type: object
                   required:
                   - devices
                   type: object
                 evictionStrategy:
-                  description: EvictionStrategy can be set to "LiveMigrate" if the VirtualMachineInstance should be migrated instead of shut-off in case of a node drain.
+                  description: EvictionStrategy can be set to "LiveMigrate" if the VirtualMachineInstance should be migrated instead of shut-off in case of a node drain, or to "ProtectUntilDrained" if it should be shut down gracefully once its node is drained.
                   type: string
+                freezeHooks:
+                  description: Commands run in the guest through the guest agent around the freeze of its filesystems, e.g. to flush and quiesce applications before the disks are snapshotted.
+                  properties:
+                    postThaw:
+                      description: PostThaw is run after the guest filesystems are thawed again
+                      properties:
+                        command:
+                          description: Command is the command line to execute in the guest, starting with the absolute path of the executable. It is not run in a shell.
+                          items:
+                            type: string
+                          type: array
+                        failurePolicy:
+                          description: 'FailurePolicy tells what happens when the hook fails or times out: Abort fails the freeze, respectively the thaw, Continue only logs the failure. Defaults to Abort.'
+                          type: string
+                        timeoutSeconds:
+                          description: Number of seconds after which the hook is considered failed. Defaults to 10 seconds. Minimum value is 1, maximum value is 15.
+                          format: int32
+                          type: integer
+                      required:
+                      - command
+                      type: object
+                    preFreeze:
+                      description: PreFreeze is run before the guest filesystems are frozen
+                      properties:
+                        command:
+                          description: Command is the command line to execute in the guest, starting with the absolute path of the executable. It is not run in a shell.
+                          items:
+                            type: string
+                          type: array
+                        failurePolicy:
+                          description: 'FailurePolicy tells what happens when the hook fails or times out: Abort fails the freeze, respectively the thaw, Continue only logs the failure. Defaults to Abort.'
+                          type: string
+                        timeoutSeconds:
+                          description: Number of seconds after which the hook is considered failed. Defaults to 10 seconds. Minimum value is 1, maximum value is 15.
+                          format: int32
+                          type: integer
+                      required:
+                      - command
+                      type: object
+                  type: object
                 hostname:
                   description: Specifies the hostname of the vmi If not specified, the hostname will be set to the name of the vmi, if dhcp or cloud-init is configured properly.
                   type: string
                 livenessProbe:
                   description: 'Periodic probe of VirtualMachineInstance liveness. VirtualmachineInstances will be stopped if the probe fails. Cannot be updated. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes'
                   properties:
+                    exec:
+                      description: Exec specifies a command run in the guest through the guest agent, the probe succeeds if the command exits with 0. Only supported by startup probes.
+                      properties:
+                        command:
+                          description: Command is the command line to execute inside the container, the working directory for the command  is root ('/') in the container's filesystem. The command is simply exec'd, it is not run inside a shell, so traditional shell instructions ('|', etc) won't work. To use a shell, you need to explicitly call out to that shell. Exit status of 0 is treated as live/healthy and non-zero is unhealthy.
+                          items:
+                            type: string
+                          type: array
+                      type: object
                     failureThreshold:
                       description: Minimum consecutive failures for the probe to be considered failed after having succeeded. Defaults to 3. Minimum value is 1.
                       format: int32
                       type: integer
+                    guestAgentPing:
+                      description: GuestAgentPing contacts the guest agent, the probe succeeds if it answers. Only supported by startup probes.
+                      type: object
                     httpGet:
                       description: HTTPGet specifies the http request to perform.
                       properties:
@@ -7002,6 +8609,9 @@ var CRDsValidation map[string]string = map[string]string{
                       format: int32
                       type: integer
                   type: object
+                livenessProbeFailureAction:
+                  description: Action taken once the liveness probe failed. Restart, the default, stops the VirtualMachineInstance. Migrate live migrates it off its node instead, or stops it if it is not live migratable. Migrate requires a livenessProbe and no readinessProbe, the liveness probe then drives the readiness of the VirtualMachineInstance. Cannot be updated.
+                  type: string
                 networks:
                   description: List of networks that can be attached to a vm's virtual interface.
                   items:
@@ -7038,16 +8648,119 @@ var CRDsValidation map[string]string = map[string]string{
                     type: string
                   description: 'NodeSelector is a selector which must be true for the vmi to fit on a node. Selector which must match a node''s labels for the vmi to be scheduled on that node. More info: https://kubernetes.io/docs/concepts/configuration/assign-pod-node/'
                   type: object
-                priorityClassName:
-                  description: If specified, indicates the pod's priority. If not specified, the pod priority will be default or zero if there is no default.
+                priorityClassName:
+                  description: If specified, indicates the pod's priority. If not specified, the pod priority will be default or zero if there is no default.
+                  type: string
+                readinessProbe:
+                  description: 'Periodic probe of VirtualMachineInstance service readiness. VirtualmachineInstances will be removed from service endpoints if the probe fails. Cannot be updated. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes'
+                  properties:
+                    exec:
+                      description: Exec specifies a command run in the guest through the guest agent, the probe succeeds if the command exits with 0. Only supported by startup probes.
+                      properties:
+                        command:
+                          description: Command is the command line to execute inside the container, the working directory for the command  is root ('/') in the container's filesystem. The command is simply exec'd, it is not run inside a shell, so traditional shell instructions ('|', etc) won't work. To use a shell, you need to explicitly call out to that shell. Exit status of 0 is treated as live/healthy and non-zero is unhealthy.
+                          items:
+                            type: string
+                          type: array
+                      type: object
+                    failureThreshold:
+                      description: Minimum consecutive failures for the probe to be considered failed after having succeeded. Defaults to 3. Minimum value is 1.
+                      format: int32
+                      type: integer
+                    guestAgentPing:
+                      description: GuestAgentPing contacts the guest agent, the probe succeeds if it answers. Only supported by startup probes.
+                      type: object
+                    httpGet:
+                      description: HTTPGet specifies the http request to perform.
+                      properties:
+                        host:
+                          description: Host name to connect to, defaults to the pod IP. You probably want to set "Host" in httpHeaders instead.
+                          type: string
+                        httpHeaders:
+                          description: Custom headers to set in the request. HTTP allows repeated headers.
+                          items:
+                            description: HTTPHeader describes a custom header to be used in HTTP probes
+                            properties:
+                              name:
+                                description: The header field name
+                                type: string
+                              value:
+                                description: The header field value
+                                type: string
+                            required:
+                            - name
+                            - value
+                            type: object
+                          type: array
+                        path:
+                          description: Path to access on the HTTP server.
+                          type: string
+                        port:
+                          anyOf:
+                          - type: integer
+                          - type: string
+                          description: Name or number of the port to access on the container. Number must be in the range 1 to 65535. Name must be an IANA_SVC_NAME.
+                          x-kubernetes-int-or-string: true
+                        scheme:
+                          description: Scheme to use for connecting to the host. Defaults to HTTP.
+                          type: string
+                      required:
+                      - port
+                      type: object
+                    initialDelaySeconds:
+                      description: 'Number of seconds after the VirtualMachineInstance has started before liveness probes are initiated. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes'
+                      format: int32
+                      type: integer
+                    periodSeconds:
+                      description: How often (in seconds) to perform the probe. Default to 10 seconds. Minimum value is 1.
+                      format: int32
+                      type: integer
+                    successThreshold:
+                      description: Minimum consecutive successes for the probe to be considered successful after having failed. Defaults to 1. Must be 1 for liveness. Minimum value is 1.
+                      format: int32
+                      type: integer
+                    tcpSocket:
+                      description: 'TCPSocket specifies an action involving a TCP port. TCP hooks not yet supported TODO: implement a realistic TCP lifecycle hook'
+                      properties:
+                        host:
+                          description: 'Optional: Host name to connect to, defaults to the pod IP.'
+                          type: string
+                        port:
+                          anyOf:
+                          - type: integer
+                          - type: string
+                          description: Number or name of the port to access on the container. Number must be in the range 1 to 65535. Name must be an IANA_SVC_NAME.
+                          x-kubernetes-int-or-string: true
+                      required:
+                      - port
+                      type: object
+                    timeoutSeconds:
+                      description: 'Number of seconds after which the probe times out. Defaults to 1 second. Minimum value is 1. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes'
+                      format: int32
+                      type: integer
+                  type: object
+                schedulerName:
+                  description: If specified, the VMI will be dispatched by specified scheduler. If not specified, the VMI will be dispatched by default scheduler.
                   type: string
-                readinessProbe:
-                  description: 'Periodic probe of VirtualMachineInstance service readiness. VirtualmachineInstances will be removed from service endpoints if the probe fails. Cannot be updated. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes'
+                startupProbe:
+                  description: Probe of the VirtualMachineInstance startup, evaluated through the guest agent. The VirtualMachineInstance is not ready until the probe succeeded. Only guestAgentPing and exec probes are supported. Cannot be updated.
                   properties:
+                    exec:
+                      description: Exec specifies a command run in the guest through the guest agent, the probe succeeds if the command exits with 0. Only supported by startup probes.
+                      properties:
+                        command:
+                          description: Command is the command line to execute inside the container, the working directory for the command  is root ('/') in the container's filesystem. The command is simply exec'd, it is not run inside a shell, so traditional shell instructions ('|', etc) won't work. To use a shell, you need to explicitly call out to that shell. Exit status of 0 is treated as live/healthy and non-zero is unhealthy.
+                          items:
+                            type: string
+                          type: array
+                      type: object
                     failureThreshold:
                       description: Minimum consecutive failures for the probe to be considered failed after having succeeded. Defaults to 3. Minimum value is 1.
                       format: int32
                       type: integer
+                    guestAgentPing:
+                      description: GuestAgentPing contacts the guest agent, the probe succeeds if it answers. Only supported by startup probes.
+                      type: object
                     httpGet:
                       description: HTTPGet specifies the http request to perform.
                       properties:
@@ -7117,9 +8830,6 @@ var CRDsValidation map[string]string = map[string]string{
                       format: int32
                       type: integer
                   type: object
-                schedulerName:
-                  description: If specified, the VMI will be dispatched by specified scheduler. If not specified, the VMI will be dispatched by default scheduler.
-                  type: string
                 subdomain:
                   description: If specified, the fully qualified vmi hostname will be "<hostname>.<subdomain>.<pod namespace>.svc.<cluster domain>". If not specified, the vmi will not have a domainname at all. The DNS entry will resolve to the vmi, no matter if the vmi itself can pick up a hostname.
                   type: string
@@ -7400,6 +9110,9 @@ var CRDsValidation map[string]string = map[string]string{
                     - name
                     type: object
                   type: array
+                workloadClass:
+                  description: WorkloadClass groups the VMIs to spread across nodes. The pods of the VMIs of the same class in a namespace prefer not to be scheduled on the same node. It has to be a DNS-1123 label.
+                  type: string
               required:
               - domain
               type: object
@@ -7901,6 +9614,19 @@ var CRDsValidation map[string]string = map[string]string{
                     running:
                       description: Running controls whether the associatied VirtualMachineInstance is created or not Mutually exclusive with RunStrategy
                       type: boolean
+                    schedule:
+                      description: Schedule starts and stops the VirtualMachine at the given times, by switching its RunStrategy between Always and Halted.
+                      properties:
+                        start:
+                          description: Start is the cron schedule, in the five field format "minute hour day-of-month month day-of-week", at which the VirtualMachine is started.
+                          type: string
+                        stop:
+                          description: Stop is the cron schedule, in the same format as Start, at which the VirtualMachine is stopped.
+                          type: string
+                        timeZone:
+                          description: TimeZone is the IANA name of the time zone the schedules are evaluated in. Defaults to UTC.
+                          type: string
+                      type: object
                     template:
                       description: Template is the direct specification of VirtualMachineInstance
                       properties:
@@ -8358,6 +10084,31 @@ var CRDsValidation map[string]string = map[string]string{
                             domain:
                               description: Specification of the desired behavior of the VirtualMachineInstance on the host.
                               properties:
+                                boot:
+                                  description: Boot configures the devices the guest boots from and the order in which the firmware tries them.
+                                  properties:
+                                    networkBootLast:
+                                      description: NetworkBootLast boots from the network (PXE) through the interfaces without a boot order, after all the other boot devices were tried.
+                                      type: boolean
+                                    order:
+                                      description: Order lists the disks and interfaces to boot from. The firmware tries them in this order and falls back to the next one if a device is not bootable. It can't be combined with bootOrder on the disks and interfaces.
+                                      items:
+                                        description: BootDevice references a disk or an interface to boot from. Exactly one of its fields has to be set.
+                                        properties:
+                                          disk:
+                                            description: Disk is the name of the disk to boot from.
+                                            type: string
+                                          interface:
+                                            description: Interface is the name of the interface to boot from.
+                                            type: string
+                                        type: object
+                                      type: array
+                                      x-kubernetes-list-type: atomic
+                                    rebootTimeoutSeconds:
+                                      description: RebootTimeoutSeconds makes the firmware retry to boot after this many seconds if none of the boot devices is bootable, instead of halting. Only supported with BIOS.
+                                      format: int32
+                                      type: integer
+                                  type: object
                                 chassis:
                                   description: Chassis specifies the chassis info passed to the domain.
                                   properties:
@@ -8381,6 +10132,22 @@ var CRDsValidation map[string]string = map[string]string{
                                         hpet:
                                           description: HPET (High Precision Event Timer) - multiple timers with periodic interrupts.
                                           properties:
+                                            catchup:
+                                              description: Catchup tunes the catchup tick policy, only valid with it.
+                                              properties:
+                                                limit:
+                                                  description: Limit is the number of missed ticks beyond which the timer stops catching up.
+                                                  format: int32
+                                                  type: integer
+                                                slew:
+                                                  description: Slew is the number of missed ticks injected at a higher rate at once.
+                                                  format: int32
+                                                  type: integer
+                                                threshold:
+                                                  description: Threshold is the number of missed ticks from which the timer catches up.
+                                                  format: int32
+                                                  type: integer
+                                              type: object
                                             present:
                                               description: Enabled set to false makes sure that the machine type or a preset can't add the timer. Defaults to true.
                                               type: boolean
@@ -8405,6 +10172,22 @@ var CRDsValidation map[string]string = map[string]string{
                                         pit:
                                           description: PIT (Programmable Interval Timer) - a timer with periodic interrupts.
                                           properties:
+                                            catchup:
+                                              description: Catchup tunes the catchup tick policy, only valid with it.
+                                              properties:
+                                                limit:
+                                                  description: Limit is the number of missed ticks beyond which the timer stops catching up.
+                                                  format: int32
+                                                  type: integer
+                                                slew:
+                                                  description: Slew is the number of missed ticks injected at a higher rate at once.
+                                                  format: int32
+                                                  type: integer
+                                                threshold:
+                                                  description: Threshold is the number of missed ticks from which the timer catches up.
+                                                  format: int32
+                                                  type: integer
+                                              type: object
                                             present:
                                               description: Enabled set to false makes sure that the machine type or a preset can't add the timer. Defaults to true.
                                               type: boolean
@@ -8415,6 +10198,22 @@ var CRDsValidation map[string]string = map[string]string{
                                         rtc:
                                           description: RTC (Real Time Clock) - a continuously running timer with periodic interrupts.
                                           properties:
+                                            catchup:
+                                              description: Catchup tunes the catchup tick policy, only valid with it.
+                                              properties:
+                                                limit:
+                                                  description: Limit is the number of missed ticks beyond which the timer stops catching up.
+                                                  format: int32
+                                                  type: integer
+                                                slew:
+                                                  description: Slew is the number of missed ticks injected at a higher rate at once.
+                                                  format: int32
+                                                  type: integer
+                                                threshold:
+                                                  description: Threshold is the number of missed ticks from which the timer catches up.
+                                                  format: int32
+                                                  type: integer
+                                              type: object
                                             present:
                                               description: Enabled set to false makes sure that the machine type or a preset can't add the timer. Defaults to true.
                                               type: boolean
@@ -8480,6 +10279,10 @@ var CRDsValidation map[string]string = map[string]string{
                                 devices:
                                   description: Devices allows adding disks, network interfaces, and others
                                   properties:
+                                    agentConnectTimeoutSeconds:
+                                      description: AgentConnectTimeoutSeconds is how long the operations requiring the guest agent, like freezing the guest filesystems or reading the guest OS info, wait for it to connect before failing. They fail right away if the guest agent isn't connected and no timeout is set. Must be between 0 and 600.
+                                      format: int32
+                                      type: integer
                                     autoattachGraphicsDevice:
                                       description: Whether to attach the default graphics device or not. VNC will not be available if set to false. Defaults to true.
                                       type: boolean
@@ -8550,6 +10353,39 @@ var CRDsValidation map[string]string = map[string]string{
                                           io:
                                             description: 'IO specifies which QEMU disk IO mode should be used. Supported values are: native, default, threads.'
                                             type: string
+                                          ioThrottleGroup:
+                                            description: IOThrottleGroup makes the disk share the I/O limits of the group with all other disks of the vmi in the same group.
+                                            properties:
+                                              name:
+                                                description: Name of the throttle group.
+                                                type: string
+                                              readBytesPerSec:
+                                                description: ReadBytesPerSec limits the read bytes per second.
+                                                format: int64
+                                                type: integer
+                                              readIOPS:
+                                                description: ReadIOPS limits the read operations per second.
+                                                format: int64
+                                                type: integer
+                                              totalBytesPerSec:
+                                                description: TotalBytesPerSec limits the read and written bytes per second. Can't be combined with ReadBytesPerSec or WriteBytesPerSec.
+                                                format: int64
+                                                type: integer
+                                              totalIOPS:
+                                                description: TotalIOPS limits the read and write operations per second. Can't be combined with ReadIOPS or WriteIOPS.
+                                                format: int64
+                                                type: integer
+                                              writeBytesPerSec:
+                                                description: WriteBytesPerSec limits the written bytes per second.
+                                                format: int64
+                                                type: integer
+                                              writeIOPS:
+                                                description: WriteIOPS limits the write operations per second.
+                                                format: int64
+                                                type: integer
+                                            required:
+                                            - name
+                                            type: object
                                           lun:
                                             description: Attach a volume as a LUN to the vmi.
                                             properties:
@@ -8569,6 +10405,9 @@ var CRDsValidation map[string]string = map[string]string{
                                           tag:
                                             description: If specified, disk address and its tag will be provided to the guest via config drive metadata
                                             type: string
+                                          wwn:
+                                            description: WWN provides the ability to specify the World Wide Name of the disk device, made up of 16 hexadecimal digits. Only supported on the scsi bus.
+                                            type: string
                                         required:
                                         - name
                                         type: object
@@ -8582,6 +10421,13 @@ var CRDsValidation map[string]string = map[string]string{
                                             type: string
                                           virtiofs:
                                             description: Virtiofs is supported
+                                            properties:
+                                              cache:
+                                                description: 'Cache selects how virtiofsd caches the shared data and metadata. Supported values: none, auto, always. Defaults to none.'
+                                                type: string
+                                              sandbox:
+                                                description: 'Sandbox selects how virtiofsd isolates itself from the rest of the host. Supported values: namespace, chroot. Defaults to namespace.'
+                                                type: string
                                             type: object
                                         required:
                                         - name
@@ -8613,6 +10459,27 @@ var CRDsValidation map[string]string = map[string]string{
                                             type: string
                                           name:
                                             type: string
+                                          pciAddress:
+                                            description: 'If specified, the host device will be placed on the guests pci address with the specified PCI address. For example: 0000:81:01.1'
+                                            type: string
+                                          rom:
+                                            description: If specified, the ROM image of the referenced config map or secret is exposed to the guest instead of the ROM of the host device. Only supported by PCI host devices.
+                                            properties:
+                                              configMap:
+                                                description: ConfigMap references a config map holding the ROM image as binary data.
+                                                properties:
+                                                  name:
+                                                    description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                                    type: string
+                                                type: object
+                                              secret:
+                                                description: Secret references a secret holding the ROM image.
+                                                properties:
+                                                  name:
+                                                    description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                                    type: string
+                                                type: object
+                                            type: object
                                         required:
                                         - deviceName
                                         - name
@@ -8680,6 +10547,9 @@ var CRDsValidation map[string]string = map[string]string{
                                           macAddress:
                                             description: 'Interface MAC address. For example: de:ad:00:00:be:af or DE-AD-00-00-BE-AF.'
                                             type: string
+                                          macAddressPool:
+                                            description: Name of a MAC address pool configured in the KubeVirt CR, to draw the MAC address of the interface from if macAddress is not set.
+                                            type: string
                                           macvtap:
                                             type: object
                                           masquerade:
@@ -8687,6 +10557,9 @@ var CRDsValidation map[string]string = map[string]string{
                                           model:
                                             description: 'Interface model. One of: e1000, e1000e, ne2k_pci, pcnet, rtl8139, virtio. Defaults to virtio. TODO:(ihar) switch to enums once opengen-api supports them. See: https://github.com/kubernetes/kube-openapi/issues/51'
                                             type: string
+                                          mtu:
+                                            description: MTU of the interface in the guest, for example 9000 on jumbo-frame networks. Must not exceed the MTU of the pod network, which it defaults to. Only supported with the bridge and masquerade bindings.
+                                            type: integer
                                           name:
                                             description: Logical name of the interface as well as a reference to the associated networks. Must match the Name of a Network.
                                             type: string
@@ -8919,11 +10792,43 @@ var CRDsValidation map[string]string = map[string]string{
                                             secureBoot:
                                               description: If set, SecureBoot will be enabled and the OVMF roms will be swapped for SecureBoot-enabled ones. Requires SMM to be enabled. Defaults to true
                                               type: boolean
+                                            secureBootCertificates:
+                                              description: If set, the certificates of the referenced secrets are enrolled in the Secure Boot variable store instead of the default keys of the firmware. Requires SecureBoot.
+                                              properties:
+                                                db:
+                                                  description: DB references the secret holding the certificates of the allowed signature database.
+                                                  properties:
+                                                    name:
+                                                      description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                                      type: string
+                                                  type: object
+                                                kek:
+                                                  description: KEK references the secret holding the Key Exchange Keys.
+                                                  properties:
+                                                    name:
+                                                      description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                                      type: string
+                                                  type: object
+                                                pk:
+                                                  description: PK references the secret holding the Platform Key, a single certificate.
+                                                  properties:
+                                                    name:
+                                                      description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                                      type: string
+                                                  type: object
+                                              type: object
                                           type: object
                                       type: object
                                     serial:
                                       description: The system-serial-number in SMBIOS
                                       type: string
+                                    smbiosSecretRef:
+                                      description: SMBiosSecretRef references a k8s secret in the namespace of the vmi that contains SMBIOS system information. The keys manufacturer and product set the matching SMBIOS system fields and the key oemStrings holds one SMBIOS OEM string per line. Missing keys are ignored.
+                                      properties:
+                                        name:
+                                          description: 'Name of the referent. More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names TODO: Add other useful fields. apiVersion, kind, uid?'
+                                          type: string
+                                      type: object
                                     uuid:
                                       description: UUID reported by the vmi bios. Defaults to a random generated uid.
                                       type: string
@@ -8943,6 +10848,13 @@ var CRDsValidation map[string]string = map[string]string{
                                 memory:
                                   description: Memory allow specifying the VMI memory features.
                                   properties:
+                                    balloonFloor:
+                                      anyOf:
+                                      - type: integer
+                                      - type: string
+                                      description: BalloonFloor is the least amount of memory left to the Guest OS when virt-launcher reclaims memory through the memory balloon, because the memory of the pod comes under pressure. Memory is only reclaimed if set, which requires the memory balloon device.
+                                      pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                                      x-kubernetes-int-or-string: true
                                     guest:
                                       anyOf:
                                       - type: integer
@@ -8956,8 +10868,44 @@ var CRDsValidation map[string]string = map[string]string{
                                         pageSize:
                                           description: PageSize specifies the hugepage size, for x86_64 architecture valid values are 1Gi and 2Mi.
                                           type: string
+                                        perNUMANode:
+                                          description: PerNUMANode splits the guest memory into guest NUMA nodes, each backed by the hugepages of a host NUMA node. The memory of all nodes has to add up to the guest memory, the vCPUs are spread evenly across the nodes.
+                                          items:
+                                            description: NUMANodeHugepages places the hugepages of a guest NUMA node on a host NUMA node.
+                                            properties:
+                                              guestNode:
+                                                description: GuestNode is the ID of the guest NUMA node. The guest nodes are numbered from 0 without gaps.
+                                                format: int32
+                                                type: integer
+                                              hostNode:
+                                                description: HostNode is the ID of the host NUMA node the hugepages are allocated on.
+                                                format: int32
+                                                type: integer
+                                              memory:
+                                                anyOf:
+                                                - type: integer
+                                                - type: string
+                                                description: Memory is the guest memory of the node, a multiple of the page size.
+                                                pattern: ^(\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$
+                                                x-kubernetes-int-or-string: true
+                                            required:
+                                            - guestNode
+                                            - hostNode
+                                            - memory
+                                            type: object
+                                          type: array
                                       type: object
+                                    locked:
+                                      description: Locked locks the guest memory in host memory, so that it is never swapped out or reclaimed, e.g. for latency sensitive or confidential workloads. virt-handler raises the memory lock limit of the pod accordingly, the locked memory still counts against its memory limit.
+                                      type: boolean
+                                    overcommitPercent:
+                                      description: OvercommitPercent is the percentage of the memory requested for the pod the guest is given, e.g. 150 requests two thirds of the guest memory. It replaces the memoryOvercommit of the cluster for the vmi and is limited to its maxMemoryOvercommit. It requires the guest memory, which the memory request is computed from.
+                                      format: int32
+                                      type: integer
                                   type: object
+                                onCrash:
+                                  description: 'OnCrash is the action taken when the guest crashes, e.g. on a kernel panic. One of: destroy, restart, preserve. Defaults to destroy.'
+                                  type: string
                                 resources:
                                   description: Resources describes the Compute Resources required by this vmi.
                                   properties:
@@ -8983,22 +10931,81 @@ var CRDsValidation map[string]string = map[string]string{
                                       description: Requests is a description of the initial vmi resources. Valid resource keys are "memory" and "cpu".
                                       type: object
                                   type: object
+                                selinux:
+                                  description: SELinux configures the SELinux context of the virt-launcher running the domain.
+                                  properties:
+                                    launcherType:
+                                      description: LauncherType is the SELinux type the virt-launcher runs with, in place of the cluster wide launcher type. The VMI is only scheduled on nodes advertising this type as available.
+                                      type: string
+                                  type: object
                               required:
                               - devices
                               type: object
                             evictionStrategy:
-                              description: EvictionStrategy can be set to "LiveMigrate" if the VirtualMachineInstance should be migrated instead of shut-off in case of a node drain.
+                              description: EvictionStrategy can be set to "LiveMigrate" if the VirtualMachineInstance should be migrated instead of shut-off in case of a node drain, or to "ProtectUntilDrained" if it should be shut down gracefully once its node is drained.
                               type: string
+                            freezeHooks:
+                              description: Commands run in the guest through the guest agent around the freeze of its filesystems, e.g. to flush and quiesce applications before the disks are snapshotted.
+                              properties:
+                                postThaw:
+                                  description: PostThaw is run after the guest filesystems are thawed again
+                                  properties:
+                                    command:
+                                      description: Command is the command line to execute in the guest, starting with the absolute path of the executable. It is not run in a shell.
+                                      items:
+                                        type: string
+                                      type: array
+                                    failurePolicy:
+                                      description: 'FailurePolicy tells what happens when the hook fails or times out: Abort fails the freeze, respectively the thaw, Continue only logs the failure. Defaults to Abort.'
+                                      type: string
+                                    timeoutSeconds:
+                                      description: Number of seconds after which the hook is considered failed. Defaults to 10 seconds. Minimum value is 1, maximum value is 15.
+                                      format: int32
+                                      type: integer
+                                  required:
+                                  - command
+                                  type: object
+                                preFreeze:
+                                  description: PreFreeze is run before the guest filesystems are frozen
+                                  properties:
+                                    command:
+                                      description: Command is the command line to execute in the guest, starting with the absolute path of the executable. It is not run in a shell.
+                                      items:
+                                        type: string
+                                      type: array
+                                    failurePolicy:
+                                      description: 'FailurePolicy tells what happens when the hook fails or times out: Abort fails the freeze, respectively the thaw, Continue only logs the failure. Defaults to Abort.'
+                                      type: string
+                                    timeoutSeconds:
+                                      description: Number of seconds after which the hook is considered failed. Defaults to 10 seconds. Minimum value is 1, maximum value is 15.
+                                      format: int32
+                                      type: integer
+                                  required:
+                                  - command
+                                  type: object
+                              type: object
                             hostname:
                               description: Specifies the hostname of the vmi If not specified, the hostname will be set to the name of the vmi, if dhcp or cloud-init is configured properly.
                               type: string
                             livenessProbe:
                               description: 'Periodic probe of VirtualMachineInstance liveness. VirtualmachineInstances will be stopped if the probe fails. Cannot be updated. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes'
                               properties:
+                                exec:
+                                  description: Exec specifies a command run in the guest through the guest agent, the probe succeeds if the command exits with 0. Only supported by startup probes.
+                                  properties:
+                                    command:
+                                      description: Command is the command line to execute inside the container, the working directory for the command  is root ('/') in the container's filesystem. The command is simply exec'd, it is not run inside a shell, so traditional shell instructions ('|', etc) won't work. To use a shell, you need to explicitly call out to that shell. Exit status of 0 is treated as live/healthy and non-zero is unhealthy.
+                                      items:
+                                        type: string
+                                      type: array
+                                  type: object
                                 failureThreshold:
                                   description: Minimum consecutive failures for the probe to be considered failed after having succeeded. Defaults to 3. Minimum value is 1.
                                   format: int32
                                   type: integer
+                                guestAgentPing:
+                                  description: GuestAgentPing contacts the guest agent, the probe succeeds if it answers. Only supported by startup probes.
+                                  type: object
                                 httpGet:
                                   description: HTTPGet specifies the http request to perform.
                                   properties:
@@ -9068,6 +11075,9 @@ var CRDsValidation map[string]string = map[string]string{
                                   format: int32
                                   type: integer
                               type: object
+                            livenessProbeFailureAction:
+                              description: Action taken once the liveness probe failed. Restart, the default, stops the VirtualMachineInstance. Migrate live migrates it off its node instead, or stops it if it is not live migratable. Migrate requires a livenessProbe and no readinessProbe, the liveness probe then drives the readiness of the VirtualMachineInstance. Cannot be updated.
+                              type: string
                             networks:
                               description: List of networks that can be attached to a vm's virtual interface.
                               items:
@@ -9110,10 +11120,22 @@ var CRDsValidation map[string]string = map[string]string{
                             readinessProbe:
                               description: 'Periodic probe of VirtualMachineInstance service readiness. VirtualmachineInstances will be removed from service endpoints if the probe fails. Cannot be updated. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes'
                               properties:
+                                exec:
+                                  description: Exec specifies a command run in the guest through the guest agent, the probe succeeds if the command exits with 0. Only supported by startup probes.
+                                  properties:
+                                    command:
+                                      description: Command is the command line to execute inside the container, the working directory for the command  is root ('/') in the container's filesystem. The command is simply exec'd, it is not run inside a shell, so traditional shell instructions ('|', etc) won't work. To use a shell, you need to explicitly call out to that shell. Exit status of 0 is treated as live/healthy and non-zero is unhealthy.
+                                      items:
+                                        type: string
+                                      type: array
+                                  type: object
                                 failureThreshold:
                                   description: Minimum consecutive failures for the probe to be considered failed after having succeeded. Defaults to 3. Minimum value is 1.
                                   format: int32
                                   type: integer
+                                guestAgentPing:
+                                  description: GuestAgentPing contacts the guest agent, the probe succeeds if it answers. Only supported by startup probes.
+                                  type: object
                                 httpGet:
                                   description: HTTPGet specifies the http request to perform.
                                   properties:
@@ -9186,6 +11208,94 @@ var CRDsValidation map[string]string = map[string]string{
                             schedulerName:
                               description: If specified, the VMI will be dispatched by specified scheduler. If not specified, the VMI will be dispatched by default scheduler.
                               type: string
+                            startupProbe:
+                              description: Probe of the VirtualMachineInstance startup, evaluated through the guest agent. The VirtualMachineInstance is not ready until the probe succeeded. Only guestAgentPing and exec probes are supported. Cannot be updated.
+                              properties:
+                                exec:
+                                  description: Exec specifies a command run in the guest through the guest agent, the probe succeeds if the command exits with 0. Only supported by startup probes.
+                                  properties:
+                                    command:
+                                      description: Command is the command line to execute inside the container, the working directory for the command  is root ('/') in the container's filesystem. The command is simply exec'd, it is not run inside a shell, so traditional shell instructions ('|', etc) won't work. To use a shell, you need to explicitly call out to that shell. Exit status of 0 is treated as live/healthy and non-zero is unhealthy.
+                                      items:
+                                        type: string
+                                      type: array
+                                  type: object
+                                failureThreshold:
+                                  description: Minimum consecutive failures for the probe to be considered failed after having succeeded. Defaults to 3. Minimum value is 1.
+                                  format: int32
+                                  type: integer
+                                guestAgentPing:
+                                  description: GuestAgentPing contacts the guest agent, the probe succeeds if it answers. Only supported by startup probes.
+                                  type: object
+                                httpGet:
+                                  description: HTTPGet specifies the http request to perform.
+                                  properties:
+                                    host:
+                                      description: Host name to connect to, defaults to the pod IP. You probably want to set "Host" in httpHeaders instead.
+                                      type: string
+                                    httpHeaders:
+                                      description: Custom headers to set in the request. HTTP allows repeated headers.
+                                      items:
+                                        description: HTTPHeader describes a custom header to be used in HTTP probes
+                                        properties:
+                                          name:
+                                            description: The header field name
+                                            type: string
+                                          value:
+                                            description: The header field value
+                                            type: string
+                                        required:
+                                        - name
+                                        - value
+                                        type: object
+                                      type: array
+                                    path:
+                                      description: Path to access on the HTTP server.
+                                      type: string
+                                    port:
+                                      anyOf:
+                                      - type: integer
+                                      - type: string
+                                      description: Name or number of the port to access on the container. Number must be in the range 1 to 65535. Name must be an IANA_SVC_NAME.
+                                      x-kubernetes-int-or-string: true
+                                    scheme:
+                                      description: Scheme to use for connecting to the host. Defaults to HTTP.
+                                      type: string
+                                  required:
+                                  - port
+                                  type: object
+                                initialDelaySeconds:
+                                  description: 'Number of seconds after the VirtualMachineInstance has started before liveness probes are initiated. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes'
+                                  format: int32
+                                  type: integer
+                                periodSeconds:
+                                  description: How often (in seconds) to perform the probe. Default to 10 seconds. Minimum value is 1.
+                                  format: int32
+                                  type: integer
+                                successThreshold:
+                                  description: Minimum consecutive successes for the probe to be considered successful after having failed. Defaults to 1. Must be 1 for liveness. Minimum value is 1.
+                                  format: int32
+                                  type: integer
+                                tcpSocket:
+                                  description: 'TCPSocket specifies an action involving a TCP port. TCP hooks not yet supported TODO: implement a realistic TCP lifecycle hook'
+                                  properties:
+                                    host:
+                                      description: 'Optional: Host name to connect to, defaults to the pod IP.'
+                                      type: string
+                                    port:
+                                      anyOf:
+                                      - type: integer
+                                      - type: string
+                                      description: Number or name of the port to access on the container. Number must be in the range 1 to 65535. Name must be an IANA_SVC_NAME.
+                                      x-kubernetes-int-or-string: true
+                                  required:
+                                  - port
+                                  type: object
+                                timeoutSeconds:
+                                  description: 'Number of seconds after which the probe times out. Defaults to 1 second. Minimum value is 1. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes'
+                                  format: int32
+                                  type: integer
+                              type: object
                             subdomain:
                               description: If specified, the fully qualified vmi hostname will be "<hostname>.<subdomain>.<pod namespace>.svc.<cluster domain>". If not specified, the vmi will not have a domainname at all. The DNS entry will resolve to the vmi, no matter if the vmi itself can pick up a hostname.
                               type: string
@@ -9466,6 +11576,9 @@ var CRDsValidation map[string]string = map[string]string{
                                 - name
                                 type: object
                               type: array
+                            workloadClass:
+                              description: WorkloadClass groups the VMIs to spread across nodes. The pods of the VMIs of the same class in a namespace prefer not to be scheduled on the same node. It has to be a DNS-1123 label.
+                              type: string
                           required:
                           - domain
                           type: object
@@ -9505,6 +11618,11 @@ var CRDsValidation map[string]string = map[string]string{
                     created:
                       description: Created indicates if the virtual machine is created in the cluster
                       type: boolean
+                    lastScheduledTransition:
+                      description: LastScheduledTransition is the time of the latest start or stop of the schedule handled by the controller.
+                      format: date-time
+                      nullable: true
+                      type: string
                     ready:
                       description: Ready indicates if the virtual machine is running and ready
                       type: boolean
@@ -9588,6 +11706,39 @@ var CRDsValidation map[string]string = map[string]string{
                                   io:
                                     description: 'IO specifies which QEMU disk IO mode should be used. Supported values are: native, default, threads.'
                                     type: string
+                                  ioThrottleGroup:
+                                    description: IOThrottleGroup makes the disk share the I/O limits of the group with all other disks of the vmi in the same group.
+                                    properties:
+                                      name:
+                                        description: Name of the throttle group.
+                                        type: string
+                                      readBytesPerSec:
+                                        description: ReadBytesPerSec limits the read bytes per second.
+                                        format: int64
+                                        type: integer
+                                      readIOPS:
+                                        description: ReadIOPS limits the read operations per second.
+                                        format: int64
+                                        type: integer
+                                      totalBytesPerSec:
+                                        description: TotalBytesPerSec limits the read and written bytes per second. Can't be combined with ReadBytesPerSec or WriteBytesPerSec.
+                                        format: int64
+                                        type: integer
+                                      totalIOPS:
+                                        description: TotalIOPS limits the read and write operations per second. Can't be combined with ReadIOPS or WriteIOPS.
+                                        format: int64
+                                        type: integer
+                                      writeBytesPerSec:
+                                        description: WriteBytesPerSec limits the written bytes per second.
+                                        format: int64
+                                        type: integer
+                                      writeIOPS:
+                                        description: WriteIOPS limits the write operations per second.
+                                        format: int64
+                                        type: integer
+                                    required:
+                                    - name
+                                    type: object
                                   lun:
                                     description: Attach a volume as a LUN to the vmi.
                                     properties:
@@ -9607,6 +11758,9 @@ var CRDsValidation map[string]string = map[string]string{
                                   tag:
                                     description: If specified, disk address and its tag will be provided to the guest via config drive metadata
                                     type: string
+                                  wwn:
+                                    description: WWN provides the ability to specify the World Wide Name of the disk device, made up of 16 hexadecimal digits. Only supported on the scsi bus.
+                                    type: string
                                 required:
                                 - name
                                 type: object
@@ -184,6 +184,39 @@ func newApiServerClusterRole() *rbacv1.ClusterRole {
 					"get", "list", "watch",
 				},
 			},
+			{
+				APIGroups: []string{
+					"snapshot.storage.k8s.io",
+				},
+				Resources: []string{
+					"volumesnapshots",
+				},
+				Verbs: []string{
+					"get", "create", "update", "delete",
+				},
+			},
+			{
+				APIGroups: []string{
+					"",
+				},
+				Resources: []string{
+					"persistentvolumeclaims",
+				},
+				Verbs: []string{
+					"get", "create", "delete",
+				},
+			},
+			{
+				APIGroups: []string{
+					"cdi.kubevirt.io",
+				},
+				Resources: []string{
+					"datavolumes",
+				},
+				Verbs: []string{
+					"get", "create",
+				},
+			},
 		},
 	}
 }
@@ -135,8 +135,11 @@ func newAdminClusterRole() *rbacv1.ClusterRole {
 				Resources: []string{
 					"virtualmachineinstances/pause",
 					"virtualmachineinstances/unpause",
+					"virtualmachineinstances/softreboot",
 					"virtualmachineinstances/addvolume",
 					"virtualmachineinstances/removevolume",
+					"virtualmachineinstances/exportdisk",
+					"virtualmachineinstances/memorydump",
 				},
 				Verbs: []string{
 					"get",
@@ -221,8 +224,11 @@ func newEditClusterRole() *rbacv1.ClusterRole {
 				Resources: []string{
 					"virtualmachineinstances/pause",
 					"virtualmachineinstances/unpause",
+					"virtualmachineinstances/softreboot",
 					"virtualmachineinstances/addvolume",
 					"virtualmachineinstances/removevolume",
+					"virtualmachineinstances/exportdisk",
+					"virtualmachineinstances/memorydump",
 				},
 				Verbs: []string{
 					"get",
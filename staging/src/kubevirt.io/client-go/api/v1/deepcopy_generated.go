@@ -132,6 +132,48 @@ func (in *BIOS) DeepCopy() *BIOS {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Boot) DeepCopyInto(out *Boot) {
+	*out = *in
+	if in.Order != nil {
+		in, out := &in.Order, &out.Order
+		*out = make([]BootDevice, len(*in))
+		copy(*out, *in)
+	}
+	if in.RebootTimeoutSeconds != nil {
+		in, out := &in.RebootTimeoutSeconds, &out.RebootTimeoutSeconds
+		*out = new(uint32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Boot.
+func (in *Boot) DeepCopy() *Boot {
+	if in == nil {
+		return nil
+	}
+	out := new(Boot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootDevice) DeepCopyInto(out *BootDevice) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootDevice.
+func (in *BootDevice) DeepCopy() *BootDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(BootDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Bootloader) DeepCopyInto(out *Bootloader) {
 	*out = *in
@@ -678,7 +720,14 @@ func (in *Devices) DeepCopyInto(out *Devices) {
 	if in.HostDevices != nil {
 		in, out := &in.HostDevices, &out.HostDevices
 		*out = make([]HostDevice, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AgentConnectTimeoutSeconds != nil {
+		in, out := &in.AgentConnectTimeoutSeconds, &out.AgentConnectTimeoutSeconds
+		*out = new(int32)
+		**out = **in
 	}
 	return
 }
@@ -707,6 +756,11 @@ func (in *Disk) DeepCopyInto(out *Disk) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.IOThrottleGroup != nil {
+		in, out := &in.IOThrottleGroup, &out.IOThrottleGroup
+		*out = new(IOThrottleGroup)
+		**out = **in
+	}
 	return
 }
 
@@ -772,6 +826,22 @@ func (in *DiskTarget) DeepCopy() *DiskTarget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainSELinux) DeepCopyInto(out *DomainSELinux) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainSELinux.
+func (in *DomainSELinux) DeepCopy() *DomainSELinux {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainSELinux)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DomainSpec) DeepCopyInto(out *DomainSpec) {
 	*out = *in
@@ -813,6 +883,21 @@ func (in *DomainSpec) DeepCopyInto(out *DomainSpec) {
 		*out = new(Chassis)
 		**out = **in
 	}
+	if in.SELinux != nil {
+		in, out := &in.SELinux, &out.SELinux
+		*out = new(DomainSELinux)
+		**out = **in
+	}
+	if in.OnCrash != nil {
+		in, out := &in.OnCrash, &out.OnCrash
+		*out = new(CrashAction)
+		**out = **in
+	}
+	if in.Boot != nil {
+		in, out := &in.Boot, &out.Boot
+		*out = new(Boot)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -857,6 +942,11 @@ func (in *EFI) DeepCopyInto(out *EFI) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.SecureBootCertificates != nil {
+		in, out := &in.SecureBootCertificates, &out.SecureBootCertificates
+		*out = new(SecureBootCertificates)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -908,6 +998,27 @@ func (in *EphemeralVolumeSource) DeepCopy() *EphemeralVolumeSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportDiskOptions) DeepCopyInto(out *ExportDiskOptions) {
+	*out = *in
+	if in.VolumeSnapshotClassName != nil {
+		in, out := &in.VolumeSnapshotClassName, &out.VolumeSnapshotClassName
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportDiskOptions.
+func (in *ExportDiskOptions) DeepCopy() *ExportDiskOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportDiskOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FeatureAPIC) DeepCopyInto(out *FeatureAPIC) {
 	*out = *in
@@ -1186,6 +1297,11 @@ func (in *Firmware) DeepCopyInto(out *Firmware) {
 		*out = new(Bootloader)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SMBiosSecretRef != nil {
+		in, out := &in.SMBiosSecretRef, &out.SMBiosSecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
 	return
 }
 
@@ -1215,6 +1331,53 @@ func (in *FloppyTarget) DeepCopy() *FloppyTarget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FreezeHook) DeepCopyInto(out *FreezeHook) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FreezeHook.
+func (in *FreezeHook) DeepCopy() *FreezeHook {
+	if in == nil {
+		return nil
+	}
+	out := new(FreezeHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FreezeHooks) DeepCopyInto(out *FreezeHooks) {
+	*out = *in
+	if in.PreFreeze != nil {
+		in, out := &in.PreFreeze, &out.PreFreeze
+		*out = new(FreezeHook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PostThaw != nil {
+		in, out := &in.PostThaw, &out.PostThaw
+		*out = new(FreezeHook)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FreezeHooks.
+func (in *FreezeHooks) DeepCopy() *FreezeHooks {
+	if in == nil {
+		return nil
+	}
+	out := new(FreezeHooks)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GPU) DeepCopyInto(out *GPU) {
 	*out = *in
@@ -1231,6 +1394,22 @@ func (in *GPU) DeepCopy() *GPU {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GuestAgentPing) DeepCopyInto(out *GuestAgentPing) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GuestAgentPing.
+func (in *GuestAgentPing) DeepCopy() *GuestAgentPing {
+	if in == nil {
+		return nil
+	}
+	out := new(GuestAgentPing)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HPETTimer) DeepCopyInto(out *HPETTimer) {
 	*out = *in
@@ -1239,6 +1418,11 @@ func (in *HPETTimer) DeepCopyInto(out *HPETTimer) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Catchup != nil {
+		in, out := &in.Catchup, &out.Catchup
+		*out = new(TimerCatchup)
+		**out = **in
+	}
 	return
 }
 
@@ -1265,6 +1449,16 @@ func (in *Handler) DeepCopyInto(out *Handler) {
 		*out = new(corev1.TCPSocketAction)
 		**out = **in
 	}
+	if in.Exec != nil {
+		in, out := &in.Exec, &out.Exec
+		*out = new(corev1.ExecAction)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GuestAgentPing != nil {
+		in, out := &in.GuestAgentPing, &out.GuestAgentPing
+		*out = new(GuestAgentPing)
+		**out = **in
+	}
 	return
 }
 
@@ -1281,6 +1475,11 @@ func (in *Handler) DeepCopy() *Handler {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostDevice) DeepCopyInto(out *HostDevice) {
 	*out = *in
+	if in.ROM != nil {
+		in, out := &in.ROM, &out.ROM
+		*out = new(HostDeviceROM)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1294,6 +1493,32 @@ func (in *HostDevice) DeepCopy() *HostDevice {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostDeviceROM) DeepCopyInto(out *HostDeviceROM) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostDeviceROM.
+func (in *HostDeviceROM) DeepCopy() *HostDeviceROM {
+	if in == nil {
+		return nil
+	}
+	out := new(HostDeviceROM)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostDisk) DeepCopyInto(out *HostDisk) {
 	*out = *in
@@ -1361,6 +1586,13 @@ func (in *HotplugVolumeStatus) DeepCopy() *HotplugVolumeStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Hugepages) DeepCopyInto(out *Hugepages) {
 	*out = *in
+	if in.PerNUMANode != nil {
+		in, out := &in.PerNUMANode, &out.PerNUMANode
+		*out = make([]NUMANodeHugepages, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1411,6 +1643,22 @@ func (in *I6300ESBWatchdog) DeepCopy() *I6300ESBWatchdog {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IOThrottleGroup) DeepCopyInto(out *IOThrottleGroup) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IOThrottleGroup.
+func (in *IOThrottleGroup) DeepCopy() *IOThrottleGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(IOThrottleGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Input) DeepCopyInto(out *Input) {
 	*out = *in
@@ -1436,6 +1684,11 @@ func (in *Interface) DeepCopyInto(out *Interface) {
 		*out = make([]Port, len(*in))
 		copy(*out, *in)
 	}
+	if in.MTU != nil {
+		in, out := &in.MTU, &out.MTU
+		*out = new(uint)
+		**out = **in
+	}
 	if in.BootOrder != nil {
 		in, out := &in.BootOrder, &out.BootOrder
 		*out = new(uint)
@@ -1716,6 +1969,36 @@ func (in *KubeVirtConfiguration) DeepCopyInto(out *KubeVirtConfiguration) {
 		*out = new(PermittedHostDevices)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MaxDrainGracePeriodSeconds != nil {
+		in, out := &in.MaxDrainGracePeriodSeconds, &out.MaxDrainGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.CustomSELinuxLauncherTypes != nil {
+		in, out := &in.CustomSELinuxLauncherTypes, &out.CustomSELinuxLauncherTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.GuestAgentCommandAllowList != nil {
+		in, out := &in.GuestAgentCommandAllowList, &out.GuestAgentCommandAllowList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SELinuxRelabelParallelism != nil {
+		in, out := &in.SELinuxRelabelParallelism, &out.SELinuxRelabelParallelism
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.SELinuxRelabelTimeoutSeconds != nil {
+		in, out := &in.SELinuxRelabelTimeoutSeconds, &out.SELinuxRelabelTimeoutSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.SELinuxRelabelSkipPaths != nil {
+		in, out := &in.SELinuxRelabelSkipPaths, &out.SELinuxRelabelSkipPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1884,6 +2167,22 @@ func (in *LunTarget) DeepCopy() *LunTarget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MacAddressPool) DeepCopyInto(out *MacAddressPool) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MacAddressPool.
+func (in *MacAddressPool) DeepCopy() *MacAddressPool {
+	if in == nil {
+		return nil
+	}
+	out := new(MacAddressPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Machine) DeepCopyInto(out *Machine) {
 	*out = *in
@@ -1922,13 +2221,23 @@ func (in *Memory) DeepCopyInto(out *Memory) {
 	if in.Hugepages != nil {
 		in, out := &in.Hugepages, &out.Hugepages
 		*out = new(Hugepages)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Guest != nil {
 		in, out := &in.Guest, &out.Guest
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.BalloonFloor != nil {
+		in, out := &in.BalloonFloor, &out.BalloonFloor
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.OvercommitPercent != nil {
+		in, out := &in.OvercommitPercent, &out.OvercommitPercent
+		*out = new(uint32)
+		**out = **in
+	}
 	return
 }
 
@@ -1942,6 +2251,65 @@ func (in *Memory) DeepCopy() *Memory {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemoryDumpOptions) DeepCopyInto(out *MemoryDumpOptions) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemoryDumpOptions.
+func (in *MemoryDumpOptions) DeepCopy() *MemoryDumpOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(MemoryDumpOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemoryDumpStatus) DeepCopyInto(out *MemoryDumpStatus) {
+	*out = *in
+	if in.StartTimestamp != nil {
+		in, out := &in.StartTimestamp, &out.StartTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.EndTimestamp != nil {
+		in, out := &in.EndTimestamp, &out.EndTimestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemoryDumpStatus.
+func (in *MemoryDumpStatus) DeepCopy() *MemoryDumpStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MemoryDumpStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemoryOvercommitStatus) DeepCopyInto(out *MemoryOvercommitStatus) {
+	*out = *in
+	out.GuestMemory = in.GuestMemory.DeepCopy()
+	out.MemoryRequest = in.MemoryRequest.DeepCopy()
+	out.PodMemoryRequest = in.PodMemoryRequest.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemoryOvercommitStatus.
+func (in *MemoryOvercommitStatus) DeepCopy() *MemoryOvercommitStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MemoryOvercommitStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MigrationConfiguration) DeepCopyInto(out *MigrationConfiguration) {
 	*out = *in
@@ -1990,6 +2358,11 @@ func (in *MigrationConfiguration) DeepCopyInto(out *MigrationConfiguration) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.MaxParallelMigrationThreads != nil {
+		in, out := &in.MaxParallelMigrationThreads, &out.MaxParallelMigrationThreads
+		*out = new(uint32)
+		**out = **in
+	}
 	return
 }
 
@@ -2003,6 +2376,26 @@ func (in *MigrationConfiguration) DeepCopy() *MigrationConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationWarmupStatus) DeepCopyInto(out *MigrationWarmupStatus) {
+	*out = *in
+	if in.LastUpdateTimestamp != nil {
+		in, out := &in.LastUpdateTimestamp, &out.LastUpdateTimestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationWarmupStatus.
+func (in *MigrationWarmupStatus) DeepCopy() *MigrationWarmupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationWarmupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MultusNetwork) DeepCopyInto(out *MultusNetwork) {
 	*out = *in
@@ -2019,6 +2412,23 @@ func (in *MultusNetwork) DeepCopy() *MultusNetwork {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NUMANodeHugepages) DeepCopyInto(out *NUMANodeHugepages) {
+	*out = *in
+	out.Memory = in.Memory.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NUMANodeHugepages.
+func (in *NUMANodeHugepages) DeepCopy() *NUMANodeHugepages {
+	if in == nil {
+		return nil
+	}
+	out := new(NUMANodeHugepages)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Network) DeepCopyInto(out *Network) {
 	*out = *in
@@ -2049,6 +2459,11 @@ func (in *NetworkConfiguration) DeepCopyInto(out *NetworkConfiguration) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.MacAddressPools != nil {
+		in, out := &in.MacAddressPools, &out.MacAddressPools
+		*out = make([]MacAddressPool, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -2131,6 +2546,11 @@ func (in *PITTimer) DeepCopyInto(out *PITTimer) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Catchup != nil {
+		in, out := &in.Catchup, &out.Catchup
+		*out = new(TimerCatchup)
+		**out = **in
+	}
 	return
 }
 
@@ -2280,6 +2700,11 @@ func (in *RTCTimer) DeepCopyInto(out *RTCTimer) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Catchup != nil {
+		in, out := &in.Catchup, &out.Catchup
+		*out = new(TimerCatchup)
+		**out = **in
+	}
 	return
 }
 
@@ -2501,6 +2926,37 @@ func (in *SecretVolumeSource) DeepCopy() *SecretVolumeSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecureBootCertificates) DeepCopyInto(out *SecureBootCertificates) {
+	*out = *in
+	if in.PK != nil {
+		in, out := &in.PK, &out.PK
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.KEK != nil {
+		in, out := &in.KEK, &out.KEK
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.DB != nil {
+		in, out := &in.DB, &out.DB
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecureBootCertificates.
+func (in *SecureBootCertificates) DeepCopy() *SecureBootCertificates {
+	if in == nil {
+		return nil
+	}
+	out := new(SecureBootCertificates)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceAccountVolumeSource) DeepCopyInto(out *ServiceAccountVolumeSource) {
 	*out = *in
@@ -2517,6 +2973,26 @@ func (in *ServiceAccountVolumeSource) DeepCopy() *ServiceAccountVolumeSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SoftRebootStatus) DeepCopyInto(out *SoftRebootStatus) {
+	*out = *in
+	if in.Timestamp != nil {
+		in, out := &in.Timestamp, &out.Timestamp
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SoftRebootStatus.
+func (in *SoftRebootStatus) DeepCopy() *SoftRebootStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SoftRebootStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Timer) DeepCopyInto(out *Timer) {
 	*out = *in
@@ -2558,6 +3034,22 @@ func (in *Timer) DeepCopy() *Timer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimerCatchup) DeepCopyInto(out *TimerCatchup) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimerCatchup.
+func (in *TimerCatchup) DeepCopy() *TimerCatchup {
+	if in == nil {
+		return nil
+	}
+	out := new(TimerCatchup)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserPasswordAccessCredential) DeepCopyInto(out *UserPasswordAccessCredential) {
 	*out = *in
@@ -2618,6 +3110,22 @@ func (in *UserPasswordAccessCredentialSource) DeepCopy() *UserPasswordAccessCred
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VCPUPin) DeepCopyInto(out *VCPUPin) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VCPUPin.
+func (in *VCPUPin) DeepCopy() *VCPUPin {
+	if in == nil {
+		return nil
+	}
+	out := new(VCPUPin)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VMISelector) DeepCopyInto(out *VMISelector) {
 	*out = *in
@@ -2927,7 +3435,7 @@ func (in *VirtualMachineInstanceMigration) DeepCopyInto(out *VirtualMachineInsta
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
@@ -3004,6 +3512,16 @@ func (in *VirtualMachineInstanceMigrationList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineInstanceMigrationSpec) DeepCopyInto(out *VirtualMachineInstanceMigrationSpec) {
 	*out = *in
+	if in.Bandwidth != nil {
+		in, out := &in.Bandwidth, &out.Bandwidth
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ParallelMigrationThreads != nil {
+		in, out := &in.ParallelMigrationThreads, &out.ParallelMigrationThreads
+		*out = new(uint32)
+		**out = **in
+	}
 	return
 }
 
@@ -3035,6 +3553,26 @@ func (in *VirtualMachineInstanceMigrationState) DeepCopyInto(out *VirtualMachine
 			(*out)[key] = val
 		}
 	}
+	if in.Bandwidth != nil {
+		in, out := &in.Bandwidth, &out.Bandwidth
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ParallelMigrationThreads != nil {
+		in, out := &in.ParallelMigrationThreads, &out.ParallelMigrationThreads
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.WarmupStatus != nil {
+		in, out := &in.WarmupStatus, &out.WarmupStatus
+		*out = new(MigrationWarmupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TargetVCPUPinning != nil {
+		in, out := &in.TargetVCPUPinning, &out.TargetVCPUPinning
+		*out = make([]VCPUPin, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -3058,6 +3596,21 @@ func (in *VirtualMachineInstanceMigrationStatus) DeepCopyInto(out *VirtualMachin
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Warmup != nil {
+		in, out := &in.Warmup, &out.Warmup
+		*out = new(MigrationWarmupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Bandwidth != nil {
+		in, out := &in.Bandwidth, &out.Bandwidth
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ParallelMigrationThreads != nil {
+		in, out := &in.ParallelMigrationThreads, &out.ParallelMigrationThreads
+		*out = new(uint32)
+		**out = **in
+	}
 	return
 }
 
@@ -3357,6 +3910,16 @@ func (in *VirtualMachineInstanceSpec) DeepCopyInto(out *VirtualMachineInstanceSp
 		*out = new(Probe)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = new(Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FreezeHooks != nil {
+		in, out := &in.FreezeHooks, &out.FreezeHooks
+		*out = new(FreezeHooks)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Networks != nil {
 		in, out := &in.Networks, &out.Networks
 		*out = make([]Network, len(*in))
@@ -3431,6 +3994,26 @@ func (in *VirtualMachineInstanceStatus) DeepCopyInto(out *VirtualMachineInstance
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DrainGracePeriodSeconds != nil {
+		in, out := &in.DrainGracePeriodSeconds, &out.DrainGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.LastSoftReboot != nil {
+		in, out := &in.LastSoftReboot, &out.LastSoftReboot
+		*out = new(SoftRebootStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MemoryDump != nil {
+		in, out := &in.MemoryDump, &out.MemoryDump
+		*out = new(MemoryDumpStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MemoryOvercommit != nil {
+		in, out := &in.MemoryOvercommit, &out.MemoryOvercommit
+		*out = new(MemoryOvercommitStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -3495,6 +4078,22 @@ func (in *VirtualMachineList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineSchedule) DeepCopyInto(out *VirtualMachineSchedule) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineSchedule.
+func (in *VirtualMachineSchedule) DeepCopy() *VirtualMachineSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachineSpec) DeepCopyInto(out *VirtualMachineSpec) {
 	*out = *in
@@ -3508,6 +4107,11 @@ func (in *VirtualMachineSpec) DeepCopyInto(out *VirtualMachineSpec) {
 		*out = new(VirtualMachineRunStrategy)
 		**out = **in
 	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(VirtualMachineSchedule)
+		**out = **in
+	}
 	if in.Template != nil {
 		in, out := &in.Template, &out.Template
 		*out = new(VirtualMachineInstanceTemplateSpec)
@@ -3595,6 +4199,10 @@ func (in *VirtualMachineStatus) DeepCopyInto(out *VirtualMachineStatus) {
 		*out = make([]VolumeSnapshotStatus, len(*in))
 		copy(*out, *in)
 	}
+	if in.LastScheduledTransition != nil {
+		in, out := &in.LastScheduledTransition, &out.LastScheduledTransition
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
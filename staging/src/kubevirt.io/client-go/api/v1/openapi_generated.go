@@ -298,6 +298,8 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"kubevirt.io/client-go/api/v1.AddVolumeOptions":                                           schema_kubevirtio_client_go_api_v1_AddVolumeOptions(ref),
 		"kubevirt.io/client-go/api/v1.AuthorizedKeysFile":                                         schema_kubevirtio_client_go_api_v1_AuthorizedKeysFile(ref),
 		"kubevirt.io/client-go/api/v1.BIOS":                                                       schema_kubevirtio_client_go_api_v1_BIOS(ref),
+		"kubevirt.io/client-go/api/v1.Boot":                                                       schema_kubevirtio_client_go_api_v1_Boot(ref),
+		"kubevirt.io/client-go/api/v1.BootDevice":                                                 schema_kubevirtio_client_go_api_v1_BootDevice(ref),
 		"kubevirt.io/client-go/api/v1.Bootloader":                                                 schema_kubevirtio_client_go_api_v1_Bootloader(ref),
 		"kubevirt.io/client-go/api/v1.CDRomTarget":                                                schema_kubevirtio_client_go_api_v1_CDRomTarget(ref),
 		"kubevirt.io/client-go/api/v1.CPU":                                                        schema_kubevirtio_client_go_api_v1_CPU(ref),
@@ -324,11 +326,13 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"kubevirt.io/client-go/api/v1.Disk":                                                       schema_kubevirtio_client_go_api_v1_Disk(ref),
 		"kubevirt.io/client-go/api/v1.DiskDevice":                                                 schema_kubevirtio_client_go_api_v1_DiskDevice(ref),
 		"kubevirt.io/client-go/api/v1.DiskTarget":                                                 schema_kubevirtio_client_go_api_v1_DiskTarget(ref),
+		"kubevirt.io/client-go/api/v1.DomainSELinux":                                              schema_kubevirtio_client_go_api_v1_DomainSELinux(ref),
 		"kubevirt.io/client-go/api/v1.DomainSpec":                                                 schema_kubevirtio_client_go_api_v1_DomainSpec(ref),
 		"kubevirt.io/client-go/api/v1.DownwardAPIVolumeSource":                                    schema_kubevirtio_client_go_api_v1_DownwardAPIVolumeSource(ref),
 		"kubevirt.io/client-go/api/v1.EFI":                                                        schema_kubevirtio_client_go_api_v1_EFI(ref),
 		"kubevirt.io/client-go/api/v1.EmptyDiskSource":                                            schema_kubevirtio_client_go_api_v1_EmptyDiskSource(ref),
 		"kubevirt.io/client-go/api/v1.EphemeralVolumeSource":                                      schema_kubevirtio_client_go_api_v1_EphemeralVolumeSource(ref),
+		"kubevirt.io/client-go/api/v1.ExportDiskOptions":                                          schema_kubevirtio_client_go_api_v1_ExportDiskOptions(ref),
 		"kubevirt.io/client-go/api/v1.FeatureAPIC":                                                schema_kubevirtio_client_go_api_v1_FeatureAPIC(ref),
 		"kubevirt.io/client-go/api/v1.FeatureHyperv":                                              schema_kubevirtio_client_go_api_v1_FeatureHyperv(ref),
 		"kubevirt.io/client-go/api/v1.FeatureKVM":                                                 schema_kubevirtio_client_go_api_v1_FeatureKVM(ref),
@@ -340,15 +344,20 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"kubevirt.io/client-go/api/v1.FilesystemVirtiofs":                                         schema_kubevirtio_client_go_api_v1_FilesystemVirtiofs(ref),
 		"kubevirt.io/client-go/api/v1.Firmware":                                                   schema_kubevirtio_client_go_api_v1_Firmware(ref),
 		"kubevirt.io/client-go/api/v1.FloppyTarget":                                               schema_kubevirtio_client_go_api_v1_FloppyTarget(ref),
+		"kubevirt.io/client-go/api/v1.FreezeHook":                                                 schema_kubevirtio_client_go_api_v1_FreezeHook(ref),
+		"kubevirt.io/client-go/api/v1.FreezeHooks":                                                schema_kubevirtio_client_go_api_v1_FreezeHooks(ref),
 		"kubevirt.io/client-go/api/v1.GPU":                                                        schema_kubevirtio_client_go_api_v1_GPU(ref),
+		"kubevirt.io/client-go/api/v1.GuestAgentPing":                                             schema_kubevirtio_client_go_api_v1_GuestAgentPing(ref),
 		"kubevirt.io/client-go/api/v1.HPETTimer":                                                  schema_kubevirtio_client_go_api_v1_HPETTimer(ref),
 		"kubevirt.io/client-go/api/v1.HostDevice":                                                 schema_kubevirtio_client_go_api_v1_HostDevice(ref),
+		"kubevirt.io/client-go/api/v1.HostDeviceROM":                                              schema_kubevirtio_client_go_api_v1_HostDeviceROM(ref),
 		"kubevirt.io/client-go/api/v1.HostDisk":                                                   schema_kubevirtio_client_go_api_v1_HostDisk(ref),
 		"kubevirt.io/client-go/api/v1.HotplugVolumeSource":                                        schema_kubevirtio_client_go_api_v1_HotplugVolumeSource(ref),
 		"kubevirt.io/client-go/api/v1.HotplugVolumeStatus":                                        schema_kubevirtio_client_go_api_v1_HotplugVolumeStatus(ref),
 		"kubevirt.io/client-go/api/v1.Hugepages":                                                  schema_kubevirtio_client_go_api_v1_Hugepages(ref),
 		"kubevirt.io/client-go/api/v1.HypervTimer":                                                schema_kubevirtio_client_go_api_v1_HypervTimer(ref),
 		"kubevirt.io/client-go/api/v1.I6300ESBWatchdog":                                           schema_kubevirtio_client_go_api_v1_I6300ESBWatchdog(ref),
+		"kubevirt.io/client-go/api/v1.IOThrottleGroup":                                            schema_kubevirtio_client_go_api_v1_IOThrottleGroup(ref),
 		"kubevirt.io/client-go/api/v1.Input":                                                      schema_kubevirtio_client_go_api_v1_Input(ref),
 		"kubevirt.io/client-go/api/v1.Interface":                                                  schema_kubevirtio_client_go_api_v1_Interface(ref),
 		"kubevirt.io/client-go/api/v1.InterfaceBindingMethod":                                     schema_kubevirtio_client_go_api_v1_InterfaceBindingMethod(ref),
@@ -368,11 +377,17 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"kubevirt.io/client-go/api/v1.KubeVirtStatus":                                             schema_kubevirtio_client_go_api_v1_KubeVirtStatus(ref),
 		"kubevirt.io/client-go/api/v1.LogVerbosity":                                               schema_kubevirtio_client_go_api_v1_LogVerbosity(ref),
 		"kubevirt.io/client-go/api/v1.LunTarget":                                                  schema_kubevirtio_client_go_api_v1_LunTarget(ref),
+		"kubevirt.io/client-go/api/v1.MacAddressPool":                                             schema_kubevirtio_client_go_api_v1_MacAddressPool(ref),
 		"kubevirt.io/client-go/api/v1.Machine":                                                    schema_kubevirtio_client_go_api_v1_Machine(ref),
 		"kubevirt.io/client-go/api/v1.MediatedHostDevice":                                         schema_kubevirtio_client_go_api_v1_MediatedHostDevice(ref),
 		"kubevirt.io/client-go/api/v1.Memory":                                                     schema_kubevirtio_client_go_api_v1_Memory(ref),
+		"kubevirt.io/client-go/api/v1.MemoryDumpOptions":                                          schema_kubevirtio_client_go_api_v1_MemoryDumpOptions(ref),
+		"kubevirt.io/client-go/api/v1.MemoryDumpStatus":                                           schema_kubevirtio_client_go_api_v1_MemoryDumpStatus(ref),
+		"kubevirt.io/client-go/api/v1.MemoryOvercommitStatus":                                     schema_kubevirtio_client_go_api_v1_MemoryOvercommitStatus(ref),
 		"kubevirt.io/client-go/api/v1.MigrationConfiguration":                                     schema_kubevirtio_client_go_api_v1_MigrationConfiguration(ref),
+		"kubevirt.io/client-go/api/v1.MigrationWarmupStatus":                                      schema_kubevirtio_client_go_api_v1_MigrationWarmupStatus(ref),
 		"kubevirt.io/client-go/api/v1.MultusNetwork":                                              schema_kubevirtio_client_go_api_v1_MultusNetwork(ref),
+		"kubevirt.io/client-go/api/v1.NUMANodeHugepages":                                          schema_kubevirtio_client_go_api_v1_NUMANodeHugepages(ref),
 		"kubevirt.io/client-go/api/v1.Network":                                                    schema_kubevirtio_client_go_api_v1_Network(ref),
 		"kubevirt.io/client-go/api/v1.NetworkConfiguration":                                       schema_kubevirtio_client_go_api_v1_NetworkConfiguration(ref),
 		"kubevirt.io/client-go/api/v1.NetworkSource":                                              schema_kubevirtio_client_go_api_v1_NetworkSource(ref),
@@ -395,11 +410,15 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"kubevirt.io/client-go/api/v1.SSHPublicKeyAccessCredentialPropagationMethod":              schema_kubevirtio_client_go_api_v1_SSHPublicKeyAccessCredentialPropagationMethod(ref),
 		"kubevirt.io/client-go/api/v1.SSHPublicKeyAccessCredentialSource":                         schema_kubevirtio_client_go_api_v1_SSHPublicKeyAccessCredentialSource(ref),
 		"kubevirt.io/client-go/api/v1.SecretVolumeSource":                                         schema_kubevirtio_client_go_api_v1_SecretVolumeSource(ref),
+		"kubevirt.io/client-go/api/v1.SecureBootCertificates":                                     schema_kubevirtio_client_go_api_v1_SecureBootCertificates(ref),
 		"kubevirt.io/client-go/api/v1.ServiceAccountVolumeSource":                                 schema_kubevirtio_client_go_api_v1_ServiceAccountVolumeSource(ref),
+		"kubevirt.io/client-go/api/v1.SoftRebootStatus":                                           schema_kubevirtio_client_go_api_v1_SoftRebootStatus(ref),
 		"kubevirt.io/client-go/api/v1.Timer":                                                      schema_kubevirtio_client_go_api_v1_Timer(ref),
+		"kubevirt.io/client-go/api/v1.TimerCatchup":                                               schema_kubevirtio_client_go_api_v1_TimerCatchup(ref),
 		"kubevirt.io/client-go/api/v1.UserPasswordAccessCredential":                               schema_kubevirtio_client_go_api_v1_UserPasswordAccessCredential(ref),
 		"kubevirt.io/client-go/api/v1.UserPasswordAccessCredentialPropagationMethod":              schema_kubevirtio_client_go_api_v1_UserPasswordAccessCredentialPropagationMethod(ref),
 		"kubevirt.io/client-go/api/v1.UserPasswordAccessCredentialSource":                         schema_kubevirtio_client_go_api_v1_UserPasswordAccessCredentialSource(ref),
+		"kubevirt.io/client-go/api/v1.VCPUPin":                                                    schema_kubevirtio_client_go_api_v1_VCPUPin(ref),
 		"kubevirt.io/client-go/api/v1.VirtualMachine":                                             schema_kubevirtio_client_go_api_v1_VirtualMachine(ref),
 		"kubevirt.io/client-go/api/v1.VirtualMachineCondition":                                    schema_kubevirtio_client_go_api_v1_VirtualMachineCondition(ref),
 		"kubevirt.io/client-go/api/v1.VirtualMachineInstance":                                     schema_kubevirtio_client_go_api_v1_VirtualMachineInstance(ref),
@@ -431,6 +450,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"kubevirt.io/client-go/api/v1.VirtualMachineInstanceStatus":                               schema_kubevirtio_client_go_api_v1_VirtualMachineInstanceStatus(ref),
 		"kubevirt.io/client-go/api/v1.VirtualMachineInstanceTemplateSpec":                         schema_kubevirtio_client_go_api_v1_VirtualMachineInstanceTemplateSpec(ref),
 		"kubevirt.io/client-go/api/v1.VirtualMachineList":                                         schema_kubevirtio_client_go_api_v1_VirtualMachineList(ref),
+		"kubevirt.io/client-go/api/v1.VirtualMachineSchedule":                                     schema_kubevirtio_client_go_api_v1_VirtualMachineSchedule(ref),
 		"kubevirt.io/client-go/api/v1.VirtualMachineSpec":                                         schema_kubevirtio_client_go_api_v1_VirtualMachineSpec(ref),
 		"kubevirt.io/client-go/api/v1.VirtualMachineStateChangeRequest":                           schema_kubevirtio_client_go_api_v1_VirtualMachineStateChangeRequest(ref),
 		"kubevirt.io/client-go/api/v1.VirtualMachineStatus":                                       schema_kubevirtio_client_go_api_v1_VirtualMachineStatus(ref),
@@ -13995,6 +14015,80 @@ func schema_kubevirtio_client_go_api_v1_BIOS(ref common.ReferenceCallback) commo
 	}
 }
 
+func schema_kubevirtio_client_go_api_v1_Boot(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Boot configures the devices the guest boots from.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"order": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Order lists the disks and interfaces to boot from. The firmware tries them in this order and falls back to the next one if a device is not bootable. It can't be combined with bootOrder on the disks and interfaces.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("kubevirt.io/client-go/api/v1.BootDevice"),
+									},
+								},
+							},
+						},
+					},
+					"networkBootLast": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NetworkBootLast boots from the network (PXE) through the interfaces without a boot order, after all the other boot devices were tried.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"rebootTimeoutSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RebootTimeoutSeconds makes the firmware retry to boot after this many seconds if none of the boot devices is bootable, instead of halting. Only supported with BIOS.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"kubevirt.io/client-go/api/v1.BootDevice"},
+	}
+}
+
+func schema_kubevirtio_client_go_api_v1_BootDevice(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BootDevice references a disk or an interface to boot from. Exactly one of its fields has to be set.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"disk": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Disk is the name of the disk to boot from.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"interface": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Interface is the name of the interface to boot from.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_kubevirtio_client_go_api_v1_Bootloader(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -14754,6 +14848,12 @@ func schema_kubevirtio_client_go_api_v1_DeveloperConfiguration(ref common.Refere
 							Format: "int32",
 						},
 					},
+					"maxMemoryOvercommit": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
 					"nodeSelectors": {
 						SchemaProps: spec.SchemaProps{
 							Type: []string{"object"},
@@ -14960,6 +15060,13 @@ func schema_kubevirtio_client_go_api_v1_Devices(ref common.ReferenceCallback) co
 							},
 						},
 					},
+					"agentConnectTimeoutSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "AgentConnectTimeoutSeconds is how long the operations requiring the guest agent, like freezing the guest filesystems or reading the guest OS info, wait for it to connect before failing. They fail right away if the guest agent isn't connected and no timeout is set. Must be between 0 and 600.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
 				},
 			},
 		},
@@ -15019,6 +15126,13 @@ func schema_kubevirtio_client_go_api_v1_Disk(ref common.ReferenceCallback) commo
 							Format:      "",
 						},
 					},
+					"wwn": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WWN provides the ability to specify the World Wide Name of the disk device, made up of 16 hexadecimal digits. Only supported on the scsi bus.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"dedicatedIOThread": {
 						SchemaProps: spec.SchemaProps{
 							Description: "dedicatedIOThread indicates this disk should have an exclusive IO Thread. Enabling this implies useIOThreads = true. Defaults to false.",
@@ -15047,12 +15161,18 @@ func schema_kubevirtio_client_go_api_v1_Disk(ref common.ReferenceCallback) commo
 							Format:      "",
 						},
 					},
+					"ioThrottleGroup": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IOThrottleGroup makes the disk share the I/O limits of the group with all other disks of the vmi in the same group.",
+							Ref:         ref("kubevirt.io/client-go/api/v1.IOThrottleGroup"),
+						},
+					},
 				},
 				Required: []string{"name"},
 			},
 		},
 		Dependencies: []string{
-			"kubevirt.io/client-go/api/v1.CDRomTarget", "kubevirt.io/client-go/api/v1.DiskTarget", "kubevirt.io/client-go/api/v1.FloppyTarget", "kubevirt.io/client-go/api/v1.LunTarget"},
+			"kubevirt.io/client-go/api/v1.CDRomTarget", "kubevirt.io/client-go/api/v1.DiskTarget", "kubevirt.io/client-go/api/v1.FloppyTarget", "kubevirt.io/client-go/api/v1.IOThrottleGroup", "kubevirt.io/client-go/api/v1.LunTarget"},
 	}
 }
 
@@ -15128,6 +15248,26 @@ func schema_kubevirtio_client_go_api_v1_DiskTarget(ref common.ReferenceCallback)
 	}
 }
 
+func schema_kubevirtio_client_go_api_v1_DomainSELinux(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DomainSELinux configures the SELinux context of the virt-launcher running the domain.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"launcherType": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LauncherType is the SELinux type the virt-launcher runs with, in place of the cluster wide launcher type. The VMI is only scheduled on nodes advertising this type as available.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_kubevirtio_client_go_api_v1_DomainSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -15195,12 +15335,31 @@ func schema_kubevirtio_client_go_api_v1_DomainSpec(ref common.ReferenceCallback)
 							Ref:         ref("kubevirt.io/client-go/api/v1.Chassis"),
 						},
 					},
+					"selinux": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SELinux configures the SELinux context of the virt-launcher running the domain.",
+							Ref:         ref("kubevirt.io/client-go/api/v1.DomainSELinux"),
+						},
+					},
+					"onCrash": {
+						SchemaProps: spec.SchemaProps{
+							Description: "OnCrash is the action taken when the guest crashes, e.g. on a kernel panic. One of: destroy, restart, preserve. Defaults to destroy.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"boot": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Boot configures the devices the guest boots from and the order in which the firmware tries them.",
+							Ref:         ref("kubevirt.io/client-go/api/v1.Boot"),
+						},
+					},
 				},
 				Required: []string{"devices"},
 			},
 		},
 		Dependencies: []string{
-			"kubevirt.io/client-go/api/v1.CPU", "kubevirt.io/client-go/api/v1.Chassis", "kubevirt.io/client-go/api/v1.Clock", "kubevirt.io/client-go/api/v1.Devices", "kubevirt.io/client-go/api/v1.Features", "kubevirt.io/client-go/api/v1.Firmware", "kubevirt.io/client-go/api/v1.Machine", "kubevirt.io/client-go/api/v1.Memory", "kubevirt.io/client-go/api/v1.ResourceRequirements"},
+			"kubevirt.io/client-go/api/v1.Boot", "kubevirt.io/client-go/api/v1.CPU", "kubevirt.io/client-go/api/v1.Chassis", "kubevirt.io/client-go/api/v1.Clock", "kubevirt.io/client-go/api/v1.Devices", "kubevirt.io/client-go/api/v1.DomainSELinux", "kubevirt.io/client-go/api/v1.Features", "kubevirt.io/client-go/api/v1.Firmware", "kubevirt.io/client-go/api/v1.Machine", "kubevirt.io/client-go/api/v1.Memory", "kubevirt.io/client-go/api/v1.ResourceRequirements"},
 	}
 }
 
@@ -15253,9 +15412,17 @@ func schema_kubevirtio_client_go_api_v1_EFI(ref common.ReferenceCallback) common
 							Format:      "",
 						},
 					},
+					"secureBootCertificates": {
+						SchemaProps: spec.SchemaProps{
+							Description: "If set, the certificates of the referenced secrets are enrolled in the Secure Boot variable store instead of the default keys of the firmware. Requires SecureBoot.",
+							Ref:         ref("kubevirt.io/client-go/api/v1.SecureBootCertificates"),
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"kubevirt.io/client-go/api/v1.SecureBootCertificates"},
 	}
 }
 
@@ -15301,6 +15468,41 @@ func schema_kubevirtio_client_go_api_v1_EphemeralVolumeSource(ref common.Referen
 	}
 }
 
+func schema_kubevirtio_client_go_api_v1_ExportDiskOptions(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ExportDiskOptions is provided when exporting a volume of a running VirtualMachineInstance to a new DataVolume",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"volumeName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "VolumeName is the name of the volume to export. The volume must be backed by a PersistentVolumeClaim or a DataVolume.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"dataVolumeName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DataVolumeName is the name of the DataVolume to create from the snapshot of the volume.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"volumeSnapshotClassName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "VolumeSnapshotClassName is the VolumeSnapshotClass used to snapshot the volume. The default VolumeSnapshotClass of the CSI driver is used when it is not set.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"volumeName", "dataVolumeName"},
+			},
+		},
+	}
+}
+
 func schema_kubevirtio_client_go_api_v1_FeatureAPIC(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -15600,6 +15802,22 @@ func schema_kubevirtio_client_go_api_v1_FilesystemVirtiofs(ref common.ReferenceC
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
 				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"cache": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Cache selects how virtiofsd caches the shared data and metadata. Supported values: none, auto, always. Defaults to none.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"sandbox": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Sandbox selects how virtiofsd isolates itself from the rest of the host. Supported values: namespace, chroot. Defaults to namespace.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
 			},
 		},
 	}
@@ -15631,11 +15849,17 @@ func schema_kubevirtio_client_go_api_v1_Firmware(ref common.ReferenceCallback) c
 							Format:      "",
 						},
 					},
+					"smbiosSecretRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SMBiosSecretRef references a k8s secret in the namespace of the vmi that contains SMBIOS system information. The keys manufacturer and product set the matching SMBIOS system fields and the key oemStrings holds one SMBIOS OEM string per line. Missing keys are ignored.",
+							Ref:         ref("k8s.io/api/core/v1.LocalObjectReference"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"kubevirt.io/client-go/api/v1.Bootloader"},
+			"k8s.io/api/core/v1.LocalObjectReference", "kubevirt.io/client-go/api/v1.Bootloader"},
 	}
 }
 
@@ -15665,6 +15889,75 @@ func schema_kubevirtio_client_go_api_v1_FloppyTarget(ref common.ReferenceCallbac
 	}
 }
 
+func schema_kubevirtio_client_go_api_v1_FreezeHook(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "FreezeHook is a command executed in the guest through the guest agent",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"command": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Command is the command line to execute in the guest, starting with the absolute path of the executable. It is not run in a shell.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"timeoutSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of seconds after which the hook is considered failed. Defaults to 10 seconds. Minimum value is 1, maximum value is 15.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"failurePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FailurePolicy tells what happens when the hook fails or times out: Abort fails the freeze, respectively the thaw, Continue only logs the failure. Defaults to Abort.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"command"},
+			},
+		},
+	}
+}
+
+func schema_kubevirtio_client_go_api_v1_FreezeHooks(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "FreezeHooks are the commands run in the guest around the freeze of its filesystems",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"preFreeze": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PreFreeze is run before the guest filesystems are frozen",
+							Ref:         ref("kubevirt.io/client-go/api/v1.FreezeHook"),
+						},
+					},
+					"postThaw": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PostThaw is run after the guest filesystems are thawed again",
+							Ref:         ref("kubevirt.io/client-go/api/v1.FreezeHook"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"kubevirt.io/client-go/api/v1.FreezeHook"},
+	}
+}
+
 func schema_kubevirtio_client_go_api_v1_GPU(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -15691,6 +15984,17 @@ func schema_kubevirtio_client_go_api_v1_GPU(ref common.ReferenceCallback) common
 	}
 }
 
+func schema_kubevirtio_client_go_api_v1_GuestAgentPing(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GuestAgentPing configures a probe pinging the guest agent",
+				Type:        []string{"object"},
+			},
+		},
+	}
+}
+
 func schema_kubevirtio_client_go_api_v1_HPETTimer(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -15711,9 +16015,17 @@ func schema_kubevirtio_client_go_api_v1_HPETTimer(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
+					"catchup": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Catchup tunes the catchup tick policy, only valid with it.",
+							Ref:         ref("kubevirt.io/client-go/api/v1.TimerCatchup"),
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"kubevirt.io/client-go/api/v1.TimerCatchup"},
 	}
 }
 
@@ -15736,10 +16048,52 @@ func schema_kubevirtio_client_go_api_v1_HostDevice(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
+					"pciAddress": {
+						SchemaProps: spec.SchemaProps{
+							Description: "If specified, the host device will be placed on the guests pci address with the specified PCI address. For example: 0000:81:01.1",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"rom": {
+						SchemaProps: spec.SchemaProps{
+							Description: "If specified, the ROM image of the referenced config map or secret is exposed to the guest instead of the ROM of the host device. Only supported by PCI host devices.",
+							Ref:         ref("kubevirt.io/client-go/api/v1.HostDeviceROM"),
+						},
+					},
 				},
 				Required: []string{"name", "deviceName"},
 			},
 		},
+		Dependencies: []string{
+			"kubevirt.io/client-go/api/v1.HostDeviceROM"},
+	}
+}
+
+func schema_kubevirtio_client_go_api_v1_HostDeviceROM(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "HostDeviceROM references the config map or the secret, in the namespace of the vmi, holding the ROM image of a host device under the key rom. Exactly one of them is required.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"configMap": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConfigMap references a config map holding the ROM image as binary data.",
+							Ref:         ref("k8s.io/api/core/v1.LocalObjectReference"),
+						},
+					},
+					"secret": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Secret references a secret holding the ROM image.",
+							Ref:         ref("k8s.io/api/core/v1.LocalObjectReference"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.LocalObjectReference"},
 	}
 }
 
@@ -15854,9 +16208,24 @@ func schema_kubevirtio_client_go_api_v1_Hugepages(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
-				},
-			},
-		},
+					"perNUMANode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PerNUMANode splits the guest memory into guest NUMA nodes, each backed by the hugepages of a host NUMA node. The memory of all nodes has to add up to the guest memory, the vCPUs are spread evenly across the nodes.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("kubevirt.io/client-go/api/v1.NUMANodeHugepages"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"kubevirt.io/client-go/api/v1.NUMANodeHugepages"},
 	}
 }
 
@@ -15899,6 +16268,69 @@ func schema_kubevirtio_client_go_api_v1_I6300ESBWatchdog(ref common.ReferenceCal
 	}
 }
 
+func schema_kubevirtio_client_go_api_v1_IOThrottleGroup(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "IOThrottleGroup limits the aggregate I/O of the disks sharing its name. All disks of a group have to set the same limits. A limit of 0 is unlimited.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the throttle group.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"totalIOPS": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TotalIOPS limits the read and write operations per second. Can't be combined with ReadIOPS or WriteIOPS.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"readIOPS": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ReadIOPS limits the read operations per second.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"writeIOPS": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WriteIOPS limits the write operations per second.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"totalBytesPerSec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TotalBytesPerSec limits the read and written bytes per second. Can't be combined with ReadBytesPerSec or WriteBytesPerSec.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"readBytesPerSec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ReadBytesPerSec limits the read bytes per second.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"writeBytesPerSec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WriteBytesPerSec limits the written bytes per second.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+	}
+}
+
 func schema_kubevirtio_client_go_api_v1_Input(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -15998,6 +16430,20 @@ func schema_kubevirtio_client_go_api_v1_Interface(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
+					"macAddressPool": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of a MAC address pool configured in the KubeVirt CR, to draw the MAC address of the interface from if macAddress is not set.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"mtu": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MTU of the interface in the guest, for example 9000 on jumbo-frame networks. Must not exceed the MTU of the pod network, which it defaults to. Only supported with the bridge and masquerade bindings.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
 					"bootOrder": {
 						SchemaProps: spec.SchemaProps{
 							Description: "BootOrder is an integer value > 0, used to determine ordering of boot devices. Lower values take precedence. Each interface or disk that has a boot order must have a unique value. Interfaces without a boot order are not tried.",
@@ -16356,6 +16802,64 @@ func schema_kubevirtio_client_go_api_v1_KubeVirtConfiguration(ref common.Referen
 							Ref: ref("kubevirt.io/client-go/api/v1.PermittedHostDevices"),
 						},
 					},
+					"maxDrainGracePeriodSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"customSELinuxLauncherTypes": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"guestAgentCommandAllowList": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"selinuxRelabelParallelism": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"selinuxRelabelTimeoutSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"selinuxRelabelSkipPaths": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SELinuxRelabelSkipPaths are the paths virt-handler never relabels, e.g. tmpfs backed devices. Entries with glob characters are matched as globs, the others as path prefixes.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
@@ -16709,6 +17213,41 @@ func schema_kubevirtio_client_go_api_v1_LunTarget(ref common.ReferenceCallback)
 	}
 }
 
+func schema_kubevirtio_client_go_api_v1_MacAddressPool(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MacAddressPool is a range of MAC addresses handed out to the interfaces requesting it.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the pool, referenced by the macAddressPool of interfaces.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"rangeStart": {
+						SchemaProps: spec.SchemaProps{
+							Description: "First MAC address of the pool. For example: 02:00:00:00:00:00.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"rangeEnd": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Last MAC address of the pool. For example: 02:00:00:00:ff:ff.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name", "rangeStart", "rangeEnd"},
+			},
+		},
+	}
+}
+
 func schema_kubevirtio_client_go_api_v1_Machine(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -16780,6 +17319,26 @@ func schema_kubevirtio_client_go_api_v1_Memory(ref common.ReferenceCallback) com
 							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
 						},
 					},
+					"balloonFloor": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BalloonFloor is the least amount of memory left to the Guest OS when virt-launcher reclaims memory through the memory balloon, because the memory of the pod comes under pressure. Memory is only reclaimed if set, which requires the memory balloon device.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"locked": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Locked locks the guest memory in host memory, so that it is never swapped out or reclaimed, e.g. for latency sensitive or confidential workloads. virt-handler raises the memory lock limit of the pod accordingly, the locked memory still counts against its memory limit.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"overcommitPercent": {
+						SchemaProps: spec.SchemaProps{
+							Description: "OvercommitPercent is the percentage of the memory requested for the pod the guest is given, e.g. 150 requests two thirds of the guest memory. It replaces the memoryOvercommit of the cluster for the vmi and is limited to its maxMemoryOvercommit. It requires the guest memory, which the memory request is computed from.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
 				},
 			},
 		},
@@ -16788,6 +17347,152 @@ func schema_kubevirtio_client_go_api_v1_Memory(ref common.ReferenceCallback) com
 	}
 }
 
+func schema_kubevirtio_client_go_api_v1_MemoryDumpOptions(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MemoryDumpOptions is provided when dumping the memory of a running VirtualMachineInstance",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"claimName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ClaimName is the name of the PersistentVolumeClaim to write the dump to. The claim must be in filesystem mode and large enough to hold the memory of the guest.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"claimName"},
+			},
+		},
+	}
+}
+
+func schema_kubevirtio_client_go_api_v1_MemoryDumpStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MemoryDumpStatus describes a memory dump of a VirtualMachineInstance",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"claimName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ClaimName is the name of the PersistentVolumeClaim the dump is written to",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Phase is the phase of the dump",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"fileName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FileName is the name of the dump file, at the root of the claim",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"targetPodName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TargetPodName is the name of the pod attaching the claim to the node of the VirtualMachineInstance",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"targetPodUID": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TargetPodUID is the UID of the pod attaching the claim, set once the claim is attached to the node",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"bytesWritten": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BytesWritten is the amount of guest memory written to the claim so far",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"bytesTotal": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BytesTotal is the amount of guest memory to write to the claim",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"startTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartTimestamp is the time the dump was requested",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"endTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EndTimestamp is the time the dump completed or failed",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message is a human readable description of the failure of the dump",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"claimName", "phase", "fileName"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_kubevirtio_client_go_api_v1_MemoryOvercommitStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MemoryOvercommitStatus describes the memory requested for a VirtualMachineInstance with an overcommit percentage",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"percent": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Percent is the overcommit percentage applied to the guest memory",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"guestMemory": {
+						SchemaProps: spec.SchemaProps{
+							Description: "GuestMemory is the memory of the guest",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"memoryRequest": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MemoryRequest is the memory requested for the guest, its memory scaled down by the overcommit percentage",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"podMemoryRequest": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodMemoryRequest is the memory requested by the compute container of the pod, including the overhead of virt-launcher",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+				},
+				Required: []string{"percent", "guestMemory", "memoryRequest", "podMemoryRequest"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
+	}
+}
+
 func schema_kubevirtio_client_go_api_v1_MigrationConfiguration(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -16848,6 +17553,12 @@ func schema_kubevirtio_client_go_api_v1_MigrationConfiguration(ref common.Refere
 							Format: "",
 						},
 					},
+					"maxParallelMigrationThreads": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
 				},
 			},
 		},
@@ -16856,6 +17567,69 @@ func schema_kubevirtio_client_go_api_v1_MigrationConfiguration(ref common.Refere
 	}
 }
 
+func schema_kubevirtio_client_go_api_v1_MigrationWarmupStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MigrationWarmupStatus estimates how close the pre-copy of a warm migration is to converging, by comparing the rate the memory is transferred at with the rate the guest dirties it.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"iteration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of pre-copy passes over the memory of the vmi",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"memoryRemainingBytes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The memory still to be transferred to the target",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"dirtyRateBytesPerSecond": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The rate the guest dirties its memory at",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"transferRateBytesPerSecond": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The rate the memory is transferred to the target at",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"converging": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Indicates that the memory is transferred faster than it is dirtied",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"estimatedDowntimeMilliseconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The time the vmi is expected to be paused for if the cutover is requested now, at the current transfer rate",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"lastUpdateTimestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The time the estimates were last reported",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema_kubevirtio_client_go_api_v1_MultusNetwork(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -16884,6 +17658,42 @@ func schema_kubevirtio_client_go_api_v1_MultusNetwork(ref common.ReferenceCallba
 	}
 }
 
+func schema_kubevirtio_client_go_api_v1_NUMANodeHugepages(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "NUMANodeHugepages places the hugepages of a guest NUMA node on a host NUMA node.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"guestNode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "GuestNode is the ID of the guest NUMA node. The guest nodes are numbered from 0 without gaps.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"hostNode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "HostNode is the ID of the host NUMA node the hugepages are allocated on.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"memory": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Memory is the guest memory of the node, a multiple of the page size.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+				},
+				Required: []string{"guestNode", "hostNode", "memory"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
+	}
+}
+
 func schema_kubevirtio_client_go_api_v1_Network(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -16942,9 +17752,24 @@ func schema_kubevirtio_client_go_api_v1_NetworkConfiguration(ref common.Referenc
 							Format: "",
 						},
 					},
+					"macAddressPools": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MacAddressPools interfaces can draw their MAC address from.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("kubevirt.io/client-go/api/v1.MacAddressPool"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"kubevirt.io/client-go/api/v1.MacAddressPool"},
 	}
 }
 
@@ -17042,9 +17867,17 @@ func schema_kubevirtio_client_go_api_v1_PITTimer(ref common.ReferenceCallback) c
 							Format:      "",
 						},
 					},
+					"catchup": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Catchup tunes the catchup tick policy, only valid with it.",
+							Ref:         ref("kubevirt.io/client-go/api/v1.TimerCatchup"),
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"kubevirt.io/client-go/api/v1.TimerCatchup"},
 	}
 }
 
@@ -17203,6 +18036,18 @@ func schema_kubevirtio_client_go_api_v1_Probe(ref common.ReferenceCallback) comm
 							Ref:         ref("k8s.io/api/core/v1.TCPSocketAction"),
 						},
 					},
+					"exec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Exec specifies a command run in the guest through the guest agent, the probe succeeds if the command exits with 0. Only supported by startup probes.",
+							Ref:         ref("k8s.io/api/core/v1.ExecAction"),
+						},
+					},
+					"guestAgentPing": {
+						SchemaProps: spec.SchemaProps{
+							Description: "GuestAgentPing contacts the guest agent, the probe succeeds if it answers. Only supported by startup probes.",
+							Ref:         ref("kubevirt.io/client-go/api/v1.GuestAgentPing"),
+						},
+					},
 					"initialDelaySeconds": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Number of seconds after the VirtualMachineInstance has started before liveness probes are initiated. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes",
@@ -17242,7 +18087,7 @@ func schema_kubevirtio_client_go_api_v1_Probe(ref common.ReferenceCallback) comm
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/api/core/v1.HTTPGetAction", "k8s.io/api/core/v1.TCPSocketAction"},
+			"k8s.io/api/core/v1.ExecAction", "k8s.io/api/core/v1.HTTPGetAction", "k8s.io/api/core/v1.TCPSocketAction", "kubevirt.io/client-go/api/v1.GuestAgentPing"},
 	}
 }
 
@@ -17315,9 +18160,17 @@ func schema_kubevirtio_client_go_api_v1_RTCTimer(ref common.ReferenceCallback) c
 							Format:      "",
 						},
 					},
+					"catchup": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Catchup tunes the catchup tick policy, only valid with it.",
+							Ref:         ref("kubevirt.io/client-go/api/v1.TimerCatchup"),
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"kubevirt.io/client-go/api/v1.TimerCatchup"},
 	}
 }
 
@@ -17588,6 +18441,39 @@ func schema_kubevirtio_client_go_api_v1_SecretVolumeSource(ref common.ReferenceC
 	}
 }
 
+func schema_kubevirtio_client_go_api_v1_SecureBootCertificates(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SecureBootCertificates references the k8s secrets, in the namespace of the vmi, holding the certificates enrolled in each Secure Boot certificate store. Each secret holds PEM encoded X.509 certificates, or a single DER encoded one, under the key certificates. All stores are required.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"pk": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PK references the secret holding the Platform Key, a single certificate.",
+							Ref:         ref("k8s.io/api/core/v1.LocalObjectReference"),
+						},
+					},
+					"kek": {
+						SchemaProps: spec.SchemaProps{
+							Description: "KEK references the secret holding the Key Exchange Keys.",
+							Ref:         ref("k8s.io/api/core/v1.LocalObjectReference"),
+						},
+					},
+					"db": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DB references the secret holding the certificates of the allowed signature database.",
+							Ref:         ref("k8s.io/api/core/v1.LocalObjectReference"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.LocalObjectReference"},
+	}
+}
+
 func schema_kubevirtio_client_go_api_v1_ServiceAccountVolumeSource(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -17608,6 +18494,35 @@ func schema_kubevirtio_client_go_api_v1_ServiceAccountVolumeSource(ref common.Re
 	}
 }
 
+func schema_kubevirtio_client_go_api_v1_SoftRebootStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SoftRebootStatus describes a soft reboot of a VirtualMachineInstance",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"mechanism": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Mechanism is the way the reboot was signaled to the guest, GuestAgent or ACPI",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"timestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timestamp is the time the reboot was signaled",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+				Required: []string{"mechanism"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema_kubevirtio_client_go_api_v1_Timer(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -17653,6 +18568,40 @@ func schema_kubevirtio_client_go_api_v1_Timer(ref common.ReferenceCallback) comm
 	}
 }
 
+func schema_kubevirtio_client_go_api_v1_TimerCatchup(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TimerCatchup tunes how a timer with the catchup tick policy injects the ticks it missed.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"threshold": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Threshold is the number of missed ticks from which the timer catches up.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"slew": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Slew is the number of missed ticks injected at a higher rate at once.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"limit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Limit is the number of missed ticks beyond which the timer stops catching up.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_kubevirtio_client_go_api_v1_UserPasswordAccessCredential(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -17723,6 +18672,34 @@ func schema_kubevirtio_client_go_api_v1_UserPasswordAccessCredentialSource(ref c
 	}
 }
 
+func schema_kubevirtio_client_go_api_v1_VCPUPin(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "VCPUPin is the host CPU a vCPU of the vmi is pinned to",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"vcpu": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The vCPU of the vmi",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"cpu": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The host CPU the vCPU is pinned to",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"vcpu", "cpu"},
+			},
+		},
+	}
+}
+
 func schema_kubevirtio_client_go_api_v1_VirtualMachine(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -18458,9 +19435,38 @@ func schema_kubevirtio_client_go_api_v1_VirtualMachineInstanceMigrationSpec(ref
 							Format:      "",
 						},
 					},
+					"warmup": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Warmup pre-copies the memory of the VMI to the target without cutting over, so that the cutover pauses the VMI for less time. The migration stays in the WarmingUp phase until commitCutover is set.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"commitCutover": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CommitCutover ends the warmup of a warm migration and lets it complete. It can only be set on migrations with warmup, and not be unset.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"bandwidth": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Bandwidth is the bandwidth the migration may use at most, per second. It is limited to the bandwidthPerMigration of the cluster, which is used if unset.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"parallelMigrationThreads": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ParallelMigrationThreads is the number of parallel connections migrating the memory (multifd). It is limited to the maxParallelMigrationThreads of the cluster. The memory is migrated over a single connection if unset.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
 				},
 			},
 		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
 	}
 }
 
@@ -18574,11 +19580,62 @@ func schema_kubevirtio_client_go_api_v1_VirtualMachineInstanceMigrationState(ref
 							Format:      "",
 						},
 					},
+					"bandwidth": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The bandwidth the migration is allowed to use, per second",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"parallelMigrationThreads": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of parallel connections migrating the memory",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"warmup": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Indicates that the migration pre-copies the memory without cutting over until the cutover is requested",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"cutoverRequested": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Indicates that the cutover of a warm migration has been requested",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"warmupStatus": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The convergence of the warmup, as reported by the source node",
+							Ref:         ref("kubevirt.io/client-go/api/v1.MigrationWarmupStatus"),
+						},
+					},
+					"targetVCPUPinning": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "The pinning of the dedicated vCPUs, as rebalanced along the NUMA layout of the target node once the migration completed",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("kubevirt.io/client-go/api/v1.VCPUPin"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+			"k8s.io/apimachinery/pkg/api/resource.Quantity", "k8s.io/apimachinery/pkg/apis/meta/v1.Time", "kubevirt.io/client-go/api/v1.MigrationWarmupStatus", "kubevirt.io/client-go/api/v1.VCPUPin"},
 	}
 }
 
@@ -18607,11 +19664,30 @@ func schema_kubevirtio_client_go_api_v1_VirtualMachineInstanceMigrationStatus(re
 							},
 						},
 					},
+					"warmup": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The convergence estimates of the warmup of a warm migration",
+							Ref:         ref("kubevirt.io/client-go/api/v1.MigrationWarmupStatus"),
+						},
+					},
+					"bandwidth": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The bandwidth the migration is allowed to use, per second, once the limits of the cluster were applied",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"parallelMigrationThreads": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of parallel connections migrating the memory, once the limits of the cluster were applied",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"kubevirt.io/client-go/api/v1.VirtualMachineInstanceMigrationCondition"},
+			"k8s.io/apimachinery/pkg/api/resource.Quantity", "kubevirt.io/client-go/api/v1.MigrationWarmupStatus", "kubevirt.io/client-go/api/v1.VirtualMachineInstanceMigrationCondition"},
 	}
 }
 
@@ -19054,6 +20130,13 @@ func schema_kubevirtio_client_go_api_v1_VirtualMachineInstanceSpec(ref common.Re
 							Ref:         ref("k8s.io/api/core/v1.Affinity"),
 						},
 					},
+					"workloadClass": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WorkloadClass groups the VMIs to spread across nodes. The pods of the VMIs of the same class in a namespace prefer not to be scheduled on the same node. It has to be a DNS-1123 label.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"schedulerName": {
 						SchemaProps: spec.SchemaProps{
 							Description: "If specified, the VMI will be dispatched by specified scheduler. If not specified, the VMI will be dispatched by default scheduler.",
@@ -19076,7 +20159,7 @@ func schema_kubevirtio_client_go_api_v1_VirtualMachineInstanceSpec(ref common.Re
 					},
 					"evictionStrategy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "EvictionStrategy can be set to \"LiveMigrate\" if the VirtualMachineInstance should be migrated instead of shut-off in case of a node drain.",
+							Description: "EvictionStrategy can be set to \"LiveMigrate\" if the VirtualMachineInstance should be migrated instead of shut-off in case of a node drain, or to \"ProtectUntilDrained\" if it should be shut down gracefully once its node is drained.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
@@ -19107,12 +20190,31 @@ func schema_kubevirtio_client_go_api_v1_VirtualMachineInstanceSpec(ref common.Re
 							Ref:         ref("kubevirt.io/client-go/api/v1.Probe"),
 						},
 					},
+					"livenessProbeFailureAction": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Action taken once the liveness probe failed. Restart, the default, stops the VirtualMachineInstance. Migrate live migrates it off its node instead, or stops it if it is not live migratable. Migrate requires a livenessProbe and no readinessProbe, the liveness probe then drives the readiness of the VirtualMachineInstance. Cannot be updated.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"readinessProbe": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Periodic probe of VirtualMachineInstance service readiness. VirtualmachineInstances will be removed from service endpoints if the probe fails. Cannot be updated. More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes",
 							Ref:         ref("kubevirt.io/client-go/api/v1.Probe"),
 						},
 					},
+					"startupProbe": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Probe of the VirtualMachineInstance startup, evaluated through the guest agent. The VirtualMachineInstance is not ready until the probe succeeded. Only guestAgentPing and exec probes are supported. Cannot be updated.",
+							Ref:         ref("kubevirt.io/client-go/api/v1.Probe"),
+						},
+					},
+					"freezeHooks": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Commands run in the guest through the guest agent around the freeze of its filesystems, e.g. to flush and quiesce applications before the disks are snapshotted.",
+							Ref:         ref("kubevirt.io/client-go/api/v1.FreezeHooks"),
+						},
+					},
 					"hostname": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Specifies the hostname of the vmi If not specified, the hostname will be set to the name of the vmi, if dhcp or cloud-init is configured properly.",
@@ -19176,7 +20278,7 @@ func schema_kubevirtio_client_go_api_v1_VirtualMachineInstanceSpec(ref common.Re
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.PodDNSConfig", "k8s.io/api/core/v1.Toleration", "kubevirt.io/client-go/api/v1.AccessCredential", "kubevirt.io/client-go/api/v1.DomainSpec", "kubevirt.io/client-go/api/v1.Network", "kubevirt.io/client-go/api/v1.Probe", "kubevirt.io/client-go/api/v1.Volume"},
+			"k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.PodDNSConfig", "k8s.io/api/core/v1.Toleration", "kubevirt.io/client-go/api/v1.AccessCredential", "kubevirt.io/client-go/api/v1.DomainSpec", "kubevirt.io/client-go/api/v1.FreezeHooks", "kubevirt.io/client-go/api/v1.Network", "kubevirt.io/client-go/api/v1.Probe", "kubevirt.io/client-go/api/v1.Volume"},
 	}
 }
 
@@ -19300,11 +20402,36 @@ func schema_kubevirtio_client_go_api_v1_VirtualMachineInstanceStatus(ref common.
 							},
 						},
 					},
+					"drainGracePeriodSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DrainGracePeriodSeconds is the effective grace period observed by virt-launcher before the VirtualMachineInstance is killed when its pod is terminated, for instance during a node drain.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"lastSoftReboot": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastSoftReboot describes the last soft reboot requested through the softreboot subresource",
+							Ref:         ref("kubevirt.io/client-go/api/v1.SoftRebootStatus"),
+						},
+					},
+					"memoryDump": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MemoryDump describes the last memory dump requested through the memorydump subresource",
+							Ref:         ref("kubevirt.io/client-go/api/v1.MemoryDumpStatus"),
+						},
+					},
+					"memoryOvercommit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MemoryOvercommit describes the memory requested for a VirtualMachineInstance with an overcommit percentage",
+							Ref:         ref("kubevirt.io/client-go/api/v1.MemoryOvercommitStatus"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"kubevirt.io/client-go/api/v1.VirtualMachineInstanceCondition", "kubevirt.io/client-go/api/v1.VirtualMachineInstanceGuestOSInfo", "kubevirt.io/client-go/api/v1.VirtualMachineInstanceMigrationState", "kubevirt.io/client-go/api/v1.VirtualMachineInstanceNetworkInterface", "kubevirt.io/client-go/api/v1.VolumeStatus"},
+			"kubevirt.io/client-go/api/v1.MemoryDumpStatus", "kubevirt.io/client-go/api/v1.MemoryOvercommitStatus", "kubevirt.io/client-go/api/v1.SoftRebootStatus", "kubevirt.io/client-go/api/v1.VirtualMachineInstanceCondition", "kubevirt.io/client-go/api/v1.VirtualMachineInstanceGuestOSInfo", "kubevirt.io/client-go/api/v1.VirtualMachineInstanceMigrationState", "kubevirt.io/client-go/api/v1.VirtualMachineInstanceNetworkInterface", "kubevirt.io/client-go/api/v1.VolumeStatus"},
 	}
 }
 
@@ -19380,6 +20507,40 @@ func schema_kubevirtio_client_go_api_v1_VirtualMachineList(ref common.ReferenceC
 	}
 }
 
+func schema_kubevirtio_client_go_api_v1_VirtualMachineSchedule(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "VirtualMachineSchedule describes when a VirtualMachine is started and stopped",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"start": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Start is the cron schedule, in the five field format \"minute hour day-of-month month day-of-week\", at which the VirtualMachine is started.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"stop": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Stop is the cron schedule, in the same format as Start, at which the VirtualMachine is stopped.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"timeZone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TimeZone is the IANA name of the time zone the schedules are evaluated in. Defaults to UTC.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_kubevirtio_client_go_api_v1_VirtualMachineSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -19401,6 +20562,12 @@ func schema_kubevirtio_client_go_api_v1_VirtualMachineSpec(ref common.ReferenceC
 							Format:      "",
 						},
 					},
+					"schedule": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Schedule starts and stops the VirtualMachine at the given times, by switching its RunStrategy between Always and Halted.",
+							Ref:         ref("kubevirt.io/client-go/api/v1.VirtualMachineSchedule"),
+						},
+					},
 					"template": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Template is the direct specification of VirtualMachineInstance",
@@ -19425,7 +20592,7 @@ func schema_kubevirtio_client_go_api_v1_VirtualMachineSpec(ref common.ReferenceC
 			},
 		},
 		Dependencies: []string{
-			"kubevirt.io/client-go/api/v1.DataVolumeTemplateSpec", "kubevirt.io/client-go/api/v1.VirtualMachineInstanceTemplateSpec"},
+			"kubevirt.io/client-go/api/v1.DataVolumeTemplateSpec", "kubevirt.io/client-go/api/v1.VirtualMachineInstanceTemplateSpec", "kubevirt.io/client-go/api/v1.VirtualMachineSchedule"},
 	}
 }
 
@@ -19556,11 +20723,17 @@ func schema_kubevirtio_client_go_api_v1_VirtualMachineStatus(ref common.Referenc
 							},
 						},
 					},
+					"lastScheduledTransition": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastScheduledTransition is the time of the latest start or stop of the schedule handled by the controller.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"kubevirt.io/client-go/api/v1.VirtualMachineCondition", "kubevirt.io/client-go/api/v1.VirtualMachineStateChangeRequest", "kubevirt.io/client-go/api/v1.VirtualMachineVolumeRequest", "kubevirt.io/client-go/api/v1.VolumeSnapshotStatus"},
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time", "kubevirt.io/client-go/api/v1.VirtualMachineCondition", "kubevirt.io/client-go/api/v1.VirtualMachineStateChangeRequest", "kubevirt.io/client-go/api/v1.VirtualMachineVolumeRequest", "kubevirt.io/client-go/api/v1.VolumeSnapshotStatus"},
 	}
 }
 
@@ -197,6 +197,65 @@ type DomainSpec struct {
 	// Chassis specifies the chassis info passed to the domain.
 	// +optional
 	Chassis *Chassis `json:"chassis,omitempty"`
+	// SELinux configures the SELinux context of the virt-launcher running the domain.
+	// +optional
+	SELinux *DomainSELinux `json:"selinux,omitempty"`
+	// OnCrash is the action taken when the guest crashes, e.g. on a kernel panic.
+	// One of: destroy, restart, preserve. Defaults to destroy.
+	// +optional
+	OnCrash *CrashAction `json:"onCrash,omitempty"`
+	// Boot configures the devices the guest boots from and the order in which
+	// the firmware tries them.
+	// +optional
+	Boot *Boot `json:"boot,omitempty"`
+}
+
+// CrashAction is the action taken when the guest crashes.
+type CrashAction string
+
+const (
+	// CrashActionDestroy stops the guest and the vmi fails, it is restarted
+	// according to the run strategy of its vm.
+	CrashActionDestroy CrashAction = "destroy"
+	// CrashActionRestart resets the guest in place, the vmi keeps running.
+	CrashActionRestart CrashAction = "restart"
+	// CrashActionPreserve keeps the crashed guest for inspection until the vmi
+	// is deleted.
+	CrashActionPreserve CrashAction = "preserve"
+)
+
+// Boot configures the devices the guest boots from.
+//
+// +k8s:openapi-gen=true
+type Boot struct {
+	// Order lists the disks and interfaces to boot from. The firmware tries
+	// them in this order and falls back to the next one if a device is not
+	// bootable. It can't be combined with bootOrder on the disks and interfaces.
+	// +optional
+	// +listType=atomic
+	Order []BootDevice `json:"order,omitempty"`
+	// NetworkBootLast boots from the network (PXE) through the interfaces
+	// without a boot order, after all the other boot devices were tried.
+	// +optional
+	NetworkBootLast bool `json:"networkBootLast,omitempty"`
+	// RebootTimeoutSeconds makes the firmware retry to boot after this many
+	// seconds if none of the boot devices is bootable, instead of halting.
+	// Only supported with BIOS.
+	// +optional
+	RebootTimeoutSeconds *uint32 `json:"rebootTimeoutSeconds,omitempty"`
+}
+
+// BootDevice references a disk or an interface to boot from.
+// Exactly one of its fields has to be set.
+//
+// +k8s:openapi-gen=true
+type BootDevice struct {
+	// Disk is the name of the disk to boot from.
+	// +optional
+	Disk string `json:"disk,omitempty"`
+	// Interface is the name of the interface to boot from.
+	// +optional
+	Interface string `json:"interface,omitempty"`
 }
 
 // Chassis specifies the chassis info passed to the domain.
@@ -210,6 +269,17 @@ type Chassis struct {
 	Sku          string `json:"sku,omitempty"`
 }
 
+// DomainSELinux configures the SELinux context of the virt-launcher running the domain.
+//
+// +k8s:openapi-gen=true
+type DomainSELinux struct {
+	// LauncherType is the SELinux type the virt-launcher runs with, in place of
+	// the cluster wide launcher type. The VMI is only scheduled on nodes
+	// advertising this type as available.
+	// +optional
+	LauncherType string `json:"launcherType,omitempty"`
+}
+
 // Represents the firmware blob used to assist in the domain creation process.
 // Used for setting the QEMU BIOS file path for the libvirt domain.
 //
@@ -242,6 +312,26 @@ type EFI struct {
 	// Defaults to true
 	// +optional
 	SecureBoot *bool `json:"secureBoot,omitempty"`
+	// If set, the certificates of the referenced secrets are enrolled in the
+	// Secure Boot variable store instead of the default keys of the firmware.
+	// Requires SecureBoot.
+	// +optional
+	SecureBootCertificates *SecureBootCertificates `json:"secureBootCertificates,omitempty"`
+}
+
+// SecureBootCertificates references the k8s secrets, in the namespace of the
+// vmi, holding the certificates enrolled in each Secure Boot certificate
+// store. Each secret holds PEM encoded X.509 certificates, or a single DER
+// encoded one, under the key certificates. All stores are required.
+//
+// +k8s:openapi-gen=true
+type SecureBootCertificates struct {
+	// PK references the secret holding the Platform Key, a single certificate.
+	PK *v1.LocalObjectReference `json:"pk,omitempty"`
+	// KEK references the secret holding the Key Exchange Keys.
+	KEK *v1.LocalObjectReference `json:"kek,omitempty"`
+	// DB references the secret holding the certificates of the allowed signature database.
+	DB *v1.LocalObjectReference `json:"db,omitempty"`
 }
 
 //
@@ -323,6 +413,25 @@ type Memory struct {
 	// Defaults to the requested memory in the resources section if not specified.
 	// + optional
 	Guest *resource.Quantity `json:"guest,omitempty"`
+	// BalloonFloor is the least amount of memory left to the Guest OS when
+	// virt-launcher reclaims memory through the memory balloon, because the
+	// memory of the pod comes under pressure.
+	// Memory is only reclaimed if set, which requires the memory balloon device.
+	// +optional
+	BalloonFloor *resource.Quantity `json:"balloonFloor,omitempty"`
+	// Locked locks the guest memory in host memory, so that it is never swapped
+	// out or reclaimed, e.g. for latency sensitive or confidential workloads.
+	// virt-handler raises the memory lock limit of the pod accordingly, the
+	// locked memory still counts against its memory limit.
+	// +optional
+	Locked bool `json:"locked,omitempty"`
+	// OvercommitPercent is the percentage of the memory requested for the pod
+	// the guest is given, e.g. 150 requests two thirds of the guest memory.
+	// It replaces the memoryOvercommit of the cluster for the vmi and is
+	// limited to its maxMemoryOvercommit. It requires the guest memory, which
+	// the memory request is computed from.
+	// +optional
+	OvercommitPercent *uint32 `json:"overcommitPercent,omitempty"`
 }
 
 // Hugepages allow to use hugepages for the VirtualMachineInstance instead of regular memory.
@@ -331,6 +440,24 @@ type Memory struct {
 type Hugepages struct {
 	// PageSize specifies the hugepage size, for x86_64 architecture valid values are 1Gi and 2Mi.
 	PageSize string `json:"pageSize,omitempty"`
+	// PerNUMANode splits the guest memory into guest NUMA nodes, each backed by
+	// the hugepages of a host NUMA node. The memory of all nodes has to add up
+	// to the guest memory, the vCPUs are spread evenly across the nodes.
+	// +optional
+	PerNUMANode []NUMANodeHugepages `json:"perNUMANode,omitempty"`
+}
+
+// NUMANodeHugepages places the hugepages of a guest NUMA node on a host NUMA node.
+//
+// +k8s:openapi-gen=true
+type NUMANodeHugepages struct {
+	// GuestNode is the ID of the guest NUMA node. The guest nodes are numbered
+	// from 0 without gaps.
+	GuestNode uint32 `json:"guestNode"`
+	// HostNode is the ID of the host NUMA node the hugepages are allocated on.
+	HostNode uint32 `json:"hostNode"`
+	// Memory is the guest memory of the node, a multiple of the page size.
+	Memory resource.Quantity `json:"memory"`
 }
 
 //
@@ -351,6 +478,12 @@ type Firmware struct {
 	Bootloader *Bootloader `json:"bootloader,omitempty"`
 	// The system-serial-number in SMBIOS
 	Serial string `json:"serial,omitempty"`
+	// SMBiosSecretRef references a k8s secret in the namespace of the vmi
+	// that contains SMBIOS system information. The keys manufacturer and
+	// product set the matching SMBIOS system fields and the key oemStrings
+	// holds one SMBIOS OEM string per line. Missing keys are ignored.
+	// +optional
+	SMBiosSecretRef *v1.LocalObjectReference `json:"smbiosSecretRef,omitempty"`
 }
 
 //
@@ -404,6 +537,10 @@ type Devices struct {
 	// +optional
 	// +listType=atomic
 	HostDevices []HostDevice `json:"hostDevices,omitempty"`
+	// AgentConnectTimeoutSeconds is how long the operations requiring the guest agent, like freezing the guest filesystems or reading the guest OS info, wait for it to connect before failing.
+	// They fail right away if the guest agent isn't connected and no timeout is set. Must be between 0 and 600.
+	// +optional
+	AgentConnectTimeoutSeconds *int32 `json:"agentConnectTimeoutSeconds,omitempty"`
 }
 
 //
@@ -430,7 +567,16 @@ type Filesystem struct {
 
 //
 // +k8s:openapi-gen=true
-type FilesystemVirtiofs struct{}
+type FilesystemVirtiofs struct {
+	// Cache selects how virtiofsd caches the shared data and metadata.
+	// Supported values: none, auto, always. Defaults to none.
+	// +optional
+	Cache VirtiofsCache `json:"cache,omitempty"`
+	// Sandbox selects how virtiofsd isolates itself from the rest of the host.
+	// Supported values: namespace, chroot. Defaults to namespace.
+	// +optional
+	Sandbox VirtiofsSandbox `json:"sandbox,omitempty"`
+}
 
 //
 // +k8s:openapi-gen=true
@@ -446,6 +592,28 @@ type HostDevice struct {
 	Name string `json:"name"`
 	// DeviceName is the resource name of the host device exposed by a device plugin
 	DeviceName string `json:"deviceName"`
+	// If specified, the host device will be placed on the guests pci address with the specified PCI address. For example: 0000:81:01.1
+	// +optional
+	PciAddress string `json:"pciAddress,omitempty"`
+	// If specified, the ROM image of the referenced config map or secret is
+	// exposed to the guest instead of the ROM of the host device. Only
+	// supported by PCI host devices.
+	// +optional
+	ROM *HostDeviceROM `json:"rom,omitempty"`
+}
+
+// HostDeviceROM references the config map or the secret, in the namespace of
+// the vmi, holding the ROM image of a host device under the key rom. Exactly
+// one of them is required.
+//
+// +k8s:openapi-gen=true
+type HostDeviceROM struct {
+	// ConfigMap references a config map holding the ROM image as binary data.
+	// +optional
+	ConfigMap *v1.LocalObjectReference `json:"configMap,omitempty"`
+	// Secret references a secret holding the ROM image.
+	// +optional
+	Secret *v1.LocalObjectReference `json:"secret,omitempty"`
 }
 
 //
@@ -465,6 +633,10 @@ type Disk struct {
 	// Serial provides the ability to specify a serial number for the disk device.
 	// +optional
 	Serial string `json:"serial,omitempty"`
+	// WWN provides the ability to specify the World Wide Name of the disk device,
+	// made up of 16 hexadecimal digits. Only supported on the scsi bus.
+	// +optional
+	WWN string `json:"wwn,omitempty"`
 	// dedicatedIOThread indicates this disk should have an exclusive IO Thread.
 	// Enabling this implies useIOThreads = true.
 	// Defaults to false.
@@ -480,6 +652,39 @@ type Disk struct {
 	// If specified, disk address and its tag will be provided to the guest via config drive metadata
 	// +optional
 	Tag string `json:"tag,omitempty"`
+	// IOThrottleGroup makes the disk share the I/O limits of the group with all
+	// other disks of the vmi in the same group.
+	// +optional
+	IOThrottleGroup *IOThrottleGroup `json:"ioThrottleGroup,omitempty"`
+}
+
+// IOThrottleGroup limits the aggregate I/O of the disks sharing its name.
+// All disks of a group have to set the same limits. A limit of 0 is unlimited.
+//
+// +k8s:openapi-gen=true
+type IOThrottleGroup struct {
+	// Name of the throttle group.
+	Name string `json:"name"`
+	// TotalIOPS limits the read and write operations per second.
+	// Can't be combined with ReadIOPS or WriteIOPS.
+	// +optional
+	TotalIOPS int64 `json:"totalIOPS,omitempty"`
+	// ReadIOPS limits the read operations per second.
+	// +optional
+	ReadIOPS int64 `json:"readIOPS,omitempty"`
+	// WriteIOPS limits the write operations per second.
+	// +optional
+	WriteIOPS int64 `json:"writeIOPS,omitempty"`
+	// TotalBytesPerSec limits the read and written bytes per second.
+	// Can't be combined with ReadBytesPerSec or WriteBytesPerSec.
+	// +optional
+	TotalBytesPerSec int64 `json:"totalBytesPerSec,omitempty"`
+	// ReadBytesPerSec limits the read bytes per second.
+	// +optional
+	ReadBytesPerSec int64 `json:"readBytesPerSec,omitempty"`
+	// WriteBytesPerSec limits the written bytes per second.
+	// +optional
+	WriteBytesPerSec int64 `json:"writeBytesPerSec,omitempty"`
 }
 
 // Represents the target of a volume to mount.
@@ -806,6 +1011,22 @@ const (
 	TrackWall RTCTimerTrack = "wall"
 )
 
+// TimerCatchup tunes how a timer with the catchup tick policy injects the
+// ticks it missed.
+//
+// +k8s:openapi-gen=true
+type TimerCatchup struct {
+	// Threshold is the number of missed ticks from which the timer catches up.
+	// +optional
+	Threshold uint32 `json:"threshold,omitempty"`
+	// Slew is the number of missed ticks injected at a higher rate at once.
+	// +optional
+	Slew uint32 `json:"slew,omitempty"`
+	// Limit is the number of missed ticks beyond which the timer stops catching up.
+	// +optional
+	Limit uint32 `json:"limit,omitempty"`
+}
+
 //
 // +k8s:openapi-gen=true
 type RTCTimer struct {
@@ -818,6 +1039,9 @@ type RTCTimer struct {
 	Enabled *bool `json:"present,omitempty"`
 	// Track the guest or the wall clock.
 	Track RTCTimerTrack `json:"track,omitempty"`
+	// Catchup tunes the catchup tick policy, only valid with it.
+	// +optional
+	Catchup *TimerCatchup `json:"catchup,omitempty"`
 }
 
 //
@@ -830,6 +1054,9 @@ type HPETTimer struct {
 	// Defaults to true.
 	// +optional
 	Enabled *bool `json:"present,omitempty"`
+	// Catchup tunes the catchup tick policy, only valid with it.
+	// +optional
+	Catchup *TimerCatchup `json:"catchup,omitempty"`
 }
 
 //
@@ -842,6 +1069,9 @@ type PITTimer struct {
 	// Defaults to true.
 	// +optional
 	Enabled *bool `json:"present,omitempty"`
+	// Catchup tunes the catchup tick policy, only valid with it.
+	// +optional
+	Catchup *TimerCatchup `json:"catchup,omitempty"`
 }
 
 //
@@ -1067,6 +1297,15 @@ type Interface struct {
 	Ports []Port `json:"ports,omitempty"`
 	// Interface MAC address. For example: de:ad:00:00:be:af or DE-AD-00-00-BE-AF.
 	MacAddress string `json:"macAddress,omitempty"`
+	// Name of a MAC address pool configured in the KubeVirt CR, to draw the
+	// MAC address of the interface from if macAddress is not set.
+	// +optional
+	MacAddressPool string `json:"macAddressPool,omitempty"`
+	// MTU of the interface in the guest, for example 9000 on jumbo-frame networks.
+	// Must not exceed the MTU of the pod network, which it defaults to.
+	// Only supported with the bridge and masquerade bindings.
+	// +optional
+	MTU *uint `json:"mtu,omitempty"`
 	// BootOrder is an integer value > 0, used to determine ordering of boot devices.
 	// Lower values take precedence.
 	// Each interface or disk that has a boot order must have a unique value.
@@ -81,6 +81,26 @@ func (DomainSpec) SwaggerDoc() map[string]string {
 		"devices":         "Devices allows adding disks, network interfaces, and others",
 		"ioThreadsPolicy": "Controls whether or not disks will share IOThreads.\nOmitting IOThreadsPolicy disables use of IOThreads.\nOne of: shared, auto\n+optional",
 		"chassis":         "Chassis specifies the chassis info passed to the domain.\n+optional",
+		"selinux":         "SELinux configures the SELinux context of the virt-launcher running the domain.\n+optional",
+		"onCrash":         "OnCrash is the action taken when the guest crashes, e.g. on a kernel panic.\nOne of: destroy, restart, preserve. Defaults to destroy.\n+optional",
+		"boot":            "Boot configures the devices the guest boots from and the order in which\nthe firmware tries them.\n+optional",
+	}
+}
+
+func (Boot) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":                     "Boot configures the devices the guest boots from.",
+		"order":                "Order lists the disks and interfaces to boot from. The firmware tries\nthem in this order and falls back to the next one if a device is not\nbootable. It can't be combined with bootOrder on the disks and interfaces.\n+optional\n+listType=atomic",
+		"networkBootLast":      "NetworkBootLast boots from the network (PXE) through the interfaces\nwithout a boot order, after all the other boot devices were tried.\n+optional",
+		"rebootTimeoutSeconds": "RebootTimeoutSeconds makes the firmware retry to boot after this many\nseconds if none of the boot devices is bootable, instead of halting.\nOnly supported with BIOS.\n+optional",
+	}
+}
+
+func (BootDevice) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":          "BootDevice references a disk or an interface to boot from.\nExactly one of its fields has to be set.",
+		"disk":      "Disk is the name of the disk to boot from.\n+optional",
+		"interface": "Interface is the name of the interface to boot from.\n+optional",
 	}
 }
 
@@ -90,6 +110,13 @@ func (Chassis) SwaggerDoc() map[string]string {
 	}
 }
 
+func (DomainSELinux) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":             "DomainSELinux configures the SELinux context of the virt-launcher running the domain.\n\n+k8s:openapi-gen=true",
+		"launcherType": "LauncherType is the SELinux type the virt-launcher runs with, in place of\nthe cluster wide launcher type. The VMI is only scheduled on nodes\nadvertising this type as available.\n+optional",
+	}
+}
+
 func (Bootloader) SwaggerDoc() map[string]string {
 	return map[string]string{
 		"":     "Represents the firmware blob used to assist in the domain creation process.\nUsed for setting the QEMU BIOS file path for the libvirt domain.\n\n+k8s:openapi-gen=true",
@@ -107,8 +134,18 @@ func (BIOS) SwaggerDoc() map[string]string {
 
 func (EFI) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":           "If set, EFI will be used instead of BIOS.\n\n+k8s:openapi-gen=true",
-		"secureBoot": "If set, SecureBoot will be enabled and the OVMF roms will be swapped for\nSecureBoot-enabled ones.\nRequires SMM to be enabled.\nDefaults to true\n+optional",
+		"":                       "If set, EFI will be used instead of BIOS.\n\n+k8s:openapi-gen=true",
+		"secureBoot":             "If set, SecureBoot will be enabled and the OVMF roms will be swapped for\nSecureBoot-enabled ones.\nRequires SMM to be enabled.\nDefaults to true\n+optional",
+		"secureBootCertificates": "If set, the certificates of the referenced secrets are enrolled in the\nSecure Boot variable store instead of the default keys of the firmware.\nRequires SecureBoot.\n+optional",
+	}
+}
+
+func (SecureBootCertificates) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":    "SecureBootCertificates references the k8s secrets, in the namespace of the\nvmi, holding the certificates enrolled in each Secure Boot certificate\nstore. Each secret holds PEM encoded X.509 certificates, or a single DER\nencoded one, under the key certificates. All stores are required.\n\n+k8s:openapi-gen=true",
+		"pk":  "PK references the secret holding the Platform Key, a single certificate.",
+		"kek": "KEK references the secret holding the Key Exchange Keys.",
+		"db":  "DB references the secret holding the certificates of the allowed signature database.",
 	}
 }
 
@@ -144,16 +181,29 @@ func (CPUFeature) SwaggerDoc() map[string]string {
 
 func (Memory) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":          "Memory allows specifying the VirtualMachineInstance memory features.\n\n+k8s:openapi-gen=true",
-		"hugepages": "Hugepages allow to use hugepages for the VirtualMachineInstance instead of regular memory.\n+optional",
-		"guest":     "Guest allows to specifying the amount of memory which is visible inside the Guest OS.\nThe Guest must lie between Requests and Limits from the resources section.\nDefaults to the requested memory in the resources section if not specified.\n+ optional",
+		"":                  "Memory allows specifying the VirtualMachineInstance memory features.\n\n+k8s:openapi-gen=true",
+		"hugepages":         "Hugepages allow to use hugepages for the VirtualMachineInstance instead of regular memory.\n+optional",
+		"guest":             "Guest allows to specifying the amount of memory which is visible inside the Guest OS.\nThe Guest must lie between Requests and Limits from the resources section.\nDefaults to the requested memory in the resources section if not specified.\n+ optional",
+		"balloonFloor":      "BalloonFloor is the least amount of memory left to the Guest OS when\nvirt-launcher reclaims memory through the memory balloon, because the\nmemory of the pod comes under pressure.\nMemory is only reclaimed if set, which requires the memory balloon device.\n+optional",
+		"locked":            "Locked locks the guest memory in host memory, so that it is never swapped\nout or reclaimed, e.g. for latency sensitive or confidential workloads.\nvirt-handler raises the memory lock limit of the pod accordingly, the\nlocked memory still counts against its memory limit.\n+optional",
+		"overcommitPercent": "OvercommitPercent is the percentage of the memory requested for the pod\nthe guest is given, e.g. 150 requests two thirds of the guest memory.\nIt replaces the memoryOvercommit of the cluster for the vmi and is\nlimited to its maxMemoryOvercommit. It requires the guest memory, which\nthe memory request is computed from.\n+optional",
 	}
 }
 
 func (Hugepages) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":         "Hugepages allow to use hugepages for the VirtualMachineInstance instead of regular memory.\n\n+k8s:openapi-gen=true",
-		"pageSize": "PageSize specifies the hugepage size, for x86_64 architecture valid values are 1Gi and 2Mi.",
+		"":            "Hugepages allow to use hugepages for the VirtualMachineInstance instead of regular memory.\n\n+k8s:openapi-gen=true",
+		"pageSize":    "PageSize specifies the hugepage size, for x86_64 architecture valid values are 1Gi and 2Mi.",
+		"perNUMANode": "PerNUMANode splits the guest memory into guest NUMA nodes, each backed by\nthe hugepages of a host NUMA node. The memory of all nodes has to add up\nto the guest memory, the vCPUs are spread evenly across the nodes.\n+optional",
+	}
+}
+
+func (NUMANodeHugepages) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":          "NUMANodeHugepages places the hugepages of a guest NUMA node on a host NUMA node.\n\n+k8s:openapi-gen=true",
+		"guestNode": "GuestNode is the ID of the guest NUMA node. The guest nodes are numbered\nfrom 0 without gaps.",
+		"hostNode":  "HostNode is the ID of the host NUMA node the hugepages are allocated on.",
+		"memory":    "Memory is the guest memory of the node, a multiple of the page size.",
 	}
 }
 
@@ -166,10 +216,11 @@ func (Machine) SwaggerDoc() map[string]string {
 
 func (Firmware) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":           "+k8s:openapi-gen=true",
-		"uuid":       "UUID reported by the vmi bios.\nDefaults to a random generated uid.",
-		"bootloader": "Settings to control the bootloader that is used.\n+optional",
-		"serial":     "The system-serial-number in SMBIOS",
+		"":                "+k8s:openapi-gen=true",
+		"uuid":            "UUID reported by the vmi bios.\nDefaults to a random generated uid.",
+		"bootloader":      "Settings to control the bootloader that is used.\n+optional",
+		"serial":          "The system-serial-number in SMBIOS",
+		"smbiosSecretRef": "SMBiosSecretRef references a k8s secret in the namespace of the vmi\nthat contains SMBIOS system information. The keys manufacturer and\nproduct set the matching SMBIOS system fields and the key oemStrings\nholds one SMBIOS OEM string per line. Missing keys are ignored.\n+optional",
 	}
 }
 
@@ -192,6 +243,7 @@ func (Devices) SwaggerDoc() map[string]string {
 		"gpus":                       "Whether to attach a GPU device to the vmi.\n+optional\n+listType=atomic",
 		"filesystems":                "Filesystems describes filesystem which is connected to the vmi.\n+optional\n+listType=atomic",
 		"hostDevices":                "Whether to attach a host device to the vmi.\n+optional\n+listType=atomic",
+		"agentConnectTimeoutSeconds": "AgentConnectTimeoutSeconds is how long the operations requiring the guest agent, like freezing the guest filesystems or reading the guest OS info, wait for it to connect before failing.\nThey fail right away if the guest agent isn't connected and no timeout is set. Must be between 0 and 600.\n+optional",
 	}
 }
 
@@ -214,7 +266,9 @@ func (Filesystem) SwaggerDoc() map[string]string {
 
 func (FilesystemVirtiofs) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"": "+k8s:openapi-gen=true",
+		"":        "+k8s:openapi-gen=true",
+		"cache":   "Cache selects how virtiofsd caches the shared data and metadata.\nSupported values: none, auto, always. Defaults to none.\n+optional",
+		"sandbox": "Sandbox selects how virtiofsd isolates itself from the rest of the host.\nSupported values: namespace, chroot. Defaults to namespace.\n+optional",
 	}
 }
 
@@ -229,6 +283,16 @@ func (HostDevice) SwaggerDoc() map[string]string {
 	return map[string]string{
 		"":           "+k8s:openapi-gen=true",
 		"deviceName": "DeviceName is the resource name of the host device exposed by a device plugin",
+		"pciAddress": "If specified, the host device will be placed on the guests pci address with the specified PCI address. For example: 0000:81:01.1\n+optional",
+		"rom":        "If specified, the ROM image of the referenced config map or secret is\nexposed to the guest instead of the ROM of the host device. Only\nsupported by PCI host devices.\n+optional",
+	}
+}
+
+func (HostDeviceROM) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":          "HostDeviceROM references the config map or the secret, in the namespace of\nthe vmi, holding the ROM image of a host device under the key rom. Exactly\none of them is required.\n\n+k8s:openapi-gen=true",
+		"configMap": "ConfigMap references a config map holding the ROM image as binary data.\n+optional",
+		"secret":    "Secret references a secret holding the ROM image.\n+optional",
 	}
 }
 
@@ -238,10 +302,25 @@ func (Disk) SwaggerDoc() map[string]string {
 		"name":              "Name is the device name",
 		"bootOrder":         "BootOrder is an integer value > 0, used to determine ordering of boot devices.\nLower values take precedence.\nEach disk or interface that has a boot order must have a unique value.\nDisks without a boot order are not tried if a disk with a boot order exists.\n+optional",
 		"serial":            "Serial provides the ability to specify a serial number for the disk device.\n+optional",
+		"wwn":               "WWN provides the ability to specify the World Wide Name of the disk device,\nmade up of 16 hexadecimal digits. Only supported on the scsi bus.\n+optional",
 		"dedicatedIOThread": "dedicatedIOThread indicates this disk should have an exclusive IO Thread.\nEnabling this implies useIOThreads = true.\nDefaults to false.\n+optional",
 		"cache":             "Cache specifies which kvm disk cache mode should be used.\n+optional",
 		"io":                "IO specifies which QEMU disk IO mode should be used.\nSupported values are: native, default, threads.\n+optional",
 		"tag":               "If specified, disk address and its tag will be provided to the guest via config drive metadata\n+optional",
+		"ioThrottleGroup":   "IOThrottleGroup makes the disk share the I/O limits of the group with all\nother disks of the vmi in the same group.\n+optional",
+	}
+}
+
+func (IOThrottleGroup) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":                 "IOThrottleGroup limits the aggregate I/O of the disks sharing its name.\nAll disks of a group have to set the same limits. A limit of 0 is unlimited.\n\n+k8s:openapi-gen=true",
+		"name":             "Name of the throttle group.",
+		"totalIOPS":        "TotalIOPS limits the read and write operations per second.\nCan't be combined with ReadIOPS or WriteIOPS.\n+optional",
+		"readIOPS":         "ReadIOPS limits the read operations per second.\n+optional",
+		"writeIOPS":        "WriteIOPS limits the write operations per second.\n+optional",
+		"totalBytesPerSec": "TotalBytesPerSec limits the read and written bytes per second.\nCan't be combined with ReadBytesPerSec or WriteBytesPerSec.\n+optional",
+		"readBytesPerSec":  "ReadBytesPerSec limits the read bytes per second.\n+optional",
+		"writeBytesPerSec": "WriteBytesPerSec limits the written bytes per second.\n+optional",
 	}
 }
 
@@ -386,12 +465,22 @@ func (Timer) SwaggerDoc() map[string]string {
 	}
 }
 
+func (TimerCatchup) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":          "TimerCatchup tunes how a timer with the catchup tick policy injects the\nticks it missed.\n\n+k8s:openapi-gen=true",
+		"threshold": "Threshold is the number of missed ticks from which the timer catches up.\n+optional",
+		"slew":      "Slew is the number of missed ticks injected at a higher rate at once.\n+optional",
+		"limit":     "Limit is the number of missed ticks beyond which the timer stops catching up.\n+optional",
+	}
+}
+
 func (RTCTimer) SwaggerDoc() map[string]string {
 	return map[string]string{
 		"":           "+k8s:openapi-gen=true",
 		"tickPolicy": "TickPolicy determines what happens when QEMU misses a deadline for injecting a tick to the guest.\nOne of \"delay\", \"catchup\".",
 		"present":    "Enabled set to false makes sure that the machine type or a preset can't add the timer.\nDefaults to true.\n+optional",
 		"track":      "Track the guest or the wall clock.",
+		"catchup":    "Catchup tunes the catchup tick policy, only valid with it.\n+optional",
 	}
 }
 
@@ -400,6 +489,7 @@ func (HPETTimer) SwaggerDoc() map[string]string {
 		"":           "+k8s:openapi-gen=true",
 		"tickPolicy": "TickPolicy determines what happens when QEMU misses a deadline for injecting a tick to the guest.\nOne of \"delay\", \"catchup\", \"merge\", \"discard\".",
 		"present":    "Enabled set to false makes sure that the machine type or a preset can't add the timer.\nDefaults to true.\n+optional",
+		"catchup":    "Catchup tunes the catchup tick policy, only valid with it.\n+optional",
 	}
 }
 
@@ -408,6 +498,7 @@ func (PITTimer) SwaggerDoc() map[string]string {
 		"":           "+k8s:openapi-gen=true",
 		"tickPolicy": "TickPolicy determines what happens when QEMU misses a deadline for injecting a tick to the guest.\nOne of \"delay\", \"catchup\", \"discard\".",
 		"present":    "Enabled set to false makes sure that the machine type or a preset can't add the timer.\nDefaults to true.\n+optional",
+		"catchup":    "Catchup tunes the catchup tick policy, only valid with it.\n+optional",
 	}
 }
 
@@ -518,15 +609,17 @@ func (I6300ESBWatchdog) SwaggerDoc() map[string]string {
 
 func (Interface) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":            "+k8s:openapi-gen=true",
-		"name":        "Logical name of the interface as well as a reference to the associated networks.\nMust match the Name of a Network.",
-		"model":       "Interface model.\nOne of: e1000, e1000e, ne2k_pci, pcnet, rtl8139, virtio.\nDefaults to virtio.",
-		"ports":       "List of ports to be forwarded to the virtual machine.",
-		"macAddress":  "Interface MAC address. For example: de:ad:00:00:be:af or DE-AD-00-00-BE-AF.",
-		"bootOrder":   "BootOrder is an integer value > 0, used to determine ordering of boot devices.\nLower values take precedence.\nEach interface or disk that has a boot order must have a unique value.\nInterfaces without a boot order are not tried.\n+optional",
-		"pciAddress":  "If specified, the virtual network interface will be placed on the guests pci address with the specified PCI address. For example: 0000:81:01.10\n+optional",
-		"dhcpOptions": "If specified the network interface will pass additional DHCP options to the VMI\n+optional",
-		"tag":         "If specified, the virtual network interface address and its tag will be provided to the guest via config drive\n+optional",
+		"":               "+k8s:openapi-gen=true",
+		"name":           "Logical name of the interface as well as a reference to the associated networks.\nMust match the Name of a Network.",
+		"model":          "Interface model.\nOne of: e1000, e1000e, ne2k_pci, pcnet, rtl8139, virtio.\nDefaults to virtio.",
+		"ports":          "List of ports to be forwarded to the virtual machine.",
+		"macAddress":     "Interface MAC address. For example: de:ad:00:00:be:af or DE-AD-00-00-BE-AF.",
+		"macAddressPool": "Name of a MAC address pool configured in the KubeVirt CR, to draw the\nMAC address of the interface from if macAddress is not set.\n+optional",
+		"mtu":            "MTU of the interface in the guest, for example 9000 on jumbo-frame networks.\nMust not exceed the MTU of the pod network, which it defaults to.\nOnly supported with the bridge and masquerade bindings.\n+optional",
+		"bootOrder":      "BootOrder is an integer value > 0, used to determine ordering of boot devices.\nLower values take precedence.\nEach interface or disk that has a boot order must have a unique value.\nInterfaces without a boot order are not tried.\n+optional",
+		"pciAddress":     "If specified, the virtual network interface will be placed on the guests pci address with the specified PCI address. For example: 0000:81:01.10\n+optional",
+		"dhcpOptions":    "If specified the network interface will pass additional DHCP options to the VMI\n+optional",
+		"tag":            "If specified, the virtual network interface address and its tag will be provided to the guest via config drive\n+optional",
 	}
 }
 
@@ -77,6 +77,12 @@ type VirtualMachineInstanceList struct {
 // +k8s:openapi-gen=true
 type EvictionStrategy string
 
+// LivenessProbeFailureAction is the action taken once the liveness probe of a
+// VirtualMachineInstance failed.
+//
+// +k8s:openapi-gen=true
+type LivenessProbeFailureAction string
+
 // VirtualMachineInstanceSpec is a description of a VirtualMachineInstance.
 //
 // +k8s:openapi-gen=true
@@ -97,6 +103,11 @@ type VirtualMachineInstanceSpec struct {
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
 	// If affinity is specifies, obey all the affinity rules
 	Affinity *k8sv1.Affinity `json:"affinity,omitempty"`
+	// WorkloadClass groups the VMIs to spread across nodes. The pods of the
+	// VMIs of the same class in a namespace prefer not to be scheduled on the
+	// same node. It has to be a DNS-1123 label.
+	// +optional
+	WorkloadClass string `json:"workloadClass,omitempty"`
 	// If specified, the VMI will be dispatched by specified scheduler.
 	// If not specified, the VMI will be dispatched by default scheduler.
 	// +optional
@@ -105,7 +116,8 @@ type VirtualMachineInstanceSpec struct {
 	Tolerations []k8sv1.Toleration `json:"tolerations,omitempty"`
 
 	// EvictionStrategy can be set to "LiveMigrate" if the VirtualMachineInstance should be
-	// migrated instead of shut-off in case of a node drain.
+	// migrated instead of shut-off in case of a node drain, or to "ProtectUntilDrained"
+	// if it should be shut down gracefully once its node is drained.
 	//
 	// +optional
 	EvictionStrategy *EvictionStrategy `json:"evictionStrategy,omitempty"`
@@ -120,12 +132,29 @@ type VirtualMachineInstanceSpec struct {
 	// More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes
 	// +optional
 	LivenessProbe *Probe `json:"livenessProbe,omitempty"`
+	// Action taken once the liveness probe failed.
+	// Restart, the default, stops the VirtualMachineInstance.
+	// Migrate live migrates it off its node instead, or stops it if it is not live migratable.
+	// Migrate requires a livenessProbe and no readinessProbe, the liveness probe then drives the readiness of the VirtualMachineInstance.
+	// Cannot be updated.
+	// +optional
+	LivenessProbeFailureAction LivenessProbeFailureAction `json:"livenessProbeFailureAction,omitempty"`
 	// Periodic probe of VirtualMachineInstance service readiness.
 	// VirtualmachineInstances will be removed from service endpoints if the probe fails.
 	// Cannot be updated.
 	// More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes
 	// +optional
 	ReadinessProbe *Probe `json:"readinessProbe,omitempty"`
+	// Probe of the VirtualMachineInstance startup, evaluated through the guest agent.
+	// The VirtualMachineInstance is not ready until the probe succeeded.
+	// Only guestAgentPing and exec probes are supported.
+	// Cannot be updated.
+	// +optional
+	StartupProbe *Probe `json:"startupProbe,omitempty"`
+	// Commands run in the guest through the guest agent around the freeze of its filesystems,
+	// e.g. to flush and quiesce applications before the disks are snapshotted.
+	// +optional
+	FreezeHooks *FreezeHooks `json:"freezeHooks,omitempty"`
 	// Specifies the hostname of the vmi
 	// If not specified, the hostname will be set to the name of the vmi, if dhcp or cloud-init is configured properly.
 	// +optional
@@ -197,6 +226,108 @@ type VirtualMachineInstanceStatus struct {
 	// +optional
 	// +listType=atomic
 	VolumeStatus []VolumeStatus `json:"volumeStatus,omitempty"`
+
+	// DrainGracePeriodSeconds is the effective grace period observed by virt-launcher before the VirtualMachineInstance is
+	// killed when its pod is terminated, for instance during a node drain.
+	// +optional
+	DrainGracePeriodSeconds *int64 `json:"drainGracePeriodSeconds,omitempty"`
+
+	// LastSoftReboot describes the last soft reboot requested through the softreboot subresource
+	// +optional
+	LastSoftReboot *SoftRebootStatus `json:"lastSoftReboot,omitempty"`
+
+	// MemoryDump describes the last memory dump requested through the memorydump subresource
+	// +optional
+	MemoryDump *MemoryDumpStatus `json:"memoryDump,omitempty"`
+
+	// MemoryOvercommit describes the memory requested for a VirtualMachineInstance with an overcommit percentage
+	// +optional
+	MemoryOvercommit *MemoryOvercommitStatus `json:"memoryOvercommit,omitempty"`
+}
+
+// SoftRebootMechanism is the way a soft reboot was signaled to the guest
+type SoftRebootMechanism string
+
+const (
+	// SoftRebootGuestAgent means that the guest agent rebooted the guest OS
+	SoftRebootGuestAgent SoftRebootMechanism = "GuestAgent"
+	// SoftRebootACPI means that the ACPI power button was pressed, because the guest agent
+	// was not connected or failed to reboot the guest
+	SoftRebootACPI SoftRebootMechanism = "ACPI"
+)
+
+// SoftRebootStatus describes a soft reboot of a VirtualMachineInstance
+// +k8s:openapi-gen=true
+type SoftRebootStatus struct {
+	// Mechanism is the way the reboot was signaled to the guest, GuestAgent or ACPI
+	Mechanism SoftRebootMechanism `json:"mechanism"`
+	// Timestamp is the time the reboot was signaled
+	// +optional
+	Timestamp *metav1.Time `json:"timestamp,omitempty"`
+}
+
+// MemoryDumpPhase is the phase of a memory dump
+type MemoryDumpPhase string
+
+const (
+	// MemoryDumpPending means the claim is being attached to the node of the VirtualMachineInstance
+	MemoryDumpPending MemoryDumpPhase = "Pending"
+	// MemoryDumpInProgress means the memory of the guest is being written to the claim
+	MemoryDumpInProgress MemoryDumpPhase = "InProgress"
+	// MemoryDumpCompleted means the dump has been written to the claim
+	MemoryDumpCompleted MemoryDumpPhase = "Completed"
+	// MemoryDumpFailed means the dump failed, the partially written dump has been removed from the claim
+	MemoryDumpFailed MemoryDumpPhase = "Failed"
+)
+
+// MemoryDumpStatus describes a memory dump of a VirtualMachineInstance
+// +k8s:openapi-gen=true
+type MemoryDumpStatus struct {
+	// ClaimName is the name of the PersistentVolumeClaim the dump is written to
+	ClaimName string `json:"claimName"`
+	// Phase is the phase of the dump
+	Phase MemoryDumpPhase `json:"phase"`
+	// FileName is the name of the dump file, at the root of the claim
+	FileName string `json:"fileName"`
+	// TargetPodName is the name of the pod attaching the claim to the node of the VirtualMachineInstance
+	// +optional
+	TargetPodName string `json:"targetPodName,omitempty"`
+	// TargetPodUID is the UID of the pod attaching the claim, set once the claim is attached to the node
+	// +optional
+	TargetPodUID types.UID `json:"targetPodUID,omitempty"`
+	// BytesWritten is the amount of guest memory written to the claim so far
+	// +optional
+	BytesWritten int64 `json:"bytesWritten,omitempty"`
+	// BytesTotal is the amount of guest memory to write to the claim
+	// +optional
+	BytesTotal int64 `json:"bytesTotal,omitempty"`
+	// StartTimestamp is the time the dump was requested
+	// +optional
+	StartTimestamp *metav1.Time `json:"startTimestamp,omitempty"`
+	// EndTimestamp is the time the dump completed or failed
+	// +optional
+	EndTimestamp *metav1.Time `json:"endTimestamp,omitempty"`
+	// Message is a human readable description of the failure of the dump
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// IsFinished returns whether the dump completed or failed
+func (s *MemoryDumpStatus) IsFinished() bool {
+	return s.Phase == MemoryDumpCompleted || s.Phase == MemoryDumpFailed
+}
+
+// MemoryOvercommitStatus describes the memory requested for a VirtualMachineInstance with an overcommit percentage
+// +k8s:openapi-gen=true
+type MemoryOvercommitStatus struct {
+	// Percent is the overcommit percentage applied to the guest memory
+	Percent uint32 `json:"percent"`
+	// GuestMemory is the memory of the guest
+	GuestMemory resource.Quantity `json:"guestMemory"`
+	// MemoryRequest is the memory requested for the guest, its memory scaled down by the overcommit percentage
+	MemoryRequest resource.Quantity `json:"memoryRequest"`
+	// PodMemoryRequest is the memory requested by the compute container of the pod, including the overhead of virt-launcher
+	PodMemoryRequest resource.Quantity `json:"podMemoryRequest"`
 }
 
 // VolumeStatus represents information about the status of volumes attached to the VirtualMachineInstance.
@@ -271,10 +402,20 @@ func (v *VirtualMachineInstance) IsMigratable() bool {
 	return false
 }
 
+// MigratesOnLivenessProbeFailure reports whether the VMI is to be migrated
+// instead of stopped once its liveness probe failed.
+func (v *VirtualMachineInstance) MigratesOnLivenessProbeFailure() bool {
+	return v.Spec.LivenessProbe != nil && v.Spec.LivenessProbeFailureAction == LivenessProbeFailureActionMigrate
+}
+
 func (v *VirtualMachineInstance) IsEvictable() bool {
 	return v.Spec.EvictionStrategy != nil && *v.Spec.EvictionStrategy == EvictionStrategyLiveMigrate
 }
 
+func (v *VirtualMachineInstance) IsProtectedUntilDrained() bool {
+	return v.Spec.EvictionStrategy != nil && *v.Spec.EvictionStrategy == EvictionStrategyProtectUntilDrained
+}
+
 func (v *VirtualMachineInstance) IsFinal() bool {
 	return v.Status.Phase == Failed || v.Status.Phase == Succeeded
 }
@@ -338,6 +479,38 @@ const (
 	VirtualMachineInstanceReasonInterfaceNotMigratable = "InterfaceNotLiveMigratable"
 	// Reason means that VMI is not live migratioable because of it's network interfaces collection
 	VirtualMachineInstanceReasonHotplugNotMigratable = "HotplugNotLiveMigratable"
+
+	// Reflects whether virt-handler applied the SELinux labels required by the VMI before starting it
+	VirtualMachineInstanceSELinuxLabelsApplied VirtualMachineInstanceConditionType = "SELinuxLabelsApplied"
+	// Reason means that virt-handler failed to apply a SELinux label required by the VMI
+	VirtualMachineInstanceReasonSELinuxRelabelFailed = "SELinuxRelabelFailed"
+	// Reason means that virt-handler did not confirm the SELinux relabeling of the VMI within the relabel timeout
+	VirtualMachineInstanceReasonSELinuxRelabelTimedOut = "SELinuxRelabelTimedOut"
+	// Reason means that virt-handler stopped retrying the VMI after repeated SELinux failures, until its spec changes
+	VirtualMachineInstanceReasonSELinuxCircuitOpen = "SELinuxCircuitOpen"
+	// Reflects whether the devices of the VMI carry the SELinux label of its launcher, as last verified by virt-handler
+	VirtualMachineInstanceSELinuxLabelsConsistent VirtualMachineInstanceConditionType = "SELinuxLabelsConsistent"
+	// Reason means that at least one device of the VMI carries a SELinux label other than the one of its launcher
+	VirtualMachineInstanceReasonSELinuxLabelMismatch = "SELinuxLabelMismatch"
+
+	// Reflects whether the startup probe of the VMI succeeded, the VMI is not ready before
+	VirtualMachineInstanceStartupProbeSucceeded VirtualMachineInstanceConditionType = "StartupProbeSucceeded"
+	// Reason means that the startup probe did not succeed yet
+	VirtualMachineInstanceReasonStartupProbePending = "StartupProbePending"
+	// Reason means that the startup probe failed more often than its failure threshold
+	VirtualMachineInstanceReasonStartupProbeFailed = "StartupProbeFailed"
+	// Reason means that the startup probe can't be evaluated since the guest agent is not connected
+	VirtualMachineInstanceReasonGuestAgentUnavailable = "GuestAgentUnavailable"
+
+	// Reflects whether the host devices passed through to the VMI are healthy on the node
+	VirtualMachineInstanceHostDevicesHealthy VirtualMachineInstanceConditionType = "HostDevicesHealthy"
+	// Reason means that at least one host device of the VMI is degraded, e.g. its link is down
+	VirtualMachineInstanceReasonHostDeviceDegraded = "HostDeviceDegraded"
+
+	// If the guest crashed and is preserved for inspection by its crash action, this is reported as true.
+	VirtualMachineInstanceCrashed VirtualMachineInstanceConditionType = "Crashed"
+	// Reason means that the guest reported a kernel panic
+	VirtualMachineInstanceReasonGuestPanicked = "GuestPanicked"
 )
 
 const (
@@ -476,6 +649,57 @@ type VirtualMachineInstanceMigrationState struct {
 	MigrationUID types.UID `json:"migrationUid,omitempty"`
 	// Lets us know if the vmi is currently running pre or post copy migration
 	Mode MigrationMode `json:"mode,omitempty"`
+	// The bandwidth the migration is allowed to use, per second
+	// +nullable
+	Bandwidth *resource.Quantity `json:"bandwidth,omitempty"`
+	// The number of parallel connections migrating the memory
+	ParallelMigrationThreads *uint32 `json:"parallelMigrationThreads,omitempty"`
+	// Indicates that the migration pre-copies the memory without cutting over
+	// until the cutover is requested
+	Warmup bool `json:"warmup,omitempty"`
+	// Indicates that the cutover of a warm migration has been requested
+	CutoverRequested bool `json:"cutoverRequested,omitempty"`
+	// The convergence of the warmup, as reported by the source node
+	// +nullable
+	WarmupStatus *MigrationWarmupStatus `json:"warmupStatus,omitempty"`
+	// The pinning of the dedicated vCPUs, as rebalanced along the NUMA layout
+	// of the target node once the migration completed
+	// +listType=atomic
+	TargetVCPUPinning []VCPUPin `json:"targetVCPUPinning,omitempty"`
+}
+
+// VCPUPin is the host CPU a vCPU of the vmi is pinned to
+//
+// +k8s:openapi-gen=true
+type VCPUPin struct {
+	// The vCPU of the vmi
+	VCPU uint32 `json:"vcpu"`
+	// The host CPU the vCPU is pinned to
+	CPU int `json:"cpu"`
+}
+
+// MigrationWarmupStatus estimates how close the pre-copy of a warm migration
+// is to converging, by comparing the rate the memory is transferred at with the
+// rate the guest dirties it.
+//
+// +k8s:openapi-gen=true
+type MigrationWarmupStatus struct {
+	// The number of pre-copy passes over the memory of the vmi
+	Iteration int64 `json:"iteration,omitempty"`
+	// The memory still to be transferred to the target
+	MemoryRemainingBytes int64 `json:"memoryRemainingBytes,omitempty"`
+	// The rate the guest dirties its memory at
+	DirtyRateBytesPerSecond int64 `json:"dirtyRateBytesPerSecond,omitempty"`
+	// The rate the memory is transferred to the target at
+	TransferRateBytesPerSecond int64 `json:"transferRateBytesPerSecond,omitempty"`
+	// Indicates that the memory is transferred faster than it is dirtied
+	Converging bool `json:"converging,omitempty"`
+	// The time the vmi is expected to be paused for if the cutover is
+	// requested now, at the current transfer rate
+	EstimatedDowntimeMilliseconds int64 `json:"estimatedDowntimeMilliseconds,omitempty"`
+	// The time the estimates were last reported
+	// +nullable
+	LastUpdateTimestamp *metav1.Time `json:"lastUpdateTimestamp,omitempty"`
 }
 
 //
@@ -573,6 +797,21 @@ const (
 	// This label declares whether a particular node is available for
 	// scheduling virtual machine instances on it. Used on Node.
 	NodeSchedulable string = "kubevirt.io/schedulable"
+	// This label prefix is followed by the SELinux launcher types virt-handler
+	// found available in the policy of a node. Used on Node.
+	SELinuxLauncherTypeLabel string = "selinux-type.node.kubevirt.io/"
+	// This label describes the SELinux mode of a node, enforcing or
+	// permissive. It is absent on nodes without SELinux. Used on Node.
+	SELinuxModeLabel string = "kubevirt.io/selinux-mode"
+	// This label carries the workload class of the VMI owning a launcher pod,
+	// the pods of the same class are spread across nodes. Used on Pod.
+	WorkloadClassLabel string = "kubevirt.io/workload-class"
+	// This annotation summarizes the last SELinux denial virt-handler found
+	// in the audit log for the launcher of a VMI. Used on VirtualMachineInstance.
+	LastSELinuxDenialAnnotation string = "kubevirt.io/last-selinux-denial"
+	// This condition reports whether SELinux policy modules required by the
+	// launchers are missing from the policy of a node. Used on Node.
+	NodeSELinuxPolicyModulesMissing string = "KubeVirtSELinuxPolicyModulesMissing"
 	// This annotation is regularly updated by virt-handler to help determine
 	// if a particular node is alive and hence should be available for new
 	// virtual machine instance scheduling. Used on Node.
@@ -621,6 +860,14 @@ const (
 	// Used on VirtualMachineInstance.
 	IgnitionAnnotation           string = "kubevirt.io/ignitiondata"
 	PlacePCIDevicesOnRootComplex string = "kubevirt.io/placePCIDevicesOnRootComplex"
+	// This annotation enables the given comma separated qemu log items, e.g.
+	// guest_errors,unimp, passed to qemu through its -d flag.
+	// Used on VirtualMachineInstance.
+	QEMULogFiltersAnnotation string = "kubevirt.io/qemu-log-filters"
+	// This annotation overrides the grace period observed by virt-launcher when
+	// the VirtualMachineInstance pod is terminated, e.g. during a node drain.
+	// Used on VirtualMachineInstance.
+	DrainGracePeriodSecondsAnnotation string = "kubevirt.io/grace-period-drain-seconds"
 
 	VirtualMachineLabel        = AppLabel + "/vm"
 	MemfdMemoryBackend  string = "kubevirt.io/memfd"
@@ -891,6 +1138,26 @@ type VirtualMachineInstanceMigrationList struct {
 type VirtualMachineInstanceMigrationSpec struct {
 	// The name of the VMI to perform the migration on. VMI must exist in the migration objects namespace
 	VMIName string `json:"vmiName,omitempty" valid:"required"`
+	// Warmup pre-copies the memory of the VMI to the target without cutting over,
+	// so that the cutover pauses the VMI for less time. The migration stays in
+	// the WarmingUp phase until commitCutover is set.
+	// +optional
+	Warmup bool `json:"warmup,omitempty"`
+	// CommitCutover ends the warmup of a warm migration and lets it complete.
+	// It can only be set on migrations with warmup, and not be unset.
+	// +optional
+	CommitCutover bool `json:"commitCutover,omitempty"`
+	// Bandwidth is the bandwidth the migration may use at most, per second.
+	// It is limited to the bandwidthPerMigration of the cluster, which is
+	// used if unset.
+	// +optional
+	Bandwidth *resource.Quantity `json:"bandwidth,omitempty"`
+	// ParallelMigrationThreads is the number of parallel connections
+	// migrating the memory (multifd). It is limited to the
+	// maxParallelMigrationThreads of the cluster. The memory is migrated over
+	// a single connection if unset.
+	// +optional
+	ParallelMigrationThreads *uint32 `json:"parallelMigrationThreads,omitempty"`
 }
 
 // VirtualMachineInstanceMigration reprents information pertaining to a VMI's migration.
@@ -899,6 +1166,17 @@ type VirtualMachineInstanceMigrationSpec struct {
 type VirtualMachineInstanceMigrationStatus struct {
 	Phase      VirtualMachineInstanceMigrationPhase       `json:"phase,omitempty"`
 	Conditions []VirtualMachineInstanceMigrationCondition `json:"conditions,omitempty"`
+	// The convergence estimates of the warmup of a warm migration
+	// +optional
+	Warmup *MigrationWarmupStatus `json:"warmup,omitempty"`
+	// The bandwidth the migration is allowed to use, per second, once the
+	// limits of the cluster were applied
+	// +optional
+	Bandwidth *resource.Quantity `json:"bandwidth,omitempty"`
+	// The number of parallel connections migrating the memory, once the
+	// limits of the cluster were applied
+	// +optional
+	ParallelMigrationThreads *uint32 `json:"parallelMigrationThreads,omitempty"`
 }
 
 // VirtualMachineInstanceMigrationPhase is a label for the condition of a VirtualMachineInstanceMigration at the current time.
@@ -919,6 +1197,8 @@ const (
 	MigrationPreparingTarget VirtualMachineInstanceMigrationPhase = "PreparingTarget"
 	// The migration's target pod is prepared and ready for migration
 	MigrationTargetReady VirtualMachineInstanceMigrationPhase = "TargetReady"
+	// The memory of the vmi is pre-copied, waiting for the cutover to be committed
+	MigrationWarmingUp VirtualMachineInstanceMigrationPhase = "WarmingUp"
 	// The migration is in progress
 	MigrationRunning VirtualMachineInstanceMigrationPhase = "Running"
 	// The migration passed
@@ -1054,6 +1334,11 @@ type VirtualMachineSpec struct {
 	// mutually exclusive with Running
 	RunStrategy *VirtualMachineRunStrategy `json:"runStrategy,omitempty" optional:"true"`
 
+	// Schedule starts and stops the VirtualMachine at the given times, by
+	// switching its RunStrategy between Always and Halted.
+	// +optional
+	Schedule *VirtualMachineSchedule `json:"schedule,omitempty"`
+
 	// Template is the direct specification of VirtualMachineInstance
 	Template *VirtualMachineInstanceTemplateSpec `json:"template"`
 
@@ -1062,6 +1347,25 @@ type VirtualMachineSpec struct {
 	DataVolumeTemplates []DataVolumeTemplateSpec `json:"dataVolumeTemplates,omitempty"`
 }
 
+// VirtualMachineSchedule describes when a VirtualMachine is started and stopped
+//
+// +k8s:openapi-gen=true
+type VirtualMachineSchedule struct {
+	// Start is the cron schedule, in the five field format
+	// "minute hour day-of-month month day-of-week", at which the
+	// VirtualMachine is started.
+	// +optional
+	Start string `json:"start,omitempty"`
+	// Stop is the cron schedule, in the same format as Start, at which the
+	// VirtualMachine is stopped.
+	// +optional
+	Stop string `json:"stop,omitempty"`
+	// TimeZone is the IANA name of the time zone the schedules are evaluated in.
+	// Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
 // StateChangeRequestType represents the existing state change requests that are possible
 //
 // +k8s:openapi-gen=true
@@ -1098,6 +1402,11 @@ type VirtualMachineStatus struct {
 	// VolumeSnapshotStatuses indicates a list of statuses whether snapshotting is
 	// supported by each volume.
 	VolumeSnapshotStatuses []VolumeSnapshotStatus `json:"volumeSnapshotStatuses,omitempty" optional:"true"`
+
+	// LastScheduledTransition is the time of the latest start or stop of the
+	// schedule handled by the controller.
+	// +optional
+	LastScheduledTransition *metav1.Time `json:"lastScheduledTransition,omitempty"`
 }
 
 // +k8s:openapi-gen=true
@@ -1215,6 +1524,31 @@ const (
 	IODefault DriverIO = "default"
 )
 
+//
+// +k8s:openapi-gen=true
+type VirtiofsCache string
+
+//
+// +k8s:openapi-gen=true
+type VirtiofsSandbox string
+
+const (
+	// VirtiofsCacheNone - Data and metadata are always read from the shared directory, which keeps the guest view
+	// coherent with changes made on the host.
+	VirtiofsCacheNone VirtiofsCache = "none"
+	// VirtiofsCacheAuto - Metadata and data are cached for a short while and data is invalidated on open.
+	VirtiofsCacheAuto VirtiofsCache = "auto"
+	// VirtiofsCacheAlways - Data and metadata are never invalidated. This is the fastest mode but the guest won't see
+	// changes made to the shared directory from outside of the guest.
+	VirtiofsCacheAlways VirtiofsCache = "always"
+
+	// VirtiofsSandboxNamespace - virtiofsd isolates itself in its own mount, pid and network namespaces.
+	VirtiofsSandboxNamespace VirtiofsSandbox = "namespace"
+	// VirtiofsSandboxChroot - virtiofsd only chroots into the shared directory. This does not require the privileges
+	// to create namespaces.
+	VirtiofsSandboxChroot VirtiofsSandbox = "chroot"
+)
+
 // Handler defines a specific action that should be taken
 // TODO: pass structured data to these actions, and document that data here.
 type Handler struct {
@@ -1226,6 +1560,20 @@ type Handler struct {
 	// TODO: implement a realistic TCP lifecycle hook
 	// +optional
 	TCPSocket *k8sv1.TCPSocketAction `json:"tcpSocket,omitempty"`
+	// Exec specifies a command run in the guest through the guest agent,
+	// the probe succeeds if the command exits with 0.
+	// Only supported by startup probes.
+	// +optional
+	Exec *k8sv1.ExecAction `json:"exec,omitempty"`
+	// GuestAgentPing contacts the guest agent, the probe succeeds if it answers.
+	// Only supported by startup probes.
+	// +optional
+	GuestAgentPing *GuestAgentPing `json:"guestAgentPing,omitempty"`
+}
+
+// GuestAgentPing configures a probe pinging the guest agent
+// +k8s:openapi-gen=true
+type GuestAgentPing struct {
 }
 
 // Probe describes a health check to be performed against a VirtualMachineInstance to determine whether it is
@@ -1257,6 +1605,46 @@ type Probe struct {
 	FailureThreshold int32 `json:"failureThreshold,omitempty"`
 }
 
+// FreezeHooks are the commands run in the guest around the freeze of its filesystems
+// +k8s:openapi-gen=true
+type FreezeHooks struct {
+	// PreFreeze is run before the guest filesystems are frozen
+	// +optional
+	PreFreeze *FreezeHook `json:"preFreeze,omitempty"`
+	// PostThaw is run after the guest filesystems are thawed again
+	// +optional
+	PostThaw *FreezeHook `json:"postThaw,omitempty"`
+}
+
+// FreezeHook is a command executed in the guest through the guest agent
+// +k8s:openapi-gen=true
+type FreezeHook struct {
+	// Command is the command line to execute in the guest, starting with the absolute
+	// path of the executable. It is not run in a shell.
+	Command []string `json:"command"`
+	// Number of seconds after which the hook is considered failed.
+	// Defaults to 10 seconds. Minimum value is 1, maximum value is 15.
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+	// FailurePolicy tells what happens when the hook fails or times out: Abort fails the
+	// freeze, respectively the thaw, Continue only logs the failure.
+	// Defaults to Abort.
+	// +optional
+	FailurePolicy FreezeHookFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// FreezeHookFailurePolicy tells what happens when a freeze hook fails
+// +k8s:openapi-gen=true
+type FreezeHookFailurePolicy string
+
+const (
+	// FreezeHookFailurePolicyAbort fails the freeze or the thaw when the hook fails.
+	// A failed pre-freeze hook leaves the guest filesystems unfrozen.
+	FreezeHookFailurePolicyAbort FreezeHookFailurePolicy = "Abort"
+	// FreezeHookFailurePolicyContinue only logs the failure of the hook.
+	FreezeHookFailurePolicyContinue FreezeHookFailurePolicy = "Continue"
+)
+
 // KubeVirt represents the object deploying all KubeVirt resources
 //
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -1444,7 +1832,13 @@ const (
 )
 
 const (
-	EvictionStrategyLiveMigrate EvictionStrategy = "LiveMigrate"
+	EvictionStrategyLiveMigrate         EvictionStrategy = "LiveMigrate"
+	EvictionStrategyProtectUntilDrained EvictionStrategy = "ProtectUntilDrained"
+)
+
+const (
+	LivenessProbeFailureActionRestart LivenessProbeFailureAction = "Restart"
+	LivenessProbeFailureActionMigrate LivenessProbeFailureAction = "Migrate"
 )
 
 // RestartOptions may be provided when deleting an API object.
@@ -1554,23 +1948,57 @@ type RemoveVolumeOptions struct {
 	Name string `json:"name"`
 }
 
+// ExportDiskOptions is provided when exporting a volume of a running VirtualMachineInstance
+// to a new DataVolume
+// +k8s:openapi-gen=true
+type ExportDiskOptions struct {
+	// VolumeName is the name of the volume to export. The volume
+	// must be backed by a PersistentVolumeClaim or a DataVolume.
+	VolumeName string `json:"volumeName"`
+	// DataVolumeName is the name of the DataVolume to create from
+	// the snapshot of the volume.
+	DataVolumeName string `json:"dataVolumeName"`
+	// VolumeSnapshotClassName is the VolumeSnapshotClass used to
+	// snapshot the volume. The default VolumeSnapshotClass of the
+	// CSI driver is used when it is not set.
+	// +optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+}
+
+// MemoryDumpOptions is provided when dumping the memory of a running VirtualMachineInstance
+// +k8s:openapi-gen=true
+type MemoryDumpOptions struct {
+	// ClaimName is the name of the PersistentVolumeClaim to write the dump to.
+	// The claim must be in filesystem mode and large enough to hold the memory
+	// of the guest.
+	ClaimName string `json:"claimName"`
+}
+
 // KubeVirtConfiguration holds all kubevirt configurations
 // +k8s:openapi-gen=true
 type KubeVirtConfiguration struct {
-	CPUModel                    string                  `json:"cpuModel,omitempty"`
-	CPURequest                  *resource.Quantity      `json:"cpuRequest,omitempty"`
-	DeveloperConfiguration      *DeveloperConfiguration `json:"developerConfiguration,omitempty"`
-	EmulatedMachines            []string                `json:"emulatedMachines,omitempty"`
-	ImagePullPolicy             k8sv1.PullPolicy        `json:"imagePullPolicy,omitempty"`
-	MigrationConfiguration      *MigrationConfiguration `json:"migrations,omitempty"`
-	MachineType                 string                  `json:"machineType,omitempty"`
-	NetworkConfiguration        *NetworkConfiguration   `json:"network,omitempty"`
-	OVMFPath                    string                  `json:"ovmfPath,omitempty"`
-	SELinuxLauncherType         string                  `json:"selinuxLauncherType,omitempty"`
-	SMBIOSConfig                *SMBiosConfiguration    `json:"smbios,omitempty"`
-	SupportedGuestAgentVersions []string                `json:"supportedGuestAgentVersions,omitempty"`
-	MemBalloonStatsPeriod       *uint32                 `json:"memBalloonStatsPeriod,omitempty"`
-	PermittedHostDevices        *PermittedHostDevices   `json:"permittedHostDevices,omitempty"`
+	CPUModel                     string                  `json:"cpuModel,omitempty"`
+	CPURequest                   *resource.Quantity      `json:"cpuRequest,omitempty"`
+	DeveloperConfiguration       *DeveloperConfiguration `json:"developerConfiguration,omitempty"`
+	EmulatedMachines             []string                `json:"emulatedMachines,omitempty"`
+	ImagePullPolicy              k8sv1.PullPolicy        `json:"imagePullPolicy,omitempty"`
+	MigrationConfiguration       *MigrationConfiguration `json:"migrations,omitempty"`
+	MachineType                  string                  `json:"machineType,omitempty"`
+	NetworkConfiguration         *NetworkConfiguration   `json:"network,omitempty"`
+	OVMFPath                     string                  `json:"ovmfPath,omitempty"`
+	SELinuxLauncherType          string                  `json:"selinuxLauncherType,omitempty"`
+	SMBIOSConfig                 *SMBiosConfiguration    `json:"smbios,omitempty"`
+	SupportedGuestAgentVersions  []string                `json:"supportedGuestAgentVersions,omitempty"`
+	MemBalloonStatsPeriod        *uint32                 `json:"memBalloonStatsPeriod,omitempty"`
+	PermittedHostDevices         *PermittedHostDevices   `json:"permittedHostDevices,omitempty"`
+	MaxDrainGracePeriodSeconds   *int64                  `json:"maxDrainGracePeriodSeconds,omitempty"`
+	CustomSELinuxLauncherTypes   []string                `json:"customSELinuxLauncherTypes,omitempty"`
+	GuestAgentCommandAllowList   []string                `json:"guestAgentCommandAllowList,omitempty"`
+	SELinuxRelabelParallelism    *uint32                 `json:"selinuxRelabelParallelism,omitempty"`
+	SELinuxRelabelTimeoutSeconds *int64                  `json:"selinuxRelabelTimeoutSeconds,omitempty"`
+	// SELinuxRelabelSkipPaths are the paths virt-handler never relabels, e.g. tmpfs backed devices.
+	// Entries with glob characters are matched as globs, the others as path prefixes.
+	SELinuxRelabelSkipPaths []string `json:"selinuxRelabelSkipPaths,omitempty"`
 }
 
 //
@@ -1595,6 +2023,7 @@ type MigrationConfiguration struct {
 	ProgressTimeout                   *int64             `json:"progressTimeout,omitempty"`
 	UnsafeMigrationOverride           *bool              `json:"unsafeMigrationOverride,omitempty"`
 	AllowPostCopy                     *bool              `json:"allowPostCopy,omitempty"`
+	MaxParallelMigrationThreads       *uint32            `json:"maxParallelMigrationThreads,omitempty"`
 }
 
 // DeveloperConfiguration holds developer options
@@ -1603,6 +2032,7 @@ type DeveloperConfiguration struct {
 	FeatureGates           []string          `json:"featureGates,omitempty"`
 	LessPVCSpaceToleration int               `json:"pvcTolerateLessSpaceUpToPercent,omitempty"`
 	MemoryOvercommit       int               `json:"memoryOvercommit,omitempty"`
+	MaxMemoryOvercommit    int               `json:"maxMemoryOvercommit,omitempty"`
 	NodeSelectors          map[string]string `json:"nodeSelectors,omitempty"`
 	UseEmulation           bool              `json:"useEmulation,omitempty"`
 	CPUAllocationRatio     int               `json:"cpuAllocationRatio,omitempty"`
@@ -1652,4 +2082,18 @@ type NetworkConfiguration struct {
 	NetworkInterface                  string `json:"defaultNetworkInterface,omitempty"`
 	PermitSlirpInterface              *bool  `json:"permitSlirpInterface,omitempty"`
 	PermitBridgeInterfaceOnPodNetwork *bool  `json:"permitBridgeInterfaceOnPodNetwork,omitempty"`
+	// MacAddressPools interfaces can draw their MAC address from.
+	MacAddressPools []MacAddressPool `json:"macAddressPools,omitempty"`
+}
+
+// MacAddressPool is a range of MAC addresses handed out to the interfaces
+// requesting it.
+// +k8s:openapi-gen=true
+type MacAddressPool struct {
+	// Name of the pool, referenced by the macAddressPool of interfaces.
+	Name string `json:"name"`
+	// First MAC address of the pool. For example: 02:00:00:00:00:00.
+	RangeStart string `json:"rangeStart"`
+	// Last MAC address of the pool. For example: 02:00:00:00:ff:ff.
+	RangeEnd string `json:"rangeEnd"`
 }
@@ -23,13 +23,17 @@ func (VirtualMachineInstanceSpec) SwaggerDoc() map[string]string {
 		"domain":                        "Specification of the desired behavior of the VirtualMachineInstance on the host.",
 		"nodeSelector":                  "NodeSelector is a selector which must be true for the vmi to fit on a node.\nSelector which must match a node's labels for the vmi to be scheduled on that node.\nMore info: https://kubernetes.io/docs/concepts/configuration/assign-pod-node/\n+optional",
 		"affinity":                      "If affinity is specifies, obey all the affinity rules",
+		"workloadClass":                 "WorkloadClass groups the VMIs to spread across nodes. The pods of the\nVMIs of the same class in a namespace prefer not to be scheduled on the\nsame node. It has to be a DNS-1123 label.\n+optional",
 		"schedulerName":                 "If specified, the VMI will be dispatched by specified scheduler.\nIf not specified, the VMI will be dispatched by default scheduler.\n+optional",
 		"tolerations":                   "If toleration is specified, obey all the toleration rules.",
-		"evictionStrategy":              "EvictionStrategy can be set to \"LiveMigrate\" if the VirtualMachineInstance should be\nmigrated instead of shut-off in case of a node drain.\n\n+optional",
+		"evictionStrategy":              "EvictionStrategy can be set to \"LiveMigrate\" if the VirtualMachineInstance should be\nmigrated instead of shut-off in case of a node drain, or to \"ProtectUntilDrained\"\nif it should be shut down gracefully once its node is drained.\n\n+optional",
 		"terminationGracePeriodSeconds": "Grace period observed after signalling a VirtualMachineInstance to stop after which the VirtualMachineInstance is force terminated.",
 		"volumes":                       "List of volumes that can be mounted by disks belonging to the vmi.",
 		"livenessProbe":                 "Periodic probe of VirtualMachineInstance liveness.\nVirtualmachineInstances will be stopped if the probe fails.\nCannot be updated.\nMore info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes\n+optional",
+		"livenessProbeFailureAction":    "Action taken once the liveness probe failed.\nRestart, the default, stops the VirtualMachineInstance.\nMigrate live migrates it off its node instead, or stops it if it is not live migratable.\nMigrate requires a livenessProbe and no readinessProbe, the liveness probe then drives the readiness of the VirtualMachineInstance.\nCannot be updated.\n+optional",
 		"readinessProbe":                "Periodic probe of VirtualMachineInstance service readiness.\nVirtualmachineInstances will be removed from service endpoints if the probe fails.\nCannot be updated.\nMore info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes\n+optional",
+		"startupProbe":                  "Probe of the VirtualMachineInstance startup, evaluated through the guest agent.\nThe VirtualMachineInstance is not ready until the probe succeeded.\nOnly guestAgentPing and exec probes are supported.\nCannot be updated.\n+optional",
+		"freezeHooks":                   "Commands run in the guest through the guest agent around the freeze of its filesystems,\ne.g. to flush and quiesce applications before the disks are snapshotted.\n+optional",
 		"hostname":                      "Specifies the hostname of the vmi\nIf not specified, the hostname will be set to the name of the vmi, if dhcp or cloud-init is configured properly.\n+optional",
 		"subdomain":                     "If specified, the fully qualified vmi hostname will be \"<hostname>.<subdomain>.<pod namespace>.svc.<cluster domain>\".\nIf not specified, the vmi will not have a domainname at all. The DNS entry will resolve to the vmi,\nno matter if the vmi itself can pick up a hostname.\n+optional",
 		"networks":                      "List of networks that can be attached to a vm's virtual interface.",
@@ -41,19 +45,57 @@ func (VirtualMachineInstanceSpec) SwaggerDoc() map[string]string {
 
 func (VirtualMachineInstanceStatus) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":                   "VirtualMachineInstanceStatus represents information about the status of a VirtualMachineInstance. Status may trail the actual\nstate of a system.\n\n+k8s:openapi-gen=true",
-		"nodeName":           "NodeName is the name where the VirtualMachineInstance is currently running.",
-		"reason":             "A brief CamelCase message indicating details about why the VMI is in this state. e.g. 'NodeUnresponsive'\n+optional",
-		"conditions":         "Conditions are specific points in VirtualMachineInstance's pod runtime.",
-		"phase":              "Phase is the status of the VirtualMachineInstance in kubernetes world. It is not the VirtualMachineInstance status, but partially correlates to it.",
-		"interfaces":         "Interfaces represent the details of available network interfaces.",
-		"guestOSInfo":        "Guest OS Information",
-		"migrationState":     "Represents the status of a live migration",
-		"migrationMethod":    "Represents the method using which the vmi can be migrated: live migration or block migration",
-		"qosClass":           "The Quality of Service (QOS) classification assigned to the virtual machine instance based on resource requirements\nSee PodQOSClass type for available QOS classes\nMore info: https://git.k8s.io/community/contributors/design-proposals/node/resource-qos.md\n+optional",
-		"evacuationNodeName": "EvacuationNodeName is used to track the eviction process of a VMI. It stores the name of the node that we want\nto evacuate. It is meant to be used by KubeVirt core components only and can't be set or modified by users.\n+optional",
-		"activePods":         "ActivePods is a mapping of pod UID to node name.\nIt is possible for multiple pods to be running for a single VMI during migration.",
-		"volumeStatus":       "VolumeStatus contains the statuses of all the volumes\n+optional\n+listType=atomic",
+		"":                        "VirtualMachineInstanceStatus represents information about the status of a VirtualMachineInstance. Status may trail the actual\nstate of a system.\n\n+k8s:openapi-gen=true",
+		"nodeName":                "NodeName is the name where the VirtualMachineInstance is currently running.",
+		"reason":                  "A brief CamelCase message indicating details about why the VMI is in this state. e.g. 'NodeUnresponsive'\n+optional",
+		"conditions":              "Conditions are specific points in VirtualMachineInstance's pod runtime.",
+		"phase":                   "Phase is the status of the VirtualMachineInstance in kubernetes world. It is not the VirtualMachineInstance status, but partially correlates to it.",
+		"interfaces":              "Interfaces represent the details of available network interfaces.",
+		"guestOSInfo":             "Guest OS Information",
+		"migrationState":          "Represents the status of a live migration",
+		"migrationMethod":         "Represents the method using which the vmi can be migrated: live migration or block migration",
+		"qosClass":                "The Quality of Service (QOS) classification assigned to the virtual machine instance based on resource requirements\nSee PodQOSClass type for available QOS classes\nMore info: https://git.k8s.io/community/contributors/design-proposals/node/resource-qos.md\n+optional",
+		"evacuationNodeName":      "EvacuationNodeName is used to track the eviction process of a VMI. It stores the name of the node that we want\nto evacuate. It is meant to be used by KubeVirt core components only and can't be set or modified by users.\n+optional",
+		"activePods":              "ActivePods is a mapping of pod UID to node name.\nIt is possible for multiple pods to be running for a single VMI during migration.",
+		"volumeStatus":            "VolumeStatus contains the statuses of all the volumes\n+optional\n+listType=atomic",
+		"drainGracePeriodSeconds": "DrainGracePeriodSeconds is the effective grace period observed by virt-launcher before the VirtualMachineInstance is\nkilled when its pod is terminated, for instance during a node drain.\n+optional",
+		"lastSoftReboot":          "LastSoftReboot describes the last soft reboot requested through the softreboot subresource\n+optional",
+		"memoryDump":              "MemoryDump describes the last memory dump requested through the memorydump subresource\n+optional",
+		"memoryOvercommit":        "MemoryOvercommit describes the memory requested for a VirtualMachineInstance with an overcommit percentage\n+optional",
+	}
+}
+
+func (SoftRebootStatus) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":          "SoftRebootStatus describes a soft reboot of a VirtualMachineInstance\n+k8s:openapi-gen=true",
+		"mechanism": "Mechanism is the way the reboot was signaled to the guest, GuestAgent or ACPI",
+		"timestamp": "Timestamp is the time the reboot was signaled\n+optional",
+	}
+}
+
+func (MemoryDumpStatus) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":               "MemoryDumpStatus describes a memory dump of a VirtualMachineInstance\n+k8s:openapi-gen=true",
+		"claimName":      "ClaimName is the name of the PersistentVolumeClaim the dump is written to",
+		"phase":          "Phase is the phase of the dump",
+		"fileName":       "FileName is the name of the dump file, at the root of the claim",
+		"targetPodName":  "TargetPodName is the name of the pod attaching the claim to the node of the VirtualMachineInstance\n+optional",
+		"targetPodUID":   "TargetPodUID is the UID of the pod attaching the claim, set once the claim is attached to the node\n+optional",
+		"bytesWritten":   "BytesWritten is the amount of guest memory written to the claim so far\n+optional",
+		"bytesTotal":     "BytesTotal is the amount of guest memory to write to the claim\n+optional",
+		"startTimestamp": "StartTimestamp is the time the dump was requested\n+optional",
+		"endTimestamp":   "EndTimestamp is the time the dump completed or failed\n+optional",
+		"message":        "Message is a human readable description of the failure of the dump\n+optional",
+	}
+}
+
+func (MemoryOvercommitStatus) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":                 "MemoryOvercommitStatus describes the memory requested for a VirtualMachineInstance with an overcommit percentage\n+k8s:openapi-gen=true",
+		"percent":          "Percent is the overcommit percentage applied to the guest memory",
+		"guestMemory":      "GuestMemory is the memory of the guest",
+		"memoryRequest":    "MemoryRequest is the memory requested for the guest, its memory scaled down by the overcommit percentage",
+		"podMemoryRequest": "PodMemoryRequest is the memory requested by the compute container of the pod, including the overhead of virt-launcher",
 	}
 }
 
@@ -135,6 +177,33 @@ func (VirtualMachineInstanceMigrationState) SwaggerDoc() map[string]string {
 		"abortStatus":                    "Indicates the final status of the live migration abortion",
 		"migrationUid":                   "The VirtualMachineInstanceMigration object associated with this migration",
 		"mode":                           "Lets us know if the vmi is currently running pre or post copy migration",
+		"bandwidth":                      "The bandwidth the migration is allowed to use, per second\n+nullable",
+		"parallelMigrationThreads":       "The number of parallel connections migrating the memory",
+		"warmup":                         "Indicates that the migration pre-copies the memory without cutting over\nuntil the cutover is requested",
+		"cutoverRequested":               "Indicates that the cutover of a warm migration has been requested",
+		"warmupStatus":                   "The convergence of the warmup, as reported by the source node\n+nullable",
+		"targetVCPUPinning":              "The pinning of the dedicated vCPUs, as rebalanced along the NUMA layout\nof the target node once the migration completed\n+listType=atomic",
+	}
+}
+
+func (VCPUPin) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":     "VCPUPin is the host CPU a vCPU of the vmi is pinned to\n\n+k8s:openapi-gen=true",
+		"vcpu": "The vCPU of the vmi",
+		"cpu":  "The host CPU the vCPU is pinned to",
+	}
+}
+
+func (MigrationWarmupStatus) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":                              "MigrationWarmupStatus estimates how close the pre-copy of a warm migration\nis to converging, by comparing the rate the memory is transferred at with the\nrate the guest dirties it.\n\n+k8s:openapi-gen=true",
+		"iteration":                     "The number of pre-copy passes over the memory of the vmi",
+		"memoryRemainingBytes":          "The memory still to be transferred to the target",
+		"dirtyRateBytesPerSecond":       "The rate the guest dirties its memory at",
+		"transferRateBytesPerSecond":    "The rate the memory is transferred to the target at",
+		"converging":                    "Indicates that the memory is transferred faster than it is dirtied",
+		"estimatedDowntimeMilliseconds": "The time the vmi is expected to be paused for if the cutover is\nrequested now, at the current transfer rate",
+		"lastUpdateTimestamp":           "The time the estimates were last reported\n+nullable",
 	}
 }
 
@@ -221,14 +290,21 @@ func (VirtualMachineInstanceMigrationList) SwaggerDoc() map[string]string {
 
 func (VirtualMachineInstanceMigrationSpec) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":        "+k8s:openapi-gen=true",
-		"vmiName": "The name of the VMI to perform the migration on. VMI must exist in the migration objects namespace",
+		"":                         "+k8s:openapi-gen=true",
+		"vmiName":                  "The name of the VMI to perform the migration on. VMI must exist in the migration objects namespace",
+		"warmup":                   "Warmup pre-copies the memory of the VMI to the target without cutting over,\nso that the cutover pauses the VMI for less time. The migration stays in\nthe WarmingUp phase until commitCutover is set.\n+optional",
+		"commitCutover":            "CommitCutover ends the warmup of a warm migration and lets it complete.\nIt can only be set on migrations with warmup, and not be unset.\n+optional",
+		"bandwidth":                "Bandwidth is the bandwidth the migration may use at most, per second.\nIt is limited to the bandwidthPerMigration of the cluster, which is\nused if unset.\n+optional",
+		"parallelMigrationThreads": "ParallelMigrationThreads is the number of parallel connections\nmigrating the memory (multifd). It is limited to the\nmaxParallelMigrationThreads of the cluster. The memory is migrated over\na single connection if unset.\n+optional",
 	}
 }
 
 func (VirtualMachineInstanceMigrationStatus) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"": "VirtualMachineInstanceMigration reprents information pertaining to a VMI's migration.\n\n+k8s:openapi-gen=true",
+		"":                         "VirtualMachineInstanceMigration reprents information pertaining to a VMI's migration.\n\n+k8s:openapi-gen=true",
+		"warmup":                   "The convergence estimates of the warmup of a warm migration\n+optional",
+		"bandwidth":                "The bandwidth the migration is allowed to use, per second, once the\nlimits of the cluster were applied\n+optional",
+		"parallelMigrationThreads": "The number of parallel connections migrating the memory, once the\nlimits of the cluster were applied\n+optional",
 	}
 }
 
@@ -272,21 +348,32 @@ func (VirtualMachineSpec) SwaggerDoc() map[string]string {
 		"":                    "VirtualMachineSpec describes how the proper VirtualMachine\nshould look like\n\n+k8s:openapi-gen=true",
 		"running":             "Running controls whether the associatied VirtualMachineInstance is created or not\nMutually exclusive with RunStrategy",
 		"runStrategy":         "Running state indicates the requested running state of the VirtualMachineInstance\nmutually exclusive with Running",
+		"schedule":            "Schedule starts and stops the VirtualMachine at the given times, by\nswitching its RunStrategy between Always and Halted.\n+optional",
 		"template":            "Template is the direct specification of VirtualMachineInstance",
 		"dataVolumeTemplates": "dataVolumeTemplates is a list of dataVolumes that the VirtualMachineInstance template can reference.\nDataVolumes in this list are dynamically created for the VirtualMachine and are tied to the VirtualMachine's life-cycle.",
 	}
 }
 
+func (VirtualMachineSchedule) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":         "VirtualMachineSchedule describes when a VirtualMachine is started and stopped\n\n+k8s:openapi-gen=true",
+		"start":    "Start is the cron schedule, in the five field format\n\"minute hour day-of-month month day-of-week\", at which the\nVirtualMachine is started.\n+optional",
+		"stop":     "Stop is the cron schedule, in the same format as Start, at which the\nVirtualMachine is stopped.\n+optional",
+		"timeZone": "TimeZone is the IANA name of the time zone the schedules are evaluated in.\nDefaults to UTC.\n+optional",
+	}
+}
+
 func (VirtualMachineStatus) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":                       "VirtualMachineStatus represents the status returned by the\ncontroller to describe how the VirtualMachine is doing\n\n+k8s:openapi-gen=true",
-		"snapshotInProgress":     "SnapshotInProgress is the name of the VirtualMachineSnapshot currently executing",
-		"created":                "Created indicates if the virtual machine is created in the cluster",
-		"ready":                  "Ready indicates if the virtual machine is running and ready",
-		"conditions":             "Hold the state information of the VirtualMachine and its VirtualMachineInstance",
-		"stateChangeRequests":    "StateChangeRequests indicates a list of actions that should be taken on a VMI\ne.g. stop a specific VMI then start a new one.",
-		"volumeRequests":         "VolumeRequests indicates a list of volumes add or remove from the VMI template and\nhotplug on an active running VMI.\n+listType=atomic",
-		"volumeSnapshotStatuses": "VolumeSnapshotStatuses indicates a list of statuses whether snapshotting is\nsupported by each volume.",
+		"":                        "VirtualMachineStatus represents the status returned by the\ncontroller to describe how the VirtualMachine is doing\n\n+k8s:openapi-gen=true",
+		"snapshotInProgress":      "SnapshotInProgress is the name of the VirtualMachineSnapshot currently executing",
+		"created":                 "Created indicates if the virtual machine is created in the cluster",
+		"ready":                   "Ready indicates if the virtual machine is running and ready",
+		"conditions":              "Hold the state information of the VirtualMachine and its VirtualMachineInstance",
+		"stateChangeRequests":     "StateChangeRequests indicates a list of actions that should be taken on a VMI\ne.g. stop a specific VMI then start a new one.",
+		"volumeRequests":          "VolumeRequests indicates a list of volumes add or remove from the VMI template and\nhotplug on an active running VMI.\n+listType=atomic",
+		"volumeSnapshotStatuses":  "VolumeSnapshotStatuses indicates a list of statuses whether snapshotting is\nsupported by each volume.",
+		"lastScheduledTransition": "LastScheduledTransition is the time of the latest start or stop of the\nschedule handled by the controller.\n+optional",
 	}
 }
 
@@ -326,9 +413,17 @@ func (VirtualMachineCondition) SwaggerDoc() map[string]string {
 
 func (Handler) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"":          "Handler defines a specific action that should be taken",
-		"httpGet":   "HTTPGet specifies the http request to perform.\n+optional",
-		"tcpSocket": "TCPSocket specifies an action involving a TCP port.\nTCP hooks not yet supported\n+optional",
+		"":               "Handler defines a specific action that should be taken",
+		"httpGet":        "HTTPGet specifies the http request to perform.\n+optional",
+		"tcpSocket":      "TCPSocket specifies an action involving a TCP port.\nTCP hooks not yet supported\n+optional",
+		"exec":           "Exec specifies a command run in the guest through the guest agent,\nthe probe succeeds if the command exits with 0.\nOnly supported by startup probes.\n+optional",
+		"guestAgentPing": "GuestAgentPing contacts the guest agent, the probe succeeds if it answers.\nOnly supported by startup probes.\n+optional",
+	}
+}
+
+func (GuestAgentPing) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"": "GuestAgentPing configures a probe pinging the guest agent\n+k8s:openapi-gen=true",
 	}
 }
 
@@ -343,6 +438,23 @@ func (Probe) SwaggerDoc() map[string]string {
 	}
 }
 
+func (FreezeHooks) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":          "FreezeHooks are the commands run in the guest around the freeze of its filesystems\n+k8s:openapi-gen=true",
+		"preFreeze": "PreFreeze is run before the guest filesystems are frozen\n+optional",
+		"postThaw":  "PostThaw is run after the guest filesystems are thawed again\n+optional",
+	}
+}
+
+func (FreezeHook) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":               "FreezeHook is a command executed in the guest through the guest agent\n+k8s:openapi-gen=true",
+		"command":        "Command is the command line to execute in the guest, starting with the absolute\npath of the executable. It is not run in a shell.",
+		"timeoutSeconds": "Number of seconds after which the hook is considered failed.\nDefaults to 10 seconds. Minimum value is 1, maximum value is 15.\n+optional",
+		"failurePolicy":  "FailurePolicy tells what happens when the hook fails or times out: Abort fails the\nfreeze, respectively the thaw, Continue only logs the failure.\nDefaults to Abort.\n+optional",
+	}
+}
+
 func (KubeVirt) SwaggerDoc() map[string]string {
 	return map[string]string{
 		"": "KubeVirt represents the object deploying all KubeVirt resources\n\n+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object\n+k8s:openapi-gen=true",
@@ -482,9 +594,26 @@ func (RemoveVolumeOptions) SwaggerDoc() map[string]string {
 	}
 }
 
+func (ExportDiskOptions) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":                        "ExportDiskOptions is provided when exporting a volume of a running VirtualMachineInstance\nto a new DataVolume\n+k8s:openapi-gen=true",
+		"volumeName":              "VolumeName is the name of the volume to export. The volume\nmust be backed by a PersistentVolumeClaim or a DataVolume.",
+		"dataVolumeName":          "DataVolumeName is the name of the DataVolume to create from\nthe snapshot of the volume.",
+		"volumeSnapshotClassName": "VolumeSnapshotClassName is the VolumeSnapshotClass used to\nsnapshot the volume. The default VolumeSnapshotClass of the\nCSI driver is used when it is not set.\n+optional",
+	}
+}
+
+func (MemoryDumpOptions) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":          "MemoryDumpOptions is provided when dumping the memory of a running VirtualMachineInstance\n+k8s:openapi-gen=true",
+		"claimName": "ClaimName is the name of the PersistentVolumeClaim to write the dump to.\nThe claim must be in filesystem mode and large enough to hold the memory\nof the guest.",
+	}
+}
+
 func (KubeVirtConfiguration) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"": "KubeVirtConfiguration holds all kubevirt configurations\n+k8s:openapi-gen=true",
+		"":                        "KubeVirtConfiguration holds all kubevirt configurations\n+k8s:openapi-gen=true",
+		"selinuxRelabelSkipPaths": "SELinuxRelabelSkipPaths are the paths virt-handler never relabels, e.g. tmpfs backed devices.\nEntries with glob characters are matched as globs, the others as path prefixes.",
 	}
 }
 
@@ -535,6 +664,16 @@ func (MediatedHostDevice) SwaggerDoc() map[string]string {
 
 func (NetworkConfiguration) SwaggerDoc() map[string]string {
 	return map[string]string{
-		"": "NetworkConfiguration holds network options\n+k8s:openapi-gen=true",
+		"":                "NetworkConfiguration holds network options\n+k8s:openapi-gen=true",
+		"macAddressPools": "MacAddressPools interfaces can draw their MAC address from.",
+	}
+}
+
+func (MacAddressPool) SwaggerDoc() map[string]string {
+	return map[string]string{
+		"":           "MacAddressPool is a range of MAC addresses handed out to the interfaces\nrequesting it.\n+k8s:openapi-gen=true",
+		"name":       "Name of the pool, referenced by the macAddressPool of interfaces.",
+		"rangeStart": "First MAC address of the pool. For example: 02:00:00:00:00:00.",
+		"rangeEnd":   "Last MAC address of the pool. For example: 02:00:00:00:ff:ff.",
 	}
 }
@@ -901,6 +901,16 @@ func (_mr *_MockVirtualMachineInstanceInterfaceRecorder) Unpause(arg0 interface{
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Unpause", arg0)
 }
 
+func (_m *MockVirtualMachineInstanceInterface) SoftReboot(name string) error {
+	ret := _m.ctrl.Call(_m, "SoftReboot", name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockVirtualMachineInstanceInterfaceRecorder) SoftReboot(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SoftReboot", arg0)
+}
+
 func (_m *MockVirtualMachineInstanceInterface) GuestOsInfo(name string) (v117.VirtualMachineInstanceGuestAgentInfo, error) {
 	ret := _m.ctrl.Call(_m, "GuestOsInfo", name)
 	ret0, _ := ret[0].(v117.VirtualMachineInstanceGuestAgentInfo)
@@ -954,6 +964,26 @@ func (_mr *_MockVirtualMachineInstanceInterfaceRecorder) RemoveVolume(arg0, arg1
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "RemoveVolume", arg0, arg1)
 }
 
+func (_m *MockVirtualMachineInstanceInterface) ExportDisk(name string, exportDiskOptions *v117.ExportDiskOptions) error {
+	ret := _m.ctrl.Call(_m, "ExportDisk", name, exportDiskOptions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockVirtualMachineInstanceInterfaceRecorder) ExportDisk(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ExportDisk", arg0, arg1)
+}
+
+func (_m *MockVirtualMachineInstanceInterface) MemoryDump(name string, memoryDumpOptions *v117.MemoryDumpOptions) error {
+	ret := _m.ctrl.Call(_m, "MemoryDump", name, memoryDumpOptions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockVirtualMachineInstanceInterfaceRecorder) MemoryDump(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "MemoryDump", arg0, arg1)
+}
+
 // Mock of ReplicaSetInterface interface
 type MockReplicaSetInterface struct {
 	ctrl     *gomock.Controller
@@ -165,11 +165,14 @@ type VirtualMachineInstanceInterface interface {
 	VNC(name string) (StreamInterface, error)
 	Pause(name string) error
 	Unpause(name string) error
+	SoftReboot(name string) error
 	GuestOsInfo(name string) (v1.VirtualMachineInstanceGuestAgentInfo, error)
 	UserList(name string) (v1.VirtualMachineInstanceGuestOSUserList, error)
 	FilesystemList(name string) (v1.VirtualMachineInstanceFileSystemList, error)
 	AddVolume(name string, addVolumeOptions *v1.AddVolumeOptions) error
 	RemoveVolume(name string, removeVolumeOptions *v1.RemoveVolumeOptions) error
+	ExportDisk(name string, exportDiskOptions *v1.ExportDiskOptions) error
+	MemoryDump(name string, memoryDumpOptions *v1.MemoryDumpOptions) error
 }
 
 type ReplicaSetInterface interface {
@@ -241,6 +241,43 @@ var _ = Describe("Kubevirt VirtualMachineInstance Client", func() {
 		Expect(err).ToNot(HaveOccurred())
 	})
 
+	It("should soft reboot a VirtualMachineInstance", func() {
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("PUT", subVMPath+"/softreboot"),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, nil),
+		))
+		err := client.VirtualMachineInstance(k8sv1.NamespaceDefault).SoftReboot("testvm")
+
+		Expect(server.ReceivedRequests()).To(HaveLen(1))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should export a disk of a VirtualMachineInstance", func() {
+		opts := &v1.ExportDiskOptions{VolumeName: "rootdisk", DataVolumeName: "rootdisk-export"}
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("PUT", subVMPath+"/exportdisk"),
+			ghttp.VerifyBody([]byte(`{"volumeName":"rootdisk","dataVolumeName":"rootdisk-export"}`)),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, nil),
+		))
+		err := client.VirtualMachineInstance(k8sv1.NamespaceDefault).ExportDisk("testvm", opts)
+
+		Expect(server.ReceivedRequests()).To(HaveLen(1))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should dump the memory of a VirtualMachineInstance", func() {
+		opts := &v1.MemoryDumpOptions{ClaimName: "dump-claim"}
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("PUT", subVMPath+"/memorydump"),
+			ghttp.VerifyBody([]byte(`{"claimName":"dump-claim"}`)),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, nil),
+		))
+		err := client.VirtualMachineInstance(k8sv1.NamespaceDefault).MemoryDump("testvm", opts)
+
+		Expect(server.ReceivedRequests()).To(HaveLen(1))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
 	It("should fetch GuestOSInfo from VirtualMachineInstance via subresource", func() {
 		osInfo := v1.VirtualMachineInstanceGuestAgentInfo{
 			GAVersion: "4.1.1",